@@ -0,0 +1,56 @@
+package codecs
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+// BlockTermState
+// Holds all state required for PostingsReaderBase to produce a PostingsEnum without re-seeking
+// the terms dict.
+// lucene.internal
+type BlockTermState interface {
+	index.TermState
+
+	// GetBlockTermState returns the part of the state owned by the terms dictionary.
+	GetBlockTermState() *BaseBlockTermState
+
+	// Clone returns a deep copy of the state.
+	Clone() BlockTermState
+}
+
+// BaseBlockTermState
+// The part of BlockTermState which is shared by every PostingsReaderBase implementation.
+type BaseBlockTermState struct {
+	// Ord
+	// Term ordinal, i.e. its position in the full list of sorted terms.
+	Ord int64
+
+	// DocFreq
+	// how many docs have this term
+	DocFreq int
+
+	// TotalTermFreq
+	// total number of occurrences of this term
+	TotalTermFreq int64
+
+	// TermBlockOrd
+	// the term's ord in the current block
+	TermBlockOrd int
+
+	// BlockFilePointer
+	// fp into the terms dict primary file (_X.tim) that holds this term
+	BlockFilePointer int64
+}
+
+func (b *BaseBlockTermState) GetBlockTermState() *BaseBlockTermState {
+	return b
+}
+
+// CopyFrom copies the terms dictionary owned part of other.
+func (b *BaseBlockTermState) CopyFrom(other index.TermState) {
+	state, ok := other.(BlockTermState)
+	if !ok {
+		return
+	}
+	*b = *state.GetBlockTermState()
+}
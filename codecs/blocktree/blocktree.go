@@ -0,0 +1,71 @@
+// Package blocktree implements a block based terms dictionary.
+//
+// Terms of a field are assigned to variable length blocks of consecutive terms. Each block
+// stores the shared-prefix compressed suffixes of its terms, their statistics and the
+// opaque metadata of the postings format, so that a lookup only has to decode a single block.
+// An FST maps the shortest prefix that identifies each block to the block's file pointer, and
+// is used to jump to the block a seek target may belong to.
+//
+// Files:
+//   - .tim: the term blocks, preceded by the postings writer's header
+//   - .tip: the FST index of each field
+//   - .tmd: per field metadata: statistics, min/max term, and the FST metadata
+package blocktree
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// DEFAULT_MIN_BLOCK_SIZE Suggested default value for the minItemsInBlock parameter to NewTermsWriter.
+	DEFAULT_MIN_BLOCK_SIZE = 25
+
+	// DEFAULT_MAX_BLOCK_SIZE Suggested default value for the maxItemsInBlock parameter to NewTermsWriter.
+	DEFAULT_MAX_BLOCK_SIZE = 48
+
+	// TERMS_EXTENSION Extension of terms file
+	TERMS_EXTENSION  = "tim"
+	TERMS_CODEC_NAME = "BlockTreeTermsDict"
+
+	// TERMS_INDEX_EXTENSION Extension of terms index file
+	TERMS_INDEX_EXTENSION  = "tip"
+	TERMS_INDEX_CODEC_NAME = "BlockTreeTermsIndex"
+
+	// TERMS_META_EXTENSION Extension of terms meta file
+	TERMS_META_EXTENSION  = "tmd"
+	TERMS_META_CODEC_NAME = "BlockTreeTermsMeta"
+
+	VERSION_START   = 0
+	VERSION_CURRENT = VERSION_START
+)
+
+// ErrUnsupportedOperation is returned by the operations the block tree terms dictionary
+// doesn't support, e.g. term ordinals.
+var ErrUnsupportedOperation = errors.New("unsupported operation")
+
+// ValidateSettings Throws error if the incoming settings are invalid.
+func ValidateSettings(minItemsInBlock, maxItemsInBlock int) error {
+	if minItemsInBlock <= 1 {
+		return fmt.Errorf("minItemsInBlock must be >= 2; got %d", minItemsInBlock)
+	}
+	if minItemsInBlock > maxItemsInBlock {
+		return fmt.Errorf("maxItemsInBlock must be >= minItemsInBlock; got maxItemsInBlock=%d minItemsInBlock=%d",
+			maxItemsInBlock, minItemsInBlock)
+	}
+	if 2*(minItemsInBlock-1) > maxItemsInBlock {
+		return fmt.Errorf("maxItemsInBlock must be at least 2*(minItemsInBlock-1); got maxItemsInBlock=%d minItemsInBlock=%d",
+			maxItemsInBlock, minItemsInBlock)
+	}
+	return nil
+}
+
+func commonPrefixLen(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
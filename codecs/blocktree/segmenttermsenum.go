@@ -0,0 +1,399 @@
+package blocktree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/fst"
+)
+
+var _ index.TermsEnum = &segmentTermsEnum{}
+
+// segmentTermsEnum Iterates through terms in this field.
+// The enum decodes one block at a time; the postings metadata of a term is only decoded when
+// it is needed (docFreq, postings, term state...).
+type segmentTermsEnum struct {
+	*coreIndex.BaseTermsEnum
+
+	fr *fieldReader
+
+	// lazily cloned from the terms dict input
+	in store.IndexInput
+
+	// lazily created enum over the terms index
+	indexEnum *fst.Enum[byte]
+
+	// file pointer of the loaded block, -1 if no block is loaded
+	blockFP int64
+
+	// file pointer of the block following the loaded one
+	nextBlockFP int64
+
+	// number of terms in the loaded block
+	entCount int
+
+	// index of the current term in the loaded block, -1 before the first term
+	termOrd int
+
+	// how many terms of the block have their stats and metadata decoded into state
+	metaDataUpto int
+
+	suffixesReader *store.BytesInput
+	statsReader    *store.BytesInput
+	metaReader     *store.BytesInput
+
+	suffixBytes []byte
+	statBytes   []byte
+	metaBytes   []byte
+
+	term  []byte
+	state codecs.BlockTermState
+
+	// the term was positioned by SeekExactExpert: state is valid but no block is loaded
+	seekPending bool
+	eof         bool
+}
+
+func newSegmentTermsEnum(fr *fieldReader) *segmentTermsEnum {
+	enum := &segmentTermsEnum{
+		fr:      fr,
+		blockFP: -1,
+		termOrd: -1,
+		state:   fr.parent.postingsReader.NewTermState(),
+	}
+	enum.BaseTermsEnum = coreIndex.NewBaseTermsEnum(&coreIndex.BaseTermsEnumConfig{SeekCeil: enum.SeekCeil})
+	return enum
+}
+
+func (e *segmentTermsEnum) input() store.IndexInput {
+	if e.in == nil {
+		e.in = e.fr.parent.termsIn.Clone().(store.IndexInput)
+	}
+	return e.in
+}
+
+// Loads the block starting at fp, leaving the enum before its first term.
+func (e *segmentTermsEnum) loadBlock(ctx context.Context, fp int64) error {
+	in := e.input()
+	if _, err := in.Seek(fp, io.SeekStart); err != nil {
+		return err
+	}
+
+	entCount, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+
+	if e.suffixBytes, err = readBlob(ctx, in, e.suffixBytes); err != nil {
+		return err
+	}
+	if e.statBytes, err = readBlob(ctx, in, e.statBytes); err != nil {
+		return err
+	}
+	if e.metaBytes, err = readBlob(ctx, in, e.metaBytes); err != nil {
+		return err
+	}
+
+	e.suffixesReader = store.NewBytesInput(e.suffixBytes)
+	e.statsReader = store.NewBytesInput(e.statBytes)
+	e.metaReader = store.NewBytesInput(e.metaBytes)
+
+	e.blockFP = fp
+	e.nextBlockFP = in.GetFilePointer()
+	e.entCount = int(entCount)
+	e.termOrd = -1
+	e.metaDataUpto = 0
+	e.term = e.term[:0]
+	e.seekPending = false
+	e.eof = false
+	return nil
+}
+
+func readBlob(ctx context.Context, in store.IndexInput, buf []byte) ([]byte, error) {
+	size, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	buf = slices.Grow(buf[:0], int(size))[:size]
+	if _, err := io.ReadFull(in, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Decodes the next term of the loaded block.
+func (e *segmentTermsEnum) nextEnt(ctx context.Context) error {
+	shared, err := e.suffixesReader.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	suffix, err := e.suffixesReader.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Always allocate a fresh term, callers are allowed to hold on to the previous one.
+	term := make([]byte, int(shared)+int(suffix))
+	copy(term, e.term[:shared])
+	if _, err := io.ReadFull(e.suffixesReader, term[shared:]); err != nil {
+		return err
+	}
+	e.term = term
+	e.termOrd++
+	return nil
+}
+
+// Decodes the stats and postings metadata of every term up to the current one.
+func (e *segmentTermsEnum) decodeMetaData(ctx context.Context) error {
+	if e.seekPending {
+		// state was set by SeekExactExpert
+		return nil
+	}
+	if e.termOrd < 0 {
+		return errors.New("terms enum is not positioned")
+	}
+
+	state := e.state.GetBlockTermState()
+	for e.metaDataUpto <= e.termOrd {
+		docFreq, err := e.statsReader.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		state.DocFreq = int(docFreq)
+
+		if e.fr.fieldInfo.GetIndexOptions() == document.INDEX_OPTIONS_DOCS {
+			// all postings have freq=1
+			state.TotalTermFreq = int64(docFreq)
+		} else {
+			delta, err := e.statsReader.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			state.TotalTermFreq = int64(docFreq) + int64(delta)
+		}
+
+		// metadata
+		absolute := e.metaDataUpto == 0
+		if err := e.fr.parent.postingsReader.DecodeTerm(ctx, e.metaReader, e.fr.fieldInfo, e.state, absolute); err != nil {
+			return err
+		}
+
+		e.metaDataUpto++
+		state.TermBlockOrd = e.metaDataUpto
+		state.BlockFilePointer = e.blockFP
+	}
+	return nil
+}
+
+// Returns the file pointer of the block a target may belong to.
+func (e *segmentTermsEnum) floorBlock(ctx context.Context, target []byte) (int64, error) {
+	if e.fr.index == nil {
+		return e.fr.firstBlockFP, nil
+	}
+
+	if e.indexEnum == nil {
+		indexEnum, err := fst.NewEnum[byte](e.fr.index)
+		if err != nil {
+			return 0, err
+		}
+		e.indexEnum = indexEnum
+	}
+
+	result, ok, err := e.indexEnum.SeekFloor(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	if !ok || result == nil {
+		// target is smaller than the key of the second block
+		return e.fr.firstBlockFP, nil
+	}
+
+	output, ok := result.GetOutput().(*fst.IntBox[int64])
+	if !ok {
+		return 0, errors.New("unexpected terms index output")
+	}
+	return output.Value(), nil
+}
+
+func (e *segmentTermsEnum) Next(ctx context.Context) ([]byte, error) {
+	if e.seekPending {
+		// Re-position on the term set by SeekExactExpert, then move past it
+		status, err := e.SeekCeil(ctx, e.term)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case index.SEEK_STATUS_END:
+			return nil, io.EOF
+		case index.SEEK_STATUS_NOT_FOUND:
+			return e.term, nil
+		}
+	}
+
+	if e.eof {
+		return nil, io.EOF
+	}
+
+	if e.blockFP == -1 {
+		if err := e.loadBlock(ctx, e.fr.firstBlockFP); err != nil {
+			return nil, err
+		}
+	}
+
+	for e.termOrd+1 >= e.entCount {
+		if e.nextBlockFP >= e.fr.endFP {
+			e.eof = true
+			return nil, io.EOF
+		}
+		if err := e.loadBlock(ctx, e.nextBlockFP); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.nextEnt(ctx); err != nil {
+		return nil, err
+	}
+	return e.term, nil
+}
+
+func (e *segmentTermsEnum) SeekExact(ctx context.Context, target []byte) (bool, error) {
+	if bytes.Compare(target, e.fr.minTerm) < 0 || bytes.Compare(target, e.fr.maxTerm) > 0 {
+		return false, nil
+	}
+
+	fp, err := e.floorBlock(ctx, target)
+	if err != nil {
+		return false, err
+	}
+
+	// The block index keys partition the term space, so if the target exists it can only be
+	// in this block.
+	if err := e.loadBlock(ctx, fp); err != nil {
+		return false, err
+	}
+
+	for e.termOrd+1 < e.entCount {
+		if err := e.nextEnt(ctx); err != nil {
+			return false, err
+		}
+
+		cmp := bytes.Compare(e.term, target)
+		if cmp == 0 {
+			return true, nil
+		}
+		if cmp > 0 {
+			break
+		}
+	}
+	return false, nil
+}
+
+func (e *segmentTermsEnum) SeekCeil(ctx context.Context, target []byte) (index.SeekStatus, error) {
+	if bytes.Compare(target, e.fr.maxTerm) > 0 {
+		e.eof = true
+		e.seekPending = false
+		return index.SEEK_STATUS_END, nil
+	}
+
+	fp, err := e.floorBlock(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.loadBlock(ctx, fp); err != nil {
+		return 0, err
+	}
+
+	for {
+		for e.termOrd+1 < e.entCount {
+			if err := e.nextEnt(ctx); err != nil {
+				return 0, err
+			}
+
+			cmp := bytes.Compare(e.term, target)
+			if cmp == 0 {
+				return index.SEEK_STATUS_FOUND, nil
+			}
+			if cmp > 0 {
+				return index.SEEK_STATUS_NOT_FOUND, nil
+			}
+		}
+
+		// target falls between this block's last term and the next block's first term
+		if e.nextBlockFP >= e.fr.endFP {
+			e.eof = true
+			return index.SEEK_STATUS_END, nil
+		}
+		if err := e.loadBlock(ctx, e.nextBlockFP); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (e *segmentTermsEnum) SeekExactByOrd(ctx context.Context, ord int64) error {
+	return ErrUnsupportedOperation
+}
+
+func (e *segmentTermsEnum) SeekExactExpert(ctx context.Context, term []byte, state index.TermState) error {
+	e.state.CopyFrom(state)
+	e.term = slices.Clone(term)
+	e.blockFP = -1
+	e.seekPending = true
+	e.eof = false
+	return nil
+}
+
+func (e *segmentTermsEnum) Term() ([]byte, error) {
+	if e.eof {
+		return nil, io.EOF
+	}
+	return e.term, nil
+}
+
+func (e *segmentTermsEnum) Ord() (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (e *segmentTermsEnum) DocFreq() (int, error) {
+	if err := e.decodeMetaData(context.Background()); err != nil {
+		return 0, err
+	}
+	return e.state.GetBlockTermState().DocFreq, nil
+}
+
+func (e *segmentTermsEnum) TotalTermFreq() (int64, error) {
+	if err := e.decodeMetaData(context.Background()); err != nil {
+		return 0, err
+	}
+	return e.state.GetBlockTermState().TotalTermFreq, nil
+}
+
+func (e *segmentTermsEnum) Postings(reuse index.PostingsEnum, flags int) (index.PostingsEnum, error) {
+	ctx := context.Background()
+	if err := e.decodeMetaData(ctx); err != nil {
+		return nil, err
+	}
+	return e.fr.parent.postingsReader.Postings(ctx, e.fr.fieldInfo, e.state, reuse, flags)
+}
+
+func (e *segmentTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
+	ctx := context.Background()
+	if err := e.decodeMetaData(ctx); err != nil {
+		return nil, err
+	}
+	return e.fr.parent.postingsReader.Impacts(ctx, e.fr.fieldInfo, e.state, flags)
+}
+
+func (e *segmentTermsEnum) TermState() (index.TermState, error) {
+	if err := e.decodeMetaData(context.Background()); err != nil {
+		return nil, err
+	}
+	return e.state.Clone(), nil
+}
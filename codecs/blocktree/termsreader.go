@@ -0,0 +1,399 @@
+package blocktree
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/geange/lucene-go/core/util/fst"
+)
+
+var _ index.FieldsProducer = &TermsReader{}
+
+// TermsReader
+// A block-based terms index and dictionary that assigns terms to variable length blocks according
+// to how they share prefixes. The terms index is an FST mapping the block keys to the file
+// pointer of the on-disk block. This reader only loads the FST of each field and the field
+// statistics into RAM; seeking a term decodes at most one block.
+//
+// Use Lucene84PostingsFormat (or another PostingsReaderBase) to read the postings.
+// See Also: TermsWriter
+// lucene.experimental
+type TermsReader struct {
+	// Open input to the main terms dict file (_X.tim)
+	termsIn store.IndexInput
+
+	// Open input to the terms index file (_X.tip)
+	indexIn store.IndexInput
+
+	// Reads the terms dict entries, to gather state to produce DocsEnum on demand
+	postingsReader codecs.PostingsReaderBase
+
+	fields     map[string]*fieldReader
+	fieldNames []string
+
+	segment string
+	version int
+}
+
+// NewTermsReader Sole constructor.
+func NewTermsReader(ctx context.Context, postingsReader codecs.PostingsReaderBase,
+	state *index.SegmentReadState) (*TermsReader, error) {
+
+	r := &TermsReader{
+		postingsReader: postingsReader,
+		fields:         make(map[string]*fieldReader),
+		segment:        state.SegmentInfo.Name(),
+	}
+
+	if err := r.open(ctx, state); err != nil {
+		_ = r.closeInputs()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *TermsReader) open(ctx context.Context, state *index.SegmentReadState) error {
+	segmentID := state.SegmentInfo.GetID()
+
+	termsName := store.SegmentFileName(r.segment, state.SegmentSuffix, TERMS_EXTENSION)
+	termsIn, err := state.Directory.OpenInput(ctx, termsName)
+	if err != nil {
+		return err
+	}
+	r.termsIn = termsIn
+	r.version, err = utils.CheckIndexHeader(ctx, r.termsIn, TERMS_CODEC_NAME, VERSION_START, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix)
+	if err != nil {
+		return err
+	}
+
+	indexName := store.SegmentFileName(r.segment, state.SegmentSuffix, TERMS_INDEX_EXTENSION)
+	indexIn, err := state.Directory.OpenInput(ctx, indexName)
+	if err != nil {
+		return err
+	}
+	r.indexIn = indexIn
+	if _, err := utils.CheckIndexHeader(ctx, r.indexIn, TERMS_INDEX_CODEC_NAME, r.version, r.version,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	// Have PostingsReader init itself
+	if err := r.postingsReader.Init(ctx, r.termsIn, state); err != nil {
+		return err
+	}
+
+	// NOTE: data file is too costly to verify checksum against all the bytes on open,
+	// but for now we at least verify proper structure of the checksum footer: which looks
+	// for FOOTER_MAGIC + algorithmID. This is cheap and can detect some forms of corruption
+	// such as file truncation.
+	if _, err := utils.RetrieveChecksum(ctx, r.termsIn); err != nil {
+		return err
+	}
+	if _, err := utils.RetrieveChecksum(ctx, r.indexIn); err != nil {
+		return err
+	}
+
+	metaName := store.SegmentFileName(r.segment, state.SegmentSuffix, TERMS_META_EXTENSION)
+	metaIn, err := store.OpenChecksumInput(ctx, state.Directory, metaName)
+	if err != nil {
+		return err
+	}
+	defer metaIn.Close()
+
+	if _, err := utils.CheckIndexHeader(ctx, metaIn, TERMS_META_CODEC_NAME, r.version, r.version,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+	if err := r.readFields(ctx, metaIn, state); err != nil {
+		return err
+	}
+	if _, err := utils.CheckCodecFooter(ctx, metaIn); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TermsReader) readFields(ctx context.Context, metaIn store.DataInput, state *index.SegmentReadState) error {
+	maxDoc, err := state.SegmentInfo.MaxDoc()
+	if err != nil {
+		return err
+	}
+
+	numFields, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < int(numFields); i++ {
+		field, err := metaIn.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		fieldInfo := state.FieldInfos.FieldInfoByNumber(int(field))
+		if fieldInfo == nil {
+			return fmt.Errorf("invalid field number: %d", field)
+		}
+
+		reader, err := r.readField(ctx, metaIn, fieldInfo)
+		if err != nil {
+			return err
+		}
+
+		if reader.docCount < 0 || reader.docCount > maxDoc {
+			return fmt.Errorf("invalid docCount: %d maxDoc: %d", reader.docCount, maxDoc)
+		}
+		if reader.sumDocFreq < int64(reader.docCount) {
+			return fmt.Errorf("invalid sumDocFreq: %d docCount: %d", reader.sumDocFreq, reader.docCount)
+		}
+		if reader.sumTotalTermFreq < reader.sumDocFreq {
+			return fmt.Errorf("invalid sumTotalTermFreq: %d sumDocFreq: %d", reader.sumTotalTermFreq, reader.sumDocFreq)
+		}
+
+		name := fieldInfo.Name()
+		if _, ok := r.fields[name]; ok {
+			return fmt.Errorf("duplicate field: %s", name)
+		}
+		r.fields[name] = reader
+		r.fieldNames = append(r.fieldNames, name)
+	}
+	sort.Strings(r.fieldNames)
+	return nil
+}
+
+func (r *TermsReader) readField(ctx context.Context, metaIn store.DataInput, fieldInfo *document.FieldInfo) (*fieldReader, error) {
+	numTerms, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if numTerms <= 0 {
+		return nil, fmt.Errorf("illegal numTerms for field number: %d", fieldInfo.Number())
+	}
+
+	sumTotalTermFreq := uint64(0)
+	if fieldInfo.GetIndexOptions() != document.INDEX_OPTIONS_DOCS {
+		sumTotalTermFreq, err = metaIn.ReadUvarint(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sumDocFreq, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if fieldInfo.GetIndexOptions() == document.INDEX_OPTIONS_DOCS {
+		// when frequencies are omitted, sumDocFreq=sumTotalTermFreq and we only write one value
+		sumTotalTermFreq = sumDocFreq
+	}
+	docCount, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	minTerm, err := readBytesRef(ctx, metaIn)
+	if err != nil {
+		return nil, err
+	}
+	maxTerm, err := readBytesRef(ctx, metaIn)
+	if err != nil {
+		return nil, err
+	}
+	firstBlockFP, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endFP, err := metaIn.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hasIndex, err := metaIn.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var termsIndex *fst.FST
+	if hasIndex == 1 {
+		indexStartFP, err := metaIn.ReadUvarint(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.indexIn.Seek(int64(indexStartFP), io.SeekStart); err != nil {
+			return nil, err
+		}
+		termsIndex, err = fst.NewFstV1(ctx, fst.NewBoxManager[int64](), metaIn, r.indexIn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &fieldReader{
+		parent:           r,
+		fieldInfo:        fieldInfo,
+		numTerms:         int64(numTerms),
+		sumTotalTermFreq: int64(sumTotalTermFreq),
+		sumDocFreq:       int64(sumDocFreq),
+		docCount:         int(docCount),
+		minTerm:          minTerm,
+		maxTerm:          maxTerm,
+		firstBlockFP:     int64(firstBlockFP),
+		endFP:            int64(endFP),
+		index:            termsIndex,
+	}, nil
+}
+
+func readBytesRef(ctx context.Context, in store.DataInput) ([]byte, error) {
+	size, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bytes := make([]byte, size)
+	if _, err := io.ReadFull(in, bytes); err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+func (r *TermsReader) Names() []string {
+	return slices.Clone(r.fieldNames)
+}
+
+func (r *TermsReader) Terms(field string) (index.Terms, error) {
+	reader, ok := r.fields[field]
+	if !ok {
+		return nil, nil
+	}
+	return reader, nil
+}
+
+func (r *TermsReader) Size() int {
+	return len(r.fields)
+}
+
+func (r *TermsReader) CheckIntegrity() error {
+	ctx := context.Background()
+
+	// terms index
+	if _, err := utils.ChecksumEntireFile(ctx, r.indexIn); err != nil {
+		return err
+	}
+
+	// term dictionary
+	if _, err := utils.ChecksumEntireFile(ctx, r.termsIn); err != nil {
+		return err
+	}
+
+	// postings
+	return r.postingsReader.CheckIntegrity(ctx)
+}
+
+func (r *TermsReader) GetMergeInstance() index.FieldsProducer {
+	return r
+}
+
+func (r *TermsReader) Close() error {
+	err := r.closeInputs()
+	if closeErr := r.postingsReader.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	// Clear so refs to terms index is GCable even if app hangs onto us:
+	clear(r.fields)
+	return err
+}
+
+func (r *TermsReader) closeInputs() error {
+	var err error
+	for _, in := range []store.IndexInput{r.termsIn, r.indexIn} {
+		if in == nil {
+			continue
+		}
+		if closeErr := in.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	r.termsIn, r.indexIn = nil, nil
+	return err
+}
+
+var _ index.Terms = &fieldReader{}
+
+// fieldReader BlockTree's implementation of index.Terms.
+type fieldReader struct {
+	parent    *TermsReader
+	fieldInfo *document.FieldInfo
+
+	numTerms         int64
+	sumTotalTermFreq int64
+	sumDocFreq       int64
+	docCount         int
+	minTerm          []byte
+	maxTerm          []byte
+
+	// file pointers of the first block and the end of the last block in the terms dict
+	firstBlockFP int64
+	endFP        int64
+
+	// nil when all terms of the field fit in a single block
+	index *fst.FST
+}
+
+func (f *fieldReader) Iterator() (index.TermsEnum, error) {
+	return newSegmentTermsEnum(f), nil
+}
+
+// Intersect The blocks of a field are laid out one after the other rather than as a tree, so
+// there are no sub-blocks to prune while walking the automaton. The AutomatonTermsEnum drives the
+// segment enum with SeekCeil instead, which goes through the terms index straight to the block
+// holding the next candidate term, skipping every block in between.
+func (f *fieldReader) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (index.TermsEnum, error) {
+	return coreIndex.NewTerms(f).Intersect(compiled, startTerm)
+}
+
+func (f *fieldReader) Size() (int, error) {
+	return int(f.numTerms), nil
+}
+
+func (f *fieldReader) GetSumTotalTermFreq() (int64, error) {
+	return f.sumTotalTermFreq, nil
+}
+
+func (f *fieldReader) GetSumDocFreq() (int64, error) {
+	return f.sumDocFreq, nil
+}
+
+func (f *fieldReader) GetDocCount() (int, error) {
+	return f.docCount, nil
+}
+
+func (f *fieldReader) HasFreqs() bool {
+	return f.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS
+}
+
+func (f *fieldReader) HasOffsets() bool {
+	return f.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+}
+
+func (f *fieldReader) HasPositions() bool {
+	return f.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS
+}
+
+func (f *fieldReader) HasPayloads() bool {
+	return f.fieldInfo.HasPayloads()
+}
+
+func (f *fieldReader) GetMin() ([]byte, error) {
+	return slices.Clone(f.minTerm), nil
+}
+
+func (f *fieldReader) GetMax() ([]byte, error) {
+	return slices.Clone(f.maxTerm), nil
+}
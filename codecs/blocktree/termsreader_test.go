@@ -0,0 +1,87 @@
+package blocktree_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestReader Indexes ids 000 to numDocs-1 with the block tree terms dictionary of lucene87, the
+// terms span many blocks
+func newTestReader(t *testing.T, numDocs int) index.DirectoryReader {
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = dir.Close() })
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	writer, err := coreIndex.NewIndexWriter(context.Background(), dir,
+		coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity))
+	assert.Nil(t, err)
+
+	for i := 0; i < numDocs; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewStringField("id", fmt.Sprintf("%03d", i), false))
+		_, err := writer.AddDocument(context.Background(), doc)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Commit(context.Background()))
+	assert.Nil(t, writer.Close())
+
+	reader, err := coreIndex.OpenDirectoryReader(context.Background(), dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+	return reader
+}
+
+func intersect(t *testing.T, reader index.DirectoryReader, pattern string, startTerm []byte) []string {
+	regexp, err := automaton.NewRegExp(pattern)
+	assert.Nil(t, err)
+	a, err := regexp.ToAutomaton()
+	assert.Nil(t, err)
+	compiled, err := automaton.NewCompiledAutomaton(a, nil, true, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT, false)
+	assert.Nil(t, err)
+	assert.Equal(t, automaton.AUTOMATON_TYPE_NORMAL, compiled.Type())
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+
+	matches := make([]string, 0)
+	for _, leaf := range leaves {
+		terms, err := leaf.LeafReader().Terms("id")
+		assert.Nil(t, err)
+		termsEnum, err := terms.Intersect(compiled, startTerm)
+		assert.Nil(t, err)
+		for {
+			term, err := termsEnum.Next(context.Background())
+			assert.Nil(t, err)
+			if term == nil {
+				break
+			}
+			matches = append(matches, string(term))
+		}
+	}
+	return matches
+}
+
+func TestFieldReader_Intersect(t *testing.T) {
+	reader := newTestReader(t, 300)
+
+	assert.Equal(t, []string{"105", "115", "125", "135", "145", "155", "165", "175", "185", "195"},
+		intersect(t, reader, "1[0-9]5", nil))
+	assert.Equal(t, []string{"000", "010", "020"}, intersect(t, reader, "0[0-2]0", nil))
+	assert.Equal(t, []string{"001", "002", "003", "298", "299"}, intersect(t, reader, "00[1-3]|29[89]", nil))
+	assert.Empty(t, intersect(t, reader, "3.*", nil))
+
+	// only the terms after startTerm
+	assert.Equal(t, []string{"165", "175", "185", "195"}, intersect(t, reader, "1[0-9]5", []byte("155")))
+}
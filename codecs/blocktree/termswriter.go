@@ -0,0 +1,518 @@
+package blocktree
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/fst"
+)
+
+var _ index.FieldsConsumer = &TermsWriter{}
+
+// TermsWriter
+// Block-based terms index and dictionary writer.
+//
+// Writes terms dict and index, block-encoding (column stride) each term's metadata for each set
+// of terms between two index terms.
+//
+// Terms are assigned to blocks of at least minItemsInBlock and at most maxItemsInBlock terms,
+// cutting where consecutive terms share the shortest prefix, which keeps the index keys short.
+// The blocks of a field are written one after another to the .tim file; the FST stored in the
+// .tip file maps the shortest prefix of each block's first term that is still greater than the
+// previous block's last term to the block's file pointer.
+//
+// Block layout in the .tim file:
+//
+//	Block      --> NumTerms, SuffixLength, Suffixes, StatsLength, Stats, MetaLength, Meta
+//	Suffixes   --> <SharedPrefix, SuffixLen, SuffixBytes> NumTerms
+//	Stats      --> <DocFreq, TotalTermFreq - DocFreq (only if freqs are indexed)> NumTerms
+//	Meta       --> postings metadata written by PostingsWriterBase.EncodeTerm, the first
+//	               term of each block is absolute encoded
+//
+// lucene.experimental
+type TermsWriter struct {
+	metaOut  store.IndexOutput
+	termsOut store.IndexOutput
+	indexOut store.IndexOutput
+
+	maxDoc          int
+	minItemsInBlock int
+	maxItemsInBlock int
+
+	postingsWriter codecs.PostingsWriterBase
+	fieldInfos     index.FieldInfos
+
+	fields []*fieldMetaData
+	closed bool
+}
+
+// NewTermsWriter Create a new writer. The number of items (terms or sub-blocks) per block will
+// aim to be between minItemsPerBlock and maxItemsPerBlock, though in some cases the blocks may be
+// smaller than the min.
+func NewTermsWriter(ctx context.Context, state *index.SegmentWriteState, postingsWriter codecs.PostingsWriterBase,
+	minItemsInBlock, maxItemsInBlock int) (*TermsWriter, error) {
+
+	if err := ValidateSettings(minItemsInBlock, maxItemsInBlock); err != nil {
+		return nil, err
+	}
+
+	maxDoc, err := state.SegmentInfo.MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &TermsWriter{
+		maxDoc:          maxDoc,
+		minItemsInBlock: minItemsInBlock,
+		maxItemsInBlock: maxItemsInBlock,
+		postingsWriter:  postingsWriter,
+		fieldInfos:      state.FieldInfos,
+		fields:          make([]*fieldMetaData, 0),
+	}
+
+	if err := w.openOutputs(ctx, state); err != nil {
+		_ = w.closeOutputs()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *TermsWriter) openOutputs(ctx context.Context, state *index.SegmentWriteState) error {
+	segment := state.SegmentInfo.Name()
+	segmentID := state.SegmentInfo.GetID()
+
+	termsName := store.SegmentFileName(segment, state.SegmentSuffix, TERMS_EXTENSION)
+	termsOut, err := state.Directory.CreateOutput(ctx, termsName)
+	if err != nil {
+		return err
+	}
+	w.termsOut = termsOut
+	if err := utils.WriteIndexHeader(ctx, w.termsOut, TERMS_CODEC_NAME, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	indexName := store.SegmentFileName(segment, state.SegmentSuffix, TERMS_INDEX_EXTENSION)
+	indexOut, err := state.Directory.CreateOutput(ctx, indexName)
+	if err != nil {
+		return err
+	}
+	w.indexOut = indexOut
+	if err := utils.WriteIndexHeader(ctx, w.indexOut, TERMS_INDEX_CODEC_NAME, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	metaName := store.SegmentFileName(segment, state.SegmentSuffix, TERMS_META_EXTENSION)
+	metaOut, err := state.Directory.CreateOutput(ctx, metaName)
+	if err != nil {
+		return err
+	}
+	w.metaOut = metaOut
+	if err := utils.WriteIndexHeader(ctx, w.metaOut, TERMS_META_CODEC_NAME, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	// have consumer write its format/header
+	return w.postingsWriter.Init(ctx, w.termsOut, state)
+}
+
+func (w *TermsWriter) Write(ctx context.Context, fields index.Fields, norms index.NormsProducer) error {
+	names := slices.Clone(fields.Names())
+	sort.Strings(names)
+
+	for _, field := range names {
+		terms, err := fields.Terms(field)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return err
+		}
+		if terms == nil {
+			continue
+		}
+
+		fieldInfo := w.fieldInfos.FieldInfo(field)
+		if fieldInfo == nil {
+			return errors.New("unknown field: " + field)
+		}
+
+		termsEnum, err := terms.Iterator()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return err
+		}
+
+		writer, err := w.newFieldWriter(fieldInfo)
+		if err != nil {
+			return err
+		}
+
+		for {
+			term, err := termsEnum.Next(ctx)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			if term == nil {
+				break
+			}
+
+			if err := writer.write(ctx, term, termsEnum, norms); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.finish(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *TermsWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	err := w.writeMeta(context.Background())
+	if closeErr := w.closeOutputs(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if closeErr := w.postingsWriter.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *TermsWriter) writeMeta(ctx context.Context) error {
+	if err := w.metaOut.WriteUvarint(ctx, uint64(len(w.fields))); err != nil {
+		return err
+	}
+	for _, field := range w.fields {
+		if err := field.writeTo(ctx, w.metaOut); err != nil {
+			return err
+		}
+	}
+
+	if err := utils.WriteFooter(w.termsOut); err != nil {
+		return err
+	}
+	if err := utils.WriteFooter(w.indexOut); err != nil {
+		return err
+	}
+	return utils.WriteFooter(w.metaOut)
+}
+
+func (w *TermsWriter) closeOutputs() error {
+	var err error
+	for _, out := range []store.IndexOutput{w.metaOut, w.termsOut, w.indexOut} {
+		if out == nil {
+			continue
+		}
+		if closeErr := out.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	w.metaOut, w.termsOut, w.indexOut = nil, nil, nil
+	return err
+}
+
+// fieldMetaData holds everything the reader needs to know about one field, written to the
+// meta file once all fields are done.
+type fieldMetaData struct {
+	fieldInfo        *document.FieldInfo
+	numTerms         int64
+	sumTotalTermFreq int64
+	sumDocFreq       int64
+	docCount         int
+	minTerm          []byte
+	maxTerm          []byte
+	firstBlockFP     int64
+	endFP            int64
+	indexStartFP     int64
+
+	// serialized FST metadata, nil when the field fits in a single block
+	indexMeta *store.BufferOutput
+}
+
+func (f *fieldMetaData) writeTo(ctx context.Context, out store.DataOutput) error {
+	if err := out.WriteUvarint(ctx, uint64(f.fieldInfo.Number())); err != nil {
+		return err
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.numTerms)); err != nil {
+		return err
+	}
+	if f.fieldInfo.GetIndexOptions() != document.INDEX_OPTIONS_DOCS {
+		if err := out.WriteUvarint(ctx, uint64(f.sumTotalTermFreq)); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.sumDocFreq)); err != nil {
+		return err
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.docCount)); err != nil {
+		return err
+	}
+	if err := writeBytesRef(ctx, out, f.minTerm); err != nil {
+		return err
+	}
+	if err := writeBytesRef(ctx, out, f.maxTerm); err != nil {
+		return err
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.firstBlockFP)); err != nil {
+		return err
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.endFP)); err != nil {
+		return err
+	}
+
+	if f.indexMeta == nil {
+		return out.WriteByte(0)
+	}
+	if err := out.WriteByte(1); err != nil {
+		return err
+	}
+	if err := out.WriteUvarint(ctx, uint64(f.indexStartFP)); err != nil {
+		return err
+	}
+	return f.indexMeta.CopyTo(out)
+}
+
+func writeBytesRef(ctx context.Context, out store.DataOutput, bytes []byte) error {
+	if err := out.WriteUvarint(ctx, uint64(len(bytes))); err != nil {
+		return err
+	}
+	_, err := out.Write(bytes)
+	return err
+}
+
+type pendingTerm struct {
+	term  []byte
+	state codecs.BlockTermState
+}
+
+// fieldWriter collects the terms of a single field and writes them block by block.
+type fieldWriter struct {
+	w         *TermsWriter
+	fieldInfo *document.FieldInfo
+	hasFreqs  bool
+
+	numTerms         int64
+	sumTotalTermFreq int64
+	sumDocFreq       int64
+	docsSeen         *bitset.BitSet
+
+	firstTerm []byte
+	lastTerm  []byte
+
+	pending []*pendingTerm
+
+	numBlocks    int
+	firstBlockFP int64
+
+	// last term of the previously written block
+	prevBlockLastTerm []byte
+
+	indexBuilder *fst.Builder
+
+	suffixWriter *store.BufferOutput
+	statsWriter  *store.BufferOutput
+	metaWriter   *store.BufferOutput
+}
+
+func (w *TermsWriter) newFieldWriter(fieldInfo *document.FieldInfo) (*fieldWriter, error) {
+	builder, err := fst.NewBuilder(fst.BYTE1, fst.NewBoxManager[int64]())
+	if err != nil {
+		return nil, err
+	}
+
+	w.postingsWriter.SetField(fieldInfo)
+
+	return &fieldWriter{
+		w:            w,
+		fieldInfo:    fieldInfo,
+		hasFreqs:     fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS,
+		docsSeen:     bitset.New(uint(w.maxDoc)),
+		pending:      make([]*pendingTerm, 0, w.maxItemsInBlock+1),
+		indexBuilder: builder,
+		suffixWriter: store.NewBufferDataOutput(),
+		statsWriter:  store.NewBufferDataOutput(),
+		metaWriter:   store.NewBufferDataOutput(),
+	}, nil
+}
+
+// Writes one term's worth of postings.
+func (f *fieldWriter) write(ctx context.Context, text []byte, termsEnum index.TermsEnum, norms index.NormsProducer) error {
+	state, err := f.w.postingsWriter.WriteTerm(ctx, text, termsEnum, f.docsSeen, norms)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		// no docs for this term
+		return nil
+	}
+
+	term := slices.Clone(text)
+	f.pending = append(f.pending, &pendingTerm{term: term, state: state})
+
+	blockState := state.GetBlockTermState()
+	f.sumDocFreq += int64(blockState.DocFreq)
+	if f.hasFreqs {
+		f.sumTotalTermFreq += blockState.TotalTermFreq
+	}
+	f.numTerms++
+	if f.firstTerm == nil {
+		f.firstTerm = term
+	}
+	f.lastTerm = term
+
+	if len(f.pending) > f.w.maxItemsInBlock {
+		return f.writeBlock(ctx, f.pending[:f.cutPoint()])
+	}
+	return nil
+}
+
+// cutPoint picks the size of the next block among [minItemsInBlock, maxItemsInBlock], preferring
+// the cut where the boundary terms share the shortest prefix, so that the index key of the
+// following block is as short as possible.
+func (f *fieldWriter) cutPoint() int {
+	best := f.w.maxItemsInBlock
+	bestPrefix := commonPrefixLen(f.pending[best-1].term, f.pending[best].term)
+	for i := f.w.maxItemsInBlock - 1; i >= f.w.minItemsInBlock && bestPrefix > 0; i-- {
+		prefix := commonPrefixLen(f.pending[i-1].term, f.pending[i].term)
+		if prefix < bestPrefix {
+			best, bestPrefix = i, prefix
+		}
+	}
+	return best
+}
+
+func (f *fieldWriter) writeBlock(ctx context.Context, terms []*pendingTerm) error {
+	termsOut := f.w.termsOut
+	fp := termsOut.GetFilePointer()
+
+	if f.numBlocks == 0 {
+		// The first block is not indexed: every target smaller than the second block's
+		// key falls back to it.
+		f.firstBlockFP = fp
+	} else {
+		first := terms[0].term
+		key := first[:commonPrefixLen(f.prevBlockLastTerm, first)+1]
+		if err := f.indexBuilder.AddInts(ctx, toInts(key), fst.NewIntBox[int64](fp)); err != nil {
+			return err
+		}
+	}
+
+	var lastTerm []byte
+	for i, pending := range terms {
+		shared := commonPrefixLen(lastTerm, pending.term)
+		if err := f.suffixWriter.WriteUvarint(ctx, uint64(shared)); err != nil {
+			return err
+		}
+		if err := writeBytesRef(ctx, f.suffixWriter, pending.term[shared:]); err != nil {
+			return err
+		}
+
+		state := pending.state.GetBlockTermState()
+		if err := f.statsWriter.WriteUvarint(ctx, uint64(state.DocFreq)); err != nil {
+			return err
+		}
+		if f.hasFreqs {
+			if err := f.statsWriter.WriteUvarint(ctx, uint64(state.TotalTermFreq-int64(state.DocFreq))); err != nil {
+				return err
+			}
+		}
+
+		if err := f.w.postingsWriter.EncodeTerm(ctx, f.metaWriter, f.fieldInfo, pending.state, i == 0); err != nil {
+			return err
+		}
+		lastTerm = pending.term
+	}
+
+	if err := termsOut.WriteUvarint(ctx, uint64(len(terms))); err != nil {
+		return err
+	}
+	for _, buf := range []*store.BufferOutput{f.suffixWriter, f.statsWriter, f.metaWriter} {
+		if err := termsOut.WriteUvarint(ctx, uint64(buf.GetFilePointer())); err != nil {
+			return err
+		}
+		if err := buf.CopyTo(termsOut); err != nil {
+			return err
+		}
+		buf.Reset()
+	}
+
+	f.prevBlockLastTerm = lastTerm
+	f.numBlocks++
+
+	// Remove slice replaced by block:
+	n := copy(f.pending, f.pending[len(terms):])
+	clear(f.pending[n:])
+	f.pending = f.pending[:n]
+	return nil
+}
+
+// Finishes all terms in this field.
+func (f *fieldWriter) finish(ctx context.Context) error {
+	if f.numTerms == 0 {
+		return nil
+	}
+
+	if len(f.pending) > 0 {
+		if err := f.writeBlock(ctx, f.pending); err != nil {
+			return err
+		}
+	}
+
+	meta := &fieldMetaData{
+		fieldInfo:        f.fieldInfo,
+		numTerms:         f.numTerms,
+		sumTotalTermFreq: f.sumTotalTermFreq,
+		sumDocFreq:       f.sumDocFreq,
+		docCount:         int(f.docsSeen.Count()),
+		minTerm:          f.firstTerm,
+		maxTerm:          f.lastTerm,
+		firstBlockFP:     f.firstBlockFP,
+		endFP:            f.w.termsOut.GetFilePointer(),
+	}
+
+	if f.numBlocks > 1 {
+		termsIndex, err := f.indexBuilder.Finish(ctx)
+		if err != nil {
+			return err
+		}
+		meta.indexStartFP = f.w.indexOut.GetFilePointer()
+		meta.indexMeta = store.NewBufferDataOutput()
+		if err := termsIndex.Save(ctx, meta.indexMeta, f.w.indexOut); err != nil {
+			return err
+		}
+	}
+
+	f.w.fields = append(f.w.fields, meta)
+	return nil
+}
+
+func toInts(bytes []byte) []int {
+	ints := make([]int, len(bytes))
+	for i, b := range bytes {
+		ints[i] = int(b)
+	}
+	return ints
+}
@@ -0,0 +1,199 @@
+package compressing
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/compress"
+)
+
+// CompressionMode
+// A compression mode. Tells how much effort should be spent on compression and decompression
+// of stored fields.
+// lucene.experimental
+type CompressionMode interface {
+	// NewCompressor Create a new Compressor instance.
+	NewCompressor() Compressor
+
+	// NewDecompressor Create a new Decompressor instance.
+	NewDecompressor() Decompressor
+}
+
+// Compressor A data compressor.
+type Compressor interface {
+	// Compress bytes into out. It is the responsibility of the compressor to add all
+	// necessary information so that a Decompressor will know when to stop decompressing bytes
+	// from the stream.
+	Compress(ctx context.Context, bytes []byte, out store.DataOutput) error
+}
+
+// Decompressor A decompressor.
+type Decompressor interface {
+	// Decompress bytes that were stored between offsets offset and offset+length in the
+	// original stream from the compressed stream in to dst. originalLength is the length of the
+	// original data (before compression). The returned slice shares dst's storage when it is
+	// large enough.
+	Decompress(ctx context.Context, in store.DataInput, originalLength, offset, length int, dst []byte) ([]byte, error)
+}
+
+var (
+	// FAST A compression mode that trades compression ratio for speed. Although the
+	// compression ratio might remain high, compression and decompression are very fast.
+	// Use this mode with indices that have a high update rate but should be able to load
+	// documents from disk quickly.
+	FAST CompressionMode = &lz4Mode{high: false}
+
+	// HIGH_COMPRESSION A compression mode that trades speed for compression ratio. Although
+	// compression and decompression might be slow, this compression mode should provide a
+	// good compression ratio. This mode might be interesting if/when your index size is much
+	// bigger than your OS cache.
+	HIGH_COMPRESSION CompressionMode = &deflateMode{level: 6}
+
+	// FAST_DECOMPRESSION This compression mode is similar to FAST but it spends more time
+	// compressing in order to improve the compression ratio. This compression mode is best
+	// used with indices that have a low update rate but should be able to load documents from
+	// disk quickly.
+	FAST_DECOMPRESSION CompressionMode = &lz4Mode{high: true}
+)
+
+type lz4Mode struct {
+	high bool
+}
+
+func (m *lz4Mode) NewCompressor() Compressor {
+	if m.high {
+		return &lz4Compressor{ht: compress.NewLZ4HighHashTable()}
+	}
+	return &lz4Compressor{ht: compress.NewLZ4FastHashTable()}
+}
+
+func (m *lz4Mode) NewDecompressor() Decompressor {
+	return &lz4Decompressor{}
+}
+
+func (m *lz4Mode) String() string {
+	if m.high {
+		return "FAST_DECOMPRESSION"
+	}
+	return "FAST"
+}
+
+type lz4Compressor struct {
+	ht compress.LZ4HashTable
+}
+
+func (c *lz4Compressor) Compress(ctx context.Context, bytes []byte, out store.DataOutput) error {
+	return compress.LZ4Compress(bytes, out, c.ht)
+}
+
+type lz4Decompressor struct {
+}
+
+func (d *lz4Decompressor) Decompress(ctx context.Context, in store.DataInput, originalLength, offset, length int, dst []byte) ([]byte, error) {
+	if offset+length > originalLength {
+		return nil, fmt.Errorf("offset+length(%d) > originalLength(%d)", offset+length, originalLength)
+	}
+	// add 7 padding bytes, this is not necessary but can help decompression run faster
+	dst = growBytes(dst, originalLength+7)
+	// LZ4 stops decompressing once offset+length bytes are restored
+	decompressedLen, err := compress.LZ4Decompress(in, offset+length, dst)
+	if err != nil {
+		return nil, err
+	}
+	if decompressedLen > originalLength {
+		return nil, fmt.Errorf("corrupted: lengths mismatch: %d > %d", decompressedLen, originalLength)
+	}
+	return dst[offset : offset+length], nil
+}
+
+type deflateMode struct {
+	level int
+}
+
+func (m *deflateMode) NewCompressor() Compressor {
+	return &deflateCompressor{level: m.level, buf: new(bytes.Buffer)}
+}
+
+func (m *deflateMode) NewDecompressor() Decompressor {
+	return &deflateDecompressor{}
+}
+
+func (m *deflateMode) String() string {
+	return "HIGH_COMPRESSION"
+}
+
+type deflateCompressor struct {
+	level  int
+	buf    *bytes.Buffer
+	writer *flate.Writer
+}
+
+func (c *deflateCompressor) Compress(ctx context.Context, bytes []byte, out store.DataOutput) error {
+	c.buf.Reset()
+	if c.writer == nil {
+		writer, err := flate.NewWriter(c.buf, c.level)
+		if err != nil {
+			return err
+		}
+		c.writer = writer
+	} else {
+		c.writer.Reset(c.buf)
+	}
+
+	if _, err := c.writer.Write(bytes); err != nil {
+		return err
+	}
+	if err := c.writer.Close(); err != nil {
+		return err
+	}
+
+	if err := out.WriteUvarint(ctx, uint64(c.buf.Len())); err != nil {
+		return err
+	}
+	_, err := out.Write(c.buf.Bytes())
+	return err
+}
+
+type deflateDecompressor struct {
+	compressed []byte
+	reader     io.ReadCloser
+}
+
+func (d *deflateDecompressor) Decompress(ctx context.Context, in store.DataInput, originalLength, offset, length int, dst []byte) ([]byte, error) {
+	if offset+length > originalLength {
+		return nil, fmt.Errorf("offset+length(%d) > originalLength(%d)", offset+length, originalLength)
+	}
+	compressedLength, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.compressed = growBytes(d.compressed, int(compressedLength))
+	if _, err := io.ReadFull(in, d.compressed); err != nil {
+		return nil, err
+	}
+
+	src := bytes.NewReader(d.compressed)
+	if d.reader == nil {
+		d.reader = flate.NewReader(src)
+	} else if err := d.reader.(flate.Resetter).Reset(src, nil); err != nil {
+		return nil, err
+	}
+
+	dst = growBytes(dst, originalLength)
+	if _, err := io.ReadFull(d.reader, dst); err != nil {
+		return nil, fmt.Errorf("corrupted: %w", err)
+	}
+	return dst[offset : offset+length], nil
+}
+
+// growBytes Returns a slice of the given length, reusing buf's storage if it is large enough.
+func growBytes(buf []byte, size int) []byte {
+	if cap(buf) < size {
+		return make([]byte, size)
+	}
+	return buf[:size]
+}
@@ -0,0 +1,381 @@
+package compressing_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/geange/lucene-go/codecs/compressing"
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util"
+	"github.com/geange/lucene-go/core/util/version"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testChunkSize       = 1024
+	testMaxDocsPerChunk = 16
+)
+
+// testCodec The lucene87 codec with tiny chunks of stored fields, so that few documents span many
+// chunks
+type testCodec struct {
+	*lucene87.Codec
+
+	storedFieldsFormat index.StoredFieldsFormat
+}
+
+func (c *testCodec) GetName() string {
+	return "CompressingTest"
+}
+
+func (c *testCodec) StoredFieldsFormat() index.StoredFieldsFormat {
+	return c.storedFieldsFormat
+}
+
+func init() {
+	format, err := compressing.NewStoredFieldsFormat("CompressingTestData", "", compressing.FAST,
+		testChunkSize, testMaxDocsPerChunk, 64)
+	if err != nil {
+		panic(err)
+	}
+	coreIndex.RegisterCodec(&testCodec{Codec: lucene87.NewCodec(), storedFieldsFormat: format})
+}
+
+func newTestWriter(t *testing.T, dir store.Directory) *coreIndex.IndexWriter {
+	codec, ok := coreIndex.GetCodecByName("CompressingTest")
+	assert.True(t, ok)
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(codec, similarity)
+	config.SetMergePolicy(coreIndex.NewTieredMergePolicy())
+	config.SetMergeScheduler(coreIndex.NewSerialMergeScheduler())
+	writer, err := coreIndex.NewIndexWriter(context.Background(), dir, config)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = writer.Rollback(context.Background()) })
+	return writer
+}
+
+type storedValue struct {
+	name  string
+	value any
+}
+
+// randomString Returns n random letters, which compress little
+func randomString(r *rand.Rand, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteByte(byte('a' + r.Intn(26)))
+	}
+	return sb.String()
+}
+
+// testDocument Returns the stored values of doc, with every stored type, values of many lengths,
+// fields with several values and some documents larger than a chunk
+func testDocument(r *rand.Rand, doc int) []storedValue {
+	values := []storedValue{{"id", fmt.Sprintf("%04d", doc)}}
+	if doc%11 == 5 {
+		// only the id
+		return values
+	}
+
+	bytes := make([]byte, doc%40)
+	r.Read(bytes)
+	values = append(values,
+		storedValue{"int", int32(doc * -7)},
+		storedValue{"long", int64(doc)<<40 - 3},
+		storedValue{"float", float32(doc) / 3},
+		storedValue{"double", float64(doc) * math.Pi},
+		storedValue{"bytes", bytes},
+		storedValue{"text", strings.Repeat("stored ", doc%30)},
+	)
+	if doc%3 == 0 {
+		values = append(values, storedValue{"int", int32(math.MinInt32)}, storedValue{"double", math.Inf(-1)})
+	}
+	if doc%97 == 13 {
+		large := make([]byte, 70*1024)
+		r.Read(large)
+		values = append(values, storedValue{"large", randomString(r, 100*1024)}, storedValue{"bytes", large})
+	}
+	return values
+}
+
+func newDocument(values []storedValue) *document.Document {
+	doc := document.NewDocument()
+	for _, v := range values {
+		switch value := v.value.(type) {
+		case string:
+			if v.name == "id" {
+				doc.Add(document.NewStringField(v.name, value, true))
+			} else {
+				doc.Add(document.NewStoredField(v.name, value))
+			}
+		case []byte:
+			doc.Add(document.NewStoredField(v.name, value))
+		case int32:
+			doc.Add(document.NewStoredField(v.name, value))
+		case int64:
+			doc.Add(document.NewStoredField(v.name, value))
+		case float32:
+			doc.Add(document.NewStoredField(v.name, value))
+		case float64:
+			doc.Add(document.NewStoredField(v.name, value))
+		}
+	}
+	return doc
+}
+
+// addDocuments Adds the docs from to to-1 and returns their stored values by id
+func addDocuments(t *testing.T, writer *coreIndex.IndexWriter, r *rand.Rand, from, to int, expected map[string][]storedValue) {
+	for i := from; i < to; i++ {
+		values := testDocument(r, i)
+		_, err := writer.AddDocument(context.Background(), newDocument(values))
+		assert.Nil(t, err)
+		expected[values[0].value.(string)] = values
+	}
+}
+
+func storedValues(t *testing.T, reader index.IndexReader, docID int) []storedValue {
+	doc, err := reader.Document(context.Background(), docID)
+	assert.Nil(t, err)
+	values := make([]storedValue, 0)
+	for field := range doc.GetFields() {
+		values = append(values, storedValue{field.Name(), field.Get()})
+	}
+	return values
+}
+
+// assertStoredFields Checks the stored values of every doc of the index, visiting the docs in a random
+// order so that chunks are read again and again
+func assertStoredFields(t *testing.T, dir store.Directory, r *rand.Rand, expected map[string][]storedValue) {
+	reader, err := coreIndex.OpenDirectoryReader(context.Background(), dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, len(expected), reader.NumDocs())
+	for _, docID := range r.Perm(reader.MaxDoc()) {
+		values := storedValues(t, reader, docID)
+		if reader.HasDeletions() {
+			id, _ := values[0].value.(string)
+			if _, ok := expected[id]; !ok {
+				continue
+			}
+		}
+		assert.Equal(t, expected[values[0].value.(string)], values, "doc %d", docID)
+	}
+}
+
+// fieldsReaders Returns the stored fields readers of the segments of the index
+func fieldsReaders(t *testing.T, dir store.Directory) []*compressing.StoredFieldsReader {
+	reader, err := coreIndex.OpenDirectoryReader(context.Background(), dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	readers := make([]*compressing.StoredFieldsReader, 0, len(leaves))
+	for _, leaf := range leaves {
+		fieldsReader := leaf.LeafReader().(index.CodecReader).GetFieldsReader()
+		readers = append(readers, fieldsReader.(*compressing.StoredFieldsReader))
+	}
+	return readers
+}
+
+func TestStoredFields_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newTestWriter(t, dir)
+	expected := make(map[string][]storedValue)
+	addDocuments(t, writer, r, 0, 500, expected)
+	assert.Nil(t, writer.Commit(context.Background()))
+
+	assertStoredFields(t, dir, r, expected)
+
+	readers := fieldsReaders(t, dir)
+	assert.Len(t, readers, 1)
+	assert.Equal(t, testChunkSize, readers[0].GetChunkSize())
+	// only the last chunk was flushed before it was full
+	assert.EqualValues(t, 1, readers[0].GetNumDirtyChunks())
+	assert.Less(t, readers[0].GetNumDirtyDocs(), int64(testMaxDocsPerChunk))
+}
+
+func TestStoredFields_ChunkBoundaries(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+
+	// documents just below, at and above the chunk size, alone or after small documents
+	for _, sizes := range [][]int{
+		{0},
+		{testChunkSize - 20},
+		{testChunkSize},
+		{testChunkSize + 1},
+		{10, testChunkSize - 30, 10},
+		{5 * testChunkSize, 1, 3 * testChunkSize},
+		{1 << 20},
+	} {
+		t.Run(fmt.Sprint(sizes), func(t *testing.T) {
+			dir, err := store.NewNIOFSDirectory(t.TempDir())
+			assert.Nil(t, err)
+			defer dir.Close()
+
+			writer := newTestWriter(t, dir)
+			expected := make(map[string][]storedValue)
+			for i, size := range sizes {
+				values := []storedValue{{"id", fmt.Sprintf("%04d", i)}, {"text", randomString(r, size)}}
+				_, err := writer.AddDocument(context.Background(), newDocument(values))
+				assert.Nil(t, err)
+				expected[values[0].value.(string)] = values
+			}
+			assert.Nil(t, writer.Commit(context.Background()))
+
+			assertStoredFields(t, dir, r, expected)
+		})
+	}
+
+	// exactly maxDocsPerChunk documents fill a chunk, nothing is left for a dirty one
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+	writer := newTestWriter(t, dir)
+	expected := make(map[string][]storedValue)
+	for i := 0; i < 2*testMaxDocsPerChunk; i++ {
+		values := []storedValue{{"id", fmt.Sprintf("%04d", i)}}
+		_, err := writer.AddDocument(context.Background(), newDocument(values))
+		assert.Nil(t, err)
+		expected[values[0].value.(string)] = values
+	}
+	assert.Nil(t, writer.Commit(context.Background()))
+	assertStoredFields(t, dir, r, expected)
+	assert.EqualValues(t, 0, fieldsReaders(t, dir)[0].GetNumDirtyChunks())
+}
+
+func TestStoredFields_Merge(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewSource(7))
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newTestWriter(t, dir)
+	expected := make(map[string][]storedValue)
+	for i, numDocs := range []int{40, 37, 150} {
+		from := len(expected)
+		addDocuments(t, writer, r, from, from+numDocs, expected)
+		assert.Nil(t, writer.Commit(ctx))
+		assert.Len(t, fieldsReaders(t, dir), i+1)
+	}
+	var numDirtyChunks, numDirtyDocs int64
+	for _, reader := range fieldsReaders(t, dir) {
+		numDirtyChunks += reader.GetNumDirtyChunks()
+		numDirtyDocs += reader.GetNumDirtyDocs()
+	}
+
+	// segments without deletions are copied chunk by chunk, their dirty chunks come along
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+	assert.Nil(t, writer.Commit(ctx))
+	readers := fieldsReaders(t, dir)
+	assert.Len(t, readers, 1)
+	assert.Greater(t, numDirtyChunks, int64(1))
+	assert.Equal(t, numDirtyChunks, readers[0].GetNumDirtyChunks())
+	assert.Equal(t, numDirtyDocs, readers[0].GetNumDirtyDocs())
+	assertStoredFields(t, dir, r, expected)
+}
+
+// deletedDocsReader A segment where the docs missing from liveDocs are deleted
+type deletedDocsReader struct {
+	index.CodecReader
+
+	liveDocs *bitset.BitSet
+}
+
+func (r *deletedDocsReader) GetLiveDocs() util.Bits {
+	return r.liveDocs
+}
+
+func (r *deletedDocsReader) NumDocs() int {
+	return int(r.liveDocs.Count())
+}
+
+func TestStoredFields_MergeDeletes(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewSource(11))
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newTestWriter(t, dir)
+	expected := make(map[string][]storedValue)
+	addDocuments(t, writer, r, 0, 120, expected)
+	assert.Nil(t, writer.Commit(ctx))
+	addDocuments(t, writer, r, 120, 205, expected)
+	assert.Nil(t, writer.Commit(ctx))
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	assert.Len(t, leaves, 2)
+
+	// the first segment loses a third of its docs and gets recompressed, the second is copied
+	first := leaves[0].LeafReader().(index.CodecReader)
+	liveDocs := bitset.New(uint(first.MaxDoc()))
+	for docID := 0; docID < first.MaxDoc(); docID++ {
+		if docID%3 != 1 {
+			liveDocs.Set(uint(docID))
+		}
+	}
+	readers := []index.CodecReader{
+		&deletedDocsReader{CodecReader: first, liveDocs: liveDocs},
+		leaves[1].LeafReader().(index.CodecReader),
+	}
+
+	want := make([][]storedValue, 0)
+	for i, leaf := range leaves {
+		for docID := 0; docID < leaf.LeafReader().MaxDoc(); docID++ {
+			if i == 0 && !liveDocs.Test(uint(docID)) {
+				continue
+			}
+			want = append(want, storedValues(t, reader, leaf.DocBase()+docID))
+		}
+	}
+
+	codec := writer.GetConfig().GetCodec()
+	info := coreIndex.NewSegmentInfo(dir, version.Last, nil, "_merged", -1, false, codec,
+		map[string]string{}, util.RandomId(), map[string]string{}, nil)
+	ioCtx := store.NewIOContext(store.WithMergeInfo(store.NewMergeInfo(len(want), -1, false, 1)))
+	merger, err := coreIndex.NewSegmentMerger(readers, info, dir, coreIndex.NewFieldNumbers(""), ioCtx)
+	assert.Nil(t, err)
+	mergeState, err := merger.Merge(ctx)
+	assert.Nil(t, err)
+
+	fieldsReader, err := codec.StoredFieldsFormat().FieldsReader(ctx, dir, info, mergeState.MergeFieldInfos, ioCtx)
+	assert.Nil(t, err)
+	defer fieldsReader.Close()
+	merged := fieldsReader.(*compressing.StoredFieldsReader)
+
+	second := leaves[1].LeafReader().(index.CodecReader).GetFieldsReader().(*compressing.StoredFieldsReader)
+	assert.EqualValues(t, 1, second.GetNumDirtyChunks())
+	assert.EqualValues(t, 2, merged.GetNumDirtyChunks())
+
+	for _, docID := range r.Perm(len(want)) {
+		visitor := document.NewDocumentStoredFieldVisitor()
+		assert.Nil(t, merged.VisitDocument(ctx, docID, visitor))
+		values := make([]storedValue, 0)
+		for field := range visitor.GetDocument().GetFields() {
+			values = append(values, storedValue{field.Name(), field.Get()})
+		}
+		assert.Equal(t, want[docID], values, "doc %d", docID)
+	}
+}
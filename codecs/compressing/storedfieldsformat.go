@@ -0,0 +1,90 @@
+package compressing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+var _ index.StoredFieldsFormat = &StoredFieldsFormat{}
+
+// StoredFieldsFormat
+// A StoredFieldsFormat that compresses documents in chunks in order to improve the
+// compression ratio.
+//
+// For a chunk size of chunkSize bytes, this StoredFieldsFormat does not support documents
+// larger than (2^31 - chunkSize) bytes.
+//
+// For optimal performance, you should use a MergePolicy that returns segments that have the
+// biggest byte size first.
+// lucene.experimental
+type StoredFieldsFormat struct {
+	formatName      string
+	segmentSuffix   string
+	compressionMode CompressionMode
+	chunkSize       int
+	maxDocsPerChunk int
+	blockSize       int
+}
+
+// NewStoredFieldsFormat
+// Create a new StoredFieldsFormat.
+//
+// formatName is the name of the format. This name will be used in the file formats to
+// perform codec header checks.
+//
+// segmentSuffix is the segment suffix. This suffix is added to the result file name only if
+// it's not the empty string.
+//
+// The compressionMode parameter allows you to choose between compression algorithms that
+// have various compression and decompression speeds so that you can pick the one that best
+// fits your indexing and searching throughput. You should never instantiate two
+// StoredFieldsFormats that have the same name but different CompressionModes.
+//
+// chunkSize is the minimum byte size of a chunk of documents. A value of 1 can make sense if
+// there is redundancy across fields. maxDocsPerChunk is an upperbound on how many docs may be
+// stored in a single chunk. This is to bound the cpu costs for highly compressible data.
+//
+// Higher values of chunkSize should improve the compression ratio but will require more
+// memory at indexing time and might make document loading a little slower (depending on the
+// size of your OS cache compared to the size of your index).
+//
+// blockSize is the number of chunks per block of the monotonic chunk index.
+func NewStoredFieldsFormat(formatName, segmentSuffix string, compressionMode CompressionMode,
+	chunkSize, maxDocsPerChunk, blockSize int) (*StoredFieldsFormat, error) {
+
+	if chunkSize < 1 {
+		return nil, fmt.Errorf("chunkSize must be >= 1, got %d", chunkSize)
+	}
+	if maxDocsPerChunk < 1 {
+		return nil, fmt.Errorf("maxDocsPerChunk must be >= 1, got %d", maxDocsPerChunk)
+	}
+	if blockSize < 64 || blockSize&(blockSize-1) != 0 {
+		return nil, fmt.Errorf("blockSize must be a power of two >= 64, got %d", blockSize)
+	}
+
+	return &StoredFieldsFormat{
+		formatName:      formatName,
+		segmentSuffix:   segmentSuffix,
+		compressionMode: compressionMode,
+		chunkSize:       chunkSize,
+		maxDocsPerChunk: maxDocsPerChunk,
+		blockSize:       blockSize,
+	}, nil
+}
+
+func (s *StoredFieldsFormat) FieldsReader(ctx context.Context, directory store.Directory, si index.SegmentInfo, fn index.FieldInfos, ioContext *store.IOContext) (index.StoredFieldsReader, error) {
+	return NewStoredFieldsReader(ctx, directory, si, s.segmentSuffix, fn, ioContext, s.formatName, s.compressionMode)
+}
+
+func (s *StoredFieldsFormat) FieldsWriter(ctx context.Context, directory store.Directory, si index.SegmentInfo, ioContext *store.IOContext) (index.StoredFieldsWriter, error) {
+	return NewStoredFieldsWriter(ctx, directory, si, s.segmentSuffix, ioContext, s.formatName,
+		s.compressionMode, s.chunkSize, s.maxDocsPerChunk, s.blockSize)
+}
+
+func (s *StoredFieldsFormat) String() string {
+	return fmt.Sprintf("StoredFieldsFormat(compressionMode=%v, chunkSize=%d, maxDocsPerChunk=%d, blockSize=%d)",
+		s.compressionMode, s.chunkSize, s.maxDocsPerChunk, s.blockSize)
+}
@@ -0,0 +1,107 @@
+package compressing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/store"
+)
+
+// Per-chunk numbers of stored fields and document lengths are written with a fixed number of
+// bits per value, which is cheap to decode:
+//   - 0: all values are equal, followed by a vInt
+//   - 8, 16 or 32: every value is written on as many bits
+
+func writeInts(ctx context.Context, values []int, out store.DataOutput) error {
+	allEqual := true
+	maxValue := 0
+	for _, v := range values {
+		if v != values[0] {
+			allEqual = false
+		}
+		maxValue |= v
+	}
+
+	if allEqual {
+		if err := out.WriteByte(0); err != nil {
+			return err
+		}
+		return out.WriteUvarint(ctx, uint64(values[0]))
+	}
+
+	switch {
+	case maxValue <= 0xFF:
+		if err := out.WriteByte(8); err != nil {
+			return err
+		}
+		for _, v := range values {
+			if err := out.WriteByte(byte(v)); err != nil {
+				return err
+			}
+		}
+	case maxValue <= 0xFFFF:
+		if err := out.WriteByte(16); err != nil {
+			return err
+		}
+		for _, v := range values {
+			if err := out.WriteUint16(ctx, uint16(v)); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := out.WriteByte(32); err != nil {
+			return err
+		}
+		for _, v := range values {
+			if err := out.WriteUint32(ctx, uint32(v)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readInts(ctx context.Context, in store.DataInput, values []int) error {
+	bpv, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch bpv {
+	case 0:
+		v, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range values {
+			values[i] = int(v)
+		}
+	case 8:
+		for i := range values {
+			v, err := in.ReadByte()
+			if err != nil {
+				return err
+			}
+			values[i] = int(v)
+		}
+	case 16:
+		for i := range values {
+			v, err := in.ReadUint16(ctx)
+			if err != nil {
+				return err
+			}
+			values[i] = int(v)
+		}
+	case 32:
+		for i := range values {
+			v, err := in.ReadUint32(ctx)
+			if err != nil {
+				return err
+			}
+			values[i] = int(v)
+		}
+	default:
+		return fmt.Errorf("unsupported number of bits per value: %d", bpv)
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package compressing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+var _ index.StoredFieldsReader = &StoredFieldsReader{}
+
+// StoredFieldsReader
+// StoredFieldsReader impl for StoredFieldsFormat.
+// lucene.experimental
+type StoredFieldsReader struct {
+	fieldInfos   index.FieldInfos
+	fieldsStream store.IndexInput
+
+	// chunk index, loaded in memory
+	chunkDocBases      *packed.MonotonicBlockPackedReader
+	chunkStartPointers *packed.MonotonicBlockPackedReader
+
+	chunkSize      int
+	indexBlockSize int
+	numDocs        int
+	numChunks      int
+	maxPointer     int64
+	numDirtyChunks int64
+	numDirtyDocs   int64
+
+	compressionMode CompressionMode
+	decompressor    Decompressor
+
+	state *blockState
+
+	closed bool
+}
+
+// NewStoredFieldsReader Sole constructor.
+func NewStoredFieldsReader(ctx context.Context, directory store.Directory, si index.SegmentInfo,
+	segmentSuffix string, fn index.FieldInfos, ioContext *store.IOContext, formatName string,
+	compressionMode CompressionMode) (*StoredFieldsReader, error) {
+
+	reader := &StoredFieldsReader{
+		fieldInfos:      fn,
+		compressionMode: compressionMode,
+		decompressor:    compressionMode.NewDecompressor(),
+	}
+	reader.state = newBlockState(reader)
+
+	if err := reader.open(ctx, directory, si, segmentSuffix, formatName); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (s *StoredFieldsReader) open(ctx context.Context, directory store.Directory, si index.SegmentInfo,
+	segmentSuffix, formatName string) error {
+
+	segment := si.Name()
+	segmentID := si.GetID()
+
+	// Load the meta data
+	metaFileName := store.SegmentFileName(segment, segmentSuffix, FIELDS_META_EXTENSION)
+	metaIn, err := store.OpenChecksumInput(ctx, directory, metaFileName)
+	if err != nil {
+		return err
+	}
+	defer metaIn.Close()
+
+	if _, err := utils.CheckIndexHeader(ctx, metaIn, formatName+CODEC_SFX_META, VERSION_START, VERSION_CURRENT, segmentID, segmentSuffix); err != nil {
+		return err
+	}
+	if err := s.readMeta(ctx, metaIn); err != nil {
+		return err
+	}
+	if _, err := utils.CheckCodecFooter(ctx, metaIn); err != nil {
+		return err
+	}
+
+	maxDoc, err := si.MaxDoc()
+	if err != nil {
+		return err
+	}
+	if s.numDocs != maxDoc {
+		return fmt.Errorf("doc count mismatch: segment has %d docs, stored fields have %d", maxDoc, s.numDocs)
+	}
+
+	if err := s.loadIndex(ctx, directory, segment, segmentSuffix, formatName, segmentID); err != nil {
+		return err
+	}
+
+	// Open the data file
+	fieldsStreamFN := store.SegmentFileName(segment, segmentSuffix, FIELDS_EXTENSION)
+	if s.fieldsStream, err = directory.OpenInput(ctx, fieldsStreamFN); err != nil {
+		return err
+	}
+	if _, err := utils.CheckIndexHeader(ctx, s.fieldsStream, formatName+CODEC_SFX_DAT, VERSION_START, VERSION_CURRENT, segmentID, segmentSuffix); err != nil {
+		return err
+	}
+
+	// NOTE: data file is too costly to verify checksum against all the bytes on open,
+	// but for now we at least verify proper structure of the checksum footer: which looks
+	// for FOOTER_MAGIC + algorithmID. This is cheap and can detect some forms of corruption
+	// such as file truncation.
+	if _, err := utils.RetrieveChecksum(ctx, s.fieldsStream); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *StoredFieldsReader) readMeta(ctx context.Context, metaIn store.DataInput) error {
+	values := make([]uint64, 0, 7)
+	for i := 0; i < 7; i++ {
+		v, err := metaIn.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+
+	s.chunkSize = int(values[0])
+	s.indexBlockSize = int(values[1])
+	s.numDocs = int(values[2])
+	s.numChunks = int(values[3])
+	s.maxPointer = int64(values[4])
+	s.numDirtyChunks = int64(values[5])
+	s.numDirtyDocs = int64(values[6])
+
+	if s.numDirtyChunks > int64(s.numChunks) {
+		return fmt.Errorf("cannot have more dirty chunks than chunks: numChunks=%d, numDirtyChunks=%d",
+			s.numChunks, s.numDirtyChunks)
+	}
+	if s.numDirtyDocs > int64(s.numDocs) {
+		return fmt.Errorf("cannot have more dirty docs than docs: numDocs=%d, numDirtyDocs=%d",
+			s.numDocs, s.numDirtyDocs)
+	}
+	return nil
+}
+
+// Loads the doc bases and start pointers of the chunks in memory.
+func (s *StoredFieldsReader) loadIndex(ctx context.Context, directory store.Directory,
+	segment, segmentSuffix, formatName string, segmentID []byte) error {
+
+	indexFileName := store.SegmentFileName(segment, segmentSuffix, FIELDS_INDEX_EXTENSION)
+	indexIn, err := store.OpenChecksumInput(ctx, directory, indexFileName)
+	if err != nil {
+		return err
+	}
+	defer indexIn.Close()
+
+	if _, err := utils.CheckIndexHeader(ctx, indexIn, formatName+CODEC_SFX_IDX, VERSION_START, VERSION_CURRENT, segmentID, segmentSuffix); err != nil {
+		return err
+	}
+
+	if s.numChunks > 0 {
+		s.chunkDocBases, err = packed.NewMonotonicBlockPackedReader(ctx, indexIn, packed.VERSION_CURRENT, s.indexBlockSize, s.numChunks, false)
+		if err != nil {
+			return err
+		}
+		s.chunkStartPointers, err = packed.NewMonotonicBlockPackedReader(ctx, indexIn, packed.VERSION_CURRENT, s.indexBlockSize, s.numChunks, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = utils.CheckCodecFooter(ctx, indexIn)
+	return err
+}
+
+func (s *StoredFieldsReader) ensureOpen() error {
+	if s.closed {
+		return errors.New("this FieldsReader is closed")
+	}
+	return nil
+}
+
+// Close the underlying IndexInputs.
+func (s *StoredFieldsReader) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.fieldsStream != nil {
+		return s.fieldsStream.Close()
+	}
+	return nil
+}
+
+// Returns the index of the chunk which contains docID.
+func (s *StoredFieldsReader) chunkIndex(docID int) (int, error) {
+	var err error
+	// the first chunk whose doc base is greater than docID
+	idx := sort.Search(s.numChunks, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		docBase, getErr := s.chunkDocBases.Get(i)
+		if getErr != nil {
+			err = getErr
+			return true
+		}
+		return int(docBase) > docID
+	})
+	if err != nil {
+		return 0, err
+	}
+	return idx - 1, nil
+}
+
+func (s *StoredFieldsReader) VisitDocument(ctx context.Context, docID int, visitor document.StoredFieldVisitor) error {
+	if err := s.ensureOpen(); err != nil {
+		return err
+	}
+	if docID < 0 || docID >= s.numDocs {
+		return fmt.Errorf("docID must be >= 0 and < maxDoc=%d (got docID=%d)", s.numDocs, docID)
+	}
+
+	doc, err := s.document(ctx, docID)
+	if err != nil {
+		return err
+	}
+
+	for fieldIDX := 0; fieldIDX < doc.numStoredFields; fieldIDX++ {
+		infoAndBits, err := doc.in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		fieldNumber := int(infoAndBits >> TYPE_BITS)
+		fieldInfo := s.fieldInfos.FieldInfoByNumber(fieldNumber)
+		if fieldInfo == nil {
+			return fmt.Errorf("unknown field number %d", fieldNumber)
+		}
+
+		bits := int(infoAndBits & TYPE_MASK)
+		if bits > NUMERIC_DOUBLE {
+			return fmt.Errorf("bits=%x", bits)
+		}
+
+		status, err := visitor.NeedsField(fieldInfo)
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case document.STORED_FIELD_VISITOR_YES:
+			if err := readField(ctx, doc.in, visitor, fieldInfo, bits); err != nil {
+				return err
+			}
+		case document.STORED_FIELD_VISITOR_NO:
+			if fieldIDX == doc.numStoredFields-1 {
+				// don't skipField on last field value; treat like STOP
+				return nil
+			}
+			if err := skipField(ctx, doc.in, bits); err != nil {
+				return err
+			}
+		case document.STORED_FIELD_VISITOR_STOP:
+			return nil
+		default:
+			return fmt.Errorf("unknown status %d", status)
+		}
+	}
+	return nil
+}
+
+func readField(ctx context.Context, in store.DataInput, visitor document.StoredFieldVisitor,
+	info *document.FieldInfo, bits int) error {
+
+	switch bits & TYPE_MASK {
+	case BYTE_ARR:
+		data, err := readBytes(ctx, in)
+		if err != nil {
+			return err
+		}
+		return visitor.BinaryField(info, data)
+	case STRING:
+		data, err := readBytes(ctx, in)
+		if err != nil {
+			return err
+		}
+		return visitor.StringField(info, data)
+	case NUMERIC_INT:
+		v, err := in.ReadZInt32(ctx)
+		if err != nil {
+			return err
+		}
+		return visitor.Int32Field(info, int32(v))
+	case NUMERIC_FLOAT:
+		v, err := in.ReadUint32(ctx)
+		if err != nil {
+			return err
+		}
+		return visitor.Float32Field(info, math.Float32frombits(v))
+	case NUMERIC_LONG:
+		v, err := in.ReadZInt64(ctx)
+		if err != nil {
+			return err
+		}
+		return visitor.Int64Field(info, v)
+	case NUMERIC_DOUBLE:
+		v, err := in.ReadUint64(ctx)
+		if err != nil {
+			return err
+		}
+		return visitor.Float64Field(info, math.Float64frombits(v))
+	default:
+		return fmt.Errorf("unknown type flag: %x", bits)
+	}
+}
+
+func readBytes(ctx context.Context, in store.DataInput) ([]byte, error) {
+	length, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// visitors are allowed to keep the value, so it must not share the chunk's buffer
+	data := make([]byte, length)
+	if _, err := io.ReadFull(in, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func skipField(ctx context.Context, in store.DataInput, bits int) error {
+	switch bits & TYPE_MASK {
+	case BYTE_ARR, STRING:
+		length, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		return in.SkipBytes(ctx, int(length))
+	case NUMERIC_INT:
+		_, err := in.ReadZInt32(ctx)
+		return err
+	case NUMERIC_FLOAT:
+		_, err := in.ReadUint32(ctx)
+		return err
+	case NUMERIC_LONG:
+		_, err := in.ReadZInt64(ctx)
+		return err
+	case NUMERIC_DOUBLE:
+		_, err := in.ReadUint64(ctx)
+		return err
+	default:
+		return fmt.Errorf("unknown type flag: %x", bits)
+	}
+}
+
+// serializedDocument A serialized document, you need to decode its input in order to get
+// an actual Document.
+type serializedDocument struct {
+	// the serialized data
+	in store.DataInput
+
+	// the length of the serialized data
+	length int
+
+	// the number of stored fields
+	numStoredFields int
+}
+
+func (s *StoredFieldsReader) document(ctx context.Context, docID int) (*serializedDocument, error) {
+	if !s.state.contains(docID) {
+		chunk, err := s.chunkIndex(docID)
+		if err != nil {
+			return nil, err
+		}
+		startPointer, err := s.chunkStartPointers.Get(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.fieldsStream.Seek(int64(startPointer), io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.state.reset(ctx, docID); err != nil {
+			return nil, err
+		}
+	}
+	return s.state.document(docID)
+}
+
+// blockState Keeps state about the current block of documents.
+type blockState struct {
+	reader *StoredFieldsReader
+
+	docBase   int
+	chunkDocs int
+
+	// number of stored fields and start offset of every document of the chunk
+	numStoredFields []int
+	offsets         []int
+
+	// the decompressed chunk
+	bytes []byte
+}
+
+func newBlockState(reader *StoredFieldsReader) *blockState {
+	return &blockState{reader: reader}
+}
+
+func (b *blockState) contains(docID int) bool {
+	return docID >= b.docBase && docID < b.docBase+b.chunkDocs
+}
+
+// Reset this block so that it stores state for the block that contains the given doc id.
+// The fields stream must be positioned on the start of the chunk.
+func (b *blockState) reset(ctx context.Context, docID int) error {
+	if err := b.doReset(ctx, docID); err != nil {
+		// if the read failed, the state is undefined
+		b.chunkDocs = 0
+		return err
+	}
+	return nil
+}
+
+func (b *blockState) doReset(ctx context.Context, docID int) error {
+	in := b.reader.fieldsStream
+
+	docBase, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	chunkDocs, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	b.docBase = int(docBase)
+	b.chunkDocs = int(chunkDocs)
+	if !b.contains(docID) || b.docBase+b.chunkDocs > b.reader.numDocs {
+		return fmt.Errorf("docBase=%d, chunkDocs=%d, numDocs=%d, docID=%d",
+			b.docBase, b.chunkDocs, b.reader.numDocs, docID)
+	}
+
+	b.numStoredFields = growInts(b.numStoredFields, b.chunkDocs)
+	b.offsets = growInts(b.offsets, b.chunkDocs+1)
+
+	if err := readInts(ctx, in, b.numStoredFields); err != nil {
+		return err
+	}
+	// lengths are read into offsets[1:] and then summed up
+	if err := readInts(ctx, in, b.offsets[1:]); err != nil {
+		return err
+	}
+	b.offsets[0] = 0
+	for i := 0; i < b.chunkDocs; i++ {
+		b.offsets[i+1] += b.offsets[i]
+	}
+
+	totalLength := b.offsets[b.chunkDocs]
+	b.bytes, err = b.reader.decompressor.Decompress(ctx, in, totalLength, 0, totalLength, b.bytes)
+	if err != nil {
+		return err
+	}
+	if len(b.bytes) != totalLength {
+		return fmt.Errorf("corrupted: expected chunk length=%d, got length=%d", totalLength, len(b.bytes))
+	}
+	return nil
+}
+
+// Get the serialized representation of the given docID. This docID has to be contained in
+// the current block.
+func (b *blockState) document(docID int) (*serializedDocument, error) {
+	if !b.contains(docID) {
+		return nil, errors.New("docID is not contained in the current block")
+	}
+
+	index := docID - b.docBase
+	offset := b.offsets[index]
+	length := b.offsets[index+1] - offset
+
+	return &serializedDocument{
+		in:              store.NewBytesInput(b.bytes[offset : offset+length]),
+		length:          length,
+		numStoredFields: b.numStoredFields[index],
+	}, nil
+}
+
+func growInts(values []int, size int) []int {
+	if cap(values) < size {
+		return make([]int, size)
+	}
+	return values[:size]
+}
+
+func (s *StoredFieldsReader) Clone(ctx context.Context) index.StoredFieldsReader {
+	clone := &StoredFieldsReader{
+		fieldInfos:         s.fieldInfos,
+		fieldsStream:       s.fieldsStream.Clone().(store.IndexInput),
+		chunkDocBases:      s.chunkDocBases,
+		chunkStartPointers: s.chunkStartPointers,
+		chunkSize:          s.chunkSize,
+		indexBlockSize:     s.indexBlockSize,
+		numDocs:            s.numDocs,
+		numChunks:          s.numChunks,
+		maxPointer:         s.maxPointer,
+		numDirtyChunks:     s.numDirtyChunks,
+		numDirtyDocs:       s.numDirtyDocs,
+		compressionMode:    s.compressionMode,
+		decompressor:       s.compressionMode.NewDecompressor(),
+	}
+	clone.state = newBlockState(clone)
+	return clone
+}
+
+func (s *StoredFieldsReader) CheckIntegrity() error {
+	_, err := utils.ChecksumEntireFile(context.Background(), s.fieldsStream.Clone().(store.IndexInput))
+	return err
+}
+
+func (s *StoredFieldsReader) GetMergeInstance() index.StoredFieldsReader {
+	return s
+}
+
+// GetNumDirtyChunks Returns the number of chunks that were flushed before they were full.
+func (s *StoredFieldsReader) GetNumDirtyChunks() int64 {
+	return s.numDirtyChunks
+}
+
+// GetNumDirtyDocs Returns the number of documents stored in chunks that were flushed before
+// they were full.
+func (s *StoredFieldsReader) GetNumDirtyDocs() int64 {
+	return s.numDirtyDocs
+}
+
+// GetChunkSize Returns the minimum byte size of a chunk.
+func (s *StoredFieldsReader) GetChunkSize() int {
+	return s.chunkSize
+}
@@ -0,0 +1,497 @@
+package compressing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+const (
+	// FIELDS_EXTENSION Extension of stored fields file
+	FIELDS_EXTENSION = "fdt"
+
+	// FIELDS_INDEX_EXTENSION Extension of stored fields index
+	FIELDS_INDEX_EXTENSION = "fdx"
+
+	// FIELDS_META_EXTENSION Extension of stored fields meta
+	FIELDS_META_EXTENSION = "fdm"
+
+	CODEC_SFX_DAT  = "Data"
+	CODEC_SFX_IDX  = "Index"
+	CODEC_SFX_META = "Meta"
+
+	VERSION_START   = 0
+	VERSION_CURRENT = VERSION_START
+)
+
+const (
+	STRING         = 0x00
+	BYTE_ARR       = 0x01
+	NUMERIC_INT    = 0x02
+	NUMERIC_FLOAT  = 0x03
+	NUMERIC_LONG   = 0x04
+	NUMERIC_DOUBLE = 0x05
+
+	TYPE_BITS = 3 // unsigned bits required to store the largest type
+	TYPE_MASK = 0x07
+)
+
+var _ index.StoredFieldsWriter = &StoredFieldsWriter{}
+
+// StoredFieldsWriter
+// StoredFieldsWriter impl for StoredFieldsFormat.
+//
+// Documents are buffered until either chunkSize bytes or maxDocsPerChunk documents have been
+// added, and then compressed together as a chunk.
+//
+// Files:
+//   - .fdt: Header, <Chunk>^ChunkCount, Footer
+//     Chunk: DocBase, ChunkDocs, NumStoredFields, DocLengths, CompressedDocs
+//   - .fdx: Header, DocBases, StartPointers, Footer: the doc base and start pointer of every
+//     chunk, written with MonotonicBlockPackedWriter
+//   - .fdm: Header, ChunkSize, BlockSize, NumDocs, ChunkCount, MaxPointer, NumDirtyChunks,
+//     NumDirtyDocs, Footer
+//
+// lucene.experimental
+type StoredFieldsWriter struct {
+	segment         string
+	fieldsStream    store.IndexOutput
+	indexStream     store.IndexOutput
+	metaStream      store.IndexOutput
+	compressionMode CompressionMode
+	compressor      Compressor
+	chunkSize       int
+	maxDocsPerChunk int
+	blockSize       int
+
+	bufferedDocs    *store.BufferOutput
+	numStoredFields []int // number of stored fields
+	endOffsets      []int // end offsets in bufferedDocs
+
+	// doc ID of the beginning of the chunk
+	docBase         int
+	numBufferedDocs int
+
+	// chunk index: the first doc ID and the start pointer of every chunk
+	chunkDocBases      []uint64
+	chunkStartPointers []uint64
+
+	// number of chunks that were flushed because of Finish, rather than because they were full
+	numDirtyChunks int64
+	// cumulative number of docs in incomplete chunks
+	numDirtyDocs int64
+
+	numStoredFieldsInDoc int
+}
+
+// NewStoredFieldsWriter Sole constructor.
+func NewStoredFieldsWriter(ctx context.Context, directory store.Directory, si index.SegmentInfo,
+	segmentSuffix string, ioContext *store.IOContext, formatName string, compressionMode CompressionMode,
+	chunkSize, maxDocsPerChunk, blockSize int) (*StoredFieldsWriter, error) {
+
+	writer := &StoredFieldsWriter{
+		segment:         si.Name(),
+		compressionMode: compressionMode,
+		compressor:      compressionMode.NewCompressor(),
+		chunkSize:       chunkSize,
+		maxDocsPerChunk: maxDocsPerChunk,
+		blockSize:       blockSize,
+		bufferedDocs:    store.NewBufferDataOutput(),
+		numStoredFields: make([]int, 0, 16),
+		endOffsets:      make([]int, 0, 16),
+	}
+
+	if err := writer.openOutputs(ctx, directory, si, segmentSuffix, formatName); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (s *StoredFieldsWriter) openOutputs(ctx context.Context, directory store.Directory, si index.SegmentInfo,
+	segmentSuffix, formatName string) error {
+
+	var err error
+	fieldsFileName := store.SegmentFileName(s.segment, segmentSuffix, FIELDS_EXTENSION)
+	if s.fieldsStream, err = directory.CreateOutput(ctx, fieldsFileName); err != nil {
+		return err
+	}
+	if err := utils.WriteIndexHeader(ctx, s.fieldsStream, formatName+CODEC_SFX_DAT, VERSION_CURRENT, si.GetID(), segmentSuffix); err != nil {
+		return err
+	}
+
+	indexFileName := store.SegmentFileName(s.segment, segmentSuffix, FIELDS_INDEX_EXTENSION)
+	if s.indexStream, err = directory.CreateOutput(ctx, indexFileName); err != nil {
+		return err
+	}
+	if err := utils.WriteIndexHeader(ctx, s.indexStream, formatName+CODEC_SFX_IDX, VERSION_CURRENT, si.GetID(), segmentSuffix); err != nil {
+		return err
+	}
+
+	metaFileName := store.SegmentFileName(s.segment, segmentSuffix, FIELDS_META_EXTENSION)
+	if s.metaStream, err = directory.CreateOutput(ctx, metaFileName); err != nil {
+		return err
+	}
+	if err := utils.WriteIndexHeader(ctx, s.metaStream, formatName+CODEC_SFX_META, VERSION_CURRENT, si.GetID(), segmentSuffix); err != nil {
+		return err
+	}
+	if err := s.metaStream.WriteUvarint(ctx, uint64(s.chunkSize)); err != nil {
+		return err
+	}
+	return s.metaStream.WriteUvarint(ctx, uint64(s.blockSize))
+}
+
+func (s *StoredFieldsWriter) Close() error {
+	var errs []error
+	for _, out := range []store.IndexOutput{s.metaStream, s.indexStream, s.fieldsStream} {
+		if out != nil {
+			errs = append(errs, out.Close())
+		}
+	}
+	s.metaStream, s.indexStream, s.fieldsStream = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+func (s *StoredFieldsWriter) StartDocument(ctx context.Context) error {
+	return nil
+}
+
+func (s *StoredFieldsWriter) FinishDocument(ctx context.Context) error {
+	s.numStoredFields = append(s.numStoredFields, s.numStoredFieldsInDoc)
+	s.numStoredFieldsInDoc = 0
+	s.endOffsets = append(s.endOffsets, len(s.bufferedDocs.Bytes()))
+	s.numBufferedDocs++
+	if s.triggerFlush() {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+func (s *StoredFieldsWriter) triggerFlush() bool {
+	return len(s.bufferedDocs.Bytes()) >= s.chunkSize || // chunks of at least chunkSize bytes
+		s.numBufferedDocs >= s.maxDocsPerChunk
+}
+
+func (s *StoredFieldsWriter) writeHeader(ctx context.Context, docBase, numBufferedDocs int,
+	numStoredFields, lengths []int) error {
+
+	// save docBase and numBufferedDocs
+	if err := s.fieldsStream.WriteUvarint(ctx, uint64(docBase)); err != nil {
+		return err
+	}
+	if err := s.fieldsStream.WriteUvarint(ctx, uint64(numBufferedDocs)); err != nil {
+		return err
+	}
+
+	// save numStoredFields
+	if err := writeInts(ctx, numStoredFields, s.fieldsStream); err != nil {
+		return err
+	}
+
+	// save lengths
+	return writeInts(ctx, lengths, s.fieldsStream)
+}
+
+func (s *StoredFieldsWriter) flush(ctx context.Context) error {
+	s.chunkDocBases = append(s.chunkDocBases, uint64(s.docBase))
+	s.chunkStartPointers = append(s.chunkStartPointers, uint64(s.fieldsStream.GetFilePointer()))
+
+	// transform end offsets into lengths
+	lengths := s.endOffsets
+	for i := len(lengths) - 1; i > 0; i-- {
+		lengths[i] = lengths[i] - lengths[i-1]
+	}
+
+	if err := s.writeHeader(ctx, s.docBase, s.numBufferedDocs, s.numStoredFields, lengths); err != nil {
+		return err
+	}
+
+	// compress stored fields to fieldsStream
+	if err := s.compressor.Compress(ctx, s.bufferedDocs.Bytes(), s.fieldsStream); err != nil {
+		return err
+	}
+
+	// reset
+	s.docBase += s.numBufferedDocs
+	s.numBufferedDocs = 0
+	s.bufferedDocs.Reset()
+	s.numStoredFields = s.numStoredFields[:0]
+	s.endOffsets = s.endOffsets[:0]
+	return nil
+}
+
+func (s *StoredFieldsWriter) WriteField(ctx context.Context, info *document.FieldInfo, field document.IndexableField) error {
+	s.numStoredFieldsInDoc++
+
+	var bits int
+	switch field.Get().(type) {
+	case int32:
+		bits = NUMERIC_INT
+	case int64:
+		bits = NUMERIC_LONG
+	case float32:
+		bits = NUMERIC_FLOAT
+	case float64:
+		bits = NUMERIC_DOUBLE
+	case string:
+		bits = STRING
+	case []byte:
+		bits = BYTE_ARR
+	default:
+		return fmt.Errorf("cannot store value of type %T", field.Get())
+	}
+
+	infoAndBits := uint64(info.Number())<<TYPE_BITS | uint64(bits)
+	if err := s.bufferedDocs.WriteUvarint(ctx, infoAndBits); err != nil {
+		return err
+	}
+
+	switch v := field.Get().(type) {
+	case int32:
+		return s.bufferedDocs.WriteZInt32(ctx, v)
+	case int64:
+		return s.bufferedDocs.WriteZInt64(ctx, v)
+	case float32:
+		return s.bufferedDocs.WriteUint32(ctx, math.Float32bits(v))
+	case float64:
+		return s.bufferedDocs.WriteUint64(ctx, math.Float64bits(v))
+	case string:
+		return s.writeBytes(ctx, []byte(v))
+	case []byte:
+		return s.writeBytes(ctx, v)
+	}
+	return nil
+}
+
+func (s *StoredFieldsWriter) writeBytes(ctx context.Context, bytes []byte) error {
+	if err := s.bufferedDocs.WriteUvarint(ctx, uint64(len(bytes))); err != nil {
+		return err
+	}
+	_, err := s.bufferedDocs.Write(bytes)
+	return err
+}
+
+func (s *StoredFieldsWriter) Finish(ctx context.Context, fis index.FieldInfos, numDocs int) error {
+	if s.numBufferedDocs > 0 {
+		s.numDirtyChunks++ // incomplete: we had to force this flush
+		s.numDirtyDocs += int64(s.numBufferedDocs)
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.docBase != numDocs {
+		return fmt.Errorf("wrote %d docs, finish called with numDocs=%d", s.docBase, numDocs)
+	}
+
+	if err := s.writeIndex(ctx); err != nil {
+		return err
+	}
+
+	if err := s.metaStream.WriteUvarint(ctx, uint64(numDocs)); err != nil {
+		return err
+	}
+	if err := s.metaStream.WriteUvarint(ctx, uint64(len(s.chunkDocBases))); err != nil {
+		return err
+	}
+	if err := s.metaStream.WriteUvarint(ctx, uint64(s.fieldsStream.GetFilePointer())); err != nil {
+		return err
+	}
+	if err := s.metaStream.WriteUvarint(ctx, uint64(s.numDirtyChunks)); err != nil {
+		return err
+	}
+	if err := s.metaStream.WriteUvarint(ctx, uint64(s.numDirtyDocs)); err != nil {
+		return err
+	}
+
+	if err := utils.WriteFooter(s.metaStream); err != nil {
+		return err
+	}
+	if err := utils.WriteFooter(s.indexStream); err != nil {
+		return err
+	}
+	return utils.WriteFooter(s.fieldsStream)
+}
+
+// Merge
+// Merges the stored fields of the readers in mergeState. The chunks of a segment written with the
+// same compression mode and chunk size and without deletions are copied without decompressing
+// them, the documents of other segments are copied one by one as serialized bytes. Falls back to
+// coreIndex.MergeStoredFields when the index is sorted or a reader was written by another format
+// or with other field numbers.
+func (s *StoredFieldsWriter) Merge(ctx context.Context, mergeState *index.MergeState) (int, error) {
+	if mergeState.NeedsIndexSort {
+		return coreIndex.MergeStoredFields(ctx, s, mergeState)
+	}
+
+	readers := make([]*StoredFieldsReader, len(mergeState.StoredFieldsReaders))
+	for i, reader := range mergeState.StoredFieldsReaders {
+		matching, ok := reader.(*StoredFieldsReader)
+		if reader != nil && (!ok || matching.compressionMode != s.compressionMode || !fieldNumbersMatch(mergeState, i)) {
+			return coreIndex.MergeStoredFields(ctx, s, mergeState)
+		}
+		readers[i] = matching
+	}
+
+	docCount := 0
+	for i, reader := range readers {
+		if reader == nil {
+			continue
+		}
+		if err := reader.CheckIntegrity(); err != nil {
+			return 0, err
+		}
+
+		var err error
+		var numDocs int
+		if reader.chunkSize == s.chunkSize && mergeState.LiveDocs[i] == nil && !s.tooDirty(reader) {
+			numDocs, err = s.copyChunks(ctx, reader)
+		} else {
+			numDocs, err = s.copyDocuments(ctx, reader, mergeState.LiveDocs[i])
+		}
+		if err != nil {
+			return 0, err
+		}
+		docCount += numDocs
+	}
+
+	if err := s.Finish(ctx, mergeState.MergeFieldInfos, docCount); err != nil {
+		return 0, err
+	}
+	return docCount, nil
+}
+
+// fieldNumbersMatch Returns whether the fields of the reader have the same numbers in the merged
+// segment, the serialized documents of the reader refer to the fields by number.
+func fieldNumbersMatch(mergeState *index.MergeState, reader int) bool {
+	for _, fi := range mergeState.FieldInfos[reader].List() {
+		other := mergeState.MergeFieldInfos.FieldInfoByNumber(fi.Number())
+		if other == nil || other.Name() != fi.Name() {
+			return false
+		}
+	}
+	return true
+}
+
+// tooDirty Returns true if the chunks of the reader should be recompressed even though they could be
+// copied. The last chunk of a segment is usually incomplete, copying them over and over would
+// degrade the compression ratio. A segment is too dirty when its dirty docs make a full chunk and
+// more than 1% of its chunks are dirty.
+func (s *StoredFieldsWriter) tooDirty(reader *StoredFieldsReader) bool {
+	return reader.numDirtyDocs > int64(s.maxDocsPerChunk) &&
+		reader.numDirtyChunks*100 > int64(reader.numChunks)
+}
+
+// copyChunks Copies the compressed chunks of reader, only the doc bases in their headers are
+// rewritten.
+func (s *StoredFieldsWriter) copyChunks(ctx context.Context, reader *StoredFieldsReader) (int, error) {
+	// the chunks are copied whole, flush the pending documents first
+	if s.numBufferedDocs > 0 {
+		s.numDirtyChunks++
+		s.numDirtyDocs += int64(s.numBufferedDocs)
+		if err := s.flush(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	rawDocs := reader.fieldsStream.Clone().(store.IndexInput)
+	for chunk := 0; chunk < reader.numChunks; chunk++ {
+		startPointer, err := reader.chunkStartPointers.Get(chunk)
+		if err != nil {
+			return 0, err
+		}
+		endPointer := uint64(reader.maxPointer)
+		if chunk+1 < reader.numChunks {
+			if endPointer, err = reader.chunkStartPointers.Get(chunk + 1); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := rawDocs.Seek(int64(startPointer), io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		if _, err := rawDocs.ReadUvarint(ctx); err != nil {
+			return 0, err
+		}
+		chunkDocs, err := rawDocs.ReadUvarint(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		s.chunkDocBases = append(s.chunkDocBases, uint64(s.docBase))
+		s.chunkStartPointers = append(s.chunkStartPointers, uint64(s.fieldsStream.GetFilePointer()))
+		if err := s.fieldsStream.WriteUvarint(ctx, uint64(s.docBase)); err != nil {
+			return 0, err
+		}
+		if err := s.fieldsStream.WriteUvarint(ctx, chunkDocs); err != nil {
+			return 0, err
+		}
+		remaining := int64(endPointer) - rawDocs.GetFilePointer()
+		if err := s.fieldsStream.CopyBytes(ctx, rawDocs, int(remaining)); err != nil {
+			return 0, err
+		}
+		s.docBase += int(chunkDocs)
+	}
+
+	// the dirty chunks of the reader are copied as they are
+	s.numDirtyChunks += reader.numDirtyChunks
+	s.numDirtyDocs += reader.numDirtyDocs
+	return reader.numDocs, nil
+}
+
+// copyDocuments Copies the serialized live documents of reader, they are compressed again with the
+// next chunks.
+func (s *StoredFieldsWriter) copyDocuments(ctx context.Context, reader *StoredFieldsReader, liveDocs util.Bits) (int, error) {
+	numDocs := 0
+	for docID := 0; docID < reader.numDocs; docID++ {
+		if liveDocs != nil && !liveDocs.Test(uint(docID)) {
+			continue
+		}
+		doc, err := reader.document(ctx, docID)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.StartDocument(ctx); err != nil {
+			return 0, err
+		}
+		if err := s.bufferedDocs.CopyBytes(ctx, doc.in, doc.length); err != nil {
+			return 0, err
+		}
+		s.numStoredFieldsInDoc = doc.numStoredFields
+		if err := s.FinishDocument(ctx); err != nil {
+			return 0, err
+		}
+		numDocs++
+	}
+	return numDocs, nil
+}
+
+// Writes the doc bases and start pointers of the chunks.
+func (s *StoredFieldsWriter) writeIndex(ctx context.Context) error {
+	for _, values := range [][]uint64{s.chunkDocBases, s.chunkStartPointers} {
+		if len(values) == 0 {
+			continue
+		}
+
+		writer := packed.NewMonotonicBlockPackedWriter(s.indexStream, s.blockSize)
+		for _, v := range values {
+			if err := writer.Add(ctx, v); err != nil {
+				return err
+			}
+		}
+		if err := writer.Finish(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package lucene80_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// enough docs for an IndexedDISI block holding more than 4096 docs, which is stored as a bitset
+const numDocs = 10000
+
+// density Decides which docs have a value: every doc, most docs (a DENSE block of the IndexedDISI)
+// or few docs (a SPARSE block)
+type density struct {
+	name string
+	has  func(doc int) bool
+}
+
+var densities = []density{
+	{"all", func(doc int) bool { return true }},
+	{"dense", func(doc int) bool { return doc%10 != 3 }},
+	{"sparse", func(doc int) bool { return doc%7 == 0 }},
+}
+
+// numericValue Returns the value of doc, the fields use a gcd, a table of few values and large values
+func numericValue(field string, doc int) int64 {
+	switch {
+	case strings.HasPrefix(field, "all"):
+		return int64(doc)*3 - 7
+	case strings.HasPrefix(field, "dense"):
+		return int64(doc % 5)
+	default:
+		return int64(doc) * int64(doc) * 1_000_003
+	}
+}
+
+func binaryValue(doc int) []byte {
+	return []byte(strings.Repeat(fmt.Sprintf("b%d", doc), doc%5))
+}
+
+// sortedValue More distinct values than a block of the terms dict
+func sortedValue(doc int) []byte {
+	return []byte(fmt.Sprintf("s%03d", doc%150))
+}
+
+// sortedSetValues Returns one or two values, added twice to check that the set deduplicates them
+func sortedSetValues(doc int) [][]byte {
+	return [][]byte{
+		[]byte(fmt.Sprintf("t%02d", doc%40)),
+		[]byte(fmt.Sprintf("t%02d", doc%13)),
+		[]byte(fmt.Sprintf("t%02d", doc%40)),
+	}
+}
+
+// sortedNumericValues Returns the values of doc, unsorted and with duplicates, sometimes one value only
+func sortedNumericValues(doc int) []int64 {
+	if doc%4 == 0 {
+		return []int64{int64(doc)}
+	}
+	return []int64{int64(doc % 4), -int64(doc), int64(doc % 4)}
+}
+
+// newTestLeafReader Indexes numDocs docs with the Lucene80 doc values format of lucene87, with a field
+// of every doc values type for every density
+func newTestLeafReader(t *testing.T) index.LeafReader {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = dir.Close() })
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	writer, err := coreIndex.NewIndexWriter(ctx, dir, coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity))
+	assert.Nil(t, err)
+
+	for i := 0; i < numDocs; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewNumericDocValuesField("constant_numeric", 42))
+		for _, d := range densities {
+			if !d.has(i) {
+				continue
+			}
+			doc.Add(document.NewNumericDocValuesField(d.name+"_numeric", numericValue(d.name, i)))
+			doc.Add(document.NewBinaryDocValuesField(d.name+"_binary", binaryValue(i)))
+			doc.Add(document.NewSortedDocValuesField(d.name+"_sorted", sortedValue(i)))
+			for _, value := range sortedSetValues(i) {
+				doc.Add(document.NewSortedSetDocValuesField(d.name+"_sortedset", value))
+			}
+			for _, value := range sortedNumericValues(i) {
+				doc.Add(document.NewSortedNumericDocValuesField(d.name+"_sortednumeric", value))
+			}
+		}
+		_, err := writer.AddDocument(ctx, doc)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Commit(ctx))
+	assert.Nil(t, writer.Close())
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	assert.Len(t, leaves, 1)
+	return leaves[0].LeafReader()
+}
+
+// sortedTerms Returns the distinct values of the docs of d in order, the index of a value is its ord
+func sortedTerms(d density, values func(doc int) [][]byte) []string {
+	terms := make([]string, 0)
+	for doc := 0; doc < numDocs; doc++ {
+		if !d.has(doc) {
+			continue
+		}
+		for _, value := range values(doc) {
+			terms = append(terms, string(value))
+		}
+	}
+	slices.Sort(terms)
+	return slices.Compact(terms)
+}
+
+// assertDocs Iterates over the docs of it with NextDoc, calling check on every doc with a value
+func assertDocs(t *testing.T, d density, it types.DocIdSetIterator, check func(doc int)) {
+	ctx := context.Background()
+	expected := 0
+	for {
+		for expected < numDocs && !d.has(expected) {
+			expected++
+		}
+		doc, err := it.NextDoc(ctx)
+		if expected == numDocs {
+			assert.True(t, err == nil || errors.Is(err, io.EOF))
+			assert.Equal(t, types.NO_MORE_DOCS, doc)
+			return
+		}
+		assert.Nil(t, err)
+		if !assert.Equal(t, expected, doc, d.name) {
+			return
+		}
+		check(doc)
+		expected++
+	}
+}
+
+// assertAdvanceExact Jumps over the docs of it with AdvanceExact, calling check on every doc with a value
+func assertAdvanceExact(t *testing.T, d density, it types.DocValuesIterator, stride int, check func(doc int)) {
+	for doc := 1; doc < numDocs; doc += stride {
+		exists, err := it.AdvanceExact(doc)
+		assert.Nil(t, err)
+		assert.Equal(t, d.has(doc), exists, "%s doc %d", d.name, doc)
+		assert.Equal(t, doc, it.DocID())
+		if exists {
+			check(doc)
+		}
+	}
+}
+
+func TestDocValues_Numeric(t *testing.T) {
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		field := d.name + "_numeric"
+		check := func(values index.NumericDocValues) func(doc int) {
+			return func(doc int) {
+				value, err := values.LongValue()
+				assert.Nil(t, err)
+				assert.Equal(t, numericValue(d.name, doc), value, "%s doc %d", field, doc)
+			}
+		}
+
+		values, err := reader.GetNumericDocValues(field)
+		assert.Nil(t, err)
+		assertDocs(t, d, values, check(values))
+
+		for _, stride := range []int{1, 6, 4099} {
+			values, err := reader.GetNumericDocValues(field)
+			assert.Nil(t, err)
+			assertAdvanceExact(t, d, values, stride, check(values))
+		}
+	}
+
+	values, err := reader.GetNumericDocValues("constant_numeric")
+	assert.Nil(t, err)
+	assertDocs(t, densities[0], values, func(doc int) {
+		value, err := values.LongValue()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 42, value)
+	})
+}
+
+func TestDocValues_Advance(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		values, err := reader.GetNumericDocValues(d.name + "_numeric")
+		assert.Nil(t, err)
+
+		for _, target := range []int{0, 3, 4, 700, 4096, 4100, 9990} {
+			expected := target
+			for !d.has(expected) {
+				expected++
+			}
+			if target <= values.DocID() {
+				continue
+			}
+			doc, err := values.Advance(ctx, target)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, doc, "%s target %d", d.name, target)
+			value, err := values.LongValue()
+			assert.Nil(t, err)
+			assert.Equal(t, numericValue(d.name, expected), value)
+		}
+
+		doc, err := values.Advance(ctx, numDocs)
+		assert.True(t, err == nil || errors.Is(err, io.EOF))
+		assert.Equal(t, types.NO_MORE_DOCS, doc)
+	}
+}
+
+func TestDocValues_Binary(t *testing.T) {
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		field := d.name + "_binary"
+		check := func(values index.BinaryDocValues) func(doc int) {
+			return func(doc int) {
+				value, err := values.BinaryValue()
+				assert.Nil(t, err)
+				assert.Equal(t, string(binaryValue(doc)), string(value), "%s doc %d", field, doc)
+			}
+		}
+
+		values, err := reader.GetBinaryDocValues(field)
+		assert.Nil(t, err)
+		assertDocs(t, d, values, check(values))
+
+		values, err = reader.GetBinaryDocValues(field)
+		assert.Nil(t, err)
+		assertAdvanceExact(t, d, values, 17, check(values))
+	}
+}
+
+func TestDocValues_Sorted(t *testing.T) {
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		field := d.name + "_sorted"
+		terms := sortedTerms(d, func(doc int) [][]byte { return [][]byte{sortedValue(doc)} })
+
+		values, err := reader.GetSortedDocValues(field)
+		assert.Nil(t, err)
+		assert.Equal(t, len(terms), values.GetValueCount())
+		check := func(values index.SortedDocValues) func(doc int) {
+			return func(doc int) {
+				ord, err := values.OrdValue()
+				assert.Nil(t, err)
+				assert.Equal(t, string(sortedValue(doc)), terms[ord], "%s doc %d", field, doc)
+				value, err := values.BinaryValue()
+				assert.Nil(t, err)
+				assert.Equal(t, string(sortedValue(doc)), string(value), "%s doc %d", field, doc)
+			}
+		}
+		assertDocs(t, d, values, check(values))
+
+		values, err = reader.GetSortedDocValues(field)
+		assert.Nil(t, err)
+		assertAdvanceExact(t, d, values, 5, check(values))
+
+		// ords and terms, in and between the blocks of the terms dict
+		for _, ord := range []int{0, 1, 15, 16, 17, len(terms) / 2, len(terms) - 1} {
+			term, err := values.LookupOrd(ord)
+			assert.Nil(t, err)
+			assert.Equal(t, terms[ord], string(term), "%s ord %d", field, ord)
+
+			found, err := values.LookupTerm([]byte(terms[ord]))
+			assert.Nil(t, err)
+			assert.Equal(t, ord, found, "%s term %s", field, terms[ord])
+		}
+		found, err := values.LookupTerm([]byte("s000a"))
+		assert.Nil(t, err)
+		assert.Equal(t, -2, found)
+		found, err = values.LookupTerm([]byte("z"))
+		assert.Nil(t, err)
+		assert.Equal(t, -len(terms)-1, found)
+
+		termsEnum, err := values.TermsEnum()
+		assert.Nil(t, err)
+		for _, expected := range terms {
+			term, err := termsEnum.Next(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, expected, string(term))
+		}
+		term, err := termsEnum.Next(context.Background())
+		assert.True(t, term == nil || errors.Is(err, io.EOF))
+	}
+}
+
+func TestDocValues_SortedSet(t *testing.T) {
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		field := d.name + "_sortedset"
+		terms := sortedTerms(d, sortedSetValues)
+
+		values, err := reader.GetSortedSetDocValues(field)
+		assert.Nil(t, err)
+		assert.EqualValues(t, len(terms), values.GetValueCount())
+		check := func(values index.SortedSetDocValues) func(doc int) {
+			return func(doc int) {
+				expected := make([]string, 0)
+				for _, value := range sortedSetValues(doc) {
+					expected = append(expected, string(value))
+				}
+				slices.Sort(expected)
+				expected = slices.Compact(expected)
+
+				actual := make([]string, 0)
+				for {
+					ord, err := values.NextOrd()
+					assert.Nil(t, err)
+					if ord == coreIndex.NO_MORE_ORDS {
+						break
+					}
+					term, err := values.LookupOrd(ord)
+					assert.Nil(t, err)
+					assert.Equal(t, terms[ord], string(term))
+					actual = append(actual, string(term))
+				}
+				assert.Equal(t, expected, actual, "%s doc %d", field, doc)
+			}
+		}
+		assertDocs(t, d, values, check(values))
+
+		values, err = reader.GetSortedSetDocValues(field)
+		assert.Nil(t, err)
+		assertAdvanceExact(t, d, values, 9, check(values))
+	}
+}
+
+func TestDocValues_SortedNumeric(t *testing.T) {
+	reader := newTestLeafReader(t)
+
+	for _, d := range densities {
+		field := d.name + "_sortednumeric"
+		check := func(values index.SortedNumericDocValues) func(doc int) {
+			return func(doc int) {
+				expected := sortedNumericValues(doc)
+				slices.Sort(expected)
+
+				assert.Equal(t, len(expected), values.DocValueCount(), "%s doc %d", field, doc)
+				actual := make([]int64, values.DocValueCount())
+				for i := range actual {
+					value, err := values.NextValue()
+					assert.Nil(t, err)
+					actual[i] = value
+				}
+				assert.Equal(t, expected, actual, "%s doc %d", field, doc)
+			}
+		}
+
+		values, err := reader.GetSortedNumericDocValues(field)
+		assert.Nil(t, err)
+		assertDocs(t, d, values, check(values))
+
+		values, err = reader.GetSortedNumericDocValues(field)
+		assert.Nil(t, err)
+		assertAdvanceExact(t, d, values, 11, check(values))
+	}
+}
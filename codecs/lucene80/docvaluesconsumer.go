@@ -0,0 +1,701 @@
+package lucene80
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+var _ index.DocValuesConsumer = &DocValuesConsumer{}
+
+// DocValuesConsumer
+// writer for DocValuesFormat
+type DocValuesConsumer struct {
+	data   store.IndexOutput
+	meta   store.IndexOutput
+	maxDoc int
+}
+
+// NewDocValuesConsumer expert: Creates a new writer
+func NewDocValuesConsumer(ctx context.Context, state *index.SegmentWriteState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) (*DocValuesConsumer, error) {
+
+	maxDoc, err := state.SegmentInfo.MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	consumer := &DocValuesConsumer{maxDoc: maxDoc}
+	if err := consumer.openOutputs(ctx, state, dataCodec, dataExtension, metaCodec, metaExtension); err != nil {
+		_ = consumer.closeOutputs()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+func (d *DocValuesConsumer) openOutputs(ctx context.Context, state *index.SegmentWriteState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) error {
+
+	var err error
+	dataName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, dataExtension)
+	if d.data, err = state.Directory.CreateOutput(ctx, dataName); err != nil {
+		return err
+	}
+	if err := utils.WriteIndexHeader(ctx, d.data, dataCodec, VERSION_CURRENT,
+		state.SegmentInfo.GetID(), state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	metaName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, metaExtension)
+	if d.meta, err = state.Directory.CreateOutput(ctx, metaName); err != nil {
+		return err
+	}
+	return utils.WriteIndexHeader(ctx, d.meta, metaCodec, VERSION_CURRENT,
+		state.SegmentInfo.GetID(), state.SegmentSuffix)
+}
+
+func (d *DocValuesConsumer) Close() error {
+	ctx := context.Background()
+	if d.meta != nil {
+		// write EOF marker
+		if err := d.meta.WriteUint32(ctx, math.MaxUint32); err != nil {
+			_ = d.closeOutputs()
+			return err
+		}
+		// write checksum
+		if err := utils.WriteFooter(d.meta); err != nil {
+			_ = d.closeOutputs()
+			return err
+		}
+	}
+	if d.data != nil {
+		if err := utils.WriteFooter(d.data); err != nil {
+			_ = d.closeOutputs()
+			return err
+		}
+	}
+	return d.closeOutputs()
+}
+
+func (d *DocValuesConsumer) closeOutputs() error {
+	var errs []error
+	for _, out := range []store.IndexOutput{d.data, d.meta} {
+		if out != nil {
+			errs = append(errs, out.Close())
+		}
+	}
+	d.data, d.meta = nil, nil
+	return errors.Join(errs...)
+}
+
+func (d *DocValuesConsumer) writeFieldEntry(ctx context.Context, field *document.FieldInfo, dvType byte) error {
+	if err := d.meta.WriteUint32(ctx, uint32(field.Number())); err != nil {
+		return err
+	}
+	return d.meta.WriteByte(dvType)
+}
+
+func (d *DocValuesConsumer) AddNumericField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	if err := d.writeFieldEntry(ctx, field, NUMERIC); err != nil {
+		return err
+	}
+
+	_, _, err := d.writeValues(ctx, func() (sortedNumericValues, error) {
+		values, err := valuesProducer.GetNumeric(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		return &singletonNumericValues{NumericDocValues: values}, nil
+	})
+	return err
+}
+
+// writeValues writes the docs that have a value and the values of a field, it returns the
+// number of docs that have a value and the total number of values.
+func (d *DocValuesConsumer) writeValues(ctx context.Context, newValues func() (sortedNumericValues, error)) (int, int, error) {
+	values, err := newValues()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	numDocsWithValue, numValues := 0, 0
+	minValue, maxValue := int64(math.MaxInt64), int64(math.MinInt64)
+	gcd := int64(0)
+	uniqueValues := make(map[int64]struct{})
+
+	if err := forEachDoc(ctx, values, func(doc int) error {
+		for i, count := 0, values.DocValueCount(); i < count; i++ {
+			v, err := values.NextValue()
+			if err != nil {
+				return err
+			}
+
+			if gcd != 1 {
+				if v < math.MinInt64/2 || v > math.MaxInt64/2 {
+					// in that case v - minValue might overflow and make the GCD computation return
+					// wrong results. Since these extreme values are unlikely, we just discard
+					// GCD computation for them
+					gcd = 1
+				} else if numValues != 0 { // minValue needs to be set first
+					gcd = greatestCommonDivisor(gcd, v-minValue)
+				}
+			}
+
+			minValue = min(minValue, v)
+			maxValue = max(maxValue, v)
+
+			if uniqueValues != nil {
+				uniqueValues[v] = struct{}{}
+				if len(uniqueValues) > 256 {
+					uniqueValues = nil
+				}
+			}
+			numValues++
+		}
+		numDocsWithValue++
+		return nil
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	if err := d.writeDocsWithField(ctx, numDocsWithValue, func() (types.DocIdSetIterator, error) {
+		return newValues()
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	if err := d.meta.WriteUint64(ctx, uint64(numValues)); err != nil {
+		return 0, 0, err
+	}
+
+	numBitsPerValue := 0
+	var encode map[int64]int
+	if minValue >= maxValue {
+		if err := d.meta.WriteUint32(ctx, math.MaxUint32); err != nil { // table size
+			return 0, 0, err
+		}
+	} else {
+		if gcd == 0 {
+			gcd = 1
+		}
+		deltaBitsRequired := packed.UnsignedBitsRequired(uint64(maxValue-minValue) / uint64(gcd))
+
+		if uniqueValues != nil && len(uniqueValues) > 1 &&
+			packed.UnsignedBitsRequired(uint64(len(uniqueValues)-1)) < deltaBitsRequired {
+
+			numBitsPerValue = packed.DirectUnsignedBitsRequired(uint64(len(uniqueValues) - 1))
+			sortedUniqueValues := make([]int64, 0, len(uniqueValues))
+			for v := range uniqueValues {
+				sortedUniqueValues = append(sortedUniqueValues, v)
+			}
+			slices.Sort(sortedUniqueValues)
+
+			if err := d.meta.WriteUint32(ctx, uint32(len(sortedUniqueValues))); err != nil {
+				return 0, 0, err
+			}
+			encode = make(map[int64]int, len(sortedUniqueValues))
+			for i, v := range sortedUniqueValues {
+				if err := d.meta.WriteUint64(ctx, uint64(v)); err != nil {
+					return 0, 0, err
+				}
+				encode[v] = i
+			}
+			minValue = 0
+			gcd = 1
+		} else {
+			numBitsPerValue = packed.DirectUnsignedBitsRequired(uint64(maxValue-minValue) / uint64(gcd))
+			if gcd == 1 && minValue > 0 &&
+				packed.DirectUnsignedBitsRequired(uint64(maxValue)) == packed.DirectUnsignedBitsRequired(uint64(maxValue-minValue)) {
+				minValue = 0
+			}
+			if err := d.meta.WriteUint32(ctx, math.MaxUint32); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	if err := d.meta.WriteByte(byte(numBitsPerValue)); err != nil {
+		return 0, 0, err
+	}
+	if err := d.meta.WriteUint64(ctx, uint64(minValue)); err != nil {
+		return 0, 0, err
+	}
+	if err := d.meta.WriteUint64(ctx, uint64(gcd)); err != nil {
+		return 0, 0, err
+	}
+
+	startOffset := d.data.GetFilePointer()
+	if err := d.meta.WriteUint64(ctx, uint64(startOffset)); err != nil {
+		return 0, 0, err
+	}
+
+	if numBitsPerValue != 0 {
+		values, err := newValues()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		writer, err := packed.NewDirectWriter(d.data, numValues, numBitsPerValue)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if err := forEachDoc(ctx, values, func(doc int) error {
+			for i, count := 0, values.DocValueCount(); i < count; i++ {
+				v, err := values.NextValue()
+				if err != nil {
+					return err
+				}
+				if encode != nil {
+					err = writer.Add(uint64(encode[v]))
+				} else {
+					err = writer.Add(uint64(v-minValue) / uint64(gcd))
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return 0, 0, err
+		}
+
+		if err := writer.Finish(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-startOffset)); err != nil {
+		return 0, 0, err
+	}
+	return numDocsWithValue, numValues, nil
+}
+
+// writeDocsWithField records the docs that have a value. Nothing is written to the data file
+// when either none or all of the docs have a value.
+func (d *DocValuesConsumer) writeDocsWithField(ctx context.Context, numDocsWithValue int,
+	newDocs func() (types.DocIdSetIterator, error)) error {
+
+	var offset, length int64
+	switch numDocsWithValue {
+	case 0:
+		offset = -2
+	case d.maxDoc:
+		offset = -1
+	default:
+		offset = d.data.GetFilePointer()
+		docs, err := newDocs()
+		if err != nil {
+			return err
+		}
+		if err := WriteBitSet(ctx, docs, d.data); err != nil {
+			return err
+		}
+		length = d.data.GetFilePointer() - offset
+	}
+
+	if err := d.meta.WriteUint64(ctx, uint64(offset)); err != nil {
+		return err
+	}
+	return d.meta.WriteUint64(ctx, uint64(length))
+}
+
+func (d *DocValuesConsumer) AddBinaryField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	if err := d.writeFieldEntry(ctx, field, BINARY); err != nil {
+		return err
+	}
+
+	values, err := valuesProducer.GetBinary(ctx, field)
+	if err != nil {
+		return err
+	}
+
+	start := d.data.GetFilePointer()
+	if err := d.meta.WriteUint64(ctx, uint64(start)); err != nil {
+		return err
+	}
+
+	numDocsWithField := 0
+	minLength, maxLength := math.MaxInt32, 0
+	if err := forEachDoc(ctx, values, func(doc int) error {
+		v, err := values.BinaryValue()
+		if err != nil {
+			return err
+		}
+		if _, err := d.data.Write(v); err != nil {
+			return err
+		}
+		minLength = min(minLength, len(v))
+		maxLength = max(maxLength, len(v))
+		numDocsWithField++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-start)); err != nil {
+		return err
+	}
+
+	if err := d.writeDocsWithField(ctx, numDocsWithField, func() (types.DocIdSetIterator, error) {
+		return valuesProducer.GetBinary(ctx, field)
+	}); err != nil {
+		return err
+	}
+
+	if err := d.meta.WriteUint32(ctx, uint32(numDocsWithField)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint32(ctx, uint32(minLength)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint32(ctx, uint32(maxLength)); err != nil {
+		return err
+	}
+
+	if maxLength <= minLength {
+		return nil
+	}
+
+	values, err = valuesProducer.GetBinary(ctx, field)
+	if err != nil {
+		return err
+	}
+	return d.writeAddresses(ctx, numDocsWithField, values, func() (int, error) {
+		v, err := values.BinaryValue()
+		if err != nil {
+			return 0, err
+		}
+		return len(v), nil
+	})
+}
+
+// writeAddresses writes the start offset of every doc of the iterator, followed by the end
+// offset of the last doc, as monotonic numerics.
+func (d *DocValuesConsumer) writeAddresses(ctx context.Context, numDocsWithField int,
+	docs types.DocIdSetIterator, docLength func() (int, error)) error {
+
+	start := d.data.GetFilePointer()
+	if err := d.meta.WriteUint64(ctx, uint64(start)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUvarint(ctx, DIRECT_MONOTONIC_BLOCK_SHIFT); err != nil {
+		return err
+	}
+
+	writer, err := packed.NewDirectMonotonicWriter(d.meta, d.data, numDocsWithField+1, DIRECT_MONOTONIC_BLOCK_SHIFT)
+	if err != nil {
+		return err
+	}
+
+	addr := int64(0)
+	if err := writer.Add(ctx, addr); err != nil {
+		return err
+	}
+	if err := forEachDoc(ctx, docs, func(doc int) error {
+		length, err := docLength()
+		if err != nil {
+			return err
+		}
+		addr += int64(length)
+		return writer.Add(ctx, addr)
+	}); err != nil {
+		return err
+	}
+	if err := writer.Finish(ctx); err != nil {
+		return err
+	}
+	return d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-start))
+}
+
+func (d *DocValuesConsumer) AddSortedField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	if err := d.writeFieldEntry(ctx, field, SORTED); err != nil {
+		return err
+	}
+
+	if _, _, err := d.writeValues(ctx, func() (sortedNumericValues, error) {
+		values, err := valuesProducer.GetSorted(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		return &singletonOrdValues{SortedDocValues: values}, nil
+	}); err != nil {
+		return err
+	}
+
+	values, err := valuesProducer.GetSorted(ctx, field)
+	if err != nil {
+		return err
+	}
+	return d.addTermsDict(ctx, int64(values.GetValueCount()), func(ord int64) ([]byte, error) {
+		return values.LookupOrd(int(ord))
+	})
+}
+
+func (d *DocValuesConsumer) addTermsDict(ctx context.Context, size int64, lookupOrd func(ord int64) ([]byte, error)) error {
+	if err := d.meta.WriteUvarint(ctx, uint64(size)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint32(ctx, TERMS_DICT_BLOCK_SHIFT); err != nil {
+		return err
+	}
+
+	start := d.data.GetFilePointer()
+	blockAddresses := make([]int64, 0, (size+TERMS_DICT_BLOCK_MASK)>>TERMS_DICT_BLOCK_SHIFT)
+	maxLength := 0
+	var previous []byte
+
+	for ord := int64(0); ord < size; ord++ {
+		term, err := lookupOrd(ord)
+		if err != nil {
+			return err
+		}
+
+		if ord&TERMS_DICT_BLOCK_MASK == 0 {
+			blockAddresses = append(blockAddresses, d.data.GetFilePointer()-start)
+			if err := d.data.WriteUvarint(ctx, uint64(len(term))); err != nil {
+				return err
+			}
+			if _, err := d.data.Write(term); err != nil {
+				return err
+			}
+		} else {
+			prefixLength := commonPrefixLength(previous, term)
+			suffixLength := len(term) - prefixLength
+			// terms are unique and sorted, so the suffix can't be empty
+			token := byte(min(prefixLength, 15) | min(15, suffixLength-1)<<4)
+			if err := d.data.WriteByte(token); err != nil {
+				return err
+			}
+			if prefixLength >= 15 {
+				if err := d.data.WriteUvarint(ctx, uint64(prefixLength-15)); err != nil {
+					return err
+				}
+			}
+			if suffixLength >= 16 {
+				if err := d.data.WriteUvarint(ctx, uint64(suffixLength-16)); err != nil {
+					return err
+				}
+			}
+			if _, err := d.data.Write(term[prefixLength:]); err != nil {
+				return err
+			}
+		}
+
+		maxLength = max(maxLength, len(term))
+		previous = append(previous[:0], term...)
+	}
+
+	if err := d.meta.WriteUint32(ctx, uint32(maxLength)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint64(ctx, uint64(start)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-start)); err != nil {
+		return err
+	}
+
+	addressesStart := d.data.GetFilePointer()
+	if err := d.meta.WriteUint64(ctx, uint64(addressesStart)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUvarint(ctx, DIRECT_MONOTONIC_BLOCK_SHIFT); err != nil {
+		return err
+	}
+	writer, err := packed.NewDirectMonotonicWriter(d.meta, d.data, len(blockAddresses), DIRECT_MONOTONIC_BLOCK_SHIFT)
+	if err != nil {
+		return err
+	}
+	for _, addr := range blockAddresses {
+		if err := writer.Add(ctx, addr); err != nil {
+			return err
+		}
+	}
+	if err := writer.Finish(ctx); err != nil {
+		return err
+	}
+	return d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-addressesStart))
+}
+
+func (d *DocValuesConsumer) AddSortedNumericField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	if err := d.writeFieldEntry(ctx, field, SORTED_NUMERIC); err != nil {
+		return err
+	}
+
+	return d.writeMultiValues(ctx, func() (sortedNumericValues, error) {
+		return valuesProducer.GetSortedNumeric(ctx, field)
+	})
+}
+
+// writeMultiValues writes values like writeValues, followed by the addresses of the values of
+// every doc if some docs have more than one value.
+func (d *DocValuesConsumer) writeMultiValues(ctx context.Context, newValues func() (sortedNumericValues, error)) error {
+	numDocsWithField, numValues, err := d.writeValues(ctx, newValues)
+	if err != nil {
+		return err
+	}
+
+	if err := d.meta.WriteUint32(ctx, uint32(numDocsWithField)); err != nil {
+		return err
+	}
+	if numValues <= numDocsWithField {
+		return nil
+	}
+
+	values, err := newValues()
+	if err != nil {
+		return err
+	}
+	return d.writeAddresses(ctx, numDocsWithField, values, func() (int, error) {
+		return values.DocValueCount(), nil
+	})
+}
+
+func (d *DocValuesConsumer) AddSortedSetField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	if err := d.writeFieldEntry(ctx, field, SORTED_SET); err != nil {
+		return err
+	}
+
+	if err := d.writeMultiValues(ctx, func() (sortedNumericValues, error) {
+		values, err := valuesProducer.GetSortedSet(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		return &sortedSetOrdValues{SortedSetDocValues: values}, nil
+	}); err != nil {
+		return err
+	}
+
+	values, err := valuesProducer.GetSortedSet(ctx, field)
+	if err != nil {
+		return err
+	}
+	return d.addTermsDict(ctx, values.GetValueCount(), values.LookupOrd)
+}
+
+// sortedNumericValues is the view of doc values that writeValues encodes: every doc has one or
+// more numeric values.
+type sortedNumericValues interface {
+	types.DocIdSetIterator
+
+	DocValueCount() int
+	NextValue() (int64, error)
+}
+
+type singletonNumericValues struct {
+	index.NumericDocValues
+}
+
+func (s *singletonNumericValues) DocValueCount() int {
+	return 1
+}
+
+func (s *singletonNumericValues) NextValue() (int64, error) {
+	return s.LongValue()
+}
+
+// singletonOrdValues exposes the ordinals of sorted doc values
+type singletonOrdValues struct {
+	index.SortedDocValues
+}
+
+func (s *singletonOrdValues) DocValueCount() int {
+	return 1
+}
+
+func (s *singletonOrdValues) NextValue() (int64, error) {
+	ord, err := s.OrdValue()
+	return int64(ord), err
+}
+
+// sortedSetOrdValues exposes the ordinals of sorted set doc values
+type sortedSetOrdValues struct {
+	index.SortedSetDocValues
+
+	ords []int64
+	upto int
+}
+
+func (s *sortedSetOrdValues) NextDoc(ctx context.Context) (int, error) {
+	doc, err := s.SortedSetDocValues.NextDoc(ctx)
+	if err != nil || doc == types.NO_MORE_DOCS {
+		return doc, err
+	}
+
+	s.ords = s.ords[:0]
+	s.upto = 0
+	for {
+		ord, err := s.NextOrd()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, err
+		}
+		if ord == coreIndex.NO_MORE_ORDS {
+			break
+		}
+		s.ords = append(s.ords, ord)
+	}
+	return doc, nil
+}
+
+func (s *sortedSetOrdValues) DocValueCount() int {
+	return len(s.ords)
+}
+
+func (s *sortedSetOrdValues) NextValue() (int64, error) {
+	ord := s.ords[s.upto]
+	s.upto++
+	return ord, nil
+}
+
+// forEachDoc calls fn for every doc of the iterator
+func forEachDoc(ctx context.Context, it types.DocIdSetIterator, fn func(doc int) error) error {
+	for {
+		doc, err := it.NextDoc(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if doc == types.NO_MORE_DOCS {
+			return nil
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+func commonPrefixLength(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+func greatestCommonDivisor(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,123 @@
+package lucene80
+
+import (
+	"context"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+func init() {
+	coreIndex.RegisterDocValuesFormat(NewDocValuesFormat())
+}
+
+const (
+	DATA_CODEC     = "Lucene80DocValuesData"
+	DATA_EXTENSION = "dvd"
+	META_CODEC     = "Lucene80DocValuesMetadata"
+	META_EXTENSION = "dvm"
+
+	VERSION_START   = 0
+	VERSION_CURRENT = VERSION_START
+
+	// indicates docvalues type
+	NUMERIC        = 0
+	BINARY         = 1
+	SORTED         = 2
+	SORTED_SET     = 3
+	SORTED_NUMERIC = 4
+
+	DIRECT_MONOTONIC_BLOCK_SHIFT = 16
+
+	TERMS_DICT_BLOCK_SHIFT = 4
+	TERMS_DICT_BLOCK_SIZE  = 1 << TERMS_DICT_BLOCK_SHIFT
+	TERMS_DICT_BLOCK_MASK  = TERMS_DICT_BLOCK_SIZE - 1
+)
+
+var _ index.DocValuesFormat = &DocValuesFormat{}
+
+// DocValuesFormat
+// Lucene 8.0 DocValues format.
+//
+// Documents that have a value for the field are encoded in a way that it is always possible to
+// know the ordinal of the current document in the set of documents that have a value. For
+// instance, say the set of documents that have a value for the field is {1, 5, 6, 11}. When the
+// iterator is on 6, it knows that this is the 3rd item of the set. This way, values can be
+// stored densely and accessed based on their index at search time. If all documents in a segment
+// have a value for the field, the index is the same as the doc ID, so this case is encoded
+// implicitly and is very fast at query time. On the other hand if some documents are missing a
+// value for the field then the set of documents that have a value is encoded into blocks. All
+// doc IDs that share the same upper 16 bits are encoded into the same block with the following
+// strategies:
+//   - SPARSE: This strategy is used when a block contains at most 4095 documents. The lower 16
+//     bits of doc IDs are stored as shorts while the upper 16 bits are given by the block ID.
+//   - DENSE: This strategy is used when a block contains between 4096 and 65535 documents. The
+//     lower bits of doc IDs are stored in a bit set.
+//   - ALL: This strategy is used when a block contains exactly 65536 documents, meaning that the
+//     block is full. In that case doc IDs do not need to be stored explicitly.
+//
+// Then the five per-document value types (Numeric,Binary,Sorted,SortedSet,SortedNumeric) are
+// encoded using the following strategies:
+//
+// NUMERIC:
+//   - Delta-compressed: per-document integers written as deltas from the minimum value,
+//     compressed with bitpacking. For more information, see packed.DirectWriter.
+//   - Table-compressed: when the number of unique values is very small (< 256), and when there
+//     are unused "gaps" in the range of values used (such as SmallFloat), a lookup table is
+//     written instead. Each per-document entry is instead the ordinal to this table, and those
+//     ordinals are compressed with bitpacking (packed.DirectWriter).
+//   - GCD-compressed: when all numbers share a common divisor, such as dates, the greatest
+//     common denominator (GCD) is computed, and quotients are stored using Delta-compressed
+//     Numerics.
+//   - Const-compressed: when there is only one possible value, no per-document data is needed
+//     and this value is encoded alone.
+//
+// BINARY:
+//   - Fixed-width Binary: one large concatenated byte[] is written, along with the fixed length.
+//     Each document's value can be addressed directly with multiplication (docID * length).
+//   - Variable-width Binary: one large concatenated byte[] is written, along with end addresses
+//     for each document. The addresses are written as Monotonic-compressed numerics.
+//
+// SORTED:
+// Sorted values are encoded as a terms dictionary plus per-document ordinals. The ordinals are
+// written with the same strategies as numerics. The terms dictionary is prefix-compressed: it
+// is split into blocks of 16 terms, the first term of each block is written in full and other
+// terms only record the suffix they don't share with the previous term. The start addresses of
+// the blocks are written as Monotonic-compressed numerics, so that looking up an ordinal only
+// needs to decode one block, and looking up a term is a binary search over the first terms of
+// the blocks.
+//
+// SORTED_SET:
+// SortedSet values are encoded like SORTED values, except that every document may have several
+// ordinals. Ordinals are written in order of document, and the start offset of every document
+// in the list of ordinals is written as Monotonic-compressed numerics.
+//
+// SORTED_NUMERIC:
+// SortedNumeric values are encoded like NUMERIC values, except that every document may have
+// several values. The start offset of every document in the list of values is written as
+// Monotonic-compressed numerics.
+//
+// Files:
+//   - .dvd: DocValues data
+//   - .dvm: DocValues metadata
+//
+// lucene.experimental
+type DocValuesFormat struct {
+}
+
+// NewDocValuesFormat Sole Constructor
+func NewDocValuesFormat() *DocValuesFormat {
+	return &DocValuesFormat{}
+}
+
+func (d *DocValuesFormat) GetName() string {
+	return "Lucene80"
+}
+
+func (d *DocValuesFormat) FieldsConsumer(ctx context.Context, state *index.SegmentWriteState) (index.DocValuesConsumer, error) {
+	return NewDocValuesConsumer(ctx, state, DATA_CODEC, DATA_EXTENSION, META_CODEC, META_EXTENSION)
+}
+
+func (d *DocValuesFormat) FieldsProducer(ctx context.Context, state *index.SegmentReadState) (index.DocValuesProducer, error) {
+	return NewDocValuesProducer(ctx, state, DATA_CODEC, DATA_EXTENSION, META_CODEC, META_EXTENSION)
+}
@@ -0,0 +1,1029 @@
+package lucene80
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+var _ index.DocValuesProducer = &DocValuesProducer{}
+
+// DocValuesProducer
+// reader for DocValuesFormat
+type DocValuesProducer struct {
+	numerics       map[string]*numericEntry
+	binaries       map[string]*binaryEntry
+	sorted         map[string]*sortedEntry
+	sortedSets     map[string]*sortedSetEntry
+	sortedNumerics map[string]*sortedNumericEntry
+	data           store.IndexInput
+	maxDoc         int
+}
+
+// NewDocValuesProducer expert: instantiates a new reader
+func NewDocValuesProducer(ctx context.Context, state *index.SegmentReadState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) (*DocValuesProducer, error) {
+
+	maxDoc, err := state.SegmentInfo.MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	producer := &DocValuesProducer{
+		numerics:       make(map[string]*numericEntry),
+		binaries:       make(map[string]*binaryEntry),
+		sorted:         make(map[string]*sortedEntry),
+		sortedSets:     make(map[string]*sortedSetEntry),
+		sortedNumerics: make(map[string]*sortedNumericEntry),
+		maxDoc:         maxDoc,
+	}
+
+	if err := producer.open(ctx, state, dataCodec, dataExtension, metaCodec, metaExtension); err != nil {
+		_ = producer.Close()
+		return nil, err
+	}
+	return producer, nil
+}
+
+func (d *DocValuesProducer) open(ctx context.Context, state *index.SegmentReadState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) error {
+
+	segmentID := state.SegmentInfo.GetID()
+
+	// read in the entries from the metadata file.
+	metaName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, metaExtension)
+	metaIn, err := store.OpenChecksumInput(ctx, state.Directory, metaName)
+	if err != nil {
+		return err
+	}
+	defer metaIn.Close()
+
+	version, err := utils.CheckIndexHeader(ctx, metaIn, metaCodec, VERSION_START, VERSION_CURRENT, segmentID, state.SegmentSuffix)
+	if err != nil {
+		return err
+	}
+	if err := d.readFields(ctx, metaIn, state.FieldInfos); err != nil {
+		return err
+	}
+	if _, err := utils.CheckCodecFooter(ctx, metaIn); err != nil {
+		return err
+	}
+
+	dataName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, dataExtension)
+	if d.data, err = state.Directory.OpenInput(ctx, dataName); err != nil {
+		return err
+	}
+	version2, err := utils.CheckIndexHeader(ctx, d.data, dataCodec, VERSION_START, VERSION_CURRENT, segmentID, state.SegmentSuffix)
+	if err != nil {
+		return err
+	}
+	if version != version2 {
+		return fmt.Errorf("format versions mismatch: meta=%d, data=%d", version, version2)
+	}
+
+	// NOTE: data file is too costly to verify checksum against all the bytes on open,
+	// but for now we at least verify proper structure of the checksum footer: which looks
+	// for FOOTER_MAGIC + algorithmID. This is cheap and can detect some forms of corruption
+	// such as file truncation.
+	_, err = utils.RetrieveChecksum(ctx, d.data)
+	return err
+}
+
+func (d *DocValuesProducer) readFields(ctx context.Context, meta store.IndexInput, infos index.FieldInfos) error {
+	for {
+		fieldNumber, err := meta.ReadUint32(ctx)
+		if err != nil {
+			return err
+		}
+		if fieldNumber == math.MaxUint32 {
+			return nil
+		}
+
+		info := infos.FieldInfoByNumber(int(fieldNumber))
+		if info == nil {
+			return fmt.Errorf("invalid field number: %d", fieldNumber)
+		}
+
+		dvType, err := meta.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch dvType {
+		case NUMERIC:
+			entry, err := readNumeric(ctx, meta)
+			if err != nil {
+				return err
+			}
+			d.numerics[info.Name()] = entry
+		case BINARY:
+			entry, err := readBinary(ctx, meta)
+			if err != nil {
+				return err
+			}
+			d.binaries[info.Name()] = entry
+		case SORTED:
+			entry, err := readSorted(ctx, meta)
+			if err != nil {
+				return err
+			}
+			d.sorted[info.Name()] = entry
+		case SORTED_SET:
+			entry, err := readSortedSet(ctx, meta)
+			if err != nil {
+				return err
+			}
+			d.sortedSets[info.Name()] = entry
+		case SORTED_NUMERIC:
+			entry, err := readSortedNumeric(ctx, meta)
+			if err != nil {
+				return err
+			}
+			d.sortedNumerics[info.Name()] = entry
+		default:
+			return fmt.Errorf("invalid doc values type: %d", dvType)
+		}
+	}
+}
+
+type numericEntry struct {
+	docsWithFieldOffset int64
+	docsWithFieldLength int64
+	numValues           int64
+	table               []int64
+	bitsPerValue        int
+	minValue            int64
+	gcd                 int64
+	valuesOffset        int64
+	valuesLength        int64
+}
+
+func readNumeric(ctx context.Context, meta store.DataInput) (*numericEntry, error) {
+	entry := &numericEntry{}
+	if err := readDocsWithField(ctx, meta, &entry.docsWithFieldOffset, &entry.docsWithFieldLength); err != nil {
+		return nil, err
+	}
+
+	numValues, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.numValues = int64(numValues)
+
+	tableSize, err := meta.ReadUint32(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tableSize != math.MaxUint32 {
+		if tableSize > 256 {
+			return nil, fmt.Errorf("invalid table size: %d", tableSize)
+		}
+		entry.table = make([]int64, tableSize)
+		for i := range entry.table {
+			v, err := meta.ReadUint64(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entry.table[i] = int64(v)
+		}
+	}
+
+	bitsPerValue, err := meta.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	entry.bitsPerValue = int(bitsPerValue)
+
+	values := make([]int64, 4)
+	for i := range values {
+		v, err := meta.ReadUint64(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = int64(v)
+	}
+	entry.minValue = values[0]
+	entry.gcd = values[1]
+	entry.valuesOffset = values[2]
+	entry.valuesLength = values[3]
+	return entry, nil
+}
+
+func readDocsWithField(ctx context.Context, meta store.DataInput, offset, length *int64) error {
+	v, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return err
+	}
+	*offset = int64(v)
+
+	v, err = meta.ReadUint64(ctx)
+	if err != nil {
+		return err
+	}
+	*length = int64(v)
+	return nil
+}
+
+// addressesEntry locates monotonic addresses, see DocValuesConsumer.writeAddresses
+type addressesEntry struct {
+	offset int64
+	length int64
+	meta   *packed.DirectMonotonicMeta
+}
+
+func readAddresses(ctx context.Context, meta store.DataInput, numValues int) (*addressesEntry, error) {
+	offset, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blockShift, err := meta.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	monotonicMeta, err := packed.LoadDirectMonotonicMeta(ctx, meta, numValues, int(blockShift))
+	if err != nil {
+		return nil, err
+	}
+	length, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &addressesEntry{
+		offset: int64(offset),
+		length: int64(length),
+		meta:   monotonicMeta,
+	}, nil
+}
+
+type binaryEntry struct {
+	dataOffset          int64
+	dataLength          int64
+	docsWithFieldOffset int64
+	docsWithFieldLength int64
+	numDocsWithField    int
+	minLength           int
+	maxLength           int
+	addresses           *addressesEntry
+}
+
+func readBinary(ctx context.Context, meta store.DataInput) (*binaryEntry, error) {
+	entry := &binaryEntry{}
+
+	dataOffset, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dataLength, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.dataOffset, entry.dataLength = int64(dataOffset), int64(dataLength)
+
+	if err := readDocsWithField(ctx, meta, &entry.docsWithFieldOffset, &entry.docsWithFieldLength); err != nil {
+		return nil, err
+	}
+
+	values := make([]int, 3)
+	for i := range values {
+		v, err := meta.ReadUint32(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = int(v)
+	}
+	entry.numDocsWithField = values[0]
+	entry.minLength = values[1]
+	entry.maxLength = values[2]
+
+	if entry.minLength < entry.maxLength {
+		if entry.addresses, err = readAddresses(ctx, meta, entry.numDocsWithField+1); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+type termsDictEntry struct {
+	termsDictSize   int64
+	blockShift      int
+	maxTermLength   int
+	termsDataOffset int64
+	termsDataLength int64
+	termsAddresses  *addressesEntry
+}
+
+func readTermDict(ctx context.Context, meta store.DataInput) (*termsDictEntry, error) {
+	entry := &termsDictEntry{}
+
+	size, err := meta.ReadUvarint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.termsDictSize = int64(size)
+
+	blockShift, err := meta.ReadUint32(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.blockShift = int(blockShift)
+
+	maxTermLength, err := meta.ReadUint32(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.maxTermLength = int(maxTermLength)
+
+	termsDataOffset, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	termsDataLength, err := meta.ReadUint64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.termsDataOffset, entry.termsDataLength = int64(termsDataOffset), int64(termsDataLength)
+
+	mask := int64(1)<<entry.blockShift - 1
+	numBlocks := int((entry.termsDictSize + mask) >> entry.blockShift)
+	if entry.termsAddresses, err = readAddresses(ctx, meta, numBlocks); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+type sortedEntry struct {
+	ords      *numericEntry
+	termsDict *termsDictEntry
+}
+
+func readSorted(ctx context.Context, meta store.DataInput) (*sortedEntry, error) {
+	ords, err := readNumeric(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	termsDict, err := readTermDict(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &sortedEntry{ords: ords, termsDict: termsDict}, nil
+}
+
+type sortedNumericEntry struct {
+	values           *numericEntry
+	numDocsWithField int
+	addresses        *addressesEntry
+}
+
+func readSortedNumeric(ctx context.Context, meta store.DataInput) (*sortedNumericEntry, error) {
+	values, err := readNumeric(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	numDocsWithField, err := meta.ReadUint32(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &sortedNumericEntry{
+		values:           values,
+		numDocsWithField: int(numDocsWithField),
+	}
+	if entry.values.numValues > int64(entry.numDocsWithField) {
+		if entry.addresses, err = readAddresses(ctx, meta, entry.numDocsWithField+1); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+type sortedSetEntry struct {
+	ords      *sortedNumericEntry
+	termsDict *termsDictEntry
+}
+
+func readSortedSet(ctx context.Context, meta store.DataInput) (*sortedSetEntry, error) {
+	ords, err := readSortedNumeric(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	termsDict, err := readTermDict(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &sortedSetEntry{ords: ords, termsDict: termsDict}, nil
+}
+
+func (d *DocValuesProducer) Close() error {
+	if d.data == nil {
+		return nil
+	}
+	return d.data.Close()
+}
+
+func (d *DocValuesProducer) GetNumeric(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
+	entry, ok := d.numerics[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a numeric doc values field", field.Name())
+	}
+
+	docs, err := d.getDocsWithField(entry.docsWithFieldOffset, entry.docsWithFieldLength, entry.numValues)
+	if err != nil {
+		return nil, err
+	}
+	values, err := d.getNumericValues(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &numericDocValues{docValuesBase: docValuesBase{docs: docs}, values: values}, nil
+}
+
+// getDocsWithField returns an iterator over the docs that have a value, see
+// DocValuesConsumer.writeDocsWithField
+func (d *DocValuesProducer) getDocsWithField(offset, length, cost int64) (docsWithFieldIterator, error) {
+	switch offset {
+	case -2:
+		// empty
+		return &denseDocs{maxDoc: 0, doc: -1}, nil
+	case -1:
+		// dense
+		return &denseDocs{maxDoc: d.maxDoc, doc: -1}, nil
+	default:
+		// sparse
+		return NewIndexedDISI(d.data, offset, length, cost)
+	}
+}
+
+// getNumericValues returns the function that decodes the value at the given index
+func (d *DocValuesProducer) getNumericValues(entry *numericEntry) (func(index int64) (int64, error), error) {
+	if entry.bitsPerValue == 0 {
+		return func(index int64) (int64, error) {
+			return entry.minValue, nil
+		}, nil
+	}
+
+	slice, err := d.data.RandomAccessSlice(entry.valuesOffset, entry.valuesLength)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := packed.NewDirectReader().GetInstance(slice, entry.bitsPerValue, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.table != nil {
+		return func(index int64) (int64, error) {
+			v, err := reader.Get(index)
+			if err != nil {
+				return 0, err
+			}
+			return entry.table[v], nil
+		}, nil
+	}
+
+	minValue, gcd := entry.minValue, entry.gcd
+	return func(index int64) (int64, error) {
+		v, err := reader.Get(index)
+		if err != nil {
+			return 0, err
+		}
+		return minValue + gcd*int64(v), nil
+	}, nil
+}
+
+func (d *DocValuesProducer) getAddresses(entry *addressesEntry) (*packed.DirectMonotonicReader, error) {
+	slice, err := d.data.RandomAccessSlice(entry.offset, entry.length)
+	if err != nil {
+		return nil, err
+	}
+	return packed.NewDirectMonotonicReader(entry.meta, slice)
+}
+
+func (d *DocValuesProducer) GetBinary(ctx context.Context, field *document.FieldInfo) (index.BinaryDocValues, error) {
+	entry, ok := d.binaries[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a binary doc values field", field.Name())
+	}
+
+	docs, err := d.getDocsWithField(entry.docsWithFieldOffset, entry.docsWithFieldLength, int64(entry.numDocsWithField))
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.data.RandomAccessSlice(entry.dataOffset, entry.dataLength)
+	if err != nil {
+		return nil, err
+	}
+
+	values := &binaryDocValues{
+		docValuesBase: docValuesBase{docs: docs},
+		data:          data,
+		length:        entry.maxLength,
+	}
+	if entry.addresses != nil {
+		if values.addresses, err = d.getAddresses(entry.addresses); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (d *DocValuesProducer) GetSorted(ctx context.Context, field *document.FieldInfo) (index.SortedDocValues, error) {
+	entry, ok := d.sorted[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a sorted doc values field", field.Name())
+	}
+
+	docs, err := d.getDocsWithField(entry.ords.docsWithFieldOffset, entry.ords.docsWithFieldLength, entry.ords.numValues)
+	if err != nil {
+		return nil, err
+	}
+	ords, err := d.getNumericValues(entry.ords)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := d.newTermsDict(entry.termsDict)
+	if err != nil {
+		return nil, err
+	}
+
+	values := &sortedDocValues{
+		docValuesBase: &docValuesBase{docs: docs},
+		ords:          ords,
+		terms:         terms,
+	}
+	values.BaseSortedDocValues = coreIndex.NewBaseSortedDocValues(&coreIndex.SortedDocValuesDefaultConfig{
+		OrdValue:      values.OrdValue,
+		LookupOrd:     values.LookupOrd,
+		GetValueCount: values.GetValueCount,
+	})
+	return values, nil
+}
+
+func (d *DocValuesProducer) GetSortedNumeric(ctx context.Context, field *document.FieldInfo) (index.SortedNumericDocValues, error) {
+	entry, ok := d.sortedNumerics[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a sorted numeric doc values field", field.Name())
+	}
+	return d.getSortedNumeric(entry)
+}
+
+func (d *DocValuesProducer) getSortedNumeric(entry *sortedNumericEntry) (*sortedNumericDocValues, error) {
+	docs, err := d.getDocsWithField(entry.values.docsWithFieldOffset, entry.values.docsWithFieldLength,
+		int64(entry.numDocsWithField))
+	if err != nil {
+		return nil, err
+	}
+	values, err := d.getNumericValues(entry.values)
+	if err != nil {
+		return nil, err
+	}
+
+	sortedNumeric := &sortedNumericDocValues{
+		docValuesBase: docValuesBase{docs: docs},
+		values:        values,
+		loadedDoc:     -1,
+	}
+	if entry.addresses != nil {
+		if sortedNumeric.addresses, err = d.getAddresses(entry.addresses); err != nil {
+			return nil, err
+		}
+	}
+	return sortedNumeric, nil
+}
+
+func (d *DocValuesProducer) GetSortedSet(ctx context.Context, field *document.FieldInfo) (index.SortedSetDocValues, error) {
+	entry, ok := d.sortedSets[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a sorted set doc values field", field.Name())
+	}
+
+	ords, err := d.getSortedNumeric(entry.ords)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := d.newTermsDict(entry.termsDict)
+	if err != nil {
+		return nil, err
+	}
+	return &sortedSetDocValues{sortedNumericDocValues: ords, terms: terms}, nil
+}
+
+func (d *DocValuesProducer) CheckIntegrity() error {
+	_, err := utils.ChecksumEntireFile(context.Background(), d.data.Clone().(store.IndexInput))
+	return err
+}
+
+func (d *DocValuesProducer) GetMergeInstance() index.DocValuesProducer {
+	return d
+}
+
+// docsWithFieldIterator iterates over the docs that have a value and tells the index of the
+// current doc among them, which is also the index of its value.
+type docsWithFieldIterator interface {
+	types.DocIdSetIterator
+
+	AdvanceExact(ctx context.Context, target int) (bool, error)
+	Index() int
+}
+
+var _ docsWithFieldIterator = &denseDocs{}
+
+// denseDocs is used when all docs have a value
+type denseDocs struct {
+	maxDoc int
+	doc    int
+}
+
+func (d *denseDocs) DocID() int {
+	return d.doc
+}
+
+func (d *denseDocs) NextDoc(ctx context.Context) (int, error) {
+	return d.Advance(ctx, d.doc+1)
+}
+
+func (d *denseDocs) Advance(ctx context.Context, target int) (int, error) {
+	if target >= d.maxDoc {
+		d.doc = types.NO_MORE_DOCS
+		return d.doc, io.EOF
+	}
+	d.doc = target
+	return d.doc, nil
+}
+
+func (d *denseDocs) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, d, target)
+}
+
+func (d *denseDocs) Cost() int64 {
+	return int64(d.maxDoc)
+}
+
+func (d *denseDocs) AdvanceExact(ctx context.Context, target int) (bool, error) {
+	d.doc = target
+	return target < d.maxDoc, nil
+}
+
+func (d *denseDocs) Index() int {
+	return d.doc
+}
+
+// docValuesBase implements types.DocValuesIterator on top of the docs that have a value
+type docValuesBase struct {
+	docs docsWithFieldIterator
+}
+
+func (b *docValuesBase) DocID() int {
+	return b.docs.DocID()
+}
+
+func (b *docValuesBase) NextDoc(ctx context.Context) (int, error) {
+	return b.docs.NextDoc(ctx)
+}
+
+func (b *docValuesBase) Advance(ctx context.Context, target int) (int, error) {
+	return b.docs.Advance(ctx, target)
+}
+
+func (b *docValuesBase) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return b.docs.SlowAdvance(ctx, target)
+}
+
+func (b *docValuesBase) Cost() int64 {
+	return b.docs.Cost()
+}
+
+func (b *docValuesBase) AdvanceExact(target int) (bool, error) {
+	return b.docs.AdvanceExact(context.Background(), target)
+}
+
+var _ index.NumericDocValues = &numericDocValues{}
+
+type numericDocValues struct {
+	docValuesBase
+
+	values func(index int64) (int64, error)
+}
+
+func (n *numericDocValues) LongValue() (int64, error) {
+	return n.values(int64(n.docs.Index()))
+}
+
+var _ index.BinaryDocValues = &binaryDocValues{}
+
+type binaryDocValues struct {
+	docValuesBase
+
+	data store.RandomAccessInput
+	// length of all values when addresses is nil
+	length    int
+	addresses *packed.DirectMonotonicReader
+}
+
+func (b *binaryDocValues) BinaryValue() ([]byte, error) {
+	idx := int64(b.docs.Index())
+
+	start, length := idx*int64(b.length), int64(b.length)
+	if b.addresses != nil {
+		var err error
+		if start, err = b.addresses.Get(idx); err != nil {
+			return nil, err
+		}
+		end, err := b.addresses.Get(idx + 1)
+		if err != nil {
+			return nil, err
+		}
+		length = end - start
+	}
+
+	value := make([]byte, length)
+	if length == 0 {
+		return value, nil
+	}
+	if _, err := b.data.ReadAt(value, start); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+var _ index.SortedDocValues = &sortedDocValues{}
+
+type sortedDocValues struct {
+	*docValuesBase
+	*coreIndex.BaseSortedDocValues
+
+	ords  func(index int64) (int64, error)
+	terms *termsDict
+}
+
+func (s *sortedDocValues) OrdValue() (int, error) {
+	ord, err := s.ords(int64(s.docs.Index()))
+	return int(ord), err
+}
+
+func (s *sortedDocValues) LookupOrd(ord int) ([]byte, error) {
+	return s.terms.lookupOrd(int64(ord))
+}
+
+func (s *sortedDocValues) GetValueCount() int {
+	return int(s.terms.entry.termsDictSize)
+}
+
+func (s *sortedDocValues) LookupTerm(key []byte) (int, error) {
+	ord, err := s.terms.lookupTerm(key)
+	return int(ord), err
+}
+
+func (s *sortedDocValues) TermsEnum() (index.TermsEnum, error) {
+	return coreIndex.NewSortedDocValuesTermsEnum(s), nil
+}
+
+var _ index.SortedNumericDocValues = &sortedNumericDocValues{}
+
+type sortedNumericDocValues struct {
+	docValuesBase
+
+	values func(index int64) (int64, error)
+	// addresses of the values of every doc, nil when every doc has a single value
+	addresses *packed.DirectMonotonicReader
+
+	// doc whose start and end are loaded
+	loadedDoc  int
+	start, end int64
+	upto       int64
+}
+
+func (s *sortedNumericDocValues) load() error {
+	doc := s.docs.DocID()
+	if doc == s.loadedDoc {
+		return nil
+	}
+
+	idx := int64(s.docs.Index())
+	if s.addresses == nil {
+		s.start, s.end = idx, idx+1
+	} else {
+		var err error
+		if s.start, err = s.addresses.Get(idx); err != nil {
+			return err
+		}
+		if s.end, err = s.addresses.Get(idx + 1); err != nil {
+			return err
+		}
+	}
+	s.upto = s.start
+	s.loadedDoc = doc
+	return nil
+}
+
+func (s *sortedNumericDocValues) NextValue() (int64, error) {
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	if s.upto >= s.end {
+		return 0, errors.New("no more values for the current doc")
+	}
+	v, err := s.values(s.upto)
+	s.upto++
+	return v, err
+}
+
+func (s *sortedNumericDocValues) DocValueCount() int {
+	if err := s.load(); err != nil {
+		return 0
+	}
+	return int(s.end - s.start)
+}
+
+var _ index.SortedSetDocValues = &sortedSetDocValues{}
+
+type sortedSetDocValues struct {
+	*sortedNumericDocValues
+
+	terms *termsDict
+}
+
+func (s *sortedSetDocValues) NextOrd() (int64, error) {
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	if s.upto >= s.end {
+		return coreIndex.NO_MORE_ORDS, nil
+	}
+	return s.NextValue()
+}
+
+func (s *sortedSetDocValues) LookupOrd(ord int64) ([]byte, error) {
+	return s.terms.lookupOrd(ord)
+}
+
+func (s *sortedSetDocValues) GetValueCount() int64 {
+	return s.terms.entry.termsDictSize
+}
+
+// termsDict reads the prefix-compressed terms written by DocValuesConsumer.addTermsDict
+type termsDict struct {
+	entry     *termsDictEntry
+	blockMask int64
+	addresses *packed.DirectMonotonicReader
+	bytes     store.IndexInput
+
+	ord  int64
+	term []byte
+}
+
+func (d *DocValuesProducer) newTermsDict(entry *termsDictEntry) (*termsDict, error) {
+	addresses, err := d.getAddresses(entry.termsAddresses)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := d.data.Slice("terms", entry.termsDataOffset, entry.termsDataLength)
+	if err != nil {
+		return nil, err
+	}
+	return &termsDict{
+		entry:     entry,
+		blockMask: int64(1)<<entry.blockShift - 1,
+		addresses: addresses,
+		bytes:     terms,
+		ord:       -1,
+		term:      make([]byte, 0, entry.maxTermLength),
+	}, nil
+}
+
+func (t *termsDict) lookupOrd(ord int64) ([]byte, error) {
+	if ord < 0 || ord >= t.entry.termsDictSize {
+		return nil, fmt.Errorf("ord must be 0 .. %d; got %d", t.entry.termsDictSize-1, ord)
+	}
+	if err := t.seekOrd(ord); err != nil {
+		return nil, err
+	}
+	return slices.Clone(t.term), nil
+}
+
+func (t *termsDict) seekOrd(ord int64) error {
+	blockIndex := ord >> t.entry.blockShift
+	if t.ord < 0 || ord < t.ord || blockIndex != t.ord>>t.entry.blockShift {
+		if err := t.seekBlock(blockIndex); err != nil {
+			return err
+		}
+	}
+	for t.ord < ord {
+		if err := t.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seekBlock positions the dict right before the first term of the block
+func (t *termsDict) seekBlock(blockIndex int64) error {
+	addr, err := t.addresses.Get(blockIndex)
+	if err != nil {
+		return err
+	}
+	if _, err := t.bytes.Seek(addr, io.SeekStart); err != nil {
+		return err
+	}
+	t.ord = blockIndex<<t.entry.blockShift - 1
+	return nil
+}
+
+func (t *termsDict) next() error {
+	ctx := context.Background()
+	t.ord++
+
+	if t.ord&t.blockMask == 0 {
+		length, err := t.bytes.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		t.term = slices.Grow(t.term[:0], int(length))[:length]
+		_, err = io.ReadFull(t.bytes, t.term)
+		return err
+	}
+
+	token, err := t.bytes.ReadByte()
+	if err != nil {
+		return err
+	}
+	prefixLength := int(token & 0x0F)
+	suffixLength := 1 + int(token>>4)
+	if prefixLength == 15 {
+		v, err := t.bytes.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		prefixLength += int(v)
+	}
+	if suffixLength == 16 {
+		v, err := t.bytes.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		suffixLength += int(v)
+	}
+
+	t.term = slices.Grow(t.term[:prefixLength], suffixLength)[:prefixLength+suffixLength]
+	_, err = io.ReadFull(t.bytes, t.term[prefixLength:])
+	return err
+}
+
+// lookupTerm returns the ordinal of key if it exists, -insertionPoint-1 otherwise
+func (t *termsDict) lookupTerm(key []byte) (int64, error) {
+	numBlocks := (t.entry.termsDictSize + t.blockMask) >> t.entry.blockShift
+
+	// find the last block whose first term is less than or equal to key
+	lo, hi := int64(0), numBlocks-1
+	for lo <= hi {
+		mid := (lo + hi) >> 1
+		if err := t.seekBlock(mid); err != nil {
+			return 0, err
+		}
+		if err := t.next(); err != nil {
+			return 0, err
+		}
+
+		cmp := bytes.Compare(t.term, key)
+		if cmp < 0 {
+			lo = mid + 1
+		} else if cmp > 0 {
+			hi = mid - 1
+		} else {
+			return t.ord, nil
+		}
+	}
+	if hi < 0 {
+		return -1, nil
+	}
+
+	if err := t.seekBlock(hi); err != nil {
+		return 0, err
+	}
+	blockEnd := min(t.entry.termsDictSize, (hi+1)<<t.entry.blockShift)
+	for t.ord+1 < blockEnd {
+		if err := t.next(); err != nil {
+			return 0, err
+		}
+		cmp := bytes.Compare(t.term, key)
+		if cmp == 0 {
+			return t.ord, nil
+		}
+		if cmp > 0 {
+			return -t.ord - 1, nil
+		}
+	}
+	return -blockEnd - 1, nil
+}
@@ -0,0 +1,412 @@
+package lucene80
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/bits"
+
+	"github.com/bits-and-blooms/bitset"
+
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+)
+
+const (
+	// maxArrayLength the maximum number of docs of a block that are stored as a sorted array
+	// of shorts, denser blocks are stored as a bit set
+	maxArrayLength = (1 << 12) - 1
+
+	// blockSize number of docs of a block
+	blockSize = 1 << 16
+
+	// denseBlockLongs number of words of a DENSE block
+	denseBlockLongs = blockSize / 64
+)
+
+type disiMethod int
+
+const (
+	disiSparse = disiMethod(iota)
+	disiDense
+	disiAll
+)
+
+var _ types.DocIdSetIterator = &IndexedDISI{}
+
+// IndexedDISI
+// Disk-based implementation of a DocIdSetIterator which can return the index of the current
+// document, i.e. the ordinal of the current document among the list of documents that this
+// iterator can return. This is useful to implement sparse doc values by only having to encode
+// values for documents that actually have a value.
+//
+// Implementation-wise, this DocIdSetIterator is inspired of roaring bitmaps and encodes ranges
+// of 65536 documents independently and picks between 3 encodings depending on the density of
+// the range:
+//   - ALL if the range contains 65536 documents exactly,
+//   - DENSE if the range contains 4096 documents or more; in that case documents are stored in
+//     a bit set,
+//   - SPARSE otherwise, and the lower 16 bits of the doc IDs are stored in a short.
+//
+// Only ranges that contain at least one value are encoded.
+// This implementation uses 6 bytes per document in the worst-case, which happens in the case
+// that all ranges contain exactly one document.
+//
+// lucene.internal
+type IndexedDISI struct {
+	slice store.IndexInput
+	cost  int64
+
+	block          int
+	blockEnd       int64
+	nextBlockIndex int
+	method         disiMethod
+
+	doc   int
+	index int
+
+	// SPARSE variables
+	exists bool
+
+	// DENSE variables
+	word      uint64
+	wordIndex int
+	// number of one bits encountered so far, including those of `word`
+	numberOfOnes int
+
+	// ALL variables
+	gap int
+}
+
+// NewIndexedDISI
+// Creates an iterator over the docs written by WriteBitSet, located at [offset, offset+length)
+// in the given input.
+func NewIndexedDISI(in store.IndexInput, offset, length, cost int64) (*IndexedDISI, error) {
+	slice, err := in.Slice("docs", offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndexedDISI{
+		slice:          slice,
+		cost:           cost,
+		block:          -1,
+		nextBlockIndex: -1,
+		doc:            -1,
+		index:          -1,
+		wordIndex:      -1,
+	}, nil
+}
+
+// WriteBitSet
+// Writes the docs of the iterator in a format that can be read by IndexedDISI.
+func WriteBitSet(ctx context.Context, it types.DocIdSetIterator, out store.DataOutput) error {
+	buffer := bitset.New(blockSize)
+	cardinality := 0
+	prevBlock := -1
+
+	for {
+		doc, err := it.NextDoc(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		if doc == types.NO_MORE_DOCS {
+			break
+		}
+
+		block := doc >> 16
+		if prevBlock != -1 && block != prevBlock {
+			if err := flushBlock(ctx, prevBlock, buffer, cardinality, out); err != nil {
+				return err
+			}
+			buffer.ClearAll()
+			cardinality = 0
+		}
+		buffer.Set(uint(doc & 0xFFFF))
+		cardinality++
+		prevBlock = block
+	}
+
+	if cardinality > 0 {
+		if err := flushBlock(ctx, prevBlock, buffer, cardinality, out); err != nil {
+			return err
+		}
+		buffer.ClearAll()
+	}
+
+	// NO_MORE_DOCS is stored explicitly
+	buffer.Set(uint(types.NO_MORE_DOCS & 0xFFFF))
+	return flushBlock(ctx, types.NO_MORE_DOCS>>16, buffer, 1, out)
+}
+
+func flushBlock(ctx context.Context, block int, buffer *bitset.BitSet, cardinality int, out store.DataOutput) error {
+	if err := out.WriteUint16(ctx, uint16(block)); err != nil {
+		return err
+	}
+	if err := out.WriteUint16(ctx, uint16(cardinality-1)); err != nil {
+		return err
+	}
+
+	if cardinality > maxArrayLength {
+		if cardinality == blockSize {
+			// all docs are set
+			return nil
+		}
+		words := buffer.Bytes()
+		for i := 0; i < denseBlockLongs; i++ {
+			if err := out.WriteUint64(ctx, words[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for doc, ok := buffer.NextSet(0); ok; doc, ok = buffer.NextSet(doc + 1) {
+		if err := out.WriteUint16(ctx, uint16(doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *IndexedDISI) DocID() int {
+	return d.doc
+}
+
+func (d *IndexedDISI) NextDoc(ctx context.Context) (int, error) {
+	return d.Advance(ctx, d.doc+1)
+}
+
+func (d *IndexedDISI) Advance(ctx context.Context, target int) (int, error) {
+	targetBlock := target & 0xFFFF0000
+	if d.block < targetBlock {
+		if err := d.advanceBlock(ctx, targetBlock); err != nil {
+			return 0, err
+		}
+	}
+
+	if d.block == targetBlock {
+		found, err := d.advanceWithinBlock(ctx, target)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			return d.doc, nil
+		}
+		if err := d.readBlockHeader(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := d.advanceWithinBlock(ctx, d.block); err != nil {
+		return 0, err
+	}
+	if d.doc == types.NO_MORE_DOCS {
+		return d.doc, io.EOF
+	}
+	return d.doc, nil
+}
+
+func (d *IndexedDISI) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, d, target)
+}
+
+func (d *IndexedDISI) Cost() int64 {
+	return d.cost
+}
+
+// AdvanceExact
+// Advance the iterator to exactly target and return whether target has a value.
+func (d *IndexedDISI) AdvanceExact(ctx context.Context, target int) (bool, error) {
+	targetBlock := target & 0xFFFF0000
+	if d.block < targetBlock {
+		if err := d.advanceBlock(ctx, targetBlock); err != nil {
+			return false, err
+		}
+	}
+
+	found := false
+	if d.block == targetBlock {
+		var err error
+		if found, err = d.advanceExactWithinBlock(ctx, target); err != nil {
+			return false, err
+		}
+	}
+	d.doc = target
+	return found, nil
+}
+
+// Index
+// Returns the index of the current document among the documents of this iterator.
+func (d *IndexedDISI) Index() int {
+	return d.index
+}
+
+func (d *IndexedDISI) advanceBlock(ctx context.Context, targetBlock int) error {
+	for {
+		if _, err := d.slice.Seek(d.blockEnd, io.SeekStart); err != nil {
+			return err
+		}
+		if err := d.readBlockHeader(ctx); err != nil {
+			return err
+		}
+		if d.block >= targetBlock {
+			return nil
+		}
+	}
+}
+
+func (d *IndexedDISI) readBlockHeader(ctx context.Context) error {
+	block, err := d.slice.ReadUint16(ctx)
+	if err != nil {
+		return err
+	}
+	d.block = int(block) << 16
+
+	cardinality, err := d.slice.ReadUint16(ctx)
+	if err != nil {
+		return err
+	}
+	numValues := int(cardinality) + 1
+
+	d.index = d.nextBlockIndex
+	d.nextBlockIndex = d.index + numValues
+
+	switch {
+	case numValues <= maxArrayLength:
+		d.method = disiSparse
+		d.blockEnd = d.slice.GetFilePointer() + int64(numValues)<<1
+	case numValues == blockSize:
+		d.method = disiAll
+		d.blockEnd = d.slice.GetFilePointer()
+		d.gap = d.block - d.index - 1
+	default:
+		d.method = disiDense
+		d.blockEnd = d.slice.GetFilePointer() + denseBlockLongs*8
+		d.wordIndex = -1
+		d.numberOfOnes = d.index + 1
+	}
+	return nil
+}
+
+// Advance to the first doc from the block that is equal to or greater than target.
+// Return true if there is such a doc and false otherwise.
+func (d *IndexedDISI) advanceWithinBlock(ctx context.Context, target int) (bool, error) {
+	switch d.method {
+	case disiSparse:
+		targetInBlock := target & 0xFFFF
+		// TODO: binary search
+		for d.index < d.nextBlockIndex {
+			doc, err := d.slice.ReadUint16(ctx)
+			if err != nil {
+				return false, err
+			}
+			d.index++
+			if int(doc) >= targetInBlock {
+				d.doc = d.block | int(doc)
+				d.exists = true
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case disiDense:
+		targetInBlock := target & 0xFFFF
+		targetWordIndex := targetInBlock >> 6
+		if err := d.readWords(ctx, targetWordIndex); err != nil {
+			return false, err
+		}
+
+		leftBits := d.word >> (target & 63)
+		if leftBits != 0 {
+			d.doc = target + bits.TrailingZeros64(leftBits)
+			d.index = d.numberOfOnes - bits.OnesCount64(leftBits)
+			return true, nil
+		}
+
+		for d.wordIndex++; d.wordIndex < denseBlockLongs; d.wordIndex++ {
+			word, err := d.slice.ReadUint64(ctx)
+			if err != nil {
+				return false, err
+			}
+			d.word = word
+			if word != 0 {
+				d.index = d.numberOfOnes
+				d.numberOfOnes += bits.OnesCount64(word)
+				d.doc = d.block | (d.wordIndex << 6) | bits.TrailingZeros64(word)
+				return true, nil
+			}
+		}
+		return false, nil
+
+	default:
+		d.doc = target
+		d.index = target - d.gap
+		return true, nil
+	}
+}
+
+// Advance the iterator exactly to the position corresponding to the given target and return
+// whether this document exists.
+func (d *IndexedDISI) advanceExactWithinBlock(ctx context.Context, target int) (bool, error) {
+	switch d.method {
+	case disiSparse:
+		targetInBlock := target & 0xFFFF
+		// TODO: binary search
+		if target == d.doc {
+			return d.exists, nil
+		}
+		for d.index < d.nextBlockIndex {
+			doc, err := d.slice.ReadUint16(ctx)
+			if err != nil {
+				return false, err
+			}
+			d.index++
+			if int(doc) >= targetInBlock {
+				if int(doc) != targetInBlock {
+					d.index--
+					if _, err := d.slice.Seek(d.slice.GetFilePointer()-2, io.SeekStart); err != nil {
+						return false, err
+					}
+					break
+				}
+				d.exists = true
+				return true, nil
+			}
+		}
+		d.exists = false
+		return false, nil
+
+	case disiDense:
+		targetInBlock := target & 0xFFFF
+		targetWordIndex := targetInBlock >> 6
+		if err := d.readWords(ctx, targetWordIndex); err != nil {
+			return false, err
+		}
+
+		leftBits := d.word >> (target & 63)
+		d.index = d.numberOfOnes - bits.OnesCount64(leftBits)
+		return leftBits&1 != 0, nil
+
+	default:
+		d.index = target - d.gap
+		return true, nil
+	}
+}
+
+// reads the words of a DENSE block up to targetWordIndex included
+func (d *IndexedDISI) readWords(ctx context.Context, targetWordIndex int) error {
+	for i := d.wordIndex + 1; i <= targetWordIndex; i++ {
+		word, err := d.slice.ReadUint64(ctx)
+		if err != nil {
+			return err
+		}
+		d.word = word
+		d.numberOfOnes += bits.OnesCount64(word)
+	}
+	d.wordIndex = targetWordIndex
+	return nil
+}
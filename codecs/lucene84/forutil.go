@@ -0,0 +1,174 @@
+package lucene84
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+const (
+	// Special number of bits per value used whenever all values to encode are equal.
+	allValuesEqual = 0
+
+	// maxEncodedBits upper limit of the number of bits a value of a block can use.
+	maxEncodedBits = 32
+)
+
+// ForUtil Encode all values in normal area with fixed bit width, which is determined by the max
+// value in this block.
+type ForUtil struct {
+	encodedSizes []int
+	encoders     []packed.Encoder
+	decoders     []packed.Decoder
+	iterations   []int
+}
+
+// maxDataSize Upper limit of the number of values that might be decoded in a single call to
+// readBlock. Although values after BLOCK_SIZE are garbage, it is necessary to allocate value
+// buffers whose size is >= maxDataSize to avoid out of range errors.
+var maxDataSize = computeMaxDataSize()
+
+func computeMaxDataSize() int {
+	size := 0
+	for bpv := 1; bpv <= maxEncodedBits; bpv++ {
+		decoder, err := packed.GetDecoder(packed.FormatPacked, packed.VERSION_CURRENT, bpv)
+		if err != nil {
+			panic(err)
+		}
+		iterations := computeIterations(decoder)
+		size = max(size, iterations*decoder.ByteValueCount())
+	}
+	return size
+}
+
+// Compute the number of iterations required to decode BLOCK_SIZE values with the provided Decoder.
+func computeIterations(decoder packed.Decoder) int {
+	return (BLOCK_SIZE + decoder.ByteValueCount() - 1) / decoder.ByteValueCount()
+}
+
+func NewForUtil() (*ForUtil, error) {
+	f := &ForUtil{
+		encodedSizes: make([]int, maxEncodedBits+1),
+		encoders:     make([]packed.Encoder, maxEncodedBits+1),
+		decoders:     make([]packed.Decoder, maxEncodedBits+1),
+		iterations:   make([]int, maxEncodedBits+1),
+	}
+
+	for bpv := 1; bpv <= maxEncodedBits; bpv++ {
+		encoder, err := packed.GetEncoder(packed.FormatPacked, packed.VERSION_CURRENT, bpv)
+		if err != nil {
+			return nil, err
+		}
+		decoder, err := packed.GetDecoder(packed.FormatPacked, packed.VERSION_CURRENT, bpv)
+		if err != nil {
+			return nil, err
+		}
+		f.encoders[bpv] = encoder
+		f.decoders[bpv] = decoder
+		f.iterations[bpv] = computeIterations(decoder)
+		f.encodedSizes[bpv] = packed.FormatPacked.ByteCount(packed.VERSION_CURRENT, BLOCK_SIZE, bpv)
+	}
+	return f, nil
+}
+
+// WriteBlock Write a block of data (For format).
+// data: the data to write
+// encoded: a buffer to use to encode data
+// out: the destination output
+func (f *ForUtil) WriteBlock(ctx context.Context, data []uint64, encoded []byte, out store.DataOutput) error {
+	if isAllEqual(data) {
+		if err := out.WriteByte(allValuesEqual); err != nil {
+			return err
+		}
+		return out.WriteUvarint(ctx, data[0])
+	}
+
+	numBits := bitsRequired(data)
+	if numBits > maxEncodedBits {
+		return fmt.Errorf("value of %d bits can't be encoded in a block", numBits)
+	}
+	encodedSize := f.encodedSizes[numBits]
+
+	if err := out.WriteByte(byte(numBits)); err != nil {
+		return err
+	}
+	f.encoders[numBits].EncodeBytes(data, encoded, f.iterations[numBits])
+	_, err := out.Write(encoded[:encodedSize])
+	return err
+}
+
+// ReadBlock Read the next block of data (For format).
+// in: the input to use to read data
+// encoded: a buffer that can be used to store encoded data
+// decoded: where to write decoded data
+func (f *ForUtil) ReadBlock(ctx context.Context, in store.DataInput, encoded []byte, decoded []uint64) error {
+	numBits, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	if numBits > maxEncodedBits {
+		return fmt.Errorf("corrupted block, numBits=%d", numBits)
+	}
+
+	if numBits == allValuesEqual {
+		value, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < BLOCK_SIZE; i++ {
+			decoded[i] = value
+		}
+		return nil
+	}
+
+	encodedSize := f.encodedSizes[numBits]
+	if _, err := io.ReadFull(in, encoded[:encodedSize]); err != nil {
+		return err
+	}
+	f.decoders[numBits].DecodeBytes(encoded, decoded, f.iterations[numBits])
+	return nil
+}
+
+// SkipBlock Skip the next block of data.
+func (f *ForUtil) SkipBlock(ctx context.Context, in store.IndexInput) error {
+	numBits, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	if numBits == allValuesEqual {
+		_, err := in.ReadUvarint(ctx)
+		return err
+	}
+	if numBits > maxEncodedBits {
+		return fmt.Errorf("corrupted block, numBits=%d", numBits)
+	}
+	_, err = in.Seek(in.GetFilePointer()+int64(f.encodedSizes[numBits]), io.SeekStart)
+	return err
+}
+
+func isAllEqual(data []uint64) bool {
+	v := data[0]
+	for i := 1; i < BLOCK_SIZE; i++ {
+		if data[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Compute the number of bits required to serialize any of the longs in data.
+func bitsRequired(data []uint64) int {
+	or := uint64(0)
+	for i := 0; i < BLOCK_SIZE; i++ {
+		or |= data[i]
+	}
+	return packed.UnsignedBitsRequired(or)
+}
+
+// maxEncodedSize returns the size of the largest encoded block.
+func maxEncodedSize() int {
+	return packed.FormatPacked.ByteCount(packed.VERSION_CURRENT, maxDataSize, maxEncodedBits)
+}
@@ -0,0 +1,413 @@
+package lucene84_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/analysis"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/attribute"
+	"github.com/stretchr/testify/assert"
+)
+
+// more than 8 blocks of 128 docs, so that the skip lists have two levels
+const numDocs = 1500
+
+// payloadFilter Sets the payload <term>/<position> on the tokens at odd positions, the other
+// tokens have no payload
+type payloadFilter struct {
+	analysis.TokenStream
+
+	termAtt    attribute.CharTermAttr
+	payloadAtt attribute.PayloadAttr
+	position   int
+}
+
+func (p *payloadFilter) IncrementToken() (bool, error) {
+	ok, err := p.TokenStream.IncrementToken()
+	if err != nil || !ok {
+		return ok, err
+	}
+	if p.position%2 == 1 {
+		err = p.payloadAtt.SetPayload(payloadOf(p.termAtt.GetString(), p.position))
+	} else {
+		err = p.payloadAtt.SetPayload(nil)
+	}
+	p.position++
+	return true, err
+}
+
+func payloadOf(term string, position int) []byte {
+	if position%2 == 0 {
+		return nil
+	}
+	return []byte(fmt.Sprintf("%s/%d", term, position))
+}
+
+// payloadField A text field whose tokens carry the payloads of payloadFilter
+type payloadField struct {
+	*document.Field[string]
+}
+
+func (p *payloadField) TokenStream(analyzer analysis.Analyzer, _ analysis.TokenStream) (analysis.TokenStream, error) {
+	stream, err := analyzer.GetTokenStreamFromText(p.Name(), p.Get().(string))
+	if err != nil {
+		return nil, err
+	}
+	source := stream.AttributeSource()
+	return &payloadFilter{TokenStream: stream, termAtt: source.CharTerm(), payloadAtt: source.Payload()}, nil
+}
+
+// docTokens Returns the tokens of the body of doc, common occurs 1 to 3 times in every doc, even in
+// every other doc, rare in every 100th doc and u<doc> once
+func docTokens(doc int) []string {
+	tokens := make([]string, 0)
+	for i := 0; i <= doc%3; i++ {
+		tokens = append(tokens, "common")
+	}
+	tokens = append(tokens, fmt.Sprintf("u%04d", doc))
+	if doc%2 == 0 {
+		tokens = append(tokens, "even")
+	}
+	if doc%100 == 0 {
+		tokens = append(tokens, "rare", "common")
+	}
+	return tokens
+}
+
+type posting struct {
+	doc       int
+	positions []int
+	starts    []int
+	ends      []int
+	payloads  [][]byte
+}
+
+// expectedPostings Returns the postings of every term of the body field
+func expectedPostings() map[string][]*posting {
+	postings := make(map[string][]*posting)
+	for doc := 0; doc < numDocs; doc++ {
+		offset := 0
+		for position, token := range docTokens(doc) {
+			list := postings[token]
+			if len(list) == 0 || list[len(list)-1].doc != doc {
+				list = append(list, &posting{doc: doc})
+				postings[token] = list
+			}
+			p := list[len(list)-1]
+			p.positions = append(p.positions, position)
+			p.starts = append(p.starts, offset)
+			p.ends = append(p.ends, offset+len(token))
+			p.payloads = append(p.payloads, payloadOf(token, position))
+			offset += len(token) + 1
+		}
+	}
+	return postings
+}
+
+// newTestLeafReader Indexes numDocs docs with the Lucene84 postings format of lucene87, the body field
+// indexes positions, offsets and payloads, the group field docs only
+func newTestLeafReader(t *testing.T) index.LeafReader {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = dir.Close() })
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	writer, err := coreIndex.NewIndexWriter(ctx, dir, coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity))
+	assert.Nil(t, err)
+
+	fieldType := document.NewFieldType()
+	assert.Nil(t, fieldType.SetIndexOptions(document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS))
+	assert.Nil(t, fieldType.SetTokenized(true))
+	fieldType.Freeze()
+
+	for i := 0; i < numDocs; i++ {
+		doc := document.NewDocument()
+		doc.Add(&payloadField{document.NewField("body", strings.Join(docTokens(i), " "), fieldType)})
+		doc.Add(document.NewStringField("group", fmt.Sprintf("g%d", i%2), false))
+		_, err := writer.AddDocument(ctx, doc)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Commit(ctx))
+	assert.Nil(t, writer.Close())
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	assert.Len(t, leaves, 1)
+	return leaves[0].LeafReader()
+}
+
+func seekExact(t *testing.T, reader index.LeafReader, field, term string) index.TermsEnum {
+	terms, err := reader.Terms(field)
+	assert.Nil(t, err)
+	termsEnum, err := terms.Iterator()
+	assert.Nil(t, err)
+	found, err := termsEnum.SeekExact(context.Background(), []byte(term))
+	assert.Nil(t, err)
+	assert.True(t, found, term)
+	return termsEnum
+}
+
+// assertPositions Checks the positions, offsets and payloads of the current doc of postingsEnum
+func assertPositions(t *testing.T, expected *posting, postingsEnum index.PostingsEnum, flags int) {
+	freq, err := postingsEnum.Freq()
+	assert.Nil(t, err)
+	assert.Equal(t, len(expected.positions), freq, "doc %d", expected.doc)
+	if flags&coreIndex.POSTINGS_ENUM_POSITIONS != coreIndex.POSTINGS_ENUM_POSITIONS {
+		return
+	}
+
+	for i := 0; i < freq; i++ {
+		position, err := postingsEnum.NextPosition()
+		assert.Nil(t, err)
+		assert.Equal(t, expected.positions[i], position, "doc %d", expected.doc)
+
+		if flags&coreIndex.POSTINGS_ENUM_OFFSETS == coreIndex.POSTINGS_ENUM_OFFSETS {
+			start, err := postingsEnum.StartOffset()
+			assert.Nil(t, err)
+			end, err := postingsEnum.EndOffset()
+			assert.Nil(t, err)
+			assert.Equal(t, [2]int{expected.starts[i], expected.ends[i]}, [2]int{start, end}, "doc %d", expected.doc)
+		}
+
+		if flags&coreIndex.POSTINGS_ENUM_PAYLOADS == coreIndex.POSTINGS_ENUM_PAYLOADS {
+			payload, err := postingsEnum.GetPayload()
+			assert.Nil(t, err)
+			if expected.payloads[i] == nil {
+				assert.Empty(t, payload, "doc %d", expected.doc)
+			} else {
+				assert.Equal(t, string(expected.payloads[i]), string(payload), "doc %d", expected.doc)
+			}
+		}
+	}
+}
+
+func TestPostings_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+	postings := expectedPostings()
+
+	// full blocks of docs and positions with a vInt tail, a short vInt-only list and a singleton
+	for _, term := range []string{"common", "even", "rare", "u0007"} {
+		expected := postings[term]
+		termsEnum := seekExact(t, reader, "body", term)
+
+		docFreq, err := termsEnum.DocFreq()
+		assert.Nil(t, err)
+		assert.Equal(t, len(expected), docFreq, term)
+		totalTermFreq := 0
+		for _, p := range expected {
+			totalTermFreq += len(p.positions)
+		}
+		ttf, err := termsEnum.TotalTermFreq()
+		assert.Nil(t, err)
+		assert.EqualValues(t, totalTermFreq, ttf, term)
+
+		for _, flags := range []int{coreIndex.POSTINGS_ENUM_FREQS, coreIndex.POSTINGS_ENUM_POSITIONS,
+			coreIndex.POSTINGS_ENUM_OFFSETS, coreIndex.POSTINGS_ENUM_PAYLOADS, coreIndex.POSTINGS_ENUM_ALL} {
+			postingsEnum, err := termsEnum.Postings(nil, flags)
+			assert.Nil(t, err)
+			for _, p := range expected {
+				doc, err := postingsEnum.NextDoc(ctx)
+				assert.Nil(t, err)
+				assert.Equal(t, p.doc, doc, "%s flags %d", term, flags)
+				assertPositions(t, p, postingsEnum, flags)
+			}
+			doc, _ := postingsEnum.NextDoc(ctx)
+			assert.Equal(t, types.NO_MORE_DOCS, doc, term)
+		}
+	}
+}
+
+func TestPostings_DocsOnly(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+
+	for group := 0; group < 2; group++ {
+		termsEnum := seekExact(t, reader, "group", fmt.Sprintf("g%d", group))
+		docFreq, err := termsEnum.DocFreq()
+		assert.Nil(t, err)
+		assert.Equal(t, numDocs/2, docFreq)
+
+		postingsEnum, err := termsEnum.Postings(nil, coreIndex.POSTINGS_ENUM_NONE)
+		assert.Nil(t, err)
+		for expected := group; expected < numDocs; expected += 2 {
+			doc, err := postingsEnum.NextDoc(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, doc)
+		}
+		doc, _ := postingsEnum.NextDoc(ctx)
+		assert.Equal(t, types.NO_MORE_DOCS, doc)
+
+		// advancing skips whole blocks through the skip data
+		postingsEnum, err = termsEnum.Postings(postingsEnum, coreIndex.POSTINGS_ENUM_NONE)
+		assert.Nil(t, err)
+		for _, target := range []int{1, 300, 302, 1024, 1400} {
+			doc, err := postingsEnum.Advance(ctx, target)
+			assert.Nil(t, err)
+			assert.Equal(t, target+(target+group)%2, doc, "g%d advance to %d", group, target)
+		}
+	}
+}
+
+func TestPostings_Advance(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+	postings := expectedPostings()
+
+	// targets inside the first block, across one and many blocks, on both skip levels and past the end
+	targets := []int{0, 5, 127, 128, 129, 400, 401, 1023, 1024, 1100, 1300, 1490, 1499, numDocs}
+	for _, term := range []string{"common", "even", "rare"} {
+		expected := postings[term]
+		termsEnum := seekExact(t, reader, "body", term)
+
+		for _, flags := range []int{coreIndex.POSTINGS_ENUM_NONE, coreIndex.POSTINGS_ENUM_FREQS, coreIndex.POSTINGS_ENUM_ALL} {
+			postingsEnum, err := termsEnum.Postings(nil, flags)
+			assert.Nil(t, err)
+
+			next := 0
+			for _, target := range targets {
+				for next < len(expected) && expected[next].doc < target {
+					next++
+				}
+				doc, _ := postingsEnum.Advance(ctx, target)
+				if next == len(expected) {
+					assert.Equal(t, types.NO_MORE_DOCS, doc, "%s advance to %d", term, target)
+					break
+				}
+				assert.Equal(t, expected[next].doc, doc, "%s advance to %d", term, target)
+				if flags != coreIndex.POSTINGS_ENUM_NONE {
+					// the positions of the skipped docs are skipped too
+					assertPositions(t, expected[next], postingsEnum, flags)
+				}
+				next++
+			}
+		}
+	}
+}
+
+func TestBlockTree_SeekExact(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+
+	terms, err := reader.Terms("body")
+	assert.Nil(t, err)
+	size, err := terms.Size()
+	assert.Nil(t, err)
+	assert.EqualValues(t, numDocs+3, size)
+
+	termsEnum, err := terms.Iterator()
+	assert.Nil(t, err)
+	for _, term := range []string{"u0000", "u0777", "u1499", "common", "rare", "even"} {
+		found, err := termsEnum.SeekExact(ctx, []byte(term))
+		assert.Nil(t, err)
+		assert.True(t, found, term)
+		current, err := termsEnum.Term()
+		assert.Nil(t, err)
+		assert.Equal(t, term, string(current))
+	}
+	for _, term := range []string{"", "a", "commo", "commons", "u1500", "u07770", "zzz"} {
+		found, err := termsEnum.SeekExact(ctx, []byte(term))
+		assert.Nil(t, err)
+		assert.False(t, found, term)
+	}
+
+	// the doc freq of the terms of many blocks
+	for i := 0; i < numDocs; i += 37 {
+		found, err := termsEnum.SeekExact(ctx, []byte(fmt.Sprintf("u%04d", i)))
+		assert.Nil(t, err)
+		assert.True(t, found)
+		docFreq, err := termsEnum.DocFreq()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, docFreq)
+		postingsEnum, err := termsEnum.Postings(nil, coreIndex.POSTINGS_ENUM_FREQS)
+		assert.Nil(t, err)
+		doc, err := postingsEnum.NextDoc(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, i, doc)
+	}
+}
+
+func TestBlockTree_SeekCeil(t *testing.T) {
+	ctx := context.Background()
+	reader := newTestLeafReader(t)
+
+	terms, err := reader.Terms("body")
+	assert.Nil(t, err)
+	termsEnum, err := terms.Iterator()
+	assert.Nil(t, err)
+
+	for _, test := range []struct {
+		target string
+		status index.SeekStatus
+		term   string
+	}{
+		{"", index.SEEK_STATUS_NOT_FOUND, "common"},
+		{"commo", index.SEEK_STATUS_NOT_FOUND, "common"},
+		{"common", index.SEEK_STATUS_FOUND, "common"},
+		{"commons", index.SEEK_STATUS_NOT_FOUND, "even"},
+		{"f", index.SEEK_STATUS_NOT_FOUND, "rare"},
+		{"s", index.SEEK_STATUS_NOT_FOUND, "u0000"},
+		{"u0100", index.SEEK_STATUS_FOUND, "u0100"},
+		{"u01005", index.SEEK_STATUS_NOT_FOUND, "u0101"},
+		{"u0999z", index.SEEK_STATUS_NOT_FOUND, "u1000"},
+		{"u1499", index.SEEK_STATUS_FOUND, "u1499"},
+	} {
+		status, err := termsEnum.SeekCeil(ctx, []byte(test.target))
+		assert.Nil(t, err)
+		assert.Equal(t, test.status, status, test.target)
+		term, err := termsEnum.Term()
+		assert.Nil(t, err)
+		assert.Equal(t, test.term, string(term), test.target)
+	}
+
+	for _, target := range []string{"u15", "v", "zzz"} {
+		status, err := termsEnum.SeekCeil(ctx, []byte(target))
+		assert.Nil(t, err)
+		assert.EqualValues(t, index.SEEK_STATUS_END, status, target)
+	}
+
+	// next continues after the term seekCeil positioned on
+	status, err := termsEnum.SeekCeil(ctx, []byte("u0499a"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, index.SEEK_STATUS_NOT_FOUND, status)
+	for i := 500; i < 510; i++ {
+		term, err := termsEnum.Next(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("u%04d", i+1), string(term))
+	}
+
+	// a full iteration visits every term in order
+	termsEnum, err = terms.Iterator()
+	assert.Nil(t, err)
+	all := make([]string, 0)
+	for {
+		term, err := termsEnum.Next(ctx)
+		if term == nil || errors.Is(err, io.EOF) {
+			break
+		}
+		assert.Nil(t, err)
+		all = append(all, string(term))
+	}
+	expected := []string{"common", "even", "rare"}
+	for i := 0; i < numDocs; i++ {
+		expected = append(expected, fmt.Sprintf("u%04d", i))
+	}
+	assert.Equal(t, expected, all)
+}
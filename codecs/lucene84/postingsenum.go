@@ -0,0 +1,908 @@
+package lucene84
+
+import (
+	"context"
+	"io"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.ImpactsEnum = &blockDocsEnum{}
+
+// blockDocsEnum enumerates the docs (and freqs) of a term, positions are not available.
+type blockDocsEnum struct {
+	forUtil *ForUtil
+	encoded []byte
+
+	docDeltaBuffer []uint64
+	freqBuffer     []uint64
+
+	docBufferUpto int
+
+	skipper *ScoreSkipReader
+	skipped bool
+
+	startDocIn store.IndexInput
+	docIn      store.IndexInput
+
+	indexHasFreq     bool
+	indexHasPos      bool
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int
+	totalTermFreq int64
+	docUpto       int
+	doc           int
+	accum         int
+	freq          int
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	// docID for next skip point, we won't use skipper if
+	// target docID is not larger than this
+	nextSkipDoc int
+
+	needsFreq bool
+
+	// docid when there is a single pulsed posting, otherwise -1
+	singletonDocID int
+}
+
+func (p *PostingsReader) newBlockDocsEnum(fieldInfo *document.FieldInfo) *blockDocsEnum {
+	indexOptions := fieldInfo.GetIndexOptions()
+	return &blockDocsEnum{
+		forUtil:          p.forUtil,
+		encoded:          make([]byte, maxEncodedSize()),
+		docDeltaBuffer:   make([]uint64, maxDataSize),
+		freqBuffer:       make([]uint64, maxDataSize),
+		startDocIn:       p.docIn,
+		indexHasFreq:     indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS,
+		indexHasPos:      indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS,
+		indexHasOffsets:  indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads: fieldInfo.HasPayloads(),
+	}
+}
+
+func (b *blockDocsEnum) canReuse(docIn store.IndexInput, fieldInfo *document.FieldInfo) bool {
+	indexOptions := fieldInfo.GetIndexOptions()
+	return docIn == b.startDocIn &&
+		b.indexHasFreq == (indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS) &&
+		b.indexHasPos == (indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS) &&
+		b.indexHasPayloads == fieldInfo.HasPayloads()
+}
+
+func (b *blockDocsEnum) reset(ctx context.Context, termState *IntBlockTermState, flags int) error {
+	b.docFreq = termState.DocFreq
+	if b.indexHasFreq {
+		b.totalTermFreq = termState.TotalTermFreq
+	} else {
+		b.totalTermFreq = int64(b.docFreq)
+	}
+	b.docTermStartFP = termState.DocStartFP
+	b.skipOffset = termState.SkipOffset
+	b.singletonDocID = termState.SingletonDocID
+	if b.docFreq > 1 {
+		if b.docIn == nil {
+			// lazy init
+			b.docIn = b.startDocIn.Clone().(store.IndexInput)
+		}
+		if _, err := b.docIn.Seek(b.docTermStartFP, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	b.doc = -1
+	b.needsFreq = coreIndex.FeatureRequested(flags, coreIndex.POSTINGS_ENUM_FREQS)
+	if !b.indexHasFreq || !b.needsFreq {
+		fill(b.freqBuffer, 1)
+	}
+	b.accum = 0
+	b.docUpto = 0
+	b.nextSkipDoc = BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	b.docBufferUpto = BLOCK_SIZE
+	b.skipped = false
+	return nil
+}
+
+// initImpacts loads the skip data upfront, impacts are read from the skip entries.
+func (b *blockDocsEnum) initImpacts(ctx context.Context) error {
+	if err := b.initSkipper(ctx); err != nil {
+		return err
+	}
+	b.nextSkipDoc = -1
+	return nil
+}
+
+func (b *blockDocsEnum) initSkipper(ctx context.Context) error {
+	if b.skipper == nil {
+		// Lazy init: first time this enum has ever been used for skipping
+		b.skipper = NewScoreSkipReader(b.docIn.Clone().(store.IndexInput), maxSkipLevels,
+			b.indexHasPos, b.indexHasOffsets, b.indexHasPayloads)
+	}
+
+	if !b.skipped {
+		// This is the first time this enum has skipped
+		// since reset() was called; load the skip data:
+		if err := b.skipper.Init(ctx, b.docTermStartFP+b.skipOffset, b.docTermStartFP, 0, 0, b.docFreq); err != nil {
+			return err
+		}
+		b.skipped = true
+	}
+	return nil
+}
+
+func (b *blockDocsEnum) DocID() int {
+	return b.doc
+}
+
+func (b *blockDocsEnum) Freq() (int, error) {
+	return b.freq, nil
+}
+
+func (b *blockDocsEnum) NextPosition() (int, error) {
+	return -1, nil
+}
+
+func (b *blockDocsEnum) StartOffset() (int, error) {
+	return -1, nil
+}
+
+func (b *blockDocsEnum) EndOffset() (int, error) {
+	return -1, nil
+}
+
+func (b *blockDocsEnum) GetPayload() ([]byte, error) {
+	return nil, nil
+}
+
+func (b *blockDocsEnum) refillDocs(ctx context.Context) error {
+	left := b.docFreq - b.docUpto
+
+	switch {
+	case left >= BLOCK_SIZE:
+		if err := b.forUtil.ReadBlock(ctx, b.docIn, b.encoded, b.docDeltaBuffer); err != nil {
+			return err
+		}
+
+		if b.indexHasFreq {
+			if b.needsFreq {
+				if err := b.forUtil.ReadBlock(ctx, b.docIn, b.encoded, b.freqBuffer); err != nil {
+					return err
+				}
+			} else {
+				// skip over freqBuffer if we don't need them at all
+				if err := b.forUtil.SkipBlock(ctx, b.docIn); err != nil {
+					return err
+				}
+			}
+		}
+	case b.docFreq == 1:
+		b.docDeltaBuffer[0] = uint64(b.singletonDocID)
+		b.freqBuffer[0] = uint64(b.totalTermFreq)
+	default:
+		// Read vInts:
+		if err := readVIntBlock(ctx, b.docIn, b.docDeltaBuffer, b.freqBuffer, left, b.indexHasFreq); err != nil {
+			return err
+		}
+		if !b.needsFreq {
+			fill(b.freqBuffer[:left], 1)
+		}
+	}
+	b.docBufferUpto = 0
+	return nil
+}
+
+func (b *blockDocsEnum) NextDoc(ctx context.Context) (int, error) {
+	if b.docUpto == b.docFreq {
+		b.doc = types.NO_MORE_DOCS
+		return b.doc, io.EOF
+	}
+	if b.docBufferUpto == BLOCK_SIZE {
+		if err := b.refillDocs(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	b.accum += int(b.docDeltaBuffer[b.docBufferUpto])
+	b.docUpto++
+
+	b.doc = b.accum
+	b.freq = int(b.freqBuffer[b.docBufferUpto])
+	b.docBufferUpto++
+	return b.doc, nil
+}
+
+func (b *blockDocsEnum) Advance(ctx context.Context, target int) (int, error) {
+	// current skip docID < docIDs generated from current buffer <= next skip docID
+	// we don't need to skip if target is buffered already
+	if err := b.AdvanceShallow(ctx, target); err != nil {
+		return 0, err
+	}
+
+	if b.docUpto == b.docFreq {
+		b.doc = types.NO_MORE_DOCS
+		return b.doc, io.EOF
+	}
+	if b.docBufferUpto == BLOCK_SIZE {
+		if err := b.refillDocs(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	// Now scan... this is an inlined/pared down version
+	// of nextDoc():
+	for {
+		b.accum += int(b.docDeltaBuffer[b.docBufferUpto])
+		b.docUpto++
+
+		if b.accum >= target {
+			break
+		}
+		b.docBufferUpto++
+		if b.docUpto == b.docFreq {
+			b.doc = types.NO_MORE_DOCS
+			return b.doc, io.EOF
+		}
+	}
+
+	b.freq = int(b.freqBuffer[b.docBufferUpto])
+	b.docBufferUpto++
+	b.doc = b.accum
+	return b.doc, nil
+}
+
+func (b *blockDocsEnum) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, b, target)
+}
+
+func (b *blockDocsEnum) Cost() int64 {
+	return int64(b.docFreq)
+}
+
+func (b *blockDocsEnum) AdvanceShallow(ctx context.Context, target int) error {
+	if b.docFreq <= BLOCK_SIZE || target <= b.nextSkipDoc {
+		return nil
+	}
+
+	if err := b.initSkipper(ctx); err != nil {
+		return err
+	}
+
+	// always plus one to fix the result, since skip position in SkipReader
+	// is a little different from MultiLevelSkipListReader
+	newDocUpto, err := b.skipper.SkipTo(ctx, target)
+	if err != nil {
+		return err
+	}
+	newDocUpto++
+
+	if newDocUpto > b.docUpto {
+		// Skipper moved
+		b.docUpto = newDocUpto
+
+		// Force to read next block
+		b.docBufferUpto = BLOCK_SIZE
+		b.accum = b.skipper.GetDoc() // actually, this is just lastSkipEntry
+		// now point to the block we want to search
+		if _, err := b.docIn.Seek(b.skipper.GetDocPointer(), io.SeekStart); err != nil {
+			return err
+		}
+	}
+	// next time we call advance, this is used to
+	// foresee whether skipper is necessary.
+	b.nextSkipDoc = b.skipper.GetNextSkipDoc()
+	return nil
+}
+
+func (b *blockDocsEnum) GetImpacts() (index.Impacts, error) {
+	if err := b.AdvanceShallow(context.Background(), b.doc); err != nil {
+		return nil, err
+	}
+	return b.skipper, nil
+}
+
+var _ index.ImpactsEnum = &everythingEnum{}
+
+// everythingEnum enumerates docs, freqs and positions, and (if indexed) offsets and payloads.
+type everythingEnum struct {
+	forUtil *ForUtil
+	encoded []byte
+
+	docDeltaBuffer         []uint64
+	freqBuffer             []uint64
+	posDeltaBuffer         []uint64
+	payloadLengthBuffer    []uint64
+	offsetStartDeltaBuffer []uint64
+	offsetLengthBuffer     []uint64
+
+	payloadBytes    []byte
+	payloadByteUpto int
+	payloadLength   int
+
+	lastStartOffset int
+	startOffset     int
+	endOffset       int
+
+	docBufferUpto int
+	posBufferUpto int
+
+	skipper *ScoreSkipReader
+	skipped bool
+
+	startDocIn store.IndexInput
+	docIn      store.IndexInput
+	posIn      store.IndexInput
+	payIn      store.IndexInput
+	payload    []byte
+
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int
+	totalTermFreq int64
+	docUpto       int
+	doc           int
+	accum         int
+	freq          int
+	position      int
+
+	// how many positions "behind" we are; nextPosition must
+	// skip these to "catch up":
+	posPendingCount int
+
+	// Lazy pos seek: if != -1 then we must seek to this FP
+	// before reading positions:
+	posPendingFP int64
+
+	// Lazy pay seek: if != -1 then we must seek to this FP
+	// before reading payloads/offsets:
+	payPendingFP int64
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's postings start in the .pos file:
+	posTermStartFP int64
+
+	// Where this term's payloads/offsets start in the .pay
+	// file:
+	payTermStartFP int64
+
+	// File pointer where the last (vInt encoded) pos delta
+	// block is.  We need this to know whether to bulk
+	// decode vs vInt decode the block:
+	lastPosBlockFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	nextSkipDoc int
+
+	needsOffsets  bool // true if we actually need offsets
+	needsPayloads bool // true if we actually need payloads
+
+	// docid when there is a single pulsed posting, otherwise -1
+	singletonDocID int
+}
+
+func (p *PostingsReader) newEverythingEnum(fieldInfo *document.FieldInfo) *everythingEnum {
+	indexOptions := fieldInfo.GetIndexOptions()
+
+	e := &everythingEnum{
+		forUtil:          p.forUtil,
+		encoded:          make([]byte, maxEncodedSize()),
+		docDeltaBuffer:   make([]uint64, maxDataSize),
+		freqBuffer:       make([]uint64, maxDataSize),
+		posDeltaBuffer:   make([]uint64, maxDataSize),
+		startDocIn:       p.docIn,
+		posIn:            p.posIn.Clone().(store.IndexInput),
+		indexHasOffsets:  indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads: fieldInfo.HasPayloads(),
+	}
+
+	if e.indexHasOffsets || e.indexHasPayloads {
+		e.payIn = p.payIn.Clone().(store.IndexInput)
+	}
+
+	if e.indexHasOffsets {
+		e.offsetStartDeltaBuffer = make([]uint64, maxDataSize)
+		e.offsetLengthBuffer = make([]uint64, maxDataSize)
+	} else {
+		e.startOffset = -1
+		e.endOffset = -1
+	}
+
+	if e.indexHasPayloads {
+		e.payloadLengthBuffer = make([]uint64, maxDataSize)
+		e.payloadBytes = make([]byte, 128)
+	}
+	return e
+}
+
+func (e *everythingEnum) canReuse(docIn store.IndexInput, fieldInfo *document.FieldInfo) bool {
+	return docIn == e.startDocIn &&
+		e.indexHasOffsets == (fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS) &&
+		e.indexHasPayloads == fieldInfo.HasPayloads()
+}
+
+func (e *everythingEnum) reset(ctx context.Context, termState *IntBlockTermState, flags int) error {
+	e.docFreq = termState.DocFreq
+	e.docTermStartFP = termState.DocStartFP
+	e.posTermStartFP = termState.PosStartFP
+	e.payTermStartFP = termState.PayStartFP
+	e.skipOffset = termState.SkipOffset
+	e.totalTermFreq = termState.TotalTermFreq
+	e.singletonDocID = termState.SingletonDocID
+	if e.docFreq > 1 {
+		if e.docIn == nil {
+			// lazy init
+			e.docIn = e.startDocIn.Clone().(store.IndexInput)
+		}
+		if _, err := e.docIn.Seek(e.docTermStartFP, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	e.posPendingFP = e.posTermStartFP
+	e.payPendingFP = e.payTermStartFP
+	e.posPendingCount = 0
+
+	switch {
+	case termState.TotalTermFreq < BLOCK_SIZE:
+		e.lastPosBlockFP = e.posTermStartFP
+	case termState.TotalTermFreq == BLOCK_SIZE:
+		e.lastPosBlockFP = -1
+	default:
+		e.lastPosBlockFP = e.posTermStartFP + termState.LastPosBlockOffset
+	}
+
+	e.needsOffsets = coreIndex.FeatureRequested(flags, coreIndex.POSTINGS_ENUM_OFFSETS)
+	e.needsPayloads = coreIndex.FeatureRequested(flags, coreIndex.POSTINGS_ENUM_PAYLOADS)
+
+	e.doc = -1
+	e.accum = 0
+	e.docUpto = 0
+	if e.docFreq > BLOCK_SIZE {
+		e.nextSkipDoc = BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	} else {
+		e.nextSkipDoc = types.NO_MORE_DOCS // not enough docs for skipping
+	}
+	e.docBufferUpto = BLOCK_SIZE
+	e.skipped = false
+	return nil
+}
+
+// initImpacts loads the skip data upfront, impacts are read from the skip entries.
+func (e *everythingEnum) initImpacts(ctx context.Context) error {
+	if err := e.initSkipper(ctx); err != nil {
+		return err
+	}
+	e.nextSkipDoc = -1
+	return nil
+}
+
+func (e *everythingEnum) initSkipper(ctx context.Context) error {
+	if e.skipper == nil {
+		// Lazy init: first time this enum has ever been used for skipping
+		e.skipper = NewScoreSkipReader(e.docIn.Clone().(store.IndexInput), maxSkipLevels,
+			true, e.indexHasOffsets, e.indexHasPayloads)
+	}
+
+	if !e.skipped {
+		// This is the first time this enum has skipped
+		// since reset() was called; load the skip data:
+		if err := e.skipper.Init(ctx, e.docTermStartFP+e.skipOffset, e.docTermStartFP,
+			e.posTermStartFP, e.payTermStartFP, e.docFreq); err != nil {
+			return err
+		}
+		e.skipped = true
+	}
+	return nil
+}
+
+func (e *everythingEnum) DocID() int {
+	return e.doc
+}
+
+func (e *everythingEnum) Freq() (int, error) {
+	return e.freq, nil
+}
+
+func (e *everythingEnum) refillDocs(ctx context.Context) error {
+	left := e.docFreq - e.docUpto
+
+	switch {
+	case left >= BLOCK_SIZE:
+		if err := e.forUtil.ReadBlock(ctx, e.docIn, e.encoded, e.docDeltaBuffer); err != nil {
+			return err
+		}
+		if err := e.forUtil.ReadBlock(ctx, e.docIn, e.encoded, e.freqBuffer); err != nil {
+			return err
+		}
+	case e.docFreq == 1:
+		e.docDeltaBuffer[0] = uint64(e.singletonDocID)
+		e.freqBuffer[0] = uint64(e.totalTermFreq)
+	default:
+		if err := readVIntBlock(ctx, e.docIn, e.docDeltaBuffer, e.freqBuffer, left, true); err != nil {
+			return err
+		}
+	}
+	e.docBufferUpto = 0
+	return nil
+}
+
+func (e *everythingEnum) refillPositions(ctx context.Context) error {
+	if e.posIn.GetFilePointer() == e.lastPosBlockFP {
+		return e.readVIntPositions(ctx)
+	}
+
+	if err := e.forUtil.ReadBlock(ctx, e.posIn, e.encoded, e.posDeltaBuffer); err != nil {
+		return err
+	}
+
+	if e.indexHasPayloads {
+		if e.needsPayloads {
+			if err := e.forUtil.ReadBlock(ctx, e.payIn, e.encoded, e.payloadLengthBuffer); err != nil {
+				return err
+			}
+			numBytes, err := e.payIn.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			if int(numBytes) > len(e.payloadBytes) {
+				e.payloadBytes = make([]byte, numBytes)
+			}
+			if _, err := io.ReadFull(e.payIn, e.payloadBytes[:numBytes]); err != nil {
+				return err
+			}
+		} else {
+			// this works, because when writing a vint block we always force the first length to be written
+			if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil { // skip over lengths
+				return err
+			}
+			if err := e.skipPayloadBytes(ctx); err != nil {
+				return err
+			}
+		}
+		e.payloadByteUpto = 0
+	}
+
+	if e.indexHasOffsets {
+		if e.needsOffsets {
+			if err := e.forUtil.ReadBlock(ctx, e.payIn, e.encoded, e.offsetStartDeltaBuffer); err != nil {
+				return err
+			}
+			if err := e.forUtil.ReadBlock(ctx, e.payIn, e.encoded, e.offsetLengthBuffer); err != nil {
+				return err
+			}
+		} else {
+			// this works, because when writing a vint block we always force the first length to be written
+			if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil { // skip over starts
+				return err
+			}
+			if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil { // skip over lengths
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readVIntPositions reads the last, vInt encoded, positions block of the term.
+func (e *everythingEnum) readVIntPositions(ctx context.Context) error {
+	count := int(e.totalTermFreq % BLOCK_SIZE)
+	payloadLength := 0
+	offsetLength := 0
+	e.payloadByteUpto = 0
+	for i := 0; i < count; i++ {
+		code, err := e.posIn.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+
+		if e.indexHasPayloads {
+			if code&1 != 0 {
+				length, err := e.posIn.ReadUvarint(ctx)
+				if err != nil {
+					return err
+				}
+				payloadLength = int(length)
+			}
+			e.payloadLengthBuffer[i] = uint64(payloadLength)
+			e.posDeltaBuffer[i] = code >> 1
+			if payloadLength != 0 {
+				if e.payloadByteUpto+payloadLength > len(e.payloadBytes) {
+					e.payloadBytes = append(e.payloadBytes, make([]byte, e.payloadByteUpto+payloadLength-len(e.payloadBytes))...)
+				}
+				if _, err := io.ReadFull(e.posIn, e.payloadBytes[e.payloadByteUpto:e.payloadByteUpto+payloadLength]); err != nil {
+					return err
+				}
+				e.payloadByteUpto += payloadLength
+			}
+		} else {
+			e.posDeltaBuffer[i] = code
+		}
+
+		if e.indexHasOffsets {
+			deltaCode, err := e.posIn.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			if deltaCode&1 != 0 {
+				length, err := e.posIn.ReadUvarint(ctx)
+				if err != nil {
+					return err
+				}
+				offsetLength = int(length)
+			}
+			e.offsetStartDeltaBuffer[i] = deltaCode >> 1
+			e.offsetLengthBuffer[i] = uint64(offsetLength)
+		}
+	}
+	e.payloadByteUpto = 0
+	return nil
+}
+
+func (e *everythingEnum) skipPayloadBytes(ctx context.Context) error {
+	numBytes, err := e.payIn.ReadUvarint(ctx) // read length of payloadBytes
+	if err != nil {
+		return err
+	}
+	// skip over payloadBytes
+	_, err = e.payIn.Seek(e.payIn.GetFilePointer()+int64(numBytes), io.SeekStart)
+	return err
+}
+
+func (e *everythingEnum) NextDoc(ctx context.Context) (int, error) {
+	if e.docUpto == e.docFreq {
+		e.doc = types.NO_MORE_DOCS
+		return e.doc, io.EOF
+	}
+	if e.docBufferUpto == BLOCK_SIZE {
+		if err := e.refillDocs(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	e.accum += int(e.docDeltaBuffer[e.docBufferUpto])
+	e.freq = int(e.freqBuffer[e.docBufferUpto])
+	e.posPendingCount += e.freq
+	e.docBufferUpto++
+	e.docUpto++
+
+	e.doc = e.accum
+	e.position = 0
+	e.lastStartOffset = 0
+	return e.doc, nil
+}
+
+func (e *everythingEnum) Advance(ctx context.Context, target int) (int, error) {
+	// TODO: make frq block load lazy/skippable
+	if err := e.AdvanceShallow(ctx, target); err != nil {
+		return 0, err
+	}
+
+	if e.docUpto == e.docFreq {
+		e.doc = types.NO_MORE_DOCS
+		return e.doc, io.EOF
+	}
+	if e.docBufferUpto == BLOCK_SIZE {
+		if err := e.refillDocs(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	// Now scan:
+	for {
+		e.accum += int(e.docDeltaBuffer[e.docBufferUpto])
+		e.freq = int(e.freqBuffer[e.docBufferUpto])
+		e.posPendingCount += e.freq
+		e.docBufferUpto++
+		e.docUpto++
+
+		if e.accum >= target {
+			break
+		}
+		if e.docUpto == e.docFreq {
+			e.doc = types.NO_MORE_DOCS
+			return e.doc, io.EOF
+		}
+	}
+
+	e.position = 0
+	e.lastStartOffset = 0
+	e.doc = e.accum
+	return e.doc, nil
+}
+
+func (e *everythingEnum) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, e, target)
+}
+
+func (e *everythingEnum) Cost() int64 {
+	return int64(e.docFreq)
+}
+
+// TODO: in theory we could avoid loading frq block
+// when not needed, ie, use skip data to load how far to
+// seek the pos pointer ... instead of having to load frq
+// blocks only to sum up how many positions to skip
+func (e *everythingEnum) skipPositions(ctx context.Context) error {
+	// Skip positions now:
+	toSkip := e.posPendingCount - e.freq
+
+	leftInBlock := BLOCK_SIZE - e.posBufferUpto
+	if toSkip < leftInBlock {
+		end := e.posBufferUpto + toSkip
+		for e.posBufferUpto < end {
+			if e.indexHasPayloads {
+				e.payloadByteUpto += int(e.payloadLengthBuffer[e.posBufferUpto])
+			}
+			e.posBufferUpto++
+		}
+	} else {
+		toSkip -= leftInBlock
+		for toSkip >= BLOCK_SIZE {
+			if err := e.forUtil.SkipBlock(ctx, e.posIn); err != nil {
+				return err
+			}
+
+			if e.indexHasPayloads {
+				// Skip payloadLength block:
+				if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil {
+					return err
+				}
+				// Skip payloadBytes block:
+				if err := e.skipPayloadBytes(ctx); err != nil {
+					return err
+				}
+			}
+
+			if e.indexHasOffsets {
+				if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil {
+					return err
+				}
+				if err := e.forUtil.SkipBlock(ctx, e.payIn); err != nil {
+					return err
+				}
+			}
+			toSkip -= BLOCK_SIZE
+		}
+		if err := e.refillPositions(ctx); err != nil {
+			return err
+		}
+		e.payloadByteUpto = 0
+		e.posBufferUpto = 0
+		for e.posBufferUpto < toSkip {
+			if e.indexHasPayloads {
+				e.payloadByteUpto += int(e.payloadLengthBuffer[e.posBufferUpto])
+			}
+			e.posBufferUpto++
+		}
+	}
+
+	e.position = 0
+	e.lastStartOffset = 0
+	return nil
+}
+
+func (e *everythingEnum) NextPosition() (int, error) {
+	ctx := context.Background()
+
+	if e.posPendingFP != -1 {
+		if _, err := e.posIn.Seek(e.posPendingFP, io.SeekStart); err != nil {
+			return 0, err
+		}
+		e.posPendingFP = -1
+
+		if e.payPendingFP != -1 && e.payIn != nil {
+			if _, err := e.payIn.Seek(e.payPendingFP, io.SeekStart); err != nil {
+				return 0, err
+			}
+			e.payPendingFP = -1
+		}
+
+		// Force buffer refill:
+		e.posBufferUpto = BLOCK_SIZE
+	}
+
+	if e.posPendingCount > e.freq {
+		if err := e.skipPositions(ctx); err != nil {
+			return 0, err
+		}
+		e.posPendingCount = e.freq
+	}
+
+	if e.posBufferUpto == BLOCK_SIZE {
+		if err := e.refillPositions(ctx); err != nil {
+			return 0, err
+		}
+		e.posBufferUpto = 0
+	}
+	e.position += int(e.posDeltaBuffer[e.posBufferUpto])
+
+	if e.indexHasPayloads {
+		e.payloadLength = int(e.payloadLengthBuffer[e.posBufferUpto])
+		e.payload = e.payloadBytes[e.payloadByteUpto : e.payloadByteUpto+e.payloadLength]
+		e.payloadByteUpto += e.payloadLength
+	}
+
+	if e.indexHasOffsets {
+		e.startOffset = e.lastStartOffset + int(e.offsetStartDeltaBuffer[e.posBufferUpto])
+		e.endOffset = e.startOffset + int(e.offsetLengthBuffer[e.posBufferUpto])
+		e.lastStartOffset = e.startOffset
+	}
+
+	e.posBufferUpto++
+	e.posPendingCount--
+	return e.position, nil
+}
+
+func (e *everythingEnum) StartOffset() (int, error) {
+	return e.startOffset, nil
+}
+
+func (e *everythingEnum) EndOffset() (int, error) {
+	return e.endOffset, nil
+}
+
+func (e *everythingEnum) GetPayload() ([]byte, error) {
+	if e.payloadLength == 0 {
+		return nil, nil
+	}
+	return e.payload, nil
+}
+
+func (e *everythingEnum) AdvanceShallow(ctx context.Context, target int) error {
+	if target <= e.nextSkipDoc {
+		return nil
+	}
+
+	if err := e.initSkipper(ctx); err != nil {
+		return err
+	}
+
+	newDocUpto, err := e.skipper.SkipTo(ctx, target)
+	if err != nil {
+		return err
+	}
+	newDocUpto++
+
+	if newDocUpto > e.docUpto {
+		// Skipper moved
+		e.docUpto = newDocUpto
+
+		// Force to read next block
+		e.docBufferUpto = BLOCK_SIZE
+		e.accum = e.skipper.GetDoc()
+		if _, err := e.docIn.Seek(e.skipper.GetDocPointer(), io.SeekStart); err != nil {
+			return err
+		}
+		e.posPendingFP = e.skipper.GetPosPointer()
+		e.payPendingFP = e.skipper.GetPayPointer()
+		e.posPendingCount = e.skipper.GetPosBufferUpto()
+		e.lastStartOffset = 0 // new document
+		e.payloadByteUpto = e.skipper.GetPayloadByteUpto()
+	}
+	e.nextSkipDoc = e.skipper.GetNextSkipDoc()
+	return nil
+}
+
+func (e *everythingEnum) GetImpacts() (index.Impacts, error) {
+	if err := e.AdvanceShallow(context.Background(), e.doc); err != nil {
+		return nil, err
+	}
+	return e.skipper, nil
+}
@@ -0,0 +1,136 @@
+package lucene84
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/codecs/blocktree"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+func init() {
+	coreIndex.RegisterPostingsFormat(NewPostingsFormat())
+}
+
+const (
+	// DOC_EXTENSION Filename extension for document number, frequencies, and skip data.
+	DOC_EXTENSION = "doc"
+
+	// POS_EXTENSION Filename extension for positions.
+	POS_EXTENSION = "pos"
+
+	// PAY_EXTENSION Filename extension for payloads and offsets.
+	PAY_EXTENSION = "pay"
+
+	// BLOCK_SIZE Size of blocks.
+	BLOCK_SIZE = 128
+
+	// maxSkipLevels Expert: The maximum number of skip levels. Smaller values result in slightly
+	// smaller indexes, but slower skipping in big posting lists.
+	maxSkipLevels = 10
+
+	skipMultiplier = 8
+
+	TERMS_CODEC = "Lucene84PostingsWriterTerms"
+	DOC_CODEC   = "Lucene84PostingsWriterDoc"
+	POS_CODEC   = "Lucene84PostingsWriterPos"
+	PAY_CODEC   = "Lucene84PostingsWriterPay"
+
+	VERSION_START   = 0
+	VERSION_CURRENT = VERSION_START
+)
+
+var _ index.PostingsFormat = &PostingsFormat{}
+
+// PostingsFormat
+// Lucene 5.0 postings format, which encodes postings in packed integer blocks for fast decode.
+//
+// Basic idea:
+//   - Packed Blocks and VInt Blocks:
+//     In packed blocks, integers are encoded with the same bit width (packed format): the block
+//     size (i.e. number of integers inside block) is fixed (currently 128). Additionally blocks
+//     that are all the same value are encoded in an optimized way.
+//     In VInt blocks, integers are encoded as VInt: the block size is variable.
+//   - Block structure:
+//     When the postings are long enough, Lucene84PostingsFormat will try to encode most
+//     integer data as a packed block.
+//     Take a term with 259 documents as an example, the first 256 document ids are encoded as
+//     two packed blocks, while the remaining 3 are encoded as one VInt block.
+//     Different kinds of data are always encoded separately into different packed blocks, but
+//     may possibly be interleaved into the same VInt block.
+//     This strategy is applied to pairs: <document number, frequency>, <position, payload
+//     length>, <position, offset start, offset length>, and <position, payload length, offset
+//     start, offset length>.
+//   - Skipdata settings:
+//     The structure of skip table is quite similar to previous version of Lucene. Skip interval
+//     is the same as block size, and each skip entry points to the beginning of each block.
+//     However, for the first block, skip data is omitted.
+//   - Positions, Payloads, and Offsets:
+//     A position is an integer indicating where the term occurs within one document. A payload
+//     is a blob of metadata associated with current position. An offset is a pair of integers
+//     indicating the tokenized start/end offsets for given term in current position: it is
+//     essentially a specialized payload.
+//     When payloads and offsets are not omitted, numPositions==numPayloads==numOffsets
+//     (assuming a null payload contributes one count). As mentioned in block structure, it is
+//     possible to encode these three either combined or separately.
+//     In all cases, payloads and offsets are stored together. When encoded as a packed block,
+//     position data is separated out as .pos, while payloads and offsets are encoded in .pay
+//     (payload metadata will also be stored directly in .pay). When encoded as VInt blocks, all
+//     these three are stored interleaved into the .pos (so is payload metadata).
+//     With this strategy, the majority of payload and offset data will be outside .pos file.
+//     So for queries that require only position data, running on a full index with payloads
+//     and offsets, this reduces disk pre-fetches.
+//
+// The term dictionary is the block tree terms dictionary, see blocktree.TermsWriter.
+type PostingsFormat struct {
+	name             string
+	minTermBlockSize int
+	maxTermBlockSize int
+}
+
+func NewPostingsFormat() *PostingsFormat {
+	return NewPostingsFormatWithBlockSize(blocktree.DEFAULT_MIN_BLOCK_SIZE, blocktree.DEFAULT_MAX_BLOCK_SIZE)
+}
+
+// NewPostingsFormatWithBlockSize
+// Creates Lucene84PostingsFormat with custom values for minBlockSize and maxBlockSize passed
+// to block terms dictionary.
+func NewPostingsFormatWithBlockSize(minTermBlockSize, maxTermBlockSize int) *PostingsFormat {
+	return &PostingsFormat{
+		name:             "Lucene84",
+		minTermBlockSize: minTermBlockSize,
+		maxTermBlockSize: maxTermBlockSize,
+	}
+}
+
+func (p *PostingsFormat) GetName() string {
+	return p.name
+}
+
+func (p *PostingsFormat) FieldsConsumer(ctx context.Context, state *index.SegmentWriteState) (index.FieldsConsumer, error) {
+	postingsWriter, err := NewPostingsWriter(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := blocktree.NewTermsWriter(ctx, state, postingsWriter, p.minTermBlockSize, p.maxTermBlockSize)
+	if err != nil {
+		_ = postingsWriter.Close()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+func (p *PostingsFormat) FieldsProducer(ctx context.Context, state *index.SegmentReadState) (index.FieldsProducer, error) {
+	postingsReader, err := NewPostingsReader(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := blocktree.NewTermsReader(ctx, postingsReader, state)
+	if err != nil {
+		_ = postingsReader.Close()
+		return nil, err
+	}
+	return producer, nil
+}
@@ -0,0 +1,325 @@
+package lucene84
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+var _ codecs.PostingsReaderBase = &PostingsReader{}
+
+// PostingsReader
+// Concrete class that reads docId(maybe frq,pos,offset,payloads) list with postings format.
+// lucene.experimental
+type PostingsReader struct {
+	docIn store.IndexInput
+	posIn store.IndexInput
+	payIn store.IndexInput
+
+	forUtil *ForUtil
+	version int
+}
+
+// NewPostingsReader Sole constructor.
+func NewPostingsReader(ctx context.Context, state *index.SegmentReadState) (*PostingsReader, error) {
+	forUtil, err := NewForUtil()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &PostingsReader{forUtil: forUtil}
+	if err := reader.openInputs(ctx, state); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// NOTE: these data files are too costly to verify checksum against all the bytes on open,
+// but for now we at least verify proper structure of the checksum footer: which looks
+// for FOOTER_MAGIC + algorithmID. This is cheap and can detect some forms of corruption
+// such as file truncation.
+func (p *PostingsReader) openInputs(ctx context.Context, state *index.SegmentReadState) error {
+	segmentID := state.SegmentInfo.GetID()
+
+	docName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, DOC_EXTENSION)
+	docIn, err := state.Directory.OpenInput(ctx, docName)
+	if err != nil {
+		return err
+	}
+	p.docIn = docIn
+
+	p.version, err = utils.CheckIndexHeader(ctx, p.docIn, DOC_CODEC, VERSION_START, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := utils.RetrieveChecksum(ctx, p.docIn); err != nil {
+		return err
+	}
+
+	if !state.FieldInfos.HasProx() {
+		return nil
+	}
+
+	posName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, POS_EXTENSION)
+	posIn, err := state.Directory.OpenInput(ctx, posName)
+	if err != nil {
+		return err
+	}
+	p.posIn = posIn
+	if _, err := utils.CheckIndexHeader(ctx, p.posIn, POS_CODEC, p.version, p.version,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+	if _, err := utils.RetrieveChecksum(ctx, p.posIn); err != nil {
+		return err
+	}
+
+	if state.FieldInfos.HasPayloads() || state.FieldInfos.HasOffsets() {
+		payName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, PAY_EXTENSION)
+		payIn, err := state.Directory.OpenInput(ctx, payName)
+		if err != nil {
+			return err
+		}
+		p.payIn = payIn
+		if _, err := utils.CheckIndexHeader(ctx, p.payIn, PAY_CODEC, p.version, p.version,
+			segmentID, state.SegmentSuffix); err != nil {
+			return err
+		}
+		if _, err := utils.RetrieveChecksum(ctx, p.payIn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostingsReader) Init(ctx context.Context, termsIn store.IndexInput, state *index.SegmentReadState) error {
+	// Make sure we are talking to the matching postings writer
+	if _, err := utils.CheckIndexHeader(ctx, termsIn, TERMS_CODEC, VERSION_START, VERSION_CURRENT,
+		state.SegmentInfo.GetID(), state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	indexBlockSize, err := termsIn.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	if indexBlockSize != BLOCK_SIZE {
+		return fmt.Errorf("index-time BLOCK_SIZE (%d) != read-time BLOCK_SIZE (%d)", indexBlockSize, BLOCK_SIZE)
+	}
+	return nil
+}
+
+func (p *PostingsReader) NewTermState() codecs.BlockTermState {
+	return NewIntBlockTermState()
+}
+
+func (p *PostingsReader) Close() error {
+	var err error
+	for _, in := range []store.IndexInput{p.docIn, p.posIn, p.payIn} {
+		if in == nil {
+			continue
+		}
+		if closeErr := in.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	p.docIn, p.posIn, p.payIn = nil, nil, nil
+	return err
+}
+
+func (p *PostingsReader) DecodeTerm(ctx context.Context, in store.DataInput, fieldInfo *document.FieldInfo,
+	state codecs.BlockTermState, absolute bool) error {
+
+	termState, ok := state.(*IntBlockTermState)
+	if !ok {
+		return errors.New("state is not *IntBlockTermState")
+	}
+
+	fieldHasPositions := fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS
+	fieldHasOffsets := fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	fieldHasPayloads := fieldInfo.HasPayloads()
+
+	if absolute {
+		termState.DocStartFP = 0
+		termState.PosStartFP = 0
+		termState.PayStartFP = 0
+	}
+
+	l, err := in.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	if l&0x01 == 0 {
+		termState.DocStartFP += int64(l >> 1)
+		if termState.DocFreq == 1 {
+			singletonDocID, err := in.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			termState.SingletonDocID = int(singletonDocID)
+		} else {
+			termState.SingletonDocID = -1
+		}
+	} else {
+		termState.SingletonDocID += int(zigZagDecode(l >> 1))
+	}
+
+	if fieldHasPositions {
+		posDelta, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		termState.PosStartFP += int64(posDelta)
+
+		if fieldHasOffsets || fieldHasPayloads {
+			payDelta, err := in.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			termState.PayStartFP += int64(payDelta)
+		}
+
+		if termState.TotalTermFreq > BLOCK_SIZE {
+			lastPosBlockOffset, err := in.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			termState.LastPosBlockOffset = int64(lastPosBlockOffset)
+		} else {
+			termState.LastPosBlockOffset = -1
+		}
+	}
+
+	if termState.DocFreq > BLOCK_SIZE {
+		skipOffset, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		termState.SkipOffset = int64(skipOffset)
+	} else {
+		termState.SkipOffset = -1
+	}
+	return nil
+}
+
+func (p *PostingsReader) Postings(ctx context.Context, fieldInfo *document.FieldInfo, state codecs.BlockTermState,
+	reuse index.PostingsEnum, flags int) (index.PostingsEnum, error) {
+
+	termState, ok := state.(*IntBlockTermState)
+	if !ok {
+		return nil, errors.New("state is not *IntBlockTermState")
+	}
+
+	indexHasPositions := fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS
+
+	if !indexHasPositions || !coreIndex.FeatureRequested(flags, coreIndex.POSTINGS_ENUM_POSITIONS) {
+		docsEnum, ok := reuse.(*blockDocsEnum)
+		if !ok || !docsEnum.canReuse(p.docIn, fieldInfo) {
+			docsEnum = p.newBlockDocsEnum(fieldInfo)
+		}
+		if err := docsEnum.reset(ctx, termState, flags); err != nil {
+			return nil, err
+		}
+		return docsEnum, nil
+	}
+
+	everythingEnum, ok := reuse.(*everythingEnum)
+	if !ok || !everythingEnum.canReuse(p.docIn, fieldInfo) {
+		everythingEnum = p.newEverythingEnum(fieldInfo)
+	}
+	if err := everythingEnum.reset(ctx, termState, flags); err != nil {
+		return nil, err
+	}
+	return everythingEnum, nil
+}
+
+func (p *PostingsReader) Impacts(ctx context.Context, fieldInfo *document.FieldInfo, state codecs.BlockTermState,
+	flags int) (index.ImpactsEnum, error) {
+
+	if state.GetBlockTermState().DocFreq <= BLOCK_SIZE {
+		// no skip data
+		postings, err := p.Postings(ctx, fieldInfo, state, nil, flags)
+		if err != nil {
+			return nil, err
+		}
+		return coreIndex.NewSlowImpactsEnum(postings), nil
+	}
+
+	postings, err := p.Postings(ctx, fieldInfo, state, nil, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	switch postings := postings.(type) {
+	case *blockDocsEnum:
+		if err := postings.initImpacts(ctx); err != nil {
+			return nil, err
+		}
+		return postings, nil
+	case *everythingEnum:
+		if err := postings.initImpacts(ctx); err != nil {
+			return nil, err
+		}
+		return postings, nil
+	default:
+		return coreIndex.NewSlowImpactsEnum(postings), nil
+	}
+}
+
+func (p *PostingsReader) CheckIntegrity(ctx context.Context) error {
+	for _, in := range []store.IndexInput{p.docIn, p.posIn, p.payIn} {
+		if in == nil {
+			continue
+		}
+		if _, err := utils.ChecksumEntireFile(ctx, in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readVIntBlock(ctx context.Context, docIn store.IndexInput, docBuffer, freqBuffer []uint64,
+	num int, indexHasFreq bool) error {
+
+	if !indexHasFreq {
+		for i := 0; i < num; i++ {
+			doc, err := docIn.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			docBuffer[i] = doc
+		}
+		return nil
+	}
+
+	for i := 0; i < num; i++ {
+		code, err := docIn.ReadUvarint(ctx)
+		if err != nil {
+			return err
+		}
+		docBuffer[i] = code >> 1
+		if code&1 != 0 {
+			freqBuffer[i] = 1
+		} else {
+			freq, err := docIn.ReadUvarint(ctx)
+			if err != nil {
+				return err
+			}
+			freqBuffer[i] = freq
+		}
+	}
+	return nil
+}
+
+func zigZagDecode(l uint64) int64 {
+	return int64(l>>1) ^ -int64(l&1)
+}
@@ -0,0 +1,694 @@
+package lucene84
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ codecs.PostingsWriterBase = &PostingsWriter{}
+
+// PostingsWriter
+// Concrete class that writes docId(maybe frq,pos,offset,payloads) list with postings format.
+// Postings list for each term will be stored separately.
+// See Also: SkipWriter for details about skipping setting and postings layout.
+type PostingsWriter struct {
+	docOut store.IndexOutput
+	posOut store.IndexOutput
+	payOut store.IndexOutput
+
+	emptyState *IntBlockTermState
+	lastState  *IntBlockTermState
+
+	// Holds starting file pointers for current term:
+	docStartFP int64
+	posStartFP int64
+	payStartFP int64
+
+	docDeltaBuffer []uint64
+	freqBuffer     []uint64
+	docBufferUpto  int
+
+	posDeltaBuffer         []uint64
+	payloadLengthBuffer    []uint64
+	offsetStartDeltaBuffer []uint64
+	offsetLengthBuffer     []uint64
+	posBufferUpto          int
+
+	payloadBytes    []byte
+	payloadByteUpto int
+
+	lastBlockDocID           int
+	lastBlockPosFP           int64
+	lastBlockPayFP           int64
+	lastBlockPosBufferUpto   int
+	lastBlockPayloadByteUpto int
+
+	lastDocID       int
+	lastPosition    int
+	lastStartOffset int
+	docCount        int
+
+	encoded []byte
+
+	forUtil    *ForUtil
+	skipWriter *SkipWriter
+
+	fieldInfo                      *document.FieldInfo
+	fieldHasNorms                  bool
+	norms                          index.NumericDocValues
+	competitiveFreqNormAccumulator *coreIndex.CompetitiveImpactAccumulator
+
+	// Current field's IndexOptions
+	writeFreqs     bool
+	writePositions bool
+	writePayloads  bool
+	writeOffsets   bool
+	enumFlags      int
+
+	postingsEnum index.PostingsEnum
+}
+
+// NewPostingsWriter Creates a postings writer
+func NewPostingsWriter(ctx context.Context, state *index.SegmentWriteState) (*PostingsWriter, error) {
+	maxDoc, err := state.SegmentInfo.MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	forUtil, err := NewForUtil()
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &PostingsWriter{
+		emptyState:                     NewIntBlockTermState(),
+		docDeltaBuffer:                 make([]uint64, maxDataSize),
+		freqBuffer:                     make([]uint64, maxDataSize),
+		encoded:                        make([]byte, maxEncodedSize()),
+		forUtil:                        forUtil,
+		competitiveFreqNormAccumulator: coreIndex.NewCompetitiveImpactAccumulator(),
+	}
+
+	docFileName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, DOC_EXTENSION)
+	writer.docOut, err = state.Directory.CreateOutput(ctx, docFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.openOutputs(ctx, state); err != nil {
+		_ = writer.closeOutputs()
+		return nil, err
+	}
+
+	// TODO: should we try skipping every 2/4 blocks...?
+	writer.skipWriter = NewSkipWriter(maxSkipLevels, BLOCK_SIZE, maxDoc,
+		writer.docOut, writer.posOut, writer.payOut)
+	return writer, nil
+}
+
+func (p *PostingsWriter) openOutputs(ctx context.Context, state *index.SegmentWriteState) error {
+	segmentID := state.SegmentInfo.GetID()
+
+	if err := utils.WriteIndexHeader(ctx, p.docOut, DOC_CODEC, VERSION_CURRENT, segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	if !state.FieldInfos.HasProx() {
+		return nil
+	}
+
+	p.posDeltaBuffer = make([]uint64, maxDataSize)
+	posFileName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, POS_EXTENSION)
+	posOut, err := state.Directory.CreateOutput(ctx, posFileName)
+	if err != nil {
+		return err
+	}
+	p.posOut = posOut
+	if err := utils.WriteIndexHeader(ctx, p.posOut, POS_CODEC, VERSION_CURRENT, segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	if state.FieldInfos.HasPayloads() {
+		p.payloadBytes = make([]byte, 128)
+		p.payloadLengthBuffer = make([]uint64, maxDataSize)
+	}
+
+	if state.FieldInfos.HasOffsets() {
+		p.offsetStartDeltaBuffer = make([]uint64, maxDataSize)
+		p.offsetLengthBuffer = make([]uint64, maxDataSize)
+	}
+
+	if state.FieldInfos.HasPayloads() || state.FieldInfos.HasOffsets() {
+		payFileName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, PAY_EXTENSION)
+		payOut, err := state.Directory.CreateOutput(ctx, payFileName)
+		if err != nil {
+			return err
+		}
+		p.payOut = payOut
+		if err := utils.WriteIndexHeader(ctx, p.payOut, PAY_CODEC, VERSION_CURRENT, segmentID, state.SegmentSuffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostingsWriter) Init(ctx context.Context, termsOut store.IndexOutput, state *index.SegmentWriteState) error {
+	if err := utils.WriteIndexHeader(ctx, termsOut, TERMS_CODEC, VERSION_CURRENT,
+		state.SegmentInfo.GetID(), state.SegmentSuffix); err != nil {
+		return err
+	}
+	return termsOut.WriteUvarint(ctx, BLOCK_SIZE)
+}
+
+func (p *PostingsWriter) SetField(fieldInfo *document.FieldInfo) {
+	indexOptions := fieldInfo.GetIndexOptions()
+	p.writeFreqs = indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS
+	p.writePositions = indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS
+	p.writeOffsets = indexOptions >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	p.writePayloads = fieldInfo.HasPayloads()
+
+	switch {
+	case !p.writeFreqs:
+		p.enumFlags = 0
+	case !p.writePositions:
+		p.enumFlags = coreIndex.POSTINGS_ENUM_FREQS
+	case !p.writeOffsets:
+		if p.writePayloads {
+			p.enumFlags = coreIndex.POSTINGS_ENUM_PAYLOADS
+		} else {
+			p.enumFlags = coreIndex.POSTINGS_ENUM_POSITIONS
+		}
+	default:
+		if p.writePayloads {
+			p.enumFlags = coreIndex.POSTINGS_ENUM_ALL
+		} else {
+			p.enumFlags = coreIndex.POSTINGS_ENUM_OFFSETS
+		}
+	}
+
+	p.skipWriter.SetField(p.writePositions, p.writeOffsets, p.writePayloads)
+	p.lastState = p.emptyState
+	p.fieldInfo = fieldInfo
+	p.fieldHasNorms = fieldInfo.HasNorms()
+}
+
+func (p *PostingsWriter) WriteTerm(ctx context.Context, term []byte, termsEnum index.TermsEnum,
+	docsSeen *bitset.BitSet, normsProducer index.NormsProducer) (codecs.BlockTermState, error) {
+
+	var norms index.NumericDocValues
+	if p.fieldHasNorms && normsProducer != nil {
+		values, err := normsProducer.GetNorms(p.fieldInfo)
+		if err != nil {
+			return nil, err
+		}
+		norms = values
+	}
+	return p.writeTerm(ctx, termsEnum, docsSeen, norms)
+}
+
+func (p *PostingsWriter) writeTerm(ctx context.Context, termsEnum index.TermsEnum,
+	docsSeen *bitset.BitSet, norms index.NumericDocValues) (codecs.BlockTermState, error) {
+
+	p.startTerm(norms)
+
+	postingsEnum, err := termsEnum.Postings(p.postingsEnum, p.enumFlags)
+	if err != nil {
+		return nil, err
+	}
+	p.postingsEnum = postingsEnum
+
+	docFreq := 0
+	totalTermFreq := int64(0)
+	for {
+		docID, err := postingsEnum.NextDoc(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if docID == types.NO_MORE_DOCS {
+			break
+		}
+		docFreq++
+		docsSeen.Set(uint(docID))
+
+		freq := -1
+		if p.writeFreqs {
+			freq, err = postingsEnum.Freq()
+			if err != nil {
+				return nil, err
+			}
+			totalTermFreq += int64(freq)
+		}
+
+		if err := p.startDoc(ctx, docID, freq); err != nil {
+			return nil, err
+		}
+
+		if p.writePositions {
+			for i := 0; i < freq; i++ {
+				pos, err := postingsEnum.NextPosition()
+				if err != nil {
+					return nil, err
+				}
+
+				var payload []byte
+				if p.writePayloads {
+					payload, err = postingsEnum.GetPayload()
+					if err != nil && !errors.Is(err, io.EOF) {
+						return nil, err
+					}
+				}
+
+				startOffset, endOffset := -1, -1
+				if p.writeOffsets {
+					startOffset, err = postingsEnum.StartOffset()
+					if err != nil {
+						return nil, err
+					}
+					endOffset, err = postingsEnum.EndOffset()
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				if err := p.addPosition(ctx, pos, payload, startOffset, endOffset); err != nil {
+					return nil, err
+				}
+			}
+		}
+		p.finishDoc()
+	}
+
+	if docFreq == 0 {
+		return nil, nil
+	}
+
+	state := NewIntBlockTermState()
+	state.DocFreq = docFreq
+	if p.writeFreqs {
+		state.TotalTermFreq = totalTermFreq
+	} else {
+		state.TotalTermFreq = -1
+	}
+	if err := p.finishTerm(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (p *PostingsWriter) startTerm(norms index.NumericDocValues) {
+	p.docStartFP = p.docOut.GetFilePointer()
+	if p.writePositions {
+		p.posStartFP = p.posOut.GetFilePointer()
+		if p.writePayloads || p.writeOffsets {
+			p.payStartFP = p.payOut.GetFilePointer()
+		}
+	}
+	p.lastDocID = 0
+	p.lastBlockDocID = -1
+	p.skipWriter.Reset()
+	p.norms = norms
+	p.competitiveFreqNormAccumulator.Clear()
+}
+
+func (p *PostingsWriter) startDoc(ctx context.Context, docID, termDocFreq int) error {
+	// Have collected a block of docs, and get a new doc.
+	// Should write skip data as well as postings list for
+	// current block.
+	if p.lastBlockDocID != -1 && p.docBufferUpto == 0 {
+		if err := p.skipWriter.BufferSkip(ctx, p.lastBlockDocID, p.competitiveFreqNormAccumulator, p.docCount,
+			p.lastBlockPosFP, p.lastBlockPayFP, p.lastBlockPosBufferUpto, p.lastBlockPayloadByteUpto); err != nil {
+			return err
+		}
+		p.competitiveFreqNormAccumulator.Clear()
+	}
+
+	docDelta := docID - p.lastDocID
+
+	if docID < 0 || (p.docCount > 0 && docDelta <= 0) {
+		return fmt.Errorf("docs out of order (%d <= %d )", docID, p.lastDocID)
+	}
+
+	p.docDeltaBuffer[p.docBufferUpto] = uint64(docDelta)
+	if p.writeFreqs {
+		p.freqBuffer[p.docBufferUpto] = uint64(termDocFreq)
+	}
+
+	p.docBufferUpto++
+	p.docCount++
+
+	if p.docBufferUpto == BLOCK_SIZE {
+		if err := p.forUtil.WriteBlock(ctx, p.docDeltaBuffer, p.encoded, p.docOut); err != nil {
+			return err
+		}
+		if p.writeFreqs {
+			if err := p.forUtil.WriteBlock(ctx, p.freqBuffer, p.encoded, p.docOut); err != nil {
+				return err
+			}
+		}
+		// NOTE: don't set docBufferUpto back to 0 here;
+		// finishDoc will do so (because it needs to see that
+		// the block was filled so it can save skip data)
+	}
+
+	p.lastDocID = docID
+	p.lastPosition = 0
+	p.lastStartOffset = 0
+
+	norm := int64(1)
+	if p.fieldHasNorms && p.norms != nil {
+		found, err := p.norms.AdvanceExact(docID)
+		if err != nil {
+			return err
+		}
+		if found {
+			norm, err = p.norms.LongValue()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.writeFreqs {
+		p.competitiveFreqNormAccumulator.Add(termDocFreq, norm)
+	} else {
+		p.competitiveFreqNormAccumulator.Add(1, norm)
+	}
+	return nil
+}
+
+func (p *PostingsWriter) addPosition(ctx context.Context, position int, payload []byte, startOffset, endOffset int) error {
+	if position > coreIndex.MAX_POSITION {
+		return fmt.Errorf("position=%d is too large (> IndexWriter.MAX_POSITION=%d)", position, coreIndex.MAX_POSITION)
+	}
+	if position < 0 {
+		return fmt.Errorf("position=%d is < 0", position)
+	}
+
+	p.posDeltaBuffer[p.posBufferUpto] = uint64(position - p.lastPosition)
+	if p.writePayloads {
+		if len(payload) == 0 {
+			// no payload
+			p.payloadLengthBuffer[p.posBufferUpto] = 0
+		} else {
+			p.payloadLengthBuffer[p.posBufferUpto] = uint64(len(payload))
+			p.payloadBytes = append(p.payloadBytes[:p.payloadByteUpto], payload...)
+			p.payloadByteUpto += len(payload)
+		}
+	}
+
+	if p.writeOffsets {
+		p.offsetStartDeltaBuffer[p.posBufferUpto] = uint64(startOffset - p.lastStartOffset)
+		p.offsetLengthBuffer[p.posBufferUpto] = uint64(endOffset - startOffset)
+		p.lastStartOffset = startOffset
+	}
+
+	p.posBufferUpto++
+	p.lastPosition = position
+	if p.posBufferUpto == BLOCK_SIZE {
+		if err := p.forUtil.WriteBlock(ctx, p.posDeltaBuffer, p.encoded, p.posOut); err != nil {
+			return err
+		}
+
+		if p.writePayloads {
+			if err := p.forUtil.WriteBlock(ctx, p.payloadLengthBuffer, p.encoded, p.payOut); err != nil {
+				return err
+			}
+			if err := p.payOut.WriteUvarint(ctx, uint64(p.payloadByteUpto)); err != nil {
+				return err
+			}
+			if _, err := p.payOut.Write(p.payloadBytes[:p.payloadByteUpto]); err != nil {
+				return err
+			}
+			p.payloadByteUpto = 0
+		}
+
+		if p.writeOffsets {
+			if err := p.forUtil.WriteBlock(ctx, p.offsetStartDeltaBuffer, p.encoded, p.payOut); err != nil {
+				return err
+			}
+			if err := p.forUtil.WriteBlock(ctx, p.offsetLengthBuffer, p.encoded, p.payOut); err != nil {
+				return err
+			}
+		}
+		p.posBufferUpto = 0
+	}
+	return nil
+}
+
+func (p *PostingsWriter) finishDoc() {
+	// Since we don't know df for current term, we had to buffer
+	// those skip data for each block, and when a new doc comes,
+	// write them to skip file.
+	if p.docBufferUpto == BLOCK_SIZE {
+		p.lastBlockDocID = p.lastDocID
+		if p.posOut != nil {
+			if p.payOut != nil {
+				p.lastBlockPayFP = p.payOut.GetFilePointer()
+			}
+			p.lastBlockPosFP = p.posOut.GetFilePointer()
+			p.lastBlockPosBufferUpto = p.posBufferUpto
+			p.lastBlockPayloadByteUpto = p.payloadByteUpto
+		}
+		p.docBufferUpto = 0
+	}
+}
+
+// Called when we are done adding docs to this term
+func (p *PostingsWriter) finishTerm(ctx context.Context, state *IntBlockTermState) error {
+	// docFreq == 1, don't write the single docid/freq to a separate file along with a pointer to it.
+	singletonDocID := -1
+	if state.DocFreq == 1 {
+		// pulse the singleton docid into the term dictionary, freq is implicitly totalTermFreq
+		singletonDocID = int(p.docDeltaBuffer[0])
+	} else {
+		// vInt encode the remaining doc deltas and freqs:
+		for i := 0; i < p.docBufferUpto; i++ {
+			docDelta := p.docDeltaBuffer[i]
+			freq := p.freqBuffer[i]
+			if !p.writeFreqs {
+				if err := p.docOut.WriteUvarint(ctx, docDelta); err != nil {
+					return err
+				}
+			} else if freq == 1 {
+				if err := p.docOut.WriteUvarint(ctx, (docDelta<<1)|1); err != nil {
+					return err
+				}
+			} else {
+				if err := p.docOut.WriteUvarint(ctx, docDelta<<1); err != nil {
+					return err
+				}
+				if err := p.docOut.WriteUvarint(ctx, freq); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	lastPosBlockOffset := int64(-1)
+	if p.writePositions {
+		// totalTermFreq is just total number of positions(or payloads, or offsets)
+		// associated with current term.
+		if state.TotalTermFreq > BLOCK_SIZE {
+			// record file offset for last pos in last block
+			lastPosBlockOffset = p.posOut.GetFilePointer() - p.posStartFP
+		}
+
+		if p.posBufferUpto > 0 {
+			if err := p.writeRemainingPositions(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	skipOffset := int64(-1)
+	if p.docCount > BLOCK_SIZE {
+		skipFP, err := p.skipWriter.WriteTo(ctx, p.docOut)
+		if err != nil {
+			return err
+		}
+		skipOffset = skipFP - p.docStartFP
+	}
+
+	state.DocStartFP = p.docStartFP
+	state.PosStartFP = p.posStartFP
+	state.PayStartFP = p.payStartFP
+	state.SingletonDocID = singletonDocID
+	state.SkipOffset = skipOffset
+	state.LastPosBlockOffset = lastPosBlockOffset
+	p.docBufferUpto = 0
+	p.posBufferUpto = 0
+	p.lastDocID = 0
+	p.docCount = 0
+	return nil
+}
+
+// vInt encode the remaining positions/payloads/offsets:
+func (p *PostingsWriter) writeRemainingPositions(ctx context.Context) error {
+	// TODO: should we send offsets/payloads to
+	// .pay...?  seems wasteful (have to store extra
+	// vLong for low (< BLOCK_SIZE) DF terms = vast vast
+	// majority)
+
+	lastPayloadLength := -1 // force first payload length to be written
+	lastOffsetLength := -1  // force first offset length to be written
+	payloadBytesReadUpto := 0
+	for i := 0; i < p.posBufferUpto; i++ {
+		posDelta := p.posDeltaBuffer[i]
+		if p.writePayloads {
+			payloadLength := int(p.payloadLengthBuffer[i])
+			if payloadLength != lastPayloadLength {
+				lastPayloadLength = payloadLength
+				if err := p.posOut.WriteUvarint(ctx, (posDelta<<1)|1); err != nil {
+					return err
+				}
+				if err := p.posOut.WriteUvarint(ctx, uint64(payloadLength)); err != nil {
+					return err
+				}
+			} else {
+				if err := p.posOut.WriteUvarint(ctx, posDelta<<1); err != nil {
+					return err
+				}
+			}
+
+			if payloadLength != 0 {
+				payload := p.payloadBytes[payloadBytesReadUpto : payloadBytesReadUpto+payloadLength]
+				if _, err := p.posOut.Write(payload); err != nil {
+					return err
+				}
+				payloadBytesReadUpto += payloadLength
+			}
+		} else {
+			if err := p.posOut.WriteUvarint(ctx, posDelta); err != nil {
+				return err
+			}
+		}
+
+		if p.writeOffsets {
+			delta := p.offsetStartDeltaBuffer[i]
+			length := int(p.offsetLengthBuffer[i])
+			if length == lastOffsetLength {
+				if err := p.posOut.WriteUvarint(ctx, delta<<1); err != nil {
+					return err
+				}
+			} else {
+				if err := p.posOut.WriteUvarint(ctx, delta<<1|1); err != nil {
+					return err
+				}
+				if err := p.posOut.WriteUvarint(ctx, uint64(length)); err != nil {
+					return err
+				}
+				lastOffsetLength = length
+			}
+		}
+	}
+
+	if p.writePayloads {
+		p.payloadByteUpto = 0
+	}
+	return nil
+}
+
+func (p *PostingsWriter) EncodeTerm(ctx context.Context, out store.DataOutput, fieldInfo *document.FieldInfo,
+	termState codecs.BlockTermState, absolute bool) error {
+
+	state, ok := termState.(*IntBlockTermState)
+	if !ok {
+		return errors.New("termState is not *IntBlockTermState")
+	}
+
+	if absolute {
+		p.lastState = p.emptyState
+	}
+
+	if p.lastState.SingletonDocID != -1 && state.SingletonDocID != -1 && state.DocStartFP == p.lastState.DocStartFP {
+		// With runs of rare values such as ID fields, the increment of pointers in the docs file is often 0.
+		// Furthermore some ID schemes like auto-increment IDs or Flake IDs are monotonic, so we encode the delta
+		// between consecutive doc IDs to save space.
+		delta := int64(state.SingletonDocID - p.lastState.SingletonDocID)
+		if err := out.WriteUvarint(ctx, (zigZagEncode(delta)<<1)|0x01); err != nil {
+			return err
+		}
+	} else {
+		if err := out.WriteUvarint(ctx, uint64(state.DocStartFP-p.lastState.DocStartFP)<<1); err != nil {
+			return err
+		}
+		if state.SingletonDocID != -1 {
+			if err := out.WriteUvarint(ctx, uint64(state.SingletonDocID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.writePositions {
+		if err := out.WriteUvarint(ctx, uint64(state.PosStartFP-p.lastState.PosStartFP)); err != nil {
+			return err
+		}
+		if p.writePayloads || p.writeOffsets {
+			if err := out.WriteUvarint(ctx, uint64(state.PayStartFP-p.lastState.PayStartFP)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.writePositions && state.LastPosBlockOffset != -1 {
+		if err := out.WriteUvarint(ctx, uint64(state.LastPosBlockOffset)); err != nil {
+			return err
+		}
+	}
+
+	if state.SkipOffset != -1 {
+		if err := out.WriteUvarint(ctx, uint64(state.SkipOffset)); err != nil {
+			return err
+		}
+	}
+	p.lastState = state
+	return nil
+}
+
+func (p *PostingsWriter) Close() error {
+	var err error
+	for _, out := range []store.IndexOutput{p.docOut, p.posOut, p.payOut} {
+		if out == nil {
+			continue
+		}
+		if footerErr := utils.WriteFooter(out); footerErr != nil && err == nil {
+			err = footerErr
+		}
+	}
+	if closeErr := p.closeOutputs(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (p *PostingsWriter) closeOutputs() error {
+	var err error
+	for _, out := range []store.IndexOutput{p.docOut, p.posOut, p.payOut} {
+		if out == nil {
+			continue
+		}
+		if closeErr := out.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	p.docOut, p.posOut, p.payOut = nil, nil, nil
+	return err
+}
+
+func zigZagEncode(i int64) uint64 {
+	return uint64((i >> 63) ^ (i << 1))
+}
@@ -0,0 +1,358 @@
+package lucene84
+
+import (
+	"context"
+	"io"
+	"math"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+var (
+	_ coreIndex.MultiLevelSkipListReaderSPI      = &SkipReader{}
+	_ coreIndex.MultiLevelSkipListReaderLevelSPI = &SkipReader{}
+)
+
+// SkipReader
+// Implements the skip list reader for block postings format that stores positions and payloads.
+//
+// Although this skipper uses MultiLevelSkipListReader as an interface, its definition of skip
+// position will be a little different.
+//
+// For example, when skipInterval = blockSize = 3, df = 2*skipInterval = 6,
+//
+//	0 1 2 3 4 5
+//	d d d d d d    (posting list)
+//	    ^     ^    (skip point in MultiLeveSkipWriter)
+//	      ^        (skip point in Lucene84SkipWriter)
+//
+// In this case, MultiLevelSkipListReader will use the last document as a skip point, while
+// SkipReader should assume no skip point will comes.
+//
+// If we use the interface directly in SkipReader, it may silly try to read another skip data
+// after the only skip point is loaded.
+//
+// To illustrate this, we can call skipTo(d[5]), since skip point d[3] has smaller docId, and
+// numSkipped+blockSize== df, the MultiLevelSkipListReader will assume the skip list isn't exhausted
+// yet, and try to load a non-existed skip point
+//
+// Therefore, we'll trim df before passing it to the interface. see trim(int)
+type SkipReader struct {
+	mtx *coreIndex.MultiLevelSkipListReaderContext
+
+	docPointer      []int64
+	posPointer      []int64
+	payPointer      []int64
+	posBufferUpto   []int
+	payloadByteUpto []int
+
+	lastPosPointer      int64
+	lastPayPointer      int64
+	lastPayloadByteUpto int
+	lastDocPointer      int64
+	lastPosBufferUpto   int
+
+	// readImpacts decodes the impacts of a skip entry, by default they are skipped.
+	readImpacts func(ctx context.Context, level int, skipStream store.IndexInput) error
+}
+
+func NewSkipReader(skipStream store.IndexInput, maxSkipLevels int,
+	hasPos, hasOffsets, hasPayloads bool) *SkipReader {
+
+	reader := &SkipReader{
+		mtx:        coreIndex.NewMultiLevelSkipListReaderContext(skipStream, maxSkipLevels, BLOCK_SIZE, skipMultiplier),
+		docPointer: make([]int64, maxSkipLevels),
+	}
+
+	if hasPos {
+		reader.posPointer = make([]int64, maxSkipLevels)
+		reader.posBufferUpto = make([]int, maxSkipLevels)
+		if hasPayloads {
+			reader.payloadByteUpto = make([]int, maxSkipLevels)
+		}
+		if hasOffsets || hasPayloads {
+			reader.payPointer = make([]int64, maxSkipLevels)
+		}
+	}
+	reader.readImpacts = reader.skipImpacts
+	return reader
+}
+
+// Trim original docFreq to tell skipReader read proper number of skip points.
+//
+// Since our definition in SkipReader is a little different from MultiLevelSkipListReader,
+// we should ignore the last skip point when docFreq is a multiple of BLOCK_SIZE.
+func trim(df int) int {
+	if df%BLOCK_SIZE == 0 {
+		return df - 1
+	}
+	return df
+}
+
+func (s *SkipReader) Init(ctx context.Context, skipPointer, docBasePointer, posBasePointer, payBasePointer int64, df int) error {
+	if err := s.mtx.Init(ctx, skipPointer, trim(df), s); err != nil {
+		return err
+	}
+	s.lastDocPointer = docBasePointer
+	s.lastPosPointer = posBasePointer
+	s.lastPayPointer = payBasePointer
+
+	fill(s.docPointer, docBasePointer)
+	if s.posPointer != nil {
+		fill(s.posPointer, posBasePointer)
+		if s.payPointer != nil {
+			fill(s.payPointer, payBasePointer)
+		}
+	}
+	return nil
+}
+
+// SkipTo Skips entries to the first beyond the current whose document number is greater
+// than or equal to target. Returns the current doc count.
+func (s *SkipReader) SkipTo(ctx context.Context, target int) (int, error) {
+	return s.mtx.SkipToWithSPI(ctx, target, s)
+}
+
+// GetDocPointer Returns the doc pointer of the doc to which the last call of SkipTo
+// has skipped.
+func (s *SkipReader) GetDocPointer() int64 {
+	return s.lastDocPointer
+}
+
+func (s *SkipReader) GetPosPointer() int64 {
+	return s.lastPosPointer
+}
+
+func (s *SkipReader) GetPosBufferUpto() int {
+	return s.lastPosBufferUpto
+}
+
+func (s *SkipReader) GetPayPointer() int64 {
+	return s.lastPayPointer
+}
+
+func (s *SkipReader) GetPayloadByteUpto() int {
+	return s.lastPayloadByteUpto
+}
+
+// GetDoc Returns the id of the doc to which the last call of SkipTo has skipped.
+func (s *SkipReader) GetDoc() int {
+	return s.mtx.GetDoc()
+}
+
+func (s *SkipReader) GetNextSkipDoc() int {
+	return s.mtx.GetSkipDoc(0)
+}
+
+func (s *SkipReader) SeekChild(level int) {
+	s.docPointer[level] = s.lastDocPointer
+	if s.posPointer != nil {
+		s.posPointer[level] = s.lastPosPointer
+		s.posBufferUpto[level] = s.lastPosBufferUpto
+		if s.payloadByteUpto != nil {
+			s.payloadByteUpto[level] = s.lastPayloadByteUpto
+		}
+		if s.payPointer != nil {
+			s.payPointer[level] = s.lastPayPointer
+		}
+	}
+}
+
+func (s *SkipReader) SetLastSkipData(level int) {
+	s.lastDocPointer = s.docPointer[level]
+
+	if s.posPointer != nil {
+		s.lastPosPointer = s.posPointer[level]
+		s.lastPosBufferUpto = s.posBufferUpto[level]
+		if s.payPointer != nil {
+			s.lastPayPointer = s.payPointer[level]
+		}
+		if s.payloadByteUpto != nil {
+			s.lastPayloadByteUpto = s.payloadByteUpto[level]
+		}
+	}
+}
+
+func (s *SkipReader) ReadSkipData(ctx context.Context, level int, skipStream store.IndexInput,
+	mtx *coreIndex.MultiLevelSkipListReaderContext) (int64, error) {
+
+	delta, err := skipStream.ReadUvarint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	docPointerDelta, err := skipStream.ReadUvarint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s.docPointer[level] += int64(docPointerDelta)
+
+	if s.posPointer != nil {
+		posPointerDelta, err := skipStream.ReadUvarint(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.posPointer[level] += int64(posPointerDelta)
+
+		posBufferUpto, err := skipStream.ReadUvarint(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.posBufferUpto[level] = int(posBufferUpto)
+
+		if s.payloadByteUpto != nil {
+			payloadByteUpto, err := skipStream.ReadUvarint(ctx)
+			if err != nil {
+				return 0, err
+			}
+			s.payloadByteUpto[level] = int(payloadByteUpto)
+		}
+
+		if s.payPointer != nil {
+			payPointerDelta, err := skipStream.ReadUvarint(ctx)
+			if err != nil {
+				return 0, err
+			}
+			s.payPointer[level] += int64(payPointerDelta)
+		}
+	}
+
+	if err := s.readImpacts(ctx, level, skipStream); err != nil {
+		return 0, err
+	}
+	return int64(delta), nil
+}
+
+// The base implementation skips impacts and moves to the next skip data.
+func (s *SkipReader) skipImpacts(ctx context.Context, level int, skipStream store.IndexInput) error {
+	length, err := skipStream.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	return skipStream.SkipBytes(ctx, int(length))
+}
+
+func (s *SkipReader) ReadLevelLength(ctx context.Context, skipStream store.IndexInput,
+	mtx *coreIndex.MultiLevelSkipListReaderContext) (int64, error) {
+
+	num, err := skipStream.ReadUvarint(ctx)
+	return int64(num), err
+}
+
+func (s *SkipReader) ReadChildPointer(ctx context.Context, skipStream store.IndexInput,
+	mtx *coreIndex.MultiLevelSkipListReaderContext) (int64, error) {
+
+	num, err := skipStream.ReadUvarint(ctx)
+	return int64(num), err
+}
+
+var _ index.Impacts = &ScoreSkipReader{}
+
+// ScoreSkipReader
+// SkipReader which also decodes the impacts of the skip entries, the impacts of a level
+// are only decoded when they are requested.
+type ScoreSkipReader struct {
+	*SkipReader
+
+	impactData       [][]byte
+	impactDataLength []int
+	numLevels        int
+	perLevelImpacts  [][]index.Impact
+}
+
+func NewScoreSkipReader(skipStream store.IndexInput, maxSkipLevels int,
+	hasPos, hasOffsets, hasPayloads bool) *ScoreSkipReader {
+
+	reader := &ScoreSkipReader{
+		SkipReader:       NewSkipReader(skipStream, maxSkipLevels, hasPos, hasOffsets, hasPayloads),
+		impactData:       make([][]byte, maxSkipLevels),
+		impactDataLength: make([]int, maxSkipLevels),
+		numLevels:        1,
+		perLevelImpacts:  make([][]index.Impact, maxSkipLevels),
+	}
+	for i := range reader.perLevelImpacts {
+		reader.perLevelImpacts[i] = []index.Impact{coreIndex.NewImpact(math.MaxInt32, 1)}
+	}
+	reader.readImpacts = reader.bufferImpacts
+	return reader
+}
+
+func (s *ScoreSkipReader) SkipTo(ctx context.Context, target int) (int, error) {
+	result, err := s.SkipReader.SkipTo(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+
+	if numberOfSkipLevels := s.mtx.NumberOfSkipLevels(); numberOfSkipLevels > 0 {
+		s.numLevels = numberOfSkipLevels
+	} else {
+		// End of postings don't have skip data anymore, so we fill with dummy data
+		// like SlowImpactsEnum.
+		s.numLevels = 1
+		s.perLevelImpacts[0] = append(s.perLevelImpacts[0][:0], coreIndex.NewImpact(math.MaxInt32, 1))
+		s.impactDataLength[0] = 0
+	}
+	return result, nil
+}
+
+func (s *ScoreSkipReader) bufferImpacts(ctx context.Context, level int, skipStream store.IndexInput) error {
+	length, err := skipStream.ReadUvarint(ctx)
+	if err != nil {
+		return err
+	}
+	if len(s.impactData[level]) < int(length) {
+		s.impactData[level] = make([]byte, length)
+	}
+	if _, err := io.ReadFull(skipStream, s.impactData[level][:length]); err != nil {
+		return err
+	}
+	s.impactDataLength[level] = int(length)
+	return nil
+}
+
+func (s *ScoreSkipReader) NumLevels() int {
+	return s.numLevels
+}
+
+func (s *ScoreSkipReader) GetDocIdUpTo(level int) int {
+	return s.mtx.GetSkipDoc(level)
+}
+
+func (s *ScoreSkipReader) GetImpacts(level int) []index.Impact {
+	if s.impactDataLength[level] > 0 {
+		impacts, err := readImpacts(s.impactData[level][:s.impactDataLength[level]], s.perLevelImpacts[level][:0])
+		if err == nil {
+			s.perLevelImpacts[level] = impacts
+		}
+		s.impactDataLength[level] = 0
+	}
+	return s.perLevelImpacts[level]
+}
+
+func readImpacts(data []byte, reuse []index.Impact) ([]index.Impact, error) {
+	in := store.NewBytesInput(data)
+	ctx := context.Background()
+
+	freq, norm := 0, int64(0)
+	for in.GetFilePointer() < in.Length() {
+		freqDelta, err := in.ReadUvarint(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if freqDelta&0x01 != 0 {
+			freq += 1 + int(freqDelta>>1)
+			normDelta, err := in.ReadZInt64(ctx)
+			if err != nil {
+				return nil, err
+			}
+			norm += 1 + normDelta
+		} else {
+			freq += 1 + int(freqDelta>>1)
+			norm++
+		}
+		reuse = append(reuse, coreIndex.NewImpact(freq, norm))
+	}
+	return reuse, nil
+}
@@ -0,0 +1,294 @@
+package lucene84
+
+import (
+	"context"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+var _ coreIndex.MultiLevelSkipListWriterSPI = &SkipWriter{}
+
+// SkipWriter
+// Write skip lists with multiple levels, and support skip within block ints.
+//
+// Assume that docFreq = 28, skipInterval = blockSize = 12
+//
+//	|       block#0       | |      block#1        | |vInts|
+//	d d d d d d d d d d d d d d d d d d d d d d d d d d d d (posting list)
+//	                        ^                       ^       (level 0 skip point)
+//
+// Note that skipWriter will ignore first document in block#0, since it is useless as a skip point.
+// Also, we'll never skip into the vInts block, only record skip data at the start its start point(if it exist).
+//
+// For each skip point, we will record:
+//  1. docID in former position, i.e. for position 12, record docID[11], etc.
+//  2. its related file points(position, payload),
+//  3. related numbers or uptos(position, payload).
+//  4. start offset.
+type SkipWriter struct {
+	mwc *coreIndex.MultiLevelSkipListWriterContext
+
+	lastSkipDoc         []int
+	lastSkipDocPointer  []int64
+	lastSkipPosPointer  []int64
+	lastSkipPayPointer  []int64
+	lastPayloadByteUpto []int
+
+	docOut store.IndexOutput
+	posOut store.IndexOutput
+	payOut store.IndexOutput
+
+	curDoc             int
+	curDocPointer      int64
+	curPosPointer      int64
+	curPayPointer      int64
+	curPosBufferUpto   int
+	curPayloadByteUpto int
+
+	curCompetitiveFreqNorms []*coreIndex.CompetitiveImpactAccumulator
+
+	fieldHasPositions bool
+	fieldHasOffsets   bool
+	fieldHasPayloads  bool
+
+	// tricky: we only skip data for blocks (terms with more than 128 docs), but re-init'ing the
+	// skipper is pretty slow for rare terms in large segments as we have to fill O(log #docs in
+	// segment) of junk. this is the vast majority of terms (worst case: ID field or similar). so
+	// in ResetSkip() we save away the previous pointers, and lazy-init only if we need to buffer
+	// skip data for the term.
+	initialized bool
+	lastDocFP   int64
+	lastPosFP   int64
+	lastPayFP   int64
+
+	freqNormOut *store.BufferOutput
+}
+
+func NewSkipWriter(maxSkipLevels, blockSize, docCount int,
+	docOut, posOut, payOut store.IndexOutput) *SkipWriter {
+
+	writer := &SkipWriter{
+		mwc:                     coreIndex.NewMultiLevelSkipListWriterContext(blockSize, skipMultiplier, maxSkipLevels, docCount),
+		lastSkipDoc:             make([]int, maxSkipLevels),
+		lastSkipDocPointer:      make([]int64, maxSkipLevels),
+		docOut:                  docOut,
+		posOut:                  posOut,
+		payOut:                  payOut,
+		curCompetitiveFreqNorms: make([]*coreIndex.CompetitiveImpactAccumulator, maxSkipLevels),
+		freqNormOut:             store.NewBufferDataOutput(),
+	}
+
+	if posOut != nil {
+		writer.lastSkipPosPointer = make([]int64, maxSkipLevels)
+		if payOut != nil {
+			writer.lastSkipPayPointer = make([]int64, maxSkipLevels)
+		}
+		writer.lastPayloadByteUpto = make([]int, maxSkipLevels)
+	}
+
+	for i := range writer.curCompetitiveFreqNorms {
+		writer.curCompetitiveFreqNorms[i] = coreIndex.NewCompetitiveImpactAccumulator()
+	}
+	return writer
+}
+
+func (s *SkipWriter) SetField(fieldHasPositions, fieldHasOffsets, fieldHasPayloads bool) {
+	s.fieldHasPositions = fieldHasPositions
+	s.fieldHasOffsets = fieldHasOffsets
+	s.fieldHasPayloads = fieldHasPayloads
+}
+
+// Reset
+// Remember the current file pointers, the skip buffers are only initialized once the
+// term buffers its first skip point.
+func (s *SkipWriter) Reset() {
+	s.lastDocFP = s.docOut.GetFilePointer()
+	if s.fieldHasPositions {
+		s.lastPosFP = s.posOut.GetFilePointer()
+		if s.fieldHasOffsets || s.fieldHasPayloads {
+			s.lastPayFP = s.payOut.GetFilePointer()
+		}
+	}
+	if s.initialized {
+		for _, acc := range s.curCompetitiveFreqNorms {
+			acc.Clear()
+		}
+	}
+	s.initialized = false
+}
+
+func (s *SkipWriter) ResetSkip(mwc *coreIndex.MultiLevelSkipListWriterContext) error {
+	mwc.ResetSkip()
+	return nil
+}
+
+func (s *SkipWriter) initSkip() error {
+	if s.initialized {
+		return nil
+	}
+
+	if err := s.ResetSkip(s.mwc); err != nil {
+		return err
+	}
+	clear(s.lastSkipDoc)
+	fill(s.lastSkipDocPointer, s.lastDocFP)
+	if s.fieldHasPositions {
+		fill(s.lastSkipPosPointer, s.lastPosFP)
+		if s.fieldHasPayloads {
+			clear(s.lastPayloadByteUpto)
+		}
+		if s.fieldHasOffsets || s.fieldHasPayloads {
+			fill(s.lastSkipPayPointer, s.lastPayFP)
+		}
+	}
+	// sets of competitive freq,norm pairs should be empty at this point
+	s.initialized = true
+	return nil
+}
+
+// BufferSkip Sets the values for the current skip data.
+func (s *SkipWriter) BufferSkip(ctx context.Context, doc int, competitiveFreqNorms *coreIndex.CompetitiveImpactAccumulator,
+	numDocs int, posFP, payFP int64, posBufferUpto, payloadByteUpto int) error {
+
+	if err := s.initSkip(); err != nil {
+		return err
+	}
+	s.curDoc = doc
+	s.curDocPointer = s.docOut.GetFilePointer()
+	s.curPosPointer = posFP
+	s.curPayPointer = payFP
+	s.curPosBufferUpto = posBufferUpto
+	s.curPayloadByteUpto = payloadByteUpto
+	s.curCompetitiveFreqNorms[0].AddAll(competitiveFreqNorms)
+	return s.mwc.BufferSkip(ctx, numDocs, s)
+}
+
+func (s *SkipWriter) WriteSkipData(ctx context.Context, level int, skipBuffer store.IndexOutput,
+	mwc *coreIndex.MultiLevelSkipListWriterContext) error {
+
+	delta := s.curDoc - s.lastSkipDoc[level]
+
+	if err := skipBuffer.WriteUvarint(ctx, uint64(delta)); err != nil {
+		return err
+	}
+	s.lastSkipDoc[level] = s.curDoc
+
+	if err := skipBuffer.WriteUvarint(ctx, uint64(s.curDocPointer-s.lastSkipDocPointer[level])); err != nil {
+		return err
+	}
+	s.lastSkipDocPointer[level] = s.curDocPointer
+
+	if s.fieldHasPositions {
+		if err := skipBuffer.WriteUvarint(ctx, uint64(s.curPosPointer-s.lastSkipPosPointer[level])); err != nil {
+			return err
+		}
+		s.lastSkipPosPointer[level] = s.curPosPointer
+		if err := skipBuffer.WriteUvarint(ctx, uint64(s.curPosBufferUpto)); err != nil {
+			return err
+		}
+
+		if s.fieldHasPayloads {
+			if err := skipBuffer.WriteUvarint(ctx, uint64(s.curPayloadByteUpto)); err != nil {
+				return err
+			}
+		}
+
+		if s.fieldHasOffsets || s.fieldHasPayloads {
+			if err := skipBuffer.WriteUvarint(ctx, uint64(s.curPayPointer-s.lastSkipPayPointer[level])); err != nil {
+				return err
+			}
+			s.lastSkipPayPointer[level] = s.curPayPointer
+		}
+	}
+
+	competitiveFreqNorms := s.curCompetitiveFreqNorms[level]
+	if level+1 < mwc.NumberOfSkipLevels {
+		s.curCompetitiveFreqNorms[level+1].AddAll(competitiveFreqNorms)
+	}
+	if err := writeImpacts(ctx, competitiveFreqNorms, s.freqNormOut); err != nil {
+		return err
+	}
+	if err := skipBuffer.WriteUvarint(ctx, uint64(s.freqNormOut.GetFilePointer())); err != nil {
+		return err
+	}
+	if err := s.freqNormOut.CopyTo(skipBuffer); err != nil {
+		return err
+	}
+	s.freqNormOut.Reset()
+	competitiveFreqNorms.Clear()
+	return nil
+}
+
+// writeImpacts the competitive (freq, norm) pairs are sorted by increasing freq and norm, only
+// the deltas minus one are written.
+func writeImpacts(ctx context.Context, acc *coreIndex.CompetitiveImpactAccumulator, out store.DataOutput) error {
+	impacts := acc.GetCompetitiveFreqNormPairs()
+	previousFreq, previousNorm := 0, int64(0)
+	for _, impact := range impacts {
+		freqDelta := impact.GetFreq() - previousFreq - 1
+		normDelta := impact.GetNorm() - previousNorm - 1
+		if normDelta == 0 {
+			// most of time, norm only increases by 1, so we can fold everything in a single byte
+			if err := out.WriteUvarint(ctx, uint64(freqDelta<<1)); err != nil {
+				return err
+			}
+		} else {
+			if err := out.WriteUvarint(ctx, uint64((freqDelta<<1)|1)); err != nil {
+				return err
+			}
+			if err := out.WriteZInt64(ctx, normDelta); err != nil {
+				return err
+			}
+		}
+		previousFreq, previousNorm = impact.GetFreq(), impact.GetNorm()
+	}
+	return nil
+}
+
+// WriteSkip
+// Writes the buffered skip lists to the given output, the higher levels are prefixed with
+// their length.
+func (s *SkipWriter) WriteSkip(ctx context.Context, output store.IndexOutput,
+	mwc *coreIndex.MultiLevelSkipListWriterContext) (int64, error) {
+
+	skipPointer := output.GetFilePointer()
+	if len(mwc.SkipBuffer) == 0 {
+		return skipPointer, nil
+	}
+
+	for level := mwc.NumberOfSkipLevels - 1; level > 0; level-- {
+		length := mwc.SkipBuffer[level].GetFilePointer()
+		if length > 0 {
+			if err := s.WriteLevelLength(ctx, length, output); err != nil {
+				return 0, err
+			}
+			if err := mwc.SkipBuffer[level].CopyTo(output); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := mwc.SkipBuffer[0].CopyTo(output); err != nil {
+		return 0, err
+	}
+	return skipPointer, nil
+}
+
+func (s *SkipWriter) WriteLevelLength(ctx context.Context, levelLength int64, output store.IndexOutput) error {
+	return output.WriteUvarint(ctx, uint64(levelLength))
+}
+
+func (s *SkipWriter) WriteChildPointer(ctx context.Context, childPointer int64, skipBuffer store.DataOutput) error {
+	return skipBuffer.WriteUvarint(ctx, uint64(childPointer))
+}
+
+// WriteTo writes the buffered skip data of the current term into output.
+func (s *SkipWriter) WriteTo(ctx context.Context, output store.IndexOutput) (int64, error) {
+	return s.WriteSkip(ctx, output, s.mwc)
+}
+
+func fill[T any](values []T, v T) {
+	for i := range values {
+		values[i] = v
+	}
+}
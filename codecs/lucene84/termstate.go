@@ -0,0 +1,63 @@
+package lucene84
+
+import (
+	"github.com/geange/lucene-go/codecs"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+var _ codecs.BlockTermState = &IntBlockTermState{}
+
+// IntBlockTermState
+// Holds all state required for PostingsReader to produce a PostingsEnum without re-seeking the
+// terms dict.
+type IntBlockTermState struct {
+	codecs.BaseBlockTermState
+
+	// file pointer to the start of the doc ids enumeration, in .doc file
+	DocStartFP int64
+
+	// file pointer to the start of the positions enumeration, in .pos file
+	PosStartFP int64
+
+	// file pointer to the start of the payloads enumeration, in .pay file
+	PayStartFP int64
+
+	// file offset for the start of the skip list, relative to docStartFP, if there are more
+	// than BLOCK_SIZE docs; otherwise -1
+	SkipOffset int64
+
+	// file offset for the last position in the last block, if there are more than BLOCK_SIZE
+	// positions; otherwise -1
+	LastPosBlockOffset int64
+
+	// docid when there is a single pulsed posting, otherwise -1. freq is always implicitly
+	// totalTermFreq in this case.
+	SingletonDocID int
+}
+
+func NewIntBlockTermState() *IntBlockTermState {
+	return &IntBlockTermState{
+		SkipOffset:         -1,
+		LastPosBlockOffset: -1,
+		SingletonDocID:     -1,
+	}
+}
+
+func (s *IntBlockTermState) Clone() codecs.BlockTermState {
+	other := NewIntBlockTermState()
+	other.CopyFrom(s)
+	return other
+}
+
+func (s *IntBlockTermState) CopyFrom(other index.TermState) {
+	s.BaseBlockTermState.CopyFrom(other)
+
+	if state, ok := other.(*IntBlockTermState); ok {
+		s.DocStartFP = state.DocStartFP
+		s.PosStartFP = state.PosStartFP
+		s.PayStartFP = state.PayStartFP
+		s.LastPosBlockOffset = state.LastPosBlockOffset
+		s.SkipOffset = state.SkipOffset
+		s.SingletonDocID = state.SingletonDocID
+	}
+}
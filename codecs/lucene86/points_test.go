@@ -0,0 +1,321 @@
+package lucene86_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/numeric"
+	"github.com/stretchr/testify/assert"
+)
+
+// more points than several leaves of 512 points
+const numDocs = 3000
+
+// docPoints Returns the 3 dimensional points of doc, from none to three per doc, with negative values
+// and many duplicates
+func docPoints(doc int) [][3]int32 {
+	points := make([][3]int32, 0)
+	for i := 0; i < doc%4; i++ {
+		points = append(points, [3]int32{
+			int32(doc%100 + i*13),
+			int32((doc*7)%1000 - 500),
+			int32(doc / 10),
+		})
+	}
+	return points
+}
+
+func docValue(doc int) int64 {
+	return int64(doc-numDocs/2) * 1_000_000_007
+}
+
+type box struct {
+	min, max [3]int32
+}
+
+func (b box) contains(point [3]int32) bool {
+	for dim := range point {
+		if point[dim] < b.min[dim] || point[dim] > b.max[dim] {
+			return false
+		}
+	}
+	return true
+}
+
+func pack(values ...int32) []byte {
+	packed := make([]byte, 4*len(values))
+	for i, value := range values {
+		numeric.IntToSortableBytes(value, packed[4*i:])
+	}
+	return packed
+}
+
+func unpack(packed []byte) [3]int32 {
+	var point [3]int32
+	for dim := range point {
+		point[dim] = numeric.SortableBytesToInt(packed[4*dim:])
+	}
+	return point
+}
+
+// boxVisitor Collects the docs with a point in b, and counts the visited points
+type boxVisitor struct {
+	b      box
+	min    []byte
+	max    []byte
+	docs   map[int]struct{}
+	points int
+}
+
+func newBoxVisitor(b box) *boxVisitor {
+	return &boxVisitor{
+		b:    b,
+		min:  pack(b.min[:]...),
+		max:  pack(b.max[:]...),
+		docs: make(map[int]struct{}),
+	}
+}
+
+func (v *boxVisitor) Visit(ctx context.Context, docID int) error {
+	v.docs[docID] = struct{}{}
+	v.points++
+	return nil
+}
+
+func (v *boxVisitor) VisitLeaf(ctx context.Context, docID int, packedValue []byte) error {
+	if v.b.contains(unpack(packedValue)) {
+		return v.Visit(ctx, docID)
+	}
+	return nil
+}
+
+func (v *boxVisitor) Compare(minPackedValue, maxPackedValue []byte) types.Relation {
+	crosses := false
+	for dim := 0; dim < 3; dim++ {
+		from, to := 4*dim, 4*dim+4
+		if bytes.Compare(minPackedValue[from:to], v.max[from:to]) > 0 ||
+			bytes.Compare(maxPackedValue[from:to], v.min[from:to]) < 0 {
+			return types.CELL_OUTSIDE_QUERY
+		}
+		if bytes.Compare(minPackedValue[from:to], v.min[from:to]) < 0 ||
+			bytes.Compare(maxPackedValue[from:to], v.max[from:to]) > 0 {
+			crosses = true
+		}
+	}
+	if crosses {
+		return types.CELL_CROSSES_QUERY
+	}
+	return types.CELL_INSIDE_QUERY
+}
+
+func (v *boxVisitor) Grow(count int) {}
+
+var boxes = []box{
+	// every point
+	{[3]int32{math.MinInt32, math.MinInt32, math.MinInt32}, [3]int32{math.MaxInt32, math.MaxInt32, math.MaxInt32}},
+	// no point
+	{[3]int32{200, math.MinInt32, math.MinInt32}, [3]int32{300, math.MaxInt32, math.MaxInt32}},
+	{[3]int32{0, 0, 0}, [3]int32{50, 499, 299}},
+	{[3]int32{10, -500, 100}, [3]int32{10, 500, 200}},
+	{[3]int32{20, -100, 0}, [3]int32{80, 100, 299}},
+	{[3]int32{38, 5, 281}, [3]int32{38, 5, 281}},
+}
+
+// newTestWriter Indexes numDocs docs with the Lucene86 points format of lucene87, committing a segment
+// every numDocs/segments docs
+func newTestWriter(t *testing.T, segments int) (*coreIndex.IndexWriter, store.Directory) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = dir.Close() })
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity)
+	config.SetMergePolicy(coreIndex.NewTieredMergePolicy())
+	config.SetMergeScheduler(coreIndex.NewSerialMergeScheduler())
+	writer, err := coreIndex.NewIndexWriter(ctx, dir, config)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = writer.Rollback(ctx) })
+
+	for i := 0; i < numDocs; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewStringField("id", fmt.Sprint(i), true))
+		for _, point := range docPoints(i) {
+			doc.Add(document.NewIntPoint("xyz", point[:]...))
+		}
+		if i%5 != 0 {
+			doc.Add(document.NewLongPoint("value", docValue(i)))
+		}
+		_, err := writer.AddDocument(ctx, doc)
+		assert.Nil(t, err)
+		if (i+1)%(numDocs/segments) == 0 {
+			assert.Nil(t, writer.Commit(ctx))
+		}
+	}
+	return writer, dir
+}
+
+func openLeafReader(t *testing.T, dir store.Directory) index.LeafReader {
+	reader, err := coreIndex.OpenDirectoryReader(context.Background(), dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	assert.Len(t, leaves, 1)
+	return leaves[0].LeafReader()
+}
+
+// idOf Returns the doc the test indexed as docID
+func idOf(t *testing.T, reader index.LeafReader, docID int) int {
+	doc, err := reader.Document(context.Background(), docID)
+	assert.Nil(t, err)
+	field, ok := doc.GetField("id")
+	assert.True(t, ok)
+	var id int
+	_, err = fmt.Sscan(field.Get().(string), &id)
+	assert.Nil(t, err)
+	return id
+}
+
+// assertPoints Checks the values of the fields against the indexed points
+func assertPoints(t *testing.T, reader index.LeafReader) {
+	ctx := context.Background()
+	values, ok := reader.GetPointValues("xyz")
+	assert.True(t, ok)
+
+	numDims, err := values.GetNumDimensions()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, numDims)
+	numIndexDims, err := values.GetNumIndexDimensions()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, numIndexDims)
+	bytesPerDim, err := values.GetBytesPerDimension()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, bytesPerDim)
+
+	size, docCount := 0, 0
+	minPoint := [3]int32{math.MaxInt32, math.MaxInt32, math.MaxInt32}
+	maxPoint := [3]int32{math.MinInt32, math.MinInt32, math.MinInt32}
+	for doc := 0; doc < numDocs; doc++ {
+		points := docPoints(doc)
+		size += len(points)
+		if len(points) > 0 {
+			docCount++
+		}
+		for _, point := range points {
+			for dim := range point {
+				minPoint[dim] = min(minPoint[dim], point[dim])
+				maxPoint[dim] = max(maxPoint[dim], point[dim])
+			}
+		}
+	}
+	assert.Equal(t, size, values.Size())
+	assert.Equal(t, docCount, values.GetDocCount())
+	minPacked, err := values.GetMinPackedValue()
+	assert.Nil(t, err)
+	assert.Equal(t, minPoint, unpack(minPacked))
+	maxPacked, err := values.GetMaxPackedValue()
+	assert.Nil(t, err)
+	assert.Equal(t, maxPoint, unpack(maxPacked))
+
+	ids := make([]int, reader.MaxDoc())
+	for docID := range ids {
+		ids[docID] = idOf(t, reader, docID)
+	}
+
+	for _, b := range boxes {
+		expectedDocs := make(map[int]struct{})
+		expectedPoints := 0
+		for doc := 0; doc < numDocs; doc++ {
+			for _, point := range docPoints(doc) {
+				if b.contains(point) {
+					expectedDocs[doc] = struct{}{}
+					expectedPoints++
+				}
+			}
+		}
+
+		visitor := newBoxVisitor(b)
+		assert.Nil(t, values.Intersect(ctx, visitor))
+		docs := make(map[int]struct{}, len(visitor.docs))
+		for docID := range visitor.docs {
+			docs[ids[docID]] = struct{}{}
+		}
+		assert.Equal(t, expectedDocs, docs, "%v", b)
+		assert.Equal(t, expectedPoints, visitor.points, "%v", b)
+
+		estimate, err := values.EstimatePointCount(ctx, newBoxVisitor(b))
+		assert.Nil(t, err)
+		// cells inside the box count as full leaves, leaves crossing it as half full leaves, even when
+		// none of their points matches
+		switch b {
+		case boxes[0]:
+			assert.GreaterOrEqual(t, estimate, size, "%v", b)
+		case boxes[1]:
+			assert.Equal(t, 0, estimate, "%v", b)
+		default:
+			if expectedPoints > 0 {
+				assert.Greater(t, estimate, 0, "%v", b)
+			}
+			assert.Less(t, estimate, size, "%v", b)
+		}
+
+		estimate, err = values.EstimateDocCount(ctx, newBoxVisitor(b))
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, estimate, docCount, "%v", b)
+		if expectedPoints == size {
+			assert.Equal(t, docCount, estimate, "%v", b)
+		}
+	}
+
+	// one dimension, one value per doc
+	values, ok = reader.GetPointValues("value")
+	assert.True(t, ok)
+	visited := make(map[int]int64)
+	visitor := &types.BytesVisitor{
+		VisitFn: func(docID int) error {
+			return fmt.Errorf("doc %d visited without its value", docID)
+		},
+		VisitLeafFn: func(ctx context.Context, docID int, packedValue []byte) error {
+			visited[ids[docID]] = numeric.SortableBytesToLong(packedValue)
+			return nil
+		},
+		CompareFn: func(minPackedValue, maxPackedValue []byte) types.Relation {
+			return types.CELL_CROSSES_QUERY
+		},
+		GrowFn: func(count int) {},
+	}
+	assert.Nil(t, values.Intersect(ctx, visitor))
+	assert.Equal(t, numDocs-numDocs/5, values.Size())
+	assert.Equal(t, numDocs-numDocs/5, len(visited))
+	for doc, value := range visited {
+		assert.NotZero(t, doc%5)
+		assert.Equal(t, docValue(doc), value)
+	}
+}
+
+func TestPoints_RoundTrip(t *testing.T) {
+	writer, dir := newTestWriter(t, 1)
+	assert.Nil(t, writer.Commit(context.Background()))
+	assertPoints(t, openLeafReader(t, dir))
+}
+
+func TestPoints_Merge(t *testing.T) {
+	ctx := context.Background()
+	writer, dir := newTestWriter(t, 3)
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+	assert.Nil(t, writer.Commit(ctx))
+	assertPoints(t, openLeafReader(t, dir))
+}
@@ -0,0 +1,61 @@
+package lucene86
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/bkd"
+)
+
+const (
+	// DATA_CODEC_NAME Codec name for the data file.
+	DATA_CODEC_NAME = "Lucene86PointsFormatData"
+
+	// INDEX_CODEC_NAME Codec name for the index file.
+	INDEX_CODEC_NAME = "Lucene86PointsFormatIndex"
+
+	// META_CODEC_NAME Codec name for the meta file.
+	META_CODEC_NAME = "Lucene86PointsFormatMeta"
+
+	// DATA_EXTENSION Filename extension for the leaf blocks
+	DATA_EXTENSION = "kdd"
+
+	// INDEX_EXTENSION Filename extension for the index per field
+	INDEX_EXTENSION = "kdi"
+
+	// META_EXTENSION Filename extension for the meta per field
+	META_EXTENSION = "kdm"
+
+	VERSION_START   = 0
+	VERSION_CURRENT = VERSION_START
+)
+
+var _ index.PointsFormat = &PointsFormat{}
+
+// PointsFormat Lucene 8.6 point format, which encodes dimensional values in a block KD-tree
+// structure for fast 1D range and N dimensional shape intersection filtering.
+// See this paper for details.
+//
+// Data is stored across three files
+//   - A .kdm file that records metadata about the fields, such as numbers of dimensions or
+//     numbers of bytes per dimension.
+//   - A .kdi file that stores inner nodes of the tree.
+//   - A .kdd file that stores leaf nodes, where most of the data lives.
+//
+// See bkd.Writer and bkd.Reader for the layout of each field's tree.
+// lucene.experimental
+type PointsFormat struct {
+}
+
+// NewPointsFormat Sole constructor
+func NewPointsFormat() *PointsFormat {
+	return &PointsFormat{}
+}
+
+func (p *PointsFormat) FieldsWriter(ctx context.Context, state *index.SegmentWriteState) (index.PointsWriter, error) {
+	return NewPointsWriter(ctx, state, bkd.DEFAULT_MAX_POINTS_IN_LEAF_NODE, bkd.DEFAULT_MAX_MB_SORT_IN_HEAP)
+}
+
+func (p *PointsFormat) FieldsReader(ctx context.Context, state *index.SegmentReadState) (index.PointsReader, error) {
+	return NewPointsReader(ctx, state)
+}
@@ -0,0 +1,174 @@
+package lucene86
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/bkd"
+)
+
+var _ index.PointsReader = &PointsReader{}
+
+// PointsReader Reads point values previously written with PointsWriter
+type PointsReader struct {
+	indexIn, dataIn store.IndexInput
+	readState       *index.SegmentReadState
+	readers         map[int]*bkd.Reader
+}
+
+// NewPointsReader Sole constructor
+func NewPointsReader(ctx context.Context, readState *index.SegmentReadState) (*PointsReader, error) {
+	reader := &PointsReader{
+		readState: readState,
+		readers:   make(map[int]*bkd.Reader),
+	}
+
+	if err := reader.open(ctx); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (p *PointsReader) open(ctx context.Context) error {
+	state := p.readState
+	segmentID := state.SegmentInfo.GetID()
+
+	var err error
+	indexName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, INDEX_EXTENSION)
+	if p.indexIn, err = state.Directory.OpenInput(ctx, indexName); err != nil {
+		return err
+	}
+	if _, err := utils.CheckIndexHeader(ctx, p.indexIn, INDEX_CODEC_NAME, VERSION_START, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	dataName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, DATA_EXTENSION)
+	if p.dataIn, err = state.Directory.OpenInput(ctx, dataName); err != nil {
+		return err
+	}
+	if _, err := utils.CheckIndexHeader(ctx, p.dataIn, DATA_CODEC_NAME, VERSION_START, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+
+	metaName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, META_EXTENSION)
+	metaIn, err := store.OpenChecksumInput(ctx, state.Directory, metaName)
+	if err != nil {
+		return err
+	}
+	defer metaIn.Close()
+
+	if _, err := utils.CheckIndexHeader(ctx, metaIn, META_CODEC_NAME, VERSION_START, VERSION_CURRENT,
+		segmentID, state.SegmentSuffix); err != nil {
+		return err
+	}
+	if err := p.readFields(ctx, metaIn); err != nil {
+		return err
+	}
+	indexLength, err := metaIn.ReadUint64(ctx)
+	if err != nil {
+		return err
+	}
+	dataLength, err := metaIn.ReadUint64(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := utils.CheckCodecFooter(ctx, metaIn); err != nil {
+		return err
+	}
+
+	// At this point, checksums of the meta file have been validated so we
+	// know that indexLength and dataLength are very likely correct.
+	if err := checkLength(indexName, p.indexIn, indexLength); err != nil {
+		return err
+	}
+	if err := checkLength(dataName, p.dataIn, dataLength); err != nil {
+		return err
+	}
+	if _, err := utils.RetrieveChecksum(ctx, p.indexIn); err != nil {
+		return err
+	}
+	_, err = utils.RetrieveChecksum(ctx, p.dataIn)
+	return err
+}
+
+func (p *PointsReader) readFields(ctx context.Context, metaIn store.IndexInput) error {
+	for {
+		fieldNumber, err := metaIn.ReadUint32(ctx)
+		if err != nil {
+			return err
+		}
+		if fieldNumber == math.MaxUint32 {
+			return nil
+		}
+
+		if p.readState.FieldInfos.FieldInfoByNumber(int(fieldNumber)) == nil {
+			return fmt.Errorf("invalid field number: %d", fieldNumber)
+		}
+
+		reader, err := bkd.NewReader(ctx, metaIn, p.indexIn, p.dataIn)
+		if err != nil {
+			return err
+		}
+		p.readers[int(fieldNumber)] = reader
+	}
+}
+
+func checkLength(fileName string, in store.IndexInput, expected uint64) error {
+	if length := uint64(in.Length()); length != expected {
+		return fmt.Errorf("truncated file %s: expected length %d but got %d", fileName, expected, length)
+	}
+	return nil
+}
+
+// GetValues Returns the underlying bkd.Reader.
+func (p *PointsReader) GetValues(ctx context.Context, field string) (types.PointValues, error) {
+	fieldInfo := p.readState.FieldInfos.FieldInfo(field)
+	if fieldInfo == nil {
+		return nil, fmt.Errorf("field=%s is unrecognized", field)
+	}
+	if fieldInfo.GetPointDimensionCount() == 0 {
+		return nil, fmt.Errorf("field=%s did not index points", field)
+	}
+
+	// the field may have no points in this segment, e.g. after all docs holding them were deleted
+	reader, ok := p.readers[fieldInfo.Number()]
+	if !ok {
+		return nil, nil
+	}
+	return reader, nil
+}
+
+func (p *PointsReader) CheckIntegrity() error {
+	ctx := context.Background()
+	if _, err := utils.ChecksumEntireFile(ctx, p.indexIn.Clone().(store.IndexInput)); err != nil {
+		return err
+	}
+	_, err := utils.ChecksumEntireFile(ctx, p.dataIn.Clone().(store.IndexInput))
+	return err
+}
+
+func (p *PointsReader) GetMergeInstance() index.PointsReader {
+	return p
+}
+
+func (p *PointsReader) Close() error {
+	var errs []error
+	for _, in := range []store.IndexInput{p.indexIn, p.dataIn} {
+		if in != nil {
+			errs = append(errs, in.Close())
+		}
+	}
+	p.indexIn, p.dataIn = nil, nil
+	// Free up heap:
+	clear(p.readers)
+	return errors.Join(errs...)
+}
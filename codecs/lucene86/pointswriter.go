@@ -0,0 +1,200 @@
+package lucene86
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/geange/lucene-go/codecs/utils"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/bkd"
+)
+
+var _ index.PointsWriter = &PointsWriter{}
+
+// PointsWriter Writes dimensional values
+type PointsWriter struct {
+	*coreIndex.BasePointsWriter
+
+	// Outputs used to write the BKD tree data files.
+	metaOut, indexOut, dataOut store.IndexOutput
+
+	writeState          *index.SegmentWriteState
+	maxPointsInLeafNode int
+	maxMBSortInHeap     float64
+	finished            bool
+}
+
+// NewPointsWriter Full constructor
+func NewPointsWriter(ctx context.Context, writeState *index.SegmentWriteState,
+	maxPointsInLeafNode int, maxMBSortInHeap float64) (*PointsWriter, error) {
+
+	writer := &PointsWriter{
+		writeState:          writeState,
+		maxPointsInLeafNode: maxPointsInLeafNode,
+		maxMBSortInHeap:     maxMBSortInHeap,
+	}
+	writer.BasePointsWriter = &coreIndex.BasePointsWriter{
+		WriteField: writer.WriteField,
+		Finish:     writer.Finish,
+	}
+
+	if err := writer.openOutputs(ctx); err != nil {
+		_ = writer.closeOutputs()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (p *PointsWriter) openOutputs(ctx context.Context) error {
+	state := p.writeState
+	segmentID := state.SegmentInfo.GetID()
+
+	outputs := []struct {
+		out       *store.IndexOutput
+		codec     string
+		extension string
+	}{
+		{&p.dataOut, DATA_CODEC_NAME, DATA_EXTENSION},
+		{&p.indexOut, INDEX_CODEC_NAME, INDEX_EXTENSION},
+		{&p.metaOut, META_CODEC_NAME, META_EXTENSION},
+	}
+
+	for _, output := range outputs {
+		fileName := store.SegmentFileName(state.SegmentInfo.Name(), state.SegmentSuffix, output.extension)
+		out, err := state.Directory.CreateOutput(ctx, fileName)
+		if err != nil {
+			return err
+		}
+		*output.out = out
+		if err := utils.WriteIndexHeader(ctx, out, output.codec, VERSION_CURRENT,
+			segmentID, state.SegmentSuffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PointsWriter) WriteField(ctx context.Context, fieldInfo *document.FieldInfo, reader index.PointsReader) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	values, err := reader.GetValues(ctx, fieldInfo.Name())
+	if err != nil {
+		return err
+	}
+
+	config, err := bkd.NewConfig(
+		fieldInfo.GetPointDimensionCount(),
+		fieldInfo.GetPointIndexDimensionCount(),
+		fieldInfo.GetPointNumBytes(),
+		p.maxPointsInLeafNode,
+	)
+	if err != nil {
+		return err
+	}
+
+	maxDoc, err := p.writeState.SegmentInfo.MaxDoc()
+	if err != nil {
+		return err
+	}
+
+	writer, err := bkd.NewWriter(maxDoc,
+		p.writeState.Directory,
+		p.writeState.SegmentInfo.Name(),
+		config,
+		p.maxMBSortInHeap,
+		values.Size())
+	if err != nil {
+		return err
+	}
+
+	if err := p.writeTree(ctx, fieldInfo, writer, values); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (p *PointsWriter) writeTree(ctx context.Context, fieldInfo *document.FieldInfo,
+	writer *bkd.Writer, values types.PointValues) error {
+
+	pointCount := 0
+	if err := values.Intersect(ctx, &types.BytesVisitor{
+		VisitFn: func(docID int) error {
+			return errors.New("illegal State")
+		},
+		VisitLeafFn: func(ctx context.Context, docID int, packedValue []byte) error {
+			pointCount++
+			return writer.Add(ctx, packedValue, docID)
+		},
+		CompareFn: func(minPackedValue, maxPackedValue []byte) types.Relation {
+			return types.CELL_CROSSES_QUERY
+		},
+		GrowFn: func(count int) {
+		},
+	}); err != nil {
+		return err
+	}
+
+	// We could have 0 points on merge since all docs with points may be deleted:
+	if pointCount == 0 {
+		return nil
+	}
+
+	finalizer, err := writer.Finish(ctx, p.metaOut, p.indexOut, p.dataOut)
+	if err != nil {
+		return err
+	}
+	if err := p.metaOut.WriteUint32(ctx, uint32(fieldInfo.Number())); err != nil {
+		return err
+	}
+	return finalizer(ctx)
+}
+
+func (p *PointsWriter) Finish() error {
+	if p.finished {
+		return errors.New("already finished")
+	}
+	p.finished = true
+
+	ctx := context.Background()
+
+	// write EOF marker
+	if err := p.metaOut.WriteUint32(ctx, math.MaxUint32); err != nil {
+		return err
+	}
+	if err := utils.WriteFooter(p.indexOut); err != nil {
+		return err
+	}
+	if err := utils.WriteFooter(p.dataOut); err != nil {
+		return err
+	}
+	if err := p.metaOut.WriteUint64(ctx, uint64(p.indexOut.GetFilePointer())); err != nil {
+		return err
+	}
+	if err := p.metaOut.WriteUint64(ctx, uint64(p.dataOut.GetFilePointer())); err != nil {
+		return err
+	}
+	return utils.WriteFooter(p.metaOut)
+}
+
+func (p *PointsWriter) Close() error {
+	return p.closeOutputs()
+}
+
+func (p *PointsWriter) closeOutputs() error {
+	var errs []error
+	for _, out := range []store.IndexOutput{p.metaOut, p.indexOut, p.dataOut} {
+		if out != nil {
+			errs = append(errs, out.Close())
+		}
+	}
+	p.metaOut, p.indexOut, p.dataOut = nil, nil, nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package lucene87
+
+import (
+	"github.com/geange/lucene-go/codecs/lucene80"
+	"github.com/geange/lucene-go/codecs/lucene84"
+	"github.com/geange/lucene-go/codecs/lucene86"
+	"github.com/geange/lucene-go/codecs/perfield"
+	"github.com/geange/lucene-go/codecs/simpletext"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+func init() {
+	coreIndex.RegisterCodec(NewCodec())
+}
+
+var _ index.Codec = &Codec{}
+
+// Codec Implements the Lucene 8.7 index format.
+//
+// Postings are written with the binary Lucene84 postings format and its block tree terms
+// dictionary, stored fields are compressed in chunks, doc values use the Lucene80 format and
+// points are indexed in the block KD-trees of the Lucene86 points format.
+// Formats which don't have a binary implementation yet fall back to their SimpleText
+// counterparts.
+//
+// Postings and doc values are written through per-field formats, use SetPostingsFormatForField
+// and SetDocValuesFormatForField to pick another format for some of the fields.
+// lucene.experimental
+type Codec struct {
+	postingsFormat     index.PostingsFormat
+	storedFieldsFormat index.StoredFieldsFormat
+	segmentInfosFormat index.SegmentInfoFormat
+	fieldInfosFormat   index.FieldInfosFormat
+	vectorsFormat      index.TermVectorsFormat
+	normsFormat        index.NormsFormat
+	liveDocsFormat     index.LiveDocsFormat
+	docValuesFormat    index.DocValuesFormat
+	compoundFormat     index.CompoundFormat
+	pointsFormat       index.PointsFormat
+
+	defaultPostingsFormat   index.PostingsFormat
+	defaultDVFormat         index.DocValuesFormat
+	postingsFormatForField  func(field string) index.PostingsFormat
+	docValuesFormatForField func(field string) index.DocValuesFormat
+}
+
+// NewCodec Instantiates a new codec.
+func NewCodec() *Codec {
+	return NewCodecWithMode(BEST_SPEED)
+}
+
+// NewCodecWithMode Instantiates a new codec, specifying the stored fields compression mode to use.
+func NewCodecWithMode(mode StoredFieldsMode) *Codec {
+	codec := &Codec{
+		storedFieldsFormat: NewStoredFieldsFormat(mode),
+		segmentInfosFormat: simpletext.NewSegmentInfoFormat(),
+		fieldInfosFormat:   simpletext.NewSimpleTextFieldInfosFormat(),
+		vectorsFormat:      simpletext.NewTermVectorsFormat(),
+		normsFormat:        simpletext.NewNormsFormat(),
+		liveDocsFormat:     simpletext.NewLiveDocsFormat(),
+		compoundFormat:     simpletext.NewCompoundFormat(),
+		pointsFormat:       lucene86.NewPointsFormat(),
+
+		defaultPostingsFormat: lucene84.NewPostingsFormat(),
+		defaultDVFormat:       lucene80.NewDocValuesFormat(),
+	}
+	codec.postingsFormat = perfield.NewPostingsFormat(codec.GetPostingsFormatForField)
+	codec.docValuesFormat = perfield.NewDocValuesFormat(codec.GetDocValuesFormatForField)
+	return codec
+}
+
+// GetPostingsFormatForField Returns the postings format that should be used for writing new segments of field.
+// The default implementation always returns "Lucene84".
+func (c *Codec) GetPostingsFormatForField(field string) index.PostingsFormat {
+	if c.postingsFormatForField != nil {
+		if format := c.postingsFormatForField(field); format != nil {
+			return format
+		}
+	}
+	return c.defaultPostingsFormat
+}
+
+// SetPostingsFormatForField Sets the function choosing the postings format of each field,
+// fields it returns nil for keep the default format.
+// The formats have to be registered with index.RegisterPostingsFormat so the segments can be read back.
+func (c *Codec) SetPostingsFormatForField(fn func(field string) index.PostingsFormat) {
+	c.postingsFormatForField = fn
+}
+
+// GetDocValuesFormatForField Returns the docvalues format that should be used for writing new segments of field.
+// The default implementation always returns "Lucene80".
+func (c *Codec) GetDocValuesFormatForField(field string) index.DocValuesFormat {
+	if c.docValuesFormatForField != nil {
+		if format := c.docValuesFormatForField(field); format != nil {
+			return format
+		}
+	}
+	return c.defaultDVFormat
+}
+
+// SetDocValuesFormatForField Sets the function choosing the doc values format of each field,
+// fields it returns nil for keep the default format.
+// The formats have to be registered with index.RegisterDocValuesFormat so the segments can be read back.
+func (c *Codec) SetDocValuesFormatForField(fn func(field string) index.DocValuesFormat) {
+	c.docValuesFormatForField = fn
+}
+
+func (c *Codec) GetName() string {
+	return "Lucene87"
+}
+
+func (c *Codec) PostingsFormat() index.PostingsFormat {
+	return c.postingsFormat
+}
+
+func (c *Codec) DocValuesFormat() index.DocValuesFormat {
+	return c.docValuesFormat
+}
+
+func (c *Codec) StoredFieldsFormat() index.StoredFieldsFormat {
+	return c.storedFieldsFormat
+}
+
+func (c *Codec) TermVectorsFormat() index.TermVectorsFormat {
+	return c.vectorsFormat
+}
+
+func (c *Codec) FieldInfosFormat() index.FieldInfosFormat {
+	return c.fieldInfosFormat
+}
+
+func (c *Codec) SegmentInfoFormat() index.SegmentInfoFormat {
+	return c.segmentInfosFormat
+}
+
+func (c *Codec) NormsFormat() index.NormsFormat {
+	return c.normsFormat
+}
+
+func (c *Codec) LiveDocsFormat() index.LiveDocsFormat {
+	return c.liveDocsFormat
+}
+
+func (c *Codec) CompoundFormat() index.CompoundFormat {
+	return c.compoundFormat
+}
+
+func (c *Codec) PointsFormat() index.PointsFormat {
+	return c.pointsFormat
+}
@@ -0,0 +1,131 @@
+package lucene87
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/codecs/compressing"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+// StoredFieldsMode Configuration option for stored fields.
+type StoredFieldsMode int
+
+const (
+	// BEST_SPEED Trade compression ratio for retrieval speed.
+	BEST_SPEED = StoredFieldsMode(iota)
+
+	// BEST_COMPRESSION Trade retrieval speed for compression ratio.
+	BEST_COMPRESSION
+)
+
+func (m StoredFieldsMode) String() string {
+	switch m {
+	case BEST_SPEED:
+		return "BEST_SPEED"
+	case BEST_COMPRESSION:
+		return "BEST_COMPRESSION"
+	default:
+		return fmt.Sprintf("StoredFieldsMode(%d)", int(m))
+	}
+}
+
+func parseStoredFieldsMode(value string) (StoredFieldsMode, error) {
+	switch value {
+	case BEST_SPEED.String():
+		return BEST_SPEED, nil
+	case BEST_COMPRESSION.String():
+		return BEST_COMPRESSION, nil
+	default:
+		return 0, fmt.Errorf("unknown stored fields mode: %s", value)
+	}
+}
+
+const (
+	// MODE_KEY Attribute key for compression mode.
+	MODE_KEY = "Lucene87StoredFieldsFormat.mode"
+
+	bestSpeedBlockLength       = 60 * 1024
+	bestSpeedMaxDocs           = 1024
+	bestCompressionBlockLength = 10 * 48 * 1024
+	bestCompressionMaxDocs     = 4096
+	storedFieldsIndexBlockSize = 1 << 10
+)
+
+var _ index.StoredFieldsFormat = &StoredFieldsFormat{}
+
+// StoredFieldsFormat
+// Lucene 8.7 stored fields format.
+//
+// # Principle
+//
+// This StoredFieldsFormat compresses blocks of documents in order to improve the compression
+// ratio compared to document-level compression. It uses the LZ4 compression algorithm by
+// default in 60KB blocks and at most 1024 documents per block, which is fast to compress and
+// very fast to decompress data. Although the default compression method (BEST_SPEED) focuses
+// more on speed than on compression ratio, it should provide interesting compression ratios
+// for redundant inputs (such as log files, HTML or plain text). For higher compression, you
+// can choose (BEST_COMPRESSION), which uses the DEFLATE algorithm with 480KB blocks and at
+// most 4096 documents per block.
+//
+// The selected mode is recorded in the segment attributes, so that a reader always decodes a
+// segment with the mode it was written with.
+//
+// # File formats
+//
+// Stored fields are represented by three files: the data file (.fdt), the chunk index (.fdx)
+// and the metadata file (.fdm), see compressing.StoredFieldsWriter.
+// lucene.experimental
+type StoredFieldsFormat struct {
+	mode StoredFieldsMode
+}
+
+// NewStoredFieldsFormat Instantiates a new StoredFieldsFormat with the specified compression mode.
+func NewStoredFieldsFormat(mode StoredFieldsMode) *StoredFieldsFormat {
+	return &StoredFieldsFormat{mode: mode}
+}
+
+func (s *StoredFieldsFormat) FieldsReader(ctx context.Context, directory store.Directory, si index.SegmentInfo, fn index.FieldInfos, ioContext *store.IOContext) (index.StoredFieldsReader, error) {
+	value, ok := si.GetAttributes()[MODE_KEY]
+	if !ok {
+		return nil, fmt.Errorf("missing value for %s for segment: %s", MODE_KEY, si.Name())
+	}
+	mode, err := parseStoredFieldsMode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := s.impl(mode)
+	if err != nil {
+		return nil, err
+	}
+	return format.FieldsReader(ctx, directory, si, fn, ioContext)
+}
+
+func (s *StoredFieldsFormat) FieldsWriter(ctx context.Context, directory store.Directory, si index.SegmentInfo, ioContext *store.IOContext) (index.StoredFieldsWriter, error) {
+	previous := si.PutAttribute(MODE_KEY, s.mode.String())
+	if previous != "" && previous != s.mode.String() {
+		return nil, fmt.Errorf("found existing value for %s for segment: %s old=%s, new=%s",
+			MODE_KEY, si.Name(), previous, s.mode)
+	}
+
+	format, err := s.impl(s.mode)
+	if err != nil {
+		return nil, err
+	}
+	return format.FieldsWriter(ctx, directory, si, ioContext)
+}
+
+func (s *StoredFieldsFormat) impl(mode StoredFieldsMode) (*compressing.StoredFieldsFormat, error) {
+	switch mode {
+	case BEST_SPEED:
+		return compressing.NewStoredFieldsFormat("Lucene87StoredFieldsFastData", "", compressing.FAST,
+			bestSpeedBlockLength, bestSpeedMaxDocs, storedFieldsIndexBlockSize)
+	case BEST_COMPRESSION:
+		return compressing.NewStoredFieldsFormat("Lucene87StoredFieldsHighData", "", compressing.HIGH_COMPRESSION,
+			bestCompressionBlockLength, bestCompressionMaxDocs, storedFieldsIndexBlockSize)
+	default:
+		return nil, fmt.Errorf("unknown stored fields mode: %s", mode)
+	}
+}
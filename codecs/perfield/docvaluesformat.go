@@ -0,0 +1,338 @@
+package perfield
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+const (
+	// PER_FIELD_DOC_VALUES_NAME Name of this DocValuesFormat.
+	PER_FIELD_DOC_VALUES_NAME = "PerFieldDV40"
+
+	// PER_FIELD_DOC_VALUES_FORMAT_KEY FieldInfo attribute name used to store the format name for each field.
+	PER_FIELD_DOC_VALUES_FORMAT_KEY = "PerFieldDocValuesFormat.format"
+
+	// PER_FIELD_DOC_VALUES_SUFFIX_KEY FieldInfo attribute name used to store the segment suffix name for each field.
+	PER_FIELD_DOC_VALUES_SUFFIX_KEY = "PerFieldDocValuesFormat.suffix"
+)
+
+var _ index.DocValuesFormat = &DocValuesFormat{}
+
+// DocValuesFormat Enables per field docvalues support.
+// Note, when extending this class, the name (GetName) is written into the index. In order for the field
+// to be read, the name of every wrapped format must resolve to the format implementation via
+// index.GetDocValuesFormatByName, so each format has to be registered with index.RegisterDocValuesFormat.
+//
+// Files written by each docvalues format have an additional suffix containing the format name. For example,
+// in a per-field configuration instead of _1.dvd filenames would look like _1_Lucene80_0.dvd.
+// lucene.experimental
+type DocValuesFormat struct {
+	docValuesFormatForField func(field string) index.DocValuesFormat
+}
+
+// NewDocValuesFormat Sole constructor. docValuesFormatForField returns the doc values format that
+// should be used for writing new segments of the field.
+func NewDocValuesFormat(docValuesFormatForField func(field string) index.DocValuesFormat) *DocValuesFormat {
+	return &DocValuesFormat{docValuesFormatForField: docValuesFormatForField}
+}
+
+func (d *DocValuesFormat) GetName() string {
+	return PER_FIELD_DOC_VALUES_NAME
+}
+
+func (d *DocValuesFormat) FieldsConsumer(ctx context.Context, state *index.SegmentWriteState) (index.DocValuesConsumer, error) {
+	return &docValuesWriter{
+		format:     d,
+		writeState: state,
+		formats:    make(map[index.DocValuesFormat]*consumerAndSuffix),
+		suffixes:   make(map[string]int),
+	}, nil
+}
+
+func (d *DocValuesFormat) FieldsProducer(ctx context.Context, state *index.SegmentReadState) (index.DocValuesProducer, error) {
+	return newDocValuesReader(ctx, state)
+}
+
+func getDocValuesFullSegmentSuffix(outerSegmentSuffix, segmentSuffix string) string {
+	if outerSegmentSuffix == "" {
+		return segmentSuffix
+	}
+	// doc values updates are written with the generation as the outer suffix
+	return outerSegmentSuffix + "_" + segmentSuffix
+}
+
+type consumerAndSuffix struct {
+	consumer index.DocValuesConsumer
+	suffix   int
+}
+
+var _ index.DocValuesConsumer = &docValuesWriter{}
+
+type docValuesWriter struct {
+	format     *DocValuesFormat
+	writeState *index.SegmentWriteState
+	formats    map[index.DocValuesFormat]*consumerAndSuffix
+	suffixes   map[string]int
+}
+
+func (d *docValuesWriter) AddNumericField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	consumer, err := d.getInstance(ctx, field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddNumericField(ctx, field, valuesProducer)
+}
+
+func (d *docValuesWriter) AddBinaryField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	consumer, err := d.getInstance(ctx, field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddBinaryField(ctx, field, valuesProducer)
+}
+
+func (d *docValuesWriter) AddSortedField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	consumer, err := d.getInstance(ctx, field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddSortedField(ctx, field, valuesProducer)
+}
+
+func (d *docValuesWriter) AddSortedNumericField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	consumer, err := d.getInstance(ctx, field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddSortedNumericField(ctx, field, valuesProducer)
+}
+
+func (d *docValuesWriter) AddSortedSetField(ctx context.Context, field *document.FieldInfo, valuesProducer index.DocValuesProducer) error {
+	consumer, err := d.getInstance(ctx, field)
+	if err != nil {
+		return err
+	}
+	return consumer.AddSortedSetField(ctx, field, valuesProducer)
+}
+
+func (d *docValuesWriter) getInstance(ctx context.Context, field *document.FieldInfo) (index.DocValuesConsumer, error) {
+	var format index.DocValuesFormat
+	if field.GetDocValuesGen() != -1 {
+		// the field is being updated, so keep writing it with the format it was first written with
+		formatName := field.GetAttribute(PER_FIELD_DOC_VALUES_FORMAT_KEY)
+		if formatName != "" {
+			var ok bool
+			format, ok = coreIndex.GetDocValuesFormatByName(formatName)
+			if !ok {
+				return nil, fmt.Errorf("doc values format %s of field %s is not registered", formatName, field.Name())
+			}
+		}
+	}
+	if format == nil {
+		format = d.format.docValuesFormatForField(field.Name())
+	}
+	if format == nil {
+		return nil, fmt.Errorf("invalid nil DocValuesFormat for field=\"%s\"", field.Name())
+	}
+	formatName := format.GetName()
+
+	field.PutAttribute(PER_FIELD_DOC_VALUES_FORMAT_KEY, formatName)
+
+	consumer, ok := d.formats[format]
+	if !ok {
+		// First time we are seeing this format; create a new instance
+
+		suffix := -1
+		if field.GetDocValuesGen() != -1 {
+			// even when dvGen is != -1, it can still be a new field, that never
+			// existed in the segment, and therefore doesn't have the recorded
+			// attributes yet.
+			if suffixAtt := field.GetAttribute(PER_FIELD_DOC_VALUES_SUFFIX_KEY); suffixAtt != "" {
+				value, err := strconv.Atoi(suffixAtt)
+				if err != nil {
+					return nil, err
+				}
+				suffix = value
+			}
+		}
+
+		if suffix == -1 {
+			// bump the suffix
+			suffix = 0
+			if last, ok := d.suffixes[formatName]; ok {
+				suffix = last + 1
+			}
+		}
+		d.suffixes[formatName] = suffix
+
+		state := *d.writeState
+		state.SegmentSuffix = getDocValuesFullSegmentSuffix(d.writeState.SegmentSuffix,
+			getSuffix(formatName, strconv.Itoa(suffix)))
+		fieldsConsumer, err := format.FieldsConsumer(ctx, &state)
+		if err != nil {
+			return nil, err
+		}
+		consumer = &consumerAndSuffix{
+			consumer: fieldsConsumer,
+			suffix:   suffix,
+		}
+		d.formats[format] = consumer
+	}
+
+	field.PutAttribute(PER_FIELD_DOC_VALUES_SUFFIX_KEY, strconv.Itoa(consumer.suffix))
+	return consumer.consumer, nil
+}
+
+func (d *docValuesWriter) Close() error {
+	errs := make([]error, 0, len(d.formats))
+	for _, consumer := range d.formats {
+		errs = append(errs, consumer.consumer.Close())
+	}
+	clear(d.formats)
+	return errors.Join(errs...)
+}
+
+var _ index.DocValuesProducer = &docValuesReader{}
+
+type docValuesReader struct {
+	fields  map[string]index.DocValuesProducer
+	formats map[string]index.DocValuesProducer
+}
+
+func newDocValuesReader(ctx context.Context, readState *index.SegmentReadState) (*docValuesReader, error) {
+	reader := &docValuesReader{
+		fields:  make(map[string]index.DocValuesProducer),
+		formats: make(map[string]index.DocValuesProducer),
+	}
+
+	if err := reader.open(ctx, readState); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (d *docValuesReader) open(ctx context.Context, readState *index.SegmentReadState) error {
+	// Read field name -> format name
+	for _, fi := range readState.FieldInfos.List() {
+		if fi.GetDocValuesType() == document.DOC_VALUES_TYPE_NONE {
+			continue
+		}
+
+		fieldName := fi.Name()
+		formatName := fi.GetAttribute(PER_FIELD_DOC_VALUES_FORMAT_KEY)
+		if formatName == "" {
+			// null formatName means the field is in fieldInfos, but has no docvalues!
+			continue
+		}
+
+		suffix := fi.GetAttribute(PER_FIELD_DOC_VALUES_SUFFIX_KEY)
+		if suffix == "" {
+			return fmt.Errorf("missing attribute: %s for field: %s", PER_FIELD_DOC_VALUES_SUFFIX_KEY, fieldName)
+		}
+
+		format, ok := coreIndex.GetDocValuesFormatByName(formatName)
+		if !ok {
+			return fmt.Errorf("doc values format %s of field %s is not registered", formatName, fieldName)
+		}
+
+		segmentSuffix := getDocValuesFullSegmentSuffix(readState.SegmentSuffix, getSuffix(formatName, suffix))
+		producer, ok := d.formats[segmentSuffix]
+		if !ok {
+			state := *readState
+			state.SegmentSuffix = segmentSuffix
+
+			var err error
+			producer, err = format.FieldsProducer(ctx, &state)
+			if err != nil {
+				return err
+			}
+			d.formats[segmentSuffix] = producer
+		}
+		d.fields[fieldName] = producer
+	}
+	return nil
+}
+
+func (d *docValuesReader) GetNumeric(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
+	producer, ok := d.fields[field.Name()]
+	if !ok {
+		return nil, nil
+	}
+	return producer.GetNumeric(ctx, field)
+}
+
+func (d *docValuesReader) GetBinary(ctx context.Context, field *document.FieldInfo) (index.BinaryDocValues, error) {
+	producer, ok := d.fields[field.Name()]
+	if !ok {
+		return nil, nil
+	}
+	return producer.GetBinary(ctx, field)
+}
+
+func (d *docValuesReader) GetSorted(ctx context.Context, field *document.FieldInfo) (index.SortedDocValues, error) {
+	producer, ok := d.fields[field.Name()]
+	if !ok {
+		return nil, nil
+	}
+	return producer.GetSorted(ctx, field)
+}
+
+func (d *docValuesReader) GetSortedNumeric(ctx context.Context, field *document.FieldInfo) (index.SortedNumericDocValues, error) {
+	producer, ok := d.fields[field.Name()]
+	if !ok {
+		return nil, nil
+	}
+	return producer.GetSortedNumeric(ctx, field)
+}
+
+func (d *docValuesReader) GetSortedSet(ctx context.Context, field *document.FieldInfo) (index.SortedSetDocValues, error) {
+	producer, ok := d.fields[field.Name()]
+	if !ok {
+		return nil, nil
+	}
+	return producer.GetSortedSet(ctx, field)
+}
+
+func (d *docValuesReader) CheckIntegrity() error {
+	for _, producer := range d.formats {
+		if err := producer.CheckIntegrity(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *docValuesReader) GetMergeInstance() index.DocValuesProducer {
+	reader := &docValuesReader{
+		fields:  make(map[string]index.DocValuesProducer, len(d.fields)),
+		formats: make(map[string]index.DocValuesProducer, len(d.formats)),
+	}
+
+	mergeInstances := make(map[index.DocValuesProducer]index.DocValuesProducer, len(d.formats))
+	for suffix, producer := range d.formats {
+		mergeInstance := producer.GetMergeInstance()
+		mergeInstances[producer] = mergeInstance
+		reader.formats[suffix] = mergeInstance
+	}
+	for field, producer := range d.fields {
+		reader.fields[field] = mergeInstances[producer]
+	}
+	return reader
+}
+
+func (d *docValuesReader) Close() error {
+	errs := make([]error, 0, len(d.formats))
+	for _, producer := range d.formats {
+		errs = append(errs, producer.Close())
+	}
+	clear(d.formats)
+	clear(d.fields)
+	return errors.Join(errs...)
+}
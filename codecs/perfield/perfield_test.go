@@ -0,0 +1,139 @@
+package perfield_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/codecs/perfield"
+	"github.com/geange/lucene-go/codecs/simpletext"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerFieldFormats(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	// title and score use SimpleText, the other fields the lucene87 defaults
+	codec := lucene87.NewCodec()
+	codec.SetPostingsFormatForField(func(field string) index.PostingsFormat {
+		if field == "title" {
+			return simpletext.NewPostingsFormat()
+		}
+		return nil
+	})
+	codec.SetDocValuesFormatForField(func(field string) index.DocValuesFormat {
+		if field == "score" {
+			return simpletext.NewSimpleTextDocValuesFormat()
+		}
+		return nil
+	})
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(codec, similarity)
+	// keep the files of each format visible in the directory
+	config.SetUseCompoundFile(false)
+	writer, err := coreIndex.NewIndexWriter(ctx, dir, config)
+	assert.Nil(t, err)
+
+	numDocs := 10
+	for i := 0; i < numDocs; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewTextField("body", fmt.Sprintf("body%d common", i), false))
+		doc.Add(document.NewTextField("title", fmt.Sprintf("title%d common", i), false))
+		doc.Add(document.NewNumericDocValuesField("rank", int64(i)))
+		doc.Add(document.NewNumericDocValuesField("score", int64(100+i)))
+		_, err := writer.AddDocument(ctx, doc)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Commit(ctx))
+	assert.Nil(t, writer.Close())
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	assert.Len(t, leaves, 1)
+	leafReader := leaves[0].LeafReader()
+
+	// the format of each field is recorded in its FieldInfo
+	fieldInfos := leafReader.GetFieldInfos()
+	postingsFormats := map[string]string{"body": "Lucene84", "title": "SimpleText"}
+	for field, formatName := range postingsFormats {
+		fieldInfo := fieldInfos.FieldInfo(field)
+		assert.Equal(t, formatName, fieldInfo.GetAttribute(perfield.PER_FIELD_POSTINGS_FORMAT_KEY), field)
+		assert.Equal(t, "0", fieldInfo.GetAttribute(perfield.PER_FIELD_POSTINGS_SUFFIX_KEY), field)
+	}
+	docValuesFormats := map[string]string{"rank": "Lucene80", "score": "SimpleText"}
+	for field, formatName := range docValuesFormats {
+		fieldInfo := fieldInfos.FieldInfo(field)
+		assert.Equal(t, formatName, fieldInfo.GetAttribute(perfield.PER_FIELD_DOC_VALUES_FORMAT_KEY), field)
+		assert.Equal(t, "0", fieldInfo.GetAttribute(perfield.PER_FIELD_DOC_VALUES_SUFFIX_KEY), field)
+	}
+
+	// each format wrote its own files, named after the format and the suffix
+	files, err := dir.ListAll(ctx)
+	assert.Nil(t, err)
+	for _, name := range []string{"_0_Lucene84_0.tim", "_0_SimpleText_0.pst", "_0_Lucene80_0.dvd", "_0_SimpleText_0.dat"} {
+		assert.Contains(t, files, name)
+	}
+
+	// reads are routed to the format the field was written with
+	for field, prefix := range map[string]string{"body": "body", "title": "title"} {
+		terms, err := leafReader.Terms(field)
+		assert.Nil(t, err)
+		termsEnum, err := terms.Iterator()
+		assert.Nil(t, err)
+		found, err := termsEnum.SeekExact(ctx, []byte(prefix+"3"))
+		assert.Nil(t, err)
+		assert.True(t, found, field)
+		docFreq, err := termsEnum.DocFreq()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, docFreq)
+
+		// the terms of a field are not visible through the other format
+		found, err = termsEnum.SeekExact(ctx, []byte(postingsOtherPrefix(prefix)+"3"))
+		assert.Nil(t, err)
+		assert.False(t, found, field)
+	}
+
+	for field, offset := range map[string]int64{"rank": 0, "score": 100} {
+		values, err := leafReader.GetNumericDocValues(field)
+		assert.Nil(t, err)
+		for i := 0; i < numDocs; i++ {
+			doc, err := values.NextDoc(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, i, doc)
+			value, err := values.LongValue()
+			assert.Nil(t, err)
+			assert.Equal(t, offset+int64(i), value, field)
+		}
+		// exhausted iterators may also report io.EOF
+		doc, err := values.NextDoc(ctx)
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF, field)
+		}
+		assert.Equal(t, types.NO_MORE_DOCS, doc)
+	}
+}
+
+func postingsOtherPrefix(prefix string) string {
+	if prefix == "body" {
+		return "title"
+	}
+	return "body"
+}
@@ -0,0 +1,342 @@
+package perfield
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+const (
+	// PER_FIELD_POSTINGS_NAME Name of this PostingsFormat.
+	PER_FIELD_POSTINGS_NAME = "PerField40"
+
+	// PER_FIELD_POSTINGS_FORMAT_KEY FieldInfo attribute name used to store the format name for each field.
+	PER_FIELD_POSTINGS_FORMAT_KEY = "PerFieldPostingsFormat.format"
+
+	// PER_FIELD_POSTINGS_SUFFIX_KEY FieldInfo attribute name used to store the segment suffix name for each field.
+	PER_FIELD_POSTINGS_SUFFIX_KEY = "PerFieldPostingsFormat.suffix"
+)
+
+var _ index.PostingsFormat = &PostingsFormat{}
+
+// PostingsFormat Enables per field postings support.
+// Note, when extending this class, the name (GetName) is written into the index. In order for the field
+// to be read, the name of every wrapped format must resolve to the format implementation via
+// index.GetPostingsFormatByName, so each format has to be registered with index.RegisterPostingsFormat.
+//
+// Files written by each postings format have an additional suffix containing the format name. For example,
+// in a per-field configuration instead of _1.doc filenames would look like _1_Lucene84_0.doc.
+// lucene.experimental
+type PostingsFormat struct {
+	postingsFormatForField func(field string) index.PostingsFormat
+}
+
+// NewPostingsFormat Sole constructor. postingsFormatForField returns the postings format that
+// should be used for writing new segments of the field.
+func NewPostingsFormat(postingsFormatForField func(field string) index.PostingsFormat) *PostingsFormat {
+	return &PostingsFormat{postingsFormatForField: postingsFormatForField}
+}
+
+func (p *PostingsFormat) GetName() string {
+	return PER_FIELD_POSTINGS_NAME
+}
+
+func (p *PostingsFormat) FieldsConsumer(ctx context.Context, state *index.SegmentWriteState) (index.FieldsConsumer, error) {
+	return &fieldsWriter{
+		format:     p,
+		writeState: state,
+	}, nil
+}
+
+func (p *PostingsFormat) FieldsProducer(ctx context.Context, state *index.SegmentReadState) (index.FieldsProducer, error) {
+	return newFieldsReader(ctx, state)
+}
+
+func getSuffix(formatName, suffix string) string {
+	return formatName + "_" + suffix
+}
+
+func getFullSegmentSuffix(fieldName, outerSegmentSuffix, segmentSuffix string) (string, error) {
+	if outerSegmentSuffix == "" {
+		return segmentSuffix, nil
+	}
+	return "", fmt.Errorf("cannot embed PerFieldPostingsFormat inside itself (field \"%s\" returned PerFieldPostingsFormat)", fieldName)
+}
+
+// fieldsGroup the fields of a segment that are written with the same postings format
+type fieldsGroup struct {
+	format index.PostingsFormat
+	fields []string
+	suffix int
+	state  *index.SegmentWriteState
+}
+
+var _ index.FieldsConsumer = &fieldsWriter{}
+
+type fieldsWriter struct {
+	format     *PostingsFormat
+	writeState *index.SegmentWriteState
+	toClose    []index.FieldsConsumer
+}
+
+func (f *fieldsWriter) Write(ctx context.Context, fields index.Fields, norms index.NormsProducer) error {
+	groups, err := f.buildFieldsGroupMapping(fields)
+	if err != nil {
+		return err
+	}
+
+	// Write postings
+	for _, group := range groups {
+		consumer, err := group.format.FieldsConsumer(ctx, group.state)
+		if err != nil {
+			return err
+		}
+		f.toClose = append(f.toClose, consumer)
+
+		maskedFields := &maskedFields{
+			in:    fields,
+			names: group.fields,
+		}
+		if err := consumer.Write(ctx, maskedFields, norms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fieldsWriter) buildFieldsGroupMapping(fields index.Fields) ([]*fieldsGroup, error) {
+	// Groups are kept in creation order, so the suffixes are assigned in field name order
+	groups := make([]*fieldsGroup, 0)
+	formatToGroup := make(map[index.PostingsFormat]*fieldsGroup)
+	suffixes := make(map[string]int)
+
+	// Assign field -> PostingsFormat
+	for _, field := range fields.Names() {
+		fieldInfo := f.writeState.FieldInfos.FieldInfo(field)
+		if fieldInfo == nil {
+			return nil, fmt.Errorf("field=%s is unrecognized", field)
+		}
+
+		format := f.format.postingsFormatForField(field)
+		if format == nil {
+			return nil, fmt.Errorf("invalid nil PostingsFormat for field=\"%s\"", field)
+		}
+		formatName := format.GetName()
+
+		group, ok := formatToGroup[format]
+		if !ok {
+			// First time we are seeing this format; create a new instance
+
+			suffix, ok := suffixes[formatName]
+			if ok {
+				suffix++
+			}
+			suffixes[formatName] = suffix
+
+			segmentSuffix, err := getFullSegmentSuffix(field, f.writeState.SegmentSuffix,
+				getSuffix(formatName, strconv.Itoa(suffix)))
+			if err != nil {
+				return nil, err
+			}
+
+			state := *f.writeState
+			state.SegmentSuffix = segmentSuffix
+			group = &fieldsGroup{
+				format: format,
+				suffix: suffix,
+				state:  &state,
+			}
+			formatToGroup[format] = group
+			groups = append(groups, group)
+		}
+		group.fields = append(group.fields, field)
+
+		// The attributes may already be set if the same FieldInfo is flushed more than once
+		previousValue := fieldInfo.GetAttribute(PER_FIELD_POSTINGS_FORMAT_KEY)
+		if previousValue != "" && previousValue != formatName {
+			return nil, fmt.Errorf("found existing value for %s, field=%s, old=%s, new=%s",
+				PER_FIELD_POSTINGS_FORMAT_KEY, fieldInfo.Name(), previousValue, formatName)
+		}
+		fieldInfo.PutAttribute(PER_FIELD_POSTINGS_FORMAT_KEY, formatName)
+
+		suffixValue := strconv.Itoa(group.suffix)
+		previousValue = fieldInfo.GetAttribute(PER_FIELD_POSTINGS_SUFFIX_KEY)
+		if previousValue != "" && previousValue != suffixValue {
+			return nil, fmt.Errorf("found existing value for %s, field=%s, old=%s, new=%s",
+				PER_FIELD_POSTINGS_SUFFIX_KEY, fieldInfo.Name(), previousValue, suffixValue)
+		}
+		fieldInfo.PutAttribute(PER_FIELD_POSTINGS_SUFFIX_KEY, suffixValue)
+	}
+
+	for _, group := range groups {
+		slices.Sort(group.fields)
+	}
+	return groups, nil
+}
+
+func (f *fieldsWriter) Close() error {
+	errs := make([]error, 0, len(f.toClose))
+	for _, consumer := range f.toClose {
+		errs = append(errs, consumer.Close())
+	}
+	f.toClose = nil
+	return errors.Join(errs...)
+}
+
+var _ index.Fields = &maskedFields{}
+
+// maskedFields exposes only the fields of one fieldsGroup to its postings format
+type maskedFields struct {
+	in    index.Fields
+	names []string
+}
+
+func (m *maskedFields) Names() []string {
+	return m.names
+}
+
+func (m *maskedFields) Terms(field string) (index.Terms, error) {
+	if _, ok := slices.BinarySearch(m.names, field); !ok {
+		return nil, nil
+	}
+	return m.in.Terms(field)
+}
+
+func (m *maskedFields) Size() int {
+	return len(m.names)
+}
+
+var _ index.FieldsProducer = &fieldsReader{}
+
+type fieldsReader struct {
+	fields     map[string]index.FieldsProducer
+	fieldNames []string
+	formats    map[string]index.FieldsProducer
+	segment    string
+}
+
+func newFieldsReader(ctx context.Context, readState *index.SegmentReadState) (*fieldsReader, error) {
+	reader := &fieldsReader{
+		fields:  make(map[string]index.FieldsProducer),
+		formats: make(map[string]index.FieldsProducer),
+		segment: readState.SegmentInfo.Name(),
+	}
+
+	if err := reader.open(ctx, readState); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (f *fieldsReader) open(ctx context.Context, readState *index.SegmentReadState) error {
+	// Read field name -> format name
+	for _, fi := range readState.FieldInfos.List() {
+		if fi.GetIndexOptions() == document.INDEX_OPTIONS_NONE {
+			continue
+		}
+
+		fieldName := fi.Name()
+		formatName := fi.GetAttribute(PER_FIELD_POSTINGS_FORMAT_KEY)
+		if formatName == "" {
+			// null formatName means the field is in fieldInfos, but has no postings!
+			continue
+		}
+
+		suffix := fi.GetAttribute(PER_FIELD_POSTINGS_SUFFIX_KEY)
+		if suffix == "" {
+			return fmt.Errorf("missing attribute: %s for field: %s", PER_FIELD_POSTINGS_SUFFIX_KEY, fieldName)
+		}
+
+		format, ok := coreIndex.GetPostingsFormatByName(formatName)
+		if !ok {
+			return fmt.Errorf("postings format %s of field %s is not registered", formatName, fieldName)
+		}
+
+		segmentSuffix, err := getFullSegmentSuffix(fieldName, readState.SegmentSuffix, getSuffix(formatName, suffix))
+		if err != nil {
+			return err
+		}
+
+		producer, ok := f.formats[segmentSuffix]
+		if !ok {
+			state := *readState
+			state.SegmentSuffix = segmentSuffix
+			producer, err = format.FieldsProducer(ctx, &state)
+			if err != nil {
+				return err
+			}
+			f.formats[segmentSuffix] = producer
+		}
+		f.fields[fieldName] = producer
+		f.fieldNames = append(f.fieldNames, fieldName)
+	}
+
+	slices.Sort(f.fieldNames)
+	return nil
+}
+
+func (f *fieldsReader) Names() []string {
+	return slices.Clone(f.fieldNames)
+}
+
+func (f *fieldsReader) Terms(field string) (index.Terms, error) {
+	producer, ok := f.fields[field]
+	if !ok {
+		return nil, nil
+	}
+	return producer.Terms(field)
+}
+
+func (f *fieldsReader) Size() int {
+	return len(f.fields)
+}
+
+func (f *fieldsReader) CheckIntegrity() error {
+	for _, producer := range f.formats {
+		if err := producer.CheckIntegrity(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fieldsReader) GetMergeInstance() index.FieldsProducer {
+	reader := &fieldsReader{
+		fields:     make(map[string]index.FieldsProducer, len(f.fields)),
+		fieldNames: f.fieldNames,
+		formats:    make(map[string]index.FieldsProducer, len(f.formats)),
+		segment:    f.segment,
+	}
+
+	mergeInstances := make(map[index.FieldsProducer]index.FieldsProducer, len(f.formats))
+	for suffix, producer := range f.formats {
+		mergeInstance := producer.GetMergeInstance()
+		mergeInstances[producer] = mergeInstance
+		reader.formats[suffix] = mergeInstance
+	}
+	for field, producer := range f.fields {
+		reader.fields[field] = mergeInstances[producer]
+	}
+	return reader
+}
+
+func (f *fieldsReader) Close() error {
+	errs := make([]error, 0, len(f.formats))
+	for _, producer := range f.formats {
+		errs = append(errs, producer.Close())
+	}
+	clear(f.formats)
+	clear(f.fields)
+	return errors.Join(errs...)
+}
+
+func (f *fieldsReader) String() string {
+	return fmt.Sprintf("PerFieldPostings(segment=%s formats=%d)", f.segment, len(f.formats))
+}
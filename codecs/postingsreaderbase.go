@@ -0,0 +1,51 @@
+package codecs
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+)
+
+// PostingsReaderBase
+// The core terms dictionaries (BlockTermsReader, BlockTreeTermsReader) interact with a single
+// instance of this class to manage creation of PostingsEnum and PostingsEnum instances. It provides
+// an IndexInput (termsIn) where this class may read any previously stored data that it had written
+// in its corresponding PostingsWriterBase at indexing time.
+// lucene.experimental
+type PostingsReaderBase interface {
+	// Init
+	// Performs any initialization, such as reading and verifying the header from the provided
+	// terms dictionary IndexInput.
+	Init(ctx context.Context, termsIn store.IndexInput, state *index.SegmentReadState) error
+
+	// NewTermState
+	// Return a newly created empty TermState
+	NewTermState() BlockTermState
+
+	// DecodeTerm
+	// Actually decode metadata for next term
+	// See Also: PostingsWriterBase.EncodeTerm
+	DecodeTerm(ctx context.Context, in store.DataInput, fieldInfo *document.FieldInfo,
+		state BlockTermState, absolute bool) error
+
+	// Postings
+	// Must fully consume state, since after this call that TermState may be reused.
+	Postings(ctx context.Context, fieldInfo *document.FieldInfo, state BlockTermState,
+		reuse index.PostingsEnum, flags int) (index.PostingsEnum, error)
+
+	// Impacts
+	// Return a ImpactsEnum that computes impacts with scorer.
+	// See Also: index.TermsEnum.Impacts(int)
+	Impacts(ctx context.Context, fieldInfo *document.FieldInfo, state BlockTermState,
+		flags int) (index.ImpactsEnum, error)
+
+	// CheckIntegrity
+	// Checks consistency of this reader.
+	// Note that this may be costly in terms of I/O, e.g. may involve computing a checksum value
+	// against large data files.
+	CheckIntegrity(ctx context.Context) error
+
+	Close() error
+}
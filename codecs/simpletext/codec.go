@@ -6,7 +6,9 @@ import (
 )
 
 func init() {
-	coreIndex.RegisterCodec(&Codec{})
+	coreIndex.RegisterCodec(NewCodec())
+	coreIndex.RegisterPostingsFormat(NewPostingsFormat())
+	coreIndex.RegisterDocValuesFormat(NewSimpleTextDocValuesFormat())
 }
 
 var _ index.Codec = &Codec{}
@@ -173,8 +173,7 @@ func (s *DocValuesReader) GetMergeInstance() index.DocValuesProducer {
 }
 
 func (s *DocValuesReader) Close() error {
-	//TODO implement me
-	panic("implement me")
+	return s.data.Close()
 }
 
 func (s *DocValuesReader) GetNumeric(ctx context.Context, fieldInfo *document.FieldInfo) (index.NumericDocValues, error) {
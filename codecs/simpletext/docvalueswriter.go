@@ -134,7 +134,7 @@ func (s *DocValuesWriter) AddNumericField(ctx context.Context, field *document.F
 	}
 	for i := 0; i < s.numDocs; i++ {
 		if values.DocID() < i {
-			if _, err := values.NextDoc(nil); err != nil {
+			if _, err := values.NextDoc(nil); err != nil && !errors.Is(err, io.EOF) {
 				return err
 			}
 			//if values.DocID() >= i {
@@ -332,6 +332,9 @@ func (s *DocValuesWriter) AddSortedField(ctx context.Context, field *document.Fi
 	for {
 		value, err := terms.Next(ctx)
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
 			return err
 		}
 
@@ -339,6 +342,7 @@ func (s *DocValuesWriter) AddSortedField(ctx context.Context, field *document.Fi
 			break
 		}
 
+		valueCount++
 		maxLength = max(maxLength, len(value))
 	}
 
@@ -378,8 +382,11 @@ func (s *DocValuesWriter) AddSortedField(ctx context.Context, field *document.Fi
 	}
 
 	for {
-		value, err := terms.Next(nil)
+		value, err := terms.Next(ctx)
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
 			return err
 		}
 
@@ -423,7 +430,7 @@ func (s *DocValuesWriter) AddSortedField(ctx context.Context, field *document.Fi
 	}
 	for i := 0; i < s.numDocs; i++ {
 		if values.DocID() < i {
-			if _, err := values.NextDoc(nil); err != nil {
+			if _, err := values.NextDoc(nil); err != nil && !errors.Is(err, io.EOF) {
 				return err
 			}
 		}
@@ -549,8 +556,7 @@ func (s *DocValuesWriter) AddSortedSetField(ctx context.Context, field *document
 }
 
 func (s *DocValuesWriter) fieldSeen(field string) error {
-	_, ok := s.fieldsSeen[field]
-	if !ok {
+	if _, ok := s.fieldsSeen[field]; ok {
 		return fmt.Errorf(`field "%s" was added more than once during flush`, field)
 	}
 	s.fieldsSeen[field] = struct{}{}
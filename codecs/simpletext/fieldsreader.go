@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
 
@@ -183,7 +184,7 @@ func (s *FieldsReader) loadTerms(ctx context.Context, term *simpleTextTerms) err
 
 				output := fst.NewPostingOutput(lastDocsStart, skipPointer, docFreq, totalTermFreq)
 
-				if err := fstCompiler.Add(ctx, bytes.Runes(lastTerm.Bytes()), output); err != nil {
+				if err := fstCompiler.AddBytes(ctx, lastTerm.Bytes(), output); err != nil {
 					return err
 				}
 				term.sumTotalTermFreq += totalTermFreq
@@ -211,7 +212,7 @@ func (s *FieldsReader) loadTerms(ctx context.Context, term *simpleTextTerms) err
 		} else if bytes.HasPrefix(text, FIELDS_TERM) {
 			if lastDocsStart != -1 {
 				output := fst.NewPostingOutput(lastDocsStart, skipPointer, docFreq, totalTermFreq)
-				if err := fstCompiler.Add(ctx, bytes.Runes(lastTerm.Bytes()), output); err != nil {
+				if err := fstCompiler.AddBytes(ctx, lastTerm.Bytes(), output); err != nil {
 					return err
 				}
 			}
@@ -406,9 +407,9 @@ func (t *simpleTextTermsEnum) Postings(reuse index.PostingsEnum, flags int) (ind
 		if reuse != nil && ok && enum.CanReuse(t.r.in) {
 			docsAndPositionsEnum = reuse.(*simpleTextPostingsEnum)
 		} else {
-			docsAndPositionsEnum = newSimpleTextPostingsEnum()
+			docsAndPositionsEnum = t.r.newSimpleTextPostingsEnum()
 		}
-		return docsAndPositionsEnum.Reset(t.docsStart, t.indexOptions, t.docFreq, t.skipPointer), nil
+		return docsAndPositionsEnum.Reset(t.docsStart, t.indexOptions, t.docFreq, t.skipPointer)
 	}
 
 	var docsEnum *simpleTextDocsEnum
@@ -442,6 +443,7 @@ type simpleTextPostingsEnum struct {
 	in      store.IndexInput
 	docID   int
 	tf      int
+	scratch *bytes.Buffer
 
 	pos           int
 	payload       []byte
@@ -454,79 +456,138 @@ type simpleTextPostingsEnum struct {
 	cost        int
 	skipReader  *SkipReader
 	nextSkipDoc int
-	seekTo      int
+	seekTo      int64
 }
 
-func newSimpleTextPostingsEnum() *simpleTextPostingsEnum {
-	panic("")
+func (s *FieldsReader) newSimpleTextPostingsEnum() *simpleTextPostingsEnum {
+	return &simpleTextPostingsEnum{
+		inStart:     s.in,
+		in:          s.in.Clone().(store.IndexInput),
+		docID:       -1,
+		scratch:     new(bytes.Buffer),
+		startOffset: -1,
+		endOffset:   -1,
+		skipReader:  NewSkipReader(s.in.Clone().(store.IndexInput)),
+		seekTo:      -1,
+	}
 }
 
 func (s *simpleTextPostingsEnum) CanReuse(in store.IndexInput) bool {
-	return s.in == in
+	return in == s.inStart
 }
 
 func (s *simpleTextPostingsEnum) DocID() int {
-	//TODO implement me
-	panic("implement me")
+	return s.docID
 }
 
 func (s *simpleTextPostingsEnum) NextDoc(context.Context) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.Advance(nil, s.docID+1)
 }
 
 func (s *simpleTextPostingsEnum) Advance(ctx context.Context, target int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	if err := s.AdvanceShallow(nil, target); err != nil {
+		return 0, err
+	}
+	return s.advanceTarget(target)
 }
 
 func (s *simpleTextPostingsEnum) SlowAdvance(ctx context.Context, target int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.Advance(nil, target)
 }
 
 func (s *simpleTextPostingsEnum) Cost() int64 {
-	//TODO implement me
-	panic("implement me")
+	return int64(s.cost)
 }
 
 func (s *simpleTextPostingsEnum) Freq() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.tf, nil
 }
 
 func (s *simpleTextPostingsEnum) NextPosition() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	if s.readPositions {
+		if err := utils.ReadLine(s.in, s.scratch); err != nil {
+			return 0, err
+		}
+		if !bytes.HasPrefix(s.scratch.Bytes(), FIELDS_POS) {
+			return 0, fmt.Errorf("expected position, got %s", s.scratch.String())
+		}
+		pos, err := strconv.Atoi(string(s.scratch.Bytes()[len(FIELDS_POS):]))
+		if err != nil {
+			return 0, err
+		}
+		s.pos = pos
+	} else {
+		s.pos = -1
+	}
+
+	if s.readOffsets {
+		if err := utils.ReadLine(s.in, s.scratch); err != nil {
+			return 0, err
+		}
+		startOffset, err := strconv.Atoi(string(s.scratch.Bytes()[len(FIELDS_START_OFFSET):]))
+		if err != nil {
+			return 0, err
+		}
+		s.startOffset = startOffset
+
+		if err := utils.ReadLine(s.in, s.scratch); err != nil {
+			return 0, err
+		}
+		endOffset, err := strconv.Atoi(string(s.scratch.Bytes()[len(FIELDS_END_OFFSET):]))
+		if err != nil {
+			return 0, err
+		}
+		s.endOffset = endOffset
+	}
+
+	fp := s.in.GetFilePointer()
+	if err := utils.ReadLine(s.in, s.scratch); err != nil {
+		return 0, err
+	}
+	if bytes.HasPrefix(s.scratch.Bytes(), FIELDS_PAYLOAD) {
+		s.payload = bytes.Clone(s.scratch.Bytes()[len(FIELDS_PAYLOAD):])
+	} else {
+		s.payload = nil
+		if _, err := s.in.Seek(fp, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+	return s.pos, nil
 }
 
 func (s *simpleTextPostingsEnum) StartOffset() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.startOffset, nil
 }
 
 func (s *simpleTextPostingsEnum) EndOffset() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.endOffset, nil
 }
 
 func (s *simpleTextPostingsEnum) GetPayload() ([]byte, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.payload, nil
 }
 
 func (s *simpleTextPostingsEnum) AdvanceShallow(ctx context.Context, target int) error {
-	//TODO implement me
-	panic("implement me")
+	if target > s.nextSkipDoc {
+		if _, err := s.skipReader.SkipTo(nil, target); err != nil {
+			return err
+		}
+		if s.skipReader.GetNextSkipDoc() != types.NO_MORE_DOCS {
+			s.seekTo = s.skipReader.GetNextSkipDocFP()
+		}
+	}
+	s.nextSkipDoc = s.skipReader.GetNextSkipDoc()
+	return nil
 }
 
 func (s *simpleTextPostingsEnum) GetImpacts() (index.Impacts, error) {
-	//TODO implement me
-	panic("implement me")
+	if err := s.AdvanceShallow(nil, s.docID); err != nil {
+		return nil, err
+	}
+	return s.skipReader.GetImpacts(), nil
 }
 
-func (s *simpleTextPostingsEnum) Reset(fp int64, indexOptions document.IndexOptions, docFreq int, skipPointer int64) index.PostingsEnum {
-
+func (s *simpleTextPostingsEnum) Reset(fp int64, indexOptions document.IndexOptions, docFreq int, skipPointer int64) (index.PostingsEnum, error) {
 	s.nextDocStart = fp
 	s.docID = -1
 	s.readPositions = indexOptions >= (document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS)
@@ -536,10 +597,95 @@ func (s *simpleTextPostingsEnum) Reset(fp int64, indexOptions document.IndexOpti
 		s.endOffset = -1
 	}
 	s.cost = docFreq
-	s.skipReader.Reset(nil, skipPointer, docFreq)
+	if err := s.skipReader.Reset(nil, skipPointer, docFreq); err != nil {
+		return nil, err
+	}
 	s.nextSkipDoc = 0
 	s.seekTo = -1
-	return s
+	return s, nil
+}
+
+// readDoc reads the doc and freq lines of the next document and leaves the input positioned
+// on its first position line, nextDocStart is moved to the line of the following document.
+func (s *simpleTextPostingsEnum) readDoc() (int, error) {
+	if s.docID == types.NO_MORE_DOCS {
+		return s.docID, io.EOF
+	}
+
+	first := true
+	if _, err := s.in.Seek(s.nextDocStart, io.SeekStart); err != nil {
+		return 0, err
+	}
+	posStart := int64(0)
+	for {
+		lineStart := s.in.GetFilePointer()
+		if err := utils.ReadLine(s.in, s.scratch); err != nil {
+			return 0, err
+		}
+
+		line := s.scratch.Bytes()
+		if bytes.HasPrefix(line, FIELDS_DOC) {
+			if !first {
+				s.nextDocStart = lineStart
+				if _, err := s.in.Seek(posStart, io.SeekStart); err != nil {
+					return 0, err
+				}
+				return s.docID, nil
+			}
+			docID, err := strconv.Atoi(string(line[len(FIELDS_DOC):]))
+			if err != nil {
+				return 0, err
+			}
+			s.docID = docID
+			s.tf = 0
+			first = false
+		} else if bytes.HasPrefix(line, FIELDS_FREQ) {
+			tf, err := strconv.Atoi(string(line[len(FIELDS_FREQ):]))
+			if err != nil {
+				return 0, err
+			}
+			s.tf = tf
+			posStart = s.in.GetFilePointer()
+		} else if bytes.HasPrefix(line, FIELDS_POS) {
+			// skip
+		} else if bytes.HasPrefix(line, FIELDS_START_OFFSET) {
+			// skip
+		} else if bytes.HasPrefix(line, FIELDS_END_OFFSET) {
+			// skip
+		} else if bytes.HasPrefix(line, FIELDS_PAYLOAD) {
+			// skip
+		} else {
+			// SKIP_LIST, FIELDS_TERM, FIELDS_FIELD or FIELDS_END
+			if !first {
+				s.nextDocStart = lineStart
+				if _, err := s.in.Seek(posStart, io.SeekStart); err != nil {
+					return 0, err
+				}
+				return s.docID, nil
+			}
+			s.docID = types.NO_MORE_DOCS
+			return s.docID, io.EOF
+		}
+	}
+}
+
+func (s *simpleTextPostingsEnum) advanceTarget(target int) (int, error) {
+	if s.seekTo > 0 {
+		s.nextDocStart = s.seekTo
+		s.seekTo = -1
+	}
+
+	doc, err := s.readDoc()
+	if err != nil {
+		return doc, err
+	}
+	for doc < target {
+		doc, err = s.readDoc()
+		if err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
 }
 
 var _ index.ImpactsEnum = &simpleTextDocsEnum{}
@@ -747,8 +893,11 @@ func (s *simpleTextDocsEnum) advanceTarget(target int) (int, error) {
 		return 0, err
 	}
 
-	for doc >= target {
-		break
+	for doc < target {
+		doc, err = s.readDoc()
+		if err != nil {
+			return 0, err
+		}
 	}
 	return doc, nil
 }
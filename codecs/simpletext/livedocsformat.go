@@ -98,7 +98,7 @@ func (s *LiveDocsFormat) WriteLiveDocs(ctx context.Context, bits util.Bits, dir
 
 	for i := 0; i < size; i++ {
 		if bits.Test(uint(i)) {
-			if err := writeValue(out, LIVE_DOCS_FORMAT_DOC, size); err != nil {
+			if err := writeValue(out, LIVE_DOCS_FORMAT_DOC, i); err != nil {
 				return err
 			}
 		}
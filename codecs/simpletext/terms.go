@@ -81,7 +81,7 @@ OUTER:
 		case bytes.Equal(text, FIELDS_END) || bytes.HasPrefix(text, FIELDS_FIELD):
 			if lastDocsStart != -1 {
 				value := fst.NewPostingOutput(lastDocsStart, skipPointer, docFreq, totalTermFreq)
-				if err := fstCompiler.Add(ctx, bytes.Runes(lastTerm.Bytes()), value); err != nil {
+				if err := fstCompiler.AddBytes(ctx, lastTerm.Bytes(), value); err != nil {
 					return err
 				}
 				s.sumTotalTermFreq += totalTermFreq
@@ -111,7 +111,7 @@ OUTER:
 		case bytes.HasPrefix(text, FIELDS_TERM):
 			if lastDocsStart != -1 {
 				value := fst.NewPostingOutput(lastDocsStart, skipPointer, docFreq, totalTermFreq)
-				if err := fstCompiler.Add(ctx, bytes.Runes(lastTerm.Bytes()), value); err != nil {
+				if err := fstCompiler.AddBytes(ctx, lastTerm.Bytes(), value); err != nil {
 					return err
 				}
 			}
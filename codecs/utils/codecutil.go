@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/geange/lucene-go/core/store"
 )
@@ -181,3 +183,149 @@ func writeCRC(output store.IndexOutput) error {
 	//}
 	return output.WriteUint64(nil, uint64(value))
 }
+
+// CheckIndexHeader
+// Reads and validates a header previously written with WriteIndexHeader.
+// When reading a file, supply the expected codec, expected version range (minVersion to maxVersion),
+// and object ID and suffix.
+// Returns: The actual version found, when a valid header is found that matches codec, with an
+// actual version where minVersion <= actual <= maxVersion, and matching expectedID and expectedSuffix.
+func CheckIndexHeader(ctx context.Context, in store.DataInput, codec string, minVersion, maxVersion int,
+	expectedID []byte, expectedSuffix string) (int, error) {
+
+	version, err := CheckHeader(ctx, in, codec, minVersion, maxVersion)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := CheckIndexHeaderID(in, expectedID); err != nil {
+		return 0, err
+	}
+	if _, err := CheckIndexHeaderSuffix(in, expectedSuffix); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// CheckIndexHeaderID Expert: just reads and verifies the object ID of an index header
+func CheckIndexHeaderID(in store.DataInput, expectedID []byte) ([]byte, error) {
+	id := make([]byte, ID_LENGTH)
+	if _, err := in.Read(id); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(id, expectedID) {
+		return nil, fmt.Errorf("file mismatch, expected id=%s, got=%s",
+			base64.StdEncoding.EncodeToString(expectedID), base64.StdEncoding.EncodeToString(id))
+	}
+	return id, nil
+}
+
+// HeaderLength Computes the length of a codec header.
+func HeaderLength(codec string) int {
+	return 9 + len(codec)
+}
+
+// IndexHeaderLength Computes the length of an index header.
+func IndexHeaderLength(codec, suffix string) int {
+	return HeaderLength(codec) + ID_LENGTH + 1 + len(suffix)
+}
+
+// CheckCodecFooter
+// Validates the codec footer previously written by WriteFooter.
+// in must be positioned at the start of the footer.
+// Returns: actual checksum value
+func CheckCodecFooter(ctx context.Context, in store.ChecksumIndexInput) (uint64, error) {
+	if err := validateFooter(ctx, in); err != nil {
+		return 0, err
+	}
+	actualChecksum := uint64(in.GetChecksum())
+	expectedChecksum, err := readCRC(ctx, in)
+	if err != nil {
+		return 0, err
+	}
+	if expectedChecksum != actualChecksum {
+		return 0, fmt.Errorf("checksum failed (hardware problem?) : expected=%x actual=%x", expectedChecksum, actualChecksum)
+	}
+	return actualChecksum, nil
+}
+
+// RetrieveChecksum
+// Returns (but does not validate) the checksum previously written by WriteFooter.
+func RetrieveChecksum(ctx context.Context, in store.IndexInput) (uint64, error) {
+	if in.Length() < int64(FooterLength()) {
+		return 0, errors.New("misplaced codec footer (file truncated?)")
+	}
+	if _, err := in.Seek(in.Length()-int64(FooterLength()), io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := validateFooter(ctx, in); err != nil {
+		return 0, err
+	}
+	return readCRC(ctx, in)
+}
+
+// ChecksumEntireFile
+// Clones the provided input, reads all bytes from the file, and calls CheckCodecFooter
+// Note that this method may be slow, as it must process the entire file.
+func ChecksumEntireFile(ctx context.Context, input store.IndexInput) (uint64, error) {
+	clone, ok := input.Clone().(store.IndexInput)
+	if !ok {
+		return 0, errors.New("input can't be cloned")
+	}
+	if _, err := clone.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	in := store.NewBufferedChecksumIndexInput(clone)
+	size := in.Length() - int64(FooterLength())
+	if size < 0 {
+		return 0, errors.New("misplaced codec footer (file truncated?)")
+	}
+
+	buf := make([]byte, 8192)
+	for size > 0 {
+		n := min(int64(len(buf)), size)
+		if _, err := in.Read(buf[:n]); err != nil {
+			return 0, err
+		}
+		size -= n
+	}
+	return CheckCodecFooter(ctx, in)
+}
+
+func validateFooter(ctx context.Context, in store.IndexInput) error {
+	remaining := in.Length() - in.GetFilePointer()
+	expected := int64(FooterLength())
+	if remaining < expected {
+		return fmt.Errorf("misplaced codec footer (file truncated?): remaining=%d, expected=%d", remaining, expected)
+	}
+	if remaining > expected {
+		return fmt.Errorf("misplaced codec footer (file extended?): remaining=%d, expected=%d", remaining, expected)
+	}
+
+	magic, err := in.ReadUint32(ctx)
+	if err != nil {
+		return err
+	}
+	if magic != FOOTER_MAGIC {
+		return fmt.Errorf("codec footer mismatch (file truncated?): actual footer=%d vs expected footer=%d", magic, FOOTER_MAGIC)
+	}
+
+	algorithmID, err := in.ReadUint32(ctx)
+	if err != nil {
+		return err
+	}
+	if algorithmID != 0 {
+		return fmt.Errorf("codec footer mismatch: unknown algorithmID: %d", algorithmID)
+	}
+	return nil
+}
+
+func readCRC(ctx context.Context, in store.IndexInput) (uint64, error) {
+	value, err := in.ReadUint64(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if (value & 0xFFFFFFFF00000000) != 0 {
+		return 0, fmt.Errorf("illegal CRC-32 checksum: %d", value)
+	}
+	return value, nil
+}
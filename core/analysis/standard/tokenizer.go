@@ -161,14 +161,11 @@ func (r *stdTokenizer) GetNextToken() (string, error) {
 			return "", err
 		}
 
-		if !unicode.IsSpace(char) {
-			r.fast += n
-			r.buff = append(r.buff, char)
-		} else {
-			r.fast += n
-			r.fast++
+		r.fast += n
+		if unicode.IsSpace(char) {
 			break
 		}
+		r.buff = append(r.buff, char)
 	}
 
 	return string(r.buff), nil
@@ -46,3 +46,17 @@ func TestTokenizer_IncrementToken(t *testing.T) {
 	assert.Nil(t, err)
 	assert.False(t, ok)
 }
+
+func TestTokenizer_Offsets(t *testing.T) {
+	tokenizer := NewTokenizer()
+	err := tokenizer.SetReader(bytes.NewReader([]byte("a bb ccc é d")))
+	assert.Nil(t, err)
+
+	for _, expected := range [][2]int{{0, 1}, {2, 4}, {5, 8}, {9, 11}, {12, 13}} {
+		ok, err := tokenizer.IncrementToken()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		offset := tokenizer.AttributeSource().Offset()
+		assert.Equal(t, expected, [2]int{offset.StartOffset(), offset.EndOffset()})
+	}
+}
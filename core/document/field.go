@@ -113,7 +113,12 @@ func (r *Field[T]) Set(v any) error {
 }
 
 func (r *Field[T]) Number() (any, bool) {
-	return 0, false
+	switch v := r.Get().(type) {
+	case int32, int64, float32, float64:
+		return v, true
+	default:
+		return 0, false
+	}
 }
 
 var _ analysis.TokenStream = &StringTokenStream{}
@@ -193,6 +193,9 @@ func (f *FieldInfo) GetAttribute(key string) string {
 // If the value of the attributes for a same field is changed between the documents, the behaviour
 // after merge is undefined.
 func (f *FieldInfo) PutAttribute(key, value string) {
+	if f.attributes == nil {
+		f.attributes = make(map[string]string)
+	}
 	f.attributes[key] = value
 }
 
@@ -1,6 +1,10 @@
 package document
 
-import "math"
+import (
+	"math"
+
+	"github.com/geange/lucene-go/core/util/numeric"
+)
 
 // FloatPoint
 // An indexed float field for fast range filters. If you also need to store the value, you should
@@ -14,7 +18,7 @@ import "math"
 // newRangeQuery(String, float[], float[]) for matching points/ranges in n-dimensional space.
 // See Also: PointValues
 type FloatPoint struct {
-	*Field[[]float32]
+	*Field[[]byte]
 }
 
 func NewFloatPoint(name string, points ...float32) (FloatPoint, error) {
@@ -24,10 +28,46 @@ func NewFloatPoint(name string, points ...float32) (FloatPoint, error) {
 	}
 	fieldType.Freeze()
 
-	field := FloatPoint{NewField(name, points, fieldType)}
+	field := FloatPoint{NewField(name, packFloatPoint(points), fieldType)}
 	return field, nil
 }
 
+func (r FloatPoint) Number() (any, bool) {
+	if r.fieldType.PointDimensionCount() > 1 {
+		return float32(0), false
+	}
+	return decodeDimensionFloat32(r.fieldsData), true
+}
+
+func (r FloatPoint) Points() []float32 {
+	return unPackFloatPoint(r.fieldsData)
+}
+
+func packFloatPoint(points []float32) []byte {
+	packed := make([]byte, len(points)*FLOAT_BYTES)
+	for dim, point := range points {
+		offset := dim * FLOAT_BYTES
+		encodeDimensionFloat32(point, packed[offset:])
+	}
+	return packed
+}
+
+func unPackFloatPoint(bs []byte) []float32 {
+	points := make([]float32, 0, len(bs)/FLOAT_BYTES)
+	for i := 0; i < len(bs); i += FLOAT_BYTES {
+		points = append(points, decodeDimensionFloat32(bs[i:]))
+	}
+	return points
+}
+
+func encodeDimensionFloat32(value float32, dest []byte) {
+	numeric.IntToSortableBytes(numeric.Float32ToSortableInt(value), dest)
+}
+
+func decodeDimensionFloat32(value []byte) float32 {
+	return numeric.SortableInt32ToFloat32(numeric.SortableBytesToInt(value))
+}
+
 // FloatRange An indexed Float Range field.
 // This field indexes dimensional ranges defined as min/max pairs. It supports up to a maximum of 4 dimensions
 // (indexed as 8 numeric values). With 1 dimension representing a single float range, 2 dimensions representing
@@ -11,10 +11,10 @@ type IntPoint struct {
 	*Field[[]byte]
 }
 
-func NewIntPoint(name string, points ...int32) IntPoint {
+func NewIntPoint(name string, points ...int32) *IntPoint {
 	packed := packIntPoint(points)
 	fieldType := genIntPointType(len(points))
-	return IntPoint{NewField(name, packed, fieldType)}
+	return &IntPoint{NewField(name, packed, fieldType)}
 }
 
 func (r *IntPoint) String() string {
@@ -26,9 +26,9 @@ type LongPoint struct {
 // Creates a new LongPoint, indexing the provided N-dimensional long point.
 // Params: name – field name point – long[] value
 // Throws: IllegalArgumentException – if the field name or value is null.
-func NewLongPoint(name string, points ...int64) LongPoint {
+func NewLongPoint(name string, points ...int64) *LongPoint {
 	packed := packLongPoint(points)
-	return LongPoint{NewField(name, packed, genLongPointType(len(points)))}
+	return &LongPoint{NewField(name, packed, genLongPointType(len(points)))}
 }
 
 func (r *LongPoint) String() string {
@@ -2,10 +2,14 @@ package index
 
 import (
 	"bytes"
+	"errors"
 
+	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/automaton"
 )
 
+var _ FilteredTermsEnum = &AutomatonTermsEnum{}
+
 // AutomatonTermsEnum
 // A FilteredTermsEnum that enumerates terms based upon what is accepted by a DFA.
 // The algorithm is such:
@@ -36,7 +40,7 @@ type AutomatonTermsEnum struct {
 	curGen int
 
 	// the reference used for seeking forwards through the term dictionary
-	seekBytesRef any
+	seekBytesRef []byte
 
 	// true if we are enumerating an infinite portion of the DFA.
 	// in this case it is faster to drive the query based on the terms dictionary.
@@ -48,13 +52,47 @@ type AutomatonTermsEnum struct {
 
 	transition *automaton.Transition
 
-	savedStates any
+	savedStates []int
+
+	// the term to start the enumeration from, if any
+	startTerm []byte
+}
+
+// NewAutomatonTermsEnum
+// Construct an enumerator based upon an automaton, enumerating the specified field, working on a
+// supplied TermsEnum
+// compiled: CompiledAutomaton
+func NewAutomatonTermsEnum(tenum index.TermsEnum, compiled *automaton.CompiledAutomaton) (*AutomatonTermsEnum, error) {
+	if compiled.Type() != automaton.AUTOMATON_TYPE_NORMAL {
+		return nil, errors.New("please use CompiledAutomaton.getTermsEnum instead")
+	}
+
+	runAutomaton := compiled.RunAutomaton()
+	enum := &AutomatonTermsEnum{
+		runAutomaton:    runAutomaton,
+		commonSuffixRef: compiled.CommonSuffixRef(),
+		finite:          compiled.Finite(),
+		automaton:       compiled.Automaton(),
+		// used for path tracking, where each bit is a numbered state.
+		visited:          make([]int, runAutomaton.GetSize()),
+		seekBytesRef:     make([]byte, 0),
+		linearUpperBound: make([]byte, 0),
+		transition:       automaton.NewTransition(),
+		savedStates:      make([]int, 0),
+	}
+	enum.FilteredTermsEnumBase = NewFilteredTermsEnumDefault(&FilteredTermsEnumDefaultConfig{
+		Accept:        enum.Accept,
+		NextSeekTerm:  enum.nextSeekTerm,
+		Tenum:         tenum,
+		StartWithSeek: true,
+	})
+	return enum, nil
 }
 
 // Records the given state has been visited.
 func (a *AutomatonTermsEnum) setVisited(state int) {
 	if !a.finite {
-		a.visited[state] = state
+		a.visited[state] = a.curGen
 	}
 }
 
@@ -63,8 +101,10 @@ func (a *AutomatonTermsEnum) isVisited(state int) bool {
 	return !a.finite && a.visited[state] == a.curGen
 }
 
+// Accept
+// Returns true if the term matches the automaton. Also stashes away the term to assist with smart enumeration.
 func (a *AutomatonTermsEnum) Accept(term []byte) (AcceptStatus, error) {
-	if len(a.commonSuffixRef) == 0 || bytes.HasPrefix(term, a.commonSuffixRef) {
+	if len(a.commonSuffixRef) == 0 || bytes.HasSuffix(term, a.commonSuffixRef) {
 		if a.runAutomaton.Run(term) {
 			if a.linear {
 				return ACCEPT_STATUS_YES, nil
@@ -83,3 +123,205 @@ func (a *AutomatonTermsEnum) Accept(term []byte) (AcceptStatus, error) {
 	}
 	return ACCEPT_STATUS_NO_AND_SEEK, nil
 }
+
+func (a *AutomatonTermsEnum) nextSeekTerm(term []byte) ([]byte, error) {
+	if term == nil && a.startTerm != nil {
+		term = a.startTerm
+	}
+
+	if term == nil {
+		// return the empty term, as it's valid
+		if a.runAutomaton.IsAccept(0) {
+			return a.seekBytesRef, nil
+		}
+	} else {
+		a.seekBytesRef = append(a.seekBytesRef[:0], term...)
+	}
+
+	// seek to the next possible string;
+	if a.nextString() {
+		// reposition
+		return a.seekBytesRef, nil
+	}
+	// no more possible strings can match
+	return nil, nil
+}
+
+// Sets the enum to operate in linear fashion, as we have found a looping transition at position:
+// we set an upper bound and act like a TermRangeQuery for this portion of the term space.
+func (a *AutomatonTermsEnum) setLinear(position int) {
+	state := 0
+	maxInterval := 0xff
+	for i := 0; i < position; i++ {
+		state = a.runAutomaton.Step(state, int(a.seekBytesRef[i]))
+	}
+
+	numTransitions := a.automaton.InitTransition(state, a.transition)
+	for i := 0; i < numTransitions; i++ {
+		a.automaton.GetNextTransition(a.transition)
+		c := int(a.seekBytesRef[position])
+		if a.transition.Min <= c && c <= a.transition.Max {
+			maxInterval = a.transition.Max
+			break
+		}
+	}
+
+	// 0xff terms don't get the optimization... not worth the trouble.
+	if maxInterval != 0xff {
+		maxInterval++
+	}
+
+	a.linearUpperBound = append(a.linearUpperBound[:0], a.seekBytesRef[:position]...)
+	a.linearUpperBound = append(a.linearUpperBound, byte(maxInterval))
+	a.linear = true
+}
+
+// Increments the byte buffer to the next String in binary order after s that will not put the
+// machine into a reject state. If such a string does not exist, returns false.
+//
+// The correctness of this method depends upon the automaton being deterministic, and having no
+// transitions to dead states.
+//
+// Returns true if more possible solutions exist for the DFA
+func (a *AutomatonTermsEnum) nextString() bool {
+	pos := 0
+	if cap(a.savedStates) < len(a.seekBytesRef)+1 {
+		a.savedStates = make([]int, len(a.seekBytesRef)+1)
+	}
+	a.savedStates = a.savedStates[:len(a.seekBytesRef)+1]
+	a.savedStates[0] = 0
+
+	for {
+		a.curGen++
+		a.linear = false
+
+		// walk the automaton until a character is rejected.
+		state := a.savedStates[pos]
+		for ; pos < len(a.seekBytesRef); pos++ {
+			a.setVisited(state)
+			nextState := a.runAutomaton.Step(state, int(a.seekBytesRef[pos]))
+			if nextState == -1 {
+				break
+			}
+			a.savedStates[pos+1] = nextState
+
+			// we found a loop, record it for faster enumeration
+			if !a.finite && !a.linear && a.isVisited(nextState) {
+				a.setLinear(pos)
+			}
+			state = nextState
+		}
+
+		// take the useful portion, and the last non-reject state, and attempt to
+		// append characters that will match.
+		if a.nextStringFrom(state, pos) {
+			return true
+		}
+
+		// no more solutions exist from this useful portion, backtrack
+		if pos = a.backtrack(pos); pos < 0 {
+			// no more solutions at all
+			return false
+		}
+
+		newState := a.runAutomaton.Step(a.savedStates[pos], int(a.seekBytesRef[pos]))
+		if newState >= 0 && a.runAutomaton.IsAccept(newState) {
+			// String is good to go as-is
+			return true
+		}
+
+		// else advance further
+		// TODO: paranoia? if we backtrack thru an infinite DFA, the loop detection is important!
+		// for now, restart from scratch for all infinite DFAs
+		if !a.finite {
+			pos = 0
+		}
+	}
+}
+
+// Returns the next String in lexicographic order that will not put the machine into a reject state.
+// This method traverses the DFA from the given position in the String, starting at the given state.
+// If this cannot satisfy the machine, returns false. This method will walk the minimal path, in
+// lexicographic order, as long as possible.
+// If this method returns false, then there might still be more solutions, it is necessary to
+// backtrack to find out.
+//
+// state: current non-reject state
+// position: useful portion of the string
+// Returns true if more possible solutions exist for the DFA from this position
+func (a *AutomatonTermsEnum) nextStringFrom(state, position int) bool {
+	// the next lexicographic character must be greater than the existing
+	// character, if it exists.
+	c := 0
+	if position < len(a.seekBytesRef) {
+		c = int(a.seekBytesRef[position])
+		// if the next byte is 0xff and is not part of the useful portion,
+		// then by definition it puts us in a reject state, and therefore this
+		// path is dead. there cannot be any higher transitions. backtrack.
+		if c == 0xff {
+			return false
+		}
+		c++
+	}
+
+	a.seekBytesRef = a.seekBytesRef[:position]
+	a.setVisited(state)
+
+	numTransitions := a.automaton.InitTransition(state, a.transition)
+	// find the minimal path (lexicographic order) that is >= c
+	for i := 0; i < numTransitions; i++ {
+		a.automaton.GetNextTransition(a.transition)
+		if a.transition.Max < c {
+			continue
+		}
+
+		nextChar := max(c, a.transition.Min)
+		// append either the next sequential char, or the minimum transition
+		a.seekBytesRef = append(a.seekBytesRef, byte(nextChar))
+		state = a.transition.Dest
+
+		// as long as is possible, continue down the minimal path in
+		// lexicographic order. if a loop or accept state is encountered, stop.
+		for !a.isVisited(state) && !a.runAutomaton.IsAccept(state) {
+			a.setVisited(state)
+
+			// Note: we work with a DFA with no transitions to dead states.
+			// so the below is ok, if it is not an accept state,
+			// then there MUST be at least one transition.
+			a.automaton.InitTransition(state, a.transition)
+			a.automaton.GetNextTransition(a.transition)
+			state = a.transition.Dest
+
+			// append the minimum transition
+			a.seekBytesRef = append(a.seekBytesRef, byte(a.transition.Min))
+
+			// we found a loop, record it for faster enumeration
+			if !a.finite && !a.linear && a.isVisited(state) {
+				a.setLinear(len(a.seekBytesRef) - 1)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Attempts to backtrack thru the string after encountering a dead end at some given position.
+// Returns false if no more possible strings can match.
+//
+// position: current position in the input String
+// Returns position >= 0 if more possible solutions exist for the DFA
+func (a *AutomatonTermsEnum) backtrack(position int) int {
+	for position > 0 {
+		position--
+		nextChar := int(a.seekBytesRef[position])
+		// if a character is 0xff it's a dead-end too,
+		// because there is no higher character in binary sort order.
+		if nextChar != 0xff {
+			a.seekBytesRef[position] = byte(nextChar + 1)
+			a.seekBytesRef = a.seekBytesRef[:position+1]
+			return position
+		}
+	}
+	// all solutions exhausted
+	return -1
+}
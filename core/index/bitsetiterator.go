@@ -35,6 +35,11 @@ func (b *BitSetIterator) DocID() int {
 	return b.doc
 }
 
+// SetDocID Set the current doc id that this iterator is on.
+func (b *BitSetIterator) SetDocID(docID int) {
+	b.doc = docID
+}
+
 func (b *BitSetIterator) NextDoc(ctx context.Context) (int, error) {
 	return b.Advance(ctx, b.doc+1)
 }
@@ -42,7 +47,8 @@ func (b *BitSetIterator) NextDoc(ctx context.Context) (int, error) {
 func (b *BitSetIterator) Advance(ctx context.Context, target int) (int, error) {
 	value, ok := b.bits.NextSet(uint(target))
 	if !ok {
-		return 0, io.EOF
+		b.doc = types.NO_MORE_DOCS
+		return b.doc, io.EOF
 	}
 
 	b.doc = int(value)
@@ -83,7 +83,7 @@ func (b *ByteSliceReader) Read(bs []byte) (n int, err error) {
 		} else {
 			copy(bs[offset:], b.buffer[b.upto:])
 			b.upto += size
-			return size, nil
+			return len(bs), nil
 		}
 	}
 	return 0, errors.New("size of bs is zero")
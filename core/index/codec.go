@@ -21,6 +21,31 @@ func GetCodecByName(name string) (index.Codec, bool) {
 	return codec, exist
 }
 
+var postingsFormatsPool = make(map[string]index.PostingsFormat)
+
+// RegisterPostingsFormat makes a postings format available by its name, which is how the
+// per-field postings format resolves the formats recorded in a segment's field infos.
+func RegisterPostingsFormat(format index.PostingsFormat) {
+	postingsFormatsPool[format.GetName()] = format
+}
+
+func GetPostingsFormatByName(name string) (index.PostingsFormat, bool) {
+	format, exist := postingsFormatsPool[name]
+	return format, exist
+}
+
+var docValuesFormatsPool = make(map[string]index.DocValuesFormat)
+
+// RegisterDocValuesFormat makes a doc values format available by its name.
+func RegisterDocValuesFormat(format index.DocValuesFormat) {
+	docValuesFormatsPool[format.GetName()] = format
+}
+
+func GetDocValuesFormatByName(name string) (index.DocValuesFormat, bool) {
+	format, exist := docValuesFormatsPool[name]
+	return format, exist
+}
+
 type BaseCompoundDirectory struct {
 }
 
@@ -52,6 +77,10 @@ func (*BaseCompoundDirectory) Sync(names map[string]struct{}) error {
 	return ErrUnsupportedOperation
 }
 
+func (*BaseCompoundDirectory) GetPendingDeletions() (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
 func (*BaseCompoundDirectory) ObtainLock(name string) (store.Lock, error) {
 	return nil, ErrUnsupportedOperation
 }
@@ -180,7 +209,7 @@ func (c *BaseCodecReader) GetPointValues(field string) (types.PointValues, bool)
 		return nil, false
 	}
 	values, err := c.GetPointsReader().GetValues(nil, field)
-	if err != nil {
+	if err != nil || values == nil {
 		return nil, false
 	}
 	return values, true
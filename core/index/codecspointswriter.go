@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"errors"
 
 	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
@@ -37,21 +38,27 @@ func (p *BasePointsWriter) MergeOneField(ctx context.Context, mergeState *MergeS
 		}
 	}
 
-	finalMaxPointCount := maxPointCount
-	finalDocCount := docCount
-
-	return p.WriteField(ctx, fieldInfo, newInnerPointsReader(finalMaxPointCount, finalDocCount))
+	return p.WriteField(ctx, fieldInfo, newInnerPointsReader(mergeState, fieldInfo, maxPointCount, docCount))
 }
 
 var _ index.PointsReader = &innerPointsReader{}
 
+// innerPointsReader exposes the points of one field of all segments being merged,
+// skipping deleted documents and mapping doc IDs to the merged segment.
 type innerPointsReader struct {
-	size     int
-	docCount int
-}
-
-func newInnerPointsReader(size int, docCount int) *innerPointsReader {
-	return &innerPointsReader{size: size, docCount: docCount}
+	mergeState *MergeState
+	fieldInfo  *document.FieldInfo
+	size       int
+	docCount   int
+}
+
+func newInnerPointsReader(mergeState *MergeState, fieldInfo *document.FieldInfo, size int, docCount int) *innerPointsReader {
+	return &innerPointsReader{
+		mergeState: mergeState,
+		fieldInfo:  fieldInfo,
+		size:       size,
+		docCount:   docCount,
+	}
 }
 
 func (i *innerPointsReader) Close() error {
@@ -59,15 +66,14 @@ func (i *innerPointsReader) Close() error {
 }
 
 func (i *innerPointsReader) CheckIntegrity() error {
-	//TODO implement me
-	panic("implement me")
+	return ErrUnsupportedOperation
 }
 
 func (i *innerPointsReader) GetValues(ctx context.Context, field string) (types.PointValues, error) {
-	return &innerPointValues{
-		size:     i.size,
-		docCount: i.docCount,
-	}, nil
+	if field != i.fieldInfo.Name() {
+		return nil, errors.New("field name must match the field being merged")
+	}
+	return &innerPointValues{reader: i}, nil
 }
 
 func (i *innerPointsReader) GetMergeInstance() index.PointsReader {
@@ -77,18 +83,61 @@ func (i *innerPointsReader) GetMergeInstance() index.PointsReader {
 var _ types.PointValues = &innerPointValues{}
 
 type innerPointValues struct {
-	size     int
-	docCount int
+	reader *innerPointsReader
 }
 
-func (i *innerPointValues) Intersect(ctx context.Context, visitor types.IntersectVisitor) error {
-	//TODO implement me
-	panic("implement me")
+func (i *innerPointValues) Intersect(ctx context.Context, mergedVisitor types.IntersectVisitor) error {
+	mergeState := i.reader.mergeState
+	fieldName := i.reader.fieldInfo.Name()
+
+	// Forward to the merged visitor
+	for idx, pointsReader := range mergeState.PointsReaders {
+		if pointsReader == nil {
+			// This segment has no points
+			continue
+		}
+		readerFieldInfo := mergeState.FieldInfos[idx].FieldInfo(fieldName)
+		if readerFieldInfo == nil || readerFieldInfo.GetPointIndexDimensionCount() == 0 {
+			// This segment never saw this field
+			continue
+		}
+
+		values, err := pointsReader.GetValues(ctx, fieldName)
+		if err != nil {
+			return err
+		}
+		if values == nil {
+			continue
+		}
+
+		docMap := mergeState.DocMaps[idx]
+		if err := values.Intersect(ctx, &types.BytesVisitor{
+			VisitFn: func(docID int) error {
+				// Should never be called because our compare method never returns Relation.CELL_INSIDE_QUERY
+				return errors.New("unexpected visit of a whole cell")
+			},
+			VisitLeafFn: func(ctx context.Context, docID int, packedValue []byte) error {
+				newDocID := docMap.Get(docID)
+				if newDocID == -1 {
+					// Doc was deleted
+					return nil
+				}
+				return mergedVisitor.VisitLeaf(ctx, newDocID, packedValue)
+			},
+			CompareFn: func(minPackedValue, maxPackedValue []byte) types.Relation {
+				// Forces this segment's PointsReader to always visit all docs + values:
+				return types.CELL_CROSSES_QUERY
+			},
+			GrowFn: func(count int) {},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (i *innerPointValues) EstimatePointCount(ctx context.Context, visitor types.IntersectVisitor) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) EstimateDocCount(ctx context.Context, visitor types.IntersectVisitor) (int, error) {
@@ -96,40 +145,35 @@ func (i *innerPointValues) EstimateDocCount(ctx context.Context, visitor types.I
 }
 
 func (i *innerPointValues) GetMinPackedValue() ([]byte, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) GetMaxPackedValue() ([]byte, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) GetNumDimensions() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) GetNumIndexDimensions() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) GetBytesPerDimension() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (i *innerPointValues) Size() int {
-	return i.size
+	return i.reader.size
 }
 
 func (i *innerPointValues) GetDocCount() int {
-	return i.docCount
+	return i.reader.docCount
 }
 
 // Merge Default merge implementation to merge incoming points readers by visiting all their points and adding to this writer
-func (p *BasePointsWriter) Merge(mergeState *MergeState) error {
+func (p *BasePointsWriter) Merge(ctx context.Context, mergeState *MergeState) error {
 	// check each incoming reader
 	for _, reader := range mergeState.PointsReaders {
 		if reader == nil {
@@ -144,7 +188,7 @@ func (p *BasePointsWriter) Merge(mergeState *MergeState) error {
 		if fieldInfo.GetPointDimensionCount() == 0 {
 			continue
 		}
-		if err := p.MergeOneField(nil, mergeState, fieldInfo); err != nil {
+		if err := p.MergeOneField(ctx, mergeState, fieldInfo); err != nil {
 			return err
 		}
 	}
@@ -66,9 +66,11 @@ func (b *baseCompositeReader) GetMetaData() index.LeafMetaData {
 	panic("implement me")
 }
 
+// GetReaderCacheHelper
+// A composite reader is not suited for caching unless it provides its own helper, see
+// StandardDirectoryReader.
 func (b *baseCompositeReader) GetReaderCacheHelper() index.CacheHelper {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (b *baseCompositeReader) GetSequentialSubReaders() []index.IndexReader {
@@ -78,10 +80,12 @@ func (b *baseCompositeReader) GetSequentialSubReaders() []index.IndexReader {
 func newBaseCompositeReader(subReaders []index.IndexReader,
 	subReadersSorter func(a, b index.LeafReader) int) (*baseCompositeReader, error) {
 
-	sort.Sort(&ReaderSorter{
-		Readers:   subReaders,
-		FnCompare: subReadersSorter,
-	})
+	if subReadersSorter != nil {
+		sort.Sort(&ReaderSorter{
+			Readers:   subReaders,
+			FnCompare: subReadersSorter,
+		})
+	}
 
 	reader := &baseCompositeReader{
 		subReaders:       subReaders,
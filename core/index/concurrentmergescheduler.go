@@ -0,0 +1,503 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/geange/lucene-go/core/store"
+)
+
+const (
+	// AUTO_DETECT_MERGES_AND_THREADS
+	// Dynamic default for maxThreadCount and maxMergeCount, used to detect the number of CPU cores
+	// and set the limits accordingly.
+	AUTO_DETECT_MERGES_AND_THREADS = -1
+
+	// MIN_BIG_MERGE_MB
+	// Floor for IO write rate limit (we will never go any lower than this)
+	MIN_BIG_MERGE_MB = 50.0
+
+	// START_MB_PER_SEC
+	// Initial value for IO write rate limit when doAutoIOThrottle is true
+	START_MB_PER_SEC = 20.0
+
+	// MIN_MERGE_MB_PER_SEC
+	// Floor for IO write rate limit (we will never go any lower than this)
+	MIN_MERGE_MB_PER_SEC = 5.0
+
+	// MAX_MERGE_MB_PER_SEC
+	// Ceiling for IO write rate limit (we will never go any higher than this)
+	MAX_MERGE_MB_PER_SEC = 10240.0
+)
+
+var _ MergeScheduler = &ConcurrentMergeScheduler{}
+
+// ConcurrentMergeScheduler
+// A MergeScheduler that runs each merge using a separate goroutine.
+//
+// Specify the max number of goroutines that may run at once, and the maximum number of simultaneous
+// merges with SetMaxMergesAndThreads.
+//
+// If the number of merges exceeds the max number of goroutines then the largest merges are paused
+// until one of the smaller merges completes.
+//
+// If more than GetMaxMergeCount merges are requested then this class will forcefully throttle the
+// incoming goroutines by pausing until one more merges complete.
+//
+// This class attempts to detect the number of CPU cores and sets the default limits accordingly,
+// see SetMaxMergesAndThreads. The IO rate of big merges is throttled adaptively, the rate goes up
+// when merges fall behind and down otherwise, see EnableAutoIOThrottle.
+type ConcurrentMergeScheduler struct {
+	lock sync.Mutex
+	// cond is signalled whenever a merge goroutine exits
+	cond *sync.Cond
+
+	// List of currently active merges.
+	mergeThreads []*mergeThread
+
+	// Max number of merge goroutines allowed to be running at once. When there are more merges
+	// then this, we forcefully pause the larger ones, letting the smaller ones run, up until
+	// maxMergeCount merges at which point we forcefully pause incoming goroutines (that presumably
+	// are the ones causing so much merging).
+	maxThreadCount int
+
+	// Max number of merges we accept before forcefully throttling the incoming goroutines
+	maxMergeCount int
+
+	// How many merge goroutines have been started.
+	mergeThreadCount int
+
+	// Current IO writes throttle rate
+	targetMBPerSec float64
+
+	// true if we should rate-limit writes for each merge
+	doAutoIOThrottle bool
+
+	forceMergeMBPerSec float64
+
+	wg sync.WaitGroup
+
+	// errors of merges which failed since the last Close
+	errs []error
+}
+
+// One merge running in its own goroutine.
+type mergeThread struct {
+	name        string
+	merge       *OneMerge
+	rateLimiter *MergeRateLimiter
+}
+
+func NewConcurrentMergeScheduler() *ConcurrentMergeScheduler {
+	scheduler := &ConcurrentMergeScheduler{
+		maxThreadCount:     AUTO_DETECT_MERGES_AND_THREADS,
+		maxMergeCount:      AUTO_DETECT_MERGES_AND_THREADS,
+		targetMBPerSec:     START_MB_PER_SEC,
+		doAutoIOThrottle:   true,
+		forceMergeMBPerSec: math.Inf(1),
+	}
+	scheduler.cond = sync.NewCond(&scheduler.lock)
+	return scheduler
+}
+
+// SetMaxMergesAndThreads
+// Expert: directly set the maximum number of merge goroutines and simultaneous merges allowed.
+//
+// maxMergeCount: the max # simultaneous merges that are allowed. If a merge is necessary yet we
+// already have this many goroutines running, the incoming goroutine (that is calling
+// add/updateDocument) will block until a merge goroutine has completed. Note that we will only run
+// the smallest maxThreadCount merges at a time.
+//
+// maxThreadCount: the max # simultaneous merge goroutines that should be running at once. This
+// must be <= maxMergeCount
+func (c *ConcurrentMergeScheduler) SetMaxMergesAndThreads(maxMergeCount, maxThreadCount int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if maxMergeCount == AUTO_DETECT_MERGES_AND_THREADS && maxThreadCount == AUTO_DETECT_MERGES_AND_THREADS {
+		// OK
+		c.maxMergeCount = AUTO_DETECT_MERGES_AND_THREADS
+		c.maxThreadCount = AUTO_DETECT_MERGES_AND_THREADS
+		return nil
+	}
+	if maxMergeCount == AUTO_DETECT_MERGES_AND_THREADS || maxThreadCount == AUTO_DETECT_MERGES_AND_THREADS {
+		return errors.New("both maxMergeCount and maxThreadCount must be AUTO_DETECT_MERGES_AND_THREADS")
+	}
+	if maxThreadCount < 1 {
+		return errors.New("maxThreadCount should be at least 1")
+	}
+	if maxMergeCount < 1 {
+		return errors.New("maxMergeCount should be at least 1")
+	}
+	if maxThreadCount > maxMergeCount {
+		return fmt.Errorf("maxThreadCount should be <= maxMergeCount (= %d)", maxMergeCount)
+	}
+	c.maxThreadCount = maxThreadCount
+	c.maxMergeCount = maxMergeCount
+	return nil
+}
+
+// GetMaxThreadCount
+// Returns maxThreadCount.
+func (c *ConcurrentMergeScheduler) GetMaxThreadCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.maxThreadCount
+}
+
+// GetMaxMergeCount
+// See SetMaxMergesAndThreads.
+func (c *ConcurrentMergeScheduler) GetMaxMergeCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.maxMergeCount
+}
+
+// SetForceMergeMBPerSec
+// Set the per-merge IO throttle rate for forced merges (default: +Inf).
+func (c *ConcurrentMergeScheduler) SetForceMergeMBPerSec(v float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.forceMergeMBPerSec = v
+	c.updateMergeThreads()
+}
+
+// GetForceMergeMBPerSec
+// Get the per-merge IO throttle rate for forced merges.
+func (c *ConcurrentMergeScheduler) GetForceMergeMBPerSec() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.forceMergeMBPerSec
+}
+
+// EnableAutoIOThrottle
+// Turn on dynamic IO throttling, to adaptively rate limit writes bytes/sec to the minimal rate
+// necessary so merges do not fall behind. By default this is enabled.
+func (c *ConcurrentMergeScheduler) EnableAutoIOThrottle() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.doAutoIOThrottle = true
+	c.targetMBPerSec = START_MB_PER_SEC
+	c.updateMergeThreads()
+}
+
+// DisableAutoIOThrottle
+// Turn off auto IO throttling.
+func (c *ConcurrentMergeScheduler) DisableAutoIOThrottle() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.doAutoIOThrottle = false
+	c.updateMergeThreads()
+}
+
+// GetAutoIOThrottle
+// Returns true if auto IO throttling is enabled.
+func (c *ConcurrentMergeScheduler) GetAutoIOThrottle() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.doAutoIOThrottle
+}
+
+// GetIORateLimitMBPerSec
+// Returns the currently set per-merge IO writes rate limit, if EnableAutoIOThrottle was called,
+// else +Inf.
+func (c *ConcurrentMergeScheduler) GetIORateLimitMBPerSec() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.doAutoIOThrottle {
+		return c.targetMBPerSec
+	}
+	return math.Inf(1)
+}
+
+// MergeThreadCount
+// Returns the number of merge goroutines that are alive, ie not yet finished.
+func (c *ConcurrentMergeScheduler) MergeThreadCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.mergeThreads)
+}
+
+func (c *ConcurrentMergeScheduler) Initialize(dir store.Directory) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.initDynamicDefaults()
+}
+
+// Sets max merges and goroutines to proper defaults for the number of CPU cores. c.lock must be held.
+func (c *ConcurrentMergeScheduler) initDynamicDefaults() {
+	if c.maxThreadCount == AUTO_DETECT_MERGES_AND_THREADS {
+		c.maxThreadCount = max(1, min(4, runtime.NumCPU()/2))
+		c.maxMergeCount = c.maxThreadCount + 5
+	}
+}
+
+func (c *ConcurrentMergeScheduler) Merge(mergeSource MergeSource, trigger MergeTrigger) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.initDynamicDefaults()
+
+	if trigger == MERGE_TRIGGER_CLOSING {
+		// Disable throttling on close:
+		c.targetMBPerSec = MAX_MERGE_MB_PER_SEC
+		c.updateMergeThreads()
+	}
+
+	// First, quickly run through the newly proposed merges
+	// and add any orthogonal merges (ie a merge not
+	// involving segments already pending to be merged) to
+	// the queue.  If we are way behind on merging, many of
+	// these newly proposed merges will likely already be
+	// registered.
+	for {
+		if !c.maybeStall(mergeSource, trigger) {
+			return nil
+		}
+
+		merge, err := mergeSource.GetNextMerge()
+		if err != nil {
+			return err
+		}
+		if merge == nil {
+			return nil
+		}
+
+		// OK to spawn a new merge goroutine to handle this
+		// merge:
+		c.mergeThreadCount++
+		thread := &mergeThread{
+			name:        fmt.Sprintf("Lucene Merge Thread #%d", c.mergeThreadCount),
+			merge:       merge,
+			rateLimiter: NewMergeRateLimiter(merge.GetMergeProgress()),
+		}
+		c.mergeThreads = append(c.mergeThreads, thread)
+		c.updateIOThrottle(merge)
+
+		c.wg.Add(1)
+		go c.runMergeThread(mergeSource, thread)
+
+		c.updateMergeThreads()
+	}
+}
+
+// This is invoked by Merge to possibly stall the incoming goroutine when there are too many merges
+// running or pending. The default behavior is to force this goroutine, which is producing too many
+// segments for merging to keep up, to wait until merges catch up. Merge goroutines themselves
+// (trigger MERGE_FINISHED) are never stalled. c.lock must be held.
+//
+// Returns false if the goroutine should not run any merges.
+func (c *ConcurrentMergeScheduler) maybeStall(mergeSource MergeSource, trigger MergeTrigger) bool {
+	for mergeSource.HasPendingMerges() && len(c.mergeThreads) >= c.maxMergeCount {
+		// This means merging has fallen too far behind: we
+		// have already created maxMergeCount goroutines, and
+		// now there's at least one more merge pending.
+		// Note that only maxThreadCount of
+		// those created merge goroutines will actually be
+		// running; the rest will be paused (see
+		// updateMergeThreads).  We stall this producer
+		// goroutine to prevent creation of new segments,
+		// until merging has caught up:
+		if trigger == MERGE_TRIGGER_MERGE_FINISHED {
+			// Never stall a merge goroutine since this blocks it from
+			// finishing and calling updateMergeThreads, and blocking it
+			// accomplishes nothing anyway (it's not really a segment producer):
+			return false
+		}
+		c.cond.Wait()
+	}
+	return true
+}
+
+func (c *ConcurrentMergeScheduler) runMergeThread(mergeSource MergeSource, thread *mergeThread) {
+	defer c.wg.Done()
+
+	err := mergeSource.Merge(thread.merge)
+	if err == nil {
+		// Let CMS run new merges if necessary:
+		err = c.Merge(mergeSource, MERGE_TRIGGER_MERGE_FINISHED)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err != nil && !errors.Is(err, ErrMergeAborted) {
+		c.errs = append(c.errs, fmt.Errorf("%s: %w", thread.name, err))
+	}
+
+	c.mergeThreads = slices.DeleteFunc(c.mergeThreads, func(t *mergeThread) bool {
+		return t == thread
+	})
+	c.updateMergeThreads()
+
+	// In case we had stalled indexing, we can now wake up
+	// and possibly unstall:
+	c.cond.Broadcast()
+}
+
+// Called whenever the running merges have changed, to set merge IO limits. This method sorts the
+// merge goroutines by their merge size in descending order and then pauses/unpauses goroutines
+// from first to last -- that way, the smallest merges get priority over larger merges.
+// c.lock must be held.
+func (c *ConcurrentMergeScheduler) updateMergeThreads() {
+	activeMerges := slices.Clone(c.mergeThreads)
+
+	// Sort the merge goroutines, largest first:
+	slices.SortStableFunc(activeMerges, func(a, b *mergeThread) int {
+		return cmp.Compare(b.merge.estimatedMergeBytes, a.merge.estimatedMergeBytes)
+	})
+
+	bigMergeCount := 0
+	for i := len(activeMerges) - 1; i >= 0; i-- {
+		if float64(activeMerges[i].merge.estimatedMergeBytes) > MIN_BIG_MERGE_MB*1024*1024 {
+			bigMergeCount = 1 + i
+			break
+		}
+	}
+
+	for i, thread := range activeMerges {
+		merge := thread.merge
+
+		// pause the goroutine if maxThreadCount is smaller than the number of merge goroutines.
+		doPause := i < bigMergeCount-c.maxThreadCount
+
+		var newMBPerSec float64
+		switch {
+		case doPause:
+			newMBPerSec = 0.0
+		case merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS:
+			newMBPerSec = c.forceMergeMBPerSec
+		case !c.doAutoIOThrottle:
+			newMBPerSec = math.Inf(1)
+		case float64(merge.estimatedMergeBytes) < MIN_BIG_MERGE_MB*1024*1024:
+			// Don't rate limit small merges:
+			newMBPerSec = math.Inf(1)
+		default:
+			newMBPerSec = c.targetMBPerSec
+		}
+
+		if thread.rateLimiter.GetMBPerSec() != newMBPerSec {
+			_ = thread.rateLimiter.SetMBPerSec(newMBPerSec)
+		}
+	}
+}
+
+// Simplistic closed-loop feedback control: if we find any other similarly sized merges running,
+// then we are falling behind, so we bump up the IO throttle, else we lower it. c.lock must be held.
+func (c *ConcurrentMergeScheduler) updateIOThrottle(newMerge *OneMerge) {
+	if !c.doAutoIOThrottle {
+		return
+	}
+
+	mergeMB := bytesToMB(newMerge.estimatedMergeBytes)
+	if mergeMB < MIN_BIG_MERGE_MB {
+		// Only watch non-trivial merges for throttling; this is safe because the MP must eventually
+		// have to do larger merges:
+		return
+	}
+
+	now := time.Now().UnixNano()
+
+	newBacklog := c.isBacklog(now, newMerge)
+	curBacklog := false
+	if !newBacklog {
+		if len(c.mergeThreads) > c.maxThreadCount {
+			// If there are already more than the maximum merge goroutines allowed, count that as backlog:
+			curBacklog = true
+		} else {
+			// Now see if any still-running merges are backlog'd:
+			for _, thread := range c.mergeThreads {
+				if c.isBacklog(now, thread.merge) {
+					curBacklog = true
+					break
+				}
+			}
+		}
+	}
+
+	switch {
+	case newBacklog:
+		// This new merge adds to the backlog: increase IO throttle by 20%
+		c.targetMBPerSec = min(c.targetMBPerSec*1.20, MAX_MERGE_MB_PER_SEC)
+	case curBacklog:
+		// We still have an existing backlog; leave the rate as is:
+	default:
+		// We are not falling behind: decrease IO throttle by 10%
+		c.targetMBPerSec = max(c.targetMBPerSec/1.10, MIN_MERGE_MB_PER_SEC)
+	}
+
+	c.updateMergeThreads()
+}
+
+// Returns true if another similarly sized big merge is running for more than 3 seconds.
+func (c *ConcurrentMergeScheduler) isBacklog(now int64, merge *OneMerge) bool {
+	mergeMB := bytesToMB(merge.estimatedMergeBytes)
+	for _, thread := range c.mergeThreads {
+		other := thread.merge
+		mergeStartNS := other.mergeStartNS.Load()
+		if other != merge &&
+			mergeStartNS != 0 &&
+			float64(other.estimatedMergeBytes) >= MIN_BIG_MERGE_MB*1024*1024 &&
+			time.Duration(now-mergeStartNS) > 3*time.Second {
+			ratio := bytesToMB(other.estimatedMergeBytes) / mergeMB
+			if ratio > 0.3 && ratio < 3.0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WrapForMerge
+// Returns a Directory whose outputs are throttled by the rate limiter of the merge's goroutine.
+func (c *ConcurrentMergeScheduler) WrapForMerge(merge *OneMerge, in store.Directory) store.Directory {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, thread := range c.mergeThreads {
+		if thread.merge == merge {
+			return &rateLimitedDirectory{Directory: in, rateLimiter: thread.rateLimiter}
+		}
+	}
+	// not one of our merges
+	return in
+}
+
+// Close
+// Waits for all running merges to finish and returns the errors of failed merges.
+func (c *ConcurrentMergeScheduler) Close() error {
+	c.wg.Wait()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err := errors.Join(c.errs...)
+	c.errs = nil
+	return err
+}
+
+func bytesToMB(bytes int64) float64 {
+	return float64(bytes) / 1024 / 1024
+}
+
+// rateLimitedDirectory rate limits all outputs created through it, it is only used while merging
+type rateLimitedDirectory struct {
+	store.Directory
+
+	rateLimiter store.RateLimiter
+}
+
+func (r *rateLimitedDirectory) CreateOutput(ctx context.Context, name string) (store.IndexOutput, error) {
+	output, err := r.Directory.CreateOutput(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return store.NewRateLimitedIndexOutput(r.rateLimiter, output), nil
+}
@@ -0,0 +1,268 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/version"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMergeSource hands out queued merges, each merge runs until its release channel is closed or
+// the merge is aborted.
+type testMergeSource struct {
+	sync.Mutex
+
+	pending  []*OneMerge
+	released map[*OneMerge]chan struct{}
+	started  chan *OneMerge
+	run      func(merge *OneMerge) error
+}
+
+func newTestMergeSource(merges ...*OneMerge) *testMergeSource {
+	source := &testMergeSource{
+		pending:  merges,
+		released: map[*OneMerge]chan struct{}{},
+		started:  make(chan *OneMerge, len(merges)),
+	}
+	for _, merge := range merges {
+		source.released[merge] = make(chan struct{})
+	}
+	return source
+}
+
+func (s *testMergeSource) GetNextMerge() (*OneMerge, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	merge := s.pending[0]
+	s.pending = s.pending[1:]
+	return merge, nil
+}
+
+func (s *testMergeSource) OnMergeFinished(merge *OneMerge) error {
+	return nil
+}
+
+func (s *testMergeSource) HasPendingMerges() bool {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.pending) != 0
+}
+
+func (s *testMergeSource) Merge(merge *OneMerge) error {
+	s.started <- merge
+	if s.run != nil {
+		if err := s.run(merge); err != nil {
+			return err
+		}
+	}
+	for !merge.IsAborted() {
+		select {
+		case <-s.released[merge]:
+			return nil
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return ErrMergeAborted
+}
+
+func (s *testMergeSource) release(merge *OneMerge) {
+	close(s.released[merge])
+}
+
+func newTestMerge(t *testing.T, name string, estimatedMB float64) *OneMerge {
+	si := NewSegmentInfo(store.NewRAMDirectory(), version.Last, nil, name, 10, false, nil,
+		map[string]string{}, nil, map[string]string{}, nil)
+	merge, err := NewOneMerge([]index.SegmentCommitInfo{index.NewSegmentCommitInfo(si, 0, 0, -1, -1, -1, nil)})
+	assert.Nil(t, err)
+	merge.estimatedMergeBytes = int64(estimatedMB * 1024 * 1024)
+	return merge
+}
+
+// mergeRates Returns the IO rate of every running merge
+func mergeRates(c *ConcurrentMergeScheduler) map[*OneMerge]float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	rates := map[*OneMerge]float64{}
+	for _, thread := range c.mergeThreads {
+		rates[thread.merge] = thread.rateLimiter.GetMBPerSec()
+	}
+	return rates
+}
+
+func TestConcurrentMergeScheduler_MaxMergeCount(t *testing.T) {
+	merges := []*OneMerge{newTestMerge(t, "_0", 1), newTestMerge(t, "_1", 1), newTestMerge(t, "_2", 1)}
+	source := newTestMergeSource(merges...)
+
+	cms := NewConcurrentMergeScheduler()
+	assert.Nil(t, cms.SetMaxMergesAndThreads(2, 1))
+
+	done := make(chan error)
+	go func() {
+		done <- cms.Merge(source, MERGE_TRIGGER_EXPLICIT)
+	}()
+	<-source.started
+	<-source.started
+
+	// the producer is stalled while maxMergeCount merges run and another one is pending
+	select {
+	case <-done:
+		t.Fatal("Merge must stall until a merge finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 2, cms.MergeThreadCount())
+	assert.True(t, source.HasPendingMerges())
+
+	source.release(merges[0])
+	assert.Nil(t, <-done)
+	assert.Same(t, merges[2], <-source.started)
+	assert.False(t, source.HasPendingMerges())
+
+	source.release(merges[1])
+	source.release(merges[2])
+	assert.Nil(t, cms.Close())
+	assert.Equal(t, 0, cms.MergeThreadCount())
+}
+
+func TestConcurrentMergeScheduler_MaxThreadCount(t *testing.T) {
+	small := newTestMerge(t, "_0", 60)
+	medium := newTestMerge(t, "_1", 80)
+	large := newTestMerge(t, "_2", 100)
+	tiny := newTestMerge(t, "_3", 1)
+	source := newTestMergeSource(large, medium, small, tiny)
+
+	cms := NewConcurrentMergeScheduler()
+	assert.Nil(t, cms.SetMaxMergesAndThreads(4, 1))
+	cms.DisableAutoIOThrottle()
+
+	assert.Nil(t, cms.Merge(source, MERGE_TRIGGER_EXPLICIT))
+	for i := 0; i < 4; i++ {
+		<-source.started
+	}
+
+	// only the smallest big merge runs, small merges are never paused
+	rates := mergeRates(cms)
+	assert.Equal(t, 0.0, rates[large])
+	assert.Equal(t, 0.0, rates[medium])
+	assert.Equal(t, math.Inf(1), rates[small])
+	assert.Equal(t, math.Inf(1), rates[tiny])
+
+	source.release(small)
+	assert.Eventually(t, func() bool {
+		return cms.MergeThreadCount() == 3
+	}, time.Second, 10*time.Millisecond)
+	rates = mergeRates(cms)
+	assert.Equal(t, 0.0, rates[large])
+	assert.Equal(t, math.Inf(1), rates[medium])
+
+	source.release(medium)
+	source.release(large)
+	source.release(tiny)
+	assert.Nil(t, cms.Close())
+}
+
+func TestConcurrentMergeScheduler_IOThrottle(t *testing.T) {
+	big := newTestMerge(t, "_0", 100)
+	forced := newTestMerge(t, "_1", 100)
+	forced.maxNumSegments = 1
+	small := newTestMerge(t, "_2", 1)
+	source := newTestMergeSource(big, forced, small)
+
+	cms := NewConcurrentMergeScheduler()
+	assert.Nil(t, cms.SetMaxMergesAndThreads(3, 3))
+	cms.SetForceMergeMBPerSec(30)
+	assert.True(t, cms.GetAutoIOThrottle())
+
+	assert.Nil(t, cms.Merge(source, MERGE_TRIGGER_EXPLICIT))
+	for i := 0; i < 3; i++ {
+		<-source.started
+	}
+
+	// no backlog, the rate went down by 10% for each big merge
+	target := START_MB_PER_SEC / 1.10 / 1.10
+	assert.InDelta(t, target, cms.GetIORateLimitMBPerSec(), 1e-9)
+	rates := mergeRates(cms)
+	assert.InDelta(t, target, rates[big], 1e-9)
+	assert.Equal(t, 30.0, rates[forced])
+	assert.Equal(t, math.Inf(1), rates[small])
+
+	cms.DisableAutoIOThrottle()
+	assert.Equal(t, math.Inf(1), mergeRates(cms)[big])
+
+	source.release(big)
+	source.release(forced)
+	source.release(small)
+	assert.Nil(t, cms.Close())
+}
+
+func TestConcurrentMergeScheduler_WrapForMerge(t *testing.T) {
+	merge := newTestMerge(t, "_0", 1)
+	merge.maxNumSegments = 1
+	source := newTestMergeSource(merge)
+
+	cms := NewConcurrentMergeScheduler()
+	cms.SetForceMergeMBPerSec(10)
+
+	elapsed := make(chan time.Duration, 1)
+	source.run = func(merge *OneMerge) error {
+		dir := cms.WrapForMerge(merge, store.NewRAMDirectory())
+		output, err := dir.CreateOutput(context.Background(), "_0.fdt")
+		if err != nil {
+			return err
+		}
+		// 2 MB at 10 MB/sec must take about 200 msec, the first pause check does not pause
+		start := time.Now()
+		data := make([]byte, 4096)
+		for i := 0; i < 512; i++ {
+			if _, err := output.Write(data); err != nil {
+				return err
+			}
+		}
+		elapsed <- time.Since(start)
+		return output.Close()
+	}
+
+	assert.Nil(t, cms.Merge(source, MERGE_TRIGGER_EXPLICIT))
+	<-source.started
+	assert.GreaterOrEqual(t, <-elapsed, 150*time.Millisecond)
+
+	source.release(merge)
+	assert.Nil(t, cms.Close())
+
+	// other merges are not throttled
+	dir := store.NewRAMDirectory()
+	assert.Same(t, dir, cms.WrapForMerge(newTestMerge(t, "_1", 1), dir))
+}
+
+func TestConcurrentMergeScheduler_Errors(t *testing.T) {
+	failed := newTestMerge(t, "_0", 1)
+	aborted := newTestMerge(t, "_1", 1)
+	source := newTestMergeSource(failed, aborted)
+	source.run = func(merge *OneMerge) error {
+		if merge == failed {
+			return fmt.Errorf("disk full")
+		}
+		return nil
+	}
+
+	cms := NewConcurrentMergeScheduler()
+	assert.Nil(t, cms.Merge(source, MERGE_TRIGGER_EXPLICIT))
+	aborted.SetAborted()
+
+	// aborted merges are not reported
+	err := cms.Close()
+	assert.ErrorContains(t, err, "disk full")
+	assert.NotErrorIs(t, err, ErrMergeAborted)
+	assert.Nil(t, cms.Close())
+}
@@ -0,0 +1,198 @@
+package index_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/simpletext"
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// crashingDirectory
+// Wraps a NIOFSDirectory and remembers what is not durable yet: files written since their last Sync
+// and renames since the last SyncMetaData. crash throws all of it away, which is the worst an OS
+// crash or a power loss may do to the directory.
+type crashingDirectory struct {
+	*store.NIOFSDirectory
+
+	mu       sync.Mutex
+	path     string
+	unsynced map[string]struct{}
+	renames  [][2]string
+}
+
+func newCrashingDirectory(t *testing.T, path string) *crashingDirectory {
+	dir, err := store.NewNIOFSDirectory(path)
+	assert.Nil(t, err)
+	return &crashingDirectory{
+		NIOFSDirectory: dir,
+		path:           path,
+		unsynced:       map[string]struct{}{},
+	}
+}
+
+func (c *crashingDirectory) CreateOutput(ctx context.Context, name string) (store.IndexOutput, error) {
+	output, err := c.NIOFSDirectory.CreateOutput(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsynced[name] = struct{}{}
+	return output, nil
+}
+
+func (c *crashingDirectory) CreateTempOutput(ctx context.Context, prefix, suffix string) (store.IndexOutput, error) {
+	output, err := c.NIOFSDirectory.CreateTempOutput(ctx, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsynced[output.GetName()] = struct{}{}
+	return output, nil
+}
+
+func (c *crashingDirectory) Sync(names map[string]struct{}) error {
+	if err := c.NIOFSDirectory.Sync(names); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range names {
+		delete(c.unsynced, name)
+	}
+	return nil
+}
+
+func (c *crashingDirectory) Rename(ctx context.Context, source, dest string) error {
+	if err := c.NIOFSDirectory.Rename(ctx, source, dest); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.renames = append(c.renames, [2]string{source, dest})
+	if _, ok := c.unsynced[source]; ok {
+		delete(c.unsynced, source)
+		c.unsynced[dest] = struct{}{}
+	}
+	return nil
+}
+
+func (c *crashingDirectory) SyncMetaData(ctx context.Context) error {
+	if err := c.NIOFSDirectory.SyncMetaData(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.renames = c.renames[:0]
+	return nil
+}
+
+func (c *crashingDirectory) DeleteFile(ctx context.Context, name string) error {
+	if err := c.NIOFSDirectory.DeleteFile(ctx, name); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.unsynced, name)
+	return nil
+}
+
+// crash Undoes the renames that were not made durable, then removes the files whose contents
+// were never synced.
+func (c *crashingDirectory) crash(t *testing.T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rename := range slices.Backward(c.renames) {
+		source, dest := rename[0], rename[1]
+		assert.Nil(t, os.Rename(filepath.Join(c.path, dest), filepath.Join(c.path, source)))
+		if _, ok := c.unsynced[dest]; ok {
+			delete(c.unsynced, dest)
+			c.unsynced[source] = struct{}{}
+		}
+	}
+	c.renames = nil
+
+	for name := range c.unsynced {
+		assert.Nil(t, os.Remove(filepath.Join(c.path, name)))
+	}
+	c.unsynced = map[string]struct{}{}
+	assert.Nil(t, c.NIOFSDirectory.Close())
+}
+
+func newCrashTestWriter(t *testing.T, dir store.Directory) *index.IndexWriter {
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := index.NewIndexWriterConfig(simpletext.NewCodec(), similarity)
+	writer, err := index.NewIndexWriter(context.Background(), dir, config)
+	assert.Nil(t, err)
+	// the crash leaves the writer open, as the process it stands for never got to close it
+	t.Cleanup(func() { _ = writer.Rollback(context.Background()) })
+	return writer
+}
+
+func addCrashTestDocuments(t *testing.T, writer *index.IndexWriter, n int) {
+	for i := 0; i < n; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewTextField("content", "a b c", true))
+		_, err := writer.AddDocument(context.Background(), doc)
+		assert.Nil(t, err)
+	}
+}
+
+// assertRecoveredNumDocs Opens the directory after a crash, as a restarted process would
+func assertRecoveredNumDocs(t *testing.T, path string, numDocs int) {
+	dir, err := store.NewNIOFSDirectory(path)
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	reader, err := index.OpenDirectoryReader(context.Background(), dir, nil, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, numDocs, reader.NumDocs())
+		assert.Nil(t, reader.Close())
+	}
+}
+
+func TestCrashAfterCommit(t *testing.T) {
+	path := t.TempDir()
+	dir := newCrashingDirectory(t, path)
+
+	writer := newCrashTestWriter(t, dir)
+	addCrashTestDocuments(t, writer, 3)
+	assert.Nil(t, writer.Commit(context.Background()))
+
+	dir.crash(t)
+	assertRecoveredNumDocs(t, path, 3)
+}
+
+func TestCrashWithUncommittedChanges(t *testing.T) {
+	path := t.TempDir()
+	dir := newCrashingDirectory(t, path)
+
+	writer := newCrashTestWriter(t, dir)
+	addCrashTestDocuments(t, writer, 3)
+	assert.Nil(t, writer.Commit(context.Background()))
+	addCrashTestDocuments(t, writer, 2)
+	assert.Nil(t, writer.Commit(context.Background()))
+
+	// flushed to new segment files, but never committed
+	addCrashTestDocuments(t, writer, 4)
+	reader, err := writer.GetReader(context.Background(), true, false)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 9, reader.NumDocs())
+		assert.Nil(t, reader.Close())
+	}
+
+	dir.crash(t)
+	assertRecoveredNumDocs(t, path, 5)
+}
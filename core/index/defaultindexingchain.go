@@ -218,22 +218,27 @@ func (d *DefaultIndexingChain) Flush(ctx context.Context, state *index.SegmentWr
 	fieldsToFlush := make(map[string]TermsHashPerField)
 
 	for _, perField := range d.fieldHash {
-		fieldsToFlush[perField.fieldInfo.Name()] = perField.termsHashPerField
+		// stored only fields are not inverted
+		if perField.invertState != nil {
+			fieldsToFlush[perField.fieldInfo.Name()] = perField.termsHashPerField
+		}
 	}
 
 	readState := index.NewSegmentReadState(state.Directory, state.SegmentInfo, state.FieldInfos, state.Context, state.SegmentSuffix)
 
-	//var norms NormsProducer
+	var normsMergeInstance index.NormsProducer
 	if readState.FieldInfos.HasNorms() {
 		norms, err := state.SegmentInfo.GetCodec().NormsFormat().NormsProducer(ctx, readState)
 		if err != nil {
 			return nil, err
 		}
+		defer norms.Close()
 
-		normsMergeInstance := norms.GetMergeInstance()
-		if err := d.termsHash.Flush(ctx, fieldsToFlush, state, sortMap, normsMergeInstance); err != nil {
-			return nil, err
-		}
+		normsMergeInstance = norms.GetMergeInstance()
+	}
+
+	if err := d.termsHash.Flush(ctx, fieldsToFlush, state, sortMap, normsMergeInstance); err != nil {
+		return nil, err
 	}
 
 	if err := d.indexWriterConfig.GetCodec().FieldInfosFormat().
@@ -560,7 +565,7 @@ func (d *DefaultIndexingChain) indexDocValue(docID int,
 		}
 
 	case document.DOC_VALUES_TYPE_BINARY:
-		if fp.docValuesWriter != nil {
+		if fp.docValuesWriter == nil {
 			fp.docValuesWriter = NewBinaryDocValuesWriter(fp.fieldInfo)
 		}
 
@@ -574,15 +579,63 @@ func (d *DefaultIndexingChain) indexDocValue(docID int,
 		}
 
 	case document.DOC_VALUES_TYPE_SORTED:
-		return errors.New("unsupported DocValues.Type")
+		if fp.docValuesWriter == nil {
+			writer, err := NewSortedDocValuesWriter(fp.fieldInfo, d.docValuesBytePool)
+			if err != nil {
+				return err
+			}
+			fp.docValuesWriter = writer
+		}
+
+		bs, err := document.Bytes(field.Get())
+		if err != nil {
+			return err
+		}
+
+		if err := fp.docValuesWriter.(*SortedDocValuesWriter).AddValue(docID, bs); err != nil {
+			return err
+		}
+
 	case document.DOC_VALUES_TYPE_SORTED_NUMERIC:
-		return errors.New("unsupported DocValues.Type")
+		if fp.docValuesWriter == nil {
+			fp.docValuesWriter = NewSortedNumericDocValuesWriter(fp.fieldInfo)
+		}
+
+		obj, ok := field.Number()
+		if !ok {
+			return errors.New("field value is not number")
+		}
+		num, err := document.Int64(obj)
+		if err != nil {
+			return err
+		}
+
+		if err := fp.docValuesWriter.(*SortedNumericDocValuesWriter).AddValue(docID, num); err != nil {
+			return err
+		}
+
 	case document.DOC_VALUES_TYPE_SORTED_SET:
-		return errors.New("unsupported DocValues.Type")
+		if fp.docValuesWriter == nil {
+			writer, err := NewSortedSetDocValuesWriter(fp.fieldInfo, d.docValuesBytePool)
+			if err != nil {
+				return err
+			}
+			fp.docValuesWriter = writer
+		}
+
+		bs, err := document.Bytes(field.Get())
+		if err != nil {
+			return err
+		}
+
+		if err := fp.docValuesWriter.(*SortedSetDocValuesWriter).AddValue(docID, bs); err != nil {
+			return err
+		}
+
 	default:
-		return errors.New("unsupported DocValues.Type")
+		return errors.New("unrecognized DocValues.Type")
 	}
-	return errors.New("unrecognized DocValues.Type")
+	return nil
 }
 
 // Returns a previously created DefaultIndexingChain.PerField, absorbing the type information from FieldType,
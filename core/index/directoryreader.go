@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/geange/lucene-go/core/interface/index"
@@ -36,6 +37,48 @@ func DirectoryReaderOpenV1(ctx context.Context, writer *IndexWriter, applyAllDel
 	return writer.GetReader(ctx, applyAllDeletes, writeAllDeletes)
 }
 
+// OpenIfChanged
+// If the index has changed since the provided reader was opened, open and return a new reader;
+// else, return nil. The new reader, if not nil, will be the same type of reader as the previous
+// one, ie a near real-time reader will open a new near real-time reader.
+//
+// This method is typically far less costly than opening a fully new DirectoryReader as it shares
+// resources (for example sub-readers) with the provided DirectoryReader, when possible.
+//
+// The provided reader is not closed (you are responsible for doing so); if a new reader is
+// returned you also must eventually close it. Be sure to never close a reader while other
+// goroutines are still using it.
+func OpenIfChanged(ctx context.Context, oldReader index.DirectoryReader) (index.DirectoryReader, error) {
+	reader, ok := oldReader.(*StandardDirectoryReader)
+	if !ok {
+		return nil, fmt.Errorf("reopen is not supported by %T", oldReader)
+	}
+	return reader.doOpenIfChanged(ctx)
+}
+
+// OpenIfChangedFromWriter
+// Expert: If there changes (committed or not) in the IndexWriter versus what the provided reader
+// is searching, then open and return a new IndexReader searching both committed and uncommitted
+// changes from the writer; else, return nil (though, the current implementation never returns
+// nil).
+//
+// This provides "near real-time" searching, in that changes made during an IndexWriter session
+// can be quickly made available for searching without closing the writer nor calling Commit.
+//
+// applyAllDeletes: If true, all buffered deletes will be applied (made visible) in the returned
+// reader. If false, the deletes are not applied but remain buffered (in IndexWriter) so that they
+// will be applied in the future. Applying deletes can be costly, so if your app can tolerate
+// deleted documents being returned you might gain some performance by passing false.
+func OpenIfChangedFromWriter(ctx context.Context, oldReader index.DirectoryReader,
+	writer *IndexWriter, applyAllDeletes bool) (index.DirectoryReader, error) {
+
+	reader, ok := oldReader.(*StandardDirectoryReader)
+	if !ok {
+		return nil, fmt.Errorf("reopen is not supported by %T", oldReader)
+	}
+	return reader.doOpenIfChangedFromWriter(ctx, writer, applyAllDeletes)
+}
+
 func (d *baseDirectoryReader) Directory() store.Directory {
 	return d.directory
 }
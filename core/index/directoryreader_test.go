@@ -0,0 +1,147 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// newReopenTestWriter Opens a writer which never merges, every commit adds a segment
+func newReopenTestWriter(t *testing.T, dir store.Directory) *coreIndex.IndexWriter {
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity)
+	config.SetMergePolicy(coreIndex.NewNoMergePolicy())
+	config.SetUseCompoundFile(false)
+
+	writer, err := coreIndex.NewIndexWriter(context.Background(), dir, config)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = writer.Rollback(context.Background()) })
+	return writer
+}
+
+// leafReaders Returns the leaf readers of reader
+func leafReaders(t *testing.T, reader index.DirectoryReader) []index.LeafReader {
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+	readers := make([]index.LeafReader, 0, len(leaves))
+	for _, leaf := range leaves {
+		readers = append(readers, leaf.LeafReader())
+	}
+	return readers
+}
+
+func TestDirectoryReader_OpenIfChanged(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newReopenTestWriter(t, dir)
+	addSegments(t, writer, 2, 10)
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	// nothing was committed since the reader was opened
+	newReader, err := coreIndex.OpenIfChanged(ctx, reader)
+	assert.Nil(t, err)
+	assert.Nil(t, newReader)
+
+	// a new segment and deletes in the first one are committed
+	infos := coreIndex.Segments(writer)
+	deleted, err := coreIndex.DeleteDocument(ctx, writer, infos[0], 1)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+	addSegments(t, writer, 1, 5)
+
+	newReader, err = coreIndex.OpenIfChanged(ctx, reader)
+	assert.Nil(t, err)
+	assert.NotNil(t, newReader)
+	defer newReader.Close()
+
+	assert.Equal(t, 20, reader.NumDocs())
+	assert.Equal(t, 24, newReader.NumDocs())
+	assert.Equal(t, 1, newReader.NumDeletedDocs())
+
+	oldLeaves := leafReaders(t, reader)
+	newLeaves := leafReaders(t, newReader)
+	assert.Len(t, oldLeaves, 2)
+	assert.Len(t, newLeaves, 3)
+	// the unchanged segment is shared, the one with new deletes is reopened
+	assert.NotSame(t, oldLeaves[0], newLeaves[0])
+	assert.Same(t, oldLeaves[1], newLeaves[1])
+	assert.Equal(t, 10, oldLeaves[0].NumDocs())
+	assert.Equal(t, 9, newLeaves[0].NumDocs())
+
+	newerReader, err := coreIndex.OpenIfChanged(ctx, newReader)
+	assert.Nil(t, err)
+	assert.Nil(t, newerReader)
+
+	// the shared reader is still usable once the old reader is closed
+	assert.Nil(t, reader.Close())
+	assert.Equal(t, 10, newLeaves[1].NumDocs())
+	terms, err := newLeaves[1].Terms("id")
+	assert.Nil(t, err)
+	size, err := terms.Size()
+	assert.Nil(t, err)
+	assert.Equal(t, 10, size)
+}
+
+func TestDirectoryReader_OpenIfChangedFromWriter(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newReopenTestWriter(t, dir)
+	addSegments(t, writer, 1, 10)
+
+	reader, err := coreIndex.DirectoryReaderOpen(ctx, writer)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, 10, reader.NumDocs())
+
+	// the writer has no change the reader doesn't see
+	newReader, err := coreIndex.OpenIfChanged(ctx, reader)
+	assert.Nil(t, err)
+	assert.Nil(t, newReader)
+
+	newReader, err = coreIndex.OpenIfChangedFromWriter(ctx, reader, writer, true)
+	assert.Nil(t, err)
+	assert.Nil(t, newReader)
+
+	// uncommitted documents are visible to the reopened reader
+	for i := 0; i < 3; i++ {
+		doc := document.NewDocument()
+		doc.Add(document.NewStringField("id", fmt.Sprintf("new-%d", i), false))
+		_, err := writer.AddDocument(ctx, doc)
+		assert.Nil(t, err)
+	}
+
+	newReader, err = coreIndex.OpenIfChanged(ctx, reader)
+	assert.Nil(t, err)
+	assert.NotNil(t, newReader)
+	defer newReader.Close()
+	assert.Equal(t, 13, newReader.NumDocs())
+
+	oldLeaves := leafReaders(t, reader)
+	newLeaves := leafReaders(t, newReader)
+	assert.Len(t, oldLeaves, 1)
+	assert.Len(t, newLeaves, 2)
+	// the segment without changes keeps its reader
+	assert.Same(t, oldLeaves[0], newLeaves[0])
+
+	newerReader, err := coreIndex.OpenIfChanged(ctx, newReader)
+	assert.Nil(t, err)
+	assert.Nil(t, newerReader)
+}
@@ -0,0 +1,198 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.FieldComparator = &DocComparator{}
+
+// DocComparator
+// Comparator that sorts by asc _doc
+type DocComparator struct {
+	docIDs               []int
+	enableSkipping       bool // if skipping functionality should be enabled
+	topValue             int
+	topValueSet          bool
+	bottom               int
+	hitsThresholdReached bool
+	queueFull            bool
+}
+
+// NewDocComparator
+// Creates a new comparator based on document ids for numHits
+func NewDocComparator(numHits int, reverse bool, sortPos int) *DocComparator {
+	return &DocComparator{
+		docIDs: make([]int, numHits),
+		// skipping functionality is enabled if we are sorting by _doc in asc order as a primary sort
+		enableSkipping: !reverse && sortPos == 0,
+	}
+}
+
+func (d *DocComparator) Compare(slot1, slot2 int) int {
+	// No overflow risk because docIDs are non-negative
+	return d.docIDs[slot1] - d.docIDs[slot2]
+}
+
+func (d *DocComparator) SetTopValue(value any) {
+	d.topValue = value.(int)
+	d.topValueSet = true
+}
+
+func (d *DocComparator) Value(slot int) any {
+	return d.docIDs[slot]
+}
+
+func (d *DocComparator) CompareValues(first, second any) int {
+	return cmp.Compare(first.(int), second.(int))
+}
+
+func (d *DocComparator) SetSingleSort() {
+}
+
+func (d *DocComparator) DisableSkipping() {
+	d.enableSkipping = false
+}
+
+func (d *DocComparator) GetLeafComparator(context index.LeafReaderContext) (index.LeafFieldComparator, error) {
+	// TODO: can we "map" our docIDs to the current
+	// reader? saves having to then subtract on every
+	// compare call
+	leaf := &docLeafComparator{
+		parent:  d,
+		docBase: context.DocBase(),
+	}
+
+	if d.enableSkipping {
+		leaf.maxDoc = context.Reader().MaxDoc()
+		// skip docs before topValue, but include docs starting with topValue
+		if d.topValueSet {
+			leaf.minDoc = max(d.topValue-leaf.docBase, 0)
+		}
+		if leaf.minDoc > 0 {
+			leaf.competitiveIterator = &minDocIterator{
+				doc:    -1,
+				minDoc: leaf.minDoc,
+				maxDoc: leaf.maxDoc,
+			}
+		} else {
+			leaf.competitiveIterator = types.DocIdSetIteratorAll(leaf.maxDoc)
+		}
+		leaf.updateIterator()
+	}
+	return leaf, nil
+}
+
+var _ index.LeafFieldComparator = &docLeafComparator{}
+
+// DocLeafComparator with skipping functionality. When sort by _doc asc, after collecting
+// top N matches and enough hits, the comparator can skip all the following documents.
+// When sort by _doc asc and "top" document is set after which search should start, the
+// comparator provides an iterator that can quickly skip to the desired "top" document.
+type docLeafComparator struct {
+	parent *DocComparator
+
+	docBase             int
+	minDoc              int
+	maxDoc              int
+	competitiveIterator types.DocIdSetIterator // iterator that starts from topValue
+}
+
+func (d *docLeafComparator) SetBottom(slot int) error {
+	d.parent.bottom = d.parent.docIDs[slot]
+	d.parent.queueFull = true // if we are setting bottom, it means that we have collected enough hits
+	d.updateIterator()
+	return nil
+}
+
+func (d *docLeafComparator) CompareBottom(doc int) (int, error) {
+	// No overflow risk because docIDs are non-negative
+	return d.parent.bottom - (d.docBase + doc), nil
+}
+
+func (d *docLeafComparator) CompareTop(doc int) (int, error) {
+	docValue := d.docBase + doc
+	return cmp.Compare(d.parent.topValue, docValue), nil
+}
+
+func (d *docLeafComparator) Copy(slot, doc int) error {
+	d.parent.docIDs[slot] = d.docBase + doc
+	return nil
+}
+
+func (d *docLeafComparator) SetScorer(scorer index.Scorable) error {
+	// update an iterator on a new segment
+	d.updateIterator()
+	return nil
+}
+
+func (d *docLeafComparator) SetHitsThresholdReached() error {
+	if !d.parent.enableSkipping {
+		return nil
+	}
+	d.parent.hitsThresholdReached = true
+	d.updateIterator()
+	return nil
+}
+
+func (d *docLeafComparator) CompetitiveIterator() (types.DocIdSetIterator, error) {
+	if !d.parent.enableSkipping {
+		return nil, nil
+	}
+	return &competitiveDISI{
+		docID: d.competitiveIterator.DocID(),
+		in: func() types.DocIdSetIterator {
+			return d.competitiveIterator
+		},
+	}, nil
+}
+
+func (d *docLeafComparator) updateIterator() {
+	if !d.parent.enableSkipping || !d.parent.hitsThresholdReached {
+		return
+	}
+
+	if d.parent.queueFull {
+		// documents arrive in doc order, once the queue is full and enough hits were
+		// collected none of the following documents are competitive
+		d.competitiveIterator = types.GetEmptyDocIdSetIterator()
+	}
+}
+
+var _ types.DocIdSetIterator = &minDocIterator{}
+
+// minDocIterator iterates over all documents in [minDoc, maxDoc)
+type minDocIterator struct {
+	doc    int
+	minDoc int
+	maxDoc int
+}
+
+func (m *minDocIterator) DocID() int {
+	return m.doc
+}
+
+func (m *minDocIterator) NextDoc(ctx context.Context) (int, error) {
+	return m.Advance(ctx, m.doc+1)
+}
+
+func (m *minDocIterator) Advance(ctx context.Context, target int) (int, error) {
+	m.doc = max(target, m.minDoc)
+	if m.doc >= m.maxDoc {
+		m.doc = types.NO_MORE_DOCS
+		return m.doc, io.EOF
+	}
+	return m.doc, nil
+}
+
+func (m *minDocIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, m, target)
+}
+
+func (m *minDocIterator) Cost() int64 {
+	return int64(max(m.maxDoc-m.minDoc, 0))
+}
@@ -0,0 +1,253 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+// DocIDMerger
+// Utility class to help merging documents from sub-readers according to either simple concatenated
+// (unsorted) order, or by a specified index-time sort, skipping deleted documents and remapping
+// non-deleted documents.
+type DocIDMerger[T DocIDMergerSub] interface {
+	// Reset
+	// Reuse API, currently only used by postings during merge
+	Reset(ctx context.Context) error
+
+	// Next
+	// Returns the next sub positioned on a live document, or io.EOF when all subs are exhausted.
+	Next(ctx context.Context) (T, error)
+}
+
+// DocIDMergerSub
+// Represents one sub-reader being merged
+type DocIDMergerSub interface {
+	// NextDoc
+	// Returns the next document ID from this sub reader, and NO_MORE_DOCS when done
+	NextDoc(ctx context.Context) (int, error)
+
+	// GetMappedDocID
+	// Mapped doc ID
+	GetMappedDocID() int
+
+	setMappedDocID(docID int)
+	getDocMap() MergeStateDocMap
+}
+
+// BaseDocIDMergerSub
+// Holds the doc map and the mapped doc ID shared by all DocIDMergerSub implementations.
+type BaseDocIDMergerSub struct {
+	docMap      MergeStateDocMap
+	mappedDocID int
+}
+
+func NewBaseDocIDMergerSub(docMap MergeStateDocMap) *BaseDocIDMergerSub {
+	return &BaseDocIDMergerSub{docMap: docMap, mappedDocID: -1}
+}
+
+func (b *BaseDocIDMergerSub) GetMappedDocID() int {
+	return b.mappedDocID
+}
+
+func (b *BaseDocIDMergerSub) setMappedDocID(docID int) {
+	b.mappedDocID = docID
+}
+
+func (b *BaseDocIDMergerSub) getDocMap() MergeStateDocMap {
+	return b.docMap
+}
+
+// NewDocIDMerger
+// Construct this from the provided subs, specifying the maximum sub count
+func NewDocIDMerger[T DocIDMergerSub](ctx context.Context, subs []T, indexIsSorted bool) (DocIDMerger[T], error) {
+	var merger DocIDMerger[T]
+	if indexIsSorted && len(subs) > 1 {
+		merger = newSortedDocIDMerger(subs)
+	} else {
+		merger = &sequentialDocIDMerger[T]{subs: subs}
+	}
+	if err := merger.Reset(ctx); err != nil {
+		return nil, err
+	}
+	return merger, nil
+}
+
+// nextMappedDoc advances sub to its next non-deleted document, returning false once it is exhausted.
+func nextMappedDoc(ctx context.Context, sub DocIDMergerSub) (bool, error) {
+	for {
+		docID, err := sub.NextDoc(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, err
+		}
+		if docID == types.NO_MORE_DOCS {
+			return false, nil
+		}
+
+		mappedDocID := sub.getDocMap().Get(docID)
+		if mappedDocID != -1 {
+			sub.setMappedDocID(mappedDocID)
+			return true, nil
+		}
+	}
+}
+
+type sequentialDocIDMerger[T DocIDMergerSub] struct {
+	subs       []T
+	current    T
+	hasCurrent bool
+	nextIndex  int
+}
+
+func (s *sequentialDocIDMerger[T]) Reset(ctx context.Context) error {
+	if len(s.subs) > 0 {
+		s.current = s.subs[0]
+		s.hasCurrent = true
+		s.nextIndex = 1
+	} else {
+		s.hasCurrent = false
+		s.nextIndex = 0
+	}
+	return nil
+}
+
+func (s *sequentialDocIDMerger[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for s.hasCurrent {
+		ok, err := nextMappedDoc(ctx, s.current)
+		if err != nil {
+			return zero, err
+		}
+		if ok {
+			return s.current, nil
+		}
+
+		if s.nextIndex == len(s.subs) {
+			s.hasCurrent = false
+			break
+		}
+		s.current = s.subs[s.nextIndex]
+		s.nextIndex++
+	}
+	return zero, io.EOF
+}
+
+type sortedDocIDMerger[T DocIDMergerSub] struct {
+	subs  []T
+	queue *structure.PriorityQueue[T]
+	first bool
+}
+
+func newSortedDocIDMerger[T DocIDMergerSub](subs []T) *sortedDocIDMerger[T] {
+	return &sortedDocIDMerger[T]{
+		subs: subs,
+		queue: structure.NewPriorityQueue[T](len(subs), func(a, b T) bool {
+			return a.GetMappedDocID() < b.GetMappedDocID()
+		}),
+	}
+}
+
+func (s *sortedDocIDMerger[T]) Reset(ctx context.Context) error {
+	// caller may not have fully consumed the queue:
+	s.queue.Clear()
+	s.first = true
+	for _, sub := range s.subs {
+		ok, err := nextMappedDoc(ctx, sub)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.queue.Add(sub)
+		}
+	}
+	return nil
+}
+
+func (s *sortedDocIDMerger[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	if s.queue.Size() == 0 {
+		return zero, io.EOF
+	}
+
+	if s.first {
+		s.first = false
+	} else {
+		top := s.queue.Top()
+		ok, err := nextMappedDoc(ctx, top)
+		if err != nil {
+			return zero, err
+		}
+		if ok {
+			s.queue.UpdateTop()
+		} else if _, err := s.queue.Pop(); err != nil {
+			return zero, err
+		}
+		if s.queue.Size() == 0 {
+			return zero, io.EOF
+		}
+	}
+	return s.queue.Top(), nil
+}
+
+// mergedDocIDIterator
+// Iterates the documents of all subs in the merged segment's doc ID space, the sub positioned on
+// the current document is available as current. Only forward iteration with NextDoc is supported.
+type mergedDocIDIterator[T DocIDMergerSub] struct {
+	docIDMerger DocIDMerger[T]
+	current     T
+	docID       int
+	cost        int64
+}
+
+func newMergedDocIDIterator[T DocIDMergerSub](ctx context.Context, subs []T, cost int64,
+	mergeState *MergeState) (*mergedDocIDIterator[T], error) {
+
+	docIDMerger, err := NewDocIDMerger(ctx, subs, mergeState.NeedsIndexSort)
+	if err != nil {
+		return nil, err
+	}
+	return &mergedDocIDIterator[T]{
+		docIDMerger: docIDMerger,
+		docID:       -1,
+		cost:        cost,
+	}, nil
+}
+
+func (m *mergedDocIDIterator[T]) DocID() int {
+	return m.docID
+}
+
+func (m *mergedDocIDIterator[T]) NextDoc(ctx context.Context) (int, error) {
+	current, err := m.docIDMerger.Next(ctx)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			m.docID = types.NO_MORE_DOCS
+		}
+		return types.NO_MORE_DOCS, err
+	}
+	m.current = current
+	m.docID = current.GetMappedDocID()
+	return m.docID, nil
+}
+
+func (m *mergedDocIDIterator[T]) Advance(ctx context.Context, target int) (int, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (m *mergedDocIDIterator[T]) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (m *mergedDocIDIterator[T]) AdvanceExact(target int) (bool, error) {
+	return false, ErrUnsupportedOperation
+}
+
+func (m *mergedDocIDIterator[T]) Cost() int64 {
+	return m.cost
+}
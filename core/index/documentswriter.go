@@ -59,13 +59,19 @@ type DocumentsWriter struct {
 }
 
 func NewDocumentsWriter(flushNotifications index.FlushNotifications, indexCreatedVersionMajor int, pendingNumDocs *atomic.Int64, enableTestPoints bool,
-	segmentName string, config *liveIndexWriterConfig, directoryOrig, directory store.Directory,
+	segmentNameSupplier func() string, config *liveIndexWriterConfig, directoryOrig, directory store.Directory,
 	globalFieldNumberMap *FieldNumbers) *DocumentsWriter {
 
-	infos := NewFieldInfosBuilder(globalFieldNumberMap)
-
 	deleteQueue := NewDocumentsWriterDeleteQueue()
 
+	// every DWPT writes its own segment, a new one is created once the previous one is flushed
+	newPerThread := func() *DocumentsWriterPerThread {
+		return NewDocumentsWriterPerThread(indexCreatedVersionMajor,
+			segmentNameSupplier(), directoryOrig,
+			directory, config, deleteQueue, NewFieldInfosBuilder(globalFieldNumberMap),
+			pendingNumDocs, enableTestPoints)
+	}
+
 	docWriter := &DocumentsWriter{
 		pendingNumDocs:                   pendingNumDocs,
 		flushNotifications:               flushNotifications,
@@ -79,10 +85,9 @@ func NewDocumentsWriter(flushNotifications index.FlushNotifications, indexCreate
 		flushControl: &DocumentsWriterFlushControl{
 			flushDeletes: new(atomic.Bool),
 			next:         new(atomic.Bool),
-			perThread: NewDocumentsWriterPerThread(indexCreatedVersionMajor,
-				segmentName, directoryOrig,
-				directory, config, deleteQueue, infos,
-				pendingNumDocs, enableTestPoints)},
+			perThread:    newPerThread(),
+			newPerThread: newPerThread,
+		},
 	}
 	return docWriter
 }
@@ -133,6 +138,8 @@ func (d *DocumentsWriter) doFlush(ctx context.Context, flushingDWPT *DocumentsWr
 
 		dwptSuccess := true
 
+		flushingDocsInRam := flushingDWPT.GetNumDocsInRAM()
+
 		ticket, err := d.ticketQueue.AddFlushTicket(flushingDWPT)
 		if err != nil {
 			return false, err
@@ -144,8 +151,7 @@ func (d *DocumentsWriter) doFlush(ctx context.Context, flushingDWPT *DocumentsWr
 			return false, err
 		}
 		d.ticketQueue.AddSegment(ticket, newSegment)
-		//
-		//d.subtractFlushedNumDocs(int64(flushingDocsInRam))
+		d.subtractFlushedNumDocs(int64(flushingDocsInRam))
 		if (len(flushingDWPT.PendingFilesToDelete()) == 0) == false {
 			files := flushingDWPT.PendingFilesToDelete()
 			d.flushNotifications.DeleteUnusedFiles(files)
@@ -43,6 +43,7 @@ type DocumentsWriterFlushControl struct {
 	peakNetBytes           int64
 	peakDelta              int64
 	perThread              *DocumentsWriterPerThread
+	newPerThread           func() *DocumentsWriterPerThread
 
 	flushPolicy     FlushPolicy
 	closed          bool
@@ -63,6 +64,11 @@ type DocumentsWriterFlushControl struct {
 //}
 
 func (d *DocumentsWriterFlushControl) ObtainAndLock() *DocumentsWriterPerThread {
+	if d.perThread.hasFlushed.Load() {
+		// the previous segment is done, start the next one
+		d.perThread = d.newPerThread()
+		d.next.Store(false)
+	}
 	return d.perThread
 }
 
@@ -71,7 +77,7 @@ func (d *DocumentsWriterFlushControl) DoAfterFlush(dwpt *DocumentsWriterPerThrea
 }
 
 func (d *DocumentsWriterFlushControl) NextPendingFlush() *DocumentsWriterPerThread {
-	if d.next.Load() {
+	if d.next.Load() || d.perThread.GetNumDocsInRAM() == 0 {
 		return nil
 	}
 	d.next.Store(true)
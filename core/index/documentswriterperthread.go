@@ -234,6 +234,7 @@ func (d *DocumentsWriterPerThread) flush(ctx context.Context, flushNotifications
 
 	flushInfo := store.NewFlushInfo(int(d.numDocsInRAM.Load()), d.lastCommittedBytesUsed)
 	ioContext := store.NewIOContext(store.WithFlushInfo(flushInfo))
+	ctx = store.WithIOContext(ctx, ioContext)
 
 	flushState := index.NewSegmentWriteState(d.directory, d.segmentInfo, d.fieldInfos.Finish(),
 		d.pendingUpdates, ioContext)
@@ -319,6 +320,7 @@ func (d *DocumentsWriterPerThread) sealFlushedSegment(ctx context.Context, flush
 		return err
 	}
 	ioContext := store.NewIOContext(store.WithFlushInfo(store.NewFlushInfo(maxDoc, sizeInBytes)))
+	ctx = store.WithIOContext(ctx, ioContext)
 
 	if d.indexWriterConfig.GetUseCompoundFile() {
 		originalFiles := newSegment.Info().Files()
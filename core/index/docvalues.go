@@ -23,11 +23,7 @@ func GetSorted(reader index.LeafReader, field string) (index.SortedDocValues, er
 	if err != nil {
 		return nil, err
 	}
-	if dv != nil {
-		return dv, nil
-	}
-
-	panic("")
+	return dv, nil
 }
 
 var _ sort.Interface = &DocValueSorter{}
@@ -0,0 +1,490 @@
+package index
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/geange/lucene-go/core/util/packed"
+)
+
+// MergeDocValues
+// Merges in the fields from the readers in mergeState. The default implementation calls
+// mergeNumericField, mergeBinaryField, mergeSortedField, mergeSortedSetField, or mergeSortedNumericField
+// for each field, depending on its type. Each field is merged by handing the consumer a producer that
+// merges and filters deleted documents on the fly.
+func MergeDocValues(ctx context.Context, consumer index.DocValuesConsumer, mergeState *MergeState) error {
+	for _, docValuesProducer := range mergeState.DocValuesProducers {
+		if docValuesProducer != nil {
+			if err := docValuesProducer.CheckIntegrity(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, mergeFieldInfo := range mergeState.MergeFieldInfos.List() {
+		var err error
+		switch mergeFieldInfo.GetDocValuesType() {
+		case document.DOC_VALUES_TYPE_NONE:
+			continue
+		case document.DOC_VALUES_TYPE_NUMERIC:
+			err = consumer.AddNumericField(ctx, mergeFieldInfo, &EmptyDocValuesProducer{
+				FnGetNumeric: func(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
+					return mergeNumericValues(ctx, field, mergeState)
+				},
+			})
+		case document.DOC_VALUES_TYPE_BINARY:
+			err = consumer.AddBinaryField(ctx, mergeFieldInfo, &EmptyDocValuesProducer{
+				FnGetBinary: func(ctx context.Context, field *document.FieldInfo) (index.BinaryDocValues, error) {
+					return mergeBinaryValues(ctx, field, mergeState)
+				},
+			})
+		case document.DOC_VALUES_TYPE_SORTED:
+			err = mergeSortedField(ctx, consumer, mergeFieldInfo, mergeState)
+		case document.DOC_VALUES_TYPE_SORTED_SET:
+			err = mergeSortedSetField(ctx, consumer, mergeFieldInfo, mergeState)
+		case document.DOC_VALUES_TYPE_SORTED_NUMERIC:
+			err = consumer.AddSortedNumericField(ctx, mergeFieldInfo, &EmptyDocValuesProducer{
+				FnGetSortedNumeric: func(ctx context.Context, field *document.FieldInfo) (index.SortedNumericDocValues, error) {
+					return mergeSortedNumericValues(ctx, field, mergeState)
+				},
+			})
+		default:
+			err = errors.New("type=" + mergeFieldInfo.GetDocValuesType().String())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readerFieldInfo returns the FieldInfo of the i-th reader being merged for mergeFieldInfo,
+// or nil if that reader has no doc values of type dvType for the field.
+func readerFieldInfo(mergeState *MergeState, i int, mergeFieldInfo *document.FieldInfo,
+	dvType document.DocValuesType) *document.FieldInfo {
+
+	if mergeState.DocValuesProducers[i] == nil {
+		return nil
+	}
+	fieldInfo := mergeState.FieldInfos[i].FieldInfo(mergeFieldInfo.Name())
+	if fieldInfo == nil || fieldInfo.GetDocValuesType() != dvType {
+		return nil
+	}
+	return fieldInfo
+}
+
+// NumericDocValuesSub Tracks state of one numeric sub-reader that we are merging
+type NumericDocValuesSub struct {
+	*BaseDocIDMergerSub
+
+	values index.NumericDocValues
+}
+
+func NewNumericDocValuesSub(docMap MergeStateDocMap, values index.NumericDocValues) *NumericDocValuesSub {
+	return &NumericDocValuesSub{
+		BaseDocIDMergerSub: NewBaseDocIDMergerSub(docMap),
+		values:             values,
+	}
+}
+
+func (n *NumericDocValuesSub) NextDoc(ctx context.Context) (int, error) {
+	return n.values.NextDoc(ctx)
+}
+
+var _ index.NumericDocValues = &mergedNumericDocValues{}
+
+type mergedNumericDocValues struct {
+	*mergedDocIDIterator[*NumericDocValuesSub]
+}
+
+func (m *mergedNumericDocValues) LongValue() (int64, error) {
+	return m.current.values.LongValue()
+}
+
+func newMergedNumericDocValues(ctx context.Context, subs []*NumericDocValuesSub, mergeState *MergeState) (*mergedNumericDocValues, error) {
+	cost := int64(0)
+	for _, sub := range subs {
+		cost += sub.values.Cost()
+	}
+	iterator, err := newMergedDocIDIterator(ctx, subs, cost, mergeState)
+	if err != nil {
+		return nil, err
+	}
+	return &mergedNumericDocValues{iterator}, nil
+}
+
+func mergeNumericValues(ctx context.Context, mergeFieldInfo *document.FieldInfo, mergeState *MergeState) (index.NumericDocValues, error) {
+	subs := make([]*NumericDocValuesSub, 0, len(mergeState.DocValuesProducers))
+	for i, producer := range mergeState.DocValuesProducers {
+		fieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_NUMERIC)
+		if fieldInfo == nil {
+			continue
+		}
+		values, err := producer.GetNumeric(ctx, fieldInfo)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, NewNumericDocValuesSub(mergeState.DocMaps[i], values))
+	}
+	return newMergedNumericDocValues(ctx, subs, mergeState)
+}
+
+// BinaryDocValuesSub Tracks state of one binary sub-reader that we are merging
+type BinaryDocValuesSub struct {
+	*BaseDocIDMergerSub
+
+	values index.BinaryDocValues
+}
+
+func NewBinaryDocValuesSub(docMap MergeStateDocMap, values index.BinaryDocValues) *BinaryDocValuesSub {
+	return &BinaryDocValuesSub{
+		BaseDocIDMergerSub: NewBaseDocIDMergerSub(docMap),
+		values:             values,
+	}
+}
+
+func (b *BinaryDocValuesSub) NextDoc(ctx context.Context) (int, error) {
+	return b.values.NextDoc(ctx)
+}
+
+var _ index.BinaryDocValues = &mergedBinaryDocValues{}
+
+type mergedBinaryDocValues struct {
+	*mergedDocIDIterator[*BinaryDocValuesSub]
+}
+
+func (m *mergedBinaryDocValues) BinaryValue() ([]byte, error) {
+	return m.current.values.BinaryValue()
+}
+
+func mergeBinaryValues(ctx context.Context, mergeFieldInfo *document.FieldInfo, mergeState *MergeState) (index.BinaryDocValues, error) {
+	subs := make([]*BinaryDocValuesSub, 0, len(mergeState.DocValuesProducers))
+	cost := int64(0)
+	for i, producer := range mergeState.DocValuesProducers {
+		fieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_BINARY)
+		if fieldInfo == nil {
+			continue
+		}
+		values, err := producer.GetBinary(ctx, fieldInfo)
+		if err != nil {
+			return nil, err
+		}
+		cost += values.Cost()
+		subs = append(subs, NewBinaryDocValuesSub(mergeState.DocMaps[i], values))
+	}
+	iterator, err := newMergedDocIDIterator(ctx, subs, cost, mergeState)
+	if err != nil {
+		return nil, err
+	}
+	return &mergedBinaryDocValues{iterator}, nil
+}
+
+// SortedNumericDocValuesSub Tracks state of one sorted numeric sub-reader that we are merging
+type SortedNumericDocValuesSub struct {
+	*BaseDocIDMergerSub
+
+	values index.SortedNumericDocValues
+}
+
+func NewSortedNumericDocValuesSub(docMap MergeStateDocMap, values index.SortedNumericDocValues) *SortedNumericDocValuesSub {
+	return &SortedNumericDocValuesSub{
+		BaseDocIDMergerSub: NewBaseDocIDMergerSub(docMap),
+		values:             values,
+	}
+}
+
+func (s *SortedNumericDocValuesSub) NextDoc(ctx context.Context) (int, error) {
+	return s.values.NextDoc(ctx)
+}
+
+var _ index.SortedNumericDocValues = &mergedSortedNumericDocValues{}
+
+type mergedSortedNumericDocValues struct {
+	*mergedDocIDIterator[*SortedNumericDocValuesSub]
+}
+
+func (m *mergedSortedNumericDocValues) NextValue() (int64, error) {
+	return m.current.values.NextValue()
+}
+
+func (m *mergedSortedNumericDocValues) DocValueCount() int {
+	return m.current.values.DocValueCount()
+}
+
+func mergeSortedNumericValues(ctx context.Context, mergeFieldInfo *document.FieldInfo, mergeState *MergeState) (index.SortedNumericDocValues, error) {
+	subs := make([]*SortedNumericDocValuesSub, 0, len(mergeState.DocValuesProducers))
+	cost := int64(0)
+	for i, producer := range mergeState.DocValuesProducers {
+		fieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_SORTED_NUMERIC)
+		if fieldInfo == nil {
+			continue
+		}
+		values, err := producer.GetSortedNumeric(ctx, fieldInfo)
+		if err != nil {
+			return nil, err
+		}
+		cost += values.Cost()
+		subs = append(subs, NewSortedNumericDocValuesSub(mergeState.DocMaps[i], values))
+	}
+	iterator, err := newMergedDocIDIterator(ctx, subs, cost, mergeState)
+	if err != nil {
+		return nil, err
+	}
+	return &mergedSortedNumericDocValues{iterator}, nil
+}
+
+// SortedDocValuesSub Tracks state of one sorted sub-reader that we are merging
+type SortedDocValuesSub struct {
+	*BaseDocIDMergerSub
+
+	values       index.SortedDocValues
+	segmentIndex int
+}
+
+func NewSortedDocValuesSub(docMap MergeStateDocMap, values index.SortedDocValues, segmentIndex int) *SortedDocValuesSub {
+	return &SortedDocValuesSub{
+		BaseDocIDMergerSub: NewBaseDocIDMergerSub(docMap),
+		values:             values,
+		segmentIndex:       segmentIndex,
+	}
+}
+
+func (s *SortedDocValuesSub) NextDoc(ctx context.Context) (int, error) {
+	return s.values.NextDoc(ctx)
+}
+
+// mergeSortedField Merges the sorted docvalues from toMerge.
+// The default implementation calls AddSortedField, passing an Iterable that merges ordinals
+// and values and filters deleted documents.
+func mergeSortedField(ctx context.Context, consumer index.DocValuesConsumer, mergeFieldInfo *document.FieldInfo, mergeState *MergeState) error {
+	// step 1: iterate thru each sub and mark terms still in use
+	toMerge := make([]index.SortedDocValues, len(mergeState.DocValuesProducers))
+	liveTerms := make([]index.TermsEnum, len(toMerge))
+	weights := make([]int64, len(toMerge))
+	for i, producer := range mergeState.DocValuesProducers {
+		fieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_SORTED)
+		if fieldInfo == nil {
+			liveTerms[i] = EmptyTermsEnum
+			continue
+		}
+		values, err := producer.GetSorted(ctx, fieldInfo)
+		if err != nil {
+			return err
+		}
+		toMerge[i] = values
+		liveTerms[i], err = values.TermsEnum()
+		if err != nil {
+			return err
+		}
+		weights[i] = int64(values.GetValueCount())
+	}
+
+	// step 2: create ordinal map (this conceptually does the "merging")
+	ordinalMap, err := NewOrdinalMap(ctx, liveTerms, NewSegmentMap(weights), packed.COMPACT)
+	if err != nil {
+		return err
+	}
+
+	// step 3: add field
+	return consumer.AddSortedField(ctx, mergeFieldInfo, &EmptyDocValuesProducer{
+		FnGetSorted: func(ctx context.Context, fieldInfo *document.FieldInfo) (index.SortedDocValues, error) {
+			if fieldInfo != mergeFieldInfo {
+				return nil, errors.New("wrong fieldInfo")
+			}
+
+			subs := make([]*SortedDocValuesSub, 0, len(toMerge))
+			cost := int64(0)
+			for i, producer := range mergeState.DocValuesProducers {
+				readerFieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_SORTED)
+				if readerFieldInfo == nil {
+					continue
+				}
+				values, err := producer.GetSorted(ctx, readerFieldInfo)
+				if err != nil {
+					return nil, err
+				}
+				cost += values.Cost()
+				subs = append(subs, NewSortedDocValuesSub(mergeState.DocMaps[i], values, i))
+			}
+
+			iterator, err := newMergedDocIDIterator(ctx, subs, cost, mergeState)
+			if err != nil {
+				return nil, err
+			}
+			return &mergedSortedDocValues{
+				mergedDocIDIterator: iterator,
+				ordinalMap:          ordinalMap,
+				toMerge:             toMerge,
+			}, nil
+		},
+	})
+}
+
+var _ index.SortedDocValues = &mergedSortedDocValues{}
+
+type mergedSortedDocValues struct {
+	*mergedDocIDIterator[*SortedDocValuesSub]
+
+	ordinalMap *OrdinalMap
+	toMerge    []index.SortedDocValues
+}
+
+func (m *mergedSortedDocValues) BinaryValue() ([]byte, error) {
+	ord, err := m.OrdValue()
+	if err != nil {
+		return nil, err
+	}
+	return m.LookupOrd(ord)
+}
+
+func (m *mergedSortedDocValues) OrdValue() (int, error) {
+	segmentOrd, err := m.current.values.OrdValue()
+	if err != nil {
+		return 0, err
+	}
+	if segmentOrd == -1 {
+		return -1, nil
+	}
+	return int(m.ordinalMap.GetGlobalOrd(m.current.segmentIndex, int64(segmentOrd))), nil
+}
+
+func (m *mergedSortedDocValues) LookupOrd(ord int) ([]byte, error) {
+	segmentNumber := m.ordinalMap.GetFirstSegmentNumber(int64(ord))
+	segmentOrd := m.ordinalMap.GetFirstSegmentOrd(int64(ord))
+	return m.toMerge[segmentNumber].LookupOrd(int(segmentOrd))
+}
+
+func (m *mergedSortedDocValues) GetValueCount() int {
+	return int(m.ordinalMap.GetValueCount())
+}
+
+func (m *mergedSortedDocValues) LookupTerm(key []byte) (int, error) {
+	return NewBaseSortedDocValues(&SortedDocValuesDefaultConfig{
+		LookupOrd:     m.LookupOrd,
+		GetValueCount: m.GetValueCount,
+	}).LookupTerm(key)
+}
+
+func (m *mergedSortedDocValues) TermsEnum() (index.TermsEnum, error) {
+	return NewSortedDocValuesTermsEnum(m), nil
+}
+
+func (m *mergedSortedDocValues) Intersect(automaton *automaton.CompiledAutomaton) (index.TermsEnum, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+// SortedSetDocValuesSub Tracks state of one sorted set sub-reader that we are merging
+type SortedSetDocValuesSub struct {
+	*BaseDocIDMergerSub
+
+	values       index.SortedSetDocValues
+	segmentIndex int
+}
+
+func NewSortedSetDocValuesSub(docMap MergeStateDocMap, values index.SortedSetDocValues, segmentIndex int) *SortedSetDocValuesSub {
+	return &SortedSetDocValuesSub{
+		BaseDocIDMergerSub: NewBaseDocIDMergerSub(docMap),
+		values:             values,
+		segmentIndex:       segmentIndex,
+	}
+}
+
+func (s *SortedSetDocValuesSub) NextDoc(ctx context.Context) (int, error) {
+	return s.values.NextDoc(ctx)
+}
+
+// mergeSortedSetField Merges the sortedset docvalues from toMerge.
+// The default implementation calls AddSortedSetField, passing an Iterable that merges ordinals
+// and values and filters deleted documents .
+func mergeSortedSetField(ctx context.Context, consumer index.DocValuesConsumer, mergeFieldInfo *document.FieldInfo, mergeState *MergeState) error {
+	toMerge := make([]index.SortedSetDocValues, len(mergeState.DocValuesProducers))
+	liveTerms := make([]index.TermsEnum, len(toMerge))
+	weights := make([]int64, len(toMerge))
+	for i, producer := range mergeState.DocValuesProducers {
+		fieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_SORTED_SET)
+		if fieldInfo == nil {
+			liveTerms[i] = EmptyTermsEnum
+			continue
+		}
+		values, err := producer.GetSortedSet(ctx, fieldInfo)
+		if err != nil {
+			return err
+		}
+		toMerge[i] = values
+		liveTerms[i] = NewSortedSetDocValuesTermsEnum(values)
+		weights[i] = values.GetValueCount()
+	}
+
+	// step 2: create ordinal map (this conceptually does the "merging")
+	ordinalMap, err := NewOrdinalMap(ctx, liveTerms, NewSegmentMap(weights), packed.COMPACT)
+	if err != nil {
+		return err
+	}
+
+	// step 3: add field
+	return consumer.AddSortedSetField(ctx, mergeFieldInfo, &EmptyDocValuesProducer{
+		FnGetSortedSet: func(ctx context.Context, fieldInfo *document.FieldInfo) (index.SortedSetDocValues, error) {
+			if fieldInfo != mergeFieldInfo {
+				return nil, errors.New("wrong fieldInfo")
+			}
+
+			subs := make([]*SortedSetDocValuesSub, 0, len(toMerge))
+			cost := int64(0)
+			for i, producer := range mergeState.DocValuesProducers {
+				readerFieldInfo := readerFieldInfo(mergeState, i, mergeFieldInfo, document.DOC_VALUES_TYPE_SORTED_SET)
+				if readerFieldInfo == nil {
+					continue
+				}
+				values, err := producer.GetSortedSet(ctx, readerFieldInfo)
+				if err != nil {
+					return nil, err
+				}
+				cost += values.Cost()
+				subs = append(subs, NewSortedSetDocValuesSub(mergeState.DocMaps[i], values, i))
+			}
+
+			iterator, err := newMergedDocIDIterator(ctx, subs, cost, mergeState)
+			if err != nil {
+				return nil, err
+			}
+			return &mergedSortedSetDocValues{
+				mergedDocIDIterator: iterator,
+				ordinalMap:          ordinalMap,
+				toMerge:             toMerge,
+			}, nil
+		},
+	})
+}
+
+var _ index.SortedSetDocValues = &mergedSortedSetDocValues{}
+
+type mergedSortedSetDocValues struct {
+	*mergedDocIDIterator[*SortedSetDocValuesSub]
+
+	ordinalMap *OrdinalMap
+	toMerge    []index.SortedSetDocValues
+}
+
+func (m *mergedSortedSetDocValues) NextOrd() (int64, error) {
+	subOrd, err := m.current.values.NextOrd()
+	if err != nil {
+		return NO_MORE_ORDS, err
+	}
+	if subOrd == NO_MORE_ORDS {
+		return NO_MORE_ORDS, nil
+	}
+	return m.ordinalMap.GetGlobalOrd(m.current.segmentIndex, subOrd), nil
+}
+
+func (m *mergedSortedSetDocValues) LookupOrd(ord int64) ([]byte, error) {
+	segmentNumber := m.ordinalMap.GetFirstSegmentNumber(ord)
+	segmentOrd := m.ordinalMap.GetFirstSegmentOrd(ord)
+	return m.toMerge[segmentNumber].LookupOrd(segmentOrd)
+}
+
+func (m *mergedSortedSetDocValues) GetValueCount() int64 {
+	return m.ordinalMap.GetValueCount()
+}
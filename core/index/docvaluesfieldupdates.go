@@ -1,6 +1,7 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -242,3 +243,79 @@ func AsNumericDocValues(iterator DocValuesFieldUpdatesIterator) index.NumericDoc
 
 type SingleValueDocValuesFieldUpdates struct {
 }
+
+// mergedDocValuesFieldUpdates
+// Doc values updates a segment received while it was being merged, moved to the doc IDs of the
+// merged segment. Updates of docs the merge dropped are skipped.
+type mergedDocValuesFieldUpdates struct {
+	DocValuesFieldUpdates
+
+	docMap MergeStateDocMap
+}
+
+func newMergedDocValuesFieldUpdates(updates DocValuesFieldUpdates, docMap MergeStateDocMap) *mergedDocValuesFieldUpdates {
+	return &mergedDocValuesFieldUpdates{
+		DocValuesFieldUpdates: updates,
+		docMap:                docMap,
+	}
+}
+
+func (m *mergedDocValuesFieldUpdates) Iterator() (DocValuesFieldUpdatesIterator, error) {
+	iterator, err := m.DocValuesFieldUpdates.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	return &mergedDocValuesFieldUpdatesIterator{
+		DocValuesFieldUpdatesIterator: iterator,
+		docMap:                        m.docMap,
+		doc:                           -1,
+	}, nil
+}
+
+type mergedDocValuesFieldUpdatesIterator struct {
+	DocValuesFieldUpdatesIterator
+
+	docMap MergeStateDocMap
+	doc    int
+}
+
+func (m *mergedDocValuesFieldUpdatesIterator) DocID() int {
+	return m.doc
+}
+
+func (m *mergedDocValuesFieldUpdatesIterator) NextDoc(ctx context.Context) (int, error) {
+	for {
+		doc, err := m.DocValuesFieldUpdatesIterator.NextDoc(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if doc == types.NO_MORE_DOCS {
+			m.doc = types.NO_MORE_DOCS
+			return m.doc, nil
+		}
+		// without an index sort the doc map keeps the order of the docs
+		if mapped := m.docMap.Get(doc); mapped != -1 {
+			m.doc = mapped
+			return m.doc, nil
+		}
+	}
+}
+
+func (m *mergedDocValuesFieldUpdatesIterator) Advance(ctx context.Context, target int) (int, error) {
+	return m.SlowAdvance(ctx, target)
+}
+
+func (m *mergedDocValuesFieldUpdatesIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	doc := m.doc
+	for doc < target {
+		var err error
+		if doc, err = m.NextDoc(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return doc, nil
+}
+
+func (m *mergedDocValuesFieldUpdatesIterator) AdvanceExact(target int) (bool, error) {
+	return false, errors.New("unsupported operation exception")
+}
@@ -47,6 +47,10 @@ func (d *DocValuesLeafReader) GetReaderCacheHelper() index.CacheHelper {
 	return nil
 }
 
+func (d *DocValuesLeafReader) GetCoreCacheHelper() index.CacheHelper {
+	return nil
+}
+
 func (d *DocValuesLeafReader) Terms(field string) (index.Terms, error) {
 	return nil, errors.New("func Terms is not yet implemented")
 }
@@ -19,8 +19,7 @@ type EmptyDocValuesProducer struct {
 }
 
 func (e *EmptyDocValuesProducer) Close() error {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func (e *EmptyDocValuesProducer) GetNumeric(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
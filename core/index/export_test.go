@@ -0,0 +1,67 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+// Segments Returns the segments of the index
+func Segments(w *IndexWriter) []index.SegmentCommitInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return slices.Clone(w.segmentInfos.segments)
+}
+
+// RunningMergeSegments Returns the segments of the running merges which opened their readers
+func RunningMergeSegments(w *IndexWriter) []index.SegmentCommitInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	segments := make([]index.SegmentCommitInfo, 0)
+	for merge := range w.runningMerges {
+		if len(merge.mergeReaders) == len(merge.segments) {
+			segments = append(segments, merge.segments...)
+		}
+	}
+	return segments
+}
+
+// DeleteDocument Deletes a document of a segment, the way resolved deletes are applied
+func DeleteDocument(ctx context.Context, w *IndexWriter, info index.SegmentCommitInfo, docID int) (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	rld, err := w.readerPool.Get(info, true)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := rld.Delete(ctx, docID)
+	return deleted, errors.Join(err, w.release(rld, true))
+}
+
+// AddDocValuesUpdate Adds resolved doc values updates to a segment, the way applied updates are
+func AddDocValuesUpdate(w *IndexWriter, info index.SegmentCommitInfo, updates DocValuesFieldUpdates) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	rld, err := w.readerPool.Get(info, true)
+	if err != nil {
+		return err
+	}
+	return errors.Join(rld.AddDVUpdate(updates), w.release(rld, true))
+}
+
+// DocValuesUpdates Returns the pending doc values updates of a field of a segment
+func DocValuesUpdates(w *IndexWriter, info index.SegmentCommitInfo, field string) ([]DocValuesFieldUpdates, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	rld, err := w.readerPool.Get(info, false)
+	if err != nil || rld == nil {
+		return nil, err
+	}
+	return slices.Clone(rld.pendingDVUpdates[field]), nil
+}
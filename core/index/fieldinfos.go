@@ -3,6 +3,7 @@ package index
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
 
 	"github.com/geange/gods-generic/sets/treeset"
@@ -134,6 +135,22 @@ func (f *fieldInfos) List() []*document.FieldInfo {
 	return f.fieldInfos
 }
 
+func (f *fieldInfos) HasFreq() bool {
+	return f.hasFreq
+}
+
+func (f *fieldInfos) HasProx() bool {
+	return f.hasProx
+}
+
+func (f *fieldInfos) HasPayloads() bool {
+	return f.hasPayloads
+}
+
+func (f *fieldInfos) HasOffsets() bool {
+	return f.hasOffsets
+}
+
 func (f *fieldInfos) HasNorms() bool {
 	return f.hasNorms
 }
@@ -220,7 +237,7 @@ func (f *FieldInfosBuilder) AddFieldInfoV(fi *document.FieldInfo, dvGen int64) (
 	return f.addOrUpdateInternal(fi.Name(), fi.Number(), fi.HasVectors(),
 		fi.OmitsNorms(), fi.HasPayloads(),
 		fi.GetIndexOptions(), fi.GetDocValuesType(), dvGen,
-		fi.Attributes(),
+		maps.Clone(fi.Attributes()),
 		fi.GetPointDimensionCount(), fi.GetPointIndexDimensionCount(), fi.GetPointNumBytes(),
 		fi.IsSoftDeletesField())
 }
@@ -466,6 +483,14 @@ func (f *FieldNumbers) verifyConsistentIndexOptions(number int, name string, ind
 }
 
 func (f *FieldNumbers) verifyConsistentDocValuesType(number int, name string, dvType document.DocValuesType) error {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.checkDocValuesType(number, name, dvType)
+}
+
+// checkDocValuesType is verifyConsistentDocValuesType for callers which hold the lock
+func (f *FieldNumbers) checkDocValuesType(number int, name string, dvType document.DocValuesType) error {
 	if f.numberToName[number] != name {
 		return fmt.Errorf(`field number %d is already mapped to field name "%s" not "%s"`,
 			number, f.numberToName[number], name)
@@ -487,6 +512,9 @@ func (f *FieldNumbers) verifyConsistentDocValuesType(number int, name string, dv
 }
 
 func (f *FieldNumbers) setIndexOptions(number int, name string, indexOptions document.IndexOptions) error {
+	f.Lock()
+	defer f.Unlock()
+
 	if err := f.verifyConsistentIndexOptions(number, name, indexOptions); err != nil {
 		return err
 	}
@@ -495,7 +523,10 @@ func (f *FieldNumbers) setIndexOptions(number int, name string, indexOptions doc
 }
 
 func (f *FieldNumbers) setDocValuesType(number int, name string, dvType document.DocValuesType) error {
-	if err := f.verifyConsistentDocValuesType(number, name, dvType); err != nil {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.checkDocValuesType(number, name, dvType); err != nil {
 		return err
 	}
 	f.docValuesType[name] = dvType
@@ -503,11 +534,17 @@ func (f *FieldNumbers) setDocValuesType(number int, name string, dvType document
 }
 
 func (f *FieldNumbers) SetDimensions(number int, name string, dimensionCount, indexDimensionCount, dimensionNumBytes int) {
+	f.Lock()
+	defer f.Unlock()
+
 	//f.verifyConsistentDimensions(number, name, dimensionCount, indexDimensionCount, dimensionNumBytes);
 	f.dimensions[name] = NewFieldDimensions(dimensionCount, indexDimensionCount, dimensionNumBytes)
 }
 
 func (f *FieldNumbers) contains(fieldName string, dvType document.DocValuesType) bool {
+	f.Lock()
+	defer f.Unlock()
+
 	if _, ok := f.nameToNumber[fieldName]; !ok {
 		return false
 	}
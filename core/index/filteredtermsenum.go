@@ -2,6 +2,8 @@ package index
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/attribute"
@@ -61,6 +63,7 @@ func NewFilteredTermsEnumDefault(cfg *FilteredTermsEnumDefaultConfig) *FilteredT
 		actualTerm:      nil,
 		tenum:           cfg.Tenum,
 		Accept:          cfg.Accept,
+		NextSeekTerm:    cfg.NextSeekTerm,
 	}
 }
 
@@ -155,7 +158,8 @@ func (f *FilteredTermsEnumBase) Next(context.Context) ([]byte, error) {
 				return nil, err
 			}
 
-			if len(t) == 0 {
+			if t == nil {
+				// no more terms to seek to
 				return nil, nil
 			}
 
@@ -170,8 +174,11 @@ func (f *FilteredTermsEnumBase) Next(context.Context) ([]byte, error) {
 				return nil, err
 			}
 		} else {
-			f.actualTerm, err = f.tenum.Term()
+			f.actualTerm, err = f.tenum.Next(nil)
 			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, nil
+				}
 				return nil, err
 			}
 			if f.actualTerm == nil {
@@ -186,15 +193,13 @@ func (f *FilteredTermsEnumBase) Next(context.Context) ([]byte, error) {
 		switch status {
 		case ACCEPT_STATUS_YES_AND_SEEK:
 			f.doSeek = true
+			return f.actualTerm, nil
 		case ACCEPT_STATUS_YES:
 			return f.actualTerm, nil
 		case ACCEPT_STATUS_NO_AND_SEEK:
 			f.doSeek = true
-			return nil, nil
 		case ACCEPT_STATUS_END:
 			return nil, nil
-		case ACCEPT_STATUS_NO:
-			return nil, nil
 		}
 	}
 }
@@ -257,18 +257,20 @@ func (f *FreqProxTermsEnum) Postings(reuse index.PostingsEnum, flags int) (index
 		return nil, errors.New("did not index freq")
 	}
 
-	//  if (reuse instanceof FreqProxDocsEnum) {
-	//        docsEnum = (FreqProxDocsEnum) reuse;
-	//        if (docsEnum.postingsArray != postingsArray) {
-	//          docsEnum = new FreqProxDocsEnum(terms, postingsArray);
-	//        }
-	//      } else {
-	//        docsEnum = new FreqProxDocsEnum(terms, postingsArray);
-	//      }
-	//      docsEnum.reset(sortedTermIDs[ord]);
-	//      return docsEnum;
-
-	panic("implement me")
+	if docsEnum, ok := reuse.(*FreqProxDocsEnum); ok {
+		if docsEnum.postingsArray != f.postingsArray {
+			docsEnum = newFreqProxDocsEnum(f.terms, f.postingsArray)
+		}
+		if err := docsEnum.reset(f.sortedTermIDs[f.ord]); err != nil {
+			return nil, err
+		}
+		return docsEnum, nil
+	}
+	docsEnum := newFreqProxDocsEnum(f.terms, f.postingsArray)
+	if err := docsEnum.reset(f.sortedTermIDs[f.ord]); err != nil {
+		return nil, err
+	}
+	return docsEnum, nil
 }
 
 func (f *FreqProxTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
@@ -276,6 +278,119 @@ func (f *FreqProxTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
 	panic("implement me")
 }
 
+var _ index.PostingsEnum = &FreqProxDocsEnum{}
+
+type FreqProxDocsEnum struct {
+	terms         *FreqProxTermsWriterPerField
+	postingsArray *FreqProxPostingsArray
+	reader        *ByteSliceReader
+	readTermFreq  bool
+	docID         int
+	freq          int
+	ended         bool
+	termID        int
+}
+
+func newFreqProxDocsEnum(terms *FreqProxTermsWriterPerField, postingsArray *FreqProxPostingsArray) *FreqProxDocsEnum {
+	return &FreqProxDocsEnum{
+		terms:         terms,
+		postingsArray: postingsArray,
+		readTermFreq:  terms.hasFreq,
+		reader:        NewByteSliceReader(),
+		docID:         -1,
+	}
+}
+
+func (f *FreqProxDocsEnum) reset(termID int) error {
+	f.termID = termID
+	if err := f.terms.initReader(f.reader, termID, 0); err != nil {
+		return err
+	}
+	f.ended = false
+	f.docID = -1
+	return nil
+}
+
+func (f *FreqProxDocsEnum) DocID() int {
+	return f.docID
+}
+
+func (f *FreqProxDocsEnum) NextDoc(ctx context.Context) (int, error) {
+	if f.docID == -1 {
+		f.docID = 0
+	}
+
+	if f.reader.EOF() {
+		if f.ended {
+			return 0, io.EOF
+		}
+		f.ended = true
+		f.docID = f.postingsArray.lastDocIDs[f.termID]
+		if f.readTermFreq {
+			f.freq = f.postingsArray.termFreqs[f.termID]
+		}
+		return f.docID, nil
+	}
+
+	code, err := f.reader.ReadUvarint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !f.readTermFreq {
+		f.docID += int(code)
+		return f.docID, nil
+	}
+
+	f.docID += int(code >> 1)
+	if code&1 != 0 {
+		f.freq = 1
+	} else {
+		freq, err := f.reader.ReadUvarint(ctx)
+		if err != nil {
+			return 0, err
+		}
+		f.freq = int(freq)
+	}
+	return f.docID, nil
+}
+
+func (f *FreqProxDocsEnum) Advance(ctx context.Context, target int) (int, error) {
+	return 0, errors.New("implement me")
+}
+
+func (f *FreqProxDocsEnum) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return 0, errors.New("implement me")
+}
+
+func (f *FreqProxDocsEnum) Cost() int64 {
+	return -1
+}
+
+func (f *FreqProxDocsEnum) Freq() (int, error) {
+	// Don't lie here ... don't want codecs writings lots
+	// of wasted 1s into the index:
+	if !f.readTermFreq {
+		return 0, errors.New("freq was not indexed")
+	}
+	return f.freq, nil
+}
+
+func (f *FreqProxDocsEnum) NextPosition() (int, error) {
+	return -1, nil
+}
+
+func (f *FreqProxDocsEnum) StartOffset() (int, error) {
+	return -1, nil
+}
+
+func (f *FreqProxDocsEnum) EndOffset() (int, error) {
+	return -1, nil
+}
+
+func (f *FreqProxDocsEnum) GetPayload() ([]byte, error) {
+	return nil, nil
+}
+
 var _ index.PostingsEnum = &FreqProxPostingsEnum{}
 
 type FreqProxPostingsEnum struct {
@@ -412,8 +527,8 @@ func (f *FreqProxPostingsEnum) NextPosition() (int, error) {
 		if err != nil {
 			return 0, err
 		}
-		slices.Grow(f.payload, int(size))
-		if _, err := f.posReader.Read(f.payload); err != nil {
+		f.payload = slices.Grow(f.payload[:0], int(size))[:size]
+		if _, err := io.ReadFull(f.posReader, f.payload); err != nil {
 			return 0, err
 		}
 	}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"sync"
 	"sync/atomic"
 
 	"github.com/geange/lucene-go/core/document"
@@ -29,16 +31,26 @@ type baseIndexReader struct {
 }
 
 func newBaseIndexReader(spi IndexReaderSPI) *baseIndexReader {
-	return &baseIndexReader{
+	reader := &baseIndexReader{
 		spi:           spi,
+		closedByChild: new(atomic.Bool),
 		refCount:      new(atomic.Int64),
 		parentReaders: make(map[index.IndexReader]struct{}),
+		closed:        new(atomic.Bool),
 	}
+	// a new reader holds one reference, released by the last DecRef
+	reader.refCount.Store(1)
+	return reader
 }
 
+// Close
+// Closes files associated with this index. Also saves any new deletions to disk. The resources
+// are released once all references taken with IncRef are dropped as well.
 func (r *baseIndexReader) Close() error {
-	r.closed.Store(true)
-	return r.spi.DoClose()
+	if r.closed.Swap(true) {
+		return nil
+	}
+	return r.DecRef()
 }
 
 func (r *baseIndexReader) DocumentWithFields(ctx context.Context, docID int, fieldsToLoad []string) (*document.Document, error) {
@@ -127,13 +139,14 @@ func (r *baseIndexReader) ensureOpen() error {
 }
 
 func (r *baseIndexReader) TryIncRef() bool {
-	count := int64(0)
 	for {
-		count = r.refCount.Load()
+		count := r.refCount.Load()
 		if count <= 0 {
 			return false
 		}
-		return r.refCount.Swap(count+1) == count
+		if r.refCount.CompareAndSwap(count, count+1) {
+			return true
+		}
 	}
 }
 
@@ -188,6 +201,47 @@ func (r *ReaderSorter) Swap(i, j int) {
 	r.Readers[i], r.Readers[j] = r.Readers[j], r.Readers[i]
 }
 
-type ClosedListener interface {
-	// Invoked when the resource (segment core, or index reader) that is being cached on is closed.
+var _ index.CacheHelper = &cacheHelper{}
+
+// cacheKeyGen generates the cache keys, a key is never reused for the lifetime of the process.
+var cacheKeyGen = new(atomic.Int64)
+
+// cacheHelper
+// The CacheHelper of a reader or of the core of a segment. The closed listeners are notified once,
+// when the owner releases its resources.
+type cacheHelper struct {
+	key string
+
+	sync.Mutex
+	listeners []index.ClosedListener
+}
+
+func newCacheHelper() *cacheHelper {
+	return &cacheHelper{
+		key: strconv.FormatInt(cacheKeyGen.Add(1), 10),
+	}
+}
+
+func (c *cacheHelper) GetKey() string {
+	return c.key
+}
+
+func (c *cacheHelper) AddClosedListener(listener index.ClosedListener) {
+	c.Lock()
+	defer c.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// notifyClosedListeners invokes all the registered listeners, even if one of them fails.
+func (c *cacheHelper) notifyClosedListeners() error {
+	c.Lock()
+	listeners := c.listeners
+	c.listeners = nil
+	c.Unlock()
+
+	errs := make([]error, 0, len(listeners))
+	for _, listener := range listeners {
+		errs = append(errs, listener.OnClose(c.key))
+	}
+	return errors.Join(errs...)
 }
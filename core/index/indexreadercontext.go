@@ -32,3 +32,9 @@ func NewBaseIndexReaderContext(parent *CompositeReaderContext, ordInParent, docB
 func (r *BaseIndexReaderContext) Identity() string {
 	return r.identity
 }
+
+// Parent
+// Returns the context of this reader's immediate parent, or nil for the top level context.
+func (r *BaseIndexReaderContext) Parent() *CompositeReaderContext {
+	return r.parent
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"maps"
 	"math"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/geange/lucene-go/core/analysis"
 	"github.com/geange/lucene-go/core/analysis/standard"
@@ -26,6 +28,10 @@ var (
 	actualMaxDocs = MAX_POSITION
 )
 
+// ErrIndexWriterClosed is returned by operations which need an open IndexWriter, once it was closed
+// or rolled back.
+var ErrIndexWriterClosed = errors.New("this IndexWriter is closed")
+
 const (
 	// MAX_DOCS
 	// Hard limit on maximum number of documents that may be added to the index.
@@ -88,21 +94,32 @@ type IndexWriter struct {
 	mergeSource              MergeSource
 	writeDocValuesLock       sync.RWMutex
 	deleter                  *IndexFileDeleter
-	//segmentsToMerge          *hashmap.Map
+
+	// lock guards segmentInfos, the deleter and the merge bookkeeping below, it is
+	// the monitor of IndexWriter. cond is signalled whenever a merge finishes or the
+	// writer finished closing.
+	lock sync.Mutex
+	cond *sync.Cond
+
+	// used by forceMerge to note those needing merging
+	segmentsToMerge     map[index.SegmentCommitInfo]bool
 	mergeMaxNumSegments int
 	writeLock           store.Lock
 	closed              bool
 	closing             bool
-	atomMaybeMerge      *atomic.Bool
 	commitUserData      map[string]string
-	//mergingSegments          *hashset.Set
-	mergeScheduler MergeScheduler
+
+	// Holds all SegmentInfo instances currently involved in merges
+	mergingSegments map[index.SegmentCommitInfo]struct{}
+	mergeScheduler  MergeScheduler
 	//runningAddIndexesMerges  *hashset.Set
-	pendingMerges []*OneMerge
-	//runningMerges            *hashset.Set
+	pendingMerges         []*OneMerge
+	runningMerges         map[*OneMerge]struct{}
 	mergeExceptions       []*OneMerge
 	mergeGen              int64
 	merges                *Merges
+	mergeCtx              context.Context    // passed to the merges the merge scheduler runs
+	cancelMerges          context.CancelFunc // cancels mergeCtx, merges are aborted on rollback
 	didMessageState       bool
 	flushCount            *atomic.Int64
 	flushDeletesCount     *atomic.Int64
@@ -123,19 +140,32 @@ func NewIndexWriter(ctx context.Context, dir store.Directory, conf *IndexWriterC
 		lastCommitChangeCount: new(atomic.Int64),
 		pendingNumDocs:        new(atomic.Int64),
 		flushCount:            new(atomic.Int64),
-		atomMaybeMerge:        new(atomic.Bool),
-	}
+		segmentsToMerge:       map[index.SegmentCommitInfo]bool{},
+		mergingSegments:       map[index.SegmentCommitInfo]struct{}{},
+		runningMerges:         map[*OneMerge]struct{}{},
+		merges:                &Merges{mergesEnabled: true},
+	}
+	writer.cond = sync.NewCond(&writer.lock)
+	writer.mergeCtx, writer.cancelMerges = context.WithCancel(context.Background())
+	writer.mergeSource = newIndexWriterMergeSource(writer)
 	conf.setIndexWriter(writer)
 	writer.config = conf
 	writer.softDeletesEnabled = conf.getSoftDeletesField() != ""
 
+	pendingDeletions, err := dir.GetPendingDeletions()
+	if err != nil {
+		return nil, err
+	}
+	if len(pendingDeletions) > 0 {
+		return nil, errors.New("directory still has pending deleted files; cannot initialize IndexWriter")
+	}
+
 	writer.directoryOrig = dir
 	writer.directory = dir
 	writer.mergeScheduler = writer.config.GetMergeScheduler()
 	writer.mergeScheduler.Initialize(writer.directoryOrig)
 
 	mode := conf.GetOpenMode()
-	var err error
 	var indexExists, create bool
 	switch mode {
 	case CREATE:
@@ -301,7 +331,7 @@ func NewIndexWriter(ctx context.Context, dir store.Directory, conf *IndexWriterC
 	writer.flushNotifications = writer.newFlushNotifications()
 
 	writer.docWriter = NewDocumentsWriter(writer.flushNotifications, writer.segmentInfos.getIndexCreatedVersionMajor(), writer.pendingNumDocs,
-		writer.enableTestPoints, writer.newSegmentName(),
+		writer.enableTestPoints, writer.newSegmentName,
 		writer.config.liveIndexWriterConfig, writer.directoryOrig, writer.directory, writer.globalFieldNumberMap)
 
 	writer.bufferedUpdatesStream.GetCompletedDelGen()
@@ -513,117 +543,980 @@ func (w *IndexWriter) Close() error {
 	if w.config.GetCommitOnClose() {
 		return w.shutdown(context.Background())
 	}
-	// TODO: rollback
-	return w.shutdown(context.Background())
+	return w.Rollback(context.Background())
+}
+
+// Rollback
+// Close the IndexWriter without committing any changes that have occurred since the last commit
+// (or since it was opened, if commit hasn't been called). This removes any temporary files that had
+// been created, after which the state of the index will be the same as it was when commit() was
+// last called or when this writer was first opened. This also clears a previous call to prepareCommit.
+// Running merges are aborted.
+func (w *IndexWriter) Rollback(ctx context.Context) error {
+	// don't call ensureOpen here: this acts like "close()" in closeable.
+
+	// Ensure that only one thread actually gets to do the
+	// closing, and make sure no commit is also in progress:
+	if w.shouldClose(true) {
+		return w.rollbackInternal(ctx)
+	}
+	return nil
+}
+
+func (w *IndexWriter) updateDocuments(ctx context.Context, delNode *Node, docs []*document.Document) (int64, error) {
+	seqNo, err := w.docWriter.updateDocuments(ctx, docs, delNode)
+	if err != nil {
+		return 0, err
+	}
+
+	seqNo, err = w.maybeProcessEvents(seqNo)
+	if err != nil {
+		return 0, err
+	}
+	return seqNo, nil
+}
+
+func (w *IndexWriter) maybeProcessEvents(seqNo int64) (int64, error) {
+	if seqNo < 0 {
+		seqNo = -seqNo
+		if err := w.processEvents(true); err != nil {
+			return 0, err
+		}
+	}
+	return seqNo, nil
+}
+
+func (w *IndexWriter) processEvents(triggerMerge bool) error {
+	if err := w.eventQueue.processEvents(); err != nil {
+		return err
+	}
+
+	if triggerMerge {
+		return w.maybeMerge(w.config.GetMergePolicy(), MERGE_TRIGGER_EXPLICIT, UNBOUNDED_MAX_MERGE_SEGMENTS)
+	}
+	return nil
+}
+
+func (w *IndexWriter) MaybeMerge() error {
+	return w.maybeMerge(w.config.GetMergePolicy(), MERGE_TRIGGER_EXPLICIT, UNBOUNDED_MAX_MERGE_SEGMENTS)
+}
+
+// ForceMerge
+// Forces merge policy to merge segments until there are <= maxNumSegments. The actual merges to be
+// executed are determined by the MergePolicy.
+//
+// This is a horribly costly operation, especially when you pass a small maxNumSegments; usually
+// you should only call this if the index is static (will no longer be changed).
+//
+// Note that this requires free space that is proportional to the size of the index in your Directory:
+// 2X if you are not using compound file format, and 3X if you are. For example, if your index size
+// is 10 MB then you need an additional 20 MB free for this to complete (30 MB if you're using
+// compound file format). This is also affected by the Codec that is used to execute the merge,
+// and may result in even a bigger index. Also, it's best to call Commit afterwards, to allow
+// IndexWriter to free up disk space.
+//
+// If doWait is true, the call blocks until all merges required for the forced merge completed and
+// returns the error of the first failed one. Once ctx is done the forced merges are aborted and the
+// call returns the error of ctx, the segments merged so far are kept. The call returns
+// ErrIndexWriterClosed if the writer is closed in the meantime.
+//
+// If doWait is false the merges run in the background when the MergeScheduler is concurrent, ctx
+// is not used once they are registered.
+func (w *IndexWriter) ForceMerge(ctx context.Context, maxNumSegments int, doWait bool) error {
+	if err := w.checkOpen(true); err != nil {
+		return err
+	}
+
+	if maxNumSegments < 1 {
+		return fmt.Errorf("maxNumSegments must be >= 1; got %d", maxNumSegments)
+	}
+
+	if err := w.flush(true, true); err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	w.resetMergeExceptions()
+	clear(w.segmentsToMerge)
+	for _, info := range w.segmentInfos.segments {
+		w.segmentsToMerge[info] = true
+	}
+	w.mergeMaxNumSegments = maxNumSegments
+
+	// Now mark all pending & running merges for forced merge:
+	for _, merge := range w.pendingMerges {
+		merge.maxNumSegments = maxNumSegments
+		if merge.info != nil {
+			w.segmentsToMerge[merge.info] = true
+		}
+	}
+	for merge := range w.runningMerges {
+		merge.maxNumSegments = maxNumSegments
+		if merge.info != nil {
+			w.segmentsToMerge[merge.info] = true
+		}
+	}
+	w.lock.Unlock()
+
+	if doWait {
+		// the SerialMergeScheduler merges while we register the merges, the concurrent one in
+		// the background while we wait
+		stop := w.abortWhenDone(ctx, func() []*OneMerge {
+			merges := make([]*OneMerge, 0)
+			for _, merge := range w.pendingMerges {
+				if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+					merges = append(merges, merge)
+				}
+			}
+			for merge := range w.runningMerges {
+				if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+					merges = append(merges, merge)
+				}
+			}
+			return merges
+		})
+		defer stop()
+	}
+
+	if err := w.maybeMerge(w.config.GetMergePolicy(), MERGE_TRIGGER_EXPLICIT, maxNumSegments); err != nil {
+		return err
+	}
+
+	if !doWait {
+		// NOTE: in the ConcurrentMergeScheduler case, when
+		// doWait is false, we can return immediately while
+		// background goroutines accomplish the merging
+		return nil
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for {
+		for _, merge := range w.mergeExceptions {
+			if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+				return fmt.Errorf("background merge hit exception: %s: %w", merge, merge.err)
+			}
+		}
+
+		// aborted merges are not pending anymore
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !w.maxNumSegmentsMergesPending() {
+			break
+		}
+		w.doWait()
+	}
+
+	// If close is called while we are still
+	// running, return an error so the caller
+	// will know merging did not complete
+	return w.checkOpenLocked(true)
+}
+
+// Returns ErrIndexWriterClosed if the writer is closed, or closing if failIfClosing is true.
+func (w *IndexWriter) checkOpen(failIfClosing bool) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.checkOpenLocked(failIfClosing)
+}
+
+// checkOpenLocked is checkOpen for callers which hold w.lock
+func (w *IndexWriter) checkOpenLocked(failIfClosing bool) error {
+	if w.closed || (failIfClosing && w.closing) {
+		return ErrIndexWriterClosed
+	}
+	return nil
+}
+
+// Aborts the merges returned by merges once ctx is done, and wakes up the goroutines waiting on
+// w.cond so they see it. merges is called with w.lock held. The returned func stops watching ctx.
+func (w *IndexWriter) abortWhenDone(ctx context.Context, merges func() []*OneMerge) func() bool {
+	return context.AfterFunc(ctx, func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		for _, merge := range merges() {
+			merge.SetAborted()
+		}
+		w.cond.Broadcast()
+	})
+}
+
+// Returns true if any merges in pendingMerges or runningMerges are maxNumSegments merges.
+// w.lock must be held.
+func (w *IndexWriter) maxNumSegmentsMergesPending() bool {
+	for _, merge := range w.pendingMerges {
+		if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+			return true
+		}
+	}
+	for merge := range w.runningMerges {
+		if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+			return true
+		}
+	}
+	return false
+}
+
+// ForceMergeDeletes
+// Forces merging of all segments that have deleted documents. The actual merges to be executed are
+// determined by the MergePolicy. For example, TieredMergePolicy only picks a segment if the
+// percentage of deleted docs is over ForceMergeDeletesPctAllowed (10% by default).
+//
+// This is often a horribly costly operation; rarely is it warranted.
+//
+// To see how many deletions you have pending in your index, call IndexReader.NumDeletedDocs.
+//
+// If doWait is true, the call blocks until the selected merges completed and returns the error of
+// the first failed one. Once ctx is done the selected merges are aborted and the call returns the
+// error of ctx. The call returns ErrIndexWriterClosed if the writer is closed in the meantime. If
+// doWait is false ctx is not used once the merges are registered.
+func (w *IndexWriter) ForceMergeDeletes(ctx context.Context, doWait bool) error {
+	if err := w.checkOpen(true); err != nil {
+		return err
+	}
+
+	if err := w.flush(true, true); err != nil {
+		return err
+	}
+
+	mergePolicy := w.config.GetMergePolicy()
+
+	w.lock.Lock()
+	spec, err := mergePolicy.FindForcedDeletesMerges(w.segmentInfos, w)
+	if err == nil && spec != nil {
+		for _, merge := range spec.Merges() {
+			if _, err = w.registerMerge(merge); err != nil {
+				break
+			}
+		}
+	}
+	w.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if spec != nil && doWait {
+		stop := w.abortWhenDone(ctx, spec.Merges)
+		defer stop()
+	}
+
+	if err := w.executeMerge(MERGE_TRIGGER_EXPLICIT); err != nil {
+		return err
+	}
+
+	if spec == nil || !doWait {
+		return nil
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for {
+		if err := w.checkOpenLocked(false); err != nil {
+			return err
+		}
+
+		// Check each merge that MergePolicy asked us to
+		// do, to see if any of them are still running and
+		// if any of them have hit an error.
+		running := false
+		for _, merge := range spec.Merges() {
+			if _, ok := w.runningMerges[merge]; ok || slices.Contains(w.pendingMerges, merge) {
+				running = true
+			}
+			if merge.err != nil {
+				return fmt.Errorf("background merge hit exception: %s: %w", merge, merge.err)
+			}
+		}
+
+		// aborted merges are not running anymore
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// If any of our merges are still running, wait:
+		if !running {
+			return nil
+		}
+		w.doWait()
+	}
+}
+
+// Forgets the errors of earlier merges, so a new forceMerge only reports its own. w.lock must be held.
+func (w *IndexWriter) resetMergeExceptions() {
+	w.mergeExceptions = nil
+	w.mergeGen++
+}
+
+func (w *IndexWriter) maybeMerge(mergePolicy MergePolicy, trigger MergeTrigger, maxNumSegments int) error {
+	if err := w.ensureOpenV1(false); err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	spec, err := w.updatePendingMerges(mergePolicy, trigger, maxNumSegments)
+	w.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if spec != nil {
+		return w.executeMerge(trigger)
+	}
+	return nil
+}
+
+func (w *IndexWriter) ensureOpen() error {
+	// TODO: fix it
+	return nil
+}
+
+func (w *IndexWriter) ensureOpenV1(failIfClosing bool) error {
+	// TODO: fix it
+	return nil
+}
+
+func (w *IndexWriter) executeMerge(trigger MergeTrigger) error {
+	return w.mergeScheduler.Merge(w.mergeSource, trigger)
+}
+
+// Asks the MergePolicy for new merges and registers them as pending. w.lock must be held.
+func (w *IndexWriter) updatePendingMerges(mergePolicy MergePolicy, trigger MergeTrigger, maxNumSegments int) (*MergeSpecification, error) {
+	if maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS && maxNumSegments <= 0 {
+		return nil, fmt.Errorf("maxNumSegments must be > 0, got %d", maxNumSegments)
+	}
+
+	if !w.merges.areEnabled() {
+		return nil, nil
+	}
+
+	var spec *MergeSpecification
+	var err error
+	if maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
+		if trigger != MERGE_TRIGGER_EXPLICIT && trigger != MERGE_TRIGGER_MERGE_FINISHED {
+			return nil, fmt.Errorf("expected EXPLICIT or MERGE_FINISHED as trigger even with maxNumSegments set but was: %d", trigger)
+		}
+
+		spec, err = mergePolicy.FindForcedMerges(w.segmentInfos, maxNumSegments, maps.Clone(w.segmentsToMerge), w)
+		if err != nil {
+			return nil, err
+		}
+		if spec != nil {
+			for _, merge := range spec.Merges() {
+				merge.maxNumSegments = maxNumSegments
+			}
+		}
+	} else {
+		switch trigger {
+		case MERGE_TRIGGER_GET_READER, MERGE_TRIGGER_COMMIT:
+			spec, err = mergePolicy.FindFullFlushMerges(trigger, w.segmentInfos, w)
+		default:
+			spec, err = mergePolicy.FindMerges(trigger, w.segmentInfos, w)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if spec != nil {
+		for _, merge := range spec.Merges() {
+			if _, err := w.registerMerge(merge); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return spec, nil
+}
+
+// Checks whether this merge involves any segments already participating in a merge. If not, this
+// merge is "registered", meaning we record that its segments are now participating in a merge, and
+// true is returned. Else (the merge conflicts) false is returned. w.lock must be held.
+func (w *IndexWriter) registerMerge(merge *OneMerge) (bool, error) {
+	if merge.registerDone {
+		return true, nil
+	}
+
+	if !w.merges.areEnabled() {
+		merge.SetAborted()
+		return false, ErrMergeAborted
+	}
+
+	isExternal := false
+	for _, info := range merge.segments {
+		if _, ok := w.mergingSegments[info]; ok {
+			return false, nil
+		}
+		if !w.segmentInfos.contains(info) {
+			return false, nil
+		}
+		if info.Info().Dir() != w.directoryOrig {
+			isExternal = true
+		}
+		if _, ok := w.segmentsToMerge[info]; ok {
+			merge.maxNumSegments = w.mergeMaxNumSegments
+		}
+	}
+
+	w.pendingMerges = append(w.pendingMerges, merge)
+	merge.mergeGen = w.mergeGen
+	merge.isExternal = isExternal
+
+	// OK it does not conflict; now record that this
+	// merge is running (while synchronized) to avoid race
+	// condition where two conflicting merges from different
+	// threads, start
+	for _, info := range merge.segments {
+		w.mergingSegments[info] = struct{}{}
+	}
+
+	for _, info := range merge.segments {
+		maxDoc, err := info.Info().MaxDoc()
+		if err != nil {
+			return false, err
+		}
+		if maxDoc > 0 {
+			size, err := info.SizeInBytes()
+			if err != nil {
+				return false, err
+			}
+			delRatio := float64(w.NumDeletedDocs(info)) / float64(maxDoc)
+			merge.estimatedMergeBytes += int64(float64(size) * (1.0 - delRatio))
+			merge.totalMergeBytes += size
+		}
+	}
+
+	// Merge is now registered
+	merge.registerDone = true
+	return true, nil
+}
+
+// Expert: the MergeScheduler calls this method to retrieve the next merge requested by the MergePolicy.
+// Returns nil if there is no pending merge.
+func (w *IndexWriter) getNextMerge() (*OneMerge, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if len(w.pendingMerges) == 0 {
+		return nil, nil
+	}
+
+	// Advance the merge from pending to running
+	merge := w.pendingMerges[0]
+	w.pendingMerges = w.pendingMerges[1:]
+	w.runningMerges[merge] = struct{}{}
+	return merge, nil
+}
+
+// Expert: returns true if there are merges waiting to be scheduled.
+func (w *IndexWriter) hasPendingMerges() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return len(w.pendingMerges) != 0
+}
+
+// Merges the indicated segments, replacing them in the stack with a single segment.
+func (w *IndexWriter) merge(ctx context.Context, merge *OneMerge) error {
+	mergePolicy := w.config.GetMergePolicy()
+
+	// Cancelling ctx aborts the merge, it stops at its next abort check
+	stop := context.AfterFunc(ctx, merge.SetAborted)
+	defer stop()
+
+	err := w.mergeInit(merge)
+	if err == nil {
+		err = w.mergeMiddle(ctx, merge, mergePolicy)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err != nil {
+		// Readers are already closed in commitMerge if we didn't hit an error
+		_ = w.closeMergeReaders(merge, true)
+		if errors.Is(err, context.Canceled) && merge.IsAborted() {
+			err = ErrMergeAborted
+		}
+	}
+	w.mergeFinish(merge)
+
+	if err != nil {
+		return w.handleMergeError(merge, err)
+	}
+
+	if !merge.IsAborted() &&
+		(merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS || (!w.closed && !w.closing)) {
+		// This merge (and, generally, any change to the
+		// segments) may now enable new merges, so we call
+		// merge policy & update pending merges.
+		if _, err := w.updatePendingMerges(mergePolicy, MERGE_TRIGGER_MERGE_FINISHED, merge.maxNumSegments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Records the failure of a merge so forceMerge can report it. Aborted merges are expected while the
+// writer rolls back, their error is dropped unless the merge was requested externally. w.lock must be held.
+func (w *IndexWriter) handleMergeError(merge *OneMerge, err error) error {
+	if errors.Is(err, ErrMergeAborted) && !merge.isExternal {
+		return nil
+	}
+
+	merge.err = err
+	if merge.mergeGen == w.mergeGen && !slices.Contains(w.mergeExceptions, merge) {
+		w.mergeExceptions = append(w.mergeExceptions, merge)
+	}
+	return err
+}
+
+// Does initial setup for a merge, which is fast but holds the lock.
+func (w *IndexWriter) mergeInit(merge *OneMerge) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !merge.registerDone {
+		return errors.New("merge must be registered before it is initialized")
+	}
+
+	if err := merge.CheckAborted(); err != nil {
+		return err
+	}
+
+	if merge.info != nil {
+		// mergeInit already done
+		return nil
+	}
+
+	// Bind a new segment name here so even with
+	// ConcurrentMergeScheduler we keep deterministic segment
+	// names.
+	mergeSegmentName := w.newSegmentNameLocked()
+	// We set the min version to null for now, it will be set later by SegmentMerger
+	si := NewSegmentInfo(w.directoryOrig, version.Last, nil, mergeSegmentName, -1, false,
+		w.config.GetCodec(), map[string]string{}, util.RandomId(), map[string]string{}, w.config.GetIndexSort())
+	details := map[string]string{
+		"mergeMaxNumSegments": strconv.Itoa(merge.maxNumSegments),
+		"mergeFactor":         strconv.Itoa(len(merge.segments)),
+	}
+	if err := SetDiagnostics(si, SOURCE_MERGE, details); err != nil {
+		return err
+	}
+	merge.SetMergeInfo(index.NewSegmentCommitInfo(si, 0, 0, -1, -1, -1, util.RandomId()))
+	return nil
+}
+
+// Does the actual (time-consuming) work of the merge, but without holding the lock.
+func (w *IndexWriter) mergeMiddle(ctx context.Context, merge *OneMerge, mergePolicy MergePolicy) (err error) {
+	if err := merge.CheckAborted(); err != nil {
+		return err
+	}
+
+	mergeDirectory := w.mergeScheduler.WrapForMerge(merge, w.directory)
+	ioCtx := store.NewIOContext(store.WithMergeInfo(merge.GetStoreMergeInfo()))
+	ctx = store.WithIOContext(ctx, ioCtx)
+	dirWrapper := store.NewTrackingDirectoryWrapper(mergeDirectory)
+	si := merge.info.Info().(*SegmentInfo)
+
+	defer func() {
+		if err != nil {
+			// The files of a failed merge were never registered with the deleter
+			w.lock.Lock()
+			_ = w.deleteNewFiles(si.Files())
+			w.lock.Unlock()
+		}
+	}()
+
+	if err := w.initMergeReaders(ctx, merge, ioCtx); err != nil {
+		return err
+	}
+
+	readers := make([]index.CodecReader, 0, len(merge.mergeReaders))
+	for _, mergeReader := range merge.mergeReaders {
+		readers = append(readers, mergeReader.reader)
+	}
+
+	merger, err := NewSegmentMerger(readers, si, dirWrapper, w.globalFieldNumberMap, ioCtx)
+	if err != nil {
+		return err
+	}
+	if err := merge.CheckAborted(); err != nil {
+		return err
+	}
+
+	merge.mergeStartNS.Store(time.Now().UnixNano())
+
+	// This is where all the work happens:
+	var mergeState *MergeState
+	if merger.ShouldMerge() {
+		mergeState, err = merger.Merge(ctx)
+		si.SetFiles(dirWrapper.GetCreatedFiles())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Very important to do this before opening the reader
+	// because codec must know if prox was written for
+	// this segment:
+	w.lock.Lock()
+	useCompoundFile, err := mergePolicy.UseCompoundFile(w.segmentInfos, merge.info, w)
+	w.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if useCompoundFile {
+		filesToRemove := si.Files()
+		trackingCFSDir := store.NewTrackingDirectoryWrapper(mergeDirectory)
+		deleteFiles := func(files map[string]struct{}) {
+			_ = w.deleteNewFilesLocked(files)
+		}
+		if err := CreateCompoundFile(ctx, trackingCFSDir, si, ioCtx, deleteFiles); err != nil {
+			return err
+		}
+
+		// delete new non cfs files directly: they were never
+		// registered with IFD
+		if err := w.deleteNewFilesLocked(filesToRemove); err != nil {
+			return err
+		}
+		si.SetUseCompoundFile(true)
+
+		if err := merge.CheckAborted(); err != nil {
+			// This can happen if rollback is called while we were building
+			// our CFS, the deferred cleanup removes the CFS files again.
+			return err
+		}
+	}
+
+	// Have codec write SegmentInfo.  Must do this after
+	// creating CFS so that 1) .si isn't slurped into CFS,
+	// and 2) .si reflects useCompoundFile=true change
+	// above:
+	if err := w.config.GetCodec().SegmentInfoFormat().Write(ctx, w.directory, si, ioCtx); err != nil {
+		return err
+	}
+
+	return w.commitMerge(ctx, merge, mergeState)
+}
+
+// Opens a SegmentReader for every segment of the merge, the readers stay referenced until
+// closeMergeReaders releases them.
+func (w *IndexWriter) initMergeReaders(ctx context.Context, merge *OneMerge, ioCtx *store.IOContext) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, info := range merge.segments {
+		// Hold onto the "live" reader; we will use this to
+		// commit merged deletes
+		rld, err := w.readerPool.Get(info, true)
+		if err != nil {
+			return err
+		}
+		rld.setIsMerging()
+
+		reader, err := rld.GetReader(ctx, ioCtx)
+		if err != nil {
+			_ = w.release(rld, false)
+			return err
+		}
+		merge.mergeReaders = append(merge.mergeReaders, MergeReader{
+			reader:       reader,
+			hardLiveDocs: reader.GetHardLiveDocs(),
+		})
+	}
+	return nil
 }
 
-func (w *IndexWriter) updateDocuments(ctx context.Context, delNode *Node, docs []*document.Document) (int64, error) {
-	seqNo, err := w.docWriter.updateDocuments(ctx, docs, delNode)
-	if err != nil {
-		return 0, err
+// Replaces the merged segments with the merged one and checkpoints. Returns ErrMergeAborted
+// if the merge was aborted in the meantime.
+func (w *IndexWriter) commitMerge(ctx context.Context, merge *OneMerge, mergeState *MergeState) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := merge.CheckAborted(); err != nil {
+		// The caller deletes the new files, they were never checkpointed.
+		return err
 	}
 
-	seqNo, err = w.maybeProcessEvents(seqNo)
+	maxDoc, err := merge.info.Info().MaxDoc()
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return seqNo, nil
-}
 
-func (w *IndexWriter) maybeProcessEvents(seqNo int64) (int64, error) {
-	if seqNo < 0 {
-		seqNo = -seqNo
-		if err := w.processEvents(true); err != nil {
-			return 0, err
+	var mergedUpdates *ReadersAndUpdates
+	if maxDoc != 0 && mergeState != nil {
+		mergedUpdates, err = w.commitMergedDeletesAndUpdates(ctx, merge, mergeState)
+		if err != nil {
+			if mergedUpdates != nil {
+				mergedUpdates.dropChanges()
+				err = errors.Join(err, w.release(mergedUpdates, false))
+				_, dropErr := w.readerPool.drop(merge.info)
+				err = errors.Join(err, dropErr)
+			}
+			return err
 		}
 	}
-	return seqNo, nil
-}
 
-func (w *IndexWriter) processEvents(triggerMerge bool) error {
-	if err := w.eventQueue.processEvents(); err != nil {
+	// A merge of fully deleted segments produces an empty segment which is dropped, as does a
+	// merge whose documents all got deleted while merging
+	dropSegment := maxDoc == 0
+	if mergedUpdates != nil && !dropSegment {
+		dropSegment = mergedUpdates.GetDelCount() == maxDoc
+	}
+	if err := w.segmentInfos.applyMergeChanges(merge, dropSegment); err != nil {
 		return err
 	}
 
-	if triggerMerge {
-		return w.maybeMerge(w.config.GetMergePolicy(), MERGE_TRIGGER_EXPLICIT, UNBOUNDED_MAX_MERGE_SEGMENTS)
+	// Now deduct the deleted docs that we just reclaimed from this
+	// merge:
+	delDocCount := int(merge.totalMaxDoc) - maxDoc
+	if dropSegment {
+		// if we drop the segment we have to reduce the pendingNumDocs by merge.totalMaxDocs since we
+		// never drop the docs when we apply deletes if the segment is currently merged.
+		delDocCount = int(merge.totalMaxDoc)
 	}
-	return nil
-}
-
-func (w *IndexWriter) MaybeMerge() error {
-	return w.maybeMerge(w.config.GetMergePolicy(), MERGE_TRIGGER_EXPLICIT, UNBOUNDED_MAX_MERGE_SEGMENTS)
-}
+	w.adjustPendingNumDocs(-int64(delDocCount))
 
-func (w *IndexWriter) maybeMerge(mergePolicy MergePolicy, trigger MergeTrigger, maxNumSegments int) error {
-	err := w.ensureOpenV1(false)
-	if err != nil {
-		return err
+	if mergedUpdates != nil {
+		if dropSegment {
+			mergedUpdates.dropChanges()
+		}
+		// Without reader pooling this writes the carried over deletes of the merged segment
+		if err := w.release(mergedUpdates, !dropSegment); err != nil {
+			return err
+		}
 	}
 
-	if _, err := w.updatePendingMerges(mergePolicy, trigger, maxNumSegments); err != nil {
-		return w.executeMerge(trigger)
+	if dropSegment {
+		if _, err := w.readerPool.drop(merge.info); err != nil {
+			return err
+		}
+		// Safe: these files must exist
+		if err := w.deleteNewFiles(merge.info.Info().Files()); err != nil {
+			return err
+		}
 	}
-	return nil
-}
 
-func (w *IndexWriter) ensureOpen() error {
-	// TODO: fix it
-	return nil
-}
+	// Must close before checkpoint, otherwise IFD won't be
+	// able to delete the held-open files from the merge
+	// readers:
+	closeErr := w.closeMergeReaders(merge, false)
 
-func (w *IndexWriter) ensureOpenV1(failIfClosing bool) error {
-	// TODO: fix it
+	// Must note the change to segmentInfos so any commits
+	// in-flight don't lose it (IFD will incRef/protect the
+	// new files we created):
+	if err := w.checkpoint(); err != nil {
+		return errors.Join(closeErr, err)
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if merge.maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS && !dropSegment {
+		// cascade the forceMerge:
+		if _, ok := w.segmentsToMerge[merge.info]; !ok {
+			w.segmentsToMerge[merge.info] = false
+		}
+	}
 	return nil
 }
 
-func (w *IndexWriter) executeMerge(trigger MergeTrigger) error {
-	return w.mergeScheduler.Merge(w.mergeSource, trigger)
-}
+// Carries over the deletes and doc values updates the merged segments received while they were
+// merging to the merged segment. The merge only saw the documents that were live when its readers
+// were opened, every document deleted since then is deleted in the merged segment too, its id is
+// remapped through the doc maps of the merge. Doc values updates are added to the merged segment
+// the same way.
+//
+// Returns the pooled ReadersAndUpdates of the merged segment if anything was carried over, nil
+// otherwise. The caller must release it. w.lock must be held.
+func (w *IndexWriter) commitMergedDeletesAndUpdates(ctx context.Context, merge *OneMerge, mergeState *MergeState) (*ReadersAndUpdates, error) {
+	var mergedUpdates *ReadersAndUpdates
+
+	for i, mergeReader := range merge.mergeReaders {
+		info := mergeReader.reader.GetOriginalSegmentInfo()
+		// We still hold a ref so it should not have been removed:
+		rld, err := w.readerPool.Get(info, false)
+		if err != nil {
+			return mergedUpdates, err
+		}
+		if rld == nil {
+			return mergedUpdates, fmt.Errorf("no pooled reader for segment %s", info.Info().Name())
+		}
 
-func (w *IndexWriter) updatePendingMerges(mergePolicy MergePolicy, trigger MergeTrigger, maxNumSegments int) (*MergeSpecification, error) {
-	panic("")
-	/*
-		// In case infoStream was disabled on init, but then enabled at some
-		// point, try again to log the config here:
-		//if err := w.messageState(); err != nil {
-		//	return nil, err
-		//}
+		docMap := mergeState.DocMaps[i]
+
+		// Doc values updates resolved while we were merging are carried over to the merged
+		// segment, with their docs mapped to the merged segment
+		for _, fieldUpdates := range rld.mergingDVUpdates {
+			for _, updates := range fieldUpdates {
+				if mergedUpdates == nil {
+					mergedUpdates, err = w.readerPool.Get(merge.info, true)
+					if err != nil {
+						return nil, err
+					}
+				}
+				if err := mergedUpdates.AddDVUpdate(newMergedDocValuesFieldUpdates(updates, docMap)); err != nil {
+					return mergedUpdates, err
+				}
+			}
+		}
 
-		//assert maxNumSegments == UNBOUNDED_MAX_MERGE_SEGMENTS || maxNumSegments > 0;
-		//assert trigger != null;
-		if w.merges.areEnabled() == false {
-			return nil, errors.New("merges is disable")
+		prevHardLiveDocs := mergeReader.hardLiveDocs
+		currentHardLiveDocs := rld.pendingDeletes.GetHardLiveDocs()
+		if currentHardLiveDocs == nil {
+			// no deletes at all
+			continue
 		}
 
-		// Do not start new merges if disaster struck
-		//if w.tragedy != null {
-		//	return null
-		//}
+		maxDoc := mergeReader.reader.MaxDoc()
+		for j := 0; j < maxDoc; j++ {
+			if prevHardLiveDocs != nil && !prevHardLiveDocs.Test(uint(j)) {
+				// This means this document was deleted before the merge started, the merge
+				// already dropped it
+				continue
+			}
+			if currentHardLiveDocs.Test(uint(j)) {
+				continue
+			}
 
-		var spec *MergeSpecification
-		var err error
-		if maxNumSegments != UNBOUNDED_MAX_MERGE_SEGMENTS {
-			// TODO:
-		} else {
-			switch trigger {
-			case GET_READER:
-			case COMMIT:
-				spec, err = mergePolicy.FindFullFlushMerges(trigger, w.segmentInfos, w)
+			if mergedUpdates == nil {
+				mergedUpdates, err = w.readerPool.Get(merge.info, true)
 				if err != nil {
 					return nil, err
 				}
-				break
-			default:
-				spec, err = mergePolicy.FindMerges(trigger, w.segmentInfos, w)
 			}
+			if _, err := mergedUpdates.Delete(ctx, docMap.Get(j)); err != nil {
+				return mergedUpdates, err
+			}
+		}
+	}
+	return mergedUpdates, nil
+}
+
+// Releases the readers of the merge. When the merge failed (suppressErrors) the pending changes
+// of the source segments are kept, otherwise they were merged away and are dropped together with
+// the pooled readers. w.lock must be held.
+func (w *IndexWriter) closeMergeReaders(merge *OneMerge, suppressErrors bool) error {
+	drop := !suppressErrors
+
+	var errs []error
+	for _, mergeReader := range merge.mergeReaders {
+		info := mergeReader.reader.GetOriginalSegmentInfo()
+		// We still hold a ref so it should not have been removed:
+		rld, err := w.readerPool.Get(info, false)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rld == nil {
+			errs = append(errs, fmt.Errorf("no pooled reader for segment %s", info.Info().Name()))
+			continue
+		}
+
+		if drop {
+			rld.dropChanges()
+		} else {
+			rld.dropMergingUpdates()
+		}
+		errs = append(errs, rld.Release(mergeReader.reader))
+		errs = append(errs, w.release(rld, true))
+		if drop {
+			_, err := w.readerPool.drop(rld.info)
+			errs = append(errs, err)
 		}
+	}
+	merge.mergeReaders = nil
 
+	if suppressErrors {
 		return nil
-	*/
+	}
+	return errors.Join(errs...)
+}
+
+// Does finishing for a merge, which is fast but holds the lock. w.lock must be held.
+func (w *IndexWriter) mergeFinish(merge *OneMerge) {
+	// forceMerge, addIndexes or waitForMerges may be waiting
+	// on merges to finish.
+	w.cond.Broadcast()
+
+	// It's possible we are called twice, eg if there was an
+	// exception inside mergeInit
+	if merge.registerDone {
+		for _, info := range merge.segments {
+			delete(w.mergingSegments, info)
+		}
+		merge.registerDone = false
+	}
+
+	delete(w.runningMerges, merge)
+}
+
+// Aborts running merges and waits until they stopped. This disables merges for the rest of the
+// writer's life, it is only used when closing. w.lock must be held.
+func (w *IndexWriter) abortMerges() {
+	// this disables merges forever since we are closing and can't reenable them
+	w.merges.disable()
+	w.cancelMerges()
+
+	// Abort all pending & running merges:
+	for _, merge := range w.pendingMerges {
+		merge.SetAborted()
+		w.mergeFinish(merge)
+	}
+	w.pendingMerges = nil
+
+	for merge := range w.runningMerges {
+		merge.SetAborted()
+	}
+
+	// We wait here to make all merges stop.  It should not
+	// take very long because they periodically check if
+	// they are aborted.
+	for len(w.runningMerges) != 0 {
+		w.doWait()
+	}
+
+	w.cond.Broadcast()
+}
+
+// NumDeletesToMerge
+// Returns the number of deletes a merge would claim back if the given segment is merged.
+func (w *IndexWriter) NumDeletesToMerge(info index.SegmentCommitInfo) (int, error) {
+	return w.NumDeletedDocs(info), nil
+}
+
+// NumDeletedDocs
+// Obtain the number of deleted docs for a pooled reader. If the reader isn't being pooled,
+// the segmentInfo's delCount is returned.
+func (w *IndexWriter) NumDeletedDocs(info index.SegmentCommitInfo) int {
+	rld, err := w.readerPool.Get(info, false)
+	if err == nil && rld != nil {
+		return rld.GetDelCount()
+	}
+	return info.GetDelCountWithSoftDeletes(true)
+}
+
+// GetMergingSegments
+// Expert: to be used by a MergePolicy to avoid selecting merges for segments already being merged.
+// The returned collection is not cloned, and thus is only safe to access if you hold IndexWriter's
+// lock (which you do when IndexWriter invokes the MergePolicy).
+func (w *IndexWriter) GetMergingSegments() []index.SegmentCommitInfo {
+	return slices.Collect(maps.Keys(w.mergingSegments))
 }
 
 func (w *IndexWriter) newSegmentName() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.newSegmentNameLocked()
+}
+
+// newSegmentNameLocked is newSegmentName for callers which hold w.lock
+func (w *IndexWriter) newSegmentNameLocked() string {
 	w.changeCount.Add(1)
 	w.segmentInfos.Changed()
 	v := w.segmentInfos.counter
@@ -657,50 +1550,64 @@ func (w *IndexWriter) shutdown(ctx context.Context) error {
 		return errors.New("cannot close: prepareCommit was already called with no corresponding call to commit")
 	}
 
-	if w.shouldClose(true) {
-		if err := w.flush(true, true); err != nil {
-			return err
-		}
-		if err := w.waitForMerges(); err != nil {
-			return err
-		}
-		if _, err := w.commitInternal(ctx, w.config.GetMergePolicy()); err != nil {
-			return err
-		}
+	// Ensure that only one thread actually gets to do the
+	// closing
+	if !w.shouldClose(true) {
+		return nil
 	}
 
-	// Ensure that only one thread actually gets to do the closing
-
-	// TODO:
-	//err := w.docWriter.Flush(ctx)
-	//if err != nil {
-	//	return err
-	//}
-	return w.rollbackInternal(ctx)
+	err := w.flush(true, true)
+	if err == nil {
+		err = w.waitForMerges()
+	}
+	if err == nil {
+		_, err = w.commitInternal(ctx, w.config.GetMergePolicy())
+	}
+	if err != nil {
+		// the writer is closed either way, but the changes since the last commit are lost
+		return errors.Join(err, w.rollbackInternal(ctx))
+	}
+	return w.rollbackInternal(ctx) // ie close, since we just committed
 }
 
 func (w *IndexWriter) rollbackInternal(ctx context.Context) error {
-	return w.rollbackInternalNoCommit(ctx)
+	err := w.rollbackInternalNoCommit(ctx)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.closed = true
+	w.closing = false
+	// So any "concurrently closing" threads wake up and see that the close has now completed:
+	w.cond.Broadcast()
+	return err
 }
 
 func (w *IndexWriter) rollbackInternalNoCommit(ctx context.Context) error {
-	// Must pre-close in case it increments changeCount so that we can then
-	// set it to false before calling rollbackInternal
-	err := w.mergeScheduler.Close()
-	if err != nil {
-		return err
-	}
+	w.lock.Lock()
+	// must be synced otherwise register merge might throw and exception if merges
+	// changes concurrently, abortMerges is synced as well
+	w.abortMerges() // this disables merges forever since we are closing and can't reenable them
+	w.lock.Unlock()
+
+	// This must happen after we've aborted merges, because aborting a merge will
+	// stop running merges and then close the merge scheduler. Errors of merges
+	// are reported, but must not keep us from rolling back.
+	schedulerErr := w.mergeScheduler.Close()
 
 	w.docWriter.Close() // mark it as closed first to prevent subsequent indexing actions/flushes
 	w.docWriter.Abort() // don't sync on IW here
 	//w.docWriter.flushControl.waitForFlush(); // wait for all concurrently running flushes
 	if err := w.publishFlushedSegments(true); err != nil {
-		return err
+		return errors.Join(schedulerErr, err)
 	}
 
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	if w.pendingCommit != nil {
 		if err := w.pendingCommit.RollbackCommit(w.directory); err != nil {
-			return err
+			return errors.Join(schedulerErr, err)
 		}
 		//w.deleter.decRef(pendingCommit);
 		//try {
@@ -716,20 +1623,34 @@ func (w *IndexWriter) rollbackInternalNoCommit(ctx context.Context) error {
 	// of its SegmentInfo instances so IFD below will remove
 	// any segments we flushed since the last commit:
 	if err := w.segmentInfos.rollbackSegmentInfos(w.rollbackSegments); err != nil {
-		return err
+		return errors.Join(schedulerErr, err)
 	}
 	rollbackMaxDoc := w.segmentInfos.TotalMaxDoc()
 	// now we need to adjust this back to the rolled back SI but don't set it to the absolute value
 	// otherwise we might hide internal bugsf
 	w.adjustPendingNumDocs(-(totalMaxDoc - rollbackMaxDoc))
 
-	return nil
+	// Don't bother saving any changes in our segmentInfos
+	if err := w.readerPool.dropAll(); err != nil {
+		return errors.Join(schedulerErr, err)
+	}
+
+	// Ask deleter to locate unreferenced files & remove
+	// them ... only when we are not experiencing a tragedy, else
+	// these methods throw ACE:
+	if err := w.deleter.Checkpoint(w.segmentInfos, false); err != nil {
+		return errors.Join(schedulerErr, err)
+	}
+	return schedulerErr
 }
 
 // Returns true if this thread should attempt to close, or
 // false if IndexWriter is now closed; else,
 // waits until another thread finishes closing
 func (w *IndexWriter) shouldClose(waitForClose bool) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	for {
 		if w.closed == false {
 			if w.closing == false {
@@ -760,23 +1681,25 @@ func (w *IndexWriter) waitForMerges() error {
 		return err
 	}
 
-	// FIXME: w.runningMerges.size() > 0
-	for len(w.pendingMerges) > 0 {
-		w.doWait()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.ensureOpenV1(false); err != nil {
+		return err
 	}
 
+	for len(w.pendingMerges) > 0 || len(w.runningMerges) > 0 {
+		w.doWait()
+	}
 	return nil
 }
 
-// FIXME: how to
+// Waits until a merge finished or the writer finished closing. w.lock must be held.
 func (w *IndexWriter) doWait() {
-	// NOTE: the callers of this method should in theory
-	// be able to do simply wait(), but, as a defense
-	// against thread timing hazards where notifyAll()
-	// fails to be called, we wait for at most 1 second
-	// and then return so caller can check if wait
-	// conditions are satisfied:
-
+	// NOTE: Lucene waits for at most 1 second here as defense against a
+	// missed notifyAll(), every change the callers wait for is followed
+	// by a Broadcast so a plain Wait is enough.
+	w.cond.Wait()
 }
 
 func (w *IndexWriter) commitInternal(ctx context.Context, mergePolicy MergePolicy) (int64, error) {
@@ -795,7 +1718,7 @@ func (w *IndexWriter) commitInternal(ctx context.Context, mergePolicy MergePolic
 		return 0, err
 	}
 
-	if w.atomMaybeMerge.Swap(false) {
+	if w.boolMaybeMerge.Swap(false) {
 		err := w.maybeMerge(mergePolicy, MERGE_TRIGGER_FULL_FLUSH, UNBOUNDED_MAX_MERGE_SEGMENTS)
 		if err != nil {
 			return 0, err
@@ -815,22 +1738,26 @@ func (w *IndexWriter) IsClosed() bool {
 }
 
 func (w *IndexWriter) nrtIsCurrent(infos *SegmentInfos) bool {
-	return false
+	w.lock.Lock()
+	defer w.lock.Unlock()
 
-	// TODO: fix it
-	//ensureOpen();
-	//isCurrent := infos.GetVersion() == w.segmentInfos.GetVersion() &&
-	//	w.docWriter.anyChanges() == false &&
-	//	//i.bufferedUpdatesStream.Any() == false &&
-	//	w.readerPool.anyDocValuesChanges() == false
-	//return isCurrent
+	return infos.GetVersion() == w.segmentInfos.GetVersion() &&
+		!w.docWriter.anyChanges() &&
+		!w.readerPool.anyDocValuesChanges()
 }
 
+// GetReader
+// Expert: returns a near real-time reader exposing all changes made with this writer so far,
+// flushing any buffered documents first. The segment readers are pooled by the writer, so
+// reopening with OpenIfChangedFromWriter only opens segments that changed since.
 func (w *IndexWriter) GetReader(ctx context.Context, applyAllDeletes bool, writeAllDeletes bool) (index.DirectoryReader, error) {
 	if writeAllDeletes && applyAllDeletes == false {
 		return nil, errors.New("applyAllDeletes must be true when writeAllDeletes=true")
 	}
 
+	// the NRT readers share the pooled segment readers from now on
+	w.readerPool.enableReaderPooling()
+
 	// this function is used to control which SR are opened in order to keep track of them
 	// and to reuse them in the case we wait for merges in this getReader call.
 
@@ -842,9 +1769,8 @@ func (w *IndexWriter) GetReader(ctx context.Context, applyAllDeletes bool, write
 		if err != nil {
 			return nil, err
 		}
-
-		segmentReader, err := rld.GetReader(ctx, nil)
-		if err != nil {
+		segmentReader, err := rld.GetReadOnlyClone(ctx, store.READ)
+		if err := errors.Join(err, w.release(rld, true)); err != nil {
 			return nil, err
 		}
 		if maxFullFlushMergeWaitMillis > 0 { // only track this if we actually do fullFlush merges
@@ -853,6 +1779,18 @@ func (w *IndexWriter) GetReader(ctx context.Context, applyAllDeletes bool, write
 		return segmentReader, nil
 	}
 
+	if _, err := w.doFlush(applyAllDeletes); err != nil {
+		return nil, err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.writeReaderPool(writeAllDeletes); err != nil {
+		return nil, err
+	}
+
+	// Prevent segmentInfos from changing while opening the reader
 	return OpenStandardDirectoryReader(w, readerFactory, w.segmentInfos, applyAllDeletes, writeAllDeletes)
 }
 
@@ -861,8 +1799,14 @@ func (w *IndexWriter) Release(readersAndUpdates *ReadersAndUpdates) error {
 }
 
 func (w *IndexWriter) release(readersAndUpdates *ReadersAndUpdates, assertLiveInfo bool) error {
-	//if i.readerPool.
-	panic("")
+	changed, err := w.readerPool.release(readersAndUpdates, assertLiveInfo)
+	if err != nil {
+		return err
+	}
+	if changed {
+		return w.checkpointNoSIS()
+	}
+	return nil
 }
 
 func (w *IndexWriter) doBeforeFlush() error {
@@ -1162,6 +2106,14 @@ func (w *IndexWriter) deleteNewFiles(files map[string]struct{}) error {
 	return w.deleter.deleteFiles(files)
 }
 
+// deleteNewFilesLocked is deleteNewFiles for callers which don't hold w.lock
+func (w *IndexWriter) deleteNewFilesLocked(files map[string]struct{}) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.deleteNewFiles(files)
+}
+
 func (w *IndexWriter) flushFailed(info *SegmentInfo) error {
 	files := info.Files()
 	return w.deleter.deleteNewFiles(files)
@@ -1201,6 +2153,9 @@ func (w *IndexWriter) startCommit(toSync *SegmentInfos) error {
 	}
 
 	if w.pendingCommitChangeCount == w.lastCommitChangeCount.Load() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+
 		err := w.deleter.DecRef(w.filesToCommit)
 		if err != nil {
 			return err
@@ -1219,16 +2174,24 @@ func (w *IndexWriter) startCommit(toSync *SegmentInfos) error {
 	w.pendingCommit = toSync
 
 	filesToSync, err := toSync.Files(false)
+	if err != nil {
+		return err
+	}
 	err = w.directory.Sync(filesToSync)
 	if err != nil {
 		return err
 	}
 
+	w.lock.Lock()
 	w.segmentInfos.UpdateGeneration(toSync)
+	w.lock.Unlock()
 	return nil
 }
 
 func (w *IndexWriter) finishCommit(ctx context.Context) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	if w.pendingCommit != nil {
 		commitFiles := w.filesToCommit
 
@@ -1299,45 +2262,13 @@ func (w *IndexWriter) prepareCommitInternal() (int64, error) {
 		return 0, err
 	}
 
-	err = w.writeReaderPool(true)
+	w.lock.Lock()
+	toCommit, err := w.cloneForCommit()
+	w.lock.Unlock()
 	if err != nil {
 		return 0, err
 	}
 
-	if w.changeCount.Load() != w.lastCommitChangeCount.Load() {
-		// There are changes to commit, so we will write a new segments_N in startCommit.
-		// The act of committing is itself an NRT-visible change (an NRT reader that was
-		// just opened before this should see it on reopen) so we increment changeCount
-		// and segments version so a future NRT reopen will see the change:
-		w.changeCount.Add(1)
-		w.segmentInfos.Changed()
-	}
-
-	if w.commitUserData != nil {
-		userData := maps.Clone(w.commitUserData)
-		w.segmentInfos.SetUserData(userData, false)
-	}
-
-	// Must clone the segmentInfos while we still
-	// hold fullFlushLock and while sync'd so that
-	// no partial changes (eg a delete w/o
-	// corresponding add from an updateDocument) can
-	// sneak into the commit point:
-	toCommit := w.segmentInfos.Clone()
-	w.pendingCommitChangeCount = w.changeCount.Load()
-	// This protects the segmentInfos we are now going
-	// to commit.  This is important in case, eg, while
-	// we are trying to sync all referenced files, a
-	// merge completes which would otherwise have
-	// removed the files we are now syncing.
-	files, err := toCommit.Files(false)
-	if err != nil {
-		return 0, err
-	}
-	err = w.deleter.IncRefFiles(files)
-	if err != nil {
-		return 0, err
-	}
 	if anyChanges {
 		// we can safely call preparePointInTimeMerge since writeReaderPool(true) above wrote all
 		// necessary files to disk and checkpointed them.
@@ -1376,6 +2307,50 @@ func (w *IndexWriter) prepareCommitInternal() (int64, error) {
 	}
 }
 
+// Writes all pending changes of the reader pool and clones segmentInfos for the commit, the files
+// of the clone are protected from deletion until the commit finished. w.lock must be held.
+func (w *IndexWriter) cloneForCommit() (*SegmentInfos, error) {
+	if err := w.writeReaderPool(true); err != nil {
+		return nil, err
+	}
+
+	if w.changeCount.Load() != w.lastCommitChangeCount.Load() {
+		// There are changes to commit, so we will write a new segments_N in startCommit.
+		// The act of committing is itself an NRT-visible change (an NRT reader that was
+		// just opened before this should see it on reopen) so we increment changeCount
+		// and segments version so a future NRT reopen will see the change:
+		w.changeCount.Add(1)
+		w.segmentInfos.Changed()
+	}
+
+	if w.commitUserData != nil {
+		userData := maps.Clone(w.commitUserData)
+		w.segmentInfos.SetUserData(userData, false)
+	}
+
+	// Must clone the segmentInfos while we still
+	// hold fullFlushLock and while sync'd so that
+	// no partial changes (eg a delete w/o
+	// corresponding add from an updateDocument) can
+	// sneak into the commit point:
+	toCommit := w.segmentInfos.Clone()
+	w.pendingCommitChangeCount = w.changeCount.Load()
+	// This protects the segmentInfos we are now going
+	// to commit.  This is important in case, eg, while
+	// we are trying to sync all referenced files, a
+	// merge completes which would otherwise have
+	// removed the files we are now syncing.
+	files, err := toCommit.Files(false)
+	if err != nil {
+		return nil, err
+	}
+	err = w.deleter.IncRefFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	return toCommit, nil
+}
+
 type KV struct {
 	Key   string
 	Value string
@@ -1390,6 +2365,9 @@ func (w *IndexWriter) publishFrozenUpdates(updates *FrozenBufferedUpdates) int64
 func (w *IndexWriter) publishFlushedSegment(newSegment index.SegmentCommitInfo, fieldInfos index.FieldInfos,
 	packet *FrozenBufferedUpdates, globalPacket *FrozenBufferedUpdates, sortMap index.DocMap) error {
 
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
 	published := false
 
 	if globalPacket != nil && globalPacket.Any() {
@@ -1466,8 +2444,11 @@ func (w *IndexWriter) publishFlushedSegment(newSegment index.SegmentCommitInfo,
 	return nil
 }
 
+// Checkpoints with IndexFileDeleter, so it's aware of new files, and increments changeCount, so on
+// close/commit we will write a new segments file, but does NOT bump segmentInfos.version.
 func (w *IndexWriter) checkpointNoSIS() error {
-	panic("")
+	w.changeCount.Add(1)
+	return w.deleter.Checkpoint(w.segmentInfos, false)
 }
 
 func (w *IndexWriter) checkpoint() error {
@@ -1572,6 +2553,14 @@ func (c *IndexWriterConfig) GetMergeScheduler() MergeScheduler {
 	return c.mergeScheduler
 }
 
+// SetMergeScheduler
+// Expert: sets the merge scheduler used by this writer. The default is NoMergeScheduler.
+// Only takes effect when IndexWriter is first created.
+func (c *IndexWriterConfig) SetMergeScheduler(mergeScheduler MergeScheduler) *IndexWriterConfig {
+	c.mergeScheduler = mergeScheduler
+	return c
+}
+
 func (c *IndexWriterConfig) GetOpenMode() OpenMode {
 	return c.openMode
 }
@@ -0,0 +1,515 @@
+package index_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMergeTestWriter Opens a writer merging with TieredMergePolicy and the given scheduler
+func newMergeTestWriter(t *testing.T, dir store.Directory, scheduler coreIndex.MergeScheduler) *coreIndex.IndexWriter {
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity)
+	config.SetMergeScheduler(scheduler)
+	config.SetMergePolicy(coreIndex.NewTieredMergePolicy())
+	config.SetUseCompoundFile(false)
+
+	writer, err := coreIndex.NewIndexWriter(context.Background(), dir, config)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = writer.Rollback(context.Background()) })
+	return writer
+}
+
+// addSegments Commits numSegments segments of numDocs documents each, the id of a document is
+// <segment>-<docID>
+func addSegments(t *testing.T, writer *coreIndex.IndexWriter, numSegments, numDocs int) {
+	ctx := context.Background()
+	for i := 0; i < numSegments; i++ {
+		for j := 0; j < numDocs; j++ {
+			doc := document.NewDocument()
+			doc.Add(document.NewStringField("id", fmt.Sprintf("%d-%d", i, j), false))
+			_, err := writer.AddDocument(ctx, doc)
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, writer.Commit(ctx))
+	}
+}
+
+// liveIds Returns the ids of the live documents of every segment
+func liveIds(t *testing.T, dir store.Directory) [][]string {
+	ctx := context.Background()
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+
+	segments := make([][]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		liveDocs := leaf.LeafReader().GetLiveDocs()
+		terms, err := leaf.LeafReader().Terms("id")
+		assert.Nil(t, err)
+		termsEnum, err := terms.Iterator()
+		assert.Nil(t, err)
+
+		ids := make([]string, 0)
+		for {
+			term, err := termsEnum.Next(ctx)
+			if term == nil || errors.Is(err, io.EOF) {
+				break
+			}
+			assert.Nil(t, err)
+			postings, err := termsEnum.Postings(nil, coreIndex.POSTINGS_ENUM_NONE)
+			assert.Nil(t, err)
+			doc, err := postings.NextDoc(ctx)
+			assert.Nil(t, err)
+			if liveDocs == nil || liveDocs.Test(uint(doc)) {
+				ids = append(ids, string(term))
+			}
+		}
+		segments = append(segments, ids)
+	}
+	return segments
+}
+
+func TestIndexWriter_MergeCarriesOverDeletes(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	cms := coreIndex.NewConcurrentMergeScheduler()
+	// forced merges stop at their first write until the rate is raised
+	cms.SetForceMergeMBPerSec(0)
+	writer := newMergeTestWriter(t, dir, cms)
+
+	addSegments(t, writer, 4, 10)
+
+	// 3-9 is deleted before the merge starts
+	infos := coreIndex.Segments(writer)
+	assert.Len(t, infos, 4)
+	deleted, err := coreIndex.DeleteDocument(ctx, writer, infos[3], 9)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+	assert.Nil(t, writer.Commit(ctx))
+
+	assert.Nil(t, writer.ForceMerge(ctx, 1, false))
+
+	var segments []index.SegmentCommitInfo
+	assert.Eventually(t, func() bool {
+		segments = coreIndex.RunningMergeSegments(writer)
+		return len(segments) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the merge already read these segments, doc 3 of every segment is deleted while merging
+	for _, info := range segments {
+		deleted, err := coreIndex.DeleteDocument(ctx, writer, info, 3)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+	}
+
+	cms.SetForceMergeMBPerSec(math.Inf(1))
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+	assert.Nil(t, writer.Commit(ctx))
+
+	segmentIds := liveIds(t, dir)
+	assert.Len(t, segmentIds, 1)
+
+	expected := make([]string, 0)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 10; j++ {
+			if j != 3 && (i != 3 || j != 9) {
+				expected = append(expected, fmt.Sprintf("%d-%d", i, j))
+			}
+		}
+	}
+	assert.ElementsMatch(t, expected, segmentIds[0])
+}
+
+// testDocValuesUpdates Resolved numeric doc values updates of a few docs of a segment
+type testDocValuesUpdates struct {
+	coreIndex.DocValuesFieldUpdates
+
+	field  string
+	docs   []int
+	values []int64
+}
+
+func (u *testDocValuesUpdates) Field() string {
+	return u.field
+}
+
+func (u *testDocValuesUpdates) GetFinished() bool {
+	return true
+}
+
+func (u *testDocValuesUpdates) Any() bool {
+	return len(u.docs) > 0
+}
+
+func (u *testDocValuesUpdates) Size() int {
+	return len(u.docs)
+}
+
+func (u *testDocValuesUpdates) Iterator() (coreIndex.DocValuesFieldUpdatesIterator, error) {
+	return &testDocValuesUpdatesIterator{updates: u, i: -1}, nil
+}
+
+type testDocValuesUpdatesIterator struct {
+	updates *testDocValuesUpdates
+	i       int
+}
+
+func (it *testDocValuesUpdatesIterator) DocID() int {
+	if it.i < 0 {
+		return -1
+	}
+	if it.i >= len(it.updates.docs) {
+		return types.NO_MORE_DOCS
+	}
+	return it.updates.docs[it.i]
+}
+
+func (it *testDocValuesUpdatesIterator) NextDoc(ctx context.Context) (int, error) {
+	it.i++
+	return it.DocID(), nil
+}
+
+func (it *testDocValuesUpdatesIterator) Advance(ctx context.Context, target int) (int, error) {
+	return it.SlowAdvance(ctx, target)
+}
+
+func (it *testDocValuesUpdatesIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	for it.DocID() < target {
+		it.i++
+	}
+	return it.DocID(), nil
+}
+
+func (it *testDocValuesUpdatesIterator) Cost() int64 {
+	return int64(len(it.updates.docs))
+}
+
+func (it *testDocValuesUpdatesIterator) AdvanceExact(target int) (bool, error) {
+	doc, _ := it.SlowAdvance(context.Background(), target)
+	return doc == target, nil
+}
+
+func (it *testDocValuesUpdatesIterator) LongValue() (int64, error) {
+	return it.updates.values[it.i], nil
+}
+
+func (it *testDocValuesUpdatesIterator) BinaryValue() ([]byte, error) {
+	return nil, errors.New("numeric updates")
+}
+
+func (it *testDocValuesUpdatesIterator) DelGen() int64 {
+	return 0
+}
+
+func (it *testDocValuesUpdatesIterator) HasValue() bool {
+	return true
+}
+
+func TestIndexWriter_MergeCarriesOverDocValuesUpdates(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	cms := coreIndex.NewConcurrentMergeScheduler()
+	cms.SetForceMergeMBPerSec(0)
+	writer := newMergeTestWriter(t, dir, cms)
+
+	addSegments(t, writer, 4, 10)
+
+	// doc 9 of the last segment is deleted before the merge starts, the merge drops it
+	infos := coreIndex.Segments(writer)
+	assert.Len(t, infos, 4)
+	deleted, err := coreIndex.DeleteDocument(ctx, writer, infos[3], 9)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+	assert.Nil(t, writer.Commit(ctx))
+
+	assert.Nil(t, writer.ForceMerge(ctx, 1, false))
+	var segments []index.SegmentCommitInfo
+	assert.Eventually(t, func() bool {
+		segments = coreIndex.RunningMergeSegments(writer)
+		return len(segments) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// updates resolved while merging, one of them for the dropped doc
+	updates := map[index.SegmentCommitInfo]*testDocValuesUpdates{
+		infos[1]: {field: "rank", docs: []int{2, 3, 5}, values: []int64{20, 30, 50}},
+		infos[3]: {field: "rank", docs: []int{8, 9}, values: []int64{80, 90}},
+	}
+	for info, update := range updates {
+		assert.Nil(t, coreIndex.AddDocValuesUpdate(writer, info, update))
+	}
+
+	cms.SetForceMergeMBPerSec(math.Inf(1))
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+
+	merged := coreIndex.Segments(writer)
+	assert.Len(t, merged, 1)
+
+	// the merged segment holds the docs of the segments in merge order
+	expected := make(map[int]int64)
+	docBase := 0
+	for _, info := range segments {
+		if update, ok := updates[info]; ok {
+			for i, doc := range update.docs {
+				if info != infos[3] || doc != 9 {
+					expected[docBase+doc] = update.values[i]
+				}
+			}
+		}
+		docBase += 10
+		if info == infos[3] {
+			docBase--
+		}
+	}
+
+	mergedUpdates, err := coreIndex.DocValuesUpdates(writer, merged[0], "rank")
+	assert.Nil(t, err)
+	assert.Len(t, mergedUpdates, 2)
+	actual := make(map[int]int64)
+	for _, update := range mergedUpdates {
+		it, err := update.Iterator()
+		assert.Nil(t, err)
+		for {
+			doc, err := it.NextDoc(ctx)
+			assert.Nil(t, err)
+			if doc == types.NO_MORE_DOCS {
+				break
+			}
+			value, err := it.LongValue()
+			assert.Nil(t, err)
+			actual[doc] = value
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestIndexWriter_RollbackAbortsMerges(t *testing.T) {
+	for _, name := range []string{"Rollback", "Close"} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dir, err := store.NewNIOFSDirectory(t.TempDir())
+			assert.Nil(t, err)
+			defer dir.Close()
+
+			cms := coreIndex.NewConcurrentMergeScheduler()
+			cms.SetForceMergeMBPerSec(0)
+			writer := newMergeTestWriter(t, dir, cms)
+
+			addSegments(t, writer, 4, 10)
+			assert.Nil(t, writer.ForceMerge(ctx, 1, false))
+			assert.Eventually(t, func() bool {
+				return len(coreIndex.RunningMergeSegments(writer)) == 4
+			}, 5*time.Second, 10*time.Millisecond)
+
+			// the merge is stopped, it only ends once it is aborted
+			done := make(chan error)
+			go func() {
+				if name == "Rollback" {
+					done <- writer.Rollback(ctx)
+				} else {
+					done <- writer.Close()
+				}
+			}()
+			select {
+			case err := <-done:
+				assert.Nil(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the running merge was not aborted")
+			}
+			assert.True(t, writer.IsClosed())
+
+			// the files of the aborted merge are removed, the index is unchanged
+			files, err := dir.ListAll(ctx)
+			assert.Nil(t, err)
+			for _, file := range files {
+				assert.False(t, strings.HasPrefix(file, "_4"), file)
+			}
+			assert.Len(t, liveIds(t, dir), 4)
+		})
+	}
+}
+
+// failingMergeDirectory Fails to create the files of merged segments
+type failingMergeDirectory struct {
+	store.Directory
+}
+
+func (d *failingMergeDirectory) CreateOutput(ctx context.Context, name string) (store.IndexOutput, error) {
+	if store.GetIOContext(ctx).Type == store.CONTEXT_MERGE {
+		return nil, errors.New("disk full")
+	}
+	return d.Directory.CreateOutput(ctx, name)
+}
+
+// numDocs Returns the number of live documents of every segment
+func numDocs(t *testing.T, dir store.Directory) []int {
+	counts := make([]int, 0)
+	for _, ids := range liveIds(t, dir) {
+		counts = append(counts, len(ids))
+	}
+	return counts
+}
+
+func TestIndexWriter_ForceMerge(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newMergeTestWriter(t, dir, coreIndex.NewSerialMergeScheduler())
+	addSegments(t, writer, 6, 10)
+	assert.Len(t, coreIndex.Segments(writer), 6)
+
+	assert.Nil(t, writer.ForceMerge(ctx, 2, true))
+	assert.Nil(t, writer.Commit(ctx))
+	counts := numDocs(t, dir)
+	assert.LessOrEqual(t, len(counts), 2)
+	assert.Equal(t, 60, sum(counts))
+
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+	assert.Nil(t, writer.Commit(ctx))
+	assert.Equal(t, []int{60}, numDocs(t, dir))
+
+	assert.NotNil(t, writer.ForceMerge(ctx, 0, true))
+}
+
+func TestIndexWriter_ForceMergeNoWait(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	cms := coreIndex.NewConcurrentMergeScheduler()
+	cms.SetForceMergeMBPerSec(0)
+	writer := newMergeTestWriter(t, dir, cms)
+	addSegments(t, writer, 4, 10)
+
+	// the merge is paused, the call returns anyway
+	assert.Nil(t, writer.ForceMerge(ctx, 1, false))
+	assert.Eventually(t, func() bool {
+		return len(coreIndex.RunningMergeSegments(writer)) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, coreIndex.Segments(writer), 4)
+
+	// the context aborts the forced merge, even though it is paused
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, writer.ForceMerge(timeoutCtx, 1, true), context.DeadlineExceeded)
+	assert.Eventually(t, func() bool {
+		return len(coreIndex.RunningMergeSegments(writer)) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, coreIndex.Segments(writer), 4)
+
+	cms.SetForceMergeMBPerSec(math.Inf(1))
+	assert.Nil(t, writer.ForceMerge(ctx, 1, true))
+	assert.Len(t, coreIndex.Segments(writer), 1)
+}
+
+func TestIndexWriter_ForceMergeError(t *testing.T) {
+	ctx := context.Background()
+	nioDir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer nioDir.Close()
+	dir := &failingMergeDirectory{Directory: nioDir}
+
+	writer := newMergeTestWriter(t, dir, coreIndex.NewConcurrentMergeScheduler())
+	addSegments(t, writer, 4, 10)
+
+	err = writer.ForceMerge(ctx, 1, true)
+	assert.ErrorContains(t, err, "background merge hit exception")
+	assert.ErrorContains(t, err, "disk full")
+	assert.Len(t, coreIndex.Segments(writer), 4)
+}
+
+func TestIndexWriter_ForceMergeDeletes(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writer := newMergeTestWriter(t, dir, coreIndex.NewSerialMergeScheduler())
+	addSegments(t, writer, 2, 10)
+
+	// 20% of the first segment is deleted, over the 10% TieredMergePolicy allows
+	infos := coreIndex.Segments(writer)
+	for _, docID := range []int{2, 5} {
+		deleted, err := coreIndex.DeleteDocument(ctx, writer, infos[0], docID)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+	}
+	assert.Nil(t, writer.Commit(ctx))
+	assert.Equal(t, 18, sum(numDocs(t, dir)))
+
+	assert.Nil(t, writer.ForceMergeDeletes(ctx, true))
+	assert.Nil(t, writer.Commit(ctx))
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, 0, reader.NumDeletedDocs())
+	assert.Equal(t, 18, reader.NumDocs())
+}
+
+func TestIndexWriter_ForceMergeClosed(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	cms := coreIndex.NewConcurrentMergeScheduler()
+	cms.SetForceMergeMBPerSec(0)
+	writer := newMergeTestWriter(t, dir, cms)
+	addSegments(t, writer, 4, 10)
+
+	// the writer is rolled back while waiting for the paused merge
+	done := make(chan error)
+	go func() {
+		done <- writer.ForceMerge(ctx, 1, true)
+	}()
+	assert.Eventually(t, func() bool {
+		return len(coreIndex.RunningMergeSegments(writer)) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, writer.Rollback(ctx))
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, coreIndex.ErrIndexWriterClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ForceMerge did not return after rollback")
+	}
+
+	assert.ErrorIs(t, writer.ForceMerge(ctx, 1, true), coreIndex.ErrIndexWriterClosed)
+	assert.ErrorIs(t, writer.ForceMergeDeletes(ctx, true), coreIndex.ErrIndexWriterClosed)
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -23,3 +23,7 @@ func NewLeafMetaData(createdVersionMajor int, minVersion *version.Version, sort
 func (l *leafMetaData) GetSort() index.Sort {
 	return l.sort
 }
+
+func (l *leafMetaData) GetMinVersion() *version.Version {
+	return l.minVersion
+}
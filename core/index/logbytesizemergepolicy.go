@@ -0,0 +1,86 @@
+package index
+
+import (
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+const (
+	// DEFAULT_MIN_MERGE_MB Default minimum segment size. See SetMinMergeMB.
+	DEFAULT_MIN_MERGE_MB = 1.6
+
+	// DEFAULT_MAX_MERGE_MB Default maximum segment size. A segment of this size or larger will never
+	// be merged. See SetMaxMergeMB.
+	DEFAULT_MAX_MERGE_MB = 2048
+
+	// DEFAULT_MAX_MERGE_MB_FOR_FORCED_MERGE Default maximum segment size. A segment of this size or
+	// larger will never be merged during ForceMerge. See SetMaxMergeMBForForcedMerge.
+	DEFAULT_MAX_MERGE_MB_FOR_FORCED_MERGE = math.MaxInt64
+)
+
+var _ MergePolicy = &LogByteSizeMergePolicy{}
+
+// LogByteSizeMergePolicy This is a LogMergePolicy that measures size of a segment as the total byte
+// size of the segment's files.
+type LogByteSizeMergePolicy struct {
+	*LogMergePolicy
+}
+
+// NewLogByteSizeMergePolicy Sole constructor, setting all settings to their defaults.
+func NewLogByteSizeMergePolicy() *LogByteSizeMergePolicy {
+	policy := &LogByteSizeMergePolicy{}
+	policy.LogMergePolicy = NewLogMergePolicy(policy)
+	policy.minMergeSize = mbToBytes(DEFAULT_MIN_MERGE_MB)
+	policy.maxMergeSize = mbToBytes(DEFAULT_MAX_MERGE_MB)
+	policy.maxMergeSizeForForcedMerge = mbToBytes(DEFAULT_MAX_MERGE_MB_FOR_FORCED_MERGE)
+	return policy
+}
+
+func (l *LogByteSizeMergePolicy) Size(info index.SegmentCommitInfo, mergeContext MergeContext) (int64, error) {
+	return l.sizeBytes(info, mergeContext)
+}
+
+// SetMaxMergeMB Determines the largest segment (measured by total byte size of the segment's files,
+// in MB) that may be merged with other segments. Small values (e.g., less than 50 MB) are best for
+// interactive indexing, as this limits the length of pauses while indexing to a few seconds. Larger
+// values are best for batched indexing and speedier searches.
+//
+// Note that SetMaxMergeDocs is also used to check whether a segment is too large for merging
+// (it's either or).
+func (l *LogByteSizeMergePolicy) SetMaxMergeMB(mb float64) {
+	l.maxMergeSize = mbToBytes(mb)
+}
+
+// GetMaxMergeMB Returns the largest segment (measured by total byte size of the segment's files,
+// in MB) that may be merged with other segments.
+func (l *LogByteSizeMergePolicy) GetMaxMergeMB() float64 {
+	return float64(l.maxMergeSize) / 1024 / 1024
+}
+
+// SetMaxMergeMBForForcedMerge Determines the largest segment (measured by total byte size of the
+// segment's files, in MB) that may be merged with other segments during forceMerge. Setting it low
+// will leave the index with more than 1 segment, even if ForceMerge is called.
+func (l *LogByteSizeMergePolicy) SetMaxMergeMBForForcedMerge(mb float64) {
+	l.maxMergeSizeForForcedMerge = mbToBytes(mb)
+}
+
+// GetMaxMergeMBForForcedMerge Returns the largest segment (measured by total byte size of the
+// segment's files, in MB) that may be merged with other segments during forceMerge.
+func (l *LogByteSizeMergePolicy) GetMaxMergeMBForForcedMerge() float64 {
+	return float64(l.maxMergeSizeForForcedMerge) / 1024 / 1024
+}
+
+// SetMinMergeMB Sets the minimum size for the lowest level segments. Any segments below this size
+// are considered to be on the same level (even if they vary drastically in size) and will be merged
+// whenever there are mergeFactor of them. This effectively truncates the "long tail" of small segments
+// that would otherwise be created into a single level. If you set this too large, it could greatly
+// increase the merging cost during indexing (if you flush many small segments).
+func (l *LogByteSizeMergePolicy) SetMinMergeMB(mb float64) {
+	l.minMergeSize = mbToBytes(mb)
+}
+
+// GetMinMergeMB Get the minimum size for a segment to remain un-merged.
+func (l *LogByteSizeMergePolicy) GetMinMergeMB() float64 {
+	return float64(l.minMergeSize) / 1024 / 1024
+}
@@ -0,0 +1,41 @@
+package index_test
+
+import (
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogByteSizeMergePolicy_FindMerges(t *testing.T) {
+	policy := coreIndex.NewLogByteSizeMergePolicy()
+
+	// segments below the min merge size are on the same level, mergeFactor of them are merged
+	segments := newSyntheticSegments(t)
+	segments.addN(9, 1*mb, 100)
+	spec, err := policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	segments.addN(1, 1*mb, 100)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.Equal(t, segments.infos.AsList(), merges[0])
+
+	// a segment over the max merge size is never merged
+	policy.SetMaxMergeMB(4)
+	segments = newSyntheticSegments(t)
+	segments.addN(10, 1*mb, 100)
+	large := segments.add(5*mb, 500, 0)
+	segments.addN(10, 1*mb, 100)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 2)
+	for _, merge := range merges {
+		assert.Len(t, merge, 10)
+		assert.NotContains(t, merge, large)
+	}
+}
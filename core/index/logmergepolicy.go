@@ -0,0 +1,620 @@
+package index
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+const (
+	// LEVEL_LOG_SPAN Defines the allowed range of log(size) for each level. A level is computed by
+	// taking the max segment log size, minus LEVEL_LOG_SPAN, and finding all segments falling
+	// within that range.
+	LEVEL_LOG_SPAN = 0.75
+
+	// DEFAULT_MERGE_FACTOR Default merge factor, which is how many segments are merged at a time
+	DEFAULT_MERGE_FACTOR = 10
+
+	// DEFAULT_MAX_MERGE_DOCS Default maximum segment size. A segment of this size or larger will
+	// never be merged. See SetMaxMergeDocs
+	DEFAULT_MAX_MERGE_DOCS = math.MaxInt32
+
+	// LOG_DEFAULT_NO_CFS_RATIO Default noCFSRatio. If a merge's size is >= 10% of the index,
+	// then we disable compound file for it.
+	LOG_DEFAULT_NO_CFS_RATIO = 0.1
+)
+
+// LogMergePolicy This class implements a MergePolicy that tries to merge segments into levels of
+// exponentially increasing size, where each level has fewer segments than the value of the merge
+// factor. Whenever extra segments (beyond the merge factor upper bound) are encountered, all
+// segments within the level are merged. You can get or set the merge factor using GetMergeFactor()
+// and SetMergeFactor(int) respectively.
+//
+// This class is abstract and requires a subclass to define the Size method which specifies how a
+// segment's size is determined. LogDocMergePolicy is one subclass that measures size by document
+// count in the segment. LogByteSizeMergePolicy is another subclass that measures size as the total
+// byte size of the file(s) for the segment.
+type LogMergePolicy struct {
+	*MergePolicyBase
+
+	// How many segments to merge at a time.
+	mergeFactor int
+
+	// Any segments whose size is smaller than this value will be rounded up to this value. This
+	// ensures that tiny segments are aggressively merged.
+	minMergeSize int64
+
+	// If the size of a segment exceeds this value then it will never be merged.
+	maxMergeSize int64
+
+	// If the size of a segment exceeds this value then it will never be merged during
+	// IndexWriter.ForceMerge.
+	maxMergeSizeForForcedMerge int64
+
+	// If a segment has more than this many documents then it will never be merged.
+	maxMergeDocs int
+
+	// If true, we pro-rate a segment's size by the percentage of non-deleted documents.
+	calibrateSizeByDeletes bool
+}
+
+// NewLogMergePolicy Sole constructor, spi provides the Size of the segments.
+func NewLogMergePolicy(spi MergePolicySPI) *LogMergePolicy {
+	policy := &LogMergePolicy{
+		MergePolicyBase:            NewMergePolicy(spi),
+		mergeFactor:                DEFAULT_MERGE_FACTOR,
+		maxMergeSizeForForcedMerge: math.MaxInt64,
+		maxMergeDocs:               DEFAULT_MAX_MERGE_DOCS,
+		calibrateSizeByDeletes:     true,
+	}
+	policy.noCFSRatio = LOG_DEFAULT_NO_CFS_RATIO
+	return policy
+}
+
+// GetMergeFactor Returns the number of segments that are merged at once and also controls the total
+// number of segments allowed to accumulate in the index.
+func (l *LogMergePolicy) GetMergeFactor() int {
+	return l.mergeFactor
+}
+
+// SetMergeFactor Determines how often segment indices are merged by addDocument(). With smaller values,
+// less RAM is used while indexing, and searches are faster, but indexing speed is slower. With larger
+// values, more RAM is used during indexing, and while searches is slower, indexing is faster. Thus
+// larger values (> 10) are best for batch index creation, and smaller values (< 10) for indices that
+// are interactively maintained.
+func (l *LogMergePolicy) SetMergeFactor(mergeFactor int) error {
+	if mergeFactor < 2 {
+		return fmt.Errorf("mergeFactor cannot be less than 2")
+	}
+	l.mergeFactor = mergeFactor
+	return nil
+}
+
+// SetCalibrateSizeByDeletes Sets whether the segment size should be calibrated by the number of
+// deletes when choosing segments for merge.
+func (l *LogMergePolicy) SetCalibrateSizeByDeletes(calibrateSizeByDeletes bool) {
+	l.calibrateSizeByDeletes = calibrateSizeByDeletes
+}
+
+// GetCalibrateSizeByDeletes Returns true if the segment size should be calibrated by the number
+// of deletes when choosing segments for merge.
+func (l *LogMergePolicy) GetCalibrateSizeByDeletes() bool {
+	return l.calibrateSizeByDeletes
+}
+
+// SetMaxMergeDocs Determines the largest segment (measured by document count) that may be merged
+// with other segments. Small values (e.g., less than 10,000) are best for interactive indexing, as
+// this limits the length of pauses while indexing to a few seconds. Larger values are best for
+// batched indexing and speedier searches.
+//
+// The default value is math.MaxInt32.
+//
+// The default merge policy (LogByteSizeMergePolicy) also allows you to set this limit by net size
+// (in MB) of the segment, using LogByteSizeMergePolicy.SetMaxMergeMB.
+func (l *LogMergePolicy) SetMaxMergeDocs(maxMergeDocs int) {
+	l.maxMergeDocs = maxMergeDocs
+}
+
+// GetMaxMergeDocs Returns the largest segment (measured by document count) that may be merged
+// with other segments.
+func (l *LogMergePolicy) GetMaxMergeDocs() int {
+	return l.maxMergeDocs
+}
+
+// sizeDocs Return the number of documents in the provided SegmentCommitInfo, pro-rated by
+// percentage of non-deleted documents if SetCalibrateSizeByDeletes is set.
+func (l *LogMergePolicy) sizeDocs(info index.SegmentCommitInfo, mergeContext MergeContext) (int64, error) {
+	maxDoc, err := info.Info().MaxDoc()
+	if err != nil {
+		return 0, err
+	}
+	if !l.calibrateSizeByDeletes {
+		return int64(maxDoc), nil
+	}
+	delCount, err := mergeContext.NumDeletesToMerge(info)
+	if err != nil {
+		return 0, err
+	}
+	return int64(maxDoc - delCount), nil
+}
+
+// sizeBytes Return the byte size of the provided SegmentCommitInfo, pro-rated by percentage of
+// non-deleted documents if SetCalibrateSizeByDeletes is set.
+func (l *LogMergePolicy) sizeBytes(info index.SegmentCommitInfo, mergeContext MergeContext) (int64, error) {
+	if l.calibrateSizeByDeletes {
+		return l.size(info, mergeContext)
+	}
+	return info.SizeInBytes()
+}
+
+// tooLarge Returns true if the segment exceeds the given size or maxMergeDocs
+func (l *LogMergePolicy) tooLarge(info index.SegmentCommitInfo, maxSize int64, mergeContext MergeContext) (bool, error) {
+	size, err := l.Size(info, mergeContext)
+	if err != nil {
+		return false, err
+	}
+	docs, err := l.sizeDocs(info, mergeContext)
+	if err != nil {
+		return false, err
+	}
+	return size >= maxSize || docs >= int64(l.maxMergeDocs), nil
+}
+
+// isMergedTo Returns true if the number of segments eligible for merging is less than or equal
+// to the specified maxNumSegments.
+func (l *LogMergePolicy) isMergedTo(infos *SegmentInfos, maxNumSegments int,
+	segmentsToMerge map[index.SegmentCommitInfo]bool, mergeContext MergeContext) (bool, error) {
+
+	numToMerge := 0
+	var mergeInfo index.SegmentCommitInfo
+	segmentIsOriginal := false
+	for i := 0; i < infos.Size() && numToMerge <= maxNumSegments; i++ {
+		info := infos.Info(i)
+		if isOriginal, ok := segmentsToMerge[info]; ok {
+			segmentIsOriginal = isOriginal
+			numToMerge++
+			mergeInfo = info
+		}
+	}
+
+	if numToMerge > maxNumSegments {
+		return false, nil
+	}
+	if numToMerge != 1 || !segmentIsOriginal {
+		return true, nil
+	}
+	return l.IsMerged(infos, mergeInfo, mergeContext)
+}
+
+func (l *LogMergePolicy) newOneMerge(infos *SegmentInfos, from, to int) (*OneMerge, error) {
+	return NewOneMerge(infos.AsList()[from:to])
+}
+
+// findForcedMergesSizeLimit Returns the merges necessary to merge the index, taking the max merge
+// size or max merge docs into consideration. This method attempts to respect the maxNumSegments
+// parameter, however it might be, due to size constraints, that more than that number of segments
+// will remain in the index. Also, this method does not guarantee that exactly maxNumSegments will
+// remain, but <= that number.
+func (l *LogMergePolicy) findForcedMergesSizeLimit(infos *SegmentInfos, last int, mergeContext MergeContext) (*MergeSpecification, error) {
+	spec := NewMergeSpecification()
+
+	start := last - 1
+	for start >= 0 {
+		info := infos.Info(start)
+		size, err := l.Size(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := l.sizeDocs(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > l.maxMergeSizeForForcedMerge || docs > int64(l.maxMergeDocs) {
+			// need to skip that segment + add a merge for the 'right' segments,
+			// unless there is only 1 which is merged.
+			addMerge := last-start-1 > 1
+			if !addMerge && start != last-1 {
+				merged, err := l.IsMerged(infos, infos.Info(start+1), mergeContext)
+				if err != nil {
+					return nil, err
+				}
+				addMerge = !merged
+			}
+			if addMerge {
+				// there is more than 1 segment to the right of
+				// this one, or a mergeable single segment.
+				merge, err := l.newOneMerge(infos, start+1, last)
+				if err != nil {
+					return nil, err
+				}
+				spec.Add(merge)
+			}
+			last = start
+		} else if last-start == l.mergeFactor {
+			// mergeFactor eligible segments were found, add them as a merge.
+			merge, err := l.newOneMerge(infos, start, last)
+			if err != nil {
+				return nil, err
+			}
+			spec.Add(merge)
+			last = start
+		}
+		start--
+	}
+
+	// Add any left-over segments, unless there is just 1
+	// already fully merged
+	if last > 0 {
+		start++
+		addMerge := start+1 < last
+		if !addMerge {
+			merged, err := l.IsMerged(infos, infos.Info(start), mergeContext)
+			if err != nil {
+				return nil, err
+			}
+			addMerge = !merged
+		}
+		if addMerge {
+			merge, err := l.newOneMerge(infos, start, last)
+			if err != nil {
+				return nil, err
+			}
+			spec.Add(merge)
+		}
+	}
+
+	if len(spec.merges) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+// findForcedMergesMaxNumSegments Returns the merges necessary to forceMerge the index. This method
+// constraints the returned merges only by the maxNumSegments parameter, and guaranteed that exactly
+// that number of segments will remain in the index.
+func (l *LogMergePolicy) findForcedMergesMaxNumSegments(infos *SegmentInfos, maxNumSegments, last int,
+	mergeContext MergeContext) (*MergeSpecification, error) {
+
+	spec := NewMergeSpecification()
+
+	// First, enroll all "full" merges (size
+	// mergeFactor) to potentially be run concurrently:
+	for last-maxNumSegments+1 >= l.mergeFactor {
+		merge, err := l.newOneMerge(infos, last-l.mergeFactor, last)
+		if err != nil {
+			return nil, err
+		}
+		spec.Add(merge)
+		last -= l.mergeFactor
+	}
+
+	// Only if there are no full merges pending do we
+	// add a final partial (< mergeFactor segments) merge:
+	if len(spec.merges) == 0 {
+		if maxNumSegments == 1 {
+			// Since we must merge down to 1 segment, the
+			// choice is simple:
+			addMerge := last > 1
+			if !addMerge {
+				merged, err := l.IsMerged(infos, infos.Info(0), mergeContext)
+				if err != nil {
+					return nil, err
+				}
+				addMerge = !merged
+			}
+			if addMerge {
+				merge, err := l.newOneMerge(infos, 0, last)
+				if err != nil {
+					return nil, err
+				}
+				spec.Add(merge)
+			}
+		} else if last > maxNumSegments {
+			// Take care to pick a partial merge that is
+			// least cost, but does not make the index too
+			// lopsided.  If we always just picked the
+			// partial tail then we could produce a highly
+			// lopsided index over time:
+
+			// We must merge this many segments to leave
+			// maxNumSegments in the index (from when
+			// forceMerge was first kicked off):
+			finalMergeSize := last - maxNumSegments + 1
+
+			// Consider all possible starting points:
+			bestSize := int64(0)
+			bestStart := 0
+
+			for i := 0; i < last-finalMergeSize+1; i++ {
+				sumSize := int64(0)
+				for j := 0; j < finalMergeSize; j++ {
+					size, err := l.Size(infos.Info(j+i), mergeContext)
+					if err != nil {
+						return nil, err
+					}
+					sumSize += size
+				}
+				if i == 0 {
+					bestStart = i
+					bestSize = sumSize
+					continue
+				}
+				prevSize, err := l.Size(infos.Info(i-1), mergeContext)
+				if err != nil {
+					return nil, err
+				}
+				if sumSize < 2*prevSize && sumSize < bestSize {
+					bestStart = i
+					bestSize = sumSize
+				}
+			}
+
+			merge, err := l.newOneMerge(infos, bestStart, bestStart+finalMergeSize)
+			if err != nil {
+				return nil, err
+			}
+			spec.Add(merge)
+		}
+	}
+
+	if len(spec.merges) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+// FindForcedMerges Returns the merges necessary to merge the index down to a specified number of
+// segments. This respects the maxMergeSizeForForcedMerge setting. By default, and assuming
+// maxNumSegments=1, only one segment will be left in the index, where that segment has no
+// deletions pending nor separate norms, and it is in compound file format if the current
+// useCompoundFile setting is true. This method returns multiple merges (mergeFactor at a time) so
+// the MergeScheduler in use may make use of concurrency.
+func (l *LogMergePolicy) FindForcedMerges(infos *SegmentInfos, maxNumSegments int,
+	segmentsToMerge map[index.SegmentCommitInfo]bool, mergeContext MergeContext) (*MergeSpecification, error) {
+
+	if maxNumSegments <= 0 {
+		return nil, fmt.Errorf("maxNumSegments must be > 0 (got %d)", maxNumSegments)
+	}
+
+	// If the segments are already merged (e.g. there's only 1 segment), or
+	// there are <maxNumSegments:.
+	merged, err := l.isMergedTo(infos, maxNumSegments, segmentsToMerge, mergeContext)
+	if err != nil {
+		return nil, err
+	}
+	if merged {
+		return nil, nil
+	}
+
+	// Find the newest (rightmost) segment that needs to
+	// be merged (other segments may have been flushed
+	// since merging started):
+	last := infos.Size()
+	for last > 0 {
+		last--
+		if _, ok := segmentsToMerge[infos.Info(last)]; ok {
+			last++
+			break
+		}
+	}
+
+	if last == 0 {
+		return nil, nil
+	}
+
+	// There is only one segment already, and it is merged
+	if maxNumSegments == 1 && last == 1 {
+		merged, err := l.IsMerged(infos, infos.Info(0), mergeContext)
+		if err != nil {
+			return nil, err
+		}
+		if merged {
+			return nil, nil
+		}
+	}
+
+	// Check if there are any segments above the threshold
+	anyTooLarge := false
+	for i := 0; i < last; i++ {
+		info := infos.Info(i)
+		size, err := l.Size(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := l.sizeDocs(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+		if size > l.maxMergeSizeForForcedMerge || docs > int64(l.maxMergeDocs) {
+			anyTooLarge = true
+			break
+		}
+	}
+
+	if anyTooLarge {
+		return l.findForcedMergesSizeLimit(infos, last, mergeContext)
+	}
+	return l.findForcedMergesMaxNumSegments(infos, maxNumSegments, last, mergeContext)
+}
+
+// FindForcedDeletesMerges Finds merges necessary to force-merge all deletes from the index.
+// We simply merge adjacent segments that have deletes, up to mergeFactor at a time.
+func (l *LogMergePolicy) FindForcedDeletesMerges(infos *SegmentInfos, mergeContext MergeContext) (*MergeSpecification, error) {
+	numSegments := infos.Size()
+
+	spec := NewMergeSpecification()
+	firstSegmentWithDeletions := -1
+	for i := 0; i < numSegments; i++ {
+		info := infos.Info(i)
+		delCount, err := mergeContext.NumDeletesToMerge(info)
+		if err != nil {
+			return nil, err
+		}
+
+		if delCount > 0 {
+			if firstSegmentWithDeletions == -1 {
+				firstSegmentWithDeletions = i
+			} else if i-firstSegmentWithDeletions == l.mergeFactor {
+				// We've seen mergeFactor segments in a row with
+				// deletions, so force a merge now:
+				merge, err := l.newOneMerge(infos, firstSegmentWithDeletions, i)
+				if err != nil {
+					return nil, err
+				}
+				spec.Add(merge)
+				firstSegmentWithDeletions = i
+			}
+		} else if firstSegmentWithDeletions != -1 {
+			// End of a sequence of segments with deletions, so,
+			// merge those past segments even if it's fewer than
+			// mergeFactor segments
+			merge, err := l.newOneMerge(infos, firstSegmentWithDeletions, i)
+			if err != nil {
+				return nil, err
+			}
+			spec.Add(merge)
+			firstSegmentWithDeletions = -1
+		}
+	}
+
+	if firstSegmentWithDeletions != -1 {
+		merge, err := l.newOneMerge(infos, firstSegmentWithDeletions, numSegments)
+		if err != nil {
+			return nil, err
+		}
+		spec.Add(merge)
+	}
+
+	if len(spec.merges) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+type segmentInfoAndLevel struct {
+	info  index.SegmentCommitInfo
+	level float64
+}
+
+// FindMerges Checks if any merges are now necessary and returns a MergeSpecification if so. A merge
+// is necessary when there are more than SetMergeFactor segments at a given level. When multiple
+// levels have too many segments, this method will return multiple merges, allowing the
+// MergeScheduler to use concurrency.
+func (l *LogMergePolicy) FindMerges(mergeTrigger MergeTrigger, infos *SegmentInfos, mergeContext MergeContext) (*MergeSpecification, error) {
+	numSegments := infos.Size()
+
+	// Compute levels, which is just log (base mergeFactor)
+	// of the size of each segment
+	levels := make([]segmentInfoAndLevel, 0, numSegments)
+	norm := math.Log(float64(l.mergeFactor))
+
+	mergingSegments := mergingSegmentSet(mergeContext)
+
+	for i := 0; i < numSegments; i++ {
+		info := infos.Info(i)
+		size, err := l.Size(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+
+		// Floor tiny segments
+		size = max(size, 1)
+
+		levels = append(levels, segmentInfoAndLevel{
+			info:  info,
+			level: math.Log(float64(size)) / norm,
+		})
+	}
+
+	levelFloor := 0.0
+	if l.minMergeSize > 0 {
+		levelFloor = math.Log(float64(l.minMergeSize)) / norm
+	}
+
+	// Now, we quantize the log values into levels.  The
+	// first level is any segment whose log size is within
+	// LEVEL_LOG_SPAN of the max size, or, who has such as
+	// segment "to the right".  Then, we find the max of all
+	// other segments and use that to define the next level
+	// segment, etc.
+
+	var spec *MergeSpecification
+
+	numMergeableSegments := len(levels)
+
+	start := 0
+	for start < numMergeableSegments {
+		// Find max level of all segments not already
+		// quantized.
+		maxLevel := levels[start].level
+		for i := 1 + start; i < numMergeableSegments; i++ {
+			maxLevel = max(maxLevel, levels[i].level)
+		}
+
+		// Now search backwards for the rightmost segment that
+		// falls into this level:
+		var levelBottom float64
+		if maxLevel <= levelFloor {
+			// All remaining segments fall into the min level
+			levelBottom = -1.0
+		} else {
+			levelBottom = maxLevel - LEVEL_LOG_SPAN
+
+			// Force a boundary at the level floor
+			if levelBottom < levelFloor && maxLevel >= levelFloor {
+				levelBottom = levelFloor
+			}
+		}
+
+		upto := numMergeableSegments - 1
+		for upto >= start {
+			if levels[upto].level >= levelBottom {
+				break
+			}
+			upto--
+		}
+
+		// Finally, record all merges that are viable at this level:
+		end := start + l.mergeFactor
+		for end <= 1+upto {
+			anyTooLarge := false
+			anyMerging := false
+			for i := start; i < end; i++ {
+				info := levels[i].info
+				tooLarge, err := l.tooLarge(info, l.maxMergeSize, mergeContext)
+				if err != nil {
+					return nil, err
+				}
+				anyTooLarge = anyTooLarge || tooLarge
+				if _, ok := mergingSegments[info]; ok {
+					anyMerging = true
+					break
+				}
+			}
+
+			if !anyMerging && !anyTooLarge {
+				if spec == nil {
+					spec = NewMergeSpecification()
+				}
+				mergeInfos := make([]index.SegmentCommitInfo, 0, end-start)
+				for i := start; i < end; i++ {
+					mergeInfos = append(mergeInfos, levels[i].info)
+				}
+				merge, err := NewOneMerge(mergeInfos)
+				if err != nil {
+					return nil, err
+				}
+				spec.Add(merge)
+			}
+
+			start = end
+			end = start + l.mergeFactor
+		}
+
+		start = 1 + upto
+	}
+
+	return spec, nil
+}
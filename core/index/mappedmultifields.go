@@ -0,0 +1,354 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/attribute"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+var _ index.Fields = &MappedMultiFields{}
+
+// MappedMultiFields
+// A Fields implementation that merges multiple Fields into one, and maps around deleted documents.
+// This is used for merging.
+// lucene.internal
+type MappedMultiFields struct {
+	mergeState *MergeState
+	names      []string
+}
+
+// NewMappedMultiFields Create a new MappedMultiFields over the FieldsProducers of mergeState.
+func NewMappedMultiFields(mergeState *MergeState) *MappedMultiFields {
+	names := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, producer := range mergeState.FieldsProducers {
+		if producer == nil {
+			continue
+		}
+		for _, name := range producer.Names() {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	return &MappedMultiFields{
+		mergeState: mergeState,
+		names:      names,
+	}
+}
+
+func (m *MappedMultiFields) Names() []string {
+	return m.names
+}
+
+func (m *MappedMultiFields) Terms(field string) (index.Terms, error) {
+	fieldInfo := m.mergeState.MergeFieldInfos.FieldInfo(field)
+	if fieldInfo == nil {
+		return nil, nil
+	}
+
+	subs := make([]index.Terms, 0, len(m.mergeState.FieldsProducers))
+	subIndexes := make([]int, 0, len(m.mergeState.FieldsProducers))
+	for i, producer := range m.mergeState.FieldsProducers {
+		if producer == nil {
+			continue
+		}
+		terms, err := producer.Terms(field)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return nil, err
+		}
+		if terms != nil {
+			subs = append(subs, terms)
+			subIndexes = append(subIndexes, i)
+		}
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	return &MappedMultiTerms{
+		mergeState: m.mergeState,
+		fieldInfo:  fieldInfo,
+		subs:       subs,
+		subIndexes: subIndexes,
+	}, nil
+}
+
+func (m *MappedMultiFields) Size() int {
+	return len(m.names)
+}
+
+var _ index.Terms = &MappedMultiTerms{}
+
+// MappedMultiTerms
+// The Terms of one field across all segments being merged. Statistics are not available,
+// as they can't be computed without visiting all postings.
+type MappedMultiTerms struct {
+	mergeState *MergeState
+	fieldInfo  *document.FieldInfo
+	subs       []index.Terms
+	subIndexes []int
+}
+
+func (m *MappedMultiTerms) Iterator() (index.TermsEnum, error) {
+	subs := make([]*multiTermsEnumSub, 0, len(m.subs))
+	for i, terms := range m.subs {
+		termsEnum, err := terms.Iterator()
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, &multiTermsEnumSub{
+			termsEnum: termsEnum,
+			docMap:    m.mergeState.DocMaps[m.subIndexes[i]],
+		})
+	}
+	return newMappedMultiTermsEnum(m.mergeState, subs), nil
+}
+
+func (m *MappedMultiTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (index.TermsEnum, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (m *MappedMultiTerms) Size() (int, error) {
+	return -1, nil
+}
+
+func (m *MappedMultiTerms) GetSumTotalTermFreq() (int64, error) {
+	return -1, nil
+}
+
+func (m *MappedMultiTerms) GetSumDocFreq() (int64, error) {
+	return -1, nil
+}
+
+func (m *MappedMultiTerms) GetDocCount() (int, error) {
+	return -1, nil
+}
+
+func (m *MappedMultiTerms) HasFreqs() bool {
+	return m.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS
+}
+
+func (m *MappedMultiTerms) HasOffsets() bool {
+	return m.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+}
+
+func (m *MappedMultiTerms) HasPositions() bool {
+	return m.fieldInfo.GetIndexOptions() >= document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS
+}
+
+func (m *MappedMultiTerms) HasPayloads() bool {
+	return m.fieldInfo.HasPayloads()
+}
+
+func (m *MappedMultiTerms) GetMin() ([]byte, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (m *MappedMultiTerms) GetMax() ([]byte, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+type multiTermsEnumSub struct {
+	termsEnum index.TermsEnum
+	docMap    MergeStateDocMap
+	current   []byte
+}
+
+var _ index.TermsEnum = &mappedMultiTermsEnum{}
+
+// mappedMultiTermsEnum
+// Merges the terms of all subs in sorted order; the postings of a term are the postings of every
+// sub positioned on it, with deleted documents removed and doc IDs remapped to the merged segment.
+type mappedMultiTermsEnum struct {
+	mergeState *MergeState
+	active     []*multiTermsEnumSub // subs which are not exhausted yet
+	matching   []*multiTermsEnumSub // subs positioned on the current term
+	term       []byte
+	attrs      *attribute.Source
+}
+
+func newMappedMultiTermsEnum(mergeState *MergeState, subs []*multiTermsEnumSub) *mappedMultiTermsEnum {
+	return &mappedMultiTermsEnum{
+		mergeState: mergeState,
+		active:     subs,
+		// all subs are unpositioned, the first call of Next advances them
+		matching: slices.Clone(subs),
+	}
+}
+
+func (m *mappedMultiTermsEnum) Next(ctx context.Context) ([]byte, error) {
+	for _, sub := range m.matching {
+		term, err := sub.termsEnum.Next(ctx)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		sub.current = term
+	}
+
+	active := m.active[:0]
+	for _, sub := range m.active {
+		if sub.current != nil {
+			active = append(active, sub)
+		}
+	}
+	m.active = active
+
+	m.matching = m.matching[:0]
+	if len(m.active) == 0 {
+		m.term = nil
+		return nil, io.EOF
+	}
+
+	for _, sub := range m.active {
+		if len(m.matching) == 0 {
+			m.matching = append(m.matching, sub)
+			continue
+		}
+		cmp := bytes.Compare(sub.current, m.matching[0].current)
+		if cmp < 0 {
+			m.matching = append(m.matching[:0], sub)
+		} else if cmp == 0 {
+			m.matching = append(m.matching, sub)
+		}
+	}
+	m.term = m.matching[0].current
+	return m.term, nil
+}
+
+func (m *mappedMultiTermsEnum) Attributes() *attribute.Source {
+	if m.attrs == nil {
+		m.attrs = attribute.NewSource()
+	}
+	return m.attrs
+}
+
+func (m *mappedMultiTermsEnum) SeekExact(ctx context.Context, text []byte) (bool, error) {
+	return false, ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) SeekCeil(ctx context.Context, text []byte) (index.SeekStatus, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) SeekExactByOrd(ctx context.Context, ord int64) error {
+	return ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) SeekExactExpert(ctx context.Context, term []byte, state index.TermState) error {
+	return ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) Term() ([]byte, error) {
+	return m.term, nil
+}
+
+func (m *mappedMultiTermsEnum) Ord() (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) DocFreq() (int, error) {
+	sum := 0
+	for _, sub := range m.matching {
+		docFreq, err := sub.termsEnum.DocFreq()
+		if err != nil {
+			return 0, err
+		}
+		sum += docFreq
+	}
+	return sum, nil
+}
+
+func (m *mappedMultiTermsEnum) TotalTermFreq() (int64, error) {
+	sum := int64(0)
+	for _, sub := range m.matching {
+		totalTermFreq, err := sub.termsEnum.TotalTermFreq()
+		if err != nil {
+			return 0, err
+		}
+		sum += totalTermFreq
+	}
+	return sum, nil
+}
+
+func (m *mappedMultiTermsEnum) Postings(reuse index.PostingsEnum, flags int) (index.PostingsEnum, error) {
+	subs := make([]*mappingPostingsSub, 0, len(m.matching))
+	cost := int64(0)
+	for _, sub := range m.matching {
+		postings, err := sub.termsEnum.Postings(nil, flags)
+		if err != nil {
+			return nil, err
+		}
+		cost += postings.Cost()
+		subs = append(subs, &mappingPostingsSub{
+			BaseDocIDMergerSub: NewBaseDocIDMergerSub(sub.docMap),
+			postings:           postings,
+		})
+	}
+
+	iterator, err := newMergedDocIDIterator(context.Background(), subs, cost, m.mergeState)
+	if err != nil {
+		return nil, err
+	}
+	return &MappingMultiPostingsEnum{iterator}, nil
+}
+
+func (m *mappedMultiTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (m *mappedMultiTermsEnum) TermState() (index.TermState, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+type mappingPostingsSub struct {
+	*BaseDocIDMergerSub
+
+	postings index.PostingsEnum
+}
+
+func (m *mappingPostingsSub) NextDoc(ctx context.Context) (int, error) {
+	return m.postings.NextDoc(ctx)
+}
+
+var _ index.PostingsEnum = &MappingMultiPostingsEnum{}
+
+// MappingMultiPostingsEnum
+// Exposes flex API, merged from flex API of sub-segments, remapping docIDs (this is used for segment merging).
+// lucene.experimental
+type MappingMultiPostingsEnum struct {
+	*mergedDocIDIterator[*mappingPostingsSub]
+}
+
+func (m *MappingMultiPostingsEnum) Freq() (int, error) {
+	return m.current.postings.Freq()
+}
+
+func (m *MappingMultiPostingsEnum) NextPosition() (int, error) {
+	return m.current.postings.NextPosition()
+}
+
+func (m *MappingMultiPostingsEnum) StartOffset() (int, error) {
+	return m.current.postings.StartOffset()
+}
+
+func (m *MappingMultiPostingsEnum) EndOffset() (int, error) {
+	return m.current.postings.EndOffset()
+}
+
+func (m *MappingMultiPostingsEnum) GetPayload() ([]byte, error) {
+	return m.current.postings.GetPayload()
+}
@@ -1,10 +1,17 @@
 package index
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
 	"github.com/geange/lucene-go/core/util"
 )
 
@@ -13,6 +20,10 @@ const (
 	DEFAULT_MAX_CFS_SEGMENT_SIZE = math.MaxInt64
 )
 
+// ErrMergeAborted is returned by a merge which was aborted before it finished, typically because
+// IndexWriter was rolled back or closed without committing.
+var ErrMergeAborted = errors.New("merge is aborted")
+
 // MergePolicy
 // Expert: a MergePolicy determines the sequence of primitive merge operations.
 // Whenever the segments in an index have been altered by IndexWriter, either the addition of a newly
@@ -205,6 +216,74 @@ func (m *MergePolicyBase) getNoCFSRatio() float64 {
 	return m.noCFSRatio
 }
 
+// GetNoCFSRatio Returns current noCFSRatio.
+func (m *MergePolicyBase) GetNoCFSRatio() float64 {
+	return m.noCFSRatio
+}
+
+// SetNoCFSRatio If a merged segment will be more than this percentage of the total size of the index,
+// leave the segment as non-compound file even if compound file is enabled. Set to 1.0 to always use CFS
+// regardless of merge size.
+func (m *MergePolicyBase) SetNoCFSRatio(noCFSRatio float64) error {
+	if noCFSRatio < 0.0 || noCFSRatio > 1.0 {
+		return fmt.Errorf("noCFSRatio must be 0.0 to 1.0 inclusive; got %v", noCFSRatio)
+	}
+	m.noCFSRatio = noCFSRatio
+	return nil
+}
+
+// GetMaxCFSSegmentSizeMB Returns the largest size allowed for a compound file segment
+func (m *MergePolicyBase) GetMaxCFSSegmentSizeMB() float64 {
+	return float64(m.maxCFSSegmentSize) / 1024 / 1024
+}
+
+// SetMaxCFSSegmentSizeMB If a merged segment will be more than this value, leave the segment as
+// non-compound file even if compound file is enabled. Set this to math.Inf(1) (default) and
+// noCFSRatio to 1.0 to always use CFS regardless of merge size.
+func (m *MergePolicyBase) SetMaxCFSSegmentSizeMB(v float64) error {
+	if v < 0.0 {
+		return fmt.Errorf("maxCFSSegmentSizeMB must be >=0 (got %v)", v)
+	}
+	m.maxCFSSegmentSize = mbToBytes(v)
+	return nil
+}
+
+// IsMerged Returns true if this single info is already fully merged (has no pending deletes, is in the
+// same dir as the writer, and matches the current compound file setting
+func (m *MergePolicyBase) IsMerged(infos *SegmentInfos, info index.SegmentCommitInfo, mergeContext MergeContext) (bool, error) {
+	delCount, err := mergeContext.NumDeletesToMerge(info)
+	if err != nil {
+		return false, err
+	}
+	if delCount != 0 {
+		return false, nil
+	}
+	useCompoundFile, err := m.UseCompoundFile(infos, info, mergeContext)
+	if err != nil {
+		return false, err
+	}
+	return useCompoundFile == info.Info().GetUseCompoundFile(), nil
+}
+
+// mbToBytes converts a size in MB to bytes, values beyond math.MaxInt64 are clamped.
+func mbToBytes(mb float64) int64 {
+	v := mb * 1024 * 1024
+	if v >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
+
+// mergingSegmentSet returns the segments that are currently merging as a set.
+func mergingSegmentSet(mergeContext MergeContext) map[index.SegmentCommitInfo]struct{} {
+	segments := mergeContext.GetMergingSegments()
+	merging := make(map[index.SegmentCommitInfo]struct{}, len(segments))
+	for _, info := range segments {
+		merging[info] = struct{}{}
+	}
+	return merging
+}
+
 func (m *MergePolicyBase) KeepFullyDeletedSegment(func() index.CodecReader) bool {
 	return false
 }
@@ -240,7 +319,7 @@ type MergeContext interface {
 type OneMerge struct {
 	info           index.SegmentCommitInfo // used by IndexWriter
 	registerDone   bool                    // used by IndexWriter
-	mergeGen       bool                    // used by IndexWriter
+	mergeGen       int64                   // used by IndexWriter
 	isExternal     bool                    // used by IndexWriter
 	maxNumSegments int                     // used by IndexWriter
 
@@ -254,12 +333,116 @@ type OneMerge struct {
 	segments     []index.SegmentCommitInfo
 
 	// Control used to pause/ stop/ resume the merge thread.
-	mergeProgress OneMergeProgress
+	mergeProgress *OneMergeProgress
 
-	mergeStartNS int64
+	mergeStartNS atomic.Int64 // 0 until the merge started, read by the MergeScheduler
 
 	// Total number of documents in segments to be merged, not accounting for deletions.
 	totalMaxDoc int64
+
+	// set by IndexWriter if the merge failed
+	err error
+}
+
+// NewOneMerge Sole constructor.
+// segments: List of SegmentCommitInfos to be merged.
+func NewOneMerge(segments []index.SegmentCommitInfo) (*OneMerge, error) {
+	if len(segments) == 0 {
+		return nil, errors.New("segments must include at least one segment")
+	}
+
+	// clone the list, as the in list may be based off original SegmentInfos and may be modified
+	segments = append(make([]index.SegmentCommitInfo, 0, len(segments)), segments...)
+	count := int64(0)
+	for _, info := range segments {
+		maxDoc, err := info.Info().MaxDoc()
+		if err != nil {
+			return nil, err
+		}
+		count += int64(maxDoc)
+	}
+
+	return &OneMerge{
+		segments:       segments,
+		totalMaxDoc:    count,
+		maxNumSegments: -1,
+		mergeProgress:  NewOneMergeProgress(),
+	}, nil
+}
+
+// GetMergeProgress Returns a OneMergeProgress instance for this merge, which provides statistics of
+// the merge threads (run time vs. sleep time) if merging is throttled.
+func (m *OneMerge) GetMergeProgress() *OneMergeProgress {
+	return m.mergeProgress
+}
+
+// SetMergeInfo Expert: Sets the SegmentCommitInfo of the merged segment. Allows sub-classes to e.g.
+// set diagnostics properties.
+func (m *OneMerge) SetMergeInfo(info index.SegmentCommitInfo) {
+	m.info = info
+}
+
+// GetMergeInfo Returns the SegmentCommitInfo for the merged segment, or nil if it hasn't been
+// initialized yet.
+func (m *OneMerge) GetMergeInfo() index.SegmentCommitInfo {
+	return m.info
+}
+
+// SetAborted Marks this merge as aborted. The merge thread should terminate at the soonest possible moment.
+func (m *OneMerge) SetAborted() {
+	m.mergeProgress.abort()
+}
+
+// IsAborted Returns true if this merge was or should be aborted.
+func (m *OneMerge) IsAborted() bool {
+	return m.mergeProgress.IsAborted()
+}
+
+// CheckAborted Returns ErrMergeAborted if the merge was aborted.
+func (m *OneMerge) CheckAborted() error {
+	if m.IsAborted() {
+		return ErrMergeAborted
+	}
+	return nil
+}
+
+// EstimatedMergeBytes Returns the estimated size in bytes of the merged segment.
+func (m *OneMerge) EstimatedMergeBytes() int64 {
+	return m.estimatedMergeBytes
+}
+
+// TotalBytesSize Returns the total size in bytes of all segments to be merged, set by IndexWriter
+// when the merge is registered.
+func (m *OneMerge) TotalBytesSize() int64 {
+	return m.totalMergeBytes
+}
+
+// GetStoreMergeInfo Return MergeInfo describing this merge.
+func (m *OneMerge) GetStoreMergeInfo() *store.MergeInfo {
+	return store.NewMergeInfo(int(m.totalMaxDoc), int(m.estimatedMergeBytes), m.isExternal, m.maxNumSegments)
+}
+
+// Segments Returns the segments to be merged.
+func (m *OneMerge) Segments() []index.SegmentCommitInfo {
+	return m.segments
+}
+
+// TotalMaxDoc Returns the total number of documents in the segments to be merged, deletions included.
+func (m *OneMerge) TotalMaxDoc() int64 {
+	return m.totalMaxDoc
+}
+
+func (m *OneMerge) String() string {
+	names := make([]string, 0, len(m.segments))
+	for _, info := range m.segments {
+		names = append(names, info.Info().Name())
+	}
+	sb := new(strings.Builder)
+	sb.WriteString(strings.Join(names, " "))
+	if m.maxNumSegments != -1 {
+		sb.WriteString(fmt.Sprintf(" [maxNumSegments=%d]", m.maxNumSegments))
+	}
+	return sb.String()
 }
 
 // A MergeSpecification instance provides the information necessary to perform multiple merges.
@@ -278,11 +461,93 @@ func (m *MergeSpecification) Add(merge *OneMerge) {
 	m.merges = append(m.merges, merge)
 }
 
+// Merges Returns the merges of this specification.
+func (m *MergeSpecification) Merges() []*OneMerge {
+	return m.merges
+}
+
+func (m *MergeSpecification) String() string {
+	sb := new(strings.Builder)
+	sb.WriteString("MergeSpec:\n")
+	for i, merge := range m.merges {
+		sb.WriteString(fmt.Sprintf("  %d: %s\n", i+1, merge))
+	}
+	return sb.String()
+}
+
 // OneMergeProgress Progress and state for an executing merge. This class encapsulates the logic to pause
 // and resume the merge thread or to abort the merge entirely.
 // lucene.experimental
 type OneMergeProgress struct {
 	pauseLock sync.Mutex
+
+	// wakes up a paused merge, the channel is buffered so a wakeup is never lost
+	wakeup  chan struct{}
+	aborted atomic.Bool
+
+	// Pause times (in nanoseconds) for each PauseReason.
+	pauseTimesNS map[PauseReason]int64
+}
+
+// NewOneMergeProgress Creates a new merge progress info.
+func NewOneMergeProgress() *OneMergeProgress {
+	return &OneMergeProgress{
+		wakeup: make(chan struct{}, 1),
+		pauseTimesNS: map[PauseReason]int64{
+			STOPPED: 0,
+			PAUSED:  0,
+			OTHER:   0,
+		},
+	}
+}
+
+// Abort the merge this progress tracks at the next possible moment.
+func (p *OneMergeProgress) abort() {
+	p.aborted.Store(true)
+	p.Wakeup() // wakeup any paused merge thread.
+}
+
+// IsAborted Return the aborted state of this merge.
+func (p *OneMergeProgress) IsAborted() bool {
+	return p.aborted.Load()
+}
+
+// PauseNanos
+// Pauses the calling goroutine for at most pauseNanos with the given reason. The pause ends early
+// once the merge is aborted or Wakeup is called and condition no longer holds.
+func (p *OneMergeProgress) PauseNanos(pauseNanos int64, reason PauseReason, condition func() bool) {
+	start := time.Now()
+	timer := time.NewTimer(time.Duration(pauseNanos))
+	defer timer.Stop()
+
+	defer func() {
+		p.pauseLock.Lock()
+		p.pauseTimesNS[reason] += time.Since(start).Nanoseconds()
+		p.pauseLock.Unlock()
+	}()
+
+	for condition() && !p.IsAborted() {
+		select {
+		case <-timer.C:
+			return
+		case <-p.wakeup:
+		}
+	}
+}
+
+// Wakeup Request a wakeup for any goroutines stalled in PauseNanos.
+func (p *OneMergeProgress) Wakeup() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// GetPauseTimes Returns pause reasons and associated times in nanoseconds.
+func (p *OneMergeProgress) GetPauseTimes() map[PauseReason]int64 {
+	p.pauseLock.Lock()
+	defer p.pauseLock.Unlock()
+	return maps.Clone(p.pauseTimesNS)
 }
 
 // PauseReason Reason for pausing the merge thread.
@@ -0,0 +1,147 @@
+package index
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/geange/lucene-go/core/store"
+)
+
+const (
+	MIN_PAUSE_CHECK_MSEC = 25
+
+	MIN_PAUSE_NS = int64(2 * time.Millisecond)
+	MAX_PAUSE_NS = int64(250 * time.Millisecond)
+)
+
+var _ store.RateLimiter = &MergeRateLimiter{}
+
+// MergeRateLimiter
+// This is the RateLimiter that IndexWriter assigns to each running merge, to give MergeSchedulers
+// ionice like control. A rate of 0 stops the merge until the rate is raised again.
+//
+// lucene.internal
+type MergeRateLimiter struct {
+	totalBytesWritten atomic.Int64
+
+	// mbPerSec and minPauseCheckBytes are read by the merge goroutine and written by the scheduler
+	mbPerSec           atomic.Uint64 // math.Float64bits of the rate
+	minPauseCheckBytes atomic.Int64
+	lastNS             int64
+
+	mergeProgress *OneMergeProgress
+}
+
+// NewMergeRateLimiter Sole constructor.
+func NewMergeRateLimiter(mergeProgress *OneMergeProgress) *MergeRateLimiter {
+	limiter := &MergeRateLimiter{mergeProgress: mergeProgress}
+	// Initially no IO limit; use setter here so minPauseCheckBytes is set:
+	_ = limiter.SetMBPerSec(math.Inf(1))
+	return limiter
+}
+
+func (m *MergeRateLimiter) SetMBPerSec(mbPerSec float64) error {
+	// 0.0 is allowed: it means the merge is paused
+	if mbPerSec < 0.0 || math.IsNaN(mbPerSec) {
+		return errors.New("mbPerSec must be positive")
+	}
+	m.mbPerSec.Store(math.Float64bits(mbPerSec))
+
+	minPauseCheckBytes := float64(MIN_PAUSE_CHECK_MSEC) / 1000.0 * mbPerSec * 1024 * 1024
+	m.minPauseCheckBytes.Store(int64(math.Min(1024*1024, minPauseCheckBytes)))
+
+	// wake the merge up so it sees the new rate
+	m.mergeProgress.Wakeup()
+	return nil
+}
+
+func (m *MergeRateLimiter) GetMBPerSec() float64 {
+	return math.Float64frombits(m.mbPerSec.Load())
+}
+
+// GetTotalBytesWritten Returns total bytes written by this merge.
+func (m *MergeRateLimiter) GetTotalBytesWritten() int64 {
+	return m.totalBytesWritten.Load()
+}
+
+func (m *MergeRateLimiter) GetMinPauseCheckBytes() int64 {
+	return m.minPauseCheckBytes.Load()
+}
+
+// Pause
+// Only the merge goroutine writing through this limiter calls Pause, it returns ErrMergeAborted
+// once the merge was aborted.
+func (m *MergeRateLimiter) Pause(bytes int64) (time.Duration, error) {
+	m.totalBytesWritten.Add(bytes)
+
+	startNS := time.Now().UnixNano()
+	curNS := startNS
+
+	// While loop because we may wake up and check again when our rate limit
+	// is changed while we were pausing:
+	paused := int64(0)
+	for {
+		delta, err := m.maybePause(bytes, curNS)
+		if err != nil {
+			return time.Duration(paused), err
+		}
+		if delta < 0 {
+			break
+		}
+		// Keep waiting.
+		paused += delta
+		curNS = time.Now().UnixNano()
+	}
+	return time.Duration(paused), nil
+}
+
+// Returns the number of nanoseconds spent in a paused state or -1 if no pause was applied.
+// If the merge was aborted ErrMergeAborted is returned.
+func (m *MergeRateLimiter) maybePause(bytes int64, curNS int64) (int64, error) {
+	// Now is a good time to abort the merge:
+	if m.mergeProgress.IsAborted() {
+		return -1, ErrMergeAborted
+	}
+
+	rate := m.GetMBPerSec() // read from the atomic rate once.
+
+	var curPauseNS int64
+	if rate == 0.0 {
+		curPauseNS = MAX_PAUSE_NS
+	} else {
+		secondsToPause := (float64(bytes) / 1024.0 / 1024.0) / rate
+
+		// Time we should sleep until; this is purely instantaneous
+		// rate (just adds seconds onto the last time we had paused to);
+		// maybe we should also offer decayed recent history one?
+		targetNS := m.lastNS + int64(1000000000*secondsToPause)
+		curPauseNS = targetNS - curNS
+	}
+
+	// We don't bother with thread pausing if the pause is smaller than 2 msec.
+	if curPauseNS <= MIN_PAUSE_NS {
+		// Set to curNS, not targetNS, to enforce the instant rate, not
+		// the "averaged over all history" rate:
+		m.lastNS = curNS
+		return -1, nil
+	}
+
+	// Defensive: don't sleep for too long; the loop above will call us again if
+	// we should keep sleeping and the rate may be adjusted in between.
+	if curPauseNS > MAX_PAUSE_NS {
+		curPauseNS = MAX_PAUSE_NS
+	}
+
+	reason := PAUSED
+	if rate == 0.0 {
+		reason = STOPPED
+	}
+
+	start := time.Now()
+	m.mergeProgress.PauseNanos(curPauseNS, reason, func() bool {
+		return rate == m.GetMBPerSec()
+	})
+	return time.Since(start).Nanoseconds(), nil
+}
@@ -21,6 +21,10 @@ type MergeScheduler interface {
 
 	// Initialize IndexWriter calls this on init.
 	Initialize(dir store.Directory)
+
+	// WrapForMerge
+	// Wraps the incoming Directory so that we can merge-throttle it using RateLimitedIndexOutput.
+	WrapForMerge(merge *OneMerge, in store.Directory) store.Directory
 }
 
 type MergeSource interface {
@@ -48,23 +52,23 @@ type indexWriterMergeSource struct {
 }
 
 func (i *indexWriterMergeSource) GetNextMerge() (*OneMerge, error) {
-	//TODO implement me
-	panic("implement me")
+	return i.writer.getNextMerge()
 }
 
 func (i *indexWriterMergeSource) OnMergeFinished(merge *OneMerge) error {
-	//TODO implement me
-	panic("implement me")
+	i.writer.lock.Lock()
+	defer i.writer.lock.Unlock()
+
+	i.writer.mergeFinish(merge)
+	return nil
 }
 
 func (i *indexWriterMergeSource) HasPendingMerges() bool {
-	//TODO implement me
-	panic("implement me")
+	return i.writer.hasPendingMerges()
 }
 
 func (i *indexWriterMergeSource) Merge(merge *OneMerge) error {
-	//TODO implement me
-	panic("implement me")
+	return i.writer.merge(i.writer.mergeCtx, merge)
 }
 
 func newIndexWriterMergeSource(writer *IndexWriter) *indexWriterMergeSource {
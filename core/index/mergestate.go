@@ -10,48 +10,11 @@ import (
 
 // MergeState
 // Holds common state used during segment merging.
-type MergeState struct {
-	// Maps document IDs from old segments to document IDs in the new segment
-	DocMaps []MergeStateDocMap
+type MergeState = index.MergeState
 
-	// SegmentInfo of the newly merged segment.
-	SegmentInfo index.SegmentInfo
-
-	// FieldInfos of the newly merged segment.
-	MergeFieldInfos index.FieldInfos
-
-	// Stored field producers being merged
-	StoredFieldsReaders []index.StoredFieldsReader
-
-	// Term vector producers being merged
-	TermVectorsReaders []index.TermVectorsReader
-
-	// Norms producers being merged
-	NormsProducers []index.NormsProducer
-
-	// DocValues producers being merged
-	DocValuesProducers []index.DocValuesProducer
-
-	// FieldInfos being merged
-	FieldInfos []index.FieldInfos
-
-	// Live docs for each reader
-	LiveDocs []util.Bits
-
-	// Postings to merge
-	FieldsProducers []index.FieldsProducer
-
-	// Point readers to merge
-	PointsReaders []index.PointsReader
-
-	// Max docs per reader
-	MaxDocs []int
-
-	// InfoStream for debugging messages.
-
-	// Indicates if the index needs to be sorted
-	NeedsIndexSort bool
-}
+// MergeStateDocMap
+// A map of doc IDs from a source segment to the merged segment, -1 means the doc was deleted.
+type MergeStateDocMap = index.MergeStateDocMap
 
 func NewMergeState(readers []index.CodecReader, segmentInfo *SegmentInfo) (*MergeState, error) {
 	if err := verifyIndexSort(readers, segmentInfo); err != nil {
@@ -110,7 +73,11 @@ func NewMergeState(readers []index.CodecReader, segmentInfo *SegmentInfo) (*Merg
 		return nil, err
 	}
 	state.SegmentInfo = segmentInfo
-	state.DocMaps = buildDocMaps(readers, segmentInfo.GetIndexSort())
+	docMaps, err := buildDocMaps(readers, segmentInfo.GetIndexSort())
+	if err != nil {
+		return nil, err
+	}
+	state.DocMaps = docMaps
 	return &state, nil
 }
 
@@ -129,18 +96,17 @@ func verifyIndexSort(readers []index.CodecReader, segmentInfo *SegmentInfo) erro
 	return nil
 }
 
-func buildDocMaps(readers []index.CodecReader, indexSort index.Sort) []MergeStateDocMap {
+func buildDocMaps(readers []index.CodecReader, indexSort index.Sort) ([]MergeStateDocMap, error) {
 	if indexSort == nil {
 		// no index sort ... we only must map around deletions, and rebase to the merged segment's docID space
 		return buildDeletionDocMaps(readers)
 	}
 
 	// do a merge sort of the incoming leaves:
-	//to := time.Now().UnixNano()
-	panic("")
+	return nil, errors.New("merging segments with an index sort is not supported yet")
 }
 
-func buildDeletionDocMaps(readers []index.CodecReader) []MergeStateDocMap {
+func buildDeletionDocMaps(readers []index.CodecReader) ([]MergeStateDocMap, error) {
 	docMaps := make([]MergeStateDocMap, 0, len(readers))
 	var totalDocs int
 
@@ -149,14 +115,16 @@ func buildDeletionDocMaps(readers []index.CodecReader) []MergeStateDocMap {
 
 		var delDocMap *packed.PackedLongValues
 		if liveDocs != nil {
-			delDocMap = removeDeletes(reader.MaxDoc(), liveDocs)
-		} else {
-			delDocMap = nil
+			docMap, err := removeDeletes(reader.MaxDoc(), liveDocs)
+			if err != nil {
+				return nil, err
+			}
+			delDocMap = docMap
 		}
 
 		docBase := totalDocs
 
-		docMaps = append(docMaps, MergeStateDocMap{func(docID int) int {
+		docMaps = append(docMaps, MergeStateDocMap{Get: func(docID int) int {
 			if liveDocs == nil {
 				return docBase + docID
 			} else if liveDocs.Test(uint(docID)) {
@@ -172,24 +140,19 @@ func buildDeletionDocMaps(readers []index.CodecReader) []MergeStateDocMap {
 
 		totalDocs += reader.NumDocs()
 	}
-	return docMaps
+	return docMaps, nil
 }
 
-func removeDeletes(maxDoc int, liveDocs util.Bits) *packed.PackedLongValues {
-	// TODO: fix it
-	panic("")
-	//docMapBuilder := packed.NewLongValuesBuilder()
-	//del := 0
-	//
-	//for i := 0; i < maxDoc; i++ {
-	//	docMapBuilder.Add(int64(i - del))
-	//	if !liveDocs.Test(uint(i)) {
-	//		del++
-	//	}
-	//}
-	//return docMapBuilder.Build()
-}
-
-type MergeStateDocMap struct {
-	Get func(docId int) int
+func removeDeletes(maxDoc int, liveDocs util.Bits) (*packed.PackedLongValues, error) {
+	docMapBuilder := packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT)
+	del := 0
+	for i := 0; i < maxDoc; i++ {
+		if err := docMapBuilder.Add(int64(i - del)); err != nil {
+			return nil, err
+		}
+		if !liveDocs.Test(uint(i)) {
+			del++
+		}
+	}
+	return docMapBuilder.Build()
 }
@@ -83,6 +83,20 @@ type MultiLevelSkipListReaderSPI interface {
 	ReadChildPointer(ctx context.Context, skipStream store.IndexInput, mtx *MultiLevelSkipListReaderContext) (int64, error)
 }
 
+// MultiLevelSkipListReaderLevelSPI
+// Optional extension of MultiLevelSkipListReaderSPI for readers that keep per-level skip data
+// (e.g. file pointers) in addition to the doc id.
+type MultiLevelSkipListReaderLevelSPI interface {
+	// SeekChild
+	// Called after the reader moved down to the given level, the per-level data of that level
+	// must be reset to the values of the last read skip entry.
+	SeekChild(level int)
+
+	// SetLastSkipData
+	// Copies the values of the current skip entry on the given level into the last skip data.
+	SetLastSkipData(level int)
+}
+
 func (m *MultiLevelSkipListReaderContext) Init(ctx context.Context, skipPointer int64, df int, spi MultiLevelSkipListReaderSPI) error {
 	m.skipPointer[0] = skipPointer
 	m.docCount = df
@@ -248,6 +262,11 @@ func (m *MultiLevelSkipListReaderContext) GetDoc() int {
 	return m.lastDoc
 }
 
+// NumberOfSkipLevels returns the number of levels which still have skip entries left.
+func (m *MultiLevelSkipListReaderContext) NumberOfSkipLevels() int {
+	return m.numberOfSkipLevels
+}
+
 func (m *MultiLevelSkipListReaderContext) SkipToWithSPI(ctx context.Context, target int, spi MultiLevelSkipListReaderSPI) (int, error) {
 	// walk up the levels until highest level is found that has a skip
 	// for this target
@@ -258,8 +277,8 @@ func (m *MultiLevelSkipListReaderContext) SkipToWithSPI(ctx context.Context, tar
 
 	for level >= 0 {
 		if target > m.skipDoc[level] {
-			if ok, err := m.loadNextSkip(ctx, level, spi); err == nil && !ok {
-				continue
+			if _, err := m.loadNextSkip(ctx, level, spi); err != nil {
+				return 0, err
 			}
 		} else {
 			// no more skips on this level, go down one level
@@ -279,6 +298,9 @@ func (m *MultiLevelSkipListReaderContext) loadNextSkip(ctx context.Context, leve
 	// we have to skip, the target document is greater than the current
 	// skip list entry
 	m.setLastSkipData(level)
+	if levelSPI, ok := spi.(MultiLevelSkipListReaderLevelSPI); ok {
+		levelSPI.SetLastSkipData(level)
+	}
 
 	m.numSkipped[level] += m.skipInterval[level]
 
@@ -324,6 +346,9 @@ func (m *MultiLevelSkipListReaderContext) seekChild(ctx context.Context, level i
 		}
 		m.childPointer[level] = pointer + m.skipPointer[level-1]
 	}
+	if levelSPI, ok := spi.(MultiLevelSkipListReaderLevelSPI); ok {
+		levelSPI.SeekChild(level)
+	}
 	return nil
 }
 
@@ -4,6 +4,10 @@ import "github.com/geange/lucene-go/core/store"
 
 var _ MergeScheduler = &NoMergeScheduler{}
 
+// NoMergeScheduler
+// A MergeScheduler which never executes any merges. Use it if you want to prevent an IndexWriter
+// from ever executing merges, regardless of the MergePolicy used. Merges selected by the MergePolicy
+// are handed back to the IndexWriter as finished, so closing the writer doesn't wait for them.
 type NoMergeScheduler struct {
 }
 
@@ -16,9 +20,24 @@ func (n *NoMergeScheduler) Close() error {
 }
 
 func (n *NoMergeScheduler) Merge(mergeSource MergeSource, trigger MergeTrigger) error {
-	return nil
+	for {
+		merge, err := mergeSource.GetNextMerge()
+		if err != nil {
+			return err
+		}
+		if merge == nil {
+			return nil
+		}
+		if err := mergeSource.OnMergeFinished(merge); err != nil {
+			return err
+		}
+	}
 }
 
 func (n *NoMergeScheduler) Initialize(dir store.Directory) {
 	return
 }
+
+func (n *NoMergeScheduler) WrapForMerge(merge *OneMerge, in store.Directory) store.Directory {
+	return in
+}
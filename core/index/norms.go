@@ -90,29 +90,25 @@ func (i *innerNormsProducer) GetNorms(fieldInfo *document.FieldInfo) (index.Nume
 		return nil, errors.New("wrong fieldInfo")
 	}
 
-	// TODO: impl it
-
-	//subs :=
-	//List<NumericDocValuesSub> subs = new ArrayList<>();
-	//assert mergeState.docMaps.length == mergeState.docValuesProducers.length;
-	//for (int i=0;i<mergeState.docValuesProducers.length;i++) {
-	//	NumericDocValues norms = null;
-	//	NormsProducer normsProducer = mergeState.normsProducers[i];
-	//	if (normsProducer != null) {
-	//		FieldInfo readerFieldInfo = mergeState.fieldInfos[i].fieldInfo(mergeFieldInfo.name);
-	//		if (readerFieldInfo != null && readerFieldInfo.hasNorms()) {
-	//			norms = normsProducer.getNorms(readerFieldInfo);
-	//		}
-	//	}
-	//
-	//	if (norms != null) {
-	//		subs.add(new NumericDocValuesSub(mergeState.docMaps[i], norms));
-	//	}
-	//}
-	//
-	//final DocIDMerger<NumericDocValuesSub> docIDMerger = DocIDMerger.of(subs, mergeState.needsIndexSort);
-
-	panic("")
+	mergeState := i.mergeState
+	subs := make([]*NumericDocValuesSub, 0, len(mergeState.NormsProducers))
+	for idx, normsProducer := range mergeState.NormsProducers {
+		if normsProducer == nil {
+			continue
+		}
+		readerFieldInfo := mergeState.FieldInfos[idx].FieldInfo(i.mergeFieldInfo.Name())
+		if readerFieldInfo == nil || !readerFieldInfo.HasNorms() {
+			continue
+		}
+		norms, err := normsProducer.GetNorms(readerFieldInfo)
+		if err != nil {
+			return nil, err
+		}
+		if norms != nil {
+			subs = append(subs, NewNumericDocValuesSub(mergeState.DocMaps[idx], norms))
+		}
+	}
+	return newMergedNumericDocValues(context.Background(), subs, mergeState)
 }
 
 func (i *innerNormsProducer) CheckIntegrity() error {
@@ -123,11 +119,6 @@ func (i *innerNormsProducer) GetMergeInstance() index.NormsProducer {
 	return i
 }
 
-// NumericDocValuesSub Tracks state of one numeric sub-reader that we are merging
-type NumericDocValuesSub struct {
-	values index.NumericDocValues
-}
-
 // NormValuesWriter
 // Buffers up pending long per doc, then flushes when segment flushes.
 type NormValuesWriter struct {
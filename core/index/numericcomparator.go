@@ -0,0 +1,449 @@
+package index
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/bits-and-blooms/bitset"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/numeric"
+)
+
+// Numeric the types a NumericComparator sorts by, they are read from NumericDocValues.
+type Numeric interface {
+	int32 | int64 | float32 | float64
+}
+
+var _ index.FieldComparator = &NumericComparator[int64]{}
+
+// NumericComparator
+// Numeric comparator for comparing numeric values. This comparator provides a skipping
+// functionality – an iterator that can skip over non-competitive documents.
+//
+// The values are read from NumericDocValues, when the same field is also indexed as a 1D point
+// the points are used to skip documents that can't compete with the bottom of the queue anymore.
+type NumericComparator[T Numeric] struct {
+	field        string
+	missingValue T
+	reverse      bool
+	bytesCount   int // how many bytes are used to encode this number
+
+	// converts the long stored in the doc values back to the sort value
+	decode func(v int64) T
+	// encodes the sort value the same way the points of the field are encoded
+	encode func(v T, dest []byte)
+
+	values   []T
+	topValue T
+	bottom   T
+
+	topValueSet          bool
+	singleSort           bool // singleSort is true, if sort is based on a single sort field.
+	hitsThresholdReached bool
+	queueFull            bool
+	canSkipDocuments     bool
+}
+
+func newNumericComparator[T Numeric](numHits int, field string, missingValue any, reverse bool, sortPos int,
+	bytesCount int, decode func(v int64) T, encode func(v T, dest []byte)) *NumericComparator[T] {
+
+	comparator := &NumericComparator[T]{
+		field:      field,
+		reverse:    reverse,
+		bytesCount: bytesCount,
+		decode:     decode,
+		encode:     encode,
+		values:     make([]T, numHits),
+		// skipping functionality is only relevant for primary sort
+		canSkipDocuments: sortPos == 0,
+	}
+	if missingValue != nil {
+		comparator.missingValue = missingValue.(T)
+	}
+	return comparator
+}
+
+// NewIntComparator
+// Comparator based on int32 for numHits. This comparator is used when there is a need to
+// compare int values stored in NumericDocValues, the points of the field are encoded as IntPoint.
+func NewIntComparator(numHits int, field string, missingValue any, reverse bool, sortPos int) *NumericComparator[int32] {
+	return newNumericComparator(numHits, field, missingValue, reverse, sortPos, 4,
+		func(v int64) int32 {
+			return int32(v)
+		},
+		numeric.IntToSortableBytes,
+	)
+}
+
+// NewLongComparator
+// Comparator based on int64 for numHits. This comparator is used when there is a need to
+// compare long values stored in NumericDocValues, the points of the field are encoded as LongPoint.
+func NewLongComparator(numHits int, field string, missingValue any, reverse bool, sortPos int) *NumericComparator[int64] {
+	return newNumericComparator(numHits, field, missingValue, reverse, sortPos, 8,
+		func(v int64) int64 {
+			return v
+		},
+		func(v int64, dest []byte) {
+			numeric.Uint64ToSortableBytes(uint64(v), dest)
+		},
+	)
+}
+
+// NewFloatComparator
+// Comparator based on float32 for numHits. This comparator is used when there is a need to
+// compare float values stored in NumericDocValues (see FloatDocValuesField), the points of
+// the field are encoded as FloatPoint.
+func NewFloatComparator(numHits int, field string, missingValue any, reverse bool, sortPos int) *NumericComparator[float32] {
+	return newNumericComparator(numHits, field, missingValue, reverse, sortPos, 4,
+		func(v int64) float32 {
+			return math.Float32frombits(uint32(v))
+		},
+		func(v float32, dest []byte) {
+			numeric.IntToSortableBytes(numeric.Float32ToSortableInt(v), dest)
+		},
+	)
+}
+
+// NewDoubleComparator
+// Comparator based on float64 for numHits. This comparator is used when there is a need to
+// compare double values stored in NumericDocValues (see DoubleDocValuesField), the points of
+// the field are encoded as DoublePoint.
+func NewDoubleComparator(numHits int, field string, missingValue any, reverse bool, sortPos int) *NumericComparator[float64] {
+	return newNumericComparator(numHits, field, missingValue, reverse, sortPos, 8,
+		func(v int64) float64 {
+			return math.Float64frombits(uint64(v))
+		},
+		func(v float64, dest []byte) {
+			numeric.Uint64ToSortableBytes(numeric.Float64ToSortableLong(v), dest)
+		},
+	)
+}
+
+func (n *NumericComparator[T]) Compare(slot1, slot2 int) int {
+	return cmp.Compare(n.values[slot1], n.values[slot2])
+}
+
+func (n *NumericComparator[T]) SetTopValue(value any) {
+	n.topValueSet = true
+	n.topValue = value.(T)
+}
+
+func (n *NumericComparator[T]) Value(slot int) any {
+	return n.values[slot]
+}
+
+func (n *NumericComparator[T]) CompareValues(first, second any) int {
+	return cmp.Compare(first.(T), second.(T))
+}
+
+func (n *NumericComparator[T]) SetSingleSort() {
+	n.singleSort = true
+}
+
+func (n *NumericComparator[T]) DisableSkipping() {
+	n.canSkipDocuments = false
+}
+
+func (n *NumericComparator[T]) GetLeafComparator(context index.LeafReaderContext) (index.LeafFieldComparator, error) {
+	reader := context.LeafReader()
+
+	docValues, err := GetNumeric(reader, n.field)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := &numericLeafComparator[T]{
+		parent:    n,
+		docValues: docValues,
+	}
+
+	if !n.canSkipDocuments {
+		return leaf, nil
+	}
+
+	pointValues, ok := reader.GetPointValues(n.field)
+	if !ok {
+		return leaf, nil
+	}
+
+	info := reader.GetFieldInfos().FieldInfo(n.field)
+	if info == nil || info.GetPointDimensionCount() == 0 {
+		return nil, fmt.Errorf("field %s doesn't index points according to FieldInfos", n.field)
+	}
+	if info.GetPointDimensionCount() > 1 {
+		return nil, fmt.Errorf("field %s is indexed with multiple dimensions, sorting is not supported", n.field)
+	}
+	if info.GetPointNumBytes() != n.bytesCount {
+		return nil, fmt.Errorf("field %s is indexed with %d bytes per dimension, but %v expected %d",
+			n.field, info.GetPointNumBytes(), n, n.bytesCount)
+	}
+
+	// skipping is enabled when points are available
+	leaf.pointValues = pointValues
+	leaf.enableSkipping = true
+	leaf.maxDoc = reader.MaxDoc()
+	if !n.reverse || n.topValueSet {
+		leaf.maxValueAsBytes = make([]byte, n.bytesCount)
+	}
+	if n.reverse || n.topValueSet {
+		leaf.minValueAsBytes = make([]byte, n.bytesCount)
+	}
+	leaf.competitiveIterator = types.DocIdSetIteratorAll(leaf.maxDoc)
+	leaf.iteratorCost = int64(leaf.maxDoc)
+	return leaf, nil
+}
+
+var _ index.LeafFieldComparator = &numericLeafComparator[int64]{}
+
+// Leaf comparator for NumericComparator that provides skipping functionality
+type numericLeafComparator[T Numeric] struct {
+	parent *NumericComparator[T]
+
+	docValues   index.NumericDocValues // nil if the segment has no values for the field
+	pointValues types.PointValues
+
+	// if skipping functionality should be enabled on this segment
+	enableSkipping  bool
+	maxDoc          int
+	minValueAsBytes []byte
+	maxValueAsBytes []byte
+
+	competitiveIterator types.DocIdSetIterator
+	iteratorCost        int64
+	maxDocVisited       int
+	updateCounter       int
+}
+
+// Retrieves the value for the given document, missingValue is returned if the document has no value.
+func (n *numericLeafComparator[T]) getValueForDoc(doc int) (T, error) {
+	if n.docValues == nil {
+		return n.parent.missingValue, nil
+	}
+
+	ok, err := n.docValues.AdvanceExact(doc)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return n.parent.missingValue, nil
+	}
+
+	v, err := n.docValues.LongValue()
+	if err != nil {
+		return 0, err
+	}
+	return n.parent.decode(v), nil
+}
+
+func (n *numericLeafComparator[T]) SetBottom(slot int) error {
+	n.parent.bottom = n.parent.values[slot]
+	n.parent.queueFull = true            // if we are setting bottom, it means that we have collected enough hits
+	return n.updateCompetitiveIterator() // update an iterator if we set a new bottom
+}
+
+func (n *numericLeafComparator[T]) CompareBottom(doc int) (int, error) {
+	value, err := n.getValueForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	return cmp.Compare(n.parent.bottom, value), nil
+}
+
+func (n *numericLeafComparator[T]) CompareTop(doc int) (int, error) {
+	value, err := n.getValueForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	return cmp.Compare(n.parent.topValue, value), nil
+}
+
+func (n *numericLeafComparator[T]) Copy(slot, doc int) error {
+	value, err := n.getValueForDoc(doc)
+	if err != nil {
+		return err
+	}
+	n.parent.values[slot] = value
+	n.maxDocVisited = doc
+	return nil
+}
+
+func (n *numericLeafComparator[T]) SetScorer(scorer index.Scorable) error {
+	if s, ok := scorer.(index.Scorer); ok {
+		n.iteratorCost = s.Iterator().Cost()
+		return n.updateCompetitiveIterator() // update an iterator when we have a new segment
+	}
+	return nil
+}
+
+func (n *numericLeafComparator[T]) SetHitsThresholdReached() error {
+	n.parent.hitsThresholdReached = true
+	return n.updateCompetitiveIterator()
+}
+
+func (n *numericLeafComparator[T]) CompetitiveIterator() (types.DocIdSetIterator, error) {
+	if !n.enableSkipping {
+		return nil, nil
+	}
+	return &competitiveDISI{
+		docID: n.competitiveIterator.DocID(),
+		in: func() types.DocIdSetIterator {
+			return n.competitiveIterator
+		},
+	}, nil
+}
+
+// Returns true if the missing value is competitive, documents with missing values can't be filtered
+// out by points then.
+func (n *numericLeafComparator[T]) isMissingValueCompetitive() bool {
+	result := cmp.Compare(n.parent.missingValue, n.parent.bottom)
+	// in reverse (desc) sort missingValue is competitive when it's greater or equal to bottom,
+	// in asc sort missingValue is competitive when it's smaller or equal to bottom
+	if n.parent.reverse {
+		return result >= 0
+	}
+	return result <= 0
+}
+
+// Narrows the competitive iterator down to the documents whose point is competitive with the bottom
+// of the queue (and the top value for paging).
+func (n *numericLeafComparator[T]) updateCompetitiveIterator() error {
+	parent := n.parent
+	if !n.enableSkipping || !parent.hitsThresholdReached || !parent.queueFull {
+		return nil
+	}
+
+	// if some documents have missing points, check that missing values prohibits optimization
+	if n.pointValues.GetDocCount() < n.maxDoc && n.isMissingValueCompetitive() {
+		return nil // we can't filter out documents, as documents with missing values are competitive
+	}
+
+	n.updateCounter++
+	if n.updateCounter > 256 && (n.updateCounter&0x1f) != 0x1f { // Start sampling if we get called too much
+		return nil
+	}
+
+	if parent.reverse {
+		parent.encode(parent.bottom, n.minValueAsBytes)
+		if parent.topValueSet {
+			parent.encode(parent.topValue, n.maxValueAsBytes)
+		}
+	} else {
+		parent.encode(parent.bottom, n.maxValueAsBytes)
+		if parent.topValueSet {
+			parent.encode(parent.topValue, n.minValueAsBytes)
+		}
+	}
+
+	bytesCount := parent.bytesCount
+	singleSort := parent.singleSort
+	result := bitset.New(uint(n.maxDoc))
+
+	visitor := &types.BytesVisitor{
+		VisitFn: func(docID int) error {
+			if docID <= n.maxDocVisited {
+				return nil // Already visited or skipped
+			}
+			result.Set(uint(docID))
+			return nil
+		},
+		VisitLeafFn: func(ctx context.Context, docID int, packedValue []byte) error {
+			if docID <= n.maxDocVisited {
+				return nil // already visited or skipped
+			}
+			if n.maxValueAsBytes != nil {
+				cmpValue := bytes.Compare(packedValue[:bytesCount], n.maxValueAsBytes)
+				// if doc's value is too high or for single sort even equal, it is not competitive and the doc can be skipped
+				if cmpValue > 0 || (singleSort && cmpValue == 0) {
+					return nil
+				}
+			}
+			if n.minValueAsBytes != nil {
+				cmpValue := bytes.Compare(packedValue[:bytesCount], n.minValueAsBytes)
+				// if doc's value is too low or for single sort even equal, it is not competitive and the doc can be skipped
+				if cmpValue < 0 || (singleSort && cmpValue == 0) {
+					return nil
+				}
+			}
+			result.Set(uint(docID)) // doc is competitive
+			return nil
+		},
+		CompareFn: func(minPackedValue, maxPackedValue []byte) types.Relation {
+			if n.maxValueAsBytes != nil {
+				cmpValue := bytes.Compare(minPackedValue[:bytesCount], n.maxValueAsBytes)
+				if cmpValue > 0 || (singleSort && cmpValue == 0) {
+					return types.CELL_OUTSIDE_QUERY
+				}
+			}
+			if n.minValueAsBytes != nil {
+				cmpValue := bytes.Compare(maxPackedValue[:bytesCount], n.minValueAsBytes)
+				if cmpValue < 0 || (singleSort && cmpValue == 0) {
+					return types.CELL_OUTSIDE_QUERY
+				}
+			}
+			if (n.maxValueAsBytes != nil && bytes.Compare(maxPackedValue[:bytesCount], n.maxValueAsBytes) > 0) ||
+				(n.minValueAsBytes != nil && bytes.Compare(minPackedValue[:bytesCount], n.minValueAsBytes) < 0) {
+				return types.CELL_CROSSES_QUERY
+			}
+			return types.CELL_INSIDE_QUERY
+		},
+		GrowFn: func(count int) {},
+	}
+
+	threshold := n.iteratorCost >> 3
+	ctx := context.Background()
+	estimatedNumberOfMatches, err := n.pointValues.EstimatePointCount(ctx, visitor) // runs in O(log(numPoints))
+	if err != nil {
+		return err
+	}
+	if int64(estimatedNumberOfMatches) >= threshold {
+		// the new range is not selective enough to be worth materializing, it doesn't reduce number of docs at least 8x
+		return nil
+	}
+
+	if err := n.pointValues.Intersect(ctx, visitor); err != nil {
+		return err
+	}
+
+	cost := int64(result.Count())
+	n.competitiveIterator = NewBitSetIterator(result, cost)
+	n.iteratorCost = cost
+	return nil
+}
+
+var _ types.DocIdSetIterator = &competitiveDISI{}
+
+// competitiveDISI is handed out as the competitive iterator of a leaf comparator. The comparator
+// swaps its iterator whenever the bottom of the queue changes, this iterator always delegates to
+// the current one.
+type competitiveDISI struct {
+	docID int
+	in    func() types.DocIdSetIterator
+}
+
+func (c *competitiveDISI) DocID() int {
+	return c.docID
+}
+
+func (c *competitiveDISI) NextDoc(ctx context.Context) (int, error) {
+	return c.Advance(ctx, c.docID+1)
+}
+
+func (c *competitiveDISI) Advance(ctx context.Context, target int) (int, error) {
+	in := c.in()
+	doc, err := in.Advance(ctx, target)
+	c.docID = in.DocID()
+	return doc, err
+}
+
+func (c *competitiveDISI) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, c, target)
+}
+
+func (c *competitiveDISI) Cost() int64 {
+	return c.in().Cost()
+}
@@ -3,6 +3,7 @@ package index
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/bits-and-blooms/bitset"
@@ -64,12 +65,18 @@ type NumericDocValuesWriter struct {
 }
 
 func NewNumericDocValuesWriter(fieldInfo *document.FieldInfo) *NumericDocValuesWriter {
-	panic("")
+	return &NumericDocValuesWriter{
+		pending:       packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		docsWithField: NewDocsWithFieldSet(),
+		fieldInfo:     fieldInfo,
+		lastDocID:     -1,
+	}
 }
 
 func (n *NumericDocValuesWriter) AddValue(docID int, value int64) error {
 	if docID <= n.lastDocID {
-		panic("")
+		return fmt.Errorf("DocValuesField \"%s\" appears more than once in this document (only one value is allowed per field)",
+			n.fieldInfo.Name())
 	}
 	if err := n.pending.Add(value); err != nil {
 		return err
@@ -81,28 +88,56 @@ func (n *NumericDocValuesWriter) AddValue(docID int, value int64) error {
 	return nil
 }
 
+func (n *NumericDocValuesWriter) finish() error {
+	if n.finalValues != nil {
+		return nil
+	}
+	finalValues, err := n.pending.Build()
+	if err != nil {
+		return err
+	}
+	n.finalValues = finalValues
+	return nil
+}
+
 func (n *NumericDocValuesWriter) Flush(state *index.SegmentWriteState, sortMap index.DocMap, consumer index.DocValuesConsumer) error {
-	// TODO: fix it
-	panic("")
-	/*
-		n.finalValues = n.pending.Build()
+	if err := n.finish(); err != nil {
+		return err
+	}
 
-		return consumer.AddNumericField(nil, n.fieldInfo, &EmptyDocValuesProducer{
-			FnGetNumeric: func(field *document.FieldInfo) (NumericDocValues, error) {
-				iterator, err := n.docsWithField.Iterator()
-				if err != nil {
-					return nil, err
-				}
-				return NewBufferedNumericDocValues(n.finalValues, iterator), nil
-			},
-		})
+	var sorted *NumericDVs
+	if sortMap != nil {
+		maxDoc, err := state.SegmentInfo.MaxDoc()
+		if err != nil {
+			return err
+		}
+		iterator, err := n.docsWithField.Iterator()
+		if err != nil {
+			return err
+		}
+		sorted = SortDocValues(maxDoc, sortMap, NewBufferedNumericDocValues(n.finalValues, iterator))
+	}
 
-	*/
+	return consumer.AddNumericField(context.TODO(), n.fieldInfo, &EmptyDocValuesProducer{
+		FnGetNumeric: func(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
+			if sorted != nil {
+				return NewSortingNumericDocValues(sorted), nil
+			}
+			iterator, err := n.docsWithField.Iterator()
+			if err != nil {
+				return nil, err
+			}
+			return NewBufferedNumericDocValues(n.finalValues, iterator), nil
+		},
+	})
 }
 
 func (n *NumericDocValuesWriter) GetDocValues() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	if err := n.finish(); err != nil {
+		return nil
+	}
+	iterator, _ := n.docsWithField.Iterator()
+	return NewBufferedNumericDocValues(n.finalValues, iterator)
 }
 
 var _ index.NumericDocValues = &BufferedNumericDocValues{}
@@ -205,8 +240,7 @@ func (s *SortingNumericDocValues) SlowAdvance(ctx context.Context, target int) (
 }
 
 func (s *SortingNumericDocValues) Cost() int64 {
-	//TODO implement me
-	panic("implement me")
+	return int64(s.dvs.docsWithField.Count())
 }
 
 func (s *SortingNumericDocValues) AdvanceExact(target int) (bool, error) {
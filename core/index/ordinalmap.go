@@ -1,11 +1,13 @@
 package index
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"slices"
 
 	"github.com/geange/lucene-go/core/interface/index"
-	"github.com/geange/lucene-go/core/types"
 )
 
 // OrdinalMap
@@ -14,25 +16,110 @@ import (
 // It's better to operate in segment-private ordinal space instead when possible.
 // lucene.internal
 type OrdinalMap struct {
-	valueCount          int64 // number of global ordinals
-	globalOrdDeltas     types.LongValues
-	firstSegments       types.LongValues
-	segmentToGlobalOrds []types.LongValues
+	valueCount          int64     // number of global ordinals
+	firstSegments       []int     // globalOrd -> first segment (in the caller's numbering) containing the term
+	firstSegmentOrds    []int64   // globalOrd -> ord of the term in its first segment
+	segmentToGlobalOrds [][]int64 // segment -> (segmentOrd -> globalOrd)
 	segmentMap          *SegmentMap
 }
 
-func NewOrdinalMap(subs []index.TermsEnum, segmentMap *SegmentMap, acceptableOverheadRatio float64) (*OrdinalMap, error) {
-	//res := &OrdinalMap{
-	//	segmentMap: segmentMap,
-	//}
-	//globalOrdDeltas := packed.NewPackedLongValuesBuilder()
-	//firstSegments := packed.NewPackedLongValuesBuilder()
-	//ordDeltas := make([]*packed.LongValuesBuilder, len(subs))
-	//firstSegmentBits := 0
-	//for i := 0; i < len(ordDeltas); i++ {
-	//	ordDeltas[i] = packed.
-	//}
-	panic("")
+// NewOrdinalMap
+// Creates an ordinal map that allows mapping ords to/from a merged space from subs.
+// subs: TermsEnums that support TermsEnum.ord(). They need not be dense (e.g. can be FilteredTermsEnums).
+// segmentMap: mapping from the segment indices to an order that puts the segments with the most
+// values first, so that the first segment of a term is the largest one containing it.
+// acceptableOverheadRatio: ignored, the mappings are kept in plain slices.
+func NewOrdinalMap(ctx context.Context, subs []index.TermsEnum, segmentMap *SegmentMap, acceptableOverheadRatio float64) (*OrdinalMap, error) {
+	res := &OrdinalMap{
+		segmentMap:          segmentMap,
+		firstSegments:       make([]int, 0),
+		firstSegmentOrds:    make([]int64, 0),
+		segmentToGlobalOrds: make([][]int64, len(subs)),
+	}
+
+	// iterate the subs in the order of segmentMap, so ties are resolved in favor of the largest segment
+	active := make([]*TermsEnumIndex, 0, len(subs))
+	for i := range subs {
+		segmentIndex := segmentMap.NewToOld(i)
+		res.segmentToGlobalOrds[segmentIndex] = make([]int64, 0)
+		sub := NewTermsEnumIndex(subs[segmentIndex], segmentIndex)
+		ok, err := nextTerm(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			active = append(active, sub)
+		}
+	}
+
+	globalOrd := int64(0)
+	for len(active) > 0 {
+		var top *TermsEnumIndex
+		for _, sub := range active {
+			if top == nil || bytes.Compare(sub.currentTerm, top.currentTerm) < 0 {
+				top = sub
+			}
+		}
+		term := bytes.Clone(top.currentTerm)
+
+		res.firstSegments = append(res.firstSegments, top.subIndex)
+		res.firstSegmentOrds = append(res.firstSegmentOrds, int64(len(res.segmentToGlobalOrds[top.subIndex])))
+
+		remaining := active[:0]
+		for _, sub := range active {
+			if bytes.Equal(sub.currentTerm, term) {
+				res.segmentToGlobalOrds[sub.subIndex] = append(res.segmentToGlobalOrds[sub.subIndex], globalOrd)
+				ok, err := nextTerm(ctx, sub)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			remaining = append(remaining, sub)
+		}
+		active = remaining
+		globalOrd++
+	}
+	res.valueCount = globalOrd
+	return res, nil
+}
+
+func nextTerm(ctx context.Context, sub *TermsEnumIndex) (bool, error) {
+	term, err := sub.Next(ctx)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return term != nil, nil
+}
+
+// GetGlobalOrd
+// Given a segment number and segment ordinal, returns the corresponding global ordinal.
+func (o *OrdinalMap) GetGlobalOrd(segmentIndex int, segmentOrd int64) int64 {
+	return o.segmentToGlobalOrds[segmentIndex][segmentOrd]
+}
+
+// GetFirstSegmentOrd
+// Given global ordinal, returns the ordinal of the first segment which contains this ordinal
+// (the corresponding to the segment return getFirstSegmentNumber).
+func (o *OrdinalMap) GetFirstSegmentOrd(globalOrd int64) int64 {
+	return o.firstSegmentOrds[globalOrd]
+}
+
+// GetFirstSegmentNumber
+// Given a global ordinal, returns the index of the first segment that contains this term.
+func (o *OrdinalMap) GetFirstSegmentNumber(globalOrd int64) int {
+	return o.firstSegments[globalOrd]
+}
+
+// GetValueCount
+// Returns the total number of unique terms in global ord space.
+func (o *OrdinalMap) GetValueCount() int64 {
+	return o.valueCount
 }
 
 type TermsEnumIndex struct {
@@ -84,14 +171,17 @@ func makeIndexMap(weight []int64) []int {
 		newToOld[i] = i
 	}
 
-	slices.SortFunc(newToOld, func(i, j int) int {
-		return Compare(weight[newToOld[j]], weight[newToOld[i]])
+	// sort by descending weight, keeping the original order on ties
+	slices.SortStableFunc(newToOld, func(i, j int) int {
+		return Compare(weight[j], weight[i])
 	})
 	return newToOld
 }
 
 func inverseInts(data []int) []int {
-	inverse := slices.Clone(data)
-	slices.Reverse(inverse)
+	inverse := make([]int, len(data))
+	for i, v := range data {
+		inverse[v] = i
+	}
 	return inverse
 }
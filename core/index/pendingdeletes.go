@@ -117,10 +117,10 @@ func (p *pendingDeletes) GetMutableBits() *bitset.BitSet {
 		// SegmentReader sharing the current liveDocs
 		// instance; must now make a private clone so we can
 		// change it:
+		doc, _ := p.info.Info().MaxDoc()
 		if p.liveDocs != nil {
-			p.writeableLiveDocs = p.liveDocs.(*bitset.BitSet).Clone()
+			p.writeableLiveDocs = cloneLiveDocs(p.liveDocs, doc)
 		} else {
-			doc, _ := p.info.Info().MaxDoc()
 			p.writeableLiveDocs = bitset.New(uint(doc))
 			p.writeableLiveDocs.FlipRange(0, uint(doc))
 		}
@@ -129,6 +129,20 @@ func (p *pendingDeletes) GetMutableBits() *bitset.BitSet {
 	return p.writeableLiveDocs
 }
 
+// cloneLiveDocs Copies live docs into a bitset, whatever Bits the LiveDocsFormat read them into
+func cloneLiveDocs(liveDocs util.Bits, maxDoc int) *bitset.BitSet {
+	if bits, ok := liveDocs.(*bitset.BitSet); ok {
+		return bits.Clone()
+	}
+	bits := bitset.New(uint(maxDoc))
+	for i := 0; i < maxDoc; i++ {
+		if liveDocs.Test(uint(i)) {
+			bits.Set(uint(i))
+		}
+	}
+	return bits
+}
+
 func (p *pendingDeletes) Delete(docID int) (bool, error) {
 	mutableBits := p.GetMutableBits()
 
@@ -142,6 +156,8 @@ func (p *pendingDeletes) Delete(docID int) (bool, error) {
 }
 
 func (p *pendingDeletes) GetLiveDocs() util.Bits {
+	// Prevent modifications to the returned live docs
+	p.writeableLiveDocs = nil
 	return p.liveDocs
 }
 
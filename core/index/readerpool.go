@@ -2,6 +2,7 @@ package index
 
 import (
 	"errors"
+	"sync"
 	"sync/atomic"
 
 	"github.com/geange/lucene-go/core/interface/index"
@@ -14,6 +15,8 @@ import (
 // of the SegmentReaders in all these places if it is in "near real-time mode" (getReader() has been
 // called on this instance).
 type ReaderPool struct {
+	sync.Mutex
+
 	readerMap               map[index.SegmentCommitInfo]*ReadersAndUpdates // Map<SegmentCommitInfo,ReadersAndUpdates>
 	directory               store.Directory
 	originalDirectory       store.Directory
@@ -85,13 +88,15 @@ func NewReaderPool(directory, originalDirectory store.Directory, segmentInfos *S
 }
 
 func (p *ReaderPool) anyDocValuesChanges() bool {
-	//for (ReadersAndUpdates rld : readerMap.values()) {
-	//	// NOTE: we don't check for pending deletes because deletes carry over in RAM to NRT readers
-	//	if (rld.getNumDVUpdates() != 0) {
-	//		return true;
-	//	}
-	//}
-	// TODO: fix it
+	p.Lock()
+	defer p.Unlock()
+
+	for _, rld := range p.readerMap {
+		// NOTE: we don't check for pending deletes because deletes carry over in RAM to NRT readers
+		if rld.GetNumDVUpdates() != 0 {
+			return true
+		}
+	}
 	return false
 }
 
@@ -123,6 +128,9 @@ func (p *ReaderPool) enableReaderPooling() {
 // Obtain a ReadersAndLiveDocs instance from the readerPool. If create is true,
 // you must later call release(ReadersAndUpdates, boolean).
 func (p *ReaderPool) Get(info index.SegmentCommitInfo, create bool) (*ReadersAndUpdates, error) {
+	p.Lock()
+	defer p.Unlock()
+
 	if p.closed.Load() {
 		return nil, errors.New("ReaderPool is already closed")
 	}
@@ -146,6 +154,9 @@ func (p *ReaderPool) Get(info index.SegmentCommitInfo, create bool) (*ReadersAnd
 }
 
 func (p *ReaderPool) commit(infos *SegmentInfos) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
 	atLeastOneChange := false
 	for _, segment := range infos.segments {
 		rld, ok := p.readerMap[segment]
@@ -180,6 +191,78 @@ func (p *ReaderPool) commit(infos *SegmentInfos) (bool, error) {
 	return atLeastOneChange, nil
 }
 
+// Release the ReadersAndUpdates. This might cause the ReadersAndUpdates to be removed from
+// the pool if reader pooling is disabled and this was the last reference.
+// Returns true if the live docs were written to disk as part of the release.
+func (p *ReaderPool) release(rld *ReadersAndUpdates, assertInfoLive bool) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	changed := false
+	// Matches IncRef in Get:
+	rld.DecRef()
+
+	if _, ok := p.readerMap[rld.info]; ok && !p.poolReaders && rld.RefCount() == 0 {
+		// This is the last ref to this RLD, and we're not
+		// pooling, so remove it:
+		written, err := rld.writeLiveDocs(p.directory)
+		if err != nil {
+			return false, err
+		}
+		changed = written
+
+		if rld.GetNumDVUpdates() == 0 {
+			if err := rld.dropReaders(); err != nil {
+				return false, err
+			}
+			delete(p.readerMap, rld.info)
+		}
+		// else: We are forced to pool this segment until its deletes fully apply (no delGen gaps)
+	}
+	return changed, nil
+}
+
+// Drops the ReadersAndUpdates of the given segment, eg. because it was merged away.
+// Returns true if the segment was pooled.
+func (p *ReaderPool) drop(info index.SegmentCommitInfo) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	rld, ok := p.readerMap[info]
+	if !ok {
+		return false, nil
+	}
+	delete(p.readerMap, info)
+	return true, rld.dropReaders()
+}
+
+// Drops all pooled readers without writing any pending changes, used on rollback.
+func (p *ReaderPool) dropAll() error {
+	p.Lock()
+	defer p.Unlock()
+
+	var errs []error
+	for info, rld := range p.readerMap {
+		rld.dropChanges()
+		if err := rld.dropReaders(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(p.readerMap, info)
+	}
+	return errors.Join(errs...)
+}
+
 func (p *ReaderPool) writeAllDocValuesUpdates() (bool, error) {
-	panic("")
+	p.Lock()
+	defer p.Unlock()
+
+	anyChanges := false
+	for _, rld := range p.readerMap {
+		changed, err := rld.writeFieldUpdates(p.directory, p.fieldNumbers, p.completedDelGenSupplier())
+		if err != nil {
+			return false, err
+		}
+		anyChanges = anyChanges || changed
+	}
+	return anyChanges, nil
 }
@@ -94,6 +94,22 @@ func (r *ReadersAndUpdates) GetDelCount() int {
 	return r.pendingDeletes.GetDelCount()
 }
 
+// Delete
+// Marks the document deleted, returns true if it was live. The reader is opened first if there is
+// none yet, it initializes the pending deletes with the live docs on disk.
+func (r *ReadersAndUpdates) Delete(ctx context.Context, docID int) (bool, error) {
+	if r.reader == nil {
+		reader, err := r.GetReader(ctx, store.READ)
+		if err != nil {
+			return false, err
+		}
+		if err := reader.DecRef(); err != nil {
+			return false, err
+		}
+	}
+	return r.pendingDeletes.Delete(docID)
+}
+
 // AddDVUpdate
 // Adds a new resolved (meaning it maps docIDs to new values) doc values packet.
 // We buffer these in RAM and write to disk when too much RAM is used or when a merge needs
@@ -150,16 +166,88 @@ func (r *ReadersAndUpdates) GetReader(ctx context.Context, ioContext *store.IOCo
 	return r.reader, nil
 }
 
+// GetReadOnlyClone
+// Returns a ref to a clone of the pooled reader that sees the deletions buffered so far.
+// NOTE: you should DecRef the reader when you're done (ie do not call Close).
+func (r *ReadersAndUpdates) GetReadOnlyClone(ctx context.Context, ioContext *store.IOContext) (*SegmentReader, error) {
+	if r.reader == nil {
+		reader, err := r.GetReader(ctx, ioContext)
+		if err != nil {
+			return nil, err
+		}
+		if err := reader.DecRef(); err != nil {
+			return nil, err
+		}
+	}
+
+	// force new liveDocs
+	liveDocs := r.pendingDeletes.GetLiveDocs()
+	if liveDocs == nil {
+		// liveDocs == nil and reader != nil. That can only be if there are no deletes
+		if err := r.reader.IncRef(); err != nil {
+			return nil, err
+		}
+		return r.reader, nil
+	}
+
+	numDocs, err := r.pendingDeletes.NumDocs()
+	if err != nil {
+		return nil, err
+	}
+	return r.reader.New(r.info, liveDocs, r.pendingDeletes.GetHardLiveDocs(), numDocs, true)
+}
+
 func (r *ReadersAndUpdates) Release(sr *SegmentReader) error {
 	return sr.DecRef()
 }
 
 func (r *ReadersAndUpdates) writeLiveDocs(directory store.Directory) (bool, error) {
-	panic("")
+	return r.pendingDeletes.WriteLiveDocs(context.Background(), directory)
 }
 
 func (r *ReadersAndUpdates) writeFieldUpdates(directory store.Directory, numbers *FieldNumbers, supplier int64) (bool, error) {
-	panic("")
+	if len(r.pendingDVUpdates) == 0 {
+		// no updates
+		return false, nil
+	}
+	return false, errors.New("writing doc values updates is not supported yet")
+}
+
+// Drops the reader held by this instance, the caller must have released its own references.
+func (r *ReadersAndUpdates) dropReaders() error {
+	if r.reader == nil {
+		return nil
+	}
+	reader := r.reader
+	r.reader = nil
+	return reader.DecRef()
+}
+
+func (r *ReadersAndUpdates) dropChanges() {
+	// Discard (don't save) changes when we are dropping
+	// the reader; this is used only on the sub-readers
+	// after a successful merge.  If deletes had
+	// accumulated on those sub-readers while the merge
+	// is running, by now we have carried forward those
+	// deletes onto the newly merged segment, so we can
+	// discard them on the sub-readers:
+	r.pendingDeletes.DropChanges()
+	r.dropMergingUpdates()
+}
+
+// Called when we are done with merging, the resolved doc values updates kept while the
+// segment was merging are not needed anymore.
+func (r *ReadersAndUpdates) dropMergingUpdates() {
+	clear(r.mergingDVUpdates)
+	r.isMerging = false
+}
+
+// Marks the segment as merging, doc values updates received from now on are also kept for the
+// merged segment.
+func (r *ReadersAndUpdates) setIsMerging() {
+	// This ensures any newly resolved doc value updates while we are merging are
+	// saved for re-applying after this segment is done merging:
+	r.isMerging = true
 }
 
 func (r *ReadersAndUpdates) IsFullyDeleted() (bool, error) {
@@ -1,5 +1,7 @@
 package index
 
+import "github.com/geange/lucene-go/core/interface/index"
+
 // SubIndex
 // Returns index of the searcher/reader for document n in the array used to construct this searcher/reader.
 func SubIndex(n int, docStarts []int) int {
@@ -23,3 +25,42 @@ func SubIndex(n int, docStarts []int) int {
 	}
 	return hi
 }
+
+// SubIndexV1
+// Returns index of the searcher/reader for document n in the slice of leaves.
+func SubIndexV1(n int, leaves []index.LeafReaderContext) int {
+	// find searcher/reader for doc n:
+	size := len(leaves)
+	lo := 0
+	hi := size - 1
+	for hi >= lo {
+		mid := (lo + hi) >> 1
+		midValue := leaves[mid].DocBase()
+		if n < midValue {
+			hi = mid - 1
+		} else if n > midValue {
+			lo = mid + 1
+		} else {
+			for mid+1 < size && leaves[mid+1].DocBase() == midValue {
+				mid++ // scan to last match
+			}
+			return mid
+		}
+	}
+	return hi
+}
+
+// GetTopLevelContext
+// Walks up the reader tree and return the given context's top level reader context, or in other
+// words the reader tree's root context.
+func GetTopLevelContext(context index.IndexReaderContext) index.IndexReaderContext {
+	for {
+		child, ok := context.(interface {
+			Parent() *CompositeReaderContext
+		})
+		if !ok || child.Parent() == nil {
+			return context
+		}
+		context = child.Parent()
+	}
+}
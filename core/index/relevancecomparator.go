@@ -0,0 +1,134 @@
+package index
+
+import (
+	"cmp"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.FieldComparator = &RelevanceComparator{}
+var _ index.LeafFieldComparator = &RelevanceComparator{}
+
+// RelevanceComparator
+// Sorts by descending relevance. NOTE: if you are sorting only by descending relevance and then
+// secondarily by ascending docID, performance is faster using TopScoreDocCollector directly
+// (which all overloads of IndexSearcher.Search use when no Sort is specified).
+type RelevanceComparator struct {
+	scores   []float64
+	bottom   float64
+	scorer   index.Scorable
+	topValue float64
+}
+
+// NewRelevanceComparator Creates a new comparator based on relevance for numHits.
+func NewRelevanceComparator(numHits int) *RelevanceComparator {
+	return &RelevanceComparator{
+		scores: make([]float64, numHits),
+	}
+}
+
+func (r *RelevanceComparator) Compare(slot1, slot2 int) int {
+	return cmp.Compare(r.scores[slot2], r.scores[slot1])
+}
+
+func (r *RelevanceComparator) SetTopValue(value any) {
+	r.topValue = value.(float64)
+}
+
+func (r *RelevanceComparator) Value(slot int) any {
+	return r.scores[slot]
+}
+
+// CompareValues Override because we sort reverse of natural float order:
+func (r *RelevanceComparator) CompareValues(first, second any) int {
+	// Reversed intentionally because relevance by default
+	// sorts descending:
+	return cmp.Compare(second.(float64), first.(float64))
+}
+
+func (r *RelevanceComparator) SetSingleSort() {
+}
+
+func (r *RelevanceComparator) DisableSkipping() {
+}
+
+func (r *RelevanceComparator) GetLeafComparator(context index.LeafReaderContext) (index.LeafFieldComparator, error) {
+	return r, nil
+}
+
+func (r *RelevanceComparator) SetBottom(slot int) error {
+	r.bottom = r.scores[slot]
+	return nil
+}
+
+func (r *RelevanceComparator) CompareBottom(doc int) (int, error) {
+	score, err := r.scorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	return cmp.Compare(score, r.bottom), nil
+}
+
+func (r *RelevanceComparator) CompareTop(doc int) (int, error) {
+	docValue, err := r.scorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	return cmp.Compare(docValue, r.topValue), nil
+}
+
+func (r *RelevanceComparator) Copy(slot, doc int) error {
+	score, err := r.scorer.Score()
+	if err != nil {
+		return err
+	}
+	r.scores[slot] = score
+	return nil
+}
+
+func (r *RelevanceComparator) SetScorer(scorer index.Scorable) error {
+	// wrap with a scoreCachingScorable so that successive calls to
+	// Score() will not incur score computation over and over again.
+	r.scorer = newScoreCachingScorable(scorer)
+	return nil
+}
+
+func (r *RelevanceComparator) CompetitiveIterator() (types.DocIdSetIterator, error) {
+	return nil, nil
+}
+
+func (r *RelevanceComparator) SetHitsThresholdReached() error {
+	return nil
+}
+
+var _ index.Scorable = &scoreCachingScorable{}
+
+// scoreCachingScorable caches the score of the current document, the comparator asks for the
+// score up to three times per collected hit.
+type scoreCachingScorable struct {
+	index.Scorable
+
+	curDoc   int
+	curScore float64
+}
+
+func newScoreCachingScorable(in index.Scorable) index.Scorable {
+	if _, ok := in.(*scoreCachingScorable); ok {
+		return in
+	}
+	return &scoreCachingScorable{Scorable: in, curDoc: -1}
+}
+
+func (s *scoreCachingScorable) Score() (float64, error) {
+	doc := s.Scorable.DocID()
+	if doc != s.curDoc {
+		score, err := s.Scorable.Score()
+		if err != nil {
+			return 0, err
+		}
+		s.curScore = score
+		s.curDoc = doc
+	}
+	return s.curScore, nil
+}
@@ -38,9 +38,9 @@ type SegmentCoreReaders struct {
 	// Thingy class holding fieldsReader, termVectorsReader,
 	// normsProducer
 
-	fieldsReaderLocal   index.StoredFieldsReader
-	termVectorsLocal    index.TermVectorsReader
-	coreClosedListeners ClosedListener
+	fieldsReaderLocal index.StoredFieldsReader
+	termVectorsLocal  index.TermVectorsReader
+	cacheHelper       *cacheHelper
 }
 
 func NewSegmentCoreReaders(ctx context.Context, dir store.Directory, si index.SegmentCommitInfo, ioContext *store.IOContext) (*SegmentCoreReaders, error) {
@@ -50,7 +50,11 @@ func NewSegmentCoreReaders(ctx context.Context, dir store.Directory, si index.Se
 	// confusing name: if (cfs) it's the cfsdir, otherwise it's the segment's directory.
 	var cfsDir store.Directory
 
-	r := &SegmentCoreReaders{}
+	r := &SegmentCoreReaders{
+		ref:         new(atomic.Int64),
+		cacheHelper: newCacheHelper(),
+	}
+	r.ref.Store(1)
 
 	if si.Info().GetUseCompoundFile() {
 		reader, err := codec.CompoundFormat().GetCompoundReader(ctx, dir, si.Info(), ioContext)
@@ -148,18 +152,21 @@ func (s *SegmentCoreReaders) decRef() error {
 			s.pointsReader,
 		}
 
-		if err := closeAll(closers...); err != nil {
-			return err
-		}
+		err := closeAll(closers...)
+		// the listeners must learn about the close even if a reader failed to close
+		return errors.Join(err, s.cacheHelper.notifyClosedListeners())
 	}
 	return nil
 }
 
+// closeAll closes all non-nil objects, even if closing one of them fails
 func closeAll(objects ...io.Closer) error {
+	var errs []error
 	for _, object := range objects {
-		if err := object.Close(); err != nil {
-			return err
+		if object == nil {
+			continue
 		}
+		errs = append(errs, object.Close())
 	}
-	return nil
+	return errors.Join(errs...)
 }
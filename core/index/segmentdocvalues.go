@@ -2,6 +2,8 @@ package index
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/geange/lucene-go/core/document"
@@ -112,6 +114,7 @@ func NewSegmentDocValuesProducer(si index.SegmentCommitInfo, dir store.Directory
 				p.dvGens = append(p.dvGens, docValuesGen)
 				p.dvProducers = append(p.dvProducers, baseProducer)
 			}
+			p.dvProducersByField[fi.Name()] = baseProducer
 		} else {
 			//assert !dvGens.contains(docValuesGen);
 			// otherwise, producer sees only the one fieldinfo it wrote
@@ -132,36 +135,62 @@ func (s *SegmentDocValuesProducer) GetMergeInstance() index.DocValuesProducer {
 }
 
 func (s *SegmentDocValuesProducer) Close() error {
-	//TODO implement me
-	panic("implement me")
+	return errors.New("unsupported operation: producers are reference counted by SegmentDocValues")
+}
+
+func (s *SegmentDocValuesProducer) getProducer(field *document.FieldInfo) (index.DocValuesProducer, error) {
+	producer, ok := s.dvProducersByField[field.Name()]
+	if !ok {
+		return nil, fmt.Errorf("field %s has no doc values", field.Name())
+	}
+	return producer, nil
 }
 
 func (s *SegmentDocValuesProducer) GetNumeric(ctx context.Context, field *document.FieldInfo) (index.NumericDocValues, error) {
-	//TODO implement me
-	panic("implement me")
+	producer, err := s.getProducer(field)
+	if err != nil {
+		return nil, err
+	}
+	return producer.GetNumeric(ctx, field)
 }
 
 func (s *SegmentDocValuesProducer) GetBinary(ctx context.Context, field *document.FieldInfo) (index.BinaryDocValues, error) {
-	//TODO implement me
-	panic("implement me")
+	producer, err := s.getProducer(field)
+	if err != nil {
+		return nil, err
+	}
+	return producer.GetBinary(ctx, field)
 }
 
 func (s *SegmentDocValuesProducer) GetSorted(ctx context.Context, fieldInfo *document.FieldInfo) (index.SortedDocValues, error) {
-	//TODO implement me
-	panic("implement me")
+	producer, err := s.getProducer(fieldInfo)
+	if err != nil {
+		return nil, err
+	}
+	return producer.GetSorted(ctx, fieldInfo)
 }
 
 func (s *SegmentDocValuesProducer) GetSortedNumeric(ctx context.Context, field *document.FieldInfo) (index.SortedNumericDocValues, error) {
-	//TODO implement me
-	panic("implement me")
+	producer, err := s.getProducer(field)
+	if err != nil {
+		return nil, err
+	}
+	return producer.GetSortedNumeric(ctx, field)
 }
 
 func (s *SegmentDocValuesProducer) GetSortedSet(ctx context.Context, field *document.FieldInfo) (index.SortedSetDocValues, error) {
-	//TODO implement me
-	panic("implement me")
+	producer, err := s.getProducer(field)
+	if err != nil {
+		return nil, err
+	}
+	return producer.GetSortedSet(ctx, field)
 }
 
 func (s *SegmentDocValuesProducer) CheckIntegrity() error {
-	//TODO implement me
-	panic("implement me")
+	for _, producer := range s.dvProducers {
+		if err := producer.CheckIntegrity(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
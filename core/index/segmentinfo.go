@@ -161,6 +161,11 @@ func (s *SegmentInfo) GetMinVersion() *version.Version {
 	return s.minVersion
 }
 
+// SetMinVersion Set the minimum version that contributed documents to this segment.
+func (s *SegmentInfo) SetMinVersion(minVersion *version.Version) {
+	s.minVersion = minVersion
+}
+
 // SetUseCompoundFile
 // Mark whether this segment is stored as a compound file.
 // Params: isCompoundFile – true if this is a compound file; else, false
@@ -237,12 +242,11 @@ func checkFileNames(files []string) error {
 // locates the boundary of the segment name, or -1
 func indexOfSegmentName(filename string) int {
 	// If it is a .del file, there's an '_' after the first character
-	idx := strings.Index(filename[1:], "_")
-	if idx == -1 {
-		// If it's not, strip everything that's before the '.'
-		idx = strings.Index(filename, ".")
+	if idx := strings.Index(filename[1:], "_"); idx != -1 {
+		return idx + 1
 	}
-	return idx
+	// If it's not, strip everything that's before the '.'
+	return strings.Index(filename, ".")
 }
 
 // StripSegmentName
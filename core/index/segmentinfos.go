@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -260,12 +261,17 @@ func (s *SegmentInfos) finishCommit(ctx context.Context, dir store.Directory) (s
 	if err := dir.Rename(ctx, src, dest); err != nil {
 		return "", err
 	}
+	// the commit is only published once the rename itself is durable
+	if err := dir.SyncMetaData(ctx); err != nil {
+		return "", err
+	}
+	s.lastGeneration = s.generation
 	return dest, nil
 }
 
 func (s *SegmentInfos) writeIndexOutput(ctx context.Context, out store.IndexOutput) error {
 	if err := codecUtil.WriteIndexHeader(ctx, out, "segments", VERSION_CURRENT,
-		util.RandomId(), fmt.Sprintf("%d", s.generation)); err != nil {
+		util.RandomId(), strconv.FormatInt(s.generation, 36)); err != nil {
 		return err
 	}
 
@@ -423,7 +429,7 @@ func (s *SegmentInfos) writeDir(ctx context.Context, directory store.Directory)
 	if err := segNOutput.Close(); err != nil {
 		return err
 	}
-	return nil
+	return directory.Sync(map[string]struct{}{segmentFileName: {}})
 }
 
 func (s *SegmentInfos) Replace(other *SegmentInfos) error {
@@ -465,6 +471,49 @@ func (s *SegmentInfos) GetVersion() int64 {
 	return s.version
 }
 
+// Returns true if the given SegmentCommitInfo is one of the segments.
+func (s *SegmentInfos) contains(info index.SegmentCommitInfo) bool {
+	return slices.Contains(s.segments, info)
+}
+
+// applyMergeChanges applies the changes of a merge: the merged away segments are replaced by
+// the new segment at the position of the first of them, or removed when dropSegment is true.
+func (s *SegmentInfos) applyMergeChanges(merge *OneMerge, dropSegment bool) error {
+	if s.indexCreatedVersionMajor >= 7 && merge.info.Info().GetMinVersion() == nil {
+		return errors.New("all segments must record the minVersion for indices created on or after Lucene 7")
+	}
+
+	mergedAway := make(map[index.SegmentCommitInfo]struct{}, len(merge.segments))
+	for _, info := range merge.segments {
+		mergedAway[info] = struct{}{}
+	}
+
+	// build a new slice, clones of this instance may share the old one
+	segments := make([]index.SegmentCommitInfo, 0, len(s.segments))
+	inserted := false
+	for _, info := range s.segments {
+		if _, ok := mergedAway[info]; ok {
+			if !inserted && !dropSegment {
+				segments = append(segments, merge.info)
+				inserted = true
+			}
+			continue
+		}
+		segments = append(segments, info)
+	}
+
+	// Either we found place to insert segment, or, we did
+	// not, but only because all segments we merged became
+	// deleted while we are merging, in which case it should
+	// be the case that the new segment is also all deleted,
+	// we insert it at the beginning if it should not be dropped:
+	if !inserted && !dropSegment {
+		segments = append([]index.SegmentCommitInfo{merge.info}, segments...)
+	}
+	s.segments = segments
+	return nil
+}
+
 func (s *SegmentInfos) Remove(index int) {
 	s.segments[index] = nil
 }
@@ -800,7 +849,7 @@ func GenerationFromSegmentsFileName(fileName string) (int64, error) {
 
 	if strings.HasPrefix(fileName, SEGMENTS) {
 		v := fileName[len(SEGMENTS)+1:]
-		return strconv.ParseInt(v, 10, 64)
+		return strconv.ParseInt(v, 36, 64)
 	}
 
 	return 0, fmt.Errorf("fileName '%s' is not a segments file", fileName)
@@ -1,8 +1,14 @@
 package index
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util/version"
 )
 
 // The SegmentMerger class combines two or more Segments, represented by an IndexReader,
@@ -10,6 +16,7 @@ import (
 type SegmentMerger struct {
 	directory         store.Directory
 	codec             index.Codec
+	ioCtx             *store.IOContext
 	mergeState        *MergeState
 	fieldInfosBuilder *FieldInfosBuilder
 }
@@ -17,16 +24,226 @@ type SegmentMerger struct {
 func NewSegmentMerger(readers []index.CodecReader, segmentInfo *SegmentInfo, dir store.Directory,
 	fieldNumbers *FieldNumbers, ioCtx *store.IOContext) (*SegmentMerger, error) {
 
-	//if ioCtx.Type != store.CONTEXT_MERGE {
-	//	return nil, errors.New("context type should be MERGE")
-	//}
-	//
-	//mergeState := store.NewMer
-	// TODO: fix it
-	panic("")
+	if ioCtx.Type != store.CONTEXT_MERGE {
+		return nil, errors.New("context type should be MERGE")
+	}
+
+	minVersion := version.Last
+	for _, reader := range readers {
+		leafMinVersion := reader.GetMetaData().GetMinVersion()
+		if leafMinVersion == nil {
+			minVersion = nil
+			break
+		}
+		if minVersion.OnOrAfter(leafMinVersion) {
+			minVersion = leafMinVersion
+		}
+	}
+	segmentInfo.SetMinVersion(minVersion)
+
+	mergeState, err := NewMergeState(readers, segmentInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SegmentMerger{
+		directory:         dir,
+		codec:             segmentInfo.GetCodec(),
+		ioCtx:             ioCtx,
+		mergeState:        mergeState,
+		fieldInfosBuilder: NewFieldInfosBuilder(fieldNumbers),
+	}, nil
 }
 
+// ShouldMerge True if any merging should happen
 func (s *SegmentMerger) ShouldMerge() bool {
 	maxDoc, _ := s.mergeState.SegmentInfo.MaxDoc()
 	return maxDoc > 0
 }
+
+// Merge Merges the readers into the directory passed to the constructor
+// Returns: The number of documents that were merged
+func (s *SegmentMerger) Merge(ctx context.Context) (*MergeState, error) {
+	if !s.ShouldMerge() {
+		return nil, errors.New("merge would result in 0 document segment")
+	}
+
+	if err := s.mergeFieldInfos(); err != nil {
+		return nil, err
+	}
+
+	numMerged, err := s.mergeFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNumMerged(numMerged, "stored field"); err != nil {
+		return nil, err
+	}
+
+	mergeFieldInfos := s.mergeState.MergeFieldInfos
+	segmentWriteState := index.NewSegmentWriteState(s.directory, s.mergeState.SegmentInfo,
+		mergeFieldInfos, nil, s.ioCtx)
+
+	if mergeFieldInfos.HasNorms() {
+		if err := s.mergeNorms(ctx, segmentWriteState); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.mergeTerms(ctx, segmentWriteState); err != nil {
+		return nil, err
+	}
+
+	if mergeFieldInfos.HasDocValues() {
+		if err := s.mergeDocValues(ctx, segmentWriteState); err != nil {
+			return nil, err
+		}
+	}
+
+	if mergeFieldInfos.HasPointValues() {
+		if err := s.mergePoints(ctx, segmentWriteState); err != nil {
+			return nil, err
+		}
+	}
+
+	if mergeFieldInfos.HasVectors() {
+		numMerged, err := s.mergeVectors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkNumMerged(numMerged, "term vector"); err != nil {
+			return nil, err
+		}
+	}
+
+	// write the merged infos
+	if err := s.codec.FieldInfosFormat().Write(ctx, s.directory, s.mergeState.SegmentInfo, "",
+		mergeFieldInfos, s.ioCtx); err != nil {
+		return nil, err
+	}
+
+	return s.mergeState, nil
+}
+
+func (s *SegmentMerger) checkNumMerged(numMerged int, what string) error {
+	maxDoc, err := s.mergeState.SegmentInfo.MaxDoc()
+	if err != nil {
+		return err
+	}
+	if numMerged != maxDoc {
+		return fmt.Errorf("%s merge: numMerged=%d vs mergeState.segmentInfo.maxDoc()=%d",
+			what, numMerged, maxDoc)
+	}
+	return nil
+}
+
+func (s *SegmentMerger) mergeFieldInfos() error {
+	for _, readerFieldInfos := range s.mergeState.FieldInfos {
+		for _, fi := range readerFieldInfos.List() {
+			if _, err := s.fieldInfosBuilder.AddFieldInfo(fi); err != nil {
+				return err
+			}
+		}
+	}
+	s.mergeState.MergeFieldInfos = s.fieldInfosBuilder.Finish()
+	return nil
+}
+
+// mergeFields Merge stored fields from each of the segments into the new one.
+// Returns: The number of documents in all of the readers
+func (s *SegmentMerger) mergeFields(ctx context.Context) (int, error) {
+	fieldsWriter, err := s.codec.StoredFieldsFormat().FieldsWriter(ctx, s.directory,
+		s.mergeState.SegmentInfo, s.ioCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	var numMerged int
+	if merger, ok := fieldsWriter.(storedFieldsMerger); ok {
+		numMerged, err = merger.Merge(ctx, s.mergeState)
+	} else {
+		numMerged, err = MergeStoredFields(ctx, fieldsWriter, s.mergeState)
+	}
+	return numMerged, closeAfter(err, fieldsWriter)
+}
+
+// storedFieldsMerger is implemented by stored fields writers with a faster merge than
+// MergeStoredFields, like copying compressed chunks
+type storedFieldsMerger interface {
+	Merge(ctx context.Context, mergeState *MergeState) (int, error)
+}
+
+// mergeVectors Merge the TermVectors from each of the segments into the new one.
+func (s *SegmentMerger) mergeVectors(ctx context.Context) (int, error) {
+	termVectorsWriter, err := s.codec.TermVectorsFormat().VectorsWriter(ctx, s.directory,
+		s.mergeState.SegmentInfo, s.ioCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	numMerged, err := MergeTermVectors(ctx, termVectorsWriter, s.mergeState)
+	return numMerged, closeAfter(err, termVectorsWriter)
+}
+
+func (s *SegmentMerger) mergeNorms(ctx context.Context, segmentWriteState *index.SegmentWriteState) error {
+	consumer, err := s.codec.NormsFormat().NormsConsumer(ctx, segmentWriteState)
+	if err != nil {
+		return err
+	}
+	return closeAfter(consumer.Merge(ctx, s.mergeState), consumer)
+}
+
+func (s *SegmentMerger) mergeTerms(ctx context.Context, segmentWriteState *index.SegmentWriteState) error {
+	var norms index.NormsProducer
+	if s.mergeState.MergeFieldInfos.HasNorms() {
+		// the postings writer reads the norms we just merged to compute impacts
+		segmentReadState := index.NewSegmentReadState(s.directory, s.mergeState.SegmentInfo,
+			s.mergeState.MergeFieldInfos, store.READ, segmentWriteState.SegmentSuffix)
+		producer, err := s.codec.NormsFormat().NormsProducer(ctx, segmentReadState)
+		if err != nil {
+			return err
+		}
+		defer producer.Close()
+		norms = producer
+	}
+
+	consumer, err := s.codec.PostingsFormat().FieldsConsumer(ctx, segmentWriteState)
+	if err != nil {
+		return err
+	}
+	return closeAfter(MergeFromReaders(ctx, consumer, s.mergeState, norms), consumer)
+}
+
+func (s *SegmentMerger) mergeDocValues(ctx context.Context, segmentWriteState *index.SegmentWriteState) error {
+	consumer, err := s.codec.DocValuesFormat().FieldsConsumer(ctx, segmentWriteState)
+	if err != nil {
+		return err
+	}
+	return closeAfter(MergeDocValues(ctx, consumer, s.mergeState), consumer)
+}
+
+// pointsMerger is implemented by points writers which embed BasePointsWriter
+type pointsMerger interface {
+	Merge(ctx context.Context, mergeState *MergeState) error
+}
+
+func (s *SegmentMerger) mergePoints(ctx context.Context, segmentWriteState *index.SegmentWriteState) error {
+	writer, err := s.codec.PointsFormat().FieldsWriter(ctx, segmentWriteState)
+	if err != nil {
+		return err
+	}
+
+	merger, ok := writer.(pointsMerger)
+	if !ok {
+		return closeAfter(fmt.Errorf("points writer %T does not support merging", writer), writer)
+	}
+	return closeAfter(merger.Merge(ctx, s.mergeState), writer)
+}
+
+// closeAfter closes c and returns err, or the error of Close if err is nil
+func closeAfter(err error, c io.Closer) error {
+	if closeErr := c.Close(); closeErr != nil && err == nil {
+		return closeErr
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -45,6 +46,8 @@ type SegmentReader struct {
 	docValuesProducer index.DocValuesProducer
 
 	fieldInfos index.FieldInfos
+
+	readerCacheHelper *cacheHelper
 }
 
 // NewSegmentReader
@@ -69,6 +72,7 @@ func NewSegmentReader(ctx context.Context, si index.SegmentCommitInfo,
 		isNRT:             false, // We pull liveDocs/DV updates from disk:
 		docValuesProducer: nil,
 		fieldInfos:        nil,
+		readerCacheHelper: newCacheHelper(),
 	}
 
 	reader.BaseCodecReader = NewBaseCodecReader(reader)
@@ -136,8 +140,11 @@ func (s *SegmentReader) New(si index.SegmentCommitInfo, liveDocs, hardLiveDocs u
 		isNRT:             isNRT,
 		docValuesProducer: nil,
 		fieldInfos:        nil,
+		readerCacheHelper: newCacheHelper(),
 	}
 
+	reader.BaseCodecReader = NewBaseCodecReader(reader)
+
 	if err := reader.core.incRef(); err != nil {
 		return nil, err
 	}
@@ -179,6 +186,11 @@ func (s *SegmentReader) MaxDoc() int {
 }
 
 func (s *SegmentReader) DoClose() error {
+	err := s.releaseResources()
+	return errors.Join(err, s.readerCacheHelper.notifyClosedListeners())
+}
+
+func (s *SegmentReader) releaseResources() error {
 	if err := s.core.decRef(); err != nil {
 		return err
 	}
@@ -194,8 +206,14 @@ func (s *SegmentReader) DoClose() error {
 }
 
 func (s *SegmentReader) GetReaderCacheHelper() index.CacheHelper {
-	//TODO implement me
-	panic("implement me")
+	return s.readerCacheHelper
+}
+
+// GetCoreCacheHelper
+// The core cache helper is shared by all the readers of the segment, its listeners are notified
+// once the last of them is closed.
+func (s *SegmentReader) GetCoreCacheHelper() index.CacheHelper {
+	return s.core.cacheHelper
 }
 
 func (s *SegmentReader) GetFieldInfos() index.FieldInfos {
@@ -237,6 +255,18 @@ func (s *SegmentReader) GetPointsReader() index.PointsReader {
 	return s.core.pointsReader
 }
 
+// GetSegmentName
+// Return the name of the segment this reader is reading.
+func (s *SegmentReader) GetSegmentName() string {
+	return s.si.Info().Name()
+}
+
+// GetSegmentInfo
+// Return the SegmentInfoPerCommit of the segment this reader is reading.
+func (s *SegmentReader) GetSegmentInfo() index.SegmentCommitInfo {
+	return s.si
+}
+
 // GetOriginalSegmentInfo
 // Returns the original SegmentInfo passed to the segment reader on creation time.
 // getSegmentInfo() returns a clone of this instance.
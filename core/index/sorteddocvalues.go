@@ -2,10 +2,17 @@ package index
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/document"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/geange/lucene-go/core/util/bytesref"
+	"github.com/geange/lucene-go/core/util/packed"
 )
 
 type SortedDocValuesDefaultConfig struct {
@@ -74,15 +81,244 @@ func (r *BaseSortedDocValues) Intersect(automaton *automaton.CompiledAutomaton)
 
 var _ DocValuesWriter = &SortedDocValuesWriter{}
 
+// SortedDocValuesWriter
+// Buffers up pending byte[] per doc, deref and sorting via int ord, then flushes when segment flushes.
 type SortedDocValuesWriter struct {
+	hash          *bytesref.BytesHash
+	pending       *packed.PackedLongValuesBuilder
+	docsWithField *DocsWithFieldSet
+	fieldInfo     *document.FieldInfo
+	lastDocID     int
+
+	finalOrds         *packed.PackedLongValues
+	finalSortedValues []int
+	finalOrdMap       []int
+}
+
+func NewSortedDocValuesWriter(fieldInfo *document.FieldInfo, pool *bytesref.BlockPool) (*SortedDocValuesWriter, error) {
+	hash, err := bytesref.NewBytesHash(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SortedDocValuesWriter{
+		hash:          hash,
+		pending:       packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		docsWithField: NewDocsWithFieldSet(),
+		fieldInfo:     fieldInfo,
+		lastDocID:     -1,
+	}, nil
+}
+
+func (s *SortedDocValuesWriter) AddValue(docID int, value []byte) error {
+	if docID <= s.lastDocID {
+		return fmt.Errorf("DocValuesField \"%s\" appears more than once in this document (only one value is allowed per field)",
+			s.fieldInfo.Name())
+	}
+	if len(value) > bytesref.BYTE_BLOCK_SIZE-2 {
+		return fmt.Errorf("DocValuesField \"%s\" is too large, must be <= %d",
+			s.fieldInfo.Name(), bytesref.BYTE_BLOCK_SIZE-2)
+	}
+
+	termID, err := s.hash.Add(value)
+	if err != nil {
+		return err
+	}
+	if termID < 0 {
+		termID = -termID - 1
+	}
+	if err := s.pending.Add(int64(termID)); err != nil {
+		return err
+	}
+	if err := s.docsWithField.Add(docID); err != nil {
+		return err
+	}
+	s.lastDocID = docID
+	return nil
+}
+
+// finish sorts the terms and freezes the pending ords, no value can be added afterwards
+func (s *SortedDocValuesWriter) finish() error {
+	if s.finalOrds != nil {
+		return nil
+	}
+
+	valueCount := s.hash.Size()
+	finalOrds, err := s.pending.Build()
+	if err != nil {
+		return err
+	}
+	s.finalOrds = finalOrds
+	s.finalSortedValues = s.hash.Sort()[:valueCount]
+	s.finalOrdMap = make([]int, valueCount)
+	for ord, termID := range s.finalSortedValues {
+		s.finalOrdMap[termID] = ord
+	}
+	return nil
+}
+
+func (s *SortedDocValuesWriter) newBufferedSortedDocValues() *BufferedSortedDocValues {
+	iterator, _ := s.docsWithField.Iterator()
+	return NewBufferedSortedDocValues(s.hash, s.finalOrds, s.finalSortedValues, s.finalOrdMap, iterator)
 }
 
 func (s *SortedDocValuesWriter) Flush(state *index.SegmentWriteState, sortMap index.DocMap, consumer index.DocValuesConsumer) error {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return err
+	}
+
+	var sorted [][]int64
+	if sortMap != nil {
+		maxDoc, err := state.SegmentInfo.MaxDoc()
+		if err != nil {
+			return err
+		}
+		values := s.newBufferedSortedDocValues()
+		sorted, err = sortMultiValues(maxDoc, sortMap, values, func() ([]int64, error) {
+			ord, err := values.OrdValue()
+			return []int64{int64(ord)}, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return consumer.AddSortedField(context.TODO(), s.fieldInfo, &EmptyDocValuesProducer{
+		FnGetSorted: func(ctx context.Context, field *document.FieldInfo) (index.SortedDocValues, error) {
+			values := s.newBufferedSortedDocValues()
+			if sorted == nil {
+				return values, nil
+			}
+			return newSortingSortedDocValues(sorted, values), nil
+		},
+	})
 }
 
 func (s *SortedDocValuesWriter) GetDocValues() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return nil
+	}
+	return s.newBufferedSortedDocValues()
+}
+
+var _ index.SortedDocValues = &BufferedSortedDocValues{}
+
+type BufferedSortedDocValues struct {
+	*BaseSortedDocValues
+
+	hash          *bytesref.BytesHash
+	sortedValues  []int
+	ordMap        []int
+	iter          packed.PackedLongValuesIterator
+	docsWithField types.DocIdSetIterator
+	ord           int
+}
+
+func NewBufferedSortedDocValues(hash *bytesref.BytesHash, docToOrd *packed.PackedLongValues,
+	sortedValues, ordMap []int, docsWithField types.DocIdSetIterator) *BufferedSortedDocValues {
+
+	values := &BufferedSortedDocValues{
+		hash:          hash,
+		sortedValues:  sortedValues,
+		ordMap:        ordMap,
+		iter:          docToOrd.Iterator(),
+		docsWithField: docsWithField,
+		ord:           -1,
+	}
+	values.BaseSortedDocValues = NewBaseSortedDocValues(&SortedDocValuesDefaultConfig{
+		OrdValue:      values.OrdValue,
+		LookupOrd:     values.LookupOrd,
+		GetValueCount: values.GetValueCount,
+	})
+	return values
+}
+
+func (b *BufferedSortedDocValues) DocID() int {
+	return b.docsWithField.DocID()
+}
+
+func (b *BufferedSortedDocValues) NextDoc(ctx context.Context) (int, error) {
+	docID, err := b.docsWithField.NextDoc(ctx)
+	if err != nil {
+		return 0, err
+	}
+	termID, err := b.iter.Next()
+	if err != nil {
+		return 0, err
+	}
+	b.ord = b.ordMap[termID]
+	return docID, nil
+}
+
+func (b *BufferedSortedDocValues) Advance(ctx context.Context, target int) (int, error) {
+	return 0, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedDocValues) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, b, target)
+}
+
+func (b *BufferedSortedDocValues) Cost() int64 {
+	return b.docsWithField.Cost()
+}
+
+func (b *BufferedSortedDocValues) AdvanceExact(target int) (bool, error) {
+	return false, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedDocValues) OrdValue() (int, error) {
+	return b.ord, nil
+}
+
+func (b *BufferedSortedDocValues) LookupOrd(ord int) ([]byte, error) {
+	if ord < 0 || ord >= len(b.sortedValues) {
+		return nil, fmt.Errorf("ord must be 0 .. %d; got %d", len(b.sortedValues)-1, ord)
+	}
+	return b.hash.Get(b.sortedValues[ord]), nil
+}
+
+func (b *BufferedSortedDocValues) GetValueCount() int {
+	return len(b.sortedValues)
+}
+
+func (b *BufferedSortedDocValues) TermsEnum() (index.TermsEnum, error) {
+	return NewSortedDocValuesTermsEnum(b), nil
+}
+
+var _ index.SortedDocValues = &SortingSortedDocValues{}
+
+type SortingSortedDocValues struct {
+	*sortingMultiValues
+	*BaseSortedDocValues
+
+	in index.SortedDocValues
+}
+
+func newSortingSortedDocValues(ords [][]int64, in index.SortedDocValues) *SortingSortedDocValues {
+	values := &SortingSortedDocValues{
+		sortingMultiValues: newSortingMultiValues(ords),
+		in:                 in,
+	}
+	values.BaseSortedDocValues = NewBaseSortedDocValues(&SortedDocValuesDefaultConfig{
+		OrdValue:      values.OrdValue,
+		LookupOrd:     in.LookupOrd,
+		GetValueCount: in.GetValueCount,
+	})
+	return values
+}
+
+func (s *SortingSortedDocValues) OrdValue() (int, error) {
+	return int(s.current()[0]), nil
+}
+
+func (s *SortingSortedDocValues) LookupOrd(ord int) ([]byte, error) {
+	return s.in.LookupOrd(ord)
+}
+
+func (s *SortingSortedDocValues) GetValueCount() int {
+	return s.in.GetValueCount()
+}
+
+func (s *SortingSortedDocValues) TermsEnum() (index.TermsEnum, error) {
+	return NewSortedDocValuesTermsEnum(s), nil
 }
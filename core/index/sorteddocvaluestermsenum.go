@@ -2,80 +2,134 @@ package index
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/attribute"
 )
 
+var _ index.TermsEnum = &SortedDocValuesTermsEnum{}
+
 // SortedDocValuesTermsEnum Creates a new TermsEnum over the provided values
 type SortedDocValuesTermsEnum struct {
+	values     index.SortedDocValues
+	currentOrd int
+	term       []byte
+	attrs      *attribute.Source
 }
 
 func NewSortedDocValuesTermsEnum(values index.SortedDocValues) *SortedDocValuesTermsEnum {
-	return &SortedDocValuesTermsEnum{}
+	return &SortedDocValuesTermsEnum{
+		values:     values,
+		currentOrd: -1,
+	}
 }
 
 func (s *SortedDocValuesTermsEnum) Next(context.Context) ([]byte, error) {
-	//TODO implement me
-	panic("implement me")
+	s.currentOrd++
+	if s.currentOrd >= s.values.GetValueCount() {
+		return nil, io.EOF
+	}
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return nil, err
+	}
+	s.term = term
+	return term, nil
 }
 
 func (s *SortedDocValuesTermsEnum) Attributes() *attribute.Source {
-	//TODO implement me
-	panic("implement me")
+	if s.attrs == nil {
+		s.attrs = attribute.NewSource()
+	}
+	return s.attrs
 }
 
 func (s *SortedDocValuesTermsEnum) SeekExact(ctx context.Context, text []byte) (bool, error) {
-	//TODO implement me
-	panic("implement me")
+	ord, err := s.values.LookupTerm(text)
+	if err != nil {
+		return false, err
+	}
+	if ord < 0 {
+		return false, nil
+	}
+	s.currentOrd = ord
+	s.term = append(s.term[:0], text...)
+	return true, nil
 }
 
 func (s *SortedDocValuesTermsEnum) SeekCeil(ctx context.Context, text []byte) (index.SeekStatus, error) {
-	//TODO implement me
-	panic("implement me")
+	ord, err := s.values.LookupTerm(text)
+	if err != nil {
+		return 0, err
+	}
+	if ord >= 0 {
+		s.currentOrd = ord
+		s.term = append(s.term[:0], text...)
+		return index.SEEK_STATUS_FOUND, nil
+	}
+
+	s.currentOrd = -ord - 1
+	if s.currentOrd == s.values.GetValueCount() {
+		return index.SEEK_STATUS_END, nil
+	}
+	// TODO: hmm can we avoid this "extra" lookup?:
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return 0, err
+	}
+	s.term = term
+	return index.SEEK_STATUS_NOT_FOUND, nil
 }
 
 func (s *SortedDocValuesTermsEnum) SeekExactByOrd(ctx context.Context, ord int64) error {
-	//TODO implement me
-	panic("implement me")
+	if ord < 0 || ord >= int64(s.values.GetValueCount()) {
+		return errors.New("ord out of bounds")
+	}
+	s.currentOrd = int(ord)
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return err
+	}
+	s.term = term
+	return nil
 }
 
 func (s *SortedDocValuesTermsEnum) SeekExactExpert(ctx context.Context, term []byte, state index.TermState) error {
-	//TODO implement me
-	panic("implement me")
+	ordState, ok := state.(*OrdTermState)
+	if !ok {
+		return errors.New("state is not an OrdTermState")
+	}
+	return s.SeekExactByOrd(ctx, ordState.Ord)
 }
 
 func (s *SortedDocValuesTermsEnum) Term() ([]byte, error) {
-	//TODO implement me
-	panic("implement me")
+	return s.term, nil
 }
 
 func (s *SortedDocValuesTermsEnum) Ord() (int64, error) {
-	//TODO implement me
-	panic("implement me")
+	return int64(s.currentOrd), nil
 }
 
 func (s *SortedDocValuesTermsEnum) DocFreq() (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (s *SortedDocValuesTermsEnum) TotalTermFreq() (int64, error) {
-	//TODO implement me
-	panic("implement me")
+	return 0, ErrUnsupportedOperation
 }
 
 func (s *SortedDocValuesTermsEnum) Postings(reuse index.PostingsEnum, flags int) (index.PostingsEnum, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, ErrUnsupportedOperation
 }
 
 func (s *SortedDocValuesTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
-	//TODO implement me
-	panic("implement me")
+	return nil, ErrUnsupportedOperation
 }
 
 func (s *SortedDocValuesTermsEnum) TermState() (index.TermState, error) {
-	//TODO implement me
-	panic("implement me")
+	state := NewOrdTermState()
+	state.Ord = int64(s.currentOrd)
+	return state, nil
 }
@@ -1,21 +1,336 @@
 package index
 
 import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/packed"
 )
 
 var _ DocValuesWriter = &SortedNumericDocValuesWriter{}
 
+// SortedNumericDocValuesWriter
+// Buffers up pending long[] per doc, sorts, then flushes when segment flushes.
 type SortedNumericDocValuesWriter struct {
+	pending       *packed.PackedLongValuesBuilder // stream of all values
+	pendingCounts *packed.PackedLongValuesBuilder // count of values per doc
+	docsWithField *DocsWithFieldSet
+	fieldInfo     *document.FieldInfo
+	currentDoc    int
+	currentValues []int64
+
+	finalValues      *packed.PackedLongValues
+	finalValuesCount *packed.PackedLongValues
+}
+
+func NewSortedNumericDocValuesWriter(fieldInfo *document.FieldInfo) *SortedNumericDocValuesWriter {
+	return &SortedNumericDocValuesWriter{
+		pending:       packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		pendingCounts: packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		docsWithField: NewDocsWithFieldSet(),
+		fieldInfo:     fieldInfo,
+		currentDoc:    -1,
+		currentValues: make([]int64, 0, 8),
+	}
+}
+
+func (s *SortedNumericDocValuesWriter) AddValue(docID int, value int64) error {
+	if docID < s.currentDoc {
+		return errors.New("out of order doc ids")
+	}
+	if docID != s.currentDoc {
+		if err := s.finishCurrentDoc(); err != nil {
+			return err
+		}
+		s.currentDoc = docID
+	}
+	s.currentValues = append(s.currentValues, value)
+	return nil
+}
+
+// finalize currentDoc: this sorts the values in the current doc
+func (s *SortedNumericDocValuesWriter) finishCurrentDoc() error {
+	if s.currentDoc == -1 {
+		return nil
+	}
+
+	slices.Sort(s.currentValues)
+	for _, v := range s.currentValues {
+		if err := s.pending.Add(v); err != nil {
+			return err
+		}
+	}
+	// record the number of values for this doc
+	if err := s.pendingCounts.Add(int64(len(s.currentValues))); err != nil {
+		return err
+	}
+	if err := s.docsWithField.Add(s.currentDoc); err != nil {
+		return err
+	}
+	s.currentValues = s.currentValues[:0]
+	return nil
+}
+
+func (s *SortedNumericDocValuesWriter) finish() error {
+	if s.finalValues != nil {
+		return nil
+	}
+
+	if err := s.finishCurrentDoc(); err != nil {
+		return err
+	}
+	finalValues, err := s.pending.Build()
+	if err != nil {
+		return err
+	}
+	finalValuesCount, err := s.pendingCounts.Build()
+	if err != nil {
+		return err
+	}
+	s.finalValues, s.finalValuesCount = finalValues, finalValuesCount
+	return nil
+}
+
+func (s *SortedNumericDocValuesWriter) newBufferedSortedNumericDocValues() *BufferedSortedNumericDocValues {
+	iterator, _ := s.docsWithField.Iterator()
+	return NewBufferedSortedNumericDocValues(s.finalValues, s.finalValuesCount, iterator)
 }
 
 func (s *SortedNumericDocValuesWriter) Flush(state *index.SegmentWriteState, sortMap index.DocMap, consumer index.DocValuesConsumer) error {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return err
+	}
+
+	var sorted [][]int64
+	if sortMap != nil {
+		maxDoc, err := state.SegmentInfo.MaxDoc()
+		if err != nil {
+			return err
+		}
+		values := s.newBufferedSortedNumericDocValues()
+		sorted, err = sortMultiValues(maxDoc, sortMap, values, func() ([]int64, error) {
+			docValues := make([]int64, values.DocValueCount())
+			for i := range docValues {
+				v, err := values.NextValue()
+				if err != nil {
+					return nil, err
+				}
+				docValues[i] = v
+			}
+			return docValues, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return consumer.AddSortedNumericField(context.TODO(), s.fieldInfo, &EmptyDocValuesProducer{
+		FnGetSortedNumeric: func(ctx context.Context, field *document.FieldInfo) (index.SortedNumericDocValues, error) {
+			if sorted == nil {
+				return s.newBufferedSortedNumericDocValues(), nil
+			}
+			return &SortingSortedNumericDocValues{sortingMultiValues: newSortingMultiValues(sorted)}, nil
+		},
+	})
 }
 
 func (s *SortedNumericDocValuesWriter) GetDocValues() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return nil
+	}
+	return s.newBufferedSortedNumericDocValues()
+}
+
+var _ index.SortedNumericDocValues = &BufferedSortedNumericDocValues{}
+
+type BufferedSortedNumericDocValues struct {
+	valuesIter     packed.PackedLongValuesIterator
+	valueCountIter packed.PackedLongValuesIterator
+	docsWithField  types.DocIdSetIterator
+	valueCount     int
+	valueUpto      int
+}
+
+func NewBufferedSortedNumericDocValues(values, valueCounts *packed.PackedLongValues,
+	docsWithField types.DocIdSetIterator) *BufferedSortedNumericDocValues {
+
+	return &BufferedSortedNumericDocValues{
+		valuesIter:     values.Iterator(),
+		valueCountIter: valueCounts.Iterator(),
+		docsWithField:  docsWithField,
+	}
+}
+
+func (b *BufferedSortedNumericDocValues) DocID() int {
+	return b.docsWithField.DocID()
+}
+
+func (b *BufferedSortedNumericDocValues) NextDoc(ctx context.Context) (int, error) {
+	// skip the values of the current doc that have not been consumed
+	for ; b.valueUpto < b.valueCount; b.valueUpto++ {
+		if _, err := b.valuesIter.Next(); err != nil {
+			return 0, err
+		}
+	}
+
+	docID, err := b.docsWithField.NextDoc(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count, err := b.valueCountIter.Next()
+	if err != nil {
+		return 0, err
+	}
+	b.valueCount = int(count)
+	b.valueUpto = 0
+	return docID, nil
+}
+
+func (b *BufferedSortedNumericDocValues) Advance(ctx context.Context, target int) (int, error) {
+	return 0, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedNumericDocValues) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, b, target)
+}
+
+func (b *BufferedSortedNumericDocValues) Cost() int64 {
+	return b.docsWithField.Cost()
+}
+
+func (b *BufferedSortedNumericDocValues) AdvanceExact(target int) (bool, error) {
+	return false, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedNumericDocValues) NextValue() (int64, error) {
+	if b.valueUpto >= b.valueCount {
+		return 0, io.EOF
+	}
+	v, err := b.valuesIter.Next()
+	if err != nil {
+		return 0, err
+	}
+	b.valueUpto++
+	return int64(v), nil
+}
+
+func (b *BufferedSortedNumericDocValues) DocValueCount() int {
+	return b.valueCount
+}
+
+var _ index.SortedNumericDocValues = &SortingSortedNumericDocValues{}
+
+type SortingSortedNumericDocValues struct {
+	*sortingMultiValues
+}
+
+func (s *SortingSortedNumericDocValues) NextValue() (int64, error) {
+	return s.next()
+}
+
+func (s *SortingSortedNumericDocValues) DocValueCount() int {
+	return len(s.current())
+}
+
+// sortMultiValues collects the values of every doc of the iterator, indexed by the doc IDs of the
+// sorted segment.
+func sortMultiValues(maxDoc int, sortMap index.DocMap, it types.DocIdSetIterator,
+	docValues func() ([]int64, error)) ([][]int64, error) {
+
+	values := make([][]int64, maxDoc)
+	for {
+		docID, err := it.NextDoc(context.Background())
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if docID == types.NO_MORE_DOCS {
+			break
+		}
+
+		v, err := docValues()
+		if err != nil {
+			return nil, err
+		}
+		values[sortMap.OldToNew(docID)] = v
+	}
+	return values, nil
+}
+
+// sortingMultiValues iterates over values collected by sortMultiValues, docs that have no
+// value are skipped.
+type sortingMultiValues struct {
+	values [][]int64
+	docID  int
+	upto   int
+	cost   int64
+}
+
+func newSortingMultiValues(values [][]int64) *sortingMultiValues {
+	cost := int64(0)
+	for _, v := range values {
+		if v != nil {
+			cost++
+		}
+	}
+	return &sortingMultiValues{
+		values: values,
+		docID:  -1,
+		cost:   cost,
+	}
+}
+
+func (s *sortingMultiValues) DocID() int {
+	return s.docID
+}
+
+func (s *sortingMultiValues) NextDoc(ctx context.Context) (int, error) {
+	return s.Advance(ctx, s.docID+1)
+}
+
+func (s *sortingMultiValues) Advance(ctx context.Context, target int) (int, error) {
+	for docID := target; docID < len(s.values); docID++ {
+		if s.values[docID] != nil {
+			s.docID = docID
+			s.upto = 0
+			return docID, nil
+		}
+	}
+	s.docID = types.NO_MORE_DOCS
+	return s.docID, io.EOF
+}
+
+func (s *sortingMultiValues) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, s, target)
+}
+
+func (s *sortingMultiValues) Cost() int64 {
+	return s.cost
+}
+
+func (s *sortingMultiValues) AdvanceExact(target int) (bool, error) {
+	s.docID = target
+	s.upto = 0
+	return s.values[target] != nil, nil
+}
+
+func (s *sortingMultiValues) current() []int64 {
+	return s.values[s.docID]
+}
+
+func (s *sortingMultiValues) next() (int64, error) {
+	values := s.current()
+	if s.upto >= len(values) {
+		return 0, io.EOF
+	}
+	v := values[s.upto]
+	s.upto++
+	return v, nil
 }
@@ -0,0 +1,157 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/attribute"
+)
+
+var _ index.TermsEnum = &SortedSetDocValuesTermsEnum{}
+
+// SortedSetDocValuesTermsEnum Implements a TermsEnum wrapping a provided SortedSetDocValues.
+type SortedSetDocValuesTermsEnum struct {
+	values     index.SortedSetDocValues
+	currentOrd int64
+	term       []byte
+	attrs      *attribute.Source
+}
+
+func NewSortedSetDocValuesTermsEnum(values index.SortedSetDocValues) *SortedSetDocValuesTermsEnum {
+	return &SortedSetDocValuesTermsEnum{
+		values:     values,
+		currentOrd: -1,
+	}
+}
+
+func (s *SortedSetDocValuesTermsEnum) Next(context.Context) ([]byte, error) {
+	s.currentOrd++
+	if s.currentOrd >= s.values.GetValueCount() {
+		return nil, io.EOF
+	}
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return nil, err
+	}
+	s.term = term
+	return term, nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) Attributes() *attribute.Source {
+	if s.attrs == nil {
+		s.attrs = attribute.NewSource()
+	}
+	return s.attrs
+}
+
+func (s *SortedSetDocValuesTermsEnum) SeekExact(ctx context.Context, text []byte) (bool, error) {
+	ord, err := s.lookupTerm(text)
+	if err != nil {
+		return false, err
+	}
+	if ord < 0 {
+		return false, nil
+	}
+	s.currentOrd = ord
+	s.term = append(s.term[:0], text...)
+	return true, nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) SeekCeil(ctx context.Context, text []byte) (index.SeekStatus, error) {
+	ord, err := s.lookupTerm(text)
+	if err != nil {
+		return 0, err
+	}
+	if ord >= 0 {
+		s.currentOrd = ord
+		s.term = append(s.term[:0], text...)
+		return index.SEEK_STATUS_FOUND, nil
+	}
+
+	s.currentOrd = -ord - 1
+	if s.currentOrd == s.values.GetValueCount() {
+		return index.SEEK_STATUS_END, nil
+	}
+	// TODO: hmm can we avoid this "extra" lookup?:
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return 0, err
+	}
+	s.term = term
+	return index.SEEK_STATUS_NOT_FOUND, nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) SeekExactByOrd(ctx context.Context, ord int64) error {
+	if ord < 0 || ord >= s.values.GetValueCount() {
+		return errors.New("ord out of bounds")
+	}
+	s.currentOrd = ord
+	term, err := s.values.LookupOrd(s.currentOrd)
+	if err != nil {
+		return err
+	}
+	s.term = term
+	return nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) SeekExactExpert(ctx context.Context, term []byte, state index.TermState) error {
+	ordState, ok := state.(*OrdTermState)
+	if !ok {
+		return errors.New("state is not an OrdTermState")
+	}
+	return s.SeekExactByOrd(ctx, ordState.Ord)
+}
+
+func (s *SortedSetDocValuesTermsEnum) Term() ([]byte, error) {
+	return s.term, nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) Ord() (int64, error) {
+	return s.currentOrd, nil
+}
+
+func (s *SortedSetDocValuesTermsEnum) DocFreq() (int, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (s *SortedSetDocValuesTermsEnum) TotalTermFreq() (int64, error) {
+	return 0, ErrUnsupportedOperation
+}
+
+func (s *SortedSetDocValuesTermsEnum) Postings(reuse index.PostingsEnum, flags int) (index.PostingsEnum, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (s *SortedSetDocValuesTermsEnum) Impacts(flags int) (index.ImpactsEnum, error) {
+	return nil, ErrUnsupportedOperation
+}
+
+func (s *SortedSetDocValuesTermsEnum) TermState() (index.TermState, error) {
+	state := NewOrdTermState()
+	state.Ord = s.currentOrd
+	return state, nil
+}
+
+// lookupTerm If key exists, returns its ordinal, else returns -insertionPoint-1, like Arrays.binarySearch.
+func (s *SortedSetDocValuesTermsEnum) lookupTerm(key []byte) (int64, error) {
+	low, high := int64(0), s.values.GetValueCount()-1
+	for low <= high {
+		mid := (low + high) >> 1
+		term, err := s.values.LookupOrd(mid)
+		if err != nil {
+			return 0, err
+		}
+		cmp := bytes.Compare(term, key)
+		if cmp < 0 {
+			low = mid + 1
+		} else if cmp > 0 {
+			high = mid - 1
+		} else {
+			return mid, nil // key found
+		}
+	}
+	return -(low + 1), nil // key not found.
+}
@@ -1,21 +1,290 @@
 package index
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/bytesref"
+	"github.com/geange/lucene-go/core/util/packed"
 )
 
 var _ DocValuesWriter = &SortedSetDocValuesWriter{}
 
+// SortedSetDocValuesWriter
+// Buffers up pending byte[]s per doc, deref and sorting via int ord, then flushes when segment flushes.
 type SortedSetDocValuesWriter struct {
+	hash          *bytesref.BytesHash
+	pending       *packed.PackedLongValuesBuilder // stream of all termIDs
+	pendingCounts *packed.PackedLongValuesBuilder // termIDs per doc
+	docsWithField *DocsWithFieldSet
+	fieldInfo     *document.FieldInfo
+	currentDoc    int
+	currentValues []int
+
+	finalOrds         *packed.PackedLongValues
+	finalOrdCounts    *packed.PackedLongValues
+	finalSortedValues []int
+	finalOrdMap       []int
+}
+
+func NewSortedSetDocValuesWriter(fieldInfo *document.FieldInfo, pool *bytesref.BlockPool) (*SortedSetDocValuesWriter, error) {
+	hash, err := bytesref.NewBytesHash(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SortedSetDocValuesWriter{
+		hash:          hash,
+		pending:       packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		pendingCounts: packed.NewPackedLongValuesBuilder(packed.DEFAULT_PAGE_SIZE, packed.COMPACT),
+		docsWithField: NewDocsWithFieldSet(),
+		fieldInfo:     fieldInfo,
+		currentDoc:    -1,
+		currentValues: make([]int, 0, 8),
+	}, nil
+}
+
+func (s *SortedSetDocValuesWriter) AddValue(docID int, value []byte) error {
+	if docID < s.currentDoc {
+		return errors.New("out of order doc ids")
+	}
+	if len(value) > bytesref.BYTE_BLOCK_SIZE-2 {
+		return fmt.Errorf("DocValuesField \"%s\" is too large, must be <= %d",
+			s.fieldInfo.Name(), bytesref.BYTE_BLOCK_SIZE-2)
+	}
+
+	if docID != s.currentDoc {
+		if err := s.finishCurrentDoc(); err != nil {
+			return err
+		}
+		s.currentDoc = docID
+	}
+
+	termID, err := s.hash.Add(value)
+	if err != nil {
+		return err
+	}
+	if termID < 0 {
+		termID = -termID - 1
+	}
+	s.currentValues = append(s.currentValues, termID)
+	return nil
+}
+
+// finalize currentDoc: this deduplicates the current term ids
+func (s *SortedSetDocValuesWriter) finishCurrentDoc() error {
+	if s.currentDoc == -1 {
+		return nil
+	}
+
+	slices.Sort(s.currentValues)
+	s.currentValues = slices.Compact(s.currentValues)
+	for _, termID := range s.currentValues {
+		if err := s.pending.Add(int64(termID)); err != nil {
+			return err
+		}
+	}
+	// record the number of unique term ids for this doc
+	if err := s.pendingCounts.Add(int64(len(s.currentValues))); err != nil {
+		return err
+	}
+	if err := s.docsWithField.Add(s.currentDoc); err != nil {
+		return err
+	}
+	s.currentValues = s.currentValues[:0]
+	return nil
+}
+
+// finish sorts the terms and freezes the pending ords, no value can be added afterwards
+func (s *SortedSetDocValuesWriter) finish() error {
+	if s.finalOrds != nil {
+		return nil
+	}
+
+	if err := s.finishCurrentDoc(); err != nil {
+		return err
+	}
+	finalOrds, err := s.pending.Build()
+	if err != nil {
+		return err
+	}
+	finalOrdCounts, err := s.pendingCounts.Build()
+	if err != nil {
+		return err
+	}
+	s.finalOrds, s.finalOrdCounts = finalOrds, finalOrdCounts
+
+	valueCount := s.hash.Size()
+	s.finalSortedValues = s.hash.Sort()[:valueCount]
+	s.finalOrdMap = make([]int, valueCount)
+	for ord, termID := range s.finalSortedValues {
+		s.finalOrdMap[termID] = ord
+	}
+	return nil
+}
+
+func (s *SortedSetDocValuesWriter) newBufferedSortedSetDocValues() *BufferedSortedSetDocValues {
+	iterator, _ := s.docsWithField.Iterator()
+	return NewBufferedSortedSetDocValues(s.hash, s.finalOrds, s.finalOrdCounts,
+		s.finalSortedValues, s.finalOrdMap, iterator)
 }
 
 func (s *SortedSetDocValuesWriter) Flush(state *index.SegmentWriteState, sortMap index.DocMap, consumer index.DocValuesConsumer) error {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return err
+	}
+
+	var sorted [][]int64
+	if sortMap != nil {
+		maxDoc, err := state.SegmentInfo.MaxDoc()
+		if err != nil {
+			return err
+		}
+		values := s.newBufferedSortedSetDocValues()
+		sorted, err = sortMultiValues(maxDoc, sortMap, values, func() ([]int64, error) {
+			return slices.Clone(values.ords), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return consumer.AddSortedSetField(context.TODO(), s.fieldInfo, &EmptyDocValuesProducer{
+		FnGetSortedSet: func(ctx context.Context, field *document.FieldInfo) (index.SortedSetDocValues, error) {
+			values := s.newBufferedSortedSetDocValues()
+			if sorted == nil {
+				return values, nil
+			}
+			return &SortingSortedSetDocValues{
+				sortingMultiValues: newSortingMultiValues(sorted),
+				in:                 values,
+			}, nil
+		},
+	})
 }
 
 func (s *SortedSetDocValuesWriter) GetDocValues() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	if err := s.finish(); err != nil {
+		return nil
+	}
+	return s.newBufferedSortedSetDocValues()
+}
+
+var _ index.SortedSetDocValues = &BufferedSortedSetDocValues{}
+
+type BufferedSortedSetDocValues struct {
+	hash          *bytesref.BytesHash
+	sortedValues  []int
+	ordMap        []int
+	ordsIter      packed.PackedLongValuesIterator
+	ordCountsIter packed.PackedLongValuesIterator
+	docsWithField types.DocIdSetIterator
+
+	// ords of the current doc
+	ords    []int64
+	ordUpto int
+}
+
+func NewBufferedSortedSetDocValues(hash *bytesref.BytesHash, ords, ordCounts *packed.PackedLongValues,
+	sortedValues, ordMap []int, docsWithField types.DocIdSetIterator) *BufferedSortedSetDocValues {
+
+	return &BufferedSortedSetDocValues{
+		hash:          hash,
+		sortedValues:  sortedValues,
+		ordMap:        ordMap,
+		ordsIter:      ords.Iterator(),
+		ordCountsIter: ordCounts.Iterator(),
+		docsWithField: docsWithField,
+	}
+}
+
+func (b *BufferedSortedSetDocValues) DocID() int {
+	return b.docsWithField.DocID()
+}
+
+func (b *BufferedSortedSetDocValues) NextDoc(ctx context.Context) (int, error) {
+	docID, err := b.docsWithField.NextDoc(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := b.ordCountsIter.Next()
+	if err != nil {
+		return 0, err
+	}
+	b.ords = b.ords[:0]
+	for i := 0; i < int(count); i++ {
+		termID, err := b.ordsIter.Next()
+		if err != nil {
+			return 0, err
+		}
+		b.ords = append(b.ords, int64(b.ordMap[termID]))
+	}
+	slices.Sort(b.ords)
+	b.ordUpto = 0
+	return docID, nil
+}
+
+func (b *BufferedSortedSetDocValues) Advance(ctx context.Context, target int) (int, error) {
+	return 0, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedSetDocValues) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, b, target)
+}
+
+func (b *BufferedSortedSetDocValues) Cost() int64 {
+	return b.docsWithField.Cost()
+}
+
+func (b *BufferedSortedSetDocValues) AdvanceExact(target int) (bool, error) {
+	return false, errors.New("unsupported Operation")
+}
+
+func (b *BufferedSortedSetDocValues) NextOrd() (int64, error) {
+	if b.ordUpto == len(b.ords) {
+		return NO_MORE_ORDS, nil
+	}
+	ord := b.ords[b.ordUpto]
+	b.ordUpto++
+	return ord, nil
+}
+
+func (b *BufferedSortedSetDocValues) LookupOrd(ord int64) ([]byte, error) {
+	if ord < 0 || ord >= int64(len(b.sortedValues)) {
+		return nil, fmt.Errorf("ord must be 0 .. %d; got %d", len(b.sortedValues)-1, ord)
+	}
+	return b.hash.Get(b.sortedValues[ord]), nil
+}
+
+func (b *BufferedSortedSetDocValues) GetValueCount() int64 {
+	return int64(len(b.sortedValues))
+}
+
+var _ index.SortedSetDocValues = &SortingSortedSetDocValues{}
+
+type SortingSortedSetDocValues struct {
+	*sortingMultiValues
+
+	in index.SortedSetDocValues
+}
+
+func (s *SortingSortedSetDocValues) NextOrd() (int64, error) {
+	if s.upto == len(s.current()) {
+		return NO_MORE_ORDS, nil
+	}
+	return s.next()
+}
+
+func (s *SortingSortedSetDocValues) LookupOrd(ord int64) ([]byte, error) {
+	return s.in.LookupOrd(ord)
+}
+
+func (s *SortingSortedSetDocValues) GetValueCount() int64 {
+	return s.in.GetValueCount()
 }
@@ -68,15 +68,42 @@ func (s *BaseSortField) GetBytesComparator() index.BytesComparator {
 	return s.bytesComparator
 }
 
-func (s *BaseSortField) GetComparator(numHits, sortPos int) index.FieldComparator {
+func (s *BaseSortField) GetComparator(numHits, sortPos int) (index.FieldComparator, error) {
 	var fieldComparator index.FieldComparator
 	switch s._type {
-	// TODO: fix it
+	case index.SCORE:
+		fieldComparator = NewRelevanceComparator(numHits)
+	case index.DOC:
+		fieldComparator = NewDocComparator(numHits, s.reverse, sortPos)
+	case index.INT:
+		fieldComparator = NewIntComparator(numHits, s.field, s.missingValueOr(int32(0)), s.reverse, sortPos)
+	case index.FLOAT:
+		fieldComparator = NewFloatComparator(numHits, s.field, s.missingValueOr(float32(0)), s.reverse, sortPos)
+	case index.LONG:
+		fieldComparator = NewLongComparator(numHits, s.field, s.missingValueOr(int64(0)), s.reverse, sortPos)
+	case index.DOUBLE:
+		fieldComparator = NewDoubleComparator(numHits, s.field, s.missingValueOr(float64(0)), s.reverse, sortPos)
+	case index.CUSTOM:
+		fieldComparator = s.comparatorSource.NewComparator(s.field, numHits, sortPos, s.reverse)
+	case index.STRING:
+		fieldComparator = NewTermOrdValComparator(numHits, s.field, s.missingValue == STRING_LAST)
+	case index.STRING_VAL:
+		fieldComparator = NewTermValComparator(numHits, s.field, s.missingValue == STRING_LAST)
+	default:
+		return nil, fmt.Errorf("illegal sort type: %s", s._type)
 	}
 	if !s.GetCanUsePoints() {
 		fieldComparator.DisableSkipping()
 	}
-	return fieldComparator
+	return fieldComparator, nil
+}
+
+// Returns the missing value of a numeric sort, or the zero value of the sort type if none was set.
+func (s *BaseSortField) missingValueOr(zero any) any {
+	if s.missingValue == nil {
+		return zero
+	}
+	return s.missingValue
 }
 
 func newSortField(field string, _type index.SortFieldType, reverse bool) (*BaseSortField, error) {
@@ -1,11 +1,14 @@
 package index
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util"
 )
 
 var _ index.DirectoryReader = &StandardDirectoryReader{}
@@ -19,6 +22,8 @@ type StandardDirectoryReader struct {
 	segmentInfos    *SegmentInfos
 	applyAllDeletes bool
 	writeAllDeletes bool
+
+	readerCacheHelper *cacheHelper
 }
 
 // NewStandardDirectoryReader
@@ -31,13 +36,17 @@ func NewStandardDirectoryReader(directory store.Directory, readers []index.Index
 		return nil, err
 	}
 
-	return &StandardDirectoryReader{
+	directoryReader := &StandardDirectoryReader{
 		baseDirectoryReader: reader,
 		writer:              writer,
 		segmentInfos:        sis,
 		applyAllDeletes:     applyAllDeletes,
 		writeAllDeletes:     writeAllDeletes,
-	}, nil
+		readerCacheHelper:   newCacheHelper(),
+	}
+	// the sub readers are released when the last reference to this reader is dropped
+	directoryReader.spi = directoryReader
+	return directoryReader, nil
 }
 
 type CompareIndexReader func(a, b index.IndexReader) int
@@ -137,6 +146,124 @@ func OpenStandardDirectoryReader(writer *IndexWriter, readerFunction func(index.
 	return result, nil
 }
 
+// openFromOldReaders
+// This constructor is only used for OpenIfChanged, the segment readers of oldReaders whose
+// segment didn't change are shared with the new reader, segments which only gained deletes or
+// doc values updates share the core readers of the old segment reader.
+func openFromOldReaders(ctx context.Context, directory store.Directory, infos *SegmentInfos,
+	oldReaders []index.IndexReader, compareFunc CompareLeafReader) (*StandardDirectoryReader, error) {
+
+	// we put the old SegmentReaders in a map, that allows us
+	// to lookup a reader using its segment name
+	segmentReaders := make(map[string]*SegmentReader, len(oldReaders))
+	for _, oldReader := range oldReaders {
+		segmentReader, ok := oldReader.(*SegmentReader)
+		if !ok {
+			return nil, fmt.Errorf("unexpected sub reader %T", oldReader)
+		}
+		segmentReaders[segmentReader.GetSegmentName()] = segmentReader
+	}
+
+	newReaders := make([]index.IndexReader, infos.Size())
+	decRefNewReaders := func() {
+		for _, reader := range newReaders {
+			if reader != nil {
+				_ = reader.DecRef()
+			}
+		}
+	}
+
+	for i := infos.Size() - 1; i >= 0; i-- {
+		commitInfo := infos.Info(i)
+
+		// find SegmentReader for this segment
+		oldReader := segmentReaders[commitInfo.Info().Name()]
+
+		// Make a best effort to detect when the app illegally "rm -rf" their
+		// index while a reader was open, and then called OpenIfChanged:
+		if oldReader != nil && !bytes.Equal(commitInfo.Info().GetID(), oldReader.GetSegmentInfo().Info().GetID()) {
+			decRefNewReaders()
+			return nil, fmt.Errorf("same segment %s has invalid changes; likely you are re-opening a reader "+
+				"after illegally removing index files yourself and building a new index in their place. "+
+				"Use IndexWriter.DeleteAll or open a new IndexWriter using OpenMode.CREATE instead", commitInfo.Info().Name())
+		}
+
+		newReader, err := reopenSegmentReader(ctx, infos, commitInfo, oldReader)
+		if err != nil {
+			decRefNewReaders()
+			return nil, err
+		}
+		newReaders[i] = newReader
+	}
+
+	reader, err := NewStandardDirectoryReader(directory, newReaders, nil, infos, compareFunc, false, false)
+	if err != nil {
+		decRefNewReaders()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// reopenSegmentReader returns a reader for commitInfo, reusing what it can of oldReader.
+// The returned reader holds a reference for the caller.
+func reopenSegmentReader(ctx context.Context, infos *SegmentInfos, commitInfo index.SegmentCommitInfo,
+	oldReader *SegmentReader) (*SegmentReader, error) {
+
+	if oldReader == nil || commitInfo.Info().GetUseCompoundFile() != oldReader.GetSegmentInfo().Info().GetUseCompoundFile() {
+		// this is a new reader
+		return NewSegmentReader(ctx, commitInfo, infos.getIndexCreatedVersionMajor(), store.READ)
+	}
+
+	oldInfo := oldReader.GetSegmentInfo()
+	if !oldReader.isNRT && oldInfo.GetDelGen() == commitInfo.GetDelGen() &&
+		oldInfo.GetFieldInfosGen() == commitInfo.GetFieldInfosGen() {
+		// No change; this reader will be shared between
+		// the old and the new one, so we must IncRef it:
+		if err := oldReader.IncRef(); err != nil {
+			return nil, err
+		}
+		return oldReader, nil
+	}
+
+	if !oldReader.isNRT && oldInfo.GetDelGen() == commitInfo.GetDelGen() {
+		// only DV updates
+		return oldReader.New(commitInfo, oldReader.GetLiveDocs(), oldReader.GetHardLiveDocs(),
+			oldReader.NumDocs(), false) // this is not an NRT reader!
+	}
+
+	// liveDocs have changed, or the old reader carried them in RAM:
+	// we must load liveDocs/DV updates from disk
+	var liveDocs util.Bits
+	if commitInfo.HasDeletions() {
+		var err error
+		liveDocs, err = commitInfo.Info().GetCodec().LiveDocsFormat().
+			ReadLiveDocs(ctx, commitInfo.Info().Dir(), commitInfo, store.READONCE)
+		if err != nil {
+			return nil, err
+		}
+	}
+	maxDoc, err := commitInfo.Info().MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+	return oldReader.New(commitInfo, liveDocs, liveDocs, maxDoc-commitInfo.GetDelCount(), false)
+}
+
+// DoClose releases the sub readers, they are closed once no other reader shares them.
+func (s *StandardDirectoryReader) DoClose() error {
+	var errs []error
+	for _, reader := range s.GetSequentialSubReaders() {
+		// try to close each reader, even if an exception is thrown
+		errs = append(errs, reader.DecRef())
+	}
+	errs = append(errs, s.readerCacheHelper.notifyClosedListeners())
+	return errors.Join(errs...)
+}
+
+func (s *StandardDirectoryReader) GetReaderCacheHelper() index.CacheHelper {
+	return s.readerCacheHelper
+}
+
 func (s *StandardDirectoryReader) GetVersion() int64 {
 	return s.segmentInfos.GetVersion()
 }
@@ -159,6 +286,70 @@ func (s *StandardDirectoryReader) IsCurrent(ctx context.Context) (bool, error) {
 	return s.writer.nrtIsCurrent(s.segmentInfos), nil
 }
 
+// doOpenIfChanged
+// Opens a new reader if the index changed since this reader was opened, nil is returned otherwise.
+// If we were obtained by writer.GetReader, re-ask the writer to get a new reader.
+func (s *StandardDirectoryReader) doOpenIfChanged(ctx context.Context) (index.DirectoryReader, error) {
+	if err := s.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	if s.writer != nil {
+		return s.doOpenFromWriter(ctx)
+	}
+	return s.doOpenNoWriter(ctx)
+}
+
+func (s *StandardDirectoryReader) doOpenIfChangedFromWriter(ctx context.Context,
+	writer *IndexWriter, applyAllDeletes bool) (index.DirectoryReader, error) {
+
+	if err := s.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	if writer == s.writer && applyAllDeletes == s.applyAllDeletes {
+		return s.doOpenFromWriter(ctx)
+	}
+	return writer.GetReader(ctx, applyAllDeletes, s.writeAllDeletes)
+}
+
+func (s *StandardDirectoryReader) doOpenFromWriter(ctx context.Context) (index.DirectoryReader, error) {
+	if s.writer.nrtIsCurrent(s.segmentInfos) {
+		return nil, nil
+	}
+
+	reader, err := s.writer.GetReader(ctx, s.applyAllDeletes, s.writeAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+
+	// If in fact no changes took place, return nil:
+	if reader.GetVersion() == s.segmentInfos.GetVersion() {
+		return nil, reader.DecRef()
+	}
+	return reader, nil
+}
+
+func (s *StandardDirectoryReader) doOpenNoWriter(ctx context.Context) (index.DirectoryReader, error) {
+	current, err := s.IsCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current {
+		return nil, nil
+	}
+
+	segmentsFile := NewFindSegmentsFile[index.DirectoryReader](s.directory)
+	segmentsFile.SetFuncDoBody(func(ctx context.Context, segmentFileName string) (index.DirectoryReader, error) {
+		infos, err := ReadCommit(ctx, s.directory, segmentFileName)
+		if err != nil {
+			return nil, err
+		}
+		return openFromOldReaders(ctx, s.directory, infos, s.GetSequentialSubReaders(), s.subReadersSorter)
+	})
+	return segmentsFile.Run(ctx)
+}
+
 func (s *StandardDirectoryReader) GetIndexCommit() (index.IndexCommit, error) {
 	return NewReaderCommit(s, s.segmentInfos, s.directory)
 }
@@ -0,0 +1,147 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/document"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// MergeStoredFields
+// Merges in the stored fields from the readers in mergeState. The default implementation skips over
+// deleted documents, and uses StartDocument, WriteField, and Finish, returning the number of documents
+// that were written. Implementations can override this method for more sophisticated merging
+// (bulk-byte copying, etc).
+func MergeStoredFields(ctx context.Context, writer index.StoredFieldsWriter, mergeState *MergeState) (int, error) {
+	subs := make([]*storedFieldsMergeSub, 0, len(mergeState.StoredFieldsReaders))
+	for i, reader := range mergeState.StoredFieldsReaders {
+		if reader == nil {
+			continue
+		}
+		if err := reader.CheckIntegrity(); err != nil {
+			return 0, err
+		}
+		subs = append(subs, &storedFieldsMergeSub{
+			BaseDocIDMergerSub: NewBaseDocIDMergerSub(mergeState.DocMaps[i]),
+			visitor:            newMergeVisitor(ctx, writer, mergeState),
+			reader:             reader,
+			maxDoc:             mergeState.MaxDocs[i],
+			docID:              -1,
+		})
+	}
+
+	docIDMerger, err := NewDocIDMerger(ctx, subs, mergeState.NeedsIndexSort)
+	if err != nil {
+		return 0, err
+	}
+
+	docCount := 0
+	for {
+		sub, err := docIDMerger.Next(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, err
+		}
+
+		if err := writer.StartDocument(ctx); err != nil {
+			return 0, err
+		}
+		if err := sub.reader.VisitDocument(ctx, sub.docID, sub.visitor); err != nil {
+			return 0, err
+		}
+		if err := writer.FinishDocument(ctx); err != nil {
+			return 0, err
+		}
+		docCount++
+	}
+
+	if err := writer.Finish(ctx, mergeState.MergeFieldInfos, docCount); err != nil {
+		return 0, err
+	}
+	return docCount, nil
+}
+
+type storedFieldsMergeSub struct {
+	*BaseDocIDMergerSub
+
+	visitor *mergeVisitor
+	reader  index.StoredFieldsReader
+	maxDoc  int
+	docID   int
+}
+
+func (s *storedFieldsMergeSub) NextDoc(ctx context.Context) (int, error) {
+	s.docID++
+	if s.docID == s.maxDoc {
+		return types.NO_MORE_DOCS, nil
+	}
+	return s.docID, nil
+}
+
+var _ document.StoredFieldVisitor = &mergeVisitor{}
+
+// mergeVisitor
+// A visitor that adds every field it sees to the writer, with its field info remapped to the
+// merged segment.
+type mergeVisitor struct {
+	ctx        context.Context
+	writer     index.StoredFieldsWriter
+	mergeState *MergeState
+}
+
+func newMergeVisitor(ctx context.Context, writer index.StoredFieldsWriter, mergeState *MergeState) *mergeVisitor {
+	return &mergeVisitor{
+		ctx:        ctx,
+		writer:     writer,
+		mergeState: mergeState,
+	}
+}
+
+func (m *mergeVisitor) remap(fieldInfo *document.FieldInfo) (*document.FieldInfo, error) {
+	mergeFieldInfo := m.mergeState.MergeFieldInfos.FieldInfo(fieldInfo.Name())
+	if mergeFieldInfo == nil {
+		return nil, errors.New("unknown field: " + fieldInfo.Name())
+	}
+	return mergeFieldInfo, nil
+}
+
+func (m *mergeVisitor) BinaryField(fieldInfo *document.FieldInfo, value []byte) error {
+	return writeMergedStoredField(m, fieldInfo, value)
+}
+
+func (m *mergeVisitor) StringField(fieldInfo *document.FieldInfo, value []byte) error {
+	return writeMergedStoredField(m, fieldInfo, string(value))
+}
+
+func (m *mergeVisitor) Int32Field(fieldInfo *document.FieldInfo, value int32) error {
+	return writeMergedStoredField(m, fieldInfo, value)
+}
+
+func (m *mergeVisitor) Int64Field(fieldInfo *document.FieldInfo, value int64) error {
+	return writeMergedStoredField(m, fieldInfo, value)
+}
+
+func (m *mergeVisitor) Float32Field(fieldInfo *document.FieldInfo, value float32) error {
+	return writeMergedStoredField(m, fieldInfo, value)
+}
+
+func (m *mergeVisitor) Float64Field(fieldInfo *document.FieldInfo, value float64) error {
+	return writeMergedStoredField(m, fieldInfo, value)
+}
+
+func (m *mergeVisitor) NeedsField(fieldInfo *document.FieldInfo) (document.STORED_FIELD_VISITOR_STATUS, error) {
+	return document.STORED_FIELD_VISITOR_YES, nil
+}
+
+func writeMergedStoredField[T document.StoredFieldType](m *mergeVisitor, fieldInfo *document.FieldInfo, value T) error {
+	mergeFieldInfo, err := m.remap(fieldInfo)
+	if err != nil {
+		return err
+	}
+	return m.writer.WriteField(m.ctx, mergeFieldInfo, document.NewStoredField(mergeFieldInfo.Name(), value))
+}
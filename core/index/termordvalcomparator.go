@@ -0,0 +1,310 @@
+package index
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.FieldComparator = &TermOrdValComparator{}
+var _ index.LeafFieldComparator = &TermOrdValComparator{}
+
+// TermOrdValComparator
+// Sorts by field's natural Term sort order, using ordinals. This is functionally equivalent to
+// TermValComparator, but it first resolves the string to their relative ordinal positions (using
+// the index returned by LeafReader.GetSortedDocValues), and does most comparisons using the
+// ordinals. For medium to large results, this comparator will be much faster than
+// TermValComparator. For very small result sets it may be slower.
+type TermOrdValComparator struct {
+	// Ords for each slot.
+	ords []int
+
+	// Values for each slot, nil if the document had no value.
+	values [][]byte
+
+	// Which reader last copied a value into the slot. When we compare two slots,
+	// we just compare-by-ord if the readerGen is the same; else we must compare the values
+	// (slower).
+	readerGen []int
+
+	// Gen of current reader we are on.
+	currentReaderGen int
+
+	// Current reader's doc ord/values.
+	termsIndex index.SortedDocValues
+
+	field string
+
+	// Bottom slot, or -1 if queue isn't full yet
+	bottomSlot int
+
+	// Bottom ord (same as ords[bottomSlot] once bottomSlot is set). Cached for faster compares.
+	bottomOrd int
+
+	// True if current bottom slot matches the current reader.
+	bottomSameReader bool
+
+	// Bottom value (same as values[bottomSlot] once bottomSlot is set). Cached for faster compares.
+	bottomValue []byte
+
+	// Set by SetTopValue.
+	topValue      []byte
+	topSameReader bool
+	topOrd        int
+
+	// -1 if missing values are sorted first, 1 if they are sorted last
+	missingSortCmp int
+
+	// Which ordinal to use for a missing value.
+	missingOrd int
+}
+
+// NewTermOrdValComparator
+// Creates this, with control over how missing values are sorted. Pass sortMissingLast=true
+// to put missing values at the end.
+func NewTermOrdValComparator(numHits int, field string, sortMissingLast bool) *TermOrdValComparator {
+	comparator := &TermOrdValComparator{
+		ords:             make([]int, numHits),
+		values:           make([][]byte, numHits),
+		readerGen:        make([]int, numHits),
+		currentReaderGen: -1,
+		field:            field,
+		bottomSlot:       -1,
+	}
+	if sortMissingLast {
+		comparator.missingSortCmp = 1
+		comparator.missingOrd = math.MaxInt32
+	} else {
+		comparator.missingSortCmp = -1
+		comparator.missingOrd = -1
+	}
+	return comparator
+}
+
+func (t *TermOrdValComparator) Compare(slot1, slot2 int) int {
+	if t.readerGen[slot1] == t.readerGen[slot2] {
+		return t.ords[slot1] - t.ords[slot2]
+	}
+
+	val1, val2 := t.values[slot1], t.values[slot2]
+	if val1 == nil {
+		if val2 == nil {
+			return 0
+		}
+		return t.missingSortCmp
+	} else if val2 == nil {
+		return -t.missingSortCmp
+	}
+	return bytes.Compare(val1, val2)
+}
+
+func (t *TermOrdValComparator) SetTopValue(value any) {
+	// null is fine: it means the last doc of the prior
+	// search was missing this value
+	if value == nil {
+		t.topValue = nil
+		return
+	}
+	t.topValue = value.([]byte)
+}
+
+func (t *TermOrdValComparator) Value(slot int) any {
+	if t.values[slot] == nil {
+		return nil
+	}
+	return t.values[slot]
+}
+
+func (t *TermOrdValComparator) CompareValues(first, second any) int {
+	val1, _ := first.([]byte)
+	val2, _ := second.([]byte)
+	if val1 == nil {
+		if val2 == nil {
+			return 0
+		}
+		return t.missingSortCmp
+	} else if val2 == nil {
+		return -t.missingSortCmp
+	}
+	return bytes.Compare(val1, val2)
+}
+
+func (t *TermOrdValComparator) SetSingleSort() {
+}
+
+func (t *TermOrdValComparator) DisableSkipping() {
+}
+
+func (t *TermOrdValComparator) GetLeafComparator(context index.LeafReaderContext) (index.LeafFieldComparator, error) {
+	termsIndex, err := GetSorted(context.LeafReader(), t.field)
+	if err != nil {
+		return nil, err
+	}
+	t.termsIndex = termsIndex
+	t.currentReaderGen++
+
+	if t.topValue != nil {
+		// Recompute topOrd/SameReader
+		ord, err := t.lookupTerm(t.topValue)
+		if err != nil {
+			return nil, err
+		}
+		if ord >= 0 {
+			t.topSameReader = true
+			t.topOrd = ord
+		} else {
+			t.topSameReader = false
+			t.topOrd = -ord - 2
+		}
+	} else {
+		t.topOrd = t.missingOrd
+		t.topSameReader = true
+	}
+
+	if t.bottomSlot != -1 {
+		// Recompute bottomOrd/SameReader
+		if err := t.SetBottom(t.bottomSlot); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Retrieves the ord for the current doc, or -1 if the document has no value.
+func (t *TermOrdValComparator) getOrdForDoc(doc int) (int, error) {
+	if t.termsIndex == nil {
+		return -1, nil
+	}
+
+	ok, err := t.termsIndex.AdvanceExact(doc)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return -1, nil
+	}
+	return t.termsIndex.OrdValue()
+}
+
+// lookupTerm behaves like SortedDocValues.LookupTerm, a segment without values
+// for the field is handled as an empty one.
+func (t *TermOrdValComparator) lookupTerm(key []byte) (int, error) {
+	if t.termsIndex == nil {
+		return -1, nil
+	}
+	return t.termsIndex.LookupTerm(key)
+}
+
+func (t *TermOrdValComparator) SetBottom(slot int) error {
+	t.bottomSlot = slot
+	t.bottomValue = t.values[t.bottomSlot]
+
+	if t.currentReaderGen == t.readerGen[t.bottomSlot] {
+		t.bottomOrd = t.ords[t.bottomSlot]
+		t.bottomSameReader = true
+		return nil
+	}
+
+	if t.bottomValue == nil {
+		// missingOrd is null for all segments
+		t.bottomOrd = t.missingOrd
+		t.bottomSameReader = true
+		t.readerGen[t.bottomSlot] = t.currentReaderGen
+		return nil
+	}
+
+	ord, err := t.lookupTerm(t.bottomValue)
+	if err != nil {
+		return err
+	}
+	if ord < 0 {
+		t.bottomOrd = -ord - 2
+		t.bottomSameReader = false
+	} else {
+		t.bottomOrd = ord
+		// exact value match
+		t.bottomSameReader = true
+		t.readerGen[t.bottomSlot] = t.currentReaderGen
+		t.ords[t.bottomSlot] = t.bottomOrd
+	}
+	return nil
+}
+
+func (t *TermOrdValComparator) CompareBottom(doc int) (int, error) {
+	docOrd, err := t.getOrdForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	if docOrd == -1 {
+		docOrd = t.missingOrd
+	}
+
+	if t.bottomSameReader {
+		// ord is precisely comparable, even in the equal case
+		return t.bottomOrd - docOrd, nil
+	} else if t.bottomOrd >= docOrd {
+		// the equals case always means bottom is > doc
+		// (because we set bottomOrd to the lower bound in
+		// SetBottom):
+		return 1, nil
+	}
+	return -1, nil
+}
+
+func (t *TermOrdValComparator) CompareTop(doc int) (int, error) {
+	ord, err := t.getOrdForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	if ord == -1 {
+		ord = t.missingOrd
+	}
+
+	if t.topSameReader {
+		// ord is precisely comparable, even in the equal
+		// case
+		return t.topOrd - ord, nil
+	} else if ord <= t.topOrd {
+		return 1, nil
+	}
+	return -1, nil
+}
+
+func (t *TermOrdValComparator) Copy(slot, doc int) error {
+	ord, err := t.getOrdForDoc(doc)
+	if err != nil {
+		return err
+	}
+
+	if ord == -1 {
+		ord = t.missingOrd
+		t.values[slot] = nil
+	} else {
+		term, err := t.termsIndex.LookupOrd(ord)
+		if err != nil {
+			return err
+		}
+		// the returned bytes may be reused by the doc values, keep our own copy
+		if t.values[slot] == nil {
+			t.values[slot] = make([]byte, 0, len(term))
+		}
+		t.values[slot] = append(t.values[slot][:0], term...)
+	}
+	t.ords[slot] = ord
+	t.readerGen[slot] = t.currentReaderGen
+	return nil
+}
+
+func (t *TermOrdValComparator) SetScorer(scorer index.Scorable) error {
+	return nil
+}
+
+func (t *TermOrdValComparator) CompetitiveIterator() (types.DocIdSetIterator, error) {
+	return nil, nil
+}
+
+func (t *TermOrdValComparator) SetHitsThresholdReached() error {
+	return nil
+}
@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"errors"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/automaton"
@@ -34,20 +35,23 @@ func (t *BaseTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm [
 	// the returned enum, instead of only being able to seek
 	// at the start
 
-	//termsEnum, err := t.DVFUIterator()
-	//if err != nil {
-	//	return nil, err
-	//}
-	//
-	//if compiled.Type() != automaton.AUTOMATON_TYPE_NORMAL {
-	//	return nil, errors.New("please use CompiledAutomaton.getTermsEnum instead")
-	//}
-	//
-	//if len(startTerm) > 0 {
-	//	//
-	//	//return nAutomatonTermsEnum(termsEnum, compiled);
-	//}
-	panic("")
+	termsEnum, err := t.spi.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	if compiled.Type() != automaton.AUTOMATON_TYPE_NORMAL {
+		return nil, errors.New("please use CompiledAutomaton.getTermsEnum instead")
+	}
+
+	enum, err := NewAutomatonTermsEnum(termsEnum, compiled)
+	if err != nil {
+		return nil, err
+	}
+	if startTerm != nil {
+		enum.startTerm = startTerm
+	}
+	return enum, nil
 }
 
 func (t *BaseTerms) GetMin() ([]byte, error) {
@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"io"
 
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/attribute"
@@ -71,7 +72,7 @@ type emptyTermsEnum struct {
 }
 
 func (e *emptyTermsEnum) Next(context.Context) ([]byte, error) {
-	return []byte{}, nil
+	return nil, io.EOF
 }
 
 func (e *emptyTermsEnum) Attributes() *attribute.Source {
@@ -0,0 +1,162 @@
+package index
+
+import (
+	"bytes"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.FieldComparator = &TermValComparator{}
+var _ index.LeafFieldComparator = &TermValComparator{}
+
+// TermValComparator
+// Sorts by field's natural Term sort order. All comparisons are done using BytesRef.compareTo,
+// which is slow for medium to large result sets but possibly very fast for very small results sets.
+type TermValComparator struct {
+	values         [][]byte
+	docTerms       index.BinaryDocValues
+	field          string
+	bottom         []byte
+	topValue       []byte
+	missingSortCmp int
+}
+
+// NewTermValComparator Sole constructor.
+func NewTermValComparator(numHits int, field string, sortMissingLast bool) *TermValComparator {
+	comparator := &TermValComparator{
+		values: make([][]byte, numHits),
+		field:  field,
+	}
+	if sortMissingLast {
+		comparator.missingSortCmp = 1
+	} else {
+		comparator.missingSortCmp = -1
+	}
+	return comparator
+}
+
+func (t *TermValComparator) Compare(slot1, slot2 int) int {
+	return t.compareValues(t.values[slot1], t.values[slot2])
+}
+
+func (t *TermValComparator) SetTopValue(value any) {
+	if value == nil {
+		t.topValue = nil
+		return
+	}
+	t.topValue = value.([]byte)
+}
+
+func (t *TermValComparator) Value(slot int) any {
+	if t.values[slot] == nil {
+		return nil
+	}
+	return t.values[slot]
+}
+
+func (t *TermValComparator) CompareValues(first, second any) int {
+	val1, _ := first.([]byte)
+	val2, _ := second.([]byte)
+	return t.compareValues(val1, val2)
+}
+
+func (t *TermValComparator) compareValues(val1, val2 []byte) int {
+	if val1 == nil {
+		if val2 == nil {
+			return 0
+		}
+		return t.missingSortCmp
+	} else if val2 == nil {
+		return -t.missingSortCmp
+	}
+	return bytes.Compare(val1, val2)
+}
+
+func (t *TermValComparator) SetSingleSort() {
+}
+
+func (t *TermValComparator) DisableSkipping() {
+}
+
+func (t *TermValComparator) GetLeafComparator(context index.LeafReaderContext) (index.LeafFieldComparator, error) {
+	docTerms, err := context.LeafReader().GetBinaryDocValues(t.field)
+	if err != nil {
+		return nil, err
+	}
+	t.docTerms = docTerms
+	return t, nil
+}
+
+// Retrieves the value for the given document, nil is returned if the document has no value.
+func (t *TermValComparator) getValueForDoc(doc int) ([]byte, error) {
+	if t.docTerms == nil {
+		return nil, nil
+	}
+
+	ok, err := t.docTerms.AdvanceExact(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	term, err := t.docTerms.BinaryValue()
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		// an empty value is still a value
+		term = []byte{}
+	}
+	return term, nil
+}
+
+func (t *TermValComparator) SetBottom(slot int) error {
+	t.bottom = t.values[slot]
+	return nil
+}
+
+func (t *TermValComparator) CompareBottom(doc int) (int, error) {
+	term, err := t.getValueForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	return t.compareValues(t.bottom, term), nil
+}
+
+func (t *TermValComparator) CompareTop(doc int) (int, error) {
+	term, err := t.getValueForDoc(doc)
+	if err != nil {
+		return 0, err
+	}
+	return t.compareValues(t.topValue, term), nil
+}
+
+func (t *TermValComparator) Copy(slot, doc int) error {
+	term, err := t.getValueForDoc(doc)
+	if err != nil {
+		return err
+	}
+
+	if term == nil {
+		t.values[slot] = nil
+		return nil
+	}
+	// the returned bytes may be reused by the doc values, keep our own copy
+	t.values[slot] = append(make([]byte, 0, len(term)), term...)
+	return nil
+}
+
+func (t *TermValComparator) SetScorer(scorer index.Scorable) error {
+	return nil
+}
+
+func (t *TermValComparator) CompetitiveIterator() (types.DocIdSetIterator, error) {
+	return nil, nil
+}
+
+func (t *TermValComparator) SetHitsThresholdReached() error {
+	return nil
+}
@@ -0,0 +1,202 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// MergeTermVectors
+// Merges in the term vectors from the readers in mergeState. The default implementation skips over
+// deleted documents, and uses StartDocument, StartField, StartTerm, AddPosition, and Finish, returning
+// the number of documents that were written. Implementations can override this method for more
+// sophisticated merging (bulk-byte copying, etc).
+func MergeTermVectors(ctx context.Context, writer index.TermVectorsWriter, mergeState *MergeState) (int, error) {
+	subs := make([]*termVectorsMergeSub, 0, len(mergeState.TermVectorsReaders))
+	for i, reader := range mergeState.TermVectorsReaders {
+		if reader != nil {
+			if err := reader.CheckIntegrity(); err != nil {
+				return 0, err
+			}
+		}
+		subs = append(subs, &termVectorsMergeSub{
+			BaseDocIDMergerSub: NewBaseDocIDMergerSub(mergeState.DocMaps[i]),
+			reader:             reader,
+			maxDoc:             mergeState.MaxDocs[i],
+			docID:              -1,
+		})
+	}
+
+	docIDMerger, err := NewDocIDMerger(ctx, subs, mergeState.NeedsIndexSort)
+	if err != nil {
+		return 0, err
+	}
+
+	docCount := 0
+	for {
+		sub, err := docIDMerger.Next(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, err
+		}
+
+		// NOTE: it's very important to first assign to vectors then pass it to
+		// termVectorsWriter.addAllDocVectors; see LUCENE-1282
+		var vectors index.Fields
+		if sub.reader != nil {
+			vectors, err = sub.reader.Get(ctx, sub.docID)
+			if err != nil {
+				return 0, err
+			}
+		}
+		if err := addAllDocVectors(ctx, writer, vectors, mergeState); err != nil {
+			return 0, err
+		}
+		docCount++
+	}
+
+	if err := writer.Finish(ctx, mergeState.MergeFieldInfos, docCount); err != nil {
+		return 0, err
+	}
+	return docCount, nil
+}
+
+type termVectorsMergeSub struct {
+	*BaseDocIDMergerSub
+
+	reader index.TermVectorsReader
+	maxDoc int
+	docID  int
+}
+
+func (t *termVectorsMergeSub) NextDoc(ctx context.Context) (int, error) {
+	t.docID++
+	if t.docID == t.maxDoc {
+		return types.NO_MORE_DOCS, nil
+	}
+	return t.docID, nil
+}
+
+// addAllDocVectors Safe (but, slowish) default method to write every vector field in the document.
+func addAllDocVectors(ctx context.Context, writer index.TermVectorsWriter, vectors index.Fields, mergeState *MergeState) error {
+	if vectors == nil {
+		if err := writer.StartDocument(ctx, 0); err != nil {
+			return err
+		}
+		return writer.FinishDocument(ctx)
+	}
+
+	names := vectors.Names()
+	if err := writer.StartDocument(ctx, len(names)); err != nil {
+		return err
+	}
+
+	for _, fieldName := range names {
+		fieldInfo := mergeState.MergeFieldInfos.FieldInfo(fieldName)
+		if fieldInfo == nil {
+			return errors.New("unknown field: " + fieldName)
+		}
+
+		terms, err := vectors.Terms(fieldName)
+		if err != nil {
+			return err
+		}
+		if terms == nil {
+			// FieldsEnum shouldn't lie...
+			continue
+		}
+
+		hasPositions := terms.HasPositions()
+		hasOffsets := terms.HasOffsets()
+		hasPayloads := terms.HasPayloads()
+
+		numTerms, err := terms.Size()
+		if err != nil {
+			return err
+		}
+		if numTerms == -1 {
+			return errors.New("terms.size() must be implemented (it returned -1)")
+		}
+
+		if err := writer.StartField(ctx, fieldInfo, numTerms, hasPositions, hasOffsets, hasPayloads); err != nil {
+			return err
+		}
+
+		termsEnum, err := terms.Iterator()
+		if err != nil {
+			return err
+		}
+
+		termCount := 0
+		for {
+			term, err := termsEnum.Next(ctx)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			if term == nil {
+				break
+			}
+			termCount++
+
+			totalTermFreq, err := termsEnum.TotalTermFreq()
+			if err != nil {
+				return err
+			}
+			freq := int(totalTermFreq)
+
+			if err := writer.StartTerm(ctx, term, freq); err != nil {
+				return err
+			}
+
+			if hasPositions || hasOffsets {
+				docsAndPositionsEnum, err := termsEnum.Postings(nil, POSTINGS_ENUM_OFFSETS|POSTINGS_ENUM_PAYLOADS)
+				if err != nil {
+					return err
+				}
+				if _, err := docsAndPositionsEnum.NextDoc(ctx); err != nil {
+					return err
+				}
+
+				for posUpto := 0; posUpto < freq; posUpto++ {
+					pos, err := docsAndPositionsEnum.NextPosition()
+					if err != nil {
+						return err
+					}
+					startOffset, err := docsAndPositionsEnum.StartOffset()
+					if err != nil {
+						return err
+					}
+					endOffset, err := docsAndPositionsEnum.EndOffset()
+					if err != nil {
+						return err
+					}
+					payload, err := docsAndPositionsEnum.GetPayload()
+					if err != nil {
+						return err
+					}
+					if err := writer.AddPosition(ctx, pos, startOffset, endOffset, payload); err != nil {
+						return err
+					}
+				}
+			}
+			if err := writer.FinishTerm(ctx); err != nil {
+				return err
+			}
+		}
+		if termCount != numTerms {
+			return errors.New("terms count mismatch")
+		}
+		if err := writer.FinishField(ctx); err != nil {
+			return err
+		}
+	}
+	return writer.FinishDocument(ctx)
+}
@@ -0,0 +1,722 @@
+package index
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+const (
+	// TIERED_DEFAULT_NO_CFS_RATIO Default noCFSRatio. If a merge's size is >= 10% of the index,
+	// then we disable compound file for it.
+	TIERED_DEFAULT_NO_CFS_RATIO = 0.1
+)
+
+var _ MergePolicy = &TieredMergePolicy{}
+
+// TieredMergePolicy Merges segments of approximately equal size, subject to an allowed number of
+// segments per tier. This is similar to LogByteSizeMergePolicy, except this merge policy is able to
+// merge non-adjacent segment, and separates how many segments are merged at once (SetMaxMergeAtOnce)
+// from how many segments are allowed per tier (SetSegmentsPerTier). This merge policy also does not
+// over-merge (i.e. cascade merges).
+//
+// For normal merging, this policy first computes a "budget" of how many segments are allowed to be
+// in the index. If the index is over-budget, then the policy sorts segments by decreasing size
+// (pro-rating by percent deletes), and then finds the least-cost merge. Merge cost is measured by a
+// combination of the "skew" of the merge (size of largest segment divided by smallest segment),
+// total merge size and percent deletes reclaimed, so that merges with lower skew, smaller size and
+// those reclaiming more deletes, are favored.
+//
+// If a merge will produce a segment that's larger than SetMaxMergedSegmentMB, then the policy will
+// merge fewer segments (down to 1 at once, if that one has deletions) to keep the segment size under
+// budget.
+//
+// NOTE: this policy freely merges non-adjacent segments; if this is a problem, use LogMergePolicy.
+//
+// NOTE: This policy always merges by byte size of the segments, always pro-rates by percent deletes.
+//
+// NOTE Starting with Lucene 7.5, there are several changes:
+//   - FindForcedMerges and FindForcedDeletesMerges) respect the max segment size by default.
+//   - When FindForcedMerges is called with maxSegmentCount other than 1, the resulting index is not
+//     guaranteed to have <= maxSegmentCount segments. Rather it is on a "best effort" basis.
+//     Specifically the theoretical ideal segment size is calculated and a "fudge factor" of 25% is
+//     added as the new maxSegmentSize, which is respected.
+//   - FindForcedDeletesMerges will only return segments that have deletes.
+//
+// lucene.experimental
+type TieredMergePolicy struct {
+	*MergePolicyBase
+
+	// User-settable
+	maxMergeAtOnce              int
+	maxMergedSegmentBytes       int64
+	maxMergeAtOnceExplicit      int
+	floorSegmentBytes           int64
+	segsPerTier                 float64
+	forceMergeDeletesPctAllowed float64
+	deletesPctAllowed           float64
+}
+
+// NewTieredMergePolicy Sole constructor, setting all settings to their defaults.
+func NewTieredMergePolicy() *TieredMergePolicy {
+	policy := &TieredMergePolicy{
+		maxMergeAtOnce:              10,
+		maxMergedSegmentBytes:       5 * 1024 * 1024 * 1024,
+		maxMergeAtOnceExplicit:      30,
+		floorSegmentBytes:           2 * 1024 * 1024,
+		segsPerTier:                 10.0,
+		forceMergeDeletesPctAllowed: 10.0,
+		deletesPctAllowed:           33.0,
+	}
+	policy.MergePolicyBase = NewMergePolicy(policy)
+	policy.noCFSRatio = TIERED_DEFAULT_NO_CFS_RATIO
+	return policy
+}
+
+// SetMaxMergeAtOnce Maximum number of segments to be merged at a time during "normal" merging.
+// For explicit merging (eg, forceMerge or forceMergeDeletes was called), see SetMaxMergeAtOnceExplicit.
+// Default is 10.
+func (t *TieredMergePolicy) SetMaxMergeAtOnce(v int) error {
+	if v < 2 {
+		return fmt.Errorf("maxMergeAtOnce must be > 1 (got %d)", v)
+	}
+	t.maxMergeAtOnce = v
+	return nil
+}
+
+// GetMaxMergeAtOnce Returns the current maxMergeAtOnce setting.
+func (t *TieredMergePolicy) GetMaxMergeAtOnce() int {
+	return t.maxMergeAtOnce
+}
+
+// SetMaxMergeAtOnceExplicit Maximum number of segments to be merged at a time, during forceMerge
+// or forceMergeDeletes. Default is 30.
+func (t *TieredMergePolicy) SetMaxMergeAtOnceExplicit(v int) error {
+	if v < 2 {
+		return fmt.Errorf("maxMergeAtOnceExplicit must be > 1 (got %d)", v)
+	}
+	t.maxMergeAtOnceExplicit = v
+	return nil
+}
+
+// GetMaxMergeAtOnceExplicit Returns the current maxMergeAtOnceExplicit setting.
+func (t *TieredMergePolicy) GetMaxMergeAtOnceExplicit() int {
+	return t.maxMergeAtOnceExplicit
+}
+
+// SetMaxMergedSegmentMB Maximum sized segment to produce during normal merging. This setting is
+// approximate: the estimate of the merged segment size is made by summing sizes of to-be-merged
+// segments (compensating for percent deleted docs). Default is 5 GB.
+func (t *TieredMergePolicy) SetMaxMergedSegmentMB(v float64) error {
+	if v < 0.0 {
+		return fmt.Errorf("maxMergedSegmentMB must be >=0 (got %v)", v)
+	}
+	t.maxMergedSegmentBytes = mbToBytes(v)
+	return nil
+}
+
+// GetMaxMergedSegmentMB Returns the current maxMergedSegmentMB setting.
+func (t *TieredMergePolicy) GetMaxMergedSegmentMB() float64 {
+	return float64(t.maxMergedSegmentBytes) / 1024 / 1024
+}
+
+// SetDeletesPctAllowed Controls the maximum percentage of deleted documents that is tolerated in
+// the index. Lower values make the index more space efficient at the expense of increased CPU and
+// I/O activity. Values must be between 20 and 50. Default value is 33.
+func (t *TieredMergePolicy) SetDeletesPctAllowed(v float64) error {
+	if v < 20 || v > 50 {
+		return fmt.Errorf("indexPctDeletedTarget must be >= 20.0 and <= 50 (got %v)", v)
+	}
+	t.deletesPctAllowed = v
+	return nil
+}
+
+// GetDeletesPctAllowed Returns the current deletesPctAllowed setting.
+func (t *TieredMergePolicy) GetDeletesPctAllowed() float64 {
+	return t.deletesPctAllowed
+}
+
+// SetFloorSegmentMB Segments smaller than this are "rounded up" to this size, ie treated as equal
+// (floor) size for merge selection. This is to prevent frequent flushing of tiny segments from
+// allowing a long tail in the index. Default is 2 MB.
+func (t *TieredMergePolicy) SetFloorSegmentMB(v float64) error {
+	if v <= 0.0 {
+		return fmt.Errorf("floorSegmentMB must be > 0.0 (got %v)", v)
+	}
+	t.floorSegmentBytes = mbToBytes(v)
+	return nil
+}
+
+// GetFloorSegmentMB Returns the current floorSegmentMB.
+func (t *TieredMergePolicy) GetFloorSegmentMB() float64 {
+	return float64(t.floorSegmentBytes) / (1024 * 1024)
+}
+
+// SetForceMergeDeletesPctAllowed When forceMergeDeletes is called, we only merge away a segment if
+// its delete percentage is over this threshold. Default is 10%.
+func (t *TieredMergePolicy) SetForceMergeDeletesPctAllowed(v float64) error {
+	if v < 0.0 || v > 100.0 {
+		return fmt.Errorf("forceMergeDeletesPctAllowed must be between 0.0 and 100.0 inclusive (got %v)", v)
+	}
+	t.forceMergeDeletesPctAllowed = v
+	return nil
+}
+
+// GetForceMergeDeletesPctAllowed Returns the current forceMergeDeletesPctAllowed setting.
+func (t *TieredMergePolicy) GetForceMergeDeletesPctAllowed() float64 {
+	return t.forceMergeDeletesPctAllowed
+}
+
+// SetSegmentsPerTier Sets the allowed number of segments per tier. Smaller values mean more merging
+// but fewer segments.
+// Default is 10.0.
+func (t *TieredMergePolicy) SetSegmentsPerTier(v float64) error {
+	if v < 2.0 {
+		return fmt.Errorf("segmentsPerTier must be >= 2.0 (got %v)", v)
+	}
+	t.segsPerTier = v
+	return nil
+}
+
+// GetSegmentsPerTier Returns the current segmentsPerTier setting.
+func (t *TieredMergePolicy) GetSegmentsPerTier() float64 {
+	return t.segsPerTier
+}
+
+// Size Return the byte size of the provided SegmentCommitInfo, pro-rated by percentage of
+// non-deleted documents.
+func (t *TieredMergePolicy) Size(info index.SegmentCommitInfo, mergeContext MergeContext) (int64, error) {
+	return t.size(info, mergeContext)
+}
+
+type segmentSizeAndDocs struct {
+	segInfo     index.SegmentCommitInfo
+	sizeInBytes int64
+	delCount    int
+	maxDoc      int
+	name        string
+}
+
+func newSegmentSizeAndDocs(info index.SegmentCommitInfo, sizeInBytes int64, segDelCount int) (*segmentSizeAndDocs, error) {
+	maxDoc, err := info.Info().MaxDoc()
+	if err != nil {
+		return nil, err
+	}
+	return &segmentSizeAndDocs{
+		segInfo:     info,
+		sizeInBytes: sizeInBytes,
+		delCount:    segDelCount,
+		maxDoc:      maxDoc,
+		name:        info.Info().Name(),
+	}, nil
+}
+
+// getSortedBySegmentSize The size can change concurrently while we are running here, because
+// deletes are now applied concurrently, so we call Size() once per segment and sort by that.
+func (t *TieredMergePolicy) getSortedBySegmentSize(infos *SegmentInfos, mergeContext MergeContext) ([]*segmentSizeAndDocs, error) {
+	sortedBySize := make([]*segmentSizeAndDocs, 0, infos.Size())
+	for _, info := range infos.AsList() {
+		size, err := t.Size(info, mergeContext)
+		if err != nil {
+			return nil, err
+		}
+		delCount, err := mergeContext.NumDeletesToMerge(info)
+		if err != nil {
+			return nil, err
+		}
+		segSizeDocs, err := newSegmentSizeAndDocs(info, size, delCount)
+		if err != nil {
+			return nil, err
+		}
+		sortedBySize = append(sortedBySize, segSizeDocs)
+	}
+
+	slices.SortFunc(sortedBySize, func(a, b *segmentSizeAndDocs) int {
+		// Sort by largest size, then by segment name
+		if c := cmp.Compare(b.sizeInBytes, a.sizeInBytes); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return sortedBySize, nil
+}
+
+func (t *TieredMergePolicy) FindMerges(mergeTrigger MergeTrigger, infos *SegmentInfos, mergeContext MergeContext) (*MergeSpecification, error) {
+	merging := mergingSegmentSet(mergeContext)
+
+	// Compute total index bytes & print details about the index
+	totIndexBytes := int64(0)
+	minSegmentBytes := int64(math.MaxInt64)
+
+	totalDelDocs := 0
+	totalMaxDoc := 0
+
+	mergingBytes := int64(0)
+
+	sortedInfos, err := t.getSortedBySegmentSize(infos, mergeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := sortedInfos[:0]
+	for _, segSizeDocs := range sortedInfos {
+		segBytes := segSizeDocs.sizeInBytes
+		if _, ok := merging[segSizeDocs.segInfo]; ok {
+			mergingBytes += segBytes
+			// if this segment is merging, then its deletes are being reclaimed already.
+			// only count live docs in the total max doc
+			totalMaxDoc += segSizeDocs.maxDoc - segSizeDocs.delCount
+		} else {
+			totalDelDocs += segSizeDocs.delCount
+			totalMaxDoc += segSizeDocs.maxDoc
+			eligible = append(eligible, segSizeDocs)
+		}
+
+		minSegmentBytes = min(segBytes, minSegmentBytes)
+		totIndexBytes += segBytes
+	}
+	sortedInfos = eligible
+
+	totalDelPct := 100 * float64(totalDelDocs) / float64(totalMaxDoc)
+	allowedDelCount := int(t.deletesPctAllowed * float64(totalMaxDoc) / 100)
+
+	// If we have too-large segments, grace them out of the maximum segment count
+	// If we're above certain thresholds of deleted docs, we can merge very large segments.
+	//
+	// remove large segments from consideration under two conditions.
+	// 1> Overall percent deleted docs relatively small and this segment is larger than 50% maxSegSize
+	// 2> overall percent deleted docs large and this segment is large and has few deleted docs
+	eligible = sortedInfos[:0]
+	for _, segSizeDocs := range sortedInfos {
+		segDelPct := 100 * float64(segSizeDocs.delCount) / float64(segSizeDocs.maxDoc)
+		if segSizeDocs.sizeInBytes > t.maxMergedSegmentBytes/2 &&
+			(totalDelPct <= t.deletesPctAllowed || segDelPct <= t.deletesPctAllowed) {
+			totIndexBytes -= segSizeDocs.sizeInBytes
+			allowedDelCount -= segSizeDocs.delCount
+			continue
+		}
+		eligible = append(eligible, segSizeDocs)
+	}
+	sortedInfos = eligible
+	allowedDelCount = max(0, allowedDelCount)
+
+	mergeFactor := int(min(float64(t.maxMergeAtOnce), t.segsPerTier))
+	// Compute max allowed segments in the index
+	levelSize := max(minSegmentBytes, t.floorSegmentBytes)
+	bytesLeft := totIndexBytes
+	allowedSegCount := 0.0
+	for {
+		segCountLevel := float64(bytesLeft) / float64(levelSize)
+		if segCountLevel < t.segsPerTier || levelSize == t.maxMergedSegmentBytes {
+			allowedSegCount += math.Ceil(segCountLevel)
+			break
+		}
+		allowedSegCount += t.segsPerTier
+		bytesLeft -= int64(t.segsPerTier * float64(levelSize))
+		levelSize = min(t.maxMergedSegmentBytes, levelSize*int64(mergeFactor))
+	}
+	// allowedSegCount may occasionally be less than segsPerTier
+	// if segment sizes are below the floor size
+	allowedSegCount = max(allowedSegCount, t.segsPerTier)
+
+	return t.doFindMerges(sortedInfos, t.maxMergedSegmentBytes, mergeFactor, int(allowedSegCount),
+		allowedDelCount, mergeTypeNatural, mergingBytes >= t.maxMergedSegmentBytes)
+}
+
+type mergeType int
+
+const (
+	// mergeTypeNatural Merge was triggered by a segment Flush or a finished merge.
+	mergeTypeNatural = mergeType(iota)
+
+	// mergeTypeForceMergeDeletes Find merges triggered by forceMergeDeletes.
+	mergeTypeForceMergeDeletes
+)
+
+func (t *TieredMergePolicy) doFindMerges(sortedEligibleInfos []*segmentSizeAndDocs, maxMergedSegmentBytes int64,
+	mergeFactor, allowedSegCount, allowedDelCount int, mergeType mergeType, maxMergeIsRunning bool) (*MergeSpecification, error) {
+
+	sortedEligible := slices.Clone(sortedEligibleInfos)
+
+	segInfosSizes := make(map[index.SegmentCommitInfo]*segmentSizeAndDocs, len(sortedEligible))
+	for _, segSizeDocs := range sortedEligible {
+		segInfosSizes[segSizeDocs.segInfo] = segSizeDocs
+	}
+
+	if len(sortedEligible) == 0 {
+		return nil, nil
+	}
+
+	toBeMerged := make(map[index.SegmentCommitInfo]struct{})
+
+	var spec *MergeSpecification
+
+	// Cycle to possibly select more than one merge:
+	// The trigger point for total deleted documents in the index leads to a bunch of large segment
+	// merges at the same time. So only put one large merge in the list of merges per cycle. We'll pick up another
+	// merge next time around.
+	haveOneLargeMerge := false
+
+	for {
+		// Gather eligible segments for merging, ie segments not already being merged and not
+		// already picked (by prior iteration of this loop) for merging
+		sortedEligible = slices.DeleteFunc(sortedEligible, func(segSizeDocs *segmentSizeAndDocs) bool {
+			_, ok := toBeMerged[segSizeDocs.segInfo]
+			return ok
+		})
+
+		if len(sortedEligible) == 0 {
+			return spec, nil
+		}
+
+		remainingDelCount := 0
+		for _, segSizeDocs := range sortedEligible {
+			remainingDelCount += segSizeDocs.delCount
+		}
+		if mergeType == mergeTypeNatural &&
+			len(sortedEligible) <= allowedSegCount &&
+			remainingDelCount <= allowedDelCount {
+			return spec, nil
+		}
+
+		// OK we are over budget -- find best merge!
+		var best []index.SegmentCommitInfo
+		bestScore := 0.0
+		bestTooLarge := false
+
+		for startIdx := 0; startIdx < len(sortedEligible); startIdx++ {
+			totAfterMergeBytes := int64(0)
+
+			candidate := make([]index.SegmentCommitInfo, 0, mergeFactor)
+			hitTooLarge := false
+			bytesThisMerge := int64(0)
+			for idx := startIdx; idx < len(sortedEligible) && len(candidate) < mergeFactor && bytesThisMerge < maxMergedSegmentBytes; idx++ {
+				segSizeDocs := sortedEligible[idx]
+				segBytes := segSizeDocs.sizeInBytes
+
+				if totAfterMergeBytes+segBytes > maxMergedSegmentBytes {
+					hitTooLarge = true
+					if len(candidate) == 0 {
+						// We should never have something coming in that _cannot_ be merged, so handle singleton merges
+						candidate = append(candidate, segSizeDocs.segInfo)
+						bytesThisMerge += segBytes
+					}
+					// NOTE: we continue, so that we can try "packing" smaller segments into this merge
+					// to see if we can get closer to the max size; this in general is not perfect since
+					// this is really "bin packing" and we'd have to try different permutations.
+					continue
+				}
+				candidate = append(candidate, segSizeDocs.segInfo)
+				bytesThisMerge += segBytes
+				totAfterMergeBytes += segBytes
+			}
+
+			// A singleton merge with no deletes makes no sense. We can get here when forceMerge is looping around...
+			if len(candidate) == 1 && segInfosSizes[candidate[0]].delCount == 0 {
+				continue
+			}
+
+			// If we didn't find a too-large merge and have a list of candidates
+			// whose length is less than the merge factor, it means we are reaching
+			// the tail of the list of segments and will only find smaller merges.
+			// Stop here.
+			if best != nil && !hitTooLarge && len(candidate) < mergeFactor {
+				break
+			}
+
+			score, err := t.score(candidate, hitTooLarge, segInfosSizes)
+			if err != nil {
+				return nil, err
+			}
+
+			if (best == nil || score < bestScore) && (!hitTooLarge || !maxMergeIsRunning) {
+				best = candidate
+				bestScore = score
+				bestTooLarge = hitTooLarge
+			}
+		}
+
+		if best == nil {
+			return spec, nil
+		}
+
+		// The mergeType == mergeTypeForceMergeDeletes behaves as the code does currently and can create a large
+		// number of concurrent big merges. If we make FindForcedDeletesMerges behave as FindForcedMerges and
+		// cycle through we should remove this.
+		if !haveOneLargeMerge || !bestTooLarge || mergeType == mergeTypeForceMergeDeletes {
+			haveOneLargeMerge = haveOneLargeMerge || bestTooLarge
+
+			if spec == nil {
+				spec = NewMergeSpecification()
+			}
+			merge, err := NewOneMerge(best)
+			if err != nil {
+				return nil, err
+			}
+			spec.Add(merge)
+		}
+
+		// whether we're going to return this list in the spec of not, we need to remove it from
+		// consideration on the next loop.
+		for _, info := range best {
+			toBeMerged[info] = struct{}{}
+		}
+	}
+}
+
+// score Expert: scores one merge, smaller scores are better.
+func (t *TieredMergePolicy) score(candidate []index.SegmentCommitInfo, hitTooLarge bool,
+	segmentsSizes map[index.SegmentCommitInfo]*segmentSizeAndDocs) (float64, error) {
+
+	totBeforeMergeBytes := int64(0)
+	totAfterMergeBytes := int64(0)
+	totAfterMergeBytesFloored := int64(0)
+	for _, info := range candidate {
+		segBytes := segmentsSizes[info].sizeInBytes
+		totAfterMergeBytes += segBytes
+		totAfterMergeBytesFloored += t.floorSize(segBytes)
+		sizeInBytes, err := info.SizeInBytes()
+		if err != nil {
+			return 0, err
+		}
+		totBeforeMergeBytes += sizeInBytes
+	}
+
+	// Roughly measure "skew" of the merge, i.e. how "balanced" the merge is
+	// (whether it divides into equal-sized segments or not):
+	var skew float64
+	if hitTooLarge {
+		// Pretend the merge has perfect skew; skew doesn't matter in this case because this
+		// merge will not "cascade" and so it cannot lead to N^2 merge cost over time:
+		mergeFactor := int(min(float64(t.maxMergeAtOnce), t.segsPerTier))
+		skew = 1.0 / float64(mergeFactor)
+	} else {
+		skew = float64(t.floorSize(segmentsSizes[candidate[0]].sizeInBytes)) / float64(totAfterMergeBytesFloored)
+	}
+
+	// Strongly favor merges with less skew (smaller mergeScore is better):
+	mergeScore := skew
+
+	// Gently favor smaller merges over bigger ones. We don't want to make this exponent too large
+	// else we can end up doing poor merges of small segments in order to avoid the large merges:
+	mergeScore *= math.Pow(float64(totAfterMergeBytes), 0.05)
+
+	// Strongly favor merges that reclaim deletes:
+	if totBeforeMergeBytes > 0 {
+		nonDelRatio := float64(totAfterMergeBytes) / float64(totBeforeMergeBytes)
+		mergeScore *= math.Pow(nonDelRatio, 2)
+	}
+	return mergeScore, nil
+}
+
+func (t *TieredMergePolicy) FindForcedMerges(infos *SegmentInfos, maxSegmentCount int,
+	segmentsToMerge map[index.SegmentCommitInfo]bool, mergeContext MergeContext) (*MergeSpecification, error) {
+
+	sortedSizeAndDocs, err := t.getSortedBySegmentSize(infos, mergeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	totalMergeBytes := int64(0)
+	merging := mergingSegmentSet(mergeContext)
+
+	// Trim the list down, remove if we're respecting max segment size and it's not original.
+	// Presumably it's been merged before and is close enough to the max segment size we
+	// shouldn't add it in again.
+	forceMergeRunning := false
+	eligible := sortedSizeAndDocs[:0]
+	for _, segSizeDocs := range sortedSizeAndDocs {
+		if _, ok := segmentsToMerge[segSizeDocs.segInfo]; !ok {
+			continue
+		}
+		if _, ok := merging[segSizeDocs.segInfo]; ok {
+			forceMergeRunning = true
+			continue
+		}
+		totalMergeBytes += segSizeDocs.sizeInBytes
+		eligible = append(eligible, segSizeDocs)
+	}
+	sortedSizeAndDocs = eligible
+
+	maxMergeBytes := t.maxMergedSegmentBytes
+
+	// Set the maximum segment size based on how many segments have been specified.
+	if maxSegmentCount == 1 {
+		maxMergeBytes = math.MaxInt64
+	} else if maxSegmentCount != math.MaxInt32 {
+		// Fudge this up a bit so we have a better chance of not having to rewrite segments. If we use the exact size,
+		// it's almost guaranteed that the segments won't fit perfectly and we'll be left with more segments than
+		// we want and have to re-merge in the code at the bottom of this method.
+		maxMergeBytes = max(int64(float64(totalMergeBytes)/float64(maxSegmentCount)), t.maxMergedSegmentBytes)
+		maxMergeBytes = int64(float64(maxMergeBytes) * 1.25)
+	}
+
+	foundDeletes := false
+	eligible = sortedSizeAndDocs[:0]
+	for _, segSizeDocs := range sortedSizeAndDocs {
+		isOriginal, ok := segmentsToMerge[segSizeDocs.segInfo]
+		if segSizeDocs.delCount != 0 {
+			// This is forceMerge, all segments with deleted docs should be merged.
+			if ok && isOriginal {
+				foundDeletes = true
+			}
+			eligible = append(eligible, segSizeDocs)
+			continue
+		}
+		// Let the scoring handle whether to merge large segments.
+		if maxSegmentCount == math.MaxInt32 && ok && !isOriginal {
+			continue
+		}
+		// Don't try to merge a segment with no deleted docs that's over the max size.
+		if maxSegmentCount != math.MaxInt32 && segSizeDocs.sizeInBytes >= maxMergeBytes {
+			continue
+		}
+		eligible = append(eligible, segSizeDocs)
+	}
+	sortedSizeAndDocs = eligible
+
+	// Nothing to merge this round.
+	if len(sortedSizeAndDocs) == 0 {
+		return nil, nil
+	}
+
+	// We only bail if there are no deletions
+	if !foundDeletes {
+		infoZero := sortedSizeAndDocs[0].segInfo
+		if maxSegmentCount != math.MaxInt32 && maxSegmentCount > 1 && len(sortedSizeAndDocs) <= maxSegmentCount {
+			return nil, nil
+		}
+		if maxSegmentCount == 1 && len(sortedSizeAndDocs) == 1 {
+			if _, ok := segmentsToMerge[infoZero]; ok {
+				return nil, nil
+			}
+			merged, err := t.IsMerged(infos, infoZero, mergeContext)
+			if err != nil {
+				return nil, err
+			}
+			if merged {
+				return nil, nil
+			}
+		}
+	}
+
+	// This is the special case of merging down to one segment
+	if len(sortedSizeAndDocs) < t.maxMergeAtOnceExplicit && maxSegmentCount == 1 && totalMergeBytes < maxMergeBytes {
+		allOfThem := make([]index.SegmentCommitInfo, 0, len(sortedSizeAndDocs))
+		for _, segSizeDocs := range sortedSizeAndDocs {
+			allOfThem = append(allOfThem, segSizeDocs.segInfo)
+		}
+		merge, err := NewOneMerge(allOfThem)
+		if err != nil {
+			return nil, err
+		}
+		spec := NewMergeSpecification()
+		spec.Add(merge)
+		return spec, nil
+	}
+
+	var spec *MergeSpecification
+
+	idx := len(sortedSizeAndDocs) - 1
+	resultingSegments := len(sortedSizeAndDocs)
+	for {
+		candidate := make([]index.SegmentCommitInfo, 0)
+		currentCandidateBytes := int64(0)
+		mergesAllowed := t.maxMergeAtOnceExplicit
+		for idx >= 0 && resultingSegments > maxSegmentCount && mergesAllowed > 0 {
+			current := sortedSizeAndDocs[idx].segInfo
+			initialCandidateSize := len(candidate)
+			currentSegmentSize, err := current.SizeInBytes()
+			if err != nil {
+				return nil, err
+			}
+			// We either add to the bin because there's space or because the it is the smallest possible bin since
+			// decrementing the index will move us to even larger segments.
+			if currentCandidateBytes+currentSegmentSize > maxMergeBytes && initialCandidateSize >= 2 {
+				break
+			}
+			candidate = append(candidate, current)
+			idx--
+			currentCandidateBytes += currentSegmentSize
+			mergesAllowed--
+			if initialCandidateSize > 0 {
+				// Any merge that handles two or more segments reduces the resulting number of segments
+				// by the number of segments handled - 1
+				resultingSegments--
+			}
+		}
+
+		// While a force merge is running, only merges that cover the maximum allowed number of segments or
+		// that create a segment close to the maximum allowed segment sized are permitted
+		candidateSize := len(candidate)
+		if candidateSize <= 1 || (forceMergeRunning && candidateSize != t.maxMergeAtOnceExplicit &&
+			float64(currentCandidateBytes) <= 0.7*float64(maxMergeBytes)) {
+			return spec, nil
+		}
+
+		merge, err := NewOneMerge(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			spec = NewMergeSpecification()
+		}
+		spec.Add(merge)
+	}
+}
+
+func (t *TieredMergePolicy) FindForcedDeletesMerges(infos *SegmentInfos, mergeContext MergeContext) (*MergeSpecification, error) {
+	merging := mergingSegmentSet(mergeContext)
+
+	haveWork := false
+	for _, info := range infos.AsList() {
+		delCount, err := mergeContext.NumDeletesToMerge(info)
+		if err != nil {
+			return nil, err
+		}
+		maxDoc, err := info.Info().MaxDoc()
+		if err != nil {
+			return nil, err
+		}
+		pctDeletes := 100. * float64(delCount) / float64(maxDoc)
+		if _, ok := merging[info]; !ok && pctDeletes > t.forceMergeDeletesPctAllowed {
+			haveWork = true
+			break
+		}
+	}
+
+	if !haveWork {
+		return nil, nil
+	}
+
+	sortedInfos, err := t.getSortedBySegmentSize(infos, mergeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	sortedInfos = slices.DeleteFunc(sortedInfos, func(segSizeDocs *segmentSizeAndDocs) bool {
+		pctDeletes := 100. * float64(segSizeDocs.delCount) / float64(segSizeDocs.maxDoc)
+		_, ok := merging[segSizeDocs.segInfo]
+		return ok || pctDeletes <= t.forceMergeDeletesPctAllowed
+	})
+
+	return t.doFindMerges(sortedInfos, t.maxMergedSegmentBytes, t.maxMergeAtOnceExplicit,
+		math.MaxInt32, 0, mergeTypeForceMergeDeletes, false)
+}
+
+func (t *TieredMergePolicy) floorSize(bytes int64) int64 {
+	return max(t.floorSegmentBytes, bytes)
+}
+
+func (t *TieredMergePolicy) String() string {
+	return fmt.Sprintf("[TieredMergePolicy: maxMergeAtOnce=%d, maxMergeAtOnceExplicit=%d, maxMergedSegmentMB=%v, "+
+		"floorSegmentMB=%v, forceMergeDeletesPctAllowed=%v, segmentsPerTier=%v, maxCFSSegmentSizeMB=%v, "+
+		"noCFSRatio=%v, deletesPctAllowed=%v",
+		t.maxMergeAtOnce, t.maxMergeAtOnceExplicit, t.GetMaxMergedSegmentMB(),
+		t.GetFloorSegmentMB(), t.forceMergeDeletesPctAllowed, t.segsPerTier, t.GetMaxCFSSegmentSizeMB(),
+		t.noCFSRatio, t.deletesPctAllowed)
+}
@@ -0,0 +1,308 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/util"
+	"github.com/geange/lucene-go/core/util/version"
+	"github.com/stretchr/testify/assert"
+)
+
+const mb = 1024 * 1024
+
+// sizedDirectory Reports the configured length for the files of synthetic segments, no file is
+// written
+type sizedDirectory struct {
+	store.Directory
+
+	lengths map[string]int64
+}
+
+func (d *sizedDirectory) FileLength(ctx context.Context, name string) (int64, error) {
+	return d.lengths[name], nil
+}
+
+// testMergeContext Reports the deletes of a segment from its commit info
+type testMergeContext struct {
+	merging []index.SegmentCommitInfo
+}
+
+func (c *testMergeContext) NumDeletesToMerge(info index.SegmentCommitInfo) (int, error) {
+	return info.GetDelCount(), nil
+}
+
+func (c *testMergeContext) NumDeletedDocs(info index.SegmentCommitInfo) int {
+	return info.GetDelCount()
+}
+
+func (c *testMergeContext) GetMergingSegments() []index.SegmentCommitInfo {
+	return c.merging
+}
+
+// syntheticSegments Builds the SegmentInfos of segments which are only described by their size and
+// number of documents
+type syntheticSegments struct {
+	t     *testing.T
+	dir   *sizedDirectory
+	infos *coreIndex.SegmentInfos
+}
+
+func newSyntheticSegments(t *testing.T) *syntheticSegments {
+	return &syntheticSegments{
+		t:     t,
+		dir:   &sizedDirectory{Directory: store.NewRAMDirectory(), lengths: map[string]int64{}},
+		infos: coreIndex.NewSegmentInfos(int(version.Last.Major())),
+	}
+}
+
+// add Adds a segment of sizeInBytes bytes holding maxDoc documents, delCount of them are deleted
+func (s *syntheticSegments) add(sizeInBytes int64, maxDoc, delCount int) index.SegmentCommitInfo {
+	name := fmt.Sprintf("_%d", s.infos.Size())
+	si := coreIndex.NewSegmentInfo(s.dir, version.Last, version.Last, name, maxDoc, false,
+		lucene87.NewCodec(), map[string]string{}, util.RandomId(), map[string]string{}, nil)
+	si.SetFiles(map[string]struct{}{name + ".si": {}})
+	s.dir.lengths[name+".si"] = sizeInBytes
+
+	delGen := int64(-1)
+	if delCount > 0 {
+		delGen = 1
+	}
+	info := index.NewSegmentCommitInfo(si, delCount, 0, delGen, -1, -1, util.RandomId())
+	assert.Nil(s.t, s.infos.Add(info))
+	return info
+}
+
+// addN Adds n segments without deletes
+func (s *syntheticSegments) addN(n int, sizeInBytes int64, maxDoc int) []index.SegmentCommitInfo {
+	infos := make([]index.SegmentCommitInfo, 0, n)
+	for i := 0; i < n; i++ {
+		infos = append(infos, s.add(sizeInBytes, maxDoc, 0))
+	}
+	return infos
+}
+
+// mergedSegments Returns the segments of every merge of spec
+func mergedSegments(spec *coreIndex.MergeSpecification) [][]index.SegmentCommitInfo {
+	if spec == nil {
+		return nil
+	}
+	segments := make([][]index.SegmentCommitInfo, 0)
+	for _, merge := range spec.Merges() {
+		segments = append(segments, merge.Segments())
+	}
+	return segments
+}
+
+// mergeSize Returns the size in bytes of the merged segments
+func mergeSize(t *testing.T, segments []index.SegmentCommitInfo) int64 {
+	total := int64(0)
+	for _, info := range segments {
+		size, err := info.SizeInBytes()
+		assert.Nil(t, err)
+		total += size
+	}
+	return total
+}
+
+func TestTieredMergePolicy_FindMergesSegmentsPerTier(t *testing.T) {
+	policy := coreIndex.NewTieredMergePolicy()
+
+	// 10 segments below the floor size are within the budget of one tier
+	segments := newSyntheticSegments(t)
+	segments.addN(10, 1*mb, 100)
+	spec, err := policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	// one more segment is over budget, 10 segments are merged at once
+	small := segments.add(512*1024, 50, 0)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.Len(t, merges[0], 10)
+	// segments below the floor size count as equal, the smaller merge is favored
+	assert.Contains(t, merges[0], small)
+
+	// segments which are merging already are not merged again
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos,
+		&testMergeContext{merging: merges[0]})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	// fewer segments per tier are allowed, merges take at most segmentsPerTier segments
+	assert.Nil(t, policy.SetSegmentsPerTier(5))
+	segments = newSyntheticSegments(t)
+	segments.addN(6, 1*mb, 100)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.Len(t, merges[0], 5)
+}
+
+func TestTieredMergePolicy_FindMergesMaxMergedSegmentSize(t *testing.T) {
+	policy := coreIndex.NewTieredMergePolicy()
+	assert.Nil(t, policy.SetMaxMergedSegmentMB(10))
+
+	segments := newSyntheticSegments(t)
+	// a segment over half of the max merged segment size with few deletes is left alone
+	large := segments.add(6*mb, 600, 0)
+	segments.addN(30, 3*mb, 300)
+
+	spec, err := policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	assert.NotEmpty(t, merges)
+	for _, merge := range merges {
+		assert.NotContains(t, merge, large)
+		assert.LessOrEqual(t, mergeSize(t, merge), int64(10*mb))
+		assert.Greater(t, len(merge), 1)
+	}
+}
+
+func TestTieredMergePolicy_FindMergesReclaimsDeletes(t *testing.T) {
+	policy := coreIndex.NewTieredMergePolicy()
+
+	// within the segment budget and below deletesPctAllowed, nothing is merged
+	segments := newSyntheticSegments(t)
+	segments.addN(4, 4*mb, 1000)
+	segments.add(4*mb, 1000, 100)
+	spec, err := policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	// more than 33% of the documents are deleted, the segment with the most deletes is merged even
+	// though the index is within its segment budget
+	segments = newSyntheticSegments(t)
+	segments.addN(2, 4*mb, 1000)
+	deleted := segments.add(4*mb, 1000, 900)
+	segments.add(4*mb, 1000, 600)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.Contains(t, merges[0], deleted)
+
+	// 28% of the documents are deleted, a lower deletesPctAllowed reclaims them
+	segments = newSyntheticSegments(t)
+	segments.addN(3, 4*mb, 1000)
+	first := segments.add(4*mb, 1000, 700)
+	second := segments.add(4*mb, 1000, 700)
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	assert.Nil(t, policy.SetDeletesPctAllowed(20))
+	spec, err = policy.FindMerges(coreIndex.MERGE_TRIGGER_SEGMENT_FLUSH, segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.Subset(t, merges[0], []index.SegmentCommitInfo{first, second})
+}
+
+func TestTieredMergePolicy_FindForcedMerges(t *testing.T) {
+	policy := coreIndex.NewTieredMergePolicy()
+
+	segments := newSyntheticSegments(t)
+	all := segments.addN(5, 4*mb, 100)
+	toMerge := map[index.SegmentCommitInfo]bool{}
+	for _, info := range all {
+		toMerge[info] = true
+	}
+
+	// merging down to one segment merges all of them at once
+	spec, err := policy.FindForcedMerges(segments.infos, 1, toMerge, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, all, merges[0])
+
+	// there are fewer segments than allowed
+	spec, err = policy.FindForcedMerges(segments.infos, 5, toMerge, &testMergeContext{})
+	assert.Nil(t, err)
+	assert.Empty(t, mergedSegments(spec))
+
+	// the smallest segments are merged until maxSegmentCount segments are left
+	segments = newSyntheticSegments(t)
+	large := segments.addN(2, 8*mb, 200)
+	small := segments.addN(3, 1*mb, 25)
+	toMerge = map[index.SegmentCommitInfo]bool{}
+	for _, info := range segments.infos.AsList() {
+		toMerge[info] = true
+	}
+	spec, err = policy.FindForcedMerges(segments.infos, 3, toMerge, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, small, merges[0])
+
+	// segments which are not part of the forced merge are never merged
+	delete(toMerge, large[0])
+	delete(toMerge, large[1])
+	spec, err = policy.FindForcedMerges(segments.infos, 1, toMerge, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, small, merges[0])
+
+	// a segment over the max merged segment size without deletes is left as it is
+	assert.Nil(t, policy.SetMaxMergedSegmentMB(5))
+	segments = newSyntheticSegments(t)
+	huge := segments.add(100*mb, 1000, 0)
+	rest := segments.addN(4, 1*mb, 10)
+	toMerge = map[index.SegmentCommitInfo]bool{}
+	for _, info := range segments.infos.AsList() {
+		toMerge[info] = true
+	}
+	spec, err = policy.FindForcedMerges(segments.infos, 2, toMerge, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.NotEmpty(t, merges)
+	for _, merge := range merges {
+		assert.NotContains(t, merge, huge)
+		assert.Subset(t, rest, merge)
+	}
+}
+
+func TestTieredMergePolicy_FindForcedDeletesMerges(t *testing.T) {
+	policy := coreIndex.NewTieredMergePolicy()
+
+	segments := newSyntheticSegments(t)
+	segments.addN(3, 4*mb, 100)
+	segments.add(4*mb, 100, 5)
+	spec, err := policy.FindForcedDeletesMerges(segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	// no segment has more than forceMergeDeletesPctAllowed deletes
+	assert.Empty(t, mergedSegments(spec))
+
+	many := segments.add(4*mb, 100, 20)
+	more := segments.add(2*mb, 100, 50)
+	spec, err = policy.FindForcedDeletesMerges(segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges := mergedSegments(spec)
+	// only the segments over the threshold are merged, together
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, []index.SegmentCommitInfo{many, more}, merges[0])
+
+	// a segment which is merging already is skipped, a single segment with deletes is merged alone
+	spec, err = policy.FindForcedDeletesMerges(segments.infos,
+		&testMergeContext{merging: []index.SegmentCommitInfo{more}})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, []index.SegmentCommitInfo{many}, merges[0])
+
+	assert.Nil(t, policy.SetForceMergeDeletesPctAllowed(30))
+	spec, err = policy.FindForcedDeletesMerges(segments.infos, &testMergeContext{})
+	assert.Nil(t, err)
+	merges = mergedSegments(spec)
+	assert.Len(t, merges, 1)
+	assert.ElementsMatch(t, []index.SegmentCommitInfo{more}, merges[0])
+}
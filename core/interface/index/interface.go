@@ -12,10 +12,15 @@ import (
 	"github.com/geange/lucene-go/core/util"
 	"github.com/geange/lucene-go/core/util/attribute"
 	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/geange/lucene-go/core/util/version"
 )
 
 type LeafMetaData interface {
 	GetSort() Sort
+
+	// GetMinVersion Return the minimum Lucene version that contributed documents to this index,
+	// or nil if this information is not available.
+	GetMinVersion() *version.Version
 }
 
 type Term interface {
@@ -329,6 +334,15 @@ type LeafReader interface {
 	// without additional synchronization.
 	GetLiveDocs() util.Bits
 
+	// GetCoreCacheHelper
+	// Optional method: Return a CacheHelper that can be used to cache based on the content of this leaf
+	// regardless of deletions. Two readers that have the same data but different sets of deleted documents
+	// or doc values updates may be considered equal. Consider using GetReaderCacheHelper if you need
+	// deletions or dv updates to be taken into account.
+	// A return item of nil indicates that this reader is not suited for caching, which is typically the
+	// case for short-lived wrappers that alter the content of the wrapped leaf reader.
+	GetCoreCacheHelper() CacheHelper
+
 	// GetPointValues
 	// Returns the PointValues used for numeric or spatial searches for the given field, or null
 	// if there are no point fields.
@@ -591,6 +605,10 @@ type FieldInfos interface {
 	FieldInfoByNumber(fieldNumber int) *document.FieldInfo
 	Size() int
 	List() []*document.FieldInfo
+	HasFreq() bool
+	HasProx() bool
+	HasPayloads() bool
+	HasOffsets() bool
 	HasNorms() bool
 	HasDocValues() bool
 	HasVectors() bool
@@ -684,7 +702,7 @@ type SortField interface {
 	//		if sortPos==1, etc. Some comparators can optimize themselves when they are the primary sort.
 	// Returns: FieldComparator to use when sorting
 	// lucene.experimental
-	GetComparator(numHits, sortPos int) FieldComparator
+	GetComparator(numHits, sortPos int) (FieldComparator, error)
 
 	//rewrite(searcher search.IndexSearcher)
 
@@ -866,6 +884,19 @@ type CacheHelper interface {
 	// Get a key that the resource can be cached on. The given entry can be compared using identity,
 	// ie. Object.equals is implemented as == and Object.hashCode is implemented as System.identityHashCode.
 	GetKey() string
+
+	// AddClosedListener
+	// Add a ClosedListener which will be called when the resource guarded by GetKey() is closed.
+	AddClosedListener(listener ClosedListener)
+}
+
+// ClosedListener
+// A listener that is called when a resource gets closed.
+// lucene.experimental
+type ClosedListener interface {
+	// OnClose
+	// Invoked when the resource (segment core, or index reader) that is being cached on is closed.
+	OnClose(key string) error
 }
 
 // SeekStatus Represents returned result from seekCeil.
@@ -228,6 +228,7 @@ type IndexSearcher interface {
 	SetSimilarity(similarity Similarity)
 	GetSimilarity() Similarity
 	Count(query Query) (int, error)
+	Explain(query Query, doc int) (types.Explanation, error)
 	GetSlices() []LeafSlice
 	CreateWeight(query Query, scoreMode ScoreMode, boost float64) (Weight, error)
 	TermStatistics(term Term, docFreq, totalTermFreq int) (types.TermStatistics, error)
@@ -317,6 +318,15 @@ type Query interface {
 	// String
 	// Convert a query to a string, with field assumed to be the default field and omitted.
 	String(field string) string
+
+	// Equals
+	// Returns true if other is a query of the same type that matches the same documents with the
+	// same scores. The query cache relies on it to find the cached entries of a query.
+	Equals(other Query) bool
+
+	// HashCode
+	// Returns a hash code for this query, queries that are equal must have the same hash code.
+	HashCode() int
 }
 
 type QueryExt interface {
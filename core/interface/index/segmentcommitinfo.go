@@ -133,7 +133,7 @@ func newSegmentCommitInfo(info SegmentInfo, delCount, softDelCount int,
 		nextWriteDocValuesGen:  nextWriteDocValuesGen,
 		dvUpdatesFiles:         map[int]map[string]struct{}{},
 		fieldInfosFiles:        map[string]struct{}{},
-		sizeInBytes:            0,
+		sizeInBytes:            -1,
 		bufferedDeletesGen:     0,
 	}
 }
@@ -325,8 +325,9 @@ func (s *segmentCommitInfo) AdvanceDelGen() {
 
 func (s *segmentCommitInfo) generationAdvanced() {
 	s.sizeInBytes = -1
+	// a segment id is 16 bytes, like the ids of SegmentInfo
 	r, _ := uuid.NewRandom()
-	s.id = []byte(r.String())
+	s.id = r[:]
 }
 
 func (s *segmentCommitInfo) GetBufferedDeletesGen() int64 {
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"bytes"
 	"context"
 
 	coreIndex "github.com/geange/lucene-go/core/index"
@@ -35,6 +36,24 @@ func (q *BinaryRangeFieldRangeQuery) getValues(reader index.LeafReader, field st
 	return NewBinaryRangeDocValues(binaryDocValues, q.numDims, q.numBytesPerDimension), nil
 }
 
+// equalsTo Compares the fields of two range queries, the queries that embed
+// BinaryRangeFieldRangeQuery check their own type before.
+func (q *BinaryRangeFieldRangeQuery) equalsTo(other *BinaryRangeFieldRangeQuery) bool {
+	return q.field == other.field &&
+		q.numDims == other.numDims &&
+		q.numBytesPerDimension == other.numBytesPerDimension &&
+		q.queryType == other.queryType &&
+		bytes.Equal(q.queryPackedValue, other.queryPackedValue)
+}
+
+func (q *BinaryRangeFieldRangeQuery) fieldsHashCode() int {
+	h := search.HashString(q.field)
+	h = 31*h + q.numDims
+	h = 31*h + q.numBytesPerDimension
+	h = 31*h + search.HashBytes(q.queryPackedValue)
+	return h
+}
+
 var _ index.Weight = &binaryRangeFieldRangeWeight{}
 
 type binaryRangeFieldRangeWeight struct {
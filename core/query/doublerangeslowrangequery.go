@@ -3,6 +3,7 @@ package query
 import (
 	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
 )
 
 type DoubleRangeSlowRangeQuery struct {
@@ -41,6 +42,15 @@ func (q *DoubleRangeSlowRangeQuery) Visit(visitor index.QueryVisitor) error {
 	return rangeQueryVisit(q.field, q, visitor)
 }
 
+func (q *DoubleRangeSlowRangeQuery) Equals(other index.Query) bool {
+	o, ok := other.(*DoubleRangeSlowRangeQuery)
+	return ok && q.equalsTo(o.BinaryRangeFieldRangeQuery)
+}
+
+func (q *DoubleRangeSlowRangeQuery) HashCode() int {
+	return 31*search.ClassHash("DoubleRangeSlowRangeQuery") + q.fieldsHashCode()
+}
+
 func encodeDoubleRanges(mins, maxs []float64) ([]byte, error) {
 	dst := make([]byte, 2*document.DOUBLE_BYTES*len(mins))
 	if err := verifyAndEncodeFloat64(mins, maxs, dst); err != nil {
@@ -3,6 +3,7 @@ package query
 import (
 	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
 )
 
 type FloatRangeSlowRangeQuery struct {
@@ -41,6 +42,15 @@ func (q *FloatRangeSlowRangeQuery) Visit(visitor index.QueryVisitor) error {
 	return rangeQueryVisit(q.field, q, visitor)
 }
 
+func (q *FloatRangeSlowRangeQuery) Equals(other index.Query) bool {
+	o, ok := other.(*FloatRangeSlowRangeQuery)
+	return ok && q.equalsTo(o.BinaryRangeFieldRangeQuery)
+}
+
+func (q *FloatRangeSlowRangeQuery) HashCode() int {
+	return 31*search.ClassHash("FloatRangeSlowRangeQuery") + q.fieldsHashCode()
+}
+
 func encodeFloatRanges(mins, maxs []float32) ([]byte, error) {
 	dst := make([]byte, 2*document.FLOAT_BYTES*len(mins))
 	if err := verifyAndEncodeFloat32(mins, maxs, dst); err != nil {
@@ -3,6 +3,7 @@ package query
 import (
 	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
 )
 
 var _ index.Query = &IntRangeSlowRangeQuery{}
@@ -43,6 +44,15 @@ func (q *IntRangeSlowRangeQuery) Visit(visitor index.QueryVisitor) error {
 	return rangeQueryVisit(q.field, q, visitor)
 }
 
+func (q *IntRangeSlowRangeQuery) Equals(other index.Query) bool {
+	o, ok := other.(*IntRangeSlowRangeQuery)
+	return ok && q.equalsTo(o.BinaryRangeFieldRangeQuery)
+}
+
+func (q *IntRangeSlowRangeQuery) HashCode() int {
+	return 31*search.ClassHash("IntRangeSlowRangeQuery") + q.fieldsHashCode()
+}
+
 func encodeIntRanges(mins, maxs []int32) ([]byte, error) {
 	dst := make([]byte, 2*document.INTEGER_BYTES*len(mins))
 	if err := verifyAndEncodeInt32(mins, maxs, dst); err != nil {
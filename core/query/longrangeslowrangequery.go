@@ -3,6 +3,7 @@ package query
 import (
 	"github.com/geange/lucene-go/core/document"
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
 )
 
 type LongRangeSlowRangeQuery struct {
@@ -41,6 +42,15 @@ func (q *LongRangeSlowRangeQuery) Visit(visitor index.QueryVisitor) error {
 	return rangeQueryVisit(q.field, q, visitor)
 }
 
+func (q *LongRangeSlowRangeQuery) Equals(other index.Query) bool {
+	o, ok := other.(*LongRangeSlowRangeQuery)
+	return ok && q.equalsTo(o.BinaryRangeFieldRangeQuery)
+}
+
+func (q *LongRangeSlowRangeQuery) HashCode() int {
+	return 31*search.ClassHash("LongRangeSlowRangeQuery") + q.fieldsHashCode()
+}
+
 func encodeLongRanges(mins, maxs []int64) ([]byte, error) {
 	dst := make([]byte, 2*document.LONG_BYTES*len(mins))
 	if err := verifyAndEncodeInt64(mins, maxs, dst); err != nil {
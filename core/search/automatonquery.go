@@ -1,7 +1,9 @@
 package search
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 
 	coreIndex "github.com/geange/lucene-go/core/index"
 	"github.com/geange/lucene-go/core/interface/index"
@@ -37,14 +39,30 @@ type AutomatonQuery struct {
 	rewriteMethod     RewriteMethod
 }
 
-func NewAutomatonQuery(term index.Term, auto *automaton.Automaton, determinizeWorkLimit int, isBinary bool) *AutomatonQuery {
+// NewAutomatonQuery
+// Create a new AutomatonQuery from an Automaton.
+//
+// term: Term containing field and possibly some pattern structure. The term text is ignored.
+// auto: Automaton to run, terms that are accepted are considered a match.
+// determinizeWorkLimit: maximum effort to spend determinizing the automaton. If the automaton
+// will need to be determinized and would require more than this much effort,
+// automaton.ErrTooComplexToDeterminize is returned. Higher numbers require more space but can
+// process more complex automata.
+// isBinary: if true, this automaton is already binary and will not go through the
+// UTF32ToUTF8 conversion
+func NewAutomatonQuery(term index.Term, auto *automaton.Automaton, determinizeWorkLimit int, isBinary bool) (*AutomatonQuery, error) {
+	compiled, err := automaton.NewCompiledAutomaton(auto, nil, true, determinizeWorkLimit, isBinary)
+	if err != nil {
+		return nil, err
+	}
 	return &AutomatonQuery{
 		field:             term.Field(),
 		automaton:         auto,
 		term:              term,
 		automatonIsBinary: isBinary,
-		compiled:          automaton.NewCompiledAutomaton(auto, nil, true, determinizeWorkLimit, isBinary),
-	}
+		compiled:          compiled,
+		rewriteMethod:     CONSTANT_SCORE_REWRITE,
+	}, nil
 }
 
 func (r *AutomatonQuery) GetField() string {
@@ -75,26 +93,43 @@ func GetTermsEnum(r *automaton.CompiledAutomaton, terms index.Terms) (index.Term
 }
 
 func (r *AutomatonQuery) GetRewriteMethod() RewriteMethod {
-	//TODO implement me
-	panic("implement me")
+	return r.rewriteMethod
 }
 
 func (r *AutomatonQuery) SetRewriteMethod(method RewriteMethod) {
-	//TODO implement me
-	panic("implement me")
+	r.rewriteMethod = method
 }
 
 func (r *AutomatonQuery) String(field string) string {
-	//TODO implement me
-	panic("implement me")
+	buf := new(bytes.Buffer)
+	if r.term.Field() != field {
+		buf.WriteString(r.term.Field())
+		buf.WriteString(":")
+	}
+	buf.WriteString("AutomatonQuery {\n")
+	buf.WriteString(r.automaton.ToDot())
+	buf.WriteString("}")
+	return buf.String()
 }
 
 func (r *AutomatonQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
-	return nil, errors.New("implement me")
+	return nil, fmt.Errorf("query %s does not implement createWeight, it must be rewritten first", r.String(""))
 }
 
 func (r *AutomatonQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
-	return r, nil
+	return r.rewriteMethod.Rewrite(reader, r)
+}
+
+// GetAutomaton
+// Returns the automaton used to create this query
+func (r *AutomatonQuery) GetAutomaton() *automaton.Automaton {
+	return r.automaton
+}
+
+// IsAutomatonBinary
+// Is this a binary (byte) oriented automaton. See the constructor.
+func (r *AutomatonQuery) IsAutomatonBinary() bool {
+	return r.automatonIsBinary
 }
 
 func (r *AutomatonQuery) Visit(visitor index.QueryVisitor) error {
@@ -114,7 +149,9 @@ func visit(auto *automaton.CompiledAutomaton, visitor index.QueryVisitor, parent
 		case automaton.AUTOMATON_TYPE_NONE:
 		case automaton.AUTOMATON_TYPE_ALL:
 			visitor.ConsumeTermsMatching(parent, field, func() *automaton.ByteRunAutomaton {
-				return automaton.NewByteRunAutomaton(automaton.MakeAnyString())
+				// a single state accepting any byte is already deterministic
+				runAutomaton, _ := automaton.NewByteRunAutomatonV1(automaton.MakeAnyBinary(), true, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+				return runAutomaton
 			})
 		case automaton.AUTOMATON_TYPE_SINGLE:
 			visitor.ConsumeTerms(parent, coreIndex.NewTerm(field, auto.Term()))
@@ -124,3 +161,63 @@ func visit(auto *automaton.CompiledAutomaton, visitor index.QueryVisitor, parent
 	}
 	return nil
 }
+
+func (r *AutomatonQuery) Equals(other index.Query) bool {
+	o, ok := other.(*AutomatonQuery)
+	return ok && r.equalsTo(o)
+}
+
+func (r *AutomatonQuery) HashCode() int {
+	return 31*ClassHash("AutomatonQuery") + r.fieldsHashCode()
+}
+
+// equalsTo Compares the fields of two automaton queries, the queries that embed AutomatonQuery
+// check their own type before.
+func (r *AutomatonQuery) equalsTo(other *AutomatonQuery) bool {
+	return r.field == other.field &&
+		TermEquals(r.term, other.term) &&
+		r.automatonIsBinary == other.automatonIsBinary &&
+		r.rewriteMethod == other.rewriteMethod &&
+		automatonEquals(r.automaton, other.automaton)
+}
+
+func (r *AutomatonQuery) fieldsHashCode() int {
+	h := HashString(r.field)
+	h = 31*h + TermHashCode(r.term)
+	if r.automaton != nil {
+		h = 31*h + r.automaton.GetNumStates()
+		h = 31*h + r.automaton.GetNumTransitions()
+	}
+	return h
+}
+
+// automatonEquals Returns true if both automata have the same states and transitions.
+func automatonEquals(a, b *automaton.Automaton) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.GetNumStates() != b.GetNumStates() || a.GetNumTransitions() != b.GetNumTransitions() {
+		return false
+	}
+
+	var transitionA, transitionB automaton.Transition
+	for state := 0; state < a.GetNumStates(); state++ {
+		if a.IsAccept(state) != b.IsAccept(state) {
+			return false
+		}
+		count := a.InitTransition(state, &transitionA)
+		if count != b.InitTransition(state, &transitionB) {
+			return false
+		}
+		for i := 0; i < count; i++ {
+			a.GetNextTransition(&transitionA)
+			b.GetNextTransition(&transitionB)
+			if transitionA.Dest != transitionB.Dest ||
+				transitionA.Min != transitionB.Min ||
+				transitionA.Max != transitionB.Max {
+				return false
+			}
+		}
+	}
+	return true
+}
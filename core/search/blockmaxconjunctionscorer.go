@@ -158,6 +158,7 @@ func (b *BlockMaxConjunctionScorer) TwoPhaseIterator() index.TwoPhaseIterator {
 	return &bmcTwoPhaseIterator{
 		approx:    approx,
 		matchCost: cost,
+		p:         b,
 	}
 }
 
@@ -42,6 +42,7 @@ func NewBoolean2ScorerSupplier(weight index.Weight, subs map[index.Occur][]index
 		subs:           subs,
 		scoreMode:      scoreMode,
 		minShouldMatch: minShouldMatch,
+		cost:           -1,
 	}, nil
 }
 
@@ -237,15 +238,10 @@ func (b *Boolean2ScorerSupplier) opt(optional []index.ScorerSupplier, minShouldM
 		optionalScorers = append(optionalScorers, scorer)
 	}
 
-	// Technically speaking, WANDScorer should be able to handle the following 3 conditions now
-	// 1. Any ScoreMode (with scoring or not)
-	// 2. Any minCompetitiveScore ( >= 0 )
-	// 3. Any minShouldMatch ( >= 0 )
-	//
-	// However, as WANDScorer uses more complex algorithm and data structure, we would like to
-	// still use DisjunctionSumScorer to handle exhaustive pure disjunctions, which may be faster
-	if scoreMode == TOP_SCORES || minShouldMatch > 1 {
-		return newWANDScorer(b.weight, optionalScorers, minShouldMatch, scoreMode)
+	// TODO: WANDScorer is not implemented yet, until then top scores disjunctions are scored
+	// exhaustively by DisjunctionSumScorer, and MinShouldMatchSumScorer handles minShouldMatch
+	if minShouldMatch > 1 {
+		return NewMinShouldMatchSumScorer(b.weight, optionalScorers, minShouldMatch)
 	}
 	return newDisjunctionScorer(b.weight, optionalScorers, scoreMode)
 }
@@ -14,6 +14,9 @@ var (
 	maxClauseCount = 1024
 )
 
+// ErrTooManyClauses is returned when a query would need more than GetMaxClauseCount clauses.
+var ErrTooManyClauses = errors.New("TooManyClauses")
+
 var _ index.Query = &BooleanQuery{}
 
 // BooleanQuery
@@ -74,7 +77,7 @@ func (b *BooleanQuery) CreateWeight(searcher index.IndexSearcher, scoreMode inde
 
 func (b *BooleanQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
 	if b.clauses == nil || len(b.clauses) == 0 {
-		return nil, errors.New("empty BooleanQuery")
+		return NewMatchNoDocsQuery("empty BooleanQuery"), nil
 	}
 
 	// optimize 1-clause queries
@@ -443,6 +446,36 @@ func (b *BooleanQuery) Visit(visitor index.QueryVisitor) error {
 	return nil
 }
 
+// Equals
+// Compares the clauses of every occur regardless of their order.
+func (b *BooleanQuery) Equals(other index.Query) bool {
+	o, ok := other.(*BooleanQuery)
+	if !ok || b.minimumNumberShouldMatch != o.minimumNumberShouldMatch {
+		return false
+	}
+	for _, occur := range booleanOccurs {
+		if !sameQueries(b.clauseSets[occur], o.clauseSets[occur]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BooleanQuery) HashCode() int {
+	h := 31*ClassHash("BooleanQuery") + b.minimumNumberShouldMatch
+	for _, occur := range booleanOccurs {
+		// the order of the clauses doesn't matter
+		sum := 0
+		for _, query := range b.clauseSets[occur] {
+			sum += QueryHashCode(query)
+		}
+		h = 31*h + sum
+	}
+	return h
+}
+
+var booleanOccurs = []index.Occur{index.OccurShould, index.OccurMust, index.OccurFilter, index.OccurMustNot}
+
 func newBooleanQuery(minimumNumberShouldMatch int, clauses []*BooleanClause) *BooleanQuery {
 	query := &BooleanQuery{
 		minimumNumberShouldMatch: minimumNumberShouldMatch,
@@ -563,7 +596,7 @@ func (b *BooleanQueryBuilder) SetMinimumNumberShouldMatch(min int) *BooleanQuery
 // Throws: BooleanQuery.TooManyClauses – if the new number of clauses exceeds the maximum clause number
 func (b *BooleanQueryBuilder) Add(clause *BooleanClause) *BooleanQueryBuilder {
 	if len(b.clauses) >= maxClauseCount {
-		b.errs = append(b.errs, errors.New("TooManyClauses"))
+		b.errs = append(b.errs, ErrTooManyClauses)
 		return b
 	}
 	b.clauses = append(b.clauses, clause)
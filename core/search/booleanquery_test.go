@@ -0,0 +1,160 @@
+package search_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// searchScores Returns the scores of the documents matching query
+func searchScores(t *testing.T, searcher index.IndexSearcher, query index.Query) map[int]float64 {
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 1000)
+	assert.Nil(t, err)
+	scores := make(map[int]float64, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		scores[scoreDoc.GetDoc()] = scoreDoc.GetScore()
+	}
+	return scores
+}
+
+// countDocs Counts the documents matching query without scoring them
+func countDocs(t *testing.T, searcher index.IndexSearcher, query index.Query) int {
+	collector := search.NewTotalHitCountCollector()
+	assert.Nil(t, searcher.SearchCollector(context.Background(), query, collector))
+	return collector.GetTotalHits()
+}
+
+// shouldQuery Returns a disjunction of term queries on body
+func shouldQuery(t *testing.T, minShouldMatch int, terms ...string) *search.BooleanQuery {
+	builder := search.NewBooleanQueryBuilder().SetMinimumNumberShouldMatch(minShouldMatch)
+	for _, term := range terms {
+		builder.AddQuery(newTermQuery("body", term), index.OccurShould)
+	}
+	query, err := builder.Build()
+	assert.Nil(t, err)
+	return query
+}
+
+func TestBooleanQuery_Disjunction(t *testing.T) {
+	searcher := searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", "a b", "a", "c", "b c"),
+		searchtest.TextDocs("body", "a", "d", "a a b"))
+
+	scoresA := searchScores(t, searcher, newTermQuery("body", "a"))
+	scoresB := searchScores(t, searcher, newTermQuery("body", "b"))
+
+	// the score of a match is the sum of the scores of its clauses
+	query := shouldQuery(t, 0, "a", "b")
+	scores := searchScores(t, searcher, query)
+	assert.Len(t, scores, 5)
+	for doc, score := range scores {
+		assert.InDelta(t, scoresA[doc]+scoresB[doc], score, 1e-9, "doc %d", doc)
+	}
+	assert.Equal(t, 5, countDocs(t, searcher, query))
+
+	// the second segment has no "c", its only optional clause is scored by itself
+	query = shouldQuery(t, 0, "b", "c")
+	assert.Len(t, searchScores(t, searcher, query), 4)
+	assert.Equal(t, 4, countDocs(t, searcher, query))
+
+	query = shouldQuery(t, 0, "missing", "other")
+	assert.Empty(t, searchScores(t, searcher, query))
+	assert.Equal(t, 0, countDocs(t, searcher, query))
+}
+
+func TestBooleanQuery_MinShouldMatch(t *testing.T) {
+	terms := []string{"a", "b", "c", "d", "e", "f"}
+	// every term is in a different share of the documents, so that the clauses have different costs
+	r := rand.New(rand.NewSource(42))
+	segments := make([][]string, 3)
+	for i := range segments {
+		for j := 0; j < 100; j++ {
+			words := make([]string, 0)
+			for k, term := range terms {
+				if r.Intn(len(terms)+1) <= k {
+					words = append(words, term)
+				}
+			}
+			if len(words) == 0 {
+				words = append(words, "none")
+			}
+			segments[i] = append(segments[i], strings.Join(words, " "))
+		}
+	}
+	searcher := searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", segments[0]...),
+		searchtest.TextDocs("body", segments[1]...),
+		searchtest.TextDocs("body", segments[2]...))
+
+	termScores := make(map[string]map[int]float64)
+	for _, term := range terms {
+		termScores[term] = searchScores(t, searcher, newTermQuery("body", term))
+	}
+
+	for _, clauses := range [][]string{{"a", "b", "c"}, {"a", "c", "e", "f"}, {"a", "b", "c", "d", "e", "f"}} {
+		for minShouldMatch := 1; minShouldMatch <= len(clauses); minShouldMatch++ {
+			t.Run(fmt.Sprintf("%v msm=%d", clauses, minShouldMatch), func(t *testing.T) {
+				expected := make(map[int]float64)
+				for doc := 0; doc < searcher.GetIndexReader().MaxDoc(); doc++ {
+					matches := 0
+					score := 0.0
+					for _, term := range clauses {
+						if termScore, ok := termScores[term][doc]; ok {
+							matches++
+							score += termScore
+						}
+					}
+					if matches >= minShouldMatch {
+						expected[doc] = score
+					}
+				}
+
+				query := shouldQuery(t, minShouldMatch, clauses...)
+				scores := searchScores(t, searcher, query)
+				assert.Equal(t, len(expected), len(scores))
+				for doc, score := range expected {
+					assert.InDelta(t, score, scores[doc], 1e-9, "doc %d", doc)
+				}
+				assert.Equal(t, len(expected), countDocs(t, searcher, query))
+			})
+		}
+	}
+}
+
+func TestBooleanQuery_MinShouldMatchWithRequired(t *testing.T) {
+	searcher := searchtest.NewSearcher(t, searchtest.TextDocs("body",
+		"x a b", "x a", "x b c", "a b c", "x", "x a b c"))
+
+	query, err := search.NewBooleanQueryBuilder().
+		SetMinimumNumberShouldMatch(2).
+		AddQuery(newTermQuery("body", "x"), index.OccurMust).
+		AddQuery(newTermQuery("body", "a"), index.OccurShould).
+		AddQuery(newTermQuery("body", "b"), index.OccurShould).
+		AddQuery(newTermQuery("body", "c"), index.OccurShould).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "(+body:x body:a body:b body:c)~2", query.String(""))
+	assert.ElementsMatch(t, []int{0, 2, 5}, searchDocs(t, searcher, query))
+	assert.Equal(t, 3, countDocs(t, searcher, query))
+
+	query, err = search.NewBooleanQueryBuilder().
+		SetMinimumNumberShouldMatch(2).
+		AddQuery(newTermQuery("body", "a"), index.OccurShould).
+		AddQuery(newTermQuery("body", "b"), index.OccurShould).
+		AddQuery(newTermQuery("body", "c"), index.OccurShould).
+		AddQuery(newTermQuery("body", "x"), index.OccurMustNot).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3}, searchDocs(t, searcher, query))
+
+	explanation, err := searcher.Explain(query, 1)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+}
@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"fmt"
 	"math"
 
 	"github.com/geange/gods-generic/sets/treeset"
@@ -63,8 +64,72 @@ func (b *BooleanWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
 }
 
 func (b *BooleanWeight) Explain(ctx index.LeafReaderContext, doc int) (types.Explanation, error) {
-	//TODO implement me
-	panic("implement me")
+	minShouldMatch := b.query.GetMinimumNumberShouldMatch()
+	subs := make([]types.Explanation, 0)
+	fail := false
+	matchCount := 0
+	shouldMatchCount := 0
+
+	for _, wc := range b.weightedClauses {
+		w := wc.weight
+		c := wc.clause
+		e, err := w.Explain(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.IsMatch() {
+			if c.IsScoring() {
+				subs = append(subs, e)
+			} else if c.IsRequired() {
+				subs = append(subs, types.ExplanationMatch(float64(0), "match on required clause, product of:",
+					types.ExplanationMatch(float64(0), fmt.Sprintf("%s clause", index.OccurFilter)), e))
+			} else if c.IsProhibited() {
+				subs = append(subs, types.ExplanationNoMatch(
+					fmt.Sprintf("match on prohibited clause (%s)", c.GetQuery().String("")), e))
+				fail = true
+			}
+			if !c.IsProhibited() {
+				matchCount++
+			}
+			if c.GetOccur() == index.OccurShould {
+				shouldMatchCount++
+			}
+		} else if c.IsRequired() {
+			subs = append(subs, types.ExplanationNoMatch(
+				fmt.Sprintf("no match on required clause (%s)", c.GetQuery().String("")), e))
+			fail = true
+		}
+	}
+
+	if fail {
+		return types.ExplanationNoMatch("Failure to meet condition(s) of required/prohibited clause(s)", subs...), nil
+	}
+	if matchCount == 0 {
+		return types.ExplanationNoMatch("No matching clauses", subs...), nil
+	}
+	if shouldMatchCount < minShouldMatch {
+		return types.ExplanationNoMatch(
+			fmt.Sprintf("Failure to match minimum number of optional clauses: %d", minShouldMatch), subs...), nil
+	}
+
+	// we have a match
+	scorer, err := b.Scorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	advanced, err := scorer.Iterator().Advance(nil, doc)
+	if err != nil {
+		return nil, err
+	}
+	if advanced != doc {
+		return nil, fmt.Errorf("scorer of %s did not match doc %d", b.query.String(""), doc)
+	}
+	score, err := scorer.Score()
+	if err != nil {
+		return nil, err
+	}
+	return types.ExplanationMatch(score, "sum of:", subs...), nil
 }
 
 func (b *BooleanWeight) Matches(context index.LeafReaderContext, doc int) (index.Matches, error) {
@@ -125,8 +190,37 @@ func disableScoring(scorer index.BulkScorer) index.BulkScorer {
 	}
 }
 
-func (*BooleanWeight) optionalBulkScorer(context index.LeafReaderContext) (index.BulkScorer, error) {
-	panic("")
+func (b *BooleanWeight) optionalBulkScorer(context index.LeafReaderContext) (index.BulkScorer, error) {
+	optional := make([]index.BulkScorer, 0)
+	for _, wc := range b.weightedClauses {
+		if wc.clause.GetOccur() != index.OccurShould {
+			continue
+		}
+
+		subScorer, err := wc.weight.BulkScorer(context)
+		if err != nil {
+			return nil, err
+		}
+		if subScorer != nil {
+			optional = append(optional, subScorer)
+		}
+	}
+
+	if len(optional) == 0 {
+		return nil, nil
+	}
+
+	if b.query.GetMinimumNumberShouldMatch() > len(optional) {
+		return nil, nil
+	}
+
+	if len(optional) == 1 {
+		return optional[0], nil
+	}
+
+	// TODO: BooleanScorer is not implemented yet, returning nil makes BulkScorer fall back to
+	// the Scorer-based impl (BS2)
+	return nil, nil
 }
 
 // Return a BulkScorer for the required clauses only,
@@ -59,6 +59,18 @@ func (b *BoostQuery) Visit(visitor index.QueryVisitor) error {
 	return b.query.Visit(visitor.GetSubVisitor(index.OccurMust, b))
 }
 
+func (b *BoostQuery) Equals(other index.Query) bool {
+	o, ok := other.(*BoostQuery)
+	return ok && b.boost == o.boost && QueryEquals(b.query, o.query)
+}
+
+func (b *BoostQuery) HashCode() int {
+	h := ClassHash("BoostQuery")
+	h = 31*h + QueryHashCode(b.query)
+	h = 31*h + HashFloat64(b.boost)
+	return h
+}
+
 func (b *BoostQuery) GetQuery() index.Query {
 	return b.query
 }
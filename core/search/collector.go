@@ -2,10 +2,16 @@ package search
 
 import (
 	"context"
+	"errors"
 
 	"github.com/geange/lucene-go/core/interface/index"
 )
 
+// ErrCollectionTerminated
+// Returned by a LeafCollector to signal that the collection of the current leaf should be
+// terminated, IndexSearcher then carries on with the next leaf.
+var ErrCollectionTerminated = errors.New("collection terminated")
+
 // SimpleCollector
 // Base Collector implementation that is used to collect all contexts.
 type SimpleCollector interface {
@@ -54,6 +60,14 @@ func NewTotalHitCountCollector() *TotalHitCountCollector {
 	return collector
 }
 
+func (t *TotalHitCountCollector) DoSetNextReader(context index.LeafReaderContext) error {
+	return nil
+}
+
+func (t *TotalHitCountCollector) SetScorer(scorer index.Scorable) error {
+	return nil
+}
+
 func (t *TotalHitCountCollector) Collect(ctx context.Context, doc int) error {
 	t.totalHits++
 	return nil
@@ -2,8 +2,11 @@ package search
 
 import (
 	"context"
+	"errors"
+	"io"
 	"sort"
 
+	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
 
@@ -48,18 +51,23 @@ func (t TimSort) Swap(i, j int) {
 }
 
 func (c *ConjunctionDISI) DocID() int {
-	//TODO implement me
-	panic("implement me")
+	return c.lead1.DocID()
 }
 
-func (c *ConjunctionDISI) NextDoc(context.Context) (int, error) {
-	//TODO implement me
-	panic("implement me")
+func (c *ConjunctionDISI) NextDoc(ctx context.Context) (int, error) {
+	doc, err := c.lead1.NextDoc(ctx)
+	if err != nil {
+		return doc, err
+	}
+	return c.doNext(ctx, doc)
 }
 
 func (c *ConjunctionDISI) Advance(ctx context.Context, target int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	doc, err := c.lead1.Advance(ctx, target)
+	if err != nil {
+		return doc, err
+	}
+	return c.doNext(ctx, doc)
 }
 
 func (c *ConjunctionDISI) SlowAdvance(ctx context.Context, target int) (int, error) {
@@ -67,15 +75,79 @@ func (c *ConjunctionDISI) SlowAdvance(ctx context.Context, target int) (int, err
 }
 
 func (c *ConjunctionDISI) Cost() int64 {
-	//TODO implement me
-	panic("implement me")
+	return c.lead1.Cost() // overestimate
 }
 
 // IntersectIterators Create a conjunction over the provided Scorers. Note that the returned DocIdSetIterator might leverage two-phase iteration in which case it is possible to retrieve the TwoPhaseIterator using TwoPhaseIterator.unwrap.
-func IntersectIterators(iterators []types.DocIdSetIterator) types.DocIdSetIterator {
-	panic("")
+func IntersectIterators(iterators []types.DocIdSetIterator) (types.DocIdSetIterator, error) {
+	if len(iterators) < 2 {
+		return nil, errors.New("cannot make a ConjunctionDISI of less than 2 iterators")
+	}
+
+	allIterators := make([]types.DocIdSetIterator, 0)
+	twoPhaseIterators := make([]index.TwoPhaseIterator, 0)
+	for _, iterator := range iterators {
+		allIterators, twoPhaseIterators = addIterator(iterator, allIterators, twoPhaseIterators)
+	}
+	return createConjunction(allIterators, twoPhaseIterators)
 }
 
-func (c *ConjunctionDISI) doNext(doc int) (int, error) {
-	panic("")
+func (c *ConjunctionDISI) doNext(ctx context.Context, doc int) (int, error) {
+advanceHead:
+	for {
+		// find agreement between the two iterators with the lower costs
+		// we special case them because they do not need the
+		// 'other.docID() < doc' check that the 'others' iterators need
+		next2, err := c.lead2.Advance(ctx, doc)
+		if err != nil {
+			return c.exhaust(ctx, err)
+		}
+		if next2 != doc {
+			doc, err = c.lead1.Advance(ctx, next2)
+			if err != nil {
+				return doc, err
+			}
+			if next2 != doc {
+				continue
+			}
+		}
+
+		// then find agreement with other iterators
+		for _, other := range c.others {
+			// other.doc may already be equal to doc if we "continued advanceHead"
+			// on the previous iteration and the advance on the lead scorer exactly matched.
+			if other.DocID() < doc {
+				next, err := other.Advance(ctx, doc)
+				if err != nil {
+					return c.exhaust(ctx, err)
+				}
+
+				if next > doc {
+					// iterator beyond the current doc - advance lead and continue to the new highest doc.
+					doc, err = c.lead1.Advance(ctx, next)
+					if err != nil {
+						return doc, err
+					}
+					continue advanceHead
+				}
+			}
+		}
+
+		// success - all iterators are on the same doc
+		return doc, nil
+	}
+}
+
+// One of the sub iterators is exhausted, so is the conjunction. The lead is moved to
+// NO_MORE_DOCS too as DocID reports its position.
+func (c *ConjunctionDISI) exhaust(ctx context.Context, err error) (int, error) {
+	if !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	if c.lead1.DocID() != types.NO_MORE_DOCS {
+		if _, err := c.lead1.Advance(ctx, types.NO_MORE_DOCS); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+	}
+	return types.NO_MORE_DOCS, io.EOF
 }
@@ -3,6 +3,7 @@ package search
 import (
 	"context"
 	"errors"
+	"io"
 	"math"
 	"sort"
 
@@ -25,8 +26,8 @@ type ConjunctionScorer struct {
 	required []index.Scorer
 }
 
-func NewConjunctionScorer(weight index.Weight, scorers []index.Scorer, required []index.Scorer) (*ConjunctionScorer, error) {
-	disi, err := intersectScorers(scorers)
+func NewConjunctionScorer(weight index.Weight, required []index.Scorer, scorers []index.Scorer) (*ConjunctionScorer, error) {
+	disi, err := intersectScorers(required)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +96,7 @@ func addScorer(scorer index.Scorer, allIterators []types.DocIdSetIterator,
 		allIterators, twoPhaseIterators = addTwoPhaseIterator(twoPhaseIter, allIterators, twoPhaseIterators)
 	} else {
 		// no approximation support, use the iterator as-is
-		// TODO
+		allIterators, twoPhaseIterators = addIterator(scorer.Iterator(), allIterators, twoPhaseIterators)
 	}
 	return allIterators, twoPhaseIterators
 }
@@ -120,7 +121,7 @@ func createConjunction(allIterators []types.DocIdSetIterator,
 	if len(allIterators) > 0 {
 		curDoc = allIterators[0].DocID()
 	} else {
-		twoPhaseIterators[0].Approximation().DocID()
+		curDoc = twoPhaseIterators[0].Approximation().DocID()
 	}
 
 	iteratorsOnTheSameDoc := true
@@ -253,13 +254,21 @@ func newConjunctionTwoPhaseIterator(approximation types.DocIdSetIterator,
 }
 
 func (c *ConjunctionTwoPhaseIterator) Approximation() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	return c.approximation
 }
 
 func (c *ConjunctionTwoPhaseIterator) Matches() (bool, error) {
-	//TODO implement me
-	panic("implement me")
+	// match cheapest first
+	for _, twoPhaseIterator := range c.twoPhaseIterators {
+		ok, err := twoPhaseIterator.Matches()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 func (c *ConjunctionTwoPhaseIterator) MatchCost() float64 {
@@ -302,33 +311,66 @@ func newBitSetConjunctionDISI(lead types.DocIdSetIterator, bitSetIterators []*co
 	for i, iterator := range disi.bitSetIterators {
 		bitSet := iterator.GetBitSet()
 		disi.bitSets[i] = bitSet
-		minLen = min(minLen, int(bitSet.Count()))
+		minLen = min(minLen, int(bitSet.Len()))
 	}
 	disi.minLength = minLen
 	return disi
 }
 
 func (b *BitSetConjunctionDISI) DocID() int {
-	//TODO implement me
-	panic("implement me")
+	return b.lead.DocID()
 }
 
-func (b *BitSetConjunctionDISI) NextDoc(context.Context) (int, error) {
-	//TODO implement me
-	panic("implement me")
+func (b *BitSetConjunctionDISI) NextDoc(ctx context.Context) (int, error) {
+	doc, err := b.lead.NextDoc(ctx)
+	if err != nil {
+		return doc, err
+	}
+	return b.doNext(ctx, doc)
 }
 
 func (b *BitSetConjunctionDISI) Advance(ctx context.Context, target int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	doc, err := b.lead.Advance(ctx, target)
+	if err != nil {
+		return doc, err
+	}
+	return b.doNext(ctx, doc)
+}
+
+func (b *BitSetConjunctionDISI) doNext(ctx context.Context, doc int) (int, error) {
+advanceLead:
+	for {
+		if doc >= b.minLength {
+			if doc != types.NO_MORE_DOCS {
+				if _, err := b.lead.Advance(ctx, types.NO_MORE_DOCS); err != nil && !errors.Is(err, io.EOF) {
+					return 0, err
+				}
+			}
+			return types.NO_MORE_DOCS, io.EOF
+		}
+
+		for _, bitSet := range b.bitSets {
+			if !bitSet.Test(uint(doc)) {
+				var err error
+				doc, err = b.lead.NextDoc(ctx)
+				if err != nil {
+					return doc, err
+				}
+				continue advanceLead
+			}
+		}
+
+		for _, iterator := range b.bitSetIterators {
+			iterator.SetDocID(doc)
+		}
+		return doc, nil
+	}
 }
 
 func (b *BitSetConjunctionDISI) SlowAdvance(ctx context.Context, target int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	return types.SlowAdvanceWithContext(ctx, b, target)
 }
 
 func (b *BitSetConjunctionDISI) Cost() int64 {
-	//TODO implement me
-	panic("implement me")
+	return b.lead.Cost()
 }
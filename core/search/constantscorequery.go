@@ -18,25 +18,99 @@ func NewConstantScoreQuery(query index.Query) *ConstantScoreQuery {
 }
 
 func (c *ConstantScoreQuery) String(field string) string {
-	//TODO implement me
-	panic("implement me")
+	return "ConstantScore(" + c.query.String(field) + ")"
 }
 
 func (c *ConstantScoreQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
-	//TODO implement me
-	panic("implement me")
+	innerWeight, err := searcher.CreateWeight(c.query, COMPLETE_NO_SCORES, 1)
+	if err != nil {
+		return nil, err
+	}
+	if !scoreMode.NeedsScores() {
+		return innerWeight, nil
+	}
+	return newConstantScoreQueryWeight(c, boost, scoreMode, innerWeight), nil
 }
 
 func (c *ConstantScoreQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
-	//TODO implement me
-	panic("implement me")
+	rewritten, err := c.query.Rewrite(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	switch query := rewritten.(type) {
+	case *MatchNoDocsQuery:
+		// bubble up MatchNoDocsQuery
+		return rewritten, nil
+	case *ConstantScoreQuery:
+		return rewritten, nil
+	case *BoostQuery:
+		return NewConstantScoreQuery(query.GetQuery()), nil
+	}
+
+	if rewritten != c.query {
+		return NewConstantScoreQuery(rewritten), nil
+	}
+	return c, nil
 }
 
 func (c *ConstantScoreQuery) Visit(visitor index.QueryVisitor) (err error) {
-	//TODO implement me
-	panic("implement me")
+	return c.query.Visit(visitor.GetSubVisitor(index.OccurFilter, c))
+}
+
+func (c *ConstantScoreQuery) Equals(other index.Query) bool {
+	o, ok := other.(*ConstantScoreQuery)
+	return ok && QueryEquals(c.query, o.query)
+}
+
+func (c *ConstantScoreQuery) HashCode() int {
+	return 31*ClassHash("ConstantScoreQuery") + QueryHashCode(c.query)
 }
 
 func (c *ConstantScoreQuery) GetQuery() index.Query {
 	return c.query
 }
+
+var _ index.Weight = &constantScoreQueryWeight{}
+
+type constantScoreQueryWeight struct {
+	*ConstantScoreWeight
+
+	scoreMode   index.ScoreMode
+	innerWeight index.Weight
+}
+
+func newConstantScoreQueryWeight(query index.Query, score float64,
+	scoreMode index.ScoreMode, innerWeight index.Weight) *constantScoreQueryWeight {
+
+	weight := &constantScoreQueryWeight{
+		scoreMode:   scoreMode,
+		innerWeight: innerWeight,
+	}
+	weight.ConstantScoreWeight = NewConstantScoreWeight(score, query, weight)
+	return weight
+}
+
+func (c *constantScoreQueryWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	innerScorer, err := c.innerWeight.Scorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if innerScorer == nil {
+		return nil, nil
+	}
+
+	twoPhaseIterator := innerScorer.TwoPhaseIterator()
+	if twoPhaseIterator == nil {
+		return NewConstantScoreScorer(c.innerWeight, c.Score(), c.scoreMode, innerScorer.Iterator())
+	}
+	return NewConstantScoreScorerV1(c.innerWeight, c.Score(), c.scoreMode, twoPhaseIterator)
+}
+
+func (c *constantScoreQueryWeight) Matches(ctx index.LeafReaderContext, doc int) (index.Matches, error) {
+	return c.innerWeight.Matches(ctx, doc)
+}
+
+func (c *constantScoreQueryWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return c.innerWeight.IsCacheable(ctx)
+}
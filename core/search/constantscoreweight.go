@@ -46,16 +46,17 @@ func (c *ConstantScoreWeight) Explain(ctx index.LeafReaderContext, doc int) (typ
 				exists = advance == doc
 			}
 		} else {
-			isMatch, err := twoPhase.Matches()
-			if err != nil {
-				return nil, err
-			}
-
 			advance, err := twoPhase.Approximation().Advance(nil, doc)
 			if err != nil {
-				return nil, err
+				if !errors.Is(err, io.EOF) {
+					return nil, err
+				}
+			} else if advance == doc {
+				exists, err = twoPhase.Matches()
+				if err != nil {
+					return nil, err
+				}
 			}
-			exists = (advance == doc) && isMatch
 		}
 
 	}
@@ -4,4 +4,141 @@ package search
 // A priority queue of DocIdSetIterators that orders by current doc ID. This specialization is needed over PriorityQueue because the pluggable comparison function makes the rebalancing quite slow.
 // lucene.internal
 type DisiPriorityQueue struct {
+	heap []*DisiWrapper
+	size int
+}
+
+func NewDisiPriorityQueue(maxSize int) *DisiPriorityQueue {
+	return &DisiPriorityQueue{
+		heap: make([]*DisiWrapper, maxSize),
+		size: 0,
+	}
+}
+
+func leftNode(node int) int {
+	return ((node + 1) << 1) - 1
+}
+
+func rightNode(leftNode int) int {
+	return leftNode + 1
+}
+
+func parentNode(node int) int {
+	return ((node + 1) >> 1) - 1
+}
+
+func (d *DisiPriorityQueue) Size() int {
+	return d.size
+}
+
+func (d *DisiPriorityQueue) Top() *DisiWrapper {
+	return d.heap[0]
+}
+
+// TopList
+// Get the list of scorers which are on the current doc.
+func (d *DisiPriorityQueue) TopList() *DisiWrapper {
+	list := d.heap[0]
+	list.next = nil
+	if d.size >= 3 {
+		list = d.topList(list, 1)
+		list = d.topList(list, 2)
+	} else if d.size == 2 && d.heap[1].doc == list.doc {
+		list = prepend(d.heap[1], list)
+	}
+	return list
+}
+
+// prepend w1 to w2 and return w1
+func prepend(w1, w2 *DisiWrapper) *DisiWrapper {
+	w1.next = w2
+	return w1
+}
+
+func (d *DisiPriorityQueue) topList(list *DisiWrapper, i int) *DisiWrapper {
+	w := d.heap[i]
+	if w.doc == list.doc {
+		list = prepend(w, list)
+		left := leftNode(i)
+		right := left + 1
+		if right < d.size {
+			list = d.topList(list, left)
+			list = d.topList(list, right)
+		} else if left < d.size && d.heap[left].doc == list.doc {
+			list = prepend(d.heap[left], list)
+		}
+	}
+	return list
+}
+
+func (d *DisiPriorityQueue) Add(entry *DisiWrapper) *DisiWrapper {
+	d.heap[d.size] = entry
+	d.upHeap(d.size)
+	d.size++
+	return d.heap[0]
+}
+
+func (d *DisiPriorityQueue) Pop() *DisiWrapper {
+	result := d.heap[0]
+	d.size--
+	i := d.size
+	d.heap[0] = d.heap[i]
+	d.heap[i] = nil
+	d.downHeap(i)
+	return result
+}
+
+func (d *DisiPriorityQueue) UpdateTop() *DisiWrapper {
+	d.downHeap(d.size)
+	return d.heap[0]
+}
+
+func (d *DisiPriorityQueue) UpdateTopWith(topReplacement *DisiWrapper) *DisiWrapper {
+	d.heap[0] = topReplacement
+	return d.UpdateTop()
+}
+
+// All
+// Returns the entries of the queue, in no particular order.
+func (d *DisiPriorityQueue) All() []*DisiWrapper {
+	return d.heap[:d.size]
+}
+
+func (d *DisiPriorityQueue) upHeap(i int) {
+	node := d.heap[i]
+	nodeDoc := node.doc
+	j := parentNode(i)
+	for j >= 0 && nodeDoc < d.heap[j].doc {
+		d.heap[i] = d.heap[j]
+		i = j
+		j = parentNode(j)
+	}
+	d.heap[i] = node
+}
+
+func (d *DisiPriorityQueue) downHeap(size int) {
+	i := 0
+	node := d.heap[0]
+	j := leftNode(i)
+	if j < size {
+		k := rightNode(j)
+		if k < size && d.heap[k].doc < d.heap[j].doc {
+			j = k
+		}
+		if d.heap[j].doc < node.doc {
+			for {
+				d.heap[i] = d.heap[j]
+				i = j
+				j = leftNode(i)
+				k = rightNode(j)
+				if k < size && d.heap[k].doc < d.heap[j].doc {
+					j = k
+				}
+				if !(j < size && d.heap[j].doc < node.doc) {
+					break
+				}
+			}
+			d.heap[i] = node
+		}
+	}
 }
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// DisiWrapper
+// Wrapper used in DisiPriorityQueue.
+// lucene.internal
+type DisiWrapper struct {
+	iterator  types.DocIdSetIterator
+	scorer    index.Scorer
+	cost      int64
+	matchCost float64      // the match cost for two-phase iterators, 0 otherwise
+	doc       int          // the current doc, used for comparison
+	next      *DisiWrapper // reference to a next element, see #topList
+
+	// An approximation of the iterator, or the iterator itself if it does not
+	// support two-phase iteration
+	approximation types.DocIdSetIterator
+
+	// A two-phase view of the iterator, or null if the iterator does not support
+	// two-phase iteration
+	twoPhaseView index.TwoPhaseIterator
+
+	// For WANDScorer
+	maxScore int64
+}
+
+func NewDisiWrapper(scorer index.Scorer) *DisiWrapper {
+	iterator := scorer.Iterator()
+	w := &DisiWrapper{
+		iterator:     iterator,
+		scorer:       scorer,
+		cost:         iterator.Cost(),
+		doc:          -1,
+		twoPhaseView: scorer.TwoPhaseIterator(),
+	}
+
+	if w.twoPhaseView != nil {
+		w.approximation = w.twoPhaseView.Approximation()
+		w.matchCost = w.twoPhaseView.MatchCost()
+	} else {
+		w.approximation = iterator
+		w.matchCost = 0
+	}
+	return w
+}
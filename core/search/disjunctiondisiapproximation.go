@@ -0,0 +1,89 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ types.DocIdSetIterator = &DisjunctionDISIApproximation{}
+
+// DisjunctionDISIApproximation
+// A DocIdSetIterator which is a disjunction of the approximations of the provided iterators.
+// lucene.internal
+type DisjunctionDISIApproximation struct {
+	subIterators *DisiPriorityQueue
+	cost         int64
+}
+
+func NewDisjunctionDISIApproximation(subIterators *DisiPriorityQueue) *DisjunctionDISIApproximation {
+	cost := int64(0)
+	for _, w := range subIterators.All() {
+		cost += w.cost
+	}
+	return &DisjunctionDISIApproximation{
+		subIterators: subIterators,
+		cost:         cost,
+	}
+}
+
+func (d *DisjunctionDISIApproximation) DocID() int {
+	return d.subIterators.Top().doc
+}
+
+func (d *DisjunctionDISIApproximation) NextDoc(ctx context.Context) (int, error) {
+	top := d.subIterators.Top()
+	doc := top.doc
+	for {
+		next, err := top.approximation.NextDoc(ctx)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			next = types.NO_MORE_DOCS
+		}
+		top.doc = next
+		top = d.subIterators.UpdateTop()
+		if top.doc != doc {
+			break
+		}
+	}
+	return d.currentDoc()
+}
+
+func (d *DisjunctionDISIApproximation) Advance(ctx context.Context, target int) (int, error) {
+	top := d.subIterators.Top()
+	for {
+		next, err := top.approximation.Advance(ctx, target)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			next = types.NO_MORE_DOCS
+		}
+		top.doc = next
+		top = d.subIterators.UpdateTop()
+		if top.doc >= target {
+			break
+		}
+	}
+	return d.currentDoc()
+}
+
+func (d *DisjunctionDISIApproximation) currentDoc() (int, error) {
+	doc := d.DocID()
+	if doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	return doc, nil
+}
+
+func (d *DisjunctionDISIApproximation) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, d, target)
+}
+
+func (d *DisjunctionDISIApproximation) Cost() int64 {
+	return d.cost
+}
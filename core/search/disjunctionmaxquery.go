@@ -29,6 +29,15 @@ func (d *DisjunctionMaxQuery) String(field string) string {
 	panic("implement me")
 }
 
+func (d *DisjunctionMaxQuery) Equals(other index.Query) bool {
+	_, ok := other.(*DisjunctionMaxQuery)
+	return ok
+}
+
+func (d *DisjunctionMaxQuery) HashCode() int {
+	return ClassHash("DisjunctionMaxQuery")
+}
+
 func (d *DisjunctionMaxQuery) GetDisjuncts() []index.Query {
 	panic("")
 }
@@ -1,6 +1,9 @@
 package search
 
 import (
+	"errors"
+	"slices"
+
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
@@ -10,6 +13,8 @@ import (
 type DisjunctionScorer struct {
 	*BaseScorer
 
+	spi DisjunctionScorerSPI
+
 	needsScores bool
 
 	subScorers *DisiPriorityQueue
@@ -19,22 +24,189 @@ type DisjunctionScorer struct {
 	twoPhase *TwoPhase
 }
 
+type DisjunctionScorerSPI interface {
+	// ScoreList
+	// Compute the score for the given linked list of scorers.
+	ScoreList(topList *DisiWrapper) (float64, error)
+}
+
+func newDisjunctionScorerBase(weight index.Weight, subScorers []index.Scorer,
+	scoreMode index.ScoreMode, spi DisjunctionScorerSPI) (*DisjunctionScorer, error) {
+
+	if len(subScorers) <= 1 {
+		return nil, errors.New("there must be at least 2 subScorers")
+	}
+
+	queue := NewDisiPriorityQueue(len(subScorers))
+	for _, scorer := range subScorers {
+		queue.Add(NewDisiWrapper(scorer))
+	}
+
+	scorer := &DisjunctionScorer{
+		BaseScorer:    NewScorer(weight),
+		spi:           spi,
+		needsScores:   scoreMode != COMPLETE_NO_SCORES,
+		subScorers:    queue,
+		approximation: NewDisjunctionDISIApproximation(queue),
+	}
+
+	hasApproximation := false
+	sumMatchCost := 0.0
+	sumApproxCost := int64(0)
+	// Compute matchCost as the average over the matchCost of the subScorers.
+	// This is weighted by the cost, which is an expected number of matching documents.
+	for _, w := range queue.All() {
+		costWeight := max(w.cost, 1)
+		sumApproxCost += costWeight
+		if w.twoPhaseView != nil {
+			hasApproximation = true
+			sumMatchCost += w.matchCost * float64(costWeight)
+		}
+	}
+
+	if hasApproximation {
+		scorer.twoPhase = &TwoPhase{
+			scorer:            scorer,
+			matchCost:         sumMatchCost / float64(sumApproxCost),
+			unverifiedMatches: make([]*DisiWrapper, 0, len(subScorers)),
+		}
+	}
+	return scorer, nil
+}
+
+func (d *DisjunctionScorer) Iterator() types.DocIdSetIterator {
+	if d.twoPhase != nil {
+		return AsDocIdSetIterator(d.twoPhase)
+	}
+	return d.approximation
+}
+
+func (d *DisjunctionScorer) TwoPhaseIterator() index.TwoPhaseIterator {
+	if d.twoPhase == nil {
+		// avoid a typed nil
+		return nil
+	}
+	return d.twoPhase
+}
+
+func (d *DisjunctionScorer) DocID() int {
+	return d.subScorers.Top().doc
+}
+
+func (d *DisjunctionScorer) getSubMatches() (*DisiWrapper, error) {
+	if d.twoPhase == nil {
+		return d.subScorers.TopList(), nil
+	}
+	return d.twoPhase.getSubMatches()
+}
+
+func (d *DisjunctionScorer) Score() (float64, error) {
+	topList, err := d.getSubMatches()
+	if err != nil {
+		return 0, err
+	}
+	return d.spi.ScoreList(topList)
+}
+
+func (d *DisjunctionScorer) GetChildren() ([]index.ChildScorable, error) {
+	topList, err := d.getSubMatches()
+	if err != nil {
+		return nil, err
+	}
+	children := make([]index.ChildScorable, 0)
+	for w := topList; w != nil; w = w.next {
+		children = append(children, NewChildScorable(w.scorer, "SHOULD"))
+	}
+	return children, nil
+}
+
 var _ index.TwoPhaseIterator = &TwoPhase{}
 
 type TwoPhase struct {
+	scorer    *DisjunctionScorer
+	matchCost float64
+
+	// list of verified matches on the current doc
+	verifiedMatches *DisiWrapper
+
+	// approximations on the current doc that have not been verified yet
+	unverifiedMatches []*DisiWrapper
+}
+
+func (t *TwoPhase) getSubMatches() (*DisiWrapper, error) {
+	// iteration order does not matter
+	for _, w := range t.unverifiedMatches {
+		ok, err := w.twoPhaseView.Matches()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			w.next = t.verifiedMatches
+			t.verifiedMatches = w
+		}
+	}
+	t.unverifiedMatches = t.unverifiedMatches[:0]
+	return t.verifiedMatches, nil
 }
 
 func (t *TwoPhase) Approximation() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	return t.scorer.approximation
 }
 
 func (t *TwoPhase) Matches() (bool, error) {
-	//TODO implement me
-	panic("implement me")
+	t.verifiedMatches = nil
+	t.unverifiedMatches = t.unverifiedMatches[:0]
+
+	for w := t.scorer.subScorers.TopList(); w != nil; {
+		next := w.next
+
+		if w.twoPhaseView == nil {
+			// implicitly verified, move it to verifiedMatches
+			w.next = t.verifiedMatches
+			t.verifiedMatches = w
+
+			if !t.scorer.needsScores {
+				// we can stop here
+				return true, nil
+			}
+		} else {
+			t.unverifiedMatches = append(t.unverifiedMatches, w)
+		}
+		w = next
+	}
+
+	if t.verifiedMatches != nil {
+		return true, nil
+	}
+
+	// verify subs that have an two-phase iterator
+	// least-costly ones first
+	slices.SortFunc(t.unverifiedMatches, func(a, b *DisiWrapper) int {
+		switch {
+		case a.matchCost < b.matchCost:
+			return -1
+		case a.matchCost > b.matchCost:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for len(t.unverifiedMatches) > 0 {
+		w := t.unverifiedMatches[0]
+		t.unverifiedMatches = t.unverifiedMatches[1:]
+		ok, err := w.twoPhaseView.Matches()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			w.next = nil
+			t.verifiedMatches = w
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (t *TwoPhase) MatchCost() float64 {
-	//TODO implement me
-	panic("implement me")
+	return t.matchCost
 }
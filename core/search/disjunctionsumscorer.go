@@ -11,28 +11,59 @@ var _ index.Scorer = &DisjunctionSumScorer{}
 // A Scorer for OR like queries, counterpart of ConjunctionScorer.
 type DisjunctionSumScorer struct {
 	*DisjunctionScorer
-}
 
-func newDisjunctionScorer(weight index.Weight, subScorers []index.Scorer, scoreMode index.ScoreMode) (*DisjunctionSumScorer, error) {
-	panic("")
+	scorers []index.Scorer
 }
 
-func (d *DisjunctionSumScorer) Score() (float64, error) {
-	//TODO implement me
-	panic("implement me")
+// Construct a DisjunctionScorer.
+// weight: The weight to be used.
+// subScorers: Array of at least two subscorers.
+func newDisjunctionScorer(weight index.Weight, subScorers []index.Scorer, scoreMode index.ScoreMode) (*DisjunctionSumScorer, error) {
+	scorer := &DisjunctionSumScorer{scorers: subScorers}
+	base, err := newDisjunctionScorerBase(weight, subScorers, scoreMode, scorer)
+	if err != nil {
+		return nil, err
+	}
+	scorer.DisjunctionScorer = base
+	return scorer, nil
 }
 
-func (d *DisjunctionSumScorer) DocID() int {
-	//TODO implement me
-	panic("implement me")
+func (d *DisjunctionSumScorer) ScoreList(topList *DisiWrapper) (float64, error) {
+	score := 0.0
+	for w := topList; w != nil; w = w.next {
+		v, err := w.scorer.Score()
+		if err != nil {
+			return 0, err
+		}
+		score += v
+	}
+	return score, nil
 }
 
-func (d *DisjunctionSumScorer) Iterator() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+func (d *DisjunctionSumScorer) AdvanceShallow(target int) (int, error) {
+	minValue := types.NO_MORE_DOCS
+	for _, scorer := range d.scorers {
+		if scorer.DocID() <= target {
+			shallowTarget, err := scorer.AdvanceShallow(target)
+			if err != nil {
+				return 0, err
+			}
+			minValue = min(minValue, shallowTarget)
+		}
+	}
+	return minValue, nil
 }
 
 func (d *DisjunctionSumScorer) GetMaxScore(upTo int) (float64, error) {
-	//TODO implement me
-	panic("implement me")
+	maxScore := 0.0
+	for _, scorer := range d.scorers {
+		if scorer.DocID() <= upTo {
+			v, err := scorer.GetMaxScore(upTo)
+			if err != nil {
+				return 0, err
+			}
+			maxScore += v
+		}
+	}
+	return maxScore, nil
 }
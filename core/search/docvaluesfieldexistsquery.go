@@ -28,3 +28,12 @@ func (d *DocValuesFieldExistsQuery) String(field string) string {
 	//TODO implement me
 	panic("implement me")
 }
+
+func (d *DocValuesFieldExistsQuery) Equals(other index.Query) bool {
+	_, ok := other.(*DocValuesFieldExistsQuery)
+	return ok
+}
+
+func (d *DocValuesFieldExistsQuery) HashCode() int {
+	return ClassHash("DocValuesFieldExistsQuery")
+}
@@ -0,0 +1,346 @@
+package search
+
+import (
+	"context"
+	"math"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ PhraseMatcher = &ExactPhraseMatcher{}
+
+// ExactPhraseMatcher
+// Expert: Find exact phrases
+type ExactPhraseMatcher struct {
+	postings             []*postingsAndPosition
+	approximation        types.DocIdSetIterator
+	impactsApproximation *ImpactsDISI
+	matchCost            float64
+}
+
+type postingsAndPosition struct {
+	postings index.PostingsEnum
+	offset   int
+	freq     int
+	upTo     int
+	pos      int
+}
+
+// NewExactPhraseMatcher
+// Expert: Creates ExactPhraseMatcher instance
+func NewExactPhraseMatcher(postings []*PostingsAndFreq, scoreMode index.ScoreMode,
+	scorer index.SimScorer, matchCost float64) (*ExactPhraseMatcher, error) {
+
+	iterators := make([]types.DocIdSetIterator, 0, len(postings))
+	impactsEnums := make([]index.ImpactsEnum, 0, len(postings))
+	positions := make([]*postingsAndPosition, 0, len(postings))
+	for _, posting := range postings {
+		iterators = append(iterators, posting.postings)
+		impactsEnums = append(impactsEnums, posting.impacts)
+		positions = append(positions, &postingsAndPosition{
+			postings: posting.postings,
+			offset:   posting.position,
+		})
+	}
+
+	approximation, err := IntersectIterators(iterators)
+	if err != nil {
+		return nil, err
+	}
+	impactsSource := mergeImpacts(impactsEnums)
+
+	matcher := &ExactPhraseMatcher{
+		postings:  positions,
+		matchCost: matchCost,
+	}
+	if scoreMode == TOP_SCORES {
+		matcher.impactsApproximation = NewImpactsDISI(approximation, impactsSource, scorer)
+		matcher.approximation = matcher.impactsApproximation
+	} else {
+		matcher.approximation = approximation
+		matcher.impactsApproximation = NewImpactsDISI(approximation, impactsSource, scorer)
+	}
+	return matcher, nil
+}
+
+func (e *ExactPhraseMatcher) Approximation() types.DocIdSetIterator {
+	return e.approximation
+}
+
+func (e *ExactPhraseMatcher) ImpactsApproximation() *ImpactsDISI {
+	return e.impactsApproximation
+}
+
+func (e *ExactPhraseMatcher) MaxFreq() (float64, error) {
+	minFreq := e.postings[0].freq
+	for _, posting := range e.postings[1:] {
+		minFreq = min(minFreq, posting.freq)
+	}
+	return float64(minFreq), nil
+}
+
+// Advance the given pos enum to the first position on or after target.
+// Return false if the enum was exhausted before reaching target and true otherwise.
+func advancePosition(posting *postingsAndPosition, target int) (bool, error) {
+	for posting.pos < target {
+		if posting.upTo == posting.freq {
+			return false, nil
+		}
+		pos, err := posting.postings.NextPosition()
+		if err != nil {
+			return false, err
+		}
+		posting.pos = pos
+		posting.upTo++
+	}
+	return true, nil
+}
+
+func (e *ExactPhraseMatcher) Reset() error {
+	for _, posting := range e.postings {
+		freq, err := posting.postings.Freq()
+		if err != nil {
+			return err
+		}
+		posting.freq = freq
+		posting.pos = -1
+		posting.upTo = 0
+	}
+	return nil
+}
+
+func (e *ExactPhraseMatcher) NextMatch() (bool, error) {
+	lead := e.postings[0]
+	if lead.upTo >= lead.freq {
+		return false, nil
+	}
+	pos, err := lead.postings.NextPosition()
+	if err != nil {
+		return false, err
+	}
+	lead.pos = pos
+	lead.upTo++
+
+ADVANCE_HEAD:
+	for {
+		phrasePos := lead.pos - lead.offset
+		for _, posting := range e.postings[1:] {
+			expectedPos := phrasePos + posting.offset
+
+			// advance up to the same position as the lead
+			ok, err := advancePosition(posting, expectedPos)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				break ADVANCE_HEAD
+			}
+
+			if posting.pos != expectedPos { // we advanced too far
+				ok, err := advancePosition(lead, posting.pos-posting.offset+lead.offset)
+				if err != nil {
+					return false, err
+				}
+				if ok {
+					continue ADVANCE_HEAD
+				}
+				break ADVANCE_HEAD
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (e *ExactPhraseMatcher) SloppyWeight() float64 {
+	return 1
+}
+
+func (e *ExactPhraseMatcher) StartPosition() int {
+	return e.postings[0].pos
+}
+
+func (e *ExactPhraseMatcher) EndPosition() int {
+	return e.postings[len(e.postings)-1].pos
+}
+
+func (e *ExactPhraseMatcher) StartOffset() (int, error) {
+	return e.postings[0].postings.StartOffset()
+}
+
+func (e *ExactPhraseMatcher) EndOffset() (int, error) {
+	return e.postings[len(e.postings)-1].postings.EndOffset()
+}
+
+func (e *ExactPhraseMatcher) GetMatchCost() float64 {
+	return e.matchCost
+}
+
+// mergeImpacts
+// Merge impacts for multiple terms of an exact phrase.
+func mergeImpacts(impactsEnums []index.ImpactsEnum) index.ImpactsSource {
+	return &phraseImpactsSource{impactsEnums: impactsEnums}
+}
+
+var _ index.ImpactsSource = &phraseImpactsSource{}
+
+type phraseImpactsSource struct {
+	impactsEnums []index.ImpactsEnum
+}
+
+func (p *phraseImpactsSource) AdvanceShallow(ctx context.Context, target int) error {
+	for _, impactsEnum := range p.impactsEnums {
+		if err := impactsEnum.AdvanceShallow(ctx, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *phraseImpactsSource) GetImpacts() (index.Impacts, error) {
+	impacts := make([]index.Impacts, 0, len(p.impactsEnums))
+
+	// Use the impacts that have the lower next boundary as a lead.
+	// It will decide on the number of levels and the block boundaries.
+	var lead index.Impacts
+	for _, impactsEnum := range p.impactsEnums {
+		current, err := impactsEnum.GetImpacts()
+		if err != nil {
+			return nil, err
+		}
+		impacts = append(impacts, current)
+		if lead == nil || current.GetDocIdUpTo(0) < lead.GetDocIdUpTo(0) {
+			lead = current
+		}
+	}
+	return &phraseImpacts{lead: lead, impacts: impacts}, nil
+}
+
+var _ index.Impacts = &phraseImpacts{}
+
+type phraseImpacts struct {
+	lead    index.Impacts
+	impacts []index.Impacts
+}
+
+func (p *phraseImpacts) NumLevels() int {
+	// Delegate to the lead
+	return p.lead.NumLevels()
+}
+
+func (p *phraseImpacts) GetDocIdUpTo(level int) int {
+	// Delegate to the lead
+	return p.lead.GetDocIdUpTo(level)
+}
+
+// Return the minimum level whose impacts are valid up to docIdUpTo, or -1 if there is no such level.
+func (p *phraseImpacts) getLevel(impacts index.Impacts, docIdUpTo int) int {
+	for level := 0; level < impacts.NumLevels(); level++ {
+		if impacts.GetDocIdUpTo(level) >= docIdUpTo {
+			return level
+		}
+	}
+	return -1
+}
+
+type impactsSubIterator struct {
+	impacts []index.Impact
+	current index.Impact
+}
+
+func (s *impactsSubIterator) next() bool {
+	if len(s.impacts) == 0 {
+		s.current = nil
+		return false
+	}
+	s.current, s.impacts = s.impacts[0], s.impacts[1:]
+	return true
+}
+
+func (p *phraseImpacts) GetImpacts(level int) []index.Impact {
+	docIdUpTo := p.GetDocIdUpTo(level)
+
+	pq := structure.NewPriorityQueue[*impactsSubIterator](len(p.impacts), func(a, b *impactsSubIterator) bool {
+		return a.current.GetFreq() < b.current.GetFreq()
+	})
+
+	hasImpacts := false
+	var onlyImpactList []index.Impact
+	// the highest norm of the first impacts of all entries
+	currentNorm := int64(0)
+	for _, impacts := range p.impacts {
+		impactsLevel := p.getLevel(impacts, docIdUpTo)
+		if impactsLevel == -1 {
+			// This instance doesn't have useful impacts, ignore it: this is safe.
+			continue
+		}
+
+		impactList := impacts.GetImpacts(impactsLevel)
+		firstImpact := impactList[0]
+		if firstImpact.GetFreq() == math.MaxInt32 && firstImpact.GetNorm() == 1 {
+			// Dummy impacts, ignore it too.
+			continue
+		}
+
+		subIterator := &impactsSubIterator{impacts: impactList}
+		subIterator.next()
+		pq.Add(subIterator)
+		if uint64(firstImpact.GetNorm()) > uint64(currentNorm) {
+			currentNorm = firstImpact.GetNorm()
+		}
+		if !hasImpacts {
+			hasImpacts = true
+			onlyImpactList = impactList
+		} else {
+			onlyImpactList = nil // there are multiple impacts
+		}
+	}
+
+	if !hasImpacts {
+		return []index.Impact{coreIndex.NewImpact(math.MaxInt32, 1)}
+	} else if onlyImpactList != nil {
+		return onlyImpactList
+	}
+
+	// Idea: merge impacts by freq. The tricky thing is that we need to
+	// consider freq values that are not in the impacts too. For
+	// instance if the list of impacts is [{freq=2,norm=10}, {freq=4,norm=12}],
+	// there might well be a document that has a freq of 2 and a length of 11,
+	// which was just not added to the list of impacts because {freq=2,norm=10}
+	// is more competitive.
+	// We walk impacts in parallel through a PQ ordered by freq. At any time,
+	// the competitive impact consists of the lowest freq among all entries of
+	// the PQ (the top) and the highest norm (tracked separately).
+	mergedImpacts := make([]index.Impact, 0)
+	top := pq.Top()
+	currentFreq := top.current.GetFreq()
+
+	for {
+		if size := len(mergedImpacts); size > 0 && mergedImpacts[size-1].GetNorm() == currentNorm {
+			mergedImpacts[size-1] = coreIndex.NewImpact(currentFreq, currentNorm)
+		} else {
+			mergedImpacts = append(mergedImpacts, coreIndex.NewImpact(currentFreq, currentNorm))
+		}
+
+		for {
+			if !top.next() {
+				// At least one clause doesn't have any more documents below the current norm,
+				// so we can safely ignore further clauses. The only reason why they have more
+				// impacts is because they cover more documents that we are not interested in.
+				return mergedImpacts
+			}
+			if uint64(top.current.GetNorm()) > uint64(currentNorm) {
+				currentNorm = top.current.GetNorm()
+			}
+			top = pq.UpdateTop()
+			if top.current.GetFreq() != currentFreq {
+				break
+			}
+		}
+
+		currentFreq = top.current.GetFreq()
+	}
+}
@@ -0,0 +1,36 @@
+package search
+
+// Executor
+// Runs the tasks of a search. IndexSearcher hands every leaf slice to the executor, so the
+// slices are searched concurrently; Execute may block until the task can be scheduled.
+type Executor interface {
+	Execute(task func())
+}
+
+var _ Executor = &BoundedExecutor{}
+
+// BoundedExecutor
+// An Executor running every task in its own goroutine, with at most maxConcurrency tasks
+// running at the same time. Execute blocks while all the slots are taken.
+type BoundedExecutor struct {
+	slots chan struct{}
+}
+
+// NewBoundedExecutor
+// Creates an executor running up to maxConcurrency tasks concurrently, values lower than 1
+// are treated as 1.
+func NewBoundedExecutor(maxConcurrency int) *BoundedExecutor {
+	return &BoundedExecutor{
+		slots: make(chan struct{}, max(maxConcurrency, 1)),
+	}
+}
+
+func (b *BoundedExecutor) Execute(task func()) {
+	b.slots <- struct{}{}
+	go func() {
+		defer func() {
+			<-b.slots
+		}()
+		task()
+	}()
+}
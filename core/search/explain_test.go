@@ -0,0 +1,140 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTermQuery(field, text string) *search.TermQuery {
+	return search.NewTermQuery(coreIndex.NewTerm(field, []byte(text)))
+}
+
+// explanationDescriptions Returns the descriptions of the direct details of e
+func explanationDescriptions(e types.Explanation) []string {
+	descriptions := make([]string, 0, len(e.GetDetails()))
+	for _, detail := range e.GetDetails() {
+		descriptions = append(descriptions, detail.GetDescription())
+	}
+	return descriptions
+}
+
+func TestIndexSearcher_Explain(t *testing.T) {
+	searcher := searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", "quick fox", "lazy dog"),
+		searchtest.TextDocs("body", "quick quick dog", "fox"))
+
+	query, err := search.NewBooleanQueryBuilder().
+		AddQuery(newTermQuery("body", "quick"), index.OccurShould).
+		AddQuery(newTermQuery("body", "dog"), index.OccurShould).
+		Build()
+	assert.Nil(t, err)
+
+	// doc 2 is the first document of the second segment, both terms have n=2 and N=4, so
+	// idf = log(1 + 2.5/2.5), and the field is 3 terms long with an average of 2
+	explanation, err := searcher.Explain(query, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, `0.6413716480756277 = sum of:
+  0.37980667427942205 = weight(body:quick in 0) [BM25Similarity], result of:
+    0.37980667427942205 = score(freq=2), computed as boost * idf * tf from:
+      0.6931471805599453 = idf, computed as log(1 + (N - n + 0.5) / (n + 0.5)) from:
+        2 = n, number of documents containing term
+        4 = N, total number of documents with field
+      0.547945205479452 = tf, computed as freq / (freq + k1 * (1 - b + b * dl / avgdl)) from:
+        2 = freq, occurrences of term within document
+        1.2 = k1, term saturation parameter
+        0.75 = b, length normalization parameter
+        3 = dl, length of field
+        2 = avgdl, average length of field
+  0.26156497379620575 = weight(body:dog in 0) [BM25Similarity], result of:
+    0.26156497379620575 = score(freq=1), computed as boost * idf * tf from:
+      0.6931471805599453 = idf, computed as log(1 + (N - n + 0.5) / (n + 0.5)) from:
+        2 = n, number of documents containing term
+        4 = N, total number of documents with field
+      0.37735849056603776 = tf, computed as freq / (freq + k1 * (1 - b + b * dl / avgdl)) from:
+        1 = freq, occurrences of term within document
+        1.2 = k1, term saturation parameter
+        0.75 = b, length normalization parameter
+        3 = dl, length of field
+        2 = avgdl, average length of field
+`, explanation.String())
+
+	// the explained score is the score of the hit
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 10)
+	assert.Nil(t, err)
+	explained := 0
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		explanation, err := searcher.Explain(query, scoreDoc.GetDoc())
+		assert.Nil(t, err)
+		assert.True(t, explanation.IsMatch())
+		assert.InDelta(t, scoreDoc.GetScore(), explanation.GetValue(), 1e-9)
+		explained++
+	}
+	assert.Equal(t, 3, explained)
+
+	// only one of the clauses matches doc 0
+	explanation, err = searcher.Explain(query, 0)
+	assert.Nil(t, err)
+	assert.True(t, explanation.IsMatch())
+	assert.Equal(t, []string{"weight(body:quick in 0) [BM25Similarity], result of:"}, explanationDescriptions(explanation))
+
+	explanation, err = searcher.Explain(query, 3)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+	assert.Equal(t, "0 = No matching clauses\n", explanation.String())
+
+	_, err = searcher.Explain(query, 4)
+	assert.NotNil(t, err)
+}
+
+func TestIndexSearcher_ExplainRequiredAndProhibited(t *testing.T) {
+	searcher := searchtest.NewSearcher(t, searchtest.TextDocs("body", "quick fox", "quick dog", "lazy dog"))
+
+	query, err := search.NewBooleanQueryBuilder().
+		AddQuery(newTermQuery("body", "quick"), index.OccurMust).
+		AddQuery(newTermQuery("body", "fox"), index.OccurFilter).
+		AddQuery(newTermQuery("body", "lazy"), index.OccurMustNot).
+		Build()
+	assert.Nil(t, err)
+
+	// only scoring clauses add to the score
+	explanation, err := searcher.Explain(query, 0)
+	assert.Nil(t, err)
+	assert.True(t, explanation.IsMatch())
+	assert.Equal(t, []string{
+		"weight(body:quick in 0) [BM25Similarity], result of:",
+		"match on required clause, product of:",
+	}, explanationDescriptions(explanation))
+	assert.Equal(t, explanation.GetDetails()[0].GetValue(), explanation.GetValue())
+	assert.Equal(t, float64(0), explanation.GetDetails()[1].GetValue())
+
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 10)
+	assert.Nil(t, err)
+	assert.Len(t, topDocs.GetScoreDocs(), 1)
+	assert.Equal(t, 0, topDocs.GetScoreDocs()[0].GetDoc())
+	assert.InDelta(t, topDocs.GetScoreDocs()[0].GetScore(), explanation.GetValue(), 1e-9)
+
+	explanation, err = searcher.Explain(query, 1)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+	assert.Equal(t, "Failure to meet condition(s) of required/prohibited clause(s)", explanation.GetDescription())
+	assert.Equal(t, []string{
+		"weight(body:quick in 1) [BM25Similarity], result of:",
+		"no match on required clause (body:fox)",
+	}, explanationDescriptions(explanation))
+
+	explanation, err = searcher.Explain(query, 2)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+	assert.Equal(t, []string{
+		"no match on required clause (body:quick)",
+		"no match on required clause (body:fox)",
+		"match on prohibited clause (body:lazy)",
+	}, explanationDescriptions(explanation))
+}
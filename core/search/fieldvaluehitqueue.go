@@ -15,11 +15,19 @@ import (
 //
 //			 cannot be null or empty
 //	size – The number of hits to retain. Must be greater than zero.
-func CreateFieldValueHitQueue(fields []index.SortField, size int) FieldValueHitQueue[*Entry] {
+func CreateFieldValueHitQueue(fields []index.SortField, size int) (FieldValueHitQueue[*Entry], error) {
 	if len(fields) == 1 {
-		return NewOneComparatorFieldValueHitQueue(fields, size)
+		queue, err := NewOneComparatorFieldValueHitQueue(fields, size)
+		if err != nil {
+			return nil, err
+		}
+		return queue, nil
+	}
+	queue, err := NewMultiComparatorsFieldValueHitQueue(fields, size)
+	if err != nil {
+		return nil, err
 	}
-	return NewMultiComparatorsFieldValueHitQueue(fields, size)
+	return queue, nil
 }
 
 // FieldValueHitQueue
@@ -40,6 +48,15 @@ type FieldValueHitQueue[T index.ScoreDoc] interface {
 	GetReverseMul() []int
 	GetComparators(ctx index.LeafReaderContext) ([]index.LeafFieldComparator, error)
 	GetComparatorsList() []index.FieldComparator
+	GetFields() []index.SortField
+	GetPriorityQueue() *structure.PriorityQueue[T]
+
+	// FillFields
+	// Given a queue Entry, creates a corresponding FieldDoc that contains the values used to sort
+	// the given document. These values are not the raw values out of the index, but the internal
+	// representation of them. This is so the given search hit can be collated by a MultiSearcher
+	// with other search hits.
+	FillFields(entry *Entry) FieldDoc
 }
 
 type FieldValueHitQueueDefault[T any] struct {
@@ -53,7 +70,7 @@ type FieldValueHitQueueDefault[T any] struct {
 
 // newFieldValueHitQueue
 // prevent instantiation and extension.
-func newFieldValueHitQueue[T any](fields []index.SortField, size int, lessThan func(a, b T) bool) *FieldValueHitQueueDefault[T] {
+func newFieldValueHitQueue[T any](fields []index.SortField, size int, lessThan func(a, b T) bool) (*FieldValueHitQueueDefault[T], error) {
 	pq := structure.NewPriorityQueue(size, lessThan)
 
 	// When we get here, fields.length is guaranteed to be > 0, therefore no
@@ -75,9 +92,13 @@ func newFieldValueHitQueue[T any](fields []index.SortField, size int, lessThan f
 		if field.GetReverse() {
 			queue.reverseMul[i] = -1
 		}
-		queue.comparators[i] = field.GetComparator(size, i)
+		comparator, err := field.GetComparator(size, i)
+		if err != nil {
+			return nil, err
+		}
+		queue.comparators[i] = comparator
 	}
-	return queue
+	return queue, nil
 }
 
 func (f *FieldValueHitQueueDefault[T]) GetComparators(ctx index.LeafReaderContext) ([]index.LeafFieldComparator, error) {
@@ -100,6 +121,23 @@ func (f *FieldValueHitQueueDefault[T]) GetComparatorsList() []index.FieldCompara
 	return f.comparators
 }
 
+// GetFields Returns the SortFields being used by this hit queue.
+func (f *FieldValueHitQueueDefault[T]) GetFields() []index.SortField {
+	return f.fields
+}
+
+func (f *FieldValueHitQueueDefault[T]) GetPriorityQueue() *structure.PriorityQueue[T] {
+	return f.PriorityQueue
+}
+
+func (f *FieldValueHitQueueDefault[T]) FillFields(entry *Entry) FieldDoc {
+	fields := make([]any, len(f.comparators))
+	for i, comparator := range f.comparators {
+		fields[i] = comparator.Value(entry.slot)
+	}
+	return NewFieldDocV1(entry.GetDoc(), entry.GetScore(), fields)
+}
+
 type Entry struct {
 	*baseScoreDoc
 
@@ -119,12 +157,16 @@ type OneComparatorFieldValueHitQueue struct {
 	*FieldValueHitQueueDefault[*Entry]
 }
 
-func NewOneComparatorFieldValueHitQueue(fields []index.SortField, size int) *OneComparatorFieldValueHitQueue {
+func NewOneComparatorFieldValueHitQueue(fields []index.SortField, size int) (*OneComparatorFieldValueHitQueue, error) {
 	queue := &OneComparatorFieldValueHitQueue{}
-	queue.FieldValueHitQueueDefault = newFieldValueHitQueue(fields, size, queue.Less)
+	hitQueue, err := newFieldValueHitQueue(fields, size, queue.Less)
+	if err != nil {
+		return nil, err
+	}
+	queue.FieldValueHitQueueDefault = hitQueue
 	queue.oneComparator = queue.comparators[0]
 	queue.oneReverseMul = queue.reverseMul[0]
-	return queue
+	return queue, nil
 }
 
 func (o *OneComparatorFieldValueHitQueue) Less(hitA, hitB *Entry) bool {
@@ -141,10 +183,14 @@ type MultiComparatorsFieldValueHitQueue struct {
 	*FieldValueHitQueueDefault[*Entry]
 }
 
-func NewMultiComparatorsFieldValueHitQueue(fields []index.SortField, size int) *MultiComparatorsFieldValueHitQueue {
+func NewMultiComparatorsFieldValueHitQueue(fields []index.SortField, size int) (*MultiComparatorsFieldValueHitQueue, error) {
 	queue := &MultiComparatorsFieldValueHitQueue{}
-	queue.FieldValueHitQueueDefault = newFieldValueHitQueue(fields, size, queue.Less)
-	return queue
+	hitQueue, err := newFieldValueHitQueue(fields, size, queue.Less)
+	if err != nil {
+		return nil, err
+	}
+	queue.FieldValueHitQueueDefault = hitQueue
+	return queue, nil
 }
 
 func (m *MultiComparatorsFieldValueHitQueue) Less(hitA, hitB *Entry) bool {
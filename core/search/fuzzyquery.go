@@ -0,0 +1,216 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/attribute"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+const (
+	FUZZY_DEFAULT_MAX_EDITS      = automaton.MAXIMUM_SUPPORTED_DISTANCE
+	FUZZY_DEFAULT_PREFIX_LENGTH  = 0
+	FUZZY_DEFAULT_MAX_EXPANSIONS = 50
+	FUZZY_DEFAULT_TRANSPOSITIONS = true
+)
+
+var _ MultiTermQuery = &FuzzyQuery{}
+
+// FuzzyQuery
+// Implements the fuzzy search query. The similarity measurement is based on the Damerau-Levenshtein
+// (optimal string alignment) algorithm, though you can explicitly choose classic Levenshtein by
+// passing false to the transpositions parameter.
+//
+// This query uses TopTermsScoringBooleanQueryRewrite as default. So terms will be
+// collected and scored according to their edit distance. Only the top terms are used for building
+// the BooleanQuery. It is not recommended to change the rewrite mode for fuzzy queries.
+//
+// At most, this query will match terms up to 2 edits. Higher distances (especially with
+// transpositions enabled), are generally not useful and will match a significant amount of the term
+// dictionary. If you really want this, consider using an n-gram indexing technique (such as the
+// SpellChecker in the suggest module) instead.
+//
+// NOTE: terms of length 1 or 2 will sometimes not match because of how the scaled distance between
+// two terms is computed. For a term to match, the edit distance between the terms must be less than
+// the minimum length term (either the input term, or the candidate term). For example, FuzzyQuery
+// on term "abcd" with maxEdits=2 will not match an indexed term "ab", and FuzzyQuery on term "a"
+// with maxEdits=2 will not match an indexed term "abc".
+type FuzzyQuery struct {
+	maxEdits       int
+	maxExpansions  int
+	transpositions bool
+	prefixLength   int
+	term           index.Term
+	rewriteMethod  RewriteMethod
+}
+
+// NewFuzzyQuery
+// Calls NewFuzzyQueryV1(term, FUZZY_DEFAULT_MAX_EDITS, FUZZY_DEFAULT_PREFIX_LENGTH,
+// FUZZY_DEFAULT_MAX_EXPANSIONS, FUZZY_DEFAULT_TRANSPOSITIONS).
+func NewFuzzyQuery(term index.Term) *FuzzyQuery {
+	return &FuzzyQuery{
+		maxEdits:       FUZZY_DEFAULT_MAX_EDITS,
+		maxExpansions:  FUZZY_DEFAULT_MAX_EXPANSIONS,
+		transpositions: FUZZY_DEFAULT_TRANSPOSITIONS,
+		prefixLength:   FUZZY_DEFAULT_PREFIX_LENGTH,
+		term:           term,
+		rewriteMethod:  NewTopTermsScoringBooleanQueryRewrite(FUZZY_DEFAULT_MAX_EXPANSIONS),
+	}
+}
+
+// NewFuzzyQueryV1
+// Create a new FuzzyQuery that will match terms with an edit distance of at most maxEdits to term.
+// If a prefixLength > 0 is specified, a common prefix of that length is also required.
+//
+// term: the term to search for
+// maxEdits: must be >= 0 and <= automaton.MAXIMUM_SUPPORTED_DISTANCE.
+// prefixLength: length of common (non-fuzzy) prefix
+// maxExpansions: the maximum number of terms to match. If this number is greater than
+// GetMaxClauseCount when the query is rewritten, then the maxClauseCount will be used instead.
+// transpositions: true if transpositions should be treated as a primitive edit operation.
+// If this is false, comparisons will implement the classic Levenshtein algorithm.
+func NewFuzzyQueryV1(term index.Term, maxEdits, prefixLength, maxExpansions int, transpositions bool) (*FuzzyQuery, error) {
+	if maxEdits < 0 || maxEdits > automaton.MAXIMUM_SUPPORTED_DISTANCE {
+		return nil, fmt.Errorf("maxEdits must be between 0 and %d", automaton.MAXIMUM_SUPPORTED_DISTANCE)
+	}
+	if prefixLength < 0 {
+		return nil, errors.New("prefixLength cannot be negative")
+	}
+	if maxExpansions <= 0 {
+		return nil, errors.New("maxExpansions must be positive")
+	}
+
+	return &FuzzyQuery{
+		maxEdits:       maxEdits,
+		maxExpansions:  maxExpansions,
+		transpositions: transpositions,
+		prefixLength:   prefixLength,
+		term:           term,
+		rewriteMethod:  NewTopTermsScoringBooleanQueryRewrite(maxExpansions),
+	}, nil
+}
+
+// GetMaxEdits
+// Returns the maximum number of edit distances allowed for this query to match.
+func (f *FuzzyQuery) GetMaxEdits() int {
+	return f.maxEdits
+}
+
+// GetPrefixLength
+// Returns the non-fuzzy prefix length. This is the number of characters at the start of a term
+// that must be identical (not fuzzy) to the query term if the query is to match that term.
+func (f *FuzzyQuery) GetPrefixLength() int {
+	return f.prefixLength
+}
+
+// GetTranspositions
+// Returns true if transpositions should be treated as a primitive edit operation. If this is false,
+// comparisons will implement the classic Levenshtein algorithm.
+func (f *FuzzyQuery) GetTranspositions() bool {
+	return f.transpositions
+}
+
+// GetTerm
+// Returns the pattern term.
+func (f *FuzzyQuery) GetTerm() index.Term {
+	return f.term
+}
+
+func (f *FuzzyQuery) GetField() string {
+	return f.term.Field()
+}
+
+// exact returns true if the query can only match the term itself.
+func (f *FuzzyQuery) exact() bool {
+	return f.maxEdits == 0 || f.prefixLength >= utf8.RuneCountInString(f.term.Text())
+}
+
+func (f *FuzzyQuery) GetTermsEnum(terms index.Terms, atts *attribute.Source) (index.TermsEnum, error) {
+	if f.exact() {
+		// can only match if it's exact
+		tenum, err := terms.Iterator()
+		if err != nil {
+			return nil, err
+		}
+		return coreIndex.NewSingleTermsEnum(tenum, f.term.Bytes()), nil
+	}
+	return NewFuzzyTermsEnum(terms, f.term, f.maxEdits, f.prefixLength, f.transpositions)
+}
+
+func (f *FuzzyQuery) GetRewriteMethod() RewriteMethod {
+	return f.rewriteMethod
+}
+
+func (f *FuzzyQuery) SetRewriteMethod(method RewriteMethod) {
+	f.rewriteMethod = method
+}
+
+func (f *FuzzyQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if f.term.Field() != field {
+		buf.WriteString(f.term.Field())
+		buf.WriteString(":")
+	}
+	buf.WriteString(f.term.Text())
+	buf.WriteString("~")
+	buf.WriteString(strconv.Itoa(f.maxEdits))
+	return buf.String()
+}
+
+func (f *FuzzyQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return nil, fmt.Errorf("query %s does not implement createWeight, it must be rewritten first", f.String(""))
+}
+
+func (f *FuzzyQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return f.rewriteMethod.Rewrite(reader, f)
+}
+
+func (f *FuzzyQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(f.term.Field()) {
+		if f.exact() {
+			visitor.ConsumeTerms(f, f.term)
+			return nil
+		}
+
+		// Note: we're rebuilding the automaton here, so this can be expensive
+		a, err := buildFuzzyAutomaton(f.term.Text(), f.prefixLength, f.transpositions, f.maxEdits)
+		if err != nil {
+			return err
+		}
+		runAutomaton, err := automaton.NewByteRunAutomaton(a)
+		if err != nil {
+			return err
+		}
+		visitor.ConsumeTermsMatching(f, f.term.Field(), func() *automaton.ByteRunAutomaton {
+			return runAutomaton
+		})
+	}
+	return nil
+}
+
+func (f *FuzzyQuery) Equals(other index.Query) bool {
+	o, ok := other.(*FuzzyQuery)
+	return ok &&
+		f.maxEdits == o.maxEdits &&
+		f.prefixLength == o.prefixLength &&
+		f.maxExpansions == o.maxExpansions &&
+		f.transpositions == o.transpositions &&
+		f.rewriteMethod == o.rewriteMethod &&
+		TermEquals(f.term, o.term)
+}
+
+func (f *FuzzyQuery) HashCode() int {
+	h := ClassHash("FuzzyQuery")
+	h = 31*h + f.maxEdits
+	h = 31*h + f.prefixLength
+	h = 31*h + f.maxExpansions
+	h = 31*h + HashBool(f.transpositions)
+	h = 31*h + TermHashCode(f.term)
+	return h
+}
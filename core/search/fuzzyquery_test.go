@@ -0,0 +1,97 @@
+package search_test
+
+import (
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFuzzyQuery(t *testing.T, text string, maxEdits, prefixLength, maxExpansions int, transpositions bool) *search.FuzzyQuery {
+	query, err := search.NewFuzzyQueryV1(coreIndex.NewTerm("body", []byte(text)),
+		maxEdits, prefixLength, maxExpansions, transpositions)
+	assert.Nil(t, err)
+	return query
+}
+
+func newFuzzyTestSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", "lucene", "lucne", "lcuene", "lucenes", "kucene", "luxeme"),
+		searchtest.TextDocs("body", "lu", "müller", "muller", "mueller", "solr"))
+}
+
+func TestFuzzyQuery(t *testing.T) {
+	searcher := newFuzzyTestSearcher(t)
+
+	query := search.NewFuzzyQuery(coreIndex.NewTerm("body", []byte("lucene")))
+	assert.Equal(t, "body:lucene~2", query.String(""))
+	docs := searchDocs(t, searcher, query)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5}, docs)
+	// the exact term has the highest boost
+	assert.Equal(t, 0, docs[0])
+	// two substitutions score lowest
+	assert.Equal(t, 5, docs[len(docs)-1])
+
+	// terms are boosted by 1 - edits / min(term length, query length)
+	rewritten, err := searcher.Rewrite(query)
+	assert.Nil(t, err)
+	assert.Equal(t, "(body:kucene)^0.833333 (body:lcuene)^0.833333 (body:lucene)^1.000000 "+
+		"(body:lucenes)^0.833333 (body:lucne)^0.800000 (body:luxeme)^0.666667", rewritten.String(""))
+
+	for maxEdits, expected := range [][]int{{0}, {0, 1, 2, 3, 4}, {0, 1, 2, 3, 4, 5}} {
+		query := newFuzzyQuery(t, "lucene", maxEdits, 0, 50, true)
+		assert.ElementsMatch(t, expected, searchDocs(t, searcher, query), "maxEdits=%d", maxEdits)
+	}
+
+	assert.Empty(t, searchDocs(t, searcher, newFuzzyQuery(t, "elasticsearch", 2, 0, 50, true)))
+}
+
+func TestFuzzyQuery_Transpositions(t *testing.T) {
+	searcher := newFuzzyTestSearcher(t)
+
+	// "lcuene" is a single transposition, or two substitutions
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 1, 0, 50, true)))
+	assert.ElementsMatch(t, []int{0, 1, 3, 4}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 1, 0, 50, false)))
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 0, 50, false)))
+}
+
+func TestFuzzyQuery_PrefixLength(t *testing.T) {
+	searcher := newFuzzyTestSearcher(t)
+
+	// the prefix has to match exactly
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 5}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 1, 50, true)))
+	assert.ElementsMatch(t, []int{0, 1, 3, 5}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 2, 50, true)))
+	assert.ElementsMatch(t, []int{0, 3}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 5, 50, true)))
+
+	// a prefix as long as the term only matches the term
+	assert.Equal(t, []int{0}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 6, 50, true)))
+	assert.Equal(t, []int{0}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 10, 50, true)))
+}
+
+func TestFuzzyQuery_Unicode(t *testing.T) {
+	searcher := newFuzzyTestSearcher(t)
+
+	// ü is a single edit although it is two bytes long
+	assert.ElementsMatch(t, []int{7, 8}, searchDocs(t, searcher, newFuzzyQuery(t, "müller", 1, 0, 50, true)))
+	assert.ElementsMatch(t, []int{7, 8, 9}, searchDocs(t, searcher, newFuzzyQuery(t, "müller", 2, 0, 50, true)))
+	assert.ElementsMatch(t, []int{7, 8, 9}, searchDocs(t, searcher, newFuzzyQuery(t, "muller", 1, 0, 50, true)))
+	// the prefix is counted in code points
+	assert.Equal(t, []int{7}, searchDocs(t, searcher, newFuzzyQuery(t, "müler", 1, 2, 50, true)))
+}
+
+func TestFuzzyQuery_MaxExpansions(t *testing.T) {
+	searcher := newFuzzyTestSearcher(t)
+
+	// the best terms are kept, for equal boosts the lowest terms
+	assert.Equal(t, []int{0}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 0, 1, true)))
+	assert.ElementsMatch(t, []int{0, 4, 2}, searchDocs(t, searcher, newFuzzyQuery(t, "lucene", 2, 0, 3, true)))
+
+	_, err := search.NewFuzzyQueryV1(coreIndex.NewTerm("body", []byte("lucene")), 3, 0, 50, true)
+	assert.NotNil(t, err)
+	_, err = search.NewFuzzyQueryV1(coreIndex.NewTerm("body", []byte("lucene")), 2, -1, 50, true)
+	assert.NotNil(t, err)
+	_, err = search.NewFuzzyQueryV1(coreIndex.NewTerm("body", []byte("lucene")), 2, 0, 0, true)
+	assert.NotNil(t, err)
+}
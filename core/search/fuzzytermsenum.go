@@ -0,0 +1,136 @@
+package search
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+var _ coreIndex.FilteredTermsEnum = &FuzzyTermsEnum{}
+var _ BoostAttribute = &FuzzyTermsEnum{}
+
+// FuzzyTermsEnum
+// Subclass of TermsEnum for enumerating all terms that are similar to the specified filter term.
+//
+// Term enumerations are always ordered by BytesRef.compareTo. Each term in the enumeration is
+// greater than all that precede it.
+type FuzzyTermsEnum struct {
+	*coreIndex.FilteredTermsEnumBase
+
+	// matchers[ed] accepts the terms within ed edits of the term
+	matchers []*automaton.CharacterRunAutomaton
+
+	// the exact prefix of the term, every accepted term starts with it
+	prefix []byte
+
+	// length of the term, in code points
+	termLength int
+
+	boost float64
+}
+
+// NewFuzzyTermsEnum
+// Constructor for enumeration of all terms from specified reader which share a prefix of length
+// prefixLength with term and which have at most maxEdits edits.
+func NewFuzzyTermsEnum(terms index.Terms, term index.Term, maxEdits, prefixLength int, transpositions bool) (*FuzzyTermsEnum, error) {
+	text := term.Text()
+	termLength := utf8.RuneCountInString(text)
+	prefix, suffix := splitFuzzyPrefix(text, prefixLength)
+
+	builder := automaton.NewLevenshteinAutomata(suffix, transpositions)
+	matchers := make([]*automaton.CharacterRunAutomaton, 0, maxEdits+1)
+	for ed := 0; ed <= maxEdits; ed++ {
+		a, err := builder.ToAutomatonWithPrefix(ed, prefix)
+		if err != nil {
+			return nil, err
+		}
+		matcher, err := automaton.NewCharacterRunAutomaton(a)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	tenum, err := terms.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	enum := &FuzzyTermsEnum{
+		matchers:   matchers,
+		prefix:     []byte(prefix),
+		termLength: termLength,
+		boost:      1,
+	}
+	enum.FilteredTermsEnumBase = coreIndex.NewFilteredTermsEnumDefault(&coreIndex.FilteredTermsEnumDefaultConfig{
+		Accept:        enum.Accept,
+		NextSeekTerm:  enum.nextSeekTerm,
+		Tenum:         tenum,
+		StartWithSeek: len(prefix) > 0,
+	})
+	return enum, nil
+}
+
+// splits text after its first prefixLength code points
+func splitFuzzyPrefix(text string, prefixLength int) (string, string) {
+	prefixEnd, n := len(text), 0
+	for i := range text {
+		if n == prefixLength {
+			prefixEnd = i
+			break
+		}
+		n++
+	}
+	return text[:prefixEnd], text[prefixEnd:]
+}
+
+// Builds a binary Automaton to match a fuzzy term
+func buildFuzzyAutomaton(text string, prefixLength int, transpositions bool, maxEdits int) (*automaton.Automaton, error) {
+	prefix, suffix := splitFuzzyPrefix(text, prefixLength)
+	builder := automaton.NewLevenshteinAutomata(suffix, transpositions)
+	return builder.ToAutomatonWithPrefix(maxEdits, prefix)
+}
+
+// nextSeekTerm the enum only seeks once, to the first term of the prefix
+func (f *FuzzyTermsEnum) nextSeekTerm(currentTerm []byte) ([]byte, error) {
+	if currentTerm == nil {
+		return f.prefix, nil
+	}
+	return nil, nil
+}
+
+func (f *FuzzyTermsEnum) Accept(term []byte) (coreIndex.AcceptStatus, error) {
+	if !bytes.HasPrefix(term, f.prefix) {
+		// terms are sorted, no more term starts with the prefix
+		return coreIndex.ACCEPT_STATUS_END, nil
+	}
+
+	text := string(term)
+	ed := len(f.matchers) - 1
+	if !f.matchers[ed].Run(text) {
+		return coreIndex.ACCEPT_STATUS_NO, nil
+	}
+
+	// now compute exact edit distance
+	for ed > 0 && f.matchers[ed-1].Run(text) {
+		ed--
+	}
+
+	if ed == 0 {
+		// exact match
+		f.boost = 1
+	} else {
+		minTermLength := min(utf8.RuneCountInString(text), f.termLength)
+		f.boost = max(0, 1-float64(ed)/float64(minTermLength))
+	}
+	return coreIndex.ACCEPT_STATUS_YES, nil
+}
+
+// GetBoost
+// Returns the boost of the current term, 1 for an exact match and decreasing with the number of edits.
+func (f *FuzzyTermsEnum) GetBoost() float64 {
+	return f.boost
+}
@@ -1,19 +1,77 @@
 package search
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
+	"sync"
 
 	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
 
 const (
 	TOTAL_HITS_THRESHOLD = 1000
+
+	// MAX_DOCS_PER_SLICE thresholds for index slice allocation logic. To change the default,
+	// use WithMaxDocsPerSlice and WithMaxSegmentsPerSlice
+	MAX_DOCS_PER_SLICE     = 250_000
+	MAX_SEGMENTS_PER_SLICE = 5
+
+	// MAX_CACHED_QUERIES the maximum number of queries of the default query cache
+	MAX_CACHED_QUERIES = 1000
+	// MAX_CACHED_RAM_BYTES the memory budget of the default query cache
+	MAX_CACHED_RAM_BYTES = 1 << 25
 )
 
+// The query cache is enabled by default: the searchers share an LRUQueryCache of MAX_CACHED_QUERIES
+// queries and MAX_CACHED_RAM_BYTES bytes, filled following a UsageTrackingQueryCachingPolicy. Use
+// SetDefaultQueryCache(nil) to disable it, or IndexSearcher.SetQueryCache for a single searcher.
+var (
+	defaultQueryCacheLock     sync.RWMutex
+	defaultQueryCache         index.QueryCache         = NewLRUQueryCache(MAX_CACHED_QUERIES, MAX_CACHED_RAM_BYTES)
+	defaultQueryCachingPolicy index.QueryCachingPolicy = NewUsageTrackingQueryCachingPolicy(DEFAULT_HISTORY_SIZE)
+)
+
+// GetDefaultQueryCache
+// Expert: Get the default QueryCache or nil if the cache is disabled. The cache is enabled by default.
+func GetDefaultQueryCache() index.QueryCache {
+	defaultQueryCacheLock.RLock()
+	defer defaultQueryCacheLock.RUnlock()
+	return defaultQueryCache
+}
+
+// SetDefaultQueryCache
+// Expert: set the default QueryCache instance, nil disables caching. It only applies to the
+// searchers created afterwards.
+func SetDefaultQueryCache(queryCache index.QueryCache) {
+	defaultQueryCacheLock.Lock()
+	defer defaultQueryCacheLock.Unlock()
+	defaultQueryCache = queryCache
+}
+
+// GetDefaultQueryCachingPolicy
+// Expert: Get the default QueryCachingPolicy.
+func GetDefaultQueryCachingPolicy() index.QueryCachingPolicy {
+	defaultQueryCacheLock.RLock()
+	defer defaultQueryCacheLock.RUnlock()
+	return defaultQueryCachingPolicy
+}
+
+// SetDefaultQueryCachingPolicy
+// Expert: set the default QueryCachingPolicy instance. It only applies to the searchers
+// created afterwards.
+func SetDefaultQueryCachingPolicy(queryCachingPolicy index.QueryCachingPolicy) {
+	defaultQueryCacheLock.Lock()
+	defer defaultQueryCacheLock.Unlock()
+	defaultQueryCachingPolicy = queryCachingPolicy
+}
+
 var _ index.IndexSearcher = &IndexSearcher{}
 
 // IndexSearcher
@@ -45,9 +103,14 @@ type IndexSearcher struct {
 	readerContext index.IndexReaderContext
 	leafContexts  []index.LeafReaderContext
 
-	// used with executor - each slice holds a set of leafs executed within one thread
+	// used with executor - each slice holds a set of leafs executed within one goroutine
 	leafSlices []index.LeafSlice
 
+	// These are only used for multi-goroutine search
+	executor            Executor
+	maxDocsPerSlice     int
+	maxSegmentsPerSlice int
+
 	// the default Similarity
 	similarity index.Similarity
 
@@ -55,25 +118,72 @@ type IndexSearcher struct {
 	queryCachingPolicy index.QueryCachingPolicy
 }
 
+// GetQueryCache
+// Return the query cache of this IndexSearcher. This will be either the default query cache
+// or the query cache that was last set through SetQueryCache. A return value of nil indicates
+// that caching is disabled.
 func (r *IndexSearcher) GetQueryCache() (index.QueryCache, error) {
-	return nil, errors.New("unsupported queryCache")
+	return r.queryCache, nil
 }
 
+// SetQueryCachingPolicy
+// Set the QueryCachingPolicy to use for query caching. This method should be called before
+// starting using this IndexSearcher.
 func (r *IndexSearcher) SetQueryCachingPolicy(queryCachingPolicy index.QueryCachingPolicy) {
-	return
+	r.queryCachingPolicy = queryCachingPolicy
 }
 
+// GetQueryCachingPolicy
+// Return the query caching policy of this IndexSearcher. This will be either the default policy or the
+// policy that was last set through SetQueryCachingPolicy.
 func (r *IndexSearcher) GetQueryCachingPolicy() (index.QueryCachingPolicy, error) {
-	return nil, errors.New("unsupported queryCachingPolicy")
+	return r.queryCachingPolicy, nil
 }
 
+// Slices
+// Expert: Creates an array of leaf slices each holding a subset of the given leaves. Each
+// LeafSlice is executed in a single goroutine. By default, segments with more than
+// maxDocsPerSlice documents get their own slice, smaller segments are grouped together.
 func (r *IndexSearcher) Slices(leaves []index.LeafReaderContext) []index.LeafSlice {
-	slices := make([]index.LeafSlice, 0, len(leaves))
-	for _, leaf := range leaves {
-		item := index.LeafSlice{Leaves: []index.LeafReaderContext{leaf}}
-		slices = append(slices, item)
+	return SlicesWithLimits(leaves, r.maxDocsPerSlice, r.maxSegmentsPerSlice)
+}
+
+// SlicesWithLimits
+// Static method to segregate LeafReaderContexts amongst multiple slices, the largest segments
+// are considered first. A slice is closed once it holds maxSegmentsPerSlice segments or more
+// than maxDocsPerSlice documents.
+func SlicesWithLimits(leaves []index.LeafReaderContext, maxDocsPerSlice, maxSegmentsPerSlice int) []index.LeafSlice {
+	// Make a copy so we can sort:
+	sortedLeaves := slices.Clone(leaves)
+
+	// Sort by maxDoc, descending:
+	slices.SortStableFunc(sortedLeaves, func(a, b index.LeafReaderContext) int {
+		return cmp.Compare(b.Reader().MaxDoc(), a.Reader().MaxDoc())
+	})
+
+	groupedLeaves := make([]index.LeafSlice, 0)
+	docSum := 0
+	var group []index.LeafReaderContext
+	for _, leaf := range sortedLeaves {
+		maxDoc := leaf.Reader().MaxDoc()
+		if maxDoc > maxDocsPerSlice {
+			groupedLeaves = append(groupedLeaves, index.LeafSlice{Leaves: []index.LeafReaderContext{leaf}})
+			continue
+		}
+
+		group = append(group, leaf)
+		docSum += maxDoc
+		if len(group) >= maxSegmentsPerSlice || docSum > maxDocsPerSlice {
+			groupedLeaves = append(groupedLeaves, index.LeafSlice{Leaves: group})
+			group = nil
+			docSum = 0
+		}
+	}
+
+	if len(group) > 0 {
+		groupedLeaves = append(groupedLeaves, index.LeafSlice{Leaves: group})
 	}
-	return slices
+	return groupedLeaves
 }
 
 func (r *IndexSearcher) Doc(ctx context.Context, docID int) (*document.Document, error) {
@@ -127,7 +237,7 @@ func (r *IndexSearcher) Count(query index.Query) (int, error) {
 		return count, nil
 	}
 
-	v, err := r.SearchByCollectorManager(nil, query, &collectorManager{})
+	v, err := r.SearchByCollectorManager(context.Background(), query, &collectorManager{})
 	if err != nil {
 		return 0, err
 	}
@@ -155,15 +265,57 @@ func (r *IndexSearcher) GetSlices() []index.LeafSlice {
 	return r.leafSlices
 }
 
-func NewIndexSearcher(r index.IndexReader) (index.IndexSearcher, error) {
+type indexSearcherOption struct {
+	executor            Executor
+	maxDocsPerSlice     int
+	maxSegmentsPerSlice int
+}
+
+type IndexSearcherOption func(*indexSearcherOption)
+
+// WithExecutor
+// Runs the leaf slices of a search concurrently on executor. Without an executor the leaves
+// are searched sequentially by the calling goroutine.
+func WithExecutor(executor Executor) IndexSearcherOption {
+	return func(o *indexSearcherOption) {
+		o.executor = executor
+	}
+}
+
+// WithMaxDocsPerSlice
+// Segments with more documents than maxDocsPerSlice are searched in their own slice.
+func WithMaxDocsPerSlice(maxDocsPerSlice int) IndexSearcherOption {
+	return func(o *indexSearcherOption) {
+		o.maxDocsPerSlice = maxDocsPerSlice
+	}
+}
+
+// WithMaxSegmentsPerSlice
+// At most maxSegmentsPerSlice small segments are grouped into one slice.
+func WithMaxSegmentsPerSlice(maxSegmentsPerSlice int) IndexSearcherOption {
+	return func(o *indexSearcherOption) {
+		o.maxSegmentsPerSlice = maxSegmentsPerSlice
+	}
+}
+
+func NewIndexSearcher(r index.IndexReader, options ...IndexSearcherOption) (index.IndexSearcher, error) {
 	ctx, err := r.GetContext()
 	if err != nil {
 		return nil, err
 	}
-	return newIndexSearcher(ctx)
+
+	opt := &indexSearcherOption{
+		executor:            nil,
+		maxDocsPerSlice:     MAX_DOCS_PER_SLICE,
+		maxSegmentsPerSlice: MAX_SEGMENTS_PER_SLICE,
+	}
+	for _, fn := range options {
+		fn(opt)
+	}
+	return newIndexSearcher(ctx, opt)
 }
 
-func newIndexSearcher(readerContext index.IndexReaderContext) (*IndexSearcher, error) {
+func newIndexSearcher(readerContext index.IndexReaderContext, opt *indexSearcherOption) (*IndexSearcher, error) {
 	leaves, err := readerContext.Leaves()
 	if err != nil {
 		return nil, err
@@ -174,15 +326,22 @@ func newIndexSearcher(readerContext index.IndexReaderContext) (*IndexSearcher, e
 		return nil, err
 	}
 
-	return &IndexSearcher{
-		reader:             readerContext.Reader(),
-		readerContext:      readerContext,
-		leafContexts:       leaves,
-		leafSlices:         nil,
-		similarity:         similarity,
-		queryCache:         nil,
-		queryCachingPolicy: nil,
-	}, nil
+	searcher := &IndexSearcher{
+		reader:              readerContext.Reader(),
+		readerContext:       readerContext,
+		leafContexts:        leaves,
+		leafSlices:          nil,
+		executor:            opt.executor,
+		maxDocsPerSlice:     opt.maxDocsPerSlice,
+		maxSegmentsPerSlice: opt.maxSegmentsPerSlice,
+		similarity:          similarity,
+		queryCache:          GetDefaultQueryCache(),
+		queryCachingPolicy:  GetDefaultQueryCachingPolicy(),
+	}
+	if searcher.executor != nil {
+		searcher.leafSlices = searcher.Slices(leaves)
+	}
+	return searcher, nil
 }
 
 func (r *IndexSearcher) GetTopReaderContext() index.IndexReaderContext {
@@ -197,6 +356,10 @@ func (r *IndexSearcher) SetSimilarity(similarity index.Similarity) {
 	r.similarity = similarity
 }
 
+// SetQueryCache
+// Set the QueryCache to use when scores are not needed. A value of nil indicates that query
+// matches should never be cached. This method should be called before starting using this
+// IndexSearcher.
 func (r *IndexSearcher) SetQueryCache(queryCache index.QueryCache) {
 	r.queryCache = queryCache
 }
@@ -286,6 +449,99 @@ func (s *searchAfterCollectorManager) Reduce(collectors []index.Collector) (any,
 	return MergeTopDocs(0, s.cappedNumHits, topDocs, true)
 }
 
+// SearchWithSort
+// Search implementation with arbitrary sorting. Finds the top n hits for query, the hits are
+// sorted by sort, the returned TopFieldDocs holds FieldDocs with the sort values of every hit.
+func (r *IndexSearcher) SearchWithSort(ctx context.Context, query index.Query, n int, sort index.Sort) (index.TopDocs, error) {
+	return r.searchAfterWithSort(ctx, nil, query, n, sort)
+}
+
+// SearchAfterWithSort
+// Finds the top n hits for query where all results are after a previous result (after),
+// allowing control over whether hit scores and max score should be computed.
+// By passing the bottom result from a previous page as after, this method can be used for
+// efficient 'deep-paging' across potentially large result sets. after must be a FieldDoc
+// returned by a previous search with the same sort.
+func (r *IndexSearcher) SearchAfterWithSort(ctx context.Context, after index.ScoreDoc, query index.Query, n int, sort index.Sort) (index.TopDocs, error) {
+	if after == nil {
+		return r.searchAfterWithSort(ctx, nil, query, n, sort)
+	}
+
+	fieldDoc, ok := after.(FieldDoc)
+	if !ok {
+		return nil, fmt.Errorf("after must be a FieldDoc; got %v", after)
+	}
+	return r.searchAfterWithSort(ctx, fieldDoc, query, n, sort)
+}
+
+func (r *IndexSearcher) searchAfterWithSort(ctx context.Context, after FieldDoc, query index.Query, numHits int, sort index.Sort) (index.TopDocs, error) {
+	limit := max(1, r.reader.MaxDoc())
+	if after != nil && after.GetDoc() >= limit {
+		return nil, errors.New("after.doc exceeds the number of documents in the reader")
+	}
+
+	cappedNumHits := min(numHits, limit)
+
+	manager := &searchAfterSortCollectorManager{
+		sort:          sort,
+		cappedNumHits: cappedNumHits,
+		after:         after,
+	}
+
+	if len(r.leafSlices) <= 1 {
+		hitsThresholdChecker, err := HitsThresholdCheckerCreate(max(TOTAL_HITS_THRESHOLD, numHits))
+		if err != nil {
+			return nil, err
+		}
+		manager.hitsThresholdChecker = hitsThresholdChecker
+	} else {
+		manager.minScoreAcc = NewMaxScoreAccumulator()
+		hitsThresholdChecker, err := HitsThresholdCheckerCreateShared(max(TOTAL_HITS_THRESHOLD, numHits))
+		if err != nil {
+			return nil, err
+		}
+		manager.hitsThresholdChecker = hitsThresholdChecker
+	}
+
+	v, err := r.SearchByCollectorManager(ctx, query, manager)
+	if err != nil {
+		return nil, err
+	}
+
+	topDocs, ok := v.(index.TopDocs)
+	if !ok {
+		return nil, errors.New("object is not TopDocs")
+	}
+
+	return topDocs, nil
+}
+
+var _ CollectorManager = &searchAfterSortCollectorManager{}
+
+type searchAfterSortCollectorManager struct {
+	sort                 index.Sort
+	hitsThresholdChecker HitsThresholdChecker
+	minScoreAcc          *MaxScoreAccumulator
+	cappedNumHits        int
+	after                FieldDoc
+}
+
+func (s *searchAfterSortCollectorManager) NewCollector() (index.Collector, error) {
+	return TopTopFieldCollectorCreate(s.sort, s.cappedNumHits, s.after, s.hitsThresholdChecker, s.minScoreAcc)
+}
+
+func (s *searchAfterSortCollectorManager) Reduce(collectors []index.Collector) (any, error) {
+	topDocs := make([]index.TopDocs, len(collectors))
+	for i, collector := range collectors {
+		docs, err := collector.(TopDocsCollector).TopDocs()
+		if err != nil {
+			return nil, err
+		}
+		topDocs[i] = docs
+	}
+	return MergeTopFieldDocs(s.sort, 0, s.cappedNumHits, topDocs, true)
+}
+
 // SearchByCollectorManager
 // Lower-level search API. Search all leaves using the given CollectorManager.
 // In contrast to search(Query, Collector), this method will use the searcher's
@@ -295,7 +551,7 @@ func (s *searchAfterCollectorManager) Reduce(collectors []index.Collector) (any,
 func (r *IndexSearcher) SearchByCollectorManager(ctx context.Context,
 	query index.Query, collectorManager CollectorManager) (any, error) {
 
-	if len(r.leafSlices) <= 1 {
+	if r.executor == nil || len(r.leafSlices) <= 1 {
 		collector, err := collectorManager.NewCollector()
 		if err != nil {
 			return nil, err
@@ -303,10 +559,9 @@ func (r *IndexSearcher) SearchByCollectorManager(ctx context.Context,
 		if err := r.SearchCollector(ctx, query, collector); err != nil {
 			return nil, err
 		}
-		return collectorManager.Reduce([]index.Collector{collector.(TopScoreDocCollector)})
+		return collectorManager.Reduce([]index.Collector{collector})
 	}
 
-	// TODO: fix it
 	collectors := make([]index.Collector, 0, len(r.leafSlices))
 	var scoreMode *index.ScoreMode
 	for i := 0; i < len(r.leafSlices); i++ {
@@ -327,11 +582,6 @@ func (r *IndexSearcher) SearchByCollectorManager(ctx context.Context,
 		}
 	}
 
-	if scoreMode == nil {
-		// no segments
-		scoreMode = &COMPLETE
-	}
-
 	query, err := r.Rewrite(query)
 	if err != nil {
 		return nil, err
@@ -342,17 +592,35 @@ func (r *IndexSearcher) SearchByCollectorManager(ctx context.Context,
 		return nil, err
 	}
 
+	// the first failing slice cancels the slices which are still running
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
 	for i, item := range r.leafSlices {
-		leaves := item.Leaves
+		leaves, collector := item.Leaves, collectors[i]
 
-		collector := collectors[i]
+		wg.Add(1)
+		r.executor.Execute(func() {
+			defer wg.Done()
 
-		// TODO: try use WaitGroup
-		if err := r.SearchLeaves(ctx, leaves, weight, collector); err != nil {
-			return nil, err
-		}
+			if err := r.SearchLeaves(ctx, leaves, weight, collector); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		})
 	}
+	wg.Wait()
 
+	if firstErr != nil {
+		return nil, firstErr
+	}
 	return collectorManager.Reduce(collectors)
 }
 
@@ -376,9 +644,18 @@ func (r *IndexSearcher) SearchCollector(ctx context.Context, query index.Query,
 func (r *IndexSearcher) SearchLeaves(ctx context.Context, leaves []index.LeafReaderContext, weight index.Weight, collector index.Collector) error {
 
 	for _, leaf := range leaves {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		leafCollector, err := collector.GetLeafCollector(ctx, leaf)
 		if err != nil {
-			continue
+			if errors.Is(err, ErrCollectionTerminated) {
+				// there is no doc of interest in this reader context
+				// continue with the following leaf
+				continue
+			}
+			return err
 		}
 
 		scorer, err := weight.BulkScorer(leaf)
@@ -388,6 +665,11 @@ func (r *IndexSearcher) SearchLeaves(ctx context.Context, leaves []index.LeafRea
 
 		if scorer != nil {
 			if _, err := scorer.Score(leafCollector, leaf.LeafReader().GetLiveDocs(), -1, -1); err != nil {
+				if errors.Is(err, ErrCollectionTerminated) {
+					// collection was terminated prematurely
+					// continue with the following leaf
+					continue
+				}
 				return err
 			}
 		}
@@ -397,15 +679,15 @@ func (r *IndexSearcher) SearchLeaves(ctx context.Context, leaves []index.LeafRea
 }
 
 func (r *IndexSearcher) CreateWeight(query index.Query, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
-	//queryCache := r.queryCache
+	queryCache := r.queryCache
 	weight, err := query.CreateWeight(r, scoreMode, boost)
 	if err != nil {
 		return nil, err
 	}
 
-	//if !scoreMode.NeedsScores() && queryCache != nil {
-	//	weight = queryCache.DoCache(weight, r.queryCachingPolicy)
-	//}
+	if !scoreMode.NeedsScores() && queryCache != nil && r.queryCachingPolicy != nil {
+		weight = queryCache.DoCache(weight, r.queryCachingPolicy)
+	}
 	return weight, nil
 }
 
@@ -426,6 +708,43 @@ func (r *IndexSearcher) Rewrite(query index.Query) (index.Query, error) {
 	return query, nil
 }
 
+// Explain
+// Returns an Explanation that describes how doc scored against query.
+// This is intended to be used in developing Similarity implementations, and, for good performance,
+// should not be displayed with every hit. Computing an explanation is as expensive as executing the
+// query over the entire index.
+func (r *IndexSearcher) Explain(query index.Query, doc int) (types.Explanation, error) {
+	query, err := r.Rewrite(query)
+	if err != nil {
+		return nil, err
+	}
+	weight, err := r.CreateWeight(query, COMPLETE, 1)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExplainWeight(weight, doc)
+}
+
+// ExplainWeight
+// Expert: low-level implementation method Returns an Explanation that describes how doc scored
+// against weight.
+// doc is the global document id, it is resolved to the leaf that contains it before
+// Weight.Explain is called.
+func (r *IndexSearcher) ExplainWeight(weight index.Weight, doc int) (types.Explanation, error) {
+	if doc < 0 || doc >= r.reader.MaxDoc() {
+		return nil, fmt.Errorf("doc %d is out of bounds [0, %d)", doc, r.reader.MaxDoc())
+	}
+
+	n := coreIndex.SubIndexV1(doc, r.leafContexts)
+	leaf := r.leafContexts[n]
+	deBasedDoc := doc - leaf.DocBase()
+	liveDocs := leaf.LeafReader().GetLiveDocs()
+	if liveDocs != nil && !liveDocs.Test(uint(deBasedDoc)) {
+		return types.ExplanationNoMatch(fmt.Sprintf("Document %d is deleted", doc)), nil
+	}
+	return weight.Explain(leaf, deBasedDoc)
+}
+
 // GetSimilarity
 // Expert: Get the Similarity to use to compute scores. This returns the Similarity
 // that has been set through setSimilarity(Similarity) or the default Similarity if none has been set explicitly.
@@ -491,6 +810,3 @@ func (r *IndexSearcher) CollectionStatistics(field string) (types.CollectionStat
 func (r *IndexSearcher) TermStatistics(term index.Term, docFreq, totalTermFreq int) (types.TermStatistics, error) {
 	return types.NewTermStatistics(term.Bytes(), int64(docFreq), int64(totalTermFreq))
 }
-
-type Executor interface {
-}
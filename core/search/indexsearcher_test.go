@@ -0,0 +1,205 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// segmentsOfSizes Returns segments holding the given number of documents each
+func segmentsOfSizes(sizes ...int) [][]*document.Document {
+	segments := make([][]*document.Document, 0, len(sizes))
+	for i, size := range sizes {
+		values := make([]string, 0, size)
+		for j := 0; j < size; j++ {
+			values = append(values, fmt.Sprintf("common doc%d_%d", i, j))
+		}
+		segments = append(segments, searchtest.TextDocs("body", values...))
+	}
+	return segments
+}
+
+// sliceSizes Returns the maxDoc of the leaves of every slice
+func sliceSizes(slices []index.LeafSlice) [][]int {
+	sizes := make([][]int, 0, len(slices))
+	for _, slice := range slices {
+		leaves := make([]int, 0, len(slice.Leaves))
+		for _, leaf := range slice.Leaves {
+			leaves = append(leaves, leaf.Reader().MaxDoc())
+		}
+		sizes = append(sizes, leaves)
+	}
+	return sizes
+}
+
+func TestSlicesWithLimits(t *testing.T) {
+	reader := searchtest.NewReader(t, segmentsOfSizes(1, 7, 3, 5, 2, 6, 4)...)
+	leaves, err := reader.Leaves()
+	assert.Nil(t, err)
+
+	// segments over maxDocsPerSlice are alone, smaller ones are grouped by size
+	assert.Equal(t, [][]int{{7}, {6}, {5, 4}, {3, 2}, {1}}, sliceSizes(search.SlicesWithLimits(leaves, 5, 2)))
+
+	// a slice is closed once it holds more than maxDocsPerSlice documents
+	assert.Equal(t, [][]int{{7, 6}, {5, 4, 3}, {2, 1}}, sliceSizes(search.SlicesWithLimits(leaves, 10, 5)))
+
+	// or maxSegmentsPerSlice segments
+	assert.Equal(t, [][]int{{7}, {6}, {5}, {4}, {3}, {2}, {1}}, sliceSizes(search.SlicesWithLimits(leaves, 100, 1)))
+	assert.Equal(t, [][]int{{7, 6, 5, 4, 3, 2, 1}}, sliceSizes(search.SlicesWithLimits(leaves, 100, 10)))
+
+	assert.Empty(t, search.SlicesWithLimits(nil, 5, 2))
+}
+
+func TestIndexSearcher_Slices(t *testing.T) {
+	reader := searchtest.NewReader(t, segmentsOfSizes(1, 7, 3, 5, 2, 6, 4)...)
+
+	// slices are only computed for an executor
+	searcher, err := search.NewIndexSearcher(reader)
+	assert.Nil(t, err)
+	assert.Empty(t, searcher.(*search.IndexSearcher).GetSlices())
+
+	searcher, err = search.NewIndexSearcher(reader, search.WithExecutor(search.NewBoundedExecutor(4)),
+		search.WithMaxDocsPerSlice(5), search.WithMaxSegmentsPerSlice(2))
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{7}, {6}, {5, 4}, {3, 2}, {1}}, sliceSizes(searcher.(*search.IndexSearcher).GetSlices()))
+}
+
+// testCollector Counts hits, the collection of a leaf may fail or wait for the search to be
+// cancelled
+type testCollector struct {
+	*search.TotalHitCountCollector
+
+	failOn   func(leaf index.LeafReaderContext) error
+	waitOn   func(leaf index.LeafReaderContext) bool
+	waiting  chan struct{}
+	canceled *atomic.Int32
+}
+
+func (c *testCollector) GetLeafCollector(ctx context.Context, leaf index.LeafReaderContext) (index.LeafCollector, error) {
+	if c.failOn != nil {
+		if err := c.failOn(leaf); err != nil {
+			return nil, err
+		}
+	}
+	if c.waitOn != nil && c.waitOn(leaf) {
+		c.waiting <- struct{}{}
+		select {
+		case <-ctx.Done():
+			c.canceled.Add(1)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("the search was not cancelled")
+		}
+	}
+	return c.TotalHitCountCollector.GetLeafCollector(ctx, leaf)
+}
+
+type testCollectorManager struct {
+	failOn   func(leaf index.LeafReaderContext) error
+	waitOn   func(leaf index.LeafReaderContext) bool
+	waiting  chan struct{}
+	canceled atomic.Int32
+}
+
+func newTestCollectorManager() *testCollectorManager {
+	return &testCollectorManager{waiting: make(chan struct{}, 16)}
+}
+
+func (m *testCollectorManager) NewCollector() (index.Collector, error) {
+	return &testCollector{
+		TotalHitCountCollector: search.NewTotalHitCountCollector(),
+		failOn:                 m.failOn,
+		waitOn:                 m.waitOn,
+		waiting:                m.waiting,
+		canceled:               &m.canceled,
+	}, nil
+}
+
+func (m *testCollectorManager) Reduce(collectors []index.Collector) (any, error) {
+	total := 0
+	for _, collector := range collectors {
+		total += collector.(*testCollector).GetTotalHits()
+	}
+	return total, nil
+}
+
+func TestIndexSearcher_SearchByCollectorManager(t *testing.T) {
+	sizes := []int{8, 7, 6, 5, 4, 3, 2, 1}
+	reader := searchtest.NewReader(t, segmentsOfSizes(sizes...)...)
+	newSearcher, err := search.NewIndexSearcher(reader, search.WithExecutor(search.NewBoundedExecutor(4)),
+		search.WithMaxDocsPerSlice(5), search.WithMaxSegmentsPerSlice(1))
+	assert.Nil(t, err)
+	searcher := newSearcher.(*search.IndexSearcher)
+	assert.Len(t, searcher.GetSlices(), len(sizes))
+
+	query := search.NewTermQuery(coreIndex.NewTerm("body", []byte("common")))
+
+	t.Run("parallel", func(t *testing.T) {
+		total, err := searcher.SearchByCollectorManager(context.Background(), query, newTestCollectorManager())
+		assert.Nil(t, err)
+		assert.Equal(t, 36, total)
+
+		// concurrent searches share the searcher
+		done := make(chan any, 8)
+		for i := 0; i < cap(done); i++ {
+			go func() {
+				total, err := searcher.SearchByCollectorManager(context.Background(), query, newTestCollectorManager())
+				assert.Nil(t, err)
+				done <- total
+			}()
+		}
+		for i := 0; i < cap(done); i++ {
+			assert.Equal(t, 36, <-done)
+		}
+	})
+
+	t.Run("first error", func(t *testing.T) {
+		failure := errors.New("leaf failed")
+		manager := newTestCollectorManager()
+		// two other slices only end once the search is cancelled
+		manager.waitOn = func(leaf index.LeafReaderContext) bool {
+			return leaf.Reader().MaxDoc() == 7 || leaf.Reader().MaxDoc() == 6
+		}
+		manager.failOn = func(leaf index.LeafReaderContext) error {
+			if leaf.Reader().MaxDoc() != 8 {
+				return nil
+			}
+			<-manager.waiting
+			<-manager.waiting
+			return failure
+		}
+		_, err := searcher.SearchByCollectorManager(context.Background(), query, manager)
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, int32(2), manager.canceled.Load())
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := searcher.SearchByCollectorManager(ctx, query, newTestCollectorManager())
+		assert.ErrorIs(t, err, context.Canceled)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		manager := newTestCollectorManager()
+		manager.waitOn = func(leaf index.LeafReaderContext) bool {
+			return leaf.Reader().MaxDoc() == 8
+		}
+		go func() {
+			<-manager.waiting
+			cancel()
+		}()
+		_, err = searcher.SearchByCollectorManager(ctx, query, manager)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(1), manager.canceled.Load())
+	})
+}
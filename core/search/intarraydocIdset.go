@@ -3,6 +3,7 @@ package search
 import (
 	"context"
 	"io"
+	"sort"
 
 	"github.com/geange/lucene-go/core/types"
 	"github.com/geange/lucene-go/core/util"
@@ -15,13 +16,13 @@ type IntArrayDocIdSet struct {
 }
 
 func (r *IntArrayDocIdSet) Iterator() types.DocIdSetIterator {
-	//TODO implement me
-	panic("implement me")
+	return NewIntArrayDocIdSetIterator(r.docs)
 }
 
+// Bits
+// The docs are only stored as a sorted list, random access is not supported.
 func (r *IntArrayDocIdSet) Bits() util.Bits {
-	//TODO implement me
-	panic("implement me")
+	return nil
 }
 
 func NewIntArrayDocIdSet(docs []int) *IntArrayDocIdSet {
@@ -49,7 +50,8 @@ func (r *IntArrayDocIdSetIterator) DocID() int {
 
 func (r *IntArrayDocIdSetIterator) NextDoc(context.Context) (int, error) {
 	if r.i == len(r.docs) {
-		return -1, io.EOF
+		r.doc = types.NO_MORE_DOCS
+		return r.doc, io.EOF
 	}
 
 	r.doc = r.docs[r.i]
@@ -58,7 +60,9 @@ func (r *IntArrayDocIdSetIterator) NextDoc(context.Context) (int, error) {
 }
 
 func (r *IntArrayDocIdSetIterator) Advance(ctx context.Context, target int) (int, error) {
-	return r.SlowAdvance(nil, target)
+	// the docs are sorted, binary search the first doc >= target among the remaining ones
+	r.i += sort.SearchInts(r.docs[r.i:], target)
+	return r.NextDoc(ctx)
 }
 
 func (r *IntArrayDocIdSetIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
@@ -0,0 +1,136 @@
+package intervals
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ IntervalsSource = &blockIntervalsSource{}
+
+type blockIntervalsSource struct {
+	*conjunctionIntervalsSource
+}
+
+func newBlockIntervalsSource(subSources []IntervalsSource) *blockIntervalsSource {
+	source := &blockIntervalsSource{}
+	source.conjunctionIntervalsSource = newConjunctionIntervalsSource(flattenBlocks(subSources), false, source)
+	return source
+}
+
+// flattenBlocks
+// A block nested inside another block is the same as its sources inlined in the outer block
+func flattenBlocks(sources []IntervalsSource) []IntervalsSource {
+	flattened := make([]IntervalsSource, 0, len(sources))
+	for _, source := range sources {
+		if block, ok := source.(*blockIntervalsSource); ok {
+			flattened = append(flattened, block.subSources...)
+		} else {
+			flattened = append(flattened, source)
+		}
+	}
+	return flattened
+}
+
+func (b *blockIntervalsSource) combine(iterators []IntervalIterator) (IntervalIterator, error) {
+	return newBlockIntervalIterator(iterators)
+}
+
+func (b *blockIntervalsSource) MinExtent() int {
+	minExtent := 0
+	for _, source := range b.subSources {
+		minExtent += source.MinExtent()
+	}
+	return minExtent
+}
+
+func (b *blockIntervalsSource) String() string {
+	return "BLOCK(" + joinSources(b.subSources) + ")"
+}
+
+var _ IntervalIterator = &blockIntervalIterator{}
+
+// blockIntervalIterator
+// Returns the intervals in which all sub-intervals appear in order and directly follow each other
+type blockIntervalIterator struct {
+	*conjunctionIntervalIterator
+
+	start, end int
+}
+
+func newBlockIntervalIterator(subIterators []IntervalIterator) (*blockIntervalIterator, error) {
+	it := &blockIntervalIterator{start: -1, end: -1}
+	conjunction, err := newConjunctionIntervalIterator(subIterators, it)
+	if err != nil {
+		return nil, err
+	}
+	it.conjunctionIntervalIterator = conjunction
+	return it, nil
+}
+
+func (b *blockIntervalIterator) Start() int {
+	return b.start
+}
+
+func (b *blockIntervalIterator) End() int {
+	return b.end
+}
+
+func (b *blockIntervalIterator) Gaps() int {
+	return 0
+}
+
+func (b *blockIntervalIterator) NextInterval() (int, error) {
+	subIterators := b.subIterators
+	if ok, err := b.advance(subIterators[0]); err != nil || !ok {
+		return b.start, err
+	}
+
+	i := 1
+	for i < len(subIterators) {
+		for subIterators[i].Start() <= subIterators[i-1].End() {
+			if ok, err := b.advance(subIterators[i]); err != nil || !ok {
+				return b.start, err
+			}
+		}
+		if subIterators[i].Start() == subIterators[i-1].End()+1 {
+			i++
+		} else {
+			if ok, err := b.advance(subIterators[0]); err != nil || !ok {
+				return b.start, err
+			}
+			i = 1
+		}
+	}
+	b.start = subIterators[0].Start()
+	b.end = subIterators[len(subIterators)-1].End()
+	return b.start, nil
+}
+
+// advance
+// Moves it to its next interval, returns false and exhausts this iterator if there is none
+func (b *blockIntervalIterator) advance(it IntervalIterator) (bool, error) {
+	start, err := it.NextInterval()
+	if err != nil {
+		return false, err
+	}
+	if start == NO_MORE_INTERVALS {
+		b.start, b.end = NO_MORE_INTERVALS, NO_MORE_INTERVALS
+		return false, nil
+	}
+	return true, nil
+}
+
+func (b *blockIntervalIterator) reset(ctx context.Context) error {
+	b.start, b.end = -1, -1
+	return nil
+}
+
+func (s *blockIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*blockIntervalsSource)
+	return ok && s.equalsTo(o.conjunctionIntervalsSource)
+}
+
+func (s *blockIntervalsSource) HashCode() int {
+	return 31*search.ClassHash("BlockIntervalsSource") + s.fieldsHashCode()
+}
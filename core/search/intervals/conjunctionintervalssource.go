@@ -0,0 +1,181 @@
+package intervals
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// conjunctionIntervalsSourceSPI
+// Implemented by the sources embedding conjunctionIntervalsSource, combine builds the iterator
+// of the source from the iterators of its sub-sources.
+type conjunctionIntervalsSourceSPI interface {
+	IntervalsSource
+
+	combine(iterators []IntervalIterator) (IntervalIterator, error)
+}
+
+// conjunctionIntervalsSource
+// Shared implementation of the sources that only match when all of their sub-sources match
+type conjunctionIntervalsSource struct {
+	subSources []IntervalsSource
+
+	// minimizing iterators advance their sub-iterators beyond the intervals they report, the
+	// matches of the sub-sources are then cached to keep their offsets available
+	isMinimizing bool
+
+	spi conjunctionIntervalsSourceSPI
+}
+
+func newConjunctionIntervalsSource(subSources []IntervalsSource, isMinimizing bool,
+	spi conjunctionIntervalsSourceSPI) *conjunctionIntervalsSource {
+
+	return &conjunctionIntervalsSource{
+		subSources:   subSources,
+		isMinimizing: isMinimizing,
+		spi:          spi,
+	}
+}
+
+func (c *conjunctionIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	subIntervals := make([]IntervalIterator, 0, len(c.subSources))
+	for _, source := range c.subSources {
+		it, err := source.Intervals(field, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if it == nil {
+			return nil, nil
+		}
+		subIntervals = append(subIntervals, it)
+	}
+	return c.spi.combine(subIntervals)
+}
+
+func (c *conjunctionIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	subs := make([]IntervalMatchesIterator, 0, len(c.subSources))
+	subIntervals := make([]IntervalIterator, 0, len(c.subSources))
+	for _, source := range c.subSources {
+		mi, err := source.Matches(field, ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		if mi == nil {
+			return nil, nil
+		}
+		if c.isMinimizing {
+			mi = newCachingMatchesIterator(mi)
+		}
+		subs = append(subs, mi)
+		subIntervals = append(subIntervals, wrapMatches(mi, doc))
+	}
+
+	it, err := c.spi.combine(subIntervals)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := firstInterval(it, doc); err != nil || !ok {
+		return nil, err
+	}
+	return &conjunctionMatchesIterator{
+		iterator: it,
+		subs:     subs,
+		query:    NewIntervalQuery(field, c.spi),
+		cached:   true,
+	}, nil
+}
+
+func (c *conjunctionIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	v := visitor.GetSubVisitor(index.OccurMust, NewIntervalQuery(field, c.spi))
+	for _, source := range c.subSources {
+		if err := source.Visit(field, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// intervalResetter
+// Implemented by the iterators embedding conjunctionIntervalIterator, reset is called each time
+// the iterator moves to a new document.
+type intervalResetter interface {
+	reset(ctx context.Context) error
+}
+
+// conjunctionIntervalIterator
+// Shared implementation of the iterators over the intervals of several sub-iterators that must
+// all match in a document
+type conjunctionIntervalIterator struct {
+	approximation types.DocIdSetIterator
+	subIterators  []IntervalIterator
+	matchCost     float64
+	resetter      intervalResetter
+}
+
+func newConjunctionIntervalIterator(subIterators []IntervalIterator, resetter intervalResetter) (*conjunctionIntervalIterator, error) {
+	iterators := make([]types.DocIdSetIterator, 0, len(subIterators))
+	matchCost := 0.0
+	for _, it := range subIterators {
+		iterators = append(iterators, it)
+		matchCost += it.MatchCost()
+	}
+	approximation, err := search.IntersectIterators(iterators)
+	if err != nil {
+		return nil, err
+	}
+	return &conjunctionIntervalIterator{
+		approximation: approximation,
+		subIterators:  subIterators,
+		matchCost:     matchCost,
+		resetter:      resetter,
+	}, nil
+}
+
+func (c *conjunctionIntervalIterator) DocID() int {
+	return c.approximation.DocID()
+}
+
+func (c *conjunctionIntervalIterator) NextDoc(ctx context.Context) (int, error) {
+	doc, err := c.approximation.NextDoc(ctx)
+	if err != nil {
+		return doc, err
+	}
+	if err := c.resetter.reset(ctx); err != nil {
+		return 0, err
+	}
+	return doc, nil
+}
+
+func (c *conjunctionIntervalIterator) Advance(ctx context.Context, target int) (int, error) {
+	doc, err := c.approximation.Advance(ctx, target)
+	if err != nil {
+		return doc, err
+	}
+	if err := c.resetter.reset(ctx); err != nil {
+		return 0, err
+	}
+	return doc, nil
+}
+
+func (c *conjunctionIntervalIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, c, target)
+}
+
+func (c *conjunctionIntervalIterator) Cost() int64 {
+	return c.approximation.Cost()
+}
+
+func (c *conjunctionIntervalIterator) MatchCost() float64 {
+	return c.matchCost
+}
+
+// equalsTo Compares the sub-sources, the outer sources check the type of the other source
+func (c *conjunctionIntervalsSource) equalsTo(other *conjunctionIntervalsSource) bool {
+	return c.isMinimizing == other.isMinimizing && sourcesEqual(c.subSources, other.subSources)
+}
+
+func (c *conjunctionIntervalsSource) fieldsHashCode() int {
+	return 31*sourcesHashCode(c.subSources) + search.HashBool(c.isMinimizing)
+}
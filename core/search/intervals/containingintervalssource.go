@@ -0,0 +1,117 @@
+package intervals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ IntervalsSource = &containingIntervalsSource{}
+
+type containingIntervalsSource struct {
+	*conjunctionIntervalsSource
+
+	big, small IntervalsSource
+}
+
+func newContainingIntervalsSource(big, small IntervalsSource) *containingIntervalsSource {
+	source := &containingIntervalsSource{big: big, small: small}
+	source.conjunctionIntervalsSource = newConjunctionIntervalsSource([]IntervalsSource{big, small}, false, source)
+	return source
+}
+
+func (c *containingIntervalsSource) combine(iterators []IntervalIterator) (IntervalIterator, error) {
+	return newContainingIntervalIterator(iterators[0], iterators[1])
+}
+
+func (c *containingIntervalsSource) MinExtent() int {
+	return c.big.MinExtent()
+}
+
+func (c *containingIntervalsSource) String() string {
+	return fmt.Sprintf("CONTAINING(%s,%s)", c.big, c.small)
+}
+
+var _ IntervalIterator = &containingIntervalIterator{}
+
+// containingIntervalIterator
+// Returns the intervals of a that contain at least one interval of b
+type containingIntervalIterator struct {
+	*conjunctionIntervalIterator
+
+	a, b IntervalIterator
+	bpos bool
+}
+
+func newContainingIntervalIterator(a, b IntervalIterator) (*containingIntervalIterator, error) {
+	it := &containingIntervalIterator{a: a, b: b, bpos: true}
+	conjunction, err := newConjunctionIntervalIterator([]IntervalIterator{a, b}, it)
+	if err != nil {
+		return nil, err
+	}
+	it.conjunctionIntervalIterator = conjunction
+	return it, nil
+}
+
+func (c *containingIntervalIterator) Start() int {
+	if !c.bpos {
+		return NO_MORE_INTERVALS
+	}
+	return c.a.Start()
+}
+
+func (c *containingIntervalIterator) End() int {
+	if !c.bpos {
+		return NO_MORE_INTERVALS
+	}
+	return c.a.End()
+}
+
+func (c *containingIntervalIterator) Gaps() int {
+	return c.a.Gaps()
+}
+
+func (c *containingIntervalIterator) NextInterval() (int, error) {
+	if !c.bpos {
+		return NO_MORE_INTERVALS, nil
+	}
+	for {
+		start, err := c.a.NextInterval()
+		if err != nil {
+			return 0, err
+		}
+		if start == NO_MORE_INTERVALS {
+			return NO_MORE_INTERVALS, nil
+		}
+		for c.b.Start() < c.a.Start() && c.b.End() < c.a.End() {
+			bstart, err := c.b.NextInterval()
+			if err != nil {
+				return 0, err
+			}
+			if bstart == NO_MORE_INTERVALS {
+				c.bpos = false
+				return NO_MORE_INTERVALS, nil
+			}
+		}
+		if c.a.Start() <= c.b.Start() && c.a.End() >= c.b.End() {
+			return c.a.Start(), nil
+		}
+	}
+}
+
+func (c *containingIntervalIterator) reset(ctx context.Context) error {
+	c.bpos = true
+	return nil
+}
+
+func (c *containingIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*containingIntervalsSource)
+	return ok && c.big.Equals(o.big) && c.small.Equals(o.small)
+}
+
+func (c *containingIntervalsSource) HashCode() int {
+	h := search.ClassHash("ContainingIntervalsSource")
+	h = 31*h + c.big.HashCode()
+	return 31*h + c.small.HashCode()
+}
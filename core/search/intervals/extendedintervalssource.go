@@ -0,0 +1,156 @@
+package intervals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ IntervalsSource = &extendedIntervalsSource{}
+
+type extendedIntervalsSource struct {
+	source        IntervalsSource
+	before, after int
+}
+
+func newExtendedIntervalsSource(source IntervalsSource, before, after int) *extendedIntervalsSource {
+	return &extendedIntervalsSource{
+		source: source,
+		before: before,
+		after:  after,
+	}
+}
+
+func (e *extendedIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	in, err := e.source.Intervals(field, ctx)
+	if err != nil || in == nil {
+		return nil, err
+	}
+	return newExtendedIntervalIterator(in, e.before, e.after), nil
+}
+
+func (e *extendedIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	mi, err := e.source.Matches(field, ctx, doc)
+	if err != nil || mi == nil {
+		return nil, err
+	}
+	return asMatches(newExtendedIntervalIterator(wrapMatches(mi, doc), e.before, e.after), mi, doc)
+}
+
+func (e *extendedIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	return e.source.Visit(field, visitor)
+}
+
+func (e *extendedIntervalsSource) MinExtent() int {
+	minExtent := e.source.MinExtent() + e.before + e.after
+	if minExtent < 0 {
+		return NO_MORE_INTERVALS
+	}
+	return minExtent
+}
+
+func (e *extendedIntervalsSource) String() string {
+	return fmt.Sprintf("EXTEND(%s,%d,%d)", e.source, e.before, e.after)
+}
+
+var _ IntervalIterator = &extendedIntervalIterator{}
+
+// extendedIntervalIterator
+// Wraps an IntervalIterator and extends the bounds of its intervals
+// Useful for specifying gaps in an ordered iterator; if you want to match `a b [2 spaces] c`,
+// you can search for phrase(a, extended(b, 0, 2), c)
+// An interval with prefix bounds extended by n will skip over matches that appear in positions
+// lower than n
+type extendedIntervalIterator struct {
+	in            IntervalIterator
+	before, after int
+	positioned    bool
+}
+
+func newExtendedIntervalIterator(in IntervalIterator, before, after int) *extendedIntervalIterator {
+	return &extendedIntervalIterator{
+		in:     in,
+		before: before,
+		after:  after,
+	}
+}
+
+func (e *extendedIntervalIterator) DocID() int {
+	return e.in.DocID()
+}
+
+func (e *extendedIntervalIterator) NextDoc(ctx context.Context) (int, error) {
+	e.positioned = false
+	return e.in.NextDoc(ctx)
+}
+
+func (e *extendedIntervalIterator) Advance(ctx context.Context, target int) (int, error) {
+	e.positioned = false
+	return e.in.Advance(ctx, target)
+}
+
+func (e *extendedIntervalIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, e, target)
+}
+
+func (e *extendedIntervalIterator) Cost() int64 {
+	return e.in.Cost()
+}
+
+func (e *extendedIntervalIterator) Start() int {
+	if !e.positioned {
+		return -1
+	}
+	start := e.in.Start()
+	if start == NO_MORE_INTERVALS {
+		return NO_MORE_INTERVALS
+	}
+	return max(0, start-e.before)
+}
+
+func (e *extendedIntervalIterator) End() int {
+	if !e.positioned {
+		return -1
+	}
+	end := e.in.End()
+	if end == NO_MORE_INTERVALS {
+		return NO_MORE_INTERVALS
+	}
+	end += e.after
+	if end < 0 || end >= NO_MORE_INTERVALS {
+		// overflow
+		end = NO_MORE_INTERVALS - 1
+	}
+	return end
+}
+
+func (e *extendedIntervalIterator) Gaps() int {
+	return e.in.Gaps()
+}
+
+func (e *extendedIntervalIterator) NextInterval() (int, error) {
+	e.positioned = true
+	if _, err := e.in.NextInterval(); err != nil {
+		return 0, err
+	}
+	return e.Start(), nil
+}
+
+func (e *extendedIntervalIterator) MatchCost() float64 {
+	return e.in.MatchCost()
+}
+
+func (e *extendedIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*extendedIntervalsSource)
+	return ok && e.before == o.before && e.after == o.after && e.source.Equals(o.source)
+}
+
+func (e *extendedIntervalsSource) HashCode() int {
+	h := search.ClassHash("ExtendedIntervalsSource")
+	h = 31*h + e.source.HashCode()
+	h = 31*h + e.before
+	return 31*h + e.after
+}
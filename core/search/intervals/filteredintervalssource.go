@@ -0,0 +1,133 @@
+package intervals
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ IntervalsSource = &filteredIntervalsSource{}
+
+// filteredIntervalsSource
+// An IntervalsSource that filters the intervals from another IntervalsSource
+type filteredIntervalsSource struct {
+	name   string
+	in     IntervalsSource
+	accept func(it IntervalIterator) bool
+}
+
+// newFilteredIntervalsSource
+// name: the name of the filter
+// in: the source to filter
+// accept: returns true if the current interval of the iterator should be returned
+func newFilteredIntervalsSource(name string, in IntervalsSource, accept func(it IntervalIterator) bool) *filteredIntervalsSource {
+	return &filteredIntervalsSource{
+		name:   name,
+		in:     in,
+		accept: accept,
+	}
+}
+
+func (f *filteredIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	it, err := f.in.Intervals(field, ctx)
+	if err != nil || it == nil {
+		return nil, err
+	}
+	return newIntervalFilter(it, f.accept), nil
+}
+
+func (f *filteredIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	mi, err := f.in.Matches(field, ctx, doc)
+	if err != nil || mi == nil {
+		return nil, err
+	}
+	return asMatches(newIntervalFilter(wrapMatches(mi, doc), f.accept), mi, doc)
+}
+
+func (f *filteredIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	return f.in.Visit(field, visitor)
+}
+
+func (f *filteredIntervalsSource) MinExtent() int {
+	return f.in.MinExtent()
+}
+
+func (f *filteredIntervalsSource) String() string {
+	return f.name + "(" + f.in.String() + ")"
+}
+
+var _ IntervalIterator = &intervalFilter{}
+
+// intervalFilter
+// Wraps an IntervalIterator and passes through those intervals that match the accept function
+type intervalFilter struct {
+	in     IntervalIterator
+	accept func(it IntervalIterator) bool
+}
+
+func newIntervalFilter(in IntervalIterator, accept func(it IntervalIterator) bool) *intervalFilter {
+	return &intervalFilter{in: in, accept: accept}
+}
+
+func (f *intervalFilter) DocID() int {
+	return f.in.DocID()
+}
+
+func (f *intervalFilter) NextDoc(ctx context.Context) (int, error) {
+	return f.in.NextDoc(ctx)
+}
+
+func (f *intervalFilter) Advance(ctx context.Context, target int) (int, error) {
+	return f.in.Advance(ctx, target)
+}
+
+func (f *intervalFilter) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, f, target)
+}
+
+func (f *intervalFilter) Cost() int64 {
+	return f.in.Cost()
+}
+
+func (f *intervalFilter) Start() int {
+	return f.in.Start()
+}
+
+func (f *intervalFilter) End() int {
+	return f.in.End()
+}
+
+func (f *intervalFilter) Gaps() int {
+	return f.in.Gaps()
+}
+
+func (f *intervalFilter) NextInterval() (int, error) {
+	for {
+		next, err := f.in.NextInterval()
+		if err != nil {
+			return 0, err
+		}
+		if next == NO_MORE_INTERVALS || f.accept(f.in) {
+			return next, nil
+		}
+	}
+}
+
+func (f *intervalFilter) MatchCost() float64 {
+	return f.in.MatchCost()
+}
+
+// Equals Compares the names of the filters, which hold their parameters, the accept functions can
+// not be compared
+func (f *filteredIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*filteredIntervalsSource)
+	return ok && f.name == o.name && f.in.Equals(o.in)
+}
+
+func (f *filteredIntervalsSource) HashCode() int {
+	h := search.ClassHash("FilteredIntervalsSource")
+	h = 31*h + search.HashString(f.name)
+	return 31*h + f.in.HashCode()
+}
@@ -0,0 +1,63 @@
+package intervals
+
+import (
+	"math"
+
+	"github.com/geange/lucene-go/core/types"
+)
+
+// NO_MORE_INTERVALS
+// When returned from NextInterval(), indicates that there are no more matching intervals on the
+// current document
+const NO_MORE_INTERVALS = math.MaxInt32
+
+// IntervalIterator
+// A DocIdSetIterator that also allows iteration over matching intervals in a document.
+//
+// Once the iterator is positioned on a document by calling Advance or NextDoc, intervals may be
+// retrieved by calling NextInterval until NO_MORE_INTERVALS is returned.
+//
+// The limits of the current interval are returned by Start and End. When the iterator has been
+// moved to a new document, but before NextInterval has been called, both these methods return -1.
+//
+// Note that it is possible for a document to return NO_MORE_INTERVALS on the first call to
+// NextInterval.
+type IntervalIterator interface {
+	types.DocIdSetIterator
+
+	// Start
+	// The start of the current interval
+	// Returns -1 if NextInterval has not yet been called and NO_MORE_INTERVALS once the iterator
+	// is exhausted.
+	Start() int
+
+	// End
+	// The end of the current interval
+	// Returns -1 if NextInterval has not yet been called and NO_MORE_INTERVALS once the iterator
+	// is exhausted.
+	End() int
+
+	// Gaps
+	// Return the number of gaps within the current interval
+	// Note that this returns the number of gaps between the immediate sub-intervals of this
+	// interval, and does not include the gaps inside those sub-intervals.
+	// Should not be called before NextInterval, or after it has returned NO_MORE_INTERVALS
+	Gaps() int
+
+	// NextInterval
+	// Advance the iterator to the next interval
+	// Returns the start of the next interval, or NO_MORE_INTERVALS if there are no more
+	// intervals on the current document
+	NextInterval() (int, error)
+
+	// MatchCost
+	// An indication of the average cost of iterating over all intervals in a document
+	// See TwoPhaseIterator.MatchCost
+	MatchCost() float64
+}
+
+// width
+// The width of the current interval
+func width(it IntervalIterator) int {
+	return it.End() - it.Start() + 1
+}
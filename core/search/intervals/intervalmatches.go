@@ -0,0 +1,511 @@
+package intervals
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// asMatches
+// Exposes the intervals of iterator over a single document as an IntervalMatchesIterator,
+// the offsets and sub-matches of each interval are read from source.
+// Returns nil if iterator has no intervals in the document.
+func asMatches(iterator IntervalIterator, source IntervalMatchesIterator, doc int) (IntervalMatchesIterator, error) {
+	if source == nil {
+		return nil, nil
+	}
+	if ok, err := firstInterval(iterator, doc); err != nil || !ok {
+		return nil, err
+	}
+	return &iteratorMatchesIterator{
+		iterator: iterator,
+		source:   source,
+		cached:   true,
+	}, nil
+}
+
+// firstInterval
+// Positions iterator on the first interval of doc, returns false if there is none
+func firstInterval(iterator IntervalIterator, doc int) (bool, error) {
+	target, err := iterator.Advance(nil, doc)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	if target != doc {
+		return false, nil
+	}
+	start, err := iterator.NextInterval()
+	if err != nil {
+		return false, err
+	}
+	return start != NO_MORE_INTERVALS, nil
+}
+
+var _ IntervalMatchesIterator = &iteratorMatchesIterator{}
+
+type iteratorMatchesIterator struct {
+	iterator IntervalIterator
+	source   IntervalMatchesIterator
+	cached   bool
+}
+
+func (i *iteratorMatchesIterator) Next() (bool, error) {
+	if i.cached {
+		i.cached = false
+		return true, nil
+	}
+	start, err := i.iterator.NextInterval()
+	if err != nil {
+		return false, err
+	}
+	return start != NO_MORE_INTERVALS, nil
+}
+
+func (i *iteratorMatchesIterator) StartPosition() int {
+	return i.iterator.Start()
+}
+
+func (i *iteratorMatchesIterator) EndPosition() int {
+	return i.iterator.End()
+}
+
+func (i *iteratorMatchesIterator) StartOffset() (int, error) {
+	return i.source.StartOffset()
+}
+
+func (i *iteratorMatchesIterator) EndOffset() (int, error) {
+	return i.source.EndOffset()
+}
+
+func (i *iteratorMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return i.source.GetSubMatches()
+}
+
+func (i *iteratorMatchesIterator) GetQuery() index.Query {
+	return i.source.GetQuery()
+}
+
+func (i *iteratorMatchesIterator) Gaps() int {
+	return i.iterator.Gaps()
+}
+
+func (i *iteratorMatchesIterator) Width() int {
+	return width(i.iterator)
+}
+
+const (
+	wrapStateUnpositioned = iota
+	wrapStateOnDoc
+	wrapStateIterating
+	wrapStateNoMoreIntervals
+	wrapStateExhausted
+)
+
+// wrapMatches
+// Exposes an IntervalMatchesIterator over a single document as an IntervalIterator, so that it
+// can be combined with other iterators.
+func wrapMatches(mi IntervalMatchesIterator, doc int) IntervalIterator {
+	return &matchesIntervalIterator{
+		mi:    mi,
+		doc:   doc,
+		state: wrapStateUnpositioned,
+	}
+}
+
+var _ IntervalIterator = &matchesIntervalIterator{}
+
+type matchesIntervalIterator struct {
+	mi    IntervalMatchesIterator
+	doc   int
+	state int
+}
+
+func (m *matchesIntervalIterator) DocID() int {
+	switch m.state {
+	case wrapStateUnpositioned:
+		return -1
+	case wrapStateExhausted:
+		return types.NO_MORE_DOCS
+	default:
+		return m.doc
+	}
+}
+
+func (m *matchesIntervalIterator) NextDoc(ctx context.Context) (int, error) {
+	if m.state == wrapStateUnpositioned {
+		m.state = wrapStateOnDoc
+		return m.doc, nil
+	}
+	m.state = wrapStateExhausted
+	return types.NO_MORE_DOCS, io.EOF
+}
+
+func (m *matchesIntervalIterator) Advance(ctx context.Context, target int) (int, error) {
+	if m.state == wrapStateUnpositioned && target <= m.doc {
+		m.state = wrapStateOnDoc
+		return m.doc, nil
+	}
+	m.state = wrapStateExhausted
+	return types.NO_MORE_DOCS, io.EOF
+}
+
+func (m *matchesIntervalIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, m, target)
+}
+
+func (m *matchesIntervalIterator) Cost() int64 {
+	return 1
+}
+
+func (m *matchesIntervalIterator) Start() int {
+	switch m.state {
+	case wrapStateIterating:
+		return m.mi.StartPosition()
+	case wrapStateNoMoreIntervals, wrapStateExhausted:
+		return NO_MORE_INTERVALS
+	default:
+		return -1
+	}
+}
+
+func (m *matchesIntervalIterator) End() int {
+	switch m.state {
+	case wrapStateIterating:
+		return m.mi.EndPosition()
+	case wrapStateNoMoreIntervals, wrapStateExhausted:
+		return NO_MORE_INTERVALS
+	default:
+		return -1
+	}
+}
+
+func (m *matchesIntervalIterator) Gaps() int {
+	return m.mi.Gaps()
+}
+
+func (m *matchesIntervalIterator) NextInterval() (int, error) {
+	if m.state != wrapStateOnDoc && m.state != wrapStateIterating {
+		return NO_MORE_INTERVALS, nil
+	}
+	ok, err := m.mi.Next()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		m.state = wrapStateNoMoreIntervals
+		return NO_MORE_INTERVALS, nil
+	}
+	m.state = wrapStateIterating
+	return m.mi.StartPosition(), nil
+}
+
+func (m *matchesIntervalIterator) MatchCost() float64 {
+	return 1
+}
+
+// A single leaf match, as reported by a MatchesIterator
+type cachedMatch struct {
+	startPosition int
+	endPosition   int
+	startOffset   int
+	endOffset     int
+	query         index.Query
+}
+
+func newCachedMatch(mi index.MatchesIterator) (cachedMatch, error) {
+	startOffset, err := mi.StartOffset()
+	if err != nil {
+		return cachedMatch{}, err
+	}
+	endOffset, err := mi.EndOffset()
+	if err != nil {
+		return cachedMatch{}, err
+	}
+	return cachedMatch{
+		startPosition: mi.StartPosition(),
+		endPosition:   mi.EndPosition(),
+		startOffset:   startOffset,
+		endOffset:     endOffset,
+		query:         mi.GetQuery(),
+	}, nil
+}
+
+// appendLeafMatches
+// Appends the leaf matches of the current position of mi: its sub-matches if it has any, or
+// the current position itself.
+func appendLeafMatches(matches []cachedMatch, mi index.MatchesIterator) ([]cachedMatch, error) {
+	sub, err := mi.GetSubMatches()
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		match, err := newCachedMatch(mi)
+		if err != nil {
+			return nil, err
+		}
+		return append(matches, match), nil
+	}
+	for {
+		ok, err := sub.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return matches, nil
+		}
+		match, err := newCachedMatch(sub)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+}
+
+var _ IntervalMatchesIterator = &cachingMatchesIterator{}
+
+// cachingMatchesIterator
+// Minimizing iterators may advance their sub-iterators one step beyond the interval they report.
+// This MatchesIterator remembers the leaves of the previous position of the iterator it wraps, so
+// that the offsets and sub-matches of the reported interval can still be retrieved.
+type cachingMatchesIterator struct {
+	in         IntervalMatchesIterator
+	positioned bool
+	cache      []cachedMatch
+}
+
+func newCachingMatchesIterator(in IntervalMatchesIterator) *cachingMatchesIterator {
+	return &cachingMatchesIterator{in: in}
+}
+
+func (c *cachingMatchesIterator) doCache() error {
+	cache, err := appendLeafMatches(c.cache[:0], c.in)
+	if err != nil {
+		return err
+	}
+	c.cache = cache
+	return nil
+}
+
+func (c *cachingMatchesIterator) Next() (bool, error) {
+	if !c.positioned {
+		c.positioned = true
+	} else if err := c.doCache(); err != nil {
+		return false, err
+	}
+	return c.in.Next()
+}
+
+// startOffset
+// Returns the start offset of the leaves of this iterator inside an interval ending at endPos
+func (c *cachingMatchesIterator) startOffset(endPos int) (int, error) {
+	if c.in.EndPosition() <= endPos || len(c.cache) == 0 {
+		return c.in.StartOffset()
+	}
+	return c.cache[0].startOffset, nil
+}
+
+// endOffset
+// Returns the end offset of the leaves of this iterator inside an interval ending at endPos
+func (c *cachingMatchesIterator) endOffset(endPos int) (int, error) {
+	if c.in.EndPosition() <= endPos || len(c.cache) == 0 {
+		return c.in.EndOffset()
+	}
+	return c.cache[len(c.cache)-1].endOffset, nil
+}
+
+// leafMatches
+// Returns the leaves of this iterator inside an interval ending at endPos
+func (c *cachingMatchesIterator) leafMatches(endPos int) ([]cachedMatch, error) {
+	if c.in.EndPosition() <= endPos {
+		if err := c.doCache(); err != nil {
+			return nil, err
+		}
+	}
+	return slices.Clone(c.cache), nil
+}
+
+func (c *cachingMatchesIterator) StartPosition() int {
+	return c.in.StartPosition()
+}
+
+func (c *cachingMatchesIterator) EndPosition() int {
+	return c.in.EndPosition()
+}
+
+func (c *cachingMatchesIterator) StartOffset() (int, error) {
+	return c.in.StartOffset()
+}
+
+func (c *cachingMatchesIterator) EndOffset() (int, error) {
+	return c.in.EndOffset()
+}
+
+func (c *cachingMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return c.in.GetSubMatches()
+}
+
+func (c *cachingMatchesIterator) GetQuery() index.Query {
+	return c.in.GetQuery()
+}
+
+func (c *cachingMatchesIterator) Gaps() int {
+	return c.in.Gaps()
+}
+
+func (c *cachingMatchesIterator) Width() int {
+	return c.in.Width()
+}
+
+var _ IntervalMatchesIterator = &conjunctionMatchesIterator{}
+
+// conjunctionMatchesIterator
+// Reports the intervals of a combined iterator, offsets and sub-matches are collected from the
+// IntervalMatchesIterators of its sources.
+type conjunctionMatchesIterator struct {
+	iterator IntervalIterator
+	subs     []IntervalMatchesIterator
+	query    index.Query
+	cached   bool
+}
+
+func (c *conjunctionMatchesIterator) Next() (bool, error) {
+	if c.cached {
+		c.cached = false
+		return true, nil
+	}
+	start, err := c.iterator.NextInterval()
+	if err != nil {
+		return false, err
+	}
+	return start != NO_MORE_INTERVALS, nil
+}
+
+func (c *conjunctionMatchesIterator) StartPosition() int {
+	return c.iterator.Start()
+}
+
+func (c *conjunctionMatchesIterator) EndPosition() int {
+	return c.iterator.End()
+}
+
+func (c *conjunctionMatchesIterator) StartOffset() (int, error) {
+	start := -1
+	endPos := c.EndPosition()
+	for _, sub := range c.subs {
+		var offset int
+		var err error
+		if caching, ok := sub.(*cachingMatchesIterator); ok {
+			offset, err = caching.startOffset(endPos)
+		} else {
+			offset, err = sub.StartOffset()
+		}
+		if err != nil {
+			return 0, err
+		}
+		if start == -1 || offset < start {
+			start = offset
+		}
+	}
+	return start, nil
+}
+
+func (c *conjunctionMatchesIterator) EndOffset() (int, error) {
+	end := -1
+	endPos := c.EndPosition()
+	for _, sub := range c.subs {
+		var offset int
+		var err error
+		if caching, ok := sub.(*cachingMatchesIterator); ok {
+			offset, err = caching.endOffset(endPos)
+		} else {
+			offset, err = sub.EndOffset()
+		}
+		if err != nil {
+			return 0, err
+		}
+		end = max(end, offset)
+	}
+	return end, nil
+}
+
+func (c *conjunctionMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	matches := make([]cachedMatch, 0)
+	endPos := c.EndPosition()
+	for _, sub := range c.subs {
+		if caching, ok := sub.(*cachingMatchesIterator); ok {
+			leaves, err := caching.leafMatches(endPos)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, leaves...)
+			continue
+		}
+
+		var err error
+		matches, err = appendLeafMatches(matches, sub)
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b cachedMatch) int {
+		if a.startPosition != b.startPosition {
+			return a.startPosition - b.startPosition
+		}
+		return a.startOffset - b.startOffset
+	})
+	return &leafMatchesIterator{matches: matches, upto: -1}, nil
+}
+
+func (c *conjunctionMatchesIterator) GetQuery() index.Query {
+	return c.query
+}
+
+func (c *conjunctionMatchesIterator) Gaps() int {
+	return c.iterator.Gaps()
+}
+
+func (c *conjunctionMatchesIterator) Width() int {
+	return width(c.iterator)
+}
+
+var _ index.MatchesIterator = &leafMatchesIterator{}
+
+// leafMatchesIterator
+// Iterates over a fixed list of leaf matches
+type leafMatchesIterator struct {
+	matches []cachedMatch
+	upto    int
+}
+
+func (l *leafMatchesIterator) Next() (bool, error) {
+	l.upto++
+	return l.upto < len(l.matches), nil
+}
+
+func (l *leafMatchesIterator) StartPosition() int {
+	return l.matches[l.upto].startPosition
+}
+
+func (l *leafMatchesIterator) EndPosition() int {
+	return l.matches[l.upto].endPosition
+}
+
+func (l *leafMatchesIterator) StartOffset() (int, error) {
+	return l.matches[l.upto].startOffset, nil
+}
+
+func (l *leafMatchesIterator) EndOffset() (int, error) {
+	return l.matches[l.upto].endOffset, nil
+}
+
+func (l *leafMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return nil, nil
+}
+
+func (l *leafMatchesIterator) GetQuery() index.Query {
+	return l.matches[l.upto].query
+}
@@ -0,0 +1,265 @@
+package intervals
+
+import (
+	"errors"
+	"io"
+
+	"github.com/geange/gods-generic/sets/treeset"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+var _ index.Query = &IntervalQuery{}
+
+// IntervalQuery
+// A query that retrieves documents containing intervals returned from an IntervalsSource
+//
+// Static constructor functions for interval sources can be found in this package, and they
+// can be combined to build complex proximity queries. For example, to find documents where
+// "lucene" appears within 5 positions of "search" in any order:
+//
+//	source := intervals.MaxWidth(intervals.Unordered(intervals.Term("lucene"), intervals.Term("search")), 5)
+//	query := intervals.NewIntervalQuery("body", source)
+//
+// Scores are computed from the sloppy frequency of the intervals of a document with an
+// IntervalScoreFunction, and are bounded by the boost of the query.
+type IntervalQuery struct {
+	field           string
+	intervalsSource IntervalsSource
+	scoreFunction   IntervalScoreFunction
+}
+
+// NewIntervalQuery
+// Create a new IntervalQuery, scored with a saturation function whose pivot is 1
+// field: the field to query
+// intervalsSource: an IntervalsSource to retrieve intervals from
+func NewIntervalQuery(field string, intervalsSource IntervalsSource) *IntervalQuery {
+	return &IntervalQuery{
+		field:           field,
+		intervalsSource: intervalsSource,
+		scoreFunction:   &saturationFunction{pivot: 1},
+	}
+}
+
+// NewIntervalQueryV1
+// Create a new IntervalQuery with a scoring pivot, see NewSaturationFunction
+// field: the field to query
+// intervalsSource: an IntervalsSource to retrieve intervals from
+// pivot: the sloppy frequency value at which the score will be half the maximum score
+func NewIntervalQueryV1(field string, intervalsSource IntervalsSource, pivot float64) (*IntervalQuery, error) {
+	scoreFunction, err := NewSaturationFunction(pivot)
+	if err != nil {
+		return nil, err
+	}
+	return NewIntervalQueryV3(field, intervalsSource, scoreFunction), nil
+}
+
+// NewIntervalQueryV2
+// Create a new IntervalQuery with a scoring pivot and exponent, see NewSigmoidFunction
+// field: the field to query
+// intervalsSource: an IntervalsSource to retrieve intervals from
+// pivot: the sloppy frequency value at which the score will be half the maximum score
+// exp: exponent, higher values make the function grow slower before 'pivot' and faster after 'pivot'
+func NewIntervalQueryV2(field string, intervalsSource IntervalsSource, pivot, exp float64) (*IntervalQuery, error) {
+	scoreFunction, err := NewSigmoidFunction(pivot, exp)
+	if err != nil {
+		return nil, err
+	}
+	return NewIntervalQueryV3(field, intervalsSource, scoreFunction), nil
+}
+
+// NewIntervalQueryV3
+// Create a new IntervalQuery scored with a custom IntervalScoreFunction
+func NewIntervalQueryV3(field string, intervalsSource IntervalsSource, scoreFunction IntervalScoreFunction) *IntervalQuery {
+	return &IntervalQuery{
+		field:           field,
+		intervalsSource: intervalsSource,
+		scoreFunction:   scoreFunction,
+	}
+}
+
+// GetField
+// The field to query
+func (q *IntervalQuery) GetField() string {
+	return q.field
+}
+
+// GetIntervalsSource
+// The IntervalsSource intervals are retrieved from
+func (q *IntervalQuery) GetIntervalsSource() IntervalsSource {
+	return q.intervalsSource
+}
+
+func (q *IntervalQuery) String(field string) string {
+	return q.intervalsSource.String()
+}
+
+func (q *IntervalQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return newIntervalWeight(q, scoreMode, boost), nil
+}
+
+func (q *IntervalQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return q, nil
+}
+
+func (q *IntervalQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(q.field) {
+		return q.intervalsSource.Visit(q.field, visitor)
+	}
+	return nil
+}
+
+var _ index.Weight = &intervalWeight{}
+
+type intervalWeight struct {
+	*search.BaseWeight
+
+	query     *IntervalQuery
+	scoreMode index.ScoreMode
+	boost     float64
+}
+
+func newIntervalWeight(query *IntervalQuery, scoreMode index.ScoreMode, boost float64) *intervalWeight {
+	weight := &intervalWeight{
+		query:     query,
+		scoreMode: scoreMode,
+		boost:     boost,
+	}
+	weight.BaseWeight = search.NewBaseWeight(query, weight)
+	return weight
+}
+
+func (w *intervalWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return w.query.intervalsSource.Visit(w.query.field, &termCollector{terms: terms})
+}
+
+func (w *intervalWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return true
+}
+
+func (w *intervalWeight) Explain(ctx index.LeafReaderContext, doc int) (types.Explanation, error) {
+	scorer, err := w.intervalScorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		newDoc, err := scorer.Iterator().Advance(nil, doc)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if newDoc == doc {
+			freq, err := scorer.Freq()
+			if err != nil {
+				return nil, err
+			}
+			return w.query.scoreFunction.Explain(w.query.intervalsSource.String(), w.boost, freq), nil
+		}
+	}
+	return types.ExplanationNoMatch("no matching intervals"), nil
+}
+
+func (w *intervalWeight) Matches(ctx index.LeafReaderContext, doc int) (index.Matches, error) {
+	return search.MatchesForField(w.query.field, &intervalMatches{
+		query:   w.query,
+		context: ctx,
+		doc:     doc,
+	}), nil
+}
+
+func (w *intervalWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	scorer, err := w.intervalScorer(ctx)
+	if err != nil || scorer == nil {
+		return nil, err
+	}
+	return scorer, nil
+}
+
+func (w *intervalWeight) intervalScorer(ctx index.LeafReaderContext) (*IntervalScorer, error) {
+	source := w.query.intervalsSource
+	intervals, err := source.Intervals(w.query.field, ctx)
+	if err != nil || intervals == nil {
+		return nil, err
+	}
+	return newIntervalScorer(w, intervals, source.MinExtent(), w.boost, w.query.scoreFunction), nil
+}
+
+var _ search.IOSupplier[index.MatchesIterator] = &intervalMatches{}
+
+type intervalMatches struct {
+	query   *IntervalQuery
+	context index.LeafReaderContext
+	doc     int
+}
+
+func (m *intervalMatches) Get() (index.MatchesIterator, error) {
+	mi, err := m.query.intervalsSource.Matches(m.query.field, m.context, m.doc)
+	if err != nil || mi == nil {
+		return nil, err
+	}
+	return &queryMatchesIterator{IntervalMatchesIterator: mi, query: m.query}, nil
+}
+
+// queryMatchesIterator
+// Reports the top-level matches of an IntervalsSource as matches of the IntervalQuery
+type queryMatchesIterator struct {
+	IntervalMatchesIterator
+
+	query index.Query
+}
+
+func (q *queryMatchesIterator) GetQuery() index.Query {
+	return q.query
+}
+
+var _ index.QueryVisitor = &termCollector{}
+
+// termCollector
+// A QueryVisitor that collects the terms of the sources it visits, excluding prohibited ones
+type termCollector struct {
+	terms *treeset.Set[index.Term]
+}
+
+func (t *termCollector) ConsumeTerms(query index.Query, terms ...index.Term) {
+	if t.terms == nil {
+		return
+	}
+	for _, term := range terms {
+		t.terms.Add(term)
+	}
+}
+
+func (t *termCollector) ConsumeTermsMatching(query index.Query, field string, automaton func() *automaton.ByteRunAutomaton) {
+}
+
+func (t *termCollector) VisitLeaf(query index.Query) error {
+	return nil
+}
+
+func (t *termCollector) AcceptField(field string) bool {
+	return true
+}
+
+func (t *termCollector) GetSubVisitor(occur index.Occur, parent index.Query) index.QueryVisitor {
+	if occur == index.OccurMustNot {
+		return &termCollector{}
+	}
+	return t
+}
+
+func (q *IntervalQuery) Equals(other index.Query) bool {
+	o, ok := other.(*IntervalQuery)
+	if !ok {
+		return false
+	}
+	return q.field == o.field && q.intervalsSource.Equals(o.intervalsSource) &&
+		q.scoreFunction.Equals(o.scoreFunction)
+}
+
+func (q *IntervalQuery) HashCode() int {
+	h := search.ClassHash("IntervalQuery")
+	h = 31*h + search.HashString(q.field)
+	h = 31*h + q.intervalsSource.HashCode()
+	return 31*h + q.scoreFunction.HashCode()
+}
@@ -0,0 +1,287 @@
+package intervals_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/intervals"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIntervalsSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		searchtest.OffsetTextDocs(t, "body",
+			"a b c a b c",
+			"a x b x x c",
+			"c b a"),
+		searchtest.OffsetTextDocs(t, "body",
+			"a a b b",
+			"b",
+			"x y z"))
+}
+
+// sourceIntervals Returns the start and end positions of the intervals of source by document
+func sourceIntervals(t *testing.T, searcher *search.IndexSearcher, source intervals.IntervalsSource) map[int][][2]int {
+	leaves, err := searcher.GetIndexReader().Leaves()
+	assert.Nil(t, err)
+
+	positions := make(map[int][][2]int)
+	for _, leaf := range leaves {
+		it, err := source.Intervals("body", leaf)
+		assert.Nil(t, err)
+		if it == nil {
+			continue
+		}
+		for {
+			doc, err := it.NextDoc(nil)
+			if errors.Is(err, io.EOF) || doc == types.NO_MORE_DOCS {
+				break
+			}
+			assert.Nil(t, err)
+			for {
+				start, err := it.NextInterval()
+				assert.Nil(t, err)
+				if start == intervals.NO_MORE_INTERVALS {
+					break
+				}
+				assert.Equal(t, start, it.Start())
+				positions[leaf.DocBase()+doc] = append(positions[leaf.DocBase()+doc], [2]int{it.Start(), it.End()})
+			}
+		}
+	}
+	return positions
+}
+
+// searchScores Returns the scores of the documents matching query
+func searchScores(t *testing.T, searcher *search.IndexSearcher, query *intervals.IntervalQuery) map[int]float64 {
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 100)
+	assert.Nil(t, err)
+	scores := make(map[int]float64, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		scores[scoreDoc.GetDoc()] = scoreDoc.GetScore()
+	}
+	return scores
+}
+
+// assertIntervals Checks the intervals of source, and that an IntervalQuery finds the documents that have intervals
+func assertIntervals(t *testing.T, searcher *search.IndexSearcher, source intervals.IntervalsSource, expected map[int][][2]int) {
+	assert.Equal(t, expected, sourceIntervals(t, searcher, source), source.String())
+	docs := make([]int, 0, len(expected))
+	for doc := range expected {
+		docs = append(docs, doc)
+	}
+	scores := searchScores(t, searcher, intervals.NewIntervalQuery("body", source))
+	matched := make([]int, 0, len(scores))
+	for doc := range scores {
+		matched = append(matched, doc)
+	}
+	assert.ElementsMatch(t, docs, matched, source.String())
+}
+
+func TestIntervals_Term(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+
+	assertIntervals(t, searcher, intervals.Term("a"), map[int][][2]int{
+		0: {{0, 0}, {3, 3}}, 1: {{0, 0}}, 2: {{2, 2}}, 3: {{0, 0}, {1, 1}},
+	})
+	assertIntervals(t, searcher, intervals.Term("missing"), map[int][][2]int{})
+	assertIntervals(t, searcher, intervals.NoIntervals("nothing"), map[int][][2]int{})
+	assert.Equal(t, "NOMATCH(nothing)", intervals.NoIntervals("nothing").String())
+}
+
+func TestIntervals_Phrase(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+
+	source := intervals.Phrase("a", "b")
+	assert.Equal(t, "BLOCK(a,b)", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 1}, {3, 4}}, 3: {{1, 2}}})
+	assertIntervals(t, searcher, intervals.Phrase("a", "b", "c"), map[int][][2]int{0: {{0, 2}, {3, 5}}})
+	assertIntervals(t, searcher, intervals.Phrase("b", "a"), map[int][][2]int{2: {{1, 2}}})
+	assertIntervals(t, searcher, intervals.Phrase("c", "a", "b", "c"), map[int][][2]int{0: {{2, 5}}})
+
+	// an extended source takes up more positions in a phrase
+	source = intervals.PhraseSources(intervals.Term("a"), intervals.Extend(intervals.Term("b"), 0, 1), intervals.Term("a"))
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 3}}})
+}
+
+func TestIntervals_Ordered(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+
+	source := intervals.Ordered(intervals.Term("a"), intervals.Term("b"))
+	assert.Equal(t, "ORDERED(a,b)", source.String())
+	// only minimal intervals, "a a b b" has no interval from the first a
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 1}, {3, 4}}, 1: {{0, 2}}, 3: {{1, 2}}})
+
+	assertIntervals(t, searcher, intervals.Ordered(intervals.Term("a"), intervals.Term("b"), intervals.Term("c")),
+		map[int][][2]int{0: {{0, 2}, {3, 5}}, 1: {{0, 5}}})
+	assertIntervals(t, searcher, intervals.Ordered(intervals.Term("c"), intervals.Term("a")),
+		map[int][][2]int{0: {{2, 3}}, 2: {{0, 2}}})
+	// the intervals of the sources may not overlap
+	assertIntervals(t, searcher, intervals.Ordered(intervals.Term("b"), intervals.Term("b")), map[int][][2]int{
+		0: {{1, 4}}, 3: {{2, 3}},
+	})
+	assertIntervals(t, searcher, intervals.Ordered(intervals.Term("a"), intervals.Term("y")), map[int][][2]int{})
+}
+
+func TestIntervals_Unordered(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+
+	source := intervals.Unordered(intervals.Term("a"), intervals.Term("b"))
+	assert.Equal(t, "UNORDERED(a,b)", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{
+		0: {{0, 1}, {1, 3}, {3, 4}}, 1: {{0, 2}}, 2: {{1, 2}}, 3: {{1, 2}},
+	})
+	assertIntervals(t, searcher, intervals.Unordered(intervals.Term("c"), intervals.Term("a"), intervals.Term("b")),
+		map[int][][2]int{0: {{0, 2}, {1, 3}, {2, 4}, {3, 5}}, 1: {{0, 5}}, 2: {{0, 2}}})
+}
+
+func TestIntervals_Filters(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+	ab := intervals.Ordered(intervals.Term("a"), intervals.Term("b"))
+
+	source := intervals.MaxGaps(ab, 0)
+	assert.Equal(t, "MAXGAPS/0(ORDERED(a,b))", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 1}, {3, 4}}, 3: {{1, 2}}})
+	assertIntervals(t, searcher, intervals.MaxGaps(ab, 1), map[int][][2]int{0: {{0, 1}, {3, 4}}, 1: {{0, 2}}, 3: {{1, 2}}})
+
+	source = intervals.MaxWidth(intervals.Unordered(intervals.Term("a"), intervals.Term("c")), 3)
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 2}, {2, 3}, {3, 5}}, 2: {{0, 2}}})
+	assertIntervals(t, searcher, intervals.MaxWidth(ab, 1), map[int][][2]int{})
+}
+
+func TestIntervals_Containing(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+	ac := intervals.Ordered(intervals.Term("a"), intervals.Term("c"))
+
+	source := intervals.Containing(ac, intervals.Term("b"))
+	assert.Equal(t, "CONTAINING(ORDERED(a,c),b)", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 2}, {3, 5}}, 1: {{0, 5}}})
+	assertIntervals(t, searcher, intervals.Containing(ac, intervals.Term("x")), map[int][][2]int{1: {{0, 5}}})
+
+	source = intervals.NotContaining(ac, intervals.Term("x"))
+	assert.Equal(t, "NOT_CONTAINING(ORDERED(a,c),x)", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{0, 2}, {3, 5}}})
+	assertIntervals(t, searcher, intervals.NotContaining(intervals.Term("a"), intervals.Term("b")), map[int][][2]int{
+		0: {{0, 0}, {3, 3}}, 1: {{0, 0}}, 2: {{2, 2}}, 3: {{0, 0}, {1, 1}},
+	})
+
+	source = intervals.Overlapping(intervals.Term("b"), ac)
+	assert.Equal(t, "OVERLAPPING(b,ORDERED(a,c))", source.String())
+	assertIntervals(t, searcher, source, map[int][][2]int{0: {{1, 1}, {4, 4}}, 1: {{2, 2}}})
+	assertIntervals(t, searcher, intervals.Overlapping(ac, intervals.Term("x")), map[int][][2]int{1: {{0, 5}}})
+}
+
+func TestIntervals_Extend(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+
+	source := intervals.Extend(intervals.Term("b"), 1, 1)
+	assert.Equal(t, "EXTEND(b,1,1)", source.String())
+	// intervals are clipped to position 0
+	assertIntervals(t, searcher, source, map[int][][2]int{
+		0: {{0, 2}, {3, 5}}, 1: {{1, 3}}, 2: {{0, 2}}, 3: {{1, 3}, {2, 4}}, 4: {{0, 1}},
+	})
+}
+
+func TestIntervalQuery_Scoring(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+	ab := intervals.Ordered(intervals.Term("a"), intervals.Term("b"))
+
+	// each interval adds 1 / (width - minExtent + 1) to the sloppy frequency:
+	// doc 0 has two intervals without gaps, doc 1 an interval with a gap and doc 3 one without
+	query := intervals.NewIntervalQuery("body", ab)
+	assert.Equal(t, "ORDERED(a,b)", query.String("body"))
+	scores := searchScores(t, searcher, query)
+	assert.Len(t, scores, 3)
+	assert.InDelta(t, 2.0/3.0, scores[0], 1e-6)
+	assert.InDelta(t, 1.0/3.0, scores[1], 1e-6)
+	assert.InDelta(t, 0.5, scores[3], 1e-6)
+
+	query, err := intervals.NewIntervalQueryV1("body", ab, 2)
+	assert.Nil(t, err)
+	scores = searchScores(t, searcher, query)
+	assert.InDelta(t, 0.5, scores[0], 1e-6)
+	assert.InDelta(t, 0.2, scores[1], 1e-6)
+
+	query, err = intervals.NewIntervalQueryV2("body", ab, 1, 2)
+	assert.Nil(t, err)
+	scores = searchScores(t, searcher, query)
+	assert.InDelta(t, 0.8, scores[0], 1e-6)
+	assert.InDelta(t, 0.2, scores[1], 1e-6)
+	assert.InDelta(t, 0.5, scores[3], 1e-6)
+
+	boosted, err := search.NewBoostQuery(intervals.NewIntervalQuery("body", ab), 2)
+	assert.Nil(t, err)
+	topDocs, err := searcher.SearchTopN(context.Background(), boosted, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, topDocs.GetScoreDocs()[0].GetDoc())
+	assert.InDelta(t, 4.0/3.0, topDocs.GetScoreDocs()[0].GetScore(), 1e-6)
+
+	explanation, err := searcher.Explain(intervals.NewIntervalQuery("body", ab), 1)
+	assert.Nil(t, err)
+	assert.True(t, explanation.IsMatch())
+	assert.InDelta(t, 1.0/3.0, explanation.GetValue(), 1e-6)
+	explanation, err = searcher.Explain(intervals.NewIntervalQuery("body", ab), 2)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+
+	_, err = intervals.NewIntervalQueryV1("body", ab, 0)
+	assert.NotNil(t, err)
+	_, err = intervals.NewIntervalQueryV2("body", ab, 1, -1)
+	assert.NotNil(t, err)
+}
+
+func TestIntervalQuery_Matches(t *testing.T) {
+	searcher := newTestIntervalsSearcher(t)
+	leaves, err := searcher.GetIndexReader().Leaves()
+	assert.Nil(t, err)
+
+	type match struct {
+		start, end             int
+		startOffset, endOffset int
+	}
+	queryMatches := func(source intervals.IntervalsSource, doc int) []match {
+		weight, err := searcher.CreateWeight(intervals.NewIntervalQuery("body", source), search.COMPLETE_NO_SCORES, 1)
+		assert.Nil(t, err)
+		leaf := leaves[coreIndex.SubIndexV1(doc, leaves)]
+		matches, err := weight.Matches(leaf, doc-leaf.DocBase())
+		assert.Nil(t, err)
+		if matches == nil {
+			return nil
+		}
+		it, err := matches.GetMatches("body")
+		assert.Nil(t, err)
+		if it == nil {
+			return nil
+		}
+		result := make([]match, 0)
+		for {
+			ok, err := it.Next()
+			assert.Nil(t, err)
+			if !ok {
+				return result
+			}
+			startOffset, err := it.StartOffset()
+			assert.Nil(t, err)
+			endOffset, err := it.EndOffset()
+			assert.Nil(t, err)
+			result = append(result, match{it.StartPosition(), it.EndPosition(), startOffset, endOffset})
+		}
+	}
+
+	ab := intervals.Ordered(intervals.Term("a"), intervals.Term("b"))
+	assert.Equal(t, []match{{0, 1, 0, 3}, {3, 4, 6, 9}}, queryMatches(ab, 0))
+	assert.Equal(t, []match{{0, 2, 0, 5}}, queryMatches(ab, 1))
+	assert.Nil(t, queryMatches(ab, 2))
+	// the second segment
+	assert.Equal(t, []match{{1, 2, 2, 5}}, queryMatches(ab, 3))
+
+	source := intervals.NotContaining(intervals.Ordered(intervals.Term("a"), intervals.Term("c")), intervals.Term("x"))
+	assert.Equal(t, []match{{0, 2, 0, 5}, {3, 5, 6, 11}}, queryMatches(source, 0))
+	assert.Nil(t, queryMatches(source, 1))
+}
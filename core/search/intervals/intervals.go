@@ -0,0 +1,133 @@
+package intervals
+
+import (
+	"fmt"
+)
+
+// Term
+// Return an IntervalsSource exposing intervals for a term
+func Term(term string) IntervalsSource {
+	return newTermIntervalsSource([]byte(term))
+}
+
+// TermBytes
+// Return an IntervalsSource exposing intervals for a term
+func TermBytes(term []byte) IntervalsSource {
+	return newTermIntervalsSource(term)
+}
+
+// Phrase
+// Return an IntervalsSource exposing intervals for a phrase consisting of a list of terms
+func Phrase(terms ...string) IntervalsSource {
+	sources := make([]IntervalsSource, 0, len(terms))
+	for _, term := range terms {
+		sources = append(sources, Term(term))
+	}
+	return PhraseSources(sources...)
+}
+
+// PhraseSources
+// Return an IntervalsSource exposing intervals for a phrase consisting of a list of
+// IntervalsSources, each of them must directly follow the previous one
+func PhraseSources(subSources ...IntervalsSource) IntervalsSource {
+	switch len(subSources) {
+	case 0:
+		return NoIntervals("empty phrase")
+	case 1:
+		return subSources[0]
+	default:
+		return newBlockIntervalsSource(subSources)
+	}
+}
+
+// Ordered
+// Create an ordered IntervalsSource
+//
+// Returns intervals in which the subsources all appear in the given order, with no overlaps.
+// Only the minimal intervals are returned: an interval is never reported if a narrower one
+// exists inside it.
+func Ordered(subSources ...IntervalsSource) IntervalsSource {
+	switch len(subSources) {
+	case 0:
+		return NoIntervals("empty ordered")
+	case 1:
+		return subSources[0]
+	default:
+		return newOrderedIntervalsSource(subSources)
+	}
+}
+
+// Unordered
+// Create an unordered IntervalsSource
+//
+// Returns the minimal intervals in which all the subsources appear, in any order.
+// Note that subsources may overlap each other.
+func Unordered(subSources ...IntervalsSource) IntervalsSource {
+	switch len(subSources) {
+	case 0:
+		return NoIntervals("empty unordered")
+	case 1:
+		return subSources[0]
+	default:
+		return newUnorderedIntervalsSource(subSources)
+	}
+}
+
+// MaxGaps
+// Create an IntervalsSource that filters a sub-source by the number of gaps between its
+// constituent subsources
+// gaps: the maximum number of gaps (positions not covered by a subsource) allowed in an
+// interval of the source
+func MaxGaps(source IntervalsSource, gaps int) IntervalsSource {
+	return newFilteredIntervalsSource(fmt.Sprintf("MAXGAPS/%d", gaps), source, func(it IntervalIterator) bool {
+		return it.Gaps() <= gaps
+	})
+}
+
+// MaxWidth
+// Create an IntervalsSource that filters a sub-source by the width of its intervals
+// width: the maximum width of an interval of the source
+func MaxWidth(source IntervalsSource, width int) IntervalsSource {
+	return newFilteredIntervalsSource(fmt.Sprintf("MAXWIDTH/%d", width), source, func(it IntervalIterator) bool {
+		return it.End()-it.Start()+1 <= width
+	})
+}
+
+// Containing
+// Returns intervals from the big source that contain one or more intervals from the small source
+func Containing(big, small IntervalsSource) IntervalsSource {
+	return newContainingIntervalsSource(big, small)
+}
+
+// NotContaining
+// Create a not-containing IntervalsSource
+//
+// Returns intervals from the minuend that do not contain intervals of the subtrahend
+func NotContaining(minuend, subtrahend IntervalsSource) IntervalsSource {
+	return newNotContainingIntervalsSource(minuend, subtrahend)
+}
+
+// Overlapping
+// Returns intervals from the source that overlap with intervals from the reference
+func Overlapping(source, reference IntervalsSource) IntervalsSource {
+	return newOverlappingIntervalsSource(source, reference)
+}
+
+// Extend
+// Create an IntervalsSource that extends the bounds of the intervals of another source
+//
+// Useful for specifying gaps in an ordered or phrase source; if you want to match `a b [2
+// spaces] c`, you can search for PhraseSources(Term("a"), Extend(Term("b"), 0, 2), Term("c")).
+// Note that when intervals are extended backwards, they are clipped to position 0.
+// before: how many positions to extend the start of the intervals by
+// after: how many positions to extend the end of the intervals by
+func Extend(source IntervalsSource, before, after int) IntervalsSource {
+	return newExtendedIntervalsSource(source, before, after)
+}
+
+// NoIntervals
+// Return an IntervalsSource that matches no intervals
+// reason: why the source matches nothing, reported by its string representation
+func NoIntervals(reason string) IntervalsSource {
+	return newNoMatchIntervalsSource(reason)
+}
@@ -0,0 +1,136 @@
+package intervals
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// IntervalScoreFunction
+// A scoring function for an IntervalQuery, computed from the sloppy frequency of the intervals of
+// a document. Scores are in the range [0, 1) and multiplied by the boost of the query.
+type IntervalScoreFunction interface {
+	// Score
+	// Computes a score from the sloppy frequency of the intervals of a document
+	Score(sloppyFreq float64) float64
+
+	// Explain
+	// Explains the score of a document
+	// interval: a description of the intervals source
+	// weight: the boost of the query
+	// sloppyFreq: the sloppy frequency of the intervals of the document
+	Explain(interval string, weight, sloppyFreq float64) types.Explanation
+
+	// Equals
+	// Returns true if other is a function of the same type with the same parameters
+	Equals(other IntervalScoreFunction) bool
+
+	// HashCode
+	// Returns a hash code consistent with Equals
+	HashCode() int
+
+	String() string
+}
+
+// NewSaturationFunction
+// Create an IntervalScoreFunction that computes S / (S + pivot), S being the sloppy frequency
+// of the intervals. pivot is the frequency that gives a score of 0.5.
+func NewSaturationFunction(pivot float64) (IntervalScoreFunction, error) {
+	if pivot <= 0 || math.IsInf(pivot, 0) || math.IsNaN(pivot) {
+		return nil, errors.New("pivot must be > 0")
+	}
+	return &saturationFunction{pivot: pivot}, nil
+}
+
+// NewSigmoidFunction
+// Create an IntervalScoreFunction that computes S^a / (S^a + pivot^a), S being the sloppy
+// frequency of the intervals. Higher values of the exponent a make the function grow slower
+// before pivot and faster after it.
+func NewSigmoidFunction(pivot, exp float64) (IntervalScoreFunction, error) {
+	if pivot <= 0 || math.IsInf(pivot, 0) || math.IsNaN(pivot) {
+		return nil, errors.New("pivot must be > 0")
+	}
+	if exp <= 0 || math.IsInf(exp, 0) || math.IsNaN(exp) {
+		return nil, errors.New("exp must be > 0")
+	}
+	return &sigmoidFunction{pivot: pivot, a: exp}, nil
+}
+
+var _ IntervalScoreFunction = &saturationFunction{}
+
+type saturationFunction struct {
+	pivot float64
+}
+
+func (s *saturationFunction) Score(sloppyFreq float64) float64 {
+	// should be f / (f + k) but we rewrite it to
+	// 1 - k / (f + k) to make sure it doesn't decrease
+	// with f in spite of rounding
+	return 1.0 - s.pivot/(sloppyFreq+s.pivot)
+}
+
+func (s *saturationFunction) Explain(interval string, weight, sloppyFreq float64) types.Explanation {
+	score := s.Score(sloppyFreq)
+	return types.ExplanationMatch(score*weight,
+		"Saturation function on interval frequency, computed as w * S / (S + k) from:",
+		types.ExplanationMatch(weight, "w, weight of this function"),
+		types.ExplanationMatch(s.pivot, "k, pivot feature value that would give a score contribution equal to w/2"),
+		types.ExplanationMatch(sloppyFreq, "S, the sloppy frequency of the interval query "+interval),
+	)
+}
+
+func (s *saturationFunction) Equals(other IntervalScoreFunction) bool {
+	o, ok := other.(*saturationFunction)
+	return ok && s.pivot == o.pivot
+}
+
+func (s *saturationFunction) HashCode() int {
+	return 31*search.ClassHash("SaturationFunction") + search.HashFloat64(s.pivot)
+}
+
+func (s *saturationFunction) String() string {
+	return fmt.Sprintf("SaturationFunction(pivot=%v)", s.pivot)
+}
+
+var _ IntervalScoreFunction = &sigmoidFunction{}
+
+type sigmoidFunction struct {
+	pivot, a float64
+}
+
+func (s *sigmoidFunction) Score(sloppyFreq float64) float64 {
+	// should be f^a / (f^a + k^a) but we rewrite it to
+	// 1 - k^a / (f^a + k^a) to make sure it doesn't decrease
+	// with f in spite of rounding
+	pivotPow := math.Pow(s.pivot, s.a)
+	return 1.0 - pivotPow/(math.Pow(sloppyFreq, s.a)+pivotPow)
+}
+
+func (s *sigmoidFunction) Explain(interval string, weight, sloppyFreq float64) types.Explanation {
+	score := s.Score(sloppyFreq)
+	return types.ExplanationMatch(score*weight,
+		"Sigmoid function on interval frequency, computed as w * S^a / (S^a + k^a) from:",
+		types.ExplanationMatch(weight, "w, weight of this function"),
+		types.ExplanationMatch(s.pivot, "k, pivot feature value that would give a score contribution equal to w/2"),
+		types.ExplanationMatch(s.a, "a, exponent, higher values make the function grow slower before k and faster after k"),
+		types.ExplanationMatch(sloppyFreq, "S, the sloppy frequency of the interval query "+interval),
+	)
+}
+
+func (s *sigmoidFunction) String() string {
+	return fmt.Sprintf("SigmoidFunction(pivot=%v, a=%v)", s.pivot, s.a)
+}
+
+func (s *sigmoidFunction) Equals(other IntervalScoreFunction) bool {
+	o, ok := other.(*sigmoidFunction)
+	return ok && s.pivot == o.pivot && s.a == o.a
+}
+
+func (s *sigmoidFunction) HashCode() int {
+	h := search.ClassHash("SigmoidFunction")
+	h = 31*h + search.HashFloat64(s.pivot)
+	return 31*h + search.HashFloat64(s.a)
+}
@@ -0,0 +1,115 @@
+package intervals
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Scorer = &IntervalScorer{}
+
+// IntervalScorer
+// Scores the documents matched by an IntervalIterator. Each interval contributes
+// 1 / (width - minExtent + 1) to the sloppy frequency of a document, so that the narrowest
+// possible intervals count fully and wider ones count less.
+type IntervalScorer struct {
+	*search.BaseScorer
+
+	intervals     IntervalIterator
+	minExtent     int
+	boost         float64
+	scoreFunction IntervalScoreFunction
+
+	freq          float64
+	lastScoredDoc int
+}
+
+func newIntervalScorer(weight index.Weight, intervals IntervalIterator, minExtent int, boost float64,
+	scoreFunction IntervalScoreFunction) *IntervalScorer {
+
+	return &IntervalScorer{
+		BaseScorer:    search.NewScorer(weight),
+		intervals:     intervals,
+		minExtent:     minExtent,
+		boost:         boost,
+		scoreFunction: scoreFunction,
+		freq:          -1,
+		lastScoredDoc: -1,
+	}
+}
+
+func (s *IntervalScorer) DocID() int {
+	return s.intervals.DocID()
+}
+
+func (s *IntervalScorer) Score() (float64, error) {
+	if err := s.ensureFreq(); err != nil {
+		return 0, err
+	}
+	return s.scoreFunction.Score(s.freq) * s.boost, nil
+}
+
+// Freq
+// Returns the sloppy frequency of the intervals of the current document
+func (s *IntervalScorer) Freq() (float64, error) {
+	if err := s.ensureFreq(); err != nil {
+		return 0, err
+	}
+	return s.freq, nil
+}
+
+func (s *IntervalScorer) ensureFreq() error {
+	if s.lastScoredDoc == s.DocID() {
+		return nil
+	}
+	s.lastScoredDoc = s.DocID()
+	s.freq = 0
+	for {
+		length := s.intervals.End() - s.intervals.Start() + 1
+		s.freq += 1.0 / float64(max(length-s.minExtent+1, 1))
+
+		start, err := s.intervals.NextInterval()
+		if err != nil {
+			return err
+		}
+		if start == NO_MORE_INTERVALS {
+			return nil
+		}
+	}
+}
+
+func (s *IntervalScorer) Iterator() types.DocIdSetIterator {
+	return search.AsDocIdSetIterator(s.TwoPhaseIterator())
+}
+
+func (s *IntervalScorer) TwoPhaseIterator() index.TwoPhaseIterator {
+	return &intervalsTwoPhaseIterator{intervals: s.intervals}
+}
+
+func (s *IntervalScorer) GetMaxScore(upTo int) (float64, error) {
+	return s.boost, nil
+}
+
+var _ index.TwoPhaseIterator = &intervalsTwoPhaseIterator{}
+
+// intervalsTwoPhaseIterator
+// A document matches if the intervals approximation has at least one interval on it
+type intervalsTwoPhaseIterator struct {
+	intervals IntervalIterator
+}
+
+func (i *intervalsTwoPhaseIterator) Approximation() types.DocIdSetIterator {
+	return i.intervals
+}
+
+func (i *intervalsTwoPhaseIterator) Matches() (bool, error) {
+	start, err := i.intervals.NextInterval()
+	if err != nil {
+		return false, err
+	}
+	return start != NO_MORE_INTERVALS, nil
+}
+
+func (i *intervalsTwoPhaseIterator) MatchCost() float64 {
+	return i.intervals.MatchCost()
+}
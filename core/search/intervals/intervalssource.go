@@ -0,0 +1,82 @@
+package intervals
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+// IntervalsSource
+// A helper type for IntervalQuery that provides an IntervalIterator for a given field and segment
+//
+// Sources are built with the functions of this package, see Term, Phrase, Ordered and friends.
+type IntervalsSource interface {
+	// Intervals
+	// Create an IntervalIterator exposing the minimum intervals defined by this IntervalsSource
+	// Returns nil if no intervals can match in the given segment.
+	// field: the field to read positions from
+	// ctx: the context for which to return the iterator
+	Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error)
+
+	// Matches
+	// Return an IntervalMatchesIterator exposing positions and offsets of the intervals of a
+	// particular document, or nil if there are no matches
+	// field: the field to retrieve positions and offsets from
+	// ctx: the LeafReaderContext containing the document
+	// doc: the document to return matches for
+	Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error)
+
+	// Visit
+	// Expert: visit the tree of sources
+	Visit(field string, visitor index.QueryVisitor) error
+
+	// MinExtent
+	// Return the minimum possible width of an interval returned by this source
+	MinExtent() int
+
+	// Equals
+	// Returns true if other is a source of the same type with equal sub-sources and parameters
+	Equals(other IntervalsSource) bool
+
+	// HashCode
+	// Returns a hash code consistent with Equals
+	HashCode() int
+
+	String() string
+}
+
+// IntervalMatchesIterator
+// An extension of MatchesIterator that allows the gaps from a wrapped IntervalIterator to be
+// reported.
+// This is necessary because MatchesIterator.GetSubMatches does not return the submatches in
+// position order, which means that gaps cannot be computed from them.
+type IntervalMatchesIterator interface {
+	index.MatchesIterator
+
+	// Gaps
+	// The number of top-level gaps inside the current match
+	Gaps() int
+
+	// Width
+	// The width of the current match
+	Width() int
+}
+
+// sourcesEqual Returns true if both lists hold equal sources in the same order
+func sourcesEqual(a, b []IntervalsSource) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sourcesHashCode(sources []IntervalsSource) int {
+	h := 1
+	for _, source := range sources {
+		h = 31*h + source.HashCode()
+	}
+	return h
+}
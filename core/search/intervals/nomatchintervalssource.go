@@ -0,0 +1,47 @@
+package intervals
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ IntervalsSource = &noMatchIntervalsSource{}
+
+// noMatchIntervalsSource
+// A source returning no matches
+type noMatchIntervalsSource struct {
+	reason string
+}
+
+func newNoMatchIntervalsSource(reason string) *noMatchIntervalsSource {
+	return &noMatchIntervalsSource{reason: reason}
+}
+
+func (n *noMatchIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	return nil, nil
+}
+
+func (n *noMatchIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	return nil, nil
+}
+
+func (n *noMatchIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	return nil
+}
+
+func (n *noMatchIntervalsSource) MinExtent() int {
+	return 0
+}
+
+func (n *noMatchIntervalsSource) String() string {
+	return "NOMATCH(" + n.reason + ")"
+}
+
+func (n *noMatchIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*noMatchIntervalsSource)
+	return ok && n.reason == o.reason
+}
+
+func (n *noMatchIntervalsSource) HashCode() int {
+	return 31*search.ClassHash("NoMatchIntervalsSource") + search.HashString(n.reason)
+}
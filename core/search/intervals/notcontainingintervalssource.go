@@ -0,0 +1,186 @@
+package intervals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ IntervalsSource = &notContainingIntervalsSource{}
+
+type notContainingIntervalsSource struct {
+	minuend, subtrahend IntervalsSource
+}
+
+func newNotContainingIntervalsSource(minuend, subtrahend IntervalsSource) *notContainingIntervalsSource {
+	return &notContainingIntervalsSource{
+		minuend:    minuend,
+		subtrahend: subtrahend,
+	}
+}
+
+func (n *notContainingIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	minIt, err := n.minuend.Intervals(field, ctx)
+	if err != nil || minIt == nil {
+		return nil, err
+	}
+	subIt, err := n.subtrahend.Intervals(field, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subIt == nil {
+		return minIt, nil
+	}
+	return newNotContainingIntervalIterator(minIt, subIt), nil
+}
+
+func (n *notContainingIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	minIt, err := n.minuend.Matches(field, ctx, doc)
+	if err != nil || minIt == nil {
+		return nil, err
+	}
+	subIt, err := n.subtrahend.Intervals(field, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subIt == nil {
+		return minIt, nil
+	}
+	return asMatches(newNotContainingIntervalIterator(wrapMatches(minIt, doc), subIt), minIt, doc)
+}
+
+func (n *notContainingIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	parent := NewIntervalQuery(field, n)
+	if err := n.minuend.Visit(field, visitor.GetSubVisitor(index.OccurMust, parent)); err != nil {
+		return err
+	}
+	return n.subtrahend.Visit(field, visitor.GetSubVisitor(index.OccurMustNot, parent))
+}
+
+func (n *notContainingIntervalsSource) MinExtent() int {
+	return n.minuend.MinExtent()
+}
+
+func (n *notContainingIntervalsSource) String() string {
+	return fmt.Sprintf("NOT_CONTAINING(%s,%s)", n.minuend, n.subtrahend)
+}
+
+var _ IntervalIterator = &notContainingIntervalIterator{}
+
+// notContainingIntervalIterator
+// Returns the intervals of a that do not contain any interval of b. Documents are driven by a
+// alone, b is only advanced to the documents a stops on.
+type notContainingIntervalIterator struct {
+	a, b IntervalIterator
+	bpos bool
+}
+
+func newNotContainingIntervalIterator(a, b IntervalIterator) *notContainingIntervalIterator {
+	return &notContainingIntervalIterator{a: a, b: b}
+}
+
+func (n *notContainingIntervalIterator) DocID() int {
+	return n.a.DocID()
+}
+
+func (n *notContainingIntervalIterator) NextDoc(ctx context.Context) (int, error) {
+	doc, err := n.a.NextDoc(ctx)
+	if err != nil {
+		return doc, err
+	}
+	return doc, n.reset(ctx, doc)
+}
+
+func (n *notContainingIntervalIterator) Advance(ctx context.Context, target int) (int, error) {
+	doc, err := n.a.Advance(ctx, target)
+	if err != nil {
+		return doc, err
+	}
+	return doc, n.reset(ctx, doc)
+}
+
+func (n *notContainingIntervalIterator) reset(ctx context.Context, doc int) error {
+	if n.b.DocID() < doc {
+		if _, err := n.b.Advance(ctx, doc); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+	if n.b.DocID() != doc {
+		n.bpos = false
+		return nil
+	}
+	start, err := n.b.NextInterval()
+	if err != nil {
+		return err
+	}
+	n.bpos = start != NO_MORE_INTERVALS
+	return nil
+}
+
+func (n *notContainingIntervalIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, n, target)
+}
+
+func (n *notContainingIntervalIterator) Cost() int64 {
+	return n.a.Cost()
+}
+
+func (n *notContainingIntervalIterator) Start() int {
+	return n.a.Start()
+}
+
+func (n *notContainingIntervalIterator) End() int {
+	return n.a.End()
+}
+
+func (n *notContainingIntervalIterator) Gaps() int {
+	return n.a.Gaps()
+}
+
+func (n *notContainingIntervalIterator) NextInterval() (int, error) {
+	if !n.bpos {
+		return n.a.NextInterval()
+	}
+	for {
+		start, err := n.a.NextInterval()
+		if err != nil {
+			return 0, err
+		}
+		if start == NO_MORE_INTERVALS {
+			return NO_MORE_INTERVALS, nil
+		}
+		for n.b.Start() < n.a.Start() && n.b.End() < n.a.End() {
+			bstart, err := n.b.NextInterval()
+			if err != nil {
+				return 0, err
+			}
+			if bstart == NO_MORE_INTERVALS {
+				n.bpos = false
+				return n.a.Start(), nil
+			}
+		}
+		if n.a.Start() > n.b.Start() || n.a.End() < n.b.End() {
+			return n.a.Start(), nil
+		}
+	}
+}
+
+func (n *notContainingIntervalIterator) MatchCost() float64 {
+	return n.a.MatchCost() + n.b.MatchCost()
+}
+
+func (n *notContainingIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*notContainingIntervalsSource)
+	return ok && n.minuend.Equals(o.minuend) && n.subtrahend.Equals(o.subtrahend)
+}
+
+func (n *notContainingIntervalsSource) HashCode() int {
+	h := search.ClassHash("NotContainingIntervalsSource")
+	h = 31*h + n.minuend.HashCode()
+	return 31*h + n.subtrahend.HashCode()
+}
@@ -0,0 +1,153 @@
+package intervals
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ IntervalsSource = &orderedIntervalsSource{}
+
+type orderedIntervalsSource struct {
+	*conjunctionIntervalsSource
+}
+
+func newOrderedIntervalsSource(subSources []IntervalsSource) *orderedIntervalsSource {
+	source := &orderedIntervalsSource{}
+	source.conjunctionIntervalsSource = newConjunctionIntervalsSource(subSources, true, source)
+	return source
+}
+
+func (o *orderedIntervalsSource) combine(iterators []IntervalIterator) (IntervalIterator, error) {
+	return newOrderedIntervalIterator(iterators)
+}
+
+func (o *orderedIntervalsSource) MinExtent() int {
+	minExtent := 0
+	for _, source := range o.subSources {
+		minExtent += source.MinExtent()
+	}
+	return minExtent
+}
+
+func (o *orderedIntervalsSource) String() string {
+	return "ORDERED(" + joinSources(o.subSources) + ")"
+}
+
+func joinSources(sources []IntervalsSource) string {
+	items := make([]string, 0, len(sources))
+	for _, source := range sources {
+		items = append(items, source.String())
+	}
+	return strings.Join(items, ",")
+}
+
+var _ IntervalIterator = &orderedIntervalIterator{}
+
+// orderedIntervalIterator
+// Returns the minimal intervals in which all sub-intervals appear in order and do not overlap
+type orderedIntervalIterator struct {
+	*conjunctionIntervalIterator
+
+	start, end, slop int
+	i                int
+}
+
+func newOrderedIntervalIterator(subIterators []IntervalIterator) (*orderedIntervalIterator, error) {
+	it := &orderedIntervalIterator{start: -1, end: -1, slop: -1}
+	conjunction, err := newConjunctionIntervalIterator(subIterators, it)
+	if err != nil {
+		return nil, err
+	}
+	it.conjunctionIntervalIterator = conjunction
+	return it, nil
+}
+
+func (o *orderedIntervalIterator) Start() int {
+	return o.start
+}
+
+func (o *orderedIntervalIterator) End() int {
+	return o.end
+}
+
+func (o *orderedIntervalIterator) Gaps() int {
+	return o.slop
+}
+
+func (o *orderedIntervalIterator) NextInterval() (int, error) {
+	o.start, o.end, o.slop = NO_MORE_INTERVALS, NO_MORE_INTERVALS, NO_MORE_INTERVALS
+	subIterators := o.subIterators
+	lastStart := math.MaxInt32
+	minimizing := false
+
+	for {
+		for {
+			if subIterators[o.i-1].End() >= lastStart {
+				return o.start, nil
+			}
+			if o.i == len(subIterators) ||
+				(minimizing && subIterators[o.i].Start() > subIterators[o.i-1].End()) {
+				break
+			}
+			for {
+				if subIterators[o.i].End() >= lastStart {
+					return o.start, nil
+				}
+				start, err := subIterators[o.i].NextInterval()
+				if err != nil {
+					return 0, err
+				}
+				if start == NO_MORE_INTERVALS {
+					return o.start, nil
+				}
+				if subIterators[o.i].Start() > subIterators[o.i-1].End() {
+					break
+				}
+			}
+			o.i++
+		}
+
+		o.start = subIterators[0].Start()
+		if o.start == NO_MORE_INTERVALS {
+			o.end = NO_MORE_INTERVALS
+			return o.end, nil
+		}
+		last := subIterators[len(subIterators)-1]
+		o.end = last.End()
+		o.slop = o.end - o.start + 1
+		for _, subIterator := range subIterators {
+			o.slop -= width(subIterator)
+		}
+		lastStart = last.Start()
+		o.i = 1
+		start, err := subIterators[0].NextInterval()
+		if err != nil {
+			return 0, err
+		}
+		if start == NO_MORE_INTERVALS {
+			return o.start, nil
+		}
+		minimizing = true
+	}
+}
+
+func (o *orderedIntervalIterator) reset(ctx context.Context) error {
+	if _, err := o.subIterators[0].NextInterval(); err != nil {
+		return err
+	}
+	o.i = 1
+	o.start, o.end, o.slop = -1, -1, -1
+	return nil
+}
+
+func (s *orderedIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*orderedIntervalsSource)
+	return ok && s.equalsTo(o.conjunctionIntervalsSource)
+}
+
+func (s *orderedIntervalsSource) HashCode() int {
+	return 31*search.ClassHash("OrderedIntervalsSource") + s.fieldsHashCode()
+}
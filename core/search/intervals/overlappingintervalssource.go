@@ -0,0 +1,117 @@
+package intervals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ IntervalsSource = &overlappingIntervalsSource{}
+
+type overlappingIntervalsSource struct {
+	*conjunctionIntervalsSource
+
+	source, reference IntervalsSource
+}
+
+func newOverlappingIntervalsSource(source, reference IntervalsSource) *overlappingIntervalsSource {
+	s := &overlappingIntervalsSource{source: source, reference: reference}
+	s.conjunctionIntervalsSource = newConjunctionIntervalsSource([]IntervalsSource{source, reference}, false, s)
+	return s
+}
+
+func (o *overlappingIntervalsSource) combine(iterators []IntervalIterator) (IntervalIterator, error) {
+	return newOverlappingIntervalIterator(iterators[0], iterators[1])
+}
+
+func (o *overlappingIntervalsSource) MinExtent() int {
+	return o.source.MinExtent()
+}
+
+func (o *overlappingIntervalsSource) String() string {
+	return fmt.Sprintf("OVERLAPPING(%s,%s)", o.source, o.reference)
+}
+
+var _ IntervalIterator = &overlappingIntervalIterator{}
+
+// overlappingIntervalIterator
+// Returns the intervals of a that share at least one position with an interval of b
+type overlappingIntervalIterator struct {
+	*conjunctionIntervalIterator
+
+	a, b IntervalIterator
+	bpos bool
+}
+
+func newOverlappingIntervalIterator(a, b IntervalIterator) (*overlappingIntervalIterator, error) {
+	it := &overlappingIntervalIterator{a: a, b: b, bpos: true}
+	conjunction, err := newConjunctionIntervalIterator([]IntervalIterator{a, b}, it)
+	if err != nil {
+		return nil, err
+	}
+	it.conjunctionIntervalIterator = conjunction
+	return it, nil
+}
+
+func (o *overlappingIntervalIterator) Start() int {
+	if !o.bpos {
+		return NO_MORE_INTERVALS
+	}
+	return o.a.Start()
+}
+
+func (o *overlappingIntervalIterator) End() int {
+	if !o.bpos {
+		return NO_MORE_INTERVALS
+	}
+	return o.a.End()
+}
+
+func (o *overlappingIntervalIterator) Gaps() int {
+	return o.a.Gaps()
+}
+
+func (o *overlappingIntervalIterator) NextInterval() (int, error) {
+	if !o.bpos {
+		return NO_MORE_INTERVALS, nil
+	}
+	for {
+		start, err := o.a.NextInterval()
+		if err != nil {
+			return 0, err
+		}
+		if start == NO_MORE_INTERVALS {
+			return NO_MORE_INTERVALS, nil
+		}
+		for o.b.End() < o.a.Start() {
+			bstart, err := o.b.NextInterval()
+			if err != nil {
+				return 0, err
+			}
+			if bstart == NO_MORE_INTERVALS {
+				o.bpos = false
+				return NO_MORE_INTERVALS, nil
+			}
+		}
+		if o.b.Start() <= o.a.End() {
+			return o.a.Start(), nil
+		}
+	}
+}
+
+func (o *overlappingIntervalIterator) reset(ctx context.Context) error {
+	o.bpos = true
+	return nil
+}
+
+func (o *overlappingIntervalsSource) Equals(other IntervalsSource) bool {
+	source, ok := other.(*overlappingIntervalsSource)
+	return ok && o.source.Equals(source.source) && o.reference.Equals(source.reference)
+}
+
+func (o *overlappingIntervalsSource) HashCode() int {
+	h := search.ClassHash("OverlappingIntervalsSource")
+	h = 31*h + o.source.HashCode()
+	return 31*h + o.reference.HashCode()
+}
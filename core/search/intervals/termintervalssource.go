@@ -0,0 +1,294 @@
+package intervals
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ IntervalsSource = &termIntervalsSource{}
+
+type termIntervalsSource struct {
+	term []byte
+}
+
+func newTermIntervalsSource(term []byte) *termIntervalsSource {
+	return &termIntervalsSource{term: term}
+}
+
+func (t *termIntervalsSource) Intervals(field string, ctx index.LeafReaderContext) (IntervalIterator, error) {
+	termsEnum, err := t.seekTerm(field, ctx)
+	if err != nil || termsEnum == nil {
+		return nil, err
+	}
+	postings, err := termsEnum.Postings(nil, coreIndex.POSTINGS_ENUM_POSITIONS)
+	if err != nil {
+		return nil, err
+	}
+	cost, err := termPositionsCost(termsEnum)
+	if err != nil {
+		return nil, err
+	}
+	return newTermIntervalIterator(postings, t.term, cost), nil
+}
+
+// seekTerm
+// Returns a TermsEnum positioned on the term, or nil if the term does not exist in the segment
+func (t *termIntervalsSource) seekTerm(field string, ctx index.LeafReaderContext) (index.TermsEnum, error) {
+	terms, err := ctx.LeafReader().Terms(field)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, nil
+	}
+	if !terms.HasPositions() {
+		return nil, fmt.Errorf("cannot create an IntervalIterator over field %s because it has no indexed positions", field)
+	}
+	termsEnum, err := terms.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := termsEnum.SeekExact(nil, t.term)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	return termsEnum, nil
+}
+
+func (t *termIntervalsSource) Matches(field string, ctx index.LeafReaderContext, doc int) (IntervalMatchesIterator, error) {
+	termsEnum, err := t.seekTerm(field, ctx)
+	if err != nil || termsEnum == nil {
+		return nil, err
+	}
+	postings, err := termsEnum.Postings(nil, coreIndex.POSTINGS_ENUM_OFFSETS)
+	if err != nil {
+		return nil, err
+	}
+	target, err := postings.Advance(nil, doc)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if target != doc {
+		return nil, nil
+	}
+	freq, err := postings.Freq()
+	if err != nil {
+		return nil, err
+	}
+	return &termMatchesIterator{
+		postings: postings,
+		query:    search.NewTermQuery(coreIndex.NewTerm(field, t.term)),
+		upto:     freq,
+		pos:      -1,
+	}, nil
+}
+
+func (t *termIntervalsSource) Visit(field string, visitor index.QueryVisitor) error {
+	visitor.ConsumeTerms(NewIntervalQuery(field, t), coreIndex.NewTerm(field, t.term))
+	return nil
+}
+
+func (t *termIntervalsSource) MinExtent() int {
+	return 1
+}
+
+func (t *termIntervalsSource) String() string {
+	return string(t.term)
+}
+
+const (
+	// A guess of the average number of simple operations for the initial seek and buffer refill
+	// per document for the positions of a term.
+	termPosnsSeekOpsPerDoc = 128
+
+	// Number of simple operations in nextPosition() when no seek or buffer refill is done.
+	termOpsPerPos = 7
+)
+
+// Returns an expected cost in simple operations of processing the occurrences of a term in a
+// document that contains the term.
+func termPositionsCost(termsEnum index.TermsEnum) (float64, error) {
+	docFreq, err := termsEnum.DocFreq()
+	if err != nil {
+		return 0, err
+	}
+	totalTermFreq, err := termsEnum.TotalTermFreq()
+	if err != nil {
+		return 0, err
+	}
+	expOccurrencesInMatchingDoc := float64(totalTermFreq) / float64(docFreq)
+	return termPosnsSeekOpsPerDoc + expOccurrencesInMatchingDoc*termOpsPerPos, nil
+}
+
+var _ IntervalIterator = &termIntervalIterator{}
+
+type termIntervalIterator struct {
+	postings index.PostingsEnum
+	term     []byte
+	cost     float64
+	pos      int
+	upto     int
+}
+
+func newTermIntervalIterator(postings index.PostingsEnum, term []byte, cost float64) *termIntervalIterator {
+	return &termIntervalIterator{
+		postings: postings,
+		term:     term,
+		cost:     cost,
+		pos:      -1,
+	}
+}
+
+func (t *termIntervalIterator) DocID() int {
+	return t.postings.DocID()
+}
+
+func (t *termIntervalIterator) NextDoc(ctx context.Context) (int, error) {
+	doc, err := t.postings.NextDoc(ctx)
+	return t.reset(doc, err)
+}
+
+func (t *termIntervalIterator) Advance(ctx context.Context, target int) (int, error) {
+	doc, err := t.postings.Advance(ctx, target)
+	return t.reset(doc, err)
+}
+
+func (t *termIntervalIterator) reset(doc int, err error) (int, error) {
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		doc = types.NO_MORE_DOCS
+	}
+	if doc == types.NO_MORE_DOCS {
+		t.upto = -1
+		t.pos = NO_MORE_INTERVALS
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	freq, err := t.postings.Freq()
+	if err != nil {
+		return 0, err
+	}
+	t.upto = freq
+	t.pos = -1
+	return doc, nil
+}
+
+func (t *termIntervalIterator) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, t, target)
+}
+
+func (t *termIntervalIterator) Cost() int64 {
+	return t.postings.Cost()
+}
+
+func (t *termIntervalIterator) Start() int {
+	return t.pos
+}
+
+func (t *termIntervalIterator) End() int {
+	return t.pos
+}
+
+func (t *termIntervalIterator) Gaps() int {
+	return 0
+}
+
+func (t *termIntervalIterator) NextInterval() (int, error) {
+	if t.upto <= 0 {
+		t.pos = NO_MORE_INTERVALS
+		return t.pos, nil
+	}
+	t.upto--
+	pos, err := t.postings.NextPosition()
+	if err != nil {
+		return 0, err
+	}
+	t.pos = pos
+	return t.pos, nil
+}
+
+func (t *termIntervalIterator) MatchCost() float64 {
+	return t.cost
+}
+
+func (t *termIntervalIterator) String() string {
+	return fmt.Sprintf("%s:%d[%d->%d]", t.term, t.DocID(), t.pos, t.pos)
+}
+
+var _ IntervalMatchesIterator = &termMatchesIterator{}
+
+// termMatchesIterator
+// Reports the positions and offsets of a single term in a document
+type termMatchesIterator struct {
+	postings index.PostingsEnum
+	query    index.Query
+	upto     int
+	pos      int
+}
+
+func (t *termMatchesIterator) Next() (bool, error) {
+	if t.upto <= 0 {
+		t.pos = NO_MORE_INTERVALS
+		return false, nil
+	}
+	t.upto--
+	pos, err := t.postings.NextPosition()
+	if err != nil {
+		return false, err
+	}
+	t.pos = pos
+	return true, nil
+}
+
+func (t *termMatchesIterator) StartPosition() int {
+	return t.pos
+}
+
+func (t *termMatchesIterator) EndPosition() int {
+	return t.pos
+}
+
+func (t *termMatchesIterator) StartOffset() (int, error) {
+	return t.postings.StartOffset()
+}
+
+func (t *termMatchesIterator) EndOffset() (int, error) {
+	return t.postings.EndOffset()
+}
+
+func (t *termMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return nil, nil
+}
+
+func (t *termMatchesIterator) GetQuery() index.Query {
+	return t.query
+}
+
+func (t *termMatchesIterator) Gaps() int {
+	return 0
+}
+
+func (t *termMatchesIterator) Width() int {
+	return 1
+}
+
+func (t *termIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*termIntervalsSource)
+	return ok && bytes.Equal(t.term, o.term)
+}
+
+func (t *termIntervalsSource) HashCode() int {
+	return 31*search.ClassHash("TermIntervalsSource") + search.HashBytes(t.term)
+}
@@ -0,0 +1,161 @@
+package intervals
+
+import (
+	"context"
+
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ IntervalsSource = &unorderedIntervalsSource{}
+
+type unorderedIntervalsSource struct {
+	*conjunctionIntervalsSource
+}
+
+func newUnorderedIntervalsSource(subSources []IntervalsSource) *unorderedIntervalsSource {
+	source := &unorderedIntervalsSource{}
+	source.conjunctionIntervalsSource = newConjunctionIntervalsSource(subSources, true, source)
+	return source
+}
+
+func (u *unorderedIntervalsSource) combine(iterators []IntervalIterator) (IntervalIterator, error) {
+	return newUnorderedIntervalIterator(iterators)
+}
+
+func (u *unorderedIntervalsSource) MinExtent() int {
+	minExtent := 0
+	for _, source := range u.subSources {
+		minExtent += source.MinExtent()
+	}
+	return minExtent
+}
+
+func (u *unorderedIntervalsSource) String() string {
+	return "UNORDERED(" + joinSources(u.subSources) + ")"
+}
+
+var _ IntervalIterator = &unorderedIntervalIterator{}
+
+// unorderedIntervalIterator
+// Returns the minimal intervals containing an interval of each sub-iterator, in any order
+type unorderedIntervalIterator struct {
+	*conjunctionIntervalIterator
+
+	queue            *structure.PriorityQueue[IntervalIterator]
+	start, end, slop int
+	queueEnd         int
+	numIterators     int
+}
+
+func newUnorderedIntervalIterator(subIterators []IntervalIterator) (*unorderedIntervalIterator, error) {
+	it := &unorderedIntervalIterator{
+		queue: structure.NewPriorityQueue[IntervalIterator](len(subIterators), func(a, b IntervalIterator) bool {
+			return a.Start() < b.Start() || (a.Start() == b.Start() && a.End() >= b.End())
+		}),
+		start:        -1,
+		end:          -1,
+		queueEnd:     -1,
+		numIterators: len(subIterators),
+	}
+	conjunction, err := newConjunctionIntervalIterator(subIterators, it)
+	if err != nil {
+		return nil, err
+	}
+	it.conjunctionIntervalIterator = conjunction
+	return it, nil
+}
+
+func (u *unorderedIntervalIterator) Start() int {
+	return u.start
+}
+
+func (u *unorderedIntervalIterator) End() int {
+	return u.end
+}
+
+func (u *unorderedIntervalIterator) Gaps() int {
+	return u.slop
+}
+
+func (u *unorderedIntervalIterator) updateRightExtreme(it IntervalIterator) {
+	u.queueEnd = max(u.queueEnd, it.End())
+}
+
+// advanceTop
+// Moves the iterator with the lowest start to its next interval, it leaves the queue if it is
+// exhausted
+func (u *unorderedIntervalIterator) advanceTop() error {
+	it, err := u.queue.Pop()
+	if err != nil {
+		// the queue is empty
+		return nil
+	}
+	start, err := it.NextInterval()
+	if err != nil {
+		return err
+	}
+	if start != NO_MORE_INTERVALS {
+		u.queue.Add(it)
+		u.updateRightExtreme(it)
+	}
+	return nil
+}
+
+func (u *unorderedIntervalIterator) NextInterval() (int, error) {
+	// first, find a matching interval
+	for u.queue.Size() == u.numIterators && u.queue.Top().Start() == u.start {
+		if err := u.advanceTop(); err != nil {
+			return 0, err
+		}
+	}
+	if u.queue.Size() < u.numIterators {
+		u.start, u.end = NO_MORE_INTERVALS, NO_MORE_INTERVALS
+		return u.start, nil
+	}
+
+	// then, minimize it
+	for {
+		u.start = u.queue.Top().Start()
+		u.end = u.queueEnd
+		u.slop = u.end - u.start + 1
+		for _, it := range u.subIterators {
+			u.slop -= width(it)
+		}
+		if u.queue.Top().End() == u.end {
+			return u.start, nil
+		}
+		if err := u.advanceTop(); err != nil {
+			return 0, err
+		}
+		if u.queue.Size() != u.numIterators || u.end != u.queueEnd {
+			return u.start, nil
+		}
+	}
+}
+
+func (u *unorderedIntervalIterator) reset(ctx context.Context) error {
+	u.queueEnd, u.start, u.end = -1, -1, -1
+	u.queue.Clear()
+	for _, it := range u.subIterators {
+		start, err := it.NextInterval()
+		if err != nil {
+			return err
+		}
+		if start == NO_MORE_INTERVALS {
+			break
+		}
+		u.queue.Add(it)
+		u.updateRightExtreme(it)
+	}
+	return nil
+}
+
+func (s *unorderedIntervalsSource) Equals(other IntervalsSource) bool {
+	o, ok := other.(*unorderedIntervalsSource)
+	return ok && s.equalsTo(o.conjunctionIntervalsSource)
+}
+
+func (s *unorderedIntervalsSource) HashCode() int {
+	return 31*search.ClassHash("UnorderedIntervalsSource") + s.fieldsHashCode()
+}
@@ -0,0 +1,725 @@
+package search
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/geange/gods-generic/sets/treeset"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+const (
+	// rough estimates of the memory used by the bookkeeping of the cache
+	hashtableRamBytesPerEntry       = 32
+	linkedHashtableRamBytesPerEntry = hashtableRamBytesPerEntry + 16
+	queryDefaultRamBytesUsed        = 1024
+)
+
+var _ index.QueryCache = &LRUQueryCache{}
+
+// LRUQueryCache
+// A QueryCache that evicts queries using a LRU (least-recently-used) eviction policy in order to
+// remain under a given maximum size and number of bytes used. This class is thread-safe.
+//
+// Note that query eviction runs in linear time with the total number of segments that have cache
+// entries so this cache works best with caching policies that only cache on "large" segments, and
+// it is advised to not share this cache across too many indices.
+//
+// A default query cache and policy instance is used in IndexSearcher. If you want to replace those
+// defaults, use SetDefaultQueryCache and SetDefaultQueryCachingPolicy, or IndexSearcher.SetQueryCache
+// and IndexSearcher.SetQueryCachingPolicy for a single searcher.
+//
+// This cache exposes some global statistics (hit count, miss count, number of cache entries, total
+// number of DocIdSets that have ever been cached, number of evicted entries).
+type LRUQueryCache struct {
+	maxSize         int
+	maxRamBytesUsed int64
+	leavesToCache   func(ctx index.LeafReaderContext) bool
+	skipCacheFactor float64
+
+	// the lock protects all the fields below, cached weights prefer the uncached path over
+	// waiting for it
+	lock sync.Mutex
+
+	// maps queries that are contained in the cache to a singleton so that this
+	// cache does not store several copies of the same query, queries are grouped
+	// by hash code
+	uniqueQueries map[int32][]*list.Element
+
+	// the same entries as uniqueQueries, ordered from the least to the most recently used
+	mostRecentlyUsedQueries *list.List
+
+	// the per-segment caches, keyed by the core cache key of the segments
+	cache map[string]*leafCache
+
+	ramBytesUsed int64
+	hitCount     int64
+	missCount    int64
+	cacheCount   int64
+	cacheSize    int64
+}
+
+type lruQueryCacheOption struct {
+	leavesToCache   func(ctx index.LeafReaderContext) bool
+	skipCacheFactor float64
+}
+
+type LRUQueryCacheOption func(*lruQueryCacheOption)
+
+// WithLeavesToCache
+// Only leaves for which leavesToCache returns true are cached. The default only caches segments
+// that have at least 10000 documents and 3% of the documents of the index.
+func WithLeavesToCache(leavesToCache func(ctx index.LeafReaderContext) bool) LRUQueryCacheOption {
+	return func(o *lruQueryCacheOption) {
+		o.leavesToCache = leavesToCache
+	}
+}
+
+// WithSkipCacheFactor
+// A clause is not cached when its cost is more than skipCacheFactor times the cost of the leading
+// clause of the conjunction it belongs to, since caching it would require evaluating all its
+// matches while the conjunction only needs a few of them. The default is 10.
+func WithSkipCacheFactor(skipCacheFactor float64) LRUQueryCacheOption {
+	return func(o *lruQueryCacheOption) {
+		o.skipCacheFactor = skipCacheFactor
+	}
+}
+
+// NewLRUQueryCache
+// Create a new instance that will cache at most maxSize queries with at most maxRamBytesUsed bytes
+// of memory.
+func NewLRUQueryCache(maxSize int, maxRamBytesUsed int64, options ...LRUQueryCacheOption) *LRUQueryCache {
+	opt := &lruQueryCacheOption{
+		leavesToCache:   NewMinSegmentSizePredicate(10000, 0.03),
+		skipCacheFactor: 10,
+	}
+	for _, fn := range options {
+		fn(opt)
+	}
+
+	return &LRUQueryCache{
+		maxSize:                 maxSize,
+		maxRamBytesUsed:         maxRamBytesUsed,
+		leavesToCache:           opt.leavesToCache,
+		skipCacheFactor:         max(opt.skipCacheFactor, 1),
+		uniqueQueries:           make(map[int32][]*list.Element),
+		mostRecentlyUsedQueries: list.New(),
+		cache:                   make(map[string]*leafCache),
+	}
+}
+
+// NewMinSegmentSizePredicate
+// Returns a predicate accepting the segments that have at least minSize documents and at least
+// minSizeRatio of the documents of the top level reader.
+func NewMinSegmentSizePredicate(minSize int, minSizeRatio float64) func(ctx index.LeafReaderContext) bool {
+	return func(ctx index.LeafReaderContext) bool {
+		maxDoc := ctx.Reader().MaxDoc()
+		if maxDoc < minSize {
+			return false
+		}
+		topLevelContext := coreIndex.GetTopLevelContext(ctx)
+		sizeRatio := float64(maxDoc) / float64(topLevelContext.Reader().MaxDoc())
+		return sizeRatio >= minSizeRatio
+	}
+}
+
+// the cached entry of a unique query
+type cachedQuery struct {
+	query    index.Query
+	hashCode int32
+}
+
+// leafCache holds the cached doc id sets of a single segment core.
+type leafCache struct {
+	key          string
+	cache        map[*cachedQuery]DocIdSet
+	ramBytesUsed int64
+}
+
+func newLeafCache(key string) *leafCache {
+	return &leafCache{
+		key:   key,
+		cache: make(map[*cachedQuery]DocIdSet),
+	}
+}
+
+// onDocIdSetCache, onDocIdSetEviction and onClear keep the statistics in sync, they must be called
+// under the lock.
+
+func (c *LRUQueryCache) onDocIdSetCache(ramBytesUsed int64) {
+	c.cacheSize++
+	c.cacheCount++
+	c.ramBytesUsed += ramBytesUsed
+}
+
+func (c *LRUQueryCache) onDocIdSetEviction(numEntries int, sumRamBytesUsed int64) {
+	c.ramBytesUsed -= sumRamBytesUsed
+	c.cacheSize -= int64(numEntries)
+}
+
+func (c *LRUQueryCache) onClear() {
+	c.ramBytesUsed = 0
+	c.cacheSize = 0
+}
+
+func (l *leafCache) get(query *cachedQuery) DocIdSet {
+	return l.cache[query]
+}
+
+func (l *leafCache) putIfAbsent(c *LRUQueryCache, query *cachedQuery, set DocIdSet) {
+	if _, ok := l.cache[query]; ok {
+		return
+	}
+	l.cache[query] = set
+	ramBytesUsed := hashtableRamBytesPerEntry + docIdSetRamBytesUsed(set)
+	l.ramBytesUsed += ramBytesUsed
+	c.onDocIdSetCache(ramBytesUsed)
+}
+
+func (l *leafCache) remove(c *LRUQueryCache, query *cachedQuery) {
+	set, ok := l.cache[query]
+	if !ok {
+		return
+	}
+	delete(l.cache, query)
+	ramBytesUsed := hashtableRamBytesPerEntry + docIdSetRamBytesUsed(set)
+	l.ramBytesUsed -= ramBytesUsed
+	c.onDocIdSetEviction(1, ramBytesUsed)
+}
+
+// findQuery returns the list entry of the unique query that is equal to query, or nil.
+func (c *LRUQueryCache) findQuery(query index.Query, hashCode int32) *list.Element {
+	for _, element := range c.uniqueQueries[hashCode] {
+		if element.Value.(*cachedQuery).query.Equals(query) {
+			return element
+		}
+	}
+	return nil
+}
+
+func (c *LRUQueryCache) requiresEviction() bool {
+	size := c.mostRecentlyUsedQueries.Len()
+	if size == 0 {
+		return false
+	}
+	return size > c.maxSize || c.ramBytesUsed > c.maxRamBytesUsed
+}
+
+// get returns the cached doc id set of query on the segment core of cacheHelper, or nil if it is
+// not cached. Must be called under the lock.
+func (c *LRUQueryCache) get(query index.Query, hashCode int32, cacheHelper index.CacheHelper) DocIdSet {
+	leafCache, ok := c.cache[cacheHelper.GetKey()]
+	if !ok {
+		c.missCount++
+		return nil
+	}
+
+	element := c.findQuery(query, hashCode)
+	if element == nil {
+		c.missCount++
+		return nil
+	}
+	// this get call moves the query to the most-recently-used position
+	c.mostRecentlyUsedQueries.MoveToBack(element)
+
+	cached := leafCache.get(element.Value.(*cachedQuery))
+	if cached == nil {
+		c.missCount++
+	} else {
+		c.hitCount++
+	}
+	return cached
+}
+
+func (c *LRUQueryCache) putIfAbsent(query index.Query, hashCode int32, set DocIdSet, cacheHelper index.CacheHelper) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element := c.findQuery(query, hashCode)
+	if element == nil {
+		element = c.mostRecentlyUsedQueries.PushBack(&cachedQuery{query: query, hashCode: hashCode})
+		c.uniqueQueries[hashCode] = append(c.uniqueQueries[hashCode], element)
+		c.ramBytesUsed += linkedHashtableRamBytesPerEntry + queryDefaultRamBytesUsed
+	}
+
+	key := cacheHelper.GetKey()
+	leaf, ok := c.cache[key]
+	if !ok {
+		leaf = newLeafCache(key)
+		c.cache[key] = leaf
+		c.ramBytesUsed += hashtableRamBytesPerEntry
+		// we just created a new leaf cache, need to register a close listener
+		cacheHelper.AddClosedListener(&coreClosedListener{cache: c})
+	}
+	leaf.putIfAbsent(c, element.Value.(*cachedQuery), set)
+	c.evictIfNecessary()
+}
+
+func (c *LRUQueryCache) evictIfNecessary() {
+	for c.requiresEviction() {
+		c.onEviction(c.mostRecentlyUsedQueries.Front())
+	}
+}
+
+// onEviction removes the unique query of element and its cached doc id sets.
+func (c *LRUQueryCache) onEviction(element *list.Element) {
+	singleton := element.Value.(*cachedQuery)
+	c.mostRecentlyUsedQueries.Remove(element)
+
+	elements := c.uniqueQueries[singleton.hashCode]
+	for i, e := range elements {
+		if e == element {
+			elements = append(elements[:i], elements[i+1:]...)
+			break
+		}
+	}
+	if len(elements) == 0 {
+		delete(c.uniqueQueries, singleton.hashCode)
+	} else {
+		c.uniqueQueries[singleton.hashCode] = elements
+	}
+
+	c.ramBytesUsed -= linkedHashtableRamBytesPerEntry + queryDefaultRamBytesUsed
+	for _, leaf := range c.cache {
+		leaf.remove(c, singleton)
+	}
+}
+
+// ClearCoreCacheKey
+// Remove all cache entries for the given core cache key.
+func (c *LRUQueryCache) ClearCoreCacheKey(coreKey string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	leaf, ok := c.cache[coreKey]
+	if !ok {
+		return
+	}
+	delete(c.cache, coreKey)
+	c.ramBytesUsed -= hashtableRamBytesPerEntry
+	c.onDocIdSetEviction(len(leaf.cache), leaf.ramBytesUsed)
+}
+
+// ClearQuery
+// Remove all cache entries for the given query.
+func (c *LRUQueryCache) ClearQuery(query index.Query) {
+	hashCode := int32(query.HashCode())
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element := c.findQuery(query, hashCode); element != nil {
+		c.onEviction(element)
+	}
+}
+
+// Clear
+// Clear the content of this cache.
+func (c *LRUQueryCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cache = make(map[string]*leafCache)
+	c.uniqueQueries = make(map[int32][]*list.Element)
+	c.mostRecentlyUsedQueries.Init()
+	c.onClear()
+}
+
+// RamBytesUsed
+// Return the estimated number of bytes used by this cache.
+func (c *LRUQueryCache) RamBytesUsed() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ramBytesUsed
+}
+
+// GetHitCount
+// Over the total number of times that a query has been looked up, return how many times a cached
+// DocIdSet has been found and returned.
+func (c *LRUQueryCache) GetHitCount() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hitCount
+}
+
+// GetMissCount
+// Over the total number of times that a query has been looked up, return how many times this
+// query was not contained in the cache.
+func (c *LRUQueryCache) GetMissCount() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.missCount
+}
+
+// GetTotalCount
+// Return the total number of times that a Query has been looked up in this QueryCache. Note that
+// this number is incremented once per segment so running a cached query only once will increment
+// this counter by the number of segments that are wrapped by the searcher. Note that by definition,
+// GetTotalCount is the sum of GetHitCount and GetMissCount.
+func (c *LRUQueryCache) GetTotalCount() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hitCount + c.missCount
+}
+
+// GetCacheSize
+// Return the total number of DocIdSets which are currently stored in the cache.
+func (c *LRUQueryCache) GetCacheSize() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cacheSize
+}
+
+// GetCacheCount
+// Return the total number of cache entries that have been generated and put in the cache. It is
+// highly desirable to have a hit count that is much higher than the cache count as the opposite
+// would indicate that the query cache makes efforts in order to cache queries but then they do
+// not get reused.
+func (c *LRUQueryCache) GetCacheCount() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cacheCount
+}
+
+// GetEvictionCount
+// Return the number of cache entries that have been removed from the cache either in order to
+// stay under the maximum configured size/ram usage, or because a segment has been closed.
+func (c *LRUQueryCache) GetEvictionCount() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cacheCount - c.cacheSize
+}
+
+func (c *LRUQueryCache) DoCache(weight index.Weight, policy index.QueryCachingPolicy) index.Weight {
+	for {
+		// double caching does not make sense
+		cachingWeight, ok := weight.(*CachingWrapperWeight)
+		if !ok {
+			break
+		}
+		weight = cachingWeight.in
+	}
+	return newCachingWrapperWeight(c, weight, policy)
+}
+
+// Check whether the matches are likely to fit in the cache: the worst case is a bit set over all
+// the documents of the index.
+func (c *LRUQueryCache) cacheEntryHasReasonableWorstCaseSize(maxDoc int) bool {
+	worstCaseRamUsage := int64(maxDoc / 8)
+	// Imagine the worst-case that a cache entry is large than the size of
+	// the cache: not only will this entry be trashed immediately but it
+	// will also evict all current entries from the cache. For this reason
+	// we only cache on an IndexReader if we have available room for
+	// 5 different filters on this reader to avoid excessive trashing
+	return worstCaseRamUsage*5 < c.maxRamBytesUsed
+}
+
+// cacheImpl
+// Default cache implementation: uses a bit set for dense sets and a sorted array of doc ids for
+// sparse sets.
+func (c *LRUQueryCache) cacheImpl(scorer index.BulkScorer, maxDoc int) (DocIdSet, error) {
+	if scorer.Cost()*100 >= int64(maxDoc) {
+		// a bit set is faster for dense sets and will enable the random-access
+		// optimization in ConjunctionDISI
+		return cacheIntoBitSet(scorer, maxDoc)
+	}
+	return cacheIntoIntArray(scorer)
+}
+
+func cacheIntoBitSet(scorer index.BulkScorer, maxDoc int) (DocIdSet, error) {
+	bits := bitset.New(uint(maxDoc))
+	cardinality := 0
+	collector := &LeafCollectorAnon{
+		FnSetScorer: func(scorer index.Scorable) error {
+			return nil
+		},
+		FnCollect: func(ctx context.Context, doc int) error {
+			bits.Set(uint(doc))
+			cardinality++
+			return nil
+		},
+		FnCompetitiveIterator: func() (types.DocIdSetIterator, error) {
+			return nil, nil
+		},
+	}
+	if _, err := scorer.Score(collector, nil, -1, -1); err != nil {
+		return nil, err
+	}
+	return NewBitDocIdSet(bits, int64(cardinality)), nil
+}
+
+func cacheIntoIntArray(scorer index.BulkScorer) (DocIdSet, error) {
+	docs := make([]int, 0, scorer.Cost())
+	collector := &LeafCollectorAnon{
+		FnSetScorer: func(scorer index.Scorable) error {
+			return nil
+		},
+		FnCollect: func(ctx context.Context, doc int) error {
+			docs = append(docs, doc)
+			return nil
+		},
+		FnCompetitiveIterator: func() (types.DocIdSetIterator, error) {
+			return nil, nil
+		},
+	}
+	if _, err := scorer.Score(collector, nil, -1, -1); err != nil {
+		return nil, err
+	}
+	return NewIntArrayDocIdSet(docs), nil
+}
+
+func docIdSetRamBytesUsed(set DocIdSet) int64 {
+	switch set := set.(type) {
+	case *BitDocIdSet:
+		return 32 + 8*int64(len(set.set.Bytes()))
+	case *IntArrayDocIdSet:
+		return 24 + 8*int64(cap(set.docs))
+	default:
+		return 0
+	}
+}
+
+var _ index.ClosedListener = &coreClosedListener{}
+
+// coreClosedListener evicts the entries of a segment core once it is closed.
+type coreClosedListener struct {
+	cache *LRUQueryCache
+}
+
+func (c *coreClosedListener) OnClose(key string) error {
+	c.cache.ClearCoreCacheKey(key)
+	return nil
+}
+
+var _ index.Weight = &CachingWrapperWeight{}
+
+// CachingWrapperWeight
+// The Weight returned by LRUQueryCache.DoCache, it serves the matches of the wrapped weight from
+// the cache once the policy decides that the query is worth caching. Scores are not preserved.
+type CachingWrapperWeight struct {
+	cache  *LRUQueryCache
+	in     index.Weight
+	policy index.QueryCachingPolicy
+
+	// the hash code of the query, computed once per weight
+	hashCode int32
+
+	// we use a sync.Once to ensure that the policy is notified only once per
+	// weight, so that a query that runs on many segments is counted once
+	used sync.Once
+}
+
+func newCachingWrapperWeight(cache *LRUQueryCache, in index.Weight, policy index.QueryCachingPolicy) *CachingWrapperWeight {
+	return &CachingWrapperWeight{
+		cache:    cache,
+		in:       in,
+		policy:   policy,
+		hashCode: int32(in.GetQuery().HashCode()),
+	}
+}
+
+func (w *CachingWrapperWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return w.in.IsCacheable(ctx)
+}
+
+func (w *CachingWrapperWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return w.in.ExtractTerms(terms)
+}
+
+func (w *CachingWrapperWeight) Matches(readerContext index.LeafReaderContext, doc int) (index.Matches, error) {
+	return w.in.Matches(readerContext, doc)
+}
+
+func (w *CachingWrapperWeight) Explain(readerContext index.LeafReaderContext, doc int) (types.Explanation, error) {
+	return w.in.Explain(readerContext, doc)
+}
+
+func (w *CachingWrapperWeight) GetQuery() index.Query {
+	return w.in.GetQuery()
+}
+
+func (w *CachingWrapperWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	supplier, err := w.ScorerSupplier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, nil
+	}
+	return supplier.Get(math.MaxInt64)
+}
+
+func (w *CachingWrapperWeight) shouldCache(ctx index.LeafReaderContext) bool {
+	topLevelContext := coreIndex.GetTopLevelContext(ctx)
+	return w.cache.cacheEntryHasReasonableWorstCaseSize(topLevelContext.Reader().MaxDoc()) &&
+		w.cache.leavesToCache(ctx)
+}
+
+// cacheHelper returns the helper to cache the matches of the segment with, or nil when the
+// segment should not be cached.
+func (w *CachingWrapperWeight) cacheHelper(ctx index.LeafReaderContext) index.CacheHelper {
+	w.used.Do(func() {
+		w.policy.OnUse(w.GetQuery())
+	})
+
+	if !w.in.IsCacheable(ctx) {
+		// this segment is not suitable for caching
+		return nil
+	}
+
+	// Short-circuit: Check whether this segment is eligible for caching
+	// before we take a lock because of get
+	if !w.shouldCache(ctx) {
+		return nil
+	}
+
+	// nil means this reader has no cache helper
+	return ctx.LeafReader().GetCoreCacheHelper()
+}
+
+// cached returns the cached matches of the segment, ok is false if the cache could not be checked
+// because it is busy, in which case the uncached version is preferred over waiting.
+func (w *CachingWrapperWeight) cached(cacheHelper index.CacheHelper) (set DocIdSet, ok bool) {
+	if !w.cache.lock.TryLock() {
+		return nil, false
+	}
+	defer w.cache.lock.Unlock()
+	return w.cache.get(w.GetQuery(), w.hashCode, cacheHelper), true
+}
+
+func (w *CachingWrapperWeight) ScorerSupplier(ctx index.LeafReaderContext) (index.ScorerSupplier, error) {
+	cacheHelper := w.cacheHelper(ctx)
+	if cacheHelper == nil {
+		return w.in.ScorerSupplier(ctx)
+	}
+
+	docIdSet, ok := w.cached(cacheHelper)
+	if !ok {
+		return w.in.ScorerSupplier(ctx)
+	}
+
+	if docIdSet == nil {
+		shouldCache, err := w.policy.ShouldCache(w.GetQuery())
+		if err != nil {
+			return nil, err
+		}
+		if !shouldCache {
+			return w.in.ScorerSupplier(ctx)
+		}
+
+		supplier, err := w.in.ScorerSupplier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if supplier == nil {
+			w.cache.putIfAbsent(w.GetQuery(), w.hashCode, GetEmptyDocIdSet(), cacheHelper)
+			return nil, nil
+		}
+		return &cachingScorerSupplier{
+			weight:      w,
+			in:          supplier,
+			ctx:         ctx,
+			cacheHelper: cacheHelper,
+		}, nil
+	}
+
+	if isEmptyDocIdSet(docIdSet) {
+		return nil, nil
+	}
+	return &scorerSupplier{scorer: w.constantScorer(docIdSet.Iterator())}, nil
+}
+
+func (w *CachingWrapperWeight) BulkScorer(ctx index.LeafReaderContext) (index.BulkScorer, error) {
+	cacheHelper := w.cacheHelper(ctx)
+	if cacheHelper == nil {
+		return w.in.BulkScorer(ctx)
+	}
+
+	docIdSet, ok := w.cached(cacheHelper)
+	if !ok {
+		return w.in.BulkScorer(ctx)
+	}
+
+	if docIdSet == nil {
+		shouldCache, err := w.policy.ShouldCache(w.GetQuery())
+		if err != nil {
+			return nil, err
+		}
+		if !shouldCache {
+			return w.in.BulkScorer(ctx)
+		}
+
+		docIdSet, err = w.cacheSegment(ctx)
+		if err != nil {
+			return nil, err
+		}
+		w.cache.putIfAbsent(w.GetQuery(), w.hashCode, docIdSet, cacheHelper)
+	}
+
+	if isEmptyDocIdSet(docIdSet) {
+		return nil, nil
+	}
+	return NewDefaultBulkScorer(w.constantScorer(docIdSet.Iterator())), nil
+}
+
+// cacheSegment collects all the matches of the wrapped weight on the segment.
+func (w *CachingWrapperWeight) cacheSegment(ctx index.LeafReaderContext) (DocIdSet, error) {
+	scorer, err := w.in.BulkScorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scorer == nil {
+		return GetEmptyDocIdSet(), nil
+	}
+	return w.cache.cacheImpl(scorer, ctx.Reader().MaxDoc())
+}
+
+func (w *CachingWrapperWeight) constantScorer(disi types.DocIdSetIterator) index.Scorer {
+	// NewConstantScoreScorer never fails when given an iterator
+	scorer, _ := NewConstantScoreScorer(w, 0, COMPLETE_NO_SCORES, disi)
+	return scorer
+}
+
+func isEmptyDocIdSet(set DocIdSet) bool {
+	_, ok := set.(*emptyDocIdSet)
+	return ok
+}
+
+var _ index.ScorerSupplier = &cachingScorerSupplier{}
+
+// cachingScorerSupplier caches the matches of the segment when the scorer is pulled, unless the
+// wrapped scorer is only used to verify a few matches of a much cheaper leading clause.
+type cachingScorerSupplier struct {
+	weight      *CachingWrapperWeight
+	in          index.ScorerSupplier
+	ctx         index.LeafReaderContext
+	cacheHelper index.CacheHelper
+}
+
+func (c *cachingScorerSupplier) Get(leadCost int64) (index.Scorer, error) {
+	// skip cache operation which would slow query down too much
+	if float64(c.in.Cost())/c.weight.cache.skipCacheFactor > float64(leadCost) {
+		return c.in.Get(leadCost)
+	}
+
+	scorer, err := c.in.Get(math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	docIdSet, err := c.weight.cache.cacheImpl(NewDefaultBulkScorer(scorer), c.ctx.Reader().MaxDoc())
+	if err != nil {
+		return nil, err
+	}
+	c.weight.cache.putIfAbsent(c.weight.GetQuery(), c.weight.hashCode, docIdSet, c.cacheHelper)
+	return c.weight.constantScorer(docIdSet.Iterator()), nil
+}
+
+func (c *cachingScorerSupplier) Cost() int64 {
+	return c.in.Cost()
+}
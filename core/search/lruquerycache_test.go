@@ -0,0 +1,158 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// alwaysCachePolicy Caches every query on its first use
+type alwaysCachePolicy struct{}
+
+func (alwaysCachePolicy) OnUse(query index.Query) {}
+
+func (alwaysCachePolicy) ShouldCache(query index.Query) (bool, error) {
+	return true, nil
+}
+
+func cacheAllLeaves(ctx index.LeafReaderContext) bool {
+	return true
+}
+
+// newCachingSearcher Opens a searcher over two segments which caches its filters in cache
+func newCachingSearcher(t *testing.T, cache index.QueryCache) (index.IndexReader, *search.IndexSearcher) {
+	reader := searchtest.NewReader(t,
+		searchtest.TextDocs("body", "quick fox", "lazy dog", "quick dog"),
+		searchtest.TextDocs("body", "quick cat", "lazy fox"))
+	searcher, err := search.NewIndexSearcher(reader)
+	assert.Nil(t, err)
+	searcher.SetQueryCache(cache)
+	searcher.SetQueryCachingPolicy(alwaysCachePolicy{})
+	return reader, searcher.(*search.IndexSearcher)
+}
+
+// countHits Runs query without scores, so that it is cached
+func countHits(t *testing.T, searcher *search.IndexSearcher, text string) int {
+	collector := search.NewTotalHitCountCollector()
+	query := search.NewTermQuery(coreIndex.NewTerm("body", []byte(text)))
+	assert.Nil(t, searcher.SearchCollector(context.Background(), query, collector))
+	return collector.GetTotalHits()
+}
+
+func TestLRUQueryCache_HitsAndMisses(t *testing.T) {
+	cache := search.NewLRUQueryCache(10, 1<<20, search.WithLeavesToCache(cacheAllLeaves))
+	_, searcher := newCachingSearcher(t, cache)
+
+	// every segment is looked up once
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, int64(0), cache.GetHitCount())
+	assert.Equal(t, int64(2), cache.GetMissCount())
+	assert.Equal(t, int64(2), cache.GetCacheCount())
+	assert.Equal(t, int64(2), cache.GetCacheSize())
+
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, int64(2), cache.GetHitCount())
+	assert.Equal(t, int64(2), cache.GetMissCount())
+	assert.Equal(t, int64(4), cache.GetTotalCount())
+	assert.Equal(t, int64(2), cache.GetCacheCount())
+
+	assert.Equal(t, 2, countHits(t, searcher, "lazy"))
+	assert.Equal(t, int64(4), cache.GetMissCount())
+	assert.Equal(t, int64(4), cache.GetCacheSize())
+	assert.Equal(t, int64(0), cache.GetEvictionCount())
+
+	cache.ClearQuery(search.NewTermQuery(coreIndex.NewTerm("body", []byte("quick"))))
+	assert.Equal(t, int64(2), cache.GetCacheSize())
+	assert.Equal(t, int64(2), cache.GetEvictionCount())
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, int64(6), cache.GetMissCount())
+
+	cache.Clear()
+	assert.Equal(t, int64(0), cache.GetCacheSize())
+	assert.Equal(t, int64(0), cache.RamBytesUsed())
+}
+
+func TestLRUQueryCache_LeavesToCache(t *testing.T) {
+	// by default only segments of at least 10000 documents are cached
+	cache := search.NewLRUQueryCache(10, 1<<20)
+	_, searcher := newCachingSearcher(t, cache)
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, int64(0), cache.GetTotalCount())
+	assert.Equal(t, int64(0), cache.GetCacheCount())
+
+	// only the first segment is accepted
+	cache = search.NewLRUQueryCache(10, 1<<20, search.WithLeavesToCache(func(ctx index.LeafReaderContext) bool {
+		return ctx.Reader().MaxDoc() == 3
+	}))
+	_, searcher = newCachingSearcher(t, cache)
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, 3, countHits(t, searcher, "quick"))
+	assert.Equal(t, int64(1), cache.GetHitCount())
+	assert.Equal(t, int64(1), cache.GetMissCount())
+	assert.Equal(t, int64(1), cache.GetCacheSize())
+}
+
+func TestLRUQueryCache_EvictionByCount(t *testing.T) {
+	cache := search.NewLRUQueryCache(2, 1<<20, search.WithLeavesToCache(cacheAllLeaves))
+	_, searcher := newCachingSearcher(t, cache)
+
+	countHits(t, searcher, "quick")
+	countHits(t, searcher, "lazy")
+	// quick becomes the most recently used query
+	countHits(t, searcher, "quick")
+	assert.Equal(t, int64(2), cache.GetHitCount())
+
+	// the least recently used query is evicted
+	assert.Equal(t, 2, countHits(t, searcher, "fox"))
+	assert.Equal(t, int64(4), cache.GetCacheSize())
+	assert.Equal(t, int64(2), cache.GetEvictionCount())
+
+	countHits(t, searcher, "quick")
+	assert.Equal(t, int64(4), cache.GetHitCount())
+	misses := cache.GetMissCount()
+	countHits(t, searcher, "lazy")
+	assert.Equal(t, misses+2, cache.GetMissCount())
+}
+
+func TestLRUQueryCache_EvictionByRam(t *testing.T) {
+	cache := search.NewLRUQueryCache(10, 1<<20, search.WithLeavesToCache(cacheAllLeaves))
+	_, searcher := newCachingSearcher(t, cache)
+	countHits(t, searcher, "quick")
+	oneQuery := cache.RamBytesUsed()
+	assert.Greater(t, oneQuery, int64(0))
+
+	// room for a single query
+	cache = search.NewLRUQueryCache(10, oneQuery*3/2, search.WithLeavesToCache(cacheAllLeaves))
+	_, searcher = newCachingSearcher(t, cache)
+	countHits(t, searcher, "quick")
+	assert.Equal(t, oneQuery, cache.RamBytesUsed())
+
+	countHits(t, searcher, "lazy")
+	assert.LessOrEqual(t, cache.RamBytesUsed(), oneQuery*3/2)
+	assert.Equal(t, int64(2), cache.GetCacheSize())
+	assert.Equal(t, int64(2), cache.GetEvictionCount())
+
+	hits := cache.GetHitCount()
+	countHits(t, searcher, "lazy")
+	assert.Equal(t, hits+2, cache.GetHitCount())
+}
+
+func TestLRUQueryCache_ClearedOnClose(t *testing.T) {
+	cache := search.NewLRUQueryCache(10, 1<<20, search.WithLeavesToCache(cacheAllLeaves))
+	reader, searcher := newCachingSearcher(t, cache)
+
+	countHits(t, searcher, "quick")
+	countHits(t, searcher, "lazy")
+	assert.Equal(t, int64(4), cache.GetCacheSize())
+
+	// closing the segments drops their entries
+	assert.Nil(t, reader.Close())
+	assert.Equal(t, int64(0), cache.GetCacheSize())
+	assert.Equal(t, int64(4), cache.GetEvictionCount())
+}
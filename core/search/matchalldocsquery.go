@@ -35,6 +35,15 @@ func (m *MatchAllDocsQuery) Visit(visitor index.QueryVisitor) error {
 	return visitor.VisitLeaf(m)
 }
 
+func (m *MatchAllDocsQuery) Equals(other index.Query) bool {
+	_, ok := other.(*MatchAllDocsQuery)
+	return ok
+}
+
+func (m *MatchAllDocsQuery) HashCode() int {
+	return ClassHash("MatchAllDocsQuery")
+}
+
 var _ index.Weight = &matchAllDocsWeight{}
 
 type matchAllDocsWeight struct {
@@ -1,7 +1,9 @@
 package search
 
 import (
+	"github.com/geange/gods-generic/sets/treeset"
 	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
 )
 
 var _ index.Query = &MatchNoDocsQuery{}
@@ -17,21 +19,58 @@ func NewMatchNoDocsQuery(reason string) *MatchNoDocsQuery {
 }
 
 func (m *MatchNoDocsQuery) String(field string) string {
-	//TODO implement me
-	panic("implement me")
+	return "MatchNoDocsQuery(\"" + m.reason + "\")"
 }
 
 func (m *MatchNoDocsQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
-	//TODO implement me
-	panic("implement me")
+	return newMatchNoDocsWeight(m), nil
 }
 
 func (m *MatchNoDocsQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
-	//TODO implement me
-	panic("implement me")
+	return m, nil
 }
 
 func (m *MatchNoDocsQuery) Visit(visitor index.QueryVisitor) (err error) {
-	//TODO implement me
-	panic("implement me")
+	return visitor.VisitLeaf(m)
+}
+
+// Equals
+// The reason is only informative, all the MatchNoDocsQuery are equal.
+func (m *MatchNoDocsQuery) Equals(other index.Query) bool {
+	_, ok := other.(*MatchNoDocsQuery)
+	return ok
+}
+
+func (m *MatchNoDocsQuery) HashCode() int {
+	return ClassHash("MatchNoDocsQuery")
+}
+
+var _ index.Weight = &matchNoDocsWeight{}
+
+type matchNoDocsWeight struct {
+	*BaseWeight
+
+	reason string
+}
+
+func newMatchNoDocsWeight(query *MatchNoDocsQuery) *matchNoDocsWeight {
+	weight := &matchNoDocsWeight{reason: query.reason}
+	weight.BaseWeight = NewBaseWeight(query, weight)
+	return weight
+}
+
+func (w *matchNoDocsWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return nil
+}
+
+func (w *matchNoDocsWeight) Explain(ctx index.LeafReaderContext, doc int) (types.Explanation, error) {
+	return types.ExplanationNoMatch(w.reason), nil
+}
+
+func (w *matchNoDocsWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	return nil, nil
+}
+
+func (w *matchNoDocsWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return true
 }
@@ -69,7 +69,7 @@ func MatchesForField(field string, mis IOSupplier[index.MatchesIterator]) index.
 
 	return &forFieldMatches{
 		mis:    mis,
-		cached: false,
+		cached: true,
 		field:  field,
 		mi:     mi,
 	}
@@ -89,10 +89,10 @@ func (f *forFieldMatches) Strings() []string {
 }
 
 func (f *forFieldMatches) GetMatches(field string) (index.MatchesIterator, error) {
-	if field == f.field {
+	if field != f.field {
 		return nil, nil
 	}
-	if f.cached == false {
+	if !f.cached {
 		return f.mis.Get()
 	}
 	f.cached = false
@@ -0,0 +1,423 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Scorer = &MinShouldMatchSumScorer{}
+
+// MinShouldMatchSumScorer
+// A Scorer for OR like queries, counterpart of ConjunctionScorer. This Scorer implements
+// Scorer.AdvanceShallow(int) and uses AdvanceShallow only on its sub-scorers.
+//
+// This implementation uses the minimumMatchers constraint actively to efficiently prune the
+// number of candidates, it is hence a mixture between a pure DisjunctionScorer and a
+// ConjunctionScorer.
+//
+// This Scorer keeps sub scorers in 3 different places:
+//   - lead: a linked list of scorer that are positioned on the desired doc ID
+//   - tail: a heap that contains at most minShouldMatch - 1 scorers that are behind the desired
+//     doc ID. These scorers are ordered by cost so that we can advance the least costly ones first.
+//   - head: a heap that contains scorers which are beyond the desired doc ID, ordered by doc ID
+//     in order to move quickly to the next candidate.
+//
+// Finding the next match consists of first setting the desired doc ID to the least entry in
+// 'head' and then advance 'tail' until there is a match.
+type MinShouldMatchSumScorer struct {
+	*BaseScorer
+
+	minShouldMatch int
+
+	// list of scorers which 'lead' the iteration and are currently
+	// positioned on 'doc'
+	lead *DisiWrapper
+	doc  int // current doc ID of the leads
+	freq int // number of scorers on the desired doc ID
+
+	// priority queue of scorers that are too advanced compared to the current
+	// doc. Ordered by doc ID.
+	head *DisiPriorityQueue
+
+	// priority queue of scorers which are behind the current doc.
+	// Ordered by cost.
+	tail     []*DisiWrapper
+	tailSize int
+
+	cost int64
+}
+
+func NewMinShouldMatchSumScorer(weight index.Weight, scorers []index.Scorer, minShouldMatch int) (*MinShouldMatchSumScorer, error) {
+	if minShouldMatch > len(scorers) {
+		return nil, errors.New("minShouldMatch should be <= the number of scorers")
+	}
+	if minShouldMatch < 1 {
+		return nil, errors.New("minShouldMatch should be >= 1")
+	}
+
+	scorer := &MinShouldMatchSumScorer{
+		BaseScorer:     NewScorer(weight),
+		minShouldMatch: minShouldMatch,
+		doc:            -1,
+		head:           NewDisiPriorityQueue(len(scorers) - minShouldMatch + 1),
+		// there can be at most minShouldMatch - 1 scorers beyond the current position
+		// otherwise we might be skipping over matching documents
+		tail: make([]*DisiWrapper, minShouldMatch-1),
+	}
+
+	costs := make([]int64, 0, len(scorers))
+	for _, subScorer := range scorers {
+		w := NewDisiWrapper(subScorer)
+		costs = append(costs, w.cost)
+		scorer.addLead(w)
+	}
+	scorer.cost = costWithMinShouldMatch(costs, len(scorers), minShouldMatch)
+	return scorer, nil
+}
+
+func (m *MinShouldMatchSumScorer) GetChildren() ([]index.ChildScorable, error) {
+	if err := m.updateFreq(); err != nil {
+		return nil, err
+	}
+	children := make([]index.ChildScorable, 0, m.freq)
+	for s := m.lead; s != nil; s = s.next {
+		children = append(children, NewChildScorable(s.scorer, "SHOULD"))
+	}
+	return children, nil
+}
+
+func (m *MinShouldMatchSumScorer) Iterator() types.DocIdSetIterator {
+	return AsDocIdSetIterator(m.TwoPhaseIterator())
+}
+
+func (m *MinShouldMatchSumScorer) TwoPhaseIterator() index.TwoPhaseIterator {
+	return &minShouldMatchTwoPhase{
+		scorer:        m,
+		approximation: &minShouldMatchApproximation{scorer: m},
+	}
+}
+
+func (m *MinShouldMatchSumScorer) Score() (float64, error) {
+	// we need to know about all matches
+	if err := m.updateFreq(); err != nil {
+		return 0, err
+	}
+	score := 0.0
+	for s := m.lead; s != nil; s = s.next {
+		v, err := s.scorer.Score()
+		if err != nil {
+			return 0, err
+		}
+		score += v
+	}
+	return score, nil
+}
+
+func (m *MinShouldMatchSumScorer) GetMaxScore(upTo int) (float64, error) {
+	// TODO: implement but be careful about floating-point errors.
+	return math.Inf(1), nil
+}
+
+func (m *MinShouldMatchSumScorer) DocID() int {
+	return m.doc
+}
+
+func (m *MinShouldMatchSumScorer) addLead(lead *DisiWrapper) {
+	lead.next = m.lead
+	m.lead = lead
+	m.freq++
+}
+
+func (m *MinShouldMatchSumScorer) pushBackLeads() {
+	for s := m.lead; s != nil; s = s.next {
+		m.addTail(s)
+	}
+}
+
+func (m *MinShouldMatchSumScorer) advanceTailTo(top *DisiWrapper) error {
+	if err := advanceWrapper(top, m.doc); err != nil {
+		return err
+	}
+	if top.doc == m.doc {
+		m.addLead(top)
+	} else {
+		m.head.Add(top)
+	}
+	return nil
+}
+
+func (m *MinShouldMatchSumScorer) advanceTail() error {
+	return m.advanceTailTo(m.popTail())
+}
+
+// setDocAndFreq
+// Reinitializes head, freq and doc from 'head'
+func (m *MinShouldMatchSumScorer) setDocAndFreq() {
+	// The top of `head` defines the next potential match
+	// pop all documents which are on this doc
+	m.lead = m.head.Pop()
+	m.lead.next = nil
+	m.freq = 1
+	m.doc = m.lead.doc
+	for m.head.Size() > 0 && m.head.Top().doc == m.doc {
+		m.addLead(m.head.Pop())
+	}
+}
+
+// doNext
+// Advance tail to the lead until there is a match.
+func (m *MinShouldMatchSumScorer) doNext() (int, error) {
+	for m.freq < m.minShouldMatch {
+		if m.freq+m.tailSize >= m.minShouldMatch {
+			// a match on doc is still possible, try to
+			// advance scorers from the tail
+			if err := m.advanceTail(); err != nil {
+				return 0, err
+			}
+		} else {
+			// no match on doc is possible anymore, move to the next potential match
+			m.pushBackLeads()
+			m.setDocAndFreq()
+		}
+	}
+	return m.currentDoc()
+}
+
+// doNextCandidate
+// Move iterators to the tail until the cumulated size of lead+tail is greater than or equal
+// to minShouldMath
+func (m *MinShouldMatchSumScorer) doNextCandidate() (int, error) {
+	for m.freq+m.tailSize < m.minShouldMatch {
+		// no match on doc is possible, move to the next potential match
+		m.pushBackLeads()
+		m.setDocAndFreq()
+	}
+	return m.currentDoc()
+}
+
+func (m *MinShouldMatchSumScorer) currentDoc() (int, error) {
+	if m.doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	return m.doc, nil
+}
+
+// updateFreq
+// Advance all entries from the tail to know about all matches on the current doc.
+func (m *MinShouldMatchSumScorer) updateFreq() error {
+	// we return the next doc when there are minShouldMatch matching clauses
+	// but some of the clauses in 'tail' might match as well
+	// in general we want to advance least-costly clauses first in order to
+	// skip over non-matching documents as fast as possible. However here,
+	// we are advancing everything anyway so iterating over clauses in
+	// (roughly) cost-descending order might help avoid some permutations in
+	// the head heap
+	for i := m.tailSize - 1; i >= 0; i-- {
+		if err := m.advanceTailTo(m.tail[i]); err != nil {
+			return err
+		}
+	}
+	m.tailSize = 0
+	return nil
+}
+
+// insertTailWithOverFlow
+// Insert an entry in 'tail' and evict the least-costly scorer if full.
+func (m *MinShouldMatchSumScorer) insertTailWithOverFlow(s *DisiWrapper) *DisiWrapper {
+	if m.tailSize < len(m.tail) {
+		m.addTail(s)
+		return nil
+	} else if len(m.tail) >= 1 {
+		top := m.tail[0]
+		if top.cost < s.cost {
+			m.tail[0] = s
+			downHeapCost(m.tail, m.tailSize)
+			return top
+		}
+	}
+	return s
+}
+
+// addTail
+// Add an entry to 'tail'. Fails if over capacity.
+func (m *MinShouldMatchSumScorer) addTail(s *DisiWrapper) {
+	m.tail[m.tailSize] = s
+	upHeapCost(m.tail, m.tailSize)
+	m.tailSize++
+}
+
+// popTail
+// Pop the least-costly scorer from 'tail'.
+func (m *MinShouldMatchSumScorer) popTail() *DisiWrapper {
+	result := m.tail[0]
+	m.tailSize--
+	m.tail[0] = m.tail[m.tailSize]
+	downHeapCost(m.tail, m.tailSize)
+	return result
+}
+
+// advanceWrapper
+// Advances the iterator of w to target, an exhausted iterator is on NO_MORE_DOCS.
+func advanceWrapper(w *DisiWrapper, target int) error {
+	doc, err := w.iterator.Advance(context.Background(), target)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		doc = types.NO_MORE_DOCS
+	}
+	w.doc = doc
+	return nil
+}
+
+func upHeapCost(heap []*DisiWrapper, i int) {
+	node := heap[i]
+	nodeCost := node.cost
+	j := parentNode(i)
+	for j >= 0 && nodeCost < heap[j].cost {
+		heap[i] = heap[j]
+		i = j
+		j = parentNode(j)
+	}
+	heap[i] = node
+}
+
+func downHeapCost(heap []*DisiWrapper, size int) {
+	i := 0
+	node := heap[0]
+	j := leftNode(i)
+	if j < size {
+		k := rightNode(j)
+		if k < size && heap[k].cost < heap[j].cost {
+			j = k
+		}
+		if heap[j].cost < node.cost {
+			for {
+				heap[i] = heap[j]
+				i = j
+				j = leftNode(i)
+				k = rightNode(j)
+				if k < size && heap[k].cost < heap[j].cost {
+					j = k
+				}
+				if j >= size || heap[j].cost >= node.cost {
+					break
+				}
+			}
+			heap[i] = node
+		}
+	}
+}
+
+var _ types.DocIdSetIterator = &minShouldMatchApproximation{}
+
+// minShouldMatchApproximation
+// Iterates over the docs where at least minShouldMatch scorers could match, matches are
+// confirmed by minShouldMatchTwoPhase.
+type minShouldMatchApproximation struct {
+	scorer *MinShouldMatchSumScorer
+}
+
+func (a *minShouldMatchApproximation) DocID() int {
+	return a.scorer.doc
+}
+
+func (a *minShouldMatchApproximation) NextDoc(ctx context.Context) (int, error) {
+	m := a.scorer
+	// We are moving to the next doc ID, so scorers in 'lead' need to go in
+	// 'tail'. If there is not enough space in 'tail', then we take the least
+	// costly scorers and advance them.
+	for s := m.lead; s != nil; s = s.next {
+		evicted := m.insertTailWithOverFlow(s)
+		if evicted != nil {
+			if err := advanceWrapper(evicted, m.doc+1); err != nil {
+				return 0, err
+			}
+			m.head.Add(evicted)
+		}
+	}
+
+	m.setDocAndFreq()
+	// It would be correct to return doNextCandidate() at this point but if you
+	// call nextDoc as opposed to advance, it probably means that you really
+	// need the next match. Returning 'doc' here would lead to a similar
+	// iteration over sub postings overall except that the decision making would
+	// happen at a higher level where more abstractions are involved and
+	// benchmarks suggested it causes a significant performance hit.
+	return m.doNext()
+}
+
+func (a *minShouldMatchApproximation) Advance(ctx context.Context, target int) (int, error) {
+	m := a.scorer
+	// Same logic as in nextDoc
+	for s := m.lead; s != nil; s = s.next {
+		evicted := m.insertTailWithOverFlow(s)
+		if evicted != nil {
+			if err := advanceWrapper(evicted, target); err != nil {
+				return 0, err
+			}
+			m.head.Add(evicted)
+		}
+	}
+
+	// But this time there might also be scorers in 'head' behind the desired
+	// target so we need to do the same thing that we did on 'lead' on 'head'
+	headTop := m.head.Top()
+	for headTop.doc < target {
+		evicted := m.insertTailWithOverFlow(headTop)
+		// We know that the tail is full since it contains at most
+		// minShouldMatch - 1 entries and we just moved at least minShouldMatch
+		// entries to it, so evicted is not null
+		if err := advanceWrapper(evicted, target); err != nil {
+			return 0, err
+		}
+		headTop = m.head.UpdateTopWith(evicted)
+	}
+
+	m.setDocAndFreq()
+	return m.doNextCandidate()
+}
+
+func (a *minShouldMatchApproximation) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, a, target)
+}
+
+func (a *minShouldMatchApproximation) Cost() int64 {
+	return a.scorer.cost
+}
+
+var _ index.TwoPhaseIterator = &minShouldMatchTwoPhase{}
+
+type minShouldMatchTwoPhase struct {
+	scorer        *MinShouldMatchSumScorer
+	approximation *minShouldMatchApproximation
+}
+
+func (t *minShouldMatchTwoPhase) Approximation() types.DocIdSetIterator {
+	return t.approximation
+}
+
+func (t *minShouldMatchTwoPhase) Matches() (bool, error) {
+	m := t.scorer
+	for m.freq < m.minShouldMatch {
+		if m.freq+m.tailSize < m.minShouldMatch {
+			return false, nil
+		}
+		// a match on doc is still possible, try to
+		// advance scorers from the tail
+		if err := m.advanceTail(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (t *minShouldMatchTwoPhase) MatchCost() float64 {
+	// maximum number of scorer that matches() might advance
+	return float64(len(t.scorer.tail))
+}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"errors"
+
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
@@ -17,41 +19,94 @@ type MultiLeafFieldComparator struct {
 	firstReverseMul int
 }
 
-func NewMultiLeafFieldComparator(comparators []index.LeafFieldComparator, reverseMul []int) *MultiLeafFieldComparator {
-	panic("")
+func NewMultiLeafFieldComparator(comparators []index.LeafFieldComparator, reverseMul []int) (*MultiLeafFieldComparator, error) {
+	if len(comparators) != len(reverseMul) {
+		return nil, errors.New("must have the same number of comparators and reverseMul")
+	}
+	if len(comparators) == 0 {
+		return nil, errors.New("must have at least one comparator")
+	}
+	return &MultiLeafFieldComparator{
+		comparators:     comparators,
+		reverseMul:      reverseMul,
+		firstComparator: comparators[0],
+		firstReverseMul: reverseMul[0],
+	}, nil
 }
 
 func (m *MultiLeafFieldComparator) SetBottom(slot int) error {
-	//TODO implement me
-	panic("implement me")
+	for _, comparator := range m.comparators {
+		if err := comparator.SetBottom(slot); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *MultiLeafFieldComparator) CompareBottom(doc int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	cmp, err := m.firstComparator.CompareBottom(doc)
+	if err != nil {
+		return 0, err
+	}
+	if cmp = m.firstReverseMul * cmp; cmp != 0 {
+		return cmp, nil
+	}
+	for i := 1; i < len(m.comparators); i++ {
+		cmp, err := m.comparators[i].CompareBottom(doc)
+		if err != nil {
+			return 0, err
+		}
+		if cmp = m.reverseMul[i] * cmp; cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
 }
 
 func (m *MultiLeafFieldComparator) CompareTop(doc int) (int, error) {
-	//TODO implement me
-	panic("implement me")
+	cmp, err := m.firstComparator.CompareTop(doc)
+	if err != nil {
+		return 0, err
+	}
+	if cmp = m.firstReverseMul * cmp; cmp != 0 {
+		return cmp, nil
+	}
+	for i := 1; i < len(m.comparators); i++ {
+		cmp, err := m.comparators[i].CompareTop(doc)
+		if err != nil {
+			return 0, err
+		}
+		if cmp = m.reverseMul[i] * cmp; cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
 }
 
 func (m *MultiLeafFieldComparator) Copy(slot, doc int) error {
-	//TODO implement me
-	panic("implement me")
+	for _, comparator := range m.comparators {
+		if err := comparator.Copy(slot, doc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *MultiLeafFieldComparator) SetScorer(scorer index.Scorable) error {
-	//TODO implement me
-	panic("implement me")
+	for _, comparator := range m.comparators {
+		if err := comparator.SetScorer(scorer); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *MultiLeafFieldComparator) CompetitiveIterator() (types.DocIdSetIterator, error) {
-	//TODO implement me
-	panic("implement me")
+	// skipping functionality is only relevant for the first comparator
+	return m.firstComparator.CompetitiveIterator()
 }
 
 func (m *MultiLeafFieldComparator) SetHitsThresholdReached() error {
-	//TODO implement me
-	panic("implement me")
+	// this is needed for skipping functionality that is only relevant for the 1st comparator
+	return m.firstComparator.SetHitsThresholdReached()
 }
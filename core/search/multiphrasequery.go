@@ -0,0 +1,403 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Query = &MultiPhraseQuery{}
+
+// MultiPhraseQuery
+// A generalized version of PhraseQuery, with the possibility of adding more than one term at
+// the same position that are treated as a disjunction (OR). To use this class to search for the
+// phrase "Microsoft app*" first create a MultiPhraseQueryBuilder and use
+// builder.Add(term) on the term "microsoft" (assuming lowercase analysis), then find all terms
+// that have "app" as prefix using LeafReader.Terms(string), seeking to "app" then iterating and
+// collecting terms until there is no longer that prefix, and finally use builder.AddTerms(terms)
+// to add them. builder.Build() returns the fully constructed (and immutable) MultiPhraseQuery.
+type MultiPhraseQuery struct {
+	field      string // could be empty if there are no terms
+	termArrays [][]index.Term
+	positions  []int
+	slop       int
+}
+
+// MultiPhraseQueryBuilder
+// A builder for multi-phrase queries
+type MultiPhraseQueryBuilder struct {
+	field      string // becomes non-empty on first Add() then is unmodified
+	termArrays [][]index.Term
+	positions  []int
+	slop       int
+	errs       []error
+}
+
+func NewMultiPhraseQueryBuilder() *MultiPhraseQueryBuilder {
+	return &MultiPhraseQueryBuilder{
+		termArrays: make([][]index.Term, 0),
+		positions:  make([]int, 0),
+		errs:       make([]error, 0),
+	}
+}
+
+// NewMultiPhraseQueryBuilderFrom
+// Copy constructor: this will create a builder that has the same configuration as the provided
+// builder.
+func NewMultiPhraseQueryBuilderFrom(query *MultiPhraseQuery) *MultiPhraseQueryBuilder {
+	termArrays := make([][]index.Term, 0, len(query.termArrays))
+	for _, terms := range query.termArrays {
+		termArrays = append(termArrays, slices.Clone(terms))
+	}
+	return &MultiPhraseQueryBuilder{
+		field:      query.field,
+		termArrays: termArrays,
+		positions:  slices.Clone(query.positions),
+		slop:       query.slop,
+		errs:       make([]error, 0),
+	}
+}
+
+// SetSlop
+// Sets the phrase slop for this query.
+// See Also: PhraseQuery.GetSlop()
+func (b *MultiPhraseQueryBuilder) SetSlop(slop int) *MultiPhraseQueryBuilder {
+	if slop < 0 {
+		b.errs = append(b.errs, errors.New("slop value cannot be negative"))
+		return b
+	}
+	b.slop = slop
+	return b
+}
+
+// Add
+// Add a single term at the next position in the phrase.
+func (b *MultiPhraseQueryBuilder) Add(term index.Term) *MultiPhraseQueryBuilder {
+	return b.AddTerms([]index.Term{term})
+}
+
+// AddTerms
+// Add multiple terms at the next position in the phrase. Any of the terms may match (a disjunction).
+// The array is not copied or mutated, the caller should consider it immutable subsequent to calling
+// this method.
+func (b *MultiPhraseQueryBuilder) AddTerms(terms []index.Term) *MultiPhraseQueryBuilder {
+	position := 0
+	if len(b.positions) > 0 {
+		position = b.positions[len(b.positions)-1] + 1
+	}
+	return b.AddTermsWithPosition(terms, position)
+}
+
+// AddTermsWithPosition
+// Allows to specify the relative position of terms within the phrase. The array is not copied
+// or mutated, the caller should consider it immutable subsequent to calling this method.
+func (b *MultiPhraseQueryBuilder) AddTermsWithPosition(terms []index.Term, position int) *MultiPhraseQueryBuilder {
+	if len(terms) == 0 {
+		b.errs = append(b.errs, errors.New("term array must not be empty"))
+		return b
+	}
+	if len(b.termArrays) == 0 {
+		b.field = terms[0].Field()
+	}
+	for _, term := range terms {
+		if term.Field() != b.field {
+			b.errs = append(b.errs, fmt.Errorf("all phrase terms must be in the same field (%s): %s:%s",
+				b.field, term.Field(), term.Text()))
+			return b
+		}
+	}
+	b.termArrays = append(b.termArrays, slices.Clone(terms))
+	b.positions = append(b.positions, position)
+	return b
+}
+
+// Build
+// Builds a MultiPhraseQuery.
+func (b *MultiPhraseQueryBuilder) Build() (*MultiPhraseQuery, error) {
+	if len(b.errs) != 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	termArrays := make([][]index.Term, 0, len(b.termArrays))
+	for _, terms := range b.termArrays {
+		termArrays = append(termArrays, slices.Clone(terms))
+	}
+	return &MultiPhraseQuery{
+		field:      b.field,
+		termArrays: termArrays,
+		positions:  slices.Clone(b.positions),
+		slop:       b.slop,
+	}, nil
+}
+
+// GetSlop
+// Sets the phrase slop for this query.
+// See Also: PhraseQuery.GetSlop()
+func (m *MultiPhraseQuery) GetSlop() int {
+	return m.slop
+}
+
+// GetTermArrays
+// Returns the arrays of arrays of terms in the multi-phrase. Do not modify!
+func (m *MultiPhraseQuery) GetTermArrays() [][]index.Term {
+	return m.termArrays
+}
+
+// GetPositions
+// Returns the relative positions of terms in this phrase. Do not modify!
+func (m *MultiPhraseQuery) GetPositions() []int {
+	return m.positions
+}
+
+func (m *MultiPhraseQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	switch len(m.termArrays) {
+	case 0:
+		return NewMatchNoDocsQuery("empty MultiPhraseQuery"), nil
+	case 1: // optimize one-term case
+		builder := NewBooleanQueryBuilder()
+		for _, term := range m.termArrays[0] {
+			builder.AddQuery(NewTermQuery(term), index.OccurShould)
+		}
+		return builder.Build()
+	default:
+		return m, nil
+	}
+}
+
+func (m *MultiPhraseQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(m.field) {
+		return nil
+	}
+	v := visitor.GetSubVisitor(index.OccurMust, m)
+	for _, terms := range m.termArrays {
+		sv := v.GetSubVisitor(index.OccurShould, m)
+		sv.ConsumeTerms(m, terms...)
+	}
+	return nil
+}
+
+func (m *MultiPhraseQuery) Equals(other index.Query) bool {
+	o, ok := other.(*MultiPhraseQuery)
+	return ok &&
+		m.slop == o.slop &&
+		m.field == o.field &&
+		slices.EqualFunc(m.termArrays, o.termArrays, termsEqual) &&
+		slices.Equal(m.positions, o.positions)
+}
+
+func (m *MultiPhraseQuery) HashCode() int {
+	h := ClassHash("MultiPhraseQuery")
+	h = 31*h + m.slop
+	for _, terms := range m.termArrays {
+		h = 31*h + termsHashCode(terms)
+	}
+	for _, position := range m.positions {
+		h = 31*h + position
+	}
+	return h
+}
+
+func (m *MultiPhraseQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if m.field != field {
+		buf.WriteString(m.field)
+		buf.WriteString(":")
+	}
+
+	buf.WriteString(`"`)
+	lastPos := -1
+	for i, terms := range m.termArrays {
+		position := m.positions[i]
+		if i != 0 {
+			buf.WriteString(" ")
+			for j := 1; j < position-lastPos; j++ {
+				buf.WriteString("? ")
+			}
+		}
+		if len(terms) > 1 {
+			buf.WriteString("(")
+			for j, term := range terms {
+				buf.WriteString(term.Text())
+				if j < len(terms)-1 {
+					buf.WriteString(" ")
+				}
+			}
+			buf.WriteString(")")
+		} else {
+			buf.WriteString(terms[0].Text())
+		}
+		lastPos = position
+	}
+	buf.WriteString(`"`)
+
+	if m.slop != 0 {
+		buf.WriteString("~")
+		buf.WriteString(strconv.Itoa(m.slop))
+	}
+	return buf.String()
+}
+
+func (m *MultiPhraseQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	weight := &multiPhraseWeight{
+		query:      m,
+		scoreMode:  scoreMode,
+		boost:      boost,
+		termStates: make(map[string]*coreIndex.TermStates),
+	}
+	phraseWeight, err := NewPhraseWeight(m, m.field, searcher, scoreMode, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.PhraseWeight = phraseWeight
+	return weight, nil
+}
+
+var _ PhraseWeightSPI = &multiPhraseWeight{}
+
+type multiPhraseWeight struct {
+	*PhraseWeight
+
+	query     *MultiPhraseQuery
+	scoreMode index.ScoreMode
+	boost     float64
+
+	// all the terms share the field of the query, they are keyed by their text
+	termStates map[string]*coreIndex.TermStates
+}
+
+func (r *multiPhraseWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	for _, arr := range r.query.termArrays {
+		for _, term := range arr {
+			terms.Add(term)
+		}
+	}
+	return nil
+}
+
+func (r *multiPhraseWeight) GetStats(searcher index.IndexSearcher) (index.SimScorer, error) {
+	context := searcher.GetTopReaderContext()
+
+	// compute idf
+	allTermStats := make([]types.TermStatistics, 0)
+	for _, terms := range r.query.termArrays {
+		for _, term := range terms {
+			ts, ok := r.termStates[term.Text()]
+			if !ok {
+				var err error
+				ts, err = coreIndex.BuildTermStates(context, term, r.scoreMode.NeedsScores())
+				if err != nil {
+					return nil, err
+				}
+				r.termStates[term.Text()] = ts
+			}
+
+			if !r.scoreMode.NeedsScores() {
+				continue
+			}
+			docFreq, err := ts.DocFreq()
+			if err != nil {
+				return nil, err
+			}
+			if docFreq > 0 {
+				totalTermFreq, err := ts.TotalTermFreq()
+				if err != nil {
+					return nil, err
+				}
+				stats, err := searcher.TermStatistics(term, docFreq, int(totalTermFreq))
+				if err != nil {
+					return nil, err
+				}
+				allTermStats = append(allTermStats, stats)
+			}
+		}
+	}
+
+	return phraseSimScorer(searcher, r.query.field, r.boost, r.scoreMode, allTermStats)
+}
+
+func (r *multiPhraseWeight) GetPhraseMatcher(ctx index.LeafReaderContext, scorer index.SimScorer, exposeOffsets bool) (PhraseMatcher, error) {
+	reader := ctx.LeafReader()
+	fieldTerms, err := reader.Terms(r.query.field)
+	if err != nil {
+		return nil, err
+	}
+	if fieldTerms == nil {
+		return nil, nil
+	}
+
+	if !fieldTerms.HasPositions() {
+		return nil, fmt.Errorf(`field "%s" was indexed without position data; cannot run MultiPhraseQuery (phrase=%s)`,
+			r.query.field, r.query.String(""))
+	}
+
+	// Reuse single TermsEnum below:
+	termsEnum, err := fieldTerms.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := coreIndex.POSTINGS_ENUM_POSITIONS
+	if exposeOffsets {
+		flags = coreIndex.POSTINGS_ENUM_ALL
+	}
+
+	postingsFreqs := make([]*PostingsAndFreq, len(r.query.termArrays))
+	totalMatchCost := 0.0
+	for pos, terms := range r.query.termArrays {
+		postings := make([]index.PostingsEnum, 0, len(terms))
+		for _, term := range terms {
+			termState, err := r.termStates[term.Text()].Get(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if termState == nil {
+				continue
+			}
+
+			if err := termsEnum.SeekExactExpert(nil, term.Bytes(), termState); err != nil {
+				return nil, err
+			}
+			postingsEnum, err := termsEnum.Postings(nil, flags)
+			if err != nil {
+				return nil, err
+			}
+			postings = append(postings, postingsEnum)
+
+			cost, err := termPositionsCost(termsEnum)
+			if err != nil {
+				return nil, err
+			}
+			totalMatchCost += cost
+		}
+
+		if len(postings) == 0 {
+			return nil, nil
+		}
+
+		var postingsEnum index.PostingsEnum
+		switch {
+		case len(postings) == 1:
+			postingsEnum = postings[0]
+		case exposeOffsets:
+			postingsEnum = newUnionFullPostingsEnum(postings)
+		default:
+			postingsEnum = newUnionPostingsEnum(postings)
+		}
+
+		postingsFreqs[pos] = NewPostingsAndFreq(postingsEnum, coreIndex.NewSlowImpactsEnum(postingsEnum),
+			r.query.positions[pos], terms...)
+	}
+
+	if r.query.slop == 0 {
+		// sort by increasing docFreq order
+		sortPostingsAndFreq(postingsFreqs)
+		return NewExactPhraseMatcher(postingsFreqs, r.scoreMode, scorer, totalMatchCost)
+	}
+	return NewSloppyPhraseMatcher(postingsFreqs, r.query.slop, r.scoreMode, scorer, totalMatchCost, exposeOffsets)
+}
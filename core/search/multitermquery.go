@@ -1,6 +1,11 @@
 package search
 
 import (
+	"bytes"
+	"errors"
+	"io"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/util/attribute"
 	"github.com/geange/lucene-go/core/util/bytesref"
@@ -62,14 +67,20 @@ type RewriteMethod interface {
 	GetTermsEnum(query MultiTermQuery, terms index.Terms, atts *attribute.Source) (index.TermsEnum, error)
 }
 
+// CONSTANT_SCORE_REWRITE
+// A rewrite method that first creates a private Filter, by visiting each term in sequence and marking
+// all docs for that term. Matching documents are assigned a constant score equal to the query's boost.
+// This method is faster than the BooleanQuery rewrite methods when the number of matched terms or
+// matched documents is non-trivial. Also, it will never hit an errant TooManyClauses exception.
+var CONSTANT_SCORE_REWRITE RewriteMethod = &constantScoreRewrite{}
+
 var _ RewriteMethod = &constantScoreRewrite{}
 
 type constantScoreRewrite struct {
 }
 
 func (c *constantScoreRewrite) Rewrite(reader index.IndexReader, query MultiTermQuery) (index.Query, error) {
-	//TODO implement me
-	panic("implement me")
+	return NewMultiTermQueryConstantScoreWrapper(query), nil
 }
 
 func (c *constantScoreRewrite) GetTermsEnum(query MultiTermQuery, terms index.Terms, atts *attribute.Source) (index.TermsEnum, error) {
@@ -78,9 +89,18 @@ func (c *constantScoreRewrite) GetTermsEnum(query MultiTermQuery, terms index.Te
 
 var _ index.Query = &MultiTermQueryConstantScoreWrapper{}
 
+// MultiTermQueryConstantScoreWrapper
+// This class also provides the functionality behind CONSTANT_SCORE_REWRITE. It tries to rewrite
+// per-segment as a boolean query that returns a constant score and otherwise fills a bit set with
+// matches and builds a Scorer on top of this bit set.
 type MultiTermQueryConstantScoreWrapper struct {
 	query MultiTermQuery
-	field string
+}
+
+// NewMultiTermQueryConstantScoreWrapper
+// Wrap a MultiTermQuery as a Filter.
+func NewMultiTermQueryConstantScoreWrapper(query MultiTermQuery) *MultiTermQueryConstantScoreWrapper {
+	return &MultiTermQueryConstantScoreWrapper{query: query}
 }
 
 func (m *MultiTermQueryConstantScoreWrapper) String(field string) string {
@@ -99,13 +119,19 @@ func (m *MultiTermQueryConstantScoreWrapper) GetField() string {
 }
 
 func (m *MultiTermQueryConstantScoreWrapper) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
-	//TODO implement me
-	panic("implement me")
+	weight := &wrapperConstantScoreWeight{
+		searcher:  searcher,
+		scoreMode: scoreMode,
+		p:         m,
+	}
+	weight.ConstantScoreWeight = NewConstantScoreWeight(boost, m, weight)
+	return weight, nil
 }
 
 type wrapperConstantScoreWeight struct {
 	*ConstantScoreWeight
 
+	searcher  index.IndexSearcher
 	scoreMode index.ScoreMode
 	p         *MultiTermQueryConstantScoreWrapper
 }
@@ -185,16 +211,144 @@ func (r *wrapperConstantScoreWeight) IsCacheable(ctx index.LeafReaderContext) bo
 }
 
 // Try to collect terms from the given terms enum and return true iff all
-// terms could be collected. If {@code false} is returned, the enum is
+// terms could be collected. If false is returned, the enum is
 // left positioned on the next term.
-func (r *wrapperConstantScoreWeight) collectTerms(ctx index.LeafReaderContext, termsEnum index.TermsEnum, terms []*termAndState) {
-	panic("")
+func (r *wrapperConstantScoreWeight) collectTerms(termsEnum index.TermsEnum) ([]*termAndState, bool, error) {
+	threshold := min(BOOLEAN_REWRITE_TERM_COUNT_THRESHOLD, GetMaxClauseCount())
+
+	terms := make([]*termAndState, 0)
+	for i := 0; i < threshold; i++ {
+		term, err := nextTerm(termsEnum)
+		if err != nil {
+			return nil, false, err
+		}
+		if term == nil {
+			return terms, true, nil
+		}
+
+		state, err := termsEnum.TermState()
+		if err != nil {
+			return nil, false, err
+		}
+		docFreq, err := termsEnum.DocFreq()
+		if err != nil {
+			return nil, false, err
+		}
+		totalTermFreq, err := termsEnum.TotalTermFreq()
+		if err != nil {
+			return nil, false, err
+		}
+		terms = append(terms, newTermAndState(bytes.Clone(term), state, docFreq, totalTermFreq))
+	}
+
+	term, err := nextTerm(termsEnum)
+	if err != nil {
+		return nil, false, err
+	}
+	return terms, term == nil, nil
+}
+
+// nextTerm advances the enum, a nil term means the enum is exhausted.
+func nextTerm(termsEnum index.TermsEnum) ([]byte, error) {
+	term, err := termsEnum.Next(nil)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return term, nil
 }
 
 // On the given leaf context, try to either rewrite to a disjunction if
 // there are few terms, or build a bitset containing matching docs.
 func (r *wrapperConstantScoreWeight) rewrite(ctx index.LeafReaderContext) (*weightOrDocIdSet, error) {
-	panic("")
+	query := r.p.query
+	terms, err := ctx.LeafReader().Terms(query.GetField())
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		// field does not exist
+		return &weightOrDocIdSet{}, nil
+	}
+
+	termsEnum, err := query.GetTermsEnum(terms, attribute.NewSource())
+	if err != nil {
+		return nil, err
+	}
+
+	collectedTerms, all, err := r.collectTerms(termsEnum)
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		// build a boolean query
+		builder := NewBooleanQueryBuilder()
+		for _, t := range collectedTerms {
+			termStates := coreIndex.NewTermStates(nil, r.searcher.GetTopReaderContext())
+			termStates.Register(t.state, ctx.Ord(), t.docFreq, t.totalTermFreq)
+			term := coreIndex.NewTerm(query.GetField(), t.term)
+			builder.AddQuery(NewTermQueryV1(term, termStates), index.OccurShould)
+		}
+		bq, err := builder.Build()
+		if err != nil {
+			return nil, err
+		}
+		weight, err := r.searcher.CreateWeight(NewConstantScoreQuery(bq), r.scoreMode, r.Score())
+		if err != nil {
+			return nil, err
+		}
+		return &weightOrDocIdSet{weight: weight}, nil
+	}
+
+	// Too many terms: go back to the terms we already collected and start building the bit set
+	builder, err := NewDocIdSetBuilderV1(ctx.Reader().MaxDoc(), terms)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs index.PostingsEnum
+	if len(collectedTerms) > 0 {
+		termsEnum2, err := terms.Iterator()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range collectedTerms {
+			if err := termsEnum2.SeekExactExpert(nil, t.term, t.state); err != nil {
+				return nil, err
+			}
+			docs, err = termsEnum2.Postings(docs, coreIndex.POSTINGS_ENUM_NONE)
+			if err != nil {
+				return nil, err
+			}
+			if err := builder.Add(nil, docs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Then keep filling the bit set with remaining terms
+	for {
+		docs, err = termsEnum.Postings(docs, coreIndex.POSTINGS_ENUM_NONE)
+		if err != nil {
+			return nil, err
+		}
+		if err := builder.Add(nil, docs); err != nil {
+			return nil, err
+		}
+
+		term, err := nextTerm(termsEnum)
+		if err != nil {
+			return nil, err
+		}
+		if term == nil {
+			break
+		}
+	}
+
+	return &weightOrDocIdSet{set: builder.Build()}, nil
 }
 
 func (r *wrapperConstantScoreWeight) scorer(set DocIdSet) (index.Scorer, error) {
@@ -234,3 +388,12 @@ func (m *MultiTermQueryConstantScoreWrapper) Visit(visitor index.QueryVisitor) (
 	}
 	return nil
 }
+
+func (m *MultiTermQueryConstantScoreWrapper) Equals(other index.Query) bool {
+	o, ok := other.(*MultiTermQueryConstantScoreWrapper)
+	return ok && QueryEquals(m.query, o.query)
+}
+
+func (m *MultiTermQueryConstantScoreWrapper) HashCode() int {
+	return 31*ClassHash("MultiTermQueryConstantScoreWrapper") + QueryHashCode(m.query)
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"github.com/geange/lucene-go/core/types"
+)
+
+// PhraseMatcher
+// Base class for exact and sloppy phrase matching
+// To find matches on a document, first advance Approximation() to the relevant document, then call Reset().
+// Clients can then call NextMatch() to iterate over the matches
+type PhraseMatcher interface {
+	// Approximation
+	// An estimate of the expected cost to determine that a single document matches.
+	Approximation() types.DocIdSetIterator
+
+	// ImpactsApproximation
+	// An approximation that skips documents that cannot produce competitive scores, it advances
+	// synchronously with Approximation().
+	ImpactsApproximation() *ImpactsDISI
+
+	// MaxFreq
+	// An upper bound on the number of possible matches on this document
+	MaxFreq() (float64, error)
+
+	// Reset
+	// Called after Approximation() has been advanced
+	Reset() error
+
+	// NextMatch
+	// Find the next match on the current document, returning false if there are none.
+	NextMatch() (bool, error)
+
+	// SloppyWeight
+	// The slop-adjusted weight of the current match
+	// The sum of the slop-adjusted weights is used as the freq for scoring
+	SloppyWeight() float64
+
+	// StartPosition
+	// The start position of the current match
+	StartPosition() int
+
+	// EndPosition
+	// The end position of the current match
+	EndPosition() int
+
+	// StartOffset
+	// The start offset of the current match
+	StartOffset() (int, error)
+
+	// EndOffset
+	// The end offset of the current match
+	EndOffset() (int, error)
+
+	// GetMatchCost
+	// An estimate of the average cost of finding all matches on a document
+	// See Also: TwoPhaseIterator.MatchCost()
+	GetMatchCost() float64
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+// PhrasePositions
+// Position of a term in a document that takes into account the term offset within the phrase.
+type PhrasePositions struct {
+	position int                // position in doc
+	count    int                // remaining pos in this doc
+	offset   int                // position in phrase
+	ord      int                // unique across all PhrasePositions instances
+	postings index.PostingsEnum // stream of docs & positions
+	rptGroup int                // >=0 indicates that this is a repeating PP
+	rptInd   int                // index in the rptGroup
+	terms    []index.Term       // for repetitions initialization
+}
+
+func NewPhrasePositions(postings index.PostingsEnum, offset, ord int, terms []index.Term) *PhrasePositions {
+	return &PhrasePositions{
+		postings: postings,
+		offset:   offset,
+		ord:      ord,
+		terms:    terms,
+		rptGroup: -1,
+	}
+}
+
+func (p *PhrasePositions) firstPosition() (bool, error) {
+	freq, err := p.postings.Freq()
+	if err != nil {
+		return false, err
+	}
+	p.count = freq // read first pos
+	return p.nextPosition()
+}
+
+// Go to next location of this term current document, and set position as location - offset,
+// so that a matching exact phrase is easily identified when all PhrasePositions have exactly
+// the same position.
+func (p *PhrasePositions) nextPosition() (bool, error) {
+	if p.count <= 0 {
+		return false, nil
+	}
+	p.count--
+
+	// read subsequent pos's
+	pos, err := p.postings.NextPosition()
+	if err != nil {
+		return false, err
+	}
+	p.position = pos - p.offset
+	return true, nil
+}
+
+// newPhraseQueue
+// The queue of a SloppyPhraseMatcher, ordered by position in the document and then by
+// offset in the phrase.
+func newPhraseQueue(size int) *structure.PriorityQueue[*PhrasePositions] {
+	return structure.NewPriorityQueue(size, func(pp1, pp2 *PhrasePositions) bool {
+		if pp1.position == pp2.position {
+			// same doc and pp.position, so decide by actual term positions.
+			// rely on: pp.position == tp.position - offset.
+			if pp1.offset == pp2.offset {
+				return pp1.ord < pp2.ord
+			}
+			return pp1.offset < pp2.offset
+		}
+		return pp1.position < pp2.position
+	})
+}
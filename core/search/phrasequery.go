@@ -0,0 +1,539 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Query = &PhraseQuery{}
+
+// PhraseQuery
+// A Query that matches documents containing a particular sequence of terms.
+// A PhraseQuery is built by QueryParser for input like "new york".
+// This query may be combined with other terms or queries with a BooleanQuery.
+// NOTE: All terms in the phrase must match, even those at the same position. If you have
+// terms at the same position, perhaps synonyms, you probably want MultiPhraseQuery instead
+// which only requires one term at a position to match.
+// Also, Leading holes don't have any particular meaning for this query and will be ignored.
+// For instance this query:
+//
+//	builder := NewPhraseQueryBuilder()
+//	builder.AddWithPosition(NewTerm("body", []byte("one")), 4)
+//	builder.AddWithPosition(NewTerm("body", []byte("two")), 5)
+//	pq, err := builder.Build()
+//
+// is equivalent to the below query:
+//
+//	builder := NewPhraseQueryBuilder()
+//	builder.AddWithPosition(NewTerm("body", []byte("one")), 0)
+//	builder.AddWithPosition(NewTerm("body", []byte("two")), 1)
+//	pq, err := builder.Build()
+type PhraseQuery struct {
+	slop      int
+	field     string
+	terms     []index.Term
+	positions []int
+}
+
+// PhraseQueryBuilder
+// A builder for phrase queries.
+type PhraseQueryBuilder struct {
+	slop      int
+	terms     []index.Term
+	positions []int
+	errs      []error
+}
+
+func NewPhraseQueryBuilder() *PhraseQueryBuilder {
+	return &PhraseQueryBuilder{
+		slop:      0,
+		terms:     make([]index.Term, 0),
+		positions: make([]int, 0),
+		errs:      make([]error, 0),
+	}
+}
+
+// SetSlop
+// Set the slop.
+// See Also: PhraseQuery.GetSlop()
+func (b *PhraseQueryBuilder) SetSlop(slop int) *PhraseQueryBuilder {
+	if slop < 0 {
+		b.errs = append(b.errs, errors.New("slop value cannot be negative"))
+		return b
+	}
+	b.slop = slop
+	return b
+}
+
+// Add
+// Adds a term to the end of the query phrase. The relative position of the term is the one
+// immediately after the last term added.
+func (b *PhraseQueryBuilder) Add(term index.Term) *PhraseQueryBuilder {
+	position := 0
+	if len(b.positions) > 0 {
+		position = b.positions[len(b.positions)-1] + 1
+	}
+	return b.AddWithPosition(term, position)
+}
+
+// AddWithPosition
+// Adds a term to the end of the query phrase. The relative position of the term within the
+// phrase is specified explicitly, but must be greater than or equal to that of the previously
+// added term. A greater position allows phrases with gaps (e.g. in connection with stopwords).
+// If the position is equal, you most likely should be using MultiPhraseQuery instead which only
+// requires one term at each position to match; this class requires all of them.
+func (b *PhraseQueryBuilder) AddWithPosition(term index.Term, position int) *PhraseQueryBuilder {
+	if term == nil {
+		b.errs = append(b.errs, errors.New("cannot add a null term to PhraseQuery"))
+		return b
+	}
+	if position < 0 {
+		b.errs = append(b.errs, fmt.Errorf("positions must be >= 0, got %d", position))
+		return b
+	}
+	if len(b.positions) > 0 {
+		lastPosition := b.positions[len(b.positions)-1]
+		if position < lastPosition {
+			b.errs = append(b.errs, fmt.Errorf("positions must be added in order, got %d after %d", position, lastPosition))
+			return b
+		}
+	}
+	if len(b.terms) > 0 && term.Field() != b.terms[0].Field() {
+		b.errs = append(b.errs, fmt.Errorf("all terms must be on the same field, got %s and %s", term.Field(), b.terms[0].Field()))
+		return b
+	}
+	b.terms = append(b.terms, term)
+	b.positions = append(b.positions, position)
+	return b
+}
+
+// Build
+// Build a phrase query based on the terms that have been added.
+func (b *PhraseQueryBuilder) Build() (*PhraseQuery, error) {
+	if len(b.errs) != 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	return newPhraseQuery(b.slop, slices.Clone(b.terms), slices.Clone(b.positions)), nil
+}
+
+func newPhraseQuery(slop int, terms []index.Term, positions []int) *PhraseQuery {
+	field := ""
+	if len(terms) > 0 {
+		field = terms[0].Field()
+	}
+	return &PhraseQuery{
+		slop:      slop,
+		field:     field,
+		terms:     terms,
+		positions: positions,
+	}
+}
+
+// NewPhraseQuery
+// Create a phrase query which will match documents that contain the given list of terms at
+// consecutive positions in field.
+func NewPhraseQuery(field string, terms ...string) *PhraseQuery {
+	return NewPhraseQueryWithSlop(0, field, terms...)
+}
+
+// NewPhraseQueryWithSlop
+// Create a phrase query which will match documents that contain the given list of terms at
+// consecutive positions in field, and at a maximum edit distance of slop.
+// See Also: GetSlop()
+func NewPhraseQueryWithSlop(slop int, field string, terms ...string) *PhraseQuery {
+	phraseTerms := make([]index.Term, 0, len(terms))
+	positions := make([]int, 0, len(terms))
+	for i, term := range terms {
+		phraseTerms = append(phraseTerms, coreIndex.NewTerm(field, []byte(term)))
+		positions = append(positions, i)
+	}
+	return &PhraseQuery{
+		slop:      max(slop, 0),
+		field:     field,
+		terms:     phraseTerms,
+		positions: positions,
+	}
+}
+
+// GetSlop
+// Return the slop for this PhraseQuery.
+// The slop is an edit distance between respective positions of terms as defined in this
+// PhraseQuery and the positions of terms in a document.
+// For instance, when searching for "quick fox", it is expected that the difference between
+// the positions of fox and quick is 1. So "a quick brown fox" would be at an edit distance of 1
+// since the difference of the positions of fox and quick is 2. Similarly, "the fox is quick"
+// would be at an edit distance of 3 since the difference of the positions of fox and quick is -2.
+// The slop defines the maximum edit distance for a document to match.
+// More exact matches are scored higher than sloppier matches, thus search results are sorted
+// by exactness.
+func (p *PhraseQuery) GetSlop() int {
+	return p.slop
+}
+
+// GetField
+// Returns the field this query applies to
+func (p *PhraseQuery) GetField() string {
+	return p.field
+}
+
+// GetTerms
+// Returns the list of terms in this phrase.
+func (p *PhraseQuery) GetTerms() []index.Term {
+	return p.terms
+}
+
+// GetPositions
+// Returns the relative positions of terms in this phrase.
+func (p *PhraseQuery) GetPositions() []int {
+	return p.positions
+}
+
+func (p *PhraseQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	switch {
+	case len(p.terms) == 0:
+		return NewMatchNoDocsQuery("empty PhraseQuery"), nil
+	case len(p.terms) == 1:
+		return NewTermQuery(p.terms[0]), nil
+	case p.positions[0] != 0:
+		newPositions := make([]int, len(p.positions))
+		for i, position := range p.positions {
+			newPositions[i] = position - p.positions[0]
+		}
+		return newPhraseQuery(p.slop, p.terms, newPositions), nil
+	default:
+		return p, nil
+	}
+}
+
+func (p *PhraseQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(p.field) {
+		return nil
+	}
+	v := visitor.GetSubVisitor(index.OccurMust, p)
+	v.ConsumeTerms(p, p.terms...)
+	return nil
+}
+
+func (p *PhraseQuery) Equals(other index.Query) bool {
+	o, ok := other.(*PhraseQuery)
+	return ok &&
+		p.slop == o.slop &&
+		p.field == o.field &&
+		termsEqual(p.terms, o.terms) &&
+		slices.Equal(p.positions, o.positions)
+}
+
+func (p *PhraseQuery) HashCode() int {
+	h := ClassHash("PhraseQuery")
+	h = 31*h + p.slop
+	h = 31*h + termsHashCode(p.terms)
+	for _, position := range p.positions {
+		h = 31*h + position
+	}
+	return h
+}
+
+func (p *PhraseQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if p.field != "" && p.field != field {
+		buf.WriteString(p.field)
+		buf.WriteString(":")
+	}
+
+	buf.WriteString(`"`)
+	maxPosition := -1
+	if len(p.positions) > 0 {
+		maxPosition = p.positions[len(p.positions)-1]
+	}
+	pieces := make([]string, maxPosition+1)
+	for i, term := range p.terms {
+		pos := p.positions[i]
+		if pieces[pos] == "" {
+			pieces[pos] = term.Text()
+		} else {
+			pieces[pos] = pieces[pos] + "|" + term.Text()
+		}
+	}
+	for i, piece := range pieces {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		if piece == "" {
+			buf.WriteString("?")
+		} else {
+			buf.WriteString(piece)
+		}
+	}
+	buf.WriteString(`"`)
+
+	if p.slop != 0 {
+		buf.WriteString("~")
+		buf.WriteString(strconv.Itoa(p.slop))
+	}
+	return buf.String()
+}
+
+func (p *PhraseQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	weight := &phraseQueryWeight{
+		query:     p,
+		scoreMode: scoreMode,
+		boost:     boost,
+	}
+	phraseWeight, err := NewPhraseWeight(p, p.field, searcher, scoreMode, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.PhraseWeight = phraseWeight
+	return weight, nil
+}
+
+// A guess of the average number of simple operations for the initial seek and buffer refill
+// per document for the positions of a term. See also Lucene50PostingsReader.BlockImpactsPostingsEnum.nextPosition().
+// Aside: Instead of being constant this could depend among others on
+// Lucene50PostingsFormat.BLOCK_SIZE, TermsEnum.docFreq(), TermsEnum.totalTermFreq(),
+// DocIdSetIterator.cost() (expected number of matching docs), LeafReader.maxDoc() (total number of docs in the segment),
+// and the seek time and block size of the device storing the index.
+const termPosnsSeekOpsPerDoc = 128
+
+// Number of simple operations in Lucene50PostingsReader.BlockImpactsPostingsEnum.nextPosition() when no seek or
+// buffer refill is done.
+const termOpsPerPos = 7
+
+// termPositionsCost
+// Returns an expected cost in simple operations of processing the occurrences of a term in a document
+// that contains the term. This is for use by TwoPhaseIterator.MatchCost implementations.
+func termPositionsCost(termsEnum index.TermsEnum) (float64, error) {
+	docFreq, err := termsEnum.DocFreq()
+	if err != nil {
+		return 0, err
+	}
+	totalTermFreq, err := termsEnum.TotalTermFreq()
+	if err != nil {
+		return 0, err
+	}
+	expOccurrencesInMatchingDoc := float64(totalTermFreq) / float64(docFreq)
+	return termPosnsSeekOpsPerDoc + expOccurrencesInMatchingDoc*termOpsPerPos, nil
+}
+
+var _ PhraseWeightSPI = &phraseQueryWeight{}
+
+type phraseQueryWeight struct {
+	*PhraseWeight
+
+	query     *PhraseQuery
+	scoreMode index.ScoreMode
+	boost     float64
+	states    []*coreIndex.TermStates
+}
+
+func (r *phraseQueryWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	for _, term := range r.query.terms {
+		terms.Add(term)
+	}
+	return nil
+}
+
+func (r *phraseQueryWeight) GetStats(searcher index.IndexSearcher) (index.SimScorer, error) {
+	positions := r.query.positions
+	if len(positions) < 2 {
+		return nil, errors.New("PhraseWeight does not support less than 2 terms, call rewrite first")
+	} else if positions[0] != 0 {
+		return nil, errors.New("PhraseWeight requires that the first position is 0, call rewrite first")
+	}
+
+	context := searcher.GetTopReaderContext()
+	r.states = make([]*coreIndex.TermStates, len(r.query.terms))
+	termStats := make([]types.TermStatistics, 0, len(r.query.terms))
+	for i, term := range r.query.terms {
+		states, err := coreIndex.BuildTermStates(context, term, r.scoreMode.NeedsScores())
+		if err != nil {
+			return nil, err
+		}
+		r.states[i] = states
+
+		if r.scoreMode.NeedsScores() {
+			docFreq, err := states.DocFreq()
+			if err != nil {
+				return nil, err
+			}
+			if docFreq > 0 {
+				totalTermFreq, err := states.TotalTermFreq()
+				if err != nil {
+					return nil, err
+				}
+				stats, err := searcher.TermStatistics(term, docFreq, int(totalTermFreq))
+				if err != nil {
+					return nil, err
+				}
+				termStats = append(termStats, stats)
+			}
+		}
+	}
+
+	return phraseSimScorer(searcher, r.query.field, r.boost, r.scoreMode, termStats)
+}
+
+func (r *phraseQueryWeight) GetPhraseMatcher(ctx index.LeafReaderContext, scorer index.SimScorer, exposeOffsets bool) (PhraseMatcher, error) {
+	terms := r.query.terms
+	reader := ctx.LeafReader()
+	fieldTerms, err := reader.Terms(r.query.field)
+	if err != nil {
+		return nil, err
+	}
+	if fieldTerms == nil {
+		return nil, nil
+	}
+	if !fieldTerms.HasPositions() {
+		return nil, fmt.Errorf(`field "%s" was indexed without position data; cannot run PhraseQuery (phrase=%s)`,
+			r.query.field, r.query.String(""))
+	}
+
+	// Reuse single TermsEnum below:
+	te, err := fieldTerms.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	postingsFreqs := make([]*PostingsAndFreq, len(terms))
+	totalMatchCost := 0.0
+	for i, term := range terms {
+		state, err := r.states[i].Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if state == nil { // term doesnt exist in this segment
+			return nil, nil
+		}
+		if err := te.SeekExactExpert(nil, term.Bytes(), state); err != nil {
+			return nil, err
+		}
+
+		postings, impacts, err := phrasePostings(te, r.scoreMode, exposeOffsets)
+		if err != nil {
+			return nil, err
+		}
+		postingsFreqs[i] = NewPostingsAndFreq(postings, impacts, r.query.positions[i], term)
+
+		cost, err := termPositionsCost(te)
+		if err != nil {
+			return nil, err
+		}
+		totalMatchCost += cost
+	}
+
+	if r.query.slop == 0 {
+		// sort by increasing docFreq order
+		sortPostingsAndFreq(postingsFreqs)
+		return NewExactPhraseMatcher(postingsFreqs, r.scoreMode, scorer, totalMatchCost)
+	}
+	return NewSloppyPhraseMatcher(postingsFreqs, r.query.slop, r.scoreMode, scorer, totalMatchCost, exposeOffsets)
+}
+
+// phraseSimScorer
+// Returns the SimScorer of a phrase. Like TermWeight, weights that don't need scores use fake
+// statistics, so that the impacts of the phrase matchers always have a scorer.
+func phraseSimScorer(searcher index.IndexSearcher, field string, boost float64,
+	scoreMode index.ScoreMode, termStats []types.TermStatistics) (index.SimScorer, error) {
+
+	similarity := searcher.GetSimilarity()
+	if !scoreMode.NeedsScores() {
+		collectionStats, err := types.NewCollectionStatistics(field, 1, 1, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		termStats, err := types.NewTermStatistics([]byte(field), 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		return similarity.Scorer(boost, collectionStats, []types.TermStatistics{termStats}), nil
+	}
+
+	if len(termStats) == 0 {
+		// no terms at all, the phrase can't match any document and we won't use similarity
+		return nil, nil
+	}
+	collectionStats, err := searcher.CollectionStatistics(field)
+	if err != nil {
+		return nil, err
+	}
+	return similarity.Scorer(boost, collectionStats, termStats), nil
+}
+
+// phrasePostings
+// Returns the positional postings of the term the TermsEnum is positioned on, along with the
+// impacts used to skip non-competitive documents.
+func phrasePostings(te index.TermsEnum, scoreMode index.ScoreMode, exposeOffsets bool) (index.PostingsEnum, index.ImpactsEnum, error) {
+	flags := coreIndex.POSTINGS_ENUM_POSITIONS
+	if exposeOffsets {
+		flags = coreIndex.POSTINGS_ENUM_OFFSETS
+	}
+
+	if scoreMode == TOP_SCORES {
+		impacts, err := te.Impacts(flags)
+		if err != nil {
+			return nil, nil, err
+		}
+		return impacts, impacts, nil
+	}
+
+	postings, err := te.Postings(nil, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return postings, coreIndex.NewSlowImpactsEnum(postings), nil
+}
+
+// PostingsAndFreq
+// The postings of a phrase position, along with the terms they were built from.
+type PostingsAndFreq struct {
+	postings index.PostingsEnum
+	impacts  index.ImpactsEnum
+	position int
+	terms    []index.Term
+	nTerms   int // for faster comparisons
+}
+
+func NewPostingsAndFreq(postings index.PostingsEnum, impacts index.ImpactsEnum, position int, terms ...index.Term) *PostingsAndFreq {
+	terms = slices.Clone(terms)
+	if len(terms) > 1 {
+		slices.SortFunc(terms, func(a, b index.Term) int {
+			return bytes.Compare(a.Bytes(), b.Bytes())
+		})
+	}
+	return &PostingsAndFreq{
+		postings: postings,
+		impacts:  impacts,
+		position: position,
+		terms:    terms,
+		nTerms:   len(terms),
+	}
+}
+
+func (p *PostingsAndFreq) CompareTo(other *PostingsAndFreq) int {
+	if p.position != other.position {
+		return p.position - other.position
+	}
+	if p.nTerms != other.nTerms {
+		return p.nTerms - other.nTerms
+	}
+	for i, term := range p.terms {
+		if res := bytes.Compare(term.Bytes(), other.terms[i].Bytes()); res != 0 {
+			return res
+		}
+	}
+	return 0
+}
+
+func sortPostingsAndFreq(postingsFreqs []*PostingsAndFreq) {
+	slices.SortStableFunc(postingsFreqs, func(a, b *PostingsAndFreq) int {
+		return a.CompareTo(b)
+	})
+}
@@ -0,0 +1,173 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// searchDocs Returns the ids of the documents matching query, best hits first
+func searchDocs(t *testing.T, searcher index.IndexSearcher, query index.Query) []int {
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 100)
+	assert.Nil(t, err)
+	docs := make([]int, 0, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		docs = append(docs, scoreDoc.GetDoc())
+	}
+	return docs
+}
+
+// matchPositions Returns the start and end positions of the matches of query in the field of doc
+func matchPositions(t *testing.T, searcher index.IndexSearcher, query index.Query, field string, doc int) [][2]int {
+	query, err := searcher.(*search.IndexSearcher).Rewrite(query)
+	assert.Nil(t, err)
+	weight, err := searcher.CreateWeight(query, search.COMPLETE_NO_SCORES, 1)
+	assert.Nil(t, err)
+	leaves, err := searcher.GetIndexReader().Leaves()
+	assert.Nil(t, err)
+	leaf := leaves[coreIndex.SubIndexV1(doc, leaves)]
+
+	matches, err := weight.Matches(leaf, doc-leaf.DocBase())
+	assert.Nil(t, err)
+	if matches == nil {
+		return nil
+	}
+	it, err := matches.GetMatches(field)
+	assert.Nil(t, err)
+	if it == nil {
+		return nil
+	}
+	positions := make([][2]int, 0)
+	for {
+		ok, err := it.Next()
+		assert.Nil(t, err)
+		if !ok {
+			return positions
+		}
+		positions = append(positions, [2]int{it.StartPosition(), it.EndPosition()})
+	}
+}
+
+func newPhraseTestSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		searchtest.TextDocs("body",
+			"the quick brown fox",
+			"quick fox jumps",
+			"fox quick"),
+		searchtest.TextDocs("body",
+			"quick quick fox fox",
+			"fast fox",
+			"the fox is quick"))
+}
+
+func TestPhraseQuery_Exact(t *testing.T) {
+	searcher := newPhraseTestSearcher(t)
+
+	query := search.NewPhraseQuery("body", "quick", "fox")
+	assert.Equal(t, `body:"quick fox"`, query.String(""))
+	assert.ElementsMatch(t, []int{1, 3}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{0, 1}}, matchPositions(t, searcher, query, "body", 1))
+	assert.Equal(t, [][2]int{{1, 2}}, matchPositions(t, searcher, query, "body", 3))
+	assert.Nil(t, matchPositions(t, searcher, query, "body", 2))
+	assert.Nil(t, matchPositions(t, searcher, query, "title", 1))
+
+	// a gap for the missing middle term
+	query, err := search.NewPhraseQueryBuilder().
+		Add(coreIndex.NewTerm("body", []byte("quick"))).
+		AddWithPosition(coreIndex.NewTerm("body", []byte("fox")), 2).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `body:"quick ? fox"`, query.String(""))
+	assert.ElementsMatch(t, []int{0, 3}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{1, 3}}, matchPositions(t, searcher, query, "body", 0))
+	assert.Equal(t, [][2]int{{0, 2}, {1, 3}}, matchPositions(t, searcher, query, "body", 3))
+
+	// a repeated term
+	query = search.NewPhraseQuery("body", "fox", "fox")
+	assert.Equal(t, []int{3}, searchDocs(t, searcher, query))
+
+	query = search.NewPhraseQuery("body", "quick", "missing")
+	assert.Empty(t, searchDocs(t, searcher, query))
+
+	_, err = search.NewPhraseQueryBuilder().
+		AddWithPosition(coreIndex.NewTerm("body", []byte("fox")), 2).
+		AddWithPosition(coreIndex.NewTerm("body", []byte("quick")), 1).
+		Build()
+	assert.NotNil(t, err)
+	_, err = search.NewPhraseQueryBuilder().
+		Add(coreIndex.NewTerm("body", []byte("quick"))).
+		Add(coreIndex.NewTerm("title", []byte("fox"))).
+		Build()
+	assert.NotNil(t, err)
+}
+
+func TestPhraseQuery_Sloppy(t *testing.T) {
+	searcher := newPhraseTestSearcher(t)
+
+	// one extra term in between costs 1
+	query := search.NewPhraseQueryWithSlop(1, "body", "quick", "fox")
+	assert.Equal(t, `body:"quick fox"~1`, query.String(""))
+	docs := searchDocs(t, searcher, query)
+	assert.ElementsMatch(t, []int{0, 1, 3}, docs)
+	// exact matches score higher than sloppy ones
+	assert.Equal(t, 0, docs[2])
+	assert.Equal(t, [][2]int{{1, 3}}, matchPositions(t, searcher, query, "body", 0))
+
+	// swapping two adjacent terms costs 2
+	query = search.NewPhraseQueryWithSlop(2, "body", "quick", "fox")
+	assert.ElementsMatch(t, []int{0, 1, 2, 3}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{0, 1}}, matchPositions(t, searcher, query, "body", 2))
+
+	// "the fox is quick" is at an edit distance of 3
+	assert.NotContains(t, searchDocs(t, searcher, query), 5)
+	query = search.NewPhraseQueryWithSlop(3, "body", "quick", "fox")
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 5}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{1, 3}}, matchPositions(t, searcher, query, "body", 5))
+
+	// the phrase frequency of a sloppy match is 1 / (1 + distance)
+	for doc, freq := range map[int]string{0: "0.5", 2: "0.3333333333333333", 3: "1", 5: "0.25"} {
+		explanation, err := searcher.Explain(query, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, "score(freq="+freq+"), computed as boost * idf * tf from:",
+			explanation.GetDetails()[0].GetDescription())
+	}
+
+	// repeated terms may not match the same position
+	query = search.NewPhraseQueryWithSlop(1, "body", "quick", "quick")
+	assert.Equal(t, []int{3}, searchDocs(t, searcher, query))
+}
+
+func TestMultiPhraseQuery(t *testing.T) {
+	searcher := newPhraseTestSearcher(t)
+
+	query, err := search.NewMultiPhraseQueryBuilder().
+		AddTerms([]index.Term{
+			coreIndex.NewTerm("body", []byte("quick")),
+			coreIndex.NewTerm("body", []byte("fast")),
+		}).
+		Add(coreIndex.NewTerm("body", []byte("fox"))).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, `body:"(quick fast) fox"`, query.String(""))
+	assert.ElementsMatch(t, []int{1, 3, 4}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{0, 1}}, matchPositions(t, searcher, query, "body", 4))
+	assert.Equal(t, [][2]int{{1, 2}}, matchPositions(t, searcher, query, "body", 3))
+
+	query, err = search.NewMultiPhraseQueryBuilderFrom(query).SetSlop(2).Build()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, searchDocs(t, searcher, query))
+	assert.Equal(t, [][2]int{{0, 1}}, matchPositions(t, searcher, query, "body", 2))
+
+	// a single term at every position is a phrase
+	query, err = search.NewMultiPhraseQueryBuilder().
+		Add(coreIndex.NewTerm("body", []byte("fast"))).
+		Add(coreIndex.NewTerm("body", []byte("fox"))).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4}, searchDocs(t, searcher, query))
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Scorer = &PhraseScorer{}
+
+// PhraseScorer
+// Scorer of PhraseQuery and MultiPhraseQuery, the frequency of a document is the sum
+// of the sloppy weights of the matches found by the PhraseMatcher.
+type PhraseScorer struct {
+	*BaseScorer
+
+	approximation        types.DocIdSetIterator
+	impactsApproximation *ImpactsDISI
+	matcher              PhraseMatcher
+	scoreMode            index.ScoreMode
+	simScorer            *LeafSimScorer
+	matchCost            float64
+	minCompetitiveScore  float64
+	freq                 float64
+}
+
+func newPhraseScorer(weight index.Weight, matcher PhraseMatcher,
+	scoreMode index.ScoreMode, simScorer *LeafSimScorer) *PhraseScorer {
+
+	return &PhraseScorer{
+		BaseScorer:           NewScorer(weight),
+		approximation:        matcher.Approximation(),
+		impactsApproximation: matcher.ImpactsApproximation(),
+		matcher:              matcher,
+		scoreMode:            scoreMode,
+		simScorer:            simScorer,
+		matchCost:            matcher.GetMatchCost(),
+	}
+}
+
+func (p *PhraseScorer) TwoPhaseIterator() index.TwoPhaseIterator {
+	return &phraseTwoPhaseIterator{scorer: p}
+}
+
+func (p *PhraseScorer) DocID() int {
+	return p.approximation.DocID()
+}
+
+func (p *PhraseScorer) Score() (float64, error) {
+	if p.freq == 0 {
+		p.freq = p.matcher.SloppyWeight()
+		for {
+			ok, err := p.matcher.NextMatch()
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				break
+			}
+			p.freq += p.matcher.SloppyWeight()
+		}
+	}
+	return p.simScorer.Score(p.DocID(), p.freq)
+}
+
+func (p *PhraseScorer) Iterator() types.DocIdSetIterator {
+	return AsDocIdSetIterator(p.TwoPhaseIterator())
+}
+
+func (p *PhraseScorer) SetMinCompetitiveScore(minScore float64) error {
+	p.minCompetitiveScore = minScore
+	return p.impactsApproximation.setMinCompetitiveScore(minScore)
+}
+
+func (p *PhraseScorer) AdvanceShallow(target int) (int, error) {
+	return p.impactsApproximation.advanceShallow(nil, target)
+}
+
+func (p *PhraseScorer) GetMaxScore(upTo int) (float64, error) {
+	return p.impactsApproximation.GetMaxScore(upTo)
+}
+
+var _ index.TwoPhaseIterator = &phraseTwoPhaseIterator{}
+
+type phraseTwoPhaseIterator struct {
+	scorer *PhraseScorer
+}
+
+func (p *phraseTwoPhaseIterator) Approximation() types.DocIdSetIterator {
+	return p.scorer.approximation
+}
+
+func (p *phraseTwoPhaseIterator) Matches() (bool, error) {
+	scorer := p.scorer
+	if err := scorer.matcher.Reset(); err != nil {
+		return false, err
+	}
+
+	if scorer.scoreMode == TOP_SCORES && scorer.minCompetitiveScore > 0 {
+		maxFreq, err := scorer.matcher.MaxFreq()
+		if err != nil {
+			return false, err
+		}
+		score, err := scorer.simScorer.Score(scorer.DocID(), maxFreq)
+		if err != nil {
+			return false, err
+		}
+		if score < scorer.minCompetitiveScore {
+			// The maximum score we could get is less than the min competitive score
+			return false, nil
+		}
+	}
+
+	scorer.freq = 0
+	return scorer.matcher.NextMatch()
+}
+
+func (p *phraseTwoPhaseIterator) MatchCost() float64 {
+	return p.scorer.matchCost
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/geange/gods-generic/sets/treeset"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// PhraseWeight
+// Expert: Weight shared by PhraseQuery and MultiPhraseQuery, the query specific parts
+// (statistics and postings) are provided by a PhraseWeightSPI.
+type PhraseWeight struct {
+	*BaseWeight
+
+	spi        PhraseWeightSPI
+	scoreMode  index.ScoreMode
+	stats      index.SimScorer
+	similarity index.Similarity
+	field      string
+}
+
+type PhraseWeightSPI interface {
+	ExtractTerms(terms *treeset.Set[index.Term]) error
+
+	// GetStats
+	// Compute the statistics of the phrase terms, called once when the weight is created.
+	GetStats(searcher index.IndexSearcher) (index.SimScorer, error)
+
+	// GetPhraseMatcher
+	// Returns a PhraseMatcher over the postings of the phrase in the given segment,
+	// or nil if the phrase can't match any document of this segment.
+	GetPhraseMatcher(ctx index.LeafReaderContext, scorer index.SimScorer, exposeOffsets bool) (PhraseMatcher, error)
+}
+
+func NewPhraseWeight(query index.Query, field string, searcher index.IndexSearcher,
+	scoreMode index.ScoreMode, spi PhraseWeightSPI) (*PhraseWeight, error) {
+
+	weight := &PhraseWeight{
+		spi:        spi,
+		scoreMode:  scoreMode,
+		similarity: searcher.GetSimilarity(),
+		field:      field,
+	}
+	weight.BaseWeight = NewBaseWeight(query, weight)
+
+	stats, err := spi.GetStats(searcher)
+	if err != nil {
+		return nil, err
+	}
+	weight.stats = stats
+	return weight, nil
+}
+
+func (p *PhraseWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	matcher, err := p.spi.GetPhraseMatcher(ctx, p.stats, false)
+	if err != nil {
+		return nil, err
+	}
+	if matcher == nil {
+		return nil, nil
+	}
+
+	simScorer, err := NewLeafSimScorer(p.stats, ctx.LeafReader(), p.field, p.scoreMode.NeedsScores())
+	if err != nil {
+		return nil, err
+	}
+	return newPhraseScorer(p, matcher, p.scoreMode, simScorer), nil
+}
+
+func (p *PhraseWeight) Explain(ctx index.LeafReaderContext, doc int) (types.Explanation, error) {
+	matcher, err := p.spi.GetPhraseMatcher(ctx, p.stats, false)
+	if err != nil {
+		return nil, err
+	}
+	if matcher == nil {
+		return types.ExplanationNoMatch("no matching terms"), nil
+	}
+	if ok, err := advanceMatcher(matcher, doc); err != nil || !ok {
+		if err != nil {
+			return nil, err
+		}
+		return types.ExplanationNoMatch("no matching terms"), nil
+	}
+
+	if err := matcher.Reset(); err != nil {
+		return nil, err
+	}
+	ok, err := matcher.NextMatch()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return types.ExplanationNoMatch("no matching phrase"), nil
+	}
+
+	freq := matcher.SloppyWeight()
+	for {
+		ok, err := matcher.NextMatch()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		freq += matcher.SloppyWeight()
+	}
+
+	docScorer, err := NewLeafSimScorer(p.stats, ctx.LeafReader(), p.field, p.scoreMode.NeedsScores())
+	if err != nil {
+		return nil, err
+	}
+	freqExplanation := types.ExplanationMatch(freq, fmt.Sprintf("phraseFreq=%v", freq))
+	scoreExplanation, err := docScorer.Explain(doc, freqExplanation)
+	if err != nil {
+		return nil, err
+	}
+
+	similarityType := reflect.TypeOf(p.similarity)
+	if similarityType.Kind() == reflect.Pointer {
+		similarityType = similarityType.Elem()
+	}
+	return types.ExplanationMatch(scoreExplanation.GetValue(),
+		fmt.Sprintf(`weight(%s in %d) [%s], result of:`, p.GetQuery().String(""), doc, similarityType.Name()),
+		scoreExplanation), nil
+}
+
+func (p *PhraseWeight) Matches(ctx index.LeafReaderContext, doc int) (index.Matches, error) {
+	return MatchesForField(p.field, &phraseMatches{
+		weight:  p,
+		context: ctx,
+		doc:     doc,
+	}), nil
+}
+
+func (p *PhraseWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return p.spi.ExtractTerms(terms)
+}
+
+func (p *PhraseWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return true
+}
+
+// advanceMatcher positions the approximation of the matcher on doc, it returns false if the
+// approximation doesn't contain doc.
+func advanceMatcher(matcher PhraseMatcher, doc int) (bool, error) {
+	approximation := matcher.Approximation()
+	if approximation.DocID() > doc {
+		return false, nil
+	}
+	if approximation.DocID() == doc {
+		return true, nil
+	}
+	target, err := approximation.Advance(nil, doc)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return target == doc, nil
+}
+
+var _ IOSupplier[index.MatchesIterator] = &phraseMatches{}
+
+type phraseMatches struct {
+	weight  *PhraseWeight
+	context index.LeafReaderContext
+	doc     int
+}
+
+func (r *phraseMatches) Get() (index.MatchesIterator, error) {
+	matcher, err := r.weight.spi.GetPhraseMatcher(r.context, r.weight.stats, true)
+	if err != nil {
+		return nil, err
+	}
+	if matcher == nil {
+		return nil, nil
+	}
+	if ok, err := advanceMatcher(matcher, r.doc); err != nil || !ok {
+		return nil, err
+	}
+	if err := matcher.Reset(); err != nil {
+		return nil, err
+	}
+	ok, err := matcher.NextMatch()
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &phraseMatchesIterator{
+		matcher: matcher,
+		query:   r.weight.GetQuery(),
+	}, nil
+}
+
+var _ index.MatchesIterator = &phraseMatchesIterator{}
+
+// phraseMatchesIterator
+// A MatchesIterator over the matches of a PhraseMatcher, the matcher is already positioned
+// on its first match.
+type phraseMatchesIterator struct {
+	matcher PhraseMatcher
+	query   index.Query
+	started bool
+}
+
+func (p *phraseMatchesIterator) Next() (bool, error) {
+	if !p.started {
+		p.started = true
+		return true, nil
+	}
+	return p.matcher.NextMatch()
+}
+
+func (p *phraseMatchesIterator) StartPosition() int {
+	return p.matcher.StartPosition()
+}
+
+func (p *phraseMatchesIterator) EndPosition() int {
+	return p.matcher.EndPosition()
+}
+
+func (p *phraseMatchesIterator) StartOffset() (int, error) {
+	return p.matcher.StartOffset()
+}
+
+func (p *phraseMatchesIterator) EndOffset() (int, error) {
+	return p.matcher.EndOffset()
+}
+
+func (p *phraseMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	// phrases are treated as leaves
+	return nil, nil
+}
+
+func (p *phraseMatchesIterator) GetQuery() index.Query {
+	return p.query
+}
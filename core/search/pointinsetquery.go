@@ -1,247 +1,276 @@
 package search
 
 import (
-	"github.com/geange/lucene-go/core/index"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	coreIndex "github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
 )
 
-// TODO: fix
-//var _ Query = &PointInSetQuery{}
+var _ coreIndex.Query = &PointInSetQuery{}
 
+// PointInSetQuery
+// Abstract query class to find all documents whose single or multi-dimensional point values,
+// previously indexed with e.g. document.IntPoint, is contained in the specified set.
+// This is for subclasses and works on the underlying binary encoding: to create range queries
+// for lucene's standard Point types, refer to factory methods on those classes.
+//
+// lucene.experimental
 type PointInSetQuery struct {
-	sortedPackedPoints         *index.PrefixCodedTerms
-	sortedPackedPointsHashCode int
-	field                      string
-	numDims                    int
-	bytesPerDim                int
+	// sorted and deduplicated packed points
+	sortedPackedPoints [][]byte
+	field              string
+	numDims            int
+	bytesPerDim        int
 }
 
-/*
-
+// NewPointInSetQuery
+// The packedPoints are sorted and deduplicated here, each of them must hold numDims*bytesPerDim bytes.
 func NewPointInSetQuery(ctx context.Context, field string, numDims int, bytesPerDim int, packedPoints [][]byte) (*PointInSetQuery, error) {
-	builder := index.NewPrefixCodedTermsBuilder()
-	for i := 1; i < len(packedPoints); i++ {
-		previous := packedPoints[i-1]
-		current := packedPoints[i]
-
-		if v := bytes.Compare(previous, current); v == 0 {
-			continue // deduplicate
-		} else if v > 0 {
-			return nil, errors.New("values are out of order")
-		}
+	if numDims < 1 {
+		return nil, fmt.Errorf("numDims must be positive, got %d", numDims)
+	}
+	if bytesPerDim < 1 {
+		return nil, fmt.Errorf("bytesPerDim must be positive, got %d", bytesPerDim)
+	}
 
-		if i == 1 {
-			err := builder.AddBytes(ctx, field, previous)
-			if err != nil {
-				return nil, err
-			}
-		}
-		err := builder.AddBytes(ctx, field, current)
-		if err != nil {
-			return nil, err
+	packedLength := numDims * bytesPerDim
+	sortedPackedPoints := make([][]byte, 0, len(packedPoints))
+	for _, point := range packedPoints {
+		if len(point) != packedLength {
+			return nil, fmt.Errorf("packed point length should be %d but got %d; field=\"%s\" numDims=%d bytesPerDim=%d",
+				packedLength, len(point), field, numDims, bytesPerDim)
 		}
+		sortedPackedPoints = append(sortedPackedPoints, slices.Clone(point))
 	}
+	slices.SortFunc(sortedPackedPoints, bytes.Compare)
+	sortedPackedPoints = slices.CompactFunc(sortedPackedPoints, bytes.Equal)
 
 	return &PointInSetQuery{
-		sortedPackedPoints: builder.Finish(),
+		sortedPackedPoints: sortedPackedPoints,
 		field:              field,
 		numDims:            numDims,
 		bytesPerDim:        bytesPerDim,
 	}, nil
 }
 
-func (p *PointInSetQuery) String(field string) string {
+// GetPackedPoints Returns the sorted and deduplicated packed points of the set.
+func (p *PointInSetQuery) GetPackedPoints() [][]byte {
+	return p.sortedPackedPoints
+}
+
+func (p *PointInSetQuery) GetField() string {
 	return p.field
 }
 
-func (p *PointInSetQuery) CreateWeight(searcher *IndexSearcher, scoreMode *ScoreMode, boost float64) (Weight, error) {
-	weight := &pisQueryWeight{
-		ConstantScoreWeight: nil,
-		p:                   p,
-		scoreMode:           scoreMode,
+func (p *PointInSetQuery) GetNumDims() int {
+	return p.numDims
+}
+
+func (p *PointInSetQuery) GetBytesPerDim() int {
+	return p.bytesPerDim
+}
+
+func (p *PointInSetQuery) String(field string) string {
+	sb := new(strings.Builder)
+	if p.field != field {
+		sb.WriteString(p.field)
+		sb.WriteString(":")
 	}
 
+	sb.WriteString("{")
+	for i, point := range p.sortedPackedPoints {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(base64.StdEncoding.EncodeToString(point))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func (p *PointInSetQuery) CreateWeight(searcher coreIndex.IndexSearcher, scoreMode coreIndex.ScoreMode, boost float64) (coreIndex.Weight, error) {
+	// We don't use RandomAccessWeight here: it's no good to approximate with "match all docs".
+	// This is an inverted structure and should be used in the first pass:
+	weight := &pisQueryWeight{
+		p:         p,
+		scoreMode: scoreMode,
+	}
 	weight.ConstantScoreWeight = NewConstantScoreWeight(boost, p, weight)
 	return weight, nil
 }
 
+func (p *PointInSetQuery) IsPointQuery() bool {
+	return true
+}
+
+func (p *PointInSetQuery) Rewrite(reader coreIndex.IndexReader) (coreIndex.Query, error) {
+	return p, nil
+}
+
+func (p *PointInSetQuery) Visit(visitor coreIndex.QueryVisitor) error {
+	if visitor.AcceptField(p.field) {
+		return visitor.VisitLeaf(p)
+	}
+	return nil
+}
+
+func (p *PointInSetQuery) Equals(other coreIndex.Query) bool {
+	o, ok := other.(*PointInSetQuery)
+	return ok &&
+		p.field == o.field &&
+		p.numDims == o.numDims &&
+		p.bytesPerDim == o.bytesPerDim &&
+		slices.EqualFunc(p.sortedPackedPoints, o.sortedPackedPoints, bytes.Equal)
+}
+
+func (p *PointInSetQuery) HashCode() int {
+	h := ClassHash("PointInSetQuery")
+	h = 31*h + HashString(p.field)
+	h = 31*h + p.numDims
+	h = 31*h + p.bytesPerDim
+	for _, point := range p.sortedPackedPoints {
+		h = 31*h + HashBytes(point)
+	}
+	return h
+}
+
 type pisQueryWeight struct {
 	*ConstantScoreWeight
+
 	p         *PointInSetQuery
-	scoreMode *ScoreMode
+	scoreMode coreIndex.ScoreMode
 }
 
-func (r *pisQueryWeight) Scorer(ctx *index.LeafReaderContext) (Scorer, error) {
-	reader := ctx.Reader().(index.LeafReader)
-	values, ok := reader.GetPointValues(r.p.field)
+func (r *pisQueryWeight) Scorer(ctx coreIndex.LeafReaderContext) (coreIndex.Scorer, error) {
+	reader, ok := ctx.Reader().(coreIndex.LeafReader)
 	if !ok {
+		return nil, errors.New("get reader error")
+	}
+
+	field := r.p.field
+	values, exist := reader.GetPointValues(field)
+	if !exist {
 		// No docs in this segment/field indexed any points
 		return nil, nil
 	}
 
-	if dims, _ := values.GetNumIndexDimensions(); dims != r.p.numDims {
-		return nil, errors.New("numIndexDims not fit")
+	dimensions, err := values.GetNumIndexDimensions()
+	if err != nil {
+		return nil, err
+	}
+	if dimensions != r.p.numDims {
+		return nil, fmt.Errorf("field=\"%s\" was indexed with numIndexDimensions=%d but this query has numDims=%d",
+			field, dimensions, r.p.numDims)
 	}
 
-	if dims, _ := values.GetBytesPerDimension(); dims != r.p.bytesPerDim {
-		return nil, errors.New("bytesPerDim not fit")
+	bytesPerDimension, err := values.GetBytesPerDimension()
+	if err != nil {
+		return nil, err
+	}
+	if bytesPerDimension != r.p.bytesPerDim {
+		return nil, fmt.Errorf("field=\"%s\" was indexed with bytesPerDim=%d but this query has bytesPerDim=%d",
+			field, bytesPerDimension, r.p.bytesPerDim)
 	}
 
-	result := NewDocIdSetBuilderV2(reader.MaxDoc(), values, r.p.field)
+	result := NewDocIdSetBuilderV2(reader.MaxDoc(), values, field)
 	if r.p.numDims == 1 {
 		// We optimize this common case, effectively doing a merge sort of the indexed values vs the queried set:
-		visitor, err := r.p.NewMergePointVisitor(r.p.sortedPackedPoints, result)
-		if err != nil {
-			return nil, err
-		}
-		err = values.Intersect(nil, visitor)
-		if err != nil {
+		if err := values.Intersect(context.Background(), r.p.newMergePointVisitor(result)); err != nil {
 			return nil, err
 		}
 	} else {
 		// NOTE: this is naive implementation, where for each point we re-walk the KD tree to intersect.
-		// We could instead do a similar
-		// optimization as the 1D case, but I think it'd mean building a query-time KD tree so we could
-		// efficiently intersect against the
-		// index, which is probably tricky!
-		visitor := r.p.NewSinglePointVisitor(result)
-		iterator, err := r.p.sortedPackedPoints.Iterator()
-		if err != nil {
-			return nil, err
-		}
-
-		for {
-			point, err := iterator.Next(nil)
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-				return nil, err
-			}
-			visitor.SetPoint(point)
-			err = values.Intersect(nil, visitor)
-			if err != nil {
+		// We could instead do a similar optimization as the 1D case, but I think it'd mean building a
+		// query-time KD tree so we could efficiently intersect against the index, which is probably tricky!
+		visitor := r.p.newSinglePointVisitor(result)
+		for _, point := range r.p.sortedPackedPoints {
+			visitor.setPoint(point)
+			if err := values.Intersect(context.Background(), visitor); err != nil {
 				return nil, err
 			}
 		}
 	}
-	return NewConstantScoreScorer(r, r.score, r.scoreMode, result.Build().Iterator())
-}
 
-func (r *pisQueryWeight) IsCacheable(ctx *index.LeafReaderContext) bool {
-	return true
+	return NewConstantScoreScorer(r, r.Score(), r.scoreMode, result.Build().Iterator())
 }
 
-func (p *PointInSetQuery) Rewrite(reader index.Reader) (Query, error) {
-	return p, nil
+func (r *pisQueryWeight) IsCacheable(ctx coreIndex.LeafReaderContext) bool {
+	return true
 }
 
-func (p *PointInSetQuery) Visit(visitor QueryVisitor) (err error) {
-	if visitor.AcceptField(p.field) {
-		return visitor.VisitLeaf(p)
-	}
-	return nil
-}
+var _ types.IntersectVisitor = &mergePointVisitor{}
 
-var _ types.IntersectVisitor = &MergePointVisitor{}
+// mergePointVisitor Essentially does a merge sort, only collecting hits when the indexed point and
+// query point are the same. This is an optimization, used in the 1D case.
+type mergePointVisitor struct {
+	result *DocIdSetBuilder
+	adder  BulkAdder
 
-type MergePointVisitor struct {
-	result             *DocIdSetBuilder
-	iterator           *index.TermIterator
-	nextQueryPoint     []byte
-	scratch            []byte
-	sortedPackedPoints *index.PrefixCodedTerms
-	adder              BulkAdder
+	sortedPackedPoints [][]byte
+	upto               int
 }
 
-func (p *PointInSetQuery) NewMergePointVisitor(sortedPackedPoints *index.PrefixCodedTerms,
-	result *DocIdSetBuilder) (*MergePointVisitor, error) {
-	iterator, err := sortedPackedPoints.Iterator()
-	if err != nil {
-		return nil, err
-	}
-
-	next, err := iterator.Next(nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &MergePointVisitor{
+func (p *PointInSetQuery) newMergePointVisitor(result *DocIdSetBuilder) *mergePointVisitor {
+	return &mergePointVisitor{
 		result:             result,
-		sortedPackedPoints: sortedPackedPoints,
-		scratch:            make([]byte, p.bytesPerDim),
-		iterator:           iterator,
-		nextQueryPoint:     next,
-	}, nil
+		sortedPackedPoints: p.sortedPackedPoints,
+	}
 }
 
-func (m *MergePointVisitor) Visit(docID int) error {
-	m.adder.Add(docID)
+func (m *mergePointVisitor) nextQueryPoint() []byte {
+	if m.upto < len(m.sortedPackedPoints) {
+		return m.sortedPackedPoints[m.upto]
+	}
 	return nil
 }
 
-func (m *MergePointVisitor) VisitLeaf(docID int, packedValue []byte) error {
-	if m.matches(packedValue) {
-		return m.Visit(docID)
-	}
+func (m *mergePointVisitor) Visit(ctx context.Context, docID int) error {
+	m.adder.Add(docID)
 	return nil
 }
 
-func (m *MergePointVisitor) VisitIterator(iterator types.DocValuesIterator, packedValue []byte) error {
+func (m *mergePointVisitor) VisitLeaf(ctx context.Context, docID int, packedValue []byte) error {
 	if m.matches(packedValue) {
-		for {
-			docID, err := iterator.NextDoc()
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-				return err
-			}
-			err = m.Visit(docID)
-			if err != nil {
-				return err
-			}
-		}
+		return m.Visit(ctx, docID)
 	}
 	return nil
 }
 
-func (m *MergePointVisitor) matches(packedValue []byte) bool {
-	m.scratch = packedValue
-	for m.nextQueryPoint != nil {
-		cmp := bytes.Compare(m.nextQueryPoint, m.scratch)
-		switch {
-		case cmp == 0:
+// matches 1D values are visited in increasing order, so the query points that are smaller than
+// packedValue will never match again and can be skipped.
+func (m *mergePointVisitor) matches(packedValue []byte) bool {
+	for point := m.nextQueryPoint(); point != nil; point = m.nextQueryPoint() {
+		cmp := bytes.Compare(point, packedValue)
+		if cmp == 0 {
 			return true
-		case cmp < 0:
-			next, err := m.iterator.Next(nil)
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-				return false
-			}
-			m.nextQueryPoint = next
-		default:
-			break
 		}
+		if cmp > 0 {
+			// query point is after this value
+			return false
+		}
+		// query point is before this value, move on to the next one
+		m.upto++
 	}
 	return false
 }
 
-func (m *MergePointVisitor) Compare(minPackedValue, maxPackedValue []byte) types.Relation {
-	var err error
-	for m.nextQueryPoint != nil {
-		cmpMin := bytes.Compare(m.nextQueryPoint, minPackedValue)
+func (m *mergePointVisitor) Compare(minPackedValue, maxPackedValue []byte) types.Relation {
+	for point := m.nextQueryPoint(); point != nil; point = m.nextQueryPoint() {
+		cmpMin := bytes.Compare(point, minPackedValue)
 		if cmpMin < 0 {
 			// query point is before the start of this cell
-			m.nextQueryPoint, err = m.iterator.Next(nil)
-			if err != nil {
-				break
-			}
+			m.upto++
 			continue
 		}
 
-		cmpMax := bytes.Compare(m.nextQueryPoint, maxPackedValue)
+		cmpMax := bytes.Compare(point, maxPackedValue)
 		if cmpMax > 0 {
 			// query point is after the end of this cell
 			return types.CELL_OUTSIDE_QUERY
@@ -251,66 +280,70 @@ func (m *MergePointVisitor) Compare(minPackedValue, maxPackedValue []byte) types
 			// NOTE: we only hit this if we are on a cell whose min and max values are exactly equal to our point,
 			// which can easily happen if many (> 1024) docs share this one value
 			return types.CELL_INSIDE_QUERY
-		} else {
-			return types.CELL_CROSSES_QUERY
 		}
+		return types.CELL_CROSSES_QUERY
 	}
 
 	// We exhausted all points in the query:
 	return types.CELL_OUTSIDE_QUERY
 }
 
-func (m *MergePointVisitor) Grow(count int) {
+func (m *mergePointVisitor) Grow(count int) {
 	m.adder = m.result.Grow(count)
 }
 
-var _ types.IntersectVisitor = &SinglePointVisitor{}
+var _ types.IntersectVisitor = &singlePointVisitor{}
 
-type SinglePointVisitor struct {
+// singlePointVisitor IntersectVisitor that queries against a highly degenerate shape: a single point.
+// This is used in the > 1D case.
+type singlePointVisitor struct {
 	result     *DocIdSetBuilder
-	pointBytes []byte
 	adder      BulkAdder
-	p          *PointInSetQuery
+	pointBytes []byte
+
+	numDims     int
+	bytesPerDim int
 }
 
-func (p *PointInSetQuery) NewSinglePointVisitor(result *DocIdSetBuilder) *SinglePointVisitor {
-	return &SinglePointVisitor{
-		result:     result,
-		pointBytes: make([]byte, p.bytesPerDim*p.numDims),
-		p:          p,
+func (p *PointInSetQuery) newSinglePointVisitor(result *DocIdSetBuilder) *singlePointVisitor {
+	return &singlePointVisitor{
+		result:      result,
+		pointBytes:  make([]byte, p.bytesPerDim*p.numDims),
+		numDims:     p.numDims,
+		bytesPerDim: p.bytesPerDim,
 	}
 }
 
-func (s *SinglePointVisitor) SetPoint(point []byte) {
+func (s *singlePointVisitor) setPoint(point []byte) {
 	copy(s.pointBytes, point)
 }
 
-func (s *SinglePointVisitor) Visit(docID int) error {
+func (s *singlePointVisitor) Visit(ctx context.Context, docID int) error {
 	s.adder.Add(docID)
 	return nil
 }
 
-func (s *SinglePointVisitor) VisitLeaf(docID int, packedValue []byte) error {
+func (s *singlePointVisitor) VisitLeaf(ctx context.Context, docID int, packedValue []byte) error {
 	if bytes.Equal(packedValue, s.pointBytes) {
 		// The point for this doc matches the point we are querying on
-		return s.Visit(docID)
+		return s.Visit(ctx, docID)
 	}
 	return nil
 }
 
-func (s *SinglePointVisitor) Compare(minPackedValue, maxPackedValue []byte) types.Relation {
+func (s *singlePointVisitor) Compare(minPackedValue, maxPackedValue []byte) types.Relation {
 	crosses := false
 
-	bytesPerDim := s.p.bytesPerDim
-	for dim := 0; dim < s.p.numDims; dim++ {
-		offset := dim * bytesPerDim
+	for dim := 0; dim < s.numDims; dim++ {
+		fromIndex := dim * s.bytesPerDim
+		toIndex := fromIndex + s.bytesPerDim
 
-		cmpMin := bytes.Compare(minPackedValue[offset:offset+bytesPerDim], s.pointBytes[offset:offset+bytesPerDim])
+		cmpMin := bytes.Compare(minPackedValue[fromIndex:toIndex], s.pointBytes[fromIndex:toIndex])
 		if cmpMin > 0 {
 			return types.CELL_OUTSIDE_QUERY
 		}
 
-		cmpMax := bytes.Compare(maxPackedValue[offset:offset+bytesPerDim], s.pointBytes[offset:offset+bytesPerDim])
+		cmpMax := bytes.Compare(maxPackedValue[fromIndex:toIndex], s.pointBytes[fromIndex:toIndex])
 		if cmpMax < 0 {
 			return types.CELL_OUTSIDE_QUERY
 		}
@@ -329,9 +362,6 @@ func (s *SinglePointVisitor) Compare(minPackedValue, maxPackedValue []byte) type
 	return types.CELL_INSIDE_QUERY
 }
 
-func (s *SinglePointVisitor) Grow(count int) {
+func (s *singlePointVisitor) Grow(count int) {
 	s.adder = s.result.Grow(count)
 }
-
-
-*/
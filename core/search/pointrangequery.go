@@ -206,7 +206,11 @@ func (p *prQueryVisitor) Grow(count int) {
 }
 
 func (r *prQueryWeight) getInverseIntersectVisitor(result *bitset.BitSet, cost []int64) types.IntersectVisitor {
-	panic("")
+	return &invPrQueryVisitor{
+		result: result,
+		cost:   cost,
+		weight: r,
+	}
 }
 
 var _ types.IntersectVisitor = &invPrQueryVisitor{}
@@ -379,9 +383,7 @@ func (r *notAllDocsScorerSupplier) Get(leadCost int64) (coreIndex.Scorer, error)
 		// than half the leaf size then maybe we can make things faster
 		// by computing the set of documents that do NOT match the range
 		result := bitset.New(uint(r.reader.MaxDoc()))
-		for i := range result.Bytes() {
-			result.Bytes()[i] = math.MaxUint64
-		}
+		result.FlipRange(0, uint(r.reader.MaxDoc()))
 		cost := []int64{int64(r.reader.MaxDoc())}
 		err := r.values.Intersect(nil, r.weight.getInverseIntersectVisitor(result, cost))
 		if err != nil {
@@ -438,6 +440,26 @@ func (p *PointRangeQuery) Visit(visitor coreIndex.QueryVisitor) (err error) {
 	return nil
 }
 
+func (p *PointRangeQuery) Equals(other coreIndex.Query) bool {
+	o, ok := other.(*PointRangeQuery)
+	return ok &&
+		p.field == o.field &&
+		p.numDims == o.numDims &&
+		p.bytesPerDim == o.bytesPerDim &&
+		bytes.Equal(p.lowerPoint, o.lowerPoint) &&
+		bytes.Equal(p.upperPoint, o.upperPoint)
+}
+
+func (p *PointRangeQuery) HashCode() int {
+	h := ClassHash("PointRangeQuery")
+	h = 31*h + HashString(p.field)
+	h = 31*h + p.numDims
+	h = 31*h + p.bytesPerDim
+	h = 31*h + HashBytes(p.lowerPoint)
+	h = 31*h + HashBytes(p.upperPoint)
+	return h
+}
+
 func copyOfSubArray(bs []byte, from, to int) []byte {
 	newBytes := make([]byte, to-from)
 	copy(newBytes, bs[from:])
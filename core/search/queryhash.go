@@ -0,0 +1,133 @@
+package search
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+// Helpers for the Equals and HashCode methods of the queries. Like Lucene's Query.classHash, a query
+// starts its hash code with the hash code of its type, so that two queries of different types with
+// the same fields have different hash codes.
+
+// ClassHash Returns the hash code of the query type with the given name.
+func ClassHash(name string) int {
+	return HashString(name)
+}
+
+// HashString Returns the hash code of s.
+func HashString(s string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int(h.Sum32())
+}
+
+// HashBytes Returns the hash code of bs.
+func HashBytes(bs []byte) int {
+	h := fnv.New32a()
+	_, _ = h.Write(bs)
+	return int(h.Sum32())
+}
+
+// HashBool Returns the hash code of b, the same as Java's Boolean.hashCode.
+func HashBool(b bool) int {
+	if b {
+		return 1231
+	}
+	return 1237
+}
+
+// HashFloat64 Returns the hash code of f.
+func HashFloat64(f float64) int {
+	bits := math.Float64bits(f)
+	return int(bits ^ (bits >> 32))
+}
+
+// TermEquals Returns true if both terms are nil, or have the same field and bytes.
+func TermEquals(a, b index.Term) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Field() == b.Field() && bytes.Equal(a.Bytes(), b.Bytes())
+}
+
+// TermHashCode Returns the hash code of term, consistent with TermEquals.
+func TermHashCode(term index.Term) int {
+	if term == nil {
+		return 0
+	}
+	return 31*HashString(term.Field()) + HashBytes(term.Bytes())
+}
+
+// QueryEquals Returns true if both queries are nil, or a equals b.
+func QueryEquals(a, b index.Query) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equals(b)
+}
+
+// QueryHashCode Returns the hash code of query, 0 for nil.
+func QueryHashCode(query index.Query) int {
+	if query == nil {
+		return 0
+	}
+	return query.HashCode()
+}
+
+// queriesEqual Returns true if both lists hold equal queries in the same order.
+func queriesEqual[T index.Query](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !QueryEquals(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameQueries Returns true if both lists hold equal queries, in any order.
+func sameQueries(a, b []index.Query) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for _, query := range a {
+		found := false
+		for i, other := range b {
+			if !matched[i] && QueryEquals(query, other) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func termsEqual(a, b []index.Term) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !TermEquals(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func termsHashCode(terms []index.Term) int {
+	h := 1
+	for _, term := range terms {
+		h = 31*h + TermHashCode(term)
+	}
+	return h
+}
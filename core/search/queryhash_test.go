@@ -0,0 +1,59 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBooleanQuery(t *testing.T, should ...string) index.Query {
+	builder := search.NewBooleanQueryBuilder().
+		AddQuery(newTermQuery("body", "x"), index.OccurMust)
+	for _, term := range should {
+		builder.AddQuery(newTermQuery("body", term), index.OccurShould)
+	}
+	query, err := builder.Build()
+	assert.Nil(t, err)
+	return query
+}
+
+func newTestBoostQuery(t *testing.T, query index.Query, boost float64) index.Query {
+	boostQuery, err := search.NewBoostQuery(query, boost)
+	assert.Nil(t, err)
+	return boostQuery
+}
+
+func TestQuery_EqualsAndHashCode(t *testing.T) {
+	equal := [][2]index.Query{
+		{newTermQuery("body", "a"), newTermQuery("body", "a")},
+		{search.NewPhraseQuery("body", "a", "b"), search.NewPhraseQuery("body", "a", "b")},
+		// the clauses of an occur are compared in any order
+		{newTestBooleanQuery(t, "a", "b"), newTestBooleanQuery(t, "b", "a")},
+		{newTestBoostQuery(t, newTermQuery("body", "a"), 2), newTestBoostQuery(t, newTermQuery("body", "a"), 2)},
+		{search.NewConstantScoreQuery(newTestBooleanQuery(t, "a")), search.NewConstantScoreQuery(newTestBooleanQuery(t, "a"))},
+		{search.NewMatchAllDocsQuery(), search.NewMatchAllDocsQuery()},
+	}
+	for _, pair := range equal {
+		assert.True(t, pair[0].Equals(pair[1]), pair[0].String(""))
+		assert.True(t, pair[1].Equals(pair[0]), pair[0].String(""))
+		assert.Equal(t, pair[0].HashCode(), pair[1].HashCode(), pair[0].String(""))
+	}
+
+	notEqual := [][2]index.Query{
+		{newTermQuery("body", "a"), newTermQuery("body", "b")},
+		{newTermQuery("body", "a"), newTermQuery("title", "a")},
+		{search.NewPhraseQuery("body", "a", "b"), search.NewPhraseQuery("body", "b", "a")},
+		{newTestBooleanQuery(t, "a", "b"), newTestBooleanQuery(t, "a", "c")},
+		{newTestBooleanQuery(t, "a"), newTestBooleanQuery(t, "a", "a")},
+		{newTestBoostQuery(t, newTermQuery("body", "a"), 2), newTestBoostQuery(t, newTermQuery("body", "a"), 3)},
+		// same fields, different types
+		{newTermQuery("body", "a"), search.NewConstantScoreQuery(newTermQuery("body", "a"))},
+		{search.NewMatchAllDocsQuery(), search.NewMatchNoDocsQuery("")},
+	}
+	for _, pair := range notEqual {
+		assert.False(t, pair[0].Equals(pair[1]), "%s %s", pair[0].String(""), pair[1].String(""))
+		assert.False(t, pair[1].Equals(pair[0]), "%s %s", pair[0].String(""), pair[1].String(""))
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+var _ MultiTermQuery = &RegexpQuery{}
+
+// RegexpQuery
+// A fast regular expression query based on the automaton package.
+//
+// * Comparisons are fast
+// * The term dictionary is enumerated in an intelligent way, to avoid comparisons. See AutomatonQuery for more details.
+//
+// The supported syntax is documented in the automaton.RegExp type. Note this might be different
+// than other regular expression implementations. For some alternatives with different syntax, look
+// under the sandbox.
+//
+// Note this query can be slow, as it needs to iterate over many terms. In order to prevent
+// extremely slow RegexpQueries, a Regexp term should not start with the expression .*
+// lucene.experimental
+type RegexpQuery struct {
+	*AutomatonQuery
+}
+
+// NewRegexpQuery
+// Constructs a query for terms matching term.
+// By default, all regular expression features are enabled.
+func NewRegexpQuery(term index.Term) (*RegexpQuery, error) {
+	return NewRegexpQueryV1(term, automaton.REGEXP_FLAG_ALL, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+}
+
+// NewRegexpQueryV1
+// Constructs a query for terms matching term.
+//
+// term: regular expression.
+// flags: optional RegExp syntax features from automaton.RegExp
+// determinizeWorkLimit: maximum effort to spend while compiling the automaton from this regexp.
+// Set higher to allow more complex queries and lower to prevent memory exhaustion. Use
+// automaton.DEFAULT_DETERMINIZE_WORK_LIMIT as a decent default if you don't otherwise know what
+// to specify.
+func NewRegexpQueryV1(term index.Term, flags, determinizeWorkLimit int) (*RegexpQuery, error) {
+	return NewRegexpQueryV2(term, flags, 0, nil, determinizeWorkLimit)
+}
+
+// NewRegexpQueryV2
+// Constructs a query for terms matching term.
+//
+// term: regular expression.
+// syntaxFlags: optional RegExp syntax features from automaton.RegExp
+// matchFlags: boolean 'or' of match behavior options such as case insensitivity
+// provider: custom AutomatonProvider for named automata, may be nil
+// determinizeWorkLimit: maximum effort to spend while compiling the automaton from this regexp.
+func NewRegexpQueryV2(term index.Term, syntaxFlags, matchFlags int,
+	provider automaton.AutomatonProvider, determinizeWorkLimit int) (*RegexpQuery, error) {
+
+	regexp, err := automaton.NewRegExpV2(term.Text(), syntaxFlags, matchFlags)
+	if err != nil {
+		return nil, err
+	}
+	auto, err := regexp.ToAutomatonWithProvider(provider, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	query, err := NewAutomatonQuery(term, auto, determinizeWorkLimit, false)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpQuery{AutomatonQuery: query}, nil
+}
+
+// GetRegexp
+// Returns the regexp of this query wrapped in a Term.
+func (r *RegexpQuery) GetRegexp() index.Term {
+	return r.term
+}
+
+// String
+// Prints a user-readable version of this query.
+func (r *RegexpQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if r.term.Field() != field {
+		buf.WriteString(r.term.Field())
+		buf.WriteString(":")
+	}
+	buf.WriteString("/")
+	buf.WriteString(r.term.Text())
+	buf.WriteString("/")
+	return buf.String()
+}
+
+func (r *RegexpQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return nil, fmt.Errorf("query %s does not implement createWeight, it must be rewritten first", r.String(""))
+}
+
+func (r *RegexpQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return r.rewriteMethod.Rewrite(reader, r)
+}
+
+func (r *RegexpQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(r.field) {
+		if err := visit(r.compiled, visitor, r, r.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RegexpQuery) Equals(other index.Query) bool {
+	o, ok := other.(*RegexpQuery)
+	return ok && r.equalsTo(o.AutomatonQuery)
+}
+
+func (r *RegexpQuery) HashCode() int {
+	return 31*ClassHash("RegexpQuery") + r.fieldsHashCode()
+}
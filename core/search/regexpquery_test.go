@@ -0,0 +1,115 @@
+package search_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/geange/lucene-go/core/util/automaton"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAutomatonProvider map[string]string
+
+func (p testAutomatonProvider) GetAutomaton(name string) (*automaton.Automaton, error) {
+	pattern, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown automaton %s", name)
+	}
+	regexp, err := automaton.NewRegExp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.ToAutomaton()
+}
+
+func newRegexpQuery(t *testing.T, regexp string) *search.RegexpQuery {
+	query, err := search.NewRegexpQuery(coreIndex.NewTerm("body", []byte(regexp)))
+	assert.Nil(t, err)
+	return query
+}
+
+func newRegexpTestSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", "lucene", "lucid", "solr", "elastic search", "lu"),
+		searchtest.TextDocs("body", "1", "42", "100", "500", "007", "lucene 42"))
+}
+
+func TestRegexpQuery(t *testing.T) {
+	searcher := newRegexpTestSearcher(t)
+
+	query := newRegexpQuery(t, "luc.*")
+	assert.Equal(t, "body:/luc.*/", query.String(""))
+	assert.Equal(t, "/luc.*/", query.String("body"))
+	assert.ElementsMatch(t, []int{0, 1, 10}, searchDocs(t, searcher, query))
+
+	for regexp, expected := range map[string][]int{
+		"lu":             {4},
+		"lu.?":           {4},
+		"lu.{3,}":        {0, 1, 10},
+		"[a-z]+":         {0, 1, 2, 3, 4, 10},
+		"[^l].*":         {2, 3, 5, 6, 7, 8, 9, 10},
+		`\d\d`:           {6, 10},
+		"<1-100>":        {5, 6, 7, 9, 10},
+		"<001-100>":      {7, 9},
+		"~(luc.*)":       {2, 3, 4, 5, 6, 7, 8, 9, 10},
+		"[a-z]+&.*e.*":   {0, 3, 10},
+		".*c.*&~(luc.*)": {3},
+		"@":              {0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		"#":              {},
+		"nothing.*":      {},
+	} {
+		assert.ElementsMatch(t, expected, searchDocs(t, searcher, newRegexpQuery(t, regexp)), regexp)
+	}
+
+	// matches are constant scoring
+	scores := searchScores(t, searcher, newRegexpQuery(t, "lu.*"))
+	assert.Len(t, scores, 4)
+	for doc, score := range scores {
+		assert.Equal(t, 1.0, score, "doc %d", doc)
+	}
+}
+
+func TestRegexpQuery_Flags(t *testing.T) {
+	searcher := newRegexpTestSearcher(t)
+
+	// without the complement flag ~ is a plain character
+	query, err := search.NewRegexpQueryV1(coreIndex.NewTerm("body", []byte("~(luc.*)")),
+		automaton.REGEXP_FLAG_INTERSECTION, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.Empty(t, searchDocs(t, searcher, query))
+
+	query, err = search.NewRegexpQueryV2(coreIndex.NewTerm("body", []byte("LUC.*")),
+		automaton.REGEXP_FLAG_ALL, automaton.REGEXP_FLAG_ASCII_CASE_INSENSITIVE, nil, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []int{0, 1, 10}, searchDocs(t, searcher, query))
+	assert.Empty(t, searchDocs(t, searcher, newRegexpQuery(t, "LUC.*")))
+
+	provider := testAutomatonProvider{"number": `\d+`}
+	query, err = search.NewRegexpQueryV2(coreIndex.NewTerm("body", []byte("<number>")),
+		automaton.REGEXP_FLAG_ALL, 0, provider, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []int{5, 6, 7, 8, 9, 10}, searchDocs(t, searcher, query))
+
+	_, err = search.NewRegexpQueryV2(coreIndex.NewTerm("body", []byte("<missing>")),
+		automaton.REGEXP_FLAG_ALL, 0, provider, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.NotNil(t, err)
+}
+
+func TestRegexpQuery_Errors(t *testing.T) {
+	_, err := search.NewRegexpQuery(coreIndex.NewTerm("body", []byte("luc(ene")))
+	assert.NotNil(t, err)
+
+	// a pathological pattern is rejected before it is run against the index
+	_, err = search.NewRegexpQuery(coreIndex.NewTerm("body", []byte("(a|b)*a(a|b){20}")))
+	assert.True(t, errors.Is(err, automaton.ErrTooComplexToDeterminize), "%v", err)
+
+	term := coreIndex.NewTerm("body", []byte("(a|b)*a(a|b){10}"))
+	_, err = search.NewRegexpQuery(term)
+	assert.Nil(t, err)
+	_, err = search.NewRegexpQueryV1(term, automaton.REGEXP_FLAG_ALL, 100)
+	assert.True(t, errors.Is(err, automaton.ErrTooComplexToDeterminize), "%v", err)
+}
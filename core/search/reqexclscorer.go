@@ -1,6 +1,9 @@
 package search
 
 import (
+	"errors"
+	"io"
+
 	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
@@ -137,19 +140,14 @@ func (t *twoPhaseIterator1) Approximation() types.DocIdSetIterator {
 }
 
 func (t *twoPhaseIterator1) Matches() (bool, error) {
-	var err error
-
 	doc := t.reqApproximation.DocID()
 	// check if the doc is not excluded
-	exclDoc := t.exclApproximation.DocID()
-	if exclDoc < doc {
-		exclDoc, err = t.exclApproximation.Advance(nil, doc)
-		if err != nil {
-			return false, err
-		}
+	exclDoc, err := advanceExcluded(t.exclApproximation, doc)
+	if err != nil {
+		return false, err
 	}
 
-	if exclDoc < doc {
+	if exclDoc != doc {
 		return matchesOrNull(t.reqTwoPhaseIterator)
 	}
 	m1, err := matchesOrNull(t.reqTwoPhaseIterator)
@@ -168,9 +166,10 @@ func (t *twoPhaseIterator1) MatchCost() float64 {
 }
 
 // Confirms whether or not the given TwoPhaseIterator matches on the current document.
+// A nil iterator means that its approximation is exact, so it always matches.
 func matchesOrNull(it index.TwoPhaseIterator) (bool, error) {
 	if it == nil {
-		return false, nil
+		return true, nil
 	}
 
 	ok, err := it.Matches()
@@ -180,6 +179,23 @@ func matchesOrNull(it index.TwoPhaseIterator) (bool, error) {
 	return ok, nil
 }
 
+// advanceExcluded Moves the excluded approximation to the first doc on or after doc, an exhausted
+// approximation is on NO_MORE_DOCS.
+func advanceExcluded(exclApproximation types.DocIdSetIterator, doc int) (int, error) {
+	exclDoc := exclApproximation.DocID()
+	if exclDoc >= doc {
+		return exclDoc, nil
+	}
+	exclDoc, err := exclApproximation.Advance(nil, doc)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return types.NO_MORE_DOCS, nil
+		}
+		return 0, err
+	}
+	return exclDoc, nil
+}
+
 var _ index.TwoPhaseIterator = &twoPhaseIterator2{}
 
 type twoPhaseIterator2 struct {
@@ -195,15 +211,11 @@ func (t *twoPhaseIterator2) Approximation() types.DocIdSetIterator {
 }
 
 func (t *twoPhaseIterator2) Matches() (bool, error) {
-	var err error
 	doc := t.reqApproximation.DocID()
 	// check if the doc is not excluded
-	exclDoc := t.exclApproximation.DocID()
-	if exclDoc < doc {
-		exclDoc, err = t.exclApproximation.Advance(nil, doc)
-		if err != nil {
-			return false, err
-		}
+	exclDoc, err := advanceExcluded(t.exclApproximation, doc)
+	if err != nil {
+		return false, err
 	}
 	if exclDoc != doc {
 		return matchesOrNull(t.reqTwoPhaseIterator)
@@ -273,13 +273,14 @@ func (i *innerTwoPhaseIterator) Approximation() types.DocIdSetIterator {
 }
 
 func (i *innerTwoPhaseIterator) Matches() (bool, error) {
-	matchValues, err := i.reqTwoPhase.Matches()
-	if err != nil {
-		return false, err
-	}
-
-	if i.reqTwoPhase != nil && matchValues == false {
-		return false, nil
+	if i.reqTwoPhase != nil {
+		matchValues, err := i.reqTwoPhase.Matches()
+		if err != nil {
+			return false, err
+		}
+		if !matchValues {
+			return false, nil
+		}
 	}
 
 	if i.scorer.optTwoPhase != nil {
@@ -288,7 +289,7 @@ func (i *innerTwoPhaseIterator) Matches() (bool, error) {
 			// after the opt approximation was advanced and before it was confirmed.
 			if i.scorer.reqScorer.DocID() != i.scorer.optApproximation.DocID() {
 				if i.scorer.optApproximation.DocID() < i.scorer.reqScorer.DocID() {
-					if _, err := i.scorer.optApproximation.Advance(context.Background(), i.scorer.reqScorer.DocID()); err != nil {
+					if _, err := i.scorer.optApproximation.Advance(context.Background(), i.scorer.reqScorer.DocID()); err != nil && !errors.Is(err, io.EOF) {
 						return false, err
 					}
 				}
@@ -298,16 +299,22 @@ func (i *innerTwoPhaseIterator) Matches() (bool, error) {
 			}
 			if ok, _ := i.scorer.optTwoPhase.Matches(); !ok {
 				// Advance the iterator to make it clear it doesn't match the current doc id
-				if _, err := i.scorer.optApproximation.NextDoc(nil); err != nil {
+				if _, err := i.scorer.optApproximation.NextDoc(nil); err != nil && !errors.Is(err, io.EOF) {
 					return false, err
 				}
 				return false, nil
 			}
-		} else if match, _ := i.scorer.optTwoPhase.Matches(); i.scorer.optApproximation.DocID() == i.scorer.reqScorer.DocID() && match == false {
-			// Advance the iterator to make it clear it doesn't match the current doc id
-			if _, err := i.scorer.optApproximation.NextDoc(nil); err != nil {
+		} else if i.scorer.optApproximation.DocID() == i.scorer.reqScorer.DocID() {
+			match, err := i.scorer.optTwoPhase.Matches()
+			if err != nil {
 				return false, err
 			}
+			if !match {
+				// Advance the iterator to make it clear it doesn't match the current doc id
+				if _, err := i.scorer.optApproximation.NextDoc(nil); err != nil && !errors.Is(err, io.EOF) {
+					return false, err
+				}
+			}
 		}
 	}
 	return true, nil
@@ -340,13 +347,26 @@ func (r *ReqOptSumScorer) Score() (float64, error) {
 	if optScorerDoc < curDoc {
 		optScorerDoc, err = r.optApproximation.Advance(context.Background(), curDoc)
 		if err != nil {
-			return 0, err
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			return score, nil
 		}
-		if match, _ := r.optTwoPhase.Matches(); r.optTwoPhase != nil && optScorerDoc == curDoc && match == false {
-			optScorerDoc, err = r.optApproximation.NextDoc(context.Background())
+		for r.optTwoPhase != nil && optScorerDoc == curDoc {
+			match, err := r.optTwoPhase.Matches()
 			if err != nil {
 				return 0, err
 			}
+			if match {
+				break
+			}
+			optScorerDoc, err = r.optApproximation.NextDoc(context.Background())
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					return 0, err
+				}
+				return score, nil
+			}
 		}
 	}
 
@@ -0,0 +1,201 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/attribute"
+)
+
+// SCORING_BOOLEAN_REWRITE
+// A rewrite method that first translates each term into BooleanClause.Occur.SHOULD clause in a
+// BooleanQuery, and keeps the scores as computed by the query. Note that typically such scores
+// are meaningless to the user, and require non-trivial CPU to compute, so it's almost always better
+// to use CONSTANT_SCORE_REWRITE instead.
+//
+// NOTE: This rewrite method will hit ErrTooManyClauses if the number of terms exceeds
+// GetMaxClauseCount.
+var SCORING_BOOLEAN_REWRITE RewriteMethod = NewScoringRewrite(&scoringBooleanQueryRewrite{})
+
+// CONSTANT_SCORE_BOOLEAN_REWRITE
+// Like SCORING_BOOLEAN_REWRITE except scores are not computed. Instead, each matching document
+// receives a constant score equal to the query's boost.
+//
+// NOTE: This rewrite method will hit ErrTooManyClauses if the number of terms exceeds
+// GetMaxClauseCount.
+var CONSTANT_SCORE_BOOLEAN_REWRITE RewriteMethod = &constantScoreBooleanQueryRewrite{}
+
+var _ RewriteMethod = &ScoringRewrite{}
+
+// ScoringRewrite
+// Base rewrite method that translates each term into a query, and keeps the scores as computed
+// by the query.
+// lucene.internal
+type ScoringRewrite struct {
+	spi ScoringRewriteSPI
+}
+
+// ScoringRewriteSPI
+// The parts of a ScoringRewrite which build the top level query.
+type ScoringRewriteSPI interface {
+	// AddClause
+	// Add a MultiTermQuery term to the top level query builder.
+	AddClause(builder *BooleanQueryBuilder, term index.Term, docCount int, boost float64, states *coreIndex.TermStates) error
+
+	// CheckMaxClauseCount
+	// This method is called after every new term to check if the number of max clauses (e.g. in
+	// BooleanQuery) is not exceeded.
+	CheckMaxClauseCount(count int) error
+}
+
+func NewScoringRewrite(spi ScoringRewriteSPI) *ScoringRewrite {
+	return &ScoringRewrite{spi: spi}
+}
+
+func (s *ScoringRewrite) GetTermsEnum(query MultiTermQuery, terms index.Terms, atts *attribute.Source) (index.TermsEnum, error) {
+	return query.GetTermsEnum(terms, atts)
+}
+
+func (s *ScoringRewrite) Rewrite(reader index.IndexReader, query MultiTermQuery) (index.Query, error) {
+	topReaderContext, err := reader.GetContext()
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := topReaderContext.Leaves()
+	if err != nil {
+		return nil, err
+	}
+
+	collected := make(map[string]*scoreTerm)
+	for _, leaf := range leaves {
+		terms, err := leaf.LeafReader().Terms(query.GetField())
+		if err != nil {
+			return nil, err
+		}
+		if terms == nil {
+			// field does not exist
+			continue
+		}
+
+		termsEnum, err := s.GetTermsEnum(query, terms, attribute.NewSource())
+		if err != nil {
+			return nil, err
+		}
+		boostAtt, hasBoost := termsEnum.(BoostAttribute)
+
+		for {
+			term, err := termsEnum.Next(nil)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			if term == nil {
+				break
+			}
+
+			state, err := termsEnum.TermState()
+			if err != nil {
+				return nil, err
+			}
+			docFreq, err := termsEnum.DocFreq()
+			if err != nil {
+				return nil, err
+			}
+			totalTermFreq, err := termsEnum.TotalTermFreq()
+			if err != nil {
+				return nil, err
+			}
+
+			if st, ok := collected[string(term)]; ok {
+				// duplicate term: update docFreq
+				st.termState.Register(state, leaf.Ord(), docFreq, totalTermFreq)
+				continue
+			}
+
+			// new entry: we populate the entry initially
+			boost := 1.0
+			if hasBoost {
+				boost = boostAtt.GetBoost()
+			}
+			st := &scoreTerm{
+				bytes:     bytes.Clone(term),
+				boost:     boost,
+				termState: coreIndex.NewTermStates(nil, topReaderContext),
+			}
+			st.termState.Register(state, leaf.Ord(), docFreq, totalTermFreq)
+			collected[string(st.bytes)] = st
+
+			if err := s.spi.CheckMaxClauseCount(len(collected)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	scoreTerms := make([]*scoreTerm, 0, len(collected))
+	for _, st := range collected {
+		scoreTerms = append(scoreTerms, st)
+	}
+	slices.SortFunc(scoreTerms, func(a, b *scoreTerm) int {
+		return bytes.Compare(a.bytes, b.bytes)
+	})
+
+	builder := NewBooleanQueryBuilder()
+	for _, st := range scoreTerms {
+		term := coreIndex.NewTerm(query.GetField(), st.bytes)
+		docFreq, err := st.termState.DocFreq()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.spi.AddClause(builder, term, docFreq, st.boost, st.termState); err != nil {
+			return nil, err
+		}
+	}
+	return builder.Build()
+}
+
+var _ ScoringRewriteSPI = &scoringBooleanQueryRewrite{}
+
+type scoringBooleanQueryRewrite struct {
+}
+
+func (s *scoringBooleanQueryRewrite) AddClause(builder *BooleanQueryBuilder, term index.Term,
+	docCount int, boost float64, states *coreIndex.TermStates) error {
+
+	query, err := NewBoostQuery(NewTermQueryV1(term, states), boost)
+	if err != nil {
+		return err
+	}
+	builder.AddQuery(query, index.OccurShould)
+	return nil
+}
+
+func (s *scoringBooleanQueryRewrite) CheckMaxClauseCount(count int) error {
+	if count > GetMaxClauseCount() {
+		return ErrTooManyClauses
+	}
+	return nil
+}
+
+var _ RewriteMethod = &constantScoreBooleanQueryRewrite{}
+
+type constantScoreBooleanQueryRewrite struct {
+}
+
+func (c *constantScoreBooleanQueryRewrite) Rewrite(reader index.IndexReader, query MultiTermQuery) (index.Query, error) {
+	bq, err := SCORING_BOOLEAN_REWRITE.Rewrite(reader, query)
+	if err != nil {
+		return nil, err
+	}
+	// strip the scores off
+	return NewConstantScoreQuery(bq), nil
+}
+
+func (c *constantScoreBooleanQueryRewrite) GetTermsEnum(query MultiTermQuery, terms index.Terms, atts *attribute.Source) (index.TermsEnum, error) {
+	return query.GetTermsEnum(terms, atts)
+}
@@ -0,0 +1,85 @@
+// Package searchtest provides the index fixtures shared by the tests of the search packages.
+package searchtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geange/lucene-go/codecs/lucene87"
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// NewReader
+// Writes a lucene87 index in a temporary directory, every group of documents is committed as its
+// own segment, and opens a reader on it. Segments are never merged, so the doc ids follow the order
+// the documents are given in. The reader and the directory are closed when the test ends.
+func NewReader(t testing.TB, segments ...[]*document.Document) index.IndexReader {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = dir.Close() })
+
+	similarity, err := search.NewBM25Similarity()
+	assert.Nil(t, err)
+	config := coreIndex.NewIndexWriterConfig(lucene87.NewCodec(), similarity)
+	config.SetMergePolicy(coreIndex.NewNoMergePolicy())
+	writer, err := coreIndex.NewIndexWriter(ctx, dir, config)
+	assert.Nil(t, err)
+	defer writer.Close()
+
+	for _, docs := range segments {
+		for _, doc := range docs {
+			_, err := writer.AddDocument(ctx, doc)
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, writer.Commit(ctx))
+	}
+
+	reader, err := coreIndex.OpenDirectoryReader(ctx, dir, nil, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+	return reader
+}
+
+// NewSearcher
+// Returns a searcher over the index written by NewReader
+func NewSearcher(t testing.TB, segments ...[]*document.Document) *search.IndexSearcher {
+	searcher, err := search.NewIndexSearcher(NewReader(t, segments...))
+	assert.Nil(t, err)
+	return searcher.(*search.IndexSearcher)
+}
+
+// TextDocs
+// Returns a document with a text field for every value
+func TextDocs(field string, values ...string) []*document.Document {
+	docs := make([]*document.Document, 0, len(values))
+	for _, value := range values {
+		doc := document.NewDocument()
+		doc.Add(document.NewTextField(field, value, false))
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// OffsetTextDocs
+// Returns a document with a text field for every value, the field also indexes the offsets of
+// its terms, so that matches can report them
+func OffsetTextDocs(t testing.TB, field string, values ...string) []*document.Document {
+	fieldType := document.NewFieldType()
+	assert.Nil(t, fieldType.SetIndexOptions(document.INDEX_OPTIONS_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS))
+	assert.Nil(t, fieldType.SetTokenized(true))
+	fieldType.Freeze()
+
+	docs := make([]*document.Document, 0, len(values))
+	for _, value := range values {
+		doc := document.NewDocument()
+		doc.Add(document.NewField(field, value, fieldType))
+		docs = append(docs, doc)
+	}
+	return docs
+}
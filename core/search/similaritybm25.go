@@ -246,6 +246,52 @@ func (b *BM25Scorer) Score(freq float64, norm int64) float64 {
 	return b.weight - b.weight/(1.0+freq*normInverse)
 }
 
+func (b *BM25Scorer) Explain(freq types.Explanation, encodedNorm int64) (types.Explanation, error) {
+	freqValue, ok := freq.GetValue().(float64)
+	if !ok {
+		return nil, fmt.Errorf("freq must be a float64, got %T", freq.GetValue())
+	}
+
+	subs := b.explainConstantFactors()
+	tfExpl := b.explainTF(freq, freqValue, encodedNorm)
+	subs = append(subs, tfExpl)
+
+	normInverse := b.cache[(byte(encodedNorm & 0xFF))]
+	return types.ExplanationMatch(b.weight-b.weight/(1.0+freqValue*normInverse),
+		fmt.Sprintf("score(freq=%v), computed as boost * idf * tf from:", freqValue), subs...), nil
+}
+
+func (b *BM25Scorer) explainTF(freq types.Explanation, freqValue float64, norm int64) types.Explanation {
+	subs := []types.Explanation{
+		freq,
+		types.ExplanationMatch(b.k1, "k1, term saturation parameter"),
+	}
+
+	doclen := LENGTH_TABLE[(byte(norm & 0xFF))]
+	normValue := b.k1 * ((1 - b.b) + b.b*doclen/b.avgDocLength)
+	subs = append(subs, types.ExplanationMatch(b.b, "b, length normalization parameter"))
+	if (norm & 0xFF) > 39 {
+		subs = append(subs, types.ExplanationMatch(doclen, "dl, length of field (approximate)"))
+	} else {
+		subs = append(subs, types.ExplanationMatch(doclen, "dl, length of field"))
+	}
+	subs = append(subs, types.ExplanationMatch(b.avgDocLength, "avgdl, average length of field"))
+
+	return types.ExplanationMatch(freqValue/(freqValue+normValue),
+		"tf, computed as freq / (freq + k1 * (1 - b + b * dl / avgdl)) from:", subs...)
+}
+
+func (b *BM25Scorer) explainConstantFactors() []types.Explanation {
+	subs := make([]types.Explanation, 0, 2)
+	// query boost
+	if b.boost != 1.0 {
+		subs = append(subs, types.ExplanationMatch(b.boost, "boost"))
+	}
+	// idf
+	subs = append(subs, b.idf)
+	return subs
+}
+
 // Implemented as log(1 + (docCount - docFreq + 0.5)/(docFreq + 0.5)).
 func idf(docFreq, docCount int64) float64 {
 	return math.Log(1 + (float64(docCount-docFreq)+0.5)/(float64(docFreq)+0.5))
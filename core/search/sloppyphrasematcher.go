@@ -0,0 +1,681 @@
+package search
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/bits-and-blooms/bitset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ PhraseMatcher = &SloppyPhraseMatcher{}
+
+// SloppyPhraseMatcher
+// Find all slop-valid position-combinations (matches) encountered while traversing/hopping the
+// PhrasePositions.
+// The sloppy frequency contribution of a match depends on the distance:
+// - highest freq for distance=0 (exact match).
+// - freq gets lower as distance gets higher.
+// Example: for query "a b"~2, a document "x a b a y" can be matched twice: once for "a b" (distance=0),
+// and once for "b a" (distance=2).
+// Possibly not all valid combinations are encountered, because for efficiency we always propagate
+// the least PhrasePosition. This allows to base on PriorityQueue and move forward faster. As result,
+// for example, document "a b c b a" would score differently for queries "a b c"~4 and "c b a"~4,
+// although they really are equivalent. Similarly, for doc "a b c b a f g", query "c b"~2 would get
+// same score as "g f"~2, although "c b"~2 could be matched twice. We may want to fix this in the
+// future (currently not, for performance reasons).
+type SloppyPhraseMatcher struct {
+	phrasePositions []*PhrasePositions
+
+	slop             int
+	numPostings      int
+	pq               *structure.PriorityQueue[*PhrasePositions] // for advancing min position
+	captureLeadMatch bool
+
+	approximation        types.DocIdSetIterator
+	impactsApproximation *ImpactsDISI
+	matchCost            float64
+
+	end int // current largest phrase position
+
+	leadPosition  int
+	leadOffset    int
+	leadEndOffset int
+	leadOrd       int
+
+	hasRpts          bool                 // flag indicating that there are repetitions (as checked in first candidate doc)
+	checkedRpts      bool                 // flag to only check for repetitions in first candidate doc
+	hasMultiTermRpts bool                 //
+	rptGroups        [][]*PhrasePositions // in each group are PPs that repeats each other (i.e. same term), sorted by (query) offset
+	rptStack         []*PhrasePositions   // temporary stack for switching colliding repeating pps
+
+	positioned  bool
+	matchLength int
+}
+
+func NewSloppyPhraseMatcher(postings []*PostingsAndFreq, slop int, scoreMode index.ScoreMode,
+	scorer index.SimScorer, matchCost float64, captureLeadMatch bool) (*SloppyPhraseMatcher, error) {
+
+	matcher := &SloppyPhraseMatcher{
+		phrasePositions:  make([]*PhrasePositions, 0, len(postings)),
+		slop:             slop,
+		numPostings:      len(postings),
+		pq:               newPhraseQueue(len(postings)),
+		captureLeadMatch: captureLeadMatch,
+		matchCost:        matchCost,
+	}
+
+	iterators := make([]types.DocIdSetIterator, 0, len(postings))
+	for i, posting := range postings {
+		matcher.phrasePositions = append(matcher.phrasePositions,
+			NewPhrasePositions(posting.postings, posting.position, i, posting.terms))
+		iterators = append(iterators, posting.postings)
+	}
+
+	approximation, err := IntersectIterators(iterators)
+	if err != nil {
+		return nil, err
+	}
+	matcher.approximation = approximation
+
+	// What would be a good upper bound of the sloppy frequency? A sum of the
+	// sub frequencies would be correct, but it is usually so much higher than
+	// the actual sloppy frequency that it doesn't help skip irrelevant
+	// documents. As a consequence for now, sloppy phrase queries use dummy
+	// impacts:
+	matcher.impactsApproximation = NewImpactsDISI(approximation, &dummyImpactsSource{}, scorer)
+	return matcher, nil
+}
+
+func (s *SloppyPhraseMatcher) Approximation() types.DocIdSetIterator {
+	return s.approximation
+}
+
+func (s *SloppyPhraseMatcher) ImpactsApproximation() *ImpactsDISI {
+	return s.impactsApproximation
+}
+
+func (s *SloppyPhraseMatcher) MaxFreq() (float64, error) {
+	// every term position in each postings list can be at the head of at most
+	// one matching phrase, so the maximum possible phrase freq is the sum of
+	// the freqs of the postings lists.
+	maxFreq := 0.0
+	for _, pp := range s.phrasePositions {
+		freq, err := pp.postings.Freq()
+		if err != nil {
+			return 0, err
+		}
+		maxFreq += float64(freq)
+	}
+	return maxFreq, nil
+}
+
+func (s *SloppyPhraseMatcher) Reset() error {
+	positioned, err := s.initPhrasePositions()
+	if err != nil {
+		return err
+	}
+	s.positioned = positioned
+	s.matchLength = math.MaxInt32
+	s.leadPosition = math.MaxInt32
+	return nil
+}
+
+func (s *SloppyPhraseMatcher) SloppyWeight() float64 {
+	return 1 / (1 + float64(s.matchLength))
+}
+
+func (s *SloppyPhraseMatcher) NextMatch() (bool, error) {
+	if !s.positioned {
+		return false, nil
+	}
+
+	// if the pq is not full, then positioned == false
+	pp, err := s.pq.Pop()
+	if err != nil {
+		return false, err
+	}
+	if err := s.captureLead(pp); err != nil {
+		return false, err
+	}
+	s.matchLength = s.end - pp.position
+	next := s.pq.Top().position
+	for {
+		ok, err := s.advancePP(pp)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			break
+		}
+
+		if s.hasRpts {
+			ok, err := s.advanceRpts(pp)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				break // pps exhausted
+			}
+		}
+
+		if pp.position > next { // done minimizing current match-length
+			s.pq.Add(pp)
+			if s.matchLength <= s.slop {
+				return true, nil
+			}
+			pp, err = s.pq.Pop()
+			if err != nil {
+				return false, err
+			}
+			next = s.pq.Top().position
+			s.matchLength = s.end - pp.position
+		} else {
+			matchLength2 := s.end - pp.position
+			if matchLength2 < s.matchLength {
+				s.matchLength = matchLength2
+			}
+		}
+
+		if err := s.captureLead(pp); err != nil {
+			return false, err
+		}
+	}
+	s.positioned = false
+	return s.matchLength <= s.slop, nil
+}
+
+func (s *SloppyPhraseMatcher) captureLead(pp *PhrasePositions) error {
+	if !s.captureLeadMatch {
+		return nil
+	}
+	s.leadOrd = pp.ord
+	s.leadPosition = pp.position + pp.offset
+
+	var err error
+	s.leadOffset, err = pp.postings.StartOffset()
+	if err != nil {
+		return err
+	}
+	s.leadEndOffset, err = pp.postings.EndOffset()
+	return err
+}
+
+func (s *SloppyPhraseMatcher) StartPosition() int {
+	// when a match is detected, the top postings is advanced until it has moved
+	// beyond its successor, to ensure that the match is of minimal width.  This
+	// means that we need to record the lead position before it is advanced.
+	// However, the priority queue doesn't guarantee that the top postings is in fact the
+	// earliest in the list, so we need to cycle through all terms to check.
+	// this is slow, but Matches is slow anyway...
+	leadPosition := s.leadPosition
+	for _, pp := range s.phrasePositions {
+		leadPosition = min(leadPosition, pp.position+pp.offset)
+	}
+	return leadPosition
+}
+
+func (s *SloppyPhraseMatcher) EndPosition() int {
+	endPosition := s.leadPosition
+	for _, pp := range s.phrasePositions {
+		if pp.ord != s.leadOrd {
+			endPosition = max(endPosition, pp.position+pp.offset)
+		}
+	}
+	return endPosition
+}
+
+func (s *SloppyPhraseMatcher) StartOffset() (int, error) {
+	// same as StartPosition, the lead offset was recorded before the lead was advanced
+	leadOffset := s.leadOffset
+	for _, pp := range s.phrasePositions {
+		offset, err := pp.postings.StartOffset()
+		if err != nil {
+			return 0, err
+		}
+		leadOffset = min(leadOffset, offset)
+	}
+	return leadOffset, nil
+}
+
+func (s *SloppyPhraseMatcher) EndOffset() (int, error) {
+	endOffset := s.leadEndOffset
+	for _, pp := range s.phrasePositions {
+		if pp.ord != s.leadOrd {
+			offset, err := pp.postings.EndOffset()
+			if err != nil {
+				return 0, err
+			}
+			endOffset = max(endOffset, offset)
+		}
+	}
+	return endOffset, nil
+}
+
+func (s *SloppyPhraseMatcher) GetMatchCost() float64 {
+	return s.matchCost
+}
+
+// advance a PhrasePosition and update 'end', return false if exhausted
+func (s *SloppyPhraseMatcher) advancePP(pp *PhrasePositions) (bool, error) {
+	ok, err := pp.nextPosition()
+	if err != nil || !ok {
+		return false, err
+	}
+	if pp.position > s.end {
+		s.end = pp.position
+	}
+	return true, nil
+}
+
+// pp was just advanced. If that caused a repeater collision, resolve by advancing the lesser
+// of the two colliding pps. Note that there can only be one collision, as by the initialization
+// there were no collisions before pp was advanced.
+func (s *SloppyPhraseMatcher) advanceRpts(pp *PhrasePositions) (bool, error) {
+	if pp.rptGroup < 0 {
+		return true, nil // not a repeater
+	}
+	rg := s.rptGroups[pp.rptGroup]
+	bits := bitset.New(uint(len(rg))) // for re-queuing after collisions are resolved
+	k0 := pp.rptInd
+	for {
+		k := s.collide(pp)
+		if k < 0 {
+			break
+		}
+		pp = s.lesser(pp, rg[k]) // always advance the lesser of the (only) two colliding pps
+		ok, err := s.advancePP(pp)
+		if err != nil || !ok {
+			return false, err // exhausted
+		}
+		if k != k0 { // careful: mark only those currently in the queue
+			bits.Set(uint(k)) // mark that pp2 need to be re-queued
+		}
+	}
+
+	// collisions resolved, now re-queue
+	// empty (partially) the queue until seeing all pps advanced for resolving collisions
+	n := 0
+	for bits.Any() {
+		pp2, err := s.pq.Pop()
+		if err != nil {
+			return false, err
+		}
+		s.rptStack[n] = pp2
+		n++
+		if pp2.rptGroup >= 0 && bits.Test(uint(pp2.rptInd)) {
+			bits.Clear(uint(pp2.rptInd))
+		}
+	}
+
+	// add back to queue
+	for i := n - 1; i >= 0; i-- {
+		s.pq.Add(s.rptStack[i])
+	}
+	return true, nil
+}
+
+// compare two pps, but only by position and offset
+func (s *SloppyPhraseMatcher) lesser(pp, pp2 *PhrasePositions) *PhrasePositions {
+	if pp.position < pp2.position || (pp.position == pp2.position && pp.offset < pp2.offset) {
+		return pp
+	}
+	return pp2
+}
+
+// index of a pp2 colliding with pp, or -1 if none
+func (s *SloppyPhraseMatcher) collide(pp *PhrasePositions) int {
+	tpPos := s.tpPos(pp)
+	for _, pp2 := range s.rptGroups[pp.rptGroup] {
+		if pp2 != pp && s.tpPos(pp2) == tpPos {
+			return pp2.rptInd
+		}
+	}
+	return -1
+}
+
+// Initialize PhrasePositions in place.
+// A one time initialization for this scorer (on first doc matching all terms):
+// * Check if there are repetitions
+// * If there are, find groups of repetitions.
+// Examples:
+// 1. no repetitions: "ho my"~2
+// 2. repetitions: "ho my my"~2
+// 3. repetitions: "my ho my"~2
+// Returns false if PPs are exhausted (and so current doc will not be a match)
+func (s *SloppyPhraseMatcher) initPhrasePositions() (bool, error) {
+	s.end = math.MinInt32
+	if !s.checkedRpts {
+		return s.initFirstTime()
+	}
+	if !s.hasRpts {
+		if err := s.initSimple(); err != nil {
+			return false, err
+		}
+		return true, nil // PPs available
+	}
+	return s.initComplex()
+}
+
+// no repeats: simplest case, and most common. It is important to keep this piece of the code simple and efficient
+func (s *SloppyPhraseMatcher) initSimple() error {
+	s.pq.Clear()
+	// position pps and build queue from list
+	for _, pp := range s.phrasePositions {
+		if _, err := pp.firstPosition(); err != nil {
+			return err
+		}
+		if pp.position > s.end {
+			s.end = pp.position
+		}
+		s.pq.Add(pp)
+	}
+	return nil
+}
+
+// with repeats: not so simple.
+func (s *SloppyPhraseMatcher) initComplex() (bool, error) {
+	if err := s.placeFirstPositions(); err != nil {
+		return false, err
+	}
+	ok, err := s.advanceRepeatGroups()
+	if err != nil || !ok {
+		return false, err // PPs exhausted
+	}
+	s.fillQueue()
+	return true, nil // PPs available
+}
+
+// move all PPs to their first position
+func (s *SloppyPhraseMatcher) placeFirstPositions() error {
+	for _, pp := range s.phrasePositions {
+		if _, err := pp.firstPosition(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fill the queue (all pps are already placed
+func (s *SloppyPhraseMatcher) fillQueue() {
+	s.pq.Clear()
+	for _, pp := range s.phrasePositions { // iterate cyclic list: done once handled max
+		if pp.position > s.end {
+			s.end = pp.position
+		}
+		s.pq.Add(pp)
+	}
+}
+
+// At initialization (each doc), each repetition group is sorted by (query) offset.
+// This provides the start condition: no collisions.
+//
+// Case 1: no multi-term repeats
+// It is sufficient to advance each pp in the group by one less than its group index.
+// So lesser pp is not advanced, 2nd one advance once, 3rd one advanced twice, etc.
+//
+// Case 2: multi-term repeats
+//
+// Returns false if PPs are exhausted.
+func (s *SloppyPhraseMatcher) advanceRepeatGroups() (bool, error) {
+	for _, rg := range s.rptGroups {
+		if s.hasMultiTermRpts {
+			// more involved, some may not collide
+			incr := 1
+			for i := 0; i < len(rg); i += incr {
+				incr = 1
+				pp := rg[i]
+				for {
+					k := s.collide(pp)
+					if k < 0 {
+						break
+					}
+					pp2 := s.lesser(pp, rg[k])
+					// at initialization always advance pp with higher offset
+					ok, err := s.advancePP(pp2)
+					if err != nil || !ok {
+						return false, err // exhausted
+					}
+					if pp2.rptInd < i { // should not happen?
+						incr = 0
+						break
+					}
+				}
+			}
+		} else {
+			// simpler, we know exactly how much to advance
+			for j := 1; j < len(rg); j++ {
+				for k := 0; k < j; k++ {
+					ok, err := rg[j].nextPosition()
+					if err != nil || !ok {
+						return false, err // PPs exhausted
+					}
+				}
+			}
+		}
+	}
+	return true, nil // PPs available
+}
+
+// initialize with checking for repeats. Heavy work, but done only for the first candidate doc.
+// If there are repetitions, check if multi-term postings (MTP) are involved.
+// Without MTP, once PPs are placed in the first candidate doc, repeats (and groups) are visible.
+// With MTP, a more complex check is needed, up-front, as there may be "hidden collisions".
+// For example P1 has {A,B}, P1 has {B,C}, and the first doc is: "A C B". At start, P1 would point
+// to "A", p2 to "C", and it will not be identified that P1 and P2 are repetitions of each other.
+// The more complex initialization has two parts:
+// (1) identification of repetition groups.
+// (2) advancing repeat groups at the start of the doc.
+// For (1), a possible solution is to just create a single repetition group,
+// made of all repeating pps. But this would slow down the check for collisions,
+// as all pps would need to be checked. Instead, we compute "connected regions"
+// on the bipartite graph of postings and terms.
+func (s *SloppyPhraseMatcher) initFirstTime() (bool, error) {
+	s.checkedRpts = true
+	if err := s.placeFirstPositions(); err != nil {
+		return false, err
+	}
+
+	rptTerms, rptTermList := s.repeatingTerms()
+	s.hasRpts = len(rptTerms) > 0
+
+	if s.hasRpts {
+		s.rptStack = make([]*PhrasePositions, s.numPostings) // needed with repetitions
+		rgs := s.gatherRptGroups(rptTerms, rptTermList)
+		s.sortRptGroups(rgs)
+		ok, err := s.advanceRepeatGroups()
+		if err != nil || !ok {
+			return false, err // PPs exhausted
+		}
+	}
+
+	s.fillQueue()
+	return true, nil // PPs available
+}
+
+// sort each repetition group by (query) offset.
+// Done only once (at first doc) and allows to initialize faster for each doc.
+func (s *SloppyPhraseMatcher) sortRptGroups(rgs [][]*PhrasePositions) {
+	s.rptGroups = make([][]*PhrasePositions, len(rgs))
+	for i, rg := range rgs {
+		slices.SortStableFunc(rg, func(pp1, pp2 *PhrasePositions) int {
+			return pp1.offset - pp2.offset
+		})
+		s.rptGroups[i] = rg
+		for j, pp := range rg {
+			pp.rptInd = j // we use this index for efficient re-queuing
+		}
+	}
+}
+
+// Detect repetition groups. Done once - for first doc
+func (s *SloppyPhraseMatcher) gatherRptGroups(rptTerms map[string]int, rptTermList []string) [][]*PhrasePositions {
+	rpp := s.repeatingPPs(rptTerms)
+	res := make([][]*PhrasePositions, 0)
+	if !s.hasMultiTermRpts {
+		// simpler - no multi-terms - can base on positions in first doc
+		for i, pp := range rpp {
+			if pp.rptGroup >= 0 {
+				continue // already marked as a repetition
+			}
+			tpPos := s.tpPos(pp)
+			for _, pp2 := range rpp[i+1:] {
+				if pp2.rptGroup >= 0 || // already marked as a repetition
+					pp2.offset == pp.offset || // not a repetition: two PPs are originally in same offset in the query!
+					s.tpPos(pp2) != tpPos { // not a repetition
+					continue
+				}
+				// a repetition
+				g := pp.rptGroup
+				if g < 0 {
+					g = len(res)
+					pp.rptGroup = g
+					res = append(res, []*PhrasePositions{pp})
+				}
+				pp2.rptGroup = g
+				res[g] = append(res[g], pp2)
+			}
+		}
+		return res
+	}
+
+	// more involved - has multi-terms
+	bb := s.ppTermsBitSets(rpp, rptTerms)
+	bb = s.unionTermGroups(bb)
+	tg := s.termGroups(rptTermList, bb)
+	for range bb {
+		res = append(res, make([]*PhrasePositions, 0))
+	}
+	for _, pp := range rpp {
+		for _, t := range pp.terms {
+			if _, ok := rptTerms[t.Text()]; ok {
+				g := tg[t.Text()]
+				if !slices.Contains(res[g], pp) {
+					res[g] = append(res[g], pp)
+				}
+				pp.rptGroup = g
+			}
+		}
+	}
+	return res
+}
+
+// Actual position in doc of a PhrasePosition, relies on that position = tpPos - offset)
+func (s *SloppyPhraseMatcher) tpPos(pp *PhrasePositions) int {
+	return pp.position + pp.offset
+}
+
+// find repeating terms and assign them ordinal values, all the terms of a phrase belong
+// to the same field so they are keyed by their text.
+func (s *SloppyPhraseMatcher) repeatingTerms() (map[string]int, []string) {
+	tord := make(map[string]int)
+	tordList := make([]string, 0)
+	tcnt := make(map[string]int)
+	for _, pp := range s.phrasePositions {
+		for _, t := range pp.terms {
+			text := t.Text()
+			tcnt[text]++
+			if tcnt[text] == 2 {
+				tord[text] = len(tordList)
+				tordList = append(tordList, text)
+			}
+		}
+	}
+	return tord, tordList
+}
+
+// find repeating pps, and for each, if has multi-terms, update this.hasMultiTermRpts
+func (s *SloppyPhraseMatcher) repeatingPPs(rptTerms map[string]int) []*PhrasePositions {
+	rp := make([]*PhrasePositions, 0)
+	for _, pp := range s.phrasePositions {
+		for _, t := range pp.terms {
+			if _, ok := rptTerms[t.Text()]; ok {
+				rp = append(rp, pp)
+				s.hasMultiTermRpts = s.hasMultiTermRpts || len(pp.terms) > 1
+				break
+			}
+		}
+	}
+	return rp
+}
+
+// bit-sets - for each repeating pp, for each of its repeating terms, the term ordinal values is set
+func (s *SloppyPhraseMatcher) ppTermsBitSets(rpp []*PhrasePositions, tord map[string]int) []*bitset.BitSet {
+	bb := make([]*bitset.BitSet, 0, len(rpp))
+	for _, pp := range rpp {
+		b := bitset.New(uint(len(tord)))
+		for _, t := range pp.terms {
+			if ord, ok := tord[t.Text()]; ok {
+				b.Set(uint(ord))
+			}
+		}
+		bb = append(bb, b)
+	}
+	return bb
+}
+
+// union (term group) bit-sets until they are disjoint (O(n^^2)), and each group have different terms
+func (s *SloppyPhraseMatcher) unionTermGroups(bb []*bitset.BitSet) []*bitset.BitSet {
+	incr := 1
+	for i := 0; i < len(bb)-1; i += incr {
+		incr = 1
+		j := i + 1
+		for j < len(bb) {
+			if bb[i].IntersectionCardinality(bb[j]) > 0 {
+				bb[i].InPlaceUnion(bb[j])
+				bb = slices.Delete(bb, j, j+1)
+				incr = 0
+			} else {
+				j++
+			}
+		}
+	}
+	return bb
+}
+
+// map each term to the single group that contains it
+func (s *SloppyPhraseMatcher) termGroups(tord []string, bb []*bitset.BitSet) map[string]int {
+	tg := make(map[string]int)
+	for i, bits := range bb { // i is the group no.
+		for ord, ok := bits.NextSet(0); ok; ord, ok = bits.NextSet(ord + 1) {
+			tg[tord[ord]] = i
+		}
+	}
+	return tg
+}
+
+var _ index.ImpactsSource = &dummyImpactsSource{}
+
+// dummyImpactsSource
+// An ImpactsSource whose impacts trigger the maximum score on all documents.
+type dummyImpactsSource struct {
+}
+
+func (d *dummyImpactsSource) AdvanceShallow(ctx context.Context, target int) error {
+	return nil
+}
+
+func (d *dummyImpactsSource) GetImpacts() (index.Impacts, error) {
+	return &dummyImpacts{}, nil
+}
+
+var _ index.Impacts = &dummyImpacts{}
+
+type dummyImpacts struct {
+}
+
+func (d *dummyImpacts) NumLevels() int {
+	return 1
+}
+
+func (d *dummyImpacts) GetDocIdUpTo(level int) int {
+	return types.NO_MORE_DOCS
+}
+
+func (d *dummyImpacts) GetImpacts(level int) []index.Impact {
+	return []index.Impact{coreIndex.NewImpact(math.MaxInt32, 1)}
+}
@@ -0,0 +1,310 @@
+package search_test
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sortDocsPerSegment = 20
+	sortSegments       = 3
+	sortNumDocs        = sortDocsPerSegment * sortSegments
+)
+
+// sortValue Returns the value doc holds in the sort fields, many docs share a value. ok is false
+// for the docs without a value.
+func sortValue(doc int) (value int, ok bool) {
+	return (doc*37)%11 - 5, doc%7 != 3
+}
+
+// newSortTestSearcher Indexes sortNumDocs docs, each segment holds sortDocsPerSegment of them, the
+// global doc ids follow the order the docs were added in.
+func newSortTestSearcher(t *testing.T) *search.IndexSearcher {
+	segments := make([][]*document.Document, 0, sortSegments)
+	for i := 0; i < sortSegments; i++ {
+		docs := make([]*document.Document, 0, sortDocsPerSegment)
+		for j := 0; j < sortDocsPerSegment; j++ {
+			doc := document.NewDocument()
+			id := i*sortDocsPerSegment + j
+			doc.Add(document.NewTextField("body", strings.Repeat("common ", 1+id%3)+"filler", false))
+			if value, ok := sortValue(id); ok {
+				floatPoint, err := document.NewFloatPoint("float", float32(value)/2)
+				assert.Nil(t, err)
+				doublePoint, err := document.NewFloat64Point("double", float64(value)/4)
+				assert.Nil(t, err)
+
+				doc.Add(document.NewNumericDocValuesField("int", int64(value)))
+				doc.Add(document.NewIntPoint("int", int32(value)))
+				doc.Add(document.NewNumericDocValuesField("long", int64(value)*math.MaxInt32))
+				doc.Add(document.NewLongPoint("long", int64(value)*math.MaxInt32))
+				doc.Add(document.NewFloatDocValuesField("float", float32(value)/2))
+				doc.Add(floatPoint)
+				doc.Add(document.NewDoubleDocValuesField("double", float64(value)/4))
+				doc.Add(doublePoint)
+				doc.Add(document.NewSortedDocValuesField("string", []byte(fmt.Sprintf("v%02d", value+5))))
+			}
+			docs = append(docs, doc)
+		}
+		segments = append(segments, docs)
+	}
+
+	return searchtest.NewSearcher(t, segments...)
+}
+
+// sortValueOr Returns the value of doc, or missingValue for the docs without a value
+func sortValueOr(doc, missingValue int) int {
+	if value, ok := sortValue(doc); ok {
+		return value
+	}
+	return missingValue
+}
+
+// sortedDocs Returns all docs sorted by the value of sortValue, ties are broken by doc id
+func sortedDocs(missingValue int, reverse bool) []int {
+	docs := make([]int, sortNumDocs)
+	for i := range docs {
+		docs[i] = i
+	}
+	slices.SortStableFunc(docs, func(a, b int) int {
+		if reverse {
+			return cmp.Compare(sortValueOr(b, missingValue), sortValueOr(a, missingValue))
+		}
+		return cmp.Compare(sortValueOr(a, missingValue), sortValueOr(b, missingValue))
+	})
+	return docs
+}
+
+func docIDs(topDocs index.TopDocs) []int {
+	docs := make([]int, 0, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		docs = append(docs, scoreDoc.GetDoc())
+	}
+	return docs
+}
+
+func sortValues(topDocs index.TopDocs) []any {
+	values := make([]any, 0, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		values = append(values, scoreDoc.(search.FieldDoc).GetFields()[0])
+	}
+	return values
+}
+
+func TestSort_NumericFields(t *testing.T) {
+	searcher := newSortTestSearcher(t)
+
+	// value converts a value of sortValue to the value of the field
+	testCases := []struct {
+		field string
+		_type index.SortFieldType
+		value func(value int) any
+	}{
+		{"int", index.INT, func(value int) any { return int32(value) }},
+		{"long", index.LONG, func(value int) any { return int64(value) * math.MaxInt32 }},
+		{"float", index.FLOAT, func(value int) any { return float32(value) / 2 }},
+		{"double", index.DOUBLE, func(value int) any { return float64(value) / 4 }},
+	}
+
+	for _, tc := range testCases {
+		for _, reverse := range []bool{false, true} {
+			// the default missing value is 0, -100 sorts the docs without a value first and 100 last
+			for _, missingValue := range []int{0, -100, 100} {
+				t.Run(fmt.Sprintf("%s reverse=%t missing=%d", tc.field, reverse, missingValue), func(t *testing.T) {
+					sortField := coreIndex.NewSortFieldV1(tc.field, tc._type, reverse)
+					if missingValue != 0 {
+						assert.Nil(t, sortField.SetMissingValue(tc.value(missingValue)))
+					}
+					sort := coreIndex.NewSort([]index.SortField{sortField})
+
+					topDocs, err := searcher.SearchWithSort(context.Background(), search.NewMatchAllDocsQuery(), sortNumDocs, sort)
+					assert.Nil(t, err)
+					assert.Equal(t, int64(sortNumDocs), topDocs.GetTotalHits().Value)
+
+					expected := sortedDocs(missingValue, reverse)
+					assert.Equal(t, expected, docIDs(topDocs))
+
+					values := make([]any, 0, len(expected))
+					for _, doc := range expected {
+						values = append(values, tc.value(sortValueOr(doc, missingValue)))
+					}
+					assert.Equal(t, values, sortValues(topDocs))
+				})
+			}
+		}
+	}
+}
+
+func TestSort_String(t *testing.T) {
+	searcher := newSortTestSearcher(t)
+
+	for _, reverse := range []bool{false, true} {
+		for _, missingLast := range []bool{false, true} {
+			t.Run(fmt.Sprintf("reverse=%t missingLast=%t", reverse, missingLast), func(t *testing.T) {
+				sortField := coreIndex.NewSortFieldV1("string", index.STRING, reverse)
+				// docs without a value sort before all values by default, reverse sorts them last then
+				missingValue := -100
+				if missingLast {
+					assert.Nil(t, sortField.SetMissingValue(coreIndex.STRING_LAST))
+					missingValue = 100
+				}
+				sort := coreIndex.NewSort([]index.SortField{sortField})
+
+				topDocs, err := searcher.SearchWithSort(context.Background(), search.NewMatchAllDocsQuery(), sortNumDocs, sort)
+				assert.Nil(t, err)
+				assert.Equal(t, sortedDocs(missingValue, reverse), docIDs(topDocs))
+			})
+		}
+	}
+}
+
+func TestSort_ScoreAndDoc(t *testing.T) {
+	ctx := context.Background()
+	searcher := newSortTestSearcher(t)
+	query := search.NewTermQuery(coreIndex.NewTerm("body", []byte("common")))
+
+	// the relevance sort is the default order of the hits, equal scores are broken by doc id
+	expected, err := searcher.SearchTopN(ctx, query, sortNumDocs)
+	assert.Nil(t, err)
+	topDocs, err := searcher.SearchWithSort(ctx, query, sortNumDocs, coreIndex.NewSort([]index.SortField{coreIndex.FIELD_SCORE}))
+	assert.Nil(t, err)
+	assert.Equal(t, docIDs(expected), docIDs(topDocs))
+	for i, scoreDoc := range topDocs.GetScoreDocs() {
+		assert.Equal(t, expected.GetScoreDocs()[i].GetScore(), scoreDoc.(search.FieldDoc).GetFields()[0])
+	}
+	assert.NotEqual(t, docIDs(expected)[0], 0)
+
+	inOrder := make([]int, sortNumDocs)
+	for i := range inOrder {
+		inOrder[i] = i
+	}
+	topDocs, err = searcher.SearchWithSort(ctx, query, sortNumDocs, coreIndex.NewSort([]index.SortField{coreIndex.FIELD_DOC}))
+	assert.Nil(t, err)
+	assert.Equal(t, inOrder, docIDs(topDocs))
+
+	slices.Reverse(inOrder)
+	reversed := coreIndex.NewSortFieldV1("", index.DOC, true)
+	topDocs, err = searcher.SearchWithSort(ctx, query, sortNumDocs, coreIndex.NewSort([]index.SortField{reversed}))
+	assert.Nil(t, err)
+	assert.Equal(t, inOrder, docIDs(topDocs))
+}
+
+func TestSort_TieBreak(t *testing.T) {
+	ctx := context.Background()
+	searcher := newSortTestSearcher(t)
+
+	// a secondary sort by reversed doc id breaks the ties of the first field
+	sort := coreIndex.NewSort([]index.SortField{
+		coreIndex.NewSortField("int", index.INT),
+		coreIndex.NewSortFieldV1("", index.DOC, true),
+	})
+	topDocs, err := searcher.SearchWithSort(ctx, search.NewMatchAllDocsQuery(), sortNumDocs, sort)
+	assert.Nil(t, err)
+
+	expected := sortedDocs(0, false)
+	for from := 0; from < len(expected); {
+		value := sortValueOr(expected[from], 0)
+		to := from + 1
+		for to < len(expected) && sortValueOr(expected[to], 0) == value {
+			to++
+		}
+		slices.Reverse(expected[from:to])
+		from = to
+	}
+	assert.Equal(t, expected, docIDs(topDocs))
+
+	// ties are broken by doc id when the queue is full as well
+	topDocs, err = searcher.SearchWithSort(ctx, search.NewMatchAllDocsQuery(), 5,
+		coreIndex.NewSort([]index.SortField{coreIndex.NewSortField("int", index.INT)}))
+	assert.Nil(t, err)
+	assert.Equal(t, sortedDocs(0, false)[:5], docIDs(topDocs))
+}
+
+func TestSort_SearchAfter(t *testing.T) {
+	ctx := context.Background()
+	searcher := newSortTestSearcher(t)
+
+	for _, reverse := range []bool{false, true} {
+		sort := coreIndex.NewSort([]index.SortField{coreIndex.NewSortFieldV1("long", index.LONG, reverse)})
+
+		// pages smaller than a segment, not aligned on the segments
+		docs := make([]int, 0, sortNumDocs)
+		var after index.ScoreDoc
+		for {
+			topDocs, err := searcher.SearchAfterWithSort(ctx, after, search.NewMatchAllDocsQuery(), 7, sort)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(sortNumDocs), topDocs.GetTotalHits().Value)
+			scoreDocs := topDocs.GetScoreDocs()
+			if len(scoreDocs) == 0 {
+				break
+			}
+			docs = append(docs, docIDs(topDocs)...)
+			after = scoreDocs[len(scoreDocs)-1]
+		}
+		assert.Equal(t, sortedDocs(0, reverse), docs, "reverse=%t", reverse)
+	}
+
+	// after must come from a sorted search
+	topDocs, err := searcher.SearchTopN(ctx, search.NewMatchAllDocsQuery(), 7)
+	assert.Nil(t, err)
+	_, err = searcher.SearchAfterWithSort(ctx, topDocs.GetScoreDocs()[6], search.NewMatchAllDocsQuery(), 7,
+		coreIndex.NewSort([]index.SortField{coreIndex.FIELD_DOC}))
+	assert.NotNil(t, err)
+}
+
+func TestSort_IllegalType(t *testing.T) {
+	sortField := coreIndex.NewSortField("int", index.REWRITEABLE)
+	_, err := sortField.GetComparator(10, 0)
+	assert.NotNil(t, err)
+}
+
+func TestSort_SkipsNonCompetitiveDocs(t *testing.T) {
+	ctx := context.Background()
+
+	// many more docs than TOTAL_HITS_THRESHOLD, the values are a permutation of the docs
+	const numDocs = 10000
+	segments := make([][]*document.Document, 0, 2)
+	for i := 0; i < 2; i++ {
+		docs := make([]*document.Document, 0, numDocs/2)
+		for j := 0; j < numDocs/2; j++ {
+			value := int64((i*numDocs/2 + j) * 7919 % numDocs)
+			doc := document.NewDocument()
+			doc.Add(document.NewNumericDocValuesField("value", value))
+			doc.Add(document.NewLongPoint("value", value))
+			docs = append(docs, doc)
+		}
+		segments = append(segments, docs)
+	}
+	searcher := searchtest.NewSearcher(t, segments...)
+
+	for _, reverse := range []bool{false, true} {
+		baseline, err := searcher.SearchWithSort(ctx, search.NewMatchAllDocsQuery(), 10,
+			coreIndex.NewSort([]index.SortField{coreIndex.NewSortFieldV1("value", index.LONG, reverse)}))
+		assert.Nil(t, err)
+		// every doc is collected without the points, the count is only a lower bound once the
+		// threshold is reached
+		assert.Equal(t, int64(numDocs), baseline.GetTotalHits().Value)
+
+		sortField := coreIndex.NewSortFieldV1("value", index.LONG, reverse)
+		sortField.SetCanUsePoints()
+		topDocs, err := searcher.SearchWithSort(ctx, search.NewMatchAllDocsQuery(), 10,
+			coreIndex.NewSort([]index.SortField{sortField}))
+		assert.Nil(t, err)
+
+		assert.Equal(t, docIDs(baseline), docIDs(topDocs), "reverse=%t", reverse)
+		assert.Equal(t, sortValues(baseline), sortValues(topDocs), "reverse=%t", reverse)
+		assert.Equal(t, index.GREATER_THAN_OR_EQUAL_TO, topDocs.GetTotalHits().Relation)
+		assert.Less(t, topDocs.GetTotalHits().Value, int64(numDocs))
+	}
+}
@@ -0,0 +1,157 @@
+package spans
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// ConjunctionSpans
+// Common super class for multiple sub spans required in a document.
+type ConjunctionSpans struct {
+	subSpans    []Spans                // in query order
+	conjunction types.DocIdSetIterator // use to move to next doc with all clauses
+
+	// a first start position is available in current doc for nextStartPosition
+	atFirstInCurrentDoc bool
+
+	// one subspans exhausted in current doc
+	oneExhaustedInCurrentDoc bool
+
+	matcher conjunctionMatcher
+}
+
+type conjunctionMatcher interface {
+	// Called to position the spans on the first match of the current doc,
+	// returns whether the current doc of the conjunction matches.
+	twoPhaseCurrentDocMatches() (bool, error)
+}
+
+func newConjunctionSpans(subSpans []Spans, matcher conjunctionMatcher) (*ConjunctionSpans, error) {
+	if len(subSpans) < 2 {
+		return nil, errors.New("less than 2 subSpans")
+	}
+	conjunction, err := intersectSpans(subSpans)
+	if err != nil {
+		return nil, err
+	}
+	return &ConjunctionSpans{
+		subSpans:            subSpans,
+		conjunction:         conjunction,
+		atFirstInCurrentDoc: true, // ensure for doc -1 that start/end positions are -1
+		matcher:             matcher,
+	}, nil
+}
+
+// intersectSpans
+// Create a conjunction over the provided Spans, using the approximations of the spans that
+// support two-phase iteration.
+func intersectSpans(spans []Spans) (types.DocIdSetIterator, error) {
+	iterators := make([]types.DocIdSetIterator, 0, len(spans))
+	for _, s := range spans {
+		if twoPhase := s.AsTwoPhaseIterator(); twoPhase != nil {
+			iterators = append(iterators, search.AsDocIdSetIterator(twoPhase))
+		} else {
+			iterators = append(iterators, s)
+		}
+	}
+	return search.IntersectIterators(iterators)
+}
+
+func (c *ConjunctionSpans) DocID() int {
+	return c.conjunction.DocID()
+}
+
+func (c *ConjunctionSpans) Cost() int64 {
+	return c.conjunction.Cost()
+}
+
+func (c *ConjunctionSpans) NextDoc(ctx context.Context) (int, error) {
+	if _, err := c.conjunction.NextDoc(ctx); err != nil {
+		return types.NO_MORE_DOCS, err
+	}
+	return c.toMatchDoc(ctx)
+}
+
+func (c *ConjunctionSpans) Advance(ctx context.Context, target int) (int, error) {
+	if _, err := c.conjunction.Advance(ctx, target); err != nil {
+		return types.NO_MORE_DOCS, err
+	}
+	return c.toMatchDoc(ctx)
+}
+
+func (c *ConjunctionSpans) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, c, target)
+}
+
+func (c *ConjunctionSpans) toMatchDoc(ctx context.Context) (int, error) {
+	c.oneExhaustedInCurrentDoc = false
+	for {
+		if c.conjunction.DocID() == types.NO_MORE_DOCS {
+			return types.NO_MORE_DOCS, io.EOF
+		}
+		ok, err := c.matcher.twoPhaseCurrentDocMatches()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return c.DocID(), nil
+		}
+		if _, err := c.conjunction.NextDoc(ctx); err != nil {
+			return types.NO_MORE_DOCS, err
+		}
+	}
+}
+
+// AsTwoPhaseIterator
+// Return a TwoPhaseIterator view of this ConjunctionSpans.
+func (c *ConjunctionSpans) AsTwoPhaseIterator() index.TwoPhaseIterator {
+	return &conjunctionSpansTwoPhaseIterator{
+		spans:     c,
+		matchCost: c.PositionsCost(),
+	}
+}
+
+// PositionsCost
+// The total matchCost/positionsCost of the sub spans, AsTwoPhaseIterator never returns nil
+// here so this is only used as the match cost of the two-phase view.
+func (c *ConjunctionSpans) PositionsCost() float64 {
+	totalMatchCost := 0.0
+	for _, spans := range c.subSpans {
+		if twoPhase := spans.AsTwoPhaseIterator(); twoPhase != nil {
+			totalMatchCost += twoPhase.MatchCost()
+		} else {
+			totalMatchCost += spans.PositionsCost()
+		}
+	}
+	return totalMatchCost
+}
+
+// GetSubSpans
+// Returns the sub spans, in query order.
+func (c *ConjunctionSpans) GetSubSpans() []Spans {
+	return c.subSpans
+}
+
+var _ index.TwoPhaseIterator = &conjunctionSpansTwoPhaseIterator{}
+
+type conjunctionSpansTwoPhaseIterator struct {
+	spans     *ConjunctionSpans
+	matchCost float64
+}
+
+func (c *conjunctionSpansTwoPhaseIterator) Approximation() types.DocIdSetIterator {
+	return c.spans.conjunction
+}
+
+func (c *conjunctionSpansTwoPhaseIterator) Matches() (bool, error) {
+	return c.spans.matcher.twoPhaseCurrentDocMatches()
+}
+
+func (c *conjunctionSpansTwoPhaseIterator) MatchCost() float64 {
+	return c.matchCost
+}
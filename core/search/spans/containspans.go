@@ -0,0 +1,58 @@
+package spans
+
+// ContainSpans
+// The conjunction of a big and a little Spans, the positions are taken from the source spans,
+// which is either of the two.
+type ContainSpans struct {
+	*ConjunctionSpans
+
+	sourceSpans Spans
+	bigSpans    Spans
+	littleSpans Spans
+}
+
+func newContainSpans(bigSpans, littleSpans, sourceSpans Spans, matcher conjunctionMatcher) (*ContainSpans, error) {
+	conjunction, err := newConjunctionSpans([]Spans{bigSpans, littleSpans}, matcher)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainSpans{
+		ConjunctionSpans: conjunction,
+		sourceSpans:      sourceSpans,
+		bigSpans:         bigSpans,
+		littleSpans:      littleSpans,
+	}, nil
+}
+
+func (c *ContainSpans) StartPosition() int {
+	switch {
+	case c.atFirstInCurrentDoc:
+		return -1
+	case c.oneExhaustedInCurrentDoc:
+		return NO_MORE_POSITIONS
+	default:
+		return c.sourceSpans.StartPosition()
+	}
+}
+
+func (c *ContainSpans) EndPosition() int {
+	switch {
+	case c.atFirstInCurrentDoc:
+		return -1
+	case c.oneExhaustedInCurrentDoc:
+		return NO_MORE_POSITIONS
+	default:
+		return c.sourceSpans.EndPosition()
+	}
+}
+
+func (c *ContainSpans) Width() int {
+	return c.sourceSpans.Width()
+}
+
+func (c *ContainSpans) Collect(collector SpanCollector) error {
+	if err := c.bigSpans.Collect(collector); err != nil {
+		return err
+	}
+	return c.littleSpans.Collect(collector)
+}
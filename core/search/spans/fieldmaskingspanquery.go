@@ -0,0 +1,113 @@
+package spans
+
+import (
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanQuery = &FieldMaskingSpanQuery{}
+
+// FieldMaskingSpanQuery
+// Wrapper to allow SpanQuery objects participate in composite single-field SpanQueries by 'lying'
+// about their search field. That is, the masked SpanQuery will function as normal, but GetField()
+// simply hands back the value supplied in this class's constructor.
+//
+// This can be used to support Queries like SpanNearQuery or SpanOrQuery across different fields,
+// which is not ordinarily permitted.
+//
+// This can be useful for denormalized relational data: for example, when indexing a document with
+// conceptually many 'children':
+//
+//	teacherid: 1
+//	studentfirstname: james
+//	studentsurname: jones
+//
+//	teacherid: 2
+//	studenfirstname: james
+//	studentsurname: smith
+//	studentfirstname: sally
+//	studentsurname: jones
+//
+// a SpanNearQuery with a slop of 0 can be applied across two SpanTermQuery objects as follows:
+//
+//	q1 := NewSpanTermQuery(NewTerm("studentfirstname", []byte("james")))
+//	q2 := NewSpanTermQuery(NewTerm("studentsurname", []byte("jones")))
+//	q2m := NewFieldMaskingSpanQuery(q2, "studentfirstname")
+//	q, err := NewSpanNearQuery([]SpanQuery{q1, q2m}, -1, false)
+//
+// to search for 'studentfirstname:james studentsurname:jones' and find teacherid 1 without matching
+// teacherid 2 (which has a 'james' in position 0 and 'jones' in position 1).
+//
+// Note: as GetField() returns the masked field, scoring will be done using the Similarity and
+// collection statistics of the field name supplied, but with the term statistics of the real field.
+// This may lead to exceptions, poor performance, and unexpected scoring behaviour.
+type FieldMaskingSpanQuery struct {
+	maskedQuery SpanQuery
+	field       string
+}
+
+func NewFieldMaskingSpanQuery(maskedQuery SpanQuery, maskedField string) *FieldMaskingSpanQuery {
+	return &FieldMaskingSpanQuery{
+		maskedQuery: maskedQuery,
+		field:       maskedField,
+	}
+}
+
+func (f *FieldMaskingSpanQuery) GetField() string {
+	return f.field
+}
+
+// GetMaskedQuery
+// Returns the wrapped query, searching its real field.
+func (f *FieldMaskingSpanQuery) GetMaskedQuery() SpanQuery {
+	return f.maskedQuery
+}
+
+// CreateWeight
+// ...this is the work horse method, it delegates to the masked query, but the spans it
+// produces are reported under the masked field.
+func (f *FieldMaskingSpanQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return f.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (f *FieldMaskingSpanQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	return f.maskedQuery.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (f *FieldMaskingSpanQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	rewritten, err := rewriteSpanQuery(f.maskedQuery, reader)
+	if err != nil {
+		return nil, err
+	}
+	if rewritten != f.maskedQuery {
+		return NewFieldMaskingSpanQuery(rewritten, f.field), nil
+	}
+	return f, nil
+}
+
+func (f *FieldMaskingSpanQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(f.field) {
+		return f.maskedQuery.Visit(visitor.GetSubVisitor(index.OccurMust, f))
+	}
+	return nil
+}
+
+func (f *FieldMaskingSpanQuery) String(field string) string {
+	return fmt.Sprintf("mask(%s) as %s", f.maskedQuery.String(field), f.field)
+}
+
+func (f *FieldMaskingSpanQuery) Equals(other index.Query) bool {
+	o, ok := other.(*FieldMaskingSpanQuery)
+	if !ok {
+		return false
+	}
+	return f.field == o.field && search.QueryEquals(f.maskedQuery, o.maskedQuery)
+}
+
+func (f *FieldMaskingSpanQuery) HashCode() int {
+	h := search.ClassHash("FieldMaskingSpanQuery")
+	h = 31*h + search.QueryHashCode(f.maskedQuery)
+	return 31*h + search.HashString(f.field)
+}
@@ -0,0 +1,255 @@
+package spans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// AcceptStatus
+// Status returned from the accept function of a FilterSpans that indicates whether a candidate match
+// should be accepted, rejected, or rejected and move on to the next document.
+type AcceptStatus int
+
+const (
+	// ACCEPT_STATUS_YES Indicates the match should be accepted
+	ACCEPT_STATUS_YES = AcceptStatus(iota)
+
+	// ACCEPT_STATUS_NO Indicates the match should be rejected
+	ACCEPT_STATUS_NO
+
+	// ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC
+	// Indicates the match should be rejected, and the enumeration may continue with the next document.
+	ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC
+)
+
+// AcceptFunc
+// Returns YES if the candidate should be an accepted match, NO if it should not, and
+// NO_MORE_IN_CURRENT_DOC if iteration should move on to the next document.
+type AcceptFunc func(candidate Spans) (AcceptStatus, error)
+
+var _ Spans = &FilterSpans{}
+
+// FilterSpans
+// A Spans implementation wrapping another spans instance, allowing to filter spans matches easily
+// by providing an AcceptFunc
+type FilterSpans struct {
+	in                  Spans // The wrapped spans instance.
+	accept              AcceptFunc
+	atFirstInCurrentDoc bool
+	startPos            int
+}
+
+// NewFilterSpans
+// Wrap the given Spans.
+func NewFilterSpans(in Spans, accept AcceptFunc) *FilterSpans {
+	return &FilterSpans{
+		in:       in,
+		accept:   accept,
+		startPos: -1,
+	}
+}
+
+func (f *FilterSpans) NextDoc(ctx context.Context) (int, error) {
+	for {
+		doc, err := f.in.NextDoc(ctx)
+		if err != nil {
+			return doc, err
+		}
+		if doc == types.NO_MORE_DOCS {
+			return types.NO_MORE_DOCS, io.EOF
+		}
+		ok, err := f.twoPhaseCurrentDocMatches()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return doc, nil
+		}
+	}
+}
+
+func (f *FilterSpans) Advance(ctx context.Context, target int) (int, error) {
+	doc, err := f.in.Advance(ctx, target)
+	for {
+		if err != nil {
+			return doc, err
+		}
+		if doc == types.NO_MORE_DOCS {
+			return types.NO_MORE_DOCS, io.EOF
+		}
+		ok, err := f.twoPhaseCurrentDocMatches()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return doc, nil
+		}
+		doc, err = f.in.NextDoc(ctx)
+	}
+}
+
+func (f *FilterSpans) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, f, target)
+}
+
+func (f *FilterSpans) DocID() int {
+	return f.in.DocID()
+}
+
+func (f *FilterSpans) NextStartPosition() (int, error) {
+	if f.atFirstInCurrentDoc {
+		f.atFirstInCurrentDoc = false
+		return f.startPos, nil
+	}
+
+	for {
+		startPos, err := f.in.NextStartPosition()
+		if err != nil {
+			return 0, err
+		}
+		f.startPos = startPos
+		if startPos == NO_MORE_POSITIONS {
+			return NO_MORE_POSITIONS, nil
+		}
+		status, err := f.accept(f.in)
+		if err != nil {
+			return 0, err
+		}
+		switch status {
+		case ACCEPT_STATUS_YES:
+			return f.startPos, nil
+		case ACCEPT_STATUS_NO:
+		case ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC:
+			f.startPos = NO_MORE_POSITIONS // startPos ahead for the current doc.
+			return f.startPos, nil
+		default:
+			return 0, errors.New("unknown AcceptStatus")
+		}
+	}
+}
+
+func (f *FilterSpans) StartPosition() int {
+	if f.atFirstInCurrentDoc {
+		return -1
+	}
+	return f.startPos
+}
+
+func (f *FilterSpans) EndPosition() int {
+	if f.atFirstInCurrentDoc {
+		return -1
+	}
+	if f.startPos != NO_MORE_POSITIONS {
+		return f.in.EndPosition()
+	}
+	return NO_MORE_POSITIONS
+}
+
+func (f *FilterSpans) Width() int {
+	return f.in.Width()
+}
+
+func (f *FilterSpans) Collect(collector SpanCollector) error {
+	return f.in.Collect(collector)
+}
+
+func (f *FilterSpans) Cost() int64 {
+	return f.in.Cost()
+}
+
+func (f *FilterSpans) String() string {
+	return fmt.Sprintf("Filter(%v)", f.in)
+}
+
+func (f *FilterSpans) AsTwoPhaseIterator() index.TwoPhaseIterator {
+	inner := f.in.AsTwoPhaseIterator()
+	if inner != nil {
+		// wrapped instance has an approximation
+		return &filterSpansTwoPhaseIterator{
+			spans:         f,
+			approximation: inner.Approximation(),
+			inner:         inner,
+			matchCost:     inner.MatchCost(), // underestimate
+		}
+	}
+	// wrapped instance has no approximation, but
+	// we can still defer matching cost.
+	return &filterSpansTwoPhaseIterator{
+		spans:         f,
+		approximation: f.in,
+		matchCost:     f.in.PositionsCost(), // overestimate
+	}
+}
+
+// PositionsCost
+// AsTwoPhaseIterator never returns nil, use the match cost of the two-phase view instead.
+func (f *FilterSpans) PositionsCost() float64 {
+	return f.AsTwoPhaseIterator().MatchCost()
+}
+
+// Returns true if the current document matches.
+// This is called during two-phase processing.
+func (f *FilterSpans) twoPhaseCurrentDocMatches() (bool, error) {
+	f.atFirstInCurrentDoc = false
+	startPos, err := f.in.NextStartPosition()
+	if err != nil {
+		return false, err
+	}
+	f.startPos = startPos
+	for f.startPos != NO_MORE_POSITIONS {
+		status, err := f.accept(f.in)
+		if err != nil {
+			return false, err
+		}
+		switch status {
+		case ACCEPT_STATUS_YES:
+			f.atFirstInCurrentDoc = true
+			return true, nil
+		case ACCEPT_STATUS_NO:
+			startPos, err := f.in.NextStartPosition()
+			if err != nil {
+				return false, err
+			}
+			f.startPos = startPos
+		case ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC:
+			f.startPos = -1
+			return false, nil
+		default:
+			return false, errors.New("unknown AcceptStatus")
+		}
+	}
+	f.startPos = -1
+	return false, nil
+}
+
+var _ index.TwoPhaseIterator = &filterSpansTwoPhaseIterator{}
+
+type filterSpansTwoPhaseIterator struct {
+	spans         *FilterSpans
+	approximation types.DocIdSetIterator
+	inner         index.TwoPhaseIterator
+	matchCost     float64
+}
+
+func (f *filterSpansTwoPhaseIterator) Approximation() types.DocIdSetIterator {
+	return f.approximation
+}
+
+func (f *filterSpansTwoPhaseIterator) Matches() (bool, error) {
+	if f.inner != nil {
+		ok, err := f.inner.Matches()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return f.spans.twoPhaseCurrentDocMatches()
+}
+
+func (f *filterSpansTwoPhaseIterator) MatchCost() float64 {
+	return f.matchCost
+}
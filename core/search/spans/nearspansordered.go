@@ -0,0 +1,166 @@
+package spans
+
+import (
+	"fmt"
+)
+
+var _ Spans = &NearSpansOrdered{}
+
+// NearSpansOrdered
+// A Spans that is formed from the ordered subspans of a SpanNearQuery where the subspans do not overlap
+// and have a maximum slop between them.
+//
+// The formed spans only contains minimum slop matches.
+// The matching slop is computed from the distance(s) between the non overlapping matching Spans.
+//
+// Successive matches are always formed from the successive Spans of the SpanNearQuery.
+//
+// The formed spans may contain overlaps when the slop is at least 1. For example, when querying using
+//
+//	t1 t2 t3
+//
+// with slop at least 1, the fragment:
+//
+//	t1 t2 t1 t3 t2 t3
+//
+// matches twice:
+//
+//	t1 t2 .. t3
+//	      t1 t3 t2 t3
+//
+// Expert: Only public for subclassing. Most implementations should not need this class
+type NearSpansOrdered struct {
+	*ConjunctionSpans
+
+	matchStart  int
+	matchEnd    int
+	matchWidth  int
+	allowedSlop int
+}
+
+func NewNearSpansOrdered(allowedSlop int, subSpans []Spans) (*NearSpansOrdered, error) {
+	spans := &NearSpansOrdered{
+		matchStart:  -1,
+		matchEnd:    -1,
+		matchWidth:  -1,
+		allowedSlop: allowedSlop,
+	}
+	conjunction, err := newConjunctionSpans(subSpans, spans)
+	if err != nil {
+		return nil, err
+	}
+	spans.ConjunctionSpans = conjunction
+	return spans, nil
+}
+
+func (n *NearSpansOrdered) twoPhaseCurrentDocMatches() (bool, error) {
+	n.oneExhaustedInCurrentDoc = false
+	return n.nextMatch()
+}
+
+// nextMatch
+// Moves the first sub spans forward until the sub spans are ordered within the allowed slop.
+func (n *NearSpansOrdered) nextMatch() (bool, error) {
+	for {
+		start, err := n.subSpans[0].NextStartPosition()
+		if err != nil {
+			return false, err
+		}
+		if start == NO_MORE_POSITIONS || n.oneExhaustedInCurrentDoc {
+			return false, nil
+		}
+		ordered, err := n.stretchToOrder()
+		if err != nil {
+			return false, err
+		}
+		if ordered && n.matchWidth <= n.allowedSlop {
+			n.atFirstInCurrentDoc = true
+			return true, nil
+		}
+	}
+}
+
+func (n *NearSpansOrdered) NextStartPosition() (int, error) {
+	if n.atFirstInCurrentDoc {
+		n.atFirstInCurrentDoc = false
+		return n.matchStart, nil
+	}
+	n.oneExhaustedInCurrentDoc = false
+	ok, err := n.nextMatch()
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		n.atFirstInCurrentDoc = false
+		return n.matchStart, nil
+	}
+	n.matchStart, n.matchEnd = NO_MORE_POSITIONS, NO_MORE_POSITIONS
+	return NO_MORE_POSITIONS, nil
+}
+
+// Order the subSpans within the same document by using nextStartPosition on all subSpans
+// after the first as little as necessary.
+// Return true when the subSpans could be ordered in this way, otherwise at least one is
+// exhausted in the current doc.
+func (n *NearSpansOrdered) stretchToOrder() (bool, error) {
+	prevSpans := n.subSpans[0]
+	n.matchStart = prevSpans.StartPosition()
+	n.matchWidth = 0
+	for _, spans := range n.subSpans[1:] {
+		position, err := advancePosition(spans, prevSpans.EndPosition())
+		if err != nil {
+			return false, err
+		}
+		if position == NO_MORE_POSITIONS {
+			n.oneExhaustedInCurrentDoc = true
+			return false, nil
+		}
+		n.matchWidth += spans.StartPosition() - prevSpans.EndPosition()
+		prevSpans = spans
+	}
+	n.matchEnd = n.subSpans[len(n.subSpans)-1].EndPosition()
+	return true, nil // all subSpans ordered and non overlapping
+}
+
+func advancePosition(spans Spans, position int) (int, error) {
+	if gapSpans, ok := spans.(*gapSpans); ok {
+		return gapSpans.skipToPosition(position), nil
+	}
+	for spans.StartPosition() < position {
+		if _, err := spans.NextStartPosition(); err != nil {
+			return 0, err
+		}
+	}
+	return spans.StartPosition(), nil
+}
+
+func (n *NearSpansOrdered) StartPosition() int {
+	if n.atFirstInCurrentDoc {
+		return -1
+	}
+	return n.matchStart
+}
+
+func (n *NearSpansOrdered) EndPosition() int {
+	if n.atFirstInCurrentDoc {
+		return -1
+	}
+	return n.matchEnd
+}
+
+func (n *NearSpansOrdered) Width() int {
+	return n.matchWidth
+}
+
+func (n *NearSpansOrdered) Collect(collector SpanCollector) error {
+	for _, spans := range n.subSpans {
+		if err := spans.Collect(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NearSpansOrdered) String() string {
+	return fmt.Sprintf("NearSpansOrdered(%v)@%d: %d - %d", n.subSpans, n.DocID(), n.StartPosition(), n.EndPosition())
+}
@@ -0,0 +1,180 @@
+package spans
+
+import (
+	"fmt"
+
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ Spans = &NearSpansUnordered{}
+
+// NearSpansUnordered
+// Similar to NearSpansOrdered, but for the unordered case.
+// Expert: Only public for subclassing. Most implementations should not need this class
+type NearSpansUnordered struct {
+	*ConjunctionSpans
+
+	allowedSlop int
+	spanWindow  *spanTotalLengthEndPositionWindow
+}
+
+func NewNearSpansUnordered(allowedSlop int, subSpans []Spans) (*NearSpansUnordered, error) {
+	spans := &NearSpansUnordered{
+		allowedSlop: allowedSlop,
+		spanWindow:  newSpanTotalLengthEndPositionWindow(subSpans),
+	}
+	conjunction, err := newConjunctionSpans(subSpans, spans)
+	if err != nil {
+		return nil, err
+	}
+	spans.ConjunctionSpans = conjunction
+	return spans, nil
+}
+
+// spanTotalLengthEndPositionWindow
+// Maintain totalSpanLength and maxEndPosition
+type spanTotalLengthEndPositionWindow struct {
+	*structure.PriorityQueue[Spans]
+
+	subSpans        []Spans
+	totalSpanLength int
+	maxEndPosition  int
+}
+
+func newSpanTotalLengthEndPositionWindow(subSpans []Spans) *spanTotalLengthEndPositionWindow {
+	return &spanTotalLengthEndPositionWindow{
+		PriorityQueue: structure.NewPriorityQueue[Spans](len(subSpans), positionsOrdered),
+		subSpans:      subSpans,
+	}
+}
+
+func (w *spanTotalLengthEndPositionWindow) startDocument() error {
+	w.Clear()
+	w.totalSpanLength = 0
+	w.maxEndPosition = -1
+	for _, spans := range w.subSpans {
+		if _, err := spans.NextStartPosition(); err != nil {
+			return err
+		}
+		w.Add(spans)
+		w.maxEndPosition = max(w.maxEndPosition, spans.EndPosition())
+		w.totalSpanLength += spans.EndPosition() - spans.StartPosition()
+	}
+	return nil
+}
+
+func (w *spanTotalLengthEndPositionWindow) nextPosition() (bool, error) {
+	topSpans := w.Top()
+	spanLength := topSpans.EndPosition() - topSpans.StartPosition()
+	nextStartPos, err := topSpans.NextStartPosition()
+	if err != nil {
+		return false, err
+	}
+	if nextStartPos == NO_MORE_POSITIONS {
+		return false, nil
+	}
+	w.totalSpanLength -= spanLength
+	w.totalSpanLength += topSpans.EndPosition() - topSpans.StartPosition()
+	w.maxEndPosition = max(w.maxEndPosition, topSpans.EndPosition())
+	w.UpdateTop()
+	return true, nil
+}
+
+func (w *spanTotalLengthEndPositionWindow) slop() int {
+	return w.maxEndPosition - w.Top().StartPosition() - w.totalSpanLength
+}
+
+func (w *spanTotalLengthEndPositionWindow) atMatch(allowedSlop int) bool {
+	return w.slop() <= allowedSlop
+}
+
+// positionsOrdered
+// Check whether two Spans in the same document are ordered with possible overlap.
+// Returns true iff spans1 starts before spans2 or the spans start at the same position,
+// and spans1 ends before spans2.
+func positionsOrdered(spans1, spans2 Spans) bool {
+	start1, start2 := spans1.StartPosition(), spans2.StartPosition()
+	if start1 == start2 {
+		return spans1.EndPosition() < spans2.EndPosition()
+	}
+	return start1 < start2
+}
+
+func (n *NearSpansUnordered) twoPhaseCurrentDocMatches() (bool, error) {
+	// at doc with all subSpans
+	if err := n.spanWindow.startDocument(); err != nil {
+		return false, err
+	}
+	for {
+		if n.spanWindow.atMatch(n.allowedSlop) {
+			n.atFirstInCurrentDoc = true
+			n.oneExhaustedInCurrentDoc = false
+			return true, nil
+		}
+		ok, err := n.spanWindow.nextPosition()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+}
+
+func (n *NearSpansUnordered) NextStartPosition() (int, error) {
+	if n.atFirstInCurrentDoc {
+		n.atFirstInCurrentDoc = false
+		return n.spanWindow.Top().StartPosition(), nil
+	}
+	for {
+		ok, err := n.spanWindow.nextPosition()
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			n.oneExhaustedInCurrentDoc = true
+			return NO_MORE_POSITIONS, nil
+		}
+		if n.spanWindow.atMatch(n.allowedSlop) {
+			return n.spanWindow.Top().StartPosition(), nil
+		}
+	}
+}
+
+func (n *NearSpansUnordered) StartPosition() int {
+	switch {
+	case n.atFirstInCurrentDoc:
+		return -1
+	case n.oneExhaustedInCurrentDoc:
+		return NO_MORE_POSITIONS
+	default:
+		return n.spanWindow.Top().StartPosition()
+	}
+}
+
+func (n *NearSpansUnordered) EndPosition() int {
+	switch {
+	case n.atFirstInCurrentDoc:
+		return -1
+	case n.oneExhaustedInCurrentDoc:
+		return NO_MORE_POSITIONS
+	default:
+		return n.spanWindow.maxEndPosition
+	}
+}
+
+// Width
+// The slop of the current match, like the width of NearSpansOrdered.
+func (n *NearSpansUnordered) Width() int {
+	return n.spanWindow.slop()
+}
+
+func (n *NearSpansUnordered) Collect(collector SpanCollector) error {
+	for _, spans := range n.subSpans {
+		if err := spans.Collect(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NearSpansUnordered) String() string {
+	return fmt.Sprintf("NearSpansUnordered(%v)@%d: %d - %d", n.subSpans, n.DocID(), n.StartPosition(), n.EndPosition())
+}
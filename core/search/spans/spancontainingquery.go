@@ -0,0 +1,116 @@
+package spans
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanContainQuerySPI = &SpanContainingQuery{}
+
+// SpanContainingQuery
+// Keep matches that contain another SpanScorer.
+type SpanContainingQuery struct {
+	*SpanContainQuery
+}
+
+// NewSpanContainingQuery
+// Construct a SpanContainingQuery matching spans from big that contain at least one spans from little.
+// This query has the boost of big. big and little must be in the same field.
+func NewSpanContainingQuery(big, little SpanQuery) (*SpanContainingQuery, error) {
+	query := &SpanContainingQuery{}
+	containQuery, err := newSpanContainQuery(big, little, query)
+	if err != nil {
+		return nil, err
+	}
+	query.SpanContainQuery = containQuery
+	return query, nil
+}
+
+func (s *SpanContainingQuery) String(field string) string {
+	return s.toString(field, "SpanContaining")
+}
+
+func (s *SpanContainingQuery) WithClauses(big, little SpanQuery) (SpanQuery, error) {
+	return NewSpanContainingQuery(big, little)
+}
+
+// GetContainSpans
+// Return spans from big that contain at least one spans from little. The payload is from the spans of big.
+func (s *SpanContainingQuery) GetContainSpans(bigSpans, littleSpans Spans) (Spans, error) {
+	spans := &spanContainingSpans{}
+	containSpans, err := newContainSpans(bigSpans, littleSpans, bigSpans, spans)
+	if err != nil {
+		return nil, err
+	}
+	spans.ContainSpans = containSpans
+	return spans, nil
+}
+
+var _ Spans = &spanContainingSpans{}
+
+type spanContainingSpans struct {
+	*ContainSpans
+}
+
+func (s *spanContainingSpans) twoPhaseCurrentDocMatches() (bool, error) {
+	s.oneExhaustedInCurrentDoc = false
+	ok, err := s.nextContaining()
+	if err != nil || !ok {
+		return false, err
+	}
+	s.atFirstInCurrentDoc = true
+	return true, nil
+}
+
+func (s *spanContainingSpans) NextStartPosition() (int, error) {
+	if s.atFirstInCurrentDoc {
+		s.atFirstInCurrentDoc = false
+		return s.bigSpans.StartPosition(), nil
+	}
+	ok, err := s.nextContaining()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return NO_MORE_POSITIONS, nil
+	}
+	return s.bigSpans.StartPosition(), nil
+}
+
+// nextContaining
+// Moves big to its next span that contains a span of little.
+func (s *spanContainingSpans) nextContaining() (bool, error) {
+	for {
+		bigStart, err := s.bigSpans.NextStartPosition()
+		if err != nil {
+			return false, err
+		}
+		if bigStart == NO_MORE_POSITIONS {
+			break
+		}
+		for s.littleSpans.StartPosition() < bigStart {
+			littleStart, err := s.littleSpans.NextStartPosition()
+			if err != nil {
+				return false, err
+			}
+			if littleStart == NO_MORE_POSITIONS {
+				s.oneExhaustedInCurrentDoc = true
+				return false, nil
+			}
+		}
+		if s.bigSpans.EndPosition() >= s.littleSpans.EndPosition() {
+			return true, nil
+		}
+	}
+	s.oneExhaustedInCurrentDoc = true
+	return false, nil
+}
+
+func (s *SpanContainingQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanContainingQuery)
+	return ok && s.equalsTo(o.SpanContainQuery)
+}
+
+func (s *SpanContainingQuery) HashCode() int {
+	return 31*search.ClassHash("SpanContainingQuery") + s.fieldsHashCode()
+}
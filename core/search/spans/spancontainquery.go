@@ -0,0 +1,168 @@
+package spans
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+// SpanContainQuery
+// Base of SpanContainingQuery and SpanWithinQuery, the query specific parts are provided by a
+// SpanContainQuerySPI.
+type SpanContainQuery struct {
+	big    SpanQuery
+	little SpanQuery
+	spi    SpanContainQuerySPI
+}
+
+type SpanContainQuerySPI interface {
+	SpanQuery
+
+	// GetContainSpans
+	// Returns the spans of the query over the spans of big and little, which are both
+	// positioned on the same document.
+	GetContainSpans(bigSpans, littleSpans Spans) (Spans, error)
+
+	// WithClauses
+	// Returns a copy of this query over big and little instead, used by Rewrite.
+	WithClauses(big, little SpanQuery) (SpanQuery, error)
+}
+
+func newSpanContainQuery(big, little SpanQuery, spi SpanContainQuerySPI) (*SpanContainQuery, error) {
+	if big.GetField() == "" || little.GetField() == "" {
+		return nil, errors.New("big and little must have a field")
+	}
+	if big.GetField() != little.GetField() {
+		return nil, errors.New("big and little not same field")
+	}
+	return &SpanContainQuery{
+		big:    big,
+		little: little,
+		spi:    spi,
+	}, nil
+}
+
+func (s *SpanContainQuery) GetBig() SpanQuery {
+	return s.big
+}
+
+func (s *SpanContainQuery) GetLittle() SpanQuery {
+	return s.little
+}
+
+func (s *SpanContainQuery) GetField() string {
+	return s.big.GetField()
+}
+
+func (s *SpanContainQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanContainQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	bigWeight, err := s.big.CreateSpanWeight(searcher, scoreMode, boost)
+	if err != nil {
+		return nil, err
+	}
+	littleWeight, err := s.little.CreateSpanWeight(searcher, scoreMode, boost)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = GetTermStates(bigWeight, littleWeight)
+	}
+	weight := &SpanContainWeight{
+		spi:          s.spi,
+		bigWeight:    bigWeight,
+		littleWeight: littleWeight,
+	}
+	base, err := NewBaseSpanWeight(s.spi, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *SpanContainQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	rewrittenBig, err := rewriteSpanQuery(s.big, reader)
+	if err != nil {
+		return nil, err
+	}
+	rewrittenLittle, err := rewriteSpanQuery(s.little, reader)
+	if err != nil {
+		return nil, err
+	}
+	if rewrittenBig != s.big || rewrittenLittle != s.little {
+		return s.spi.WithClauses(rewrittenBig, rewrittenLittle)
+	}
+	return s.spi, nil
+}
+
+func (s *SpanContainQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(s.GetField()) {
+		return nil
+	}
+	v := visitor.GetSubVisitor(index.OccurMust, s.spi)
+	if err := s.big.Visit(v); err != nil {
+		return err
+	}
+	return s.little.Visit(v)
+}
+
+func (s *SpanContainQuery) toString(field, name string) string {
+	return fmt.Sprintf("%s(%s, %s)", name, s.big.String(field), s.little.String(field))
+}
+
+var _ SpanWeight = &SpanContainWeight{}
+
+type SpanContainWeight struct {
+	*BaseSpanWeight
+
+	spi          SpanContainQuerySPI
+	bigWeight    SpanWeight
+	littleWeight SpanWeight
+}
+
+func (s *SpanContainWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	if err := s.bigWeight.ExtractTerms(terms); err != nil {
+		return err
+	}
+	return s.littleWeight.ExtractTerms(terms)
+}
+
+func (s *SpanContainWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return s.bigWeight.IsCacheable(ctx) && s.littleWeight.IsCacheable(ctx)
+}
+
+func (s *SpanContainWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	s.bigWeight.ExtractTermStates(contexts)
+	s.littleWeight.ExtractTermStates(contexts)
+}
+
+func (s *SpanContainWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	bigSpans, err := s.bigWeight.GetSpans(ctx, requiredPostings)
+	if err != nil || bigSpans == nil {
+		return nil, err
+	}
+	littleSpans, err := s.littleWeight.GetSpans(ctx, requiredPostings)
+	if err != nil || littleSpans == nil {
+		return nil, err
+	}
+	return s.spi.GetContainSpans(bigSpans, littleSpans)
+}
+
+// equalsTo Compares the clauses of the queries, the outer queries check the type of the other query.
+func (s *SpanContainQuery) equalsTo(other *SpanContainQuery) bool {
+	return search.QueryEquals(s.big, other.big) && search.QueryEquals(s.little, other.little)
+}
+
+func (s *SpanContainQuery) fieldsHashCode() int {
+	return 31*search.QueryHashCode(s.big) + search.QueryHashCode(s.little)
+}
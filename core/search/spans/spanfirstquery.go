@@ -0,0 +1,58 @@
+package spans
+
+import (
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanPositionCheckQuerySPI = &SpanFirstQuery{}
+
+// SpanFirstQuery
+// Matches spans near the beginning of a field.
+// This class is a simple extension of SpanPositionRangeQuery in that it assumes the start to be zero
+// and only checks the end boundary.
+type SpanFirstQuery struct {
+	*SpanPositionRangeQuery
+}
+
+// NewSpanFirstQuery
+// Construct a SpanFirstQuery matching spans in match whose end position is less than or equal to end.
+func NewSpanFirstQuery(match SpanQuery, end int) *SpanFirstQuery {
+	query := &SpanFirstQuery{
+		SpanPositionRangeQuery: &SpanPositionRangeQuery{
+			start: 0,
+			end:   end,
+		},
+	}
+	query.SpanPositionCheckQuery = newSpanPositionCheckQuery(match, query)
+	return query
+}
+
+func (s *SpanFirstQuery) AcceptPosition(spans Spans) (AcceptStatus, error) {
+	if spans.StartPosition() >= s.end {
+		return ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC, nil
+	}
+	if spans.EndPosition() <= s.end {
+		return ACCEPT_STATUS_YES, nil
+	}
+	return ACCEPT_STATUS_NO, nil
+}
+
+func (s *SpanFirstQuery) WithMatch(match SpanQuery) SpanQuery {
+	return NewSpanFirstQuery(match, s.end)
+}
+
+func (s *SpanFirstQuery) String(field string) string {
+	return fmt.Sprintf("spanFirst(%s, %d)", s.match.String(field), s.end)
+}
+
+func (s *SpanFirstQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanFirstQuery)
+	return ok && s.equalsTo(o.SpanPositionRangeQuery)
+}
+
+func (s *SpanFirstQuery) HashCode() int {
+	return 31*search.ClassHash("SpanFirstQuery") + s.fieldsHashCode()
+}
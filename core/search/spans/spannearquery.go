@@ -0,0 +1,488 @@
+package spans
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ SpanQuery = &SpanNearQuery{}
+
+// SpanNearQuery
+// Matches spans which are near one another. One can specify slop, the maximum number of intervening
+// unmatched positions, as well as whether matches are required to be in-order.
+type SpanNearQuery struct {
+	clauses []SpanQuery
+	slop    int
+	inOrder bool
+	field   string
+}
+
+// SpanNearQueryBuilder
+// A builder for SpanNearQueries
+type SpanNearQueryBuilder struct {
+	ordered bool
+	field   string
+	clauses []SpanQuery
+	slop    int
+	errs    []error
+}
+
+// NewSpanNearQueryBuilder
+// Construct a new builder
+// field: the field to search in
+// ordered: whether or not clauses must be in-order to match
+func NewSpanNearQueryBuilder(field string, ordered bool) *SpanNearQueryBuilder {
+	return &SpanNearQueryBuilder{
+		ordered: ordered,
+		field:   field,
+		clauses: make([]SpanQuery, 0),
+		errs:    make([]error, 0),
+	}
+}
+
+// NewOrderedNearQuery
+// Returns a new SpanNearQueryBuilder for an ordered query on a particular field
+func NewOrderedNearQuery(field string) *SpanNearQueryBuilder {
+	return NewSpanNearQueryBuilder(field, true)
+}
+
+// NewUnorderedNearQuery
+// Returns a new SpanNearQueryBuilder for an unordered query on a particular field
+func NewUnorderedNearQuery(field string) *SpanNearQueryBuilder {
+	return NewSpanNearQueryBuilder(field, false)
+}
+
+// AddClause
+// Add a new clause
+func (b *SpanNearQueryBuilder) AddClause(clause SpanQuery) *SpanNearQueryBuilder {
+	if clause.GetField() != b.field {
+		b.errs = append(b.errs, fmt.Errorf("cannot add clause %s to SpanNearQuery for field %s", clause.String(""), b.field))
+		return b
+	}
+	b.clauses = append(b.clauses, clause)
+	return b
+}
+
+// AddGap
+// Add a gap after the previous clause of a defined width
+func (b *SpanNearQueryBuilder) AddGap(width int) *SpanNearQueryBuilder {
+	if !b.ordered {
+		b.errs = append(b.errs, errors.New("gaps can only be added to ordered near queries"))
+		return b
+	}
+	b.clauses = append(b.clauses, newSpanGapQuery(b.field, width))
+	return b
+}
+
+// SetSlop
+// Set the slop for this query
+func (b *SpanNearQueryBuilder) SetSlop(slop int) *SpanNearQueryBuilder {
+	b.slop = slop
+	return b
+}
+
+// Build
+// Build the query
+func (b *SpanNearQueryBuilder) Build() (*SpanNearQuery, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	return NewSpanNearQuery(b.clauses, b.slop, b.ordered)
+}
+
+// NewSpanNearQuery
+// Construct a SpanNearQuery. Matches spans matching a span from each clause, with up to slop total
+// unmatched positions between them.
+// When inOrder is true, the spans from each clause must be in the same order as in clauses and must
+// be non-overlapping.
+// When inOrder is false, the spans from each clause need not be ordered and may overlap.
+//
+// clauses: the clauses to find near each other, in the same field, at least 2.
+// slop: The slop value
+// inOrder: true if order is important
+func NewSpanNearQuery(clauses []SpanQuery, slop int, inOrder bool) (*SpanNearQuery, error) {
+	query := &SpanNearQuery{
+		clauses: make([]SpanQuery, 0, len(clauses)),
+		slop:    slop,
+		inOrder: inOrder,
+	}
+	for _, clause := range clauses {
+		if query.field == "" {
+			query.field = clause.GetField()
+		} else if clause.GetField() != "" && clause.GetField() != query.field {
+			return nil, errors.New("clauses must have same field")
+		}
+		query.clauses = append(query.clauses, clause)
+	}
+	return query, nil
+}
+
+// GetClauses
+// Return the clauses whose spans are matched.
+func (s *SpanNearQuery) GetClauses() []SpanQuery {
+	return s.clauses
+}
+
+// GetSlop
+// Return the maximum number of intervening unmatched positions permitted.
+func (s *SpanNearQuery) GetSlop() int {
+	return s.slop
+}
+
+// IsInOrder
+// Return true if matches are required to be in-order.
+func (s *SpanNearQuery) IsInOrder() bool {
+	return s.inOrder
+}
+
+func (s *SpanNearQuery) GetField() string {
+	return s.field
+}
+
+func (s *SpanNearQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("spanNear([")
+	for i, clause := range s.clauses {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(clause.String(field))
+	}
+	buf.WriteString("], ")
+	buf.WriteString(strconv.Itoa(s.slop))
+	buf.WriteString(", ")
+	buf.WriteString(strconv.FormatBool(s.inOrder))
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (s *SpanNearQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanNearQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	subWeights := make([]SpanWeight, 0, len(s.clauses))
+	for _, clause := range s.clauses {
+		weight, err := clause.CreateSpanWeight(searcher, scoreMode, boost)
+		if err != nil {
+			return nil, err
+		}
+		subWeights = append(subWeights, weight)
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = GetTermStates(subWeights...)
+	}
+	weight := &SpanNearWeight{
+		query:      s,
+		subWeights: subWeights,
+	}
+	base, err := NewBaseSpanWeight(s, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *SpanNearQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	actuallyRewritten := false
+	rewrittenClauses := make([]SpanQuery, 0, len(s.clauses))
+	for _, clause := range s.clauses {
+		query, err := rewriteSpanQuery(clause, reader)
+		if err != nil {
+			return nil, err
+		}
+		actuallyRewritten = actuallyRewritten || query != clause
+		rewrittenClauses = append(rewrittenClauses, query)
+	}
+	if actuallyRewritten {
+		clone := *s
+		clone.clauses = rewrittenClauses
+		return &clone, nil
+	}
+	return s, nil
+}
+
+func (s *SpanNearQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(s.GetField()) {
+		return nil
+	}
+	v := visitor.GetSubVisitor(index.OccurMust, s)
+	for _, clause := range s.clauses {
+		if err := clause.Visit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ SpanWeight = &SpanNearWeight{}
+
+type SpanNearWeight struct {
+	*BaseSpanWeight
+
+	query      *SpanNearQuery
+	subWeights []SpanWeight
+}
+
+func (s *SpanNearWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	for _, w := range s.subWeights {
+		w.ExtractTermStates(contexts)
+	}
+}
+
+func (s *SpanNearWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	terms, err := ctx.LeafReader().Terms(s.query.field)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, nil // field does not exist
+	}
+
+	subSpans := make([]Spans, 0, len(s.subWeights))
+	for _, w := range s.subWeights {
+		spans, err := w.GetSpans(ctx, requiredPostings)
+		if err != nil {
+			return nil, err
+		}
+		if spans == nil {
+			return nil, nil // all required
+		}
+		subSpans = append(subSpans, spans)
+	}
+
+	if len(subSpans) == 1 {
+		return subSpans[0], nil
+	}
+
+	// all NearSpans require at least two subSpans
+	if !s.query.inOrder {
+		return NewNearSpansUnordered(s.query.slop, subSpans)
+	}
+	return NewNearSpansOrdered(s.query.slop, subSpans)
+}
+
+func (s *SpanNearWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	for _, w := range s.subWeights {
+		if err := w.ExtractTerms(terms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SpanNearWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	for _, w := range s.subWeights {
+		if !w.IsCacheable(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+var _ SpanQuery = &spanGapQuery{}
+
+// spanGapQuery
+// A fixed width gap in an ordered SpanNearQuery, added with SpanNearQueryBuilder.AddGap.
+type spanGapQuery struct {
+	field string
+	width int
+}
+
+func newSpanGapQuery(field string, width int) *spanGapQuery {
+	return &spanGapQuery{
+		field: field,
+		width: width,
+	}
+}
+
+func (s *spanGapQuery) GetField() string {
+	return s.field
+}
+
+func (s *spanGapQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *spanGapQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	weight := &spanGapWeight{query: s}
+	base, err := NewBaseSpanWeight(s, searcher, nil, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *spanGapQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return s, nil
+}
+
+func (s *spanGapQuery) Visit(visitor index.QueryVisitor) error {
+	return visitor.VisitLeaf(s)
+}
+
+func (s *spanGapQuery) String(field string) string {
+	return fmt.Sprintf("SpanGap(%s:%d)", s.field, s.width)
+}
+
+var _ SpanWeight = &spanGapWeight{}
+
+type spanGapWeight struct {
+	*BaseSpanWeight
+
+	query *spanGapQuery
+}
+
+func (s *spanGapWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+}
+
+func (s *spanGapWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	return newGapSpans(s.query.width), nil
+}
+
+func (s *spanGapWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return nil
+}
+
+func (s *spanGapWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return true
+}
+
+var _ Spans = &gapSpans{}
+
+// gapSpans
+// Matches every document, at every position, with a fixed width.
+type gapSpans struct {
+	doc   int
+	pos   int
+	width int
+}
+
+func newGapSpans(width int) *gapSpans {
+	return &gapSpans{
+		doc:   -1,
+		pos:   -1,
+		width: width,
+	}
+}
+
+func (g *gapSpans) NextStartPosition() (int, error) {
+	g.pos++
+	return g.pos, nil
+}
+
+func (g *gapSpans) skipToPosition(position int) int {
+	g.pos = position
+	return g.pos
+}
+
+func (g *gapSpans) StartPosition() int {
+	return g.pos
+}
+
+func (g *gapSpans) EndPosition() int {
+	return g.pos + g.width
+}
+
+func (g *gapSpans) Width() int {
+	return g.width
+}
+
+func (g *gapSpans) Collect(collector SpanCollector) error {
+	return nil
+}
+
+func (g *gapSpans) PositionsCost() float64 {
+	return 0
+}
+
+func (g *gapSpans) AsTwoPhaseIterator() index.TwoPhaseIterator {
+	return nil
+}
+
+func (g *gapSpans) DocID() int {
+	return g.doc
+}
+
+func (g *gapSpans) NextDoc(ctx context.Context) (int, error) {
+	return g.Advance(ctx, g.doc+1)
+}
+
+func (g *gapSpans) Advance(ctx context.Context, target int) (int, error) {
+	g.pos = -1
+	g.doc = target
+	if g.doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	return g.doc, nil
+}
+
+func (g *gapSpans) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, g, target)
+}
+
+func (g *gapSpans) Cost() int64 {
+	return 0
+}
+
+func (s *SpanNearQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanNearQuery)
+	if !ok {
+		return false
+	}
+	return s.inOrder == o.inOrder && s.slop == o.slop && s.field == o.field &&
+		spanQueriesEqual(s.clauses, o.clauses)
+}
+
+func (s *SpanNearQuery) HashCode() int {
+	h := search.ClassHash("SpanNearQuery")
+	h = 31*h + spanQueriesHashCode(s.clauses)
+	h = 31*h + s.slop
+	return 31*h + search.HashBool(s.inOrder)
+}
+
+func (s *spanGapQuery) Equals(other index.Query) bool {
+	o, ok := other.(*spanGapQuery)
+	return ok && s.field == o.field && s.width == o.width
+}
+
+func (s *spanGapQuery) HashCode() int {
+	h := search.ClassHash("SpanGapQuery")
+	h = 31*h + search.HashString(s.field)
+	return 31*h + s.width
+}
+
+func spanQueriesEqual(a, b []SpanQuery) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !search.QueryEquals(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func spanQueriesHashCode(queries []SpanQuery) int {
+	h := 1
+	for _, query := range queries {
+		h = 31*h + search.QueryHashCode(query)
+	}
+	return h
+}
@@ -0,0 +1,272 @@
+package spans
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ SpanQuery = &SpanNotQuery{}
+
+// SpanNotQuery
+// Removes matches which overlap with another SpanQuery or which are within x tokens before or
+// y tokens after another SpanQuery.
+type SpanNotQuery struct {
+	include SpanQuery
+	exclude SpanQuery
+	pre     int
+	post    int
+}
+
+// NewSpanNotQuery
+// Construct a SpanNotQuery matching spans from include which have no overlap with spans from exclude.
+func NewSpanNotQuery(include, exclude SpanQuery) (*SpanNotQuery, error) {
+	return NewSpanNotQueryV2(include, exclude, 0, 0)
+}
+
+// NewSpanNotQueryV1
+// Construct a SpanNotQuery matching spans from include which have no overlap with spans from exclude
+// within dist tokens of include. Inversely, a negative dist value may be used to specify a certain
+// amount of allowable overlap.
+func NewSpanNotQueryV1(include, exclude SpanQuery, dist int) (*SpanNotQuery, error) {
+	return NewSpanNotQueryV2(include, exclude, dist, dist)
+}
+
+// NewSpanNotQueryV2
+// Construct a SpanNotQuery matching spans from include which have no overlap with spans from exclude
+// within pre tokens before or post tokens of include. Inversely, negative values for pre and/or post
+// allow a certain amount of overlap to occur.
+func NewSpanNotQueryV2(include, exclude SpanQuery, pre, post int) (*SpanNotQuery, error) {
+	if include.GetField() != "" && exclude.GetField() != "" && include.GetField() != exclude.GetField() {
+		return nil, errors.New("clauses must have same field")
+	}
+	return &SpanNotQuery{
+		include: include,
+		exclude: exclude,
+		pre:     pre,
+		post:    post,
+	}, nil
+}
+
+// GetInclude
+// Return the SpanQuery whose matches are filtered.
+func (s *SpanNotQuery) GetInclude() SpanQuery {
+	return s.include
+}
+
+// GetExclude
+// Return the SpanQuery whose matches must not overlap those returned.
+func (s *SpanNotQuery) GetExclude() SpanQuery {
+	return s.exclude
+}
+
+func (s *SpanNotQuery) GetField() string {
+	return s.include.GetField()
+}
+
+func (s *SpanNotQuery) String(field string) string {
+	return fmt.Sprintf("spanNot(%s, %s, %d, %d)", s.include.String(field), s.exclude.String(field), s.pre, s.post)
+}
+
+func (s *SpanNotQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanNotQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	includeWeight, err := s.include.CreateSpanWeight(searcher, scoreMode, boost)
+	if err != nil {
+		return nil, err
+	}
+	excludeWeight, err := s.exclude.CreateSpanWeight(searcher, scoreMode, boost)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = GetTermStates(includeWeight)
+	}
+	weight := &SpanNotWeight{
+		query:         s,
+		includeWeight: includeWeight,
+		excludeWeight: excludeWeight,
+	}
+	base, err := NewBaseSpanWeight(s, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *SpanNotQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	rewrittenInclude, err := rewriteSpanQuery(s.include, reader)
+	if err != nil {
+		return nil, err
+	}
+	rewrittenExclude, err := rewriteSpanQuery(s.exclude, reader)
+	if err != nil {
+		return nil, err
+	}
+	if rewrittenInclude != s.include || rewrittenExclude != s.exclude {
+		return NewSpanNotQueryV2(rewrittenInclude, rewrittenExclude, s.pre, s.post)
+	}
+	return s, nil
+}
+
+func (s *SpanNotQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(s.GetField()) {
+		return nil
+	}
+	if err := s.include.Visit(visitor.GetSubVisitor(index.OccurMust, s)); err != nil {
+		return err
+	}
+	return s.exclude.Visit(visitor.GetSubVisitor(index.OccurMustNot, s))
+}
+
+var _ SpanWeight = &SpanNotWeight{}
+
+type SpanNotWeight struct {
+	*BaseSpanWeight
+
+	query         *SpanNotQuery
+	includeWeight SpanWeight
+	excludeWeight SpanWeight
+}
+
+func (s *SpanNotWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	s.includeWeight.ExtractTermStates(contexts)
+}
+
+func (s *SpanNotWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	includeSpans, err := s.includeWeight.GetSpans(ctx, requiredPostings)
+	if err != nil || includeSpans == nil {
+		return nil, err
+	}
+
+	excludeSpans, err := s.excludeWeight.GetSpans(ctx, requiredPostings)
+	if err != nil {
+		return nil, err
+	}
+	if excludeSpans == nil {
+		return includeSpans, nil
+	}
+
+	excluder := &spanNotExcluder{
+		excludeSpans:  excludeSpans,
+		pre:           s.query.pre,
+		post:          s.query.post,
+		lastApproxDoc: -1,
+	}
+	if excludeTwoPhase := excludeSpans.AsTwoPhaseIterator(); excludeTwoPhase != nil {
+		excluder.excludeTwoPhase = excludeTwoPhase
+		excluder.excludeApproximation = excludeTwoPhase.Approximation()
+	}
+	return NewFilterSpans(includeSpans, excluder.accept), nil
+}
+
+func (s *SpanNotWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return s.includeWeight.ExtractTerms(terms)
+}
+
+func (s *SpanNotWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return s.includeWeight.IsCacheable(ctx) && s.excludeWeight.IsCacheable(ctx)
+}
+
+// spanNotExcluder
+// Rejects the include spans that overlap an exclude span, catching up the exclude spans with
+// the candidates.
+type spanNotExcluder struct {
+	excludeSpans         Spans
+	excludeTwoPhase      index.TwoPhaseIterator
+	excludeApproximation types.DocIdSetIterator
+	pre                  int
+	post                 int
+
+	// last document we have checked matches() against for the exclusion, and failed
+	// when using approximations, so we don't call it again, and pass thru all inclusions.
+	lastApproxDoc    int
+	lastApproxResult bool
+}
+
+func (e *spanNotExcluder) accept(candidate Spans) (AcceptStatus, error) {
+	doc := candidate.DocID()
+	if doc > e.excludeSpans.DocID() {
+		// catch up 'exclude' to the current doc
+		if e.excludeTwoPhase != nil {
+			target, err := e.excludeApproximation.Advance(nil, doc)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			if target == doc {
+				e.lastApproxDoc = doc
+				if e.lastApproxResult, err = e.excludeTwoPhase.Matches(); err != nil {
+					return 0, err
+				}
+			}
+		} else {
+			if _, err := e.excludeSpans.Advance(nil, doc); err != nil && !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+		}
+	} else if e.excludeTwoPhase != nil && doc == e.excludeSpans.DocID() && doc != e.lastApproxDoc {
+		// excludeSpans already sitting on our candidate doc, but matches not called yet.
+		e.lastApproxDoc = doc
+		var err error
+		if e.lastApproxResult, err = e.excludeTwoPhase.Matches(); err != nil {
+			return 0, err
+		}
+	}
+
+	if doc != e.excludeSpans.DocID() || (doc == e.lastApproxDoc && !e.lastApproxResult) {
+		return ACCEPT_STATUS_YES, nil
+	}
+
+	if e.excludeSpans.StartPosition() == -1 { // init exclude start position if needed
+		if _, err := e.excludeSpans.NextStartPosition(); err != nil {
+			return 0, err
+		}
+	}
+
+	for e.excludeSpans.EndPosition() <= candidate.StartPosition()-e.pre {
+		// exclude end position is before a possible exclusion
+		pos, err := e.excludeSpans.NextStartPosition()
+		if err != nil {
+			return 0, err
+		}
+		if pos == NO_MORE_POSITIONS {
+			return ACCEPT_STATUS_YES, nil // no more exclude at current doc.
+		}
+	}
+
+	// exclude end position far enough in current doc, check start position:
+	if e.excludeSpans.StartPosition()-e.post >= candidate.EndPosition() {
+		// exclude starts too late, or exclude is after end of candidate
+		return ACCEPT_STATUS_YES, nil
+	}
+	return ACCEPT_STATUS_NO, nil
+}
+
+func (s *SpanNotQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanNotQuery)
+	if !ok {
+		return false
+	}
+	return search.QueryEquals(s.include, o.include) && search.QueryEquals(s.exclude, o.exclude) &&
+		s.pre == o.pre && s.post == o.post
+}
+
+func (s *SpanNotQuery) HashCode() int {
+	h := search.ClassHash("SpanNotQuery")
+	h = 31*h + search.QueryHashCode(s.include)
+	h = 31*h + search.QueryHashCode(s.exclude)
+	h = 31*h + s.pre
+	return 31*h + s.post
+}
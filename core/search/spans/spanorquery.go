@@ -0,0 +1,366 @@
+package spans
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ SpanQuery = &SpanOrQuery{}
+
+// SpanOrQuery
+// Matches the union of its clauses.
+type SpanOrQuery struct {
+	clauses []SpanQuery
+	field   string
+}
+
+// NewSpanOrQuery
+// Construct a SpanOrQuery merging the provided clauses. All clauses must have the same field.
+func NewSpanOrQuery(clauses ...SpanQuery) (*SpanOrQuery, error) {
+	query := &SpanOrQuery{
+		clauses: make([]SpanQuery, 0, len(clauses)),
+	}
+	for _, clause := range clauses {
+		if err := query.AddClause(clause); err != nil {
+			return nil, err
+		}
+	}
+	return query, nil
+}
+
+// AddClause
+// Adds a clause to this query
+func (s *SpanOrQuery) AddClause(clause SpanQuery) error {
+	if s.field == "" {
+		s.field = clause.GetField()
+	} else if clause.GetField() != "" && clause.GetField() != s.field {
+		return errors.New("clauses must have same field")
+	}
+	s.clauses = append(s.clauses, clause)
+	return nil
+}
+
+// GetClauses
+// Return the clauses whose spans are matched.
+func (s *SpanOrQuery) GetClauses() []SpanQuery {
+	return s.clauses
+}
+
+func (s *SpanOrQuery) GetField() string {
+	return s.field
+}
+
+func (s *SpanOrQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	rewritten := &SpanOrQuery{
+		clauses: make([]SpanQuery, 0, len(s.clauses)),
+	}
+	actuallyRewritten := false
+	for _, clause := range s.clauses {
+		query, err := rewriteSpanQuery(clause, reader)
+		if err != nil {
+			return nil, err
+		}
+		actuallyRewritten = actuallyRewritten || query != clause
+		if err := rewritten.AddClause(query); err != nil {
+			return nil, err
+		}
+	}
+	if actuallyRewritten {
+		return rewritten, nil
+	}
+	return s, nil
+}
+
+func (s *SpanOrQuery) Visit(visitor index.QueryVisitor) error {
+	if !visitor.AcceptField(s.GetField()) {
+		return nil
+	}
+	v := visitor.GetSubVisitor(index.OccurShould, s)
+	for _, clause := range s.clauses {
+		if err := clause.Visit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SpanOrQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("spanOr([")
+	for i, clause := range s.clauses {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(clause.String(field))
+	}
+	buf.WriteString("])")
+	return buf.String()
+}
+
+func (s *SpanOrQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanOrQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	subWeights := make([]SpanWeight, 0, len(s.clauses))
+	for _, clause := range s.clauses {
+		weight, err := clause.CreateSpanWeight(searcher, scoreMode, 1)
+		if err != nil {
+			return nil, err
+		}
+		subWeights = append(subWeights, weight)
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = GetTermStates(subWeights...)
+	}
+	weight := &SpanOrWeight{
+		query:      s,
+		subWeights: subWeights,
+	}
+	base, err := NewBaseSpanWeight(s, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+var _ SpanWeight = &SpanOrWeight{}
+
+type SpanOrWeight struct {
+	*BaseSpanWeight
+
+	query      *SpanOrQuery
+	subWeights []SpanWeight
+}
+
+func (s *SpanOrWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	for _, w := range s.subWeights {
+		if err := w.ExtractTerms(terms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SpanOrWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	for _, w := range s.subWeights {
+		if !w.IsCacheable(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *SpanOrWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	for _, w := range s.subWeights {
+		w.ExtractTermStates(contexts)
+	}
+}
+
+func (s *SpanOrWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	subSpans := make([]Spans, 0, len(s.subWeights))
+	for _, w := range s.subWeights {
+		spans, err := w.GetSpans(ctx, requiredPostings)
+		if err != nil {
+			return nil, err
+		}
+		if spans != nil {
+			subSpans = append(subSpans, spans)
+		}
+	}
+
+	switch len(subSpans) {
+	case 0:
+		return nil, nil
+	case 1:
+		return subSpans[0], nil
+	default:
+		return newSpanOrSpans(s.query, subSpans), nil
+	}
+}
+
+var _ Spans = &spanOrSpans{}
+
+// spanOrSpans
+// The union of the sub spans. The sub spans are kept in a queue by doc, and the sub spans on the
+// current doc are merged by position in a SpanPositionQueue. Sub spans are advanced as exact iterators,
+// so the union needs no two-phase iteration.
+type spanOrSpans struct {
+	query            *SpanOrQuery
+	subSpans         []Spans
+	byDocQueue       *structure.PriorityQueue[Spans]
+	byPositionQueue  *SpanPositionQueue
+	topPositionSpans Spans
+	cost             int64
+	positionsCost    float64
+}
+
+func newSpanOrSpans(query *SpanOrQuery, subSpans []Spans) *spanOrSpans {
+	byDocQueue := structure.NewPriorityQueue[Spans](len(subSpans), func(a, b Spans) bool {
+		return a.DocID() < b.DocID()
+	})
+
+	cost := int64(0)
+	sumPositionsCost, sumCost := 0.0, int64(0)
+	for _, spans := range subSpans {
+		byDocQueue.Add(spans)
+		cost += spans.Cost()
+		costWeight := max(spans.Cost(), 1)
+		sumPositionsCost += spans.PositionsCost() * float64(costWeight)
+		sumCost += costWeight
+	}
+
+	return &spanOrSpans{
+		query:           query,
+		subSpans:        subSpans,
+		byDocQueue:      byDocQueue,
+		byPositionQueue: NewSpanPositionQueue(len(subSpans)), // when empty use -1
+		cost:            cost,
+		positionsCost:   sumPositionsCost / float64(sumCost),
+	}
+}
+
+func (s *spanOrSpans) NextDoc(ctx context.Context) (int, error) {
+	s.topPositionSpans = nil
+	topDocSpans := s.byDocQueue.Top()
+	currentDoc := topDocSpans.DocID()
+	if currentDoc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	for {
+		if _, err := topDocSpans.NextDoc(ctx); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		topDocSpans = s.byDocQueue.UpdateTop()
+		if topDocSpans.DocID() != currentDoc {
+			break
+		}
+	}
+	return s.toDoc(topDocSpans.DocID())
+}
+
+func (s *spanOrSpans) Advance(ctx context.Context, target int) (int, error) {
+	s.topPositionSpans = nil
+	topDocSpans := s.byDocQueue.Top()
+	for {
+		if _, err := topDocSpans.Advance(ctx, target); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		topDocSpans = s.byDocQueue.UpdateTop()
+		if topDocSpans.DocID() >= target {
+			break
+		}
+	}
+	return s.toDoc(topDocSpans.DocID())
+}
+
+func (s *spanOrSpans) toDoc(doc int) (int, error) {
+	if doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	return doc, nil
+}
+
+func (s *spanOrSpans) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, s, target)
+}
+
+func (s *spanOrSpans) DocID() int {
+	return s.byDocQueue.Top().DocID()
+}
+
+func (s *spanOrSpans) Cost() int64 {
+	return s.cost
+}
+
+func (s *spanOrSpans) AsTwoPhaseIterator() index.TwoPhaseIterator {
+	return nil
+}
+
+func (s *spanOrSpans) PositionsCost() float64 {
+	return s.positionsCost
+}
+
+// called at first nextStartPosition
+func (s *spanOrSpans) fillPositionQueue() error {
+	// add all matching Spans at current doc to byPositionQueue
+	currentDoc := s.DocID()
+	for _, spans := range s.subSpans {
+		if spans.DocID() != currentDoc {
+			continue
+		}
+		if _, err := spans.NextStartPosition(); err != nil {
+			return err
+		}
+		s.byPositionQueue.Add(spans)
+	}
+	return nil
+}
+
+func (s *spanOrSpans) NextStartPosition() (int, error) {
+	if s.topPositionSpans == nil {
+		s.byPositionQueue.Clear()
+		if err := s.fillPositionQueue(); err != nil { // fills byPositionQueue at first position
+			return 0, err
+		}
+		s.topPositionSpans = s.byPositionQueue.Top()
+	} else {
+		if _, err := s.topPositionSpans.NextStartPosition(); err != nil {
+			return 0, err
+		}
+		s.topPositionSpans = s.byPositionQueue.UpdateTop()
+	}
+	return s.topPositionSpans.StartPosition(), nil
+}
+
+func (s *spanOrSpans) StartPosition() int {
+	if s.topPositionSpans == nil {
+		return -1
+	}
+	return s.topPositionSpans.StartPosition()
+}
+
+func (s *spanOrSpans) EndPosition() int {
+	if s.topPositionSpans == nil {
+		return -1
+	}
+	return s.topPositionSpans.EndPosition()
+}
+
+func (s *spanOrSpans) Width() int {
+	return s.topPositionSpans.Width()
+}
+
+func (s *spanOrSpans) Collect(collector SpanCollector) error {
+	if s.topPositionSpans != nil {
+		return s.topPositionSpans.Collect(collector)
+	}
+	return nil
+}
+
+func (s *spanOrSpans) String() string {
+	return fmt.Sprintf("spanOr(%s)@%d: %d - %d", s.query.String(""), s.DocID(), s.StartPosition(), s.EndPosition())
+}
+
+func (s *SpanOrQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanOrQuery)
+	return ok && s.field == o.field && spanQueriesEqual(s.clauses, o.clauses)
+}
+
+func (s *SpanOrQuery) HashCode() int {
+	return 31*search.ClassHash("SpanOrQuery") + spanQueriesHashCode(s.clauses)
+}
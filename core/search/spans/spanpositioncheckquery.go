@@ -0,0 +1,132 @@
+package spans
+
+import (
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+// SpanPositionCheckQuery
+// Base for queries which restrict the positions of the spans of an other SpanQuery, the restriction
+// is provided by a SpanPositionCheckQuerySPI.
+type SpanPositionCheckQuery struct {
+	match SpanQuery
+	spi   SpanPositionCheckQuerySPI
+}
+
+type SpanPositionCheckQuerySPI interface {
+	SpanQuery
+
+	// AcceptPosition
+	// Implementing classes are required to return whether the current position is a match for the passed
+	// in "match" SpanQuery.
+	// This is only called if the underlying last Spans.NextStartPosition() for the match indicated a
+	// valid start position.
+	// spans: The Spans instance, positioned at the spot to check
+	AcceptPosition(spans Spans) (AcceptStatus, error)
+
+	// WithMatch
+	// Returns a copy of this query restricting the positions of match instead, used by Rewrite.
+	WithMatch(match SpanQuery) SpanQuery
+}
+
+func newSpanPositionCheckQuery(match SpanQuery, spi SpanPositionCheckQuerySPI) *SpanPositionCheckQuery {
+	return &SpanPositionCheckQuery{
+		match: match,
+		spi:   spi,
+	}
+}
+
+// GetMatch
+// Returns the SpanQuery whose matches are filtered.
+func (s *SpanPositionCheckQuery) GetMatch() SpanQuery {
+	return s.match
+}
+
+func (s *SpanPositionCheckQuery) GetField() string {
+	return s.match.GetField()
+}
+
+func (s *SpanPositionCheckQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanPositionCheckQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	matchWeight, err := s.match.CreateSpanWeight(searcher, scoreMode, boost)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = GetTermStates(matchWeight)
+	}
+	weight := &SpanPositionCheckWeight{
+		spi:         s.spi,
+		matchWeight: matchWeight,
+	}
+	base, err := NewBaseSpanWeight(s.spi, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *SpanPositionCheckQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	rewritten, err := rewriteSpanQuery(s.match, reader)
+	if err != nil {
+		return nil, err
+	}
+	if rewritten != s.match {
+		return s.spi.WithMatch(rewritten), nil
+	}
+	return s.spi, nil
+}
+
+func (s *SpanPositionCheckQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(s.GetField()) {
+		return s.match.Visit(visitor.GetSubVisitor(index.OccurMust, s.spi))
+	}
+	return nil
+}
+
+var _ SpanWeight = &SpanPositionCheckWeight{}
+
+type SpanPositionCheckWeight struct {
+	*BaseSpanWeight
+
+	spi         SpanPositionCheckQuerySPI
+	matchWeight SpanWeight
+}
+
+func (s *SpanPositionCheckWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	return s.matchWeight.ExtractTerms(terms)
+}
+
+func (s *SpanPositionCheckWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return s.matchWeight.IsCacheable(ctx)
+}
+
+func (s *SpanPositionCheckWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	s.matchWeight.ExtractTermStates(contexts)
+}
+
+func (s *SpanPositionCheckWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	matchSpans, err := s.matchWeight.GetSpans(ctx, requiredPostings)
+	if err != nil || matchSpans == nil {
+		return nil, err
+	}
+	return NewFilterSpans(matchSpans, s.spi.AcceptPosition), nil
+}
+
+// equalsTo Compares the matches of the queries, the outer queries check the type of the other query.
+func (s *SpanPositionCheckQuery) equalsTo(other *SpanPositionCheckQuery) bool {
+	return search.QueryEquals(s.match, other.match)
+}
+
+func (s *SpanPositionCheckQuery) fieldsHashCode() int {
+	return search.QueryHashCode(s.match)
+}
@@ -0,0 +1,17 @@
+package spans
+
+import (
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+// SpanPositionQueue
+// A priority queue of Spans in the same document, ordered by start position and then by end position.
+type SpanPositionQueue struct {
+	*structure.PriorityQueue[Spans]
+}
+
+func NewSpanPositionQueue(maxSize int) *SpanPositionQueue {
+	return &SpanPositionQueue{
+		PriorityQueue: structure.NewPriorityQueue[Spans](maxSize, positionsOrdered),
+	}
+}
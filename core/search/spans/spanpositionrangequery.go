@@ -0,0 +1,80 @@
+package spans
+
+import (
+	"fmt"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanPositionCheckQuerySPI = &SpanPositionRangeQuery{}
+
+// SpanPositionRangeQuery
+// Checks to see if the GetMatch() lies between a start and end position
+// See Also: SpanFirstQuery for a derivation that is optimized for the case where start position is 0.
+type SpanPositionRangeQuery struct {
+	*SpanPositionCheckQuery
+
+	start int
+	end   int
+}
+
+func NewSpanPositionRangeQuery(match SpanQuery, start, end int) *SpanPositionRangeQuery {
+	query := &SpanPositionRangeQuery{
+		start: start,
+		end:   end,
+	}
+	query.SpanPositionCheckQuery = newSpanPositionCheckQuery(match, query)
+	return query
+}
+
+func (s *SpanPositionRangeQuery) AcceptPosition(spans Spans) (AcceptStatus, error) {
+	if spans.StartPosition() >= s.end {
+		return ACCEPT_STATUS_NO_MORE_IN_CURRENT_DOC, nil
+	}
+	if spans.StartPosition() >= s.start && spans.EndPosition() <= s.end {
+		return ACCEPT_STATUS_YES, nil
+	}
+	return ACCEPT_STATUS_NO, nil
+}
+
+func (s *SpanPositionRangeQuery) WithMatch(match SpanQuery) SpanQuery {
+	return NewSpanPositionRangeQuery(match, s.start, s.end)
+}
+
+// GetStart
+// Returns the minimum position permitted in a match
+func (s *SpanPositionRangeQuery) GetStart() int {
+	return s.start
+}
+
+// GetEnd
+// Returns the maximum end position permitted in a match.
+func (s *SpanPositionRangeQuery) GetEnd() int {
+	return s.end
+}
+
+func (s *SpanPositionRangeQuery) String(field string) string {
+	return fmt.Sprintf("spanPosRange(%s, %d, %d)", s.match.String(field), s.start, s.end)
+}
+
+func (s *SpanPositionRangeQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanPositionRangeQuery)
+	return ok && s.equalsTo(o)
+}
+
+func (s *SpanPositionRangeQuery) HashCode() int {
+	return 31*search.ClassHash("SpanPositionRangeQuery") + s.fieldsHashCode()
+}
+
+// equalsTo Compares the matches and the positions of the queries.
+func (s *SpanPositionRangeQuery) equalsTo(other *SpanPositionRangeQuery) bool {
+	return s.start == other.start && s.end == other.end &&
+		s.SpanPositionCheckQuery.equalsTo(other.SpanPositionCheckQuery)
+}
+
+func (s *SpanPositionRangeQuery) fieldsHashCode() int {
+	h := s.SpanPositionCheckQuery.fieldsHashCode()
+	h = 31*h + s.start
+	return 31*h + s.end
+}
@@ -0,0 +1,49 @@
+package spans
+
+import (
+	"fmt"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+)
+
+// SpanQuery
+// Base interface for span-based queries.
+type SpanQuery interface {
+	index.Query
+
+	// GetField
+	// Returns the name of the field matched by this query.
+	GetField() string
+
+	// CreateSpanWeight
+	// Same as CreateWeight, but returns the SpanWeight so that the spans of this query can be used
+	// by enclosing span queries.
+	CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error)
+}
+
+// GetTermStates
+// Build a map of terms to TermStates, for use in constructing SpanWeights
+// lucene.internal
+func GetTermStates(weights ...SpanWeight) *treemap.Map[index.Term, *coreIndex.TermStates] {
+	terms := treemap.NewWith[index.Term, *coreIndex.TermStates](index.TermCompare)
+	for _, w := range weights {
+		w.ExtractTermStates(terms)
+	}
+	return terms
+}
+
+// rewriteSpanQuery
+// Rewrites a clause of a span query, the rewritten query must still be a SpanQuery.
+func rewriteSpanQuery(query SpanQuery, reader index.IndexReader) (SpanQuery, error) {
+	rewritten, err := query.Rewrite(reader)
+	if err != nil {
+		return nil, err
+	}
+	spanQuery, ok := rewritten.(SpanQuery)
+	if !ok {
+		return nil, fmt.Errorf("%s rewrote to %s which is not a SpanQuery", query.String(""), rewritten.String(""))
+	}
+	return spanQuery, nil
+}
@@ -0,0 +1,392 @@
+package spans_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/geange/lucene-go/core/search/spans"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fieldDocs Returns a document for every value, each value holds the text of the given fields
+func fieldDocs(fields []string, values ...[]string) []*document.Document {
+	docs := make([]*document.Document, 0, len(values))
+	for _, value := range values {
+		doc := document.NewDocument()
+		for i, field := range fields {
+			doc.Add(document.NewTextField(field, value[i], false))
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func bodyDocs(values ...string) []*document.Document {
+	docs := make([][]string, 0, len(values))
+	for _, value := range values {
+		docs = append(docs, []string{value})
+	}
+	return fieldDocs([]string{"body"}, docs...)
+}
+
+func newTestSpansSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		bodyDocs(
+			"the quick brown fox jumps over the lazy dog",
+			"the lazy dog sleeps while the quick fox jumps",
+			"quick quick fox"),
+		bodyDocs(
+			"fox quick brown",
+			"brown fox the quick",
+			"dog"))
+}
+
+func spanTerm(field, text string) *spans.SpanTermQuery {
+	return spans.NewSpanTermQuery(coreIndex.NewTerm(field, []byte(text)))
+}
+
+func spanNear(t *testing.T, slop int, inOrder bool, clauses ...spans.SpanQuery) *spans.SpanNearQuery {
+	query, err := spans.NewSpanNearQuery(clauses, slop, inOrder)
+	assert.Nil(t, err)
+	return query
+}
+
+// spanPositions Returns the start and end positions of the spans of query by document
+func spanPositions(t *testing.T, searcher *search.IndexSearcher, query spans.SpanQuery) map[int][][2]int {
+	rewritten, err := searcher.Rewrite(query)
+	assert.Nil(t, err)
+	weight, err := rewritten.(spans.SpanQuery).CreateSpanWeight(searcher, search.COMPLETE_NO_SCORES, 1)
+	assert.Nil(t, err)
+	leaves, err := searcher.GetIndexReader().Leaves()
+	assert.Nil(t, err)
+
+	positions := make(map[int][][2]int)
+	for _, leaf := range leaves {
+		leafSpans, err := weight.GetSpans(leaf, spans.POSTINGS_POSITIONS)
+		assert.Nil(t, err)
+		if leafSpans == nil {
+			continue
+		}
+		for {
+			doc, err := leafSpans.NextDoc(nil)
+			if errors.Is(err, io.EOF) || doc == types.NO_MORE_DOCS {
+				break
+			}
+			assert.Nil(t, err)
+			for {
+				start, err := leafSpans.NextStartPosition()
+				assert.Nil(t, err)
+				if start == spans.NO_MORE_POSITIONS {
+					break
+				}
+				positions[leaf.DocBase()+doc] = append(positions[leaf.DocBase()+doc], [2]int{start, leafSpans.EndPosition()})
+			}
+		}
+	}
+	return positions
+}
+
+// searchDocs Returns the documents matching query
+func searchDocs(t *testing.T, searcher *search.IndexSearcher, query index.Query) []int {
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 100)
+	assert.Nil(t, err)
+	docs := make([]int, 0, len(topDocs.GetScoreDocs()))
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		docs = append(docs, scoreDoc.GetDoc())
+	}
+	return docs
+}
+
+// assertSpans Checks the spans of query, and that the searcher finds the documents that have spans
+func assertSpans(t *testing.T, searcher *search.IndexSearcher, query spans.SpanQuery, expected map[int][][2]int) {
+	assert.Equal(t, expected, spanPositions(t, searcher, query), query.String(""))
+	docs := make([]int, 0, len(expected))
+	for doc := range expected {
+		docs = append(docs, doc)
+	}
+	assert.ElementsMatch(t, docs, searchDocs(t, searcher, query), query.String(""))
+}
+
+func TestSpanTermQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+
+	query := spanTerm("body", "quick")
+	assert.Equal(t, "body:quick", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}}, 1: {{6, 7}}, 2: {{0, 1}, {1, 2}}, 3: {{1, 2}}, 4: {{3, 4}},
+	})
+	assertSpans(t, searcher, spanTerm("body", "missing"), map[int][][2]int{})
+	assertSpans(t, searcher, spanTerm("missing", "quick"), map[int][][2]int{})
+}
+
+func TestSpanNearQuery_Ordered(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	quick, fox := spanTerm("body", "quick"), spanTerm("body", "fox")
+
+	query := spanNear(t, 0, true, quick, fox)
+	assert.Equal(t, "spanNear([body:quick, body:fox], 0, true)", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{1: {{6, 8}}, 2: {{1, 3}}})
+
+	assertSpans(t, searcher, spanNear(t, 1, true, quick, fox), map[int][][2]int{
+		0: {{1, 4}}, 1: {{6, 8}}, 2: {{0, 3}, {1, 3}},
+	})
+	// the order of the clauses matters
+	assertSpans(t, searcher, spanNear(t, 0, true, fox, quick), map[int][][2]int{3: {{0, 2}}})
+
+	query = spanNear(t, 1, true, spanTerm("body", "the"), quick, fox)
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{0, 4}}, 1: {{5, 8}}})
+
+	// a gap is a position that has to be there, but may be any term
+	query, err := spans.NewOrderedNearQuery("body").AddClause(quick).AddGap(1).AddClause(fox).Build()
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{1, 4}}, 2: {{0, 3}}})
+
+	_, err = spans.NewOrderedNearQuery("body").AddClause(spanTerm("title", "quick")).Build()
+	assert.NotNil(t, err)
+	_, err = spans.NewUnorderedNearQuery("body").AddGap(1).Build()
+	assert.NotNil(t, err)
+}
+
+func TestSpanNearQuery_Unordered(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	quick, fox := spanTerm("body", "quick"), spanTerm("body", "fox")
+
+	assertSpans(t, searcher, spanNear(t, 0, false, quick, fox), map[int][][2]int{
+		1: {{6, 8}}, 2: {{1, 3}}, 3: {{0, 2}},
+	})
+	assertSpans(t, searcher, spanNear(t, 1, false, fox, quick), map[int][][2]int{
+		0: {{1, 4}}, 1: {{6, 8}}, 2: {{0, 3}, {1, 3}}, 3: {{0, 2}}, 4: {{1, 4}},
+	})
+	assertSpans(t, searcher, spanNear(t, 1, false, spanTerm("body", "dog"), spanTerm("body", "lazy")), map[int][][2]int{
+		0: {{7, 9}}, 1: {{1, 3}},
+	})
+	assertSpans(t, searcher, spanNear(t, 10, false, fox, spanTerm("body", "missing")), map[int][][2]int{})
+}
+
+func TestSpanOrQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+
+	query, err := spans.NewSpanOrQuery(spanTerm("body", "dog"), spanTerm("body", "quick"))
+	assert.Nil(t, err)
+	assert.Equal(t, "spanOr([body:dog, body:quick])", query.String(""))
+	// the spans of all clauses, in the order of their positions
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}, {8, 9}}, 1: {{2, 3}, {6, 7}}, 2: {{0, 1}, {1, 2}}, 3: {{1, 2}}, 4: {{3, 4}}, 5: {{0, 1}},
+	})
+
+	query, err = spans.NewSpanOrQuery(spanNear(t, 0, true, spanTerm("body", "quick"), spanTerm("body", "fox")),
+		spanTerm("body", "fox"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{3, 4}}, 1: {{6, 8}, {7, 8}}, 2: {{1, 3}, {2, 3}}, 3: {{0, 1}}, 4: {{1, 2}},
+	})
+
+	_, err = spans.NewSpanOrQuery(spanTerm("body", "dog"), spanTerm("title", "dog"))
+	assert.NotNil(t, err)
+}
+
+func TestSpanNotQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	quick, fox := spanTerm("body", "quick"), spanTerm("body", "fox")
+
+	// excluded spans have to overlap
+	query, err := spans.NewSpanNotQuery(quick, fox)
+	assert.Nil(t, err)
+	assert.Equal(t, "spanNot(body:quick, body:fox, 0, 0)", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}}, 1: {{6, 7}}, 2: {{0, 1}, {1, 2}}, 3: {{1, 2}}, 4: {{3, 4}},
+	})
+
+	// no fox right after quick
+	query, err = spans.NewSpanNotQueryV2(quick, fox, 0, 1)
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}}, 2: {{0, 1}}, 3: {{1, 2}}, 4: {{3, 4}},
+	})
+
+	// no fox right before quick
+	query, err = spans.NewSpanNotQueryV2(quick, fox, 1, 0)
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}}, 1: {{6, 7}}, 2: {{0, 1}, {1, 2}}, 4: {{3, 4}},
+	})
+
+	query, err = spans.NewSpanNotQueryV1(quick, fox, 1)
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{
+		0: {{1, 2}}, 2: {{0, 1}}, 4: {{3, 4}},
+	})
+
+	// an excluded term inside of the included span
+	query, err = spans.NewSpanNotQuery(spanNear(t, 1, true, quick, fox), spanTerm("body", "brown"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{1: {{6, 8}}, 2: {{0, 3}, {1, 3}}})
+
+	_, err = spans.NewSpanNotQuery(quick, spanTerm("title", "fox"))
+	assert.NotNil(t, err)
+}
+
+func TestSpanFirstQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+
+	query := spans.NewSpanFirstQuery(spanTerm("body", "fox"), 2)
+	assert.Equal(t, "spanFirst(body:fox, 2)", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{3: {{0, 1}}, 4: {{1, 2}}})
+
+	assertSpans(t, searcher, spans.NewSpanFirstQuery(spanTerm("body", "quick"), 2), map[int][][2]int{
+		0: {{1, 2}}, 2: {{0, 1}, {1, 2}}, 3: {{1, 2}},
+	})
+	// the whole span has to end before end
+	query = spans.NewSpanFirstQuery(spanNear(t, 1, true, spanTerm("body", "quick"), spanTerm("body", "fox")), 3)
+	assertSpans(t, searcher, query, map[int][][2]int{2: {{0, 3}, {1, 3}}})
+	assertSpans(t, searcher, spans.NewSpanFirstQuery(spanTerm("body", "dog"), 0), map[int][][2]int{})
+}
+
+func TestSpanContainingQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	big := spanNear(t, 2, true, spanTerm("body", "quick"), spanTerm("body", "jumps"))
+	assertSpans(t, searcher, big, map[int][][2]int{0: {{1, 5}}, 1: {{6, 9}}})
+
+	// the spans of big that contain little
+	query, err := spans.NewSpanContainingQuery(big, spanTerm("body", "fox"))
+	assert.Nil(t, err)
+	assert.Equal(t, "SpanContaining(spanNear([body:quick, body:jumps], 2, true), body:fox)", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{1, 5}}, 1: {{6, 9}}})
+
+	query, err = spans.NewSpanContainingQuery(big, spanTerm("body", "brown"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{1, 5}}})
+
+	query, err = spans.NewSpanContainingQuery(big, spanTerm("body", "the"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{})
+
+	_, err = spans.NewSpanContainingQuery(big, spanTerm("title", "fox"))
+	assert.NotNil(t, err)
+}
+
+func TestSpanWithinQuery(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	big := spanNear(t, 2, true, spanTerm("body", "quick"), spanTerm("body", "jumps"))
+
+	// the spans of little inside of big
+	query, err := spans.NewSpanWithinQuery(big, spanTerm("body", "fox"))
+	assert.Nil(t, err)
+	assert.Equal(t, "SpanWithin(spanNear([body:quick, body:jumps], 2, true), body:fox)", query.String(""))
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{3, 4}}, 1: {{7, 8}}})
+
+	query, err = spans.NewSpanWithinQuery(big, spanTerm("body", "quick"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{1, 2}}, 1: {{6, 7}}})
+
+	query, err = spans.NewSpanWithinQuery(big, spanTerm("body", "the"))
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{})
+}
+
+func TestFieldMaskingSpanQuery(t *testing.T) {
+	searcher := searchtest.NewSearcher(t, fieldDocs([]string{"body", "title"},
+		[]string{"quick brown", "fox"},
+		[]string{"quick", "dog"},
+		[]string{"fox", "quick"},
+		[]string{"brown quick", "the fox"}))
+
+	masked := spans.NewFieldMaskingSpanQuery(spanTerm("title", "fox"), "body")
+	assert.Equal(t, "body", masked.GetField())
+	assert.Equal(t, "mask(title:fox) as body", masked.String(""))
+	assertSpans(t, searcher, masked, map[int][][2]int{0: {{0, 1}}, 3: {{1, 2}}})
+
+	// the masked spans are positioned as if they were in body
+	assertSpans(t, searcher, spanNear(t, 0, false, spanTerm("body", "quick"), masked), map[int][][2]int{
+		0: {{0, 1}}, 3: {{1, 2}},
+	})
+	assertSpans(t, searcher, spanNear(t, 0, true, spanTerm("body", "brown"), masked), map[int][][2]int{3: {{0, 2}}})
+
+	query, err := spans.NewSpanOrQuery(spanTerm("body", "fox"), masked)
+	assert.Nil(t, err)
+	assertSpans(t, searcher, query, map[int][][2]int{0: {{0, 1}}, 2: {{0, 1}}, 3: {{1, 2}}})
+
+	// without the mask the clauses are in different fields
+	_, err = spans.NewSpanOrQuery(spanTerm("body", "fox"), spanTerm("title", "fox"))
+	assert.NotNil(t, err)
+}
+
+func TestSpanQuery_Matches(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+	quick, fox := spanTerm("body", "quick"), spanTerm("body", "fox")
+
+	matchPositions := func(query spans.SpanQuery, doc int) [][2]int {
+		weight, err := searcher.CreateWeight(query, search.COMPLETE_NO_SCORES, 1)
+		assert.Nil(t, err)
+		leaves, err := searcher.GetIndexReader().Leaves()
+		assert.Nil(t, err)
+		leaf := leaves[coreIndex.SubIndexV1(doc, leaves)]
+
+		matches, err := weight.Matches(leaf, doc-leaf.DocBase())
+		assert.Nil(t, err)
+		if matches == nil {
+			return nil
+		}
+		it, err := matches.GetMatches("body")
+		assert.Nil(t, err)
+		if it == nil {
+			return nil
+		}
+		positions := make([][2]int, 0)
+		for {
+			ok, err := it.Next()
+			assert.Nil(t, err)
+			if !ok {
+				return positions
+			}
+			positions = append(positions, [2]int{it.StartPosition(), it.EndPosition()})
+		}
+	}
+
+	// the end positions of matches are inclusive
+	near := spanNear(t, 1, true, quick, fox)
+	assert.Equal(t, [][2]int{{1, 3}}, matchPositions(near, 0))
+	assert.Equal(t, [][2]int{{0, 2}, {1, 2}}, matchPositions(near, 2))
+	assert.Nil(t, matchPositions(near, 3))
+
+	query, err := spans.NewSpanNotQueryV2(quick, fox, 0, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{0, 0}}, matchPositions(query, 2))
+
+	query2, err := spans.NewSpanWithinQuery(spanNear(t, 2, true, quick, spanTerm("body", "jumps")), fox)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{7, 7}}, matchPositions(query2, 1))
+}
+
+func TestSpanQuery_Scoring(t *testing.T) {
+	searcher := newTestSpansSearcher(t)
+
+	// closer matches score higher
+	query := spanNear(t, 2, false, spanTerm("body", "quick"), spanTerm("body", "fox"))
+	topDocs, err := searcher.SearchTopN(context.Background(), query, 10)
+	assert.Nil(t, err)
+	scores := make(map[int]float64)
+	for _, scoreDoc := range topDocs.GetScoreDocs() {
+		scores[scoreDoc.GetDoc()] = scoreDoc.GetScore()
+		assert.Greater(t, scoreDoc.GetScore(), 0.0)
+	}
+	assert.Len(t, scores, 5)
+	assert.Greater(t, scores[3], scores[4])
+
+	explanation, err := searcher.Explain(query, 4)
+	assert.Nil(t, err)
+	assert.True(t, explanation.IsMatch())
+	assert.InDelta(t, scores[4], explanation.GetValue(), 1e-9)
+
+	explanation, err = searcher.Explain(query, 5)
+	assert.Nil(t, err)
+	assert.False(t, explanation.IsMatch())
+}
@@ -1,9 +1,16 @@
 package spans
 
 import (
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
 	"github.com/geange/lucene-go/core/types"
 )
 
+// NO_MORE_POSITIONS
+// The start and end position of a Spans that is exhausted in the current doc.
+const NO_MORE_POSITIONS = math.MaxInt32
+
 // Spans
 // Iterates through combinations of start/end positions per-doc. Each start/end position represents a range of
 // term positions within the current document. These are enumerated in order, by increasing document number,
@@ -32,9 +39,24 @@ type Spans interface {
 	// lower values means that the match is better.
 	Width() int
 
+	// Collect
 	// Collect postings data from the leaves of the current Spans. This method should only be called after
 	// nextStartPosition(), and before NO_MORE_POSITIONS has been reached.
 	// collector: a SpanCollector
 	// lucene.experimental
-	collect(collector SpanCollector)
+	Collect(collector SpanCollector) error
+
+	// PositionsCost
+	// Return an estimation of the cost of using the positions of this Spans for any single document,
+	// but only after AsTwoPhaseIterator returned nil. Otherwise this method should not be called.
+	// The returned value is independent of the current document.
+	// lucene.experimental
+	PositionsCost() float64
+
+	// AsTwoPhaseIterator
+	// Optional method: Return a TwoPhaseIterator view of this Spans. A return value of nil indicates
+	// that two-phase iteration is not supported. Note that the returned TwoPhaseIterator's approximation
+	// must advance documents synchronously with this iterator: advancing the approximation must advance
+	// this iterator and vice-versa.
+	AsTwoPhaseIterator() index.TwoPhaseIterator
 }
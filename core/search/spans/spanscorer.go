@@ -0,0 +1,124 @@
+package spans
+
+import (
+	"math"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ index.Scorer = &SpanScorer{}
+
+// SpanScorer
+// A basic Scorer over Spans.
+// lucene.experimental
+type SpanScorer struct {
+	*search.BaseScorer
+
+	spans     Spans
+	docScorer *search.LeafSimScorer
+
+	// accumulated sloppy freq (computed in setFreqCurrentDoc)
+	freq float64
+	// number of matches (computed in setFreqCurrentDoc)
+	numMatches       int
+	lastCollectedDoc int
+}
+
+// NewSpanScorer
+// Sole constructor. docScorer may be nil if scores are not needed.
+func NewSpanScorer(weight index.Weight, spans Spans, docScorer *search.LeafSimScorer) *SpanScorer {
+	return &SpanScorer{
+		BaseScorer:       search.NewScorer(weight),
+		spans:            spans,
+		docScorer:        docScorer,
+		lastCollectedDoc: -1,
+	}
+}
+
+// GetSpans
+// return the Spans for this Scorer
+func (s *SpanScorer) GetSpans() Spans {
+	return s.spans
+}
+
+func (s *SpanScorer) DocID() int {
+	return s.spans.DocID()
+}
+
+func (s *SpanScorer) Iterator() types.DocIdSetIterator {
+	return s.spans
+}
+
+func (s *SpanScorer) TwoPhaseIterator() index.TwoPhaseIterator {
+	return s.spans.AsTwoPhaseIterator()
+}
+
+// Score the current doc. The default implementation scores the doc with the similarity
+// using the slop-adjusted freq.
+func (s *SpanScorer) scoreCurrentDoc() (float64, error) {
+	if s.docScorer == nil {
+		return 0, nil
+	}
+	return s.docScorer.Score(s.DocID(), s.freq)
+}
+
+// Sets freq and numMatches for the current document.
+// This will be called at most once per document.
+func (s *SpanScorer) setFreqCurrentDoc() error {
+	s.freq = 0
+	s.numMatches = 0
+
+	startPos, err := s.spans.NextStartPosition()
+	if err != nil {
+		return err
+	}
+	for startPos != NO_MORE_POSITIONS {
+		s.numMatches++
+		if s.docScorer == nil { // scores not required, break out here
+			s.freq = 1
+			return nil
+		}
+		s.freq += 1.0 / (1.0 + float64(s.spans.Width()))
+
+		startPos, err = s.spans.NextStartPosition()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure setFreqCurrentDoc is called, if not already called for the current doc.
+func (s *SpanScorer) ensureFreq() error {
+	currentDoc := s.DocID()
+	if s.lastCollectedDoc != currentDoc {
+		if err := s.setFreqCurrentDoc(); err != nil {
+			return err
+		}
+		s.lastCollectedDoc = currentDoc
+	}
+	return nil
+}
+
+func (s *SpanScorer) Score() (float64, error) {
+	if err := s.ensureFreq(); err != nil {
+		return 0, err
+	}
+	return s.scoreCurrentDoc()
+}
+
+func (s *SpanScorer) GetMaxScore(upTo int) (float64, error) {
+	return math.MaxFloat32, nil
+}
+
+// SloppyFreq
+// Returns the intermediate "sloppy freq" adjusted for edit distance
+// lucene.internal
+func (s *SpanScorer) SloppyFreq() (float64, error) {
+	if err := s.ensureFreq(); err != nil {
+		return 0, err
+	}
+	return s.freq, nil
+}
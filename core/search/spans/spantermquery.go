@@ -0,0 +1,217 @@
+package spans
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	"github.com/geange/gods-generic/sets/treeset"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanQuery = &SpanTermQuery{}
+
+// SpanTermQuery
+// Matches spans containing a term. This should not be used for terms that are indexed at position math.MaxInt32.
+type SpanTermQuery struct {
+	term       index.Term
+	termStates *coreIndex.TermStates
+}
+
+// NewSpanTermQuery
+// Construct a SpanTermQuery matching the named term's spans.
+func NewSpanTermQuery(term index.Term) *SpanTermQuery {
+	return &SpanTermQuery{term: term}
+}
+
+// NewSpanTermQueryV1
+// Expert: Construct a SpanTermQuery matching the named term's spans, using the provided TermStates
+func NewSpanTermQueryV1(term index.Term, termStates *coreIndex.TermStates) *SpanTermQuery {
+	return &SpanTermQuery{
+		term:       term,
+		termStates: termStates,
+	}
+}
+
+// GetTerm
+// Return the term whose spans are matched.
+func (s *SpanTermQuery) GetTerm() index.Term {
+	return s.term
+}
+
+func (s *SpanTermQuery) GetField() string {
+	return s.term.Field()
+}
+
+func (s *SpanTermQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return s.CreateSpanWeight(searcher, scoreMode, boost)
+}
+
+func (s *SpanTermQuery) CreateSpanWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (SpanWeight, error) {
+	topContext := searcher.GetTopReaderContext()
+	states := s.termStates
+	if states == nil || !states.WasBuiltFor(topContext) {
+		var err error
+		states, err = coreIndex.BuildTermStates(topContext, s.term, scoreMode.NeedsScores())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var terms *treemap.Map[index.Term, *coreIndex.TermStates]
+	if scoreMode.NeedsScores() {
+		terms = treemap.NewWith[index.Term, *coreIndex.TermStates](index.TermCompare)
+		terms.Put(s.term, states)
+	}
+	return newSpanTermWeight(s, states, searcher, terms, boost)
+}
+
+func (s *SpanTermQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return s, nil
+}
+
+func (s *SpanTermQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(s.term.Field()) {
+		visitor.ConsumeTerms(s, s.term)
+	}
+	return nil
+}
+
+func (s *SpanTermQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if s.term.Field() != field {
+		buf.WriteString(s.term.Field())
+		buf.WriteString(":")
+	}
+	buf.WriteString(s.term.Text())
+	return buf.String()
+}
+
+var _ SpanWeight = &SpanTermWeight{}
+
+// SpanTermWeight
+// Creates SpanTermQuery scorer instances
+type SpanTermWeight struct {
+	*BaseSpanWeight
+
+	query      *SpanTermQuery
+	termStates *coreIndex.TermStates
+}
+
+func newSpanTermWeight(query *SpanTermQuery, termStates *coreIndex.TermStates, searcher index.IndexSearcher,
+	terms *treemap.Map[index.Term, *coreIndex.TermStates], boost float64) (*SpanTermWeight, error) {
+
+	weight := &SpanTermWeight{
+		query:      query,
+		termStates: termStates,
+	}
+	base, err := NewBaseSpanWeight(query, searcher, terms, boost, weight)
+	if err != nil {
+		return nil, err
+	}
+	weight.BaseSpanWeight = base
+	return weight, nil
+}
+
+func (s *SpanTermWeight) ExtractTerms(terms *treeset.Set[index.Term]) error {
+	terms.Add(s.query.term)
+	return nil
+}
+
+func (s *SpanTermWeight) IsCacheable(ctx index.LeafReaderContext) bool {
+	return true
+}
+
+func (s *SpanTermWeight) ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates]) {
+	contexts.Put(s.query.term, s.termStates)
+}
+
+func (s *SpanTermWeight) GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error) {
+	term := s.query.term
+	state, err := s.termStates.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil { // term is not present in that reader
+		return nil, nil
+	}
+
+	terms, err := ctx.LeafReader().Terms(term.Field())
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, nil
+	}
+	if !terms.HasPositions() {
+		return nil, fmt.Errorf(`field "%s" was indexed without position data; cannot run SpanTermQuery (term=%s)`,
+			term.Field(), term.Text())
+	}
+
+	termsEnum, err := terms.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	if err := termsEnum.SeekExactExpert(nil, term.Bytes(), state); err != nil {
+		return nil, err
+	}
+
+	postings, err := termsEnum.Postings(nil, requiredPostings.GetRequiredPostings())
+	if err != nil {
+		return nil, err
+	}
+	positionsCost, err := termPositionsCost(termsEnum)
+	if err != nil {
+		return nil, err
+	}
+	return NewTermSpans(postings, term, positionsCost*PHRASE_TO_SPAN_TERM_POSITIONS_COST), nil
+}
+
+const (
+	// A guess of the average number of simple operations for the initial seek and buffer refill
+	// per document for the positions of a term.
+	// See also Lucene50PostingsReader.BlockPostingsEnum.nextPosition().
+	// Aside: Instead of being constant this could depend among others on
+	// Lucene50PostingsFormat.BLOCK_SIZE,
+	// TermsEnum.docFreq(),
+	// TermsEnum.totalTermFreq(),
+	// DocIdSetIterator.cost() (expected number of matching docs),
+	// LeafReader.maxDoc() (total number of docs in the segment),
+	// and the seek time and block size of the device storing the index.
+	TERM_POSNS_SEEK_OPS_PER_DOC = 128
+
+	// Number of simple operations in Lucene50PostingsReader.BlockPostingsEnum.nextPosition()
+	// when no seek or buffer refill is done.
+	TERM_OPS_PER_POS = 7
+
+	// The positionsCost of a TermSpans is scaled with this factor, spans are more expensive
+	// to match than the positions of a PhraseQuery.
+	PHRASE_TO_SPAN_TERM_POSITIONS_COST = 4.0
+)
+
+// Returns an expected cost in simple operations of processing the occurrences of a term in a
+// document that contains the term.
+func termPositionsCost(termsEnum index.TermsEnum) (float64, error) {
+	docFreq, err := termsEnum.DocFreq()
+	if err != nil {
+		return 0, err
+	}
+	totalTermFreq, err := termsEnum.TotalTermFreq()
+	if err != nil {
+		return 0, err
+	}
+	expOccurrencesInMatchingDoc := float64(totalTermFreq) / float64(docFreq)
+	return TERM_POSNS_SEEK_OPS_PER_DOC + expOccurrencesInMatchingDoc*TERM_OPS_PER_POS, nil
+}
+
+// Equals Compares the terms only, the term states are a cache of the term lookup.
+func (s *SpanTermQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanTermQuery)
+	return ok && search.TermEquals(s.term, o.term)
+}
+
+func (s *SpanTermQuery) HashCode() int {
+	return 31*search.ClassHash("SpanTermQuery") + search.TermHashCode(s.term)
+}
@@ -0,0 +1,374 @@
+package spans
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	"github.com/geange/gods-generic/maps/treemap"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/types"
+)
+
+// Postings
+// Enumeration defining what postings information should be retrieved from the index for a given Spans
+type Postings int
+
+const (
+	POSTINGS_POSITIONS = Postings(iota)
+	POSTINGS_PAYLOADS
+	POSTINGS_OFFSETS
+)
+
+// GetRequiredPostings
+// Returns the flags to pass to TermsEnum.Postings to retrieve this postings information.
+func (p Postings) GetRequiredPostings() int {
+	switch p {
+	case POSTINGS_PAYLOADS:
+		return coreIndex.POSTINGS_ENUM_PAYLOADS
+	case POSTINGS_OFFSETS:
+		return coreIndex.POSTINGS_ENUM_PAYLOADS | coreIndex.POSTINGS_ENUM_OFFSETS
+	default:
+		return coreIndex.POSTINGS_ENUM_POSITIONS
+	}
+}
+
+// AtLeast
+// Returns the postings that retrieve at least the information of both p and postings.
+func (p Postings) AtLeast(postings Postings) Postings {
+	return max(p, postings)
+}
+
+// SpanWeight
+// Expert-only. Public for use by other weight implementations
+type SpanWeight interface {
+	index.Weight
+
+	// ExtractTermStates
+	// Collect all TermStates used by this Weight
+	// contexts: a map to add the TermStates to
+	ExtractTermStates(contexts *treemap.Map[index.Term, *coreIndex.TermStates])
+
+	// GetSpans
+	// Expert: Return a Spans object iterating over matches from this Weight,
+	// or nil if no spans can match in the given segment.
+	// ctx: a LeafReaderContext for this Spans
+	// requiredPostings: the postings information required by the Spans
+	GetSpans(ctx index.LeafReaderContext, requiredPostings Postings) (Spans, error)
+
+	// GetSimScorer
+	// Return a LeafSimScorer for this context, or nil if scores are not needed
+	GetSimScorer(ctx index.LeafReaderContext) (*search.LeafSimScorer, error)
+}
+
+// BaseSpanWeight
+// Scoring and matching shared by all SpanWeights, the spans themselves are provided by the
+// SpanWeight embedding it.
+type BaseSpanWeight struct {
+	*search.BaseWeight
+
+	spi        SpanWeight
+	similarity index.Similarity
+	simScorer  index.SimScorer
+	field      string
+}
+
+// NewBaseSpanWeight
+// Create a new SpanWeight
+// query: the parent query
+// searcher: the IndexSearcher to query against
+// termStates: a map of terms to TermStates for use in building the similarity. May be nil if scores are not required
+// spi: the SpanWeight embedding this BaseSpanWeight
+func NewBaseSpanWeight(query SpanQuery, searcher index.IndexSearcher,
+	termStates *treemap.Map[index.Term, *coreIndex.TermStates], boost float64, spi SpanWeight) (*BaseSpanWeight, error) {
+
+	weight := &BaseSpanWeight{
+		spi:        spi,
+		similarity: searcher.GetSimilarity(),
+		field:      query.GetField(),
+	}
+	weight.BaseWeight = search.NewBaseWeight(query, weight)
+
+	simScorer, err := buildSimWeight(query, searcher, termStates, boost)
+	if err != nil {
+		return nil, err
+	}
+	weight.simScorer = simScorer
+	return weight, nil
+}
+
+func buildSimWeight(query SpanQuery, searcher index.IndexSearcher,
+	termStates *treemap.Map[index.Term, *coreIndex.TermStates], boost float64) (index.SimScorer, error) {
+
+	if termStates == nil || termStates.Size() == 0 || query.GetField() == "" {
+		return nil, nil
+	}
+
+	termStats := make([]types.TermStatistics, 0, termStates.Size())
+	it := termStates.Iterator()
+	for it.Next() {
+		states := it.Value()
+		docFreq, err := states.DocFreq()
+		if err != nil {
+			return nil, err
+		}
+		if docFreq > 0 {
+			totalTermFreq, err := states.TotalTermFreq()
+			if err != nil {
+				return nil, err
+			}
+			stats, err := searcher.TermStatistics(it.Key(), docFreq, int(totalTermFreq))
+			if err != nil {
+				return nil, err
+			}
+			termStats = append(termStats, stats)
+		}
+	}
+
+	if len(termStats) == 0 {
+		// no terms at all exist, we won't use similarity
+		return nil, nil
+	}
+	collectionStats, err := searcher.CollectionStatistics(query.GetField())
+	if err != nil {
+		return nil, err
+	}
+	return searcher.GetSimilarity().Scorer(boost, collectionStats, termStats), nil
+}
+
+func (s *BaseSpanWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
+	scorer, err := s.spanScorer(ctx)
+	if err != nil || scorer == nil {
+		return nil, err
+	}
+	return scorer, nil
+}
+
+func (s *BaseSpanWeight) spanScorer(ctx index.LeafReaderContext) (*SpanScorer, error) {
+	spans, err := s.spi.GetSpans(ctx, POSTINGS_POSITIONS)
+	if err != nil {
+		return nil, err
+	}
+	if spans == nil {
+		return nil, nil
+	}
+	docScorer, err := s.GetSimScorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpanScorer(s.spi, spans, docScorer), nil
+}
+
+func (s *BaseSpanWeight) GetSimScorer(ctx index.LeafReaderContext) (*search.LeafSimScorer, error) {
+	if s.simScorer == nil {
+		return nil, nil
+	}
+	return search.NewLeafSimScorer(s.simScorer, ctx.LeafReader(), s.field, true)
+}
+
+func (s *BaseSpanWeight) Explain(ctx index.LeafReaderContext, doc int) (types.Explanation, error) {
+	scorer, err := s.spanScorer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil && s.simScorer != nil {
+		newDoc, err := scorer.Iterator().Advance(nil, doc)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if newDoc == doc {
+			freq, err := scorer.SloppyFreq()
+			if err != nil {
+				return nil, err
+			}
+			docScorer, err := search.NewLeafSimScorer(s.simScorer, ctx.LeafReader(), s.field, true)
+			if err != nil {
+				return nil, err
+			}
+			freqExplanation := types.ExplanationMatch(freq, fmt.Sprintf("phraseFreq=%v", freq))
+			scoreExplanation, err := docScorer.Explain(doc, freqExplanation)
+			if err != nil {
+				return nil, err
+			}
+
+			similarityType := reflect.TypeOf(s.similarity)
+			if similarityType.Kind() == reflect.Pointer {
+				similarityType = similarityType.Elem()
+			}
+			return types.ExplanationMatch(scoreExplanation.GetValue(),
+				fmt.Sprintf(`weight(%s in %d) [%s], result of:`, s.GetQuery().String(""), doc, similarityType.Name()),
+				scoreExplanation), nil
+		}
+	}
+	return types.ExplanationNoMatch("no matching term"), nil
+}
+
+func (s *BaseSpanWeight) Matches(ctx index.LeafReaderContext, doc int) (index.Matches, error) {
+	return search.MatchesForField(s.field, &spanMatches{
+		weight:  s,
+		context: ctx,
+		doc:     doc,
+	}), nil
+}
+
+var _ search.IOSupplier[index.MatchesIterator] = &spanMatches{}
+
+type spanMatches struct {
+	weight  *BaseSpanWeight
+	context index.LeafReaderContext
+	doc     int
+}
+
+func (r *spanMatches) Get() (index.MatchesIterator, error) {
+	spans, err := r.weight.spi.GetSpans(r.context, POSTINGS_OFFSETS)
+	if err != nil {
+		return nil, err
+	}
+	if spans == nil {
+		return nil, nil
+	}
+	doc, err := spans.Advance(nil, r.doc)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if doc != r.doc {
+		return nil, nil
+	}
+	return &spanMatchesIterator{
+		spans: spans,
+		query: r.weight.GetQuery(),
+	}, nil
+}
+
+var _ index.MatchesIterator = &spanMatchesIterator{}
+
+// spanMatchesIterator
+// A MatchesIterator over the spans of a document, the leaves of each span are collected to report
+// its offsets and its individual terms as sub-matches.
+type spanMatchesIterator struct {
+	spans      Spans
+	query      index.Query
+	innerTerms []innerTerm
+}
+
+type innerTerm struct {
+	position    int
+	startOffset int
+	endOffset   int
+}
+
+func (s *spanMatchesIterator) CollectLeaf(postings index.PostingsEnum, position int, term index.Term) error {
+	startOffset, err := postings.StartOffset()
+	if err != nil {
+		return err
+	}
+	endOffset, err := postings.EndOffset()
+	if err != nil {
+		return err
+	}
+	s.innerTerms = append(s.innerTerms, innerTerm{
+		position:    position,
+		startOffset: startOffset,
+		endOffset:   endOffset,
+	})
+	return nil
+}
+
+func (s *spanMatchesIterator) Reset() {
+	s.innerTerms = s.innerTerms[:0]
+}
+
+func (s *spanMatchesIterator) Next() (bool, error) {
+	pos, err := s.spans.NextStartPosition()
+	if err != nil {
+		return false, err
+	}
+	if pos == NO_MORE_POSITIONS {
+		return false, nil
+	}
+	s.Reset()
+	if err := s.spans.Collect(s); err != nil {
+		return false, err
+	}
+	slices.SortStableFunc(s.innerTerms, func(a, b innerTerm) int {
+		return a.position - b.position
+	})
+	return true, nil
+}
+
+func (s *spanMatchesIterator) StartPosition() int {
+	return s.spans.StartPosition()
+}
+
+func (s *spanMatchesIterator) EndPosition() int {
+	return s.spans.EndPosition() - 1
+}
+
+func (s *spanMatchesIterator) StartOffset() (int, error) {
+	if len(s.innerTerms) == 0 {
+		return -1, nil
+	}
+	return s.innerTerms[0].startOffset, nil
+}
+
+func (s *spanMatchesIterator) EndOffset() (int, error) {
+	if len(s.innerTerms) == 0 {
+		return -1, nil
+	}
+	return s.innerTerms[len(s.innerTerms)-1].endOffset, nil
+}
+
+func (s *spanMatchesIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return &innerTermsIterator{
+		terms: slices.Clone(s.innerTerms),
+		upto:  -1,
+		query: s.query,
+	}, nil
+}
+
+func (s *spanMatchesIterator) GetQuery() index.Query {
+	return s.query
+}
+
+var _ index.MatchesIterator = &innerTermsIterator{}
+
+// innerTermsIterator
+// Iterates over the individual terms of a span, which are leaves.
+type innerTermsIterator struct {
+	terms []innerTerm
+	upto  int
+	query index.Query
+}
+
+func (i *innerTermsIterator) Next() (bool, error) {
+	i.upto++
+	return i.upto < len(i.terms), nil
+}
+
+func (i *innerTermsIterator) StartPosition() int {
+	return i.terms[i.upto].position
+}
+
+func (i *innerTermsIterator) EndPosition() int {
+	return i.terms[i.upto].position
+}
+
+func (i *innerTermsIterator) StartOffset() (int, error) {
+	return i.terms[i.upto].startOffset, nil
+}
+
+func (i *innerTermsIterator) EndOffset() (int, error) {
+	return i.terms[i.upto].endOffset, nil
+}
+
+func (i *innerTermsIterator) GetSubMatches() (index.MatchesIterator, error) {
+	return nil, nil
+}
+
+func (i *innerTermsIterator) GetQuery() index.Query {
+	return i.query
+}
@@ -0,0 +1,116 @@
+package spans
+
+import (
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+)
+
+var _ SpanContainQuerySPI = &SpanWithinQuery{}
+
+// SpanWithinQuery
+// Keep matches that are contained within another Spans.
+type SpanWithinQuery struct {
+	*SpanContainQuery
+}
+
+// NewSpanWithinQuery
+// Construct a SpanWithinQuery matching spans from little that are inside of big.
+// This query has the boost of little. big and little must be in the same field.
+func NewSpanWithinQuery(big, little SpanQuery) (*SpanWithinQuery, error) {
+	query := &SpanWithinQuery{}
+	containQuery, err := newSpanContainQuery(big, little, query)
+	if err != nil {
+		return nil, err
+	}
+	query.SpanContainQuery = containQuery
+	return query, nil
+}
+
+func (s *SpanWithinQuery) String(field string) string {
+	return s.toString(field, "SpanWithin")
+}
+
+func (s *SpanWithinQuery) WithClauses(big, little SpanQuery) (SpanQuery, error) {
+	return NewSpanWithinQuery(big, little)
+}
+
+// GetContainSpans
+// Return spans from little that are contained in a spans from big. The payload is from the spans of little.
+func (s *SpanWithinQuery) GetContainSpans(bigSpans, littleSpans Spans) (Spans, error) {
+	spans := &spanWithinSpans{}
+	containSpans, err := newContainSpans(bigSpans, littleSpans, littleSpans, spans)
+	if err != nil {
+		return nil, err
+	}
+	spans.ContainSpans = containSpans
+	return spans, nil
+}
+
+var _ Spans = &spanWithinSpans{}
+
+type spanWithinSpans struct {
+	*ContainSpans
+}
+
+func (s *spanWithinSpans) twoPhaseCurrentDocMatches() (bool, error) {
+	s.oneExhaustedInCurrentDoc = false
+	ok, err := s.nextWithin()
+	if err != nil || !ok {
+		return false, err
+	}
+	s.atFirstInCurrentDoc = true
+	return true, nil
+}
+
+func (s *spanWithinSpans) NextStartPosition() (int, error) {
+	if s.atFirstInCurrentDoc {
+		s.atFirstInCurrentDoc = false
+		return s.littleSpans.StartPosition(), nil
+	}
+	ok, err := s.nextWithin()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return NO_MORE_POSITIONS, nil
+	}
+	return s.littleSpans.StartPosition(), nil
+}
+
+// nextWithin
+// Moves little to its next span that is contained in a span of big.
+func (s *spanWithinSpans) nextWithin() (bool, error) {
+	for {
+		littleStart, err := s.littleSpans.NextStartPosition()
+		if err != nil {
+			return false, err
+		}
+		if littleStart == NO_MORE_POSITIONS {
+			break
+		}
+		for s.bigSpans.EndPosition() < s.littleSpans.EndPosition() {
+			bigStart, err := s.bigSpans.NextStartPosition()
+			if err != nil {
+				return false, err
+			}
+			if bigStart == NO_MORE_POSITIONS {
+				s.oneExhaustedInCurrentDoc = true
+				return false, nil
+			}
+		}
+		if s.bigSpans.StartPosition() <= s.littleSpans.StartPosition() {
+			return true, nil
+		}
+	}
+	s.oneExhaustedInCurrentDoc = true
+	return false, nil
+}
+
+func (s *SpanWithinQuery) Equals(other index.Query) bool {
+	o, ok := other.(*SpanWithinQuery)
+	return ok && s.equalsTo(o.SpanContainQuery)
+}
+
+func (s *SpanWithinQuery) HashCode() int {
+	return 31*search.ClassHash("SpanWithinQuery") + s.fieldsHashCode()
+}
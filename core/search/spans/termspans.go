@@ -0,0 +1,143 @@
+package spans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+)
+
+var _ Spans = &TermSpans{}
+
+// TermSpans
+// Expert: Public for extension only. This does not work correctly for terms that indexed at position
+// math.MaxInt32.
+type TermSpans struct {
+	postings      index.PostingsEnum
+	term          index.Term
+	doc           int
+	freq          int
+	count         int
+	position      int
+	positionsCost float64
+}
+
+func NewTermSpans(postings index.PostingsEnum, term index.Term, positionsCost float64) *TermSpans {
+	return &TermSpans{
+		postings:      postings,
+		term:          term,
+		doc:           -1,
+		position:      -1,
+		positionsCost: positionsCost,
+	}
+}
+
+func (t *TermSpans) DocID() int {
+	return t.doc
+}
+
+func (t *TermSpans) NextDoc(ctx context.Context) (int, error) {
+	doc, err := t.postings.NextDoc(ctx)
+	return t.toDoc(doc, err)
+}
+
+func (t *TermSpans) Advance(ctx context.Context, target int) (int, error) {
+	doc, err := t.postings.Advance(ctx, target)
+	return t.toDoc(doc, err)
+}
+
+func (t *TermSpans) toDoc(doc int, err error) (int, error) {
+	t.position = -1
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		doc = types.NO_MORE_DOCS
+	}
+	t.doc = doc
+	if doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	freq, err := t.postings.Freq()
+	if err != nil {
+		return 0, err
+	}
+	t.freq = freq
+	t.count = 0
+	return doc, nil
+}
+
+func (t *TermSpans) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, t, target)
+}
+
+func (t *TermSpans) Cost() int64 {
+	return t.postings.Cost()
+}
+
+func (t *TermSpans) NextStartPosition() (int, error) {
+	if t.count == t.freq {
+		t.position = NO_MORE_POSITIONS
+		return t.position, nil
+	}
+	position, err := t.postings.NextPosition()
+	if err != nil {
+		return 0, err
+	}
+	t.position = position
+	t.count++
+	return t.position, nil
+}
+
+func (t *TermSpans) StartPosition() int {
+	return t.position
+}
+
+func (t *TermSpans) EndPosition() int {
+	switch t.position {
+	case -1:
+		return -1
+	case NO_MORE_POSITIONS:
+		return NO_MORE_POSITIONS
+	default:
+		return t.position + 1
+	}
+}
+
+func (t *TermSpans) Width() int {
+	return 0
+}
+
+func (t *TermSpans) Collect(collector SpanCollector) error {
+	return collector.CollectLeaf(t.postings, t.position, t.term)
+}
+
+func (t *TermSpans) PositionsCost() float64 {
+	return t.positionsCost
+}
+
+func (t *TermSpans) AsTwoPhaseIterator() index.TwoPhaseIterator {
+	return nil
+}
+
+// GetPostings
+// Returns the underlying postings enum
+func (t *TermSpans) GetPostings() index.PostingsEnum {
+	return t.postings
+}
+
+func (t *TermSpans) String() string {
+	switch t.doc {
+	case -1:
+		return fmt.Sprintf("spans(%s:%s)@START", t.term.Field(), t.term.Text())
+	case types.NO_MORE_DOCS:
+		return fmt.Sprintf("spans(%s:%s)@ENDDOC", t.term.Field(), t.term.Text())
+	}
+	if t.position == NO_MORE_POSITIONS {
+		return fmt.Sprintf("spans(%s:%s)@%d - ENDPOS", t.term.Field(), t.term.Text(), t.doc)
+	}
+	return fmt.Sprintf("spans(%s:%s)@%d - %d", t.term.Field(), t.term.Text(), t.doc, t.position)
+}
@@ -52,3 +52,12 @@ func (t *TermInSetQuery) String(field string) string {
 	//TODO implement me
 	panic("implement me")
 }
+
+func (t *TermInSetQuery) Equals(other index.Query) bool {
+	o, ok := other.(*TermInSetQuery)
+	return ok && t.field == o.field && t.termData == o.termData
+}
+
+func (t *TermInSetQuery) HashCode() int {
+	return 31*ClassHash("TermInSetQuery") + HashString(t.field)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/geange/gods-generic/sets/treeset"
@@ -86,6 +87,17 @@ func (t *TermQuery) Visit(visitor index.QueryVisitor) error {
 	return nil
 }
 
+// Equals
+// Two TermQuery are equal if their terms are equal, the term states are not compared.
+func (t *TermQuery) Equals(other index.Query) bool {
+	o, ok := other.(*TermQuery)
+	return ok && TermEquals(t.term, o.term)
+}
+
+func (t *TermQuery) HashCode() int {
+	return 31*ClassHash("TermQuery") + TermHashCode(t.term)
+}
+
 var _ index.Weight = &TermWeight{}
 
 type TermWeight struct {
@@ -112,47 +124,43 @@ func (t *TermWeight) Explain(context index.LeafReaderContext, doc int) (types.Ex
 	if err != nil {
 		return nil, err
 	}
-	if scorer == nil {
-		return nil, errors.New("no matching term")
-	}
-
-	tScorer, ok := scorer.(*TermScorer)
-	if !ok {
-		return nil, errors.New("no matching term")
-	}
 
-	newDoc, err := tScorer.Iterator().Advance(nil, doc)
-	if err != nil {
-		return nil, err
-	}
-	if newDoc == doc {
-		freq, err := tScorer.Freq()
-		if err != nil {
+	if tScorer, ok := scorer.(*TermScorer); ok {
+		newDoc, err := tScorer.Iterator().Advance(nil, doc)
+		if err != nil && !errors.Is(err, io.EOF) {
 			return nil, err
 		}
+		if newDoc == doc {
+			freq, err := tScorer.Freq()
+			if err != nil {
+				return nil, err
+			}
 
-		docScorer, err := NewLeafSimScorer(t.simScorer, context.Reader().(index.LeafReader), t.term.Field(), true)
-		if err != nil {
-			return nil, err
-		}
+			docScorer, err := NewLeafSimScorer(t.simScorer, context.LeafReader(), t.term.Field(), true)
+			if err != nil {
+				return nil, err
+			}
 
-		freqExplanation := types.ExplanationMatch(freq, "freq, occurrences of term within document")
-		scoreExplanation, err := docScorer.Explain(doc, freqExplanation)
-		if err != nil {
-			return nil, err
-		}
+			freqExplanation := types.ExplanationMatch(float64(freq), "freq, occurrences of term within document")
+			scoreExplanation, err := docScorer.Explain(doc, freqExplanation)
+			if err != nil {
+				return nil, err
+			}
 
-		return types.ExplanationMatch(scoreExplanation.GetValue().(float64),
-			fmt.Sprintf(`weight(%s in %d) [%s]`, t.GetQuery(), doc, reflect.TypeOf(t.similarity).Name()),
-			scoreExplanation), nil
+			similarityType := reflect.TypeOf(t.similarity)
+			if similarityType.Kind() == reflect.Pointer {
+				similarityType = similarityType.Elem()
+			}
+			return types.ExplanationMatch(scoreExplanation.GetValue(),
+				fmt.Sprintf(`weight(%s in %d) [%s], result of:`, t.GetQuery().String(""), doc, similarityType.Name()),
+				scoreExplanation), nil
+		}
 	}
-
-	return nil, errors.New("no matching term")
+	return types.ExplanationNoMatch("no matching term"), nil
 }
 
 func (t *TermWeight) GetQuery() index.Query {
-	//TODO implement me
-	panic("implement me")
+	return t.TermQuery
 }
 
 func (t *TermWeight) Scorer(ctx index.LeafReaderContext) (index.Scorer, error) {
@@ -226,7 +234,7 @@ func (t *TermQuery) NewTermWeight(searcher index.IndexSearcher, scoreMode index.
 		TermQuery:  t,
 	}
 
-	weight.BaseWeight = NewBaseWeight(weight, weight)
+	weight.BaseWeight = NewBaseWeight(t, weight)
 
 	var collectionStats types.CollectionStatistics
 	var termStats types.TermStatistics
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/geange/lucene-go/core/interface/index"
@@ -34,75 +35,94 @@ func MergeTopDocs(start, topN int, shardHits []index.TopDocs, setShardIndex bool
 	return mergeAuxTopDocs(nil, start, topN, shardHits, setShardIndex)
 }
 
+// MergeTopFieldDocs
+// Returns a new TopFieldDocs, containing topN results across the provided TopFieldDocs, sorting by
+// the specified Sort. Each of the TopDocs must have been sorted by the same Sort, and sort field
+// values must have been filled (ie, fillFields=true must be passed to TopFieldCollector.create).
+// If setShardIndex is true, this overwrites the shardIndex of each ScoreDoc to the index of the
+// shard it came from.
+func MergeTopFieldDocs(sort index.Sort, start, topN int, shardHits []index.TopDocs, setShardIndex bool) (index.TopDocs, error) {
+	if sort == nil {
+		return nil, errors.New("sort must be non-null when merging field-docs")
+	}
+	return mergeAuxTopDocs(sort, start, topN, shardHits, setShardIndex)
+}
+
 // Auxiliary method used by the merge impls.
 // A sort value of null is used to indicate that docs should be sorted by score.
 func mergeAuxTopDocs(sort index.Sort, start, size int, shardHits []index.TopDocs, setShardIndex bool) (index.TopDocs, error) {
+	var queue *structure.PriorityQueue[*ShardRef]
 	if sort == nil {
-		queue := NewScoreMergeSortQueue(shardHits)
-		totalHitCount := int64(0)
-		totalHitsRelation := index.EQUAL_TO
-		availHitCount := 0
-		for shardIDX := 0; shardIDX < len(shardHits); shardIDX++ {
-			shard := shardHits[shardIDX]
-			// totalHits can be non-zero even if no hits were
-			// collected, when searchAfter was used:
-			totalHitCount += shard.GetTotalHits().Value
-			// If any hit count is a lower bound then the merged
-			// total hit count is a lower bound as well
-			if shard.GetTotalHits().Relation == index.GREATER_THAN_OR_EQUAL_TO {
-				totalHitsRelation = index.GREATER_THAN_OR_EQUAL_TO
+		queue = NewScoreMergeSortQueue(shardHits).PriorityQueue
+	} else {
+		mergeSortQueue, err := NewMergeSortQueue(sort, shardHits)
+		if err != nil {
+			return nil, err
+		}
+		queue = mergeSortQueue.PriorityQueue
+	}
+
+	totalHitCount := int64(0)
+	totalHitsRelation := index.EQUAL_TO
+	availHitCount := 0
+	for shardIDX := 0; shardIDX < len(shardHits); shardIDX++ {
+		shard := shardHits[shardIDX]
+		// totalHits can be non-zero even if no hits were
+		// collected, when searchAfter was used:
+		totalHitCount += shard.GetTotalHits().Value
+		// If any hit count is a lower bound then the merged
+		// total hit count is a lower bound as well
+		if shard.GetTotalHits().Relation == index.GREATER_THAN_OR_EQUAL_TO {
+			totalHitsRelation = index.GREATER_THAN_OR_EQUAL_TO
+		}
+		if shard.GetScoreDocs() != nil && len(shard.GetScoreDocs()) > 0 {
+			availHitCount += len(shard.GetScoreDocs())
+			queue.Add(NewShardRef(shardIDX, setShardIndex == false))
+		}
+	}
+
+	var hits []index.ScoreDoc
+	if availHitCount <= start {
+		hits = make([]index.ScoreDoc, 0)
+	} else {
+		hits = make([]index.ScoreDoc, min(size, availHitCount-start))
+		requestedResultWindow := start + size
+		numIterOnHits := min(availHitCount, requestedResultWindow)
+		hitUpto := 0
+
+		for hitUpto < numIterOnHits {
+			//assert queue.size() > 0;
+			ref := queue.Top()
+			hit := shardHits[ref.shardIndex].GetScoreDocs()[ref.hitIndex]
+			ref.hitIndex++
+
+			if setShardIndex {
+				// caller asked us to record shardIndex (index of the TopDocs array) this hit is coming from:
+				hit.SetShardIndex(ref.shardIndex)
+			} else if hit.GetShardIndex() == -1 {
+				return nil, fmt.Errorf("setShardIndex is false but TopDocs[%d].scoreDocs[%d] is not set", ref.shardIndex, ref.hitIndex-1)
 			}
-			if shard.GetScoreDocs() != nil && len(shard.GetScoreDocs()) > 0 {
-				availHitCount += len(shard.GetScoreDocs())
-				queue.Add(NewShardRef(shardIDX, setShardIndex == false))
+
+			if hitUpto >= start {
+				hits[hitUpto-start] = hit
 			}
-		}
 
-		var hits []index.ScoreDoc
-		if availHitCount <= start {
-			hits = make([]index.ScoreDoc, 0)
-		} else {
-			hits = make([]index.ScoreDoc, min(size, availHitCount-start))
-			requestedResultWindow := start + size
-			numIterOnHits := min(availHitCount, requestedResultWindow)
-			hitUpto := 0
-
-			for hitUpto < numIterOnHits {
-				//assert queue.size() > 0;
-				ref := queue.Top()
-				hit := shardHits[ref.shardIndex].GetScoreDocs()[ref.hitIndex]
-				ref.hitIndex++
-
-				if setShardIndex {
-					// caller asked us to record shardIndex (index of the TopDocs array) this hit is coming from:
-					hit.SetShardIndex(ref.shardIndex)
-				} else if hit.GetShardIndex() == -1 {
-					return nil, fmt.Errorf("setShardIndex is false but TopDocs[%d].scoreDocs[%d] is not set", ref.shardIndex, ref.hitIndex-1)
-				}
-
-				if hitUpto >= start {
-					hits[hitUpto-start] = hit
-				}
-
-				hitUpto++
-
-				if ref.hitIndex < len(shardHits[ref.shardIndex].GetScoreDocs()) {
-					// Not done with this these TopDocs yet:
-					queue.UpdateTop()
-				} else {
-					queue.Pop()
-				}
+			hitUpto++
+
+			if ref.hitIndex < len(shardHits[ref.shardIndex].GetScoreDocs()) {
+				// Not done with this these TopDocs yet:
+				queue.UpdateTop()
+			} else {
+				queue.Pop()
 			}
 		}
+	}
 
-		totalHits := index.NewTotalHits(totalHitCount, totalHitsRelation)
-		if sort == nil {
-			return NewTopDocs(totalHits, hits), nil
-		}
-		return NewTopFieldDocs(totalHits, hits, sort.GetSort()), nil
-	} else {
-		panic("")
+	totalHits := index.NewTotalHits(totalHitCount, totalHitsRelation)
+	if sort == nil {
+		return NewTopDocs(totalHits, hits), nil
 	}
+	return NewTopFieldDocs(totalHits, hits, sort.GetSort()), nil
 }
 
 // ShardRef
@@ -166,6 +186,64 @@ func NewScoreMergeSortQueue(shardHits []index.TopDocs) *ScoreMergeSortQueue {
 	return queue
 }
 
+type MergeSortQueue struct {
+	*structure.PriorityQueue[*ShardRef]
+
+	// These are really FieldDoc instances:
+	shardHits   [][]index.ScoreDoc
+	comparators []index.FieldComparator
+	reverseMul  []int
+}
+
+func NewMergeSortQueue(sort index.Sort, shardHits []index.TopDocs) (*MergeSortQueue, error) {
+	queue := &MergeSortQueue{
+		shardHits: make([][]index.ScoreDoc, len(shardHits)),
+	}
+	for shardIDX, shardHit := range shardHits {
+		shard := shardHit.GetScoreDocs()
+		if shard == nil {
+			continue
+		}
+		queue.shardHits[shardIDX] = shard
+		// Fail gracefully if API is misused:
+		for _, scoreDoc := range shard {
+			if _, ok := scoreDoc.(FieldDoc); !ok {
+				return nil, fmt.Errorf("shard %d was not sorted by the provided Sort", shardIDX)
+			}
+		}
+	}
+
+	sortFields := sort.GetSort()
+	queue.comparators = make([]index.FieldComparator, len(sortFields))
+	queue.reverseMul = make([]int, len(sortFields))
+	for compIDX, sortField := range sortFields {
+		comparator, err := sortField.GetComparator(1, compIDX)
+		if err != nil {
+			return nil, err
+		}
+		queue.comparators[compIDX] = comparator
+		queue.reverseMul[compIDX] = 1
+		if sortField.GetReverse() {
+			queue.reverseMul[compIDX] = -1
+		}
+	}
+
+	queue.PriorityQueue = structure.NewPriorityQueue(len(shardHits), func(first, second *ShardRef) bool {
+		//assert first != second;
+		firstFD := queue.shardHits[first.shardIndex][first.hitIndex].(FieldDoc)
+		secondFD := queue.shardHits[second.shardIndex][second.hitIndex].(FieldDoc)
+
+		for compIDX, comparator := range queue.comparators {
+			cmp := queue.reverseMul[compIDX] * comparator.CompareValues(firstFD.GetFields()[compIDX], secondFD.GetFields()[compIDX])
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return tieBreakLessThan(first, firstFD, second, secondFD)
+	})
+	return queue, nil
+}
+
 func tieBreakLessThan(first *ShardRef, firstDoc index.ScoreDoc, second *ShardRef, secondDoc index.ScoreDoc) bool {
 	firstShardIndex := first.GetShardIndex(firstDoc)
 	secondShardIndex := second.GetShardIndex(secondDoc)
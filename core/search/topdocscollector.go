@@ -66,14 +66,27 @@ var EMPTY_TOPDOCS = &BaseTopDocs{
 	scoreDocs: make([]index.ScoreDoc, 0),
 }
 
+// TopDocsCollectorSPI
+// The methods of TopDocsCollector a collector embedding TopDocsCollectorDefault may override,
+// TopDocsRange calls them through the spi.
+type TopDocsCollectorSPI interface {
+	TopDocsSize() int
+	PopulateResults(results []index.ScoreDoc, howMany int) error
+	NewTopDocs(results []index.ScoreDoc, howMany int) (index.TopDocs, error)
+}
+
 type TopDocsCollectorDefault[T index.ScoreDoc] struct {
 	pq                *structure.PriorityQueue[T]
 	totalHits         int
 	totalHitsRelation index.TotalHitsRelation
+
+	spi TopDocsCollectorSPI
 }
 
 func newTopDocsCollectorDefault[T index.ScoreDoc](pq *structure.PriorityQueue[T]) *TopDocsCollectorDefault[T] {
-	return &TopDocsCollectorDefault[T]{pq: pq}
+	collector := &TopDocsCollectorDefault[T]{pq: pq}
+	collector.spi = collector
+	return collector
 }
 
 func (t *TopDocsCollectorDefault[T]) PopulateResults(results []index.ScoreDoc, howMany int) error {
@@ -109,25 +122,25 @@ func (t *TopDocsCollectorDefault[T]) TopDocsSize() int {
 }
 
 func (t *TopDocsCollectorDefault[T]) TopDocs() (index.TopDocs, error) {
-	return t.TopDocsRange(0, t.TopDocsSize())
+	return t.TopDocsRange(0, t.spi.TopDocsSize())
 }
 
 func (t *TopDocsCollectorDefault[T]) TopDocsFrom(start int) (index.TopDocs, error) {
-	return t.TopDocsRange(start, t.TopDocsSize())
+	return t.TopDocsRange(start, t.spi.TopDocsSize())
 }
 
 func (t *TopDocsCollectorDefault[T]) TopDocsRange(start, howMany int) (index.TopDocs, error) {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	size := t.TopDocsSize()
+	size := t.spi.TopDocsSize()
 
 	// Don't bother to throw an exception, just return an empty TopDocs in case
 	// the parameters are invalid or out of range.
 	// TODO: shouldn't we throw IAE if apps give bad params here so they dont
 	// have sneaky silent bugs?
 	if start < 0 || start >= size || howMany <= 0 {
-		return t.NewTopDocs(nil, start)
+		return t.spi.NewTopDocs(nil, start)
 	}
 
 	// We know that start < pqsize, so just fix howMany.
@@ -144,9 +157,9 @@ func (t *TopDocsCollectorDefault[T]) TopDocsRange(start, howMany int) (index.Top
 	}
 
 	// Get the requested results from pq.
-	if err := t.PopulateResults(results, howMany); err != nil {
+	if err := t.spi.PopulateResults(results, howMany); err != nil {
 		return nil, err
 	}
 
-	return t.NewTopDocs(results, start)
+	return t.spi.NewTopDocs(results, start)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 
 	cindex "github.com/geange/lucene-go/core/index"
 	"github.com/geange/lucene-go/core/interface/index"
@@ -27,7 +28,10 @@ func TopTopFieldCollectorCreate(sort index.Sort, numHits int, after FieldDoc,
 	}
 
 	// here we assume that if hitsThreshold was set, we let a comparator to skip non-competitive docs
-	queue := CreateFieldValueHitQueue(sort.GetSort(), numHits)
+	queue, err := CreateFieldValueHitQueue(sort.GetSort(), numHits)
+	if err != nil {
+		return nil, err
+	}
 	if after == nil {
 		// inform a comparator that sort is based on this single field
 		// to enable some optimizations for skipping over non-competitive documents
@@ -63,21 +67,26 @@ func (c *MultiComparatorLeafCollector) SetScorer(scorer index.Scorable) error {
 	return nil
 }
 
-func NewMultiComparatorLeafCollector(comparators []index.LeafFieldComparator, reverseMul []int) *MultiComparatorLeafCollector {
+func NewMultiComparatorLeafCollector(comparators []index.LeafFieldComparator, reverseMul []int) (*MultiComparatorLeafCollector, error) {
 	this := &MultiComparatorLeafCollector{}
 	if len(comparators) == 1 {
 		this.reverseMul = reverseMul[0]
 		this.comparator = comparators[0]
 	} else {
+		comparator, err := NewMultiLeafFieldComparator(comparators, reverseMul)
+		if err != nil {
+			return nil, err
+		}
 		this.reverseMul = 1
-		this.comparator = NewMultiLeafFieldComparator(comparators, reverseMul)
+		this.comparator = comparator
 	}
-	return this
+	return this, nil
 }
 
 type TopFieldCollector struct {
 	*TopDocsCollectorDefault[*Entry]
 
+	queue                FieldValueHitQueue[*Entry]
 	numHits              int
 	hitsThresholdChecker HitsThresholdChecker
 	firstComparator      index.FieldComparator
@@ -105,6 +114,55 @@ type TopFieldCollector struct {
 	scoreMode index.ScoreMode
 }
 
+func newTopFieldCollector(queue FieldValueHitQueue[*Entry], numHits int, hitsThresholdChecker HitsThresholdChecker,
+	needsScores bool, minScoreAcc *MaxScoreAccumulator) *TopFieldCollector {
+
+	collector := &TopFieldCollector{
+		TopDocsCollectorDefault: newTopDocsCollectorDefault(queue.GetPriorityQueue()),
+		queue:                   queue,
+		numHits:                 numHits,
+		hitsThresholdChecker:    hitsThresholdChecker,
+		numComparators:          len(queue.GetComparatorsList()),
+		firstComparator:         queue.GetComparatorsList()[0],
+		minScoreAcc:             minScoreAcc,
+		needsScores:             needsScores,
+	}
+	collector.spi = collector
+
+	reverseMul := queue.GetReverseMul()[0]
+	_, isRelevance := collector.firstComparator.(*cindex.RelevanceComparator)
+	if isRelevance && reverseMul == 1 && hitsThresholdChecker.GetHitsThreshold() != math.MaxInt32 {
+		collector.scoreMode = TOP_SCORES
+		collector.canSetMinScore = true
+	} else {
+		collector.canSetMinScore = false
+		if hitsThresholdChecker.GetHitsThreshold() != math.MaxInt32 {
+			if needsScores {
+				collector.scoreMode = TOP_DOCS_WITH_SCORES
+			} else {
+				collector.scoreMode = TOP_DOCS
+			}
+		} else {
+			if needsScores {
+				collector.scoreMode = COMPLETE
+			} else {
+				collector.scoreMode = COMPLETE_NO_SCORES
+			}
+		}
+	}
+	return collector
+}
+
+// Returns true if the relevance score is needed to sort documents.
+func sortNeedsScores(sort index.Sort) bool {
+	for _, field := range sort.GetSort() {
+		if field.GetType() == index.SCORE {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *TopFieldCollector) ScoreMode() index.ScoreMode {
 	return t.scoreMode
 }
@@ -149,17 +207,17 @@ func (t *TopFieldCollector) updateGlobalMinCompetitiveScore(scorer index.Scorabl
 var _ TopDocsCollector = &SimpleFieldCollector{}
 
 type SimpleFieldCollector struct {
-	sort index.Sort
-
-	queue FieldValueHitQueue[*Entry]
-
 	*TopFieldCollector
-	*TopDocsCollectorDefault[*Entry]
+
+	sort index.Sort
 }
 
 func NewSimpleFieldCollector(sort index.Sort, queue FieldValueHitQueue[*Entry], numHits int,
 	hitsThresholdChecker HitsThresholdChecker, minScoreAcc *MaxScoreAccumulator) (*SimpleFieldCollector, error) {
-	panic("")
+	return &SimpleFieldCollector{
+		TopFieldCollector: newTopFieldCollector(queue, numHits, hitsThresholdChecker, sortNeedsScores(sort), minScoreAcc),
+		sort:              sort,
+	}, nil
 }
 
 func (s *SimpleFieldCollector) GetLeafCollector(ctx context.Context, readerContext index.LeafReaderContext) (index.LeafCollector, error) {
@@ -181,11 +239,14 @@ func (s *SimpleFieldCollector) GetLeafCollector(ctx context.Context, readerConte
 	if err != nil {
 		return nil, err
 	}
-	reverseMul := s.queue.GetReverseMul()
+	leafCollector, err := NewMultiComparatorLeafCollector(comparators, s.queue.GetReverseMul())
+	if err != nil {
+		return nil, err
+	}
 
 	return &simpleLeafCollector{
 		SimpleFieldCollector:         s,
-		MultiComparatorLeafCollector: NewMultiComparatorLeafCollector(comparators, reverseMul),
+		MultiComparatorLeafCollector: leafCollector,
 		collectedAllCompetitiveHits:  false,
 	}, nil
 
@@ -200,6 +261,17 @@ type simpleLeafCollector struct {
 	collectedAllCompetitiveHits bool
 }
 
+func (s *simpleLeafCollector) SetScorer(scorer index.Scorable) error {
+	if err := s.MultiComparatorLeafCollector.SetScorer(scorer); err != nil {
+		return err
+	}
+
+	if s.minScoreAcc == nil {
+		return s.updateMinCompetitiveScore(scorer)
+	}
+	return s.updateGlobalMinCompetitiveScore(scorer)
+}
+
 func (s *simpleLeafCollector) Collect(ctx context.Context, doc int) error {
 	s.totalHits++
 	s.hitsThresholdChecker.IncrementHitCount()
@@ -231,7 +303,7 @@ func (s *simpleLeafCollector) Collect(ctx context.Context, doc int) error {
 			if s.searchSortPartOfIndexSort.Value() {
 				if s.hitsThresholdChecker.IsThresholdReached() {
 					s.totalHitsRelation = index.GREATER_THAN_OR_EQUAL_TO
-					return errors.New("CollectionTerminatedException")
+					return ErrCollectionTerminated
 				} else {
 					s.collectedAllCompetitiveHits = true
 				}
@@ -288,18 +360,33 @@ var _ TopDocsCollector = &PagingFieldCollector{}
 
 type PagingFieldCollector struct {
 	*TopFieldCollector
-	*TopDocsCollectorDefault[*Entry]
 
 	sort          index.Sort
 	collectedHits int
-	queue         *FieldValueHitQueueDefault[*Entry]
 	after         FieldDoc
 }
 
 func NewPagingFieldCollector(sort index.Sort, queue FieldValueHitQueue[*Entry], after FieldDoc, numHits int,
 	hitsThresholdChecker HitsThresholdChecker, minScoreAcc *MaxScoreAccumulator) (*PagingFieldCollector, error) {
 
-	panic("")
+	collector := &PagingFieldCollector{
+		TopFieldCollector: newTopFieldCollector(queue, numHits, hitsThresholdChecker, sortNeedsScores(sort), minScoreAcc),
+		sort:              sort,
+		collectedHits:     0,
+		after:             after,
+	}
+	collector.spi = collector
+
+	comparators := queue.GetComparatorsList()
+	// Tell all comparators their top value:
+	for i, comparator := range comparators {
+		comparator.SetTopValue(after.GetFields()[i])
+	}
+	return collector, nil
+}
+
+func (p *PagingFieldCollector) TopDocsSize() int {
+	return min(p.collectedHits, p.pq.Size())
 }
 
 func (p *PagingFieldCollector) GetLeafCollector(ctx context.Context, readerContext index.LeafReaderContext) (index.LeafCollector, error) {
@@ -320,11 +407,14 @@ func (p *PagingFieldCollector) GetLeafCollector(ctx context.Context, readerConte
 	if err != nil {
 		return nil, err
 	}
-	reverseMul := p.queue.GetReverseMul()
+	leafCollector, err := NewMultiComparatorLeafCollector(comparators, p.queue.GetReverseMul())
+	if err != nil {
+		return nil, err
+	}
 
 	return &pagingLeafCollector{
 		PagingFieldCollector:         p,
-		MultiComparatorLeafCollector: NewMultiComparatorLeafCollector(comparators, reverseMul),
+		MultiComparatorLeafCollector: leafCollector,
 		collectedAllCompetitiveHits:  false,
 		afterDoc:                     afterDoc,
 	}, nil
@@ -392,7 +482,7 @@ func (p *pagingLeafCollector) Collect(ctx context.Context, doc int) error {
 			if p.searchSortPartOfIndexSort.Value() {
 				if p.hitsThresholdChecker.IsThresholdReached() {
 					p.totalHitsRelation = index.GREATER_THAN_OR_EQUAL_TO
-					return errors.New("CollectionTerminatedException")
+					return ErrCollectionTerminated
 				} else {
 					p.collectedAllCompetitiveHits = true
 				}
@@ -463,8 +553,28 @@ func (p *pagingLeafCollector) CompetitiveIterator() (types.DocIdSetIterator, err
 	return p.comparator.CompetitiveIterator()
 }
 
+func (t *TopFieldCollector) PopulateResults(results []index.ScoreDoc, howMany int) error {
+	// avoid casting if unnecessary.
+	for i := howMany - 1; i >= 0; i-- {
+		entry, err := t.pq.Pop()
+		if err != nil {
+			return err
+		}
+		results[i] = t.queue.FillFields(entry)
+	}
+	return nil
+}
+
+func (t *TopFieldCollector) NewTopDocs(results []index.ScoreDoc, howMany int) (index.TopDocs, error) {
+	if len(results) == 0 {
+		results = make([]index.ScoreDoc, 0)
+	}
+
+	return NewTopFieldDocs(index.NewTotalHits(int64(t.totalHits), t.totalHitsRelation), results, t.queue.GetFields()), nil
+}
+
 func canEarlyTerminate(searchSort, indexSort index.Sort) bool {
-	return canEarlyTerminateOnDocId(indexSort) ||
+	return canEarlyTerminateOnDocId(searchSort) ||
 		canEarlyTerminateOnPrefix(searchSort, indexSort)
 }
 
@@ -318,8 +318,7 @@ func (p *PagingTopScoreDocCollector) GetLeafCollector(ctx context.Context, reade
 }
 
 func (p *PagingTopScoreDocCollector) ScoreMode() index.ScoreMode {
-	//TODO implement me
-	panic("implement me")
+	return p.hitsThresholdChecker.ScoreMode()
 }
 
 func (p *PagingTopScoreDocCollector) TopDocsSize() int {
@@ -338,5 +337,12 @@ func (p *PagingTopScoreDocCollector) NewTopDocs(results []index.ScoreDoc, howMan
 }
 
 func NewPagingTopScoreDocCollector(hits int, after index.ScoreDoc, checker HitsThresholdChecker, acc *MaxScoreAccumulator) (TopScoreDocCollector, error) {
-	panic("")
+	collector := &PagingTopScoreDocCollector{
+		BaseTopScoreDocCollector: newTopScoreDocCollector(hits, checker, acc),
+		after:                    after,
+		collectedHits:            0,
+	}
+	// the sentinels never leave the queue, only the collected hits are returned
+	collector.spi = collector
+	return collector, nil
 }
@@ -0,0 +1,233 @@
+package search
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/attribute"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+// BoostAttribute
+// Implemented by the TermsEnum of a MultiTermQuery to give a boost to the enumerated terms, the
+// TopTermsRewrite keeps the terms with the highest boosts. The terms of an enum that doesn't
+// implement it have a boost of 1.
+type BoostAttribute interface {
+	// GetBoost
+	// Retrieve the boost of the current term.
+	GetBoost() float64
+}
+
+var _ RewriteMethod = &TopTermsRewrite{}
+
+// TopTermsRewrite
+// Base rewrite method for collecting only the top terms via a priority queue.
+type TopTermsRewrite struct {
+	size int
+	spi  TopTermsRewriteSPI
+}
+
+// TopTermsRewriteSPI
+// The parts of a TopTermsRewrite which build the top level query.
+type TopTermsRewriteSPI interface {
+	// GetMaxSize
+	// Return a suitable top-terms size, the size of the TopTermsRewrite is capped to it.
+	GetMaxSize() int
+
+	// AddClause
+	// Add a MultiTermQuery term to the top level query builder.
+	AddClause(builder *BooleanQueryBuilder, term index.Term, docCount int, boost float64, states *coreIndex.TermStates) error
+}
+
+// NewTopTermsRewrite
+// Create a TopTermsRewrite for at most size terms.
+// NOTE: if BooleanQuery.GetMaxClauseCount is smaller than size, then it will be used instead.
+func NewTopTermsRewrite(size int, spi TopTermsRewriteSPI) *TopTermsRewrite {
+	return &TopTermsRewrite{size: size, spi: spi}
+}
+
+// GetSize
+// return the maximum priority queue size
+func (t *TopTermsRewrite) GetSize() int {
+	return t.size
+}
+
+func (t *TopTermsRewrite) GetTermsEnum(query MultiTermQuery, terms index.Terms, atts *attribute.Source) (index.TermsEnum, error) {
+	return query.GetTermsEnum(terms, atts)
+}
+
+// scoreTerm A term collected by the TopTermsRewrite, with its statistics over all the leaves.
+type scoreTerm struct {
+	bytes     []byte
+	boost     float64
+	termState *coreIndex.TermStates
+}
+
+// lessCompetitive returns true if the term with boost a is less competitive than the term with
+// boost b, for equal boosts the lowest term wins.
+func lessCompetitive(a, b *scoreTerm) bool {
+	if a.boost == b.boost {
+		return bytes.Compare(a.bytes, b.bytes) > 0
+	}
+	return a.boost < b.boost
+}
+
+func (t *TopTermsRewrite) Rewrite(reader index.IndexReader, query MultiTermQuery) (index.Query, error) {
+	maxSize := min(t.size, t.spi.GetMaxSize())
+
+	stQueue := structure.NewPriorityQueue(maxSize, lessCompetitive)
+	visitedTerms := make(map[string]*scoreTerm)
+
+	topReaderContext, err := reader.GetContext()
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := topReaderContext.Leaves()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leaf := range leaves {
+		terms, err := leaf.LeafReader().Terms(query.GetField())
+		if err != nil {
+			return nil, err
+		}
+		if terms == nil {
+			// field does not exist
+			continue
+		}
+
+		termsEnum, err := t.GetTermsEnum(query, terms, attribute.NewSource())
+		if err != nil {
+			return nil, err
+		}
+		boostAtt, hasBoost := termsEnum.(BoostAttribute)
+
+		for {
+			term, err := termsEnum.Next(nil)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			if term == nil {
+				break
+			}
+
+			boost := 1.0
+			if hasBoost {
+				boost = boostAtt.GetBoost()
+			}
+
+			// ignore uncompetitive hits
+			if stQueue.Size() == maxSize {
+				if lessCompetitive(&scoreTerm{bytes: term, boost: boost}, stQueue.Top()) {
+					continue
+				}
+			}
+
+			state, err := termsEnum.TermState()
+			if err != nil {
+				return nil, err
+			}
+			docFreq, err := termsEnum.DocFreq()
+			if err != nil {
+				return nil, err
+			}
+			totalTermFreq, err := termsEnum.TotalTermFreq()
+			if err != nil {
+				return nil, err
+			}
+
+			if st, ok := visitedTerms[string(term)]; ok {
+				// the term was collected in a previous leaf, its boost doesn't depend on the leaf
+				st.termState.Register(state, leaf.Ord(), docFreq, totalTermFreq)
+				continue
+			}
+
+			st := &scoreTerm{
+				bytes:     bytes.Clone(term),
+				boost:     boost,
+				termState: coreIndex.NewTermStates(nil, topReaderContext),
+			}
+			st.termState.Register(state, leaf.Ord(), docFreq, totalTermFreq)
+
+			// possibly drop entries from queue
+			if stQueue.Size() == maxSize {
+				dropped, err := stQueue.Pop()
+				if err != nil {
+					return nil, err
+				}
+				delete(visitedTerms, string(dropped.bytes))
+			}
+			stQueue.Add(st)
+			visitedTerms[string(st.bytes)] = st
+		}
+	}
+
+	scoreTerms := make([]*scoreTerm, 0, stQueue.Size())
+	for stQueue.Size() > 0 {
+		st, err := stQueue.Pop()
+		if err != nil {
+			return nil, err
+		}
+		scoreTerms = append(scoreTerms, st)
+	}
+	slices.SortFunc(scoreTerms, func(a, b *scoreTerm) int {
+		return bytes.Compare(a.bytes, b.bytes)
+	})
+
+	builder := NewBooleanQueryBuilder()
+	for _, st := range scoreTerms {
+		term := coreIndex.NewTerm(query.GetField(), st.bytes)
+		docFreq, err := st.termState.DocFreq()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.spi.AddClause(builder, term, docFreq, st.boost, st.termState); err != nil {
+			return nil, err
+		}
+	}
+	return builder.Build()
+}
+
+var _ TopTermsRewriteSPI = &TopTermsScoringBooleanQueryRewrite{}
+
+// TopTermsScoringBooleanQueryRewrite
+// A rewrite method that first translates each term into BooleanClause.Occur.SHOULD clause in a
+// BooleanQuery, and keeps the scores as computed by the query.
+//
+// This rewrite method only uses the top scoring terms so it will not overflow the boolean max
+// clause count. It is the default rewrite method for FuzzyQuery.
+type TopTermsScoringBooleanQueryRewrite struct {
+	*TopTermsRewrite
+}
+
+// NewTopTermsScoringBooleanQueryRewrite
+// Create a TopTermsScoringBooleanQueryRewrite for at most size terms.
+// NOTE: if BooleanQuery.GetMaxClauseCount is smaller than size, then it will be used instead.
+func NewTopTermsScoringBooleanQueryRewrite(size int) *TopTermsScoringBooleanQueryRewrite {
+	rewrite := &TopTermsScoringBooleanQueryRewrite{}
+	rewrite.TopTermsRewrite = NewTopTermsRewrite(size, rewrite)
+	return rewrite
+}
+
+func (t *TopTermsScoringBooleanQueryRewrite) GetMaxSize() int {
+	return GetMaxClauseCount()
+}
+
+func (t *TopTermsScoringBooleanQueryRewrite) AddClause(builder *BooleanQueryBuilder, term index.Term,
+	docCount int, boost float64, states *coreIndex.TermStates) error {
+
+	query, err := NewBoostQuery(NewTermQueryV1(term, states), boost)
+	if err != nil {
+		return err
+	}
+	builder.AddQuery(query, index.OccurShould)
+	return nil
+}
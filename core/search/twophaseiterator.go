@@ -53,7 +53,7 @@ func (t *twoPhaseIteratorAsDocIdSetIterator) Cost() int64 {
 func (t *twoPhaseIteratorAsDocIdSetIterator) doNext(doc int) (int, error) {
 	for {
 		if doc == types.NO_MORE_DOCS {
-			return 0, io.EOF
+			return types.NO_MORE_DOCS, io.EOF
 		}
 
 		isMatch, err := t.twoPhaseIterator.Matches()
@@ -64,7 +64,10 @@ func (t *twoPhaseIteratorAsDocIdSetIterator) doNext(doc int) (int, error) {
 			return doc, nil
 		}
 
-		doc = t.approximation.DocID()
+		doc, err = t.approximation.NextDoc(nil)
+		if err != nil {
+			return types.NO_MORE_DOCS, err
+		}
 	}
 }
 
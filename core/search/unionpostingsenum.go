@@ -0,0 +1,252 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/types"
+	"github.com/geange/lucene-go/core/util/structure"
+)
+
+var _ index.PostingsEnum = &unionPostingsEnum{}
+
+// unionPostingsEnum
+// Takes the logical union of multiple PostingsEnum iterators.
+// Note: positions are merged during freq()
+type unionPostingsEnum struct {
+	// queue ordered by docid
+	docsQueue *structure.PriorityQueue[index.PostingsEnum]
+
+	// cost of this enum: sum of its subs
+	cost int64
+
+	// sorted positions of the current doc, from all the subs
+	posQueue []int
+	// index of the next position in posQueue
+	posIndex int
+	// current doc posQueue is working
+	posQueueDoc int
+	// list of subs (unordered)
+	subs []index.PostingsEnum
+}
+
+func newUnionPostingsEnum(subs []index.PostingsEnum) *unionPostingsEnum {
+	docsQueue := structure.NewPriorityQueue(len(subs), func(a, b index.PostingsEnum) bool {
+		return a.DocID() < b.DocID()
+	})
+
+	cost := int64(0)
+	for _, sub := range subs {
+		docsQueue.Add(sub)
+		cost += sub.Cost()
+	}
+
+	return &unionPostingsEnum{
+		docsQueue:   docsQueue,
+		cost:        cost,
+		posQueue:    make([]int, 0, 16),
+		posQueueDoc: -2,
+		subs:        subs,
+	}
+}
+
+func (u *unionPostingsEnum) Freq() (int, error) {
+	doc := u.DocID()
+	if doc != u.posQueueDoc {
+		u.posQueue = u.posQueue[:0]
+		u.posIndex = 0
+		for _, sub := range u.subs {
+			if sub.DocID() != doc {
+				continue
+			}
+			freq, err := sub.Freq()
+			if err != nil {
+				return 0, err
+			}
+			for i := 0; i < freq; i++ {
+				pos, err := sub.NextPosition()
+				if err != nil {
+					return 0, err
+				}
+				u.posQueue = append(u.posQueue, pos)
+			}
+		}
+		slices.Sort(u.posQueue)
+		u.posQueueDoc = doc
+	}
+	return len(u.posQueue), nil
+}
+
+func (u *unionPostingsEnum) NextPosition() (int, error) {
+	pos := u.posQueue[u.posIndex]
+	u.posIndex++
+	return pos, nil
+}
+
+func (u *unionPostingsEnum) DocID() int {
+	return u.docsQueue.Top().DocID()
+}
+
+func (u *unionPostingsEnum) NextDoc(ctx context.Context) (int, error) {
+	top := u.docsQueue.Top()
+	doc := top.DocID()
+
+	for {
+		if _, err := top.NextDoc(ctx); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		top = u.docsQueue.UpdateTop()
+		if top.DocID() != doc {
+			break
+		}
+	}
+	return u.currentDoc()
+}
+
+func (u *unionPostingsEnum) Advance(ctx context.Context, target int) (int, error) {
+	top := u.docsQueue.Top()
+
+	for {
+		if _, err := top.Advance(ctx, target); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		top = u.docsQueue.UpdateTop()
+		if top.DocID() >= target {
+			break
+		}
+	}
+	return u.currentDoc()
+}
+
+func (u *unionPostingsEnum) currentDoc() (int, error) {
+	doc := u.DocID()
+	if doc == types.NO_MORE_DOCS {
+		return types.NO_MORE_DOCS, io.EOF
+	}
+	return doc, nil
+}
+
+func (u *unionPostingsEnum) SlowAdvance(ctx context.Context, target int) (int, error) {
+	return types.SlowAdvanceWithContext(ctx, u, target)
+}
+
+func (u *unionPostingsEnum) Cost() int64 {
+	return u.cost
+}
+
+func (u *unionPostingsEnum) StartOffset() (int, error) {
+	return -1, nil // offsets are unsupported
+}
+
+func (u *unionPostingsEnum) EndOffset() (int, error) {
+	return -1, nil // offsets are unsupported
+}
+
+func (u *unionPostingsEnum) GetPayload() ([]byte, error) {
+	return nil, nil // payloads are unsupported
+}
+
+var _ index.PostingsEnum = &unionFullPostingsEnum{}
+
+// unionFullPostingsEnum
+// Slower version of unionPostingsEnum that delegates offsets and positions, for use by
+// MatchesIterator
+type unionFullPostingsEnum struct {
+	*unionPostingsEnum
+
+	freq    int
+	started bool
+
+	posQueue *structure.PriorityQueue[*unionPostingsAndPosition]
+	subs     []*unionPostingsAndPosition
+}
+
+type unionPostingsAndPosition struct {
+	pe   index.PostingsEnum
+	pos  int
+	upto int
+}
+
+func newUnionFullPostingsEnum(subs []index.PostingsEnum) *unionFullPostingsEnum {
+	postings := make([]*unionPostingsAndPosition, 0, len(subs))
+	for _, pe := range subs {
+		postings = append(postings, &unionPostingsAndPosition{pe: pe})
+	}
+
+	return &unionFullPostingsEnum{
+		unionPostingsEnum: newUnionPostingsEnum(subs),
+		freq:              -1,
+		posQueue: structure.NewPriorityQueue(len(subs), func(a, b *unionPostingsAndPosition) bool {
+			return a.pos < b.pos
+		}),
+		subs: postings,
+	}
+}
+
+func (u *unionFullPostingsEnum) Freq() (int, error) {
+	doc := u.DocID()
+	if doc == u.posQueueDoc {
+		return u.freq, nil
+	}
+
+	u.freq = 0
+	u.started = false
+	u.posQueue.Clear()
+	for _, pp := range u.subs {
+		if pp.pe.DocID() != doc {
+			continue
+		}
+		pos, err := pp.pe.NextPosition()
+		if err != nil {
+			return 0, err
+		}
+		freq, err := pp.pe.Freq()
+		if err != nil {
+			return 0, err
+		}
+		pp.pos = pos
+		pp.upto = freq
+		u.posQueue.Add(pp)
+		u.freq += freq
+	}
+	u.posQueueDoc = doc
+	return u.freq, nil
+}
+
+func (u *unionFullPostingsEnum) NextPosition() (int, error) {
+	if !u.started {
+		u.started = true
+		return u.posQueue.Top().pos, nil
+	}
+
+	top := u.posQueue.Top()
+	if top.upto == 1 {
+		if _, err := u.posQueue.Pop(); err != nil {
+			return 0, err
+		}
+		return u.posQueue.Top().pos, nil
+	}
+
+	pos, err := top.pe.NextPosition()
+	if err != nil {
+		return 0, err
+	}
+	top.pos = pos
+	top.upto--
+	return u.posQueue.UpdateTop().pos, nil
+}
+
+func (u *unionFullPostingsEnum) StartOffset() (int, error) {
+	return u.posQueue.Top().pe.StartOffset()
+}
+
+func (u *unionFullPostingsEnum) EndOffset() (int, error) {
+	return u.posQueue.Top().pe.EndOffset()
+}
+
+func (u *unionFullPostingsEnum) GetPayload() ([]byte, error) {
+	return u.posQueue.Top().pe.GetPayload()
+}
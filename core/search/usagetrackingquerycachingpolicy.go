@@ -0,0 +1,128 @@
+package search
+
+import (
+	"math"
+	"sync"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util"
+)
+
+const (
+	// DEFAULT_HISTORY_SIZE the number of recently used queries the default policy tracks.
+	DEFAULT_HISTORY_SIZE = 256
+
+	// hash code used to fill the history before any query is tracked
+	historySentinel = math.MinInt32
+)
+
+var _ index.QueryCachingPolicy = &UsageTrackingQueryCachingPolicy{}
+
+// UsageTrackingQueryCachingPolicy
+// A QueryCachingPolicy that tracks usage statistics of recently-used filters in order to decide on
+// which filters are worth caching.
+type UsageTrackingQueryCachingPolicy struct {
+	sync.Mutex
+
+	// we only track hash codes to avoid holding references to possible
+	// large queries; this may cause rare false positives, but at worse
+	// this just means we cache a query that was not in fact used enough
+	recentlyUsedFilters *util.FrequencyTrackingRingBuffer
+}
+
+// NewUsageTrackingQueryCachingPolicy
+// Create a new instance that tracks the historySize most recently used filters.
+func NewUsageTrackingQueryCachingPolicy(historySize int) *UsageTrackingQueryCachingPolicy {
+	return &UsageTrackingQueryCachingPolicy{
+		recentlyUsedFilters: util.NewFrequencyTrackingRingBuffer(historySize, historySentinel),
+	}
+}
+
+// isCostly
+// This does not measure the cost of iterating over the filter (for this we already have the
+// DocIdSetIterator.Cost API) but the cost to build the DocIdSet in the first place.
+func isCostly(query index.Query) bool {
+	switch query.(type) {
+	case MultiTermQuery, *MultiTermQueryConstantScoreWrapper, *TermInSetQuery,
+		*PointRangeQuery, *PointInSetQuery:
+		return true
+	default:
+		return false
+	}
+}
+
+func shouldNeverCache(query index.Query) bool {
+	switch query := query.(type) {
+	case *TermQuery:
+		// We do not bother caching term queries since they are already plenty fast.
+		return true
+	case *DocValuesFieldExistsQuery:
+		// We do not bother caching DocValuesFieldExistsQuery queries since they are already plenty fast.
+		return true
+	case *MatchAllDocsQuery:
+		// MatchAllDocsQuery has an iterator that is faster than what a bit set could do.
+		return true
+	case *MatchNoDocsQuery:
+		// For the below queries, it's cheap to notice they cannot match any docs so
+		// we do not bother caching them.
+		return true
+	case *BooleanQuery:
+		return len(query.Clauses()) == 0
+	case *DisjunctionMaxQuery:
+		return len(query.GetDisjuncts()) == 0
+	default:
+		return false
+	}
+}
+
+// MinFrequencyToCache
+// For a given filter, return how many times it should appear in the history before being cached.
+// The default implementation returns 2 for filters that need to evaluate against the entire index
+// to build a DocIdSetIterator, like MultiTermQuery, point-based queries or TermInSetQuery, and 5
+// for other filters.
+func (u *UsageTrackingQueryCachingPolicy) MinFrequencyToCache(query index.Query) int {
+	if isCostly(query) {
+		return 2
+	}
+
+	// default: cache after the filter has been seen 5 times
+	minFrequency := 5
+	switch query.(type) {
+	case *BooleanQuery, *DisjunctionMaxQuery:
+		// Say you keep reusing a boolean query that looks like "A OR B" and
+		// never use the A and B queries out of that context. 5 times after it
+		// has been used, we would cache both A, B and A OR B, which is
+		// wasteful. So instead we cache compound queries a bit earlier so that
+		// we would only cache "A OR B" in that case.
+		minFrequency--
+	}
+	return minFrequency
+}
+
+func (u *UsageTrackingQueryCachingPolicy) OnUse(query index.Query) {
+	if shouldNeverCache(query) {
+		return
+	}
+
+	// call hashCode outside of the lock in case it's somewhat expensive
+	hashCode := int32(query.HashCode())
+
+	u.Lock()
+	defer u.Unlock()
+	u.recentlyUsedFilters.Add(hashCode)
+}
+
+func (u *UsageTrackingQueryCachingPolicy) frequency(query index.Query) int {
+	hashCode := int32(query.HashCode())
+
+	u.Lock()
+	defer u.Unlock()
+	return int(u.recentlyUsedFilters.Frequency(hashCode))
+}
+
+func (u *UsageTrackingQueryCachingPolicy) ShouldCache(query index.Query) (bool, error) {
+	if shouldNeverCache(query) {
+		return false, nil
+	}
+	return u.frequency(query) >= u.MinFrequencyToCache(query), nil
+}
@@ -0,0 +1,100 @@
+package search_test
+
+import (
+	"testing"
+
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/stretchr/testify/assert"
+)
+
+// shouldCacheAfter Returns after how many uses the policy caches query, 0 if it was not cached
+// after maxUses uses
+func shouldCacheAfter(t *testing.T, policy *search.UsageTrackingQueryCachingPolicy, query index.Query, maxUses int) int {
+	for i := 1; i <= maxUses; i++ {
+		policy.OnUse(query)
+		shouldCache, err := policy.ShouldCache(query)
+		assert.Nil(t, err)
+		if shouldCache {
+			return i
+		}
+	}
+	return 0
+}
+
+func TestUsageTrackingQueryCachingPolicy_MinFrequencyToCache(t *testing.T) {
+	term := func(text string) index.Query {
+		return search.NewTermQuery(coreIndex.NewTerm("body", []byte(text)))
+	}
+	wildcard, err := search.NewWildcardQuery(coreIndex.NewTerm("body", []byte("fo*")))
+	assert.Nil(t, err)
+	boolean, err := search.NewBooleanQueryBuilder().
+		AddQuery(term("a"), index.OccurShould).
+		AddQuery(term("b"), index.OccurShould).
+		Build()
+	assert.Nil(t, err)
+	phrase := search.NewPhraseQuery("body", "quick", "fox")
+
+	policy := search.NewUsageTrackingQueryCachingPolicy(search.DEFAULT_HISTORY_SIZE)
+	// queries which are costly to build are cached sooner, compound ones a bit sooner
+	assert.Equal(t, 2, policy.MinFrequencyToCache(wildcard))
+	assert.Equal(t, 4, policy.MinFrequencyToCache(boolean))
+	assert.Equal(t, 5, policy.MinFrequencyToCache(phrase))
+
+	assert.Equal(t, 2, shouldCacheAfter(t, policy, wildcard, 10))
+	assert.Equal(t, 4, shouldCacheAfter(t, policy, boolean, 10))
+	assert.Equal(t, 5, shouldCacheAfter(t, policy, phrase, 10))
+
+	// equal queries share their usage
+	assert.Equal(t, 1, shouldCacheAfter(t, policy, search.NewPhraseQuery("body", "quick", "fox"), 10))
+}
+
+func TestUsageTrackingQueryCachingPolicy_NeverCache(t *testing.T) {
+	policy := search.NewUsageTrackingQueryCachingPolicy(search.DEFAULT_HISTORY_SIZE)
+
+	empty, err := search.NewBooleanQueryBuilder().Build()
+	assert.Nil(t, err)
+	for _, query := range []index.Query{
+		search.NewTermQuery(coreIndex.NewTerm("body", []byte("fox"))),
+		search.NewMatchAllDocsQuery(),
+		search.NewMatchNoDocsQuery("no reason"),
+		empty,
+	} {
+		assert.Equal(t, 0, shouldCacheAfter(t, policy, query, 100), "%T", query)
+	}
+}
+
+func TestUsageTrackingQueryCachingPolicy_History(t *testing.T) {
+	policy := search.NewUsageTrackingQueryCachingPolicy(4)
+
+	phrase := search.NewPhraseQuery("body", "quick", "fox")
+	for i := 0; i < 4; i++ {
+		policy.OnUse(phrase)
+	}
+	shouldCache, err := policy.ShouldCache(phrase)
+	assert.Nil(t, err)
+	assert.False(t, shouldCache)
+
+	// the history only holds the 4 most recent uses
+	policy.OnUse(phrase)
+	shouldCache, err = policy.ShouldCache(phrase)
+	assert.Nil(t, err)
+	assert.False(t, shouldCache)
+
+	wildcard, err := search.NewWildcardQuery(coreIndex.NewTerm("body", []byte("fo*")))
+	assert.Nil(t, err)
+	policy.OnUse(wildcard)
+	policy.OnUse(wildcard)
+	shouldCache, err = policy.ShouldCache(wildcard)
+	assert.Nil(t, err)
+	assert.True(t, shouldCache)
+
+	// older uses are forgotten
+	policy.OnUse(search.NewPhraseQuery("body", "lazy", "dog"))
+	policy.OnUse(search.NewPhraseQuery("body", "lazy", "cat"))
+	policy.OnUse(search.NewPhraseQuery("body", "lazy", "cow"))
+	shouldCache, err = policy.ShouldCache(wildcard)
+	assert.Nil(t, err)
+	assert.False(t, shouldCache)
+}
@@ -50,26 +50,3 @@ func (w *WANDScorer) GetMaxScore(upTo int) (float64, error) {
 	//TODO implement me
 	panic("implement me")
 }
-
-// DisiWrapper
-// Wrapper used in DisiPriorityQueue.
-// lucene.internal
-type DisiWrapper struct {
-	iterator  types.DocIdSetIterator
-	scorer    index.Scorer
-	cost      int64
-	matchCost float64      // the match cost for two-phase iterators, 0 otherwise
-	doc       int          // the current doc, used for comparison
-	next      *DisiWrapper // reference to a next element, see #topList
-
-	// An approximation of the iterator, or the iterator itself if it does not
-	// support two-phase iteration
-	approximation types.DocIdSetIterator
-
-	// A two-phase view of the iterator, or null if the iterator does not support
-	// two-phase iteration
-	twoPhaseView index.TwoPhaseIterator
-
-	// For WANDScorer
-	maxScore int64
-}
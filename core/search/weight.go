@@ -163,10 +163,13 @@ func (d *DefaultBulkScorer) Score(collector index.LeafCollector, acceptDocs util
 			competitiveIterator = NewStartDISIWrapper(competitiveIterator)
 		}
 
-		filteredIterator = IntersectIterators([]types.DocIdSetIterator{
+		filteredIterator, err = IntersectIterators([]types.DocIdSetIterator{
 			scorerIterator,
 			competitiveIterator,
 		})
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	if filteredIterator.DocID() == -1 && minDoc == 0 && maxDoc == types.NO_MORE_DOCS {
@@ -177,7 +180,7 @@ func (d *DefaultBulkScorer) Score(collector index.LeafCollector, acceptDocs util
 	} else {
 		doc := filteredIterator.DocID()
 		if doc < minDoc {
-			doc, err = filteredIterator.Advance(nil, minDoc)
+			doc, err = filteredIterator.Advance(context.Background(), minDoc)
 			if err != nil {
 				return 0, err
 			}
@@ -287,6 +290,7 @@ func NewStartDISIWrapper(in types.DocIdSetIterator) *StartDISIWrapper {
 	return &StartDISIWrapper{
 		in:         in,
 		startDocID: in.DocID(),
+		docID:      -1,
 	}
 }
 
@@ -294,8 +298,8 @@ func (s *StartDISIWrapper) DocID() int {
 	return s.docID
 }
 
-func (s *StartDISIWrapper) NextDoc(context.Context) (int, error) {
-	return s.Advance(nil, s.docID+1)
+func (s *StartDISIWrapper) NextDoc(ctx context.Context) (int, error) {
+	return s.Advance(ctx, s.docID+1)
 }
 
 func (s *StartDISIWrapper) Advance(ctx context.Context, target int) (int, error) {
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/geange/lucene-go/core/interface/index"
+	"github.com/geange/lucene-go/core/util/automaton"
+)
+
+const (
+	// WILDCARD_STRING String equality with support for wildcards
+	WILDCARD_STRING = '*'
+
+	// WILDCARD_CHAR Char equality with support for wildcards
+	WILDCARD_CHAR = '?'
+
+	// WILDCARD_ESCAPE Escape character
+	WILDCARD_ESCAPE = '\\'
+)
+
+var _ MultiTermQuery = &WildcardQuery{}
+
+// WildcardQuery
+// Implements the wildcard search query. Supported wildcards are *, which matches any character
+// sequence (including the empty one), and ?, which matches any single character. '\' is the escape
+// character.
+//
+// Note this query can be slow, as it needs to iterate over many terms. In order to prevent
+// extremely slow WildcardQueries, a Wildcard term should not start with the wildcard *
+//
+// This query uses the CONSTANT_SCORE_REWRITE rewrite method.
+type WildcardQuery struct {
+	*AutomatonQuery
+}
+
+// NewWildcardQuery
+// Constructs a query for terms matching term.
+func NewWildcardQuery(term index.Term) (*WildcardQuery, error) {
+	return NewWildcardQueryV1(term, automaton.DEFAULT_DETERMINIZE_WORK_LIMIT)
+}
+
+// NewWildcardQueryV1
+// Constructs a query for terms matching term.
+// determinizeWorkLimit: maximum effort to spend while compiling the automaton from this wildcard.
+// Set higher to allow more complex queries and lower to prevent memory exhaustion. Use
+// automaton.DEFAULT_DETERMINIZE_WORK_LIMIT as a decent default if you don't otherwise know what
+// to specify.
+func NewWildcardQueryV1(term index.Term, determinizeWorkLimit int) (*WildcardQuery, error) {
+	query, err := NewAutomatonQuery(term, WildcardToAutomaton(term), determinizeWorkLimit, false)
+	if err != nil {
+		return nil, err
+	}
+	return &WildcardQuery{AutomatonQuery: query}, nil
+}
+
+// WildcardToAutomaton
+// Convert Lucene wildcard syntax into an automaton.
+func WildcardToAutomaton(wildcardQuery index.Term) *automaton.Automaton {
+	automata := make([]*automaton.Automaton, 0)
+
+	wildcardText := wildcardQuery.Text()
+	for i := 0; i < len(wildcardText); {
+		c, length := utf8.DecodeRuneInString(wildcardText[i:])
+		switch c {
+		case WILDCARD_STRING:
+			automata = append(automata, automaton.MakeAnyString())
+		case WILDCARD_CHAR:
+			automata = append(automata, automaton.MakeAnyChar())
+		case WILDCARD_ESCAPE:
+			// add the next codepoint instead, if it exists
+			if i+length < len(wildcardText) {
+				nextChar, nextLength := utf8.DecodeRuneInString(wildcardText[i+length:])
+				length += nextLength
+				automata = append(automata, automaton.MakeChar(int(nextChar)))
+				break
+			}
+			// else fallthru, lenient parsing with a trailing \
+			automata = append(automata, automaton.MakeChar(int(c)))
+		default:
+			automata = append(automata, automaton.MakeChar(int(c)))
+		}
+		i += length
+	}
+
+	return automaton.Concatenate(automata...)
+}
+
+// GetTerm
+// Returns the pattern term.
+func (w *WildcardQuery) GetTerm() index.Term {
+	return w.term
+}
+
+// String
+// Prints a user-readable version of this query.
+func (w *WildcardQuery) String(field string) string {
+	buf := new(bytes.Buffer)
+	if w.GetField() != field {
+		buf.WriteString(w.GetField())
+		buf.WriteString(":")
+	}
+	buf.WriteString(w.term.Text())
+	return buf.String()
+}
+
+func (w *WildcardQuery) CreateWeight(searcher index.IndexSearcher, scoreMode index.ScoreMode, boost float64) (index.Weight, error) {
+	return nil, fmt.Errorf("query %s does not implement createWeight, it must be rewritten first", w.String(""))
+}
+
+func (w *WildcardQuery) Rewrite(reader index.IndexReader) (index.Query, error) {
+	return w.rewriteMethod.Rewrite(reader, w)
+}
+
+func (w *WildcardQuery) Visit(visitor index.QueryVisitor) error {
+	if visitor.AcceptField(w.field) {
+		if err := visit(w.compiled, visitor, w, w.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *WildcardQuery) Equals(other index.Query) bool {
+	o, ok := other.(*WildcardQuery)
+	return ok && w.equalsTo(o.AutomatonQuery)
+}
+
+func (w *WildcardQuery) HashCode() int {
+	return 31*ClassHash("WildcardQuery") + w.fieldsHashCode()
+}
@@ -0,0 +1,132 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/geange/lucene-go/core/document"
+	coreIndex "github.com/geange/lucene-go/core/index"
+	"github.com/geange/lucene-go/core/search"
+	"github.com/geange/lucene-go/core/search/searchtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWildcardQuery(t *testing.T, field, text string) *search.WildcardQuery {
+	query, err := search.NewWildcardQuery(coreIndex.NewTerm(field, []byte(text)))
+	assert.Nil(t, err)
+	return query
+}
+
+func newWildcardTestSearcher(t *testing.T) *search.IndexSearcher {
+	return searchtest.NewSearcher(t,
+		searchtest.TextDocs("body", "lucene", "lucid", "lucene lucid", "solr"),
+		searchtest.TextDocs("body", "lu", "luke", "elastic", "lucene"))
+}
+
+func TestWildcardQuery(t *testing.T) {
+	searcher := newWildcardTestSearcher(t)
+
+	query := newWildcardQuery(t, "body", "luc*")
+	assert.Equal(t, "body:luc*", query.String(""))
+	assert.Equal(t, "luc*", query.String("body"))
+	assert.ElementsMatch(t, []int{0, 1, 2, 7}, searchDocs(t, searcher, query))
+
+	for wildcard, expected := range map[string][]int{
+		"*":        {0, 1, 2, 3, 4, 5, 6, 7},
+		"l*":       {0, 1, 2, 4, 5, 7},
+		"*e":       {0, 2, 5, 7},
+		"l*e":      {0, 2, 5, 7},
+		"*c*":      {0, 1, 2, 6, 7},
+		"lu*":      {0, 1, 2, 4, 5, 7},
+		"lu?e":     {5},
+		"lu??":     {5},
+		"luc?d":    {1, 2},
+		"??":       {4},
+		"?":        {},
+		"?u*":      {0, 1, 2, 4, 5, 7},
+		"*?*?*?*?": {0, 1, 2, 3, 5, 6, 7},
+		"lucene":   {0, 2, 7},
+		"lucen":    {},
+		"x*":       {},
+	} {
+		assert.ElementsMatch(t, expected, searchDocs(t, searcher, newWildcardQuery(t, "body", wildcard)), wildcard)
+	}
+
+	assert.Empty(t, searchDocs(t, searcher, newWildcardQuery(t, "missing", "*")))
+}
+
+func TestWildcardQuery_Escape(t *testing.T) {
+	docs := make([]*document.Document, 0)
+	for _, value := range []string{"a*b", "a?b", "axb", "ab", `a\b`, "a*", `a\`} {
+		doc := document.NewDocument()
+		doc.Add(document.NewStringField("id", value, false))
+		docs = append(docs, doc)
+	}
+	searcher := searchtest.NewSearcher(t, docs)
+
+	for wildcard, expected := range map[string][]int{
+		"a*b":     {0, 1, 2, 3, 4},
+		"a?b":     {0, 1, 2, 4},
+		`a\*b`:    {0},
+		`a\?b`:    {1},
+		`a\xb`:    {2},
+		`a\\b`:    {4},
+		`a\*`:     {5},
+		`a\*\*`:   {},
+		`a\*?`:    {0},
+		`a\\`:     {6},
+		`*\\*`:    {4, 6},
+		`*\**`:    {0, 5},
+		`a?\*`:    {},
+		`\a\*\b`:  {0},
+		`a\`:      {6},
+		`a*\`:     {6},
+		`a\b\\\*`: {},
+	} {
+		assert.ElementsMatch(t, expected, searchDocs(t, searcher, newWildcardQuery(t, "id", wildcard)), wildcard)
+	}
+}
+
+func TestWildcardQuery_RewriteMethod(t *testing.T) {
+	searcher := newWildcardTestSearcher(t)
+
+	scoresLucene := searchScores(t, searcher, newTermQuery("body", "lucene"))
+	scoresLucid := searchScores(t, searcher, newTermQuery("body", "lucid"))
+
+	// the default rewrite gives every match the same score
+	query := newWildcardQuery(t, "body", "luc*")
+	assert.Equal(t, search.CONSTANT_SCORE_REWRITE, query.GetRewriteMethod())
+	rewritten, err := searcher.Rewrite(query)
+	assert.Nil(t, err)
+	assert.IsType(t, &search.MultiTermQueryConstantScoreWrapper{}, rewritten)
+	assert.Equal(t, map[int]float64{0: 1, 1: 1, 2: 1, 7: 1}, searchScores(t, searcher, query))
+
+	query.SetRewriteMethod(search.CONSTANT_SCORE_BOOLEAN_REWRITE)
+	rewritten, err = searcher.Rewrite(query)
+	assert.Nil(t, err)
+	assert.IsType(t, &search.ConstantScoreQuery{}, rewritten)
+	assert.Equal(t, map[int]float64{0: 1, 1: 1, 2: 1, 7: 1}, searchScores(t, searcher, query))
+
+	// a scoring rewrite sums the scores of the matching terms
+	query.SetRewriteMethod(search.SCORING_BOOLEAN_REWRITE)
+	rewritten, err = searcher.Rewrite(query)
+	assert.Nil(t, err)
+	assert.Equal(t, "(body:lucene)^1.000000 (body:lucid)^1.000000", rewritten.String(""))
+	scores := searchScores(t, searcher, query)
+	assert.Len(t, scores, 4)
+	for doc, score := range scores {
+		assert.InDelta(t, scoresLucene[doc]+scoresLucid[doc], score, 1e-9, "doc %d", doc)
+	}
+
+	// only the top terms are kept, for equal boosts the lowest terms
+	query = newWildcardQuery(t, "body", "lu*")
+	query.SetRewriteMethod(search.NewTopTermsScoringBooleanQueryRewrite(2))
+	rewritten, err = searcher.Rewrite(query)
+	assert.Nil(t, err)
+	assert.Equal(t, "(body:lu)^1.000000 (body:lucene)^1.000000", rewritten.String(""))
+	assert.ElementsMatch(t, []int{0, 2, 4, 7}, searchDocs(t, searcher, query))
+
+	query.SetRewriteMethod(search.NewTopTermsScoringBooleanQueryRewrite(10))
+	assert.ElementsMatch(t, []int{0, 1, 2, 4, 5, 7}, searchDocs(t, searcher, query))
+	scores = searchScores(t, searcher, query)
+	assert.InDelta(t, scoresLucene[2]+scoresLucid[2], scores[2], 1e-9)
+}
@@ -1,5 +1,7 @@
 package store
 
+import "context"
+
 var (
 	DEFAULT  = NewIOContext(WithContextType(CONTEXT_DEFAULT))
 	READONCE = NewIOContext(WithReadOnce(true))
@@ -77,3 +79,25 @@ func newIOContext() *IOContext {
 		ReadOnce:  false,
 	}
 }
+
+type ioContextKey struct{}
+
+// WithIOContext Returns a copy of ctx carrying ioContext. Directory.CreateOutput has no IOContext
+// parameter, directories that place or throttle files by context read it back with GetIOContext.
+func WithIOContext(ctx context.Context, ioContext *IOContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ioContextKey{}, ioContext)
+}
+
+// GetIOContext Returns the IOContext carried by ctx, DEFAULT when there is none.
+func GetIOContext(ctx context.Context) *IOContext {
+	if ctx == nil {
+		return DEFAULT
+	}
+	if ioContext, ok := ctx.Value(ioContextKey{}).(*IOContext); ok && ioContext != nil {
+		return ioContext
+	}
+	return DEFAULT
+}
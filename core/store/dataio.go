@@ -152,9 +152,12 @@ func (d *BaseDataInput) ReadUvarint(context.Context) (uint64, error) {
 	return num, err
 }
 
-func (d *BaseDataInput) ReadZInt32(context.Context) (int64, error) {
-	//TODO implement me
-	panic("implement me")
+func (d *BaseDataInput) ReadZInt32(ctx context.Context) (int64, error) {
+	num, err := d.ReadUvarint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(int32(zigzag.Decode(num))), nil
 }
 
 func (d *BaseDataInput) ReadUint64(context.Context) (uint64, error) {
@@ -382,8 +385,8 @@ func (d *BaseDataOutput) WriteUvarint(ctx context.Context, i uint64) error {
 }
 
 func (d *BaseDataOutput) WriteZInt32(ctx context.Context, i int32) error {
-	//TODO implement me
-	panic("implement me")
+	num := zigzag.Encode(int64(i))
+	return d.WriteUvarint(ctx, num)
 }
 
 func (d *BaseDataOutput) WriteUint64(ctx context.Context, i uint64) error {
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -54,16 +55,10 @@ type Directory interface {
 	// file extension .tmp.
 	CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error)
 
-	// Sync Ensures that any writes to these files are moved to stable storage (made durable).
-	// Lucene uses this to properly commit changes to the index, to prevent a machine/OS crash
-	// from corrupting the index.
-	// See Also: syncMetaData()
-	//Sync(ctx context.Context, names []string) error
-
 	// SyncMetaData Ensures that directory metadata, such as recent file renames,
 	// are moved to stable storage.
-	// See Also: sync(Collection)
-	//SyncMetaData(ctx context.Context) error
+	// See Also: Sync
+	SyncMetaData(ctx context.Context) error
 
 	// Rename
 	// Renames source file to dest file where dest must not already exist in the directory.
@@ -102,10 +97,14 @@ type Directory interface {
 	// Throws: AlreadyClosedException – if this directory is closed.
 	EnsureOpen() error
 
+	// Sync Ensures that any writes to these files are moved to stable storage (made durable).
+	// Lucene uses this to properly commit changes to the index, to prevent a machine/OS crash
+	// from corrupting the index.
+	// See Also: SyncMetaData
 	Sync(files map[string]struct{}) error
 
 	// GetPendingDeletions Returns a set of files currently pending deletion in this directory.
-	//GetPendingDeletions() (map[string]struct{}, error)
+	GetPendingDeletions() (map[string]struct{}, error)
 }
 
 func OpenChecksumInput(ctx context.Context, dir Directory, name string) (ChecksumIndexInput, error) {
@@ -120,32 +119,24 @@ type BaseDirectory struct {
 	DeleteFile func(ctx context.Context, name string) error
 }
 
-type DirectoryDeleter interface {
-	DeleteFile(ctx context.Context, name string) error
-}
-
-func CopyFrom(ctx context.Context, d DirectoryDeleter, from Directory, src, dest string, ioContext *IOContext) error {
-	is, err := from.OpenInput(ctx, src)
+// CopyFrom Copies src of from to dest of to, the output is created through to.CreateOutput with
+// ioContext, so that wrappers see the copy as any other write. A partial dest is removed on failure.
+func CopyFrom(ctx context.Context, to Directory, from Directory, src, dest string, ioContext *IOContext) error {
+	input, err := from.OpenInput(ctx, src)
 	if err != nil {
 		return err
 	}
+	defer input.Close()
 
-	os, err := from.CreateOutput(ctx, dest)
+	output, err := to.CreateOutput(WithIOContext(ctx, ioContext), dest)
 	if err != nil {
 		return err
 	}
 
-	if err := os.CopyBytes(ctx, is, int(is.Length())); err != nil {
-		// IOUtils.deleteFilesIgnoringExceptions(this, dest)
-		// TODO: 删除目标文件
-		return err
+	if err := output.CopyBytes(ctx, input, int(input.Length())); err != nil {
+		return errors.Join(err, output.Close(), to.DeleteFile(ctx, dest))
 	}
-
-	if err := d.DeleteFile(ctx, dest); err != nil {
-		return err
-	}
-
-	return nil
+	return output.Close()
 }
 
 // Creates a file name for a temporary file. The name will start with prefix, end with suffix and have a reserved file extension .tmp.
@@ -0,0 +1,27 @@
+//go:build linux || darwin
+
+package store
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+var errLockUnavailable = errors.New("lock unavailable")
+
+// tryLockFile Acquires an exclusive advisory lock on the file without blocking, errLockUnavailable
+// is returned when another open file holds the lock.
+func tryLockFile(file *os.File) error {
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return errLockUnavailable
+		}
+		return err
+	}
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
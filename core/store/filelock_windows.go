@@ -0,0 +1,49 @@
+//go:build windows
+
+package store
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
+
+	errorLockViolation syscall.Errno = 33
+)
+
+var (
+	errLockUnavailable = errors.New("lock unavailable")
+
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+// tryLockFile Acquires an exclusive lock on the first byte of the file without blocking,
+// errLockUnavailable is returned when another handle holds the lock.
+func tryLockFile(file *os.File) error {
+	overlapped := new(syscall.Overlapped)
+	r1, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock|lockfileFailImmediately,
+		0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		if errors.Is(err, errorLockViolation) {
+			return errLockUnavailable
+		}
+		return err
+	}
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	overlapped := new(syscall.Overlapped)
+	r1, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}
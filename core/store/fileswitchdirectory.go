@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var _ Directory = &FileSwitchDirectory{}
+
+// FileSwitchDirectory
+// Expert: A Directory instance that switches files between two other Directory instances.
+//
+// Files with the specified extensions are placed in the primary directory; others are placed in the
+// secondary directory. The provided map must not change once passed to this type, and must allow
+// multiple threads to call contains at once.
+//
+// Locks with a name having the specified extensions are delegated to the primary directory; others
+// are delegated to the secondary directory. Ideally, both Directory instances should use the same
+// lock factory.
+type FileSwitchDirectory struct {
+	primaryExtensions map[string]struct{}
+	primaryDir        Directory
+	secondaryDir      Directory
+	doClose           bool
+}
+
+// NewFileSwitchDirectory
+// primaryExtensions: the extensions, without the leading dot, of the files placed in primaryDir
+// doClose: whether Close also closes both directories
+func NewFileSwitchDirectory(primaryExtensions map[string]struct{}, primaryDir, secondaryDir Directory,
+	doClose bool) *FileSwitchDirectory {
+
+	return &FileSwitchDirectory{
+		primaryExtensions: primaryExtensions,
+		primaryDir:        primaryDir,
+		secondaryDir:      secondaryDir,
+		doClose:           doClose,
+	}
+}
+
+// GetPrimaryDir Return the primary directory
+func (f *FileSwitchDirectory) GetPrimaryDir() Directory {
+	return f.primaryDir
+}
+
+// GetSecondaryDir Return the secondary directory
+func (f *FileSwitchDirectory) GetSecondaryDir() Directory {
+	return f.secondaryDir
+}
+
+// GetExtension Utility method to return a file's extension, without the leading dot.
+func GetExtension(name string) string {
+	i := strings.LastIndexByte(name, '.')
+	if i == -1 {
+		return ""
+	}
+	return name[i+1:]
+}
+
+func (f *FileSwitchDirectory) getDirectory(name string) Directory {
+	if _, ok := f.primaryExtensions[GetExtension(name)]; ok {
+		return f.primaryDir
+	}
+	return f.secondaryDir
+}
+
+func (f *FileSwitchDirectory) ListAll(ctx context.Context) ([]string, error) {
+	// LUCENE-3380: either or both of our dirs could be FSDirs,
+	// but if one underlying delegate is an FSDir and mkdirs() has not
+	// yet been called, because so far everything is written to the other,
+	// in this case, we don't want to throw a NoSuchDirectoryException
+	primary, err := f.primaryDir.ListAll(ctx)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	secondary, err := f.secondaryDir.ListAll(ctx)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	names := append(primary, secondary...)
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+func (f *FileSwitchDirectory) DeleteFile(ctx context.Context, name string) error {
+	return f.getDirectory(name).DeleteFile(ctx, name)
+}
+
+func (f *FileSwitchDirectory) FileLength(ctx context.Context, name string) (int64, error) {
+	return f.getDirectory(name).FileLength(ctx, name)
+}
+
+func (f *FileSwitchDirectory) CreateOutput(ctx context.Context, name string) (IndexOutput, error) {
+	return f.getDirectory(name).CreateOutput(ctx, name)
+}
+
+// CreateTempOutput Temp files have the reserved extension tmp, they are placed by that extension
+func (f *FileSwitchDirectory) CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error) {
+	return f.getDirectory(genTempFileName(prefix, suffix, 0)).CreateTempOutput(ctx, prefix, suffix)
+}
+
+func (f *FileSwitchDirectory) SyncMetaData(ctx context.Context) error {
+	// we call it on both and then we rely on the FS to not sync the same dir twice
+	if err := f.primaryDir.SyncMetaData(ctx); err != nil {
+		return err
+	}
+	return f.secondaryDir.SyncMetaData(ctx)
+}
+
+func (f *FileSwitchDirectory) Sync(files map[string]struct{}) error {
+	primaryNames := make(map[string]struct{})
+	secondaryNames := make(map[string]struct{})
+
+	for name := range files {
+		if f.getDirectory(name) == f.primaryDir {
+			primaryNames[name] = struct{}{}
+		} else {
+			secondaryNames[name] = struct{}{}
+		}
+	}
+
+	if err := f.primaryDir.Sync(primaryNames); err != nil {
+		return err
+	}
+	return f.secondaryDir.Sync(secondaryNames)
+}
+
+func (f *FileSwitchDirectory) Rename(ctx context.Context, source, dest string) error {
+	sourceDir := f.getDirectory(source)
+	// won't happen with standard lucene index files since pending and commit will
+	// always have the same extension ("")
+	if sourceDir != f.getDirectory(dest) {
+		return fmt.Errorf("source and dest are in different directories: %s, %s", source, dest)
+	}
+	return sourceDir.Rename(ctx, source, dest)
+}
+
+func (f *FileSwitchDirectory) OpenInput(ctx context.Context, name string) (IndexInput, error) {
+	return f.getDirectory(name).OpenInput(ctx, name)
+}
+
+func (f *FileSwitchDirectory) ObtainLock(name string) (Lock, error) {
+	return f.getDirectory(name).ObtainLock(name)
+}
+
+func (f *FileSwitchDirectory) Close() error {
+	if !f.doClose {
+		return nil
+	}
+	return errors.Join(f.primaryDir.Close(), f.secondaryDir.Close())
+}
+
+func (f *FileSwitchDirectory) CopyFrom(ctx context.Context, from Directory, src, dest string, ioContext *IOContext) error {
+	return f.getDirectory(dest).CopyFrom(ctx, from, src, dest, ioContext)
+}
+
+func (f *FileSwitchDirectory) EnsureOpen() error {
+	if err := f.primaryDir.EnsureOpen(); err != nil {
+		return err
+	}
+	return f.secondaryDir.EnsureOpen()
+}
+
+func (f *FileSwitchDirectory) GetPendingDeletions() (map[string]struct{}, error) {
+	primary, err := f.primaryDir.GetPendingDeletions()
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := f.secondaryDir.GetPendingDeletions()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := maps.Clone(primary)
+	if pending == nil {
+		pending = make(map[string]struct{})
+	}
+	maps.Copy(pending, secondary)
+	return pending, nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSwitchDirectory(t *testing.T) {
+	primary, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	secondary, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+
+	dir := NewFileSwitchDirectory(map[string]struct{}{"fdt": {}, "fdx": {}}, primary, secondary, true)
+
+	ctx := context.Background()
+	writeTestFile(t, ctx, dir, "_0.fdt", []byte("stored fields"))
+	writeTestFile(t, ctx, dir, "_0.fdx", []byte("index"))
+	writeTestFile(t, ctx, dir, "_0.tim", []byte("terms"))
+
+	files, err := primary.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.fdt", "_0.fdx"}, files)
+	files, err = secondary.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.tim"}, files)
+
+	files, err = dir.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.fdt", "_0.fdx", "_0.tim"}, files)
+
+	assert.Equal(t, []byte("stored fields"), readTestFile(t, ctx, dir, "_0.fdt"))
+	size, err := dir.FileLength(ctx, "_0.tim")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len("terms")), size)
+
+	assert.Nil(t, dir.Sync(map[string]struct{}{"_0.fdt": {}, "_0.tim": {}}))
+	assert.Nil(t, dir.SyncMetaData(ctx))
+
+	assert.Nil(t, dir.Rename(ctx, "_0.tim", "_1.tim"))
+	assert.NotNil(t, dir.Rename(ctx, "_0.fdx", "_1.tim"))
+
+	assert.Nil(t, dir.DeleteFile(ctx, "_0.fdx"))
+	files, err = dir.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.fdt", "_1.tim"}, files)
+
+	// temp files have the tmp extension
+	output, err := dir.CreateTempOutput(ctx, "_0", "fdt")
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+	files, err = secondary.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, files, output.GetName())
+
+	assert.Nil(t, dir.Close())
+	assert.NotNil(t, primary.EnsureOpen())
+	assert.NotNil(t, secondary.EnsureOpen())
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func TestSimpleFSLock(t *testing.T) {
-	dir, err := NewNIOFSDirectory(os.TempDir())
+	dir, err := NewNIOFSDirectoryV1(os.TempDir(), NewSimpleFSLockFactory())
 	if assert.Nil(t, err) {
 		defer dir.Close()
 	}
@@ -0,0 +1,421 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ Directory = &MetricsDirectoryWrapper{}
+
+// MetricsDirectoryWrapper
+// A Directory wrapper that records the IO performed through it, grouped by file type: the bytes
+// read and written, the number of inputs currently open and, with WithLatencyTracking, the time
+// spent in reads and writes.
+//
+// The file type is the extension of the file name, except for the segments_N and pending_segments_N
+// files, which are grouped as "segments" and "pending_segments". Reads through clones and slices are
+// accounted to the file they come from.
+//
+// See Also: GetStats
+type MetricsDirectoryWrapper struct {
+	Directory
+
+	sync.RWMutex
+
+	metrics      map[string]*fileTypeMetrics
+	trackLatency bool
+}
+
+type MetricsDirectoryOption func(m *MetricsDirectoryWrapper)
+
+// WithLatencyTracking
+// Also records the time spent in reads and writes. Every call is timed, including the one byte reads
+// and writes of the data inputs and outputs, so it is disabled by default.
+func WithLatencyTracking(trackLatency bool) MetricsDirectoryOption {
+	return func(m *MetricsDirectoryWrapper) {
+		m.trackLatency = trackLatency
+	}
+}
+
+func NewMetricsDirectoryWrapper(wrapped Directory, options ...MetricsDirectoryOption) *MetricsDirectoryWrapper {
+	wrapper := &MetricsDirectoryWrapper{
+		Directory: wrapped,
+		metrics:   make(map[string]*fileTypeMetrics),
+	}
+	for _, option := range options {
+		option(wrapper)
+	}
+	return wrapper
+}
+
+// FileTypeStats
+// A snapshot of the IO performed on the files of a type.
+type FileTypeStats struct {
+	// BytesRead The bytes read, through inputs, their clones and slices
+	BytesRead int64
+
+	// BytesWritten The bytes written through outputs
+	BytesWritten int64
+
+	// Reads The number of read calls
+	Reads int64
+
+	// Writes The number of write calls
+	Writes int64
+
+	// ReadTime The time spent in read calls, 0 unless latency tracking is enabled
+	ReadTime time.Duration
+
+	// WriteTime The time spent in write calls, 0 unless latency tracking is enabled
+	WriteTime time.Duration
+
+	// OpenInputs The number of inputs currently open, clones and slices are not counted
+	OpenInputs int64
+
+	// InputsOpened The number of inputs opened so far
+	InputsOpened int64
+
+	// OutputsCreated The number of outputs created so far
+	OutputsCreated int64
+}
+
+// ReadLatency Returns the average time of a read call, 0 unless latency tracking is enabled
+func (s FileTypeStats) ReadLatency() time.Duration {
+	if s.Reads == 0 {
+		return 0
+	}
+	return s.ReadTime / time.Duration(s.Reads)
+}
+
+// WriteLatency Returns the average time of a write call, 0 unless latency tracking is enabled
+func (s FileTypeStats) WriteLatency() time.Duration {
+	if s.Writes == 0 {
+		return 0
+	}
+	return s.WriteTime / time.Duration(s.Writes)
+}
+
+type fileTypeMetrics struct {
+	bytesRead      atomic.Int64
+	bytesWritten   atomic.Int64
+	reads          atomic.Int64
+	writes         atomic.Int64
+	readNanos      atomic.Int64
+	writeNanos     atomic.Int64
+	openInputs     atomic.Int64
+	inputsOpened   atomic.Int64
+	outputsCreated atomic.Int64
+
+	trackLatency bool
+}
+
+// startTimer Returns the start time of a call, the zero time when latencies are not tracked
+func (m *fileTypeMetrics) startTimer() time.Time {
+	if !m.trackLatency {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (m *fileTypeMetrics) recordRead(n int, start time.Time) {
+	if m.trackLatency {
+		m.readNanos.Add(int64(time.Since(start)))
+	}
+	m.reads.Add(1)
+	m.bytesRead.Add(int64(n))
+}
+
+func (m *fileTypeMetrics) recordWrite(n int, start time.Time) {
+	if m.trackLatency {
+		m.writeNanos.Add(int64(time.Since(start)))
+	}
+	m.writes.Add(1)
+	m.bytesWritten.Add(int64(n))
+}
+
+// reset The inputs still open decrement openInputs once closed, so it is kept
+func (m *fileTypeMetrics) reset() {
+	m.bytesRead.Store(0)
+	m.bytesWritten.Store(0)
+	m.reads.Store(0)
+	m.writes.Store(0)
+	m.readNanos.Store(0)
+	m.writeNanos.Store(0)
+	m.inputsOpened.Store(0)
+	m.outputsCreated.Store(0)
+}
+
+func (m *fileTypeMetrics) stats() FileTypeStats {
+	return FileTypeStats{
+		BytesRead:      m.bytesRead.Load(),
+		BytesWritten:   m.bytesWritten.Load(),
+		Reads:          m.reads.Load(),
+		Writes:         m.writes.Load(),
+		ReadTime:       time.Duration(m.readNanos.Load()),
+		WriteTime:      time.Duration(m.writeNanos.Load()),
+		OpenInputs:     m.openInputs.Load(),
+		InputsOpened:   m.inputsOpened.Load(),
+		OutputsCreated: m.outputsCreated.Load(),
+	}
+}
+
+// GetFileType Returns the type a file is accounted to
+func GetFileType(name string) string {
+	switch {
+	case strings.HasPrefix(name, "pending_segments"):
+		return "pending_segments"
+	case strings.HasPrefix(name, "segments"):
+		return "segments"
+	default:
+		return GetExtension(name)
+	}
+}
+
+// GetStats Returns a snapshot of the IO performed so far, keyed by file type
+func (m *MetricsDirectoryWrapper) GetStats() map[string]FileTypeStats {
+	m.RLock()
+	defer m.RUnlock()
+
+	stats := make(map[string]FileTypeStats, len(m.metrics))
+	for fileType, metrics := range m.metrics {
+		stats[fileType] = metrics.stats()
+	}
+	return stats
+}
+
+// GetFileTypeStats Returns a snapshot of the IO performed so far on the files of fileType
+func (m *MetricsDirectoryWrapper) GetFileTypeStats(fileType string) FileTypeStats {
+	m.RLock()
+	defer m.RUnlock()
+
+	if metrics, ok := m.metrics[fileType]; ok {
+		return metrics.stats()
+	}
+	return FileTypeStats{}
+}
+
+// ResetStats Clears the counters, except for the number of inputs currently open
+func (m *MetricsDirectoryWrapper) ResetStats() {
+	m.RLock()
+	defer m.RUnlock()
+
+	for _, metrics := range m.metrics {
+		metrics.reset()
+	}
+}
+
+func (m *MetricsDirectoryWrapper) getMetrics(name string) *fileTypeMetrics {
+	fileType := GetFileType(name)
+
+	m.RLock()
+	metrics, ok := m.metrics[fileType]
+	m.RUnlock()
+	if ok {
+		return metrics
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if metrics, ok := m.metrics[fileType]; ok {
+		return metrics
+	}
+	metrics = &fileTypeMetrics{trackLatency: m.trackLatency}
+	m.metrics[fileType] = metrics
+	return metrics
+}
+
+func (m *MetricsDirectoryWrapper) CreateOutput(ctx context.Context, name string) (IndexOutput, error) {
+	output, err := m.Directory.CreateOutput(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	metrics := m.getMetrics(name)
+	metrics.outputsCreated.Add(1)
+	return newMetricsIndexOutput(output, metrics), nil
+}
+
+func (m *MetricsDirectoryWrapper) CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error) {
+	output, err := m.Directory.CreateTempOutput(ctx, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	metrics := m.getMetrics(output.GetName())
+	metrics.outputsCreated.Add(1)
+	return newMetricsIndexOutput(output, metrics), nil
+}
+
+func (m *MetricsDirectoryWrapper) OpenInput(ctx context.Context, name string) (IndexInput, error) {
+	input, err := m.Directory.OpenInput(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	metrics := m.getMetrics(name)
+	metrics.inputsOpened.Add(1)
+	metrics.openInputs.Add(1)
+	return newMetricsIndexInput(input, metrics, false), nil
+}
+
+// CopyFrom Copies through CreateOutput, so that the bytes written are counted
+func (m *MetricsDirectoryWrapper) CopyFrom(ctx context.Context, from Directory, src, dest string, ioContext *IOContext) error {
+	return CopyFrom(ctx, m, from, src, dest, ioContext)
+}
+
+var _ IndexOutput = &metricsIndexOutput{}
+
+type metricsIndexOutput struct {
+	*BaseIndexOutput
+
+	delegate IndexOutput
+	metrics  *fileTypeMetrics
+}
+
+func newMetricsIndexOutput(delegate IndexOutput, metrics *fileTypeMetrics) *metricsIndexOutput {
+	output := &metricsIndexOutput{
+		delegate: delegate,
+		metrics:  metrics,
+	}
+	output.BaseIndexOutput = NewBaseIndexOutput(delegate.GetName(), output)
+	return output
+}
+
+func (o *metricsIndexOutput) Write(b []byte) (int, error) {
+	start := o.metrics.startTimer()
+	n, err := o.delegate.Write(b)
+	o.metrics.recordWrite(n, start)
+	return n, err
+}
+
+func (o *metricsIndexOutput) Close() error {
+	return o.delegate.Close()
+}
+
+func (o *metricsIndexOutput) GetFilePointer() int64 {
+	return o.delegate.GetFilePointer()
+}
+
+func (o *metricsIndexOutput) GetChecksum() (uint32, error) {
+	return o.delegate.GetChecksum()
+}
+
+var _ IndexInput = &metricsIndexInput{}
+
+type metricsIndexInput struct {
+	*BaseIndexInput
+
+	delegate IndexInput
+	metrics  *fileTypeMetrics
+
+	// clones and slices are not counted as open inputs, they are never closed
+	isClone bool
+	closed  bool
+}
+
+func newMetricsIndexInput(delegate IndexInput, metrics *fileTypeMetrics, isClone bool) *metricsIndexInput {
+	input := &metricsIndexInput{
+		delegate: delegate,
+		metrics:  metrics,
+		isClone:  isClone,
+	}
+	input.BaseIndexInput = NewBaseIndexInput(input)
+	return input
+}
+
+func (i *metricsIndexInput) Read(p []byte) (int, error) {
+	start := i.metrics.startTimer()
+	n, err := i.delegate.Read(p)
+	i.metrics.recordRead(n, start)
+	return n, err
+}
+
+func (i *metricsIndexInput) Clone() CloneReader {
+	return newMetricsIndexInput(i.delegate.Clone().(IndexInput), i.metrics, true)
+}
+
+func (i *metricsIndexInput) Close() error {
+	if !i.isClone && !i.closed {
+		i.closed = true
+		i.metrics.openInputs.Add(-1)
+	}
+	return i.delegate.Close()
+}
+
+func (i *metricsIndexInput) Seek(offset int64, whence int) (int64, error) {
+	return i.delegate.Seek(offset, whence)
+}
+
+func (i *metricsIndexInput) GetFilePointer() int64 {
+	return i.delegate.GetFilePointer()
+}
+
+func (i *metricsIndexInput) Slice(sliceDescription string, offset, length int64) (IndexInput, error) {
+	slice, err := i.delegate.Slice(sliceDescription, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return newMetricsIndexInput(slice, i.metrics, true), nil
+}
+
+func (i *metricsIndexInput) Length() int64 {
+	return i.delegate.Length()
+}
+
+func (i *metricsIndexInput) RandomAccessSlice(offset int64, length int64) (RandomAccessInput, error) {
+	slice, err := i.delegate.RandomAccessSlice(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsRandomAccessInput{delegate: slice, metrics: i.metrics}, nil
+}
+
+var _ RandomAccessInput = &metricsRandomAccessInput{}
+
+type metricsRandomAccessInput struct {
+	delegate RandomAccessInput
+	metrics  *fileTypeMetrics
+}
+
+func (r *metricsRandomAccessInput) ReadAt(p []byte, off int64) (int, error) {
+	start := r.metrics.startTimer()
+	n, err := r.delegate.ReadAt(p, off)
+	r.metrics.recordRead(n, start)
+	return n, err
+}
+
+func (r *metricsRandomAccessInput) ReadU8(pos int64) (byte, error) {
+	start := r.metrics.startTimer()
+	v, err := r.delegate.ReadU8(pos)
+	r.metrics.recordRead(readSize(1, err), start)
+	return v, err
+}
+
+func (r *metricsRandomAccessInput) ReadU16(pos int64) (uint16, error) {
+	start := r.metrics.startTimer()
+	v, err := r.delegate.ReadU16(pos)
+	r.metrics.recordRead(readSize(2, err), start)
+	return v, err
+}
+
+func (r *metricsRandomAccessInput) ReadU32(pos int64) (uint32, error) {
+	start := r.metrics.startTimer()
+	v, err := r.delegate.ReadU32(pos)
+	r.metrics.recordRead(readSize(4, err), start)
+	return v, err
+}
+
+func (r *metricsRandomAccessInput) ReadU64(pos int64) (uint64, error) {
+	start := r.metrics.startTimer()
+	v, err := r.delegate.ReadU64(pos)
+	r.metrics.recordRead(readSize(8, err), start)
+	return v, err
+}
+
+// readSize Returns the bytes a fixed size read consumed, none when it failed
+func readSize(size int, err error) int {
+	if err != nil {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFileType(t *testing.T) {
+	assert.Equal(t, "segments", GetFileType("segments_1"))
+	assert.Equal(t, "pending_segments", GetFileType("pending_segments_2"))
+	assert.Equal(t, "doc", GetFileType("_0_Lucene90_0.doc"))
+	assert.Equal(t, "tmp", GetFileType("_0_fdt_1.tmp"))
+	assert.Equal(t, "", GetFileType("noext"))
+}
+
+func TestMetricsDirectoryWrapper(t *testing.T) {
+	delegate, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	dir := NewMetricsDirectoryWrapper(delegate)
+	defer dir.Close()
+
+	ctx := context.Background()
+	output, err := dir.CreateOutput(ctx, "_0.fdt")
+	assert.Nil(t, err)
+	_, err = output.Write(make([]byte, 100))
+	assert.Nil(t, err)
+	assert.Nil(t, output.WriteUint32(ctx, 7))
+	assert.Nil(t, output.Close())
+
+	writeTestFile(t, ctx, dir, "segments_1", []byte{1, 2, 3})
+
+	stats := dir.GetFileTypeStats("fdt")
+	assert.Equal(t, int64(104), stats.BytesWritten)
+	assert.Equal(t, int64(2), stats.Writes)
+	assert.Equal(t, int64(1), stats.OutputsCreated)
+	assert.Equal(t, int64(3), dir.GetFileTypeStats("segments").BytesWritten)
+
+	input, err := dir.OpenInput(ctx, "_0.fdt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), dir.GetFileTypeStats("fdt").OpenInputs)
+
+	_, err = io.ReadFull(input, make([]byte, 100))
+	assert.Nil(t, err)
+	value, err := input.ReadUint32(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), value)
+
+	// clones and slices count the bytes but not the open inputs
+	clone := input.Clone().(IndexInput)
+	_, err = clone.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	_, err = clone.ReadByte()
+	assert.Nil(t, err)
+
+	slice, err := input.Slice("test", 10, 20)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(slice, make([]byte, 20))
+	assert.Nil(t, err)
+
+	random, err := input.RandomAccessSlice(0, 104)
+	assert.Nil(t, err)
+	value, err = random.ReadU32(100)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), value)
+
+	stats = dir.GetFileTypeStats("fdt")
+	assert.Equal(t, int64(100+4+1+20+4), stats.BytesRead)
+	assert.Equal(t, int64(1), stats.OpenInputs)
+	assert.Equal(t, int64(1), stats.InputsOpened)
+	// latencies are not tracked by default
+	assert.Equal(t, time.Duration(0), stats.ReadTime)
+	assert.Equal(t, time.Duration(0), stats.WriteTime)
+
+	assert.Nil(t, input.Close())
+	assert.Equal(t, int64(0), dir.GetFileTypeStats("fdt").OpenInputs)
+
+	all := dir.GetStats()
+	assert.Len(t, all, 2)
+	assert.Contains(t, all, "fdt")
+	assert.Contains(t, all, "segments")
+
+	dir.ResetStats()
+	stats = dir.GetFileTypeStats("fdt")
+	assert.Equal(t, FileTypeStats{}, stats)
+}
+
+func TestMetricsDirectoryWrapper_LatencyTracking(t *testing.T) {
+	delegate, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	dir := NewMetricsDirectoryWrapper(delegate, WithLatencyTracking(true))
+	defer dir.Close()
+
+	ctx := context.Background()
+	writeTestFile(t, ctx, dir, "_0.fdt", make([]byte, 100))
+
+	input, err := dir.OpenInput(ctx, "_0.fdt")
+	assert.Nil(t, err)
+	defer input.Close()
+	_, err = io.ReadFull(input, make([]byte, 100))
+	assert.Nil(t, err)
+
+	stats := dir.GetFileTypeStats("fdt")
+	assert.True(t, stats.ReadTime > 0)
+	assert.True(t, stats.ReadLatency() > 0)
+	assert.True(t, stats.WriteTime > 0)
+	assert.True(t, stats.WriteLatency() > 0)
+}
@@ -12,6 +12,12 @@ var (
 	_ DataInput = &MmapDataInput{}
 )
 
+// MmapDataInput
+// A DataInput reading a file mapped into memory as a whole. Clones share the mapping, closing
+// any of them unmaps it for all.
+//
+// Deprecated: use MMapDirectory, its inputs map large files in chunks, support Seek, Slice and
+// random access reads, and only unmap when the input returned by OpenInput is closed.
 type MmapDataInput struct {
 	*BaseDataInput
 
@@ -20,6 +26,7 @@ type MmapDataInput struct {
 	isEOF  bool
 }
 
+// Deprecated: use MMapDirectory.OpenInput.
 func NewMmapDataInput(file string) (*MmapDataInput, error) {
 	reader, err := mmap.Open(file)
 	if err != nil {
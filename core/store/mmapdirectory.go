@@ -0,0 +1,140 @@
+//go:build linux || darwin
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// DEFAULT_MAX_CHUNK_SIZE Default max chunk size: 16 GiB on 64 bit platforms, 256 MiB on 32 bit platforms.
+	DEFAULT_MAX_CHUNK_SIZE = 1 << (28 + 6*(strconv.IntSize/64))
+)
+
+var _ FSDirectory = &MMapDirectory{}
+
+// MMapDirectory
+// File-based Directory implementation that uses mmap for reading, and NIOFSDirectory.FSIndexOutput for writing.
+//
+// NOTE: memory mapping uses up a portion of the virtual memory address space in your process equal to the
+// size of the file being mapped. Before using this class, be sure you have plenty of virtual address space,
+// e.g. by using a 64 bit platform.
+//
+// Files are mapped in chunks of at most maxChunkSize bytes, so that files larger than the biggest
+// contiguous mapping the platform supports can still be read. Reads that fit into a single chunk are
+// served straight from the page cache, clones and slices share the mappings of the input they were
+// created from.
+//
+// The mappings of a file are released when the IndexInput returned by OpenInput is closed. Clones and
+// slices of a closed input return an error instead of reading unmapped memory.
+type MMapDirectory struct {
+	*NIOFSDirectory
+
+	chunkSizePower int
+}
+
+// NewMMapDirectory
+// Create a new MMapDirectory for the named location, mapping files in chunks of DEFAULT_MAX_CHUNK_SIZE bytes.
+func NewMMapDirectory(path string) (*MMapDirectory, error) {
+	return NewMMapDirectoryV1(path, DEFAULT_MAX_CHUNK_SIZE)
+}
+
+// NewMMapDirectoryV1
+// Create a new MMapDirectory for the named location, locked with a NativeFSLockFactory.
+// maxChunkSize: maximum chunk size used for memory mapping, it must be a power of 2 and a multiple of
+// the page size of the platform.
+func NewMMapDirectoryV1(path string, maxChunkSize int) (*MMapDirectory, error) {
+	return NewMMapDirectoryV2(path, NewNativeFSLockFactory(), maxChunkSize)
+}
+
+// NewMMapDirectoryV2
+// Create a new MMapDirectory for the named location.
+// lockFactory: the lock factory to use
+// maxChunkSize: maximum chunk size used for memory mapping, it must be a power of 2 and a multiple of
+// the page size of the platform.
+func NewMMapDirectoryV2(path string, lockFactory LockFactory, maxChunkSize int) (*MMapDirectory, error) {
+	if maxChunkSize <= 0 || maxChunkSize&(maxChunkSize-1) != 0 {
+		return nil, fmt.Errorf("maximum chunk size for mmap must be a power of 2, got %d", maxChunkSize)
+	}
+	if pageSize := os.Getpagesize(); maxChunkSize < pageSize {
+		return nil, fmt.Errorf("maximum chunk size for mmap must be >= %d, got %d", pageSize, maxChunkSize)
+	}
+
+	dir, err := NewNIOFSDirectoryV1(path, lockFactory)
+	if err != nil {
+		return nil, err
+	}
+	return &MMapDirectory{
+		NIOFSDirectory: dir,
+		chunkSizePower: bits.TrailingZeros(uint(maxChunkSize)),
+	}, nil
+}
+
+// GetMaxChunkSize
+// Returns the current mmap chunk size.
+func (m *MMapDirectory) GetMaxChunkSize() int {
+	return 1 << m.chunkSizePower
+}
+
+// OpenInput Creates an IndexInput for the file with the given name, the whole file is mapped
+// into memory before it returns.
+func (m *MMapDirectory) OpenInput(ctx context.Context, name string) (IndexInput, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if err := m.EnsureOpen(); err != nil {
+		return nil, err
+	}
+	if err := m.ensureCanRead(name); err != nil {
+		return nil, err
+	}
+
+	path := m.resolveFilePath(name)
+	file, err := os.OpenFile(path, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// the mappings stay valid after the file descriptor is closed
+	defer file.Close()
+
+	desc := fmt.Sprintf("MMapIndexInput(path=\"%s\")", path)
+	chunks, err := mapChunks(desc, file, m.chunkSizePower)
+	if err != nil {
+		return nil, err
+	}
+	return newMMapIndexInput(desc, newMmapGuard(desc, chunks), m.chunkSizePower), nil
+}
+
+// mapChunks Maps the whole file into memory, in chunks of 1<<chunkSizePower bytes.
+func mapChunks(desc string, file *os.File, chunkSizePower int) ([][]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	length := info.Size()
+	if length == 0 {
+		return [][]byte{}, nil
+	}
+
+	chunkSize := int64(1) << chunkSizePower
+	nrChunks := int((length + chunkSize - 1) >> chunkSizePower)
+	chunks := make([][]byte, 0, nrChunks)
+	for offset := int64(0); offset < length; offset += chunkSize {
+		size := min(chunkSize, length-offset)
+		chunk, err := syscall.Mmap(int(file.Fd()), offset, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+		if err != nil {
+			for _, mapped := range chunks {
+				_ = syscall.Munmap(mapped)
+			}
+			return nil, fmt.Errorf("map failed: %s, chunk at %d: %w", desc, offset, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,239 @@
+//go:build linux || darwin
+
+package store
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeMMapTestFile(t *testing.T, dir Directory, name string, size int) []byte {
+	output, err := dir.CreateOutput(context.Background(), name)
+	assert.Nil(t, err)
+
+	bs := make([]byte, size)
+	for i := range bs {
+		bs[i] = byte(i % 251)
+	}
+	_, err = output.Write(bs)
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+	return bs
+}
+
+func TestNewMMapDirectoryV1(t *testing.T) {
+	_, err := NewMMapDirectoryV1(t.TempDir(), 3*os.Getpagesize())
+	assert.NotNil(t, err)
+
+	_, err = NewMMapDirectoryV1(t.TempDir(), os.Getpagesize()/2)
+	assert.NotNil(t, err)
+
+	dir, err := NewMMapDirectoryV1(t.TempDir(), os.Getpagesize())
+	assert.Nil(t, err)
+	assert.Equal(t, os.Getpagesize(), dir.GetMaxChunkSize())
+	assert.Nil(t, dir.Close())
+}
+
+func TestMMapDirectory_OpenInput(t *testing.T) {
+	pageSize := os.Getpagesize()
+	dir, err := NewMMapDirectoryV1(t.TempDir(), pageSize)
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	// spans three chunks, the last one partially
+	expect := writeMMapTestFile(t, dir, "a", 2*pageSize+100)
+
+	input, err := dir.OpenInput(context.Background(), "a")
+	assert.Nil(t, err)
+	defer input.Close()
+
+	assert.EqualValues(t, len(expect), input.Length())
+
+	bs := make([]byte, len(expect)+10)
+	n, err := input.Read(bs)
+	assert.Nil(t, err)
+	assert.Equal(t, len(expect), n)
+	assert.Equal(t, expect, bs[:n])
+	assert.EqualValues(t, len(expect), input.GetFilePointer())
+
+	_, err = input.Read(bs)
+	assert.ErrorIs(t, err, io.EOF)
+	_, err = input.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// read across a chunk boundary
+	_, err = input.Seek(int64(pageSize-2), io.SeekStart)
+	assert.Nil(t, err)
+	v, err := input.ReadUint32(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, uint32(expect[pageSize-2])<<24|uint32(expect[pageSize-1])<<16|
+		uint32(expect[pageSize])<<8|uint32(expect[pageSize+1]), v)
+	assert.EqualValues(t, pageSize+2, input.GetFilePointer())
+
+	_, err = input.Seek(1, io.SeekEnd)
+	assert.Nil(t, err)
+	b, err := input.ReadByte()
+	assert.Nil(t, err)
+	assert.Equal(t, expect[len(expect)-1], b)
+
+	_, err = input.Seek(int64(len(expect)+1), io.SeekStart)
+	assert.NotNil(t, err)
+}
+
+func TestMMapDirectory_OpenInputEmpty(t *testing.T) {
+	dir, err := NewMMapDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writeMMapTestFile(t, dir, "empty", 0)
+
+	input, err := dir.OpenInput(context.Background(), "empty")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, input.Length())
+
+	_, err = input.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Nil(t, input.Close())
+}
+
+func TestMMapDirectory_OpenInputPendingDelete(t *testing.T) {
+	dir, err := NewMMapDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writeMMapTestFile(t, dir, "a", 10)
+	dir.removeFile = func(name string) error {
+		return fs.ErrPermission
+	}
+	assert.Nil(t, dir.DeleteFile(context.Background(), "a"))
+
+	// the file is still on disk but must not be read anymore
+	_, err = dir.OpenInput(context.Background(), "a")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestMMapIndexInput_SliceAndClone(t *testing.T) {
+	pageSize := os.Getpagesize()
+	dir, err := NewMMapDirectoryV1(t.TempDir(), pageSize)
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	expect := writeMMapTestFile(t, dir, "a", 3*pageSize)
+
+	input, err := dir.OpenInput(context.Background(), "a")
+	assert.Nil(t, err)
+	defer input.Close()
+
+	offset := pageSize - 10
+	slice, err := input.Slice("test", int64(offset), 20)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 20, slice.Length())
+	assert.EqualValues(t, 0, slice.GetFilePointer())
+
+	bs := make([]byte, 30)
+	n, err := slice.Read(bs)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, n)
+	assert.Equal(t, expect[offset:offset+20], bs[:n])
+
+	_, err = slice.Seek(5, io.SeekStart)
+	assert.Nil(t, err)
+	clone := slice.Clone().(IndexInput)
+	assert.EqualValues(t, 5, clone.GetFilePointer())
+
+	b, err := clone.ReadByte()
+	assert.Nil(t, err)
+	assert.Equal(t, expect[offset+5], b)
+	// the clone is positioned independently
+	assert.EqualValues(t, 5, slice.GetFilePointer())
+
+	_, err = input.Slice("test", int64(3*pageSize-5), 10)
+	assert.NotNil(t, err)
+
+	// closing a clone or a slice keeps the mapping alive
+	assert.Nil(t, clone.Close())
+	assert.Nil(t, slice.Close())
+	_, err = input.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	b, err = input.ReadByte()
+	assert.Nil(t, err)
+	assert.Equal(t, expect[0], b)
+}
+
+func TestMMapIndexInput_RandomAccessSlice(t *testing.T) {
+	pageSize := os.Getpagesize()
+	dir, err := NewMMapDirectoryV1(t.TempDir(), pageSize)
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	expect := writeMMapTestFile(t, dir, "a", 2*pageSize)
+
+	input, err := dir.OpenInput(context.Background(), "a")
+	assert.Nil(t, err)
+	defer input.Close()
+
+	offset := pageSize - 8
+	random, err := input.RandomAccessSlice(int64(offset), 16)
+	assert.Nil(t, err)
+
+	u8, err := random.ReadU8(0)
+	assert.Nil(t, err)
+	assert.Equal(t, expect[offset], u8)
+
+	u16, err := random.ReadU16(7)
+	assert.Nil(t, err)
+	assert.EqualValues(t, uint16(expect[offset+7])<<8|uint16(expect[offset+8]), u16)
+
+	u32, err := random.ReadU32(0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, uint32(expect[offset])<<24|uint32(expect[offset+1])<<16|
+		uint32(expect[offset+2])<<8|uint32(expect[offset+3]), u32)
+
+	u64, err := random.ReadU64(4)
+	assert.Nil(t, err)
+	v := uint64(0)
+	for _, b := range expect[offset+4 : offset+12] {
+		v = v<<8 | uint64(b)
+	}
+	assert.Equal(t, v, u64)
+
+	_, err = random.ReadU64(9)
+	assert.NotNil(t, err)
+
+	bs := make([]byte, 20)
+	n, err := random.ReadAt(bs, 4)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 12, n)
+	assert.Equal(t, expect[offset+4:offset+16], bs[:n])
+}
+
+func TestMMapIndexInput_Close(t *testing.T) {
+	dir, err := NewMMapDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer dir.Close()
+
+	writeMMapTestFile(t, dir, "a", 100)
+
+	input, err := dir.OpenInput(context.Background(), "a")
+	assert.Nil(t, err)
+
+	clone := input.Clone().(IndexInput)
+	slice, err := input.RandomAccessSlice(10, 10)
+	assert.Nil(t, err)
+
+	assert.Nil(t, input.Close())
+	// closing twice is harmless
+	assert.Nil(t, input.Close())
+
+	_, err = clone.ReadByte()
+	assert.NotNil(t, err)
+	_, err = clone.Read(make([]byte, 4))
+	assert.NotNil(t, err)
+	_, err = slice.ReadU32(0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,295 @@
+//go:build linux || darwin
+
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+)
+
+// mmapGuard
+// Owns the mapped chunks of a file, shared by an MMapIndexInput and all of its clones and slices.
+// Reads hold the read lock while they access the chunks, so unmap can never pull the memory
+// away from under a running read.
+type mmapGuard struct {
+	sync.RWMutex
+
+	desc   string
+	chunks [][]byte
+	closed bool
+}
+
+func newMmapGuard(desc string, chunks [][]byte) *mmapGuard {
+	return &mmapGuard{
+		desc:   desc,
+		chunks: chunks,
+	}
+}
+
+// acquire Locks the guard for reading, the caller must call RUnlock when acquire succeeds
+func (g *mmapGuard) acquire() error {
+	g.RLock()
+	if g.closed {
+		g.RUnlock()
+		return fmt.Errorf("already closed: %s", g.desc)
+	}
+	return nil
+}
+
+// unmap Releases all the mappings, further reads fail. It is safe to call unmap more than once.
+func (g *mmapGuard) unmap() error {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+
+	var errs []error
+	for _, chunk := range g.chunks {
+		if err := syscall.Munmap(chunk); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	g.chunks = nil
+	return errors.Join(errs...)
+}
+
+var (
+	_ IndexInput        = &MMapIndexInput{}
+	_ RandomAccessInput = &MMapIndexInput{}
+)
+
+// MMapIndexInput
+// An IndexInput reading from memory mapped chunks of a file. Clones and slices share the mappings
+// of the input they were created from, only closing the input returned by MMapDirectory.OpenInput
+// unmaps them.
+type MMapIndexInput struct {
+	*BaseIndexInput
+
+	desc           string
+	guard          *mmapGuard
+	chunkSizePower int
+	chunkSizeMask  int64
+
+	// off, end and pos are absolute positions in the mapped file
+	off     int64
+	end     int64
+	pos     int64
+	isClone bool
+}
+
+func newMMapIndexInput(desc string, guard *mmapGuard, chunkSizePower int) *MMapIndexInput {
+	end := int64(0)
+	if n := len(guard.chunks); n > 0 {
+		end = int64(n-1)<<chunkSizePower + int64(len(guard.chunks[n-1]))
+	}
+
+	input := &MMapIndexInput{
+		desc:           desc,
+		guard:          guard,
+		chunkSizePower: chunkSizePower,
+		chunkSizeMask:  int64(1)<<chunkSizePower - 1,
+		off:            0,
+		end:            end,
+		pos:            0,
+	}
+	input.BaseIndexInput = NewBaseIndexInput(input)
+	return input
+}
+
+func (m *MMapIndexInput) newView(desc string, off, end, pos int64) *MMapIndexInput {
+	input := &MMapIndexInput{
+		desc:           desc,
+		guard:          m.guard,
+		chunkSizePower: m.chunkSizePower,
+		chunkSizeMask:  m.chunkSizeMask,
+		off:            off,
+		end:            end,
+		pos:            pos,
+		isClone:        true,
+	}
+	input.BaseIndexInput = NewBaseIndexInput(input)
+	return input
+}
+
+// copyAt Copies the mapped bytes starting at the absolute position pos into p, crossing chunk
+// boundaries when needed. The guard must be held and the range must be inside the mapping.
+func (m *MMapIndexInput) copyAt(p []byte, pos int64) {
+	for copied := 0; copied < len(p); {
+		chunk := m.guard.chunks[pos>>m.chunkSizePower]
+		n := copy(p[copied:], chunk[pos&m.chunkSizeMask:])
+		copied += n
+		pos += int64(n)
+	}
+}
+
+// readUint Decodes a big-endian unsigned integer of size bytes at the absolute position pos.
+// Values that fit in a single chunk are decoded in place, without copying.
+func (m *MMapIndexInput) readUint(pos int64, size int) (uint64, error) {
+	if pos < m.off || pos+int64(size) > m.end {
+		return 0, io.EOF
+	}
+
+	if err := m.guard.acquire(); err != nil {
+		return 0, err
+	}
+	defer m.guard.RUnlock()
+
+	var bs []byte
+	chunk := m.guard.chunks[pos>>m.chunkSizePower]
+	if idx := pos & m.chunkSizeMask; idx+int64(size) <= int64(len(chunk)) {
+		bs = chunk[idx : idx+int64(size)]
+	} else {
+		bs = make([]byte, size)
+		m.copyAt(bs, pos)
+	}
+
+	switch size {
+	case 1:
+		return uint64(bs[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bs)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bs)), nil
+	default:
+		return binary.BigEndian.Uint64(bs), nil
+	}
+}
+
+func (m *MMapIndexInput) Read(p []byte) (int, error) {
+	if m.pos >= m.end {
+		return 0, io.EOF
+	}
+
+	if err := m.guard.acquire(); err != nil {
+		return 0, err
+	}
+	defer m.guard.RUnlock()
+
+	size := int(min(int64(len(p)), m.end-m.pos))
+	m.copyAt(p[:size], m.pos)
+	m.pos += int64(size)
+	return size, nil
+}
+
+func (m *MMapIndexInput) ReadByte() (byte, error) {
+	v, err := m.readUint(m.pos, 1)
+	if err != nil {
+		return 0, err
+	}
+	m.pos++
+	return byte(v), nil
+}
+
+func (m *MMapIndexInput) Clone() CloneReader {
+	return m.newView(m.desc, m.off, m.end, m.pos)
+}
+
+// Close Unmaps the file when called on the input returned by MMapDirectory.OpenInput,
+// closing a clone or a slice has no effect.
+func (m *MMapIndexInput) Close() error {
+	if m.isClone {
+		return nil
+	}
+	return m.guard.unmap()
+}
+
+func (m *MMapIndexInput) Seek(pos int64, whence int) (int64, error) {
+	nextPos := int64(0)
+
+	switch whence {
+	case io.SeekStart:
+		nextPos = m.off + pos
+	case io.SeekCurrent:
+		nextPos = m.pos + pos
+	case io.SeekEnd:
+		nextPos = m.end - pos
+	}
+
+	if nextPos < m.off || nextPos > m.end {
+		return 0, fmt.Errorf("seek past EOF: %s", m.desc)
+	}
+
+	m.pos = nextPos
+	return m.pos - m.off, nil
+}
+
+func (m *MMapIndexInput) GetFilePointer() int64 {
+	return m.pos - m.off
+}
+
+func (m *MMapIndexInput) Length() int64 {
+	return m.end - m.off
+}
+
+// Slice Creates a slice sharing the mappings of this input
+func (m *MMapIndexInput) Slice(sliceDescription string, offset, length int64) (IndexInput, error) {
+	return m.slice(sliceDescription, offset, length)
+}
+
+func (m *MMapIndexInput) slice(sliceDescription string, offset, length int64) (*MMapIndexInput, error) {
+	if offset < 0 || length < 0 || offset+length > m.Length() {
+		return nil, fmt.Errorf("slice() %s out of bounds: offset=%d,length=%d,fileLength=%d: %s",
+			sliceDescription, offset, length, m.Length(), m.desc)
+	}
+
+	desc := m.desc
+	if sliceDescription != "" {
+		desc = m.desc + " [slice=" + sliceDescription + "]"
+	}
+	off := m.off + offset
+	return m.newView(desc, off, off+length, off), nil
+}
+
+// RandomAccessSlice Creates a slice sharing the mappings of this input, its absolute reads are
+// served from the mappings directly
+func (m *MMapIndexInput) RandomAccessSlice(offset int64, length int64) (RandomAccessInput, error) {
+	return m.slice("randomaccess", offset, length)
+}
+
+func (m *MMapIndexInput) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d: %s", off, m.desc)
+	}
+	pos := m.off + off
+	if pos >= m.end {
+		return 0, io.EOF
+	}
+
+	if err := m.guard.acquire(); err != nil {
+		return 0, err
+	}
+	defer m.guard.RUnlock()
+
+	size := int(min(int64(len(p)), m.end-pos))
+	m.copyAt(p[:size], pos)
+	if size < len(p) {
+		return size, io.EOF
+	}
+	return size, nil
+}
+
+func (m *MMapIndexInput) ReadU8(pos int64) (byte, error) {
+	v, err := m.readUint(m.off+pos, 1)
+	return byte(v), err
+}
+
+func (m *MMapIndexInput) ReadU16(pos int64) (uint16, error) {
+	v, err := m.readUint(m.off+pos, 2)
+	return uint16(v), err
+}
+
+func (m *MMapIndexInput) ReadU32(pos int64) (uint32, error) {
+	v, err := m.readUint(m.off+pos, 4)
+	return uint32(v), err
+}
+
+func (m *MMapIndexInput) ReadU64(pos int64) (uint64, error) {
+	return m.readUint(m.off+pos, 8)
+}
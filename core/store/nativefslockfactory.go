@@ -0,0 +1,184 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var _ FSLockFactory = &NativeFSLockFactory{}
+
+// NativeFSLockFactory Implements LockFactory using native OS file locks: flock on Linux and macOS,
+// LockFileEx on Windows. When the process holding a lock exits, even abnormally, the operating system
+// releases the lock, so a leftover lock file does not prevent a new writer from obtaining it. The lock
+// files are not deleted on release, their mere existence says nothing about whether the lock is held.
+//
+// File locks are held per process by some operating systems, so locks obtained by this process are
+// also tracked in memory: obtaining a lock this process already holds fails, even through another
+// Directory instance pointing at the same path.
+//
+// This is the default LockFactory of NIOFSDirectory and MMapDirectory.
+//
+// NOTE: advisory locks are not reliable on some network filesystems such as NFS, use
+// SimpleFSLockFactory there.
+// See Also: LockFactory
+type NativeFSLockFactory struct {
+	*FSLockFactoryBase
+}
+
+func NewNativeFSLockFactory() *NativeFSLockFactory {
+	factory := &NativeFSLockFactory{}
+	factory.FSLockFactoryBase = NewFSLockFactoryBase(factory)
+	return factory
+}
+
+// nativeLocksHeld The real paths of the locks held by this process
+var nativeLocksHeld = struct {
+	sync.Mutex
+	paths map[string]struct{}
+}{paths: make(map[string]struct{})}
+
+func (n *NativeFSLockFactory) ObtainFSLock(dir FSDirectory, lockName string) (Lock, error) {
+	lockDir, err := dir.GetDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure that lockDir exists and is a directory.
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, err
+	}
+
+	lockFile := filepath.Join(lockDir, lockName)
+
+	// create the file if it does not exist yet, it may be left over by a previous process
+	file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same file may be reached through different paths, the in-process check needs a canonical one
+	realPath, err := filepath.EvalSymlinks(lockFile)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	_, creationTime, _ := FileTime(info)
+
+	if !markNativeLockHeld(realPath) {
+		_ = file.Close()
+		return nil, fmt.Errorf("lock held by this process: %s", realPath)
+	}
+
+	if err := tryLockFile(file); err != nil {
+		_ = file.Close()
+		clearNativeLockHeld(realPath)
+		if errors.Is(err, errLockUnavailable) {
+			return nil, fmt.Errorf("lock held by another program: %s", realPath)
+		}
+		return nil, err
+	}
+
+	return newNativeFSLock(file, realPath, creationTime), nil
+}
+
+func markNativeLockHeld(path string) bool {
+	nativeLocksHeld.Lock()
+	defer nativeLocksHeld.Unlock()
+
+	if _, ok := nativeLocksHeld.paths[path]; ok {
+		return false
+	}
+	nativeLocksHeld.paths[path] = struct{}{}
+	return true
+}
+
+func clearNativeLockHeld(path string) {
+	nativeLocksHeld.Lock()
+	defer nativeLocksHeld.Unlock()
+
+	delete(nativeLocksHeld.paths, path)
+}
+
+func isNativeLockHeld(path string) bool {
+	nativeLocksHeld.Lock()
+	defer nativeLocksHeld.Unlock()
+
+	_, ok := nativeLocksHeld.paths[path]
+	return ok
+}
+
+var _ Lock = &NativeFSLock{}
+
+type NativeFSLock struct {
+	sync.Mutex
+
+	file         *os.File
+	path         string
+	creationTime time.Time
+	closed       bool
+}
+
+func newNativeFSLock(file *os.File, path string, creationTime time.Time) *NativeFSLock {
+	return &NativeFSLock{file: file, path: path, creationTime: creationTime}
+}
+
+// Close Releases the lock, the lock file itself is kept.
+func (n *NativeFSLock) Close() error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.closed {
+		return nil
+	}
+	// whatever happens, the lock is gone for this process
+	defer clearNativeLockHeld(n.path)
+	n.closed = true
+
+	unlockErr := unlockFile(n.file)
+	closeErr := n.file.Close()
+	return errors.Join(unlockErr, closeErr)
+}
+
+func (n *NativeFSLock) EnsureValid() error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.closed {
+		return fmt.Errorf("lock instance already released: %s", n.path)
+	}
+	// check we are still in the locks map (some debugger or something crazy didn't remove us)
+	if !isNativeLockHeld(n.path) {
+		return fmt.Errorf("lock path unexpectedly cleared from map: %s", n.path)
+	}
+
+	// we are the only one holding the lock, nobody should have written to the lock file
+	info, err := n.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != 0 {
+		return fmt.Errorf("unexpected lock file size: %d, (lock=%s)", info.Size(), n.path)
+	}
+
+	// the file our lock is held on must still be the one at the path
+	info, err = os.Stat(n.path)
+	if err != nil {
+		return err
+	}
+	_, ctime, _ := FileTime(info)
+	if !n.creationTime.Equal(ctime) {
+		return fmt.Errorf(
+			"underlying file changed by an external force at %s, (lock=%s)",
+			ctime.String(), n.path)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNativeFSLockFactory(t *testing.T) {
+	dirPath := t.TempDir()
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+
+	lock, err := dir.ObtainLock("write.lock")
+	assert.Nil(t, err)
+	assert.Nil(t, lock.EnsureValid())
+
+	// double lock in the same process, also through another directory instance
+	_, err = dir.ObtainLock("write.lock")
+	assert.NotNil(t, err)
+	other, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer other.Close()
+	}
+	_, err = other.ObtainLock("write.lock")
+	assert.NotNil(t, err)
+
+	// other lock names are independent
+	otherLock, err := dir.ObtainLock("other.lock")
+	assert.Nil(t, err)
+	assert.Nil(t, otherLock.Close())
+
+	assert.Nil(t, lock.Close())
+	// closing twice is harmless
+	assert.Nil(t, lock.Close())
+	assert.NotNil(t, lock.EnsureValid())
+
+	// the lock file is kept, and does not prevent locking again
+	_, err = os.Stat(filepath.Join(dirPath, "write.lock"))
+	assert.Nil(t, err)
+
+	lock, err = other.ObtainLock("write.lock")
+	assert.Nil(t, err)
+	assert.Nil(t, lock.Close())
+}
+
+func TestNativeFSLockFactory_StaleLockFile(t *testing.T) {
+	dirPath := t.TempDir()
+
+	// left over by a process that was killed
+	file, err := os.Create(filepath.Join(dirPath, "write.lock"))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+
+	lock, err := dir.ObtainLock("write.lock")
+	assert.Nil(t, err)
+	assert.Nil(t, lock.EnsureValid())
+	assert.Nil(t, lock.Close())
+}
+
+func TestNativeFSLock_EnsureValid(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a locked file can not be written or removed by other handles on Windows")
+	}
+
+	dirPath := t.TempDir()
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+	lockPath := filepath.Join(dirPath, "write.lock")
+
+	{
+		lock, err := dir.ObtainLock("write.lock")
+		assert.Nil(t, err)
+
+		// somebody wrote to the lock file
+		assert.Nil(t, os.WriteFile(lockPath, []byte{1}, 0644))
+		assert.NotNil(t, lock.EnsureValid())
+		assert.Nil(t, lock.Close())
+	}
+
+	{
+		lock, err := dir.ObtainLock("write.lock")
+		assert.Nil(t, err)
+
+		// somebody removed the lock file
+		assert.Nil(t, os.Remove(lockPath))
+		assert.NotNil(t, lock.EnsureValid())
+		assert.Nil(t, lock.Close())
+	}
+}
+
+const nativeLockHelperDirEnv = "NATIVE_LOCK_HELPER_DIR"
+
+// TestNativeFSLockHelperProcess Is not a real test, it holds a lock for TestNativeFSLockFactory_OtherProcess
+func TestNativeFSLockHelperProcess(t *testing.T) {
+	dirPath := os.Getenv(nativeLockHelperDirEnv)
+	if dirPath == "" {
+		t.Skip("helper process")
+	}
+
+	dir, err := NewNIOFSDirectory(dirPath)
+	if err != nil {
+		os.Exit(1)
+	}
+	if _, err := dir.ObtainLock("write.lock"); err != nil {
+		os.Exit(1)
+	}
+	os.Stdout.WriteString("locked\n")
+
+	// hold the lock until killed
+	select {}
+}
+
+func TestNativeFSLockFactory_OtherProcess(t *testing.T) {
+	dirPath := t.TempDir()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNativeFSLockHelperProcess$")
+	cmd.Env = append(os.Environ(), nativeLockHelperDirEnv+"="+dirPath)
+	stdout, err := cmd.StdoutPipe()
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.Start())
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "locked\n", line)
+
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+	_, err = dir.ObtainLock("write.lock")
+	assert.NotNil(t, err)
+
+	// the OS releases the lock of a killed process, the lock file stays
+	assert.Nil(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+
+	lock, err := dir.ObtainLock("write.lock")
+	assert.Nil(t, err)
+	assert.Nil(t, lock.EnsureValid())
+	assert.Nil(t, lock.Close())
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"sync/atomic"
 )
@@ -33,9 +35,50 @@ type NIOFSDirectory struct {
 	lockFactory         LockFactory   // Holds the LockFactory instance (implements locking for this Directory instance).
 	dir                 string        // The underlying filesystem directory
 	nextTempFileCounter *atomic.Int64 // Used to generate temp file names in createTempOutput.
+
+	// Files we previously tried to delete, but hit an error while deleting, so we keep retrying
+	// (e.g. on Windows, a file that is still open can not be deleted).
+	pendingDeletes map[string]struct{}
+
+	// Used to amortize the cost of retrying pending deletions over the directory operations.
+	opsSinceLastDelete *atomic.Int64
+
+	// removeFile Removes a file from the filesystem, os.Remove unless a test replaces it.
+	removeFile func(name string) error
 }
 
+// Sync Fsyncs each of the named files, so that their contents survive an OS crash or power loss.
 func (n *NIOFSDirectory) Sync(names map[string]struct{}) error {
+	if err := n.EnsureOpen(); err != nil {
+		return err
+	}
+
+	for name := range names {
+		if err := fsync(n.resolveFilePath(name), false); err != nil {
+			return err
+		}
+	}
+
+	n.Lock()
+	defer n.Unlock()
+	n.maybeDeletePendingFiles()
+	return nil
+}
+
+// SyncMetaData Fsyncs the directory itself, so that recent renames, such as the one publishing
+// a segments_N file, survive an OS crash or power loss.
+func (n *NIOFSDirectory) SyncMetaData(ctx context.Context) error {
+	if err := n.EnsureOpen(); err != nil {
+		return err
+	}
+
+	if err := fsync(n.dir, true); err != nil {
+		return err
+	}
+
+	n.Lock()
+	defer n.Unlock()
+	n.maybeDeletePendingFiles()
 	return nil
 }
 
@@ -43,7 +86,16 @@ func (n *NIOFSDirectory) CopyFrom(ctx context.Context, from Directory, src, dest
 	return CopyFrom(ctx, n, from, src, dest, ioContext)
 }
 
+// NewNIOFSDirectory
+// Create a new NIOFSDirectory for the named location, locked with a NativeFSLockFactory.
 func NewNIOFSDirectory(path string) (*NIOFSDirectory, error) {
+	return NewNIOFSDirectoryV1(path, NewNativeFSLockFactory())
+}
+
+// NewNIOFSDirectoryV1
+// Create a new NIOFSDirectory for the named location.
+// lockFactory: the lock factory to use
+func NewNIOFSDirectoryV1(path string, lockFactory LockFactory) (*NIOFSDirectory, error) {
 	dirPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
@@ -66,9 +118,12 @@ func NewNIOFSDirectory(path string) (*NIOFSDirectory, error) {
 
 	dir := &NIOFSDirectory{
 		open:                &atomic.Bool{},
-		lockFactory:         NewSimpleFSLockFactory(),
+		lockFactory:         lockFactory,
 		dir:                 dirPath,
 		nextTempFileCounter: &atomic.Int64{},
+		pendingDeletes:      make(map[string]struct{}),
+		opsSinceLastDelete:  &atomic.Int64{},
+		removeFile:          os.Remove,
 	}
 	dir.open.Store(true)
 	return dir, nil
@@ -81,6 +136,9 @@ func (n *NIOFSDirectory) OpenInput(ctx context.Context, name string) (IndexInput
 	if err := n.EnsureOpen(); err != nil {
 		return nil, err
 	}
+	if err := n.ensureCanRead(name); err != nil {
+		return nil, err
+	}
 
 	path := n.resolveFilePath(name)
 
@@ -91,6 +149,15 @@ func (n *NIOFSDirectory) OpenInput(ctx context.Context, name string) (IndexInput
 	return NewNIOFSIndexInput(file)
 }
 
+// ensureCanRead Returns an error if the file is pending deletion, it is gone for the callers even
+// though it is still on disk. The caller must hold the lock.
+func (n *NIOFSDirectory) ensureCanRead(name string) error {
+	if _, ok := n.pendingDeletes[name]; ok {
+		return fmt.Errorf("file %s is pending delete and cannot be opened for read: %w", name, fs.ErrNotExist)
+	}
+	return nil
+}
+
 func (n *NIOFSDirectory) ObtainLock(name string) (Lock, error) {
 	n.Lock()
 	defer n.Unlock()
@@ -98,6 +165,7 @@ func (n *NIOFSDirectory) ObtainLock(name string) (Lock, error) {
 	return n.lockFactory.ObtainLock(n, name)
 }
 
+// ListAll Returns the names of the files in the directory, files pending deletion are not listed.
 func (n *NIOFSDirectory) ListAll(context.Context) ([]string, error) {
 	n.Lock()
 	defer n.Unlock()
@@ -125,22 +193,38 @@ func (n *NIOFSDirectory) ListAll(context.Context) ([]string, error) {
 	}
 	names := make([]string, 0, len(entries))
 	for _, entry := range entries {
+		if _, ok := n.pendingDeletes[entry.Name()]; ok {
+			continue
+		}
 		names = append(names, entry.Name())
 	}
 	return names, nil
 }
 
+// DeleteFile Deletes the file, a file that can not be deleted right now is recorded as pending
+// deletion and deleted later on. Deleting a missing or pending file is a no-op, so callers may
+// safely retry.
 func (n *NIOFSDirectory) DeleteFile(ctx context.Context, name string) error {
 	n.Lock()
 	defer n.Unlock()
 
-	return n.privateDeleteFile(name)
+	if err := n.EnsureOpen(); err != nil {
+		return err
+	}
+
+	n.privateDeleteFile(name)
+	n.maybeDeletePendingFiles()
+	return nil
 }
 
 func (n *NIOFSDirectory) FileLength(ctx context.Context, name string) (int64, error) {
 	n.Lock()
 	defer n.Unlock()
 
+	if err := n.ensureCanRead(name); err != nil {
+		return 0, err
+	}
+
 	filePath := filepath.Join(n.dir, name)
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -157,6 +241,12 @@ func (n *NIOFSDirectory) CreateOutput(ctx context.Context, name string) (IndexOu
 		return nil, err
 	}
 
+	// the file is being brought back, the old one must be gone first
+	if _, ok := n.pendingDeletes[name]; ok {
+		n.privateDeleteFile(name)
+	}
+	n.maybeDeletePendingFiles()
+
 	return n.NewFSIndexOutput(name)
 }
 
@@ -168,8 +258,13 @@ func (n *NIOFSDirectory) CreateTempOutput(ctx context.Context, prefix, suffix st
 		return nil, err
 	}
 
+	n.maybeDeletePendingFiles()
+
 	for {
 		name := genTempFileName(prefix, suffix, n.nextTempFileCounter.Add(1))
+		if _, ok := n.pendingDeletes[name]; ok {
+			continue
+		}
 		output, err := n.NewFSIndexOutput(name)
 		if err != nil {
 			if errors.Is(err, fs.ErrExist) {
@@ -181,6 +276,8 @@ func (n *NIOFSDirectory) CreateTempOutput(ctx context.Context, prefix, suffix st
 	}
 }
 
+// Rename Renames source to dest, replacing dest if it exists. The rename is only durable once
+// SyncMetaData returns.
 func (n *NIOFSDirectory) Rename(ctx context.Context, source, dest string) error {
 	n.Lock()
 	defer n.Unlock()
@@ -188,11 +285,26 @@ func (n *NIOFSDirectory) Rename(ctx context.Context, source, dest string) error
 	if err := n.EnsureOpen(); err != nil {
 		return err
 	}
-	return os.Rename(n.resolveFilePath(source), n.resolveFilePath(dest))
+
+	if _, ok := n.pendingDeletes[source]; ok {
+		return fmt.Errorf("file %s is pending delete and cannot be moved: %w", source, fs.ErrNotExist)
+	}
+
+	if err := os.Rename(n.resolveFilePath(source), n.resolveFilePath(dest)); err != nil {
+		return err
+	}
+	// dest was overwritten, there is nothing left to delete
+	delete(n.pendingDeletes, dest)
+	n.maybeDeletePendingFiles()
+	return nil
 }
 
 func (n *NIOFSDirectory) Close() error {
+	n.Lock()
+	defer n.Unlock()
+
 	n.open.Store(false)
+	n.deletePendingFiles()
 	return nil
 }
 
@@ -203,24 +315,83 @@ func (n *NIOFSDirectory) EnsureOpen() error {
 	return errors.New("directory is closed")
 }
 
+// GetPendingDeletions Retries the pending deletions first, then returns the files that still
+// could not be deleted.
+func (n *NIOFSDirectory) GetPendingDeletions() (map[string]struct{}, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.deletePendingFiles()
+	return maps.Clone(n.pendingDeletes), nil
+}
+
 func (n *NIOFSDirectory) resolveFilePath(name string) string {
 	return filepath.Join(n.dir, name)
 }
 
-func (n *NIOFSDirectory) privateDeleteFile(name string) error {
-	if err := os.Remove(n.resolveFilePath(name)); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil
-		}
-		return err
+// privateDeleteFile Deletes the file, or records it as pending deletion if the filesystem
+// refuses to delete it for now. The caller must hold the lock.
+func (n *NIOFSDirectory) privateDeleteFile(name string) {
+	if err := n.removeFile(n.resolveFilePath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		n.pendingDeletes[name] = struct{}{}
+		return
+	}
+	delete(n.pendingDeletes, name)
+}
+
+// deletePendingFiles Tries to delete any pending files that we had previously tried to delete
+// but failed. The caller must hold the lock.
+func (n *NIOFSDirectory) deletePendingFiles() {
+	for name := range maps.Clone(n.pendingDeletes) {
+		n.privateDeleteFile(name)
+	}
+}
+
+// maybeDeletePendingFiles Retries the pending deletions once every len(pendingDeletes) operations.
+// The caller must hold the lock.
+func (n *NIOFSDirectory) maybeDeletePendingFiles() {
+	if len(n.pendingDeletes) == 0 {
+		return
+	}
+
+	// This is a silly heuristic to try to avoid O(N^2), where N = number of files pending deletion,
+	// behaviour on Windows.
+	count := n.opsSinceLastDelete.Add(1)
+	if count >= int64(len(n.pendingDeletes)) {
+		n.opsSinceLastDelete.Add(-count)
+		n.deletePendingFiles()
 	}
-	return nil
 }
 
 func (n *NIOFSDirectory) GetLockFactory() LockFactory {
 	return n.lockFactory
 }
 
+// fsync Ensures that the file or directory at path is written to stable storage.
+func fsync(path string, isDir bool) error {
+	if isDir && runtime.GOOS == "windows" {
+		// opening a directory for fsync is not possible on Windows, NTFS keeps its metadata
+		// consistent on its own
+		return nil
+	}
+
+	// some operating systems refuse to fsync a file that is opened read-only
+	flag := os.O_WRONLY
+	if isDir {
+		flag = os.O_RDONLY
+	}
+	file, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("fsync %s: %w", path, err)
+	}
+	return nil
+}
+
 var _ IndexOutput = &FSIndexOutput{}
 
 type FSIndexOutput struct {
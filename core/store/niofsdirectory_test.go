@@ -29,7 +29,7 @@ func TestNIOFSDirectory(t *testing.T) {
 }
 
 func TestNIOFSDirectory_ObtainLock(t *testing.T) {
-	dir, err := NewNIOFSDirectory(os.TempDir())
+	dir, err := NewNIOFSDirectoryV1(os.TempDir(), NewSimpleFSLockFactory())
 	if assert.Nil(t, err) {
 		defer dir.Close()
 	}
@@ -195,6 +195,28 @@ func TestNIOFSDirectory_OpenInput(t *testing.T) {
 	input.Close()
 }
 
+func TestNIOFSDirectory_CopyFrom(t *testing.T) {
+	ctx := context.Background()
+	from, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer from.Close()
+	to, err := NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+	defer to.Close()
+
+	writeTestFile(t, ctx, from, "x", []byte{1, 2, 3})
+	assert.Nil(t, to.CopyFrom(ctx, from, "x", "y", DEFAULT))
+
+	// the copy is made in the target directory, the source is left alone
+	assert.Equal(t, []byte{1, 2, 3}, readTestFile(t, ctx, to, "y"))
+	assert.Equal(t, []byte{1, 2, 3}, readTestFile(t, ctx, from, "x"))
+	names, err := from.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x"}, names)
+
+	assert.NotNil(t, to.CopyFrom(ctx, from, "missing", "z", DEFAULT))
+}
+
 func TestNIOFSDirectory_Rename(t *testing.T) {
 	dirPath := filepath.Join(os.TempDir(), "rename")
 
@@ -289,3 +311,125 @@ func TestNIOFSDirectory_CreateTempOutput(t *testing.T) {
 	_, err = os.Stat(file3Name)
 	assert.Nil(t, err)
 }
+
+func TestNIOFSDirectory_Sync(t *testing.T) {
+	dir, err := NewNIOFSDirectory(t.TempDir())
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+
+	output, err := dir.CreateOutput(context.Background(), "x")
+	assert.Nil(t, err)
+	_, err = output.Write([]byte{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+
+	err = dir.Sync(map[string]struct{}{"x": {}})
+	assert.Nil(t, err)
+
+	err = dir.Sync(map[string]struct{}{"missing": {}})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	err = dir.Rename(context.Background(), "x", "y")
+	assert.Nil(t, err)
+	err = dir.SyncMetaData(context.Background())
+	assert.Nil(t, err)
+
+	assert.Nil(t, dir.Close())
+	assert.NotNil(t, dir.Sync(map[string]struct{}{"y": {}}))
+	assert.NotNil(t, dir.SyncMetaData(context.Background()))
+}
+
+func TestNIOFSDirectory_PendingDeletions(t *testing.T) {
+	dirPath := t.TempDir()
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+
+	for _, name := range []string{"a", "b"} {
+		output, err := dir.CreateOutput(context.Background(), name)
+		assert.Nil(t, err)
+		assert.Nil(t, output.Close())
+	}
+
+	// the filesystem refuses to delete files for now, e.g. Windows with open files
+	refuse := true
+	dir.removeFile = func(name string) error {
+		if refuse {
+			return fs.ErrPermission
+		}
+		return os.Remove(name)
+	}
+
+	assert.Nil(t, dir.DeleteFile(context.Background(), "a"))
+	// deleting again is safe
+	assert.Nil(t, dir.DeleteFile(context.Background(), "a"))
+
+	pending, err := dir.GetPendingDeletions()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]struct{}{"a": {}}, pending)
+
+	names, err := dir.ListAll(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b"}, names)
+
+	_, err = dir.FileLength(context.Background(), "a")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = dir.OpenInput(context.Background(), "a")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	err = dir.Rename(context.Background(), "a", "c")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	// the file is still on disk
+	_, err = os.Stat(filepath.Join(dirPath, "a"))
+	assert.Nil(t, err)
+
+	refuse = false
+	pending, err = dir.GetPendingDeletions()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	_, err = os.Stat(filepath.Join(dirPath, "a"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestNIOFSDirectory_PendingDeletionsRetried(t *testing.T) {
+	dirPath := t.TempDir()
+	dir, err := NewNIOFSDirectory(dirPath)
+	if assert.Nil(t, err) {
+		defer dir.Close()
+	}
+
+	output, err := dir.CreateOutput(context.Background(), "a")
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+
+	refuse := true
+	dir.removeFile = func(name string) error {
+		if refuse {
+			return fs.ErrPermission
+		}
+		return os.Remove(name)
+	}
+	assert.Nil(t, dir.DeleteFile(context.Background(), "a"))
+	refuse = false
+
+	// any following operation retries the deletion
+	output, err = dir.CreateOutput(context.Background(), "b")
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+
+	_, err = os.Stat(filepath.Join(dirPath, "a"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Empty(t, dir.pendingDeletes)
+
+	// a pending file can be created again
+	refuse = true
+	assert.Nil(t, dir.DeleteFile(context.Background(), "b"))
+	refuse = false
+	output, err = dir.CreateOutput(context.Background(), "b")
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+	assert.Empty(t, dir.pendingDeletes)
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var _ Directory = &NRTCachingDirectory{}
+
+// NRTCachingDirectory
+// Wraps a RAMDirectory around any provided delegate directory, to be used during NRT search.
+//
+// This directory caches small newly flushed or merged segment files in RAM, so that a near-real-time
+// reader opened right after the flush does not have to read them back from disk. Files are written
+// to the cache when the IOContext carried by the context of CreateOutput (see WithIOContext) says the
+// segment is small enough:
+//
+//   - its estimated size, taken from the MergeInfo or FlushInfo, is at most maxMergeSizeMB
+//   - the cache would not grow past maxCachedMB
+//
+// Cached files are moved to the delegate when they are synced, which happens on commit, so
+// everything committed is durable.
+//
+// Here's a simple example usage:
+//
+//	fsDir, _ := store.NewMMapDirectory("/path/to/index")
+//	cachedFSDir := store.NewNRTCachingDirectory(fsDir, 5.0, 60.0)
+//	writer, _ := index.NewIndexWriter(ctx, cachedFSDir, conf)
+//
+// This will cache all newly flushed segments, all merges whose expected segment size is <= 5 MB,
+// unless the net cached bytes exceeds 60 MB at which point all writes will not be cached (until
+// the net bytes falls below 60 MB).
+type NRTCachingDirectory struct {
+	sync.Mutex
+
+	Directory
+
+	cache             *RAMDirectory
+	maxMergeSizeBytes int64
+	maxCachedBytes    int64
+
+	// held while a file moves from the cache to the delegate
+	uncacheLock sync.Mutex
+}
+
+// NewNRTCachingDirectory
+// We will cache a newly created output if 1) it's a flush or a merge and the estimated size of the
+// merged segment is <= maxMergeSizeMB, and 2) the total cached bytes is <= maxCachedMB
+func NewNRTCachingDirectory(delegate Directory, maxMergeSizeMB, maxCachedMB float64) *NRTCachingDirectory {
+	return &NRTCachingDirectory{
+		Directory:         delegate,
+		cache:             NewRAMDirectory(),
+		maxMergeSizeBytes: int64(maxMergeSizeMB * 1024 * 1024),
+		maxCachedBytes:    int64(maxCachedMB * 1024 * 1024),
+	}
+}
+
+// GetDelegate
+// Returns the directory files end up in once they are synced
+func (n *NRTCachingDirectory) GetDelegate() Directory {
+	return n.Directory
+}
+
+// ListCachedFiles
+// Returns the names of the files currently held in RAM
+func (n *NRTCachingDirectory) ListCachedFiles() ([]string, error) {
+	return n.cache.ListAll(context.Background())
+}
+
+// RamBytesUsed
+// Returns how many bytes are being used by the RAMDirectory cache
+func (n *NRTCachingDirectory) RamBytesUsed() int64 {
+	return n.cache.RamBytesUsed()
+}
+
+func (n *NRTCachingDirectory) ListAll(ctx context.Context) ([]string, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	cached, err := n.cache.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := n.Directory.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := append(cached, files...)
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+func (n *NRTCachingDirectory) DeleteFile(ctx context.Context, name string) error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isCached(name) {
+		return n.cache.DeleteFile(ctx, name)
+	}
+	return n.Directory.DeleteFile(ctx, name)
+}
+
+func (n *NRTCachingDirectory) FileLength(ctx context.Context, name string) (int64, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isCached(name) {
+		return n.cache.FileLength(ctx, name)
+	}
+	return n.Directory.FileLength(ctx, name)
+}
+
+func (n *NRTCachingDirectory) CreateOutput(ctx context.Context, name string) (IndexOutput, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.doCacheWrite(name, GetIOContext(ctx)) {
+		// This is fine: file may not exist
+		_ = n.Directory.DeleteFile(ctx, name)
+		return n.cache.CreateOutput(ctx, name)
+	}
+
+	if n.isCached(name) {
+		_ = n.cache.DeleteFile(ctx, name)
+	}
+	return n.Directory.CreateOutput(ctx, name)
+}
+
+func (n *NRTCachingDirectory) CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	first, second := Directory(n.Directory), Directory(n.cache)
+	if n.doCacheWrite(prefix, GetIOContext(ctx)) {
+		first, second = n.cache, n.Directory
+	}
+
+	// the temp file name must not exist in the other directory either
+	for {
+		output, err := first.CreateTempOutput(ctx, prefix, suffix)
+		if err != nil {
+			return nil, err
+		}
+		exists, err := fileExists(ctx, second, output.GetName())
+		if err != nil {
+			return nil, errors.Join(err, output.Close(), first.DeleteFile(ctx, output.GetName()))
+		}
+		if !exists {
+			return output, nil
+		}
+		if err := errors.Join(output.Close(), first.DeleteFile(ctx, output.GetName())); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Sync Moves the cached files among names to the delegate before syncing them
+func (n *NRTCachingDirectory) Sync(names map[string]struct{}) error {
+	for name := range names {
+		if err := n.unCache(context.Background(), name); err != nil {
+			return err
+		}
+	}
+	return n.Directory.Sync(names)
+}
+
+func (n *NRTCachingDirectory) Rename(ctx context.Context, source, dest string) error {
+	if err := n.unCache(ctx, source); err != nil {
+		return err
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isCached(dest) {
+		return fmt.Errorf("target file %s already exists: %w", dest, fs.ErrExist)
+	}
+	return n.Directory.Rename(ctx, source, dest)
+}
+
+func (n *NRTCachingDirectory) OpenInput(ctx context.Context, name string) (IndexInput, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isCached(name) {
+		return n.cache.OpenInput(ctx, name)
+	}
+	return n.Directory.OpenInput(ctx, name)
+}
+
+// Close this directory, which flushes any cached files to the delegate and then closes the delegate.
+func (n *NRTCachingDirectory) Close() error {
+	// NOTE: technically we shouldn't have to do this, ie,
+	// IndexWriter should have sync'd all files, but we do
+	// it for defensive reasons... or in case the app is
+	// doing something custom (creating outputs directly w/o
+	// using IndexWriter):
+	names, err := n.cache.ListAll(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := n.unCache(context.Background(), name); err != nil {
+			return err
+		}
+	}
+	return errors.Join(n.cache.Close(), n.Directory.Close())
+}
+
+// doCacheWrite Returns true if this file should be written to the RAMDirectory.
+func (n *NRTCachingDirectory) doCacheWrite(name string, ioContext *IOContext) bool {
+	// commits are published through the delegate right away
+	if strings.HasPrefix(name, "segments") || strings.HasPrefix(name, "pending_segments") {
+		return false
+	}
+
+	bytes := int64(0)
+	if ioContext.MergeInfo != nil {
+		bytes = int64(ioContext.MergeInfo.EstimatedMergeBytes)
+	} else if ioContext.FlushInfo != nil {
+		bytes = ioContext.FlushInfo.EstimatedSegmentSize
+	}
+
+	return bytes <= n.maxMergeSizeBytes && bytes+n.cache.RamBytesUsed() <= n.maxCachedBytes
+}
+
+// isCached The caller must hold the lock
+func (n *NRTCachingDirectory) isCached(name string) bool {
+	_, err := n.cache.FileLength(context.Background(), name)
+	return err == nil
+}
+
+// unCache Copies the file from the cache to the delegate, then drops it from the cache
+func (n *NRTCachingDirectory) unCache(ctx context.Context, name string) error {
+	// Only let one thread uncache at a time; this only
+	// happens during commit() or close():
+	n.uncacheLock.Lock()
+	defer n.uncacheLock.Unlock()
+
+	n.Lock()
+	cached := n.isCached(name)
+	n.Unlock()
+	if !cached {
+		// Another thread beat us...
+		return nil
+	}
+
+	in, err := n.cache.OpenInput(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := n.Directory.CreateOutput(ctx, name)
+	if err != nil {
+		return fmt.Errorf("cannot uncache file %s: %w", name, err)
+	}
+	if err := out.CopyBytes(ctx, in, int(in.Length())); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	n.Lock()
+	defer n.Unlock()
+	return n.cache.DeleteFile(ctx, name)
+}
+
+// fileExists Returns true if the file exists in the directory
+func fileExists(ctx context.Context, dir Directory, name string) (bool, error) {
+	if _, err := dir.FileLength(ctx, name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, ctx context.Context, dir Directory, name string, data []byte) {
+	output, err := dir.CreateOutput(ctx, name)
+	assert.Nil(t, err)
+	_, err = output.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, output.Close())
+}
+
+func readTestFile(t *testing.T, ctx context.Context, dir Directory, name string) []byte {
+	input, err := dir.OpenInput(ctx, name)
+	assert.Nil(t, err)
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	assert.Nil(t, err)
+	return data
+}
+
+func TestNRTCachingDirectory(t *testing.T) {
+	dirPath := t.TempDir()
+	delegate, err := NewNIOFSDirectory(dirPath)
+	assert.Nil(t, err)
+
+	dir := NewNRTCachingDirectory(delegate, 1, 2)
+
+	ctx := context.Background()
+	flushCtx := WithIOContext(ctx, NewIOContext(WithFlushInfo(NewFlushInfo(10, 4096))))
+
+	data := bytes.Repeat([]byte("0123456789"), 300)
+	writeTestFile(t, flushCtx, dir, "_0.cfs", data)
+
+	cached, err := dir.ListCachedFiles()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.cfs"}, cached)
+	assert.True(t, dir.RamBytesUsed() >= int64(len(data)))
+
+	// not on disk yet
+	_, err = os.Stat(filepath.Join(dirPath, "_0.cfs"))
+	assert.NotNil(t, err)
+
+	size, err := dir.FileLength(ctx, "_0.cfs")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(data)), size)
+	assert.Equal(t, data, readTestFile(t, ctx, dir, "_0.cfs"))
+
+	// segments files always go to the delegate
+	writeTestFile(t, flushCtx, dir, "segments_1", []byte{1, 2, 3})
+	_, err = os.Stat(filepath.Join(dirPath, "segments_1"))
+	assert.Nil(t, err)
+
+	files, err := dir.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_0.cfs", "segments_1"}, files)
+
+	// sync moves the file to the delegate
+	assert.Nil(t, dir.Sync(map[string]struct{}{"_0.cfs": {}}))
+	cached, err = dir.ListCachedFiles()
+	assert.Nil(t, err)
+	assert.Empty(t, cached)
+	assert.Equal(t, int64(0), dir.RamBytesUsed())
+
+	onDisk, err := os.ReadFile(filepath.Join(dirPath, "_0.cfs"))
+	assert.Nil(t, err)
+	assert.Equal(t, data, onDisk)
+	assert.Equal(t, data, readTestFile(t, ctx, dir, "_0.cfs"))
+
+	assert.Nil(t, dir.DeleteFile(ctx, "_0.cfs"))
+	files, err = dir.ListAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"segments_1"}, files)
+
+	assert.Nil(t, dir.Close())
+}
+
+func TestNRTCachingDirectory_TooLarge(t *testing.T) {
+	dirPath := t.TempDir()
+	delegate, err := NewNIOFSDirectory(dirPath)
+	assert.Nil(t, err)
+
+	dir := NewNRTCachingDirectory(delegate, 1, 2)
+	defer dir.Close()
+
+	ctx := context.Background()
+
+	// the merged segment is larger than maxMergeSizeMB
+	mergeCtx := WithIOContext(ctx, NewIOContext(WithMergeInfo(NewMergeInfo(100, 2*1024*1024, false, -1))))
+	writeTestFile(t, mergeCtx, dir, "_1.cfs", []byte{1, 2, 3})
+
+	cached, err := dir.ListCachedFiles()
+	assert.Nil(t, err)
+	assert.Empty(t, cached)
+	_, err = os.Stat(filepath.Join(dirPath, "_1.cfs"))
+	assert.Nil(t, err)
+
+	// the cache would grow past maxCachedMB
+	flushCtx := WithIOContext(ctx, NewIOContext(WithFlushInfo(NewFlushInfo(10, 1024*1024))))
+	writeTestFile(t, flushCtx, dir, "_2.cfs", make([]byte, 1024*1024+10))
+	writeTestFile(t, flushCtx, dir, "_3.cfs", []byte{1, 2, 3})
+
+	cached, err = dir.ListCachedFiles()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_2.cfs"}, cached)
+}
+
+func TestNRTCachingDirectory_Close(t *testing.T) {
+	dirPath := t.TempDir()
+	delegate, err := NewNIOFSDirectory(dirPath)
+	assert.Nil(t, err)
+
+	dir := NewNRTCachingDirectory(delegate, 1, 2)
+
+	ctx := WithIOContext(context.Background(), NewIOContext(WithFlushInfo(NewFlushInfo(1, 10))))
+	writeTestFile(t, ctx, dir, "_0.si", []byte("segment info"))
+
+	// rename moves the source to the delegate first
+	assert.Nil(t, dir.Rename(ctx, "_0.si", "_1.si"))
+	onDisk, err := os.ReadFile(filepath.Join(dirPath, "_1.si"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("segment info"), onDisk)
+
+	writeTestFile(t, ctx, dir, "_2.si", []byte("other segment"))
+	assert.Nil(t, dir.Close())
+
+	// cached files are written to the delegate on close
+	onDisk, err = os.ReadFile(filepath.Join(dirPath, "_2.si"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("other segment"), onDisk)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -34,12 +35,39 @@ func (d *RAMDirectory) Sync(files map[string]struct{}) error {
 	return nil
 }
 
+func (d *RAMDirectory) SyncMetaData(ctx context.Context) error {
+	return nil
+}
+
+func (d *RAMDirectory) GetPendingDeletions() (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
 func (d *RAMDirectory) ListAll(ctx context.Context) ([]string, error) {
+	d.RLock()
+	defer d.RUnlock()
+
 	files := maps.Keys(d.fileMap)
+	slices.Sort(files)
 	return files, nil
 }
 
+// RamBytesUsed Returns the total size in bytes of all the files in this directory.
+func (d *RAMDirectory) RamBytesUsed() int64 {
+	d.RLock()
+	defer d.RUnlock()
+
+	size := int64(0)
+	for _, file := range d.fileMap {
+		size += file.GetLength()
+	}
+	return size
+}
+
 func (d *RAMDirectory) DeleteFile(ctx context.Context, name string) error {
+	d.Lock()
+	defer d.Unlock()
+
 	if _, ok := d.fileMap[name]; !ok {
 		return os.ErrNotExist
 	}
@@ -48,6 +76,9 @@ func (d *RAMDirectory) DeleteFile(ctx context.Context, name string) error {
 }
 
 func (d *RAMDirectory) FileLength(ctx context.Context, name string) (int64, error) {
+	d.RLock()
+	defer d.RUnlock()
+
 	file, ok := d.fileMap[name]
 	if !ok {
 		return 0, os.ErrNotExist
@@ -56,6 +87,9 @@ func (d *RAMDirectory) FileLength(ctx context.Context, name string) (int64, erro
 }
 
 func (d *RAMDirectory) CreateOutput(ctx context.Context, name string) (IndexOutput, error) {
+	d.Lock()
+	defer d.Unlock()
+
 	if _, ok := d.fileMap[name]; ok {
 		return nil, os.ErrExist
 	}
@@ -66,6 +100,9 @@ func (d *RAMDirectory) CreateOutput(ctx context.Context, name string) (IndexOutp
 }
 
 func (d *RAMDirectory) CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error) {
+	d.Lock()
+	defer d.Unlock()
+
 	// Make the file first...
 	file := NewRAMFile(d)
 
@@ -115,6 +152,9 @@ func (d *RAMDirectory) ObtainLock(name string) (Lock, error) {
 }
 
 func (d *RAMDirectory) Close() error {
+	d.Lock()
+	defer d.Unlock()
+
 	clear(d.fileMap)
 	return nil
 }
@@ -1,127 +1,104 @@
 package store
 
 import (
+	"fmt"
 	"io"
-	"slices"
 )
 
 var _ IndexInput = &RAMInputStream{}
 
+// RAMInputStream
+// A memory-resident IndexInput implementation. The buffers of the RAMFile are expected to hold
+// RAM_BUFFER_SIZE bytes each, except the last one, which is how RAMOutputStream writes them.
 type RAMInputStream struct {
 	*BaseIndexInput
 
-	file               *RAMFile
-	length             int
-	currentBuffer      []byte
-	currentBufferIndex int
-	bufferLength       int
-	bufferPosition     int
+	name string
+	file *RAMFile
+
+	// off, end and pos are absolute positions in the file
+	off int64
+	end int64
+	pos int64
 }
 
 func NewRAMInputStream(name string, file *RAMFile, length int) (*RAMInputStream, error) {
-	stream := &RAMInputStream{
-		file:   file,
-		length: length,
+	if int64(length) > file.GetLength() {
+		return nil, fmt.Errorf("length %d exceeds the file size %d: %s", length, file.GetLength(), name)
 	}
+	return newRAMInputStream(name, file, 0, int64(length)), nil
+}
 
-	stream.BaseIndexInput = NewBaseIndexInput(stream)
-	if err := stream.setCurrentBuffer(); err != nil {
-		return nil, err
+func newRAMInputStream(name string, file *RAMFile, off, end int64) *RAMInputStream {
+	stream := &RAMInputStream{
+		name: name,
+		file: file,
+		off:  off,
+		end:  end,
+		pos:  off,
 	}
-	return stream, nil
+	stream.BaseIndexInput = NewBaseIndexInput(stream)
+	return stream
 }
 
 func (s *RAMInputStream) Read(p []byte) (n int, err error) {
-	size := len(p)
-	dstIdx := 0
-
-	for size > 0 {
-		if s.bufferPosition == s.bufferLength {
-			if err := s.nextBuffer(); err != nil {
-				return 0, err
-			}
-		}
-
-		buffSize := s.bufferLength - s.bufferPosition
-		if buffSize > size {
-			copy(p[dstIdx:], s.currentBuffer[s.bufferPosition:])
-			break
-		}
-
-		size -= buffSize
-		dstIdx += buffSize
+	if s.pos >= s.end {
+		return 0, io.EOF
 	}
-	return len(p), nil
-}
 
-func (s *RAMInputStream) nextBuffer() error {
-	buff, ok := s.file.GetBuffer(s.currentBufferIndex)
-	if !ok {
-		return io.EOF
+	size := int(min(int64(len(p)), s.end-s.pos))
+	for n < size {
+		buffer, ok := s.file.GetBuffer(int(s.pos / RAM_BUFFER_SIZE))
+		if !ok {
+			return n, io.ErrUnexpectedEOF
+		}
+		copied := copy(p[n:size], buffer[s.pos%RAM_BUFFER_SIZE:])
+		n += copied
+		s.pos += int64(copied)
 	}
-	s.currentBuffer = buff
-	s.bufferPosition = 0
-	s.bufferLength = len(buff)
-	return nil
+	return n, nil
 }
 
 func (s *RAMInputStream) Clone() CloneReader {
-	stream := &RAMInputStream{
-		file:               s.file.Clone(),
-		currentBuffer:      slices.Clone(s.currentBuffer),
-		currentBufferIndex: s.currentBufferIndex,
-		bufferLength:       s.bufferLength,
-		bufferPosition:     s.bufferPosition,
-	}
-	stream.BaseIndexInput = NewBaseIndexInput(stream)
+	stream := newRAMInputStream(s.name, s.file, s.off, s.end)
+	stream.pos = s.pos
 	return stream
 }
 
 func (s *RAMInputStream) Seek(offset int64, whence int) (int64, error) {
-
-	newBufferIndex := int(offset / RAM_BUFFER_SIZE)
-
-	if newBufferIndex != s.currentBufferIndex {
-		s.currentBufferIndex = newBufferIndex
-		if err := s.setCurrentBuffer(); err != nil {
-			return 0, err
-		}
+	nextPos := int64(0)
+
+	switch whence {
+	case io.SeekStart:
+		nextPos = s.off + offset
+	case io.SeekCurrent:
+		nextPos = s.pos + offset
+	case io.SeekEnd:
+		nextPos = s.end - offset
 	}
 
-	s.bufferPosition = int(offset % RAM_BUFFER_SIZE)
-
 	// This is not >= because seeking to exact end of file is OK: this is where
 	// you'd also be if you did a readBytes of all bytes in the file
-	if s.GetFilePointer() > s.Length() {
+	if nextPos < s.off || nextPos > s.end {
 		return 0, io.ErrUnexpectedEOF
 	}
-	return offset, nil
+
+	s.pos = nextPos
+	return s.pos - s.off, nil
 }
 
 func (s *RAMInputStream) GetFilePointer() int64 {
-	return int64(RAM_BUFFER_SIZE*s.currentBufferIndex + s.bufferPosition)
+	return s.pos - s.off
 }
 
 func (s *RAMInputStream) Slice(sliceDescription string, offset, length int64) (IndexInput, error) {
-	//TODO implement me
-	panic("implement me")
+	if offset < 0 || length < 0 || offset+length > s.Length() {
+		return nil, fmt.Errorf("slice() %s out of bounds: offset=%d,length=%d,fileLength=%d: %s",
+			sliceDescription, offset, length, s.Length(), s.name)
+	}
+	return newRAMInputStream(s.name+" [slice="+sliceDescription+"]", s.file, s.off+offset, s.off+offset+length), nil
 }
 
 func (s *RAMInputStream) Length() int64 {
-	return s.file.GetLength()
-}
-
-func (s *RAMInputStream) setCurrentBuffer() error {
-	if s.currentBufferIndex < s.file.NumBuffers() {
-		buffer, ok := s.file.GetBuffer(s.currentBufferIndex)
-		if !ok {
-			return io.ErrUnexpectedEOF
-		}
-		s.currentBuffer = buffer
-		bufferStart := RAM_BUFFER_SIZE * s.currentBufferIndex
-		s.bufferLength = min(RAM_BUFFER_SIZE, s.length-bufferStart)
-	} else {
-		s.currentBuffer = nil
-	}
-	return nil
+	return s.end - s.off
 }
@@ -48,6 +48,7 @@ func (s *RAMOutputStream) Close() error {
 
 func (s *RAMOutputStream) flush() {
 	s.file.Write(s.buffer.Bytes())
+	s.buffer.Reset()
 }
 
 func (s *RAMOutputStream) Write(p []byte) (n int, err error) {
@@ -109,21 +109,21 @@ func (b *BytesRandomAccessInput) ReadU8(pos int64) (byte, error) {
 }
 
 func (b *BytesRandomAccessInput) ReadU16(pos int64) (uint16, error) {
-	if pos+2 >= int64(len(b.bs)) {
+	if pos+2 > int64(len(b.bs)) {
 		return 0, io.ErrUnexpectedEOF
 	}
 	return b.byteOrder.Uint16(b.bs[pos:]), nil
 }
 
 func (b *BytesRandomAccessInput) ReadU32(pos int64) (uint32, error) {
-	if pos+4 >= int64(len(b.bs)) {
+	if pos+4 > int64(len(b.bs)) {
 		return 0, io.ErrUnexpectedEOF
 	}
 	return b.byteOrder.Uint32(b.bs[pos:]), nil
 }
 
 func (b *BytesRandomAccessInput) ReadU64(pos int64) (uint64, error) {
-	if pos+8 >= int64(len(b.bs)) {
+	if pos+8 > int64(len(b.bs)) {
 		return 0, io.ErrUnexpectedEOF
 	}
 	return b.byteOrder.Uint64(b.bs[pos:]), nil
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var _ Directory = &RateLimitedDirectoryWrapper{}
+
+// RateLimitedDirectoryWrapper
+// A Directory wrapper that allows IndexOutput rate limiting using IO context specific rate limiters.
+//
+// The IOContext of an output is the one carried by the context passed to CreateOutput, see
+// WithIOContext. IndexWriter sets it for flushes (CONTEXT_FLUSH) and merges (CONTEXT_MERGE), so
+// merges can be throttled to leave IO bandwidth to searches while flushes run at full speed.
+//
+// Limits can be changed at any time. Changing the rate of a context that is already limited also
+// applies to the outputs that are open, on their next pause check.
+//
+// See Also: SetRateLimiter, SetMaxWriteMBPerSec
+type RateLimitedDirectoryWrapper struct {
+	Directory
+
+	sync.RWMutex
+
+	contextRateLimiters map[ContextType]RateLimiter
+}
+
+func NewRateLimitedDirectoryWrapper(wrapped Directory) *RateLimitedDirectoryWrapper {
+	return &RateLimitedDirectoryWrapper{
+		Directory:           wrapped,
+		contextRateLimiters: make(map[ContextType]RateLimiter),
+	}
+}
+
+// GetDelegate Returns the wrapped Directory
+func (r *RateLimitedDirectoryWrapper) GetDelegate() Directory {
+	return r.Directory
+}
+
+func (r *RateLimitedDirectoryWrapper) CreateOutput(ctx context.Context, name string) (IndexOutput, error) {
+	output, err := r.Directory.CreateOutput(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.wrapOutput(ctx, output), nil
+}
+
+func (r *RateLimitedDirectoryWrapper) CreateTempOutput(ctx context.Context, prefix, suffix string) (IndexOutput, error) {
+	output, err := r.Directory.CreateTempOutput(ctx, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	return r.wrapOutput(ctx, output), nil
+}
+
+// CopyFrom Copies through CreateOutput, so that the copy is rate limited as well
+func (r *RateLimitedDirectoryWrapper) CopyFrom(ctx context.Context, from Directory, src, dest string, ioContext *IOContext) error {
+	return CopyFrom(ctx, r, from, src, dest, ioContext)
+}
+
+func (r *RateLimitedDirectoryWrapper) wrapOutput(ctx context.Context, output IndexOutput) IndexOutput {
+	limiter := r.GetRateLimiter(GetIOContext(ctx).Type)
+	if limiter == nil {
+		return output
+	}
+	return NewRateLimitedIndexOutput(limiter, output)
+}
+
+// GetRateLimiter Returns the RateLimiter of the given context, nil if the context is not rate limited
+func (r *RateLimitedDirectoryWrapper) GetRateLimiter(context ContextType) RateLimiter {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.contextRateLimiters[context]
+}
+
+// SetMaxWriteMBPerSec
+// Sets the maximum (approx) MB/sec allowed by all write IO performed by IndexOutput created with
+// the given context. Pass 0 to have no limit.
+//
+// NOTE: an IndexOutput created while the context had no limit stays unlimited, and one created
+// before the limit is removed keeps being limited.
+func (r *RateLimitedDirectoryWrapper) SetMaxWriteMBPerSec(mbPerSec float64, context ContextType) error {
+	if mbPerSec < 0 || math.IsNaN(mbPerSec) {
+		return fmt.Errorf("mbPerSec must be positive or 0, got %f", mbPerSec)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if mbPerSec == 0 {
+		delete(r.contextRateLimiters, context)
+		return nil
+	}
+
+	if limiter, ok := r.contextRateLimiters[context]; ok {
+		return limiter.SetMBPerSec(mbPerSec)
+	}
+
+	limiter, err := NewSimpleRateLimiter(mbPerSec)
+	if err != nil {
+		return err
+	}
+	r.contextRateLimiters[context] = limiter
+	return nil
+}
+
+// SetRateLimiter
+// Sets the rate limiter to be used to limit (approx) MB/sec allowed by all IO performed with the
+// given context. Pass nil to have no limit.
+//
+// Passing an instance of rate limiter compared to setting it using SetMaxWriteMBPerSec allows to
+// use the same limiter instance across several directories globally limiting IO across them.
+func (r *RateLimitedDirectoryWrapper) SetRateLimiter(limiter RateLimiter, context ContextType) {
+	r.Lock()
+	defer r.Unlock()
+
+	if limiter == nil {
+		delete(r.contextRateLimiters, context)
+		return
+	}
+	r.contextRateLimiters[context] = limiter
+}
+
+// GetMaxWriteMBPerSec
+// See Also: SetMaxWriteMBPerSec. Returns 0 if the context is not rate limited.
+func (r *RateLimitedDirectoryWrapper) GetMaxWriteMBPerSec(context ContextType) float64 {
+	limiter := r.GetRateLimiter(context)
+	if limiter == nil {
+		return 0
+	}
+	return limiter.GetMBPerSec()
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedDirectoryWrapper(t *testing.T) {
+	dir := NewRateLimitedDirectoryWrapper(NewRAMDirectory())
+	defer dir.Close()
+
+	assert.NotNil(t, dir.SetMaxWriteMBPerSec(-1, CONTEXT_MERGE))
+	assert.Nil(t, dir.SetMaxWriteMBPerSec(10, CONTEXT_MERGE))
+	assert.Equal(t, 10.0, dir.GetMaxWriteMBPerSec(CONTEXT_MERGE))
+	assert.Equal(t, 0.0, dir.GetMaxWriteMBPerSec(CONTEXT_FLUSH))
+
+	ctx := context.Background()
+	mergeCtx := WithIOContext(ctx, NewIOContext(WithMergeInfo(NewMergeInfo(10, 1024*1024, false, -1))))
+	flushCtx := WithIOContext(ctx, NewIOContext(WithFlushInfo(NewFlushInfo(10, 1024*1024))))
+
+	output, err := dir.CreateOutput(flushCtx, "_0.fdt")
+	assert.Nil(t, err)
+	assert.IsType(t, &RAMOutputStream{}, output)
+	assert.Nil(t, output.Close())
+
+	// 1 MB at 10 MB/sec must take about 100 msec
+	output, err = dir.CreateOutput(mergeCtx, "_1.fdt")
+	assert.Nil(t, err)
+	assert.IsType(t, &RateLimitedIndexOutput{}, output)
+
+	data := make([]byte, 4096)
+	start := time.Now()
+	for i := 0; i < 256; i++ {
+		_, err := output.Write(data)
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	assert.Nil(t, output.Close())
+
+	size, err := dir.FileLength(ctx, "_1.fdt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(256*4096), size)
+
+	// the limiter is updated in place
+	limiter := dir.GetRateLimiter(CONTEXT_MERGE)
+	assert.Nil(t, dir.SetMaxWriteMBPerSec(20, CONTEXT_MERGE))
+	assert.Same(t, limiter, dir.GetRateLimiter(CONTEXT_MERGE))
+	assert.Equal(t, 20.0, limiter.GetMBPerSec())
+
+	// copies are limited with the given context
+	assert.Nil(t, dir.SetMaxWriteMBPerSec(10, CONTEXT_MERGE))
+	start = time.Now()
+	assert.Nil(t, dir.CopyFrom(ctx, dir, "_1.fdt", "_2.fdt", NewIOContext(WithMergeInfo(NewMergeInfo(10, 1024*1024, false, -1)))))
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	size, err = dir.FileLength(ctx, "_2.fdt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(256*4096), size)
+
+	assert.Nil(t, dir.SetMaxWriteMBPerSec(0, CONTEXT_MERGE))
+	assert.Nil(t, dir.GetRateLimiter(CONTEXT_MERGE))
+
+	// a shared limiter
+	shared, err := NewSimpleRateLimiter(5)
+	assert.Nil(t, err)
+	dir.SetRateLimiter(shared, CONTEXT_FLUSH)
+	assert.Equal(t, 5.0, dir.GetMaxWriteMBPerSec(CONTEXT_FLUSH))
+	dir.SetRateLimiter(nil, CONTEXT_FLUSH)
+	assert.Equal(t, 0.0, dir.GetMaxWriteMBPerSec(CONTEXT_FLUSH))
+}
@@ -0,0 +1,60 @@
+package store
+
+var _ IndexOutput = &RateLimitedIndexOutput{}
+
+// RateLimitedIndexOutput
+// A rate limiting IndexOutput
+type RateLimitedIndexOutput struct {
+	*BaseIndexOutput
+
+	delegate    IndexOutput
+	rateLimiter RateLimiter
+
+	// How many bytes we've written since we last called rateLimiter.Pause.
+	bytesSinceLastPause int64
+
+	// Cached here to not always have to call RateLimiter.GetMinPauseCheckBytes() which does
+	// locking, to check if we should pause.
+	currentMinPauseCheckBytes int64
+}
+
+func NewRateLimitedIndexOutput(rateLimiter RateLimiter, delegate IndexOutput) *RateLimitedIndexOutput {
+	output := &RateLimitedIndexOutput{
+		delegate:                  delegate,
+		rateLimiter:               rateLimiter,
+		currentMinPauseCheckBytes: rateLimiter.GetMinPauseCheckBytes(),
+	}
+	output.BaseIndexOutput = NewBaseIndexOutput(delegate.GetName(), output)
+	return output
+}
+
+func (r *RateLimitedIndexOutput) Write(b []byte) (int, error) {
+	r.bytesSinceLastPause += int64(len(b))
+	if err := r.checkRate(); err != nil {
+		return 0, err
+	}
+	return r.delegate.Write(b)
+}
+
+func (r *RateLimitedIndexOutput) Close() error {
+	return r.delegate.Close()
+}
+
+func (r *RateLimitedIndexOutput) GetFilePointer() int64 {
+	return r.delegate.GetFilePointer()
+}
+
+func (r *RateLimitedIndexOutput) GetChecksum() (uint32, error) {
+	return r.delegate.GetChecksum()
+}
+
+func (r *RateLimitedIndexOutput) checkRate() error {
+	if r.bytesSinceLastPause > r.currentMinPauseCheckBytes {
+		if _, err := r.rateLimiter.Pause(r.bytesSinceLastPause); err != nil {
+			return err
+		}
+		r.bytesSinceLastPause = 0
+		r.currentMinPauseCheckBytes = r.rateLimiter.GetMinPauseCheckBytes()
+	}
+	return nil
+}
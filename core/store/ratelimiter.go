@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter
+// Abstract base class to rate limit IO. Typically implementations are shared across multiple
+// IndexInputs or IndexOutputs (for example those involved all merging). Those IndexInputs and
+// IndexOutputs would call Pause whenever they have read or written more than
+// GetMinPauseCheckBytes bytes.
+type RateLimiter interface {
+	// SetMBPerSec
+	// Sets an updated MB per second rate limit.
+	SetMBPerSec(mbPerSec float64) error
+
+	// GetMBPerSec
+	// The current MB per second rate limit.
+	GetMBPerSec() float64
+
+	// Pause
+	// Pauses, if necessary, to keep the instantaneous IO rate at or below the target.
+	// Note: the implementation is thread-safe
+	// Returns: the pause time
+	Pause(bytes int64) (time.Duration, error)
+
+	// GetMinPauseCheckBytes
+	// How many bytes caller should add up itself before invoking pause.
+	GetMinPauseCheckBytes() int64
+}
+
+const (
+	// MIN_PAUSE_CHECK_MSEC
+	// the minimum interval in milliseconds between two pause checks of a SimpleRateLimiter
+	MIN_PAUSE_CHECK_MSEC = 5
+)
+
+var _ RateLimiter = &SimpleRateLimiter{}
+
+// SimpleRateLimiter
+// Simple class to rate limit IO.
+type SimpleRateLimiter struct {
+	sync.Mutex
+
+	mbPerSec           float64
+	minPauseCheckBytes int64
+	lastNS             int64
+}
+
+// NewSimpleRateLimiter
+// mbPerSec is the MB/sec max IO rate
+func NewSimpleRateLimiter(mbPerSec float64) (*SimpleRateLimiter, error) {
+	limiter := &SimpleRateLimiter{
+		lastNS: time.Now().UnixNano(),
+	}
+	if err := limiter.SetMBPerSec(mbPerSec); err != nil {
+		return nil, err
+	}
+	return limiter, nil
+}
+
+func (s *SimpleRateLimiter) SetMBPerSec(mbPerSec float64) error {
+	if mbPerSec <= 0 || math.IsNaN(mbPerSec) {
+		return errors.New("mbPerSec must be positive")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.mbPerSec = mbPerSec
+	s.minPauseCheckBytes = int64((MIN_PAUSE_CHECK_MSEC / 1000.0) * mbPerSec * 1024 * 1024)
+	return nil
+}
+
+func (s *SimpleRateLimiter) GetMBPerSec() float64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.mbPerSec
+}
+
+func (s *SimpleRateLimiter) GetMinPauseCheckBytes() int64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.minPauseCheckBytes
+}
+
+// Pause
+// Pauses, if necessary, to keep the instantaneous IO rate at or below the target. Be sure to only
+// call this method when bytes > GetMinPauseCheckBytes, otherwise it will pause way too long!
+func (s *SimpleRateLimiter) Pause(bytes int64) (time.Duration, error) {
+	startNS := time.Now().UnixNano()
+
+	s.Lock()
+	secondsToPause := float64(bytes) / 1024.0 / 1024.0 / s.mbPerSec
+
+	// Time we should sleep until; this is purely instantaneous
+	// rate (just adds seconds onto the last time we had paused to);
+	// maybe we should also offer decayed recent history one?
+	targetNS := s.lastNS + int64(1000000000*secondsToPause)
+
+	if startNS >= targetNS {
+		// OK, current time is already beyond the target sleep time,
+		// no pausing to do.
+
+		// Set to startNS, not targetNS, to enforce the instant rate, not
+		// the "averaged over all history" rate:
+		s.lastNS = startNS
+		s.Unlock()
+		return 0, nil
+	}
+	s.lastNS = targetNS
+	s.Unlock()
+
+	pause := time.Duration(targetNS - startNS)
+	time.Sleep(pause)
+	return pause, nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleRateLimiter(t *testing.T) {
+	_, err := NewSimpleRateLimiter(0)
+	assert.NotNil(t, err)
+
+	limiter, err := NewSimpleRateLimiter(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, limiter.GetMBPerSec())
+	assert.Equal(t, int64(52428), limiter.GetMinPauseCheckBytes())
+
+	// 1 MB at 10 MB/sec must take about 100 msec
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		_, err := limiter.Pause(1024 * 1024 / 10)
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+}
+
+func TestRateLimitedIndexOutput(t *testing.T) {
+	limiter, err := NewSimpleRateLimiter(10)
+	assert.Nil(t, err)
+
+	delegate := NewOutputStream("test", newMockWriter())
+	output := NewRateLimitedIndexOutput(limiter, delegate)
+	assert.Equal(t, "test", output.GetName())
+
+	data := make([]byte, 4096)
+	start := time.Now()
+	for i := 0; i < 256; i++ {
+		_, err := output.Write(data)
+		assert.Nil(t, err)
+	}
+	err = output.WriteUint32(context.Background(), 1)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(256*4096+4), output.GetFilePointer())
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	assert.Nil(t, output.Close())
+}
@@ -1,10 +1,18 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Explanation interface {
 	IsMatch() bool
 	GetValue() any
 	GetDescription() string
 	GetDetails() []Explanation
+
+	// String renders the explanation tree, one node per line.
+	String() string
 }
 
 // Explanation
@@ -64,3 +72,20 @@ func (e *explanation) GetDescription() string {
 func (e *explanation) GetDetails() []Explanation {
 	return e.details
 }
+
+// String
+// Render an explanation as text, every node is printed as "value = description" on its own line,
+// with its details indented below it.
+func (e *explanation) String() string {
+	sb := new(strings.Builder)
+	writeExplanation(sb, e, 0)
+	return sb.String()
+}
+
+func writeExplanation(sb *strings.Builder, e Explanation, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(fmt.Sprintf("%v = %s\n", e.GetValue(), e.GetDescription()))
+	for _, detail := range e.GetDetails() {
+		writeExplanation(sb, detail, depth+1)
+	}
+}
@@ -2,9 +2,11 @@ package automaton
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/bits-and-blooms/bitset"
 	"github.com/geange/lucene-go/core/util/array"
-	"sort"
 )
 
 // Automaton Represents an automaton and all its states and transitions. States are integers and must be
@@ -165,18 +167,8 @@ func (r *Automaton) Copy(other *Automaton) {
 	r.nextState += other.nextState
 	otherNumStates := other.GetNumStates()
 	otherAcceptStates := other.getAcceptStates()
-	state := uint(0)
-
-	for {
-		if state < uint(otherNumStates) {
-			if state, ok := otherAcceptStates.NextSet(state); ok {
-				r.SetAccept(stateOffset+int(state), true)
-				state++
-				continue
-			}
-		}
-
-		break
+	for state, ok := otherAcceptStates.NextSet(0); ok && state < uint(otherNumStates); state, ok = otherAcceptStates.NextSet(state + 1) {
+		r.SetAccept(stateOffset+int(state), true)
 	}
 
 	// Bulk copy and then fixup dest for each transition:
@@ -333,8 +325,8 @@ func (r *destMinMaxSorter) Len() int {
 }
 
 func (r *destMinMaxSorter) Less(i, j int) bool {
-	iStart := 3 * i
-	jStart := 3 * j
+	iStart := 3 * (r.from + i)
+	jStart := 3 * (r.from + j)
 
 	iDest := r.transitions[iStart]
 	jDest := r.transitions[jStart]
@@ -368,7 +360,7 @@ func (r *destMinMaxSorter) Less(i, j int) bool {
 }
 
 func (r *destMinMaxSorter) Swap(i, j int) {
-	iStart, jStart := 3*i, 3*j
+	iStart, jStart := 3*(r.from+i), 3*(r.from+j)
 	r.swapOne(iStart, jStart)
 	r.swapOne(iStart+1, jStart+1)
 	r.swapOne(iStart+2, jStart+2)
@@ -390,8 +382,8 @@ func (r *minMaxDestSorter) Len() int {
 }
 
 func (r *minMaxDestSorter) Less(i, j int) bool {
-	iStart := 3 * i
-	jStart := 3 * j
+	iStart := 3 * (r.from + i)
+	jStart := 3 * (r.from + j)
 
 	// First min:
 	iMin := r.transitions[iStart+1]
@@ -424,7 +416,7 @@ func (r *minMaxDestSorter) Less(i, j int) bool {
 }
 
 func (r *minMaxDestSorter) Swap(i, j int) {
-	iStart, jStart := 3*i, 3*j
+	iStart, jStart := 3*(r.from+i), 3*(r.from+j)
 	r.swapOne(iStart, jStart)
 	r.swapOne(iStart+1, jStart+1)
 	r.swapOne(iStart+2, jStart+2)
@@ -667,3 +659,49 @@ func (r *Builder) sort(from, to int) {
 func (r *Builder) IsAccept(state int) bool {
 	return r.isAccept.Test(uint(state))
 }
+
+// ToDot Returns the dot (graphviz) representation of this automaton. This is extremely useful for
+// visualizing the automaton.
+func (r *Automaton) ToDot() string {
+	b := new(strings.Builder)
+	b.WriteString("digraph Automaton {\n")
+	b.WriteString("  rankdir = LR\n")
+	b.WriteString("  node [width=0.2, height=0.2, fontsize=8]\n")
+
+	numStates := r.GetNumStates()
+	if numStates > 0 {
+		b.WriteString("  initial [shape=plaintext,label=\"\"]\n")
+		b.WriteString("  initial -> 0\n")
+	}
+
+	t := NewTransition()
+	for state := 0; state < numStates; state++ {
+		if r.IsAccept(state) {
+			fmt.Fprintf(b, "  %d [shape=doublecircle,label=\"%d\"]\n", state, state)
+		} else {
+			fmt.Fprintf(b, "  %d [shape=circle,label=\"%d\"]\n", state, state)
+		}
+
+		numTransitions := r.InitTransition(state, t)
+		for i := 0; i < numTransitions; i++ {
+			r.GetNextTransition(t)
+			fmt.Fprintf(b, "  %d -> %d [label=\"", state, t.Dest)
+			appendCharString(t.Min, b)
+			if t.Max != t.Min {
+				b.WriteByte('-')
+				appendCharString(t.Max, b)
+			}
+			b.WriteString("\"]\n")
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func appendCharString(c int, b *strings.Builder) {
+	if c >= 0x21 && c <= 0x7e && c != '\\' && c != '"' {
+		b.WriteRune(rune(c))
+		return
+	}
+	fmt.Fprintf(b, "\\\\U%08x", c)
+}
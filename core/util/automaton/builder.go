@@ -44,6 +44,43 @@ func (r *Builder) CopyStates(other *Automaton) {
 	}
 }
 
+// Copy Copies over all states/transitions from other.
+func (r *Builder) Copy(other *Automaton) {
+	offset := r.GetNumStates()
+	otherNumStates := other.GetNumStates()
+
+	// Copy all states
+	r.CopyStates(other)
+
+	// Copy all transitions
+	t := NewTransition()
+	for s := 0; s < otherNumStates; s++ {
+		count := other.InitTransition(s, t)
+		for i := 0; i < count; i++ {
+			other.GetNextTransition(t)
+			r.AddTransition(offset+s, offset+t.Dest, t.Min, t.Max)
+		}
+	}
+}
+
+// AddEpsilon Add a [virtual] epsilon transition between source and dest. Dest state must already
+// have all transitions added because this method simply copies those same transitions over to source.
+func (r *Builder) AddEpsilon(source, dest int) {
+	for upto := 0; upto < r.nextTransition; upto += 4 {
+		if r.transitions[upto] == dest {
+			r.AddTransition(source, r.transitions[upto+1], r.transitions[upto+2], r.transitions[upto+3])
+		}
+	}
+	if r.IsAccept(dest) {
+		r.SetAccept(source, true)
+	}
+}
+
+// GetNumStates How many states this automaton has.
+func (r *Builder) GetNumStates() int {
+	return r.nextState
+}
+
 func (r *Builder) AddTransitionLabel(source, dest, label int) {
 	r.AddTransition(source, dest, label, label)
 }
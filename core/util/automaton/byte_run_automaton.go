@@ -5,24 +5,23 @@ type ByteRunAutomaton struct {
 	*RunAutomaton
 }
 
-func NewByteRunAutomaton(a *Automaton) *ByteRunAutomaton {
-	return &ByteRunAutomaton{
-		NewRunAutomatonV1(a, 256, 10000),
-	}
+// NewByteRunAutomaton Converts incoming automaton to byte-based (UTF32ToUTF8) first
+func NewByteRunAutomaton(a *Automaton) (*ByteRunAutomaton, error) {
+	return NewByteRunAutomatonV1(a, false, DEFAULT_DETERMINIZE_WORK_LIMIT)
 }
 
-func NewByteRunAutomatonV1(a *Automaton, isBinary bool, determinizeWorkLimit int) *ByteRunAutomaton {
-	var auto *Automaton
-
-	if isBinary {
-		auto = a
-	} else {
-
+// NewByteRunAutomatonV1 expert: if isBinary is true, the input is already byte-based
+func NewByteRunAutomatonV1(a *Automaton, isBinary bool, determinizeWorkLimit int) (*ByteRunAutomaton, error) {
+	auto := a
+	if !isBinary {
+		auto = NewUTF32ToUTF8().Convert(a)
 	}
 
-	return &ByteRunAutomaton{
-		NewRunAutomatonV1(auto, 256, determinizeWorkLimit),
+	runAutomaton, err := NewRunAutomatonV1(auto, 256, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
 	}
+	return &ByteRunAutomaton{runAutomaton}, nil
 }
 
 // Run Returns true if the given byte array is accepted by this automaton
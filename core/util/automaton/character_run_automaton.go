@@ -0,0 +1,46 @@
+package automaton
+
+import "unicode/utf8"
+
+// CharacterRunAutomaton Automaton representation for matching unicode strings.
+type CharacterRunAutomaton struct {
+	*RunAutomaton
+}
+
+// NewCharacterRunAutomaton Construct with a default number of determinizeWorkLimit.
+func NewCharacterRunAutomaton(a *Automaton) (*CharacterRunAutomaton, error) {
+	return NewCharacterRunAutomatonV1(a, DEFAULT_DETERMINIZE_WORK_LIMIT)
+}
+
+// NewCharacterRunAutomatonV1 Construct specifying determinizeWorkLimit.
+func NewCharacterRunAutomatonV1(a *Automaton, determinizeWorkLimit int) (*CharacterRunAutomaton, error) {
+	runAutomaton, err := NewRunAutomatonV1(a, utf8.MaxRune+1, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &CharacterRunAutomaton{runAutomaton}, nil
+}
+
+// Run Returns true if the given string is accepted by this automaton.
+func (r *CharacterRunAutomaton) Run(s string) bool {
+	p := 0
+	for _, c := range s {
+		p = r.Step(p, int(c))
+		if p == -1 {
+			return false
+		}
+	}
+	return r.accept[p]
+}
+
+// RunRunes Returns true if the given code points are accepted by this automaton.
+func (r *CharacterRunAutomaton) RunRunes(s []rune) bool {
+	p := 0
+	for _, c := range s {
+		p = r.Step(p, int(c))
+		if p == -1 {
+			return false
+		}
+	}
+	return r.accept[p]
+}
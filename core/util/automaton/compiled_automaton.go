@@ -40,7 +40,7 @@ type CompiledAutomaton struct {
 // NewCompiledAutomaton
 // Create this. If finite is null, we use Operations.isFinite to determine whether it is finite. If simplify is true, we run possibly expensive operations to determine if the automaton is one the cases in CompiledAutomaton.AUTOMATON_TYPE. If simplify requires determinizing the automaton then at most determinizeWorkLimit effort will be spent. Any more than that will cause a TooComplexToDeterminizeException.
 func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bool,
-	determinizeWorkLimit int, isBinary bool) *CompiledAutomaton {
+	determinizeWorkLimit int, isBinary bool) (*CompiledAutomaton, error) {
 
 	this := &CompiledAutomaton{}
 
@@ -64,7 +64,7 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 			this.automaton = nil
 			this.finite = nil
 			this.sinkState = -1
-			return this
+			return this, nil
 		}
 
 		var isTotal bool
@@ -85,10 +85,14 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 			this.automaton = nil
 			this.finite = nil
 			this.sinkState = -1
-			return this
+			return this, nil
 		}
 
-		automaton = DeterminizeAutomaton(automaton, determinizeWorkLimit)
+		var err error
+		automaton, err = DeterminizeAutomaton(automaton, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
 
 		singleton, _ := GetSingletonAutomaton(automaton)
 
@@ -101,12 +105,15 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 			this.finite = nil
 
 			if isBinary {
-				this.term, _ = intsToBytes(singleton)
+				this.term, err = intsToBytes(singleton)
 			} else {
-				this.term, _ = unicodeIntsToBytes(singleton)
+				this.term, err = unicodeIntsToBytes(singleton)
+			}
+			if err != nil {
+				return nil, err
 			}
 			this.sinkState = -1
-			return this
+			return this, nil
 		}
 	}
 
@@ -127,7 +134,7 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 		binary = automaton
 	} else {
 		// Incoming automaton is unicode, and we must convert to UTF8 to match what's in the index:
-		// FIXME
+		binary = NewUTF32ToUTF8().Convert(automaton)
 	}
 
 	// compute a common suffix for infinite DFAs, this is an optimization for "leading wildcard"
@@ -135,7 +142,10 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 	if this.finite.Load() || automaton.GetNumStates()+automaton.GetNumTransitions() > 1000 {
 		this.commonSuffixRef = nil
 	} else {
-		suffix := GetCommonSuffixBytesRef(binary)
+		suffix, err := GetCommonSuffixBytesRef(binary, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
 		if len(suffix) == 0 {
 			this.commonSuffixRef = nil
 		} else {
@@ -144,13 +154,17 @@ func NewCompiledAutomaton(automaton *Automaton, finite *atomic.Bool, simplify bo
 	}
 
 	// This will determinize the binary automaton for us:
-	this.runAutomaton = NewByteRunAutomatonV1(binary, true, determinizeWorkLimit)
+	runAutomaton, err := NewByteRunAutomatonV1(binary, true, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	this.runAutomaton = runAutomaton
 	this.automaton = this.runAutomaton.automaton
 
 	// TODO: this is a bit fragile because if the automaton is not minimized there could be more than 1 sink state but this-prefix will fail
 	// to run for those:
 	this.sinkState = findSinkState(this.automaton)
-	return this
+	return this, nil
 }
 
 func findSinkState(automaton *Automaton) int {
@@ -213,6 +227,30 @@ func (r *CompiledAutomaton) RunAutomaton() *ByteRunAutomaton {
 	return r.runAutomaton
 }
 
+// Automaton
+// Returns the deterministic binary automaton. Only valid for AUTOMATON_TYPE_NORMAL.
+func (r *CompiledAutomaton) Automaton() *Automaton {
+	return r.automaton
+}
+
+// CommonSuffixRef
+// Returns the shared common suffix of all accepted terms, or nil if there is none.
+func (r *CompiledAutomaton) CommonSuffixRef() []byte {
+	return r.commonSuffixRef
+}
+
+// Finite
+// Returns true if the automaton accepts a finite set of strings.
+func (r *CompiledAutomaton) Finite() bool {
+	return r.finite != nil && r.finite.Load()
+}
+
+// SinkState
+// Returns the state which accepts all suffixes, or -1.
+func (r *CompiledAutomaton) SinkState() int {
+	return r.sinkState
+}
+
 //func (r *CompiledAutomaton) GetTermsEnum(terms index.Terms) (index.TermsEnum, error) {
 //	switch r._type {
 //	case AUTOMATON_TYPE_NONE:
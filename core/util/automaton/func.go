@@ -1,10 +1,217 @@
 package automaton
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MIN_CODE_POINT = 0
+	MAX_CODE_POINT = utf8.MaxRune
+)
+
+// MakeEmpty Returns a new (deterministic) automaton with the empty language.
+func MakeEmpty() *Automaton {
+	a := NewAutomaton()
+	a.finishState()
+	return a
+}
+
+// MakeEmptyString Returns a new (deterministic) automaton that accepts only the empty string.
+func MakeEmptyString() *Automaton {
+	a := NewAutomaton()
+	a.CreateState()
+	a.SetAccept(0, true)
+	return a
+}
+
+// MakeAnyString Returns a new (deterministic) automaton that accepts all strings.
 func MakeAnyString() *Automaton {
 	a := NewAutomaton()
 	s := a.CreateState()
 	a.SetAccept(s, true)
-	a.AddTransition(s, s, 0, 255)
+	_ = a.AddTransition(s, s, MIN_CODE_POINT, MAX_CODE_POINT)
+	a.finishState()
+	return a
+}
+
+// MakeAnyBinary Returns a new (deterministic) automaton that accepts all binary terms.
+func MakeAnyBinary() *Automaton {
+	a := NewAutomaton()
+	s := a.CreateState()
+	a.SetAccept(s, true)
+	_ = a.AddTransition(s, s, 0, 255)
 	a.finishState()
 	return a
 }
+
+// MakeAnyChar Returns a new (deterministic) automaton that accepts any single codepoint.
+func MakeAnyChar() *Automaton {
+	return MakeCharRange(MIN_CODE_POINT, MAX_CODE_POINT)
+}
+
+// MakeChar Returns a new (deterministic) automaton that accepts a single codepoint of the given value.
+func MakeChar(c int) *Automaton {
+	return MakeCharRange(c, c)
+}
+
+// MakeCharRange Returns a new (deterministic) automaton that accepts a single codepoint whose value
+// is in the given interval (including both end points).
+func MakeCharRange(min, max int) *Automaton {
+	if min > max {
+		return MakeEmpty()
+	}
+	a := NewAutomaton()
+	s1 := a.CreateState()
+	s2 := a.CreateState()
+	a.SetAccept(s2, true)
+	_ = a.AddTransition(s1, s2, min, max)
+	a.finishState()
+	return a
+}
+
+// MakeString Returns a new (deterministic) automaton that accepts the single given string.
+func MakeString(s string) *Automaton {
+	a := NewAutomaton()
+	lastState := a.CreateState()
+	for _, cp := range s {
+		state := a.CreateState()
+		_ = a.AddTransitionLabel(lastState, state, int(cp))
+		lastState = state
+	}
+	a.SetAccept(lastState, true)
+	a.finishState()
+	return a
+}
+
+// MakeBinary Returns a new (deterministic) automaton that accepts the single given binary term.
+func MakeBinary(term []byte) *Automaton {
+	a := NewAutomaton()
+	lastState := a.CreateState()
+	for _, b := range term {
+		state := a.CreateState()
+		_ = a.AddTransitionLabel(lastState, state, int(b))
+		lastState = state
+	}
+	a.SetAccept(lastState, true)
+	a.finishState()
+	return a
+}
+
+// MakeDecimalInterval
+// Returns a new automaton that accepts strings representing decimal (base 10) non-negative integers
+// in the given interval.
+// min: minimal value of interval
+// max: maximal value of interval (both end points are included in the interval)
+// digits: if > 0, use fixed number of digits (strings must be prefixed by 0's to obtain the right length) -
+// otherwise, the number of digits is not fixed (any number of leading 0s is accepted)
+func MakeDecimalInterval(min, max, digits int) (*Automaton, error) {
+	x := strconv.Itoa(min)
+	y := strconv.Itoa(max)
+	if min > max || (digits > 0 && len(y) > digits) {
+		return nil, errors.New("invalid decimal interval")
+	}
+
+	d := len(y)
+	if digits > 0 {
+		d = digits
+	}
+	if len(x) < d {
+		x = strings.Repeat("0", d-len(x)) + x
+	}
+	if len(y) < d {
+		y = strings.Repeat("0", d-len(y)) + y
+	}
+
+	builder := NewNewBuilder()
+	if digits <= 0 {
+		// Reserve the "real" initial state:
+		builder.CreateState()
+	}
+
+	initials := make([]int, 0)
+	between(builder, x, y, 0, &initials, digits <= 0)
+
+	a1 := builder.Finish()
+	if digits <= 0 {
+		_ = a1.AddTransitionLabel(0, 0, '0')
+		for _, p := range initials {
+			a1.AddEpsilon(0, p)
+		}
+		a1.finishState()
+	}
+	return a1, nil
+}
+
+// Constructs sub-automaton corresponding to decimal numbers of length len(x)-n.
+func anyOfRightLength(builder *Builder, x string, n int) int {
+	s := builder.CreateState()
+	if len(x) == n {
+		builder.SetAccept(s, true)
+	} else {
+		builder.AddTransition(s, anyOfRightLength(builder, x, n+1), '0', '9')
+	}
+	return s
+}
+
+// Constructs sub-automaton corresponding to decimal numbers of value at least x[n:] and
+// length len(x)-n.
+func atLeast(builder *Builder, x string, n int, initials *[]int, zeros bool) int {
+	s := builder.CreateState()
+	if len(x) == n {
+		builder.SetAccept(s, true)
+	} else {
+		if zeros {
+			*initials = append(*initials, s)
+		}
+		c := int(x[n])
+		builder.AddTransitionLabel(s, atLeast(builder, x, n+1, initials, zeros && c == '0'), c)
+		if c < '9' {
+			builder.AddTransition(s, anyOfRightLength(builder, x, n+1), c+1, '9')
+		}
+	}
+	return s
+}
+
+// Constructs sub-automaton corresponding to decimal numbers of value at most x[n:] and
+// length len(x)-n.
+func atMost(builder *Builder, x string, n int) int {
+	s := builder.CreateState()
+	if len(x) == n {
+		builder.SetAccept(s, true)
+	} else {
+		c := int(x[n])
+		builder.AddTransitionLabel(s, atMost(builder, x, n+1), c)
+		if c > '0' {
+			builder.AddTransition(s, anyOfRightLength(builder, x, n+1), '0', c-1)
+		}
+	}
+	return s
+}
+
+// Constructs sub-automaton corresponding to decimal numbers of value between x[n:] and y[n:]
+// and of length len(x)-n (x and y have same length).
+func between(builder *Builder, x, y string, n int, initials *[]int, zeros bool) int {
+	s := builder.CreateState()
+	if len(x) == n {
+		builder.SetAccept(s, true)
+	} else {
+		if zeros {
+			*initials = append(*initials, s)
+		}
+		cx := int(x[n])
+		cy := int(y[n])
+		if cx == cy {
+			builder.AddTransitionLabel(s, between(builder, x, y, n+1, initials, zeros && cx == '0'), cx)
+		} else { // cx<cy
+			builder.AddTransitionLabel(s, atLeast(builder, x, n+1, initials, zeros && cx == '0'), cx)
+			builder.AddTransitionLabel(s, atMost(builder, y, n+1), cy)
+			if cx+1 < cy {
+				builder.AddTransition(s, anyOfRightLength(builder, x, n+1), cx+1, cy-1)
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,294 @@
+package automaton
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// MAXIMUM_SUPPORTED_DISTANCE Maximum edit distance this class can generate an automaton for.
+const MAXIMUM_SUPPORTED_DISTANCE = 2
+
+// LevenshteinAutomata Class to construct DFAs that match a word within some edit distance.
+//
+// Implements the algorithm described in: Schulz and Mihov: Fast String Correction with Levenshtein
+// Automata. The DFA is built by a subset construction over the positions of the word, the input
+// alphabet is reduced to the code points of the word plus a single class for every other code point,
+// so the size of the DFA only depends on the length of the word and the edit distance.
+//
+// lucene.experimental
+type LevenshteinAutomata struct {
+	// the input word, as code points
+	word []int
+
+	// the sorted distinct code points of the word
+	alphabet []int
+
+	// max code point of the alphabet
+	alphaMax int
+
+	withTranspositions bool
+}
+
+// NewLevenshteinAutomata Create a new LevenshteinAutomata for some input String. Optionally count
+// transpositions as a primitive edit.
+func NewLevenshteinAutomata(input string, withTranspositions bool) *LevenshteinAutomata {
+	return NewLevenshteinAutomataV1(toCodePoints(input), utf8.MaxRune, withTranspositions)
+}
+
+// NewLevenshteinAutomataV1 Expert: specify a custom maximum possible symbol (alphaMax); default is
+// utf8.MaxRune.
+func NewLevenshteinAutomataV1(word []int, alphaMax int, withTranspositions bool) *LevenshteinAutomata {
+	alphabet := slices.Clone(word)
+	slices.Sort(alphabet)
+	alphabet = slices.Compact(alphabet)
+
+	return &LevenshteinAutomata{
+		word:               word,
+		alphabet:           alphabet,
+		alphaMax:           alphaMax,
+		withTranspositions: withTranspositions,
+	}
+}
+
+// ToAutomaton Compute a DFA that accepts all strings within an edit distance of n.
+//
+// All automata have the following properties:
+//   - They are deterministic (DFA).
+//   - There are no transitions to dead states.
+//   - They are not minimal (some transitions could be combined).
+func (r *LevenshteinAutomata) ToAutomaton(n int) (*Automaton, error) {
+	return r.ToAutomatonWithPrefix(n, "")
+}
+
+// ToAutomatonWithPrefix Compute a DFA that accepts all strings within an edit distance of n, matching
+// the specified exact prefix.
+func (r *LevenshteinAutomata) ToAutomatonWithPrefix(n int, prefix string) (*Automaton, error) {
+	if n < 0 || n > MAXIMUM_SUPPORTED_DISTANCE {
+		return nil, fmt.Errorf("max edits must be 0..%d, inclusive; got: %d", MAXIMUM_SUPPORTED_DISTANCE, n)
+	}
+
+	a := NewAutomaton()
+
+	// the prefix is matched exactly, one state per code point
+	prefixStates := 0
+	for range prefix {
+		a.CreateState()
+		prefixStates++
+	}
+	states := newLevStates(r, n)
+	start := states.closure([]levPosition{{}})
+	states.add(a, start)
+
+	upto := 0
+	for _, c := range prefix {
+		if err := a.AddTransitionLabel(upto, upto+1, int(c)); err != nil {
+			return nil, err
+		}
+		upto++
+	}
+
+	for upto = prefixStates; upto-prefixStates < len(states.sets); upto++ {
+		set := states.sets[upto-prefixStates]
+
+		// code points of the word
+		for _, c := range r.alphabet {
+			next := states.step(set, c)
+			if len(next) == 0 {
+				continue
+			}
+			if err := a.AddTransitionLabel(upto, states.add(a, next), c); err != nil {
+				return nil, err
+			}
+		}
+
+		// any other code point
+		next := states.step(set, -1)
+		if len(next) == 0 {
+			continue
+		}
+		dest := states.add(a, next)
+		lower := 0
+		for _, c := range r.alphabet {
+			if c > lower {
+				if err := a.AddTransition(upto, dest, lower, c-1); err != nil {
+					return nil, err
+				}
+			}
+			lower = c + 1
+		}
+		if lower <= r.alphaMax {
+			if err := a.AddTransition(upto, dest, lower, r.alphaMax); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	a.finishState()
+	return a, nil
+}
+
+// levPosition A position of the nondeterministic Levenshtein automaton: offset characters of
+// the word have been consumed with edits edits. A transposed position has read the character
+// following offset, and waits for the character at offset to complete the transposition.
+type levPosition struct {
+	offset     int
+	edits      int
+	transposed bool
+}
+
+// subsumes Returns true if every string accepted from o is also accepted from p.
+func (p levPosition) subsumes(o levPosition) bool {
+	if p.transposed || o.transposed || p.edits >= o.edits {
+		return false
+	}
+	return abs(p.offset-o.offset) <= o.edits-p.edits
+}
+
+func compareLevPosition(a, b levPosition) int {
+	if a.offset != b.offset {
+		return a.offset - b.offset
+	}
+	if a.edits != b.edits {
+		return a.edits - b.edits
+	}
+	if a.transposed == b.transposed {
+		return 0
+	}
+	if b.transposed {
+		return -1
+	}
+	return 1
+}
+
+// levStates Maps the sets of positions of the nondeterministic automaton to the states of the DFA,
+// the states are created in the order of sets.
+type levStates struct {
+	*LevenshteinAutomata
+
+	n    int
+	ids  map[string]int
+	sets [][]levPosition
+	key  []byte
+}
+
+func newLevStates(r *LevenshteinAutomata, n int) *levStates {
+	return &levStates{
+		LevenshteinAutomata: r,
+		n:                   n,
+		ids:                 make(map[string]int),
+		sets:                make([][]levPosition, 0),
+	}
+}
+
+// add Returns the state of the set, creating it if this set was never seen.
+func (s *levStates) add(a *Automaton, set []levPosition) int {
+	s.key = s.key[:0]
+	for _, p := range set {
+		s.key = binary.AppendUvarint(s.key, uint64(p.offset))
+		s.key = binary.AppendUvarint(s.key, uint64(p.edits))
+		if p.transposed {
+			s.key = append(s.key, 1)
+		} else {
+			s.key = append(s.key, 0)
+		}
+	}
+
+	if state, ok := s.ids[string(s.key)]; ok {
+		return state
+	}
+
+	state := a.CreateState()
+	s.ids[string(s.key)] = state
+	s.sets = append(s.sets, set)
+
+	for _, p := range set {
+		// the end of the word can be reached by deleting the remaining characters
+		if !p.transposed && len(s.word)-p.offset <= s.n-p.edits {
+			a.SetAccept(state, true)
+			break
+		}
+	}
+	return state
+}
+
+// step Returns the positions reachable from set by reading c, c is -1 for a code point that
+// doesn't occur in the word.
+func (s *levStates) step(set []levPosition, c int) []levPosition {
+	next := make([]levPosition, 0, len(set)*2)
+	for _, p := range s.deletions(slices.Clone(set)) {
+		if p.transposed {
+			if s.word[p.offset] == c {
+				next = append(next, levPosition{offset: p.offset + 2, edits: p.edits})
+			}
+			continue
+		}
+
+		if p.offset < len(s.word) && s.word[p.offset] == c {
+			next = append(next, levPosition{offset: p.offset + 1, edits: p.edits})
+		}
+		if p.edits < s.n {
+			// insertion
+			next = append(next, levPosition{offset: p.offset, edits: p.edits + 1})
+			if p.offset < len(s.word) {
+				// substitution
+				next = append(next, levPosition{offset: p.offset + 1, edits: p.edits + 1})
+			}
+			if s.withTranspositions && p.offset+1 < len(s.word) && s.word[p.offset+1] == c {
+				next = append(next, levPosition{offset: p.offset, edits: p.edits + 1, transposed: true})
+			}
+		}
+	}
+	return s.closure(next)
+}
+
+// deletions Adds to set the positions reachable by deleting characters of the word.
+func (s *levStates) deletions(set []levPosition) []levPosition {
+	for i := 0; i < len(set); i++ {
+		p := set[i]
+		if !p.transposed && p.edits < s.n && p.offset < len(s.word) {
+			set = append(set, levPosition{offset: p.offset + 1, edits: p.edits + 1})
+		}
+	}
+	return set
+}
+
+// closure Adds the deletions of the positions of set, and removes the subsumed positions. The
+// subsumed positions don't change the language accepted from set, but they have to be restored
+// by deletions before stepping.
+func (s *levStates) closure(set []levPosition) []levPosition {
+	set = s.deletions(set)
+	slices.SortFunc(set, compareLevPosition)
+	set = slices.Compact(set)
+
+	reduced := set[:0:0]
+	for i, p := range set {
+		subsumed := false
+		for j, o := range set {
+			if i != j && o.subsumes(p) {
+				subsumed = true
+				break
+			}
+		}
+		if !subsumed {
+			reduced = append(reduced, p)
+		}
+	}
+	return reduced
+}
+
+func toCodePoints(s string) []int {
+	codePoints := make([]int, 0, len(s))
+	for _, c := range s {
+		codePoints = append(codePoints, int(c))
+	}
+	return codePoints
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
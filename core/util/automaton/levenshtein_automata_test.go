@@ -0,0 +1,159 @@
+package automaton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// editDistance Returns the Levenshtein distance of a and b, a transposition of adjacent code points
+// is a single edit if transpositions is set (optimal string alignment distance)
+func editDistance(a, b []rune, transpositions bool) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if transpositions && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// allStrings Returns every string of at most maxLength code points of alphabet
+func allStrings(alphabet []rune, maxLength int) [][]rune {
+	all := [][]rune{{}}
+	last := [][]rune{{}}
+	for length := 1; length <= maxLength; length++ {
+		next := make([][]rune, 0, len(last)*len(alphabet))
+		for _, s := range last {
+			for _, c := range alphabet {
+				next = append(next, append(append(make([]rune, 0, length), s...), c))
+			}
+		}
+		all = append(all, next...)
+		last = next
+	}
+	return all
+}
+
+func newLevenshteinRunAutomaton(t *testing.T, word string, n int, transpositions bool) *CharacterRunAutomaton {
+	a, err := NewLevenshteinAutomata(word, transpositions).ToAutomaton(n)
+	assert.Nil(t, err)
+	assert.True(t, a.IsDeterministic())
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+	return matcher
+}
+
+func TestLevenshteinAutomata(t *testing.T) {
+	for _, transpositions := range []bool{false, true} {
+		matchers := make([]*CharacterRunAutomaton, 0, MAXIMUM_SUPPORTED_DISTANCE+1)
+		for n := 0; n <= MAXIMUM_SUPPORTED_DISTANCE; n++ {
+			matchers = append(matchers, newLevenshteinRunAutomaton(t, "fox", n, transpositions))
+		}
+
+		assert.True(t, matchers[0].Run("fox"))
+		assert.False(t, matchers[0].Run("fo"))
+		assert.False(t, matchers[0].Run(""))
+
+		// a deletion, an insertion and a substitution
+		for _, s := range []string{"fo", "foxy", "box"} {
+			assert.False(t, matchers[0].Run(s), s)
+			assert.True(t, matchers[1].Run(s), s)
+		}
+		// two edits
+		for _, s := range []string{"f", "xfo", "bax", "fxxx", "afoxb", "dog"} {
+			assert.False(t, matchers[1].Run(s), s)
+			assert.True(t, matchers[2].Run(s), s)
+		}
+		for _, s := range []string{"", "cat", "fooooox"} {
+			assert.False(t, matchers[2].Run(s), s)
+		}
+
+		// a transposition is a single edit, or a deletion and an insertion
+		assert.Equal(t, transpositions, matchers[1].Run("ofx"))
+		assert.Equal(t, transpositions, matchers[1].Run("fxo"))
+		assert.True(t, matchers[2].Run("ofx"))
+	}
+
+	_, err := NewLevenshteinAutomata("fox", true).ToAutomaton(MAXIMUM_SUPPORTED_DISTANCE + 1)
+	assert.NotNil(t, err)
+	_, err = NewLevenshteinAutomata("fox", true).ToAutomaton(-1)
+	assert.NotNil(t, err)
+}
+
+func TestLevenshteinAutomata_Unicode(t *testing.T) {
+	matcher := newLevenshteinRunAutomaton(t, "müller", 1, true)
+	// an edit replaces a code point, not a byte
+	assert.True(t, matcher.Run("muller"))
+	assert.True(t, matcher.Run("müler"))
+	assert.True(t, matcher.Run("mülelr"))
+	assert.True(t, matcher.Run("mü世ller"))
+	assert.False(t, matcher.Run("mueller"))
+
+	matcher = newLevenshteinRunAutomaton(t, "日本語", 1, false)
+	assert.True(t, matcher.Run("日本"))
+	assert.True(t, matcher.Run("日本人"))
+	assert.True(t, matcher.Run("日本語。"))
+	assert.False(t, matcher.Run("日"))
+	// a supplementary code point is a single character
+	assert.True(t, matcher.Run("日😀語"))
+}
+
+func TestLevenshteinAutomata_Prefix(t *testing.T) {
+	a, err := NewLevenshteinAutomata("cene", true).ToAutomatonWithPrefix(1, "lu")
+	assert.Nil(t, err)
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+
+	for _, s := range []string{"lucene", "lucen", "lucenes", "lucane", "luecne", "lucnee", "lucenä"} {
+		assert.True(t, matcher.Run(s), s)
+	}
+	// the prefix has to match exactly
+	for _, s := range []string{"kucene", "lcene", "ulcene", "ucene", "lu", ""} {
+		assert.False(t, matcher.Run(s), s)
+	}
+
+	a, err = NewLevenshteinAutomata("", true).ToAutomatonWithPrefix(0, "ßü")
+	assert.Nil(t, err)
+	matcher, err = NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+	assert.True(t, matcher.Run("ßü"))
+	assert.False(t, matcher.Run("ßüx"))
+}
+
+func TestLevenshteinAutomata_AllStrings(t *testing.T) {
+	for _, word := range []string{"", "a", "ab", "aab", "abcb", "aé世"} {
+		alphabet := []rune{'x', 'ü'}
+		for _, c := range word {
+			alphabet = append(alphabet, c)
+		}
+		candidates := allStrings(alphabet, len([]rune(word))+MAXIMUM_SUPPORTED_DISTANCE+1)
+
+		for _, transpositions := range []bool{false, true} {
+			for n := 0; n <= MAXIMUM_SUPPORTED_DISTANCE; n++ {
+				matcher := newLevenshteinRunAutomaton(t, word, n, transpositions)
+				for _, candidate := range candidates {
+					expected := editDistance([]rune(word), candidate, transpositions) <= n
+					if expected != matcher.Run(string(candidate)) {
+						t.Fatalf("%q within %d edits of %q (transpositions=%v): expected %v",
+							string(candidate), n, word, transpositions, expected)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package automaton
+
+import (
+	"github.com/bits-and-blooms/bitset"
+)
+
+// Minimize
+// Minimizes (and determinizes if not already deterministic) the given automaton using Hopcroft's
+// algorithm.
+//
+// determinizeWorkLimit: maximum effort to spend determinizing the automaton. Set higher to allow
+// more complex queries and lower to prevent memory exhaustion. Use DEFAULT_DETERMINIZE_WORK_LIMIT
+// as a decent default if you don't otherwise know what to specify.
+func Minimize(a *Automaton, determinizeWorkLimit int) (*Automaton, error) {
+	if a.GetNumStates() == 0 || (!a.IsAccept(0) && a.GetNumTransitionsWithState(0) == 0) {
+		// Fastmatch for common case
+		return NewAutomaton(), nil
+	}
+
+	a, err := DeterminizeAutomaton(a, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.GetNumTransitionsWithState(0) == 1 {
+		t := NewTransition()
+		a.getTransition(0, 0, t)
+		if t.Dest == 0 && t.Min == MIN_CODE_POINT && t.Max == MAX_CODE_POINT {
+			// Accepts all strings
+			return a, nil
+		}
+	}
+	a = totalize(a)
+
+	// initialize data structures
+	sigma := a.GetStartPoints()
+	sigmaLen, statesLen := len(sigma), a.GetNumStates()
+
+	reverse := make([][][]int, statesLen)
+	partition := make([]map[int]struct{}, statesLen)
+	splitBlock := make([][]int, statesLen)
+	block := make([]int, statesLen)
+	active := make([][]*stateList, statesLen)
+	active2 := make([][]*stateListNode, statesLen)
+	pending := make([]intPair, 0)
+	pending2 := bitset.New(uint(sigmaLen * statesLen))
+	split := bitset.New(uint(statesLen))
+	refine := bitset.New(uint(statesLen))
+	refine2 := bitset.New(uint(statesLen))
+	for q := 0; q < statesLen; q++ {
+		reverse[q] = make([][]int, sigmaLen)
+		partition[q] = make(map[int]struct{})
+		active[q] = make([]*stateList, sigmaLen)
+		active2[q] = make([]*stateListNode, sigmaLen)
+		for x := 0; x < sigmaLen; x++ {
+			active[q][x] = &stateList{}
+		}
+	}
+
+	// find initial partition and reverse edges
+	for q := 0; q < statesLen; q++ {
+		j := 1
+		if a.IsAccept(q) {
+			j = 0
+		}
+		partition[j][q] = struct{}{}
+		block[q] = j
+		for x := 0; x < sigmaLen; x++ {
+			r := reverse[a.Step(q, sigma[x])]
+			r[x] = append(r[x], q)
+		}
+	}
+
+	// initialize active sets
+	for j := 0; j <= 1; j++ {
+		for x := 0; x < sigmaLen; x++ {
+			for q := range partition[j] {
+				if reverse[q][x] != nil {
+					active2[q][x] = active[j][x].add(q)
+				}
+			}
+		}
+	}
+
+	// initialize pending
+	for x := 0; x < sigmaLen; x++ {
+		j := 1
+		if active[0][x].size <= active[1][x].size {
+			j = 0
+		}
+		pending = append(pending, intPair{n1: j, n2: x})
+		pending2.Set(uint(x*statesLen + j))
+	}
+
+	// process pending until fixed point
+	k := 2
+	for len(pending) > 0 {
+		ip := pending[0]
+		pending = pending[1:]
+		p, x := ip.n1, ip.n2
+		pending2.Clear(uint(x*statesLen + p))
+
+		// find states that need to be split off their blocks
+		for m := active[p][x].first; m != nil; m = m.next {
+			for _, i := range reverse[m.q][x] {
+				if split.Test(uint(i)) {
+					continue
+				}
+				split.Set(uint(i))
+				j := block[i]
+				splitBlock[j] = append(splitBlock[j], i)
+				if !refine2.Test(uint(j)) {
+					refine2.Set(uint(j))
+					refine.Set(uint(j))
+				}
+			}
+		}
+
+		// refine blocks
+		for jj, ok := refine.NextSet(0); ok; jj, ok = refine.NextSet(jj + 1) {
+			j := int(jj)
+			sb := splitBlock[j]
+			if len(sb) < len(partition[j]) {
+				b1 := partition[j]
+				b2 := partition[k]
+				for _, s := range sb {
+					delete(b1, s)
+					b2[s] = struct{}{}
+					block[s] = k
+					for c := 0; c < sigmaLen; c++ {
+						sn := active2[s][c]
+						if sn != nil && sn.sl == active[j][c] {
+							sn.remove()
+							active2[s][c] = active[k][c].add(s)
+						}
+					}
+				}
+
+				// update pending
+				for c := 0; c < sigmaLen; c++ {
+					aj := active[j][c].size
+					ak := active[k][c].size
+					ofs := c * statesLen
+					if !pending2.Test(uint(ofs+j)) && 0 < aj && aj <= ak {
+						pending2.Set(uint(ofs + j))
+						pending = append(pending, intPair{n1: j, n2: c})
+					} else {
+						pending2.Set(uint(ofs + k))
+						pending = append(pending, intPair{n1: k, n2: c})
+					}
+				}
+				k++
+			}
+
+			refine2.Clear(jj)
+			for _, s := range sb {
+				split.Clear(uint(s))
+			}
+			splitBlock[j] = sb[:0]
+		}
+		refine.ClearAll()
+	}
+
+	result := NewAutomaton()
+	t := NewTransition()
+
+	// make a new state for each equivalence class, set initial state
+	stateMap := make([]int, statesLen)
+	stateRep := make([]int, k)
+
+	result.CreateState()
+
+	for n := 0; n < k; n++ {
+		newState := 0
+		if _, isInitial := partition[n][0]; !isInitial {
+			newState = result.CreateState()
+		}
+
+		for q := range partition[n] {
+			stateMap[q] = newState
+			result.SetAccept(newState, a.IsAccept(q))
+			stateRep[newState] = q // select representative
+		}
+	}
+
+	// build transitions and set acceptance
+	for n := 0; n < k; n++ {
+		numTransitions := a.InitTransition(stateRep[n], t)
+		for i := 0; i < numTransitions; i++ {
+			a.GetNextTransition(t)
+			_ = result.AddTransition(n, stateMap[t.Dest], t.Min, t.Max)
+		}
+	}
+	result.finishState()
+
+	return RemoveDeadStates(result), nil
+}
+
+type intPair struct {
+	n1, n2 int
+}
+
+type stateList struct {
+	size        int
+	first, last *stateListNode
+}
+
+func (s *stateList) add(q int) *stateListNode {
+	return newStateListNode(q, s)
+}
+
+type stateListNode struct {
+	q          int
+	next, prev *stateListNode
+	sl         *stateList
+}
+
+func newStateListNode(q int, sl *stateList) *stateListNode {
+	node := &stateListNode{q: q, sl: sl}
+	sl.size++
+	if sl.size == 1 {
+		sl.first, sl.last = node, node
+	} else {
+		sl.last.next = node
+		node.prev = sl.last
+		sl.last = node
+	}
+	return node
+}
+
+func (s *stateListNode) remove() {
+	s.sl.size--
+	if s.sl.first == s {
+		s.sl.first = s.next
+	} else {
+		s.prev.next = s.next
+	}
+	if s.sl.last == s {
+		s.sl.last = s.prev
+	} else {
+		s.next.prev = s.prev
+	}
+}
@@ -1,51 +1,559 @@
 package automaton
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"sync/atomic"
 
 	"github.com/bits-and-blooms/bitset"
 	"github.com/geange/gods-generic/cmp"
-	"github.com/geange/lucene-go/core/util/bitmixer"
-	"github.com/geange/lucene-go/core/util/structure"
 )
 
+// DEFAULT_DETERMINIZE_WORK_LIMIT Default maximum effort that DeterminizeAutomaton should spend before
+// giving up.
+const DEFAULT_DETERMINIZE_WORK_LIMIT = 10000
+
+// ErrTooComplexToDeterminize
+// Returned by DeterminizeAutomaton (and the operations that rely on it) when the powerset
+// construction would need more work than the given limit.
+var ErrTooComplexToDeterminize = errors.New("automaton is too complex to determinize")
+
+// Concatenate
+// Returns an automaton that accepts the concatenation of the languages of the given automata.
+// Complexity: linear in total number of states.
+func Concatenate(as ...*Automaton) *Automaton {
+	result := NewAutomaton()
+
+	// First pass: create all states
+	for _, a := range as {
+		if a.GetNumStates() == 0 {
+			result.finishState()
+			return result
+		}
+		numStates := a.GetNumStates()
+		for s := 0; s < numStates; s++ {
+			result.CreateState()
+		}
+	}
+
+	// Second pass: add transitions, carefully linking accept
+	// states of A to init state of next A:
+	stateOffset := 0
+	t := NewTransition()
+	for i, a := range as {
+		numStates := a.GetNumStates()
+
+		var nextA *Automaton
+		if i < len(as)-1 {
+			nextA = as[i+1]
+		}
+
+		for s := 0; s < numStates; s++ {
+			numTransitions := a.InitTransition(s, t)
+			for j := 0; j < numTransitions; j++ {
+				a.GetNextTransition(t)
+				_ = result.AddTransition(stateOffset+s, stateOffset+t.Dest, t.Min, t.Max)
+			}
+
+			if !a.IsAccept(s) {
+				continue
+			}
+
+			followA := nextA
+			followOffset := stateOffset
+			upto := i + 1
+			for {
+				if followA == nil {
+					result.SetAccept(stateOffset+s, true)
+					break
+				}
+
+				// Adds a "virtual" epsilon transition:
+				numTransitions = followA.InitTransition(0, t)
+				for j := 0; j < numTransitions; j++ {
+					followA.GetNextTransition(t)
+					_ = result.AddTransition(stateOffset+s, followOffset+numStates+t.Dest, t.Min, t.Max)
+				}
+
+				if !followA.IsAccept(0) {
+					break
+				}
+
+				// Keep chaining if followA accepts empty string
+				followOffset += followA.GetNumStates()
+				if upto == len(as)-1 {
+					followA = nil
+				} else {
+					followA = as[upto+1]
+				}
+				upto++
+			}
+		}
+
+		stateOffset += numStates
+	}
+
+	if result.GetNumStates() == 0 {
+		// the concatenation of no automata is the empty string
+		result.CreateState()
+		result.SetAccept(0, true)
+	}
+
+	result.finishState()
+	return result
+}
+
+// Optional
+// Returns an automaton that accepts the union of the empty string and the language of the given automaton.
+// This may create a dead state.
+// Complexity: linear in number of states.
+func Optional(a *Automaton) *Automaton {
+	result := NewAutomaton()
+	result.CreateState()
+	result.SetAccept(0, true)
+	if a.GetNumStates() > 0 {
+		result.Copy(a)
+		result.AddEpsilon(0, 1)
+	}
+	result.finishState()
+	return result
+}
+
+// Repeat
+// Returns an automaton that accepts the Kleene star (zero or more concatenated repetitions) of the
+// language of the given automaton. Never modifies the input automaton language.
+// Complexity: linear in number of states.
+func Repeat(a *Automaton) *Automaton {
+	if a.GetNumStates() == 0 {
+		// Repeating the empty automata will still only accept the empty automata.
+		return a
+	}
+
+	builder := NewNewBuilder()
+	builder.CreateState()
+	builder.SetAccept(0, true)
+	builder.Copy(a)
+
+	t := NewTransition()
+	count := a.InitTransition(0, t)
+	for i := 0; i < count; i++ {
+		a.GetNextTransition(t)
+		builder.AddTransition(0, t.Dest+1, t.Min, t.Max)
+	}
+
+	numStates := a.GetNumStates()
+	for s := 0; s < numStates; s++ {
+		if a.IsAccept(s) {
+			count = a.InitTransition(0, t)
+			for i := 0; i < count; i++ {
+				a.GetNextTransition(t)
+				builder.AddTransition(s+1, t.Dest+1, t.Min, t.Max)
+			}
+		}
+	}
+
+	return builder.Finish()
+}
+
+// RepeatMin
+// Returns an automaton that accepts count or more concatenated repetitions of the language of the
+// given automaton.
+// Complexity: linear in number of states and in count.
+func RepeatMin(a *Automaton, count int) *Automaton {
+	if count == 0 {
+		return Repeat(a)
+	}
+	as := make([]*Automaton, 0, count+1)
+	for i := 0; i < count; i++ {
+		as = append(as, a)
+	}
+	as = append(as, Repeat(a))
+	return Concatenate(as...)
+}
+
+// RepeatMinMax
+// Returns an automaton that accepts between min and max (including both) concatenated repetitions
+// of the language of the given automaton.
+// If min > max, the empty automaton is returned.
+// Complexity: linear in number of states and in min and max.
+func RepeatMinMax(a *Automaton, min, max int) *Automaton {
+	if min > max {
+		return MakeEmpty()
+	}
+
+	var b *Automaton
+	switch min {
+	case 0:
+		b = MakeEmptyString()
+	case 1:
+		b = NewAutomaton()
+		b.Copy(a)
+	default:
+		as := make([]*Automaton, 0, min)
+		for i := 0; i < min; i++ {
+			as = append(as, a)
+		}
+		b = Concatenate(as...)
+	}
+
+	prevAcceptStates := acceptStates(b, 0)
+	builder := NewNewBuilder()
+	builder.Copy(b)
+	for i := min; i < max; i++ {
+		numStates := builder.GetNumStates()
+		builder.Copy(a)
+		for _, s := range prevAcceptStates {
+			builder.AddEpsilon(s, numStates)
+		}
+		prevAcceptStates = acceptStates(a, numStates)
+	}
+
+	return builder.Finish()
+}
+
+// acceptStates returns the accept states of a, shifted by offset
+func acceptStates(a *Automaton, offset int) []int {
+	numStates := uint(a.GetNumStates())
+	isAccept := a.getAcceptStates()
+
+	result := make([]int, 0)
+	for s, ok := isAccept.NextSet(0); ok && s < numStates; s, ok = isAccept.NextSet(s + 1) {
+		result = append(result, offset+int(s))
+	}
+	return result
+}
+
+// Complement
+// Returns a (deterministic) automaton that accepts the complement of the language of the given automaton.
+// Complexity: linear in number of states if already deterministic and exponential otherwise.
+func Complement(a *Automaton, determinizeWorkLimit int) (*Automaton, error) {
+	a, err := DeterminizeAutomaton(a, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	a = totalize(a)
+	numStates := a.GetNumStates()
+	for p := 0; p < numStates; p++ {
+		a.SetAccept(p, !a.IsAccept(p))
+	}
+	return RemoveDeadStates(a), nil
+}
+
+// Minus
+// Returns a (deterministic) automaton that accepts the intersection of the language of a1 and the
+// complement of the language of a2. As a side-effect, the automata may be determinized, if not
+// already deterministic.
+// Complexity: quadratic in number of states if a2 already deterministic and exponential in number
+// of a2's states otherwise.
+func Minus(a1, a2 *Automaton, determinizeWorkLimit int) (*Automaton, error) {
+	if IsEmptyAutomaton(a1) || a1 == a2 {
+		return MakeEmpty(), nil
+	}
+	if IsEmptyAutomaton(a2) {
+		return a1, nil
+	}
+	complement, err := Complement(a2, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	return Intersection(a1, complement), nil
+}
+
+// Intersection
+// Returns an automaton that accepts the intersection of the languages of the given automata.
+// Never modifies the input automata languages.
+// Complexity: quadratic in number of states.
+func Intersection(a1, a2 *Automaton) *Automaton {
+	if a1 == a2 {
+		return a1
+	}
+	if a1.GetNumStates() == 0 {
+		return a1
+	}
+	if a2.GetNumStates() == 0 {
+		return a2
+	}
+
+	transitions1 := a1.getSortedTransitions()
+	transitions2 := a2.getSortedTransitions()
+
+	c := NewAutomaton()
+	c.CreateState()
+
+	type statePair struct {
+		s1, s2 int
+	}
+
+	worklist := []statePair{{0, 0}}
+	newstates := map[statePair]int{{0, 0}: 0}
+
+	for len(worklist) > 0 {
+		p := worklist[0]
+		worklist = worklist[1:]
+
+		s := newstates[p]
+		c.SetAccept(s, a1.IsAccept(p.s1) && a2.IsAccept(p.s2))
+
+		t1 := transitions1[p.s1]
+		t2 := transitions2[p.s2]
+		for n1, b2 := 0, 0; n1 < len(t1); n1++ {
+			for b2 < len(t2) && t2[b2].Max < t1[n1].Min {
+				b2++
+			}
+			for n2 := b2; n2 < len(t2) && t1[n1].Max >= t2[n2].Min; n2++ {
+				if t2[n2].Max >= t1[n1].Min {
+					q := statePair{t1[n1].Dest, t2[n2].Dest}
+					r, ok := newstates[q]
+					if !ok {
+						r = c.CreateState()
+						worklist = append(worklist, q)
+						newstates[q] = r
+					}
+					minValue := max(t1[n1].Min, t2[n2].Min)
+					maxValue := min(t1[n1].Max, t2[n2].Max)
+					_ = c.AddTransition(s, r, minValue, maxValue)
+				}
+			}
+		}
+	}
+	c.finishState()
+
+	return RemoveDeadStates(c)
+}
+
+// SameLanguage
+// Returns true if these two automata accept exactly the same language. This is a costly
+// computation! Both automata must be determinized and have no dead states!
+func SameLanguage(a1, a2 *Automaton) (bool, error) {
+	if a1 == a2 {
+		return true, nil
+	}
+	ok, err := SubsetOf(a2, a1)
+	if err != nil || !ok {
+		return false, err
+	}
+	return SubsetOf(a1, a2)
+}
+
+// SubsetOf
+// Returns true if the language of a1 is a subset of the language of a2. Both automata must be
+// determinized and must have no dead states.
+// Complexity: quadratic in number of states.
+func SubsetOf(a1, a2 *Automaton) (bool, error) {
+	if !a1.IsDeterministic() {
+		return false, errors.New("a1 must be deterministic")
+	}
+	if !a2.IsDeterministic() {
+		return false, errors.New("a2 must be deterministic")
+	}
+
+	if a1.GetNumStates() == 0 {
+		// Empty language is always a subset of any other language
+		return true, nil
+	}
+	if a2.GetNumStates() == 0 {
+		return IsEmptyAutomaton(a1), nil
+	}
+
+	transitions1 := a1.getSortedTransitions()
+	transitions2 := a2.getSortedTransitions()
+
+	type statePair struct {
+		s1, s2 int
+	}
+
+	worklist := []statePair{{0, 0}}
+	visited := map[statePair]struct{}{{0, 0}: {}}
+	for len(worklist) > 0 {
+		p := worklist[0]
+		worklist = worklist[1:]
+
+		if a1.IsAccept(p.s1) && !a2.IsAccept(p.s2) {
+			return false, nil
+		}
+
+		t1 := transitions1[p.s1]
+		t2 := transitions2[p.s2]
+		for n1, b2 := 0, 0; n1 < len(t1); n1++ {
+			for b2 < len(t2) && t2[b2].Max < t1[n1].Min {
+				b2++
+			}
+			min1, max1 := t1[n1].Min, t1[n1].Max
+
+			for n2 := b2; n2 < len(t2) && t1[n1].Max >= t2[n2].Min; n2++ {
+				if t2[n2].Min > min1 {
+					return false, nil
+				}
+				if t2[n2].Max < MAX_CODE_POINT {
+					min1 = t2[n2].Max + 1
+				} else {
+					min1 = MAX_CODE_POINT
+					max1 = MIN_CODE_POINT
+				}
+				q := statePair{t1[n1].Dest, t2[n2].Dest}
+				if _, ok := visited[q]; !ok {
+					worklist = append(worklist, q)
+					visited[q] = struct{}{}
+				}
+			}
+			if min1 <= max1 {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Union
+// Returns an automaton that accepts the union of the languages of the given automata.
+// Complexity: linear in number of states.
+func Union(as ...*Automaton) *Automaton {
+	result := NewAutomaton()
+
+	// Create initial state:
+	result.CreateState()
+
+	// Copy over all automata
+	for _, a := range as {
+		result.Copy(a)
+	}
+
+	// Add epsilon transition from new initial state to each initial state
+	stateOffset := 1
+	for _, a := range as {
+		if a.GetNumStates() == 0 {
+			continue
+		}
+		result.AddEpsilon(0, stateOffset)
+		stateOffset += a.GetNumStates()
+	}
+
+	result.finishState()
+
+	return RemoveDeadStates(result)
+}
+
 // DeterminizeAutomaton Determinizes the given automaton.
 // Worst case complexity: exponential in number of states.
-// Params: 	workLimit – Maximum amount of "work" that the powerset construction will spend before throwing
+// Params: 	workLimit – Maximum amount of "work" that the powerset construction will spend before
 //
-//	TooComplexToDeterminizeException. Higher numbers allow this operation to consume more memory and
+//	returning ErrTooComplexToDeterminize. Higher numbers allow this operation to consume more memory and
 //	CPU but allow more complex automatons. Use DEFAULT_DETERMINIZE_WORK_LIMIT as a decent default
 //	if you don't otherwise know what to specify.
-//
-// Throws: TooComplexToDeterminizeException – if determinizing requires more than workLimit "effort"
-func DeterminizeAutomaton(a *Automaton, workLimit int) *Automaton {
+func DeterminizeAutomaton(a *Automaton, workLimit int) (*Automaton, error) {
 	if a.IsDeterministic() {
-		return a
+		return a, nil
 	}
 	if a.GetNumStates() <= 1 {
 		// Already determinized
-		return a
+		return a, nil
 	}
 
 	// subset construction
 	b := NewNewBuilder()
 
-	// Same initial values and state will always have the same hashCode
-	initialset := NewFrozenIntSet([]int{0}, bitmixer.Mix32(0)+1, 0)
 	// Create state 0:
-	b.CreateState()
+	initialset := NewFrozenIntSet([]int{0}, b.CreateState(), 0)
+	b.SetAccept(0, a.IsAccept(0))
 
-	worklist := make([]*FrozenIntSet, 0)
-	newstate := structure.NewMap[IntSet, int]()
+	worklist := []*FrozenIntSet{initialset}
+	newstate := map[string]*FrozenIntSet{intSetKey(initialset.values): initialset}
 
-	worklist = append(worklist, initialset)
-	b.SetAccept(0, a.IsAccept(0))
-	newstate.Put(initialset, 0)
+	// we aggregate the net work the sets are costing us, instead of counting the determinized states
+	effortSpent := 0
+	effortLimit := workLimit * 10
+
+	type point struct {
+		label int
+		dest  int
+		start bool
+	}
+
+	t := NewTransition()
+	points := make([]point, 0)
+	active := make(map[int]int)
 
-	// TODO:
+	for len(worklist) > 0 {
+		s := worklist[0]
+		worklist = worklist[1:]
 
-	return a
+		effortSpent += s.Size()
+		if effortSpent >= effortLimit {
+			return nil, fmt.Errorf("%w: work limit %d exceeded", ErrTooComplexToDeterminize, workLimit)
+		}
+
+		// Collate all outgoing transitions by their interval end points:
+		points = points[:0]
+		for _, q := range s.values {
+			count := a.InitTransition(q, t)
+			for i := 0; i < count; i++ {
+				a.GetNextTransition(t)
+				points = append(points,
+					point{label: t.Min, dest: t.Dest, start: true},
+					point{label: t.Max + 1, dest: t.Dest, start: false},
+				)
+			}
+		}
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].label < points[j].label
+		})
+
+		lastPoint := -1
+		for i := 0; i < len(points); {
+			label := points[i].label
+
+			if len(active) > 0 {
+				dests := make([]int, 0, len(active))
+				for dest := range active {
+					dests = append(dests, dest)
+				}
+				slices.Sort(dests)
+
+				key := intSetKey(dests)
+				q, ok := newstate[key]
+				if !ok {
+					q = NewFrozenIntSet(dests, b.CreateState(), 0)
+					worklist = append(worklist, q)
+					accept := false
+					for _, dest := range dests {
+						if a.IsAccept(dest) {
+							accept = true
+							break
+						}
+					}
+					b.SetAccept(q.state, accept)
+					newstate[key] = q
+				}
+				b.AddTransition(s.state, q.state, lastPoint, label-1)
+			}
+
+			for ; i < len(points) && points[i].label == label; i++ {
+				p := points[i]
+				if p.start {
+					active[p.dest]++
+					continue
+				}
+				active[p.dest]--
+				if active[p.dest] == 0 {
+					delete(active, p.dest)
+				}
+			}
+			lastPoint = label
+		}
+	}
+
+	return b.Finish(), nil
+}
+
+func intSetKey(values []int) string {
+	buf := make([]byte, 0, len(values)*2)
+	for _, v := range values {
+		buf = binary.AppendUvarint(buf, uint64(v))
+	}
+	return string(buf)
 }
 
 // IsEmptyAutomaton
@@ -101,6 +609,9 @@ func IsTotalAutomaton(a *Automaton) bool {
 // Returns true if the given automaton accepts all strings for the specified min/max range of the alphabet.
 // The automaton must be minimized.
 func IsTotalAutomatonRange(a *Automaton, minAlphabet, maxAlphabet int) bool {
+	if a.GetNumStates() == 0 {
+		return false
+	}
 	if a.IsAccept(0) && a.GetNumTransitionsWithState(0) == 1 {
 		t := NewTransition()
 		a.getTransition(0, 0, t)
@@ -109,10 +620,16 @@ func IsTotalAutomatonRange(a *Automaton, minAlphabet, maxAlphabet int) bool {
 	return false
 }
 
+// GetSingletonAutomaton
+// If this automaton accepts a single input, return it. Else, return nil.
+// The automaton must be deterministic.
 func GetSingletonAutomaton(a *Automaton) ([]int, error) {
 	if a.IsDeterministic() == false {
 		return nil, errors.New("input automaton must be deterministic")
 	}
+	if a.GetNumStates() == 0 {
+		return nil, nil
+	}
 
 	ints := make([]int, 0)
 	visited := make(map[int]struct{})
@@ -124,7 +641,7 @@ func GetSingletonAutomaton(a *Automaton) ([]int, error) {
 		if a.IsAccept(s) == false {
 			if a.GetNumTransitionsWithState(s) == 1 {
 				a.getTransition(s, 0, t)
-				if _, ok := visited[t.Dest]; t.Min == t.Max && ok {
+				if _, ok := visited[t.Dest]; t.Min == t.Max && !ok {
 					ints = append(ints, t.Min)
 					s = t.Dest
 					continue
@@ -177,17 +694,49 @@ func isFinite(scratch *Transition, a *Automaton, state int, path, visited *bitse
 	return flag
 }
 
+// GetCommonPrefixBytesRef
+// Returns the longest BytesRef that is a prefix of all accepted strings and visits each state at most once.
+// The automaton must be deterministic.
+// Returns: common prefix, which can be an empty (length 0) BytesRef (never nil)
+func GetCommonPrefixBytesRef(a *Automaton) []byte {
+	builder := make([]byte, 0)
+	if a.GetNumStates() == 0 {
+		return builder
+	}
+
+	visited := make(map[int]struct{})
+	s := 0
+	t := NewTransition()
+	for {
+		visited[s] = struct{}{}
+		if a.IsAccept(s) || a.GetNumTransitionsWithState(s) != 1 {
+			return builder
+		}
+		a.getTransition(s, 0, t)
+		if _, ok := visited[t.Dest]; t.Min != t.Max || ok {
+			return builder
+		}
+		builder = append(builder, byte(t.Min))
+		s = t.Dest
+	}
+}
+
 // GetCommonSuffixBytesRef
 // Returns the longest BytesRef that is a suffix of all accepted strings. Worst case complexity: quadratic with the number of states+transitions.
 // Returns: common suffix, which can be an empty (length 0) BytesRef (never null)
-func GetCommonSuffixBytesRef(a *Automaton) []byte {
+func GetCommonSuffixBytesRef(a *Automaton, determinizeWorkLimit int) ([]byte, error) {
 	// reverse the language of the automaton, then reverse its common prefix.
-	panic("")
+	r, err := DeterminizeAutomaton(reverseAutomaton(a), determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	ref := GetCommonPrefixBytesRef(r)
+	reverse(ref)
+	return ref, nil
 }
 
 func reverse[T cmp.Ordered](ref []T) {
-	i, j := 0, len(ref)-1
-	for i < j {
+	for i, j := 0, len(ref)-1; i < j; i, j = i+1, j-1 {
 		ref[i], ref[j] = ref[j], ref[i]
 	}
 }
@@ -196,6 +745,54 @@ func reverseAutomaton(a *Automaton) *Automaton {
 	return reverseAutomatonIntSet(a, nil)
 }
 
+// Returns an automaton accepting the reverse language.
+func reverseAutomatonIntSet(a *Automaton, initialStates map[int]struct{}) *Automaton {
+	if IsEmptyAutomaton(a) {
+		return NewAutomaton()
+	}
+
+	numStates := a.GetNumStates()
+
+	// Build a new automaton with all edges reversed
+	builder := NewNewBuilder()
+
+	// Initial node; we'll add epsilon transitions in the end:
+	builder.CreateState()
+
+	for s := 0; s < numStates; s++ {
+		builder.CreateState()
+	}
+
+	// Old initial state becomes new accept state:
+	builder.SetAccept(1, true)
+
+	t := NewTransition()
+	for s := 0; s < numStates; s++ {
+		numTransitions := a.GetNumTransitionsWithState(s)
+		a.InitTransition(s, t)
+		for i := 0; i < numTransitions; i++ {
+			a.GetNextTransition(t)
+			builder.AddTransition(t.Dest+1, s+1, t.Min, t.Max)
+		}
+	}
+
+	result := builder.Finish()
+
+	for _, s := range acceptStates(a, 0) {
+		result.AddEpsilon(0, s+1)
+		if initialStates != nil {
+			initialStates[s+1] = struct{}{}
+		}
+	}
+
+	result.finishState()
+
+	return result
+}
+
+// RemoveDeadStates
+// Removes transitions to dead states (a state is "dead" if it is not reachable from the initial
+// state or no accept state is reachable from it.)
 func RemoveDeadStates(a *Automaton) *Automaton {
 	numStates := a.GetNumStates()
 	liveSet := getLiveStates(a)
@@ -219,78 +816,153 @@ func RemoveDeadStates(a *Automaton) *Automaton {
 			for j := 0; j < numTransitions; j++ {
 				a.GetNextTransition(t)
 				if liveSet.Test(uint(t.Dest)) {
-					result.AddTransition(mp[i], mp[t.Dest], t.Min, t.Max)
+					_ = result.AddTransition(mp[i], mp[t.Dest], t.Min, t.Max)
 				}
 			}
 		}
 	}
 
 	result.finishState()
-	//assert hasDeadStates(result) == false;
 	return result
 }
 
-func getLiveStates(a *Automaton) *bitset.BitSet {
-	live := getLiveStatesFromInitial(a)
-	live.Union(getLiveStatesToAccept(a))
-	return live
+// HasDeadStates
+// Returns true if this automaton has any states that cannot be reached from the initial state or
+// cannot reach an accept state. Cost is O(numTransitions+numStates).
+func HasDeadStates(a *Automaton) bool {
+	liveStates := getLiveStates(a)
+	numLive := int(liveStates.Count())
+	numStates := a.GetNumStates()
+	return numLive < numStates
 }
 
-func getLiveStatesFromInitial(a *Automaton) *bitset.BitSet {
-	panic("")
+// HasDeadStatesFromInitial
+// Returns true if there are dead states reachable from an initial state.
+func HasDeadStatesFromInitial(a *Automaton) bool {
+	reachableFromInitial := getLiveStatesFromInitial(a)
+	reachableFromAccept := getLiveStatesToAccept(a)
+	reachableFromInitial.InPlaceDifference(reachableFromAccept)
+	return reachableFromInitial.Any()
 }
 
-func getLiveStatesToAccept(a *Automaton) *bitset.BitSet {
-	panic("")
+// HasDeadStatesToAccept
+// Returns true if there are dead states that reach an accept state.
+func HasDeadStatesToAccept(a *Automaton) bool {
+	reachableFromInitial := getLiveStatesFromInitial(a)
+	reachableFromAccept := getLiveStatesToAccept(a)
+	reachableFromAccept.InPlaceDifference(reachableFromInitial)
+	return reachableFromAccept.Any()
 }
 
-func reverseAutomatonIntSet(a *Automaton, initialStates map[int]struct{}) *Automaton {
-	if IsEmptyAutomaton(a) {
-		return NewAutomaton()
-	}
+// Returns bitset marking states reachable from the initial state and from which an accept state is reachable.
+func getLiveStates(a *Automaton) *bitset.BitSet {
+	live := getLiveStatesFromInitial(a)
+	live.InPlaceIntersection(getLiveStatesToAccept(a))
+	return live
+}
 
+// Returns bitset marking states reachable from the initial state.
+func getLiveStatesFromInitial(a *Automaton) *bitset.BitSet {
 	numStates := a.GetNumStates()
+	live := bitset.New(uint(numStates))
+	if numStates == 0 {
+		return live
+	}
 
-	// Build a new automaton with all edges reversed
-	builder := NewNewBuilder()
+	workList := []int{0}
+	live.Set(0)
 
-	// Initial node; we'll add epsilon transitions in the end:
-	builder.CreateState()
+	t := NewTransition()
+	for len(workList) > 0 {
+		s := workList[0]
+		workList = workList[1:]
 
-	for s := 0; s < numStates; s++ {
-		builder.CreateState()
+		count := a.InitTransition(s, t)
+		for i := 0; i < count; i++ {
+			a.GetNextTransition(t)
+			if !live.Test(uint(t.Dest)) {
+				live.Set(uint(t.Dest))
+				workList = append(workList, t.Dest)
+			}
+		}
 	}
+	return live
+}
 
-	// Old initial state becomes new accept state:
-	builder.SetAccept(1, true)
+// Returns bitset marking states that can reach an accept state.
+func getLiveStatesToAccept(a *Automaton) *bitset.BitSet {
+	builder := NewNewBuilder()
 
+	// NOTE: not quite the same thing as what reverseAutomaton does:
 	t := NewTransition()
+	numStates := a.GetNumStates()
 	for s := 0; s < numStates; s++ {
-		numTransitions := a.GetNumTransitionsWithState(s)
-		a.InitTransition(s, t)
-		for i := 0; i < numTransitions; i++ {
+		builder.CreateState()
+	}
+	for s := 0; s < numStates; s++ {
+		count := a.InitTransition(s, t)
+		for i := 0; i < count; i++ {
 			a.GetNextTransition(t)
-			builder.AddTransition(t.Dest+1, s+1, t.Min, t.Max)
+			builder.AddTransition(t.Dest, s, t.Min, t.Max)
 		}
 	}
+	a2 := builder.Finish()
 
-	result := builder.Finish()
+	live := bitset.New(uint(numStates))
+	workList := acceptStates(a, 0)
+	for _, s := range workList {
+		live.Set(uint(s))
+	}
 
-	s := 0
-	acceptStates := a.getAcceptStates()
-	for {
-		if _, ok := acceptStates.NextSet(uint(s)); !(ok && s < numStates) {
-			break
+	for len(workList) > 0 {
+		s := workList[0]
+		workList = workList[1:]
+
+		count := a2.InitTransition(s, t)
+		for i := 0; i < count; i++ {
+			a2.GetNextTransition(t)
+			if !live.Test(uint(t.Dest)) {
+				live.Set(uint(t.Dest))
+				workList = append(workList, t.Dest)
+			}
 		}
+	}
+	return live
+}
 
-		result.AddEpsilon(0, s+1)
-		if initialStates != nil {
-			initialStates[s+1] = struct{}{}
+// Returns a new automaton accepting the same language with added transitions to a dead state so that
+// from every state and every label there is a transition.
+func totalize(a *Automaton) *Automaton {
+	result := NewAutomaton()
+	numStates := a.GetNumStates()
+	for i := 0; i < numStates; i++ {
+		result.CreateState()
+		result.SetAccept(i, a.IsAccept(i))
+	}
+
+	deadState := result.CreateState()
+	_ = result.AddTransition(deadState, deadState, MIN_CODE_POINT, MAX_CODE_POINT)
+
+	t := NewTransition()
+	for i := 0; i < numStates; i++ {
+		maxi := MIN_CODE_POINT
+		count := a.InitTransition(i, t)
+		for j := 0; j < count; j++ {
+			a.GetNextTransition(t)
+			_ = result.AddTransition(i, t.Dest, t.Min, t.Max)
+			if t.Min > maxi {
+				_ = result.AddTransition(i, deadState, maxi, t.Min-1)
+			}
+			if t.Max+1 > maxi {
+				maxi = t.Max + 1
+			}
+		}
+
+		if maxi <= MAX_CODE_POINT {
+			_ = result.AddTransition(i, deadState, maxi, MAX_CODE_POINT)
 		}
-		s++
 	}
 
 	result.finishState()
-
 	return result
 }
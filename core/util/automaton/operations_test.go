@@ -0,0 +1,236 @@
+package automaton
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomRegExp Returns a random regular expression over the characters a, b and c
+func randomRegExp(r *rand.Rand, depth int) string {
+	if depth == 0 {
+		return []string{"a", "b", "c", ".", "[ab]", "()"}[r.Intn(6)]
+	}
+	switch r.Intn(7) {
+	case 0:
+		return randomRegExp(r, depth-1) + randomRegExp(r, depth-1)
+	case 1:
+		return "(" + randomRegExp(r, depth-1) + "|" + randomRegExp(r, depth-1) + ")"
+	case 2:
+		return "(" + randomRegExp(r, depth-1) + ")*"
+	case 3:
+		return "(" + randomRegExp(r, depth-1) + ")?"
+	case 4:
+		return "(" + randomRegExp(r, depth-1) + "){1,2}"
+	case 5:
+		return "(" + randomRegExp(r, depth-1) + "&" + randomRegExp(r, depth-1) + ")"
+	default:
+		return "~(" + randomRegExp(r, depth-1) + ")"
+	}
+}
+
+// randomAutomaton Returns a random automaton, which is usually not deterministic
+func randomAutomaton(t *testing.T, r *rand.Rand) (*Automaton, string) {
+	pattern := randomRegExp(r, 1+r.Intn(3))
+	regexp, err := NewRegExp(pattern)
+	assert.Nil(t, err, pattern)
+	a, err := regexp.ToAutomaton()
+	assert.Nil(t, err, pattern)
+	if r.Intn(2) == 0 {
+		// the union of an automaton with one of its parts is not deterministic
+		a = Union(a, MakeString(pattern[:1]), Concatenate(MakeChar('a'), a))
+		pattern = "(" + pattern + "|" + pattern[:1] + "|a(" + pattern + "))"
+	}
+	return a, pattern
+}
+
+// runAll Returns whether a accepts each of the strings
+func runAll(t *testing.T, a *Automaton, strs []string) []bool {
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+	accepted := make([]bool, len(strs))
+	for i, s := range strs {
+		accepted[i] = matcher.Run(s)
+	}
+	return accepted
+}
+
+func testStrings() []string {
+	strs := make([]string, 0)
+	for _, s := range allStrings([]rune{'a', 'b', 'c', 'd'}, 5) {
+		strs = append(strs, string(s))
+	}
+	return strs
+}
+
+func TestOperations_Minimize(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	strs := testStrings()
+	for i := 0; i < 100; i++ {
+		a, pattern := randomAutomaton(t, r)
+
+		minimal, err := Minimize(a, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern)
+		assert.True(t, minimal.IsDeterministic(), pattern)
+		assert.Equal(t, runAll(t, a, strs), runAll(t, minimal, strs), pattern)
+
+		det, err := DeterminizeAutomaton(a, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern)
+		det = RemoveDeadStates(det)
+		same, err := SameLanguage(minimal, det)
+		assert.Nil(t, err, pattern)
+		assert.True(t, same, pattern)
+		assert.LessOrEqual(t, minimal.GetNumStates(), det.GetNumStates(), pattern)
+
+		// a minimal automaton can't be minimized further
+		again, err := Minimize(minimal, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern)
+		assert.Equal(t, minimal.GetNumStates(), again.GetNumStates(), pattern)
+	}
+
+	// different expressions of the same language have the same minimal automaton
+	for _, patterns := range [][2]string{{"(a|b)*", "(a*b*)*"}, {"ab|ac", "a[bc]"}, {"a+", "aa*"}, {"(aa)*a", "a(aa)*"}} {
+		minimal := make([]*Automaton, 0, 2)
+		for _, pattern := range patterns {
+			regexp, err := NewRegExp(pattern)
+			assert.Nil(t, err)
+			a, err := regexp.ToAutomaton()
+			assert.Nil(t, err)
+			minimal = append(minimal, a)
+		}
+		same, err := SameLanguage(minimal[0], minimal[1])
+		assert.Nil(t, err)
+		assert.True(t, same, "%v", patterns)
+		assert.Equal(t, minimal[0].GetNumStates(), minimal[1].GetNumStates(), "%v", patterns)
+	}
+
+	a, err := Minimize(Union(MakeString("ab"), MakeString("ac")), DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, a.GetNumStates())
+
+	a, err = Minimize(Intersection(MakeString("ab"), MakeString("ac")), DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, a.GetNumStates())
+}
+
+func TestOperations_SubsetOf(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	strs := testStrings()
+	for i := 0; i < 100; i++ {
+		a1, pattern1 := randomAutomaton(t, r)
+		a2, pattern2 := randomAutomaton(t, r)
+		a1, err := Minimize(a1, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err)
+		a2, err = Minimize(a2, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err)
+		pair := pattern1 + " " + pattern2
+
+		accepted1 := runAll(t, a1, strs)
+		accepted2 := runAll(t, a2, strs)
+
+		intersection := Intersection(a1, a2)
+		for j, accepted := range runAll(t, intersection, strs) {
+			assert.Equal(t, accepted1[j] && accepted2[j], accepted, "%s: %q", pair, strs[j])
+		}
+		subset, err := SubsetOf(intersection, a1)
+		assert.Nil(t, err, pair)
+		assert.True(t, subset, pair)
+		subset, err = SubsetOf(intersection, a2)
+		assert.Nil(t, err, pair)
+		assert.True(t, subset, pair)
+
+		union, err := Minimize(Union(a1, a2), DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pair)
+		subset, err = SubsetOf(a1, union)
+		assert.Nil(t, err, pair)
+		assert.True(t, subset, pair)
+
+		// the subset relation agrees with the accepted strings
+		subset, err = SubsetOf(a1, a2)
+		assert.Nil(t, err, pair)
+		for j := range strs {
+			if accepted1[j] && !accepted2[j] {
+				assert.False(t, subset, "%s: %q", pair, strs[j])
+				break
+			}
+		}
+	}
+
+	for _, test := range []struct {
+		a1, a2 string
+		subset bool
+	}{
+		{"ab", "a.", true},
+		{"a.", "ab", false},
+		{"a+", "a*", true},
+		{"a*", "a+", false},
+		{"#", "a", true},
+		{"a", "#", false},
+		{"(ab)*", "(a|b)*", true},
+		{"[a-c]+", "~(.*d.*)", true},
+	} {
+		regexp1, err := NewRegExp(test.a1)
+		assert.Nil(t, err)
+		a1, err := regexp1.ToAutomaton()
+		assert.Nil(t, err)
+		regexp2, err := NewRegExp(test.a2)
+		assert.Nil(t, err)
+		a2, err := regexp2.ToAutomaton()
+		assert.Nil(t, err)
+
+		subset, err := SubsetOf(a1, a2)
+		assert.Nil(t, err)
+		assert.Equal(t, test.subset, subset, "%s in %s", test.a1, test.a2)
+		same, err := SameLanguage(a1, a2)
+		assert.Nil(t, err)
+		assert.False(t, same, "%s = %s", test.a1, test.a2)
+	}
+
+	_, err := SubsetOf(Union(MakeString("ab"), MakeString("ac")), MakeAnyString())
+	assert.NotNil(t, err)
+}
+
+func TestOperations_Minus(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	strs := testStrings()
+	for i := 0; i < 100; i++ {
+		a1, pattern1 := randomAutomaton(t, r)
+		a2, pattern2 := randomAutomaton(t, r)
+		pair := pattern1 + " " + pattern2
+
+		empty, err := Minus(a1, a1, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern1)
+		assert.True(t, IsEmptyAutomaton(empty), pattern1)
+
+		// also for an equivalent automaton which is not the same instance
+		minimal, err := Minimize(a1, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern1)
+		empty, err = Minus(a1, minimal, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern1)
+		assert.True(t, IsEmptyAutomaton(empty), pattern1)
+		empty, err = Minus(minimal, a1, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern1)
+		assert.True(t, IsEmptyAutomaton(empty), pattern1)
+
+		accepted1 := runAll(t, a1, strs)
+		accepted2 := runAll(t, a2, strs)
+		minus, err := Minus(a1, a2, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pair)
+		for j, accepted := range runAll(t, minus, strs) {
+			assert.Equal(t, accepted1[j] && !accepted2[j], accepted, "%s: %q", pair, strs[j])
+		}
+
+		complement, err := Complement(a1, DEFAULT_DETERMINIZE_WORK_LIMIT)
+		assert.Nil(t, err, pattern1)
+		for j, accepted := range runAll(t, complement, strs) {
+			assert.Equal(t, !accepted1[j], accepted, "%s: %q", pattern1, strs[j])
+		}
+		assert.True(t, IsEmptyAutomaton(Intersection(a1, complement)), pattern1)
+	}
+
+	a, err := Minus(MakeAnyString(), MakeString("abc"), DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, true, false, true}, runAll(t, a, []string{"", "ab", "abc", strings.Repeat("abc", 2)}))
+}
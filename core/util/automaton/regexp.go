@@ -0,0 +1,1000 @@
+package automaton
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax flags of a RegExp, optional features of the regular expression syntax can be enabled
+// or disabled with them.
+const (
+	// REGEXP_FLAG_INTERSECTION Syntax flag, enables intersection (&).
+	REGEXP_FLAG_INTERSECTION = 0x0001
+
+	// REGEXP_FLAG_COMPLEMENT Syntax flag, enables complement (~).
+	REGEXP_FLAG_COMPLEMENT = 0x0002
+
+	// REGEXP_FLAG_EMPTY Syntax flag, enables empty language (#).
+	REGEXP_FLAG_EMPTY = 0x0004
+
+	// REGEXP_FLAG_ANYSTRING Syntax flag, enables anystring (@).
+	REGEXP_FLAG_ANYSTRING = 0x0008
+
+	// REGEXP_FLAG_AUTOMATON Syntax flag, enables named automata (<identifier>).
+	REGEXP_FLAG_AUTOMATON = 0x0010
+
+	// REGEXP_FLAG_INTERVAL Syntax flag, enables numerical intervals ( <n-m>).
+	REGEXP_FLAG_INTERVAL = 0x0020
+
+	// REGEXP_FLAG_ALL Syntax flag, enables all optional regexp syntax.
+	REGEXP_FLAG_ALL = 0xff
+
+	// REGEXP_FLAG_NONE Syntax flag, enables no optional regexp syntax.
+	REGEXP_FLAG_NONE = 0x0000
+
+	// REGEXP_FLAG_ASCII_CASE_INSENSITIVE Allows case insensitive matching of ASCII characters.
+	// This is a match flag, not a syntax flag.
+	REGEXP_FLAG_ASCII_CASE_INSENSITIVE = 0x0100
+)
+
+// RegExpKind The type of expression represented by a RegExp node.
+type RegExpKind int
+
+const (
+	REGEXP_UNION         = RegExpKind(iota) // The union of two expressions
+	REGEXP_CONCATENATION                    // A sequence of two expressions
+	REGEXP_INTERSECTION                     // The intersection of two expressions
+	REGEXP_OPTIONAL                         // An optional expression
+	REGEXP_REPEAT                           // An expression that repeats
+	REGEXP_REPEAT_MIN                       // An expression that repeats a minimum number of times
+	REGEXP_REPEAT_MINMAX                    // An expression that repeats a minimum and maximum number of times
+	REGEXP_COMPLEMENT                       // The complement of an expression
+	REGEXP_CHAR                             // A Character
+	REGEXP_CHAR_RANGE                       // A Character range
+	REGEXP_ANYCHAR                          // Any Character allowed
+	REGEXP_EMPTY                            // An empty expression
+	REGEXP_STRING                           // A string expression
+	REGEXP_ANYSTRING                        // Any string allowed
+	REGEXP_AUTOMATON                        // An Automaton expression
+	REGEXP_INTERVAL                         // An Interval expression
+	REGEXP_PRE_CLASS                        // A predefined character class like \d, \s or \w
+)
+
+// AutomatonProvider Automaton provider for RegExp.ToAutomatonWithProvider
+type AutomatonProvider interface {
+	// GetAutomaton Returns automaton of the given name, or nil if there is none.
+	GetAutomaton(name string) (*Automaton, error)
+}
+
+// RegExp
+// Regular Expression extension to Automaton.
+//
+// Regular expressions are built from the following abstract syntax:
+//
+//	regexp     ::= unionexp
+//	             |
+//	unionexp   ::= interexp | unionexp        (union)
+//	             | interexp
+//	interexp   ::= concatexp & interexp       (intersection)                [OPTIONAL]
+//	             | concatexp
+//	concatexp  ::= repeatexp concatexp        (concatenation)
+//	             | repeatexp
+//	repeatexp  ::= repeatexp ?                (zero or one occurrence)
+//	             | repeatexp *                (zero or more occurrences)
+//	             | repeatexp +                (one or more occurrences)
+//	             | repeatexp {n}              (n occurrences)
+//	             | repeatexp {n,}             (n or more occurrences)
+//	             | repeatexp {n,m}            (n to m occurrences, including both)
+//	             | complexp
+//	complexp   ::= ~ complexp                 (complement)                  [OPTIONAL]
+//	             | charclassexp
+//	charclassexp ::= [ charclasses ]          (character class)
+//	             | [^ charclasses ]           (negated character class)
+//	             | simpleexp
+//	charclasses ::= charclass charclasses
+//	             | charclass
+//	charclass  ::= charexp - charexp          (character range, including end-points)
+//	             | charexp
+//	simpleexp  ::= charexp
+//	             | .                          (any single character)
+//	             | #                          (the empty language)          [OPTIONAL]
+//	             | @                          (any string)                  [OPTIONAL]
+//	             | " <Unicode string without double-quotes> "  (a string)
+//	             | ( )                        (the empty string)
+//	             | ( unionexp )               (precedence override)
+//	             | < <identifier> >           (named automaton)             [OPTIONAL]
+//	             | <n-m>                      (numerical interval)          [OPTIONAL]
+//	charexp    ::= <Unicode character>        (a single non-reserved character)
+//	             | \d | \D | \s | \S | \w | \W (a predefined character class)
+//	             | \ <Unicode character>      (a single character)
+//
+// The productions marked [OPTIONAL] are only allowed if specified by the syntax flags passed to
+// the RegExp constructor. The reserved characters used in the (enabled) syntax must be escaped with
+// backslash (\) or double-quotes ("..."). (In contrast to other regexp syntaxes, this is required
+// also in character classes.) Be aware that dash (-) has a special meaning in charclass expressions.
+// An identifier is a string not containing right angle bracket (>) or dash (-). Numerical intervals
+// are specified by non-negative decimal integers and include both end points, and if n and m have
+// the same number of digits, then the conforming strings must have that length (i.e. prefixed by 0's).
+type RegExp struct {
+	kind RegExpKind
+
+	// The left child of the expression
+	exp1 *RegExp
+
+	// The right child of the expression
+	exp2 *RegExp
+
+	// String expression
+	s string
+
+	// Character expression
+	c int
+
+	// Limits for repeatable type expressions
+	min, max, digits int
+
+	// Extents for range type expressions
+	from, to int
+
+	// The string that was used to construct the regex. Compare to toString.
+	originalString string
+
+	// Syntax and match flags
+	flags int
+
+	pos int
+}
+
+// NewRegExp Constructs new RegExp from a string. Same as NewRegExpV1(s, REGEXP_FLAG_ALL).
+func NewRegExp(s string) (*RegExp, error) {
+	return NewRegExpV1(s, REGEXP_FLAG_ALL)
+}
+
+// NewRegExpV1 Constructs new RegExp from a string.
+func NewRegExpV1(s string, syntaxFlags int) (*RegExp, error) {
+	return NewRegExpV2(s, syntaxFlags, 0)
+}
+
+// NewRegExpV2 Constructs new RegExp from a string.
+// s: regexp string
+// syntaxFlags: boolean 'or' of optional syntax constructs to be enabled
+// matchFlags: boolean 'or' of match behavior options such as case insensitivity
+func NewRegExpV2(s string, syntaxFlags, matchFlags int) (*RegExp, error) {
+	if syntaxFlags > REGEXP_FLAG_ALL {
+		return nil, errors.New("illegal syntax flag")
+	}
+	if matchFlags > 0 && matchFlags <= REGEXP_FLAG_ALL {
+		return nil, errors.New("illegal match flag")
+	}
+
+	r := &RegExp{
+		originalString: s,
+		flags:          syntaxFlags | matchFlags,
+	}
+
+	var e *RegExp
+	if len(s) == 0 {
+		e = makeStringExp(r.flags, "")
+	} else {
+		var err error
+		e, err = r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		if r.pos < len(r.originalString) {
+			return nil, fmt.Errorf("end-of-string expected at position %d", r.pos)
+		}
+	}
+
+	r.kind = e.kind
+	r.exp1 = e.exp1
+	r.exp2 = e.exp2
+	r.s = e.s
+	r.c = e.c
+	r.min = e.min
+	r.max = e.max
+	r.digits = e.digits
+	r.from = e.from
+	r.to = e.to
+	return r, nil
+}
+
+// ToAutomaton Constructs new Automaton from this RegExp. Same as
+// ToAutomatonWithProvider(nil, DEFAULT_DETERMINIZE_WORK_LIMIT) (empty automaton map).
+func (r *RegExp) ToAutomaton() (*Automaton, error) {
+	return r.toAutomaton(nil, nil, DEFAULT_DETERMINIZE_WORK_LIMIT)
+}
+
+// ToAutomatonWithLimit Constructs new Automaton from this RegExp. The constructed automaton is
+// minimal and deterministic and has no transitions to dead states.
+//
+// determinizeWorkLimit: maximum effort to spend while determinizing the automata. If determinizing
+// the automata would require more than this effort, ErrTooComplexToDeterminize is returned. Higher
+// numbers require more space but can process more complex regexes.
+func (r *RegExp) ToAutomatonWithLimit(determinizeWorkLimit int) (*Automaton, error) {
+	return r.toAutomaton(nil, nil, determinizeWorkLimit)
+}
+
+// ToAutomatonWithProvider Constructs new Automaton from this RegExp. The constructed automaton is
+// minimal and deterministic and has no transitions to dead states.
+//
+// provider: provider of automata for named identifiers
+// determinizeWorkLimit: maximum effort to spend while determinizing the automata.
+func (r *RegExp) ToAutomatonWithProvider(provider AutomatonProvider, determinizeWorkLimit int) (*Automaton, error) {
+	return r.toAutomaton(nil, provider, determinizeWorkLimit)
+}
+
+// ToAutomatonWithMap Constructs new Automaton from this RegExp. The constructed automaton is
+// minimal and deterministic and has no transitions to dead states.
+//
+// automata: a map from automaton identifiers to automata (of type Automaton).
+// determinizeWorkLimit: maximum effort to spend while determinizing the automata.
+func (r *RegExp) ToAutomatonWithMap(automata map[string]*Automaton, determinizeWorkLimit int) (*Automaton, error) {
+	return r.toAutomaton(automata, nil, determinizeWorkLimit)
+}
+
+func (r *RegExp) toAutomaton(automata map[string]*Automaton, provider AutomatonProvider, determinizeWorkLimit int) (*Automaton, error) {
+	a, err := r.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
+	if a.IsDeterministic() {
+		return a, nil
+	}
+	// bounded repeats and named automata are not minimized on the way up
+	return Minimize(a, determinizeWorkLimit)
+}
+
+func (r *RegExp) toAutomatonInternal(automata map[string]*Automaton,
+	provider AutomatonProvider, determinizeWorkLimit int) (*Automaton, error) {
+
+	switch r.kind {
+	case REGEXP_PRE_CLASS:
+		expanded, err := r.expandPredefined()
+		if err != nil {
+			return nil, err
+		}
+		return expanded.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+
+	case REGEXP_UNION, REGEXP_CONCATENATION:
+		list := make([]*Automaton, 0)
+		if err := r.exp1.findLeaves(r.kind, &list, automata, provider, determinizeWorkLimit); err != nil {
+			return nil, err
+		}
+		if err := r.exp2.findLeaves(r.kind, &list, automata, provider, determinizeWorkLimit); err != nil {
+			return nil, err
+		}
+		if r.kind == REGEXP_UNION {
+			return Minimize(Union(list...), determinizeWorkLimit)
+		}
+		return Minimize(Concatenate(list...), determinizeWorkLimit)
+
+	case REGEXP_INTERSECTION:
+		a1, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		a2, err := r.exp2.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		return Minimize(Intersection(a1, a2), determinizeWorkLimit)
+
+	case REGEXP_OPTIONAL:
+		a, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		return Minimize(Optional(a), determinizeWorkLimit)
+
+	case REGEXP_REPEAT:
+		a, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		return Minimize(Repeat(a), determinizeWorkLimit)
+
+	case REGEXP_REPEAT_MIN:
+		a, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		minNumStates := (a.GetNumStates() - 1) * r.min
+		if minNumStates > determinizeWorkLimit {
+			return nil, fmt.Errorf("%w: %s would need %d states", ErrTooComplexToDeterminize, r.originalString, minNumStates)
+		}
+		return Minimize(RepeatMin(a, r.min), determinizeWorkLimit)
+
+	case REGEXP_REPEAT_MINMAX:
+		a, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		minMaxNumStates := (a.GetNumStates() - 1) * r.max
+		if minMaxNumStates > determinizeWorkLimit {
+			return nil, fmt.Errorf("%w: %s would need %d states", ErrTooComplexToDeterminize, r.originalString, minMaxNumStates)
+		}
+		return RepeatMinMax(a, r.min, r.max), nil
+
+	case REGEXP_COMPLEMENT:
+		a, err := r.exp1.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		a, err = Complement(a, determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		return Minimize(a, determinizeWorkLimit)
+
+	case REGEXP_CHAR:
+		if r.check(REGEXP_FLAG_ASCII_CASE_INSENSITIVE) {
+			return r.toCaseInsensitiveChar(r.c, determinizeWorkLimit)
+		}
+		return MakeChar(r.c), nil
+
+	case REGEXP_CHAR_RANGE:
+		return MakeCharRange(r.from, r.to), nil
+
+	case REGEXP_ANYCHAR:
+		return MakeAnyChar(), nil
+
+	case REGEXP_EMPTY:
+		return MakeEmpty(), nil
+
+	case REGEXP_STRING:
+		if r.check(REGEXP_FLAG_ASCII_CASE_INSENSITIVE) {
+			return r.toCaseInsensitiveString(determinizeWorkLimit)
+		}
+		return MakeString(r.s), nil
+
+	case REGEXP_ANYSTRING:
+		return MakeAnyString(), nil
+
+	case REGEXP_AUTOMATON:
+		var aa *Automaton
+		if automata != nil {
+			aa = automata[r.s]
+		}
+		if aa == nil && provider != nil {
+			var err error
+			aa, err = provider.GetAutomaton(r.s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if aa == nil {
+			return nil, fmt.Errorf("'%s' not found", r.s)
+		}
+		return aa, nil
+
+	case REGEXP_INTERVAL:
+		return MakeDecimalInterval(r.min, r.max, r.digits)
+
+	default:
+		return nil, fmt.Errorf("unknown regexp kind %d", r.kind)
+	}
+}
+
+func (r *RegExp) toCaseInsensitiveChar(codepoint, determinizeWorkLimit int) (*Automaton, error) {
+	case1 := MakeChar(codepoint)
+	// For now we only work with ASCII characters
+	if codepoint > 128 {
+		return case1, nil
+	}
+
+	altCase := int(unicode.ToLower(rune(codepoint)))
+	if unicode.IsLower(rune(codepoint)) {
+		altCase = int(unicode.ToUpper(rune(codepoint)))
+	}
+	if altCase == codepoint {
+		return case1, nil
+	}
+	return Minimize(Union(case1, MakeChar(altCase)), determinizeWorkLimit)
+}
+
+func (r *RegExp) toCaseInsensitiveString(determinizeWorkLimit int) (*Automaton, error) {
+	list := make([]*Automaton, 0, len(r.s))
+	for _, c := range r.s {
+		a, err := r.toCaseInsensitiveChar(int(c), determinizeWorkLimit)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return Minimize(Concatenate(list...), determinizeWorkLimit)
+}
+
+func (r *RegExp) findLeaves(kind RegExpKind, list *[]*Automaton, automata map[string]*Automaton,
+	provider AutomatonProvider, determinizeWorkLimit int) error {
+
+	if r.kind == kind {
+		if err := r.exp1.findLeaves(kind, list, automata, provider, determinizeWorkLimit); err != nil {
+			return err
+		}
+		return r.exp2.findLeaves(kind, list, automata, provider, determinizeWorkLimit)
+	}
+
+	a, err := r.toAutomatonInternal(automata, provider, determinizeWorkLimit)
+	if err != nil {
+		return err
+	}
+	*list = append(*list, a)
+	return nil
+}
+
+// GetOriginalString The string that was used to construct the regex. Compare to String.
+func (r *RegExp) GetOriginalString() string {
+	return r.originalString
+}
+
+// String Constructs string from parsed regular expression.
+func (r *RegExp) String() string {
+	b := new(strings.Builder)
+	r.toStringBuilder(b)
+	return b.String()
+}
+
+func (r *RegExp) toStringBuilder(b *strings.Builder) {
+	switch r.kind {
+	case REGEXP_UNION:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		b.WriteString("|")
+		r.exp2.toStringBuilder(b)
+		b.WriteString(")")
+	case REGEXP_CONCATENATION:
+		r.exp1.toStringBuilder(b)
+		r.exp2.toStringBuilder(b)
+	case REGEXP_INTERSECTION:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		b.WriteString("&")
+		r.exp2.toStringBuilder(b)
+		b.WriteString(")")
+	case REGEXP_OPTIONAL:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		b.WriteString(")?")
+	case REGEXP_REPEAT:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		b.WriteString(")*")
+	case REGEXP_REPEAT_MIN:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		fmt.Fprintf(b, "){%d,}", r.min)
+	case REGEXP_REPEAT_MINMAX:
+		b.WriteString("(")
+		r.exp1.toStringBuilder(b)
+		fmt.Fprintf(b, "){%d,%d}", r.min, r.max)
+	case REGEXP_COMPLEMENT:
+		b.WriteString("~(")
+		r.exp1.toStringBuilder(b)
+		b.WriteString(")")
+	case REGEXP_CHAR:
+		b.WriteString("\\")
+		b.WriteRune(rune(r.c))
+	case REGEXP_CHAR_RANGE:
+		b.WriteString("[\\")
+		b.WriteRune(rune(r.from))
+		b.WriteString("-\\")
+		b.WriteRune(rune(r.to))
+		b.WriteString("]")
+	case REGEXP_ANYCHAR:
+		b.WriteString(".")
+	case REGEXP_EMPTY:
+		b.WriteString("#")
+	case REGEXP_STRING:
+		b.WriteString("\"")
+		b.WriteString(r.s)
+		b.WriteString("\"")
+	case REGEXP_ANYSTRING:
+		b.WriteString("@")
+	case REGEXP_AUTOMATON:
+		b.WriteString("<")
+		b.WriteString(r.s)
+		b.WriteString(">")
+	case REGEXP_INTERVAL:
+		s1 := strconv.Itoa(r.min)
+		s2 := strconv.Itoa(r.max)
+		b.WriteString("<")
+		if r.digits > 0 && len(s1) < r.digits {
+			b.WriteString(strings.Repeat("0", r.digits-len(s1)))
+		}
+		b.WriteString(s1)
+		b.WriteString("-")
+		if r.digits > 0 && len(s2) < r.digits {
+			b.WriteString(strings.Repeat("0", r.digits-len(s2)))
+		}
+		b.WriteString(s2)
+		b.WriteString(">")
+	case REGEXP_PRE_CLASS:
+		b.WriteString("\\")
+		b.WriteRune(rune(r.from))
+	}
+}
+
+// GetIdentifiers Returns the sorted set of automaton identifiers that occur in this regular expression.
+func (r *RegExp) GetIdentifiers() []string {
+	set := make(map[string]struct{})
+	r.getIdentifiers(set)
+
+	identifiers := make([]string, 0, len(set))
+	for identifier := range set {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+	return identifiers
+}
+
+func (r *RegExp) getIdentifiers(set map[string]struct{}) {
+	switch r.kind {
+	case REGEXP_UNION, REGEXP_CONCATENATION, REGEXP_INTERSECTION:
+		r.exp1.getIdentifiers(set)
+		r.exp2.getIdentifiers(set)
+	case REGEXP_OPTIONAL, REGEXP_REPEAT, REGEXP_REPEAT_MIN, REGEXP_REPEAT_MINMAX, REGEXP_COMPLEMENT:
+		r.exp1.getIdentifiers(set)
+	case REGEXP_AUTOMATON:
+		set[r.s] = struct{}{}
+	}
+}
+
+func (r *RegExp) expandPredefined() (*RegExp, error) {
+	// See https://docs.oracle.com/javase/tutorial/essential/regex/pre_char_classes.html
+	switch r.from {
+	case 'd':
+		return NewRegExp("[0-9]") // digit
+	case 'D':
+		return NewRegExp("[^0-9]") // non-digit
+	case 's':
+		return NewRegExp("[ \t\n\r]") // whitespace
+	case 'S':
+		return NewRegExp("[^\\s]") // non-whitespace
+	case 'w':
+		return NewRegExp("[a-zA-Z_0-9]") // word
+	case 'W':
+		return NewRegExp("[^\\w]") // non-word
+	default:
+		return nil, fmt.Errorf("invalid character class %c", r.from)
+	}
+}
+
+func makeUnionExp(flags int, exp1, exp2 *RegExp) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_UNION, exp1: exp1, exp2: exp2}
+}
+
+func isCharOrString(exp *RegExp) bool {
+	return exp.kind == REGEXP_CHAR || exp.kind == REGEXP_STRING
+}
+
+func makeConcatenationExp(flags int, exp1, exp2 *RegExp) *RegExp {
+	if isCharOrString(exp1) && isCharOrString(exp2) {
+		return makeStringFromExps(flags, exp1, exp2)
+	}
+
+	rexp1, rexp2 := exp1, exp2
+	if exp1.kind == REGEXP_CONCATENATION && isCharOrString(exp1.exp2) && isCharOrString(exp2) {
+		rexp1 = exp1.exp1
+		rexp2 = makeStringFromExps(flags, exp1.exp2, exp2)
+	} else if isCharOrString(exp1) && exp2.kind == REGEXP_CONCATENATION && isCharOrString(exp2.exp1) {
+		rexp1 = makeStringFromExps(flags, exp1, exp2.exp1)
+		rexp2 = exp2.exp2
+	}
+	return &RegExp{flags: flags, kind: REGEXP_CONCATENATION, exp1: rexp1, exp2: rexp2}
+}
+
+func makeStringFromExps(flags int, exp1, exp2 *RegExp) *RegExp {
+	b := new(strings.Builder)
+	for _, exp := range []*RegExp{exp1, exp2} {
+		if exp.kind == REGEXP_STRING {
+			b.WriteString(exp.s)
+		} else {
+			b.WriteRune(rune(exp.c))
+		}
+	}
+	return makeStringExp(flags, b.String())
+}
+
+func makeIntersectionExp(flags int, exp1, exp2 *RegExp) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_INTERSECTION, exp1: exp1, exp2: exp2}
+}
+
+func makeOptionalExp(flags int, exp *RegExp) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_OPTIONAL, exp1: exp}
+}
+
+func makeRepeatExp(flags int, exp *RegExp) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_REPEAT, exp1: exp}
+}
+
+func makeRepeatMinExp(flags int, exp *RegExp, min int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_REPEAT_MIN, exp1: exp, min: min}
+}
+
+func makeRepeatMinMaxExp(flags int, exp *RegExp, min, max int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_REPEAT_MINMAX, exp1: exp, min: min, max: max}
+}
+
+func makeComplementExp(flags int, exp *RegExp) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_COMPLEMENT, exp1: exp}
+}
+
+func makeCharExp(flags int, c int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_CHAR, c: c}
+}
+
+func makeCharRangeExp(flags int, from, to int) (*RegExp, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: from (%d) cannot be > to (%d)", from, to)
+	}
+	return &RegExp{flags: flags, kind: REGEXP_CHAR_RANGE, from: from, to: to}, nil
+}
+
+func makeAnyCharExp(flags int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_ANYCHAR}
+}
+
+func makeEmptyExp(flags int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_EMPTY}
+}
+
+func makeStringExp(flags int, s string) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_STRING, s: s}
+}
+
+func makeAnyStringExp(flags int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_ANYSTRING}
+}
+
+func makeAutomatonExp(flags int, s string) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_AUTOMATON, s: s}
+}
+
+func makeIntervalExp(flags int, min, max, digits int) *RegExp {
+	return &RegExp{flags: flags, kind: REGEXP_INTERVAL, min: min, max: max, digits: digits}
+}
+
+func (r *RegExp) peek(s string) bool {
+	if !r.more() {
+		return false
+	}
+	c, _ := utf8.DecodeRuneInString(r.originalString[r.pos:])
+	return strings.ContainsRune(s, c)
+}
+
+func (r *RegExp) match(c rune) bool {
+	if r.pos >= len(r.originalString) {
+		return false
+	}
+	cp, size := utf8.DecodeRuneInString(r.originalString[r.pos:])
+	if cp == c {
+		r.pos += size
+		return true
+	}
+	return false
+}
+
+func (r *RegExp) more() bool {
+	return r.pos < len(r.originalString)
+}
+
+func (r *RegExp) next() (int, error) {
+	if !r.more() {
+		return 0, errors.New("unexpected end-of-string")
+	}
+	c, size := utf8.DecodeRuneInString(r.originalString[r.pos:])
+	r.pos += size
+	return int(c), nil
+}
+
+func (r *RegExp) check(flag int) bool {
+	return r.flags&flag != 0
+}
+
+func (r *RegExp) parseUnionExp() (*RegExp, error) {
+	e, err := r.parseInterExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.match('|') {
+		e2, err := r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		e = makeUnionExp(r.flags, e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseInterExp() (*RegExp, error) {
+	e, err := r.parseConcatExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.check(REGEXP_FLAG_INTERSECTION) && r.match('&') {
+		e2, err := r.parseInterExp()
+		if err != nil {
+			return nil, err
+		}
+		e = makeIntersectionExp(r.flags, e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseConcatExp() (*RegExp, error) {
+	e, err := r.parseRepeatExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.more() && !r.peek(")|") && (!r.check(REGEXP_FLAG_INTERSECTION) || !r.peek("&")) {
+		e2, err := r.parseConcatExp()
+		if err != nil {
+			return nil, err
+		}
+		e = makeConcatenationExp(r.flags, e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseRepeatExp() (*RegExp, error) {
+	e, err := r.parseComplExp()
+	if err != nil {
+		return nil, err
+	}
+
+	for r.peek("?*+{") {
+		switch {
+		case r.match('?'):
+			e = makeOptionalExp(r.flags, e)
+		case r.match('*'):
+			e = makeRepeatExp(r.flags, e)
+		case r.match('+'):
+			e = makeRepeatMinExp(r.flags, e, 1)
+		case r.match('{'):
+			start := r.pos
+			for r.peek("0123456789") {
+				r.pos++
+			}
+			if start == r.pos {
+				return nil, fmt.Errorf("integer expected at position %d", r.pos)
+			}
+			n, err := strconv.Atoi(r.originalString[start:r.pos])
+			if err != nil {
+				return nil, err
+			}
+
+			m := -1
+			if r.match(',') {
+				start = r.pos
+				for r.peek("0123456789") {
+					r.pos++
+				}
+				if start != r.pos {
+					m, err = strconv.Atoi(r.originalString[start:r.pos])
+					if err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				m = n
+			}
+
+			if !r.match('}') {
+				return nil, fmt.Errorf("expected '}' at position %d", r.pos)
+			}
+
+			if m == -1 {
+				e = makeRepeatMinExp(r.flags, e, n)
+			} else {
+				e = makeRepeatMinMaxExp(r.flags, e, n, m)
+			}
+		}
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseComplExp() (*RegExp, error) {
+	if r.check(REGEXP_FLAG_COMPLEMENT) && r.match('~') {
+		e, err := r.parseComplExp()
+		if err != nil {
+			return nil, err
+		}
+		return makeComplementExp(r.flags, e), nil
+	}
+	return r.parseCharClassExp()
+}
+
+func (r *RegExp) parseCharClassExp() (*RegExp, error) {
+	if !r.match('[') {
+		return r.parseSimpleExp()
+	}
+
+	negate := r.match('^')
+	e, err := r.parseCharClasses()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		e = makeIntersectionExp(r.flags, makeAnyCharExp(r.flags), makeComplementExp(r.flags, e))
+	}
+	if !r.match(']') {
+		return nil, fmt.Errorf("expected ']' at position %d", r.pos)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseCharClasses() (*RegExp, error) {
+	e, err := r.parseCharClass()
+	if err != nil {
+		return nil, err
+	}
+	for r.more() && !r.peek("]") {
+		e2, err := r.parseCharClass()
+		if err != nil {
+			return nil, err
+		}
+		e = makeUnionExp(r.flags, e, e2)
+	}
+	return e, nil
+}
+
+func (r *RegExp) parseCharClass() (*RegExp, error) {
+	predefinedExp, err := r.matchPredefinedCharacterClass()
+	if err != nil {
+		return nil, err
+	}
+	if predefinedExp != nil {
+		return predefinedExp, nil
+	}
+
+	c, err := r.parseCharExp()
+	if err != nil {
+		return nil, err
+	}
+	if r.match('-') {
+		to, err := r.parseCharExp()
+		if err != nil {
+			return nil, err
+		}
+		return makeCharRangeExp(r.flags, c, to)
+	}
+	return makeCharExp(r.flags, c), nil
+}
+
+func (r *RegExp) matchPredefinedCharacterClass() (*RegExp, error) {
+	// See https://docs.oracle.com/javase/tutorial/essential/regex/pre_char_classes.html
+	if !r.match('\\') {
+		return nil, nil
+	}
+
+	if r.peek("dDwWsS") {
+		from, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		return &RegExp{flags: r.flags, kind: REGEXP_PRE_CLASS, from: from}, nil
+	}
+
+	if r.peek("\\") {
+		c, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		return makeCharExp(r.flags, c), nil
+	}
+
+	// From https://docs.oracle.com/javase/7/docs/api/java/util/regex/Pattern.html#bs
+	// "It is an error to use a backslash prior to any alphabetic character that does not denote an escaped
+	// construct;"
+	if r.peek("abcefghijklmnopqrtuvxyz") || r.peek("ABCEFGHIJKLMNOPQRTUVXYZ") {
+		c, _ := r.next()
+		return nil, fmt.Errorf("invalid character class \\%c", c)
+	}
+	return nil, nil
+}
+
+func (r *RegExp) parseSimpleExp() (*RegExp, error) {
+	switch {
+	case r.match('.'):
+		return makeAnyCharExp(r.flags), nil
+
+	case r.check(REGEXP_FLAG_EMPTY) && r.match('#'):
+		return makeEmptyExp(r.flags), nil
+
+	case r.check(REGEXP_FLAG_ANYSTRING) && r.match('@'):
+		return makeAnyStringExp(r.flags), nil
+
+	case r.match('"'):
+		start := r.pos
+		for r.more() && !r.peek("\"") {
+			if _, err := r.next(); err != nil {
+				return nil, err
+			}
+		}
+		if !r.match('"') {
+			return nil, fmt.Errorf("expected '\"' at position %d", r.pos)
+		}
+		return makeStringExp(r.flags, r.originalString[start:r.pos-1]), nil
+
+	case r.match('('):
+		if r.match(')') {
+			return makeStringExp(r.flags, ""), nil
+		}
+		e, err := r.parseUnionExp()
+		if err != nil {
+			return nil, err
+		}
+		if !r.match(')') {
+			return nil, fmt.Errorf("expected ')' at position %d", r.pos)
+		}
+		return e, nil
+
+	case (r.check(REGEXP_FLAG_AUTOMATON) || r.check(REGEXP_FLAG_INTERVAL)) && r.match('<'):
+		start := r.pos
+		for r.more() && !r.peek(">") {
+			if _, err := r.next(); err != nil {
+				return nil, err
+			}
+		}
+		if !r.match('>') {
+			return nil, fmt.Errorf("expected '>' at position %d", r.pos)
+		}
+		s := r.originalString[start : r.pos-1]
+		i := strings.IndexByte(s, '-')
+		if i == -1 {
+			if !r.check(REGEXP_FLAG_AUTOMATON) {
+				return nil, fmt.Errorf("interval syntax error at position %d", r.pos-1)
+			}
+			return makeAutomatonExp(r.flags, s), nil
+		}
+
+		if !r.check(REGEXP_FLAG_INTERVAL) {
+			return nil, fmt.Errorf("illegal identifier at position %d", r.pos-1)
+		}
+		if i == 0 || i == len(s)-1 || i != strings.LastIndexByte(s, '-') {
+			return nil, fmt.Errorf("interval syntax error at position %d", r.pos-1)
+		}
+		smin, smax := s[:i], s[i+1:]
+		imin, err := strconv.Atoi(smin)
+		if err != nil {
+			return nil, fmt.Errorf("interval syntax error at position %d", r.pos-1)
+		}
+		imax, err := strconv.Atoi(smax)
+		if err != nil {
+			return nil, fmt.Errorf("interval syntax error at position %d", r.pos-1)
+		}
+		digits := 0
+		if len(smin) == len(smax) {
+			digits = len(smin)
+		}
+		if imin > imax {
+			imin, imax = imax, imin
+		}
+		return makeIntervalExp(r.flags, imin, imax, digits), nil
+
+	default:
+		predefined, err := r.matchPredefinedCharacterClass()
+		if err != nil {
+			return nil, err
+		}
+		if predefined != nil {
+			return predefined, nil
+		}
+		c, err := r.parseCharExp()
+		if err != nil {
+			return nil, err
+		}
+		return makeCharExp(r.flags, c), nil
+	}
+}
+
+func (r *RegExp) parseCharExp() (int, error) {
+	r.match('\\')
+	return r.next()
+}
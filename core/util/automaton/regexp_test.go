@@ -0,0 +1,167 @@
+package automaton
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRegExpRunAutomaton(t *testing.T, pattern string, syntaxFlags, matchFlags int) *CharacterRunAutomaton {
+	regexp, err := NewRegExpV2(pattern, syntaxFlags, matchFlags)
+	assert.Nil(t, err, pattern)
+	a, err := regexp.ToAutomaton()
+	assert.Nil(t, err, pattern)
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err, pattern)
+	return matcher
+}
+
+// assertRegExp Checks that pattern, parsed with syntaxFlags, accepts and rejects the given strings
+func assertRegExp(t *testing.T, pattern string, syntaxFlags int, accepted []string, rejected []string) {
+	matcher := newRegExpRunAutomaton(t, pattern, syntaxFlags, 0)
+	for _, s := range accepted {
+		assert.True(t, matcher.Run(s), "%s should accept %q", pattern, s)
+	}
+	for _, s := range rejected {
+		assert.False(t, matcher.Run(s), "%s should reject %q", pattern, s)
+	}
+}
+
+func TestRegExp_Repeat(t *testing.T) {
+	assertRegExp(t, "ab*c", REGEXP_FLAG_ALL, []string{"ac", "abc", "abbbc"}, []string{"", "ab", "abd", "abcc"})
+	assertRegExp(t, "(ab|cd)+", REGEXP_FLAG_ALL, []string{"ab", "cd", "abcdab"}, []string{"", "abc", "ac"})
+	assertRegExp(t, "ab?", REGEXP_FLAG_ALL, []string{"a", "ab"}, []string{"", "abb"})
+	assertRegExp(t, "a{2}", REGEXP_FLAG_ALL, []string{"aa"}, []string{"a", "aaa"})
+	assertRegExp(t, "a{2,}", REGEXP_FLAG_ALL, []string{"aa", "aaaaa"}, []string{"", "a"})
+	assertRegExp(t, "a{1,3}", REGEXP_FLAG_ALL, []string{"a", "aa", "aaa"}, []string{"", "aaaa"})
+	assertRegExp(t, "()", REGEXP_FLAG_ALL, []string{""}, []string{"a"})
+
+	// any single code point
+	assertRegExp(t, "a.c", REGEXP_FLAG_ALL, []string{"abc", "aéc", "a世c", "a😀c"}, []string{"ac", "abbc"})
+}
+
+func TestRegExp_CharacterClasses(t *testing.T) {
+	assertRegExp(t, "[a-c]x", REGEXP_FLAG_ALL, []string{"ax", "bx", "cx"}, []string{"dx", "x", "abx"})
+	assertRegExp(t, "[^a-c]x", REGEXP_FLAG_ALL, []string{"dx", "éx", "世x"}, []string{"ax", "cx", "x"})
+	assertRegExp(t, "[a-cx-z0]+", REGEXP_FLAG_ALL, []string{"a0y", "zzz"}, []string{"d", ""})
+	assertRegExp(t, "[à-ö]", REGEXP_FLAG_ALL, []string{"à", "é", "ö"}, []string{"a", "ø"})
+	// reserved characters are escaped, also in classes
+	assertRegExp(t, `[a\-z]`, REGEXP_FLAG_ALL, []string{"a", "-", "z"}, []string{"b"})
+	assertRegExp(t, `[\]]`, REGEXP_FLAG_ALL, []string{"]"}, []string{`\`})
+
+	// predefined classes
+	assertRegExp(t, `\d+`, REGEXP_FLAG_ALL, []string{"0", "123"}, []string{"", "1a"})
+	assertRegExp(t, `\D`, REGEXP_FLAG_ALL, []string{"a", "-"}, []string{"5"})
+	assertRegExp(t, `\w+`, REGEXP_FLAG_ALL, []string{"a_Z9"}, []string{"a-b", " "})
+	assertRegExp(t, `\W`, REGEXP_FLAG_ALL, []string{"-", " "}, []string{"a", "_"})
+	assertRegExp(t, `a\sb`, REGEXP_FLAG_ALL, []string{"a b", "a\tb", "a\nb"}, []string{"ab", "axb"})
+	assertRegExp(t, `\S`, REGEXP_FLAG_ALL, []string{"a"}, []string{" "})
+	assertRegExp(t, `[\d_]+`, REGEXP_FLAG_ALL, []string{"1_2"}, []string{"a"})
+}
+
+func TestRegExp_Strings(t *testing.T) {
+	assertRegExp(t, `"a*b"`, REGEXP_FLAG_ALL, []string{"a*b"}, []string{"b", "aab"})
+	assertRegExp(t, `a\*b`, REGEXP_FLAG_ALL, []string{"a*b"}, []string{"b", "aab"})
+	assertRegExp(t, `\.`, REGEXP_FLAG_ALL, []string{"."}, []string{"a"})
+}
+
+func TestRegExp_Interval(t *testing.T) {
+	// numbers with any number of leading zeros
+	assertRegExp(t, "<1-100>", REGEXP_FLAG_ALL,
+		[]string{"1", "9", "42", "99", "100", "007"},
+		[]string{"0", "101", "1000", "", "4a"})
+	// with the same number of digits, the numbers are padded with zeros
+	assertRegExp(t, "<01-10>", REGEXP_FLAG_ALL, []string{"01", "05", "10"}, []string{"1", "5", "001", "11"})
+	assertRegExp(t, "x<5-7>", REGEXP_FLAG_INTERVAL, []string{"x5", "x6", "x7"}, []string{"x4", "x8"})
+
+	// without the flag angle brackets are plain characters
+	assertRegExp(t, "<1-10>", REGEXP_FLAG_NONE, []string{"<1-10>"}, []string{"5"})
+}
+
+func TestRegExp_Complement(t *testing.T) {
+	assertRegExp(t, "~(abc)", REGEXP_FLAG_ALL, []string{"", "ab", "abcd", "x"}, []string{"abc"})
+	assertRegExp(t, "a~(b+)", REGEXP_FLAG_COMPLEMENT, []string{"a", "ac", "abc"}, []string{"ab", "abbb"})
+
+	assertRegExp(t, "~a", REGEXP_FLAG_NONE, []string{"~a"}, []string{"b", ""})
+	assertRegExp(t, "~a", REGEXP_FLAG_INTERSECTION, []string{"~a"}, []string{"b"})
+}
+
+func TestRegExp_Intersection(t *testing.T) {
+	assertRegExp(t, "[a-z]+&.*ab.*", REGEXP_FLAG_ALL, []string{"ab", "xaby"}, []string{"xay", "a1ab", ""})
+	assertRegExp(t, "a.c&.b.", REGEXP_FLAG_INTERSECTION, []string{"abc"}, []string{"axc", "abd"})
+	assertRegExp(t, "a&b", REGEXP_FLAG_INTERSECTION, nil, []string{"a", "b", ""})
+
+	assertRegExp(t, "a&b", REGEXP_FLAG_NONE, []string{"a&b"}, []string{"a"})
+	assertRegExp(t, "a&b", REGEXP_FLAG_COMPLEMENT, []string{"a&b"}, []string{"a"})
+}
+
+func TestRegExp_EmptyAndAnyString(t *testing.T) {
+	assertRegExp(t, "#", REGEXP_FLAG_ALL, nil, []string{"", "#"})
+	assertRegExp(t, "#", REGEXP_FLAG_NONE, []string{"#"}, []string{""})
+	assertRegExp(t, "@", REGEXP_FLAG_ALL, []string{"", "abc", "@"}, nil)
+	assertRegExp(t, "@", REGEXP_FLAG_NONE, []string{"@"}, []string{"", "abc"})
+	assertRegExp(t, "@&~(.*foo.*)", REGEXP_FLAG_ALL, []string{"", "fo", "bar"}, []string{"foo", "afoob"})
+}
+
+func TestRegExp_CaseInsensitive(t *testing.T) {
+	matcher := newRegExpRunAutomaton(t, "ab[c-d]é", REGEXP_FLAG_ALL, REGEXP_FLAG_ASCII_CASE_INSENSITIVE)
+	for _, s := range []string{"abcé", "ABcé", "aBdé"} {
+		assert.True(t, matcher.Run(s), s)
+	}
+	// only ASCII letters ignore case, ranges do not
+	assert.False(t, matcher.Run("abcÉ"))
+	assert.False(t, matcher.Run("abCé"))
+
+	matcher = newRegExpRunAutomaton(t, "abc", REGEXP_FLAG_ALL, 0)
+	assert.False(t, matcher.Run("ABC"))
+}
+
+func TestRegExp_NamedAutomata(t *testing.T) {
+	digits, err := NewRegExp(`\d+`)
+	assert.Nil(t, err)
+	digitsAutomaton, err := digits.ToAutomaton()
+	assert.Nil(t, err)
+
+	regexp, err := NewRegExp("id<digits>")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"digits"}, regexp.GetIdentifiers())
+	a, err := regexp.ToAutomatonWithMap(map[string]*Automaton{"digits": digitsAutomaton}, DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.Nil(t, err)
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+	assert.True(t, matcher.Run("id42"))
+	assert.False(t, matcher.Run("id"))
+
+	_, err = regexp.ToAutomatonWithMap(map[string]*Automaton{}, DEFAULT_DETERMINIZE_WORK_LIMIT)
+	assert.NotNil(t, err)
+}
+
+func TestRegExp_ParseErrors(t *testing.T) {
+	for _, pattern := range []string{"(ab", "ab)", "[a-", "[]", "a{2", "a{x}", `"abc`, `a\`, "<1-"} {
+		_, err := NewRegExp(pattern)
+		assert.NotNil(t, err, pattern)
+	}
+}
+
+func TestRegExp_DeterminizeWorkLimit(t *testing.T) {
+	// the DFA of a string with an "a" n characters from its end has 2^n states
+	regexp, err := NewRegExp("(a|b)*a(a|b){20}")
+	assert.Nil(t, err)
+	_, err = regexp.ToAutomaton()
+	assert.True(t, errors.Is(err, ErrTooComplexToDeterminize), "%v", err)
+
+	regexp, err = NewRegExp("(a|b)*a(a|b){10}")
+	assert.Nil(t, err)
+	a, err := regexp.ToAutomaton()
+	assert.Nil(t, err)
+	assert.Equal(t, 2048, a.GetNumStates())
+	matcher, err := NewCharacterRunAutomaton(a)
+	assert.Nil(t, err)
+	assert.True(t, matcher.Run("bbabbbbbbbbbb"))
+	assert.False(t, matcher.Run("bbbabbbbbbbbb"))
+
+	// the same pattern is rejected with a lower limit
+	_, err = regexp.ToAutomatonWithLimit(10)
+	assert.True(t, errors.Is(err, ErrTooComplexToDeterminize), "%v", err)
+}
@@ -18,12 +18,16 @@ type RunAutomaton struct {
 	classmap []int
 }
 
-func NewRunAutomatonV1(a *Automaton, alphabetSize, determinizeWorkLimit int) *RunAutomaton {
+func NewRunAutomatonV1(a *Automaton, alphabetSize, determinizeWorkLimit int) (*RunAutomaton, error) {
+	a, err := DeterminizeAutomaton(a, determinizeWorkLimit)
+	if err != nil {
+		return nil, err
+	}
 	size := Max(1, a.GetNumStates())
 	points := a.GetStartPoints()
 
 	r := RunAutomaton{
-		automaton:    DeterminizeAutomaton(a, determinizeWorkLimit),
+		automaton:    a,
 		alphabetSize: alphabetSize,
 		size:         size,
 		accept:       make([]bool, size),
@@ -38,7 +42,8 @@ func NewRunAutomatonV1(a *Automaton, alphabetSize, determinizeWorkLimit int) *Ru
 
 	transition := &Transition{}
 
-	for n := 0; n < size; n++ {
+	// an automaton without states still has the initial state, which rejects everything
+	for n := 0; n < a.GetNumStates(); n++ {
 		r.accept[n] = a.IsAccept(n)
 		transition.Source = n
 		transition.TransitionUpto = -1
@@ -56,7 +61,7 @@ func NewRunAutomatonV1(a *Automaton, alphabetSize, determinizeWorkLimit int) *Ru
 		r.classmap[j] = i
 	}
 
-	return &r
+	return &r, nil
 }
 
 // GetSize Returns number of states in automaton.
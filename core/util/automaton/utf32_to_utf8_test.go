@@ -0,0 +1,110 @@
+package automaton
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomCodePoint Returns a random valid code point, of a random UTF-8 length
+func randomCodePoint(r *rand.Rand) int {
+	for {
+		var c int
+		switch r.Intn(4) {
+		case 0:
+			c = r.Intn(0x80)
+		case 1:
+			c = 0x80 + r.Intn(0x800-0x80)
+		case 2:
+			c = 0x800 + r.Intn(0x10000-0x800)
+		default:
+			c = 0x10000 + r.Intn(MAX_CODE_POINT+1-0x10000)
+		}
+		if c < 0xD800 || c > 0xDFFF {
+			return c
+		}
+	}
+}
+
+func assertCodePointRange(t *testing.T, r *rand.Rand, min, max int) {
+	matcher, err := NewByteRunAutomaton(MakeCharRange(min, max))
+	assert.Nil(t, err)
+
+	codePoints := []int{min, max, min - 1, max + 1, (min + max) / 2, 0, MAX_CODE_POINT}
+	for i := 0; i < 200; i++ {
+		codePoints = append(codePoints, randomCodePoint(r))
+	}
+	for _, c := range codePoints {
+		if c < 0 || c > MAX_CODE_POINT || !utf8.ValidRune(rune(c)) {
+			continue
+		}
+		accepted := matcher.Run(utf8.AppendRune(nil, rune(c)))
+		if accepted != (c >= min && c <= max) {
+			t.Fatalf("[%#x, %#x] accepted %#x: %v", min, max, c, accepted)
+		}
+	}
+
+	// only a single complete code point is accepted
+	encoded := utf8.AppendRune(nil, rune(max))
+	assert.False(t, matcher.Run(encoded[:len(encoded)-1]))
+	assert.False(t, matcher.Run(append(encoded, encoded...)))
+	assert.False(t, matcher.Run(nil))
+}
+
+func TestUTF32ToUTF8_Ranges(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+
+	// the boundaries of the UTF-8 lengths
+	for _, bounds := range [][2]int{
+		{0, 0}, {0, 0x7F}, {0x7F, 0x80}, {0x80, 0x7FF}, {0x7FF, 0x800}, {0x800, 0xFFFF},
+		{0xFFFF, 0x10000}, {0x10000, MAX_CODE_POINT}, {0, MAX_CODE_POINT}, {0x41, 0x10FFFF},
+		{0x3F, 0x3FFF}, {0x1000, 0x1FFF}, {0x40000, 0x7FFFF}, {0x100, 0x101},
+	} {
+		assertCodePointRange(t, r, bounds[0], bounds[1])
+	}
+
+	for i := 0; i < 200; i++ {
+		c1 := randomCodePoint(r)
+		c2 := randomCodePoint(r)
+		assertCodePointRange(t, r, min(c1, c2), max(c1, c2))
+	}
+}
+
+var multiByteReplacer = strings.NewReplacer("b", "(b|😀)", "c", "[é-世]")
+
+func TestUTF32ToUTF8_Convert(t *testing.T) {
+	r := rand.New(rand.NewSource(19))
+
+	// each UTF-8 length of a code point range needs its own intermediate states
+	a := NewUTF32ToUTF8().Convert(Union(MakeCharRange(0x80, 0x7FF), MakeCharRange(0x800, 0x10FFFF)))
+	assert.Greater(t, a.GetNumStates(), 2)
+
+	// the converted automaton accepts the encodings of the strings accepted by the original one
+	alphabet := []rune{'a', 'b', 'é', '世', '😀'}
+	var strs []string
+	for _, s := range allStrings(alphabet, 4) {
+		strs = append(strs, string(s))
+	}
+	for i := 0; i < 50; i++ {
+		pattern := randomRegExp(r, 1+r.Intn(3))
+		// the random expressions only use a, b and c, mix in multi-byte characters
+		pattern = multiByteReplacer.Replace(pattern)
+		regexp, err := NewRegExp(pattern)
+		assert.Nil(t, err, pattern)
+		a, err := regexp.ToAutomaton()
+		assert.Nil(t, err, pattern)
+
+		characters, err := NewCharacterRunAutomaton(a)
+		assert.Nil(t, err, pattern)
+		bytes, err := NewByteRunAutomaton(a)
+		assert.Nil(t, err, pattern)
+		for _, s := range strs {
+			assert.Equal(t, characters.Run(s), bytes.Run([]byte(s)), "%s: %q", pattern, s)
+		}
+	}
+
+	assert.Equal(t, 0, NewUTF32ToUTF8().Convert(MakeEmpty()).GetNumStates())
+}
@@ -216,7 +216,7 @@ func readInts24Visitor(in store.IndexInput, count int, visitor types.IntersectVi
 		if err := visitor.Visit(nil, uint24(bs[3:6])); err != nil {
 			return err
 		}
-		if err := visitor.Visit(nil, uint24(bs[6:6])); err != nil {
+		if err := visitor.Visit(nil, uint24(bs[6:9])); err != nil {
 			return err
 		}
 		if err := visitor.Visit(nil, uint24(bs[9:12])); err != nil {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/geange/lucene-go/core/store"
+	"github.com/geange/lucene-go/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,3 +62,27 @@ func TestWriteDocIdsInt32(t *testing.T) {
 
 	assert.Equal(t, docIds, newDocIds)
 }
+
+func TestReadIntsVisitorInt24(t *testing.T) {
+	output := store.NewBufferDataOutput()
+	docIds := make([]int, 100)
+	for i := range docIds {
+		docIds[i] = rand.Intn(0xFFFFFF)
+	}
+	docIds[0], docIds[1] = 1, 0 // make sure the ids are not sorted
+	err := WriteDocIds(nil, docIds, output)
+	assert.Nil(t, err)
+
+	input := store.NewBytesInput(output.Bytes())
+
+	newDocIds := make([]int, 0, 100)
+	err = ReadIntsVisitor(nil, input, 100, &types.BytesVisitor{
+		VisitFn: func(docID int) error {
+			newDocIds = append(newDocIds, docID)
+			return nil
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, docIds, newDocIds)
+}
@@ -165,6 +165,10 @@ func NewReader(ctx context.Context, metaIn, indexIn, dataIn store.IndexInput) (*
 }
 
 func (r *Reader) Intersect(ctx context.Context, visitor types.IntersectVisitor) error {
+	if ctx == nil {
+		// callers on the search side don't always carry a context
+		ctx = context.Background()
+	}
 	state, err := r.GetIntersectState(ctx, visitor)
 	if err != nil {
 		return err
@@ -174,6 +178,10 @@ func (r *Reader) Intersect(ctx context.Context, visitor types.IntersectVisitor)
 }
 
 func (r *Reader) EstimatePointCount(ctx context.Context, visitor types.IntersectVisitor) (int, error) {
+	if ctx == nil {
+		// callers on the search side don't always carry a context
+		ctx = context.Background()
+	}
 	state, err := r.GetIntersectState(ctx, visitor)
 	if err != nil {
 		return -1, nil
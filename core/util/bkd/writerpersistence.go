@@ -51,7 +51,7 @@ func (w *Writer) writeIndex(ctx context.Context, metaOut, indexOut store.IndexOu
 	if err := metaOut.WriteUvarint(ctx, uint64(w.pointCount)); err != nil {
 		return err
 	}
-	if err := metaOut.WriteUvarint(ctx, uint64(w.docsSeen.Len())); err != nil {
+	if err := metaOut.WriteUvarint(ctx, uint64(w.docsSeen.Count())); err != nil {
 		return err
 	}
 	if err := metaOut.WriteUvarint(ctx, uint64(len(packedIndex))); err != nil {
@@ -194,7 +194,7 @@ func (w *Writer) writeHighCardinalityLeafBlockPackedValues(out store.DataOutput,
 
 	commonPrefixLengths[sortedDim]++
 
-	for i := 0; i < count; i++ {
+	for i := 0; i < count; {
 		runLen := runLen(packedValues, i, min(i+0xff, count), compressedByteOffset)
 		first := packedValues(i)
 		prefixByte := first[compressedByteOffset]
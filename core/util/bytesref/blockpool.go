@@ -138,7 +138,7 @@ func (r *BlockPool) NewSlice(size int) int {
 // AllocSlice
 // Creates a new byte slice with the given starting size and returns the slices offset in the pool.
 func (r *BlockPool) AllocSlice(slice []byte, upto int) int {
-	level := slice[upto]
+	level := slice[upto] & 15
 	newLevel := NEXT_LEVEL_ARRAY[level]
 	newSize := LEVEL_SIZE_ARRAY[newLevel]
 
@@ -207,7 +207,7 @@ func (r *BlockPool) GetAddress(offset uint32) ([]byte, error) {
 	values := r.buffers[bufferIndex]
 
 	size, n := binary.Uvarint(values[pos:])
-	if size == 0 {
+	if n <= 0 {
 		return nil, io.EOF
 	}
 
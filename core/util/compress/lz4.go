@@ -0,0 +1,387 @@
+package compress
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/geange/lucene-go/core/store"
+)
+
+// LZ4 compression and decompression routines.
+//
+// https://github.com/lz4/lz4/tree/dev/lib
+// http://fastcompression.blogspot.fr/p/lz4.html
+//
+// The high-compression option is a simpler version of the one of the original algorithm, and
+// only retains a better hash table that remembers about more occurrences of a previous 4-bytes
+// sequence, and removes all the logic about handling of the case when overlapping matches are
+// found.
+// lucene.internal
+
+const (
+	// LZ4_MAX_DISTANCE Window size: this is the maximum supported distance between two strings
+	// so that LZ4 can replace the second one by a reference to the first one.
+	LZ4_MAX_DISTANCE = 1 << 16
+
+	// LZ4_MEMORY_USAGE log2 of the number of bytes used by the hash table of the fast compressor.
+	LZ4_MEMORY_USAGE = 14
+
+	lz4MinMatch     = 4 // minimum length of a match
+	lz4LastLiterals = 5 // the last 5 bytes are always encoded as literals
+	lz4HashLogHC    = 15
+)
+
+var ErrCorruptLZ4 = errors.New("corrupt LZ4 stream")
+
+func lz4Hash(i uint32, hashBits int) int {
+	return int((i * 0x9E3779B1) >> (32 - hashBits))
+}
+
+func lz4ReadInt(buf []byte, i int) uint32 {
+	return binary.BigEndian.Uint32(buf[i:])
+}
+
+func lz4CommonBytes(b []byte, o1, o2, limit int) int {
+	count := 0
+	for o2 < limit && b[o1] == b[o2] {
+		o1++
+		o2++
+		count++
+	}
+	return count
+}
+
+// LZ4Decompress Decompress at least decompressedLen bytes into dest. Note that the output
+// may contain more bytes than decompressedLen: the full sequence which contains the last
+// requested byte is always decompressed, as long as it fits in dest.
+// Returns the number of bytes written to dest.
+func LZ4Decompress(compressed store.DataInput, decompressedLen int, dest []byte) (int, error) {
+	dOff := 0
+	for {
+		// literals
+		token, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		literalLen := int(token >> 4)
+
+		if literalLen != 0 {
+			if literalLen == 0x0F {
+				extra, err := lz4ReadLength(compressed)
+				if err != nil {
+					return 0, err
+				}
+				literalLen += extra
+			}
+			if dOff+literalLen > len(dest) {
+				return 0, ErrCorruptLZ4
+			}
+			if _, err := io.ReadFull(compressed, dest[dOff:dOff+literalLen]); err != nil {
+				return 0, err
+			}
+			dOff += literalLen
+		}
+
+		if dOff >= decompressedLen {
+			break
+		}
+
+		// matchs
+		lo, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		hi, err := compressed.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		matchDec := int(lo) | int(hi)<<8
+
+		matchLen := int(token & 0x0F)
+		if matchLen == 0x0F {
+			extra, err := lz4ReadLength(compressed)
+			if err != nil {
+				return 0, err
+			}
+			matchLen += extra
+		}
+		matchLen += lz4MinMatch
+
+		ref := dOff - matchDec
+		if matchDec == 0 || ref < 0 || dOff+matchLen > len(dest) {
+			return 0, ErrCorruptLZ4
+		}
+
+		// copying a multiple of 8 bytes can make decompression from 5% to 10% faster, but
+		// the source and destination may overlap so copy byte by byte when they do
+		if matchDec >= matchLen {
+			copy(dest[dOff:dOff+matchLen], dest[ref:ref+matchLen])
+		} else {
+			for i := 0; i < matchLen; i++ {
+				dest[dOff+i] = dest[ref+i]
+			}
+		}
+		dOff += matchLen
+
+		if dOff >= decompressedLen {
+			break
+		}
+	}
+	return dOff, nil
+}
+
+func lz4ReadLength(in store.DataInput) (int, error) {
+	n := 0
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n += int(b)
+		if b != 0xFF {
+			return n, nil
+		}
+	}
+}
+
+func lz4EncodeLen(l int, out store.DataOutput) error {
+	for l >= 0xFF {
+		if err := out.WriteByte(0xFF); err != nil {
+			return err
+		}
+		l -= 0xFF
+	}
+	return out.WriteByte(byte(l))
+}
+
+func lz4EncodeLiterals(bytes []byte, token int, anchor, literalLen int, out store.DataOutput) error {
+	if err := out.WriteByte(byte(token)); err != nil {
+		return err
+	}
+
+	// encode literal length
+	if literalLen >= 0x0F {
+		if err := lz4EncodeLen(literalLen-0x0F, out); err != nil {
+			return err
+		}
+	}
+
+	// encode literals
+	_, err := out.Write(bytes[anchor : anchor+literalLen])
+	return err
+}
+
+func lz4EncodeLastLiterals(bytes []byte, anchor, literalLen int, out store.DataOutput) error {
+	token := min(literalLen, 0x0F) << 4
+	return lz4EncodeLiterals(bytes, token, anchor, literalLen, out)
+}
+
+func lz4EncodeSequence(bytes []byte, anchor, matchRef, matchOff, matchLen int, out store.DataOutput) error {
+	literalLen := matchOff - anchor
+	// encode token
+	token := (min(literalLen, 0x0F) << 4) | min(matchLen-4, 0x0F)
+	if err := lz4EncodeLiterals(bytes, token, anchor, literalLen, out); err != nil {
+		return err
+	}
+
+	// encode match dec
+	matchDec := matchOff - matchRef
+	if err := out.WriteByte(byte(matchDec)); err != nil {
+		return err
+	}
+	if err := out.WriteByte(byte(matchDec >> 8)); err != nil {
+		return err
+	}
+
+	// encode match len
+	if matchLen >= lz4MinMatch+0x0F {
+		return lz4EncodeLen(matchLen-0x0F-lz4MinMatch, out)
+	}
+	return nil
+}
+
+// LZ4HashTable A record of previous occurrences of sequences of 4 bytes.
+type LZ4HashTable interface {
+	// Reset this hash table in order to compress the given content.
+	reset(bytes []byte)
+
+	// Advance the cursor to off and return an index that stored the same 4 bytes as
+	// bytes[off:off+4]. This may only be called on strictly increasing sequences of offsets.
+	// A return value of -1 indicates that no other index could be found.
+	get(off int) int
+
+	// Return an index that is less than off and stores the same 4 bytes. Unlike get, it
+	// doesn't need to be called on increasing offsets. A return value of -1 indicates that
+	// no other index could be found.
+	previous(off int) int
+}
+
+var _ LZ4HashTable = &LZ4FastHashTable{}
+
+// LZ4FastHashTable Simple lossy LZ4HashTable that only stores the last occurrence for each
+// hash on 2^14 bytes of memory.
+type LZ4FastHashTable struct {
+	hashTable []int32
+	bytes     []byte
+}
+
+// NewLZ4FastHashTable Create a new instance.
+func NewLZ4FastHashTable() *LZ4FastHashTable {
+	return &LZ4FastHashTable{
+		// 4 bytes per entry
+		hashTable: make([]int32, 1<<(LZ4_MEMORY_USAGE-2)),
+	}
+}
+
+func (h *LZ4FastHashTable) reset(bytes []byte) {
+	h.bytes = bytes
+	for i := range h.hashTable {
+		h.hashTable[i] = -1
+	}
+}
+
+func (h *LZ4FastHashTable) get(off int) int {
+	v := lz4ReadInt(h.bytes, off)
+	idx := lz4Hash(v, LZ4_MEMORY_USAGE-2)
+	ref := int(h.hashTable[idx])
+	h.hashTable[idx] = int32(off)
+	return ref
+}
+
+func (h *LZ4FastHashTable) previous(off int) int {
+	return -1
+}
+
+var _ LZ4HashTable = &LZ4HighHashTable{}
+
+// LZ4HighHashTable A higher-precision LZ4HashTable. It stores up to 256 occurrences of
+// 4-bytes sequences in the last 2^16 bytes, which makes it much more likely to find matches
+// than LZ4FastHashTable.
+type LZ4HighHashTable struct {
+	next       int
+	hashTable  []int
+	chainTable []uint16
+	attempts   int
+	bytes      []byte
+}
+
+const (
+	lz4MaxAttempts = 256
+	lz4Mask        = LZ4_MAX_DISTANCE - 1
+)
+
+// NewLZ4HighHashTable Create a new instance.
+func NewLZ4HighHashTable() *LZ4HighHashTable {
+	return &LZ4HighHashTable{
+		hashTable:  make([]int, 1<<lz4HashLogHC),
+		chainTable: make([]uint16, LZ4_MAX_DISTANCE),
+	}
+}
+
+func (h *LZ4HighHashTable) reset(bytes []byte) {
+	for i := range h.hashTable {
+		h.hashTable[i] = -1
+	}
+	clear(h.chainTable)
+	h.bytes = bytes
+	h.next = 0
+}
+
+func (h *LZ4HighHashTable) addHash(off int) {
+	v := lz4ReadInt(h.bytes, off)
+	idx := lz4Hash(v, lz4HashLogHC)
+	delta := LZ4_MAX_DISTANCE - 1
+	if prev := h.hashTable[idx]; prev != -1 && off-prev < LZ4_MAX_DISTANCE {
+		delta = off - prev
+	}
+	h.chainTable[off&lz4Mask] = uint16(delta)
+	h.hashTable[idx] = off
+}
+
+func (h *LZ4HighHashTable) get(off int) int {
+	for h.next < off {
+		h.addHash(h.next)
+		h.next++
+	}
+
+	v := lz4ReadInt(h.bytes, off)
+	ref := h.hashTable[lz4Hash(v, lz4HashLogHC)]
+
+	h.attempts = 0
+	for ref >= 0 && off-ref < LZ4_MAX_DISTANCE && h.attempts < lz4MaxAttempts {
+		if lz4ReadInt(h.bytes, ref) == v {
+			return ref
+		}
+		ref -= int(h.chainTable[ref&lz4Mask])
+		h.attempts++
+	}
+	return -1
+}
+
+func (h *LZ4HighHashTable) previous(off int) int {
+	v := lz4ReadInt(h.bytes, off)
+	ref := off - int(h.chainTable[off&lz4Mask])
+	for ref >= 0 && ref < off && h.attempts < lz4MaxAttempts {
+		if lz4ReadInt(h.bytes, ref) == v {
+			return ref
+		}
+		ref -= int(h.chainTable[ref&lz4Mask])
+		h.attempts++
+	}
+	return -1
+}
+
+// LZ4Compress Compress bytes into out using the given hash table: LZ4FastHashTable uses at
+// most 16KB of memory, LZ4HighHashTable compresses better at the cost of speed.
+// ht shouldn't be shared across goroutines but can safely be reused.
+func LZ4Compress(bytes []byte, out store.DataOutput, ht LZ4HashTable) error {
+	end := len(bytes)
+	off := 0
+	anchor := 0
+
+	if len(bytes) > lz4LastLiterals+lz4MinMatch {
+		limit := end - lz4LastLiterals
+		matchLimit := limit - lz4MinMatch
+		ht.reset(bytes)
+
+	main:
+		for off <= limit {
+			// find a match
+			var ref int
+			for {
+				if off >= matchLimit {
+					break main
+				}
+				ref = ht.get(off)
+				if ref != -1 && ref < off && off-ref < LZ4_MAX_DISTANCE &&
+					lz4ReadInt(bytes, ref) == lz4ReadInt(bytes, off) {
+					break
+				}
+				off++
+			}
+
+			// compute match length
+			matchLen := lz4MinMatch + lz4CommonBytes(bytes, ref+lz4MinMatch, off+lz4MinMatch, limit)
+
+			// try to find a better match
+			for r2 := ht.previous(ref); r2 != -1 && off-r2 < LZ4_MAX_DISTANCE; r2 = ht.previous(r2) {
+				matchLen2 := lz4MinMatch + lz4CommonBytes(bytes, r2+lz4MinMatch, off+lz4MinMatch, limit)
+				if matchLen2 > matchLen {
+					ref = r2
+					matchLen = matchLen2
+				}
+			}
+
+			if err := lz4EncodeSequence(bytes, anchor, ref, off, matchLen, out); err != nil {
+				return err
+			}
+			off += matchLen
+			anchor = off
+		}
+	}
+
+	// last literals
+	literalLen := end - anchor
+	return lz4EncodeLastLiterals(bytes, anchor, literalLen, out)
+}
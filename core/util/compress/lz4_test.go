@@ -0,0 +1,72 @@
+package compress
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/geange/lucene-go/core/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLZ4RoundTrip(t *testing.T, data []byte, ht LZ4HashTable) int {
+	out := store.NewBufferDataOutput()
+	err := LZ4Compress(data, out, ht)
+	assert.Nil(t, err)
+
+	compressed := out.Bytes()
+	restored := make([]byte, len(data))
+	n, err := LZ4Decompress(store.NewBytesInput(compressed), len(data), restored)
+	assert.Nil(t, err)
+	assert.Equal(t, len(data), n)
+	assert.True(t, bytes.Equal(data, restored[:n]))
+	return len(compressed)
+}
+
+func TestLZ4(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	inputs := map[string][]byte{
+		"empty":  {},
+		"short":  []byte("abc"),
+		"repeat": bytes.Repeat([]byte("a"), 1000),
+		"text":   bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog "), 300),
+	}
+
+	random := make([]byte, 100000)
+	r.Read(random)
+	inputs["random"] = random
+
+	// long runs of few symbols, larger than the window
+	lowEntropy := make([]byte, 200000)
+	for i := range lowEntropy {
+		lowEntropy[i] = byte('a' + r.Intn(3))
+	}
+	inputs["lowEntropy"] = lowEntropy
+
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			fast := testLZ4RoundTrip(t, data, NewLZ4FastHashTable())
+			high := testLZ4RoundTrip(t, data, NewLZ4HighHashTable())
+			assert.LessOrEqual(t, high, fast+len(data)/100+16)
+		})
+	}
+
+	t.Run("compressible", func(t *testing.T) {
+		data := inputs["text"]
+		size := testLZ4RoundTrip(t, data, NewLZ4FastHashTable())
+		assert.Less(t, size, len(data)/10)
+	})
+
+	t.Run("reuse hash table", func(t *testing.T) {
+		fast, high := NewLZ4FastHashTable(), NewLZ4HighHashTable()
+		for i := 0; i < 20; i++ {
+			data := make([]byte, r.Intn(5000))
+			for j := range data {
+				data[j] = byte('a' + r.Intn(4))
+			}
+			testLZ4RoundTrip(t, data, fast)
+			testLZ4RoundTrip(t, data, high)
+		}
+	})
+}
@@ -32,8 +32,8 @@ func NewFrequencyTrackingRingBuffer(maxSize int, sentinel int32) *FrequencyTrack
 // Add a new item to this ring buffer, potentially removing the oldest entry from this buffer if it is already full.
 func (f *FrequencyTrackingRingBuffer) Add(i int32) {
 	// remove the previous value
-	//removed := f.buffer[f.position]
-	//removedFromBag := f.frequencies.remove(removed)
+	removed := f.buffer[f.position]
+	f.frequencies.remove(removed)
 	// add the new value
 	f.buffer[f.position] = i
 	f.frequencies.add(i)
@@ -71,7 +71,7 @@ func newIntBag(maxSize int) *intBag {
 
 // Return the frequency of the give key in the bag.
 func (i *intBag) frequency(key int32) int32 {
-	for slot := key & i.mask; ; slot++ {
+	for slot := key & i.mask; ; slot = (slot + 1) & i.mask {
 		if i.keys[slot] == key {
 			return i.freqs[slot]
 		} else if i.freqs[slot] == 0 {
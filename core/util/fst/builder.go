@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/binary"
 	"math"
-	"slices"
 )
 
 // Builder
@@ -407,6 +406,15 @@ func (b *Builder) AddStr(ctx context.Context, input string, output Output) error
 	return b.Add(ctx, []rune(input), output)
 }
 
+// AddBytes adds a binary input, one label per byte, as expected by BYTE1 FSTs.
+func (b *Builder) AddBytes(ctx context.Context, input []byte, output Output) error {
+	newInput := make([]int, len(input))
+	for i, v := range input {
+		newInput[i] = int(v)
+	}
+	return b.AddInts(ctx, newInput, output)
+}
+
 func (b *Builder) Add(ctx context.Context, input []rune, output Output) error {
 	newInput := make([]int, len(input))
 	for i, v := range input {
@@ -454,12 +462,8 @@ func (b *Builder) AddInts(ctx context.Context, input []int, output Output) error
 	// 如果frontier长度小于输入的长度，进行扩容
 	inputLenPlus1 := len(input) + 1
 	if len(b.frontier) < inputLenPlus1 {
-		frontierSize := len(b.frontier)
-
-		b.frontier = slices.Grow(b.frontier, inputLenPlus1)
-
-		for i := frontierSize; i < inputLenPlus1; i++ {
-			b.frontier[i] = NewUnCompiledNode(b, i)
+		for i := len(b.frontier); i < inputLenPlus1; i++ {
+			b.frontier = append(b.frontier, NewUnCompiledNode(b, i))
 		}
 	}
 
@@ -490,10 +494,10 @@ func (b *Builder) AddInts(ctx context.Context, input []int, output Output) error
 
 		lastOutput := parentNode.GetLastOutput()
 
-		var commonOutputPrefix Output
-		var wordSuffix Output
+		commonOutputPrefix := b.noOutput
 
 		if !lastOutput.IsNoOutput() {
+			var wordSuffix Output
 			commonOutputPrefix, err = output.Common(lastOutput)
 			if err != nil {
 				return err
@@ -536,7 +540,7 @@ func (b *Builder) AddInts(ctx context.Context, input []int, output Output) error
 	}
 
 	// save last input
-	b.lastInput = input
+	b.lastInput = append(b.lastInput[:0], input...)
 
 	return err
 }
@@ -190,7 +190,7 @@ func (b *builderBytesReader) ReadByte() (byte, error) {
 			return 0, ErrItemNotFound
 		}
 		b.current = current
-		b.nextBuffer++
+		b.nextBuffer--
 		b.nextRead = int(b.bs.blockSize - 1)
 	}
 	v := b.current[b.nextRead]
@@ -86,6 +86,7 @@ func (r *ByteStore) WriteByte(b byte) error {
 	if r.nextWrite == r.blockSize || len(r.current) == 0 {
 		r.current = make([]byte, r.blockSize)
 		r.blocks.Add(r.current)
+		r.nextWrite = 0
 	}
 	r.current[r.nextWrite] = b
 	r.nextWrite++
@@ -367,8 +368,8 @@ func (r *ByteStore) SkipBytes(size int64) error {
 		}
 
 		size -= chunk
-		current := make([]byte, r.blockSize)
-		r.blocks.Add(current)
+		r.current = make([]byte, r.blockSize)
+		r.blocks.Add(r.current)
 		r.nextWrite = 0
 	}
 	return nil
@@ -2,8 +2,11 @@ package fst
 
 import (
 	"context"
+	"math/rand"
+	"sort"
 	"testing"
 
+	"github.com/geange/lucene-go/core/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -393,3 +396,98 @@ func TestBytesEnumSeekFloor(t *testing.T) {
 		}
 	}
 }
+
+func TestBytesEnumSeekRandomLongKeys(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewSource(42))
+
+	keySet := make(map[string]struct{})
+	for len(keySet) < 3000 {
+		key := make([]byte, 1+r.Intn(48))
+		for i := range key {
+			key[i] = byte('a' + r.Intn(6))
+		}
+		keySet[string(key)] = struct{}{}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder, err := NewBuilder(BYTE1, NewBoxManager[int64]())
+	assert.Nil(t, err)
+
+	// the builder must not keep a reference to the caller's input
+	input := make([]int, 0, 64)
+	for i, key := range keys {
+		input = input[:0]
+		for _, b := range []byte(key) {
+			input = append(input, int(b))
+		}
+		err = builder.AddInts(ctx, input, NewIntBox[int64](int64(i+1)))
+		assert.Nil(t, err)
+	}
+
+	fst, err := builder.Finish(ctx)
+	assert.Nil(t, err)
+
+	metaOut := store.NewBufferDataOutput()
+	dataOut := store.NewBufferDataOutput()
+	err = fst.Save(ctx, metaOut, dataOut)
+	assert.Nil(t, err)
+
+	loaded, err := NewFstV1(ctx, NewBoxManager[int64](), store.NewBytesInput(metaOut.Bytes()), store.NewBytesInput(dataOut.Bytes()))
+	assert.Nil(t, err)
+
+	for _, f := range []*FST{fst, loaded} {
+		fstEnum, err := NewEnum[byte](f)
+		assert.Nil(t, err)
+
+		for i, key := range keys {
+			next, err := fstEnum.Next(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, key, string(next.GetInput()))
+			assert.Equal(t, int64(i+1), next.GetOutput().(*IntBox[int64]).Value())
+		}
+
+		// the same enum is reused for every seek
+		for i := 0; i < 2000; i++ {
+			target := make([]byte, 1+r.Intn(50))
+			for j := range target {
+				target[j] = byte('a' + r.Intn(7))
+			}
+			idx := sort.SearchStrings(keys, string(target))
+
+			result, ok, err := fstEnum.SeekCeil(ctx, target)
+			assert.Nil(t, err)
+			if idx == len(keys) {
+				assert.False(t, ok)
+			} else {
+				assert.True(t, ok)
+				assert.Equal(t, keys[idx], string(result.GetInput()))
+				assert.Equal(t, int64(idx+1), result.GetOutput().(*IntBox[int64]).Value())
+			}
+
+			floor := idx
+			if idx == len(keys) || keys[idx] != string(target) {
+				floor--
+			}
+			result, ok, err = fstEnum.SeekFloor(ctx, target)
+			assert.Nil(t, err)
+			if floor < 0 {
+				assert.False(t, ok)
+			} else {
+				assert.True(t, ok)
+				assert.Equal(t, keys[floor], string(result.GetInput()))
+				assert.Equal(t, int64(floor+1), result.GetOutput().(*IntBox[int64]).Value())
+			}
+
+			key := keys[r.Intn(len(keys))]
+			result, ok, err = fstEnum.SeekExact(ctx, []byte(key))
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, key, string(result.GetInput()))
+		}
+	}
+}
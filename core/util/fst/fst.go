@@ -318,9 +318,8 @@ func (f *FST) AddNode(ctx context.Context, builder *Builder, nodeIn *UnCompiledN
 	doFixedLengthArcs := shouldExpandNodeWithFixedLengthArcs(builder, nodeIn)
 	if doFixedLengthArcs {
 		if len(builder.numBytesPerArc) < nodeIn.NumArcs() {
-			size := array.Oversize(nodeIn.NumArcs(), INTEGER_BYTES)
-			builder.numBytesPerArc = make([]int, size)
-			builder.numLabelBytesPerArc = make([]int, size)
+			builder.numBytesPerArc = array.Grow(builder.numBytesPerArc, nodeIn.NumArcs())
+			builder.numLabelBytesPerArc = array.Grow(builder.numLabelBytesPerArc, nodeIn.NumArcs())
 		}
 	}
 
@@ -63,7 +63,7 @@ func IntToSortableBytes(value int32, result []byte) {
 // Decodes an integer value previously written with intToSortableBytes
 // 请参阅: IntToSortableBytes(int, byte[])
 func SortableBytesToInt(encoded []byte) int32 {
-	return int32(binary.BigEndian.Uint32(encoded))
+	return int32(binary.BigEndian.Uint32(encoded) ^ 0x80000000)
 }
 
 // Float64ToSortableLong
@@ -89,3 +89,26 @@ func SortableUint64ToFloat64(encoded uint64) float64 {
 func SortableFloat64Bits(bits uint64) uint64 {
 	return bits ^ uint64(int64(bits)>>63)&0x7fffffffffffffff
 }
+
+// Float32ToSortableInt
+// Converts a float value to a sortable signed int. The value is converted by getting their IEEE 754
+// floating-point "float format" bit layout and then some bits are swapped, to be able to compare the
+// result as int. By this the precision is not reduced, but the value can easily used as an int.
+// The sort order (including NaN) is defined by Float.compareTo; NaN is greater than positive infinity.
+// 请参阅: SortableInt32ToFloat32
+func Float32ToSortableInt(value float32) int32 {
+	return SortableFloat32Bits(int32(math.Float32bits(value)))
+}
+
+// SortableInt32ToFloat32
+// Converts a sortable int back to a float.
+// 请参阅: Float32ToSortableInt
+func SortableInt32ToFloat32(encoded int32) float32 {
+	return math.Float32frombits(uint32(SortableFloat32Bits(encoded)))
+}
+
+// SortableFloat32Bits
+// Converts IEEE 754 representation of a float to sortable order (or back to the original)
+func SortableFloat32Bits(bits int32) int32 {
+	return bits ^ (bits>>31)&0x7fffffff
+}
@@ -67,3 +67,23 @@ func TestSortableDoubleBits(t *testing.T) {
 		sortNum = preNum
 	}
 }
+
+func TestSortableFloatBits(t *testing.T) {
+	nums := []float32{
+		float32(math.Inf(-1)),
+		-10,
+		-0.5,
+		0,
+		1,
+		10,
+		float32(math.Inf(1)),
+	}
+
+	preNum := int32(math.MinInt32)
+	for _, num := range nums {
+		sortNum := Float32ToSortableInt(num)
+		assert.True(t, sortNum > preNum)
+		assert.Equal(t, num, SortableInt32ToFloat32(sortNum))
+		preNum = sortNum
+	}
+}
@@ -0,0 +1,306 @@
+package packed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/geange/lucene-go/core/store"
+)
+
+const (
+	// DIRECT_MONOTONIC_MIN_BLOCK_SHIFT
+	// The smallest supported block shift, blocks hold at least 2 values.
+	DIRECT_MONOTONIC_MIN_BLOCK_SHIFT = 2
+
+	// DIRECT_MONOTONIC_MAX_BLOCK_SHIFT
+	// The largest supported block shift.
+	DIRECT_MONOTONIC_MAX_BLOCK_SHIFT = 22
+)
+
+// DirectMonotonicWriter
+// Write monotonically-increasing sequences of integers. This writer splits data into blocks and
+// then for each block, computes the average slope, the minimum value and only encode the delta
+// from the expected value using a DirectWriter.
+//
+// Metadata (min, average, offset and bits per value of every block) goes to the meta output, so
+// that it can be loaded in memory, while deltas go to the data output.
+// See Also: DirectMonotonicReader
+// lucene.internal
+type DirectMonotonicWriter struct {
+	meta             store.DataOutput
+	data             store.IndexOutput
+	numValues        int
+	baseDataPointer  int64
+	buffer           []int64
+	bufferSize       int
+	count            int
+	finished         bool
+	previous         int64
+	previousAssigned bool
+}
+
+// NewDirectMonotonicWriter
+// Returns an instance suitable for encoding numValues into monotonic blocks of 2^blockShift
+// values. Metadata will be written to meta and actual data to data.
+func NewDirectMonotonicWriter(meta store.DataOutput, data store.IndexOutput, numValues, blockShift int) (*DirectMonotonicWriter, error) {
+	if blockShift < DIRECT_MONOTONIC_MIN_BLOCK_SHIFT || blockShift > DIRECT_MONOTONIC_MAX_BLOCK_SHIFT {
+		return nil, fmt.Errorf("blockShift must be in [%d-%d], got %d",
+			DIRECT_MONOTONIC_MIN_BLOCK_SHIFT, DIRECT_MONOTONIC_MAX_BLOCK_SHIFT, blockShift)
+	}
+	if numValues < 0 {
+		return nil, fmt.Errorf("numValues can't be negative, got %d", numValues)
+	}
+
+	numBlocks := 0
+	if numValues != 0 {
+		numBlocks = ((numValues - 1) >> blockShift) + 1
+	}
+	if numBlocks > math.MaxInt32 {
+		return nil, fmt.Errorf("blockShift is too low for the provided number of values: blockShift=%d, numValues=%d",
+			blockShift, numValues)
+	}
+
+	return &DirectMonotonicWriter{
+		meta:            meta,
+		data:            data,
+		numValues:       numValues,
+		baseDataPointer: data.GetFilePointer(),
+		buffer:          make([]int64, min(numValues, 1<<blockShift)),
+	}, nil
+}
+
+func (d *DirectMonotonicWriter) flush(ctx context.Context) error {
+	if d.bufferSize == 0 {
+		return errors.New("flush an empty buffer")
+	}
+
+	avgInc := float32(float64(d.buffer[d.bufferSize-1]-d.buffer[0]) / float64(max(1, d.bufferSize-1)))
+	for i := 0; i < d.bufferSize; i++ {
+		expected := int64(avgInc * float32(i))
+		d.buffer[i] -= expected
+	}
+
+	minValue := d.buffer[0]
+	for i := 1; i < d.bufferSize; i++ {
+		minValue = min(d.buffer[i], minValue)
+	}
+
+	maxDelta := uint64(0)
+	for i := 0; i < d.bufferSize; i++ {
+		d.buffer[i] -= minValue
+		// use | will change nothing when it comes to computing required bits
+		// but has the benefit of working fine with negative values too
+		// (in case of overflow)
+		maxDelta |= uint64(d.buffer[i])
+	}
+
+	if err := d.meta.WriteUint64(ctx, uint64(minValue)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint32(ctx, math.Float32bits(avgInc)); err != nil {
+		return err
+	}
+	if err := d.meta.WriteUint64(ctx, uint64(d.data.GetFilePointer()-d.baseDataPointer)); err != nil {
+		return err
+	}
+
+	if maxDelta == 0 {
+		if err := d.meta.WriteByte(0); err != nil {
+			return err
+		}
+	} else {
+		bitsRequired := DirectUnsignedBitsRequired(maxDelta)
+		writer, err := NewDirectWriter(d.data, d.bufferSize, bitsRequired)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < d.bufferSize; i++ {
+			if err := writer.Add(uint64(d.buffer[i])); err != nil {
+				return err
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			return err
+		}
+		if err := d.meta.WriteByte(byte(bitsRequired)); err != nil {
+			return err
+		}
+	}
+	d.bufferSize = 0
+	return nil
+}
+
+// Add
+// Write a new value. Note that data might not make it to storage until Finish() is called.
+// Returns an error if v is less than the previous value.
+func (d *DirectMonotonicWriter) Add(ctx context.Context, v int64) error {
+	if d.previousAssigned && v < d.previous {
+		return fmt.Errorf("values do not come in order: %d, %d", d.previous, v)
+	}
+	if d.bufferSize == len(d.buffer) {
+		if err := d.flush(ctx); err != nil {
+			return err
+		}
+	}
+	d.buffer[d.bufferSize] = v
+	d.bufferSize++
+	d.previous = v
+	d.previousAssigned = true
+	d.count++
+	return nil
+}
+
+// Finish
+// This must be called exactly once after all values have been added.
+func (d *DirectMonotonicWriter) Finish(ctx context.Context) error {
+	if d.count != d.numValues {
+		return fmt.Errorf("wrong number of values added, expected: %d, got: %d", d.numValues, d.count)
+	}
+	if d.finished {
+		return errors.New("#finish has been called already")
+	}
+	if d.bufferSize > 0 {
+		if err := d.flush(ctx); err != nil {
+			return err
+		}
+	}
+	d.finished = true
+	return nil
+}
+
+// DirectMonotonicMeta
+// In-memory metadata that needs to be kept around for DirectMonotonicReader to read data from disk.
+type DirectMonotonicMeta struct {
+	blockShift int
+	numBlocks  int
+	mins       []int64
+	avgs       []float32
+	bpvs       []byte
+	offsets    []int64
+}
+
+// LoadDirectMonotonicMeta
+// Load metadata from the given DataInput.
+func LoadDirectMonotonicMeta(ctx context.Context, metaIn store.DataInput, numValues, blockShift int) (*DirectMonotonicMeta, error) {
+	numBlocks := numValues >> blockShift
+	if numBlocks<<blockShift < numValues {
+		numBlocks++
+	}
+
+	meta := &DirectMonotonicMeta{
+		blockShift: blockShift,
+		numBlocks:  numBlocks,
+		mins:       make([]int64, numBlocks),
+		avgs:       make([]float32, numBlocks),
+		bpvs:       make([]byte, numBlocks),
+		offsets:    make([]int64, numBlocks),
+	}
+
+	for i := 0; i < numBlocks; i++ {
+		minValue, err := metaIn.ReadUint64(ctx)
+		if err != nil {
+			return nil, err
+		}
+		meta.mins[i] = int64(minValue)
+
+		avg, err := metaIn.ReadUint32(ctx)
+		if err != nil {
+			return nil, err
+		}
+		meta.avgs[i] = math.Float32frombits(avg)
+
+		offset, err := metaIn.ReadUint64(ctx)
+		if err != nil {
+			return nil, err
+		}
+		meta.offsets[i] = int64(offset)
+
+		bpv, err := metaIn.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		meta.bpvs[i] = bpv
+	}
+	return meta, nil
+}
+
+// DirectMonotonicReader
+// Retrieves an instance previously written by DirectMonotonicWriter.
+// See Also: DirectMonotonicWriter
+type DirectMonotonicReader struct {
+	blockShift int
+	blockMask  int64
+	readers    []LongValuesReader
+	mins       []int64
+	avgs       []float32
+}
+
+// NewDirectMonotonicReader
+// Retrieves an instance from the specified slice.
+func NewDirectMonotonicReader(meta *DirectMonotonicMeta, data store.RandomAccessInput) (*DirectMonotonicReader, error) {
+	readers := make([]LongValuesReader, meta.numBlocks)
+	for i := range readers {
+		if meta.bpvs[i] == 0 {
+			readers[i] = zeroLongValuesReader{}
+			continue
+		}
+
+		reader, err := NewDirectReader().GetInstance(data, int(meta.bpvs[i]), meta.offsets[i])
+		if err != nil {
+			return nil, err
+		}
+		readers[i] = reader
+	}
+
+	return &DirectMonotonicReader{
+		blockShift: meta.blockShift,
+		blockMask:  int64(1)<<meta.blockShift - 1,
+		readers:    readers,
+		mins:       meta.mins,
+		avgs:       meta.avgs,
+	}, nil
+}
+
+// Get
+// Returns the value at the given index.
+func (d *DirectMonotonicReader) Get(index int64) (int64, error) {
+	block := index >> d.blockShift
+	blockIndex := index & d.blockMask
+	delta, err := d.readers[block].Get(blockIndex)
+	if err != nil {
+		return 0, err
+	}
+	return d.mins[block] + int64(d.avgs[block]*float32(blockIndex)) + int64(delta), nil
+}
+
+// BinarySearch
+// Return the index of a key if it exists, or its insertion point otherwise like
+// slices.BinarySearch. This method only works on sequences of strictly increasing values
+// between fromIndex (inclusive) and toIndex (exclusive).
+func (d *DirectMonotonicReader) BinarySearch(fromIndex, toIndex, key int64) (int64, bool, error) {
+	lo, hi := fromIndex, toIndex-1
+	for lo <= hi {
+		mid := int64(uint64(lo+hi) >> 1)
+		midVal, err := d.Get(mid)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if midVal < key {
+			lo = mid + 1
+		} else if midVal > key {
+			hi = mid - 1
+		} else {
+			return mid, true, nil
+		}
+	}
+	return lo, false, nil
+}
+
+type zeroLongValuesReader struct{}
+
+func (zeroLongValuesReader) Get(index int64) (uint64, error) {
+	return 0, nil
+}
@@ -0,0 +1,88 @@
+package packed
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/geange/lucene-go/core/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectMonotonic(t *testing.T) {
+	testDirectMonotonic(t, []int64{}, 2)
+	testDirectMonotonic(t, []int64{5}, 2)
+	testDirectMonotonic(t, []int64{1, 1, 1, 1, 1, 1, 1}, 2)
+	testDirectMonotonic(t, []int64{-10, -3, 0, 7, 7, 100, 1 << 40}, 2)
+
+	r := rand.New(rand.NewSource(1))
+	values := make([]int64, 10000)
+	for i := 1; i < len(values); i++ {
+		values[i] = values[i-1] + r.Int63n(1000)
+	}
+	testDirectMonotonic(t, values, 4)
+	testDirectMonotonic(t, values, 10)
+	testDirectMonotonic(t, values, 16)
+}
+
+func testDirectMonotonic(t *testing.T, values []int64, blockShift int) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+
+	metaOut, err := dir.CreateOutput(ctx, "meta")
+	assert.Nil(t, err)
+	dataOut, err := dir.CreateOutput(ctx, "data")
+	assert.Nil(t, err)
+
+	writer, err := NewDirectMonotonicWriter(metaOut, dataOut, len(values), blockShift)
+	assert.Nil(t, err)
+	for _, v := range values {
+		assert.Nil(t, writer.Add(ctx, v))
+	}
+	assert.Nil(t, writer.Finish(ctx))
+	assert.NotNil(t, writer.Finish(ctx))
+	assert.Nil(t, metaOut.Close())
+	assert.Nil(t, dataOut.Close())
+
+	metaIn, err := dir.OpenInput(ctx, "meta")
+	assert.Nil(t, err)
+	meta, err := LoadDirectMonotonicMeta(ctx, metaIn, len(values), blockShift)
+	assert.Nil(t, err)
+
+	dataIn, err := dir.OpenInput(ctx, "data")
+	assert.Nil(t, err)
+	slice, err := dataIn.RandomAccessSlice(0, dataIn.Length())
+	assert.Nil(t, err)
+
+	reader, err := NewDirectMonotonicReader(meta, slice)
+	assert.Nil(t, err)
+	for i, v := range values {
+		got, err := reader.Get(int64(i))
+		assert.Nil(t, err)
+		assert.Equal(t, v, got)
+	}
+
+	if len(values) > 1 && values[0] < values[len(values)-1] {
+		idx, found, err := reader.BinarySearch(0, int64(len(values)), values[len(values)-1])
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, values[len(values)-1], values[idx])
+	}
+}
+
+func TestDirectMonotonicWriterOutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	dir, err := store.NewNIOFSDirectory(t.TempDir())
+	assert.Nil(t, err)
+
+	metaOut, err := dir.CreateOutput(ctx, "meta")
+	assert.Nil(t, err)
+	dataOut, err := dir.CreateOutput(ctx, "data")
+	assert.Nil(t, err)
+
+	writer, err := NewDirectMonotonicWriter(metaOut, dataOut, 2, 2)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Add(ctx, 10))
+	assert.NotNil(t, writer.Add(ctx, 9))
+}
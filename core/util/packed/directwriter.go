@@ -2,9 +2,11 @@ package packed
 
 import (
 	"errors"
+	"math"
+	"slices"
+
 	"github.com/geange/lucene-go/core/store"
 	"github.com/geange/lucene-go/core/util/packed/common"
-	"math"
 )
 
 // DirectWriter
@@ -68,10 +70,11 @@ func (d *DirectWriter) Add(v uint64) error {
 
 func (d *DirectWriter) flush() error {
 	d.encoder.EncodeBytes(d.nextValues, d.nextBlocks, d.iterations)
-	blockCount := FormatPacked.ByteCount(VERSION_CURRENT, len(d.nextValues), d.bitsPerValue)
+	blockCount := FormatPacked.ByteCount(VERSION_CURRENT, d.off, d.bitsPerValue)
 	if _, err := d.output.Write(d.nextBlocks[:blockCount]); err != nil {
 		return err
 	}
+	clear(d.nextValues)
 	d.off = 0
 	return nil
 }
@@ -96,23 +99,30 @@ func (d *DirectWriter) Finish() error {
 	return nil
 }
 
-// GetInstance
-// Returns an instance suitable for encoding numValues using bitsPerValue
-//func GetInstance(output store.DataOutput, numValues, bitsPerValue int) (*DirectWriter, error) {
-//	if _, ok := slices.BinarySearch(SUPPORTED_BITS_PER_VALUE, bitsPerValue); !ok {
-//		return nil, errors.New("unsupported bitsPerValue")
-//	}
-//	return NewDirectWriter(output, numValues, bitsPerValue)
-//}
+// DirectWriterBitsPerValue
+// The bits per value supported by DirectWriter and DirectReader.
+var DirectWriterBitsPerValue = []int{1, 2, 4, 8, 12, 16, 20, 24, 28, 32, 40, 48, 56, 64}
+
+// DirectBitsRequired
+// Returns how many bits are required to hold values up to and including maxValue, rounded up
+// to the next amount of bits per value supported by DirectWriter.
+func DirectBitsRequired(maxValue int64) (int, error) {
+	bitsRequired, err := BitsRequired(maxValue)
+	if err != nil {
+		return 0, err
+	}
+	return roundBits(bitsRequired), nil
+}
+
+// DirectUnsignedBitsRequired
+// Returns how many bits are required to hold values up to and including maxValue, interpreted as
+// an unsigned value, rounded up to the next amount of bits per value supported by DirectWriter.
+func DirectUnsignedBitsRequired(maxValue uint64) int {
+	return roundBits(UnsignedBitsRequired(maxValue))
+}
 
 // Round a number of bits per value to the next amount of bits per value that is supported by this writer.
-// bitsRequired – the amount of bits required
-// the next number of bits per value that is gte the provided value and supported by this writer
-//func roundBits(bitsRequired int) int {
-//	index, _ := slices.BinarySearch(SUPPORTED_BITS_PER_VALUE, bitsRequired)
-//	if index < 0 {
-//		return SUPPORTED_BITS_PER_VALUE[-index-1]
-//	} else {
-//		return bitsRequired
-//	}
-//}
+func roundBits(bitsRequired int) int {
+	index, _ := slices.BinarySearch(DirectWriterBitsPerValue, bitsRequired)
+	return DirectWriterBitsPerValue[index]
+}
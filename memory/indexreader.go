@@ -70,6 +70,10 @@ func (m *IndexReader) GetReaderCacheHelper() index.CacheHelper {
 	return nil
 }
 
+func (m *IndexReader) GetCoreCacheHelper() index.CacheHelper {
+	return nil
+}
+
 func (m *IndexReader) Terms(field string) (index.Terms, error) {
 	return m.memoryFields.Terms(field)
 }